@@ -0,0 +1,78 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/bench"
+	"github.com/hyperledger/firefly/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var benchClientConfig client.Config
+var benchOptions bench.Options
+var benchJSON bool
+
+// runBench is replaced in unit tests
+var runBench = bench.Run
+
+var benchCommand = &cobra.Command{
+	Use:   "bench",
+	Short: "Send messages to a running node, and report the latency of each stage of the message pipeline",
+	Long: `Sends a configurable load of broadcast, or private, messages to a running node using its API,
+and waits for each message to be confirmed by subscribing to events over a WebSocket.
+The latency of each stage of the pipeline is reported as percentiles.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+		c, err := client.New(ctx, &benchClientConfig)
+		if err != nil {
+			return err
+		}
+		report, err := runBench(ctx, c, &benchOptions)
+		if err != nil {
+			return err
+		}
+		if benchJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(report)
+		}
+		return report.WriteText(cmd.OutOrStdout())
+	},
+}
+
+func init() {
+	flags := benchCommand.Flags()
+	flags.StringVarP(&benchClientConfig.URL, "url", "u", "http://localhost:5000", "URL of the API of the node")
+	flags.StringVarP(&benchClientConfig.Namespace, "namespace", "n", client.DefaultNamespace, "namespace to send the messages in")
+	flags.StringVar(&benchClientConfig.Username, "username", "", "username for basic auth")
+	flags.StringVar(&benchClientConfig.Password, "password", "", "password for basic auth")
+	flags.IntVarP(&benchOptions.Count, "count", "c", 100, "number of messages to send")
+	flags.Float64VarP(&benchOptions.Rate, "rate", "r", 0, "messages to send per second, or as fast as possible if 0")
+	flags.IntVar(&benchOptions.Concurrency, "concurrency", 10, "number of API requests in-flight at once")
+	flags.IntVarP(&benchOptions.MessageSize, "size", "s", 1024, "size in bytes of the value sent in each message")
+	flags.IntVarP(&benchOptions.GroupSize, "groupsize", "g", 0, "send private messages to a group of this many organizations, rather than broadcasts")
+	flags.DurationVarP(&benchOptions.Timeout, "timeout", "t", 2*time.Minute, "how long to wait for all the messages to be confirmed")
+	flags.BoolVar(&benchJSON, "json", false, "output the report as JSON")
+	rootCmd.AddCommand(benchCommand)
+}
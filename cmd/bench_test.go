@@ -0,0 +1,93 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/bench"
+	"github.com/hyperledger/firefly/pkg/client"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func runBenchCommand(t *testing.T, fn func(ctx context.Context, c *client.Client, options *bench.Options) (*bench.Report, error), args ...string) (string, error) {
+	runBench = fn
+	out := &bytes.Buffer{}
+	rootCmd.SetOut(out)
+	rootCmd.SetArgs(append([]string{"bench"}, args...))
+	defer func() {
+		runBench = bench.Run
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs([]string{})
+		benchJSON = false
+		benchClientConfig.URL = "http://localhost:5000"
+	}()
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func testBenchReport() *bench.Report {
+	return &bench.Report{
+		Sent:      5,
+		Confirmed: 5,
+		Elapsed:   fftypes.FFDuration(time.Second),
+		Stages: []*bench.StageLatency{
+			{Stage: bench.StageTotal, Count: 5, P50: fftypes.FFDuration(time.Millisecond)},
+		},
+	}
+}
+
+func TestBenchText(t *testing.T) {
+	out, err := runBenchCommand(t, func(ctx context.Context, c *client.Client, options *bench.Options) (*bench.Report, error) {
+		assert.Equal(t, 5, options.Count)
+		assert.Equal(t, 2.5, options.Rate)
+		assert.Equal(t, 10, options.Concurrency)
+		assert.Equal(t, 64, options.MessageSize)
+		assert.Equal(t, 2, options.GroupSize)
+		assert.Equal(t, 30*time.Second, options.Timeout)
+		return testBenchReport(), nil
+	}, "--count", "5", "--rate", "2.5", "--size", "64", "--groupsize", "2", "--timeout", "30s")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "Confirmed:   5")
+	assert.Regexp(t, `total\s+5\s+1ms`, out)
+}
+
+func TestBenchJSON(t *testing.T) {
+	out, err := runBenchCommand(t, func(ctx context.Context, c *client.Client, options *bench.Options) (*bench.Report, error) {
+		return testBenchReport(), nil
+	}, "--json")
+	assert.NoError(t, err)
+	assert.Contains(t, out, `"confirmed": 5`)
+	assert.Contains(t, out, `"p50": "1ms"`)
+}
+
+func TestBenchFail(t *testing.T) {
+	_, err := runBenchCommand(t, func(ctx context.Context, c *client.Client, options *bench.Options) (*bench.Report, error) {
+		return nil, fmt.Errorf("pop")
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestBenchBadURL(t *testing.T) {
+	_, err := runBenchCommand(t, nil, "--url", ":bad")
+	assert.Regexp(t, "FF10162", err)
+}
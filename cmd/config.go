@@ -0,0 +1,92 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/internal/secrets/gcpsm"
+	"github.com/hyperledger/firefly/internal/secrets/vault"
+	"github.com/spf13/cobra"
+)
+
+var configCommand = &cobra.Command{
+	Use:   "config",
+	Short: "Configuration utilities",
+}
+
+var configCheckCommand = &cobra.Command{
+	Use:   "check",
+	Short: "Validate a configuration file, without starting the node",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		resetConfig()
+		if err := config.ReadConfig(cfgFile); err != nil {
+			return i18n.WrapError(ctx, err, i18n.MsgConfigFailed)
+		}
+		problems := checkConfig(ctx)
+		for _, p := range problems {
+			fmt.Fprintln(cmd.OutOrStdout(), p.Error())
+		}
+		if len(problems) > 0 {
+			return i18n.NewError(ctx, i18n.MsgConfigInvalid, len(problems))
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Configuration is valid")
+		return nil
+	},
+}
+
+func init() {
+	configCommand.AddCommand(configCheckCommand)
+	rootCmd.AddCommand(configCommand)
+}
+
+// resetConfig resets the configuration, ready for it to be read, including registering
+// the secrets providers that can be referenced from configuration values
+func resetConfig() {
+	config.Reset()
+	config.InitSecretsProviders(&vault.Vault{}, &gcpsm.SecretManager{})
+}
+
+func checkConfig(ctx context.Context) []error {
+	return append(config.Validate(ctx), orchestrator.ValidatePluginConfig(ctx)...)
+}
+
+// validateConfig is run on startup, and only fails if strict validation is enabled
+func validateConfig(ctx context.Context) error {
+	problems := checkConfig(ctx)
+	if len(problems) == 0 {
+		return nil
+	}
+	strict := config.GetBool(config.ConfigStrict)
+	for _, p := range problems {
+		if strict {
+			log.L(ctx).Errorf("Invalid configuration: %s", p)
+		} else {
+			log.L(ctx).Warnf("Invalid configuration: %s", p)
+		}
+	}
+	if strict {
+		return i18n.NewError(ctx, i18n.MsgConfigInvalid, len(problems))
+	}
+	return nil
+}
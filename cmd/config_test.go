@@ -0,0 +1,135 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const validTestConfig = `
+blockchain:
+  type: ethereum
+  ethereum:
+    ethconnect:
+      url: http://localhost:8080
+database:
+  type: sqlite3
+  sqlite3:
+    url: test.db
+dataexchange:
+  type: ffdx
+  ffdx:
+    url: http://localhost:5000
+sharedstorage:
+  type: ipfs
+  ipfs:
+    api:
+      url: http://localhost:5001
+`
+
+func runWithTestConfig(t *testing.T, yaml string, args ...string) (string, error) {
+	dir, err := ioutil.TempDir("", "ff")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	cfgPath := path.Join(dir, "firefly.core.yaml")
+	err = ioutil.WriteFile(cfgPath, []byte(yaml), 0664)
+	assert.NoError(t, err)
+
+	orchestrator.InitConfig()
+	out := &bytes.Buffer{}
+	rootCmd.SetOut(out)
+	rootCmd.SetArgs(append(args, "-f", cfgPath))
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs([]string{})
+		cfgFile = ""
+	}()
+	err = rootCmd.Execute()
+	return out.String(), err
+}
+
+func runConfigCheck(t *testing.T, yaml string) (string, error) {
+	return runWithTestConfig(t, yaml, "config", "check")
+}
+
+func TestConfigCheckValid(t *testing.T) {
+	out, err := runConfigCheck(t, validTestConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "Configuration is valid\n", out)
+}
+
+func TestConfigCheckInvalid(t *testing.T) {
+	out, err := runConfigCheck(t, validTestConfig+`
+http:
+  prot: 5000
+`)
+	assert.Regexp(t, "FF10383", err)
+	assert.Regexp(t, "FF10379.*http.prot", out)
+}
+
+func TestConfigCheckMissingFile(t *testing.T) {
+	rootCmd.SetArgs([]string{"config", "check", "-f", "nonexistent.yaml"})
+	defer func() {
+		rootCmd.SetArgs([]string{})
+		cfgFile = ""
+	}()
+	err := rootCmd.Execute()
+	assert.Regexp(t, "FF10101", err)
+}
+
+func TestExecStrictConfigInvalid(t *testing.T) {
+	_, err := runWithTestConfig(t, validTestConfig+`
+config:
+  strict: true
+http:
+  prot: 5000
+`)
+	assert.Regexp(t, "FF10383", err)
+}
+
+func TestValidateConfigOk(t *testing.T) {
+	config.Reset()
+	orchestrator.InitConfig()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(validTestConfig))
+	assert.NoError(t, err)
+	assert.NoError(t, validateConfig(context.Background()))
+}
+
+func TestValidateConfigWarnOnly(t *testing.T) {
+	config.Reset()
+	viper.Set("unknown.key", "value")
+	assert.NoError(t, validateConfig(context.Background()))
+}
+
+func TestValidateConfigStrict(t *testing.T) {
+	config.Reset()
+	viper.Set("unknown.key", "value")
+	config.Set(config.ConfigStrict, true)
+	assert.Regexp(t, "FF10383", validateConfig(context.Background()))
+}
@@ -84,13 +84,14 @@ func getOrchestrator() orchestrator.Orchestrator {
 // Execute is called by the main method of the package
 func Execute() error {
 	apiserver.InitConfig()
+	orchestrator.InitConfig()
 	return rootCmd.Execute()
 }
 
 func run() error {
 
 	// Read the configuration
-	config.Reset()
+	resetConfig()
 	err := config.ReadConfig(cfgFile)
 
 	// Setup logging after reading config (even if failed), to output header correctly
@@ -108,12 +109,19 @@ func run() error {
 		return i18n.WrapError(ctx, err, i18n.MsgConfigFailed)
 	}
 
+	// Check the config for unknown keys and invalid values
+	if err = validateConfig(ctx); err != nil {
+		cancelCtx()
+		return err
+	}
+
 	// Setup signal handling to cancel the context, which shuts down the API Server
 	errChan := make(chan error)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
 
 	for {
 		orchestratorCtx, cancelOrchestratorCtx := context.WithCancel(ctx)
+		config.WatchSecrets(orchestratorCtx, cancelOrchestratorCtx)
 		o := getOrchestrator()
 		as := apiserver.NewAPIServer()
 		go startFirefly(orchestratorCtx, cancelOrchestratorCtx, o, as, errChan)
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE contractlisteners DROP COLUMN checkpoint;
+
+COMMIT;
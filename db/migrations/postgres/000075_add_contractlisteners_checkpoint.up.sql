@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE contractlisteners ADD COLUMN checkpoint VARCHAR(256);
+UPDATE contractlisteners SET checkpoint = '';
+ALTER TABLE contractlisteners ALTER COLUMN checkpoint SET NOT NULL;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+CREATE TABLE messageapprovals (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  message_id       UUID            NOT NULL,
+  policy           VARCHAR(64)     NOT NULL,
+  approver         VARCHAR(1024)   NOT NULL,
+  comment          TEXT,
+  created          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX messageapprovals_id ON messageapprovals(id);
+CREATE UNIQUE INDEX messageapprovals_message_approver ON messageapprovals(message_id, approver);
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+CREATE TABLE legalholds (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  hold_type        VARCHAR(64)     NOT NULL,
+  ref              UUID            NOT NULL,
+  reason           TEXT,
+  created          BIGINT          NOT NULL,
+  released         BIGINT,
+  release_reason   TEXT
+);
+
+CREATE UNIQUE INDEX legalholds_id ON legalholds(id);
+CREATE INDEX legalholds_ref ON legalholds(namespace, ref);
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+ALTER TABLE messages ADD COLUMN labels VARCHAR(1024);
+ALTER TABLE data ADD COLUMN labels VARCHAR(1024);
+
+UPDATE messages SET labels = '';
+UPDATE data SET labels = '';
+
+ALTER TABLE messages ALTER COLUMN labels SET NOT NULL;
+ALTER TABLE data ALTER COLUMN labels SET NOT NULL;
+
+CREATE INDEX messages_labels ON messages(labels);
+CREATE INDEX data_labels ON data(labels);
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+CREATE TABLE savedqueries (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  name             VARCHAR(64)     NOT NULL,
+  description      TEXT,
+  collection       VARCHAR(64)     NOT NULL,
+  filter           TEXT            NOT NULL,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT
+);
+
+CREATE UNIQUE INDEX savedqueries_id ON savedqueries(id);
+CREATE UNIQUE INDEX savedqueries_name ON savedqueries(namespace, name);
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+CREATE TABLE reports (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  period           VARCHAR(64)     NOT NULL,
+  period_start     BIGINT          NOT NULL,
+  period_end       BIGINT          NOT NULL,
+  created          BIGINT          NOT NULL,
+  metrics          TEXT
+);
+
+CREATE UNIQUE INDEX reports_id ON reports(id);
+CREATE UNIQUE INDEX reports_period ON reports(namespace, period, period_start);
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE tokenapproval DROP COLUMN allowance;
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE tokenapproval ADD COLUMN allowance VARCHAR(65);
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+CREATE TABLE swaps (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  state            VARCHAR(64)     NOT NULL,
+  escrow           VARCHAR(1024)   NOT NULL,
+  initiator        TEXT            NOT NULL,
+  counterparty     TEXT            NOT NULL,
+  error            TEXT,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT,
+  expires          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX swaps_id ON swaps(id);
+CREATE INDEX swaps_state ON swaps(namespace, state);
+COMMIT;
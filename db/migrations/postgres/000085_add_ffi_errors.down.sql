@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE ffi DROP COLUMN errors;
+COMMIT;
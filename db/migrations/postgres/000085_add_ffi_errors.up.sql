@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TABLE ffi ADD COLUMN errors TEXT;
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+DROP TABLE IF EXISTS governancevotes;
+DROP TABLE IF EXISTS governanceproposals;
+COMMIT;
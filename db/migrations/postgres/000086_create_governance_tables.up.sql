@@ -0,0 +1,33 @@
+BEGIN;
+CREATE TABLE governanceproposals (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  ptype            VARCHAR(64)     NOT NULL,
+  description      TEXT,
+  change           TEXT,
+  author           VARCHAR(1024)   NOT NULL,
+  message_id       UUID,
+  state            VARCHAR(64)     NOT NULL,
+  approvals        INTEGER         NOT NULL,
+  rejections       INTEGER         NOT NULL,
+  created          BIGINT          NOT NULL,
+  decided          BIGINT
+);
+
+CREATE UNIQUE INDEX governanceproposals_id ON governanceproposals(id);
+CREATE INDEX governanceproposals_state ON governanceproposals(state);
+
+CREATE TABLE governancevotes (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  proposal_id      UUID            NOT NULL,
+  approve          BOOLEAN         NOT NULL,
+  comment          TEXT,
+  author           VARCHAR(1024)   NOT NULL,
+  message_id       UUID,
+  created          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX governancevotes_id ON governancevotes(id);
+CREATE UNIQUE INDEX governancevotes_author ON governancevotes(proposal_id, author);
+COMMIT;
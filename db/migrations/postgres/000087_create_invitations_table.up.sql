@@ -0,0 +1,18 @@
+BEGIN;
+CREATE TABLE invitations (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  description      TEXT,
+  inviter          VARCHAR(1024)   NOT NULL,
+  node_id          UUID,
+  peer             TEXT,
+  state            VARCHAR(64)     NOT NULL,
+  redeemed_by      VARCHAR(1024),
+  created          BIGINT          NOT NULL,
+  expires          BIGINT          NOT NULL,
+  redeemed         BIGINT,
+  secret           CHAR(64)        NOT NULL
+);
+
+CREATE UNIQUE INDEX invitations_id ON invitations(id);
+COMMIT;
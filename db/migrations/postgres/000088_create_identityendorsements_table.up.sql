@@ -0,0 +1,14 @@
+BEGIN;
+CREATE TABLE identityendorsements (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  identity_id      UUID            NOT NULL,
+  did              VARCHAR(1024)   NOT NULL,
+  author           VARCHAR(1024)   NOT NULL,
+  message_id       UUID,
+  created          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX identityendorsements_id ON identityendorsements(id);
+CREATE UNIQUE INDEX identityendorsements_author ON identityendorsements(identity_id, author);
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+CREATE TABLE messagetemplates (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  name             VARCHAR(64)     NOT NULL,
+  description      TEXT,
+  header           TEXT,
+  msg_group        TEXT,
+  data             TEXT,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT
+);
+
+CREATE UNIQUE INDEX messagetemplates_id ON messagetemplates(id);
+CREATE UNIQUE INDEX messagetemplates_name ON messagetemplates(namespace, name);
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE messages ADD COLUMN priority BIGINT;
+UPDATE messages SET priority = 0;
+ALTER TABLE messages ALTER COLUMN priority SET NOT NULL;
+
+COMMIT;
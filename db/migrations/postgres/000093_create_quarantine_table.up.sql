@@ -0,0 +1,14 @@
+BEGIN;
+CREATE TABLE quarantine (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  message_id       UUID            NOT NULL,
+  batch_id         UUID,
+  reason           TEXT,
+  created          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX quarantine_id ON quarantine(id);
+CREATE INDEX quarantine_message ON quarantine(namespace, message_id);
+COMMIT;
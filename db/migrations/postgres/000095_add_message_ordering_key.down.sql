@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE messages DROP COLUMN ordering_key;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE messages ADD COLUMN ordering_key VARCHAR(256);
+UPDATE messages SET ordering_key = '';
+
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+ALTER TABLE blobs ADD COLUMN threat VARCHAR(1024);
+UPDATE blobs SET threat = '';
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+ALTER TABLE events ADD COLUMN compacted BIGINT;
+UPDATE events SET compacted = 0;
+ALTER TABLE events ALTER COLUMN compacted SET NOT NULL;
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+CREATE TABLE messagestates (
+  seq              SERIAL          PRIMARY KEY,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  state            VARCHAR(64),
+  confirmed        BIGINT,
+  batch_id         UUID,
+  tx_id            UUID,
+  reply_count      BIGINT          NOT NULL,
+  updated          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX messagestates_id ON messagestates(id);
+CREATE INDEX messagestates_state ON messagestates(namespace, state);
+
+INSERT INTO messagestates (id, namespace, state, confirmed, batch_id, tx_id, reply_count, updated)
+  SELECT m.id, m.namespace, m.state, m.confirmed, m.batch_id, b.tx_id,
+    (SELECT COUNT(*) FROM messages r WHERE r.cid = m.id AND r.state = 'confirmed'),
+    m.confirmed
+  FROM messages m LEFT JOIN batches b ON b.id = m.batch_id
+  WHERE m.confirmed IS NOT NULL;
+COMMIT;
@@ -0,0 +1,3 @@
+BEGIN;
+CREATE INDEX events_topic_prefix ON events(topic varchar_pattern_ops);
+COMMIT;
@@ -0,0 +1,2 @@
+ALTER TABLE contractlisteners ADD COLUMN checkpoint VARCHAR(256);
+UPDATE contractlisteners SET checkpoint = '';
@@ -0,0 +1,13 @@
+CREATE TABLE messageapprovals (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  message_id       UUID            NOT NULL,
+  policy           VARCHAR(64)     NOT NULL,
+  approver         VARCHAR(1024)   NOT NULL,
+  comment          TEXT,
+  created          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX messageapprovals_id ON messageapprovals(id);
+CREATE UNIQUE INDEX messageapprovals_message_approver ON messageapprovals(message_id, approver);
@@ -0,0 +1,5 @@
+DROP INDEX messages_labels;
+DROP INDEX data_labels;
+
+ALTER TABLE messages DROP COLUMN labels;
+ALTER TABLE data DROP COLUMN labels;
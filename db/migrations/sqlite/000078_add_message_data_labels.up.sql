@@ -0,0 +1,8 @@
+ALTER TABLE messages ADD COLUMN labels VARCHAR(1024);
+ALTER TABLE data ADD COLUMN labels VARCHAR(1024);
+
+UPDATE messages SET labels = '';
+UPDATE data SET labels = '';
+
+CREATE INDEX messages_labels ON messages(labels);
+CREATE INDEX data_labels ON data(labels);
@@ -0,0 +1,14 @@
+CREATE TABLE savedqueries (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  name             VARCHAR(64)     NOT NULL,
+  description      TEXT,
+  collection       VARCHAR(64)     NOT NULL,
+  filter           TEXT            NOT NULL,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT
+);
+
+CREATE UNIQUE INDEX savedqueries_id ON savedqueries(id);
+CREATE UNIQUE INDEX savedqueries_name ON savedqueries(namespace, name);
@@ -0,0 +1,13 @@
+CREATE TABLE reports (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  period           VARCHAR(64)     NOT NULL,
+  period_start     BIGINT          NOT NULL,
+  period_end       BIGINT          NOT NULL,
+  created          BIGINT          NOT NULL,
+  metrics          TEXT
+);
+
+CREATE UNIQUE INDEX reports_id ON reports(id);
+CREATE UNIQUE INDEX reports_period ON reports(namespace, period, period_start);
@@ -0,0 +1 @@
+ALTER TABLE tokenapproval ADD COLUMN allowance VARCHAR(65);
@@ -0,0 +1,16 @@
+CREATE TABLE swaps (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  state            VARCHAR(64)     NOT NULL,
+  escrow           VARCHAR(1024)   NOT NULL,
+  initiator        TEXT            NOT NULL,
+  counterparty     TEXT            NOT NULL,
+  error            TEXT,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT,
+  expires          BIGINT          NOT NULL
+);
+
+CREATE UNIQUE INDEX swaps_id ON swaps(id);
+CREATE INDEX swaps_state ON swaps(namespace, state);
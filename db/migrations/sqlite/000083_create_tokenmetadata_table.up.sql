@@ -0,0 +1,14 @@
+CREATE TABLE tokenmetadata (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  pool_id          UUID            NOT NULL,
+  token_index      VARCHAR(1024)   NOT NULL,
+  data_id          UUID            NOT NULL,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT
+);
+
+CREATE UNIQUE INDEX tokenmetadata_id ON tokenmetadata(id);
+CREATE UNIQUE INDEX tokenmetadata_token ON tokenmetadata(pool_id, token_index);
+CREATE INDEX tokenmetadata_data ON tokenmetadata(data_id);
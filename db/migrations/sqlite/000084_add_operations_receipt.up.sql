@@ -0,0 +1 @@
+ALTER TABLE operations ADD COLUMN receipt TEXT;
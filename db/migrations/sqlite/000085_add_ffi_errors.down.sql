@@ -0,0 +1 @@
+ALTER TABLE ffi DROP COLUMN errors;
@@ -0,0 +1 @@
+ALTER TABLE ffi ADD COLUMN errors TEXT;
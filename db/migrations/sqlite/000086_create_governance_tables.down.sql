@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS governancevotes;
+DROP TABLE IF EXISTS governanceproposals;
@@ -0,0 +1,16 @@
+CREATE TABLE invitations (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  description      TEXT,
+  inviter          VARCHAR(1024)   NOT NULL,
+  node_id          UUID,
+  peer             TEXT,
+  state            VARCHAR(64)     NOT NULL,
+  redeemed_by      VARCHAR(1024),
+  created          BIGINT          NOT NULL,
+  expires          BIGINT          NOT NULL,
+  redeemed         BIGINT,
+  secret           CHAR(64)        NOT NULL
+);
+
+CREATE UNIQUE INDEX invitations_id ON invitations(id);
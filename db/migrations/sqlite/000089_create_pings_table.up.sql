@@ -0,0 +1,15 @@
+CREATE TABLE pings (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  target           VARCHAR(1024)   NOT NULL,
+  state            VARCHAR(64)     NOT NULL,
+  reply_id         UUID,
+  created          BIGINT          NOT NULL,
+  confirmed        BIGINT,
+  completed        BIGINT,
+  chain_latency    VARCHAR(64),
+  round_trip       VARCHAR(64)
+);
+
+CREATE UNIQUE INDEX pings_id ON pings(id);
+CREATE INDEX pings_target ON pings(target);
@@ -0,0 +1,15 @@
+CREATE TABLE messagetemplates (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  name             VARCHAR(64)     NOT NULL,
+  description      TEXT,
+  header           TEXT,
+  msg_group        TEXT,
+  data             TEXT,
+  created          BIGINT          NOT NULL,
+  updated          BIGINT
+);
+
+CREATE UNIQUE INDEX messagetemplates_id ON messagetemplates(id);
+CREATE UNIQUE INDEX messagetemplates_name ON messagetemplates(namespace, name);
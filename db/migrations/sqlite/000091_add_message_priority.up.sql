@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN priority BIGINT;
+UPDATE messages SET priority = 0;
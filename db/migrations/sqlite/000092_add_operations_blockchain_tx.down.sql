@@ -0,0 +1 @@
+ALTER TABLE operations DROP COLUMN blockchain_tx;
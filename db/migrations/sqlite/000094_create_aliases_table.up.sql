@@ -0,0 +1,14 @@
+CREATE TABLE aliases (
+  seq              INTEGER         PRIMARY KEY AUTOINCREMENT,
+  id               UUID            NOT NULL,
+  namespace        VARCHAR(64)     NOT NULL,
+  name             VARCHAR(64)     NOT NULL,
+  description      TEXT,
+  identity         VARCHAR(1024),
+  key              VARCHAR(1024),
+  created          BIGINT          NOT NULL,
+  updated          BIGINT
+);
+
+CREATE UNIQUE INDEX aliases_id ON aliases(id);
+CREATE UNIQUE INDEX aliases_name ON aliases(namespace, name);
@@ -0,0 +1,2 @@
+ALTER TABLE messages ADD COLUMN ordering_key VARCHAR(256);
+UPDATE messages SET ordering_key = '';
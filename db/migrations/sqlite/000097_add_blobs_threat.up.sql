@@ -0,0 +1,2 @@
+ALTER TABLE blobs ADD COLUMN threat VARCHAR(1024);
+UPDATE blobs SET threat = '';
@@ -0,0 +1,2 @@
+ALTER TABLE events ADD COLUMN compacted BIGINT;
+UPDATE events SET compacted = 0;
@@ -0,0 +1 @@
+CREATE INDEX events_topic_prefix ON events(topic COLLATE NOCASE);
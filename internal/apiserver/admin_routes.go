@@ -25,4 +25,42 @@ var adminRoutes = []*oapispec.Route{
 	postResetConfig,
 	putConfigRecord,
 	deleteConfigRecord,
+	getSubscriptions,
+	getSubscriptionByID,
+	postNewSubscription,
+	putSubscription,
+	deleteSubscription,
+	postSubscriptionDryRun,
+	getSubscriptionTap,
+	postSubscriptionRewind,
+	postContractListenerRewind,
+	getBatchPinMigration,
+	postBatchPinMigrationActivate,
+	getQuarantine,
+	getQuarantineByID,
+	postQuarantineReprocess,
+}
+
+// adminExclusiveRoutes are served on the main API as well as the admin listener by default,
+// but can be restricted to the admin listener only with admin.exclusive
+var adminExclusiveRoutes = map[*oapispec.Route]bool{
+	getSubscriptions:    true,
+	getSubscriptionByID: true,
+	postNewSubscription: true,
+	putSubscription:     true,
+	deleteSubscription:  true,
+}
+
+func (as *apiServer) apiRoutes() []*oapispec.Route {
+	filtered := make([]*oapispec.Route, 0, len(routes))
+	for _, route := range routes {
+		if !as.adminExclusive || !adminExclusiveRoutes[route] {
+			filtered = append(filtered, route)
+		}
+	}
+	return append(filtered, extensionRoutes(false)...)
+}
+
+func (as *apiServer) adminAPIRoutes() []*oapispec.Route {
+	return append(append([]*oapispec.Route{}, adminRoutes...), extensionRoutes(true)...)
 }
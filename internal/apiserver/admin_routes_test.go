@@ -0,0 +1,52 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAdminGetSubscriptions(t *testing.T) {
+	o, r := newTestAdminServer()
+	req := httptest.NewRequest("GET", "/admin/api/v1/namespaces/mynamespace/subscriptions", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetSubscriptions", mock.Anything, "mynamespace", mock.Anything).
+		Return([]*fftypes.Subscription{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestAdminExclusiveRemovesSubscriptionRoutes(t *testing.T) {
+	o, as := newTestServer()
+	as.adminExclusive = true
+	r := as.createMuxRouter(context.Background(), o)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/subscriptions", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 404, res.Result().StatusCode)
+	assert.Len(t, as.apiRoutes(), len(routes)-len(adminExclusiveRoutes))
+}
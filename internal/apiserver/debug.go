@@ -0,0 +1,74 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/orchestrator"
+)
+
+// addDebugRoutes exposes runtime diagnostics on the admin listener under /admin/debug, so that stalls
+// can be diagnosed in production. They are only added when the admin listener requires authentication.
+func (as *apiServer) addDebugRoutes(ctx context.Context, r *mux.Router, o orchestrator.Orchestrator) {
+	if !config.GetBool(config.AdminDebugEnabled) {
+		return
+	}
+	authType := strings.ToLower(adminConfigPrefix.GetString(HTTPConfAuthType))
+	if authType == "" || authType == authTypeNone {
+		log.L(ctx).Warnf("Admin debug endpoints are disabled, as the admin listener does not require authentication")
+		return
+	}
+
+	// The pprof handlers expect to be served under /debug/pprof/
+	r.PathPrefix("/admin/debug/pprof/cmdline").Handler(http.StripPrefix("/admin", http.HandlerFunc(pprof.Cmdline)))
+	r.PathPrefix("/admin/debug/pprof/profile").Handler(http.StripPrefix("/admin", http.HandlerFunc(pprof.Profile)))
+	r.PathPrefix("/admin/debug/pprof/symbol").Handler(http.StripPrefix("/admin", http.HandlerFunc(pprof.Symbol)))
+	r.PathPrefix("/admin/debug/pprof/trace").Handler(http.StripPrefix("/admin", http.HandlerFunc(pprof.Trace)))
+	r.PathPrefix("/admin/debug/pprof/").Handler(http.StripPrefix("/admin", http.HandlerFunc(pprof.Index)))
+	r.HandleFunc("/admin/debug/goroutines", as.apiWrapper(as.goroutinesHandler)).Methods(http.MethodGet)
+	r.HandleFunc("/admin/debug/queues", as.apiWrapper(as.queueDepthsHandler(o))).Methods(http.MethodGet)
+	log.L(ctx).Infof("Admin debug endpoints enabled")
+}
+
+// goroutinesHandler writes the stack of every goroutine, in the same format as an unrecovered panic
+func (as *apiServer) goroutinesHandler(res http.ResponseWriter, req *http.Request) (status int, err error) {
+	res.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	res.Header().Set("X-Goroutine-Count", strconv.Itoa(runtime.NumGoroutine()))
+	return 200, runtimepprof.Lookup("goroutine").WriteTo(res, 2)
+}
+
+func (as *apiServer) queueDepthsHandler(o orchestrator.Orchestrator) func(res http.ResponseWriter, req *http.Request) (status int, err error) {
+	return func(res http.ResponseWriter, req *http.Request) (status int, err error) {
+		depths, err := o.GetQueueDepths(req.Context())
+		if err != nil {
+			return 500, err
+		}
+		res.Header().Set("Content-Type", "application/json")
+		return 200, json.NewEncoder(res).Encode(depths)
+	}
+}
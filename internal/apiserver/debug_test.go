@@ -0,0 +1,106 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/orchestratormocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestDebugServer(authType string) (*orchestratormocks.Orchestrator, *mux.Router) {
+	config.Reset()
+	InitConfig()
+	config.Set(config.AdminDebugEnabled, true)
+	adminConfigPrefix.Set(HTTPConfAuthType, authType)
+	return newTestAdminServer()
+}
+
+func TestDebugDisabled(t *testing.T) {
+	config.Reset()
+	_, r := newTestAdminServer()
+	req := httptest.NewRequest("GET", "/admin/debug/queues", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 404, res.Result().StatusCode)
+}
+
+func TestDebugDisabledNoAuth(t *testing.T) {
+	_, r := newTestDebugServer("none")
+	defer config.Reset()
+	req := httptest.NewRequest("GET", "/admin/debug/queues", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 404, res.Result().StatusCode)
+}
+
+func TestDebugQueues(t *testing.T) {
+	o, r := newTestDebugServer("basic")
+	defer config.Reset()
+	o.On("GetQueueDepths", mock.Anything).Return(&fftypes.QueueDepths{
+		BatchProcessors: []*fftypes.BatchQueueDepth{{Name: "proc1", Queued: 3}},
+	}, nil)
+	req := httptest.NewRequest("GET", "/admin/debug/queues", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var depths fftypes.QueueDepths
+	err := json.NewDecoder(res.Body).Decode(&depths)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, depths.BatchProcessors[0].Queued)
+}
+
+func TestDebugQueuesFail(t *testing.T) {
+	o, r := newTestDebugServer("bearer")
+	defer config.Reset()
+	o.On("GetQueueDepths", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	req := httptest.NewRequest("GET", "/admin/debug/queues", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 500, res.Result().StatusCode)
+	assert.Regexp(t, "pop", res.Body.String())
+}
+
+func TestDebugGoroutines(t *testing.T) {
+	_, r := newTestDebugServer("basic")
+	defer config.Reset()
+	req := httptest.NewRequest("GET", "/admin/debug/goroutines", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.NotEmpty(t, res.Result().Header.Get("X-Goroutine-Count"))
+	assert.Contains(t, res.Body.String(), "TestDebugGoroutines")
+}
+
+func TestDebugPprof(t *testing.T) {
+	_, r := newTestDebugServer("basic")
+	defer config.Reset()
+	for _, path := range []string{"/admin/debug/pprof/", "/admin/debug/pprof/goroutine?debug=1", "/admin/debug/pprof/cmdline", "/admin/debug/pprof/symbol"} {
+		req := httptest.NewRequest("GET", path, nil)
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		assert.Equal(t, 200, res.Result().StatusCode, path)
+	}
+}
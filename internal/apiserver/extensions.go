@@ -0,0 +1,86 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/oapispec"
+)
+
+// RouteBundle is a set of routes added to the API by an extension package. Extensions are compiled into
+// the binary, and register their bundle from an init function. The handlers of the routes are called with
+// the orchestrator, in the same way as the built-in routes.
+type RouteBundle struct {
+	// Name identifies the extension
+	Name string
+	// Routes are served on the main API, and included in its Swagger definition
+	Routes []*oapispec.Route
+	// AdminRoutes are served on the admin API, if it is enabled
+	AdminRoutes []*oapispec.Route
+}
+
+var (
+	routeBundles   []*RouteBundle
+	routeBundleMux sync.Mutex
+)
+
+// RegisterRouteBundle adds the routes of an extension to the API server. It must be called before the server
+// starts, and panics if the bundle, or any of its routes, has a name that is already in use. Built-in routes
+// take precedence over extension routes that match the same path.
+func RegisterRouteBundle(bundle *RouteBundle) {
+	routeBundleMux.Lock()
+	defer routeBundleMux.Unlock()
+	names := make(map[string]bool)
+	inUse := [][]*oapispec.Route{routes, adminRoutes}
+	for _, existing := range routeBundles {
+		if existing.Name == bundle.Name {
+			panic(fmt.Sprintf("Route bundle %s already registered", bundle.Name))
+		}
+		inUse = append(inUse, existing.Routes, existing.AdminRoutes)
+	}
+	for _, list := range inUse {
+		for _, route := range list {
+			names[route.Name] = true
+		}
+	}
+	for _, list := range [][]*oapispec.Route{bundle.Routes, bundle.AdminRoutes} {
+		for _, route := range list {
+			if names[route.Name] {
+				panic(fmt.Sprintf("Route %s in bundle %s re-used", route.Name, bundle.Name))
+			}
+			names[route.Name] = true
+		}
+	}
+	routeBundles = append(routeBundles, bundle)
+}
+
+// extensionRoutes returns the routes of all registered bundles, for the main or admin API
+func extensionRoutes(admin bool) []*oapispec.Route {
+	routeBundleMux.Lock()
+	defer routeBundleMux.Unlock()
+	var extRoutes []*oapispec.Route
+	for _, bundle := range routeBundles {
+		if admin {
+			extRoutes = append(extRoutes, bundle.AdminRoutes...)
+		} else {
+			extRoutes = append(extRoutes, bundle.Routes...)
+		}
+	}
+	return extRoutes
+}
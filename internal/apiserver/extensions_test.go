@@ -0,0 +1,154 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/oapispec"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRouteBundle() *RouteBundle {
+	return &RouteBundle{
+		Name: "ut",
+		Routes: []*oapispec.Route{
+			{
+				Name:            "getUTStatus",
+				Path:            "namespaces/{ns}/ut/status",
+				Method:          http.MethodGet,
+				PathParams:      []*oapispec.PathParam{{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD}},
+				Description:     i18n.MsgTBD,
+				JSONOutputValue: func() interface{} { return &fftypes.JSONObject{} },
+				JSONOutputCodes: []int{http.StatusOK},
+				JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+					return fftypes.JSONObject{"preInit": r.Or.IsPreInit(), "ns": r.PP["ns"]}, nil
+				},
+			},
+		},
+		AdminRoutes: []*oapispec.Route{
+			{
+				Name:            "postUTReset",
+				Path:            "ut/reset",
+				Method:          http.MethodPost,
+				Description:     i18n.MsgTBD,
+				JSONInputValue:  func() interface{} { return &fftypes.JSONObject{} },
+				JSONOutputValue: func() interface{} { return &fftypes.JSONObject{} },
+				JSONOutputCodes: []int{http.StatusOK},
+				JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+					return r.Input, nil
+				},
+			},
+		},
+	}
+}
+
+func withTestRouteBundle() func() {
+	RegisterRouteBundle(testRouteBundle())
+	return func() {
+		routeBundles = nil
+	}
+}
+
+func TestRouteBundleServed(t *testing.T) {
+	defer withTestRouteBundle()()
+
+	o, r := newTestAPIServer()
+	o.On("IsPreInit").Return(false)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/ut/status", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var output fftypes.JSONObject
+	err := json.NewDecoder(res.Body).Decode(&output)
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", output.GetString("ns"))
+
+	req = httptest.NewRequest("GET", "/api/swagger.json", nil)
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Contains(t, res.Body.String(), "getUTStatus")
+	assert.NotContains(t, res.Body.String(), "postUTReset")
+}
+
+func TestRouteBundleAdminServed(t *testing.T) {
+	defer withTestRouteBundle()()
+
+	_, r := newTestAdminServer()
+	req := httptest.NewRequest("POST", "/admin/api/v1/ut/reset", bytes.NewReader([]byte(`{"all":true}`)))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.JSONEq(t, `{"all":true}`, res.Body.String())
+
+	req = httptest.NewRequest("GET", "/admin/api/swagger.json", nil)
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Contains(t, res.Body.String(), "postUTReset")
+	assert.NotContains(t, res.Body.String(), "getUTStatus")
+
+	// Extension routes on the main API are not served on the admin API
+	req = httptest.NewRequest("GET", "/admin/api/v1/namespaces/ns1/ut/status", nil)
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 404, res.Result().StatusCode)
+}
+
+func TestRouteBundleAdminExclusive(t *testing.T) {
+	defer withTestRouteBundle()()
+
+	o, as := newTestServer()
+	as.adminExclusive = true
+	as.createMuxRouter(context.Background(), o)
+	assert.Len(t, as.apiRoutes(), len(routes)-len(adminExclusiveRoutes)+1)
+}
+
+func TestRegisterRouteBundleDuplicateName(t *testing.T) {
+	defer withTestRouteBundle()()
+
+	assert.PanicsWithValue(t, "Route bundle ut already registered", func() {
+		RegisterRouteBundle(&RouteBundle{Name: "ut"})
+	})
+}
+
+func TestRegisterRouteBundleDuplicateRoute(t *testing.T) {
+	defer withTestRouteBundle()()
+
+	assert.PanicsWithValue(t, "Route getStatus in bundle ut2 re-used", func() {
+		RegisterRouteBundle(&RouteBundle{
+			Name:   "ut2",
+			Routes: []*oapispec.Route{{Name: "getStatus"}},
+		})
+	})
+	assert.PanicsWithValue(t, "Route postUTReset in bundle ut2 re-used", func() {
+		RegisterRouteBundle(&RouteBundle{
+			Name:        "ut2",
+			AdminRoutes: []*oapispec.Route{{Name: "postUTReset"}},
+		})
+	})
+	assert.Len(t, routeBundles, 1)
+}
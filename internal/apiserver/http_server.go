@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -31,6 +32,8 @@ import (
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 const (
@@ -40,6 +43,8 @@ const (
 	HTTPConfPublicURL = "publicURL"
 	// HTTPConfPort the local port to listen on for HTTP/Websocket connections
 	HTTPConfPort = "port"
+	// HTTPConfSocketPath the path of a Unix domain socket to listen on, instead of the TCP address/port
+	HTTPConfSocketPath = "socketPath"
 	// HTTPConfReadTimeout the write timeout for the HTTP server
 	HTTPConfReadTimeout = "readTimeout"
 	// HTTPConfWriteTimeout the write timeout for the HTTP server
@@ -54,6 +59,24 @@ const (
 	HTTPConfTLSEnabled = "tls.enabled"
 	// HTTPConfTLSKeyFile the private key file for TLS on the server
 	HTTPConfTLSKeyFile = "tls.keyFile"
+	// HTTPConfCompressionEnabled whether GET responses are compressed with gzip/deflate, when the client accepts it
+	HTTPConfCompressionEnabled = "compression.enabled"
+	// HTTPConfCompressionMinSize the minimum size of a response body before it is compressed
+	HTTPConfCompressionMinSize = "compression.minSize"
+	// HTTPConfCompressionLevel the compression level 1 (fastest) to 9 (best), or -1 for the default
+	HTTPConfCompressionLevel = "compression.level"
+	// HTTPConfHTTP2Enabled whether HTTP/2 is negotiated (via ALPN) on TLS connections
+	HTTPConfHTTP2Enabled = "http2.enabled"
+	// HTTPConfAuthType the authentication policy for the listener: none, basic or bearer
+	HTTPConfAuthType = "auth.type"
+	// HTTPConfAuthBasicUsername the username required when basic authentication is enabled
+	HTTPConfAuthBasicUsername = "auth.basic.username"
+	// HTTPConfAuthBasicPassword the password required when basic authentication is enabled
+	HTTPConfAuthBasicPassword = "auth.basic.password"
+	// HTTPConfAuthBearerTokens the list of accepted tokens when bearer authentication is enabled
+	HTTPConfAuthBearerTokens = "auth.bearer.tokens"
+	// HTTPConfHTTP2Cleartext whether HTTP/2 without TLS (h2c) is accepted, for use inside service meshes that terminate TLS
+	HTTPConfHTTP2Cleartext = "http2.h2c"
 )
 
 type IServer interface {
@@ -79,6 +102,7 @@ func initHTTPConfPrefx(prefix config.Prefix, defaultPort int) {
 	prefix.AddKnownKey(HTTPConfAddress, "127.0.0.1")
 	prefix.AddKnownKey(HTTPConfPublicURL)
 	prefix.AddKnownKey(HTTPConfPort, defaultPort)
+	prefix.AddKnownKey(HTTPConfSocketPath)
 	prefix.AddKnownKey(HTTPConfReadTimeout, "15s")
 	prefix.AddKnownKey(HTTPConfWriteTimeout, "15s")
 	prefix.AddKnownKey(HTTPConfTLSCAFile)
@@ -86,6 +110,15 @@ func initHTTPConfPrefx(prefix config.Prefix, defaultPort int) {
 	prefix.AddKnownKey(HTTPConfTLSClientAuth)
 	prefix.AddKnownKey(HTTPConfTLSEnabled, false)
 	prefix.AddKnownKey(HTTPConfTLSKeyFile)
+	prefix.AddKnownKey(HTTPConfCompressionEnabled, false)
+	prefix.AddKnownKey(HTTPConfCompressionMinSize, "1Kb")
+	prefix.AddKnownKey(HTTPConfCompressionLevel, -1)
+	prefix.AddKnownKey(HTTPConfHTTP2Enabled, true)
+	prefix.AddKnownKey(HTTPConfHTTP2Cleartext, false)
+	prefix.AddKnownKey(HTTPConfAuthType, authTypeNone)
+	prefix.AddKnownKey(HTTPConfAuthBasicUsername)
+	prefix.AddKnownKey(HTTPConfAuthBasicPassword)
+	prefix.AddKnownKey(HTTPConfAuthBearerTokens)
 }
 
 func newHTTPServer(ctx context.Context, name string, r *mux.Router, onClose chan error, conf config.Prefix) (hs *httpServer, err error) {
@@ -106,6 +139,10 @@ func newHTTPServer(ctx context.Context, name string, r *mux.Router, onClose chan
 }
 
 func (hs *httpServer) createListener(ctx context.Context) (net.Listener, error) {
+	socketPath := hs.conf.GetString(HTTPConfSocketPath)
+	if socketPath != "" {
+		return hs.createSocketListener(ctx, socketPath)
+	}
 	listenAddr := fmt.Sprintf("%s:%d", hs.conf.GetString(HTTPConfAddress), hs.conf.GetUint(HTTPConfPort))
 	listener, err := net.Listen("tcp", listenAddr)
 	if err != nil {
@@ -115,6 +152,19 @@ func (hs *httpServer) createListener(ctx context.Context) (net.Listener, error)
 	return listener, err
 }
 
+func (hs *httpServer) createSocketListener(ctx context.Context, socketPath string) (net.Listener, error) {
+	// Clean up any socket left behind by a previous process that did not shut down cleanly
+	if fi, err := os.Stat(socketPath); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(socketPath)
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgAPIServerStartFailed, socketPath)
+	}
+	log.L(ctx).Infof("%s listening on Unix socket %s", hs.name, socketPath)
+	return listener, err
+}
+
 func (hs *httpServer) createServer(ctx context.Context, r *mux.Router) (srv IServer, err error) {
 
 	// Support client auth
@@ -144,8 +194,19 @@ func (hs *httpServer) createServer(ctx context.Context, r *mux.Router) (srv ISer
 		return nil, i18n.WrapError(ctx, err, i18n.MsgTLSConfigFailed)
 	}
 
-	srv = &http.Server{
-		Handler:      wrapCorsIfEnabled(ctx, r),
+	handler, err := wrapAuthIfEnabled(ctx, hs.name, hs.conf, r)
+	if err != nil {
+		return nil, err
+	}
+	handler = wrapCompressionIfEnabled(ctx, hs.conf, wrapCorsIfEnabled(ctx, handler))
+	http2Enabled := hs.conf.GetBool(HTTPConfHTTP2Enabled)
+	if http2Enabled && !hs.tlsEnabled && hs.conf.GetBool(HTTPConfHTTP2Cleartext) {
+		log.L(ctx).Infof("%s accepting HTTP/2 cleartext (h2c) connections", hs.name)
+		handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+
+	httpServer := &http.Server{
+		Handler:      handler,
 		WriteTimeout: hs.conf.GetDuration(HTTPConfWriteTimeout),
 		ReadTimeout:  hs.conf.GetDuration(HTTPConfReadTimeout),
 		TLSConfig: &tls.Config{
@@ -166,7 +227,11 @@ func (hs *httpServer) createServer(ctx context.Context, r *mux.Router) (srv ISer
 			return newCtx
 		},
 	}
-	return srv, nil
+	if !http2Enabled {
+		// A non-nil empty map disables the automatic HTTP/2 upgrade on TLS connections
+		httpServer.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+	return httpServer, nil
 }
 
 func (hs *httpServer) serveHTTP(ctx context.Context) {
@@ -32,6 +32,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -40,6 +41,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/apiservermocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/net/http2"
 )
 
 func TestInvalidListener(t *testing.T) {
@@ -197,3 +199,112 @@ func TestTLSServerSelfSignedWithClientAuth(t *testing.T) {
 	err = <-errChan
 	assert.NoError(t, err)
 }
+
+func TestHTTP2CleartextServer(t *testing.T) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfHTTP2Cleartext, true)
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	r := mux.NewRouter()
+	r.HandleFunc("/test", func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(200)
+		json.NewEncoder(res).Encode(map[string]interface{}{"proto": req.Proto})
+	})
+	errChan := make(chan error)
+	hs, err := newHTTPServer(context.Background(), "ut", r, errChan, cp)
+	assert.NoError(t, err)
+	go hs.serveHTTP(ctx)
+
+	c := http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+	res, err := c.Get(fmt.Sprintf("http://%s/test", hs.l.Addr().String()))
+	assert.NoError(t, err)
+	if res != nil {
+		assert.Equal(t, 200, res.StatusCode)
+		var resBody map[string]interface{}
+		json.NewDecoder(res.Body).Decode(&resBody)
+		assert.Equal(t, "HTTP/2.0", resBody["proto"])
+	}
+
+	cancelCtx()
+	err = <-errChan
+	assert.NoError(t, err)
+}
+
+func TestHTTP2Disabled(t *testing.T) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfHTTP2Enabled, false)
+	hs, err := newHTTPServer(context.Background(), "ut", mux.NewRouter(), make(chan error), cp)
+	assert.NoError(t, err)
+	defer hs.l.Close()
+	assert.NotNil(t, hs.s.(*http.Server).TLSNextProto)
+	assert.Empty(t, hs.s.(*http.Server).TLSNextProto)
+}
+
+func TestUnixSocketServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ffsock")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "firefly.sock")
+
+	// Leave a stale socket behind, which should be cleaned up
+	stale, err := net.Listen("unix", socketPath)
+	assert.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfSocketPath, socketPath)
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	r := mux.NewRouter()
+	r.HandleFunc("/test", func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(200)
+		json.NewEncoder(res).Encode(map[string]interface{}{"hello": "socket"})
+	})
+	errChan := make(chan error)
+	hs, err := newHTTPServer(context.Background(), "ut", r, errChan, cp)
+	assert.NoError(t, err)
+	go hs.serveHTTP(ctx)
+
+	c := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return net.Dial("unix", socketPath)
+			},
+		},
+	}
+	res, err := c.Get("http://localhost/test")
+	assert.NoError(t, err)
+	if res != nil {
+		assert.Equal(t, 200, res.StatusCode)
+		var resBody map[string]interface{}
+		json.NewDecoder(res.Body).Decode(&resBody)
+		assert.Equal(t, "socket", resBody["hello"])
+	}
+
+	cancelCtx()
+	err = <-errChan
+	assert.NoError(t, err)
+	_, err = os.Stat(socketPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUnixSocketServerBadPath(t *testing.T) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfSocketPath, "/does/not/exist/firefly.sock")
+	_, err := newHTTPServer(context.Background(), "ut", mux.NewRouter(), make(chan error), cp)
+	assert.Regexp(t, "FF10104", err)
+}
@@ -28,7 +28,9 @@ import (
 
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/orchestrator"
 	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
 type filterResultsWithCount struct {
@@ -37,6 +39,15 @@ type filterResultsWithCount struct {
 	Items interface{} `json:"items"`
 }
 
+// aliasKeyFields are the filter fields that hold signing keys, so references to aliases in the
+// address book resolve to the key of the alias. All other fields resolve to the identity.
+var aliasKeyFields = map[string]bool{
+	"from":   true,
+	"key":    true,
+	"signer": true,
+	"to":     true,
+}
+
 type filterModifiers struct {
 	negate          bool
 	caseInsensitive bool
@@ -62,6 +73,15 @@ func filterResult(items interface{}, res *database.FilterResult, err error) (int
 	}, nil
 }
 
+// keyValues converts a list of keys for use in an "in" filter condition
+func keyValues(keys []string) []driver.Value {
+	values := make([]driver.Value, len(keys))
+	for i, k := range keys {
+		values[i] = k
+	}
+	return values
+}
+
 func (as *apiServer) getValues(values url.Values, key string) (results []string) {
 	for queryName, queryValues := range values {
 		// We choose to be case insensitive for our filters, so protocolID and protocolid can be used interchangeably
@@ -73,15 +93,55 @@ func (as *apiServer) getValues(values url.Values, key string) (results []string)
 }
 
 func (as *apiServer) buildFilter(req *http.Request, ff database.QueryFactory) (database.AndFilter, error) {
-	ctx := req.Context()
-	log.L(ctx).Debugf("Query: %s", req.URL.RawQuery)
+	log.L(req.Context()).Debugf("Query: %s", req.URL.RawQuery)
+	_ = req.ParseForm()
+	return as.buildFilterFromValues(req.Context(), req.Form, ff)
+}
+
+// resolveFilterAliases replaces any references to aliases in the address book in the query values,
+// such as "author=alias:partner", with the identity or key that the alias maps to
+func (as *apiServer) resolveFilterAliases(ctx context.Context, o orchestrator.Orchestrator, ns string, form url.Values) error {
+	if ns == "" {
+		return nil
+	}
+	for field, values := range form {
+		for i, value := range values {
+			// The alias reference can follow the operators and modifiers of the filter syntax
+			idx := strings.Index(value, fftypes.AliasPrefix)
+			if idx < 0 || strings.Trim(value[:idx], "!:?<>=@^$") != "" {
+				continue
+			}
+			name := value[idx+len(fftypes.AliasPrefix):]
+			alias, err := o.GetAliasByName(ctx, ns, name)
+			if err != nil {
+				return err
+			}
+			if alias == nil {
+				return i18n.NewError(ctx, i18n.MsgAliasNotFound, name)
+			}
+			target := alias.Identity
+			if aliasKeyFields[strings.ToLower(field)] {
+				if alias.Key == "" {
+					return i18n.NewError(ctx, i18n.MsgAliasKeyMissing, name)
+				}
+				target = alias.Key
+			} else if target == "" {
+				return i18n.NewError(ctx, i18n.MsgAliasIdentityMissing, name)
+			}
+			values[i] = value[:idx] + target
+		}
+	}
+	return nil
+}
+
+// buildFilterFromValues builds a filter from a set of query values, using the REST query syntax
+func (as *apiServer) buildFilterFromValues(ctx context.Context, form url.Values, ff database.QueryFactory) (database.AndFilter, error) {
 	fb := ff.NewFilterLimit(ctx, as.defaultFilterLimit)
 	possibleFields := fb.Fields()
 	sort.Strings(possibleFields)
 	filter := fb.And()
-	_ = req.ParseForm()
 	for _, field := range possibleFields {
-		values := as.getValues(req.Form, field)
+		values := as.getValues(form, field)
 		if len(values) == 1 {
 			cond, err := as.getCondition(ctx, fb, field, values[0])
 			if err != nil {
@@ -101,23 +161,23 @@ func (as *apiServer) buildFilter(req *http.Request, ff database.QueryFactory) (d
 			filter.Condition(fb.Or(fs...))
 		}
 	}
-	skipVals := as.getValues(req.Form, "skip")
+	skipVals := as.getValues(form, "skip")
 	if len(skipVals) > 0 {
 		s, _ := strconv.ParseUint(skipVals[0], 10, 64)
 		if as.maxFilterSkip != 0 && s > as.maxFilterSkip {
-			return nil, i18n.NewError(req.Context(), i18n.MsgMaxFilterSkip, as.maxFilterSkip)
+			return nil, i18n.NewError(ctx, i18n.MsgMaxFilterSkip, as.maxFilterSkip)
 		}
 		filter.Skip(s)
 	}
-	limitVals := as.getValues(req.Form, "limit")
+	limitVals := as.getValues(form, "limit")
 	if len(limitVals) > 0 {
 		l, _ := strconv.ParseUint(limitVals[0], 10, 64)
 		if as.maxFilterLimit != 0 && l > as.maxFilterLimit {
-			return nil, i18n.NewError(req.Context(), i18n.MsgMaxFilterLimit, as.maxFilterLimit)
+			return nil, i18n.NewError(ctx, i18n.MsgMaxFilterLimit, as.maxFilterLimit)
 		}
 		filter.Limit(l)
 	}
-	sortVals := as.getValues(req.Form, "sort")
+	sortVals := as.getValues(form, "sort")
 	for _, sv := range sortVals {
 		subSortVals := strings.Split(sv, ",")
 		for _, ssv := range subSortVals {
@@ -127,14 +187,14 @@ func (as *apiServer) buildFilter(req *http.Request, ff database.QueryFactory) (d
 			}
 		}
 	}
-	descendingVals := as.getValues(req.Form, "descending")
-	ascendingVals := as.getValues(req.Form, "ascending")
+	descendingVals := as.getValues(form, "descending")
+	ascendingVals := as.getValues(form, "ascending")
 	if len(descendingVals) > 0 && (descendingVals[0] == "" || strings.EqualFold(descendingVals[0], "true")) {
 		filter.Descending()
 	} else if len(ascendingVals) > 0 && (ascendingVals[0] == "" || strings.EqualFold(ascendingVals[0], "true")) {
 		filter.Ascending()
 	}
-	countVals := as.getValues(req.Form, "count")
+	countVals := as.getValues(form, "count")
 	filter.Count(len(countVals) > 0 && (countVals[0] == "" || strings.EqualFold(countVals[0], "true")))
 	return filter, nil
 }
@@ -176,7 +236,7 @@ opFinder:
 				// Detected ">>" or "<<" full operators
 				break opFinder
 			}
-		case '=', '@', '^', '$':
+		case '=', '@', '^', '$', '~':
 			// Always terminates the opFinder
 			// Could be ">=" or "<=" (due to above logic continuing on '>' or '<' first char)
 			operator = append(operator, r)
@@ -206,6 +266,8 @@ func (as *apiServer) mapOperation(ctx context.Context, fb database.FilterBuilder
 		return as.checkNoMods(ctx, mods, field, op, fb.Gt(field, matchString))
 	case "<", "<<":
 		return as.checkNoMods(ctx, mods, field, op, fb.Lt(field, matchString))
+	case "~":
+		return as.checkNoMods(ctx, mods, field, op, fb.TopicMatch(field, matchString))
 	case "@":
 		if mods.caseInsensitive {
 			if mods.negate {
@@ -17,12 +17,17 @@
 package apiserver
 
 import (
+	"context"
 	"fmt"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
+	"github.com/hyperledger/firefly/mocks/orchestratormocks"
 	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestBuildFilterDescending(t *testing.T) {
@@ -87,6 +92,7 @@ func TestBuildFilterEachCombo(t *testing.T) {
 	testIndividualFilter(t, "tag=!$cat", "( tag !$ 'cat' )")
 	testIndividualFilter(t, "tag=:$cat", "( tag :$ 'cat' )")
 	testIndividualFilter(t, "tag=!:$cat", "( tag ;$ 'cat' )")
+	testIndividualFilter(t, "tag=~cat.*", "( tag ~= 'cat.*' )")
 	testIndividualFilter(t, "tag==", "( tag == '' )")
 	testIndividualFilter(t, "tag=!=", "( tag != '' )")
 	testIndividualFilter(t, "tag=:!=", "( tag ;= '' )")
@@ -112,6 +118,7 @@ func TestCheckNoMods(t *testing.T) {
 	testFailFilter(t, "tag=!<test", "FF10322")
 	testFailFilter(t, "tag=!:<=test", "FF10322")
 	testFailFilter(t, "tag=<=test&tag=!<=test", "FF10322")
+	testFailFilter(t, "tag=!~test", "FF10322")
 }
 
 func TestBuildFilterAscending(t *testing.T) {
@@ -147,3 +154,77 @@ func TestBuildFilterLimitLimit(t *testing.T) {
 	_, err := as.buildFilter(req, database.MessageQueryFactory)
 	assert.Regexp(t, "FF10184.*500", err)
 }
+
+func TestGetMessagesFilterAlias(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/messages?author=alias:partner&key=!alias:partner", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetAliasByName", mock.Anything, "ns1", "partner").Return(&fftypes.Alias{
+		Name:     "partner",
+		Identity: "did:firefly:org/org1",
+		Key:      "0x12345",
+	}, nil)
+	o.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(filter database.AndFilter) bool {
+		fi, _ := filter.Finalize()
+		return fi.String() == "( author == 'did:firefly:org/org1' ) && ( key != '0x12345' )"
+	})).Return([]*fftypes.Message{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	o.AssertExpectations(t)
+}
+
+func TestGetMessagesFilterAliasNotFound(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/messages?author=alias:partner", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetAliasByName", mock.Anything, "ns1", "partner").Return(nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 404, res.Result().StatusCode)
+	assert.Regexp(t, "FF10523", res.Body.String())
+}
+
+func TestResolveFilterAliasesNoNamespace(t *testing.T) {
+	as := &apiServer{}
+	form := url.Values{"author": []string{"alias:partner"}}
+	err := as.resolveFilterAliases(context.Background(), &orchestratormocks.Orchestrator{}, "", form)
+	assert.NoError(t, err)
+	assert.Equal(t, "alias:partner", form.Get("author"))
+}
+
+func TestResolveFilterAliasesNotPrefix(t *testing.T) {
+	as := &apiServer{}
+	form := url.Values{"tag": []string{"@my alias:partner"}}
+	err := as.resolveFilterAliases(context.Background(), &orchestratormocks.Orchestrator{}, "ns1", form)
+	assert.NoError(t, err)
+	assert.Equal(t, "@my alias:partner", form.Get("tag"))
+}
+
+func TestResolveFilterAliasesLookupFail(t *testing.T) {
+	as := &apiServer{}
+	mor := &orchestratormocks.Orchestrator{}
+	mor.On("GetAliasByName", mock.Anything, "ns1", "partner").Return(nil, fmt.Errorf("pop"))
+	err := as.resolveFilterAliases(context.Background(), mor, "ns1", url.Values{"author": []string{"alias:partner"}})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResolveFilterAliasesNoKey(t *testing.T) {
+	as := &apiServer{}
+	mor := &orchestratormocks.Orchestrator{}
+	mor.On("GetAliasByName", mock.Anything, "ns1", "partner").Return(&fftypes.Alias{Identity: "did:firefly:org/org1"}, nil)
+	err := as.resolveFilterAliases(context.Background(), mor, "ns1", url.Values{"signer": []string{"alias:partner"}})
+	assert.Regexp(t, "FF10524", err)
+}
+
+func TestResolveFilterAliasesNoIdentity(t *testing.T) {
+	as := &apiServer{}
+	mor := &orchestratormocks.Orchestrator{}
+	mor.On("GetAliasByName", mock.Anything, "ns1", "partner").Return(&fftypes.Alias{Key: "0x12345"}, nil)
+	err := as.resolveFilterAliases(context.Background(), mor, "ns1", url.Values{"author": []string{"alias:partner"}})
+	assert.Regexp(t, "FF10525", err)
+}
@@ -0,0 +1,77 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/oapispec"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var getSubscriptionTap = &oapispec.Route{
+	Name:   "getSubscriptionTap",
+	Path:   "namespaces/{ns}/subscriptions/{subid}/tap",
+	Method: http.MethodGet,
+	PathParams: []*oapispec.PathParam{
+		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
+		{Name: "subid", Description: i18n.MsgTBD},
+	},
+	QueryParams: []*oapispec.QueryParam{
+		{Name: "events", Example: "10", Description: i18n.MsgTBD},
+	},
+	FilterFactory:   nil,
+	Description:     i18n.MsgTBD,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []*fftypes.SubscriptionTapRecord{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		eventCount := config.GetInt(config.SubscriptionTapMaxEvents)
+		if r.QP["events"] != "" {
+			if eventCount, err = strconv.Atoi(r.QP["events"]); err != nil {
+				return nil, i18n.NewError(r.Ctx, i18n.MsgInvalidChartNumberParam, "events")
+			}
+		}
+		records, err := getOr(r.Ctx).TapSubscription(r.Ctx, r.PP["ns"], r.PP["subid"], eventCount)
+		if err != nil {
+			return nil, err
+		}
+		return newTapStream(records), nil
+	},
+}
+
+// newTapStream streams the records from a subscription tap as newline delimited JSON.
+// The request context ends the tap when the client disconnects, which closes the records channel.
+func newTapStream(records <-chan *fftypes.SubscriptionTapRecord) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		enc := json.NewEncoder(pw)
+		var err error
+		for rec := range records {
+			if err == nil {
+				err = enc.Encode(rec)
+			}
+		}
+		pw.Close()
+	}()
+	return pr
+}
@@ -0,0 +1,96 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetSubscriptionTap(t *testing.T) {
+	o, r := newTestAdminServer()
+	req := httptest.NewRequest("GET", "/admin/api/v1/namespaces/mynamespace/subscriptions/abcd12345/tap?events=2", nil)
+	res := httptest.NewRecorder()
+
+	records := make(chan *fftypes.SubscriptionTapRecord, 2)
+	records <- &fftypes.SubscriptionTapRecord{Decision: fftypes.TapDecisionMatched}
+	records <- &fftypes.SubscriptionTapRecord{Decision: fftypes.TapDecisionAcked}
+	close(records)
+	o.On("TapSubscription", mock.Anything, "mynamespace", "abcd12345", 2).
+		Return((<-chan *fftypes.SubscriptionTapRecord)(records), nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.True(t, res.Flushed)
+	assert.Regexp(t, "(?s)\"decision\":\"matched\".*\n.*\"decision\":\"acked\"", res.Body.String())
+}
+
+func TestGetSubscriptionTapDefaultEvents(t *testing.T) {
+	config.Reset()
+	o, r := newTestAdminServer()
+	req := httptest.NewRequest("GET", "/admin/api/v1/namespaces/mynamespace/subscriptions/abcd12345/tap", nil)
+	res := httptest.NewRecorder()
+
+	records := make(chan *fftypes.SubscriptionTapRecord)
+	close(records)
+	o.On("TapSubscription", mock.Anything, "mynamespace", "abcd12345", 100).
+		Return((<-chan *fftypes.SubscriptionTapRecord)(records), nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetSubscriptionTapBadEvents(t *testing.T) {
+	_, r := newTestAdminServer()
+	req := httptest.NewRequest("GET", "/admin/api/v1/namespaces/mynamespace/subscriptions/abcd12345/tap?events=many", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10299", res.Body.String())
+}
+
+func TestGetSubscriptionTapFail(t *testing.T) {
+	config.Reset()
+	o, r := newTestAdminServer()
+	req := httptest.NewRequest("GET", "/admin/api/v1/namespaces/mynamespace/subscriptions/abcd12345/tap", nil)
+	res := httptest.NewRecorder()
+
+	o.On("TapSubscription", mock.Anything, "mynamespace", "abcd12345", 100).
+		Return(nil, fmt.Errorf("pop"))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
+
+func TestTapStreamReaderClosed(t *testing.T) {
+	records := make(chan *fftypes.SubscriptionTapRecord)
+	reader := newTapStream(records)
+	reader.Close()
+	records <- &fftypes.SubscriptionTapRecord{}
+	records <- &fftypes.SubscriptionTapRecord{}
+	close(records)
+	_, err := ioutil.ReadAll(reader)
+	assert.Error(t, err)
+}
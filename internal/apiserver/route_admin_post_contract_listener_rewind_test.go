@@ -0,0 +1,44 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/contractmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostContractListenerRewind(t *testing.T) {
+	o, r := newTestAdminServer()
+	mcm := &contractmocks.Manager{}
+	o.On("Contracts").Return(mcm)
+	req := httptest.NewRequest("POST", "/admin/api/v1/namespaces/mynamespace/contracts/listeners/listener1/rewind", bytes.NewReader([]byte(`{"blockNumber":12345}`)))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mcm.On("RewindContractListener", mock.Anything, "mynamespace", "listener1", mock.MatchedBy(func(rewind *fftypes.ContractListenerRewind) bool {
+		return *rewind.BlockNumber == 12345
+	})).Return(&fftypes.ContractListener{Checkpoint: "000000012345"}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
@@ -0,0 +1,126 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/oapispec"
+	"github.com/hyperledger/firefly/internal/orchestrator"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var getEventStream = &oapispec.Route{
+	Name:   "getEventStream",
+	Path:   "namespaces/{ns}/events/stream",
+	Method: http.MethodGet,
+	PathParams: []*oapispec.PathParam{
+		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
+	},
+	QueryParams: []*oapispec.QueryParam{
+		{Name: "firstevent", Example: "newest", Description: i18n.MsgTBD},
+	},
+	FilterFactory:   nil,
+	Description:     i18n.MsgTBD,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []*fftypes.EnrichedEvent{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	Streaming:       true,
+	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		// A reconnecting server-sent events client resumes after the last event it received
+		firstEvent := r.Req.Header.Get("Last-Event-ID")
+		if firstEvent == "" {
+			firstEvent = r.QP["firstevent"]
+		}
+		events, err := getOr(r.Ctx).StreamEvents(r.Ctx, r.PP["ns"], firstEvent)
+		if err != nil {
+			return nil, err
+		}
+		r.ResponseHeaders.Set("Content-Type", "text/event-stream")
+		r.ResponseHeaders.Set("Cache-Control", "no-cache")
+		return newEventStream(events), nil
+	},
+}
+
+// newEventStream streams events in the server-sent events format, with the sequence of each event as its ID.
+// The request context ends the stream when the client disconnects, which closes the events channel.
+func newEventStream(events <-chan *fftypes.EnrichedEvent) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		var err error
+		for event := range events {
+			if err == nil {
+				b, _ := json.Marshal(event)
+				_, err = fmt.Fprintf(pw, "id: %d\ndata: %s\n\n", event.Sequence, b)
+			}
+		}
+		pw.Close()
+	}()
+	return pr
+}
+
+var eventStreamUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		// Cors is handled by the API server that wraps this handler
+		return true
+	},
+}
+
+// eventStreamWebSocket serves the event stream to WebSocket clients, as one JSON text frame per event.
+// The client tracks the sequence of the last event it processed, and supplies it as firstevent to resume.
+func (as *apiServer) eventStreamWebSocket(o orchestrator.Orchestrator) func(res http.ResponseWriter, req *http.Request) (int, error) {
+	return func(res http.ResponseWriter, req *http.Request) (int, error) {
+		// The hijacked connection does not cancel the request context on disconnect, so we do that from the read loop
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+		events, err := o.StreamEvents(ctx, mux.Vars(req)["ns"], req.URL.Query().Get("firstevent"))
+		if err != nil {
+			return 500, err
+		}
+		wsConn, err := eventStreamUpgrader.Upgrade(res, req, nil)
+		if err != nil {
+			// The upgrader has already written the error response
+			log.L(ctx).Errorf("WebSocket upgrade failed: %s", err)
+			return http.StatusBadRequest, nil
+		}
+		defer wsConn.Close()
+		go func() {
+			defer cancel()
+			for {
+				if _, _, err := wsConn.NextReader(); err != nil {
+					return
+				}
+			}
+		}()
+		for event := range events {
+			if err := wsConn.WriteJSON(event); err != nil {
+				log.L(ctx).Debugf("Event stream closed: %s", err)
+				cancel()
+			}
+		}
+		return http.StatusSwitchingProtocols, nil
+	}
+}
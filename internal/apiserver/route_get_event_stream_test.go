@@ -0,0 +1,179 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestEventsChannel(events ...*fftypes.EnrichedEvent) <-chan *fftypes.EnrichedEvent {
+	ch := make(chan *fftypes.EnrichedEvent, len(events))
+	for _, e := range events {
+		ch <- e
+	}
+	close(ch)
+	return ch
+}
+
+func noDeadline(ctx context.Context) bool {
+	_, hasDeadline := ctx.Deadline()
+	return !hasDeadline
+}
+
+func TestGetEventStream(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/events/stream?firstevent=oldest", nil)
+	res := httptest.NewRecorder()
+
+	events := newTestEventsChannel(
+		&fftypes.EnrichedEvent{Event: fftypes.Event{Sequence: 101, Type: fftypes.EventTypeMessageConfirmed}},
+		&fftypes.EnrichedEvent{Event: fftypes.Event{Sequence: 102, Type: fftypes.EventTypeMessageConfirmed}},
+	)
+	o.On("StreamEvents", mock.MatchedBy(noDeadline), "mynamespace", "oldest").Return(events, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "text/event-stream", res.Result().Header.Get("Content-Type"))
+	assert.True(t, res.Flushed)
+	assert.Regexp(t, "^id: 101\ndata: \\{.*\"type\":\"message_confirmed\".*\\}\n\nid: 102\ndata: \\{.*\\}\n\n$", res.Body.String())
+}
+
+func TestGetEventStreamLastEventID(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/events/stream?firstevent=oldest", nil)
+	req.Header.Set("Last-Event-ID", "12345")
+	res := httptest.NewRecorder()
+
+	o.On("StreamEvents", mock.Anything, "mynamespace", "12345").Return(newTestEventsChannel(), nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Empty(t, res.Body.String())
+}
+
+func TestGetEventStreamFail(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/events/stream", nil)
+	res := httptest.NewRecorder()
+
+	o.On("StreamEvents", mock.Anything, "mynamespace", "").Return(nil, fmt.Errorf("pop"))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+	assert.Equal(t, "application/json", res.Result().Header.Get("Content-Type"))
+}
+
+func TestEventStreamReaderClosed(t *testing.T) {
+	events := make(chan *fftypes.EnrichedEvent)
+	reader := newEventStream(events)
+	reader.Close()
+	events <- &fftypes.EnrichedEvent{}
+	events <- &fftypes.EnrichedEvent{}
+	close(events)
+	_, err := ioutil.ReadAll(reader)
+	assert.Error(t, err)
+}
+
+func TestGetEventStreamWebSocket(t *testing.T) {
+	o, r := newTestAPIServer()
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	events := make(chan *fftypes.EnrichedEvent, 1)
+	events <- &fftypes.EnrichedEvent{Event: fftypes.Event{Sequence: 101}}
+	streamCtx := make(chan context.Context, 1)
+	o.On("StreamEvents", mock.MatchedBy(noDeadline), "mynamespace", "100").
+		Run(func(args mock.Arguments) { streamCtx <- args[0].(context.Context) }).
+		Return((<-chan *fftypes.EnrichedEvent)(events), nil)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/namespaces/mynamespace/events/stream?firstevent=100"
+	wsConn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+
+	var event fftypes.EnrichedEvent
+	err = wsConn.ReadJSON(&event)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(101), event.Sequence)
+
+	// Disconnecting the client ends the stream
+	wsConn.Close()
+	<-(<-streamCtx).Done()
+	close(events)
+}
+
+func TestGetEventStreamWebSocketWriteFail(t *testing.T) {
+	o, r := newTestAPIServer()
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	events := make(chan *fftypes.EnrichedEvent)
+	streamCtx := make(chan context.Context, 1)
+	o.On("StreamEvents", mock.Anything, "mynamespace", "").
+		Run(func(args mock.Arguments) { streamCtx <- args[0].(context.Context) }).
+		Return((<-chan *fftypes.EnrichedEvent)(events), nil)
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/namespaces/mynamespace/events/stream"
+	wsConn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+	wsConn.Close()
+
+	// Keep sending until the server notices the closed connection
+	ctx := <-streamCtx
+	for ctx.Err() == nil {
+		select {
+		case events <- &fftypes.EnrichedEvent{}:
+		case <-ctx.Done():
+		}
+	}
+	close(events)
+}
+
+func TestGetEventStreamWebSocketStreamFail(t *testing.T) {
+	o, r := newTestAPIServer()
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	o.On("StreamEvents", mock.Anything, "mynamespace", "").Return(nil, fmt.Errorf("pop"))
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/namespaces/mynamespace/events/stream"
+	_, res, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.Regexp(t, "bad handshake", err)
+	assert.Equal(t, 500, res.StatusCode)
+}
+
+func TestGetEventStreamWebSocketUpgradeFail(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/events/stream", nil)
+	req.Header.Set("Upgrade", "websocket")
+	res := httptest.NewRecorder()
+
+	o.On("StreamEvents", mock.Anything, "mynamespace", "").Return(make(<-chan *fftypes.EnrichedEvent), nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusBadRequest, res.Result().StatusCode)
+}
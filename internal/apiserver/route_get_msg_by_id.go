@@ -36,14 +36,23 @@ var getMsgByID = &oapispec.Route{
 	},
 	QueryParams: []*oapispec.QueryParam{
 		{Name: "fetchdata", IsBool: true, Description: i18n.MsgFetchDataDesc},
+		{Name: "include", Description: i18n.MsgIncludeDesc},
 	},
 	FilterFactory:   nil,
 	Description:     i18n.MsgTBD,
 	JSONInputValue:  nil,
-	JSONOutputValue: func() interface{} { return &fftypes.MessageInOut{} }, // can include full values
+	JSONOutputValue: func() interface{} { return &fftypes.MessageWithIncludes{} }, // can include full values and related records
 	JSONOutputCodes: []int{http.StatusOK},
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
-		if strings.EqualFold(r.QP["data"], "true") || strings.EqualFold(r.QP["fetchdata"], "true") {
+		fetchData := strings.EqualFold(r.QP["data"], "true") || strings.EqualFold(r.QP["fetchdata"], "true")
+		if r.QP["include"] != "" {
+			includes := strings.Split(r.QP["include"], ",")
+			if fetchData {
+				includes = append(includes, "data")
+			}
+			return getOr(r.Ctx).GetMessageByIDWithIncludes(r.Ctx, r.PP["ns"], r.PP["msgid"], includes)
+		}
+		if fetchData {
 			return getOr(r.Ctx).GetMessageByIDWithData(r.Ctx, r.PP["ns"], r.PP["msgid"])
 		}
 		return getOr(r.Ctx).GetMessageByID(r.Ctx, r.PP["ns"], r.PP["msgid"])
@@ -50,3 +50,29 @@ func TestGetMessageByIDWithData(t *testing.T) {
 
 	assert.Equal(t, 200, res.Result().StatusCode)
 }
+
+func TestGetMessageByIDWithIncludes(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages/abcd12345?include=transaction,events", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetMessageByIDWithIncludes", mock.Anything, "mynamespace", "abcd12345", []string{"transaction", "events"}).
+		Return(&fftypes.MessageWithIncludes{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetMessageByIDWithIncludesAndData(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/mynamespace/messages/abcd12345?include=events&fetchdata", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("GetMessageByIDWithIncludes", mock.Anything, "mynamespace", "abcd12345", []string{"events", "data"}).
+		Return(&fftypes.MessageWithIncludes{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
@@ -0,0 +1,62 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/oapispec"
+)
+
+var getReportDownload = &oapispec.Route{
+	Name:   "getReportDownload",
+	Path:   "namespaces/{ns}/reports/{id}/download",
+	Method: http.MethodGet,
+	PathParams: []*oapispec.PathParam{
+		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
+		{Name: "id", Description: i18n.MsgTBD},
+	},
+	QueryParams: []*oapispec.QueryParam{
+		{Name: "format", Example: "csv", Description: i18n.MsgTBD},
+	},
+	FilterFactory:   nil,
+	Description:     i18n.MsgTBD,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []byte{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		report, err := getOr(r.Ctx).GetReportByID(r.Ctx, r.PP["ns"], r.PP["id"])
+		if err != nil || report == nil {
+			return nil, err
+		}
+		format := r.QP["format"]
+		if format == "" {
+			format = "json"
+		}
+		content, err := report.Formatted(r.Ctx, format)
+		if err != nil {
+			return nil, err
+		}
+		r.ResponseHeaders.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, report.FileName(format)))
+		return ioutil.NopCloser(bytes.NewReader(content)), nil
+	},
+}
@@ -0,0 +1,105 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestDownloadReport() *fftypes.Report {
+	start := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	return &fftypes.Report{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Period:    fftypes.ReportPeriodMonthly,
+		Start:     fftypes.UnixTime(start.Unix()),
+		End:       fftypes.UnixTime(start.AddDate(0, 1, 0).Unix()),
+		Metrics:   &fftypes.ReportMetrics{},
+	}
+}
+
+func TestGetReportDownloadCSV(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/reports/abcd1234/download?format=csv", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetReportByID", mock.Anything, "ns1", "abcd1234").
+		Return(newTestDownloadReport(), nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, `attachment; filename="ns1-monthly-2022-03-01.csv"`, res.Result().Header.Get("Content-Disposition"))
+	assert.Regexp(t, "^section,name,metric,value", res.Body.String())
+}
+
+func TestGetReportDownloadDefaultJSON(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/reports/abcd1234/download", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetReportByID", mock.Anything, "ns1", "abcd1234").
+		Return(newTestDownloadReport(), nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, `attachment; filename="ns1-monthly-2022-03-01.json"`, res.Result().Header.Get("Content-Disposition"))
+	assert.Regexp(t, "\"namespace\": \"ns1\"", res.Body.String())
+}
+
+func TestGetReportDownloadBadFormat(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/reports/abcd1234/download?format=xml", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetReportByID", mock.Anything, "ns1", "abcd1234").
+		Return(newTestDownloadReport(), nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10429", res.Body.String())
+}
+
+func TestGetReportDownloadNotFound(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/reports/abcd1234/download", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetReportByID", mock.Anything, "ns1", "abcd1234").
+		Return(nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 404, res.Result().StatusCode)
+}
+
+func TestGetReportDownloadFail(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/reports/abcd1234/download", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetReportByID", mock.Anything, "ns1", "abcd1234").
+		Return(nil, fmt.Errorf("pop"))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
@@ -0,0 +1,58 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/oapispec"
+)
+
+var getSavedQueryResults = &oapispec.Route{
+	Name:   "getSavedQueryResults",
+	Path:   "namespaces/{ns}/savedqueries/{name}/results",
+	Method: http.MethodGet,
+	PathParams: []*oapispec.PathParam{
+		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
+		{Name: "name", Description: i18n.MsgTBD},
+	},
+	QueryParams: []*oapispec.QueryParam{
+		{Name: "skip", Description: i18n.MsgTBD},
+		{Name: "limit", Description: i18n.MsgTBD},
+	},
+	FilterFactory:   nil,
+	Description:     i18n.MsgTBD,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []interface{}{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		query, err := getOr(r.Ctx).GetSavedQueryByName(r.Ctx, r.PP["ns"], r.PP["name"])
+		if err != nil {
+			return nil, err
+		}
+		if query == nil {
+			return nil, i18n.NewError(r.Ctx, i18n.Msg404NotFound)
+		}
+		collection, filter, err := savedQueryFilter(r, query, r.QP)
+		if err != nil {
+			return nil, err
+		}
+		return filterResult(collection.get(r, r.PP["ns"], filter))
+	},
+}
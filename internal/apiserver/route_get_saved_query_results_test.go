@@ -0,0 +1,88 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetSavedQueryResults(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/savedqueries/unconfirmed/results?limit=5", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetSavedQueryByName", mock.Anything, "ns1", "unconfirmed").Return(&fftypes.SavedQuery{
+		Name:       "unconfirmed",
+		Collection: "messages",
+		Filter:     "confirmed=?&limit=100&count",
+	}, nil)
+	o.On("GetMessages", mock.Anything, "ns1", mock.MatchedBy(func(filter database.AndFilter) bool {
+		fi, _ := filter.Finalize()
+		return fi.Limit == 5 && fi.Count && fi.Children[0].Op == database.FilterOpEq
+	})).Return([]*fftypes.Message{{}}, &database.FilterResult{TotalCount: &[]int64{10}[0]}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	var result filterResultsWithCount
+	json.NewDecoder(res.Body).Decode(&result)
+	assert.Equal(t, int64(10), result.Total)
+	assert.Equal(t, int64(1), result.Count)
+}
+
+func TestGetSavedQueryResultsNotFound(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/savedqueries/unknown/results", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetSavedQueryByName", mock.Anything, "ns1", "unknown").Return(nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 404, res.Result().StatusCode)
+}
+
+func TestGetSavedQueryResultsLookupFail(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/savedqueries/query1/results", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetSavedQueryByName", mock.Anything, "ns1", "query1").Return(nil, fmt.Errorf("pop"))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 500, res.Result().StatusCode)
+}
+
+func TestGetSavedQueryResultsBadCollection(t *testing.T) {
+	o, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/savedqueries/query1/results", nil)
+	res := httptest.NewRecorder()
+
+	o.On("GetSavedQueryByName", mock.Anything, "ns1", "query1").Return(&fftypes.SavedQuery{
+		Collection: "removed",
+	}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10419", res.Body.String())
+}
@@ -0,0 +1,56 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/oapispec"
+	"github.com/hyperledger/firefly/internal/serialization"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var getSerializationSchema = &oapispec.Route{
+	Name:   "getSerializationSchema",
+	Path:   "serialization/{format}/schema",
+	Method: http.MethodGet,
+	PathParams: []*oapispec.PathParam{
+		{Name: "format", Example: "protobuf", Description: i18n.MsgTBD},
+	},
+	QueryParams:     nil,
+	FilterFactory:   nil,
+	Description:     i18n.MsgTBD,
+	JSONInputValue:  nil,
+	JSONOutputValue: func() interface{} { return []byte{} },
+	JSONOutputCodes: []int{http.StatusOK},
+	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		format := fftypes.SubOptsSerialization(r.PP["format"])
+		schema, err := serialization.Schema(r.Ctx, format)
+		if err != nil {
+			return nil, err
+		}
+		if format == fftypes.SubOptsSerializationProtobuf {
+			r.ResponseHeaders.Set("Content-Type", "text/plain")
+		} else {
+			r.ResponseHeaders.Set("Content-Type", "application/schema+json")
+		}
+		return ioutil.NopCloser(strings.NewReader(schema)), nil
+	},
+}
@@ -0,0 +1,59 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSerializationSchemaProtobuf(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/serialization/protobuf/schema", nil)
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "text/plain", res.Result().Header.Get("Content-Type"))
+	assert.Regexp(t, "message EventDelivery", res.Body.String())
+}
+
+func TestGetSerializationSchemaMsgPack(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/serialization/msgpack/schema", nil)
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Equal(t, "application/schema+json", res.Result().Header.Get("Content-Type"))
+	assert.Regexp(t, "\"subscription\"", res.Body.String())
+}
+
+func TestGetSerializationSchemaBadFormat(t *testing.T) {
+	_, r := newTestAPIServer()
+	req := httptest.NewRequest("GET", "/api/v1/serialization/xml/schema", nil)
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10498", res.Body.String())
+}
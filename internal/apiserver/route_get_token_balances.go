@@ -33,13 +33,23 @@ var getTokenBalances = &oapispec.Route{
 	PathParams: []*oapispec.PathParam{
 		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
 	},
-	QueryParams:     nil,
+	QueryParams: []*oapispec.QueryParam{
+		{Name: "counterparty", Description: i18n.MsgTBD},
+	},
 	FilterFactory:   database.TokenBalanceQueryFactory,
 	Description:     i18n.MsgTBD,
 	JSONInputValue:  nil,
 	JSONOutputValue: func() interface{} { return []*fftypes.TokenBalance{} },
 	JSONOutputCodes: []int{http.StatusOK},
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
-		return filterResult(getOr(r.Ctx).Assets().GetTokenBalances(r.Ctx, r.PP["ns"], r.Filter))
+		filter := r.Filter
+		if counterparty, ok := r.QP["counterparty"]; ok {
+			keys, err := getOr(r.Ctx).Assets().GetCounterpartyKeys(r.Ctx, r.PP["ns"], counterparty)
+			if err != nil {
+				return nil, err
+			}
+			filter.Condition(filter.Builder().In("key", keyValues(keys)))
+		}
+		return filterResult(getOr(r.Ctx).Assets().GetTokenBalances(r.Ctx, r.PP["ns"], filter))
 	},
 }
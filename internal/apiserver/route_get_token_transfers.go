@@ -35,6 +35,7 @@ var getTokenTransfers = &oapispec.Route{
 	},
 	QueryParams: []*oapispec.QueryParam{
 		{Name: "fromOrTo", Description: i18n.MsgTBD},
+		{Name: "counterparty", Description: i18n.MsgTBD},
 	},
 	FilterFactory:   database.TokenTransferQueryFactory,
 	Description:     i18n.MsgTBD,
@@ -50,6 +51,17 @@ var getTokenTransfers = &oapispec.Route{
 					Condition(fb.Eq("from", fromOrTo)).
 					Condition(fb.Eq("to", fromOrTo)))
 		}
+		if counterparty, ok := r.QP["counterparty"]; ok {
+			keys, err := getOr(r.Ctx).Assets().GetCounterpartyKeys(r.Ctx, r.PP["ns"], counterparty)
+			if err != nil {
+				return nil, err
+			}
+			fb := database.TokenTransferQueryFactory.NewFilter(r.Ctx)
+			filter.Condition(
+				fb.Or().
+					Condition(fb.In("from", keyValues(keys))).
+					Condition(fb.In("to", keyValues(keys))))
+		}
 		return filterResult(getOr(r.Ctx).Assets().GetTokenTransfers(r.Ctx, r.PP["ns"], filter))
 	},
 }
@@ -17,9 +17,11 @@
 package apiserver
 
 import (
+	"context"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/mocks/assetmocks"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -58,3 +60,35 @@ func TestGetTokenTransfersFromOrTo(t *testing.T) {
 
 	assert.Equal(t, 200, res.Result().StatusCode)
 }
+
+func TestGetTokenTransfersCounterparty(t *testing.T) {
+	o, r := newTestAPIServer()
+	mam := &assetmocks.Manager{}
+	o.On("Assets").Return(mam)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/tokens/transfers?counterparty=org1", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mam.On("GetCounterpartyKeys", mock.Anything, "ns1", "org1").Return([]string{"0x1", "0x2"}, nil)
+	mam.On("GetTokenTransfers", mock.Anything, "ns1", mock.MatchedBy(func(filter database.AndFilter) bool {
+		info, _ := filter.Finalize()
+		return info.String() == "( ( from IN ['0x1','0x2'] ) || ( to IN ['0x1','0x2'] ) )"
+	})).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestGetTokenTransfersCounterpartyFail(t *testing.T) {
+	o, r := newTestAPIServer()
+	mam := &assetmocks.Manager{}
+	o.On("Assets").Return(mam)
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/tokens/transfers?counterparty=org1", nil)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mam.On("GetCounterpartyKeys", mock.Anything, "ns1", "org1").Return(nil, i18n.NewError(context.Background(), i18n.MsgCounterpartyNotFound, "org1"))
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 404, res.Result().StatusCode)
+}
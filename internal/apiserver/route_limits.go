@@ -0,0 +1,101 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const defaultRouteRetryAfter = 1 * time.Second
+
+type routeLimitConfig struct {
+	Name          string             `json:"name"`
+	Timeout       fftypes.FFDuration `json:"timeout,omitempty"`
+	MaxConcurrent int                `json:"maxConcurrent,omitempty"`
+	RetryAfter    fftypes.FFDuration `json:"retryAfter,omitempty"`
+}
+
+// routeLimit bounds the time and the number of workers a single route can consume, so that
+// requests blocked on one slow dependency cannot exhaust the HTTP server
+type routeLimit struct {
+	timeout    time.Duration
+	slots      chan struct{}
+	retryAfter string
+}
+
+func (as *apiServer) loadRouteLimits(ctx context.Context) error {
+	names := make(map[string]bool)
+	for _, route := range append(as.apiRoutes(), as.adminAPIRoutes()...) {
+		names[route.Name] = true
+	}
+	as.routeLimits = make(map[string]*routeLimit)
+	for i, limitObject := range config.GetObjectArray(config.APIRoutes) {
+		var lc *routeLimitConfig
+		b, _ := json.Marshal(limitObject)
+		if err := json.Unmarshal(b, &lc); err != nil {
+			return i18n.NewError(ctx, i18n.MsgRouteLimitInvalid, i, err)
+		}
+		switch {
+		case !names[lc.Name]:
+			return i18n.NewError(ctx, i18n.MsgRouteLimitInvalid, i, fmt.Sprintf("unknown route '%s'", lc.Name))
+		case as.routeLimits[lc.Name] != nil:
+			return i18n.NewError(ctx, i18n.MsgRouteLimitInvalid, i, fmt.Sprintf("duplicate route '%s'", lc.Name))
+		case lc.Timeout < 0 || lc.MaxConcurrent < 0 || lc.RetryAfter < 0:
+			return i18n.NewError(ctx, i18n.MsgRouteLimitInvalid, i, "limits cannot be negative")
+		}
+		limit := &routeLimit{
+			timeout: time.Duration(lc.Timeout),
+		}
+		if lc.MaxConcurrent > 0 {
+			limit.slots = make(chan struct{}, lc.MaxConcurrent)
+			retryAfter := time.Duration(lc.RetryAfter)
+			if retryAfter == 0 {
+				retryAfter = defaultRouteRetryAfter
+			}
+			// Retry-After is a whole number of seconds
+			limit.retryAfter = strconv.FormatInt(int64((retryAfter+time.Second-1)/time.Second), 10)
+		}
+		log.L(ctx).Infof("API route '%s' limited: timeout=%s maxConcurrent=%d", lc.Name, limit.timeout, lc.MaxConcurrent)
+		as.routeLimits[lc.Name] = limit
+	}
+	return nil
+}
+
+// acquire takes one of the concurrent request slots of the route, or returns a 503 error with a
+// Retry-After header if they are all in use. The returned func releases the slot.
+func (rl *routeLimit) acquire(res http.ResponseWriter, req *http.Request, name string) (func(), error) {
+	if rl.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case rl.slots <- struct{}{}:
+		return func() { <-rl.slots }, nil
+	default:
+		res.Header().Set("Retry-After", rl.retryAfter)
+		return nil, i18n.NewError(req.Context(), i18n.MsgRouteSaturated, name)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/orchestratormocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLoadRouteLimits(t *testing.T) {
+	config.Reset()
+	config.Set(config.APIRoutes, fftypes.JSONObjectArray{
+		{"name": "getStatus", "timeout": "5s", "maxConcurrent": 2, "retryAfter": "1500ms"},
+		{"name": "getConfig", "timeout": "1s"},
+		{"name": "getMsgs", "maxConcurrent": 1},
+	})
+	_, as := newTestServer()
+	err := as.loadRouteLimits(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 5*time.Second, as.routeLimits["getStatus"].timeout)
+	assert.Equal(t, 2, cap(as.routeLimits["getStatus"].slots))
+	assert.Equal(t, "2", as.routeLimits["getStatus"].retryAfter)
+	assert.Equal(t, time.Second, as.routeLimits["getConfig"].timeout)
+	assert.Nil(t, as.routeLimits["getConfig"].slots)
+	assert.Equal(t, "1", as.routeLimits["getMsgs"].retryAfter)
+}
+
+func TestLoadRouteLimitsInvalid(t *testing.T) {
+	for _, limits := range []fftypes.JSONObjectArray{
+		{{"name": "getStatus", "maxConcurrent": "many"}},
+		{{"name": "unknown"}},
+		{{"name": "getStatus"}, {"name": "getStatus"}},
+		{{"name": "getStatus", "maxConcurrent": -1}},
+	} {
+		config.Reset()
+		config.Set(config.APIRoutes, limits)
+		_, as := newTestServer()
+		err := as.loadRouteLimits(context.Background())
+		assert.Regexp(t, "FF10544", err)
+	}
+}
+
+func TestServeRouteLimitsFail(t *testing.T) {
+	config.Reset()
+	InitConfig()
+	config.Set(config.APIRoutes, fftypes.JSONObjectArray{{"name": "unknown"}})
+	as := NewAPIServer()
+	err := as.Serve(context.Background(), &orchestratormocks.Orchestrator{})
+	assert.Regexp(t, "FF10544", err)
+}
+
+func TestRouteSaturated(t *testing.T) {
+	o, as := newTestServer()
+	as.routeLimits = map[string]*routeLimit{
+		"getStatus": {slots: make(chan struct{}, 1), retryAfter: "3"},
+	}
+	r := as.createMuxRouter(context.Background(), o)
+	o.On("GetStatus", mock.Anything).Return(&fftypes.NodeStatus{}, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/status", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Empty(t, as.routeLimits["getStatus"].slots)
+
+	// All slots in use
+	as.routeLimits["getStatus"].slots <- struct{}{}
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 503, res.Result().StatusCode)
+	assert.Equal(t, "3", res.Result().Header.Get("Retry-After"))
+	var resErr fftypes.RESTError
+	err := json.NewDecoder(res.Body).Decode(&resErr)
+	assert.NoError(t, err)
+	assert.Regexp(t, "FF10545.*getStatus", resErr.Error)
+}
+
+func TestRouteTimeout(t *testing.T) {
+	o, as := newTestServer()
+	as.routeLimits = map[string]*routeLimit{
+		"getStatus": {timeout: 2 * time.Second},
+	}
+	r := as.createMuxRouter(context.Background(), o)
+	deadlines := make(chan time.Time, 2)
+	o.On("GetStatus", mock.Anything).Return(&fftypes.NodeStatus{}, nil).Run(func(args mock.Arguments) {
+		deadline, _ := args[0].(context.Context).Deadline()
+		deadlines <- deadline
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/status", nil)
+	res := httptest.NewRecorder()
+	startTime := time.Now()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.WithinDuration(t, startTime.Add(2*time.Second), <-deadlines, time.Second)
+
+	// The Request-Timeout header cannot extend the timeout of the route
+	req.Header.Set("Request-Timeout", "1h")
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.WithinDuration(t, startTime.Add(2*time.Second), <-deadlines, time.Second)
+}
+
+func TestRequestTimeoutShorterThanRoute(t *testing.T) {
+	_, as := newTestServer()
+	req, err := http.NewRequest("GET", "http://test.example.com", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Request-Timeout", "1s")
+	assert.Equal(t, time.Second, as.requestTimeout(req, time.Minute, time.Minute))
+}
@@ -18,6 +18,7 @@ package apiserver
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
@@ -32,15 +33,25 @@ var postContractInterfaceGenerate = &oapispec.Route{
 	PathParams: []*oapispec.PathParam{
 		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
 	},
-	QueryParams:     []*oapispec.QueryParam{},
+	QueryParams: []*oapispec.QueryParam{
+		{Name: "publish", Description: i18n.MsgTBD, IsBool: true},
+		{Name: "confirm", Description: i18n.MsgConfirmQueryParam, IsBool: true},
+	},
 	FilterFactory:   nil,
 	Description:     i18n.MsgTBD,
 	JSONInputValue:  func() interface{} { return &fftypes.FFIGenerationRequest{} },
 	JSONInputMask:   nil,
 	JSONOutputValue: func() interface{} { return &fftypes.FFI{} },
-	JSONOutputCodes: []int{http.StatusOK},
+	JSONOutputCodes: []int{http.StatusOK, http.StatusAccepted},
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
 		generationRequest := r.Input.(*fftypes.FFIGenerationRequest)
-		return getOr(r.Ctx).Contracts().GenerateFFI(r.Ctx, r.PP["ns"], generationRequest)
+		ffi, err := getOr(r.Ctx).Contracts().GenerateFFI(r.Ctx, r.PP["ns"], generationRequest)
+		if err != nil || !strings.EqualFold(r.QP["publish"], "true") {
+			return ffi, err
+		}
+		// Store and broadcast the generated interface, exactly as if it had been posted to contracts/interfaces
+		waitConfirm := strings.EqualFold(r.QP["confirm"], "true")
+		r.SuccessStatus = syncRetcode(waitConfirm)
+		return getOr(r.Ctx).Contracts().BroadcastFFI(r.Ctx, r.PP["ns"], ffi, waitConfirm)
 	},
 }
@@ -45,3 +45,25 @@ func TestPostContractInterfaceGenerate(t *testing.T) {
 
 	assert.Equal(t, 200, res.Result().StatusCode)
 }
+
+func TestPostContractInterfaceGeneratePublish(t *testing.T) {
+	o, r := newTestAPIServer()
+	mcm := &contractmocks.Manager{}
+	o.On("Contracts").Return(mcm)
+	input := fftypes.FFIGenerationRequest{}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/contracts/interfaces/generate?publish", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	ffi := &fftypes.FFI{Name: "generated"}
+	mcm.On("GenerateFFI", mock.Anything, "ns1", mock.Anything).
+		Return(ffi, nil)
+	mcm.On("BroadcastFFI", mock.Anything, "ns1", ffi, false).
+		Return(ffi, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 202, res.Result().StatusCode)
+	mcm.AssertExpectations(t)
+}
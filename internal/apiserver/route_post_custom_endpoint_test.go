@@ -0,0 +1,61 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/inboundmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostCustomEndpoint(t *testing.T) {
+	o, r := newTestAPIServer()
+	mib := &inboundmocks.Manager{}
+	o.On("Inbound").Return(mib)
+	buf := bytes.NewBufferString(`{"order":"12345"}`)
+	req := httptest.NewRequest("POST", "/api/v1/custom/purchase-orders", buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mib.On("Receive", mock.Anything, "purchase-orders", mock.MatchedBy(func(body *fftypes.JSONAny) bool {
+		return body.JSONObject().GetString("order") == "12345"
+	}), false).Return(&fftypes.Message{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 202, res.Result().StatusCode)
+}
+
+func TestPostCustomEndpointSync(t *testing.T) {
+	o, r := newTestAPIServer()
+	mib := &inboundmocks.Manager{}
+	o.On("Inbound").Return(mib)
+	buf := bytes.NewBufferString(`{"order":"12345"}`)
+	req := httptest.NewRequest("POST", "/api/v1/custom/purchase-orders?confirm", buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mib.On("Receive", mock.Anything, "purchase-orders", mock.AnythingOfType("*fftypes.JSONAny"), true).
+		Return(&fftypes.Message{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
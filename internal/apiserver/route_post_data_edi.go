@@ -0,0 +1,55 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/oapispec"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var postDataEDI = &oapispec.Route{
+	Name:   "postDataEDI",
+	Path:   "namespaces/{ns}/data/edi",
+	Method: http.MethodPost,
+	PathParams: []*oapispec.PathParam{
+		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
+	},
+	QueryParams:     nil,
+	FilterFactory:   nil,
+	Description:     i18n.MsgTBD,
+	JSONInputValue:  func() interface{} { return &fftypes.EDIInput{} },
+	JSONInputMask:   nil,
+	JSONOutputValue: func() interface{} { return fftypes.DataArray{} },
+	JSONOutputCodes: []int{http.StatusCreated},
+	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		output, err = getOr(r.Ctx).Data().UploadEDI(r.Ctx, r.PP["ns"], r.Input.(*fftypes.EDIInput).Raw)
+		return output, err
+	},
+	FormUploadHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
+		raw, err := ioutil.ReadAll(r.Part.Data)
+		if err != nil {
+			return nil, err
+		}
+		output, err = getOr(r.Ctx).Data().UploadEDI(r.Ctx, r.PP["ns"], string(raw))
+		return output, err
+	},
+}
@@ -0,0 +1,73 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/mailgatewaymocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostEmailReply(t *testing.T) {
+	o, r := newTestAPIServer()
+	mmg := &mailgatewaymocks.Manager{}
+	o.On("MailGateway").Return(mmg)
+	input := fftypes.EmailReplyInput{Raw: "Subject: hello\r\n\r\n"}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/email/replies", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	mmg.On("IngestReply", mock.Anything, "ns1", mock.MatchedBy(func(raw io.Reader) bool {
+		b, _ := ioutil.ReadAll(raw)
+		return string(b) == input.Raw
+	}), false).Return(&fftypes.Message{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 202, res.Result().StatusCode)
+}
+
+func TestPostEmailReplyUploadSync(t *testing.T) {
+	o, r := newTestAPIServer()
+	mmg := &mailgatewaymocks.Manager{}
+	o.On("MailGateway").Return(mmg)
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	writer, err := w.CreateFormFile("file", "reply.eml")
+	assert.NoError(t, err)
+	writer.Write([]byte("Subject: hello\r\n\r\n"))
+	w.Close()
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/email/replies?confirm", &b)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	res := httptest.NewRecorder()
+
+	mmg.On("IngestReply", mock.Anything, "ns1", mock.Anything, true).Return(&fftypes.Message{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
@@ -0,0 +1,45 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostLegalHold(t *testing.T) {
+	o, r := newTestAPIServer()
+	input := fftypes.LegalHoldInput{Type: fftypes.LegalHoldTypeMessage, Ref: fftypes.NewUUID(), Reason: "case 1234"}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/legalholds", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("PlaceLegalHold", mock.Anything, "ns1", mock.MatchedBy(func(in *fftypes.LegalHoldInput) bool {
+		return in.Reason == "case 1234"
+	})).Return(&fftypes.LegalHold{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 201, res.Result().StatusCode)
+}
@@ -36,7 +36,7 @@ var postNewContractListener = &oapispec.Route{
 	FilterFactory:   nil,
 	Description:     i18n.MsgTBD,
 	JSONInputValue:  func() interface{} { return &fftypes.ContractListenerInput{} },
-	JSONInputMask:   []string{"Namespace", "ProtocolID"},
+	JSONInputMask:   []string{"Namespace", "ProtocolID", "Checkpoint", "Backfill"},
 	JSONOutputValue: func() interface{} { return &fftypes.ContractListener{} },
 	JSONOutputCodes: []int{http.StatusOK},
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
@@ -19,6 +19,7 @@ package apiserver
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
@@ -33,7 +34,9 @@ var postNewMessageRequestReply = &oapispec.Route{
 	PathParams: []*oapispec.PathParam{
 		{Name: "ns", ExampleFromConf: config.NamespacesDefault, Description: i18n.MsgTBD},
 	},
-	QueryParams:     nil,
+	QueryParams: []*oapispec.QueryParam{
+		{Name: "timeout", Example: "60s", Description: i18n.MsgReplyTimeoutDesc},
+	},
 	FilterFactory:   nil,
 	Description:     i18n.MsgTBD,
 	JSONInputValue:  func() interface{} { return &fftypes.MessageInOut{} },
@@ -41,7 +44,20 @@ var postNewMessageRequestReply = &oapispec.Route{
 	JSONOutputValue: func() interface{} { return &fftypes.MessageInOut{} },
 	JSONOutputCodes: []int{http.StatusOK}, // Sync operation
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
-		output, err = getOr(r.Ctx).RequestReply(r.Ctx, r.PP["ns"], r.Input.(*fftypes.MessageInOut))
+		timeout, err := replyTimeout(r)
+		if err != nil {
+			return nil, err
+		}
+		output, err = getOr(r.Ctx).RequestReply(r.Ctx, r.PP["ns"], r.Input.(*fftypes.MessageInOut), timeout)
 		return output, err
 	},
 }
+
+// replyTimeout is the optional timeout query parameter of the request/reply APIs, which is zero when not set
+func replyTimeout(r *oapispec.APIRequest) (time.Duration, error) {
+	if r.QP["timeout"] == "" {
+		return 0, nil
+	}
+	timeout, err := fftypes.ParseDurationString(r.QP["timeout"], time.Millisecond)
+	return time.Duration(timeout), err
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -29,7 +30,7 @@ import (
 
 func TestPostNewMessageRequestReply(t *testing.T) {
 	o, r := newTestAPIServer()
-	o.On("RequestReply", mock.Anything, "ns1", mock.Anything).Return(&fftypes.MessageInOut{}, nil)
+	o.On("RequestReply", mock.Anything, "ns1", mock.Anything, time.Duration(0)).Return(&fftypes.MessageInOut{}, nil)
 	input := &fftypes.MessageInOut{}
 	var buf bytes.Buffer
 	json.NewEncoder(&buf).Encode(&input)
@@ -40,3 +41,30 @@ func TestPostNewMessageRequestReply(t *testing.T) {
 
 	assert.Equal(t, 200, res.Result().StatusCode)
 }
+
+func TestPostNewMessageRequestReplyTimeout(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("RequestReply", mock.Anything, "ns1", mock.Anything, 5*time.Second).Return(&fftypes.MessageInOut{}, nil)
+	input := &fftypes.MessageInOut{}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/requestreply?timeout=5s", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestPostNewMessageRequestReplyBadTimeout(t *testing.T) {
+	_, r := newTestAPIServer()
+	input := &fftypes.MessageInOut{}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/messages/requestreply?timeout=bad", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 400, res.Result().StatusCode)
+}
@@ -94,6 +94,16 @@ func newSubscriptionSchemaGenerator(ctx context.Context) string {
 	return string(b)
 }
 
+// checkSubscriptionNamespaces only allows a filter to match events across namespaces on the admin API, as the
+// main API is scoped by the namespace in the path
+func checkSubscriptionNamespaces(r *oapispec.APIRequest, filter *fftypes.SubscriptionFilter) error {
+	ns := r.PP["ns"]
+	if !r.Admin && filter.SpansNamespaces(ns) {
+		return i18n.NewError(r.Ctx, i18n.MsgSubscriptionNamespacesAdmin, ns)
+	}
+	return nil
+}
+
 var postNewSubscription = &oapispec.Route{
 	Name:   "postNewSubscription",
 	Path:   "namespaces/{ns}/subscriptions",
@@ -109,7 +119,11 @@ var postNewSubscription = &oapispec.Route{
 	JSONOutputCodes: []int{http.StatusCreated}, // Sync operation
 	JSONInputSchema: newSubscriptionSchemaGenerator,
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
-		output, err = getOr(r.Ctx).CreateSubscription(r.Ctx, r.PP["ns"], r.Input.(*fftypes.Subscription))
+		sub := r.Input.(*fftypes.Subscription)
+		if err = checkSubscriptionNamespaces(r, &sub.Filter); err != nil {
+			return nil, err
+		}
+		output, err = getOr(r.Ctx).CreateSubscription(r.Ctx, r.PP["ns"], sub)
 		return output, err
 	},
 }
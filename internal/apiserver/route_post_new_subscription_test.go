@@ -42,3 +42,42 @@ func TestPostNewSubscription(t *testing.T) {
 
 	assert.Equal(t, 201, res.Result().StatusCode)
 }
+
+func TestPostNewSubscriptionCrossNamespaceMainAPI(t *testing.T) {
+	o, r := newTestAPIServer()
+	input := fftypes.Subscription{
+		Filter: fftypes.SubscriptionFilter{
+			Namespaces: []string{fftypes.SubscriptionAllNamespaces},
+		},
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/subscriptions", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+	o.AssertNotCalled(t, "CreateSubscription", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPostNewSubscriptionCrossNamespaceAdminAPI(t *testing.T) {
+	o, r := newTestAdminServer()
+	input := fftypes.Subscription{
+		Filter: fftypes.SubscriptionFilter{
+			Namespaces: []string{"ns2"},
+		},
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/admin/api/v1/namespaces/ns1/subscriptions", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("CreateSubscription", mock.Anything, "ns1", mock.AnythingOfType("*fftypes.Subscription")).
+		Return(&fftypes.Subscription{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 201, res.Result().StatusCode)
+}
@@ -0,0 +1,93 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func postTestSavedQuery(t *testing.T, query *fftypes.SavedQuery, expectSave bool) *httptest.ResponseRecorder {
+	o, r := newTestAPIServer()
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(query)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/savedqueries", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	if expectSave {
+		o.On("SaveQuery", mock.Anything, "ns1", mock.AnythingOfType("*fftypes.SavedQuery")).
+			Return(&fftypes.SavedQuery{}, nil)
+	}
+	r.ServeHTTP(res, req)
+	o.AssertExpectations(t)
+	return res
+}
+
+func TestPostSavedQuery(t *testing.T) {
+	res := postTestSavedQuery(t, &fftypes.SavedQuery{
+		Name:       "unconfirmed",
+		Collection: "messages",
+		Filter:     "confirmed=?&created=<now-1h&sort=created",
+	}, true)
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestPostSavedQueryBadCollection(t *testing.T) {
+	res := postTestSavedQuery(t, &fftypes.SavedQuery{
+		Name:       "query1",
+		Collection: "unknown",
+	}, false)
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10419.*batches, blockchainevents", res.Body.String())
+}
+
+func TestPostSavedQueryBadQueryString(t *testing.T) {
+	res := postTestSavedQuery(t, &fftypes.SavedQuery{
+		Name:       "query1",
+		Collection: "messages",
+		Filter:     "tag=%zz",
+	}, false)
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10420", res.Body.String())
+}
+
+func TestPostSavedQueryBadModifier(t *testing.T) {
+	res := postTestSavedQuery(t, &fftypes.SavedQuery{
+		Name:       "query1",
+		Collection: "messages",
+		Filter:     "created=!>0",
+	}, false)
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10322", res.Body.String())
+}
+
+func TestPostSavedQueryBadValue(t *testing.T) {
+	res := postTestSavedQuery(t, &fftypes.SavedQuery{
+		Name:       "query1",
+		Collection: "messages",
+		Filter:     "created=<now1h",
+	}, false)
+	assert.Equal(t, 400, res.Result().StatusCode)
+	assert.Regexp(t, "FF10418", res.Body.String())
+}
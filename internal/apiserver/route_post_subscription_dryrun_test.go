@@ -0,0 +1,83 @@
+// Copyright © 2021 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPostSubscriptionDryRun(t *testing.T) {
+	o, r := newTestAPIServer()
+	input := fftypes.SubscriptionDryRun{Limit: 10}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/subscriptions/test", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("DryRunSubscription", mock.Anything, "ns1", mock.AnythingOfType("*fftypes.SubscriptionDryRun")).
+		Return([]*fftypes.SubscriptionDryRunResult{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
+
+func TestPostSubscriptionDryRunCrossNamespaceMainAPI(t *testing.T) {
+	o, r := newTestAPIServer()
+	input := fftypes.SubscriptionDryRun{
+		Filter: fftypes.SubscriptionFilter{
+			Namespaces: []string{fftypes.SubscriptionAllNamespaces},
+		},
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/api/v1/namespaces/ns1/subscriptions/test", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+	o.AssertNotCalled(t, "DryRunSubscription", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPostSubscriptionDryRunCrossNamespaceAdminAPI(t *testing.T) {
+	o, r := newTestAdminServer()
+	input := fftypes.SubscriptionDryRun{
+		Filter: fftypes.SubscriptionFilter{
+			Namespaces: []string{fftypes.SubscriptionAllNamespaces},
+		},
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("POST", "/admin/api/v1/namespaces/ns1/subscriptions/test", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	o.On("DryRunSubscription", mock.Anything, "ns1", mock.AnythingOfType("*fftypes.SubscriptionDryRun")).
+		Return([]*fftypes.SubscriptionDryRunResult{}, nil)
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Result().StatusCode)
+}
@@ -40,7 +40,11 @@ var putSubscription = &oapispec.Route{
 	JSONOutputCodes: []int{http.StatusOK}, // Sync operation
 	JSONInputSchema: newSubscriptionSchemaGenerator,
 	JSONHandler: func(r *oapispec.APIRequest) (output interface{}, err error) {
-		output, err = getOr(r.Ctx).CreateUpdateSubscription(r.Ctx, r.PP["ns"], r.Input.(*fftypes.Subscription))
+		sub := r.Input.(*fftypes.Subscription)
+		if err = checkSubscriptionNamespaces(r, &sub.Filter); err != nil {
+			return nil, err
+		}
+		output, err = getOr(r.Ctx).CreateUpdateSubscription(r.Ctx, r.PP["ns"], sub)
 		return output, err
 	},
 }
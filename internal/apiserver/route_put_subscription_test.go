@@ -42,3 +42,22 @@ func TestPutSubscription(t *testing.T) {
 
 	assert.Equal(t, 200, res.Result().StatusCode)
 }
+
+func TestPutSubscriptionCrossNamespaceMainAPI(t *testing.T) {
+	o, r := newTestAPIServer()
+	input := fftypes.Subscription{
+		Filter: fftypes.SubscriptionFilter{
+			Namespaces: []string{"ns1", "ns2"},
+		},
+	}
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(&input)
+	req := httptest.NewRequest("PUT", "/api/v1/namespaces/ns1/subscriptions", &buf)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	res := httptest.NewRecorder()
+
+	r.ServeHTTP(res, req)
+
+	assert.Equal(t, 403, res.Result().StatusCode)
+	o.AssertNotCalled(t, "CreateUpdateSubscription", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -21,12 +21,20 @@ import "github.com/hyperledger/firefly/internal/oapispec"
 const emptyObjectSchema = `{"type": "object"}`
 
 var routes = []*oapispec.Route{
+	deleteAlias,
 	deleteContractListener,
+	deleteMessageTemplate,
+	deleteSavedQuery,
 	deleteSubscription,
+	getAliasByName,
+	getAliases,
+	getApprovals,
 	getBatchByID,
+	getBatchManifest,
 	getBatches,
 	getBlockchainEventByID,
 	getBlockchainEvents,
+	getChanges,
 	getChartHistogram,
 	getContractAPIByName,
 	getContractAPIs,
@@ -41,30 +49,54 @@ var routes = []*oapispec.Route{
 	getDataMsgs,
 	getDatatypeByName,
 	getDatatypes,
+	getEventStream, // ahead of getEventByID, which would otherwise match events/stream
 	getEventByID,
 	getEvents,
+	getGovernanceProposalByID,
+	getGovernanceProposals,
+	getGovernanceVotes,
 	getGroupByHash,
 	getGroups,
 	getIdentities,
 	getIdentityByID,
 	getIdentityDID,
 	getIdentityVerifiers,
+	getLegalHoldByID,
+	getLegalHolds,
+	getMessageTemplateByName,
+	getMessageTemplates,
+	getMsgAnonymousAuthor,
+	getMsgApprovals,
 	getMsgByID,
 	getMsgData,
 	getMsgEvents,
 	getMsgs,
+	getMsgStates,
 	getMsgTxn,
 	getNamespace,
 	getNamespaces,
+	getNetworkInvitation,
+	getNetworkInvitations,
 	getNetworkNode,
 	getNetworkNodes,
 	getNetworkOrg,
+	getNetworkOrgEndorsements,
 	getNetworkOrgs,
+	getNetworkPing,
+	getNetworkPings,
 	getOpByID,
 	getOps,
+	getReportByID,
+	getReportDownload,
+	getReports,
+	getSavedQueries,
+	getSavedQueryByName,
+	getSavedQueryResults,
+	getSerializationSchema,
 	getStatus,
 	getStatusBatchManager,
 	getStatusPins,
+	getStatusSummary,
 	getSubscriptionByID,
 	getSubscriptions,
 	getTokenAccountPools,
@@ -72,18 +104,29 @@ var routes = []*oapispec.Route{
 	getTokenApprovals,
 	getTokenBalances,
 	getTokenConnectors,
+	getTokenMetadata,
+	getTokenMetadataList,
 	getTokenPoolByNameOrID,
 	getTokenPools,
+	getTokenSwapByID,
+	getTokenSwaps,
 	getTokenTransferByID,
 	getTokenTransfers,
+	getTopicSchema,
+	getTopicSchemas,
 	getTxnBlockchainEvents,
+	getTxnBlockchainTxns,
 	getTxnByID,
 	getTxnOps,
 	getTxns,
 	getTxnStatus,
 	getVerifierByID,
 	getVerifiers,
+	patchDataLabels,
+	patchMsgLabels,
 	patchUpdateIdentity,
+	postAlias,
+	postBatchVerify,
 	postContractAPIInvoke,
 	postContractAPIQuery,
 	postContractInterfaceGenerate,
@@ -91,26 +134,52 @@ var routes = []*oapispec.Route{
 	postContractInterfaceQuery,
 	postContractInvoke,
 	postContractQuery,
+	postCustomEndpoint,
 	postData,
+	postDataByIDs,
+	postDataEDI,
+	postEmailReply,
+	postEventsByIDs,
+	postGovernanceProposal,
+	postGovernanceVote,
+	postGroupPreview,
+	postInvitationRedeem,
+	postLegalHold,
+	postLegalHoldRelease,
+	postMessageTemplate,
+	postMsgApproval,
+	postMsgsByIDs,
+	postNetworkOrgEndorse,
+	postNetworkOrgPing,
 	postNewContractAPI,
 	postNewContractInterface,
 	postNewContractListener,
 	postNewDatatype,
+	postNewDatatypeBundle,
 	postNewIdentity,
+	postNewInvitation,
 	postNewMessageBroadcast,
+	postNewMessageLocal,
 	postNewMessagePrivate,
 	postNewMessageRequestReply,
+	postNewMessageRequestReplyBroadcast,
+	postNewMessageValidate,
 	postNewNamespace,
 	postNewOrganization,
 	postNewOrganizationSelf,
 	postNewSubscription,
 	postNodesSelf,
 	postOpRetry,
+	postSavedQuery,
+	postSubscriptionDryRun,
 	postTokenApproval,
 	postTokenBurn,
 	postTokenMint,
 	postTokenPool,
+	postTokenSwap,
 	postTokenTransfer,
+	postTxnsByIDs,
 	putContractAPI,
 	putSubscription,
+	putTokenMetadata,
 }
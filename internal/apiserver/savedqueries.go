@@ -0,0 +1,101 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/oapispec"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+type savedQueryCollection struct {
+	factory database.QueryFactory
+	get     func(r *oapispec.APIRequest, ns string, filter database.AndFilter) (interface{}, *database.FilterResult, error)
+}
+
+// savedQueryCollections are the collections that saved queries can be run against, using the same
+// filter fields as the REST API for listing each collection
+var savedQueryCollections = map[string]*savedQueryCollection{
+	"batches": {database.BatchQueryFactory, func(r *oapispec.APIRequest, ns string, filter database.AndFilter) (interface{}, *database.FilterResult, error) {
+		items, res, err := getOr(r.Ctx).GetBatches(r.Ctx, ns, filter)
+		return items, res, err
+	}},
+	"blockchainevents": {database.BlockchainEventQueryFactory, func(r *oapispec.APIRequest, ns string, filter database.AndFilter) (interface{}, *database.FilterResult, error) {
+		items, res, err := getOr(r.Ctx).GetBlockchainEvents(r.Ctx, ns, filter)
+		return items, res, err
+	}},
+	"data": {database.DataQueryFactory, func(r *oapispec.APIRequest, ns string, filter database.AndFilter) (interface{}, *database.FilterResult, error) {
+		items, res, err := getOr(r.Ctx).GetData(r.Ctx, ns, filter)
+		return items, res, err
+	}},
+	"events": {database.EventQueryFactory, func(r *oapispec.APIRequest, ns string, filter database.AndFilter) (interface{}, *database.FilterResult, error) {
+		items, res, err := getOr(r.Ctx).GetEvents(r.Ctx, ns, filter)
+		return items, res, err
+	}},
+	"messages": {database.MessageQueryFactory, func(r *oapispec.APIRequest, ns string, filter database.AndFilter) (interface{}, *database.FilterResult, error) {
+		items, res, err := getOr(r.Ctx).GetMessages(r.Ctx, ns, filter)
+		return items, res, err
+	}},
+	"operations": {database.OperationQueryFactory, func(r *oapispec.APIRequest, ns string, filter database.AndFilter) (interface{}, *database.FilterResult, error) {
+		items, res, err := getOr(r.Ctx).GetOperations(r.Ctx, ns, filter)
+		return items, res, err
+	}},
+	"tokentransfers": {database.TokenTransferQueryFactory, func(r *oapispec.APIRequest, ns string, filter database.AndFilter) (interface{}, *database.FilterResult, error) {
+		items, res, err := getOr(r.Ctx).Assets().GetTokenTransfers(r.Ctx, ns, filter)
+		return items, res, err
+	}},
+	"transactions": {database.TransactionQueryFactory, func(r *oapispec.APIRequest, ns string, filter database.AndFilter) (interface{}, *database.FilterResult, error) {
+		items, res, err := getOr(r.Ctx).GetTransactions(r.Ctx, ns, filter)
+		return items, res, err
+	}},
+}
+
+// savedQueryFilter resolves the collection of a saved query, and builds its filter. The filter is built
+// each time the query is run, so that relative times in the filter are evaluated at that point.
+// Any of the supplied overrides (such as "skip" and "limit" for paging) replace those in the saved filter.
+func savedQueryFilter(r *oapispec.APIRequest, query *fftypes.SavedQuery, overrides map[string]string) (*savedQueryCollection, database.AndFilter, error) {
+	collection, ok := savedQueryCollections[query.Collection]
+	if !ok {
+		names := make([]string, 0, len(savedQueryCollections))
+		for name := range savedQueryCollections {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, nil, i18n.NewError(r.Ctx, i18n.MsgSavedQueryCollectionUnknown, query.Collection, strings.Join(names, ", "))
+	}
+	values, err := url.ParseQuery(query.Filter)
+	if err != nil {
+		return nil, nil, i18n.WrapError(r.Ctx, err, i18n.MsgSavedQueryFilterInvalid, err)
+	}
+	for k, v := range overrides {
+		values.Set(k, v)
+	}
+	filter, err := r.FilterFromQuery(values, collection.factory)
+	if err == nil {
+		// Check the values in the filter are valid for the fields they are used with
+		_, err = filter.Finalize()
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return collection, filter, nil
+}
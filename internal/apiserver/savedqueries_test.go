@@ -0,0 +1,56 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/assetmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSavedQueryCollections(t *testing.T) {
+	o, r := newTestAPIServer()
+	mam := &assetmocks.Manager{}
+	o.On("Assets").Return(mam)
+	o.On("GetBatches", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.BatchPersisted{}, nil, nil)
+	o.On("GetBlockchainEvents", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.BlockchainEvent{}, nil, nil)
+	o.On("GetData", mock.Anything, "ns1", mock.Anything).Return(fftypes.DataArray{}, nil, nil)
+	o.On("GetEvents", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	o.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+	o.On("GetOperations", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	mam.On("GetTokenTransfers", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	o.On("GetTransactions", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.Transaction{}, nil, nil)
+
+	for collection := range savedQueryCollections {
+		o.On("GetSavedQueryByName", mock.Anything, "ns1", collection).Return(&fftypes.SavedQuery{
+			Name:       collection,
+			Collection: collection,
+			Filter:     "created=<now-1h",
+		}, nil)
+		req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/savedqueries/"+collection+"/results", nil)
+		res := httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		assert.Equal(t, 200, res.Result().StatusCode, collection)
+	}
+
+	o.AssertExpectations(t)
+	mam.AssertExpectations(t)
+}
@@ -18,12 +18,15 @@ package apiserver
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"reflect"
 	"regexp"
 	"strings"
@@ -71,7 +74,9 @@ type apiServer struct {
 	apiTimeout         time.Duration
 	apiMaxTimeout      time.Duration
 	metricsEnabled     bool
+	adminExclusive     bool
 	ffiSwaggerGen      oapiffi.FFISwaggerGen
+	routeLimits        map[string]*routeLimit
 }
 
 func InitConfig() {
@@ -89,6 +94,7 @@ func NewAPIServer() Server {
 		apiTimeout:         config.GetDuration(config.APIRequestTimeout),
 		apiMaxTimeout:      config.GetDuration(config.APIRequestMaxTimeout),
 		metricsEnabled:     config.GetBool(config.MetricsEnabled),
+		adminExclusive:     config.GetBool(config.AdminEnabled) && config.GetBool(config.AdminExclusive),
 		ffiSwaggerGen:      oapiffi.NewFFISwaggerGen(),
 	}
 }
@@ -103,6 +109,10 @@ func (as *apiServer) Serve(ctx context.Context, o orchestrator.Orchestrator) (er
 	adminErrChan := make(chan error)
 	metricsErrChan := make(chan error)
 
+	if err := as.loadRouteLimits(ctx); err != nil {
+		return err
+	}
+
 	if !o.IsPreInit() {
 		apiHTTPServer, err := newHTTPServer(ctx, "api", as.createMuxRouter(ctx, o), httpErrChan, apiConfigPrefix)
 		if err != nil {
@@ -112,7 +122,7 @@ func (as *apiServer) Serve(ctx context.Context, o orchestrator.Orchestrator) (er
 	}
 
 	if config.GetBool(config.AdminEnabled) {
-		adminHTTPServer, err := newHTTPServer(ctx, "admin", as.createAdminMuxRouter(o), adminErrChan, adminConfigPrefix)
+		adminHTTPServer, err := newHTTPServer(ctx, "admin", as.createAdminMuxRouter(ctx, o), adminErrChan, adminConfigPrefix)
 		if err != nil {
 			return err
 		}
@@ -207,9 +217,29 @@ func (as *apiServer) getParams(req *http.Request, route *oapispec.Route) (queryP
 	return queryParams, pathParams
 }
 
-func (as *apiServer) routeHandler(o orchestrator.Orchestrator, apiBaseURL string, route *oapispec.Route) http.HandlerFunc {
+func (as *apiServer) routeHandler(o orchestrator.Orchestrator, apiBaseURL string, admin bool, route *oapispec.Route) http.HandlerFunc {
 	// Check the mandatory parts are ok at startup time
-	return as.apiWrapper(func(res http.ResponseWriter, req *http.Request) (int, error) {
+	wrapper := as.apiWrapper
+	limit := as.routeLimits[route.Name]
+	switch {
+	case route.Streaming:
+		wrapper = as.streamWrapper
+	case limit != nil && limit.timeout > 0:
+		wrapper = func(handler func(res http.ResponseWriter, req *http.Request) (status int, err error)) http.HandlerFunc {
+			return as.handlerWrapper(handler, func(req *http.Request) time.Duration {
+				// The timeout of the route replaces the default, and caps any Request-Timeout header
+				return as.requestTimeout(req, limit.timeout, limit.timeout)
+			})
+		}
+	}
+	return wrapper(func(res http.ResponseWriter, req *http.Request) (int, error) {
+		if limit != nil {
+			release, err := limit.acquire(res, req, route.Name)
+			if err != nil {
+				return http.StatusServiceUnavailable, err
+			}
+			defer release()
+		}
 
 		var jsonInput interface{}
 		if route.JSONInputValue != nil {
@@ -242,7 +272,10 @@ func (as *apiServer) routeHandler(o orchestrator.Orchestrator, apiBaseURL string
 		if err == nil {
 			queryParams, pathParams = as.getParams(req, route)
 			if route.FilterFactory != nil {
-				filter, err = as.buildFilter(req, route.FilterFactory)
+				_ = req.ParseForm()
+				if err = as.resolveFilterAliases(req.Context(), o, pathParams["ns"], req.Form); err == nil {
+					filter, err = as.buildFilter(req, route.FilterFactory)
+				}
 			}
 		}
 
@@ -259,6 +292,10 @@ func (as *apiServer) routeHandler(o orchestrator.Orchestrator, apiBaseURL string
 				SuccessStatus:   http.StatusOK,
 				APIBaseURL:      apiBaseURL,
 				ResponseHeaders: res.Header(),
+				Admin:           admin,
+				FilterFromQuery: func(query url.Values, ff database.QueryFactory) (database.AndFilter, error) {
+					return as.buildFilterFromValues(rCtx, query, ff)
+				},
 			}
 			if len(route.JSONOutputCodes) > 0 {
 				r.SuccessStatus = route.JSONOutputCodes[0]
@@ -282,7 +319,11 @@ func (as *apiServer) routeHandler(o orchestrator.Orchestrator, apiBaseURL string
 			}
 		}
 		if err == nil {
-			status, err = as.handleOutput(req.Context(), res, status, output)
+			if req.Method == http.MethodGet && route.FilterFactory == nil && status == http.StatusOK {
+				status, err = as.handleETagOutput(req, res, status, output)
+			} else {
+				status, err = as.handleOutput(req.Context(), res, status, output)
+			}
 		}
 		return status, err
 	})
@@ -307,9 +348,11 @@ func (as *apiServer) handleOutput(ctx context.Context, res http.ResponseWriter,
 		res.WriteHeader(204)
 	case reader != nil:
 		defer reader.Close()
-		res.Header().Add("Content-Type", "application/octet-stream")
+		if res.Header().Get("Content-Type") == "" {
+			res.Header().Add("Content-Type", "application/octet-stream")
+		}
 		res.WriteHeader(status)
-		_, marshalErr = io.Copy(res, reader)
+		_, marshalErr = io.Copy(newFlushingWriter(res), reader)
 	default:
 		res.Header().Add("Content-Type", "application/json")
 		res.WriteHeader(status)
@@ -323,14 +366,71 @@ func (as *apiServer) handleOutput(ctx context.Context, res http.ResponseWriter,
 	return status, nil
 }
 
+// handleETagOutput serves single object responses with an ETag computed from the JSON content, so a client
+// polling the same object receives a 304 with no body until the object changes
+func (as *apiServer) handleETagOutput(req *http.Request, res http.ResponseWriter, status int, output interface{}) (int, error) {
+	vOutput := reflect.ValueOf(output)
+	if output == nil || (vOutput.Kind() == reflect.Ptr && vOutput.IsNil()) {
+		return as.handleOutput(req.Context(), res, status, output)
+	}
+	if _, isReader := output.(io.ReadCloser); isReader {
+		return as.handleOutput(req.Context(), res, status, output)
+	}
+	b, err := json.Marshal(output)
+	if err != nil {
+		err = i18n.WrapError(req.Context(), err, i18n.MsgResponseMarshalError)
+		log.L(req.Context()).Errorf(err.Error())
+		return 500, err
+	}
+	hash := sha256.Sum256(b)
+	etag := fmt.Sprintf(`"%s"`, hex.EncodeToString(hash[:]))
+	res.Header().Set("ETag", etag)
+	for _, match := range strings.Split(req.Header.Get("If-None-Match"), ",") {
+		match = strings.TrimPrefix(strings.TrimSpace(match), "W/")
+		if match == etag || match == "*" {
+			res.WriteHeader(http.StatusNotModified)
+			return http.StatusNotModified, nil
+		}
+	}
+	res.Header().Add("Content-Type", "application/json")
+	res.WriteHeader(status)
+	_, _ = res.Write(append(b, '\n'))
+	return status, nil
+}
+
+// flushingWriter pushes each chunk of a streamed response to the client as soon as it is copied,
+// so long-lived streams are delivered incrementally rather than when the server buffer fills
+type flushingWriter struct {
+	w io.Writer
+	f http.Flusher
+}
+
+func newFlushingWriter(res http.ResponseWriter) io.Writer {
+	f, ok := res.(http.Flusher)
+	if !ok {
+		return res
+	}
+	return &flushingWriter{w: res, f: f}
+}
+
+func (fw *flushingWriter) Write(b []byte) (int, error) {
+	n, err := fw.w.Write(b)
+	fw.f.Flush()
+	return n, err
+}
+
 func (as *apiServer) getTimeout(req *http.Request) time.Duration {
+	return as.requestTimeout(req, as.apiTimeout, as.apiMaxTimeout)
+}
+
+func (as *apiServer) requestTimeout(req *http.Request, defaultTimeout, maxTimeout time.Duration) time.Duration {
 	// Configure a server-side timeout on each request, to try and avoid cases where the API requester
 	// times out, and we continue to churn indefinitely processing the request.
 	// Long-running processes should be dispatched asynchronously (API returns 202 Accepted asap),
 	// and the caller can either listen on the websocket for updates, or poll the status of the affected object.
 	// This is dependent on the context being passed down through to all blocking operations down the stack
 	// (while avoiding passing the context to asynchronous tasks that are dispatched as a result of the request)
-	reqTimeout := as.apiTimeout
+	reqTimeout := defaultTimeout
 	reqTimeoutHeader := req.Header.Get("Request-Timeout")
 	if reqTimeoutHeader != "" {
 		customTimeout, err := fftypes.ParseDurationString(reqTimeoutHeader, time.Second /* default is seconds */)
@@ -338,8 +438,8 @@ func (as *apiServer) getTimeout(req *http.Request) time.Duration {
 			log.L(req.Context()).Warnf("Invalid Request-Timeout header '%s': %s", reqTimeoutHeader, err)
 		} else {
 			reqTimeout = time.Duration(customTimeout)
-			if reqTimeout > as.apiMaxTimeout {
-				reqTimeout = as.apiMaxTimeout
+			if reqTimeout > maxTimeout {
+				reqTimeout = maxTimeout
 			}
 		}
 	}
@@ -347,10 +447,25 @@ func (as *apiServer) getTimeout(req *http.Request) time.Duration {
 }
 
 func (as *apiServer) apiWrapper(handler func(res http.ResponseWriter, req *http.Request) (status int, err error)) http.HandlerFunc {
+	return as.handlerWrapper(handler, as.getTimeout)
+}
+
+// streamWrapper wraps handlers for long-lived streams, which end when the client disconnects rather
+// than on the API request timeout
+func (as *apiServer) streamWrapper(handler func(res http.ResponseWriter, req *http.Request) (status int, err error)) http.HandlerFunc {
+	return as.handlerWrapper(handler, nil)
+}
+
+func (as *apiServer) handlerWrapper(handler func(res http.ResponseWriter, req *http.Request) (status int, err error), getTimeout func(req *http.Request) time.Duration) http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
 
-		reqTimeout := as.getTimeout(req)
-		ctx, cancel := context.WithTimeout(req.Context(), reqTimeout)
+		var ctx context.Context
+		var cancel func()
+		if getTimeout != nil {
+			ctx, cancel = context.WithTimeout(req.Context(), getTimeout(req))
+		} else {
+			ctx, cancel = context.WithCancel(req.Context())
+		}
 		httpReqID := fftypes.ShortID()
 		ctx = log.WithLogField(ctx, "httpreq", httpReqID)
 		req = req.WithContext(ctx)
@@ -483,6 +598,26 @@ func (as *apiServer) contractSwaggerGenerator(o orchestrator.Orchestrator, apiBa
 	}
 }
 
+func (as *apiServer) ffiSwaggerGenerator(o orchestrator.Orchestrator, apiBaseURL string) func(req *http.Request) (*openapi3.T, error) {
+	return func(req *http.Request) (*openapi3.T, error) {
+		vars := mux.Vars(req)
+		interfaceID, err := fftypes.ParseUUID(req.Context(), vars["interfaceId"])
+		if err != nil {
+			return nil, err
+		}
+		ffi, err := o.Contracts().GetFFIByIDWithChildren(req.Context(), interfaceID)
+		if err != nil {
+			return nil, err
+		} else if ffi == nil || ffi.Namespace != vars["ns"] {
+			return nil, i18n.NewError(req.Context(), i18n.Msg404NoResult)
+		}
+
+		// There is no API to supply a location, so it must be passed in the body of each request
+		baseURL := fmt.Sprintf("%s/namespaces/%s/contracts/interfaces/%s", apiBaseURL, vars["ns"], interfaceID)
+		return as.ffiSwaggerGen.Generate(req.Context(), baseURL, &fftypes.ContractAPI{}, ffi), nil
+	}
+}
+
 func (as *apiServer) createMuxRouter(ctx context.Context, o orchestrator.Orchestrator) *mux.Router {
 	r := mux.NewRouter()
 
@@ -492,9 +627,25 @@ func (as *apiServer) createMuxRouter(ctx context.Context, o orchestrator.Orchest
 
 	publicURL := as.getPublicURL(apiConfigPrefix, "")
 	apiBaseURL := fmt.Sprintf("%s/api/v1", publicURL)
-	for _, route := range routes {
+	apiRoutes := as.apiRoutes()
+
+	// Registered ahead of the API routes, as the UI path would otherwise match contracts/interfaces/{name}/{version}
+	ffiPath := `/api/v1/namespaces/{ns}/contracts/interfaces/{interfaceId:[0-9a-fA-F-]{36}}/api`
+	r.HandleFunc(ffiPath+`/swagger{ext:\.yaml|\.json|}`, as.apiWrapper(as.swaggerHandler(as.ffiSwaggerGenerator(o, apiBaseURL))))
+	r.HandleFunc(ffiPath, func(rw http.ResponseWriter, req *http.Request) {
+		url := req.URL.String() + "/swagger.yaml"
+		handler := as.apiWrapper(as.swaggerUIHandler(url))
+		handler(rw, req)
+	})
+
+	// WebSocket connections to the event stream are upgraded, rather than handled as a route
+	r.HandleFunc("/api/v1/"+getEventStream.Path, as.streamWrapper(as.eventStreamWebSocket(o))).
+		Methods(http.MethodGet).
+		HeadersRegexp("Upgrade", "(?i)websocket")
+
+	for _, route := range apiRoutes {
 		if route.JSONHandler != nil {
-			r.HandleFunc(fmt.Sprintf("/api/v1/%s", route.Path), as.routeHandler(o, apiBaseURL, route)).
+			r.HandleFunc(fmt.Sprintf("/api/v1/%s", route.Path), as.routeHandler(o, apiBaseURL, false, route)).
 				Methods(route.Method)
 		}
 	}
@@ -506,7 +657,7 @@ func (as *apiServer) createMuxRouter(ctx context.Context, o orchestrator.Orchest
 		handler(rw, req)
 	})
 
-	r.HandleFunc(`/api/swagger{ext:\.yaml|\.json|}`, as.apiWrapper(as.swaggerHandler(as.swaggerGenerator(routes, apiBaseURL))))
+	r.HandleFunc(`/api/swagger{ext:\.yaml|\.json|}`, as.apiWrapper(as.swaggerHandler(as.swaggerGenerator(apiRoutes, apiBaseURL))))
 	r.HandleFunc(`/api`, as.apiWrapper(as.swaggerUIHandler(publicURL+"/api/swagger.yaml")))
 	r.HandleFunc(`/favicon{any:.*}.png`, favIcons)
 
@@ -522,7 +673,7 @@ func (as *apiServer) createMuxRouter(ctx context.Context, o orchestrator.Orchest
 	return r
 }
 
-func (as *apiServer) createAdminMuxRouter(o orchestrator.Orchestrator) *mux.Router {
+func (as *apiServer) createAdminMuxRouter(ctx context.Context, o orchestrator.Orchestrator) *mux.Router {
 	r := mux.NewRouter()
 	if as.metricsEnabled {
 		r.Use(metrics.GetAdminServerInstrumentation().Middleware)
@@ -530,15 +681,17 @@ func (as *apiServer) createAdminMuxRouter(o orchestrator.Orchestrator) *mux.Rout
 
 	publicURL := as.getPublicURL(adminConfigPrefix, "admin")
 	apiBaseURL := fmt.Sprintf("%s/admin/api/v1", publicURL)
-	for _, route := range adminRoutes {
+	adminAPIRoutes := as.adminAPIRoutes()
+	for _, route := range adminAPIRoutes {
 		if route.JSONHandler != nil {
-			r.HandleFunc(fmt.Sprintf("/admin/api/v1/%s", route.Path), as.routeHandler(o, apiBaseURL, route)).
+			r.HandleFunc(fmt.Sprintf("/admin/api/v1/%s", route.Path), as.routeHandler(o, apiBaseURL, true, route)).
 				Methods(route.Method)
 		}
 	}
-	r.HandleFunc(`/admin/api/swagger{ext:\.yaml|\.json|}`, as.apiWrapper(as.swaggerHandler(as.swaggerGenerator(adminRoutes, apiBaseURL))))
+	r.HandleFunc(`/admin/api/swagger{ext:\.yaml|\.json|}`, as.apiWrapper(as.swaggerHandler(as.swaggerGenerator(adminAPIRoutes, apiBaseURL))))
 	r.HandleFunc(`/admin/api`, as.apiWrapper(as.swaggerUIHandler(publicURL+"/api/swagger.yaml")))
 	r.HandleFunc(`/favicon{any:.*}.png`, favIcons)
+	as.addDebugRoutes(ctx, r, o)
 
 	return r
 }
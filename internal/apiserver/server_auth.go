@@ -0,0 +1,99 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const (
+	authTypeNone   = "none"
+	authTypeBasic  = "basic"
+	authTypeBearer = "bearer"
+)
+
+// wrapAuthIfEnabled applies the authentication policy configured for an individual listener,
+// so that the admin listener can be protected differently to the main API listener
+func wrapAuthIfEnabled(ctx context.Context, name string, conf config.Prefix, chain http.Handler) (http.Handler, error) {
+	authType := strings.ToLower(conf.GetString(HTTPConfAuthType))
+	var check func(req *http.Request) bool
+	switch authType {
+	case "", authTypeNone:
+		return chain, nil
+	case authTypeBasic:
+		username := conf.GetString(HTTPConfAuthBasicUsername)
+		password := conf.GetString(HTTPConfAuthBasicPassword)
+		if username == "" || password == "" {
+			return nil, i18n.NewError(ctx, i18n.MsgAuthCredentialsMissing, authType, name)
+		}
+		check = func(req *http.Request) bool {
+			u, p, ok := req.BasicAuth()
+			return ok && secureCompare(u, username) && secureCompare(p, password)
+		}
+	case authTypeBearer:
+		tokens := conf.GetStringSlice(HTTPConfAuthBearerTokens)
+		if len(tokens) == 0 {
+			return nil, i18n.NewError(ctx, i18n.MsgAuthCredentialsMissing, authType, name)
+		}
+		check = func(req *http.Request) bool {
+			authHeader := req.Header.Get("Authorization")
+			if len(authHeader) < 7 || !strings.EqualFold(authHeader[0:7], "bearer ") {
+				return false
+			}
+			supplied := strings.TrimSpace(authHeader[7:])
+			matched := false
+			for _, t := range tokens {
+				// Check all tokens, to avoid leaking timing information
+				if secureCompare(supplied, t) {
+					matched = true
+				}
+			}
+			return matched
+		}
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgUnknownAuthType, authType, name)
+	}
+	log.L(ctx).Infof("%s listener requires %s authentication", name, authType)
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if !check(req) {
+			log.L(req.Context()).Warnf("Unauthorized %s %s on %s listener", req.Method, req.URL.Path, name)
+			if authType == authTypeBasic {
+				res.Header().Set("WWW-Authenticate", `Basic realm="firefly"`)
+			}
+			res.Header().Set("Content-Type", "application/json")
+			res.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(res).Encode(&fftypes.RESTError{
+				Error: i18n.NewError(req.Context(), i18n.MsgUnauthorized).Error(),
+			})
+			return
+		}
+		chain.ServeHTTP(res, req)
+	}), nil
+}
+
+func secureCompare(supplied, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(supplied), []byte(expected)) == 1
+}
@@ -0,0 +1,126 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAuthConf(authType string) config.Prefix {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfAuthType, authType)
+	return cp
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(200)
+	})
+}
+
+func TestAuthNone(t *testing.T) {
+	cp := newTestAuthConf("none")
+	h, err := wrapAuthIfEnabled(context.Background(), "ut", cp, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, h)
+}
+
+func TestAuthUnknownType(t *testing.T) {
+	cp := newTestAuthConf("magic")
+	_, err := wrapAuthIfEnabled(context.Background(), "ut", cp, nil)
+	assert.Regexp(t, "FF10377", err)
+}
+
+func TestAuthBasicMissingCreds(t *testing.T) {
+	cp := newTestAuthConf("basic")
+	_, err := wrapAuthIfEnabled(context.Background(), "ut", cp, nil)
+	assert.Regexp(t, "FF10378", err)
+}
+
+func TestAuthBearerMissingTokens(t *testing.T) {
+	cp := newTestAuthConf("bearer")
+	_, err := wrapAuthIfEnabled(context.Background(), "ut", cp, nil)
+	assert.Regexp(t, "FF10378", err)
+}
+
+func TestAuthBasic(t *testing.T) {
+	cp := newTestAuthConf("basic")
+	cp.Set(HTTPConfAuthBasicUsername, "admin")
+	cp.Set(HTTPConfAuthBasicPassword, "s3cret")
+	h, err := wrapAuthIfEnabled(context.Background(), "ut", cp, okHandler())
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Code)
+	assert.Regexp(t, "Basic", res.Header().Get("WWW-Authenticate"))
+	assert.Regexp(t, "FF10376", res.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.SetBasicAuth("admin", "wrong")
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.SetBasicAuth("admin", "s3cret")
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Code)
+}
+
+func TestAuthBearer(t *testing.T) {
+	cp := newTestAuthConf("bearer")
+	cp.Set(HTTPConfAuthBearerTokens, []string{"token1", "token2"})
+	h, err := wrapAuthIfEnabled(context.Background(), "ut", cp, okHandler())
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Basic abc")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Code)
+	assert.Empty(t, res.Header().Get("WWW-Authenticate"))
+
+	req = httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "Bearer token3")
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	assert.Equal(t, 401, res.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Authorization", "bearer token2")
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Code)
+}
+
+func TestHTTPServerAuthConfigError(t *testing.T) {
+	cp := newTestAuthConf("basic")
+	_, err := newHTTPServer(context.Background(), "ut", mux.NewRouter(), make(chan error), cp)
+	assert.Regexp(t, "FF10378", err)
+}
@@ -0,0 +1,204 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+func wrapCompressionIfEnabled(ctx context.Context, conf config.Prefix, chain http.Handler) http.Handler {
+	if !conf.GetBool(HTTPConfCompressionEnabled) {
+		return chain
+	}
+	minSize := int(conf.GetByteSize(HTTPConfCompressionMinSize))
+	level := conf.GetInt(HTTPConfCompressionLevel)
+	log.L(ctx).Debugf("Compression minSize=%d level=%d", minSize, level)
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		encoding := selectEncoding(req)
+		if encoding == "" {
+			chain.ServeHTTP(res, req)
+			return
+		}
+		cw := &compressingResponseWriter{
+			ResponseWriter: res,
+			encoding:       encoding,
+			minSize:        minSize,
+			level:          level,
+			status:         http.StatusOK,
+		}
+		defer cw.close()
+		chain.ServeHTTP(cw, req)
+	})
+}
+
+// selectEncoding chooses gzip in preference to deflate, for GET requests where the
+// client has advertised support. Websocket upgrades are never compressed.
+func selectEncoding(req *http.Request) string {
+	if req.Method != http.MethodGet || strings.EqualFold(req.Header.Get("Upgrade"), "websocket") {
+		return ""
+	}
+	accepted := map[string]bool{}
+	for _, ae := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		parts := strings.Split(ae, ";")
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		disabled := false
+		for _, p := range parts[1:] {
+			if strings.ReplaceAll(strings.TrimSpace(p), " ", "") == "q=0" {
+				disabled = true
+			}
+		}
+		accepted[name] = !disabled
+	}
+	switch {
+	case accepted[encodingGzip]:
+		return encodingGzip
+	case accepted[encodingDeflate]:
+		return encodingDeflate
+	default:
+		return ""
+	}
+}
+
+// compressingResponseWriter buffers the start of the response, and only switches to
+// compressing once the response is known to exceed the minimum size. Small responses
+// are passed through untouched, as compressing them costs more than it saves.
+type compressingResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	minSize     int
+	level       int
+	status      int
+	wroteHeader bool
+	decided     bool
+	buffer      []byte
+	compressor  io.WriteCloser
+}
+
+func (cw *compressingResponseWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.passthrough()
+	}
+}
+
+func (cw *compressingResponseWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.decided {
+		cw.buffer = append(cw.buffer, b...)
+		if len(cw.buffer) < cw.minSize {
+			return len(b), nil
+		}
+		if err := cw.startCompression(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if cw.compressor != nil {
+		return cw.compressor.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+func (cw *compressingResponseWriter) passthrough() {
+	cw.decided = true
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+func (cw *compressingResponseWriter) startCompression() (err error) {
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" {
+		// Handler has already encoded the content
+		cw.passthrough()
+		_, err = cw.ResponseWriter.Write(cw.flushBuffer())
+		return err
+	}
+	cw.decided = true
+	h.Set("Content-Encoding", cw.encoding)
+	h.Add("Vary", "Accept-Encoding")
+	h.Del("Content-Length")
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if cw.encoding == encodingGzip {
+		var gw *gzip.Writer
+		if gw, err = gzip.NewWriterLevel(cw.ResponseWriter, cw.level); err == nil {
+			cw.compressor = gw
+		}
+	} else {
+		var fw *flate.Writer
+		if fw, err = flate.NewWriter(cw.ResponseWriter, cw.level); err == nil {
+			cw.compressor = fw
+		}
+	}
+	if err != nil {
+		return err
+	}
+	_, err = cw.compressor.Write(cw.flushBuffer())
+	return err
+}
+
+func (cw *compressingResponseWriter) flushBuffer() []byte {
+	b := cw.buffer
+	cw.buffer = nil
+	return b
+}
+
+func (cw *compressingResponseWriter) Flush() {
+	if !cw.decided {
+		// Streaming responses are sent as-is from the point they flush
+		cw.passthrough()
+		_, _ = cw.ResponseWriter.Write(cw.flushBuffer())
+	}
+	if f, ok := cw.compressor.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressingResponseWriter) close() {
+	if !cw.decided {
+		if !cw.wroteHeader {
+			// Nothing was written by the handler
+			return
+		}
+		cw.passthrough()
+		_, _ = cw.ResponseWriter.Write(cw.flushBuffer())
+		return
+	}
+	if cw.compressor != nil {
+		_ = cw.compressor.Close()
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCompressionHandler(body string) (http.Handler, config.Prefix) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfCompressionEnabled, true)
+	cp.Set(HTTPConfCompressionMinSize, "10")
+	return wrapCompressionIfEnabled(context.Background(), cp, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Type", "text/plain")
+		res.WriteHeader(200)
+		for _, c := range body {
+			_, _ = res.Write([]byte{byte(c)})
+		}
+	})), cp
+}
+
+func TestCompressionDisabled(t *testing.T) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	assert.Nil(t, wrapCompressionIfEnabled(context.Background(), cp, nil))
+}
+
+func TestCompressionGzipLargeBody(t *testing.T) {
+	body := strings.Repeat("firefly", 100)
+	h, _ := newTestCompressionHandler(body)
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept-Encoding", "deflate, gzip;q=1.0")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "gzip", res.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", res.Header().Get("Vary"))
+	gr, err := gzip.NewReader(res.Body)
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(gr)
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(b))
+}
+
+func TestCompressionDeflateLargeBody(t *testing.T) {
+	body := strings.Repeat("firefly", 100)
+	h, _ := newTestCompressionHandler(body)
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0, deflate")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Equal(t, "deflate", res.Header().Get("Content-Encoding"))
+	b, err := ioutil.ReadAll(flate.NewReader(res.Body))
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(b))
+}
+
+func TestCompressionSmallBodyUncompressed(t *testing.T) {
+	h, _ := newTestCompressionHandler("tiny")
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Empty(t, res.Header().Get("Content-Encoding"))
+	assert.Equal(t, "tiny", res.Body.String())
+}
+
+func TestCompressionSkippedForPost(t *testing.T) {
+	body := strings.Repeat("firefly", 100)
+	h, _ := newTestCompressionHandler(body)
+	req := httptest.NewRequest(http.MethodPost, "/test", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	assert.Empty(t, res.Header().Get("Content-Encoding"))
+	assert.Equal(t, body, res.Body.String())
+}
+
+func TestCompressionSkippedNotAccepted(t *testing.T) {
+	body := strings.Repeat("firefly", 100)
+	h, _ := newTestCompressionHandler(body)
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	assert.Empty(t, res.Header().Get("Content-Encoding"))
+	assert.Equal(t, body, res.Body.String())
+}
+
+func TestCompressionSkippedWebsocket(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Upgrade", "websocket")
+	assert.Empty(t, selectEncoding(req))
+}
+
+func TestCompressionAlreadyEncoded(t *testing.T) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfCompressionEnabled, true)
+	cp.Set(HTTPConfCompressionMinSize, "1")
+	h := wrapCompressionIfEnabled(context.Background(), cp, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.Header().Set("Content-Encoding", "br")
+		_, _ = res.Write([]byte("already"))
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, "br", res.Header().Get("Content-Encoding"))
+	assert.Equal(t, "already", res.Body.String())
+}
+
+func TestCompressionNoContent(t *testing.T) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfCompressionEnabled, true)
+	h := wrapCompressionIfEnabled(context.Background(), cp, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(204)
+		res.WriteHeader(500) // ignored
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 204, res.Code)
+	assert.Empty(t, res.Header().Get("Content-Encoding"))
+}
+
+func TestCompressionNothingWritten(t *testing.T) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfCompressionEnabled, true)
+	h := wrapCompressionIfEnabled(context.Background(), cp, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, 200, res.Code)
+	assert.Empty(t, res.Body.String())
+}
+
+func TestCompressionFlushStreams(t *testing.T) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfCompressionEnabled, true)
+	cp.Set(HTTPConfCompressionMinSize, "10")
+	h := wrapCompressionIfEnabled(context.Background(), cp, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		_, _ = res.Write([]byte("part1"))
+		res.(http.Flusher).Flush()
+		_, _ = res.Write([]byte("part2-longer-than-min-size"))
+		res.(http.Flusher).Flush()
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	assert.True(t, res.Flushed)
+	assert.Empty(t, res.Header().Get("Content-Encoding"))
+	assert.Equal(t, "part1part2-longer-than-min-size", res.Body.String())
+}
+
+func TestCompressionFlushAfterCompressing(t *testing.T) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfCompressionEnabled, true)
+	cp.Set(HTTPConfCompressionMinSize, "1")
+	h := wrapCompressionIfEnabled(context.Background(), cp, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		_, _ = res.Write([]byte("part1"))
+		res.(http.Flusher).Flush()
+		_, _ = res.Write([]byte("part2"))
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	assert.Equal(t, "gzip", res.Header().Get("Content-Encoding"))
+	gr, err := gzip.NewReader(res.Body)
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(gr)
+	assert.NoError(t, err)
+	assert.Equal(t, "part1part2", string(b))
+}
+
+func TestCompressionBadLevel(t *testing.T) {
+	config.Reset()
+	cp := config.NewPluginConfig("ut")
+	initHTTPConfPrefx(cp, 0)
+	cp.Set(HTTPConfCompressionEnabled, true)
+	cp.Set(HTTPConfCompressionMinSize, "1")
+	cp.Set(HTTPConfCompressionLevel, 99)
+	var writeErr error
+	h := wrapCompressionIfEnabled(context.Background(), cp, http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		_, writeErr = res.Write([]byte("some data"))
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept-Encoding", "deflate")
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+
+	assert.Error(t, writeErr)
+}
@@ -30,19 +30,23 @@ func wrapCorsIfEnabled(ctx context.Context, chain http.Handler) http.Handler {
 		return chain
 	}
 	corsOptions := cors.Options{
-		AllowedOrigins:   config.GetStringSlice(config.CorsAllowedOrigins),
-		AllowedMethods:   config.GetStringSlice(config.CorsAllowedMethods),
-		AllowedHeaders:   config.GetStringSlice(config.CorsAllowedHeaders),
-		AllowCredentials: config.GetBool(config.CorsAllowCredentials),
-		MaxAge:           config.GetInt(config.CorsMaxAge),
-		Debug:            config.GetBool(config.CorsDebug),
+		AllowedOrigins:     config.GetStringSlice(config.CorsAllowedOrigins),
+		AllowedMethods:     config.GetStringSlice(config.CorsAllowedMethods),
+		AllowedHeaders:     config.GetStringSlice(config.CorsAllowedHeaders),
+		ExposedHeaders:     config.GetStringSlice(config.CorsExposedHeaders),
+		AllowCredentials:   config.GetBool(config.CorsAllowCredentials),
+		MaxAge:             config.GetInt(config.CorsMaxAge),
+		OptionsPassthrough: config.GetBool(config.CorsOptionsPassthrough),
+		Debug:              config.GetBool(config.CorsDebug),
 	}
-	log.L(ctx).Debugf("CORS origins=%v methods=%v headers=%v creds=%t maxAge=%d",
+	log.L(ctx).Debugf("CORS origins=%v methods=%v headers=%v exposed=%v creds=%t maxAge=%d passthrough=%t",
 		corsOptions.AllowedOrigins,
 		corsOptions.AllowedMethods,
 		corsOptions.AllowedHeaders,
+		corsOptions.ExposedHeaders,
 		corsOptions.AllowCredentials,
 		corsOptions.MaxAge,
+		corsOptions.OptionsPassthrough,
 	)
 	c := cors.New(corsOptions)
 	return c.Handler(chain)
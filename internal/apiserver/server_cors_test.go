@@ -18,6 +18,8 @@ package apiserver
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/config"
@@ -29,3 +31,32 @@ func TestServerCorsDisabled(t *testing.T) {
 	config.Set(config.CorsEnabled, false)
 	assert.Nil(t, wrapCorsIfEnabled(context.Background(), nil))
 }
+
+func TestServerCorsExposedHeaders(t *testing.T) {
+	config.Reset()
+	config.Set(config.CorsExposedHeaders, []string{"X-Custom"})
+	handler := wrapCorsIfEnabled(context.Background(), http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(200)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Origin", "http://example.com")
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	assert.Equal(t, "X-Custom", res.Header().Get("Access-Control-Expose-Headers"))
+}
+
+func TestServerCorsOptionsPassthrough(t *testing.T) {
+	config.Reset()
+	config.Set(config.CorsOptionsPassthrough, true)
+	called := false
+	handler := wrapCorsIfEnabled(context.Background(), http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		called = true
+		res.WriteHeader(200)
+	}))
+	req := httptest.NewRequest(http.MethodOptions, "/test", nil)
+	req.Header.Set("Origin", "http://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	assert.True(t, called)
+}
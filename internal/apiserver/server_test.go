@@ -24,6 +24,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -61,7 +62,7 @@ func newTestAPIServer() (*orchestratormocks.Orchestrator, *mux.Router) {
 
 func newTestAdminServer() (*orchestratormocks.Orchestrator, *mux.Router) {
 	mor, as := newTestServer()
-	r := as.createAdminMuxRouter(mor)
+	r := as.createAdminMuxRouter(context.Background(), mor)
 	return mor, r
 }
 
@@ -128,7 +129,7 @@ func TestStartMetricsFail(t *testing.T) {
 
 func TestJSONHTTPServePOST201(t *testing.T) {
 	mo, as := newTestServer()
-	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", &oapispec.Route{
+	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", false, &oapispec.Route{
 		Name:            "testRoute",
 		Path:            "/test",
 		Method:          "POST",
@@ -154,7 +155,7 @@ func TestJSONHTTPServePOST201(t *testing.T) {
 
 func TestJSONHTTPResponseEncodeFail(t *testing.T) {
 	mo, as := newTestServer()
-	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", &oapispec.Route{
+	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", false, &oapispec.Route{
 		Name:            "testRoute",
 		Path:            "/test",
 		Method:          "GET",
@@ -179,7 +180,7 @@ func TestJSONHTTPResponseEncodeFail(t *testing.T) {
 
 func TestJSONHTTPNilResponseNon204(t *testing.T) {
 	mo, as := newTestServer()
-	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", &oapispec.Route{
+	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", false, &oapispec.Route{
 		Name:            "testRoute",
 		Path:            "/test",
 		Method:          "GET",
@@ -204,7 +205,7 @@ func TestJSONHTTPNilResponseNon204(t *testing.T) {
 
 func TestJSONHTTPDefault500Error(t *testing.T) {
 	mo, as := newTestServer()
-	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", &oapispec.Route{
+	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", false, &oapispec.Route{
 		Name:            "testRoute",
 		Path:            "/test",
 		Method:          "GET",
@@ -229,7 +230,7 @@ func TestJSONHTTPDefault500Error(t *testing.T) {
 
 func TestStatusCodeHintMapping(t *testing.T) {
 	mo, as := newTestServer()
-	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", &oapispec.Route{
+	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", false, &oapispec.Route{
 		Name:            "testRoute",
 		Path:            "/test",
 		Method:          "GET",
@@ -254,7 +255,7 @@ func TestStatusCodeHintMapping(t *testing.T) {
 
 func TestStatusInvalidContentType(t *testing.T) {
 	mo, as := newTestServer()
-	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", &oapispec.Route{
+	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", false, &oapispec.Route{
 		Name:            "testRoute",
 		Path:            "/test",
 		Method:          "POST",
@@ -292,7 +293,7 @@ func TestNotFound(t *testing.T) {
 
 func TestTimeout(t *testing.T) {
 	mo, as := newTestServer()
-	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", &oapispec.Route{
+	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", false, &oapispec.Route{
 		Name:            "testRoute",
 		Path:            "/test",
 		Method:          "POST",
@@ -319,7 +320,7 @@ func TestTimeout(t *testing.T) {
 
 func TestBadTimeout(t *testing.T) {
 	mo, as := newTestServer()
-	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", &oapispec.Route{
+	handler := as.routeHandler(mo, "http://localhost:5000/api/v1", false, &oapispec.Route{
 		Name:            "testRoute",
 		Path:            "/test",
 		Method:          "POST",
@@ -502,6 +503,86 @@ func TestContractAPISwaggerJSONGetFFIFail(t *testing.T) {
 	assert.Equal(t, 500, res.StatusCode)
 }
 
+func TestFFISwaggerJSON(t *testing.T) {
+	o, as := newTestServer()
+	r := as.createMuxRouter(context.Background(), o)
+	mcm := &contractmocks.Manager{}
+	o.On("Contracts").Return(mcm)
+	mffi := as.ffiSwaggerGen.(*oapiffimocks.FFISwaggerGen)
+	s := httptest.NewServer(r)
+	defer s.Close()
+
+	ffi := &fftypes.FFI{
+		ID:        fftypes.NewUUID(),
+		Namespace: "default",
+	}
+
+	mcm.On("GetFFIByIDWithChildren", mock.Anything, ffi.ID).Return(ffi, nil)
+	mffi.On("Generate", mock.Anything, "http://127.0.0.1:5000/api/v1/namespaces/default/contracts/interfaces/"+ffi.ID.String(), &fftypes.ContractAPI{}, ffi).Return(&openapi3.T{})
+
+	res, err := http.Get(fmt.Sprintf("http://%s/api/v1/namespaces/default/contracts/interfaces/%s/api/swagger.json", s.Listener.Addr(), ffi.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+}
+
+func TestFFISwaggerJSONBadID(t *testing.T) {
+	o, as := newTestServer()
+	r := as.createMuxRouter(context.Background(), o)
+	s := httptest.NewServer(r)
+	defer s.Close()
+
+	res, err := http.Get(fmt.Sprintf("http://%s/api/v1/namespaces/default/contracts/interfaces/%s/api/swagger.json", s.Listener.Addr(), strings.Repeat("-", 36)))
+	assert.NoError(t, err)
+	assert.Equal(t, 400, res.StatusCode)
+}
+
+func TestFFISwaggerJSONGetFFIFail(t *testing.T) {
+	o, as := newTestServer()
+	r := as.createMuxRouter(context.Background(), o)
+	mcm := &contractmocks.Manager{}
+	o.On("Contracts").Return(mcm)
+	s := httptest.NewServer(r)
+	defer s.Close()
+
+	id := fftypes.NewUUID()
+	mcm.On("GetFFIByIDWithChildren", mock.Anything, id).Return(nil, fmt.Errorf("pop"))
+
+	res, err := http.Get(fmt.Sprintf("http://%s/api/v1/namespaces/default/contracts/interfaces/%s/api/swagger.json", s.Listener.Addr(), id))
+	assert.NoError(t, err)
+	assert.Equal(t, 500, res.StatusCode)
+}
+
+func TestFFISwaggerJSONWrongNamespace(t *testing.T) {
+	o, as := newTestServer()
+	r := as.createMuxRouter(context.Background(), o)
+	mcm := &contractmocks.Manager{}
+	o.On("Contracts").Return(mcm)
+	s := httptest.NewServer(r)
+	defer s.Close()
+
+	ffi := &fftypes.FFI{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns2",
+	}
+	mcm.On("GetFFIByIDWithChildren", mock.Anything, ffi.ID).Return(ffi, nil)
+
+	res, err := http.Get(fmt.Sprintf("http://%s/api/v1/namespaces/default/contracts/interfaces/%s/api/swagger.json", s.Listener.Addr(), ffi.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, 404, res.StatusCode)
+}
+
+func TestFFISwaggerUI(t *testing.T) {
+	_, r := newTestAPIServer()
+	s := httptest.NewServer(r)
+	defer s.Close()
+
+	res, err := http.Get(fmt.Sprintf("http://%s/api/v1/namespaces/default/contracts/interfaces/%s/api", s.Listener.Addr(), fftypes.NewUUID()))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, res.StatusCode)
+	b, _ := ioutil.ReadAll(res.Body)
+	assert.Regexp(t, "html", string(b))
+}
+
 func TestContractAPISwaggerUI(t *testing.T) {
 	_, r := newTestAPIServer()
 	s := httptest.NewServer(r)
@@ -513,3 +594,75 @@ func TestContractAPISwaggerUI(t *testing.T) {
 	b, _ := ioutil.ReadAll(res.Body)
 	assert.Regexp(t, "html", string(b))
 }
+
+func TestFlushingWriterNotFlusher(t *testing.T) {
+	res := struct{ http.ResponseWriter }{httptest.NewRecorder()}
+	assert.Equal(t, res, newFlushingWriter(res))
+}
+
+func TestGetSingleObjectETag(t *testing.T) {
+	o, r := newTestAPIServer()
+	msg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	o.On("GetMessageByID", mock.Anything, "ns1", "msg1").Return(msg, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/messages/msg1", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	etag := res.Result().Header.Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{64}"$`, etag)
+	var output fftypes.Message
+	err := json.NewDecoder(res.Body).Decode(&output)
+	assert.NoError(t, err)
+	assert.Equal(t, msg.Header.ID, output.Header.ID)
+
+	for _, match := range []string{etag, `"other", ` + etag, "W/" + etag, "*"} {
+		req.Header.Set("If-None-Match", match)
+		res = httptest.NewRecorder()
+		r.ServeHTTP(res, req)
+		assert.Equal(t, 304, res.Result().StatusCode)
+		assert.Equal(t, etag, res.Result().Header.Get("ETag"))
+		assert.Empty(t, res.Body.Bytes())
+	}
+
+	// The object has changed
+	msg.Hash = fftypes.NewRandB32()
+	req.Header.Set("If-None-Match", etag)
+	res = httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.NotEqual(t, etag, res.Result().Header.Get("ETag"))
+}
+
+func TestGetCollectionNoETag(t *testing.T) {
+	o, r := newTestAPIServer()
+	o.On("GetMessages", mock.Anything, "ns1", mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/namespaces/ns1/messages", nil)
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	assert.Equal(t, 200, res.Result().StatusCode)
+	assert.Empty(t, res.Result().Header.Get("ETag"))
+}
+
+func TestHandleETagOutputNotJSON(t *testing.T) {
+	_, as := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/anything", nil)
+
+	res := httptest.NewRecorder()
+	status, err := as.handleETagOutput(req, res, 200, ioutil.NopCloser(strings.NewReader("some data")))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "some data", res.Body.String())
+	assert.Empty(t, res.Result().Header.Get("ETag"))
+
+	res = httptest.NewRecorder()
+	status, err = as.handleETagOutput(req, res, 200, (*fftypes.Message)(nil))
+	assert.Regexp(t, "FF10143", err)
+	assert.Equal(t, 404, status)
+
+	res = httptest.NewRecorder()
+	status, err = as.handleETagOutput(req, res, 200, map[bool]bool{true: false})
+	assert.Regexp(t, "FF10107", err)
+	assert.Equal(t, 500, status)
+}
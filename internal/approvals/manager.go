@@ -0,0 +1,153 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approvals
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Manager holds back local messages that are tagged with an approval policy, until the required
+// number of designated approvers have approved them
+type Manager interface {
+	ApplyPolicy(ctx context.Context, msg *fftypes.Message)
+	ApproveMessage(ctx context.Context, ns, id string, input *fftypes.ApprovalInput) (*fftypes.MessageApproval, error)
+}
+
+type approvalManager struct {
+	database database.Plugin
+	policies map[string]*fftypes.ApprovalPolicy
+}
+
+func NewApprovalManager(ctx context.Context, di database.Plugin) (Manager, error) {
+	if di == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	am := &approvalManager{
+		database: di,
+		policies: make(map[string]*fftypes.ApprovalPolicy),
+	}
+	for i, policyObject := range config.GetObjectArray(config.ApprovalsPolicies) {
+		var policy *fftypes.ApprovalPolicy
+		b, _ := json.Marshal(policyObject)
+		if err := json.Unmarshal(b, &policy); err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgApprovalPolicyInvalid, i)
+		}
+		if err := fftypes.ValidateFFNameField(ctx, policy.Name, fmt.Sprintf("approvals.policies[%d].name", i)); err != nil {
+			return nil, err
+		}
+		if policy.Required < 1 || policy.Required > len(policy.Approvers) {
+			return nil, i18n.NewError(ctx, i18n.MsgApprovalPolicyRequired, i, len(policy.Approvers))
+		}
+		if _, exists := am.policies[policy.Name]; exists {
+			log.L(ctx).Warnf("Duplicate approval policy (ignored): %s", policy.Name)
+			continue
+		}
+		am.policies[policy.Name] = policy
+	}
+	return am, nil
+}
+
+// ApplyPolicy is called as a new local message is written, and holds back broadcast and private messages
+// whose tag is the name of an approval policy
+func (am *approvalManager) ApplyPolicy(ctx context.Context, msg *fftypes.Message) {
+	if msg.State != fftypes.MessageStateReady ||
+		(msg.Header.Type != fftypes.MessageTypeBroadcast && msg.Header.Type != fftypes.MessageTypePrivate) {
+		return
+	}
+	if policy, ok := am.policies[msg.Header.Tag]; ok {
+		log.L(ctx).Infof("Message %s requires %d approvals under policy '%s'", msg.Header.ID, policy.Required, policy.Name)
+		msg.State = fftypes.MessageStateAwaitingApproval
+	}
+}
+
+func (am *approvalManager) ApproveMessage(ctx context.Context, ns, id string, input *fftypes.ApprovalInput) (approval *fftypes.MessageApproval, err error) {
+	msgID, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Approver == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgApproverMissing)
+	}
+
+	err = am.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		msg, err := am.database.GetMessageByID(ctx, msgID)
+		if err != nil {
+			return err
+		}
+		if msg == nil || msg.Header.Namespace != ns {
+			return i18n.NewError(ctx, i18n.Msg404NotFound)
+		}
+		if msg.State != fftypes.MessageStateAwaitingApproval {
+			return i18n.NewError(ctx, i18n.MsgMessageNotAwaitingApproval, msgID)
+		}
+		policy, ok := am.policies[msg.Header.Tag]
+		if !ok {
+			return i18n.NewError(ctx, i18n.MsgApprovalPolicyNotFound, msg.Header.Tag)
+		}
+		if !policy.HasApprover(input.Approver) {
+			return i18n.NewError(ctx, i18n.MsgNotApprover, input.Approver, policy.Name)
+		}
+
+		fb := database.MessageApprovalQueryFactory.NewFilter(ctx)
+		existing, _, err := am.database.GetMessageApprovals(ctx, fb.Eq("message", msgID))
+		if err != nil {
+			return err
+		}
+		approvals := 1
+		for _, previous := range existing {
+			if previous.Approver == input.Approver {
+				return i18n.NewError(ctx, i18n.MsgAlreadyApproved, input.Approver, msgID)
+			}
+			// Only count approvers that are still designated by the policy
+			if policy.HasApprover(previous.Approver) {
+				approvals++
+			}
+		}
+
+		approval = &fftypes.MessageApproval{
+			ID:        fftypes.NewUUID(),
+			Namespace: ns,
+			Message:   msgID,
+			Policy:    policy.Name,
+			Approver:  input.Approver,
+			Comment:   input.Comment,
+		}
+		if err := am.database.InsertMessageApproval(ctx, approval); err != nil {
+			return err
+		}
+		log.L(ctx).Infof("Message %s approved by '%s' (%d/%d) under policy '%s'", msgID, input.Approver, approvals, policy.Required, policy.Name)
+
+		if approvals >= policy.Required {
+			// Replacing the message gives it a new sequence, so that the batch manager picks it up to send
+			msg.State = fftypes.MessageStateReady
+			return am.database.ReplaceMessage(ctx, msg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return approval, nil
+}
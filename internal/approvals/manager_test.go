@@ -0,0 +1,256 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approvals
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestApprovalManager(t *testing.T) (*approvalManager, *databasemocks.Plugin) {
+	config.Reset()
+	config.Set(config.ApprovalsPolicies, fftypes.JSONObjectArray{
+		{"name": "payments", "required": 2, "approvers": []interface{}{"alice", "bob", "carol"}},
+		{"name": "payments", "required": 1, "approvers": []interface{}{"dave"}},
+	})
+	mdi := &databasemocks.Plugin{}
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+	am, err := NewApprovalManager(context.Background(), mdi)
+	assert.NoError(t, err)
+	return am.(*approvalManager), mdi
+}
+
+func newTestAwaitingMessage() *fftypes.Message {
+	return &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Type:      fftypes.MessageTypeBroadcast,
+			Tag:       "payments",
+		},
+		State: fftypes.MessageStateAwaitingApproval,
+	}
+}
+
+func TestNewApprovalManagerMissingDeps(t *testing.T) {
+	_, err := NewApprovalManager(context.Background(), nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestNewApprovalManagerBadName(t *testing.T) {
+	config.Reset()
+	config.Set(config.ApprovalsPolicies, fftypes.JSONObjectArray{
+		{"name": "!bad", "required": 1, "approvers": []interface{}{"alice"}},
+	})
+	_, err := NewApprovalManager(context.Background(), &databasemocks.Plugin{})
+	assert.Regexp(t, "FF10131.*approvals.policies\\[0\\].name", err)
+}
+
+func TestNewApprovalManagerBadRequired(t *testing.T) {
+	config.Reset()
+	config.Set(config.ApprovalsPolicies, fftypes.JSONObjectArray{
+		{"name": "payments", "required": 3, "approvers": []interface{}{"alice", "bob"}},
+	})
+	_, err := NewApprovalManager(context.Background(), &databasemocks.Plugin{})
+	assert.Regexp(t, "FF10408", err)
+}
+
+func TestNewApprovalManagerBadPolicy(t *testing.T) {
+	config.Reset()
+	config.Set(config.ApprovalsPolicies, fftypes.JSONObjectArray{
+		{"name": "payments", "required": "two", "approvers": []interface{}{"alice", "bob"}},
+	})
+	_, err := NewApprovalManager(context.Background(), &databasemocks.Plugin{})
+	assert.Regexp(t, "FF10402", err)
+}
+
+func TestNewApprovalManagerDuplicateIgnored(t *testing.T) {
+	am, _ := newTestApprovalManager(t)
+	assert.Len(t, am.policies, 1)
+	assert.Equal(t, 2, am.policies["payments"].Required)
+}
+
+func TestApplyPolicy(t *testing.T) {
+	am, _ := newTestApprovalManager(t)
+
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{Type: fftypes.MessageTypePrivate, Tag: "payments"},
+		State:  fftypes.MessageStateReady,
+	}
+	am.ApplyPolicy(context.Background(), msg)
+	assert.Equal(t, fftypes.MessageStateAwaitingApproval, msg.State)
+
+	msg = &fftypes.Message{
+		Header: fftypes.MessageHeader{Type: fftypes.MessageTypeBroadcast, Tag: "other"},
+		State:  fftypes.MessageStateReady,
+	}
+	am.ApplyPolicy(context.Background(), msg)
+	assert.Equal(t, fftypes.MessageStateReady, msg.State)
+
+	msg = &fftypes.Message{
+		Header: fftypes.MessageHeader{Type: fftypes.MessageTypeDefinition, Tag: "payments"},
+		State:  fftypes.MessageStateReady,
+	}
+	am.ApplyPolicy(context.Background(), msg)
+	assert.Equal(t, fftypes.MessageStateReady, msg.State)
+
+	msg = &fftypes.Message{
+		Header: fftypes.MessageHeader{Type: fftypes.MessageTypeBroadcast, Tag: "payments"},
+		State:  fftypes.MessageStateStaged,
+	}
+	am.ApplyPolicy(context.Background(), msg)
+	assert.Equal(t, fftypes.MessageStateStaged, msg.State)
+}
+
+func TestApproveMessagePending(t *testing.T) {
+	am, mdi := newTestApprovalManager(t)
+
+	msg := newTestAwaitingMessage()
+	mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	mdi.On("GetMessageApprovals", mock.Anything, mock.Anything).Return([]*fftypes.MessageApproval{
+		{Approver: "eve"}, // no longer an approver
+	}, nil, nil)
+	mdi.On("InsertMessageApproval", mock.Anything, mock.MatchedBy(func(a *fftypes.MessageApproval) bool {
+		return a.Message.Equals(msg.Header.ID) && a.Approver == "alice" && a.Policy == "payments" && a.Comment == "ok"
+	})).Return(nil)
+
+	approval, err := am.ApproveMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.ApprovalInput{
+		Approver: "alice",
+		Comment:  "ok",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", approval.Namespace)
+	assert.Equal(t, fftypes.MessageStateAwaitingApproval, msg.State)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestApproveMessageReleased(t *testing.T) {
+	am, mdi := newTestApprovalManager(t)
+
+	msg := newTestAwaitingMessage()
+	mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	mdi.On("GetMessageApprovals", mock.Anything, mock.Anything).Return([]*fftypes.MessageApproval{
+		{Approver: "alice"},
+	}, nil, nil)
+	mdi.On("InsertMessageApproval", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("ReplaceMessage", mock.Anything, mock.MatchedBy(func(m *fftypes.Message) bool {
+		return m.State == fftypes.MessageStateReady
+	})).Return(nil)
+
+	_, err := am.ApproveMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.ApprovalInput{
+		Approver: "bob",
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestApproveMessageBadID(t *testing.T) {
+	am, _ := newTestApprovalManager(t)
+	_, err := am.ApproveMessage(context.Background(), "ns1", "bad", &fftypes.ApprovalInput{Approver: "alice"})
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestApproveMessageNoApprover(t *testing.T) {
+	am, _ := newTestApprovalManager(t)
+	_, err := am.ApproveMessage(context.Background(), "ns1", fftypes.NewUUID().String(), &fftypes.ApprovalInput{})
+	assert.Regexp(t, "FF10407", err)
+}
+
+func TestApproveMessageGetMessageFail(t *testing.T) {
+	am, mdi := newTestApprovalManager(t)
+	mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := am.ApproveMessage(context.Background(), "ns1", fftypes.NewUUID().String(), &fftypes.ApprovalInput{Approver: "alice"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestApproveMessageNotFound(t *testing.T) {
+	am, mdi := newTestApprovalManager(t)
+	msg := newTestAwaitingMessage()
+	mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(msg, nil)
+	_, err := am.ApproveMessage(context.Background(), "ns2", msg.Header.ID.String(), &fftypes.ApprovalInput{Approver: "alice"})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestApproveMessageNotAwaiting(t *testing.T) {
+	am, mdi := newTestApprovalManager(t)
+	msg := newTestAwaitingMessage()
+	msg.State = fftypes.MessageStateSent
+	mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(msg, nil)
+	_, err := am.ApproveMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.ApprovalInput{Approver: "alice"})
+	assert.Regexp(t, "FF10403", err)
+}
+
+func TestApproveMessagePolicyNotFound(t *testing.T) {
+	am, mdi := newTestApprovalManager(t)
+	msg := newTestAwaitingMessage()
+	msg.Header.Tag = "removed"
+	mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(msg, nil)
+	_, err := am.ApproveMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.ApprovalInput{Approver: "alice"})
+	assert.Regexp(t, "FF10404", err)
+}
+
+func TestApproveMessageNotApprover(t *testing.T) {
+	am, mdi := newTestApprovalManager(t)
+	msg := newTestAwaitingMessage()
+	mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(msg, nil)
+	_, err := am.ApproveMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.ApprovalInput{Approver: "eve"})
+	assert.Regexp(t, "FF10405", err)
+}
+
+func TestApproveMessageGetApprovalsFail(t *testing.T) {
+	am, mdi := newTestApprovalManager(t)
+	msg := newTestAwaitingMessage()
+	mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(msg, nil)
+	mdi.On("GetMessageApprovals", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := am.ApproveMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.ApprovalInput{Approver: "alice"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestApproveMessageAlreadyApproved(t *testing.T) {
+	am, mdi := newTestApprovalManager(t)
+	msg := newTestAwaitingMessage()
+	mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(msg, nil)
+	mdi.On("GetMessageApprovals", mock.Anything, mock.Anything).Return([]*fftypes.MessageApproval{
+		{Approver: "alice"},
+	}, nil, nil)
+	_, err := am.ApproveMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.ApprovalInput{Approver: "alice"})
+	assert.Regexp(t, "FF10406", err)
+}
+
+func TestApproveMessageInsertFail(t *testing.T) {
+	am, mdi := newTestApprovalManager(t)
+	msg := newTestAwaitingMessage()
+	mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(msg, nil)
+	mdi.On("GetMessageApprovals", mock.Anything, mock.Anything).Return([]*fftypes.MessageApproval{}, nil, nil)
+	mdi.On("InsertMessageApproval", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := am.ApproveMessage(context.Background(), "ns1", msg.Header.ID.String(), &fftypes.ApprovalInput{Approver: "alice"})
+	assert.EqualError(t, err, "pop")
+}
@@ -47,6 +47,7 @@ type Manager interface {
 	GetTokenBalances(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.TokenBalance, *database.FilterResult, error)
 	GetTokenAccounts(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.TokenAccount, *database.FilterResult, error)
 	GetTokenAccountPools(ctx context.Context, ns, key string, filter database.AndFilter) ([]*fftypes.TokenAccountPool, *database.FilterResult, error)
+	GetCounterpartyKeys(ctx context.Context, ns, counterparty string) ([]string, error)
 
 	GetTokenTransfers(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.TokenTransfer, *database.FilterResult, error)
 	GetTokenTransferByID(ctx context.Context, ns, id string) (*fftypes.TokenTransfer, error)
@@ -62,6 +63,10 @@ type Manager interface {
 	TokenApproval(ctx context.Context, ns string, approval *fftypes.TokenApprovalInput, waitConfirm bool) (*fftypes.TokenApproval, error)
 	GetTokenApprovals(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.TokenApproval, *database.FilterResult, error)
 
+	SetTokenMetadata(ctx context.Context, ns, poolNameOrID, tokenIndex string, input *fftypes.TokenMetadataInput) (*fftypes.TokenMetadata, error)
+	GetTokenMetadataList(ctx context.Context, ns, poolNameOrID string, filter database.AndFilter) ([]*fftypes.TokenMetadata, *database.FilterResult, error)
+	GetTokenMetadataValue(ctx context.Context, ns, poolNameOrID, tokenIndex string) (*fftypes.JSONAny, error)
+
 	// From operations.OperationHandler
 	PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error)
 	RunOperation(ctx context.Context, op *fftypes.PreparedOperation) (complete bool, err error)
@@ -138,6 +143,34 @@ func (am *assetManager) GetTokenAccountPools(ctx context.Context, ns, key string
 	return am.database.GetTokenAccountPools(ctx, key, am.scopeNS(ns, filter))
 }
 
+// GetCounterpartyKeys resolves an identity DID, or an org name, to the keys registered to that identity,
+// so that balances and transfers can be filtered by counterparty
+func (am *assetManager) GetCounterpartyKeys(ctx context.Context, ns, counterparty string) ([]string, error) {
+	identity, retryable, err := am.identity.CachedIdentityLookup(ctx, counterparty)
+	if err != nil {
+		if retryable {
+			return nil, err
+		}
+		return nil, i18n.WrapError(ctx, err, i18n.MsgCounterpartyNotFound, counterparty)
+	}
+	if identity.Type == fftypes.IdentityTypeCustom && identity.Namespace != ns {
+		return nil, i18n.NewError(ctx, i18n.MsgCounterpartyNotFound, counterparty)
+	}
+	fb := database.VerifierQueryFactory.NewFilter(ctx)
+	verifiers, _, err := am.database.GetVerifiers(ctx, fb.And(
+		fb.Eq("identity", identity.ID),
+		fb.Eq("namespace", identity.Namespace),
+	))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(verifiers))
+	for i, v := range verifiers {
+		keys[i] = v.Value
+	}
+	return keys, nil
+}
+
 func (am *assetManager) GetTokenConnectors(ctx context.Context, ns string) ([]*fftypes.TokenConnector, error) {
 	if err := fftypes.ValidateFFNameField(ctx, ns, "namespace"); err != nil {
 		return nil, err
@@ -16,6 +16,7 @@ package assets
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/config"
@@ -139,6 +140,67 @@ func TestGetTokenAccountPools(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetCounterpartyKeys(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	org1 := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Type: fftypes.IdentityTypeOrg, Namespace: fftypes.SystemNamespace}}
+	mim.On("CachedIdentityLookup", context.Background(), "org1").Return(org1, false, nil)
+	mdi.On("GetVerifiers", context.Background(), mock.Anything).Return([]*fftypes.Verifier{
+		{VerifierRef: fftypes.VerifierRef{Value: "0x1"}},
+		{VerifierRef: fftypes.VerifierRef{Value: "0x2"}},
+	}, nil, nil)
+	keys, err := am.GetCounterpartyKeys(context.Background(), "ns1", "org1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0x1", "0x2"}, keys)
+}
+
+func TestGetCounterpartyKeysNotFound(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", context.Background(), "org1").Return(nil, false, fmt.Errorf("pop"))
+	_, err := am.GetCounterpartyKeys(context.Background(), "ns1", "org1")
+	assert.Regexp(t, "FF10430.*pop", err)
+}
+
+func TestGetCounterpartyKeysLookupFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mim := am.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", context.Background(), "org1").Return(nil, true, fmt.Errorf("pop"))
+	_, err := am.GetCounterpartyKeys(context.Background(), "ns1", "org1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetCounterpartyKeysWrongNamespace(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mim := am.identity.(*identitymanagermocks.Manager)
+	custom1 := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Type: fftypes.IdentityTypeCustom, Namespace: "ns2"}}
+	mim.On("CachedIdentityLookup", context.Background(), "did:firefly:ns/ns2/custom1").Return(custom1, false, nil)
+	_, err := am.GetCounterpartyKeys(context.Background(), "ns1", "did:firefly:ns/ns2/custom1")
+	assert.Regexp(t, "FF10430", err)
+}
+
+func TestGetCounterpartyKeysVerifiersFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	mdi := am.database.(*databasemocks.Plugin)
+	mim := am.identity.(*identitymanagermocks.Manager)
+	org1 := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Type: fftypes.IdentityTypeOrg, Namespace: fftypes.SystemNamespace}}
+	mim.On("CachedIdentityLookup", context.Background(), "org1").Return(org1, false, nil)
+	mdi.On("GetVerifiers", context.Background(), mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := am.GetCounterpartyKeys(context.Background(), "ns1", "org1")
+	assert.EqualError(t, err, "pop")
+}
+
 func TestGetTokenConnectors(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
@@ -131,6 +131,9 @@ func (s *approveSender) sendInternal(ctx context.Context, method sendMethod) err
 }
 
 func (am *assetManager) validateApproval(ctx context.Context, ns string, approval *fftypes.TokenApprovalInput) (err error) {
+	if approval.Allowance != nil && approval.Allowance.Int().Sign() < 0 {
+		return i18n.NewError(ctx, i18n.MsgTokenAllowanceNegative)
+	}
 	if approval.Connector == "" {
 		connector, err := am.getTokenConnectorName(ctx, ns)
 		if err != nil {
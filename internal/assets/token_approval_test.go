@@ -202,6 +202,24 @@ func TestApprovalBadConnector(t *testing.T) {
 	assert.Regexp(t, "FF10272", err)
 }
 
+func TestApprovalNegativeAllowance(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+
+	approval := &fftypes.TokenApprovalInput{
+		TokenApproval: fftypes.TokenApproval{
+			Approved:  true,
+			Operator:  "operator",
+			Key:       "key",
+			Allowance: fftypes.NewFFBigInt(-1),
+		},
+		Pool: "pool1",
+	}
+
+	_, err := am.TokenApproval(context.Background(), "ns1", approval, false)
+	assert.Regexp(t, "FF10431", err)
+}
+
 func TestApprovalUnknownPoolSuccess(t *testing.T) {
 	am, cancel := newTestAssets(t)
 	defer cancel()
@@ -0,0 +1,120 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assets
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (am *assetManager) getNonFungiblePool(ctx context.Context, ns, poolNameOrID string) (*fftypes.TokenPool, error) {
+	pool, err := am.GetTokenPoolByNameOrID(ctx, ns, poolNameOrID)
+	if err != nil {
+		return nil, err
+	}
+	if pool.Namespace != ns {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	if pool.Type != fftypes.TokenTypeNonFungible {
+		return nil, i18n.NewError(ctx, i18n.MsgTokenMetadataNotNonFungible)
+	}
+	return pool, nil
+}
+
+// SetTokenMetadata links a token to the data record that holds its metadata document.
+// A token_metadata_updated event is emitted whenever the token is linked to a different data record.
+func (am *assetManager) SetTokenMetadata(ctx context.Context, ns, poolNameOrID, tokenIndex string, input *fftypes.TokenMetadataInput) (*fftypes.TokenMetadata, error) {
+	pool, err := am.getNonFungiblePool(ctx, ns, poolNameOrID)
+	if err != nil {
+		return nil, err
+	}
+	if input.Data == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgTokenMetadataInvalidData, "")
+	}
+	data, err := am.database.GetDataByID(ctx, input.Data, true)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil || data.Namespace != ns || data.Value.IsNil() {
+		return nil, i18n.NewError(ctx, i18n.MsgTokenMetadataInvalidData, input.Data)
+	}
+	if _, ok := data.Value.JSONObjectOk(true); !ok {
+		return nil, i18n.NewError(ctx, i18n.MsgTokenMetadataInvalidData, input.Data)
+	}
+
+	metadata := &fftypes.TokenMetadata{
+		ID:         fftypes.NewUUID(),
+		Namespace:  ns,
+		Pool:       pool.ID,
+		TokenIndex: tokenIndex,
+		Data:       input.Data,
+	}
+	err = am.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		existing, err := am.database.GetTokenMetadata(ctx, pool.ID, tokenIndex)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.Data.Equals(input.Data) {
+			metadata = existing
+			return nil
+		}
+		if err := am.database.UpsertTokenMetadata(ctx, metadata); err != nil {
+			return err
+		}
+		event := fftypes.NewEvent(fftypes.EventTypeTokenMetadataUpdated, ns, metadata.ID, nil, pool.ID.String())
+		return am.database.InsertEvent(ctx, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+func (am *assetManager) GetTokenMetadataList(ctx context.Context, ns, poolNameOrID string, filter database.AndFilter) ([]*fftypes.TokenMetadata, *database.FilterResult, error) {
+	pool, err := am.getNonFungiblePool(ctx, ns, poolNameOrID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return am.database.GetTokenMetadataList(ctx, am.scopeNS(ns, filter).Condition(filter.Builder().Eq("pool", pool.ID)))
+}
+
+// GetTokenMetadataValue returns the metadata document for a token, as the raw JSON of the linked data record.
+// This is the document an ERC-721 tokenURI is expected to resolve to.
+func (am *assetManager) GetTokenMetadataValue(ctx context.Context, ns, poolNameOrID, tokenIndex string) (*fftypes.JSONAny, error) {
+	pool, err := am.getNonFungiblePool(ctx, ns, poolNameOrID)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := am.database.GetTokenMetadata(ctx, pool.ID, tokenIndex)
+	if err != nil {
+		return nil, err
+	}
+	if metadata == nil || metadata.Namespace != ns {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	data, err := am.database.GetDataByID(ctx, metadata.Data, true)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return data.Value, nil
+}
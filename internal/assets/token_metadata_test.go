@@ -0,0 +1,301 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package assets
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestNFTPool(mdi *databasemocks.Plugin) *fftypes.TokenPool {
+	pool := &fftypes.TokenPool{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Name:      "nfts",
+		Type:      fftypes.TokenTypeNonFungible,
+	}
+	mdi.On("GetTokenPool", mock.Anything, "ns1", "nfts").Return(pool, nil)
+	return pool
+}
+
+func TestSetTokenMetadata(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	pool := newTestNFTPool(mdi)
+	dataID := fftypes.NewUUID()
+
+	mdi.On("GetDataByID", context.Background(), dataID, true).Return(&fftypes.Data{
+		ID: dataID, Namespace: "ns1", Value: fftypes.JSONAnyPtr(`{"name":"token1"}`),
+	}, nil)
+	mdi.On("GetTokenMetadata", mock.Anything, pool.ID, "1").Return(nil, nil)
+	mdi.On("UpsertTokenMetadata", mock.Anything, mock.MatchedBy(func(m *fftypes.TokenMetadata) bool {
+		return m.Pool.Equals(pool.ID) && m.TokenIndex == "1" && m.Data.Equals(dataID)
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeTokenMetadataUpdated && e.Topic == pool.ID.String()
+	})).Return(nil)
+
+	metadata, err := am.SetTokenMetadata(context.Background(), "ns1", "nfts", "1", &fftypes.TokenMetadataInput{Data: dataID})
+	assert.NoError(t, err)
+	assert.Equal(t, dataID, metadata.Data)
+	mdi.AssertExpectations(t)
+}
+
+func TestSetTokenMetadataUnchanged(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	pool := newTestNFTPool(mdi)
+	dataID := fftypes.NewUUID()
+	existing := &fftypes.TokenMetadata{ID: fftypes.NewUUID(), Pool: pool.ID, TokenIndex: "1", Data: dataID}
+
+	mdi.On("GetDataByID", context.Background(), dataID, true).Return(&fftypes.Data{
+		ID: dataID, Namespace: "ns1", Value: fftypes.JSONAnyPtr(`{}`),
+	}, nil)
+	mdi.On("GetTokenMetadata", mock.Anything, pool.ID, "1").Return(existing, nil)
+
+	metadata, err := am.SetTokenMetadata(context.Background(), "ns1", "nfts", "1", &fftypes.TokenMetadataInput{Data: dataID})
+	assert.NoError(t, err)
+	assert.Equal(t, existing, metadata)
+	mdi.AssertExpectations(t)
+}
+
+func TestSetTokenMetadataGetExistingFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	pool := newTestNFTPool(mdi)
+	dataID := fftypes.NewUUID()
+
+	mdi.On("GetDataByID", context.Background(), dataID, true).Return(&fftypes.Data{
+		ID: dataID, Namespace: "ns1", Value: fftypes.JSONAnyPtr(`{}`),
+	}, nil)
+	mdi.On("GetTokenMetadata", mock.Anything, pool.ID, "1").Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.SetTokenMetadata(context.Background(), "ns1", "nfts", "1", &fftypes.TokenMetadataInput{Data: dataID})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSetTokenMetadataUpsertFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	pool := newTestNFTPool(mdi)
+	dataID := fftypes.NewUUID()
+
+	mdi.On("GetDataByID", context.Background(), dataID, true).Return(&fftypes.Data{
+		ID: dataID, Namespace: "ns1", Value: fftypes.JSONAnyPtr(`{}`),
+	}, nil)
+	mdi.On("GetTokenMetadata", mock.Anything, pool.ID, "1").Return(&fftypes.TokenMetadata{Data: fftypes.NewUUID()}, nil)
+	mdi.On("UpsertTokenMetadata", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := am.SetTokenMetadata(context.Background(), "ns1", "nfts", "1", &fftypes.TokenMetadataInput{Data: dataID})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSetTokenMetadataBadPool(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetTokenPool", mock.Anything, "ns1", "nfts").Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.SetTokenMetadata(context.Background(), "ns1", "nfts", "1", &fftypes.TokenMetadataInput{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSetTokenMetadataPoolWrongNamespace(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	pool := &fftypes.TokenPool{ID: fftypes.NewUUID(), Namespace: "ns2"}
+	mdi.On("GetTokenPoolByID", mock.Anything, pool.ID).Return(pool, nil)
+
+	_, err := am.SetTokenMetadata(context.Background(), "ns1", pool.ID.String(), "1", &fftypes.TokenMetadataInput{})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestSetTokenMetadataFungiblePool(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetTokenPool", mock.Anything, "ns1", "coins").Return(&fftypes.TokenPool{
+		Namespace: "ns1", Type: fftypes.TokenTypeFungible,
+	}, nil)
+
+	_, err := am.SetTokenMetadata(context.Background(), "ns1", "coins", "1", &fftypes.TokenMetadataInput{})
+	assert.Regexp(t, "FF10433", err)
+}
+
+func TestSetTokenMetadataNoData(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	newTestNFTPool(mdi)
+
+	_, err := am.SetTokenMetadata(context.Background(), "ns1", "nfts", "1", &fftypes.TokenMetadataInput{})
+	assert.Regexp(t, "FF10434", err)
+}
+
+func TestSetTokenMetadataGetDataFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	newTestNFTPool(mdi)
+	dataID := fftypes.NewUUID()
+	mdi.On("GetDataByID", context.Background(), dataID, true).Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.SetTokenMetadata(context.Background(), "ns1", "nfts", "1", &fftypes.TokenMetadataInput{Data: dataID})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSetTokenMetadataDataNotFound(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	newTestNFTPool(mdi)
+	dataID := fftypes.NewUUID()
+	mdi.On("GetDataByID", context.Background(), dataID, true).Return(nil, nil)
+
+	_, err := am.SetTokenMetadata(context.Background(), "ns1", "nfts", "1", &fftypes.TokenMetadataInput{Data: dataID})
+	assert.Regexp(t, "FF10434", err)
+}
+
+func TestSetTokenMetadataDataNotObject(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	newTestNFTPool(mdi)
+	dataID := fftypes.NewUUID()
+	mdi.On("GetDataByID", context.Background(), dataID, true).Return(&fftypes.Data{
+		ID: dataID, Namespace: "ns1", Value: fftypes.JSONAnyPtr(`"just a string"`),
+	}, nil)
+
+	_, err := am.SetTokenMetadata(context.Background(), "ns1", "nfts", "1", &fftypes.TokenMetadataInput{Data: dataID})
+	assert.Regexp(t, "FF10434", err)
+}
+
+func TestGetTokenMetadataList(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	newTestNFTPool(mdi)
+	mdi.On("GetTokenMetadataList", context.Background(), mock.Anything).Return([]*fftypes.TokenMetadata{}, nil, nil)
+
+	fb := database.TokenMetadataQueryFactory.NewFilter(context.Background())
+	_, _, err := am.GetTokenMetadataList(context.Background(), "ns1", "nfts", fb.And())
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+}
+
+func TestGetTokenMetadataListBadPool(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetTokenPool", mock.Anything, "ns1", "nfts").Return(nil, nil)
+
+	fb := database.TokenMetadataQueryFactory.NewFilter(context.Background())
+	_, _, err := am.GetTokenMetadataList(context.Background(), "ns1", "nfts", fb.And())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetTokenMetadataValue(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	pool := newTestNFTPool(mdi)
+	dataID := fftypes.NewUUID()
+	mdi.On("GetTokenMetadata", context.Background(), pool.ID, "1").Return(&fftypes.TokenMetadata{
+		Namespace: "ns1", Data: dataID,
+	}, nil)
+	mdi.On("GetDataByID", context.Background(), dataID, true).Return(&fftypes.Data{
+		Value: fftypes.JSONAnyPtr(`{"name":"token1"}`),
+	}, nil)
+
+	value, err := am.GetTokenMetadataValue(context.Background(), "ns1", "nfts", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"token1"}`, value.String())
+}
+
+func TestGetTokenMetadataValueBadPool(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	mdi.On("GetTokenPool", mock.Anything, "ns1", "nfts").Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.GetTokenMetadataValue(context.Background(), "ns1", "nfts", "1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetTokenMetadataValueGetMetadataFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	pool := newTestNFTPool(mdi)
+	mdi.On("GetTokenMetadata", context.Background(), pool.ID, "1").Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.GetTokenMetadataValue(context.Background(), "ns1", "nfts", "1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetTokenMetadataValueNotLinked(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	pool := newTestNFTPool(mdi)
+	mdi.On("GetTokenMetadata", context.Background(), pool.ID, "1").Return(nil, nil)
+
+	_, err := am.GetTokenMetadataValue(context.Background(), "ns1", "nfts", "1")
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetTokenMetadataValueGetDataFail(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	pool := newTestNFTPool(mdi)
+	dataID := fftypes.NewUUID()
+	mdi.On("GetTokenMetadata", context.Background(), pool.ID, "1").Return(&fftypes.TokenMetadata{
+		Namespace: "ns1", Data: dataID,
+	}, nil)
+	mdi.On("GetDataByID", context.Background(), dataID, true).Return(nil, fmt.Errorf("pop"))
+
+	_, err := am.GetTokenMetadataValue(context.Background(), "ns1", "nfts", "1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetTokenMetadataValueDataNotFound(t *testing.T) {
+	am, cancel := newTestAssets(t)
+	defer cancel()
+	mdi := am.database.(*databasemocks.Plugin)
+	pool := newTestNFTPool(mdi)
+	dataID := fftypes.NewUUID()
+	mdi.On("GetTokenMetadata", context.Background(), pool.ID, "1").Return(&fftypes.TokenMetadata{
+		Namespace: "ns1", Data: dataID,
+	}, nil)
+	mdi.On("GetDataByID", context.Background(), dataID, true).Return(nil, nil)
+
+	_, err := am.GetTokenMetadataValue(context.Background(), "ns1", "nfts", "1")
+	assert.Regexp(t, "FF10109", err)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/pinning"
 	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/internal/sysmessaging"
 	"github.com/hyperledger/firefly/internal/txcommon"
@@ -37,6 +38,10 @@ func NewBatchManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, di data
 	if di == nil || dm == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
+	pp, err := pinning.NewPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
 	pCtx, cancelCtx := context.WithCancel(log.WithLogField(ctx, "role", "batchmgr"))
 	readPageSize := config.GetUint(config.BatchManagerReadPageSize)
 	bm := &batchManager{
@@ -55,11 +60,9 @@ func NewBatchManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, di data
 		allDispatchers:             make([]*dispatcher, 0),
 		newMessages:                make(chan int64, 1),
 		done:                       make(chan struct{}),
-		retry: &retry.Retry{
-			InitialDelay: config.GetDuration(config.BatchRetryInitDelay),
-			MaximumDelay: config.GetDuration(config.BatchRetryMaxDelay),
-			Factor:       config.GetFloat64(config.BatchRetryFactor),
-		},
+		retry:                      retry.NewFromConfig(config.BatchRetryInitDelay, config.BatchRetryMaxDelay, config.BatchRetryFactor),
+		pinning:                    pp,
+		lifecycleEvents:            config.GetBool(config.BatchLifecycleEvents),
 	}
 	return bm, nil
 }
@@ -71,6 +74,7 @@ type Manager interface {
 	Close()
 	WaitStop()
 	Status() *ManagerStatus
+	QueueDepths() []*fftypes.BatchQueueDepth
 }
 
 type ManagerStatus struct {
@@ -101,6 +105,8 @@ type batchManager struct {
 	messagePollTimeout         time.Duration
 	minimumPollTime            time.Duration
 	startupOffsetRetryAttempts int
+	pinning                    *pinning.Policy
+	lifecycleEvents            bool
 }
 
 type DispatchHandler func(context.Context, *DispatchState) error
@@ -179,6 +185,13 @@ func (bm *batchManager) getProcessor(txType fftypes.TransactionType, msgType fft
 				signer:            *signer,
 				group:             group,
 				dispatch:          dispatcher.handler,
+				// The private messages of pinned batches in a namespace with deferred pinning are confirmed
+				// as soon as they are delivered, and the pins are anchored later
+				deferredPinning: txType == fftypes.TransactionTypeBatchPin &&
+					dispatcher.options.BatchType == fftypes.BatchTypePrivate &&
+					bm.pinning.IsDeferred(namespace),
+				lifecycleEvents: bm.lifecycleEvents,
+				contextSalt:     bm.pinning.ContextSalt(namespace),
 			},
 			bm.retry,
 			bm.txHelper,
@@ -376,6 +389,16 @@ func (bm *batchManager) Status() *ManagerStatus {
 	}
 }
 
+// QueueDepths returns the number of messages waiting for, and in, the assembly of each batch processor
+func (bm *batchManager) QueueDepths() []*fftypes.BatchQueueDepth {
+	processors := bm.getProcessors()
+	depths := make([]*fftypes.BatchQueueDepth, len(processors))
+	for i, p := range processors {
+		depths[i] = p.queueDepth()
+	}
+	return depths
+}
+
 func (bm *batchManager) Close() {
 	bm.cancelCtx() // all processor contexts are child contexts
 }
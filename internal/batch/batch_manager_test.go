@@ -136,6 +136,9 @@ func TestE2EDispatchBroadcast(t *testing.T) {
 	// Check the status while we know there's a flush going on
 	status := bm.Status()
 	assert.NotNil(t, status.Processors[0].Status.Flushing)
+	depths := bm.QueueDepths()
+	assert.Equal(t, "utdispatcher", depths[0].Dispatcher)
+	assert.Equal(t, status.Processors[0].Status.Flushing, depths[0].Flushing)
 
 	b := <-waitForDispatch
 	assert.Equal(t, *msg.Header.ID, *b.Payload.Messages[0].Header.ID)
@@ -299,6 +302,47 @@ func TestInitFailNoPersistence(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestInitFailBadPinningRules(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "pinning": fftypes.JSONObject{"deferred": "yes"}},
+	})
+	defer config.Reset()
+	_, err := NewBatchManager(context.Background(), &sysmessagingmocks.LocalNodeInfo{}, &databasemocks.Plugin{}, &datamocks.Manager{}, nil)
+	assert.Regexp(t, "FF10515", err)
+}
+
+func TestGetProcessorDeferredPinning(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "pinning": fftypes.JSONObject{"deferred": true, "contextSalt": "secret"}},
+	})
+	defer config.Reset()
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	mni := &sysmessagingmocks.LocalNodeInfo{}
+	bm, _ := NewBatchManager(context.Background(), mni, mdi, mdm, txcommon.NewTransactionHelper(mdi, mdm))
+	defer bm.Close()
+	handler := func(c context.Context, state *DispatchState) error { return nil }
+	bm.RegisterDispatcher("private", fftypes.TransactionTypeBatchPin, []fftypes.MessageType{fftypes.MessageTypePrivate}, handler, DispatcherOptions{BatchType: fftypes.BatchTypePrivate})
+	bm.RegisterDispatcher("broadcast", fftypes.TransactionTypeBatchPin, []fftypes.MessageType{fftypes.MessageTypeBroadcast}, handler, DispatcherOptions{BatchType: fftypes.BatchTypeBroadcast})
+
+	group := fftypes.NewRandB32()
+	signer := &fftypes.SignerRef{Author: "did:firefly:org/abcd", Key: "0x12345"}
+	processor, err := bm.(*batchManager).getProcessor(fftypes.TransactionTypeBatchPin, fftypes.MessageTypePrivate, group, "ns1", signer)
+	assert.NoError(t, err)
+	assert.True(t, processor.conf.deferredPinning)
+	assert.Equal(t, "secret", processor.conf.contextSalt)
+	processor, err = bm.(*batchManager).getProcessor(fftypes.TransactionTypeBatchPin, fftypes.MessageTypePrivate, group, "ns2", signer)
+	assert.NoError(t, err)
+	assert.False(t, processor.conf.deferredPinning)
+	assert.Empty(t, processor.conf.contextSalt)
+	processor, err = bm.(*batchManager).getProcessor(fftypes.TransactionTypeBatchPin, fftypes.MessageTypeBroadcast, nil, "ns1", signer)
+	assert.NoError(t, err)
+	assert.False(t, processor.conf.deferredPinning)
+}
+
 func TestGetInvalidBatchTypeMsg(t *testing.T) {
 
 	mdi := &databasemocks.Plugin{}
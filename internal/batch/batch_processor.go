@@ -18,7 +18,6 @@ package batch
 
 import (
 	"context"
-	"crypto/sha256"
 	"database/sql/driver"
 	"encoding/binary"
 	"fmt"
@@ -29,6 +28,7 @@ import (
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/internal/pinning"
 	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/internal/sysmessaging"
 	"github.com/hyperledger/firefly/internal/txcommon"
@@ -50,6 +50,12 @@ type batchProcessorConf struct {
 	signer         fftypes.SignerRef
 	group          *fftypes.Bytes32
 	dispatch       DispatchHandler
+	// deferredPinning confirms the private messages of the batch once dispatched, ahead of the pin being anchored
+	deferredPinning bool
+	// lifecycleEvents emits an event as the batch is assembled, sealed and dispatched
+	lifecycleEvents bool
+	// contextSalt is the salt for the contexts and pins of the messages, if the namespace has salted contexts
+	contextSalt string
 }
 
 // FlushStatus is an object that can be returned on REST queries to understand the status
@@ -117,9 +123,12 @@ func newBatchProcessor(ctx context.Context, ni sysmessaging.LocalNodeInfo, di da
 		quescing:  make(chan bool, 1),
 		done:      make(chan struct{}),
 		retry: &retry.Retry{
-			InitialDelay: baseRetryConf.InitialDelay,
-			MaximumDelay: baseRetryConf.MaximumDelay,
-			Factor:       baseRetryConf.Factor,
+			InitialDelay:   baseRetryConf.InitialDelay,
+			MaximumDelay:   baseRetryConf.MaximumDelay,
+			Factor:         baseRetryConf.Factor,
+			Jitter:         baseRetryConf.Jitter,
+			MaximumElapsed: baseRetryConf.MaximumElapsed,
+			Breaker:        baseRetryConf.Breaker,
 		},
 		conf:             conf,
 		flushedSequences: []int64{},
@@ -153,6 +162,18 @@ func (bp *batchProcessor) status() *ProcessorStatus {
 	}
 }
 
+func (bp *batchProcessor) queueDepth() *fftypes.BatchQueueDepth {
+	bp.statusMux.Lock()
+	defer bp.statusMux.Unlock()
+	return &fftypes.BatchQueueDepth{
+		Dispatcher: bp.conf.dispatcherName,
+		Name:       bp.conf.name,
+		Queued:     len(bp.newWork),
+		Assembling: len(bp.assemblyQueue),
+		Flushing:   bp.flushStatus.Flushing,
+	}
+}
+
 func (bp *batchProcessor) newAssembly(initalWork ...*batchWork) {
 	bp.assemblyID = fftypes.NewUUID()
 	bp.assemblyQueue = append([]*batchWork{}, initalWork...)
@@ -190,8 +211,10 @@ func (bp *batchProcessor) addWork(newWork *batchWork) (full, overflow bool) {
 		newQueue = append(newQueue, newWork)
 	}
 	log.L(bp.ctx).Debugf("Added message %s sequence=%d to in-flight batch assembly %s", newWork.msg.Header.ID, newWork.msg.Sequence, bp.assemblyID)
+	bp.statusMux.Lock()
 	bp.assemblyQueueBytes += newWork.estimateSize()
 	bp.assemblyQueue = newQueue
+	bp.statusMux.Unlock()
 	full = len(bp.assemblyQueue) >= int(bp.conf.BatchMaxSize) || (bp.assemblyQueueBytes >= bp.conf.BatchMaxBytes)
 	overflow = len(bp.assemblyQueue) > 1 && (bp.assemblyQueueBytes > bp.conf.BatchMaxBytes)
 	return full, overflow
@@ -422,8 +445,8 @@ func (bp *batchProcessor) initFlushState(id *fftypes.UUID, flushWork []*batchWor
 
 func (bp *batchProcessor) maskContext(ctx context.Context, msg *fftypes.Message, topic string) (msgPinString string, contextOrPin *fftypes.Bytes32, err error) {
 
-	hashBuilder := sha256.New()
-	hashBuilder.Write([]byte(topic))
+	hashBuilder := pinning.NewContextHash(bp.conf.contextSalt)
+	hashBuilder.Write([]byte(msg.Header.PinContext(topic)))
 
 	// For broadcast we do not need to mask the context, which is just the hash
 	// of the topic. There would be no way to unmask it if we did, because we don't have
@@ -529,12 +552,29 @@ func (bp *batchProcessor) sealBatch(state *DispatchState) (err error) {
 			log.L(ctx).Debugf("Batch %s sealed. Hash=%s", state.Persisted.ID, state.Persisted.Hash)
 
 			// At this point the manifest of the batch is finalized. We write it to the database
-			return bp.database.UpsertBatch(ctx, &state.Persisted)
+			if err = bp.database.UpsertBatch(ctx, &state.Persisted); err != nil {
+				return err
+			}
+
+			if bp.conf.lifecycleEvents {
+				// The batch was assembled when the flush state was built, which is when it was created
+				if err = bp.insertBatchEvent(ctx, state, fftypes.EventTypeBatchAssembled, state.Persisted.Created); err != nil {
+					return err
+				}
+				return bp.insertBatchEvent(ctx, state, fftypes.EventTypeBatchSealed, fftypes.Now())
+			}
+			return nil
 		})
 	})
 	return err
 }
 
+func (bp *batchProcessor) insertBatchEvent(ctx context.Context, state *DispatchState, eventType fftypes.EventType, created *fftypes.FFTime) error {
+	event := fftypes.NewEvent(eventType, state.Persisted.Namespace, state.Persisted.ID, state.Persisted.TX.ID, "")
+	event.Created = created
+	return bp.database.InsertEvent(ctx, event)
+}
+
 func (bp *batchProcessor) dispatchBatch(state *DispatchState) error {
 	// Call the dispatcher to do the heavy lifting - will only exit if we're closed
 	return operations.RunWithOperationCache(bp.ctx, func(ctx context.Context) error {
@@ -579,6 +619,18 @@ func (bp *batchProcessor) markPayloadDispatched(state *DispatchState) error {
 				return err
 			}
 
+			if bp.conf.deferredPinning {
+				if err = bp.markDeferredMessagesConfirmed(ctx, state); err != nil {
+					return err
+				}
+			}
+
+			if bp.conf.lifecycleEvents {
+				if err = bp.insertBatchEvent(ctx, state, fftypes.EventTypeBatchDispatched, fftypes.Now()); err != nil {
+					return err
+				}
+			}
+
 			for _, dataID := range state.BlobsPublished {
 				for _, d := range state.Payload.Data {
 					if d.ID.Equals(dataID) {
@@ -592,16 +644,17 @@ func (bp *batchProcessor) markPayloadDispatched(state *DispatchState) error {
 				}
 			}
 
-			if bp.conf.txType == fftypes.TransactionTypeUnpinned {
-				for _, msg := range state.Payload.Messages {
-					// Emit a confirmation event locally immediately
-					for _, topic := range msg.Header.Topics {
-						// One event per topic
-						event := fftypes.NewEvent(fftypes.EventTypeMessageConfirmed, state.Persisted.Namespace, msg.Header.ID, state.Persisted.TX.ID, topic)
-						event.Correlator = msg.Header.CID
-						if err := bp.database.InsertEvent(ctx, event); err != nil {
-							return err
-						}
+			for _, msg := range state.Payload.Messages {
+				if !bp.confirmOnDispatch(msg) {
+					continue
+				}
+				// Emit a confirmation event locally immediately
+				for _, topic := range msg.Header.Topics {
+					// One event per topic
+					event := fftypes.NewEvent(fftypes.EventTypeMessageConfirmed, state.Persisted.Namespace, msg.Header.ID, state.Persisted.TX.ID, topic)
+					event.Correlator = msg.Header.CID
+					if err := bp.database.InsertEvent(ctx, event); err != nil {
+						return err
 					}
 				}
 			}
@@ -610,3 +663,35 @@ func (bp *batchProcessor) markPayloadDispatched(state *DispatchState) error {
 		})
 	})
 }
+
+// confirmOnDispatch returns true for messages that are confirmed as soon as their batch has been dispatched
+func (bp *batchProcessor) confirmOnDispatch(msg *fftypes.Message) bool {
+	return bp.conf.txType == fftypes.TransactionTypeUnpinned ||
+		(bp.conf.deferredPinning && msg.Header.Type == fftypes.MessageTypePrivate)
+}
+
+// markDeferredMessagesConfirmed confirms the private messages of a batch with deferred pinning, once the batch
+// has been delivered. Other messages in the batch, such as group inits, still wait for the pin to be anchored.
+func (bp *batchProcessor) markDeferredMessagesConfirmed(ctx context.Context, state *DispatchState) error {
+	msgIDs := make([]driver.Value, 0, len(state.Payload.Messages))
+	for _, msg := range state.Payload.Messages {
+		if bp.confirmOnDispatch(msg) {
+			// The aggregator decides to reconcile, rather than confirm, from the state of the cached message
+			msg.State = fftypes.MessageStateConfirmed
+			bp.data.UpdateMessageIfCached(ctx, msg)
+			msgIDs = append(msgIDs, msg.Header.ID)
+		}
+	}
+	if len(msgIDs) == 0 {
+		return nil
+	}
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.In("id", msgIDs),
+		fb.Eq("state", fftypes.MessageStateSent),
+	)
+	update := database.MessageQueryFactory.NewUpdate(ctx).
+		Set("state", fftypes.MessageStateConfirmed).
+		Set("confirmed", fftypes.Now())
+	return bp.database.UpdateMessages(ctx, filter, update)
+}
@@ -16,7 +16,10 @@ package batch
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,6 +31,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/sysmessagingmocks"
 	"github.com/hyperledger/firefly/mocks/txcommonmocks"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -396,6 +400,78 @@ func TestMaskContextsDuplicate(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestMaskContextsOrderingKey(t *testing.T) {
+	log.SetLevel("debug")
+	config.Reset()
+
+	mdi, bp := newTestBatchProcessor(func(c context.Context, state *DispatchState) error {
+		return nil
+	})
+
+	contexts := make(map[fftypes.Bytes32]bool)
+	mdi.On("UpsertNonceNext", mock.Anything, mock.MatchedBy(func(n *fftypes.Nonce) bool {
+		contexts[*n.Context] = true
+		return true
+	})).Return(nil)
+	mdi.On("UpdateMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	group := fftypes.NewRandB32()
+	newMsg := func(orderingKey string) *fftypes.Message {
+		return &fftypes.Message{
+			Header: fftypes.MessageHeader{
+				ID:          fftypes.NewUUID(),
+				Type:        fftypes.MessageTypePrivate,
+				Group:       group,
+				Topics:      fftypes.FFStringArray{"topic1"},
+				OrderingKey: orderingKey,
+			},
+		}
+	}
+	payload := &fftypes.BatchPayload{
+		Messages: []*fftypes.Message{newMsg(""), newMsg("account1"), newMsg("account2"), newMsg("account1")},
+	}
+
+	_, err := bp.maskContexts(bp.ctx, payload)
+	assert.NoError(t, err)
+	assert.Len(t, contexts, 3)
+
+	bp.cancelCtx()
+	<-bp.done
+
+	mdi.AssertExpectations(t)
+}
+
+func TestMaskContextsSalted(t *testing.T) {
+	log.SetLevel("debug")
+	config.Reset()
+
+	_, bp := newTestBatchProcessor(func(c context.Context, state *DispatchState) error {
+		return nil
+	})
+	bp.conf.contextSalt = "secret"
+
+	payload := &fftypes.BatchPayload{
+		Messages: []*fftypes.Message{
+			{
+				Header: fftypes.MessageHeader{
+					ID:     fftypes.NewUUID(),
+					Type:   fftypes.MessageTypeBroadcast,
+					Topics: fftypes.FFStringArray{"topic1"},
+				},
+			},
+		},
+	}
+
+	contexts, err := bp.maskContexts(bp.ctx, payload)
+	assert.NoError(t, err)
+	h := hmac.New(sha256.New, []byte("secret"))
+	h.Write([]byte("topic1"))
+	assert.Equal(t, []*fftypes.Bytes32{fftypes.HashResult(h)}, contexts)
+
+	bp.cancelCtx()
+	<-bp.done
+}
+
 func TestMaskContextsUpdataMessageFail(t *testing.T) {
 	log.SetLevel("debug")
 	config.Reset()
@@ -507,3 +583,192 @@ func TestDispatchWithPublicBlobUpdatesFail(t *testing.T) {
 
 	mdi.AssertExpectations(t)
 }
+
+func TestMarkPayloadDispatchedDeferredPinning(t *testing.T) {
+	config.Reset()
+
+	mdi, bp := newTestBatchProcessor(func(c context.Context, state *DispatchState) error { return nil })
+	bp.cancelCtx()
+	bp.conf.deferredPinning = true
+
+	privateMsg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Type: fftypes.MessageTypePrivate, Topics: fftypes.FFStringArray{"topic1", "topic2"}}}
+	groupInitMsg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Type: fftypes.MessageTypeGroupInit, Topics: fftypes.FFStringArray{"topic1"}}}
+
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.MatchedBy(func(u database.Update) bool {
+		info, _ := u.Finalize()
+		val, _ := info.SetOperations[1].Value.Value()
+		return val == string(fftypes.MessageStateSent)
+	})).Return(nil).Once()
+	mdi.On("UpdateMessages", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return strings.Contains(fi.String(), privateMsg.Header.ID.String()) &&
+			!strings.Contains(fi.String(), groupInitMsg.Header.ID.String())
+	}), mock.MatchedBy(func(u database.Update) bool {
+		info, _ := u.Finalize()
+		val, _ := info.SetOperations[0].Value.Value()
+		return val == string(fftypes.MessageStateConfirmed)
+	})).Return(nil).Once()
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeMessageConfirmed && e.Reference.Equals(privateMsg.Header.ID)
+	})).Return(nil).Twice()
+
+	mdm := bp.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageIfCached", mock.Anything, mock.Anything).Return()
+
+	err := bp.markPayloadDispatched(&DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{ID: fftypes.NewUUID(), Namespace: "ns1"},
+		},
+		Payload: fftypes.BatchPayload{
+			Messages: []*fftypes.Message{groupInitMsg, privateMsg},
+		},
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestMarkPayloadDispatchedDeferredPinningNoPrivate(t *testing.T) {
+	config.Reset()
+
+	mdi, bp := newTestBatchProcessor(func(c context.Context, state *DispatchState) error { return nil })
+	bp.cancelCtx()
+	bp.conf.deferredPinning = true
+
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+	mdm := bp.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageIfCached", mock.Anything, mock.Anything).Return()
+
+	err := bp.markPayloadDispatched(&DispatchState{
+		Payload: fftypes.BatchPayload{
+			Messages: []*fftypes.Message{
+				{Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Type: fftypes.MessageTypeGroupInit}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestMarkPayloadDispatchedDeferredPinningFail(t *testing.T) {
+	config.Reset()
+
+	mdi, bp := newTestBatchProcessor(func(c context.Context, state *DispatchState) error { return nil })
+	bp.cancelCtx()
+	bp.conf.deferredPinning = true
+
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	mdm := bp.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageIfCached", mock.Anything, mock.Anything).Return()
+
+	err := bp.markPayloadDispatched(&DispatchState{
+		Payload: fftypes.BatchPayload{
+			Messages: []*fftypes.Message{
+				{Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Type: fftypes.MessageTypePrivate}},
+			},
+		},
+	})
+	assert.Regexp(t, "FF10158", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestSealBatchLifecycleEvents(t *testing.T) {
+	config.Reset()
+
+	mdi, bp := newTestBatchProcessor(func(c context.Context, state *DispatchState) error { return nil })
+	bp.cancelCtx()
+	bp.conf.txType = fftypes.TransactionTypeUnpinned
+	bp.conf.lifecycleEvents = true
+
+	txID := fftypes.NewUUID()
+	mth := bp.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeUnpinned).Return(txID, nil)
+
+	state := bp.initFlushState(fftypes.NewUUID(), []*batchWork{})
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpsertBatch", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeBatchAssembled && e.Reference.Equals(state.Persisted.ID) &&
+			e.Transaction.Equals(txID) && e.Created == state.Persisted.Created
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeBatchSealed && e.Reference.Equals(state.Persisted.ID)
+	})).Return(nil)
+
+	err := bp.sealBatch(state)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
+func TestSealBatchLifecycleEventsFail(t *testing.T) {
+	config.Reset()
+
+	mdi, bp := newTestBatchProcessor(func(c context.Context, state *DispatchState) error { return nil })
+	bp.cancelCtx()
+	bp.conf.txType = fftypes.TransactionTypeUnpinned
+	bp.conf.lifecycleEvents = true
+
+	mth := bp.txHelper.(*txcommonmocks.Helper)
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeUnpinned).Return(fftypes.NewUUID(), nil)
+
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpsertBatch", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := bp.sealBatch(bp.initFlushState(fftypes.NewUUID(), []*batchWork{}))
+	assert.Regexp(t, "FF10158", err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
+func TestMarkPayloadDispatchedLifecycleEvents(t *testing.T) {
+	config.Reset()
+
+	mdi, bp := newTestBatchProcessor(func(c context.Context, state *DispatchState) error { return nil })
+	bp.cancelCtx()
+	bp.conf.lifecycleEvents = true
+
+	batchID := fftypes.NewUUID()
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeBatchDispatched && e.Reference.Equals(batchID)
+	})).Return(nil)
+
+	err := bp.markPayloadDispatched(&DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{ID: batchID, Namespace: "ns1"},
+		},
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestMarkPayloadDispatchedLifecycleEventsFail(t *testing.T) {
+	config.Reset()
+
+	mdi, bp := newTestBatchProcessor(func(c context.Context, state *DispatchState) error { return nil })
+	bp.cancelCtx()
+	bp.conf.lifecycleEvents = true
+
+	mockRunAsGroupPassthrough(mdi)
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := bp.markPayloadDispatched(&DispatchState{})
+	assert.Regexp(t, "FF10158", err)
+
+	mdi.AssertExpectations(t)
+}
@@ -19,13 +19,17 @@ package batchpin
 import (
 	"context"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/internal/pinning"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/sharedstorage"
 )
 
 type Submitter interface {
@@ -39,23 +43,43 @@ type Submitter interface {
 }
 
 type batchPinSubmitter struct {
-	database   database.Plugin
-	identity   identity.Manager
-	blockchain blockchain.Plugin
-	metrics    metrics.Manager
-	operations operations.Manager
+	database      database.Plugin
+	identity      identity.Manager
+	blockchain    blockchain.Plugin
+	sharedstorage sharedstorage.Plugin
+	metrics       metrics.Manager
+	operations    operations.Manager
+	rollup        *pinRollup
+	pinning       *pinning.Policy
+	deferred      map[string]*deferredPins
+	// lifecycleEvents emits an event when the pin of each batch is submitted to the blockchain
+	lifecycleEvents bool
 }
 
-func NewBatchPinSubmitter(ctx context.Context, di database.Plugin, im identity.Manager, bi blockchain.Plugin, mm metrics.Manager, om operations.Manager) (Submitter, error) {
-	if di == nil || im == nil || bi == nil || mm == nil || om == nil {
+func NewBatchPinSubmitter(ctx context.Context, di database.Plugin, im identity.Manager, bi blockchain.Plugin, ss sharedstorage.Plugin, mm metrics.Manager, om operations.Manager) (Submitter, error) {
+	if di == nil || im == nil || bi == nil || ss == nil || mm == nil || om == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
+	pp, err := pinning.NewPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
 	bp := &batchPinSubmitter{
-		database:   di,
-		identity:   im,
-		blockchain: bi,
-		metrics:    mm,
-		operations: om,
+		database:        di,
+		identity:        im,
+		blockchain:      bi,
+		sharedstorage:   ss,
+		metrics:         mm,
+		operations:      om,
+		pinning:         pp,
+		deferred:        make(map[string]*deferredPins),
+		lifecycleEvents: config.GetBool(config.BatchLifecycleEvents),
+	}
+	if config.GetBool(config.BatchPinRollupEnabled) {
+		bp.rollup = newPinRollup(ctx, bp)
+	}
+	for _, ns := range pp.DeferredNamespaces() {
+		bp.deferred[ns] = newDeferredPins(ctx, bp, ns, pp.Interval(ns))
 	}
 	om.RegisterHandler(ctx, bp, []fftypes.OpType{
 		fftypes.OpTypeBlockchainBatchPin,
@@ -82,5 +106,27 @@ func (bp *batchPinSubmitter) SubmitPinnedBatch(ctx context.Context, batch *fftyp
 	if bp.metrics.IsMetricsEnabled() {
 		bp.metrics.CountBatchPin()
 	}
+	if deferred, ok := bp.deferred[batch.Namespace]; ok && batch.Group != nil {
+		// The messages of the batch are confirmed off-chain, and the pin is anchored later
+		deferred.add(op, batch, contexts)
+		return nil
+	}
+	if bp.rollup != nil && !bp.isPrivateTransaction(batch) {
+		// The operation remains pending until the rollup containing it is submitted
+		bp.rollup.add(op, batch, contexts)
+		return nil
+	}
 	return bp.operations.RunOperation(ctx, opBatchPin(op, batch, contexts))
 }
+
+// batchPinSubmitted records the submission of the blockchain transaction that pins a batch. The transaction
+// is already in flight at this point, so a failure to insert the event is logged rather than returned.
+func (bp *batchPinSubmitter) batchPinSubmitted(ctx context.Context, ns string, batchID, txID *fftypes.UUID) {
+	if !bp.lifecycleEvents {
+		return
+	}
+	event := fftypes.NewEvent(fftypes.EventTypeBatchPinSubmitted, ns, batchID, txID, "")
+	if err := bp.database.InsertEvent(ctx, event); err != nil {
+		log.L(ctx).Errorf("Failed to insert %s event for batch '%s': %s", event.Type, batchID, err)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
+	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -38,6 +39,7 @@ func newTestBatchPinSubmitter(t *testing.T, enableMetrics bool) *batchPinSubmitt
 	mdi := &databasemocks.Plugin{}
 	mim := &identitymanagermocks.Manager{}
 	mbi := &blockchainmocks.Plugin{}
+	mps := &sharedstoragemocks.Plugin{}
 	mmi := &metricsmocks.Manager{}
 	mom := &operationmocks.Manager{}
 	mmi.On("IsMetricsEnabled").Return(enableMetrics)
@@ -46,13 +48,13 @@ func newTestBatchPinSubmitter(t *testing.T, enableMetrics bool) *batchPinSubmitt
 		mmi.On("CountBatchPin").Return()
 	}
 	mbi.On("Name").Return("ut").Maybe()
-	bps, err := NewBatchPinSubmitter(context.Background(), mdi, mim, mbi, mmi, mom)
+	bps, err := NewBatchPinSubmitter(context.Background(), mdi, mim, mbi, mps, mmi, mom)
 	assert.NoError(t, err)
 	return bps.(*batchPinSubmitter)
 }
 
 func TestInitFail(t *testing.T) {
-	_, err := NewBatchPinSubmitter(context.Background(), nil, nil, nil, nil, nil)
+	_, err := NewBatchPinSubmitter(context.Background(), nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 }
 
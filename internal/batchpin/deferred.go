@@ -0,0 +1,110 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchpin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// deferredPins holds the pins of the private batches of a namespace with deferred pinning. The messages in
+// those batches are confirmed off-chain as soon as they are delivered, and the pins are anchored on a schedule.
+type deferredPins struct {
+	ctx      context.Context
+	bp       *batchPinSubmitter
+	interval time.Duration
+	mux      sync.Mutex
+	pending  []*deferredPin
+	done     chan struct{}
+}
+
+type deferredPin struct {
+	op       *fftypes.Operation
+	batch    *fftypes.BatchPersisted
+	contexts []*fftypes.Bytes32
+}
+
+func newDeferredPins(ctx context.Context, bp *batchPinSubmitter, ns string, interval time.Duration) *deferredPins {
+	dp := &deferredPins{
+		ctx:      log.WithLogger(ctx, log.L(ctx).WithField("role", "deferred-pins").WithField("ns", ns)),
+		bp:       bp,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+	go dp.anchorLoop()
+	return dp
+}
+
+func (dp *deferredPins) add(op *fftypes.Operation, batch *fftypes.BatchPersisted, contexts []*fftypes.Bytes32) {
+	dp.mux.Lock()
+	defer dp.mux.Unlock()
+	dp.pending = append(dp.pending, &deferredPin{
+		op:       op,
+		batch:    batch,
+		contexts: contexts,
+	})
+}
+
+// remove withdraws a pin that is still waiting to be anchored, returning false if it is not pending
+func (dp *deferredPins) remove(opID *fftypes.UUID) bool {
+	dp.mux.Lock()
+	defer dp.mux.Unlock()
+	for i, entry := range dp.pending {
+		if entry.op.ID.Equals(opID) {
+			dp.pending = append(dp.pending[0:i:i], dp.pending[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (dp *deferredPins) anchorLoop() {
+	defer close(dp.done)
+	ticker := time.NewTicker(dp.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-dp.ctx.Done():
+			log.L(dp.ctx).Debugf("Deferred pin loop exiting")
+			return
+		case <-ticker.C:
+		}
+		dp.flush(dp.ctx)
+	}
+}
+
+// flush submits all the pending pins, in the order the batches were dispatched. Failures are recorded
+// against the batch pin operations, which can then be retried individually.
+func (dp *deferredPins) flush(ctx context.Context) {
+	dp.mux.Lock()
+	pending := dp.pending
+	dp.pending = nil
+	dp.mux.Unlock()
+
+	if len(pending) > 0 {
+		log.L(ctx).Infof("Anchoring %d deferred batch pins", len(pending))
+	}
+	for _, entry := range pending {
+		if err := dp.bp.operations.RunOperation(ctx, opBatchPin(entry.op, entry.batch, entry.contexts)); err != nil {
+			log.L(ctx).Errorf("Failed to anchor deferred pin of batch '%s': %s", entry.batch.ID, err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchpin
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
+	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestDeferredBatchPinSubmitter(t *testing.T, ctx context.Context, interval string) *batchPinSubmitter {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "default"},
+		{"name": "ns1", "pinning": fftypes.JSONObject{"deferred": true, "interval": interval}},
+	})
+	mbi := &blockchainmocks.Plugin{}
+	mmi := &metricsmocks.Manager{}
+	mom := &operationmocks.Manager{}
+	mbi.On("Name").Return("ut").Maybe()
+	mmi.On("IsMetricsEnabled").Return(false)
+	mom.On("RegisterHandler", mock.Anything, mock.Anything, mock.Anything)
+	bps, err := NewBatchPinSubmitter(ctx, &databasemocks.Plugin{}, &identitymanagermocks.Manager{}, mbi, &sharedstoragemocks.Plugin{}, mmi, mom)
+	assert.NoError(t, err)
+	return bps.(*batchPinSubmitter)
+}
+
+func TestInitBadPinningRules(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "pinning": fftypes.JSONObject{"deferred": "yes"}},
+	})
+	defer config.Reset()
+	_, err := NewBatchPinSubmitter(context.Background(), &databasemocks.Plugin{}, &identitymanagermocks.Manager{}, &blockchainmocks.Plugin{}, &sharedstoragemocks.Plugin{}, &metricsmocks.Manager{}, &operationmocks.Manager{})
+	assert.Regexp(t, "FF10515", err)
+}
+
+func TestSubmitPinnedBatchDeferred(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	bp := newTestDeferredBatchPinSubmitter(t, ctx, "1h")
+	defer config.Reset()
+	assert.Len(t, bp.deferred, 1)
+
+	batch := newTestRollupBatch("ns1", "0x12345")
+	batch.Group = fftypes.NewRandB32()
+	contexts := []*fftypes.Bytes32{fftypes.NewRandB32()}
+
+	mom := bp.operations.(*operationmocks.Manager)
+	mom.On("AddOrReuseOperation", ctx, mock.Anything).Return(nil)
+
+	err := bp.SubmitPinnedBatch(ctx, batch, contexts)
+	assert.NoError(t, err)
+	assert.Len(t, bp.deferred["ns1"].pending, 1)
+
+	cancel()
+	<-bp.deferred["ns1"].done
+
+	mom.On("RunOperation", ctx, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		data := op.Data.(batchPinData)
+		return op.Type == fftypes.OpTypeBlockchainBatchPin && data.Batch == batch
+	})).Return(fmt.Errorf("pop"))
+
+	bp.deferred["ns1"].flush(ctx)
+	assert.Empty(t, bp.deferred["ns1"].pending)
+
+	mom.AssertExpectations(t)
+}
+
+func TestSubmitPinnedBatchNotDeferred(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bp := newTestDeferredBatchPinSubmitter(t, ctx, "1h")
+	defer config.Reset()
+
+	broadcastBatch := newTestRollupBatch("ns1", "0x12345")
+	otherNSBatch := newTestRollupBatch("default", "0x12345")
+	otherNSBatch.Group = fftypes.NewRandB32()
+
+	mom := bp.operations.(*operationmocks.Manager)
+	mom.On("AddOrReuseOperation", ctx, mock.Anything).Return(nil)
+	mom.On("RunOperation", ctx, mock.Anything).Return(nil).Twice()
+
+	err := bp.SubmitPinnedBatch(ctx, broadcastBatch, []*fftypes.Bytes32{})
+	assert.NoError(t, err)
+	err = bp.SubmitPinnedBatch(ctx, otherNSBatch, []*fftypes.Bytes32{})
+	assert.NoError(t, err)
+	assert.Empty(t, bp.deferred["ns1"].pending)
+
+	mom.AssertExpectations(t)
+}
+
+func TestDeferredPinsAnchorLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bp := newTestDeferredBatchPinSubmitter(t, ctx, "1ms")
+	defer config.Reset()
+
+	batch := newTestRollupBatch("ns1", "0x12345")
+	anchored := make(chan struct{})
+	mom := bp.operations.(*operationmocks.Manager)
+	mom.On("RunOperation", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		close(anchored)
+	}).Once()
+
+	bp.deferred["ns1"].add(&fftypes.Operation{ID: fftypes.NewUUID()}, batch, []*fftypes.Bytes32{})
+	<-anchored
+
+	mom.AssertExpectations(t)
+}
+
+func TestCompensateOperationWithdrawsDeferredPin(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bp := newTestDeferredBatchPinSubmitter(t, ctx, "1h")
+	defer config.Reset()
+
+	op1 := &fftypes.Operation{ID: fftypes.NewUUID(), Namespace: "ns1", Type: fftypes.OpTypeBlockchainBatchPin}
+	op2 := &fftypes.Operation{ID: fftypes.NewUUID(), Namespace: "ns1", Type: fftypes.OpTypeBlockchainBatchPin}
+	bp.deferred["ns1"].add(op1, newTestRollupBatch("ns1", "0x12345"), []*fftypes.Bytes32{})
+	bp.deferred["ns1"].add(op2, newTestRollupBatch("ns1", "0x12345"), []*fftypes.Bytes32{})
+
+	mdi := bp.database.(*databasemocks.Plugin)
+	mdi.On("ResolveOperation", ctx, op1.ID, fftypes.OpStatusFailed, mock.Anything, fftypes.JSONObject(nil)).Return(nil)
+
+	complete, err := bp.CompensateOperation(ctx, fftypes.NewUUID(), op1)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	assert.Len(t, bp.deferred["ns1"].pending, 1)
+	assert.Equal(t, op2, bp.deferred["ns1"].pending[0].op)
+
+	_, err = bp.CompensateOperation(ctx, fftypes.NewUUID(), op1)
+	assert.Regexp(t, "FF10504", err)
+
+	mdi.AssertExpectations(t)
+}
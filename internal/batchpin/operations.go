@@ -18,7 +18,9 @@ package batchpin
 
 import (
 	"context"
+	"sort"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -80,20 +82,95 @@ func (bp *batchPinSubmitter) RunOperation(ctx context.Context, op *fftypes.Prepa
 	switch data := op.Data.(type) {
 	case batchPinData:
 		batch := data.Batch
-		return false, bp.blockchain.SubmitBatchPin(ctx, op.ID, nil /* TODO: ledger selection */, batch.Key, &blockchain.BatchPin{
+		privateFrom, privateFor, err := bp.privateTransactionKeys(ctx, batch)
+		if err != nil {
+			return false, err
+		}
+		err = bp.blockchain.SubmitBatchPin(ctx, op.ID, nil /* TODO: ledger selection */, batch.Key, &blockchain.BatchPin{
 			Namespace:       batch.Namespace,
 			TransactionID:   batch.TX.ID,
 			BatchID:         batch.ID,
 			BatchHash:       batch.Hash,
 			BatchPayloadRef: batch.PayloadRef,
 			Contexts:        data.Contexts,
+			PrivateFrom:     privateFrom,
+			PrivateFor:      privateFor,
 		})
+		if err == nil {
+			bp.batchPinSubmitted(ctx, batch.Namespace, batch.ID, batch.TX.ID)
+		}
+		return false, err
 
 	default:
 		return false, i18n.NewError(ctx, i18n.MsgOperationNotSupported)
 	}
 }
 
+// CompensationRequired withdraws a pin that has not yet been submitted, if the private data of the batch
+// could not be delivered - as the recipients would otherwise receive a pin for a batch they never get
+func (bp *batchPinSubmitter) CompensationRequired(step, failed *fftypes.Operation) bool {
+	return step.Type == fftypes.OpTypeBlockchainBatchPin &&
+		step.Status == fftypes.OpStatusPending &&
+		(failed.Type == fftypes.OpTypeDataExchangeBatchSend || failed.Type == fftypes.OpTypeDataExchangeBlobSend)
+}
+
+// CompensateOperation removes the pin from the rollup, or deferred anchor, it is waiting in. A pin that has
+// already been submitted to the blockchain cannot be withdrawn.
+func (bp *batchPinSubmitter) CompensateOperation(ctx context.Context, id *fftypes.UUID, step *fftypes.Operation) (complete bool, err error) {
+	if !bp.withdraw(step) {
+		return false, i18n.NewError(ctx, i18n.MsgStepNotCompensable, step.ID, step.Type)
+	}
+	return true, bp.database.ResolveOperation(ctx, step.ID, fftypes.OpStatusFailed, i18n.Expand(ctx, i18n.MsgBatchPinWithdrawn, id), nil)
+}
+
+func (bp *batchPinSubmitter) withdraw(step *fftypes.Operation) bool {
+	if deferred, ok := bp.deferred[step.Namespace]; ok && deferred.remove(step.ID) {
+		return true
+	}
+	return bp.rollup != nil && bp.rollup.remove(step.ID)
+}
+
+func (bp *batchPinSubmitter) isPrivateTransaction(batch *fftypes.BatchPersisted) bool {
+	return batch.Group != nil && bp.blockchain.Capabilities().PrivateTransactions
+}
+
+// privateTransactionKeys resolves the private transaction keys of the nodes in the group of a private batch,
+// so the pin can be submitted privately to those nodes, on blockchains that support private transactions
+func (bp *batchPinSubmitter) privateTransactionKeys(ctx context.Context, batch *fftypes.BatchPersisted) (privateFrom string, privateFor []string, err error) {
+	if !bp.isPrivateTransaction(batch) {
+		return "", nil, nil
+	}
+	privateFrom = config.GetString(config.NodePrivateTransactionKey)
+	if privateFrom == "" {
+		return "", nil, i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "privateTransactionKey", "node")
+	}
+	group, err := bp.database.GetGroupByHash(ctx, batch.Group)
+	if err != nil {
+		return "", nil, err
+	} else if group == nil {
+		return "", nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	keys := make(map[string]bool)
+	for _, member := range group.Members {
+		node, err := bp.identity.CachedIdentityLookupByID(ctx, member.Node)
+		if err != nil {
+			return "", nil, err
+		} else if node == nil {
+			return "", nil, i18n.NewError(ctx, i18n.MsgNodeNotFound, member.Node)
+		}
+		key := node.Profile.GetString(blockchain.NodeProfilePrivateTransactionKey)
+		if key == "" {
+			return "", nil, i18n.NewError(ctx, i18n.MsgNodeNoPrivateTransactionKey, node.Name)
+		}
+		if !keys[key] {
+			keys[key] = true
+			privateFor = append(privateFor, key)
+		}
+	}
+	sort.Strings(privateFor)
+	return privateFrom, privateFor, nil
+}
+
 func opBatchPin(op *fftypes.Operation, batch *fftypes.BatchPersisted, contexts []*fftypes.Bytes32) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
 		ID:   op.ID,
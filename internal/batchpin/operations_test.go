@@ -19,8 +19,11 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -64,6 +67,43 @@ func TestPrepareAndRunBatchPin(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestRunBatchPinLifecycleEvent(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	bp.lifecycleEvents = true
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeBlockchainBatchPin,
+		ID:   fftypes.NewUUID(),
+	}
+	batch := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			SignerRef: fftypes.SignerRef{
+				Key: "0x123",
+			},
+		},
+		TX: fftypes.TransactionRef{
+			ID: fftypes.NewUUID(),
+		},
+	}
+
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mdi := bp.database.(*databasemocks.Plugin)
+	mbi.On("SubmitBatchPin", context.Background(), op.ID, mock.Anything, "0x123", mock.Anything).Return(nil)
+	mdi.On("InsertEvent", context.Background(), mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeBatchPinSubmitted && e.Namespace == "ns1" &&
+			e.Reference.Equals(batch.ID) && e.Transaction.Equals(batch.TX.ID)
+	})).Return(fmt.Errorf("pop"))
+
+	complete, err := bp.RunOperation(context.Background(), opBatchPin(op, batch, []*fftypes.Bytes32{}))
+
+	assert.False(t, complete)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
 func TestPrepareOperationNotSupported(t *testing.T) {
 	bp := newTestBatchPinSubmitter(t, false)
 
@@ -146,3 +186,272 @@ func TestPrepareOperationBatchPinNotFound(t *testing.T) {
 	_, err := bp.PrepareOperation(context.Background(), op)
 	assert.Regexp(t, "FF10109", err)
 }
+
+func newTestPrivateBatch() *fftypes.BatchPersisted {
+	return &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID:    fftypes.NewUUID(),
+			Group: fftypes.NewRandB32(),
+			SignerRef: fftypes.SignerRef{
+				Key: "0x123",
+			},
+		},
+	}
+}
+
+func TestRunBatchPinPrivate(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	config.Reset()
+	config.Set(config.NodePrivateTransactionKey, "key1")
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeBlockchainBatchPin,
+		ID:   fftypes.NewUUID(),
+	}
+	batch := newTestPrivateBatch()
+	node1 := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}
+	node1.Profile = fftypes.JSONObject{blockchain.NodeProfilePrivateTransactionKey: "key1"}
+	node2 := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}
+	node2.Profile = fftypes.JSONObject{blockchain.NodeProfilePrivateTransactionKey: "key2"}
+	group := &fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{
+			Members: fftypes.Members{
+				{Identity: "did:firefly:org/org2", Node: node2.ID},
+				{Identity: "did:firefly:org/org1", Node: node1.ID},
+				{Identity: "did:firefly:org/org1a", Node: node1.ID},
+			},
+		},
+	}
+
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mdi := bp.database.(*databasemocks.Plugin)
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{PrivateTransactions: true})
+	mdi.On("GetGroupByHash", context.Background(), batch.Group).Return(group, nil)
+	mim.On("CachedIdentityLookupByID", context.Background(), node1.ID).Return(node1, nil)
+	mim.On("CachedIdentityLookupByID", context.Background(), node2.ID).Return(node2, nil)
+	mbi.On("SubmitBatchPin", context.Background(), op.ID, mock.Anything, "0x123", mock.MatchedBy(func(pin *blockchain.BatchPin) bool {
+		return pin.PrivateFrom == "key1" && assert.Equal(t, []string{"key1", "key2"}, pin.PrivateFor)
+	})).Return(nil)
+
+	_, err := bp.RunOperation(context.Background(), opBatchPin(op, batch, nil))
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestRunBatchPinPrivateNotSupported(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeBlockchainBatchPin,
+		ID:   fftypes.NewUUID(),
+	}
+	batch := newTestPrivateBatch()
+
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{})
+	mbi.On("SubmitBatchPin", context.Background(), op.ID, mock.Anything, "0x123", mock.MatchedBy(func(pin *blockchain.BatchPin) bool {
+		return pin.PrivateFrom == "" && pin.PrivateFor == nil
+	})).Return(nil)
+
+	_, err := bp.RunOperation(context.Background(), opBatchPin(op, batch, nil))
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+}
+
+func TestRunBatchPinPrivateNoLocalKey(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	config.Reset()
+	config.Set(config.NodePrivateTransactionKey, "")
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeBlockchainBatchPin,
+		ID:   fftypes.NewUUID(),
+	}
+
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{PrivateTransactions: true})
+
+	_, err := bp.RunOperation(context.Background(), opBatchPin(op, newTestPrivateBatch(), nil))
+	assert.Regexp(t, "FF10138.*privateTransactionKey", err)
+}
+
+func TestRunBatchPinPrivateGroupFail(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	config.Reset()
+	config.Set(config.NodePrivateTransactionKey, "key1")
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeBlockchainBatchPin,
+		ID:   fftypes.NewUUID(),
+	}
+	batch := newTestPrivateBatch()
+
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mdi := bp.database.(*databasemocks.Plugin)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{PrivateTransactions: true})
+	mdi.On("GetGroupByHash", context.Background(), batch.Group).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bp.RunOperation(context.Background(), opBatchPin(op, batch, nil))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRunBatchPinPrivateGroupNotFound(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	config.Reset()
+	config.Set(config.NodePrivateTransactionKey, "key1")
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeBlockchainBatchPin,
+		ID:   fftypes.NewUUID(),
+	}
+	batch := newTestPrivateBatch()
+
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mdi := bp.database.(*databasemocks.Plugin)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{PrivateTransactions: true})
+	mdi.On("GetGroupByHash", context.Background(), batch.Group).Return(nil, nil)
+
+	_, err := bp.RunOperation(context.Background(), opBatchPin(op, batch, nil))
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestRunBatchPinPrivateNodeLookupFail(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	config.Reset()
+	config.Set(config.NodePrivateTransactionKey, "key1")
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeBlockchainBatchPin,
+		ID:   fftypes.NewUUID(),
+	}
+	batch := newTestPrivateBatch()
+	nodeID := fftypes.NewUUID()
+	group := &fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{
+			Members: fftypes.Members{{Identity: "did:firefly:org/org1", Node: nodeID}},
+		},
+	}
+
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mdi := bp.database.(*databasemocks.Plugin)
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{PrivateTransactions: true})
+	mdi.On("GetGroupByHash", context.Background(), batch.Group).Return(group, nil)
+	mim.On("CachedIdentityLookupByID", context.Background(), nodeID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := bp.RunOperation(context.Background(), opBatchPin(op, batch, nil))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRunBatchPinPrivateNodeNotFound(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	config.Reset()
+	config.Set(config.NodePrivateTransactionKey, "key1")
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeBlockchainBatchPin,
+		ID:   fftypes.NewUUID(),
+	}
+	batch := newTestPrivateBatch()
+	nodeID := fftypes.NewUUID()
+	group := &fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{
+			Members: fftypes.Members{{Identity: "did:firefly:org/org1", Node: nodeID}},
+		},
+	}
+
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mdi := bp.database.(*databasemocks.Plugin)
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{PrivateTransactions: true})
+	mdi.On("GetGroupByHash", context.Background(), batch.Group).Return(group, nil)
+	mim.On("CachedIdentityLookupByID", context.Background(), nodeID).Return(nil, nil)
+
+	_, err := bp.RunOperation(context.Background(), opBatchPin(op, batch, nil))
+	assert.Regexp(t, "FF10224", err)
+}
+
+func TestRunBatchPinPrivateNodeNoKey(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	config.Reset()
+	config.Set(config.NodePrivateTransactionKey, "key1")
+
+	op := &fftypes.Operation{
+		Type: fftypes.OpTypeBlockchainBatchPin,
+		ID:   fftypes.NewUUID(),
+	}
+	batch := newTestPrivateBatch()
+	node := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Name: "node1"}}
+	group := &fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{
+			Members: fftypes.Members{{Identity: "did:firefly:org/org1", Node: node.ID}},
+		},
+	}
+
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mdi := bp.database.(*databasemocks.Plugin)
+	mim := bp.identity.(*identitymanagermocks.Manager)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{PrivateTransactions: true})
+	mdi.On("GetGroupByHash", context.Background(), batch.Group).Return(group, nil)
+	mim.On("CachedIdentityLookupByID", context.Background(), node.ID).Return(node, nil)
+
+	_, err := bp.RunOperation(context.Background(), opBatchPin(op, batch, nil))
+	assert.Regexp(t, "FF10401.*node1", err)
+}
+
+func TestCompensationRequired(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+
+	pending := &fftypes.Operation{Type: fftypes.OpTypeBlockchainBatchPin, Status: fftypes.OpStatusPending}
+	succeeded := &fftypes.Operation{Type: fftypes.OpTypeBlockchainBatchPin, Status: fftypes.OpStatusSucceeded}
+	assert.True(t, bp.CompensationRequired(pending, &fftypes.Operation{Type: fftypes.OpTypeDataExchangeBatchSend}))
+	assert.True(t, bp.CompensationRequired(pending, &fftypes.Operation{Type: fftypes.OpTypeDataExchangeBlobSend}))
+	assert.False(t, bp.CompensationRequired(pending, &fftypes.Operation{Type: fftypes.OpTypeTokenTransfer}))
+	assert.False(t, bp.CompensationRequired(succeeded, &fftypes.Operation{Type: fftypes.OpTypeDataExchangeBatchSend}))
+}
+
+func TestCompensateOperationWithdrawsPin(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+
+	key := rollupKey{namespace: "ns1", signingKey: "0x12345"}
+	entry1 := newTestRollupEntry("ns1", "0x12345")
+	entry2 := newTestRollupEntry("ns1", "0x12345")
+	bp.rollup = &pinRollup{pending: map[rollupKey][]*rolledUpOp{key: {entry1, entry2}}}
+	compensationID := fftypes.NewUUID()
+
+	mdi := bp.database.(*databasemocks.Plugin)
+	mdi.On("ResolveOperation", context.Background(), entry1.op.ID, fftypes.OpStatusFailed, mock.MatchedBy(func(errorMsg string) bool {
+		return errorMsg == "Batch pin withdrawn by compensation operation '"+compensationID.String()+"'"
+	}), fftypes.JSONObject(nil)).Return(nil)
+
+	complete, err := bp.CompensateOperation(context.Background(), compensationID, entry1.op)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, []*rolledUpOp{entry2}, bp.rollup.pending[key])
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCompensateOperationAlreadySubmitted(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+
+	bp.rollup = &pinRollup{pending: map[rollupKey][]*rolledUpOp{}}
+	step := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainBatchPin}
+
+	_, err := bp.CompensateOperation(context.Background(), fftypes.NewUUID(), step)
+	assert.Regexp(t, "FF10504", err)
+}
+
+func TestCompensateOperationNoRollup(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+
+	step := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainBatchPin}
+
+	_, err := bp.CompensateOperation(context.Background(), fftypes.NewUUID(), step)
+	assert.Regexp(t, "FF10504", err)
+}
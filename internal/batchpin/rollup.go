@@ -0,0 +1,233 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchpin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// pinRollup accumulates batch pins, and periodically submits them to the blockchain as a single
+// transaction that pins the Merkle root of the pins. The full rollup is published to shared storage,
+// and each batch pin operation retains the proof that its batch is included under the root.
+type pinRollup struct {
+	ctx      context.Context
+	bp       *batchPinSubmitter
+	interval time.Duration
+	maxPins  int
+	mux      sync.Mutex
+	pending  map[rollupKey][]*rolledUpOp
+	order    []rollupKey
+	full     chan bool
+	done     chan struct{}
+}
+
+// rollupKey identifies the pins that can share a rollup, as the namespace and signer are visible on-chain
+type rollupKey struct {
+	namespace  string
+	signingKey string
+}
+
+type rolledUpOp struct {
+	op  *fftypes.Operation
+	pin *fftypes.RolledUpPin
+}
+
+func newPinRollup(ctx context.Context, bp *batchPinSubmitter) *pinRollup {
+	pr := &pinRollup{
+		ctx:      log.WithLogger(ctx, log.L(ctx).WithField("role", "pin-rollup")),
+		bp:       bp,
+		interval: config.GetDuration(config.BatchPinRollupInterval),
+		maxPins:  config.GetInt(config.BatchPinRollupMaxPins),
+		pending:  make(map[rollupKey][]*rolledUpOp),
+		full:     make(chan bool, 1),
+		done:     make(chan struct{}),
+	}
+	if pr.maxPins <= 0 {
+		pr.maxPins = 1
+	}
+	go pr.rollupLoop()
+	return pr
+}
+
+func (pr *pinRollup) add(op *fftypes.Operation, batch *fftypes.BatchPersisted, contexts []*fftypes.Bytes32) {
+	key := rollupKey{namespace: batch.Namespace, signingKey: batch.Key}
+	pr.mux.Lock()
+	defer pr.mux.Unlock()
+	if _, ok := pr.pending[key]; !ok {
+		pr.order = append(pr.order, key)
+	}
+	pr.pending[key] = append(pr.pending[key], &rolledUpOp{
+		op: op,
+		pin: &fftypes.RolledUpPin{
+			TransactionID: batch.TX.ID,
+			BatchID:       batch.ID,
+			BatchHash:     batch.Hash,
+			PayloadRef:    batch.PayloadRef,
+			Contexts:      contexts,
+		},
+	})
+	if len(pr.pending[key]) >= pr.maxPins {
+		select {
+		case pr.full <- true:
+		default:
+		}
+	}
+}
+
+// remove withdraws a pin that is still waiting to be rolled up, returning false if it is not pending
+func (pr *pinRollup) remove(opID *fftypes.UUID) bool {
+	pr.mux.Lock()
+	defer pr.mux.Unlock()
+	for key, entries := range pr.pending {
+		for i, entry := range entries {
+			if entry.op.ID.Equals(opID) {
+				pr.pending[key] = append(entries[0:i:i], entries[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (pr *pinRollup) rollupLoop() {
+	defer close(pr.done)
+	ticker := time.NewTicker(pr.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-pr.ctx.Done():
+			log.L(pr.ctx).Debugf("Pin rollup loop exiting")
+			return
+		case <-ticker.C:
+		case <-pr.full:
+		}
+		pr.flush(pr.ctx)
+	}
+}
+
+func (pr *pinRollup) flush(ctx context.Context) {
+	pr.mux.Lock()
+	pending, order := pr.pending, pr.order
+	pr.pending, pr.order = make(map[rollupKey][]*rolledUpOp), nil
+	pr.mux.Unlock()
+
+	for _, key := range order {
+		for entries := pending[key]; len(entries) > 0; {
+			count := len(entries)
+			if count > pr.maxPins {
+				count = pr.maxPins
+			}
+			pr.submitRollup(ctx, key, entries[0:count])
+			entries = entries[count:]
+		}
+	}
+}
+
+// submitRollup submits a single rollup transaction. Failures are recorded against the batch pin
+// operations, which can then be retried individually.
+func (pr *pinRollup) submitRollup(ctx context.Context, key rollupKey, entries []*rolledUpOp) {
+	rollup := &fftypes.PinRollup{
+		ID:        fftypes.NewUUID(),
+		Namespace: key.namespace,
+		Pins:      make([]*fftypes.RolledUpPin, len(entries)),
+	}
+	for i, entry := range entries {
+		rollup.Pins[i] = entry.pin
+	}
+	leaves := rollup.Leaves()
+	rollup.Root = fftypes.MerkleRoot(leaves)
+
+	if err := pr.submit(ctx, key, rollup, leaves, entries); err != nil {
+		log.L(ctx).Errorf("Failed to submit rollup '%s' of %d batch pins: %s", rollup.ID, len(entries), err)
+		for _, entry := range entries {
+			if err := pr.bp.database.ResolveOperation(ctx, entry.op.ID, fftypes.OpStatusFailed, err.Error(), nil); err != nil {
+				log.L(ctx).Errorf("Failed to update operation '%s': %s", entry.op.ID, err)
+			}
+		}
+		return
+	}
+	for _, entry := range entries {
+		pr.bp.batchPinSubmitted(ctx, key.namespace, entry.pin.BatchID, entry.pin.TransactionID)
+	}
+}
+
+func (pr *pinRollup) submit(ctx context.Context, key rollupKey, rollup *fftypes.PinRollup, leaves []*fftypes.Bytes32, entries []*rolledUpOp) error {
+	payload, _ := json.Marshal(rollup)
+	payloadRef, err := pr.bp.sharedstorage.PublishData(ctx, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	opIDs := make([]string, len(entries))
+	for i, entry := range entries {
+		opIDs[i] = entry.op.ID.String()
+	}
+	op := fftypes.NewOperation(pr.bp.blockchain, key.namespace, rollup.ID, fftypes.OpTypeBlockchainPinRollup)
+	op.Input = fftypes.JSONObject{
+		"rollup":     rollup.ID.String(),
+		"root":       rollup.Root.String(),
+		"payloadRef": payloadRef,
+		"operations": opIDs,
+	}
+	err = pr.bp.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		err := pr.bp.database.InsertTransaction(ctx, &fftypes.Transaction{
+			ID:        rollup.ID,
+			Namespace: key.namespace,
+			Type:      fftypes.TransactionTypePinRollup,
+			Created:   fftypes.Now(),
+		})
+		if err == nil {
+			err = pr.bp.database.InsertOperation(ctx, op)
+		}
+		for i := 0; err == nil && i < len(entries); i++ {
+			err = pr.bp.database.ResolveOperation(ctx, entries[i].op.ID, fftypes.OpStatusPending, "", fftypes.JSONObject{
+				"rollup":   rollup.ID.String(),
+				"rollupOp": op.ID.String(),
+				"root":     rollup.Root.String(),
+				"leaf":     leaves[i].String(),
+				"proof":    fftypes.MerkleProof(leaves, i),
+			})
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = pr.bp.blockchain.SubmitBatchPin(ctx, op.ID, nil /* TODO: ledger selection */, key.signingKey, &blockchain.BatchPin{
+		Namespace:       key.namespace,
+		TransactionID:   rollup.ID,
+		BatchID:         rollup.ID,
+		BatchHash:       rollup.Root,
+		BatchPayloadRef: payloadRef,
+	})
+	if err != nil {
+		if updateErr := pr.bp.database.ResolveOperation(ctx, op.ID, fftypes.OpStatusFailed, err.Error(), nil); updateErr != nil {
+			log.L(ctx).Errorf("Failed to update operation '%s': %s", op.ID, updateErr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,302 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchpin
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
+	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRollupBatch(ns, key string) *fftypes.BatchPersisted {
+	return &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: ns,
+			Hash:      fftypes.NewRandB32(),
+			SignerRef: fftypes.SignerRef{
+				Key: key,
+			},
+		},
+		TX: fftypes.TransactionRef{
+			ID: fftypes.NewUUID(),
+		},
+	}
+}
+
+func newTestRollupEntry(ns, key string) *rolledUpOp {
+	batch := newTestRollupBatch(ns, key)
+	return &rolledUpOp{
+		op: &fftypes.Operation{ID: fftypes.NewUUID()},
+		pin: &fftypes.RolledUpPin{
+			TransactionID: batch.TX.ID,
+			BatchID:       batch.ID,
+			BatchHash:     batch.Hash,
+			Contexts:      []*fftypes.Bytes32{fftypes.NewRandB32()},
+		},
+	}
+}
+
+func mockRunAsGroup(mdi *databasemocks.Plugin) {
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+}
+
+func TestRollupSubmitPinnedBatches(t *testing.T) {
+	config.Reset()
+	config.Set(config.BatchPinRollupEnabled, true)
+	config.Set(config.BatchPinRollupInterval, "1h")
+	config.Set(config.BatchPinRollupMaxPins, 2)
+	defer config.Reset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mdi := &databasemocks.Plugin{}
+	mbi := &blockchainmocks.Plugin{}
+	mps := &sharedstoragemocks.Plugin{}
+	mmi := &metricsmocks.Manager{}
+	mom := &operationmocks.Manager{}
+	mom.On("RegisterHandler", mock.Anything, mock.Anything, mock.Anything)
+	mbi.On("Name").Return("ut")
+	mmi.On("IsMetricsEnabled").Return(false)
+	bps, err := NewBatchPinSubmitter(ctx, mdi, nil, mbi, mps, mmi, mom)
+	assert.Regexp(t, "FF10128", err)
+	bps, err = NewBatchPinSubmitter(ctx, mdi, &identitymanagermocks.Manager{}, mbi, mps, mmi, mom)
+	assert.NoError(t, err)
+	bp := bps.(*batchPinSubmitter)
+	assert.NotNil(t, bp.rollup)
+
+	batch1 := newTestRollupBatch("ns1", "0x12345")
+	batch2 := newTestRollupBatch("ns1", "0x12345")
+	contexts := []*fftypes.Bytes32{fftypes.NewRandB32()}
+
+	proofs := map[fftypes.UUID]fftypes.JSONObject{}
+	submitted := make(chan *blockchain.BatchPin)
+	mom.On("AddOrReuseOperation", ctx, mock.Anything).Return(nil)
+	mps.On("PublishData", mock.Anything, mock.Anything).Return("ref1", nil)
+	mockRunAsGroup(mdi)
+	mdi.On("InsertTransaction", mock.Anything, mock.MatchedBy(func(tx *fftypes.Transaction) bool {
+		return tx.Type == fftypes.TransactionTypePinRollup && tx.Namespace == "ns1"
+	})).Return(nil)
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Type == fftypes.OpTypeBlockchainPinRollup && len(op.Input.GetStringArray("operations")) == 2
+	})).Return(nil)
+	mdi.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusPending, "", mock.Anything).
+		Run(func(args mock.Arguments) {
+			proofs[*args[1].(*fftypes.UUID)] = args[4].(fftypes.JSONObject)
+		}).
+		Return(nil)
+	mbi.On("SubmitBatchPin", mock.Anything, mock.Anything, mock.Anything, "0x12345", mock.Anything).
+		Run(func(args mock.Arguments) {
+			submitted <- args[4].(*blockchain.BatchPin)
+		}).
+		Return(nil)
+
+	err = bp.SubmitPinnedBatch(ctx, batch1, contexts)
+	assert.NoError(t, err)
+	err = bp.SubmitPinnedBatch(ctx, batch2, contexts)
+	assert.NoError(t, err)
+
+	pin := <-submitted
+	assert.Equal(t, "ns1", pin.Namespace)
+	assert.Equal(t, "ref1", pin.BatchPayloadRef)
+	assert.Empty(t, pin.Contexts)
+	assert.Equal(t, pin.TransactionID, pin.BatchID)
+
+	rollup := &fftypes.PinRollup{
+		Namespace: "ns1",
+		Pins: []*fftypes.RolledUpPin{
+			{TransactionID: batch1.TX.ID, BatchID: batch1.ID, BatchHash: batch1.Hash, Contexts: contexts},
+			{TransactionID: batch2.TX.ID, BatchID: batch2.ID, BatchHash: batch2.Hash, Contexts: contexts},
+		},
+	}
+	leaves := rollup.Leaves()
+	assert.Equal(t, fftypes.MerkleRoot(leaves), pin.BatchHash)
+	assert.Len(t, proofs, 2)
+	for _, output := range proofs {
+		assert.Equal(t, pin.BatchHash.String(), output.GetString("root"))
+		assert.Equal(t, pin.BatchID.String(), output.GetString("rollup"))
+		leaf, err := fftypes.ParseBytes32(context.Background(), output.GetString("leaf"))
+		assert.NoError(t, err)
+		assert.True(t, fftypes.VerifyMerkleProof(leaf, output["proof"].([]*fftypes.MerkleProofStep), pin.BatchHash))
+	}
+
+	cancel()
+	<-bp.rollup.done
+}
+
+func TestRollupSkipsPrivateTransactions(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	bp.rollup = &pinRollup{}
+	ctx := context.Background()
+
+	batch := newTestRollupBatch("ns1", "0x12345")
+	batch.Group = fftypes.NewRandB32()
+
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mmi := bp.metrics.(*metricsmocks.Manager)
+	mom := bp.operations.(*operationmocks.Manager)
+	mbi.On("Capabilities").Return(&blockchain.Capabilities{PrivateTransactions: true})
+	mom.On("AddOrReuseOperation", ctx, mock.Anything).Return(nil)
+	mmi.On("IsMetricsEnabled").Return(false)
+	mom.On("RunOperation", ctx, mock.Anything).Return(nil)
+
+	err := bp.SubmitPinnedBatch(ctx, batch, []*fftypes.Bytes32{})
+	assert.NoError(t, err)
+
+	mom.AssertExpectations(t)
+}
+
+func TestRollupLoopTicker(t *testing.T) {
+	config.Reset()
+	config.Set(config.BatchPinRollupInterval, "1ms")
+	config.Set(config.BatchPinRollupMaxPins, 0)
+	defer config.Reset()
+
+	bp := newTestBatchPinSubmitter(t, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	pr := newPinRollup(ctx, bp)
+	assert.Equal(t, 1, pr.maxPins)
+
+	flushed := make(chan bool)
+	mps := bp.sharedstorage.(*sharedstoragemocks.Plugin)
+	mps.On("PublishData", mock.Anything, mock.Anything).Return("", fmt.Errorf("pop"))
+	mdi := bp.database.(*databasemocks.Plugin)
+	mdi.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusFailed, "pop", fftypes.JSONObject(nil)).
+		Run(func(args mock.Arguments) { flushed <- true }).
+		Return(nil)
+
+	pr.mux.Lock()
+	entry := newTestRollupEntry("ns1", "0x12345")
+	key := rollupKey{namespace: "ns1", signingKey: "0x12345"}
+	pr.pending[key] = []*rolledUpOp{entry}
+	pr.order = []rollupKey{key}
+	pr.mux.Unlock()
+
+	<-flushed
+	cancel()
+	<-pr.done
+}
+
+func TestRollupFlushChunks(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	pr := &pinRollup{bp: bp, maxPins: 2, pending: make(map[rollupKey][]*rolledUpOp), full: make(chan bool, 1)}
+
+	for i := 0; i < 3; i++ {
+		b := newTestRollupBatch("ns1", "0x12345")
+		pr.add(&fftypes.Operation{ID: fftypes.NewUUID()}, b, []*fftypes.Bytes32{fftypes.NewRandB32()})
+	}
+	pr.add(&fftypes.Operation{ID: fftypes.NewUUID()}, newTestRollupBatch("ns2", "0x12345"), nil)
+	assert.Len(t, pr.full, 1)
+
+	mps := bp.sharedstorage.(*sharedstoragemocks.Plugin)
+	mps.On("PublishData", mock.Anything, mock.Anything).Return("ref1", nil)
+	mdi := bp.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
+	mdi.On("InsertTransaction", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusPending, "", mock.Anything).Return(nil)
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	var namespaces []string
+	mbi.On("SubmitBatchPin", mock.Anything, mock.Anything, mock.Anything, "0x12345", mock.Anything).
+		Run(func(args mock.Arguments) {
+			namespaces = append(namespaces, args[4].(*blockchain.BatchPin).Namespace)
+		}).
+		Return(nil)
+
+	pr.flush(context.Background())
+	assert.Equal(t, []string{"ns1", "ns1", "ns2"}, namespaces)
+	assert.Empty(t, pr.pending)
+	mdi.AssertNumberOfCalls(t, "ResolveOperation", 4)
+}
+
+func TestRollupSubmitDBFail(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	pr := &pinRollup{bp: bp, maxPins: 2}
+
+	mps := bp.sharedstorage.(*sharedstoragemocks.Plugin)
+	mps.On("PublishData", mock.Anything, mock.Anything).Return("ref1", nil)
+	mdi := bp.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
+	mdi.On("InsertTransaction", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mdi.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusFailed, "pop", fftypes.JSONObject(nil)).Return(fmt.Errorf("pop2"))
+
+	pr.submitRollup(context.Background(), rollupKey{namespace: "ns1"}, []*rolledUpOp{newTestRollupEntry("ns1", "")})
+	mdi.AssertExpectations(t)
+}
+
+func TestRollupSubmitBlockchainFail(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	pr := &pinRollup{bp: bp, maxPins: 2}
+
+	mps := bp.sharedstorage.(*sharedstoragemocks.Plugin)
+	mps.On("PublishData", mock.Anything, mock.Anything).Return("ref1", nil)
+	mdi := bp.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
+	mdi.On("InsertTransaction", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusPending, "", mock.Anything).Return(nil)
+	mdi.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusFailed, "pop", fftypes.JSONObject(nil)).Return(fmt.Errorf("pop2"))
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("SubmitBatchPin", mock.Anything, mock.Anything, mock.Anything, "", mock.Anything).Return(fmt.Errorf("pop"))
+
+	pr.submitRollup(context.Background(), rollupKey{namespace: "ns1"}, []*rolledUpOp{newTestRollupEntry("ns1", "")})
+	mdi.AssertNumberOfCalls(t, "ResolveOperation", 3)
+}
+
+func TestRollupSubmitLifecycleEvents(t *testing.T) {
+	bp := newTestBatchPinSubmitter(t, false)
+	bp.lifecycleEvents = true
+	pr := &pinRollup{bp: bp, maxPins: 2}
+
+	entry1 := newTestRollupEntry("ns1", "")
+	entry2 := newTestRollupEntry("ns1", "")
+	mps := bp.sharedstorage.(*sharedstoragemocks.Plugin)
+	mps.On("PublishData", mock.Anything, mock.Anything).Return("ref1", nil)
+	mdi := bp.database.(*databasemocks.Plugin)
+	mockRunAsGroup(mdi)
+	mdi.On("InsertTransaction", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("ResolveOperation", mock.Anything, mock.Anything, fftypes.OpStatusPending, "", mock.Anything).Return(nil)
+	for _, entry := range []*rolledUpOp{entry1, entry2} {
+		batchID, txID := entry.pin.BatchID, entry.pin.TransactionID
+		mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+			return e.Type == fftypes.EventTypeBatchPinSubmitted && e.Namespace == "ns1" &&
+				e.Reference.Equals(batchID) && e.Transaction.Equals(txID)
+		})).Return(nil)
+	}
+	mbi := bp.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("SubmitBatchPin", mock.Anything, mock.Anything, mock.Anything, "", mock.Anything).Return(nil)
+
+	pr.submitRollup(context.Background(), rollupKey{namespace: "ns1"}, []*rolledUpOp{entry1, entry2})
+	mdi.AssertExpectations(t)
+}
@@ -0,0 +1,346 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/client"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// The stages of the message pipeline that latency is reported for. The submit, confirm and total
+// stages are measured on the clock of the client, and the node stage on the clock of the node.
+const (
+	// StageSubmit is from sending the API request, to the node accepting the message
+	StageSubmit = "submit"
+	// StageConfirm is from the node accepting the message, to the client receiving its confirmation event
+	StageConfirm = "confirm"
+	// StageNode is from the node creating the message, to the message being confirmed on the node
+	StageNode = "node"
+	// StageTotal is from sending the API request, to the client receiving the confirmation event
+	StageTotal = "total"
+)
+
+// Options configures the load generated by Run
+type Options struct {
+	// Count is the number of messages to send
+	Count int
+	// Rate is the number of messages to send per second, or as fast as possible if zero
+	Rate float64
+	// Concurrency is the number of API requests in-flight at once
+	Concurrency int
+	// MessageSize is the size in bytes of the value sent in each message
+	MessageSize int
+	// GroupSize sends private messages to a group of that many organizations, rather than broadcasts
+	GroupSize int
+	// Timeout is how long to wait for all the messages to be confirmed, or unlimited if zero
+	Timeout time.Duration
+}
+
+// StageLatency is the distribution of the latency of one stage of the pipeline
+type StageLatency struct {
+	Stage string             `json:"stage"`
+	Count int                `json:"count"`
+	P50   fftypes.FFDuration `json:"p50"`
+	P90   fftypes.FFDuration `json:"p90"`
+	P99   fftypes.FFDuration `json:"p99"`
+	Max   fftypes.FFDuration `json:"max"`
+}
+
+// Report is the result of a run. Latencies are only reported for the messages that were confirmed.
+type Report struct {
+	Sent       int                `json:"sent"`
+	Failed     int                `json:"failed"`
+	Confirmed  int                `json:"confirmed"`
+	Rejected   int                `json:"rejected"`
+	TimedOut   int                `json:"timedOut"`
+	Elapsed    fftypes.FFDuration `json:"elapsed"`
+	Throughput float64            `json:"throughput"`
+	Stages     []*StageLatency    `json:"stages"`
+}
+
+type result struct {
+	submitted time.Time
+	accepted  time.Time
+	received  time.Time
+	rejected  bool
+	message   *fftypes.Message
+	complete  bool
+}
+
+type bench struct {
+	ctx       context.Context
+	client    *client.Client
+	options   Options
+	tag       string
+	members   []fftypes.MemberInput
+	value     *fftypes.JSONAny
+	mux       sync.Mutex
+	results   map[fftypes.UUID]*result
+	failed    int
+	completed int
+	done      chan struct{}
+}
+
+// Run sends messages to a node with the client, tagged so that they can be told apart from other
+// messages on the node, and waits for each to be confirmed or rejected. The messages that are not
+// confirmed within the timeout, or before the context is cancelled, are reported as timed out.
+func Run(ctx context.Context, c *client.Client, options *Options) (*Report, error) {
+	if options.Count <= 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgBenchInvalidCount)
+	}
+	b := &bench{
+		ctx:     log.WithLogger(ctx, log.L(ctx).WithField("role", "bench")),
+		client:  c,
+		options: *options,
+		tag:     fmt.Sprintf("bench-%s", fftypes.NewUUID()),
+		value:   fftypes.JSONAnyPtr(`"` + strings.Repeat("x", options.MessageSize) + `"`),
+		results: make(map[fftypes.UUID]*result),
+		done:    make(chan struct{}),
+	}
+	if b.options.Concurrency < 1 {
+		b.options.Concurrency = 1
+	}
+	if options.GroupSize > 0 {
+		orgs, err := c.GetOrganizations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(orgs) < options.GroupSize {
+			return nil, i18n.NewError(ctx, i18n.MsgBenchGroupTooLarge, options.GroupSize, len(orgs))
+		}
+		for _, org := range orgs[:options.GroupSize] {
+			b.members = append(b.members, fftypes.MemberInput{Identity: org.DID})
+		}
+	}
+
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		b.ctx, cancel = context.WithTimeout(b.ctx, options.Timeout)
+		defer cancel()
+	}
+	sub, err := c.Subscribe(b.ctx, &client.SubscribeOptions{
+		Filter: fftypes.SubscriptionFilter{
+			Events:  fmt.Sprintf("^(%s|%s)$", fftypes.EventTypeMessageConfirmed, fftypes.EventTypeMessageRejected),
+			Message: fftypes.MessageFilter{Tag: fmt.Sprintf("^%s$", b.tag)},
+		},
+		AutoAck: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Close()
+
+	log.L(b.ctx).Infof("Sending %d messages with tag '%s'", options.Count, b.tag)
+	start := time.Now()
+	go b.sendLoop()
+	b.receiveLoop(sub)
+	return b.report(time.Since(start)), nil
+}
+
+func (b *bench) sendLoop() {
+	var tick <-chan time.Time
+	if b.options.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / b.options.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	slots := make(chan struct{}, b.options.Concurrency)
+	for i := 0; i < b.options.Count; i++ {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-b.ctx.Done():
+				return
+			}
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-b.ctx.Done():
+			return
+		}
+		go func() {
+			b.send()
+			<-slots
+		}()
+	}
+}
+
+func (b *bench) send() {
+	msg := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{Tag: b.tag},
+		},
+		InlineData: fftypes.InlineData{{Value: b.value}},
+	}
+	submitted := time.Now()
+	var sent *fftypes.Message
+	var err error
+	if b.members != nil {
+		msg.Group = &fftypes.InputGroup{Members: b.members}
+		sent, err = b.client.SendPrivate(b.ctx, msg, false)
+	} else {
+		sent, err = b.client.Broadcast(b.ctx, msg, false)
+	}
+	accepted := time.Now()
+
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if err != nil {
+		log.L(b.ctx).Errorf("Failed to send message: %s", err)
+		b.failed++
+		b.markComplete()
+		return
+	}
+	r := b.getResult(sent.Header.ID)
+	r.submitted = submitted
+	r.accepted = accepted
+	b.checkComplete(r)
+}
+
+// receiveLoop runs until every message has been sent and confirmed, or the subscription is closed
+// because the context is done
+func (b *bench) receiveLoop(sub *client.Subscription) {
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			b.received(event)
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func (b *bench) received(event *client.Event) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	r := b.getResult(event.Reference)
+	r.received = time.Now()
+	r.rejected = event.Type == fftypes.EventTypeMessageRejected
+	r.message = event.Message
+	b.checkComplete(r)
+}
+
+// getResult returns the result for a message, which is created by whichever of the API response
+// or the confirmation event is received first. Must be called with the lock held.
+func (b *bench) getResult(id *fftypes.UUID) *result {
+	r := b.results[*id]
+	if r == nil {
+		r = &result{}
+		b.results[*id] = r
+	}
+	return r
+}
+
+func (b *bench) checkComplete(r *result) {
+	if !r.complete && !r.accepted.IsZero() && !r.received.IsZero() {
+		r.complete = true
+		b.markComplete()
+	}
+}
+
+func (b *bench) markComplete() {
+	b.completed++
+	if b.completed == b.options.Count {
+		close(b.done)
+	}
+}
+
+func (b *bench) report(elapsed time.Duration) *Report {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	report := &Report{
+		Failed:  b.failed,
+		Elapsed: fftypes.FFDuration(elapsed),
+	}
+	latencies := make(map[string][]time.Duration)
+	for _, r := range b.results {
+		switch {
+		case r.accepted.IsZero():
+			// Confirmation of a message whose API request did not complete
+			continue
+		case r.received.IsZero():
+			report.TimedOut++
+		case r.rejected:
+			report.Rejected++
+		default:
+			report.Confirmed++
+			latencies[StageConfirm] = append(latencies[StageConfirm], r.received.Sub(r.accepted))
+			latencies[StageTotal] = append(latencies[StageTotal], r.received.Sub(r.submitted))
+			if r.message != nil && r.message.Confirmed != nil && r.message.Header.Created != nil {
+				latencies[StageNode] = append(latencies[StageNode], time.Time(*r.message.Confirmed).Sub(time.Time(*r.message.Header.Created)))
+			}
+		}
+		report.Sent++
+		latencies[StageSubmit] = append(latencies[StageSubmit], r.accepted.Sub(r.submitted))
+	}
+	report.Throughput = float64(report.Confirmed) / elapsed.Seconds()
+	for _, stage := range []string{StageSubmit, StageConfirm, StageNode, StageTotal} {
+		report.Stages = append(report.Stages, newStageLatency(stage, latencies[stage]))
+	}
+	return report
+}
+
+func newStageLatency(stage string, latencies []time.Duration) *StageLatency {
+	sl := &StageLatency{Stage: stage, Count: len(latencies)}
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		sl.P50 = percentile(latencies, 0.5)
+		sl.P90 = percentile(latencies, 0.9)
+		sl.P99 = percentile(latencies, 0.99)
+		sl.Max = fftypes.FFDuration(latencies[len(latencies)-1])
+	}
+	return sl
+}
+
+// percentile uses the nearest-rank method on a sorted, non-empty, list of latencies
+func percentile(sorted []time.Duration, p float64) fftypes.FFDuration {
+	rank := int(math.Ceil(p * float64(len(sorted))))
+	return fftypes.FFDuration(sorted[rank-1])
+}
+
+// WriteText writes the report as a table
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Sent:\t%d\n", r.Sent)
+	fmt.Fprintf(tw, "Failed:\t%d\n", r.Failed)
+	fmt.Fprintf(tw, "Confirmed:\t%d\n", r.Confirmed)
+	fmt.Fprintf(tw, "Rejected:\t%d\n", r.Rejected)
+	fmt.Fprintf(tw, "Timed out:\t%d\n", r.TimedOut)
+	fmt.Fprintf(tw, "Elapsed:\t%s\n", r.Elapsed.String())
+	fmt.Fprintf(tw, "Throughput:\t%.2f msg/s\n", r.Throughput)
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "STAGE\tCOUNT\tP50\tP90\tP99\tMAX")
+	for _, s := range r.Stages {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\n", s.Stage, s.Count, s.P50.String(), s.P90.String(), s.P99.String(), s.Max.String())
+	}
+	return tw.Flush()
+}
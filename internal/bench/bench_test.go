@@ -0,0 +1,321 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/pkg/client"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+// testNode is a fake node, that accepts messages and delivers an event for each over a WebSocket
+type testNode struct {
+	t         *testing.T
+	server    *httptest.Server
+	mux       sync.Mutex
+	conn      *websocket.Conn
+	connected chan struct{}
+	orgs      []*fftypes.Identity
+	status    int
+	eventType fftypes.EventType
+	noEvents  bool
+	noWS      bool
+	groups    []*fftypes.InputGroup
+	sizes     []int
+}
+
+func newTestNode(t *testing.T) (*testNode, *client.Client) {
+	n := &testNode{
+		t:         t,
+		connected: make(chan struct{}),
+		status:    http.StatusAccepted,
+		eventType: fftypes.EventTypeMessageConfirmed,
+	}
+	n.server = httptest.NewServer(http.HandlerFunc(n.handle))
+	c, err := client.New(context.Background(), &client.Config{URL: n.server.URL})
+	assert.NoError(t, err)
+	return n, c
+}
+
+func (n *testNode) handle(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/ws":
+		n.handleWS(w, r)
+	case "/api/v1/network/organizations":
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(n.orgs)
+	case "/api/v1/namespaces/default/messages/broadcast", "/api/v1/namespaces/default/messages/private":
+		n.handleMessage(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (n *testNode) handleWS(w http.ResponseWriter, r *http.Request) {
+	if n.noWS {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	upgrader := &websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	assert.NoError(n.t, err)
+	var start fftypes.WSClientActionStartPayload
+	err = conn.ReadJSON(&start)
+	assert.NoError(n.t, err)
+	assert.True(n.t, start.Ephemeral)
+	assert.True(n.t, *start.AutoAck)
+	assert.Equal(n.t, "^(message_confirmed|message_rejected)$", start.Filter.Events)
+	assert.Regexp(n.t, `^\^bench-.*\$$`, start.Filter.Message.Tag)
+	n.mux.Lock()
+	n.conn = conn
+	n.mux.Unlock()
+	close(n.connected)
+}
+
+func (n *testNode) handleMessage(w http.ResponseWriter, r *http.Request) {
+	var in fftypes.MessageInOut
+	err := json.NewDecoder(r.Body).Decode(&in)
+	assert.NoError(n.t, err)
+	if n.status != http.StatusAccepted {
+		w.WriteHeader(n.status)
+		return
+	}
+	<-n.connected
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:      fftypes.NewUUID(),
+			Tag:     in.Header.Tag,
+			Created: fftypes.Now(),
+		},
+		Confirmed: fftypes.Now(),
+	}
+	n.mux.Lock()
+	n.groups = append(n.groups, in.Group)
+	n.sizes = append(n.sizes, len(*in.InlineData[0].Value))
+	if !n.noEvents {
+		// Deliver the event before the response, as a fast node might
+		err = n.conn.WriteJSON(&fftypes.EventDelivery{
+			EnrichedEvent: fftypes.EnrichedEvent{
+				Event: fftypes.Event{
+					ID:        fftypes.NewUUID(),
+					Type:      n.eventType,
+					Reference: msg.Header.ID,
+				},
+				Message: msg,
+			},
+		})
+		assert.NoError(n.t, err)
+	}
+	n.mux.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(msg)
+}
+
+func (n *testNode) close() {
+	n.mux.Lock()
+	if n.conn != nil {
+		n.conn.Close()
+	}
+	n.mux.Unlock()
+	n.server.Close()
+}
+
+func TestRunBroadcast(t *testing.T) {
+	n, c := newTestNode(t)
+	defer n.close()
+
+	report, err := Run(context.Background(), c, &Options{
+		Count:       5,
+		Concurrency: 2,
+		MessageSize: 10,
+		Timeout:     10 * time.Second,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, report.Sent)
+	assert.Equal(t, 5, report.Confirmed)
+	assert.Zero(t, report.Failed)
+	assert.Zero(t, report.TimedOut)
+	assert.Greater(t, report.Throughput, 0.0)
+	assert.Len(t, report.Stages, 4)
+	for _, s := range report.Stages {
+		assert.Equal(t, 5, s.Count)
+		assert.LessOrEqual(t, int64(s.P50), int64(s.Max))
+	}
+	assert.Equal(t, []int{12, 12, 12, 12, 12}, n.sizes)
+	assert.Nil(t, n.groups[0])
+}
+
+func TestRunPrivateWithRate(t *testing.T) {
+	n, c := newTestNode(t)
+	defer n.close()
+	n.orgs = []*fftypes.Identity{
+		{IdentityBase: fftypes.IdentityBase{DID: "did:firefly:org/org1"}},
+		{IdentityBase: fftypes.IdentityBase{DID: "did:firefly:org/org2"}},
+		{IdentityBase: fftypes.IdentityBase{DID: "did:firefly:org/org3"}},
+	}
+
+	report, err := Run(context.Background(), c, &Options{
+		Count:     3,
+		Rate:      1000,
+		GroupSize: 2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Confirmed)
+	assert.Equal(t, []fftypes.MemberInput{
+		{Identity: "did:firefly:org/org1"},
+		{Identity: "did:firefly:org/org2"},
+	}, n.groups[0].Members)
+}
+
+func TestRunRejected(t *testing.T) {
+	n, c := newTestNode(t)
+	defer n.close()
+	n.eventType = fftypes.EventTypeMessageRejected
+
+	report, err := Run(context.Background(), c, &Options{Count: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Sent)
+	assert.Equal(t, 2, report.Rejected)
+	assert.Zero(t, report.Confirmed)
+	assert.Equal(t, 2, report.Stages[0].Count)
+	assert.Zero(t, report.Stages[1].Count)
+}
+
+func TestRunSendFail(t *testing.T) {
+	n, c := newTestNode(t)
+	defer n.close()
+	n.status = http.StatusInternalServerError
+
+	report, err := Run(context.Background(), c, &Options{Count: 2})
+	assert.NoError(t, err)
+	assert.Zero(t, report.Sent)
+	assert.Equal(t, 2, report.Failed)
+}
+
+func TestRunTimeout(t *testing.T) {
+	n, c := newTestNode(t)
+	defer n.close()
+	n.noEvents = true
+
+	report, err := Run(context.Background(), c, &Options{
+		Count:   2,
+		Timeout: 100 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.Sent)
+	assert.Equal(t, 2, report.TimedOut)
+}
+
+func TestRunCancelledBeforeSend(t *testing.T) {
+	n, c := newTestNode(t)
+	defer n.close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &bench{
+		ctx:     ctx,
+		client:  c,
+		options: Options{Count: 2, Concurrency: 1},
+		done:    make(chan struct{}),
+	}
+	cancel()
+	b.sendLoop()
+	b.options.Rate = 1
+	b.sendLoop()
+	assert.Empty(t, n.sizes)
+}
+
+func TestRunCountInvalid(t *testing.T) {
+	_, err := Run(context.Background(), nil, &Options{})
+	assert.Regexp(t, "FF10555", err)
+}
+
+func TestRunGetOrgsFail(t *testing.T) {
+	n, c := newTestNode(t)
+	n.close()
+
+	_, err := Run(context.Background(), c, &Options{Count: 1, GroupSize: 1})
+	assert.Error(t, err)
+}
+
+func TestRunGroupTooLarge(t *testing.T) {
+	n, c := newTestNode(t)
+	defer n.close()
+
+	_, err := Run(context.Background(), c, &Options{Count: 1, GroupSize: 1})
+	assert.Regexp(t, "FF10556", err)
+}
+
+func TestRunSubscribeFail(t *testing.T) {
+	n, c := newTestNode(t)
+	defer n.close()
+	n.noWS = true
+
+	_, err := Run(context.Background(), c, &Options{Count: 1})
+	assert.Error(t, err)
+}
+
+func TestReportUnacceptedConfirmation(t *testing.T) {
+	b := &bench{
+		results: map[fftypes.UUID]*result{
+			*fftypes.NewUUID(): {received: time.Now()},
+		},
+	}
+	report := b.report(time.Second)
+	assert.Zero(t, report.Sent)
+	assert.Zero(t, report.Stages[0].Count)
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+	sl := newStageLatency(StageTotal, latencies)
+	assert.Equal(t, fftypes.FFDuration(50*time.Millisecond), sl.P50)
+	assert.Equal(t, fftypes.FFDuration(90*time.Millisecond), sl.P90)
+	assert.Equal(t, fftypes.FFDuration(99*time.Millisecond), sl.P99)
+	assert.Equal(t, fftypes.FFDuration(100*time.Millisecond), sl.Max)
+	assert.Equal(t, fftypes.FFDuration(time.Millisecond), percentile(latencies[:1], 0.99))
+}
+
+func TestWriteText(t *testing.T) {
+	report := &Report{
+		Sent:       10,
+		Confirmed:  10,
+		Elapsed:    fftypes.FFDuration(2 * time.Second),
+		Throughput: 5,
+		Stages: []*StageLatency{
+			{Stage: StageSubmit, Count: 10, P50: fftypes.FFDuration(time.Millisecond), Max: fftypes.FFDuration(3 * time.Millisecond)},
+		},
+	}
+	buf := &bytes.Buffer{}
+	err := report.WriteText(buf)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "Throughput:  5.00 msg/s")
+	assert.Regexp(t, `submit\s+10\s+1ms\s+0s\s+0s\s+3ms`, buf.String())
+}
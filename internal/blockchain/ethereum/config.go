@@ -32,6 +32,12 @@ const (
 	defaultAddressResolverResponseField = "address"
 	defaultAddressResolverCacheSize     = 1000
 	defaultAddressResolverCacheTTL      = "24h"
+
+	defaultRevertErrorsCacheSize = 1000
+	defaultRevertErrorsCacheTTL  = "24h"
+
+	defaultFinalityPollingInterval    = "1s"
+	defaultFinalityReorgCheckInterval = "10s"
 )
 
 const (
@@ -67,6 +73,41 @@ const (
 	AddressResolverCacheSize = "cache.size"
 	// AddressResolverCacheTTL the TTL on cache entries
 	AddressResolverCacheTTL = "cache.ttl"
+
+	// FinalityConfigKey is a sub-key in the config to contain the policy for when events are considered final
+	FinalityConfigKey = "finality"
+	// FinalityConfirmations the number of blocks that must be mined on top of an event before it is processed
+	FinalityConfirmations = "confirmations"
+	// FinalityBlockTag a block tag such as "finalized" or "safe" on PoS chains - events are processed once they are at or below the tagged block
+	FinalityBlockTag = "blockTag"
+	// FinalityPollingInterval how often to poll the node for the latest block, while waiting for events to become final
+	FinalityPollingInterval = "pollingInterval"
+	// FinalityReorgDepth the number of recent blocks containing batch pins to check for reorganizations, after the pins have been processed
+	FinalityReorgDepth = "reorgDepth"
+	// FinalityReorgCheckInterval how often to check the recent blocks containing batch pins for reorganizations
+	FinalityReorgCheckInterval = "reorgCheckInterval"
+	// FinalityRPCConfigKey is a sub-key containing the HTTP config for the JSON-RPC endpoint of an Ethereum node, used to check finality
+	FinalityRPCConfigKey = "rpc"
+
+	// MigrationConfigKey is a sub-key in the config to move batch pinning to a new BatchPin contract
+	MigrationConfigKey = "migration"
+	// MigrationInstance the address of the new BatchPin contract, which is listened to alongside the existing contract
+	MigrationInstance = "instance"
+	// MigrationActivationBlock the block from which batch pins are submitted to the new contract, and from which it is listened to
+	MigrationActivationBlock = "activationBlock"
+
+	// RevertErrorsConfigKey is a sub-key in the config for the cache of custom errors, used to decode the reason a submitted transaction reverted
+	RevertErrorsConfigKey = "revertErrors"
+	// RevertErrorsCacheSize the maximum number of in-flight operations to hold custom errors for
+	RevertErrorsCacheSize = "cache.size"
+	// RevertErrorsCacheTTL how long to hold the custom errors for an operation, waiting for its receipt
+	RevertErrorsCacheTTL = "cache.ttl"
+
+	// PrivateTransactionsConfigKey is a sub-key in the config to contain the private transaction config
+	PrivateTransactionsConfigKey = "privateTransactions"
+	// PrivateTransactionsEnabled when true batch pins for private groups are submitted as private transactions (privateFrom/privateFor) via a private transaction manager such as Tessera.
+	// The BatchPin contract must be available to private transactions, and every node must publish its key via node.privateTransactionKey
+	PrivateTransactionsEnabled = "enabled"
 )
 
 func (e *Ethereum) InitPrefix(prefix config.Prefix) {
@@ -88,4 +129,23 @@ func (e *Ethereum) InitPrefix(prefix config.Prefix) {
 	addressResolverConf.AddKnownKey(AddressResolverResponseField, defaultAddressResolverResponseField)
 	addressResolverConf.AddKnownKey(AddressResolverCacheSize, defaultAddressResolverCacheSize)
 	addressResolverConf.AddKnownKey(AddressResolverCacheTTL, defaultAddressResolverCacheTTL)
+
+	finalityConf := prefix.SubPrefix(FinalityConfigKey)
+	finalityConf.AddKnownKey(FinalityConfirmations, 0)
+	finalityConf.AddKnownKey(FinalityBlockTag)
+	finalityConf.AddKnownKey(FinalityPollingInterval, defaultFinalityPollingInterval)
+	finalityConf.AddKnownKey(FinalityReorgDepth, 0)
+	finalityConf.AddKnownKey(FinalityReorgCheckInterval, defaultFinalityReorgCheckInterval)
+	restclient.InitPrefix(finalityConf.SubPrefix(FinalityRPCConfigKey))
+
+	migrationConf := prefix.SubPrefix(MigrationConfigKey)
+	migrationConf.AddKnownKey(MigrationInstance)
+	migrationConf.AddKnownKey(MigrationActivationBlock, 0)
+
+	revertErrorsConf := prefix.SubPrefix(RevertErrorsConfigKey)
+	revertErrorsConf.AddKnownKey(RevertErrorsCacheSize, defaultRevertErrorsCacheSize)
+	revertErrorsConf.AddKnownKey(RevertErrorsCacheTTL, defaultRevertErrorsCacheTTL)
+
+	privateTXConf := prefix.SubPrefix(PrivateTransactionsConfigKey)
+	privateTXConf.AddKnownKey(PrivateTransactionsEnabled, false)
 }
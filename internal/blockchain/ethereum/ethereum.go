@@ -21,18 +21,23 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/config/wsconfig"
+	"github.com/hyperledger/firefly/internal/faults"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/restclient"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/wsclient"
+	"github.com/karlseguin/ccache"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
@@ -62,6 +67,11 @@ type Ethereum struct {
 	wsconn          wsclient.WSClient
 	closed          chan struct{}
 	addressResolver *addressResolver
+	finality        *finalityPolicy
+	reorgs          *reorgDetector
+	migration       *batchPinMigration
+	revertErrors    *ccache.Cache
+	revertErrorsTTL time.Duration
 }
 
 type eventStreamWebsocket struct {
@@ -122,11 +132,13 @@ type ABIElementMarshaling struct {
 }
 
 type EthconnectMessageRequest struct {
-	Headers EthconnectMessageHeaders `json:"headers,omitempty"`
-	To      string                   `json:"to"`
-	From    string                   `json:"from,omitempty"`
-	Method  ABIElementMarshaling     `json:"method"`
-	Params  []interface{}            `json:"params"`
+	Headers     EthconnectMessageHeaders `json:"headers,omitempty"`
+	To          string                   `json:"to"`
+	From        string                   `json:"from,omitempty"`
+	Method      ABIElementMarshaling     `json:"method"`
+	Params      []interface{}            `json:"params"`
+	PrivateFrom string                   `json:"privateFrom,omitempty"`
+	PrivateFor  []string                 `json:"privateFor,omitempty"`
 }
 
 type EthconnectMessageHeaders struct {
@@ -163,34 +175,41 @@ func (e *Ethereum) Init(ctx context.Context, prefix config.Prefix, callbacks blo
 		}
 	}
 
+	finalityConf := prefix.SubPrefix(FinalityConfigKey)
+	if e.finality, err = newFinalityPolicy(ctx, finalityConf); err != nil {
+		return err
+	}
+	e.reorgs = newReorgDetector(e.finality, finalityConf, callbacks)
+
+	revertErrorsConf := prefix.SubPrefix(RevertErrorsConfigKey)
+	e.revertErrors = ccache.New(ccache.Configure().MaxSize(revertErrorsConf.GetInt64(RevertErrorsCacheSize)))
+	e.revertErrorsTTL = revertErrorsConf.GetDuration(RevertErrorsCacheTTL)
+
 	if ethconnectConf.GetString(restclient.HTTPConfigURL) == "" {
 		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "url", "blockchain.ethconnect")
 	}
 
 	e.client = restclient.New(e.ctx, ethconnectConf)
+	faults.WrapClient(e.client, faults.PluginBlockchain)
 	e.capabilities = &blockchain.Capabilities{
-		GlobalSequencer: true,
+		GlobalSequencer:     true,
+		PrivateTransactions: prefix.SubPrefix(PrivateTransactionsConfigKey).GetBool(PrivateTransactionsEnabled),
 	}
 
 	e.instancePath = ethconnectConf.GetString(EthconnectConfigInstancePath)
 	if e.instancePath == "" {
 		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "instance", "blockchain.ethconnect")
 	}
+	if e.instancePath, err = e.resolveInstancePath(ctx, e.instancePath); err != nil {
+		return err
+	}
 
-	// Backwards compatibility from when instance path was not a contract address
-	if strings.HasPrefix(strings.ToLower(e.instancePath), "/contracts/") {
-		address, err := e.getContractAddress(ctx, e.instancePath)
-		if err != nil {
+	migrationConf := prefix.SubPrefix(MigrationConfigKey)
+	if migrationInstance := migrationConf.GetString(MigrationInstance); migrationInstance != "" {
+		if migrationInstance, err = e.resolveInstancePath(ctx, migrationInstance); err != nil {
 			return err
 		}
-		e.instancePath = address
-	} else if strings.HasPrefix(e.instancePath, "/instances/") {
-		e.instancePath = strings.Replace(e.instancePath, "/instances/", "", 1)
-	}
-
-	// Ethconnect needs the "0x" prefix in some cases
-	if !strings.HasPrefix(e.instancePath, "0x") {
-		e.instancePath = fmt.Sprintf("0x%s", e.instancePath)
+		e.migration = newBatchPinMigration(e.instancePath, migrationInstance, uint64(migrationConf.GetUint(MigrationActivationBlock)))
 	}
 
 	e.topic = ethconnectConf.GetString(EthconnectConfigTopic)
@@ -221,9 +240,15 @@ func (e *Ethereum) Init(ctx context.Context, prefix config.Prefix, callbacks blo
 		return err
 	}
 	log.L(e.ctx).Infof("Event stream: %s (topic=%s)", e.initInfo.stream.ID, e.topic)
-	if e.initInfo.sub, err = e.streams.ensureSubscription(e.ctx, e.instancePath, e.initInfo.stream.ID, batchPinEventABI); err != nil {
+	if e.initInfo.sub, err = e.streams.ensureSubscription(e.ctx, e.instancePath, e.initInfo.stream.ID, batchPinEventABI, string(fftypes.SubOptsFirstEventOldest)); err != nil {
 		return err
 	}
+	if e.migration != nil {
+		// Listen to the new contract from the activation block, alongside the existing contract
+		if e.migration.sub, err = e.streams.ensureSubscription(e.ctx, e.migration.status.To, e.initInfo.stream.ID, batchPinEventABI, e.migration.firstEvent()); err != nil {
+			return err
+		}
+	}
 
 	e.closed = make(chan struct{})
 	go e.eventLoop()
@@ -231,6 +256,26 @@ func (e *Ethereum) Init(ctx context.Context, prefix config.Prefix, callbacks blo
 	return nil
 }
 
+// resolveInstancePath returns the address of a BatchPin contract from its configured instance path
+func (e *Ethereum) resolveInstancePath(ctx context.Context, instancePath string) (string, error) {
+	// Backwards compatibility from when instance path was not a contract address
+	if strings.HasPrefix(strings.ToLower(instancePath), "/contracts/") {
+		address, err := e.getContractAddress(ctx, instancePath)
+		if err != nil {
+			return "", err
+		}
+		instancePath = address
+	} else if strings.HasPrefix(instancePath, "/instances/") {
+		instancePath = strings.Replace(instancePath, "/instances/", "", 1)
+	}
+
+	// Ethconnect needs the "0x" prefix in some cases
+	if !strings.HasPrefix(instancePath, "0x") {
+		instancePath = fmt.Sprintf("0x%s", instancePath)
+	}
+	return instancePath, nil
+}
+
 func (e *Ethereum) Start() error {
 	return e.wsconn.Connect()
 }
@@ -344,11 +389,24 @@ func (e *Ethereum) handleBatchPinEvent(ctx context.Context, msgJSON fftypes.JSON
 		},
 	}
 
+	blockHash := msgJSON.GetString("blockHash")
+	if e.reorgs != nil {
+		if err := e.reorgs.delivered(ctx, blockNumber, blockHash); err != nil {
+			return err
+		}
+	}
+
 	// If there's an error dispatching the event, we must return the error and shutdown
-	return e.callbacks.BatchPinComplete(batch, &fftypes.VerifierRef{
+	if err := e.callbacks.BatchPinComplete(batch, &fftypes.VerifierRef{
 		Type:  fftypes.VerifierTypeEthAddress,
 		Value: authorAddress,
-	})
+	}); err != nil {
+		return err
+	}
+	if e.reorgs != nil {
+		e.reorgs.track(blockNumber, blockHash, &batchID)
+	}
+	return nil
 }
 
 func (e *Ethereum) handleContractEvent(ctx context.Context, msgJSON fftypes.JSONObject) (err error) {
@@ -401,17 +459,95 @@ func (e *Ethereum) handleReceipt(ctx context.Context, reply fftypes.JSONObject)
 		l.Errorf("Reply cannot be processed - bad ID: %+v", reply)
 		return nil // Swallow this and move on
 	}
+	if e.migration != nil {
+		e.migration.observeBlock(ctx, reply.GetString("blockNumber"))
+	}
 	updateType := fftypes.OpStatusSucceeded
 	if replyType != "TransactionSuccess" {
 		updateType = fftypes.OpStatusFailed
 	}
+	var errorData *fftypes.BlockchainErrorData
+	if updateType == fftypes.OpStatusFailed {
+		message, errorData = e.decodeRevertReason(requestID, reply, message)
+	}
 	l.Infof("Ethconnect '%s' reply: request=%s tx=%s message=%s", replyType, requestID, txHash, message)
-	return e.callbacks.BlockchainOpUpdate(operationID, updateType, txHash, message, reply)
+	return e.callbacks.BlockchainOpUpdate(operationID, e.buildTransaction(reply, txHash, updateType), message, reply, buildReceipt(reply, updateType, message, errorData))
+}
+
+// ethereumTransactionExtras are the fields of the reply only Ethereum transactions have
+var ethereumTransactionExtras = []string{"nonce", "transactionIndex", "to", "contractAddress"}
+
+// buildTransaction describes the transaction in the normalized form shared with other blockchains. A mined
+// transaction is only final when no finality policy is configured, as otherwise confirmations are still required.
+func (e *Ethereum) buildTransaction(reply fftypes.JSONObject, txHash string, updateType fftypes.OpStatus) *fftypes.BlockchainTransaction {
+	tx := &fftypes.BlockchainTransaction{
+		ID:     txHash,
+		Signer: reply.GetString("from"),
+		Status: updateType,
+	}
+	if blockNumber := replyInteger(reply, "blockNumber"); blockNumber != nil {
+		bn := blockNumber.Int().Uint64()
+		tx.Finality = &fftypes.BlockchainFinality{
+			BlockNumber: &bn,
+			BlockHash:   reply.GetString("blockHash"),
+			Final:       e.finality == nil,
+		}
+	}
+	for _, key := range ethereumTransactionExtras {
+		if v, ok := reply.GetStringOk(key); ok {
+			if tx.Extras == nil {
+				tx.Extras = fftypes.JSONObject{}
+			}
+			tx.Extras[key] = v
+		}
+	}
+	return tx
+}
+
+func replyInteger(reply fftypes.JSONObject, keys ...string) *fftypes.FFBigInt {
+	for _, key := range keys {
+		if s, ok := reply.GetStringOk(key); ok {
+			if i, ok := new(big.Int).SetString(s, 0); ok {
+				return (*fftypes.FFBigInt)(i)
+			}
+		}
+	}
+	return nil
 }
 
-func (e *Ethereum) handleMessageBatch(ctx context.Context, messages []interface{}) error {
+// buildReceipt extracts the cost of the transaction from the reply. The fee is only known when
+// the connector reports both the gas used and the price paid for it.
+func buildReceipt(reply fftypes.JSONObject, updateType fftypes.OpStatus, message string, errorData *fftypes.BlockchainErrorData) *fftypes.BlockchainReceipt {
+	receipt := &fftypes.BlockchainReceipt{
+		GasUsed:           replyInteger(reply, "gasUsed"),
+		EffectiveGasPrice: replyInteger(reply, "effectiveGasPrice", "gasPrice"),
+	}
+	if blockNumber := replyInteger(reply, "blockNumber"); blockNumber != nil {
+		bn := blockNumber.Int().Uint64()
+		receipt.BlockNumber = &bn
+	}
+	if receipt.GasUsed != nil && receipt.EffectiveGasPrice != nil {
+		receipt.Fee = (*fftypes.FFBigInt)(new(big.Int).Mul(receipt.GasUsed.Int(), receipt.EffectiveGasPrice.Int()))
+	}
+	if updateType == fftypes.OpStatusFailed {
+		receipt.RevertReason = message
+		receipt.ErrorData = errorData
+	}
+	if receipt.BlockNumber == nil && receipt.GasUsed == nil && receipt.RevertReason == "" {
+		return nil
+	}
+	return receipt
+}
+
+func (e *Ethereum) handleMessageBatch(ctx context.Context, messages []interface{}) (err error) {
 	l := log.L(ctx)
 
+	if e.finality != nil {
+		if messages, err = e.finality.waitForFinality(ctx, messages); err != nil {
+			return err
+		}
+	}
+
 	for i, msgI := range messages {
 		msgMap, ok := msgI.(map[string]interface{})
 		if !ok {
@@ -427,7 +563,11 @@ func (e *Ethereum) handleMessageBatch(ctx context.Context, messages []interface{
 		l1.Infof("Received '%s' message", signature)
 		l1.Tracef("Message: %+v", msgJSON)
 
-		if sub == e.initInfo.sub.ID {
+		if e.migration != nil {
+			e.migration.observeBlock(ctx1, msgJSON.GetString("blockNumber"))
+		}
+
+		if sub == e.initInfo.sub.ID || (e.migration != nil && e.migration.isSubscription(sub)) {
 			switch signature {
 			case broadcastBatchEventSignature:
 				if err := e.handleBatchPinEvent(ctx1, msgJSON); err != nil {
@@ -450,11 +590,22 @@ func (e *Ethereum) eventLoop() {
 	l := log.L(e.ctx).WithField("role", "event-loop")
 	ctx := log.WithLogger(e.ctx, l)
 	ack, _ := json.Marshal(map[string]string{"type": "ack", "topic": e.topic})
+	var reorgCheck <-chan time.Time
+	if e.reorgs != nil {
+		ticker := time.NewTicker(e.reorgs.interval)
+		defer ticker.Stop()
+		reorgCheck = ticker.C
+	}
 	for {
 		select {
 		case <-ctx.Done():
 			l.Debugf("Event loop exiting (context cancelled)")
 			return
+		case <-reorgCheck:
+			if err := e.reorgs.check(ctx); err != nil {
+				l.Errorf("Event loop exiting: %s", err)
+				return
+			}
 		case msgBytes, ok := <-e.wsconn.Receive():
 			if !ok {
 				l.Debugf("Event loop exiting (receive channel closed)")
@@ -510,16 +661,18 @@ func (e *Ethereum) NormalizeSigningKey(ctx context.Context, key string) (string,
 	return resolved, err
 }
 
-func (e *Ethereum) invokeContractMethod(ctx context.Context, address, signingKey string, abi ABIElementMarshaling, requestID string, input []interface{}) (*resty.Response, error) {
+func (e *Ethereum) invokeContractMethod(ctx context.Context, address, signingKey string, abi ABIElementMarshaling, requestID string, input []interface{}, privateFrom string, privateFor []string) (*resty.Response, error) {
 	body := EthconnectMessageRequest{
 		Headers: EthconnectMessageHeaders{
 			Type: "SendTransaction",
 			ID:   requestID,
 		},
-		From:   signingKey,
-		To:     address,
-		Method: abi,
-		Params: input,
+		From:        signingKey,
+		To:          address,
+		Method:      abi,
+		Params:      input,
+		PrivateFrom: privateFrom,
+		PrivateFor:  privateFor,
 	}
 	return e.client.R().
 		SetContext(ctx).
@@ -557,14 +710,32 @@ func (e *Ethereum) SubmitBatchPin(ctx context.Context, operationID *fftypes.UUID
 		batch.BatchPayloadRef,
 		ethHashes,
 	}
-	res, err := e.invokeContractMethod(ctx, e.instancePath, signingKey, batchPinMethodABI, operationID.String(), input)
+	address := e.instancePath
+	if e.migration != nil {
+		address = e.migration.address()
+	}
+	res, err := e.invokeContractMethod(ctx, address, signingKey, batchPinMethodABI, operationID.String(), input, batch.PrivateFrom, batch.PrivateFor)
 	if err != nil || !res.IsSuccess() {
 		return restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
 	}
 	return nil
 }
 
-func (e *Ethereum) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) error {
+func (e *Ethereum) GetBatchPinMigration(ctx context.Context) *fftypes.BatchPinMigration {
+	if e.migration == nil {
+		return nil
+	}
+	return e.migration.snapshot()
+}
+
+func (e *Ethereum) ActivateBatchPinMigration(ctx context.Context) (*fftypes.BatchPinMigration, error) {
+	if e.migration == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgNoBatchPinMigration)
+	}
+	return e.migration.activate(ctx), nil
+}
+
+func (e *Ethereum) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors fftypes.FFIErrors) error {
 	ethereumLocation, err := parseContractLocation(ctx, location)
 	if err != nil {
 		return err
@@ -573,7 +744,15 @@ func (e *Ethereum) InvokeContract(ctx context.Context, operationID *fftypes.UUID
 	if err != nil {
 		return err
 	}
-	res, err := e.invokeContractMethod(ctx, ethereumLocation.Address, signingKey, abi, operationID.String(), orderedInput)
+	if len(errors) > 0 {
+		// Keep the custom errors until the receipt arrives, so a revert can be decoded
+		abiErrors, err := e.abiErrorsFromFFI(ctx, errors)
+		if err != nil {
+			return err
+		}
+		e.revertErrors.Set(operationID.String(), abiErrors, e.revertErrorsTTL)
+	}
+	res, err := e.invokeContractMethod(ctx, ethereumLocation.Address, signingKey, abi, operationID.String(), orderedInput, "", nil)
 	if err != nil || !res.IsSuccess() {
 		return restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
 	}
@@ -627,7 +806,11 @@ func (e *Ethereum) AddContractListener(ctx context.Context, listener *fftypes.Co
 	}
 
 	subName := fmt.Sprintf("ff-sub-%s", listener.ID)
-	result, err := e.streams.createSubscription(ctx, location, e.initInfo.stream.ID, subName, listener.Options.FirstEvent, abi)
+	firstEvent := listener.Options.FirstEvent
+	if listener.Options.FromBlock != nil {
+		firstEvent = strconv.FormatUint(*listener.Options.FromBlock, 10)
+	}
+	result, err := e.streams.createSubscription(ctx, location, e.initInfo.stream.ID, subName, firstEvent, abi)
 	if err != nil {
 		return err
 	}
@@ -639,6 +822,13 @@ func (e *Ethereum) DeleteContractListener(ctx context.Context, subscription *fft
 	return e.streams.deleteSubscription(ctx, subscription.ProtocolID)
 }
 
+func (e *Ethereum) RewindContractListener(ctx context.Context, subscription *fftypes.ContractListener, blockNumber uint64) (string, error) {
+	if err := e.streams.resetSubscription(ctx, subscription.ProtocolID, strconv.FormatUint(blockNumber, 10)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%.12d", blockNumber), nil
+}
+
 func (e *Ethereum) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
 	return &FFIParamValidator{}, nil
 }
@@ -803,6 +993,12 @@ func (e *Ethereum) convertABIToFFI(ns, name, version, description string, abi []
 				Returns: e.convertABIArgumentsToFFI(element.Outputs),
 			}
 			ffi.Methods = append(ffi.Methods, method)
+		case "error":
+			ffiError := &fftypes.FFIError{
+				Name:   element.Name,
+				Params: e.convertABIArgumentsToFFI(element.Inputs),
+			}
+			ffi.Errors = append(ffi.Errors, ffiError)
 		}
 	}
 	return ffi
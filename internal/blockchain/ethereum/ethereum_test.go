@@ -23,17 +23,20 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/wsmocks"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/hyperledger/firefly/pkg/wsclient"
 	"github.com/jarcoal/httpmock"
+	"github.com/karlseguin/ccache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -41,6 +44,7 @@ import (
 var utConfPrefix = config.NewPluginConfig("eth_unit_tests")
 var utEthconnectConf = utConfPrefix.SubPrefix(EthconnectConfigKey)
 var utAddressResolverConf = utConfPrefix.SubPrefix(AddressResolverConfigKey)
+var utMigrationConf = utConfPrefix.SubPrefix(MigrationConfigKey)
 
 func testFFIMethod() *fftypes.FFIMethod {
 	return &fftypes.FFIMethod{
@@ -83,6 +87,7 @@ func newTestEthereum() (*Ethereum, func()) {
 		prefixLong:   defaultPrefixLong,
 		callbacks:    em,
 		wsconn:       wsm,
+		revertErrors: ccache.New(ccache.Configure().MaxSize(100)),
 	}
 	return e, func() {
 		cancel()
@@ -110,6 +115,15 @@ func TestInitBadAddressResolver(t *testing.T) {
 	assert.Regexp(t, "FF10337.*urlTemplate", err)
 }
 
+func TestInitBadFinality(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf()
+	utConfPrefix.SubPrefix(FinalityConfigKey).Set(FinalityConfirmations, 12)
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{})
+	assert.Regexp(t, "FF10138.*finality.rpc", err)
+}
+
 func TestInitMissingInstance(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -178,6 +192,7 @@ func TestInitAllNewStreamsAndWSEvent(t *testing.T) {
 	assert.Equal(t, "es12345", e.initInfo.stream.ID)
 	assert.Equal(t, "sub12345", e.initInfo.sub.ID)
 	assert.True(t, e.Capabilities().GlobalSequencer)
+	assert.False(t, e.Capabilities().PrivateTransactions)
 
 	err = e.Start()
 	assert.NoError(t, err)
@@ -374,6 +389,108 @@ func TestInitOldInstancePathError(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 }
 
+func TestInitMigration(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf()
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams",
+		httpmock.NewJsonResponderOrPanic(200, []eventStream{}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/eventstreams",
+		httpmock.NewJsonResponderOrPanic(200, eventStream{ID: "es12345"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions",
+		httpmock.NewJsonResponderOrPanic(200, []subscription{}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/subscriptions",
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			if body["address"] == "0x67890" {
+				assert.Equal(t, "1000", body["fromBlock"])
+				return httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub67890"})(req)
+			}
+			assert.Equal(t, "0", body["fromBlock"])
+			return httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub12345"})(req)
+		})
+
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(restclient.HTTPCustomClient, mockedClient)
+	utEthconnectConf.Set(EthconnectConfigInstancePath, "0x12345")
+	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
+	utMigrationConf.Set(MigrationInstance, "/instances/67890")
+	utMigrationConf.Set(MigrationActivationBlock, 1000)
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{})
+	assert.NoError(t, err)
+	assert.Equal(t, "sub12345", e.initInfo.sub.ID)
+	assert.Equal(t, "sub67890", e.migration.sub.ID)
+	assert.Equal(t, &fftypes.BatchPinMigration{
+		From:            "0x12345",
+		To:              "0x67890",
+		ActivationBlock: 1000,
+	}, e.GetBatchPinMigration(context.Background()))
+}
+
+func TestInitMigrationBadInstance(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf()
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/contracts/firefly2",
+		httpmock.NewJsonResponderOrPanic(500, "pop"))
+
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(restclient.HTTPCustomClient, mockedClient)
+	utEthconnectConf.Set(EthconnectConfigInstancePath, "0x12345")
+	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
+	utMigrationConf.Set(MigrationInstance, "/contracts/firefly2")
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{})
+	assert.Regexp(t, "FF10111", err)
+}
+
+func TestInitMigrationSubscriptionFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	resetConf()
+
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/eventstreams",
+		httpmock.NewJsonResponderOrPanic(200, []eventStream{}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/eventstreams",
+		httpmock.NewJsonResponderOrPanic(200, eventStream{ID: "es12345"}))
+	httpmock.RegisterResponder("GET", "http://localhost:12345/subscriptions",
+		httpmock.NewJsonResponderOrPanic(200, []subscription{}))
+	httpmock.RegisterResponder("POST", "http://localhost:12345/subscriptions",
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			if body["address"] == "0x67890" {
+				return httpmock.NewJsonResponderOrPanic(500, "pop")(req)
+			}
+			return httpmock.NewJsonResponderOrPanic(200, subscription{ID: "sub12345"})(req)
+		})
+
+	utEthconnectConf.Set(restclient.HTTPConfigURL, "http://localhost:12345")
+	utEthconnectConf.Set(restclient.HTTPCustomClient, mockedClient)
+	utEthconnectConf.Set(EthconnectConfigInstancePath, "0x12345")
+	utEthconnectConf.Set(EthconnectConfigTopic, "topic1")
+	utMigrationConf.Set(MigrationInstance, "0x67890")
+
+	err := e.Init(e.ctx, utConfPrefix, &blockchainmocks.Callbacks{})
+	assert.Regexp(t, "FF10111", err)
+}
+
 func TestStreamQueryError(t *testing.T) {
 
 	e, cancel := newTestEthereum()
@@ -547,6 +664,107 @@ func TestSubmitBatchPinOK(t *testing.T) {
 			assert.Equal(t, "0x9ffc50ff6bfe4502adc793aea54cc059c5df767cfe444e038eb51c5523097db5", params[1])
 			assert.Equal(t, ethHexFormatB32(batch.BatchHash), params[2])
 			assert.Equal(t, "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD", params[3])
+			assert.Nil(t, body["privateFor"])
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+
+	err := e.SubmitBatchPin(context.Background(), nil, nil, addr, batch)
+
+	assert.NoError(t, err)
+
+}
+
+func TestSubmitBatchPinMigration(t *testing.T) {
+
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	e.migration = newBatchPinMigration("0x12345", "0x67890", 1000)
+
+	addr := ethHexFormatB32(fftypes.NewRandB32())
+	batch := &blockchain.BatchPin{
+		TransactionID:   fftypes.NewUUID(),
+		BatchID:         fftypes.NewUUID(),
+		BatchHash:       fftypes.NewRandB32(),
+		BatchPayloadRef: "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+		Contexts:        []*fftypes.Bytes32{},
+	}
+
+	var to string
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			to = body["to"].(string)
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+
+	err := e.SubmitBatchPin(context.Background(), nil, nil, addr, batch)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x12345", to)
+
+	// A receipt from the activation block switches to the new contract
+	em := &blockchainmocks.Callbacks{}
+	e.callbacks = em
+	opID := fftypes.NewUUID()
+	em.On("BlockchainOpUpdate", opID, mock.Anything, "", mock.Anything, mock.Anything).Return(nil)
+	err = e.handleReceipt(context.Background(), fftypes.JSONObject{
+		"blockNumber": "1000",
+		"headers": map[string]interface{}{
+			"requestId": opID.String(),
+			"type":      "TransactionSuccess",
+		},
+	})
+	assert.NoError(t, err)
+	em.AssertExpectations(t)
+
+	err = e.SubmitBatchPin(context.Background(), nil, nil, addr, batch)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x67890", to)
+}
+
+func TestActivateBatchPinMigration(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+
+	assert.Nil(t, e.GetBatchPinMigration(context.Background()))
+	_, err := e.ActivateBatchPinMigration(context.Background())
+	assert.Regexp(t, "FF10437", err)
+
+	e.migration = newBatchPinMigration("0x12345", "0x67890", 1000)
+	status, err := e.ActivateBatchPinMigration(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, status.Active)
+	assert.NotNil(t, status.Activated)
+	assert.Equal(t, "0x67890", e.migration.address())
+}
+
+func TestSubmitBatchPinPrivate(t *testing.T) {
+
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	addr := ethHexFormatB32(fftypes.NewRandB32())
+	batch := &blockchain.BatchPin{
+		TransactionID: fftypes.MustParseUUID("9ffc50ff-6bfe-4502-adc7-93aea54cc059"),
+		BatchID:       fftypes.MustParseUUID("c5df767c-fe44-4e03-8eb5-1c5523097db5"),
+		BatchHash:     fftypes.NewRandB32(),
+		Contexts: []*fftypes.Bytes32{
+			fftypes.NewRandB32(),
+		},
+		PrivateFrom: "key1",
+		PrivateFor:  []string{"key1", "key2"},
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "key1", body["privateFrom"])
+			assert.Equal(t, []interface{}{"key1", "key2"}, body["privateFor"])
 			return httpmock.NewJsonResponderOrPanic(200, "")(req)
 		})
 
@@ -869,6 +1087,115 @@ func TestHandleMessageBatchPinExit(t *testing.T) {
 
 }
 
+func TestHandleMessageBatchPinMigration(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x67890",
+		"blockNumber": "1000",
+		"transactionIndex": "0x1",
+		"transactionHash": "0x0c50dff0893e795293189d9cc5ba0d63c4020d8758ace4a69d02c9d6d43cb695",
+		"data": {
+			"author": "0x91d2b4381a4cd5c7c0f27565a7d4b829844c8635",
+			"namespace": "ns1",
+			"uuids": "0xe19af8b390604051812d7597d19adfb9a04c7cc37d444c2ba3b054e21326697e",
+			"batchHash": "0x9c19a93b6e85fee041f60f097121829e54cd4aa97ed070d1bc76147caf911fed",
+			"payloadRef": "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD"
+    },
+		"subId": "sb-new",
+		"signature": "BatchPin(address,uint256,string,bytes32,bytes32,string,bytes32[])",
+		"logIndex": "51",
+		"timestamp": "1620576488"
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{
+		callbacks: em,
+		migration: newBatchPinMigration("0x12345", "0x67890", 1000),
+	}
+	e.initInfo.sub = &subscription{
+		ID: "sb-old",
+	}
+	e.migration.sub = &subscription{
+		ID: "sb-new",
+	}
+
+	em.On("BatchPinComplete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+	status := e.GetBatchPinMigration(context.Background())
+	assert.True(t, status.Active)
+	assert.Equal(t, uint64(1000), *status.LatestBlock)
+}
+
+func TestHandleMessageBatchPinReorg(t *testing.T) {
+	data := fftypes.JSONAnyPtr(`
+[
+  {
+		"address": "0x1C197604587F046FD40684A8f21f4609FB811A7b",
+		"blockNumber": "38011",
+		"blockHash": "0xaaaa",
+		"transactionIndex": "0x1",
+		"transactionHash": "0x0c50dff0893e795293189d9cc5ba0d63c4020d8758ace4a69d02c9d6d43cb695",
+		"data": {
+			"author": "0x91d2b4381a4cd5c7c0f27565a7d4b829844c8635",
+			"namespace": "ns1",
+			"uuids": "0xe19af8b390604051812d7597d19adfb9a04c7cc37d444c2ba3b054e21326697e",
+			"batchHash": "0x9c19a93b6e85fee041f60f097121829e54cd4aa97ed070d1bc76147caf911fed",
+			"payloadRef": "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD"
+    },
+		"subId": "sb-b5b97a4e-a317-4053-6400-1474650efcb5",
+		"signature": "BatchPin(address,uint256,string,bytes32,bytes32,string,bytes32[])",
+		"logIndex": "51",
+		"timestamp": "1620576488"
+  }
+]`)
+
+	em := &blockchainmocks.Callbacks{}
+	oldBatchID := fftypes.NewUUID()
+	e := &Ethereum{
+		callbacks: em,
+		reorgs: &reorgDetector{
+			callbacks: em,
+			blocks: map[int64]*trackedBlock{
+				38011: {hash: "0xbbbb", batchIDs: []*fftypes.UUID{oldBatchID}},
+			},
+		},
+	}
+	e.initInfo.sub = &subscription{
+		ID: "sb-b5b97a4e-a317-4053-6400-1474650efcb5",
+	}
+
+	em.On("ChainReorg", &blockchain.Reorg{BlockNumber: 38011, BatchIDs: []*fftypes.UUID{oldBatchID}}).Return(nil).Once()
+	em.On("BatchPinComplete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	var events []interface{}
+	err := json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.NoError(t, err)
+
+	tracked := e.reorgs.blocks[38011]
+	assert.Equal(t, "0xaaaa", tracked.hash)
+	assert.Equal(t, "a04c7cc3-7d44-4c2b-a3b0-54e21326697e", tracked.batchIDs[0].String())
+
+	tracked.hash = "0xcccc"
+	em.On("ChainReorg", mock.Anything).Return(fmt.Errorf("pop"))
+	err = json.Unmarshal(data.Bytes(), &events)
+	assert.NoError(t, err)
+	err = e.handleMessageBatch(context.Background(), events)
+	assert.EqualError(t, err, "pop")
+
+	em.AssertExpectations(t)
+}
+
 func TestHandleMessageBatchPinEmpty(t *testing.T) {
 	em := &blockchainmocks.Callbacks{}
 	e := &Ethereum{callbacks: em}
@@ -890,6 +1217,21 @@ func TestHandleMessageBatchPinEmpty(t *testing.T) {
 	assert.Equal(t, 0, len(em.Calls))
 }
 
+func TestHandleMessageBatchFinalityCancelled(t *testing.T) {
+	em := &blockchainmocks.Callbacks{}
+	e := &Ethereum{callbacks: em}
+	e.finality = &finalityPolicy{
+		client: resty.New().SetBaseURL("http://localhost:12345"),
+		retry:  &retry.Retry{},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := e.handleMessageBatch(ctx, []interface{}{})
+	assert.Regexp(t, "FF10158", err)
+	assert.Equal(t, 0, len(em.Calls))
+}
+
 func TestHandleMessageBatchMissingData(t *testing.T) {
 	em := &blockchainmocks.Callbacks{}
 	e := &Ethereum{callbacks: em}
@@ -1088,6 +1430,25 @@ func TestEventLoopSendClosed(t *testing.T) {
 	e.eventLoop() // we're simply looking for it exiting
 }
 
+func TestEventLoopReorgCheckExit(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	rd, mcb, done := newTestReorgDetector(t, 100, map[int64]string{})
+	defer done()
+	rd.interval = time.Millisecond
+	rd.track(99, "0x99", fftypes.NewUUID())
+	mcb.On("ChainReorg", mock.Anything).Return(fmt.Errorf("pop"))
+	e.reorgs = rd
+
+	r := make(<-chan []byte)
+	wsm := e.wsconn.(*wsmocks.WSClient)
+	wsm.On("Receive").Return(r)
+	wsm.On("Close").Return()
+	e.closed = make(chan struct{})
+	e.eventLoop() // we're simply looking for it exiting
+	mcb.AssertExpectations(t)
+}
+
 func TestHandleReceiptTXSuccess(t *testing.T) {
 	em := &blockchainmocks.Callbacks{}
 	wsm := &wsmocks.WSClient{}
@@ -1105,6 +1466,7 @@ func TestHandleReceiptTXSuccess(t *testing.T) {
 		"blockHash": "0xad269b2b43481e44500f583108e8d24bd841fb767c7f526772959d195b9c72d5",
 		"blockNumber": "209696",
 		"cumulativeGasUsed": "24655",
+		"effectiveGasPrice": "0x3b9aca00",
 		"from": "0x91d2b4381a4cd5c7c0f27565a7d4b829844c8635",
 		"gasUsed": "24655",
 		"headers": {
@@ -1125,10 +1487,25 @@ func TestHandleReceiptTXSuccess(t *testing.T) {
 
 	em.On("BlockchainOpUpdate",
 		operationID,
-		fftypes.OpStatusSucceeded,
-		"0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8",
+		mock.MatchedBy(func(tx *fftypes.BlockchainTransaction) bool {
+			return tx.ID == "0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8" &&
+				tx.Status == fftypes.OpStatusSucceeded &&
+				tx.Signer == "0x91d2b4381a4cd5c7c0f27565a7d4b829844c8635" &&
+				*tx.Finality.BlockNumber == 209696 &&
+				tx.Finality.BlockHash == "0xad269b2b43481e44500f583108e8d24bd841fb767c7f526772959d195b9c72d5" &&
+				tx.Finality.Final &&
+				tx.Extras.GetString("nonce") == "0" &&
+				tx.Extras.GetString("to") == "0xd3266a857285fb75eb7df37353b4a15c8bb828f5"
+		}),
 		"",
-		mock.Anything).Return(nil)
+		mock.Anything,
+		mock.MatchedBy(func(receipt *fftypes.BlockchainReceipt) bool {
+			return *receipt.BlockNumber == 209696 &&
+				receipt.GasUsed.Int().Int64() == 24655 &&
+				receipt.EffectiveGasPrice.Int().Int64() == 1000000000 &&
+				receipt.Fee.Int().String() == "24655000000000" &&
+				receipt.RevertReason == ""
+		})).Return(nil)
 
 	err := json.Unmarshal(data.Bytes(), &reply)
 	assert.NoError(t, err)
@@ -1137,6 +1514,55 @@ func TestHandleReceiptTXSuccess(t *testing.T) {
 
 }
 
+func TestHandleReceiptTXFailedRevertReason(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	em := e.callbacks.(*blockchainmocks.Callbacks)
+
+	operationID := fftypes.NewUUID()
+	revertData := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000012" +
+		"496e73756666696369656e742066756e64730000000000000000000000000000"
+	reply := fftypes.JSONObject{
+		"blockNumber":     "209696",
+		"gasUsed":         "24655",
+		"errorMessage":    "Transaction reverted",
+		"revertReason":    revertData,
+		"transactionHash": "0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8",
+		"headers": map[string]interface{}{
+			"requestId": operationID.String(),
+			"type":      "TransactionFailure",
+		},
+	}
+
+	em.On("BlockchainOpUpdate",
+		operationID,
+		mock.MatchedBy(func(tx *fftypes.BlockchainTransaction) bool {
+			return tx.ID == "0x71a38acb7a5d4a970854f6d638ceb1fa10a4b59cbf4ed7674273a1a8dc8b36b8" &&
+				tx.Status == fftypes.OpStatusFailed &&
+				*tx.Finality.BlockNumber == 209696 &&
+				tx.Extras == nil
+		}),
+		"Insufficient funds",
+		mock.Anything,
+		mock.MatchedBy(func(receipt *fftypes.BlockchainReceipt) bool {
+			return receipt.RevertReason == "Insufficient funds" &&
+				receipt.ErrorData.Name == "Error" &&
+				receipt.ErrorData.Params.GetString("reason") == "Insufficient funds" &&
+				receipt.ErrorData.Data == revertData
+		})).Return(nil)
+
+	err := e.handleReceipt(context.Background(), reply)
+	assert.NoError(t, err)
+
+	em.AssertExpectations(t)
+}
+
+func TestBuildReceiptNoDetails(t *testing.T) {
+	assert.Nil(t, buildReceipt(fftypes.JSONObject{"gasUsed": "bad"}, fftypes.OpStatusSucceeded, "", nil))
+}
+
 func TestHandleBadPayloadsAndThenReceiptFailure(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1165,10 +1591,14 @@ func TestHandleBadPayloadsAndThenReceiptFailure(t *testing.T) {
 	em := e.callbacks.(*blockchainmocks.Callbacks)
 	txsu := em.On("BlockchainOpUpdate",
 		operationID,
-		fftypes.OpStatusFailed,
-		"",
+		&fftypes.BlockchainTransaction{
+			Status: fftypes.OpStatusFailed,
+		},
 		"Packing arguments for method 'broadcastBatch': abi: cannot use [3]uint8 as type [32]uint8 as argument",
-		mock.Anything).Return(fmt.Errorf("Shutdown"))
+		mock.Anything,
+		&fftypes.BlockchainReceipt{
+			RevertReason: "Packing arguments for method 'broadcastBatch': abi: cannot use [3]uint8 as type [32]uint8 as argument",
+		}).Return(fmt.Errorf("Shutdown"))
 	done := make(chan struct{})
 	txsu.RunFn = func(a mock.Arguments) {
 		close(done)
@@ -1268,6 +1698,45 @@ func TestAddSubscription(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestAddSubscriptionFromBlock(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.initInfo.stream = &eventStream{
+		ID: "es-1",
+	}
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	fromBlock := uint64(12345)
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &fftypes.FFISerializedEvent{},
+			Options: &fftypes.ContractListenerOptions{
+				FromBlock: &fromBlock,
+			},
+		},
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "12345", body["fromBlock"])
+			return httpmock.NewJsonResponderOrPanic(200, &subscription{})(req)
+		})
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.NoError(t, err)
+}
+
 func TestAddSubscriptionBadParamDetails(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1416,6 +1885,57 @@ func TestDeleteSubscriptionFail(t *testing.T) {
 	assert.Regexp(t, "FF10111", err)
 }
 
+func TestRewindSubscription(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := &fftypes.ContractListener{
+		ProtocolID: "sb-1",
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions/sb-1/reset`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]string
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "12345", body["initialBlock"])
+			return httpmock.NewStringResponse(204, ""), nil
+		})
+
+	checkpoint, err := e.RewindContractListener(context.Background(), sub, 12345)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "000000012345", checkpoint)
+	assert.Less(t, checkpoint, "000000012345/000000/000000")
+}
+
+func TestRewindSubscriptionFail(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := &fftypes.ContractListener{
+		ProtocolID: "sb-1",
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions/sb-1/reset`,
+		httpmock.NewStringResponder(500, ""))
+
+	_, err := e.RewindContractListener(context.Background(), sub, 12345)
+
+	assert.Regexp(t, "FF10111", err)
+}
+
 func TestHandleMessageContractEvent(t *testing.T) {
 	data := fftypes.JSONAnyPtr(`
 [
@@ -1580,10 +2100,72 @@ func TestInvokeContractOK(t *testing.T) {
 			assert.Equal(t, float64(2), params[1])
 			return httpmock.NewJsonResponderOrPanic(200, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.NoError(t, err)
 }
 
+func TestInvokeContractWithErrors(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+	signingKey := ethHexFormatB32(fftypes.NewRandB32())
+	location := &Location{
+		Address: "0x12345",
+	}
+	method := testFFIMethod()
+	params := map[string]interface{}{
+		"x": float64(1),
+		"y": float64(2),
+	}
+	errors := fftypes.FFIErrors{
+		{
+			Name: "Overflow",
+			Params: fftypes.FFIParams{
+				{Name: "limit", Schema: fftypes.JSONAnyPtr(`{"type":"integer","details":{"type":"uint256"}}`)},
+			},
+		},
+	}
+	locationBytes, err := json.Marshal(location)
+	assert.NoError(t, err)
+	httpmock.RegisterResponder("POST", `http://localhost:12345/`,
+		func(req *http.Request) (*http.Response, error) {
+			return httpmock.NewJsonResponderOrPanic(200, "")(req)
+		})
+	operationID := fftypes.NewUUID()
+	err = e.InvokeContract(context.Background(), operationID, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, errors)
+	assert.NoError(t, err)
+
+	item := e.revertErrors.Get(operationID.String())
+	assert.NotNil(t, item)
+	abiErrors := item.Value().([]*ABIElementMarshaling)
+	assert.Equal(t, "Overflow(uint256)", abiSignature(abiErrors[0]))
+}
+
+func TestInvokeContractBadErrors(t *testing.T) {
+	e, cancel := newTestEthereum()
+	defer cancel()
+	signingKey := ethHexFormatB32(fftypes.NewRandB32())
+	location := &Location{
+		Address: "0x12345",
+	}
+	method := testFFIMethod()
+	params := map[string]interface{}{
+		"x": float64(1),
+		"y": float64(2),
+	}
+	errors := fftypes.FFIErrors{
+		{
+			Name:   "Overflow",
+			Params: fftypes.FFIParams{{Name: "limit", Schema: fftypes.JSONAnyPtr(`{"type":"integer"`)}},
+		},
+	}
+	locationBytes, err := json.Marshal(location)
+	assert.NoError(t, err)
+	err = e.InvokeContract(context.Background(), fftypes.NewUUID(), signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, errors)
+	assert.Regexp(t, "unexpected EOF", err)
+}
+
 func TestInvokeContractAddressNotSet(t *testing.T) {
 	e, cancel := newTestEthereum()
 	defer cancel()
@@ -1596,7 +2178,7 @@ func TestInvokeContractAddressNotSet(t *testing.T) {
 	}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "'address' not set", err)
 }
 
@@ -1620,7 +2202,7 @@ func TestInvokeContractEthconnectError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "FF10111", err)
 }
 
@@ -1647,7 +2229,7 @@ func TestInvokeContractPrepareFail(t *testing.T) {
 	}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "invalid json", err)
 }
 
@@ -2061,6 +2643,15 @@ func TestConvertABIToFFI(t *testing.T) {
 			}},
 			Outputs: []ABIArgumentMarshaling{},
 		},
+		{
+			Name: "ValueTooLarge",
+			Type: "error",
+			Inputs: []ABIArgumentMarshaling{{
+				Name:         "value",
+				Type:         "uint256",
+				InternalType: "uint256",
+			}},
+		},
 	}
 
 	schema := fftypes.JSONAnyPtr(`{"type":"integer","details":{"type":"uint256","internalType":"uint256"}}`)
@@ -2105,6 +2696,17 @@ func TestConvertABIToFFI(t *testing.T) {
 				},
 			},
 		},
+		Errors: fftypes.FFIErrors{
+			{
+				Name: "ValueTooLarge",
+				Params: fftypes.FFIParams{
+					{
+						Name:   "value",
+						Schema: schema,
+					},
+				},
+			},
+		},
 	}
 
 	actualFFI := e.convertABIToFFI("default", "SimpleStorage", "v0.0.1", "desc", abi)
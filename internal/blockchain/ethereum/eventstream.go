@@ -160,6 +160,17 @@ func (s *streamManager) createSubscription(ctx context.Context, location *Locati
 	return &sub, nil
 }
 
+func (s *streamManager) resetSubscription(ctx context.Context, subID, initialBlock string) error {
+	res, err := s.client.R().
+		SetContext(ctx).
+		SetBody(map[string]string{"initialBlock": initialBlock}).
+		Post("/subscriptions/" + subID + "/reset")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgEthconnectRESTErr)
+	}
+	return nil
+}
+
 func (s *streamManager) deleteSubscription(ctx context.Context, subID string) error {
 	res, err := s.client.R().
 		SetContext(ctx).
@@ -170,7 +181,7 @@ func (s *streamManager) deleteSubscription(ctx context.Context, subID string) er
 	return nil
 }
 
-func (s *streamManager) ensureSubscription(ctx context.Context, instancePath, stream string, abi ABIElementMarshaling) (sub *subscription, err error) {
+func (s *streamManager) ensureSubscription(ctx context.Context, instancePath, stream string, abi ABIElementMarshaling, firstEvent string) (sub *subscription, err error) {
 	// Include a hash of the instance path in the subscription, so if we ever point at a different
 	// contract configuration, we re-subscribe from block 0.
 	// We don't need full strength hashing, so just use the first 16 chars for readability.
@@ -198,7 +209,7 @@ func (s *streamManager) ensureSubscription(ctx context.Context, instancePath, st
 	}
 
 	if sub == nil {
-		if sub, err = s.createSubscription(ctx, location, stream, subName, string(fftypes.SubOptsFirstEventOldest), abi); err != nil {
+		if sub, err = s.createSubscription(ctx, location, stream, subName, firstEvent, abi); err != nil {
 			return nil, err
 		}
 	}
@@ -0,0 +1,230 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/internal/retry"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// finalityPolicy holds back each batch of events from ethconnect until every event in the batch
+// is final, based on a number of confirmations, or a block tag such as "finalized" on PoS chains.
+// Once final, each event is re-checked against the transaction receipt on the node, so an event
+// that was removed by a reorg while it was waiting is dropped rather than processed.
+type finalityPolicy struct {
+	client        *resty.Client
+	confirmations int64
+	blockTag      string
+	retry         *retry.Retry
+}
+
+type jsonRPCRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int64         `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type jsonRPCError struct {
+	Code    int64  `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonRPCResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *jsonRPCError   `json:"error,omitempty"`
+}
+
+func newFinalityPolicy(ctx context.Context, prefix config.Prefix) (*finalityPolicy, error) {
+	fp := &finalityPolicy{
+		confirmations: prefix.GetInt64(FinalityConfirmations),
+		blockTag:      prefix.GetString(FinalityBlockTag),
+	}
+	if fp.confirmations <= 0 && fp.blockTag == "" && prefix.GetInt64(FinalityReorgDepth) <= 0 {
+		return nil, nil
+	}
+	rpcConf := prefix.SubPrefix(FinalityRPCConfigKey)
+	if rpcConf.GetString(restclient.HTTPConfigURL) == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgMissingPluginConfig, "url", "blockchain.ethereum.finality.rpc")
+	}
+	fp.client = restclient.New(ctx, rpcConf)
+	pollingInterval := prefix.GetDuration(FinalityPollingInterval)
+	fp.retry = &retry.Retry{
+		InitialDelay: pollingInterval,
+		MaximumDelay: pollingInterval,
+		Factor:       1,
+	}
+	return fp, nil
+}
+
+func (fp *finalityPolicy) rpc(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	var rpcRes jsonRPCResponse
+	res, err := fp.client.R().
+		SetContext(ctx).
+		SetBody(&jsonRPCRequest{
+			JSONRPC: "2.0",
+			ID:      time.Now().UnixNano(),
+			Method:  method,
+			Params:  params,
+		}).
+		SetResult(&rpcRes).
+		SetError(&rpcRes).
+		Post("")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgEthereumRPCErr)
+	}
+	if rpcRes.Error != nil {
+		return i18n.NewError(ctx, i18n.MsgEthereumRPCErr, rpcRes.Error.Message)
+	}
+	if err := json.Unmarshal(rpcRes.Result, result); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgEthereumRPCErr, err)
+	}
+	return nil
+}
+
+func parseHexInt(ctx context.Context, s string) (int64, error) {
+	i, ok := big.NewInt(0).SetString(s, 0)
+	if !ok {
+		return -1, i18n.NewError(ctx, i18n.MsgEthereumRPCErr, s)
+	}
+	return i.Int64(), nil
+}
+
+func (fp *finalityPolicy) headBlock(ctx context.Context) (int64, error) {
+	var head string
+	if err := fp.rpc(ctx, "eth_blockNumber", &head); err != nil {
+		return -1, err
+	}
+	return parseHexInt(ctx, head)
+}
+
+// finalizedBlock returns the highest block number that is considered final, or -1 if no block is final yet
+func (fp *finalityPolicy) finalizedBlock(ctx context.Context) (int64, error) {
+	if fp.blockTag != "" {
+		var block fftypes.JSONObject
+		if err := fp.rpc(ctx, "eth_getBlockByNumber", &block, fp.blockTag, false); err != nil {
+			return -1, err
+		}
+		if block == nil {
+			return -1, i18n.NewError(ctx, i18n.MsgEthereumBlockNotFound, fp.blockTag)
+		}
+		return parseHexInt(ctx, block.GetString("number"))
+	}
+	headNumber, err := fp.headBlock(ctx)
+	if err != nil {
+		return -1, err
+	}
+	return headNumber - fp.confirmations, nil
+}
+
+// waitForFinality blocks until all the events in the batch are final, then returns the
+// events that are still on the canonical chain
+func (fp *finalityPolicy) waitForFinality(ctx context.Context, messages []interface{}) ([]interface{}, error) {
+	l := log.L(ctx)
+	maxBlock := int64(-1)
+	for _, msgI := range messages {
+		if msgMap, ok := msgI.(map[string]interface{}); ok {
+			if blockNumber := fftypes.JSONObject(msgMap).GetInt64("blockNumber"); blockNumber > maxBlock {
+				maxBlock = blockNumber
+			}
+		}
+	}
+
+	err := fp.retry.DoCustomLog(ctx, func(attempt int) (retry bool, err error) {
+		finalized, err := fp.finalizedBlock(ctx)
+		if err != nil {
+			l.Errorf("Failed to query finalized block (attempt %d): %s", attempt, err)
+			return true, err
+		}
+		if finalized < maxBlock {
+			l.Debugf("Waiting for block %d to be final (finalized=%d)", maxBlock, finalized)
+			return true, i18n.NewError(ctx, i18n.MsgEthereumBlockNotFinal, maxBlock, finalized)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	final := make([]interface{}, 0, len(messages))
+	type minedBlock struct {
+		number int64
+		hash   string
+	}
+	receiptBlocks := map[string]*minedBlock{}
+	for _, msgI := range messages {
+		msgMap, ok := msgI.(map[string]interface{})
+		if !ok {
+			final = append(final, msgI) // will be discarded by the event processing
+			continue
+		}
+		msgJSON := fftypes.JSONObject(msgMap)
+		txHash := msgJSON.GetString("transactionHash")
+		if txHash == "" {
+			final = append(final, msgI)
+			continue
+		}
+		mined, checked := receiptBlocks[txHash]
+		if !checked {
+			mined = &minedBlock{}
+			err := fp.retry.DoCustomLog(ctx, func(attempt int) (retry bool, err error) {
+				mined.number, mined.hash, err = fp.receiptBlock(ctx, txHash)
+				if err != nil {
+					l.Errorf("Failed to query receipt for transaction %s (attempt %d): %s", txHash, attempt, err)
+				}
+				return true, err
+			})
+			if err != nil {
+				return nil, err
+			}
+			receiptBlocks[txHash] = mined
+		}
+		if blockNumber := msgJSON.GetInt64("blockNumber"); mined.number != blockNumber {
+			l.Warnf("Discarding event from transaction %s in block %d, which was removed by a chain reorganization (current block: %d)", txHash, blockNumber, mined.number)
+			continue
+		}
+		if msgJSON.GetString("blockHash") == "" {
+			// Record the hash of the block, so reorgs can be detected after the event has been processed
+			msgMap["blockHash"] = mined.hash
+		}
+		final = append(final, msgI)
+	}
+	return final, nil
+}
+
+// receiptBlock returns the block number and hash the transaction is currently mined in, or -1 if it is not mined
+func (fp *finalityPolicy) receiptBlock(ctx context.Context, txHash string) (int64, string, error) {
+	var receipt fftypes.JSONObject
+	if err := fp.rpc(ctx, "eth_getTransactionReceipt", &receipt, txHash); err != nil {
+		return -1, "", err
+	}
+	if receipt == nil {
+		return -1, "", nil
+	}
+	blockNumber, err := parseHexInt(ctx, receipt.GetString("blockNumber"))
+	return blockNumber, receipt.GetString("blockHash"), err
+}
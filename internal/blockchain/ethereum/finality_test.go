@@ -0,0 +1,247 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func utFinalityConfigPrefix() config.Prefix {
+	config.Reset()
+	prefix := config.NewPluginConfig("utfinality")
+	(&Ethereum{}).InitPrefix(prefix)
+	return prefix.SubPrefix(FinalityConfigKey)
+}
+
+func newTestFinalityServer(t *testing.T, handler func(method string, params []interface{}) (result interface{}, status int)) (config.Prefix, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var req jsonRPCRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		assert.NoError(t, err)
+		assert.Equal(t, "2.0", req.JSONRPC)
+		result, status := handler(req.Method, req.Params)
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(status)
+		if rpcErr, ok := result.(*jsonRPCError); ok {
+			json.NewEncoder(rw).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": rpcErr})
+			return
+		}
+		json.NewEncoder(rw).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	prefix := utFinalityConfigPrefix()
+	prefix.SubPrefix(FinalityRPCConfigKey).Set(restclient.HTTPConfigURL, server.URL)
+	prefix.Set(FinalityPollingInterval, "1ms")
+	return prefix, server.Close
+}
+
+func TestFinalityDisabled(t *testing.T) {
+	fp, err := newFinalityPolicy(context.Background(), utFinalityConfigPrefix())
+	assert.NoError(t, err)
+	assert.Nil(t, fp)
+}
+
+func TestFinalityMissingRPC(t *testing.T) {
+	prefix := utFinalityConfigPrefix()
+	prefix.Set(FinalityConfirmations, 5)
+	_, err := newFinalityPolicy(context.Background(), prefix)
+	assert.Regexp(t, "FF10138.*finality.rpc", err)
+}
+
+func TestFinalityConfirmationsWithReorg(t *testing.T) {
+	head := 0x60
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		switch method {
+		case "eth_blockNumber":
+			head++
+			return fmt.Sprintf("0x%x", head), 200
+		case "eth_getTransactionReceipt":
+			switch params[0] {
+			case "0x1111":
+				return map[string]interface{}{"blockNumber": "0x64"}, 200
+			case "0x2222":
+				return nil, 200
+			default:
+				return map[string]interface{}{"blockNumber": "0x66"}, 200
+			}
+		}
+		return nil, 404
+	})
+	defer done()
+	prefix.Set(FinalityConfirmations, 12)
+
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+
+	messages := []interface{}{
+		map[string]interface{}{"blockNumber": "100", "transactionHash": "0x1111", "logIndex": "0"},
+		map[string]interface{}{"blockNumber": "100", "transactionHash": "0x1111", "logIndex": "1"},
+		map[string]interface{}{"blockNumber": "101", "transactionHash": "0x2222"},
+		map[string]interface{}{"blockNumber": "101", "transactionHash": "0x3333"},
+		map[string]interface{}{"blockNumber": "101"},
+		"not an object",
+	}
+	final, err := fp.waitForFinality(context.Background(), messages)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{messages[0], messages[1], messages[4], messages[5]}, final)
+	assert.Equal(t, 101+12, head)
+}
+
+func TestFinalityBlockTag(t *testing.T) {
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		switch method {
+		case "eth_getBlockByNumber":
+			assert.Equal(t, "finalized", params[0])
+			return map[string]interface{}{"number": "0x64"}, 200
+		case "eth_getTransactionReceipt":
+			return map[string]interface{}{"blockNumber": "0x64", "blockHash": "0xabcd"}, 200
+		}
+		return nil, 404
+	})
+	defer done()
+	prefix.Set(FinalityBlockTag, "finalized")
+
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+
+	messages := []interface{}{
+		map[string]interface{}{"blockNumber": "100", "transactionHash": "0x1111"},
+		map[string]interface{}{"blockNumber": "100", "transactionHash": "0x1111", "blockHash": "0x1234"},
+	}
+	final, err := fp.waitForFinality(context.Background(), messages)
+	assert.NoError(t, err)
+	assert.Equal(t, messages, final)
+	assert.Equal(t, "0xabcd", messages[0].(map[string]interface{})["blockHash"])
+	assert.Equal(t, "0x1234", messages[1].(map[string]interface{})["blockHash"])
+}
+
+func TestFinalityBlockTagNotFound(t *testing.T) {
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		return nil, 200
+	})
+	defer done()
+	prefix.Set(FinalityBlockTag, "finalized")
+
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+
+	_, err = fp.finalizedBlock(context.Background())
+	assert.Regexp(t, "FF10400.*finalized", err)
+}
+
+func TestFinalityBlockTagRPCFail(t *testing.T) {
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		return &jsonRPCError{Code: -32000, Message: "pop"}, 200
+	})
+	defer done()
+	prefix.Set(FinalityBlockTag, "finalized")
+
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+
+	_, err = fp.finalizedBlock(context.Background())
+	assert.Regexp(t, "FF10398.*pop", err)
+}
+
+func TestFinalityBlockNumberHTTPFail(t *testing.T) {
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		return nil, 500
+	})
+	defer done()
+	prefix.Set(FinalityConfirmations, 1)
+
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+
+	_, err = fp.finalizedBlock(context.Background())
+	assert.Regexp(t, "FF10398", err)
+}
+
+func TestFinalityBlockNumberBadResult(t *testing.T) {
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		return 12345, 200
+	})
+	defer done()
+	prefix.Set(FinalityConfirmations, 1)
+
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+
+	_, err = fp.finalizedBlock(context.Background())
+	assert.Regexp(t, "FF10398", err)
+}
+
+func TestFinalityBlockNumberBadHex(t *testing.T) {
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		return "0xzzz", 200
+	})
+	defer done()
+	prefix.Set(FinalityConfirmations, 1)
+
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+
+	_, err = fp.finalizedBlock(context.Background())
+	assert.Regexp(t, "FF10398.*0xzzz", err)
+}
+
+func TestFinalityWaitCancelled(t *testing.T) {
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		return nil, 500
+	})
+	defer done()
+	prefix.Set(FinalityConfirmations, 1)
+
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = fp.waitForFinality(ctx, []interface{}{
+		map[string]interface{}{"blockNumber": "100", "transactionHash": "0x1111"},
+	})
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestFinalityReceiptCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		if method == "eth_blockNumber" {
+			return "0x100", 200
+		}
+		cancel()
+		return nil, 500
+	})
+	defer done()
+	prefix.Set(FinalityConfirmations, 1)
+
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+
+	_, err = fp.waitForFinality(ctx, []interface{}{
+		map[string]interface{}{"blockNumber": "100", "transactionHash": "0x1111"},
+	})
+	assert.Regexp(t, "FF10158", err)
+}
@@ -0,0 +1,115 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// batchPinMigration moves batch pinning from the configured BatchPin contract to a new one.
+// Pins are accepted from both contracts for the whole transition, so no event is lost whichever
+// contract a member submitted to. This node submits to the new contract once a block at or after the
+// activation block has been observed, or once an administrator activates the migration.
+type batchPinMigration struct {
+	mux    sync.Mutex
+	status fftypes.BatchPinMigration
+	sub    *subscription
+}
+
+func newBatchPinMigration(from, to string, activationBlock uint64) *batchPinMigration {
+	if to == "" {
+		return nil
+	}
+	return &batchPinMigration{
+		status: fftypes.BatchPinMigration{
+			From:            from,
+			To:              to,
+			ActivationBlock: activationBlock,
+		},
+	}
+}
+
+// firstEvent is where the subscription to the new contract starts
+func (m *batchPinMigration) firstEvent() string {
+	return strconv.FormatUint(m.status.ActivationBlock, 10)
+}
+
+func (m *batchPinMigration) isSubscription(subID string) bool {
+	return m.sub != nil && m.sub.ID == subID
+}
+
+// observeBlock is called with the block number of every event and receipt from the connector
+func (m *batchPinMigration) observeBlock(ctx context.Context, blockNumber string) {
+	bn, err := strconv.ParseUint(blockNumber, 10, 64)
+	if err != nil {
+		return
+	}
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.status.LatestBlock == nil || bn > *m.status.LatestBlock {
+		m.status.LatestBlock = &bn
+	}
+	if !m.status.Active && bn >= m.status.ActivationBlock {
+		log.L(ctx).Infof("BatchPin contract migration to %s activated by block %d", m.status.To, bn)
+		m.activateLocked()
+	}
+}
+
+func (m *batchPinMigration) activate(ctx context.Context) *fftypes.BatchPinMigration {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if !m.status.Active {
+		log.L(ctx).Infof("BatchPin contract migration to %s activated by an administrator", m.status.To)
+		m.activateLocked()
+	}
+	return m.snapshotLocked()
+}
+
+func (m *batchPinMigration) activateLocked() {
+	m.status.Active = true
+	m.status.Activated = fftypes.Now()
+}
+
+// address is the BatchPin contract that new pins are submitted to
+func (m *batchPinMigration) address() string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.status.Active {
+		return m.status.To
+	}
+	return m.status.From
+}
+
+func (m *batchPinMigration) snapshot() *fftypes.BatchPinMigration {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return m.snapshotLocked()
+}
+
+func (m *batchPinMigration) snapshotLocked() *fftypes.BatchPinMigration {
+	status := m.status
+	if m.status.LatestBlock != nil {
+		latest := *m.status.LatestBlock
+		status.LatestBlock = &latest
+	}
+	return &status
+}
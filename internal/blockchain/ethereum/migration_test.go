@@ -0,0 +1,59 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchPinMigrationNotConfigured(t *testing.T) {
+	assert.Nil(t, newBatchPinMigration("0x12345", "", 1000))
+}
+
+func TestBatchPinMigrationObserveBlock(t *testing.T) {
+	m := newBatchPinMigration("0x12345", "0x67890", 1000)
+	assert.Equal(t, "1000", m.firstEvent())
+	assert.False(t, m.isSubscription("sub1"))
+
+	m.observeBlock(context.Background(), "")
+	assert.Nil(t, m.snapshot().LatestBlock)
+
+	m.observeBlock(context.Background(), "999")
+	m.observeBlock(context.Background(), "998")
+	status := m.snapshot()
+	assert.Equal(t, uint64(999), *status.LatestBlock)
+	assert.False(t, status.Active)
+	assert.Equal(t, "0x12345", m.address())
+
+	m.observeBlock(context.Background(), "1001")
+	status = m.snapshot()
+	assert.Equal(t, uint64(1001), *status.LatestBlock)
+	assert.True(t, status.Active)
+	assert.Equal(t, "0x67890", m.address())
+
+	// The snapshot is a copy
+	*status.LatestBlock = 0
+	assert.Equal(t, uint64(1001), *m.snapshot().LatestBlock)
+
+	// Activating again does not change the activation time
+	activated := status.Activated
+	status = m.activate(context.Background())
+	assert.Equal(t, activated, status.Activated)
+}
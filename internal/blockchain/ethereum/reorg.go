@@ -0,0 +1,129 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// reorgDetector remembers the hashes of the recent blocks that contained batch pins, so that if one of
+// those blocks is later replaced by a chain reorganization the affected batches can be reported to the
+// core. The connector re-delivers any pins that are mined again on the new chain.
+// All functions are called from the event loop, so no locking is required.
+type reorgDetector struct {
+	fp        *finalityPolicy
+	callbacks blockchain.Callbacks
+	depth     int64
+	interval  time.Duration
+	blocks    map[int64]*trackedBlock
+}
+
+type trackedBlock struct {
+	hash     string
+	batchIDs []*fftypes.UUID
+}
+
+func newReorgDetector(fp *finalityPolicy, prefix config.Prefix, callbacks blockchain.Callbacks) *reorgDetector {
+	depth := prefix.GetInt64(FinalityReorgDepth)
+	if fp == nil || depth <= 0 {
+		return nil
+	}
+	return &reorgDetector{
+		fp:        fp,
+		callbacks: callbacks,
+		depth:     depth,
+		interval:  prefix.GetDuration(FinalityReorgCheckInterval),
+		blocks:    make(map[int64]*trackedBlock),
+	}
+}
+
+// delivered is called before a batch pin is processed. If the pin arrives in a block we have seen
+// before with a different hash, that block (and everything after it) has been replaced.
+func (rd *reorgDetector) delivered(ctx context.Context, blockNumber int64, blockHash string) error {
+	if tb, ok := rd.blocks[blockNumber]; ok && blockHash != "" && tb.hash != blockHash {
+		return rd.reorged(ctx, blockNumber)
+	}
+	return nil
+}
+
+// track is called after a batch pin has been processed successfully
+func (rd *reorgDetector) track(blockNumber int64, blockHash string, batchID *fftypes.UUID) {
+	if blockHash == "" {
+		return
+	}
+	tb, ok := rd.blocks[blockNumber]
+	if !ok {
+		tb = &trackedBlock{hash: blockHash}
+		rd.blocks[blockNumber] = tb
+	}
+	tb.batchIDs = append(tb.batchIDs, batchID)
+}
+
+func (rd *reorgDetector) trackedBlockNumbers() []int64 {
+	numbers := make([]int64, 0, len(rd.blocks))
+	for n := range rd.blocks {
+		numbers = append(numbers, n)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers
+}
+
+// check compares the tracked blocks against the current chain, forgetting any that are now deeper
+// than the configured depth. Only errors from the callbacks (which mean we are shutting down) are returned.
+func (rd *reorgDetector) check(ctx context.Context) error {
+	l := log.L(ctx)
+	head, err := rd.fp.headBlock(ctx)
+	if err != nil {
+		l.Warnf("Failed to query head block for reorg check: %s", err)
+		return nil
+	}
+	for _, n := range rd.trackedBlockNumbers() {
+		if n < head-rd.depth {
+			delete(rd.blocks, n)
+			continue
+		}
+		var block fftypes.JSONObject
+		if err := rd.fp.rpc(ctx, "eth_getBlockByNumber", &block, fmt.Sprintf("0x%x", n), false); err != nil {
+			l.Warnf("Failed to query block %d for reorg check: %s", n, err)
+			return nil
+		}
+		if block == nil || block.GetString("hash") != rd.blocks[n].hash {
+			return rd.reorged(ctx, n)
+		}
+	}
+	return nil
+}
+
+func (rd *reorgDetector) reorged(ctx context.Context, blockNumber int64) error {
+	reorg := &blockchain.Reorg{BlockNumber: uint64(blockNumber)}
+	for _, n := range rd.trackedBlockNumbers() {
+		if n >= blockNumber {
+			reorg.BatchIDs = append(reorg.BatchIDs, rd.blocks[n].batchIDs...)
+			delete(rd.blocks, n)
+		}
+	}
+	log.L(ctx).Warnf("Chain reorganization detected from block %d, affecting %d batches", blockNumber, len(reorg.BatchIDs))
+	return rd.callbacks.ChainReorg(reorg)
+}
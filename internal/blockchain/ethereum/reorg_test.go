@@ -0,0 +1,173 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestReorgDetector(t *testing.T, head int64, hashes map[int64]string) (*reorgDetector, *blockchainmocks.Callbacks, func()) {
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		switch method {
+		case "eth_blockNumber":
+			return fmt.Sprintf("0x%x", head), 200
+		case "eth_getBlockByNumber":
+			assert.Equal(t, false, params[1])
+			n, err := parseHexInt(context.Background(), params[0].(string))
+			assert.NoError(t, err)
+			if hash, ok := hashes[n]; ok {
+				return map[string]interface{}{"hash": hash}, 200
+			}
+			return nil, 200
+		}
+		return nil, 404
+	})
+	prefix.Set(FinalityReorgDepth, 10)
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+	mcb := &blockchainmocks.Callbacks{}
+	rd := newReorgDetector(fp, prefix, mcb)
+	assert.NotNil(t, rd)
+	return rd, mcb, done
+}
+
+func TestReorgDetectorDisabled(t *testing.T) {
+	prefix := utFinalityConfigPrefix()
+	assert.Nil(t, newReorgDetector(nil, prefix, nil))
+	assert.Nil(t, newReorgDetector(&finalityPolicy{}, prefix, nil))
+}
+
+func TestReorgDetectorEnablesFinality(t *testing.T) {
+	prefix := utFinalityConfigPrefix()
+	prefix.Set(FinalityReorgDepth, 5)
+	_, err := newFinalityPolicy(context.Background(), prefix)
+	assert.Regexp(t, "FF10138.*finality.rpc", err)
+}
+
+func TestReorgCheckNoChange(t *testing.T) {
+	rd, mcb, done := newTestReorgDetector(t, 120, map[int64]string{115: "0x115"})
+	defer done()
+
+	rd.track(100, "0x100", fftypes.NewUUID())
+	rd.track(105, "0x105", fftypes.NewUUID())
+	rd.track(115, "0x115", fftypes.NewUUID())
+	rd.track(116, "", fftypes.NewUUID())
+
+	err := rd.check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{115}, rd.trackedBlockNumbers())
+	mcb.AssertExpectations(t)
+}
+
+func TestReorgCheckReplacedBlock(t *testing.T) {
+	rd, mcb, done := newTestReorgDetector(t, 120, map[int64]string{110: "0x110", 112: "0xnew"})
+	defer done()
+
+	batch1 := fftypes.NewUUID()
+	batch2 := fftypes.NewUUID()
+	batch3 := fftypes.NewUUID()
+	batch4 := fftypes.NewUUID()
+	rd.track(110, "0x110", batch1)
+	rd.track(112, "0x112", batch2)
+	rd.track(112, "0x112", batch3)
+	rd.track(115, "0x115", batch4)
+
+	mcb.On("ChainReorg", mock.MatchedBy(func(reorg *blockchain.Reorg) bool {
+		return reorg.BlockNumber == 112 &&
+			len(reorg.BatchIDs) == 3 &&
+			*reorg.BatchIDs[0] == *batch2 &&
+			*reorg.BatchIDs[1] == *batch3 &&
+			*reorg.BatchIDs[2] == *batch4
+	})).Return(nil)
+
+	err := rd.check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{110}, rd.trackedBlockNumbers())
+	mcb.AssertExpectations(t)
+}
+
+func TestReorgCheckMissingBlock(t *testing.T) {
+	rd, mcb, done := newTestReorgDetector(t, 120, map[int64]string{})
+	defer done()
+
+	rd.track(118, "0x118", fftypes.NewUUID())
+	mcb.On("ChainReorg", mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := rd.check(context.Background())
+	assert.EqualError(t, err, "pop")
+	assert.Empty(t, rd.trackedBlockNumbers())
+}
+
+func TestReorgCheckHeadFail(t *testing.T) {
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		return nil, 500
+	})
+	defer done()
+	prefix.Set(FinalityReorgDepth, 10)
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+	rd := newReorgDetector(fp, prefix, nil)
+
+	rd.track(15, "0x15", fftypes.NewUUID())
+	err = rd.check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{15}, rd.trackedBlockNumbers())
+}
+
+func TestReorgCheckBlockFail(t *testing.T) {
+	prefix, done := newTestFinalityServer(t, func(method string, params []interface{}) (interface{}, int) {
+		if method == "eth_blockNumber" {
+			return "0x10", 200
+		}
+		return nil, 500
+	})
+	defer done()
+	prefix.Set(FinalityReorgDepth, 10)
+	fp, err := newFinalityPolicy(context.Background(), prefix)
+	assert.NoError(t, err)
+	rd := newReorgDetector(fp, prefix, nil)
+
+	rd.track(15, "0x15", fftypes.NewUUID())
+	err = rd.check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{15}, rd.trackedBlockNumbers())
+}
+
+func TestReorgDelivered(t *testing.T) {
+	mcb := &blockchainmocks.Callbacks{}
+	rd := &reorgDetector{callbacks: mcb, blocks: map[int64]*trackedBlock{}}
+
+	batch1 := fftypes.NewUUID()
+	rd.track(10, "0x10", batch1)
+
+	assert.NoError(t, rd.delivered(context.Background(), 10, "0x10"))
+	assert.NoError(t, rd.delivered(context.Background(), 10, ""))
+	assert.NoError(t, rd.delivered(context.Background(), 11, "0x11"))
+
+	mcb.On("ChainReorg", &blockchain.Reorg{BlockNumber: 10, BatchIDs: []*fftypes.UUID{batch1}}).Return(nil)
+	assert.NoError(t, rd.delivered(context.Background(), 10, "0xnew"))
+	assert.Empty(t, rd.trackedBlockNumbers())
+	mcb.AssertExpectations(t)
+}
@@ -0,0 +1,250 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	errorStringSignature = "Error(string)"
+	panicSignature       = "Panic(uint256)"
+	abiWordSize          = 32
+)
+
+// panicCodes are the reasons the Solidity compiler inserts a Panic(uint256) revert
+var panicCodes = map[uint64]string{
+	0x00: "generic compiler inserted panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division or modulo by zero",
+	0x21: "conversion to an invalid enum value",
+	0x22: "incorrectly encoded storage byte array",
+	0x31: "pop on an empty array",
+	0x32: "array index out of bounds",
+	0x41: "too much memory allocated",
+	0x51: "call to a zero-initialized internal function",
+}
+
+var revertDataRegex = regexp.MustCompile(`0x[0-9a-fA-F]{8,}`)
+
+// abiErrorsFromFFI builds the ABI definitions of the custom errors declared on an interface
+func (e *Ethereum) abiErrorsFromFFI(ctx context.Context, errors fftypes.FFIErrors) ([]*ABIElementMarshaling, error) {
+	abiErrors := make([]*ABIElementMarshaling, len(errors))
+	for i, ffiError := range errors {
+		abiErrors[i] = &ABIElementMarshaling{
+			Type:   "error",
+			Name:   ffiError.Name,
+			Inputs: make([]ABIArgumentMarshaling, len(ffiError.Params)),
+		}
+		if err := e.addParamsToList(ctx, abiErrors[i].Inputs, ffiError.Params); err != nil {
+			return nil, err
+		}
+	}
+	return abiErrors, nil
+}
+
+// decodeRevertReason replaces the message from a failed transaction with a readable form of the
+// revert data, if any could be found. The custom errors registered when the operation was submitted
+// are only consulted if the data is not a standard Error(string) or Panic(uint256).
+func (e *Ethereum) decodeRevertReason(operationID string, reply fftypes.JSONObject, message string) (string, *fftypes.BlockchainErrorData) {
+	data := findRevertData(reply, message)
+	if data == nil {
+		return message, nil
+	}
+	var customErrors []*ABIElementMarshaling
+	if item := e.revertErrors.Get(operationID); item != nil {
+		customErrors = item.Value().([]*ABIElementMarshaling)
+	}
+	decoded, errorData := decodeRevertData(data, customErrors)
+	if decoded == "" {
+		return message, errorData
+	}
+	return decoded, errorData
+}
+
+// findRevertData looks for the ABI encoded revert data in the reply, which ethconnect returns either
+// in its own field or embedded in the error message. Only hex that is a 4 byte selector followed by
+// whole ABI words is accepted, so transaction hashes and addresses in the message are not mistaken for it.
+func findRevertData(reply fftypes.JSONObject, message string) []byte {
+	candidates := []string{reply.GetString("revertReason")}
+	candidates = append(candidates, revertDataRegex.FindAllString(message, -1)...)
+	for _, c := range candidates {
+		if !strings.HasPrefix(c, "0x") {
+			continue
+		}
+		data, err := hex.DecodeString(c[2:])
+		if err == nil && len(data) >= 4 && (len(data)-4)%abiWordSize == 0 {
+			return data
+		}
+	}
+	return nil
+}
+
+// decodeRevertData returns a readable message and the structured error data for revert data.
+// The message is empty if the selector does not match a known error, or the arguments cannot be decoded.
+func decodeRevertData(data []byte, customErrors []*ABIElementMarshaling) (string, *fftypes.BlockchainErrorData) {
+	errorData := &fftypes.BlockchainErrorData{
+		Data: "0x" + hex.EncodeToString(data),
+	}
+	selector := hex.EncodeToString(data[0:4])
+	args := data[4:]
+
+	switch selector {
+	case errorSelector(errorStringSignature):
+		reason, ok := decodeABIValue(args, 0, "string")
+		if !ok {
+			return "", errorData
+		}
+		errorData.Name = "Error"
+		errorData.Signature = errorStringSignature
+		errorData.Params = fftypes.JSONObject{"reason": reason}
+		return reason.(string), errorData
+
+	case errorSelector(panicSignature):
+		code, ok := decodeABIValue(args, 0, "uint256")
+		if !ok {
+			return "", errorData
+		}
+		codeInt, _ := new(big.Int).SetString(code.(string), 10)
+		description := "unknown panic code"
+		if d, ok := panicCodes[codeInt.Uint64()]; ok && codeInt.IsUint64() {
+			description = d
+		}
+		hexCode := "0x" + codeInt.Text(16)
+		errorData.Name = "Panic"
+		errorData.Signature = panicSignature
+		errorData.Params = fftypes.JSONObject{"code": hexCode}
+		return fmt.Sprintf("Panic(%s): %s", hexCode, description), errorData
+	}
+
+	for _, customError := range customErrors {
+		signature := abiSignature(customError)
+		if errorSelector(signature) != selector {
+			continue
+		}
+		params := fftypes.JSONObject{}
+		formatted := make([]string, len(customError.Inputs))
+		for i, input := range customError.Inputs {
+			value, ok := decodeABIValue(args, i*abiWordSize, input.Type)
+			if !ok {
+				return "", errorData
+			}
+			name := input.Name
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			params[name] = value
+			formatted[i] = fmt.Sprintf("%s=%v", name, value)
+		}
+		errorData.Name = customError.Name
+		errorData.Signature = signature
+		errorData.Params = params
+		return fmt.Sprintf("%s(%s)", customError.Name, strings.Join(formatted, ", ")), errorData
+	}
+	return "", errorData
+}
+
+func errorSelector(signature string) string {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(signature))
+	return hex.EncodeToString(h.Sum(nil)[0:4])
+}
+
+// abiSignature is the canonical signature of an ABI element, such as "InsufficientBalance(uint256,uint256)",
+// with tuples expanded into their component types
+func abiSignature(element *ABIElementMarshaling) string {
+	return element.Name + "(" + abiArgumentTypes(element.Inputs) + ")"
+}
+
+func abiArgumentTypes(args []ABIArgumentMarshaling) string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		if strings.HasPrefix(arg.Type, "tuple") {
+			types[i] = "(" + abiArgumentTypes(arg.Components) + ")" + strings.TrimPrefix(arg.Type, "tuple")
+		} else {
+			types[i] = arg.Type
+		}
+	}
+	return strings.Join(types, ",")
+}
+
+func abiWord(data []byte, offset int) ([]byte, bool) {
+	if offset < 0 || offset+abiWordSize > len(data) {
+		return nil, false
+	}
+	return data[offset : offset+abiWordSize], true
+}
+
+// decodeABIValue decodes the elementary value in the head slot at the offset. Numbers are returned as
+// decimal strings, and bytes as hex. Arrays and tuples are not supported.
+func decodeABIValue(data []byte, offset int, abiType string) (interface{}, bool) {
+	word, ok := abiWord(data, offset)
+	if !ok {
+		return nil, false
+	}
+	switch {
+	case abiType == "string" || abiType == "bytes":
+		start := new(big.Int).SetBytes(word)
+		if !start.IsInt64() || start.Int64() > int64(len(data)) {
+			return nil, false
+		}
+		lengthWord, ok := abiWord(data, int(start.Int64()))
+		if !ok {
+			return nil, false
+		}
+		length := new(big.Int).SetBytes(lengthWord)
+		contentStart := int(start.Int64()) + abiWordSize
+		if !length.IsInt64() || length.Int64() > int64(len(data)-contentStart) {
+			return nil, false
+		}
+		content := data[contentStart : contentStart+int(length.Int64())]
+		if abiType == "string" {
+			return string(content), true
+		}
+		return "0x" + hex.EncodeToString(content), true
+	case abiType == "bool":
+		return new(big.Int).SetBytes(word).Sign() != 0, true
+	case abiType == "address":
+		return "0x" + hex.EncodeToString(word[12:]), true
+	case strings.HasPrefix(abiType, "uint") && !strings.Contains(abiType, "["):
+		return new(big.Int).SetBytes(word).String(), true
+	case strings.HasPrefix(abiType, "int") && !strings.Contains(abiType, "["):
+		value := new(big.Int).SetBytes(word)
+		if word[0]&0x80 != 0 {
+			value.Sub(value, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return value.String(), true
+	case strings.HasPrefix(abiType, "bytes") && !strings.Contains(abiType, "["):
+		size, err := strconv.Atoi(strings.TrimPrefix(abiType, "bytes"))
+		if err != nil || size < 1 || size > abiWordSize {
+			return nil, false
+		}
+		return "0x" + hex.EncodeToString(word[0:size]), true
+	default:
+		return nil, false
+	}
+}
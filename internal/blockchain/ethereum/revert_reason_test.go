@@ -0,0 +1,222 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ethereum
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func abiUintWord(v int64) string {
+	return hex.EncodeToString(new(big.Int).SetInt64(v).FillBytes(make([]byte, 32)))
+}
+
+func abiDynamicContent(b []byte) string {
+	padded := make([]byte, (len(b)+31)/32*32)
+	copy(padded, b)
+	return abiUintWord(int64(len(b))) + hex.EncodeToString(padded)
+}
+
+func revertData(t *testing.T, hexStr string) []byte {
+	b, err := hex.DecodeString(strings.TrimPrefix(hexStr, "0x"))
+	assert.NoError(t, err)
+	return b
+}
+
+func testCustomErrors(t *testing.T) []*ABIElementMarshaling {
+	e, _ := newTestEthereum()
+	abiErrors, err := e.abiErrorsFromFFI(context.Background(), fftypes.FFIErrors{
+		{
+			Name: "InsufficientBalance",
+			Params: fftypes.FFIParams{
+				{Name: "available", Schema: fftypes.JSONAnyPtr(`{"type":"integer","details":{"type":"uint256"}}`)},
+				{Name: "required", Schema: fftypes.JSONAnyPtr(`{"type":"integer","details":{"type":"uint256"}}`)},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	return abiErrors
+}
+
+func TestErrorSelectors(t *testing.T) {
+	assert.Equal(t, "08c379a0", errorSelector(errorStringSignature))
+	assert.Equal(t, "4e487b71", errorSelector(panicSignature))
+}
+
+func TestDecodeRevertDataErrorString(t *testing.T) {
+	data := revertData(t, "08c379a0"+abiUintWord(32)+abiDynamicContent([]byte("Insufficient funds")))
+	message, errorData := decodeRevertData(data, nil)
+	assert.Equal(t, "Insufficient funds", message)
+	assert.Equal(t, "Error", errorData.Name)
+	assert.Equal(t, "Error(string)", errorData.Signature)
+	assert.Equal(t, "Insufficient funds", errorData.Params.GetString("reason"))
+	assert.Equal(t, "0x"+hex.EncodeToString(data), errorData.Data)
+}
+
+func TestDecodeRevertDataErrorStringBad(t *testing.T) {
+	data := revertData(t, "08c379a0"+abiUintWord(1024))
+	message, errorData := decodeRevertData(data, nil)
+	assert.Empty(t, message)
+	assert.Empty(t, errorData.Name)
+	assert.Equal(t, "0x08c379a0"+abiUintWord(1024), errorData.Data)
+}
+
+func TestDecodeRevertDataPanic(t *testing.T) {
+	message, errorData := decodeRevertData(revertData(t, "4e487b71"+abiUintWord(0x11)), nil)
+	assert.Equal(t, "Panic(0x11): arithmetic overflow or underflow", message)
+	assert.Equal(t, "Panic", errorData.Name)
+	assert.Equal(t, "0x11", errorData.Params.GetString("code"))
+
+	message, _ = decodeRevertData(revertData(t, "4e487b71"+abiUintWord(0x99)), nil)
+	assert.Equal(t, "Panic(0x99): unknown panic code", message)
+
+	message, errorData = decodeRevertData(revertData(t, "4e487b71"), nil)
+	assert.Empty(t, message)
+	assert.Empty(t, errorData.Name)
+}
+
+func TestDecodeRevertDataCustomError(t *testing.T) {
+	customErrors := testCustomErrors(t)
+	selector := errorSelector("InsufficientBalance(uint256,uint256)")
+	data := revertData(t, selector+abiUintWord(10)+abiUintWord(25))
+	message, errorData := decodeRevertData(data, customErrors)
+	assert.Equal(t, "InsufficientBalance(available=10, required=25)", message)
+	assert.Equal(t, "InsufficientBalance", errorData.Name)
+	assert.Equal(t, "InsufficientBalance(uint256,uint256)", errorData.Signature)
+	assert.Equal(t, fftypes.JSONObject{"available": "10", "required": "25"}, errorData.Params)
+}
+
+func TestDecodeRevertDataCustomErrorTruncated(t *testing.T) {
+	customErrors := testCustomErrors(t)
+	selector := errorSelector("InsufficientBalance(uint256,uint256)")
+	message, errorData := decodeRevertData(revertData(t, selector+abiUintWord(10)), customErrors)
+	assert.Empty(t, message)
+	assert.Empty(t, errorData.Name)
+}
+
+func TestDecodeRevertDataUnknownSelector(t *testing.T) {
+	message, errorData := decodeRevertData(revertData(t, "deadbeef"), testCustomErrors(t))
+	assert.Empty(t, message)
+	assert.Equal(t, "0xdeadbeef", errorData.Data)
+}
+
+func TestDecodeRevertDataUnnamedParams(t *testing.T) {
+	customErrors := []*ABIElementMarshaling{
+		{
+			Type: "error",
+			Name: "Rejected",
+			Inputs: []ABIArgumentMarshaling{
+				{Type: "address"},
+				{Type: "bool"},
+				{Type: "int256"},
+				{Type: "bytes4"},
+				{Type: "bytes"},
+			},
+		},
+	}
+	negative := hex.EncodeToString(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(5)).Bytes())
+	address := strings.Repeat("00", 12) + strings.Repeat("ab", 20)
+	bytes4 := "cafebabe" + strings.Repeat("00", 28)
+	data := revertData(t, errorSelector("Rejected(address,bool,int256,bytes4,bytes)")+
+		address+abiUintWord(1)+negative+bytes4+abiUintWord(160)+abiDynamicContent([]byte{0x01, 0x02}))
+	message, errorData := decodeRevertData(data, customErrors)
+	assert.Equal(t, "Rejected(0=0x"+strings.Repeat("ab", 20)+", 1=true, 2=-5, 3=0xcafebabe, 4=0x0102)", message)
+	assert.Equal(t, "Rejected", errorData.Name)
+}
+
+func TestABISignatureTuple(t *testing.T) {
+	assert.Equal(t, "Bad((uint256,address)[],string)", abiSignature(&ABIElementMarshaling{
+		Name: "Bad",
+		Inputs: []ABIArgumentMarshaling{
+			{Type: "tuple[]", Components: []ABIArgumentMarshaling{{Type: "uint256"}, {Type: "address"}}},
+			{Type: "string"},
+		},
+	}))
+}
+
+func TestDecodeABIValueUnsupported(t *testing.T) {
+	word := revertData(t, abiUintWord(1))
+	_, ok := decodeABIValue(word, 0, "uint256[]")
+	assert.False(t, ok)
+	_, ok = decodeABIValue(word, 0, "bytes33")
+	assert.False(t, ok)
+	_, ok = decodeABIValue(word, 0, "bytesX")
+	assert.False(t, ok)
+	_, ok = decodeABIValue(word, 0, "tuple")
+	assert.False(t, ok)
+	_, ok = decodeABIValue(word, 32, "uint256")
+	assert.False(t, ok)
+}
+
+func TestDecodeABIValueBadDynamic(t *testing.T) {
+	// Offset too large to be an int
+	_, ok := decodeABIValue(revertData(t, strings.Repeat("ff", 32)), 0, "string")
+	assert.False(t, ok)
+	// Offset points to the end of the data, with no length
+	_, ok = decodeABIValue(revertData(t, abiUintWord(32)), 0, "string")
+	assert.False(t, ok)
+	// Length is longer than the remaining data
+	_, ok = decodeABIValue(revertData(t, abiUintWord(32)+abiUintWord(64)), 0, "bytes")
+	assert.False(t, ok)
+}
+
+func TestFindRevertData(t *testing.T) {
+	data := "0x08c379a0" + abiUintWord(32) + abiDynamicContent([]byte("nope"))
+	assert.Equal(t, revertData(t, data), findRevertData(fftypes.JSONObject{"revertReason": data}, ""))
+	assert.Equal(t, revertData(t, data), findRevertData(fftypes.JSONObject{}, "execution reverted: "+data))
+	// Transaction hashes and non-hex reasons are ignored
+	assert.Nil(t, findRevertData(fftypes.JSONObject{"revertReason": "out of gas"}, "tx 0x"+strings.Repeat("ab", 32)+" failed"))
+	assert.Nil(t, findRevertData(fftypes.JSONObject{"revertReason": "0xzz"}, ""))
+}
+
+func TestAbiErrorsFromFFIBadSchema(t *testing.T) {
+	e, _ := newTestEthereum()
+	_, err := e.abiErrorsFromFFI(context.Background(), fftypes.FFIErrors{
+		{
+			Name:   "Bad",
+			Params: fftypes.FFIParams{{Name: "x", Schema: fftypes.JSONAnyPtr(`{"type":"integer"`)}},
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestDecodeRevertReasonCached(t *testing.T) {
+	e, _ := newTestEthereum()
+	opID := fftypes.NewUUID().String()
+	e.revertErrors.Set(opID, testCustomErrors(t), time.Minute)
+	data := "0x" + errorSelector("InsufficientBalance(uint256,uint256)") + abiUintWord(1) + abiUintWord(2)
+
+	message, errorData := e.decodeRevertReason(opID, fftypes.JSONObject{}, "reverted "+data)
+	assert.Equal(t, "InsufficientBalance(available=1, required=2)", message)
+	assert.Equal(t, "InsufficientBalance", errorData.Name)
+
+	// Without the custom errors the original message is kept, along with the raw data
+	message, errorData = e.decodeRevertReason(fftypes.NewUUID().String(), fftypes.JSONObject{}, "reverted "+data)
+	assert.Equal(t, "reverted "+data, message)
+	assert.Equal(t, data, errorData.Data)
+
+	message, errorData = e.decodeRevertReason(opID, fftypes.JSONObject{}, "out of gas")
+	assert.Equal(t, "out of gas", message)
+	assert.Nil(t, errorData)
+}
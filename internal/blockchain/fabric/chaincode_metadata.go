@@ -0,0 +1,164 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// FFIGenerationInput is the metadata reported by a chaincode built with the Fabric contract API
+// (the output of "org.hyperledger.fabric:GetMetadata"), and optionally the name of the contract
+// within the chaincode to generate the interface for
+type FFIGenerationInput struct {
+	Contract string             `json:"contract,omitempty"`
+	Metadata *chaincodeMetadata `json:"metadata,omitempty"`
+}
+
+type chaincodeMetadata struct {
+	Contracts  map[string]*chaincodeContract `json:"contracts"`
+	Components struct {
+		Schemas map[string]interface{} `json:"schemas"`
+	} `json:"components"`
+}
+
+type chaincodeContract struct {
+	Name         string                  `json:"name"`
+	Transactions []*chaincodeTransaction `json:"transactions"`
+}
+
+type chaincodeTransaction struct {
+	Name       string                `json:"name"`
+	Parameters []*chaincodeParameter `json:"parameters"`
+	Returns    interface{}           `json:"returns"`
+}
+
+type chaincodeParameter struct {
+	Name   string      `json:"name"`
+	Schema interface{} `json:"schema"`
+}
+
+const componentSchemaRefPrefix = "#/components/schemas/"
+
+func (f *Fabric) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
+	var input FFIGenerationInput
+	err := json.Unmarshal(generationRequest.Input.Bytes(), &input)
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationFailed, "unable to deserialize JSON as chaincode metadata")
+	}
+	if input.Metadata == nil || len(input.Metadata.Contracts) == 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationFailed, "chaincode metadata contains no contracts")
+	}
+
+	contractName := input.Contract
+	if contractName == "" {
+		if len(input.Metadata.Contracts) > 1 {
+			names := make([]string, 0, len(input.Metadata.Contracts))
+			for name := range input.Metadata.Contracts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationFailed, fmt.Sprintf("chaincode metadata contains multiple contracts - specify one of: %s", strings.Join(names, ", ")))
+		}
+		for name := range input.Metadata.Contracts {
+			contractName = name
+		}
+	}
+	contract, ok := input.Metadata.Contracts[contractName]
+	if !ok {
+		return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationFailed, fmt.Sprintf("contract '%s' not found in chaincode metadata", contractName))
+	}
+
+	ffi := &fftypes.FFI{
+		Namespace:   generationRequest.Namespace,
+		Name:        generationRequest.Name,
+		Version:     generationRequest.Version,
+		Description: generationRequest.Description,
+		Methods:     []*fftypes.FFIMethod{},
+		Events:      []*fftypes.FFIEvent{},
+	}
+	for _, tx := range contract.Transactions {
+		// The only contract in a chaincode is its default contract, so its transactions can be called
+		// without qualification. Otherwise the transaction must be qualified with the contract name.
+		methodName := tx.Name
+		if len(input.Metadata.Contracts) > 1 {
+			methodName = fmt.Sprintf("%s:%s", contractName, tx.Name)
+		}
+		method := &fftypes.FFIMethod{
+			Name:    methodName,
+			Params:  fftypes.FFIParams{},
+			Returns: fftypes.FFIParams{},
+		}
+		for _, param := range tx.Parameters {
+			method.Params = append(method.Params, &fftypes.FFIParam{
+				Name:   param.Name,
+				Schema: input.Metadata.schemaJSON(param.Schema),
+			})
+		}
+		if tx.Returns != nil {
+			method.Returns = append(method.Returns, &fftypes.FFIParam{
+				Name:   "",
+				Schema: input.Metadata.schemaJSON(tx.Returns),
+			})
+		}
+		ffi.Methods = append(ffi.Methods, method)
+	}
+	return ffi, nil
+}
+
+// schemaJSON serializes a schema from the metadata, inlining any references to the shared
+// component schemas so that the resulting FFI parameter is self-contained
+func (m *chaincodeMetadata) schemaJSON(schema interface{}) *fftypes.JSONAny {
+	b, _ := json.Marshal(m.resolveRefs(schema, map[string]bool{}))
+	return fftypes.JSONAnyPtrBytes(b)
+}
+
+func (m *chaincodeMetadata) resolveRefs(schema interface{}, resolving map[string]bool) interface{} {
+	switch s := schema.(type) {
+	case map[string]interface{}:
+		if ref, ok := s["$ref"].(string); ok && strings.HasPrefix(ref, componentSchemaRefPrefix) {
+			name := strings.TrimPrefix(ref, componentSchemaRefPrefix)
+			component, ok := m.Components.Schemas[name]
+			if ok && !resolving[name] {
+				// Guard against recursive types, which are left as an unresolved reference
+				resolving[name] = true
+				defer delete(resolving, name)
+				return m.resolveRefs(component, resolving)
+			}
+			return s
+		}
+		resolved := make(map[string]interface{}, len(s))
+		for k, v := range s {
+			resolved[k] = m.resolveRefs(v, resolving)
+		}
+		return resolved
+	case []interface{}:
+		resolved := make([]interface{}, len(s))
+		for i, v := range s {
+			resolved[i] = m.resolveRefs(v, resolving)
+		}
+		return resolved
+	default:
+		return s
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fabric
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+const testChaincodeMetadata = `{
+	"contracts": {
+		"AssetContract": {
+			"name": "AssetContract",
+			"transactions": [
+				{
+					"name": "CreateAsset",
+					"tag": ["submitTx"],
+					"parameters": [
+						{"name": "id", "schema": {"type": "string"}},
+						{"name": "asset", "schema": {"$ref": "#/components/schemas/Asset"}}
+					]
+				},
+				{
+					"name": "ReadAsset",
+					"tag": ["evaluate"],
+					"parameters": [
+						{"name": "id", "schema": {"type": "string"}}
+					],
+					"returns": {"$ref": "#/components/schemas/Asset"}
+				}
+			]
+		}
+	},
+	"components": {
+		"schemas": {
+			"Asset": {
+				"type": "object",
+				"required": ["owner"],
+				"properties": {
+					"owner": {"type": "string"},
+					"parts": {"type": "array", "items": {"$ref": "#/components/schemas/Asset"}},
+					"other": {"$ref": "#/components/schemas/Missing"}
+				}
+			}
+		}
+	}
+}`
+
+func TestGenerateFFI(t *testing.T) {
+	e, _ := newTestFabric()
+	ffi, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Namespace:   "ns1",
+		Name:        "assets",
+		Version:     "v1.0.0",
+		Description: "desc",
+		Input:       fftypes.JSONAnyPtr(`{"metadata":` + testChaincodeMetadata + `}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "assets", ffi.Name)
+	assert.Equal(t, "ns1", ffi.Namespace)
+	assert.Len(t, ffi.Methods, 2)
+
+	create := ffi.Methods[0]
+	assert.Equal(t, "CreateAsset", create.Name)
+	assert.Len(t, create.Params, 2)
+	assert.Equal(t, "id", create.Params[0].Name)
+	assert.JSONEq(t, `{"type":"string"}`, create.Params[0].Schema.String())
+	assert.JSONEq(t, `{
+		"type": "object",
+		"required": ["owner"],
+		"properties": {
+			"owner": {"type": "string"},
+			"parts": {"type": "array", "items": {"$ref": "#/components/schemas/Asset"}},
+			"other": {"$ref": "#/components/schemas/Missing"}
+		}
+	}`, create.Params[1].Schema.String())
+	assert.Empty(t, create.Returns)
+
+	read := ffi.Methods[1]
+	assert.Equal(t, "ReadAsset", read.Name)
+	assert.Len(t, read.Returns, 1)
+	assert.Regexp(t, `"type":"object"`, read.Returns[0].Schema.String())
+}
+
+func TestGenerateFFIMultipleContracts(t *testing.T) {
+	e, _ := newTestFabric()
+	input := `{
+		"contracts": {
+			"B": {"name": "B", "transactions": [{"name": "Tx1"}]},
+			"A": {"name": "A", "transactions": [{"name": "Tx1"}]}
+		}
+	}`
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Input: fftypes.JSONAnyPtr(`{"metadata":` + input + `}`),
+	})
+	assert.Regexp(t, "FF10346.*A, B", err)
+
+	ffi, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Input: fftypes.JSONAnyPtr(`{"contract":"B","metadata":` + input + `}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "B:Tx1", ffi.Methods[0].Name)
+}
+
+func TestGenerateFFIContractNotFound(t *testing.T) {
+	e, _ := newTestFabric()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Input: fftypes.JSONAnyPtr(`{"contract":"C","metadata":` + testChaincodeMetadata + `}`),
+	})
+	assert.Regexp(t, "FF10346.*C", err)
+}
+
+func TestGenerateFFINoContracts(t *testing.T) {
+	e, _ := newTestFabric()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Input: fftypes.JSONAnyPtr(`{"metadata":{"contracts":{}}}`),
+	})
+	assert.Regexp(t, "FF10346", err)
+}
+
+func TestGenerateFFIBadInput(t *testing.T) {
+	e, _ := newTestFabric()
+	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
+		Input: fftypes.JSONAnyPtr(`[]`),
+	})
+	assert.Regexp(t, "FF10346", err)
+}
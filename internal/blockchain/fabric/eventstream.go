@@ -140,6 +140,17 @@ func (s *streamManager) createSubscription(ctx context.Context, location *Locati
 	return &sub, nil
 }
 
+func (s *streamManager) resetSubscription(ctx context.Context, subID, initialBlock string) error {
+	res, err := s.client.R().
+		SetContext(ctx).
+		SetBody(map[string]string{"initialBlock": initialBlock}).
+		Post("/subscriptions/" + subID + "/reset")
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgFabconnectRESTErr)
+	}
+	return nil
+}
+
 func (s *streamManager) deleteSubscription(ctx context.Context, subID string) error {
 	res, err := s.client.R().
 		SetContext(ctx).
@@ -29,6 +29,7 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/config/wsconfig"
+	"github.com/hyperledger/firefly/internal/faults"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/restclient"
@@ -180,6 +181,7 @@ func (f *Fabric) Init(ctx context.Context, prefix config.Prefix, callbacks block
 	f.prefixLong = fabconnectConf.GetString(FabconnectPrefixLong)
 
 	f.client = restclient.New(f.ctx, fabconnectConf)
+	faults.WrapClient(f.client, faults.PluginBlockchain)
 	f.capabilities = &blockchain.Capabilities{
 		GlobalSequencer: true,
 	}
@@ -390,7 +392,46 @@ func (f *Fabric) handleReceipt(ctx context.Context, reply fftypes.JSONObject) er
 		updateType = fftypes.OpStatusFailed
 	}
 	l.Infof("Fabconnect '%s' reply tx=%s (request=%s) %s", replyType, txHash, requestID, message)
-	return f.callbacks.BlockchainOpUpdate(operationID, updateType, txHash, message, reply)
+	return f.callbacks.BlockchainOpUpdate(operationID, buildTransaction(reply, txHash, updateType), message, reply, buildReceipt(reply, updateType, message))
+}
+
+func replyBlockNumber(reply fftypes.JSONObject) *uint64 {
+	if s, ok := reply.GetStringOk("blockNumber"); ok {
+		if blockNumber, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return &blockNumber
+		}
+	}
+	return nil
+}
+
+// buildTransaction describes the transaction in the normalized form shared with other blockchains. Fabric
+// has deterministic finality, so a transaction committed to a block is always final.
+func buildTransaction(reply fftypes.JSONObject, txID string, updateType fftypes.OpStatus) *fftypes.BlockchainTransaction {
+	tx := &fftypes.BlockchainTransaction{
+		ID:     txID,
+		Status: updateType,
+	}
+	if blockNumber := replyBlockNumber(reply); blockNumber != nil {
+		tx.Finality = &fftypes.BlockchainFinality{
+			BlockNumber: blockNumber,
+			Final:       true,
+		}
+	}
+	return tx
+}
+
+// buildReceipt records the block and any error from the reply - Fabric transactions do not have a fee
+func buildReceipt(reply fftypes.JSONObject, updateType fftypes.OpStatus, message string) *fftypes.BlockchainReceipt {
+	receipt := &fftypes.BlockchainReceipt{
+		BlockNumber: replyBlockNumber(reply),
+	}
+	if updateType == fftypes.OpStatusFailed {
+		receipt.RevertReason = message
+	}
+	if receipt.BlockNumber == nil && receipt.RevertReason == "" {
+		return nil
+	}
+	return receipt
 }
 
 func (f *Fabric) handleMessageBatch(ctx context.Context, messages []interface{}) error {
@@ -569,7 +610,15 @@ func (f *Fabric) SubmitBatchPin(ctx context.Context, operationID *fftypes.UUID,
 	return nil
 }
 
-func (f *Fabric) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) error {
+func (f *Fabric) GetBatchPinMigration(ctx context.Context) *fftypes.BatchPinMigration {
+	return nil
+}
+
+func (f *Fabric) ActivateBatchPinMigration(ctx context.Context) (*fftypes.BatchPinMigration, error) {
+	return nil, i18n.NewError(ctx, i18n.MsgNoBatchPinMigration)
+}
+
+func (f *Fabric) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors fftypes.FFIErrors) error {
 	// All arguments must be JSON serialized
 	args, err := jsonEncodeInput(input)
 	if err != nil {
@@ -695,7 +744,11 @@ func (f *Fabric) AddContractListener(ctx context.Context, listener *fftypes.Cont
 	if err != nil {
 		return err
 	}
-	result, err := f.streams.createSubscription(ctx, location, f.initInfo.stream.ID, "", listener.Event.Name, listener.Options.FirstEvent)
+	firstEvent := listener.Options.FirstEvent
+	if listener.Options.FromBlock != nil {
+		firstEvent = strconv.FormatUint(*listener.Options.FromBlock, 10)
+	}
+	result, err := f.streams.createSubscription(ctx, location, f.initInfo.stream.ID, "", listener.Event.Name, firstEvent)
 	if err != nil {
 		return err
 	}
@@ -707,11 +760,14 @@ func (f *Fabric) DeleteContractListener(ctx context.Context, subscription *fftyp
 	return f.streams.deleteSubscription(ctx, subscription.ProtocolID)
 }
 
+func (f *Fabric) RewindContractListener(ctx context.Context, subscription *fftypes.ContractListener, blockNumber uint64) (string, error) {
+	if err := f.streams.resetSubscription(ctx, subscription.ProtocolID, strconv.FormatUint(blockNumber, 10)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%.12d", blockNumber), nil
+}
+
 func (f *Fabric) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
 	// Fabconnect does not require any additional validation beyond "JSON Schema correctness" at this time
 	return nil, nil
 }
-
-func (f *Fabric) GenerateFFI(ctx context.Context, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error) {
-	return nil, i18n.NewError(ctx, i18n.MsgFFIGenerationUnsupported)
-}
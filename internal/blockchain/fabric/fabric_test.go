@@ -925,10 +925,14 @@ func TestEventLoopUnexpectedMessage(t *testing.T) {
 	em := e.callbacks.(*blockchainmocks.Callbacks)
 	txsu := em.On("BlockchainOpUpdate",
 		operationID,
-		fftypes.OpStatusFailed,
-		"",
+		&fftypes.BlockchainTransaction{
+			Status: fftypes.OpStatusFailed,
+		},
 		"Packing arguments for method 'broadcastBatch': abi: cannot use [3]uint8 as type [32]uint8 as argument",
-		mock.Anything).Return(fmt.Errorf("Shutdown"))
+		mock.Anything,
+		&fftypes.BlockchainReceipt{
+			RevertReason: "Packing arguments for method 'broadcastBatch': abi: cannot use [3]uint8 as type [32]uint8 as argument",
+		}).Return(fmt.Errorf("Shutdown"))
 	done := make(chan struct{})
 	txsu.RunFn = func(a mock.Arguments) {
 		close(done)
@@ -964,15 +968,24 @@ func TestHandleReceiptTXSuccess(t *testing.T) {
 				"type": "TransactionSuccess"
 		},
 		"transactionId": "ce79343000e851a0c742f63a733ce19a5f8b9ce1c719b6cecd14f01bcf81fff2",
+		"blockNumber": 1234,
 		"receivedAt": 1630033474675
   }`)
 
+	blockNumber := uint64(1234)
 	em.On("BlockchainOpUpdate",
 		operationID,
-		fftypes.OpStatusSucceeded,
-		"ce79343000e851a0c742f63a733ce19a5f8b9ce1c719b6cecd14f01bcf81fff2",
+		&fftypes.BlockchainTransaction{
+			ID:     "ce79343000e851a0c742f63a733ce19a5f8b9ce1c719b6cecd14f01bcf81fff2",
+			Status: fftypes.OpStatusSucceeded,
+			Finality: &fftypes.BlockchainFinality{
+				BlockNumber: &blockNumber,
+				Final:       true,
+			},
+		},
 		"",
-		mock.Anything).Return(nil)
+		mock.Anything,
+		&fftypes.BlockchainReceipt{BlockNumber: &blockNumber}).Return(nil)
 
 	err := json.Unmarshal(data, &reply)
 	assert.NoError(t, err)
@@ -1057,10 +1070,13 @@ func TestHandleReceiptFailedTx(t *testing.T) {
 
 	em.On("BlockchainOpUpdate",
 		operationID,
-		fftypes.OpStatusFailed,
-		"ce79343000e851a0c742f63a733ce19a5f8b9ce1c719b6cecd14f01bcf81fff2",
+		&fftypes.BlockchainTransaction{
+			ID:     "ce79343000e851a0c742f63a733ce19a5f8b9ce1c719b6cecd14f01bcf81fff2",
+			Status: fftypes.OpStatusFailed,
+		},
 		"",
-		mock.Anything).Return(nil)
+		mock.Anything,
+		(*fftypes.BlockchainReceipt)(nil)).Return(nil)
 
 	err := json.Unmarshal(data, &reply)
 	assert.NoError(t, err)
@@ -1111,6 +1127,46 @@ func TestAddSubscription(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestAddSubscriptionFromBlock(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.initInfo.stream = &eventStream{
+		ID: "es-1",
+	}
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	fromBlock := uint64(12345)
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"channel":   "firefly",
+				"chaincode": "mycode",
+			}.String()),
+			Event: &fftypes.FFISerializedEvent{},
+			Options: &fftypes.ContractListenerOptions{
+				FromBlock: &fromBlock,
+			},
+		},
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]interface{}
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "12345", body["fromBlock"])
+			return httpmock.NewJsonResponderOrPanic(200, &subscription{})(req)
+		})
+
+	err := e.AddContractListener(context.Background(), sub)
+
+	assert.NoError(t, err)
+}
+
 func TestAddSubscriptionBadLocation(t *testing.T) {
 	e, cancel := newTestFabric()
 	defer cancel()
@@ -1222,6 +1278,57 @@ func TestDeleteSubscriptionFail(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 }
 
+func TestRewindSubscription(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := &fftypes.ContractListener{
+		ProtocolID: "sb-1",
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions/sb-1/reset`,
+		func(req *http.Request) (*http.Response, error) {
+			var body map[string]string
+			json.NewDecoder(req.Body).Decode(&body)
+			assert.Equal(t, "12345", body["initialBlock"])
+			return httpmock.NewStringResponse(204, ""), nil
+		})
+
+	checkpoint, err := e.RewindContractListener(context.Background(), sub, 12345)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "000000012345", checkpoint)
+	assert.Less(t, checkpoint, "000000012345/000000/000000")
+}
+
+func TestRewindSubscriptionFail(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+	httpmock.ActivateNonDefault(e.client.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	e.streams = &streamManager{
+		client: e.client,
+	}
+
+	sub := &fftypes.ContractListener{
+		ProtocolID: "sb-1",
+	}
+
+	httpmock.RegisterResponder("POST", `http://localhost:12345/subscriptions/sb-1/reset`,
+		httpmock.NewStringResponder(500, ""))
+
+	_, err := e.RewindContractListener(context.Background(), sub, 12345)
+
+	assert.Regexp(t, "FF10284", err)
+}
+
 func TestHandleMessageContractEvent(t *testing.T) {
 	data := []byte(`
 [
@@ -1376,7 +1483,7 @@ func TestInvokeContractOK(t *testing.T) {
 			assert.Equal(t, "test", body["args"].(map[string]interface{})["description"])
 			return httpmock.NewJsonResponderOrPanic(200, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.NoError(t, err)
 }
 
@@ -1407,7 +1514,7 @@ func TestInvokeContractBadSchema(t *testing.T) {
 	}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "FF10151", err)
 }
 
@@ -1423,7 +1530,7 @@ func TestInvokeContractChaincodeNotSet(t *testing.T) {
 	}
 	locationBytes, err := json.Marshal(location)
 	assert.NoError(t, err)
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "FF10310", err)
 }
 
@@ -1448,7 +1555,7 @@ func TestInvokeContractFabconnectError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "FF10284", err)
 }
 
@@ -1622,7 +1729,7 @@ func TestInvokeJSONEncodeParamsError(t *testing.T) {
 		func(req *http.Request) (*http.Response, error) {
 			return httpmock.NewJsonResponderOrPanic(400, "")(req)
 		})
-	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params)
+	err = e.InvokeContract(context.Background(), nil, signingKey, fftypes.JSONAnyPtrBytes(locationBytes), method, params, nil)
 	assert.Regexp(t, "FF10151", err)
 }
 
@@ -1633,13 +1740,11 @@ func TestGetFFIParamValidator(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestGenerateFFI(t *testing.T) {
-	e, _ := newTestFabric()
-	_, err := e.GenerateFFI(context.Background(), &fftypes.FFIGenerationRequest{
-		Name:        "Simple",
-		Version:     "v0.0.1",
-		Description: "desc",
-		Input:       fftypes.JSONAnyPtr(`[]`),
-	})
-	assert.Regexp(t, "FF10347", err)
+func TestBatchPinMigrationNotSupported(t *testing.T) {
+	e, cancel := newTestFabric()
+	defer cancel()
+
+	assert.Nil(t, e.GetBatchPinMigration(context.Background()))
+	_, err := e.ActivateBatchPinMigration(context.Background())
+	assert.Regexp(t, "FF10437", err)
 }
@@ -0,0 +1,217 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bridging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/broadcast"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/sysmessaging"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Manager copies confirmed messages from one local namespace to another, according to the rules
+// configured in bridges.rules - for example to promote confirmed orders from an "intake" namespace
+// to a "settlement" namespace. Each rule consumes a durable subscription, so messages confirmed while
+// the node is down are bridged on restart, and the copy is acknowledged only once it has been sent.
+type Manager interface {
+	Start() error
+}
+
+// Rule is the configuration of a single bridge, in the bridges.rules array
+type Rule struct {
+	Name   string                     `json:"name"`
+	From   string                     `json:"from"`
+	To     string                     `json:"to"`
+	Filter fftypes.SubscriptionFilter `json:"filter,omitempty"`
+	Type   fftypes.MessageType        `json:"type,omitempty"`
+	Topic  string                     `json:"topic,omitempty"`
+	Tag    string                     `json:"tag,omitempty"`
+	Group  *fftypes.InputGroup        `json:"group,omitempty"`
+}
+
+type bridgeManager struct {
+	ctx       context.Context
+	database  database.Plugin
+	data      data.Manager
+	sysevents sysmessaging.SystemEvents
+	broadcast broadcast.Manager
+	messaging privatemessaging.Manager
+	rules     []*Rule
+}
+
+func NewBridgeManager(ctx context.Context, di database.Plugin, dm data.Manager, se sysmessaging.SystemEvents, bm broadcast.Manager, pm privatemessaging.Manager) (Manager, error) {
+	if di == nil || dm == nil || se == nil || bm == nil || pm == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	bg := &bridgeManager{
+		ctx:       log.WithLogField(ctx, "role", "bridge-manager"),
+		database:  di,
+		data:      dm,
+		sysevents: se,
+		broadcast: bm,
+		messaging: pm,
+	}
+	names := make(map[string]bool)
+	for i, ruleObject := range config.GetObjectArray(config.BridgesRules) {
+		var rule *Rule
+		b, _ := json.Marshal(ruleObject)
+		if err := json.Unmarshal(b, &rule); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgBridgeRuleInvalid, i, err)
+		}
+		if err := fftypes.ValidateFFNameField(ctx, rule.Name, "name"); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgBridgeRuleInvalid, i, err)
+		}
+		if names[rule.Name] {
+			return nil, i18n.NewError(ctx, i18n.MsgBridgeRuleInvalid, i, fmt.Sprintf("duplicate name '%s'", rule.Name))
+		}
+		if err := fftypes.ValidateFFNameField(ctx, rule.From, "from"); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgBridgeRuleInvalid, i, err)
+		}
+		if err := fftypes.ValidateFFNameField(ctx, rule.To, "to"); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgBridgeRuleInvalid, i, err)
+		}
+		if rule.From == rule.To {
+			return nil, i18n.NewError(ctx, i18n.MsgBridgeRuleInvalid, i, "from and to must be different namespaces")
+		}
+		switch rule.Type {
+		case "":
+			rule.Type = fftypes.MessageTypeBroadcast
+		case fftypes.MessageTypeBroadcast:
+		case fftypes.MessageTypePrivate:
+			if rule.Group == nil {
+				return nil, i18n.NewError(ctx, i18n.MsgBridgeRuleInvalid, i, "a group is required for private messages")
+			}
+		default:
+			return nil, i18n.NewError(ctx, i18n.MsgBridgeRuleInvalid, i, fmt.Sprintf("unsupported type '%s'", rule.Type))
+		}
+		// Only confirmed messages are bridged, whatever events the filter selects
+		rule.Filter.Events = string(fftypes.EventTypeMessageConfirmed)
+		names[rule.Name] = true
+		bg.rules = append(bg.rules, rule)
+	}
+	return bg, nil
+}
+
+func (bg *bridgeManager) Start() error {
+	for _, rule := range bg.rules {
+		r := rule
+		listener := func(event *fftypes.EventDelivery) error {
+			return bg.bridgeMessage(bg.ctx, r, event.Reference)
+		}
+		if err := bg.sysevents.AddDurableSystemEventListener(bg.ctx, r.From, "bridge-"+r.Name, &r.Filter, listener); err != nil {
+			return err
+		}
+		log.L(bg.ctx).Infof("Bridge '%s' copying %s messages from namespace '%s' to '%s'", r.Name, r.Type, r.From, r.To)
+	}
+	return nil
+}
+
+// rejected checks whether an error is a problem with the message itself, which retrying will not resolve
+func rejected(err error) bool {
+	code := strings.SplitN(err.Error(), ":", 2)[0]
+	status, ok := i18n.GetStatusHint(code)
+	return ok && status >= 400 && status < 500
+}
+
+func (bg *bridgeManager) bridgeMessage(ctx context.Context, rule *Rule, msgID *fftypes.UUID) error {
+	msg, data, _, err := bg.data.GetMessageWithDataCached(ctx, msgID)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		log.L(ctx).Warnf("Bridge '%s' skipping message %s, which was not found", rule.Name, msgID)
+		return nil
+	}
+
+	// Loop prevention - a message bridged into this namespace refers to the message it was copied
+	// from in its CID, so it is never bridged back out again
+	if msg.Header.CID != nil {
+		correlated, err := bg.database.GetMessageByID(ctx, msg.Header.CID)
+		if err != nil {
+			return err
+		}
+		if correlated != nil && correlated.Header.Namespace != msg.Header.Namespace {
+			log.L(ctx).Debugf("Bridge '%s' skipping message %s, which was bridged from namespace '%s'", rule.Name, msgID, correlated.Header.Namespace)
+			return nil
+		}
+	}
+
+	// The event is redelivered if we fail before it is acknowledged, so check for an existing copy
+	fb := database.MessageQueryFactory.NewFilterLimit(ctx, 1)
+	existing, _, err := bg.database.GetMessages(ctx, fb.And(
+		fb.Eq("namespace", rule.To),
+		fb.Eq("cid", msgID),
+	))
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		log.L(ctx).Infof("Bridge '%s' already copied message %s to %s", rule.Name, msgID, existing[0].Header.ID)
+		return nil
+	}
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				CID:    msgID,
+				Tag:    msg.Header.Tag,
+				Topics: msg.Header.Topics,
+			},
+		},
+		Group: rule.Group,
+	}
+	if rule.Tag != "" {
+		in.Header.Tag = rule.Tag
+	}
+	if rule.Topic != "" {
+		in.Header.Topics = fftypes.FFStringArray{rule.Topic}
+	}
+	for _, d := range data {
+		in.InlineData = append(in.InlineData, &fftypes.DataRefOrValue{
+			Validator: d.Validator,
+			Datatype:  d.Datatype,
+			Value:     d.Value,
+			Blob:      d.Blob,
+		})
+	}
+
+	var out *fftypes.Message
+	if rule.Type == fftypes.MessageTypePrivate {
+		out, err = bg.messaging.SendMessage(ctx, rule.To, in, false)
+	} else {
+		out, err = bg.broadcast.BroadcastMessage(ctx, rule.To, in, false)
+	}
+	if err != nil {
+		if rejected(err) {
+			log.L(ctx).Errorf("Bridge '%s' could not copy message %s to namespace '%s': %s", rule.Name, msgID, rule.To, err)
+			return nil
+		}
+		return err
+	}
+	log.L(ctx).Infof("Bridge '%s' copied message %s to %s in namespace '%s'", rule.Name, msgID, out.Header.ID, rule.To)
+	return nil
+}
@@ -0,0 +1,313 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bridging
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/events/system"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/mocks/sysmessagingmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestBridges(t *testing.T, rules fftypes.JSONObjectArray) *bridgeManager {
+	config.Reset()
+	config.Set(config.BridgesRules, rules)
+	bg, err := NewBridgeManager(context.Background(), &databasemocks.Plugin{}, &datamocks.Manager{}, &sysmessagingmocks.SystemEvents{}, &broadcastmocks.Manager{}, &privatemessagingmocks.Manager{})
+	assert.NoError(t, err)
+	return bg.(*bridgeManager)
+}
+
+func newTestPromoteOrders(t *testing.T) (*bridgeManager, *Rule) {
+	bg := newTestBridges(t, fftypes.JSONObjectArray{
+		{"name": "promote", "from": "intake", "to": "settlement", "topic": "settle"},
+	})
+	return bg, bg.rules[0]
+}
+
+func TestNewBridgeManagerMissingDeps(t *testing.T) {
+	_, err := NewBridgeManager(context.Background(), nil, nil, nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestNewBridgeManagerInvalid(t *testing.T) {
+	for _, rules := range []fftypes.JSONObjectArray{
+		{{"name": "promote", "from": false}},
+		{{"name": "!bad", "from": "intake", "to": "settlement"}},
+		{{"name": "promote", "from": "intake", "to": "settlement"}, {"name": "promote", "from": "intake", "to": "settlement"}},
+		{{"name": "promote", "from": "!bad", "to": "settlement"}},
+		{{"name": "promote", "from": "intake", "to": "!bad"}},
+		{{"name": "promote", "from": "intake", "to": "intake"}},
+		{{"name": "promote", "from": "intake", "to": "settlement", "type": "private"}},
+		{{"name": "promote", "from": "intake", "to": "settlement", "type": "transfer_broadcast"}},
+	} {
+		config.Reset()
+		config.Set(config.BridgesRules, rules)
+		_, err := NewBridgeManager(context.Background(), &databasemocks.Plugin{}, &datamocks.Manager{}, &sysmessagingmocks.SystemEvents{}, &broadcastmocks.Manager{}, &privatemessagingmocks.Manager{})
+		assert.Regexp(t, "FF10501", err)
+	}
+}
+
+func TestStartBridges(t *testing.T) {
+	bg := newTestBridges(t, fftypes.JSONObjectArray{
+		{"name": "promote", "from": "intake", "to": "settlement", "filter": fftypes.JSONObject{"topic": "orders"}},
+		{"name": "escalate", "from": "intake", "to": "support", "type": "private", "group": fftypes.JSONObject{
+			"members": []interface{}{fftypes.JSONObject{"identity": "org1"}},
+		}},
+	})
+	assert.Equal(t, fftypes.MessageTypeBroadcast, bg.rules[0].Type)
+
+	msgID := fftypes.NewUUID()
+	mse := bg.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddDurableSystemEventListener", mock.Anything, "intake", "bridge-promote", mock.MatchedBy(func(filter *fftypes.SubscriptionFilter) bool {
+		return filter.Events == "message_confirmed" && filter.Topic == "orders"
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		mdm := bg.data.(*datamocks.Manager)
+		mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(nil, nil, false, nil)
+		err := args[4].(system.EventListener)(&fftypes.EventDelivery{
+			EnrichedEvent: fftypes.EnrichedEvent{
+				Event: fftypes.Event{Reference: msgID},
+			},
+		})
+		assert.NoError(t, err)
+	}).Return(nil)
+	mse.On("AddDurableSystemEventListener", mock.Anything, "intake", "bridge-escalate", mock.Anything, mock.Anything).Return(nil)
+
+	err := bg.Start()
+	assert.NoError(t, err)
+
+	mse.AssertExpectations(t)
+}
+
+func TestStartBridgesFail(t *testing.T) {
+	bg, _ := newTestPromoteOrders(t)
+
+	mse := bg.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddDurableSystemEventListener", mock.Anything, "intake", "bridge-promote", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := bg.Start()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestBridgeMessageBroadcast(t *testing.T) {
+	bg, rule := newTestPromoteOrders(t)
+
+	msgID := fftypes.NewUUID()
+	cid := fftypes.NewUUID()
+	blob := &fftypes.BlobRef{Hash: fftypes.NewRandB32()}
+	mdm := bg.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        msgID,
+			Namespace: "intake",
+			CID:       cid,
+			Tag:       "order",
+			Topics:    fftypes.FFStringArray{"orders"},
+		},
+	}, fftypes.DataArray{
+		{Validator: fftypes.ValidatorTypeJSON, Datatype: &fftypes.DatatypeRef{Name: "order", Version: "1"}, Value: fftypes.JSONAnyPtr(`{"id":1}`)},
+		{Blob: blob},
+	}, true, nil)
+
+	mdi := bg.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", mock.Anything, cid).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "intake"},
+	}, nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+
+	mbm := bg.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastMessage", mock.Anything, "settlement", mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		return in.Header.CID.Equals(msgID) &&
+			in.Header.Tag == "order" &&
+			in.Header.Topics.String() == "settle" &&
+			len(in.InlineData) == 2 &&
+			in.InlineData[0].Datatype.Name == "order" &&
+			in.InlineData[0].Value.String() == `{"id":1}` &&
+			in.InlineData[1].Blob == blob
+	}), false).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID()},
+	}, nil)
+
+	err := bg.bridgeMessage(bg.ctx, rule, msgID)
+	assert.NoError(t, err)
+
+	mdm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+}
+
+func TestBridgeMessagePrivate(t *testing.T) {
+	bg := newTestBridges(t, fftypes.JSONObjectArray{
+		{"name": "escalate", "from": "intake", "to": "support", "type": "private", "tag": "escalated", "group": fftypes.JSONObject{
+			"members": []interface{}{fftypes.JSONObject{"identity": "org1"}},
+		}},
+	})
+
+	msgID := fftypes.NewUUID()
+	mdm := bg.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "intake", Tag: "order"},
+	}, fftypes.DataArray{}, true, nil)
+
+	mdi := bg.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+
+	mpm := bg.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("SendMessage", mock.Anything, "support", mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		return in.Header.Tag == "escalated" && in.Group.Members[0].Identity == "org1"
+	}), false).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID()},
+	}, nil)
+
+	err := bg.bridgeMessage(bg.ctx, bg.rules[0], msgID)
+	assert.NoError(t, err)
+
+	mpm.AssertExpectations(t)
+}
+
+func TestBridgeMessageGetMessageFail(t *testing.T) {
+	bg, rule := newTestPromoteOrders(t)
+
+	msgID := fftypes.NewUUID()
+	mdm := bg.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(nil, nil, false, fmt.Errorf("pop"))
+
+	err := bg.bridgeMessage(bg.ctx, rule, msgID)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestBridgeMessageSkipBridged(t *testing.T) {
+	bg, rule := newTestPromoteOrders(t)
+
+	msgID := fftypes.NewUUID()
+	cid := fftypes.NewUUID()
+	mdm := bg.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "intake", CID: cid},
+	}, fftypes.DataArray{}, true, nil)
+
+	mdi := bg.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", mock.Anything, cid).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "settlement"},
+	}, nil)
+
+	err := bg.bridgeMessage(bg.ctx, rule, msgID)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBridgeMessageGetCorrelatedFail(t *testing.T) {
+	bg, rule := newTestPromoteOrders(t)
+
+	msgID := fftypes.NewUUID()
+	cid := fftypes.NewUUID()
+	mdm := bg.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "intake", CID: cid},
+	}, fftypes.DataArray{}, true, nil)
+
+	mdi := bg.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", mock.Anything, cid).Return(nil, fmt.Errorf("pop"))
+
+	err := bg.bridgeMessage(bg.ctx, rule, msgID)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestBridgeMessageAlreadyCopied(t *testing.T) {
+	bg, rule := newTestPromoteOrders(t)
+
+	msgID := fftypes.NewUUID()
+	mdm := bg.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "intake"},
+	}, fftypes.DataArray{}, true, nil)
+
+	mdi := bg.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{
+		{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}},
+	}, nil, nil)
+
+	err := bg.bridgeMessage(bg.ctx, rule, msgID)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBridgeMessageGetMessagesFail(t *testing.T) {
+	bg, rule := newTestPromoteOrders(t)
+
+	msgID := fftypes.NewUUID()
+	mdm := bg.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "intake"},
+	}, fftypes.DataArray{}, true, nil)
+
+	mdi := bg.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := bg.bridgeMessage(bg.ctx, rule, msgID)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestBridgeMessageRejected(t *testing.T) {
+	bg, rule := newTestPromoteOrders(t)
+
+	msgID := fftypes.NewUUID()
+	mdm := bg.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "intake"},
+	}, fftypes.DataArray{}, true, nil)
+
+	mdi := bg.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+
+	mbm := bg.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastMessage", mock.Anything, "settlement", mock.Anything, false).Return(nil, i18n.NewError(bg.ctx, i18n.MsgDatatypeNotFound, "order"))
+
+	err := bg.bridgeMessage(bg.ctx, rule, msgID)
+	assert.NoError(t, err)
+}
+
+func TestBridgeMessageSendFail(t *testing.T) {
+	bg, rule := newTestPromoteOrders(t)
+
+	msgID := fftypes.NewUUID()
+	mdm := bg.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "intake"},
+	}, fftypes.DataArray{}, true, nil)
+
+	mdi := bg.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+
+	mbm := bg.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastMessage", mock.Anything, "settlement", mock.Anything, false).Return(nil, fmt.Errorf("pop"))
+
+	err := bg.bridgeMessage(bg.ctx, rule, msgID)
+	assert.EqualError(t, err, "pop")
+}
@@ -0,0 +1,139 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broadcast
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// anonymousAuthorField is the field in the value of the data attachment of an anonymous broadcast
+// that holds the encrypted identity of the real author
+const anonymousAuthorField = "anonymousAuthor"
+
+// anonymousRelay holds the relay identity that anonymous broadcasts are sent as, and the cipher for the identity
+// key that is shared privately between the members authorized to see the real authors
+type anonymousRelay struct {
+	signer fftypes.SignerRef
+	aead   cipher.AEAD
+}
+
+func newAnonymousRelay(ctx context.Context) (*anonymousRelay, error) {
+	identityKey := config.GetString(config.BroadcastAnonymousIdentityKey)
+	if identityKey == "" {
+		return nil, nil
+	}
+	keyBytes, err := hex.DecodeString(strings.TrimPrefix(identityKey, "0x"))
+	if err != nil || len(keyBytes) != 32 {
+		return nil, i18n.NewError(ctx, i18n.MsgAnonymousIdentityKeyInvalid)
+	}
+	block, _ := aes.NewCipher(keyBytes) // cannot fail for a 32 byte key
+	aead, _ := cipher.NewGCM(block)
+	return &anonymousRelay{
+		signer: fftypes.SignerRef{
+			Author: config.GetString(config.BroadcastAnonymousAuthor),
+			Key:    config.GetString(config.BroadcastAnonymousKey),
+		},
+		aead: aead,
+	}, nil
+}
+
+// anonymize switches the author of the message to the relay identity, and attaches the real author encrypted
+// with the identity key. The message ID is bound into the encryption, so the attachment cannot be replayed.
+func (s *broadcastSender) anonymize(ctx context.Context) error {
+	msg := s.msg.Message
+	relay := s.mgr.anonymous
+	if relay == nil || (relay.signer.Author == "" && relay.signer.Key == "") {
+		return i18n.NewError(ctx, i18n.MsgAnonymousNotConfigured)
+	}
+	if msg.Header.Type != fftypes.MessageTypeBroadcast {
+		return i18n.NewError(ctx, i18n.MsgAnonymousBroadcastType, msg.Header.Type)
+	}
+
+	author, _ := json.Marshal(&msg.Header.SignerRef)
+	nonce := make([]byte, relay.aead.NonceSize())
+	_, _ = rand.Read(nonce)
+	sealed := relay.aead.Seal(nonce, nonce, author, msg.Header.ID[:])
+	msg.InlineData = append(msg.InlineData, &fftypes.DataRefOrValue{
+		Value: fftypes.JSONAnyPtr(fftypes.JSONObject{
+			anonymousAuthorField: base64.StdEncoding.EncodeToString(sealed),
+		}.String()),
+	})
+
+	msg.Header.SignerRef = relay.signer
+	if err := s.mgr.identity.ResolveInputSigningIdentity(ctx, msg.Header.Namespace, &msg.Header.SignerRef); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgAuthorInvalid)
+	}
+	return nil
+}
+
+// GetAnonymousAuthor decrypts the real author of an anonymous broadcast, and checks the key is registered to that
+// author. Only the members holding the identity key are able to do this.
+func (bm *broadcastManager) GetAnonymousAuthor(ctx context.Context, ns, id string) (*fftypes.SignerRef, error) {
+	if bm.anonymous == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgAnonymousNotConfigured)
+	}
+	msgID, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	msg, data, _, err := bm.data.GetMessageWithDataCached(ctx, msgID)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil || msg.Header.Namespace != ns {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+
+	var encoded string
+	for _, d := range data {
+		if encoded = d.Value.JSONObjectNowarn().GetString(anonymousAuthorField); encoded != "" {
+			break
+		}
+	}
+	if encoded == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgNotAnonymousBroadcast, msgID)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	nonceSize := bm.anonymous.aead.NonceSize()
+	if err != nil || len(sealed) < nonceSize {
+		return nil, i18n.NewError(ctx, i18n.MsgAnonymousAuthorDecryptFailed, msgID)
+	}
+	author, err := bm.anonymous.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], msgID[:])
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgAnonymousAuthorDecryptFailed, msgID)
+	}
+
+	var signer fftypes.SignerRef
+	if err := json.Unmarshal(author, &signer); err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgAnonymousAuthorDecryptFailed, msgID)
+	}
+	if err := bm.identity.ResolveInputSigningIdentity(ctx, ns, &signer); err != nil {
+		return nil, err
+	}
+	return &signer, nil
+}
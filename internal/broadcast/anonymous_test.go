@@ -0,0 +1,260 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package broadcast
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
+	"github.com/hyperledger/firefly/mocks/batchmocks"
+	"github.com/hyperledger/firefly/mocks/batchpinmocks"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
+	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testIdentityKey = "0x000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func newTestAnonymousBroadcast(t *testing.T) (*broadcastManager, func()) {
+	bm, cancel := newTestBroadcast(t)
+	config.Set(config.BroadcastAnonymousAuthor, "did:firefly:org/relay")
+	config.Set(config.BroadcastAnonymousKey, "0xrelay")
+	config.Set(config.BroadcastAnonymousIdentityKey, testIdentityKey)
+	var err error
+	bm.anonymous, err = newAnonymousRelay(bm.ctx)
+	assert.NoError(t, err)
+	return bm, cancel
+}
+
+func sealTestAuthor(bm *broadcastManager, msgID *fftypes.UUID, author []byte) string {
+	nonce := make([]byte, bm.anonymous.aead.NonceSize())
+	return base64.StdEncoding.EncodeToString(bm.anonymous.aead.Seal(nonce, nonce, author, msgID[:]))
+}
+
+func TestInitBadAnonymousIdentityKey(t *testing.T) {
+	config.Reset()
+	config.Set(config.BroadcastAnonymousIdentityKey, "0x1234")
+	_, err := NewBroadcastManager(context.Background(), &databasemocks.Plugin{}, &identitymanagermocks.Manager{}, &datamocks.Manager{}, &blockchainmocks.Plugin{}, &dataexchangemocks.Plugin{}, &sharedstoragemocks.Plugin{}, &batchmocks.Manager{}, &syncasyncmocks.Bridge{}, &batchpinmocks.Submitter{}, &metricsmocks.Manager{}, &operationmocks.Manager{}, &approvalmocks.Manager{})
+	assert.Regexp(t, "FF10531", err)
+}
+
+func TestBroadcastAnonymousRoundTrip(t *testing.T) {
+	bm, cancel := newTestAnonymousBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				SignerRef: fftypes.SignerRef{
+					Author: "did:firefly:org/whistleblower",
+					Key:    "0x12345",
+				},
+			},
+		},
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+		Anonymous: true,
+	}
+	msg, err := bm.BroadcastMessage(ctx, "ns1", in, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "did:firefly:org/relay", msg.Header.Author)
+	assert.Equal(t, "0xrelay", msg.Header.Key)
+	assert.Len(t, in.InlineData, 2)
+
+	mdm.On("GetMessageWithDataCached", ctx, msg.Header.ID).Return(msg, fftypes.DataArray{
+		{Value: in.InlineData[0].Value},
+		{Value: in.InlineData[1].Value},
+	}, true, nil)
+	signer, err := bm.GetAnonymousAuthor(ctx, "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, "did:firefly:org/whistleblower", signer.Author)
+	assert.Equal(t, "0x12345", signer.Key)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestBroadcastAnonymousNotConfigured(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", mock.Anything, "ns1", mock.Anything).Return(nil)
+
+	_, err := bm.BroadcastMessage(context.Background(), "ns1", &fftypes.MessageInOut{
+		Anonymous: true,
+	}, false)
+	assert.Regexp(t, "FF10530", err)
+}
+
+func TestBroadcastAnonymousNotBroadcastType(t *testing.T) {
+	bm, cancel := newTestAnonymousBroadcast(t)
+	defer cancel()
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", mock.Anything, "ns1", mock.Anything).Return(nil)
+
+	_, err := bm.BroadcastMessage(context.Background(), "ns1", &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Type: fftypes.MessageTypeTransferBroadcast,
+			},
+		},
+		Anonymous: true,
+	}, false)
+	assert.Regexp(t, "FF10532", err)
+}
+
+func TestBroadcastAnonymousRelayInvalid(t *testing.T) {
+	bm, cancel := newTestAnonymousBroadcast(t)
+	defer cancel()
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", mock.Anything, "ns1", mock.Anything).Return(nil).Once()
+	mim.On("ResolveInputSigningIdentity", mock.Anything, "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := bm.BroadcastMessage(context.Background(), "ns1", &fftypes.MessageInOut{
+		Anonymous: true,
+	}, false)
+	assert.Regexp(t, "FF10206.*pop", err)
+}
+
+func TestGetAnonymousAuthorNotConfigured(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	_, err := bm.GetAnonymousAuthor(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10530", err)
+}
+
+func TestGetAnonymousAuthorBadID(t *testing.T) {
+	bm, cancel := newTestAnonymousBroadcast(t)
+	defer cancel()
+	_, err := bm.GetAnonymousAuthor(context.Background(), "ns1", "!uuid")
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestGetAnonymousAuthorLookupFail(t *testing.T) {
+	bm, cancel := newTestAnonymousBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(nil, nil, false, fmt.Errorf("pop"))
+	_, err := bm.GetAnonymousAuthor(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.Regexp(t, "pop", err)
+}
+
+func TestGetAnonymousAuthorWrongNamespace(t *testing.T) {
+	bm, cancel := newTestAnonymousBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "ns2"},
+	}, fftypes.DataArray{}, true, nil)
+	_, err := bm.GetAnonymousAuthor(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetAnonymousAuthorNotAnonymous(t *testing.T) {
+	bm, cancel := newTestAnonymousBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "ns1"},
+	}, fftypes.DataArray{
+		{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		{Blob: &fftypes.BlobRef{}},
+	}, true, nil)
+	_, err := bm.GetAnonymousAuthor(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10533", err)
+}
+
+func TestGetAnonymousAuthorBadEncoding(t *testing.T) {
+	bm, cancel := newTestAnonymousBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "ns1"},
+	}, fftypes.DataArray{
+		{Value: fftypes.JSONAnyPtr(`{"anonymousAuthor": "!base64"}`)},
+	}, true, nil)
+	_, err := bm.GetAnonymousAuthor(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10534", err)
+}
+
+func TestGetAnonymousAuthorOtherMessage(t *testing.T) {
+	bm, cancel := newTestAnonymousBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	// The attachment was sealed for a different message, so cannot be decrypted
+	sealed := sealTestAuthor(bm, fftypes.NewUUID(), []byte(`{"author": "did:firefly:org/org1"}`))
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "ns1"},
+	}, fftypes.DataArray{
+		{Value: fftypes.JSONAnyPtr(fmt.Sprintf(`{"anonymousAuthor": "%s"}`, sealed))},
+	}, true, nil)
+	_, err := bm.GetAnonymousAuthor(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10534", err)
+}
+
+func TestGetAnonymousAuthorBadJSON(t *testing.T) {
+	bm, cancel := newTestAnonymousBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	msgID := fftypes.NewUUID()
+	sealed := sealTestAuthor(bm, msgID, []byte(`!json`))
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "ns1"},
+	}, fftypes.DataArray{
+		{Value: fftypes.JSONAnyPtr(fmt.Sprintf(`{"anonymousAuthor": "%s"}`, sealed))},
+	}, true, nil)
+	_, err := bm.GetAnonymousAuthor(context.Background(), "ns1", msgID.String())
+	assert.Regexp(t, "FF10534", err)
+}
+
+func TestGetAnonymousAuthorUnverified(t *testing.T) {
+	bm, cancel := newTestAnonymousBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	msgID := fftypes.NewUUID()
+	sealed := sealTestAuthor(bm, msgID, []byte(`{"author": "did:firefly:org/org1", "key": "0x12345"}`))
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "ns1"},
+	}, fftypes.DataArray{
+		{Value: fftypes.JSONAnyPtr(fmt.Sprintf(`{"anonymousAuthor": "%s"}`, sealed))},
+	}, true, nil)
+	mim.On("ResolveInputSigningIdentity", mock.Anything, "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := bm.GetAnonymousAuthor(context.Background(), "ns1", msgID.String())
+	assert.Regexp(t, "pop", err)
+}
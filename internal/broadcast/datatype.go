@@ -1,4 +1,4 @@
-// Copyright © 2021 Kaleido, Inc.
+// Copyright © 2022 Kaleido, Inc.
 //
 // SPDX-License-Identifier: Apache-2.0
 //
@@ -19,19 +19,24 @@ package broadcast
 import (
 	"context"
 
+	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
-func (bm *broadcastManager) BroadcastDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype, waitConfirm bool) (*fftypes.Message, error) {
-
-	// Validate the input data definition data
+func (bm *broadcastManager) prepareDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype) error {
 	datatype.ID = fftypes.NewUUID()
 	datatype.Created = fftypes.Now()
 	datatype.Namespace = ns
 	if datatype.Validator == "" {
 		datatype.Validator = fftypes.ValidatorTypeJSON
 	}
-	if err := datatype.Validate(ctx, false); err != nil {
+	return datatype.Validate(ctx, false)
+}
+
+func (bm *broadcastManager) BroadcastDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype, waitConfirm bool) (*fftypes.Message, error) {
+
+	// Validate the input data definition data
+	if err := bm.prepareDatatype(ctx, ns, datatype); err != nil {
 		return nil, err
 	}
 	if err := bm.data.VerifyNamespaceExists(ctx, datatype.Namespace); err != nil {
@@ -49,3 +54,87 @@ func (bm *broadcastManager) BroadcastDatatype(ctx context.Context, ns string, da
 	}
 	return msg, err
 }
+
+// BroadcastDatatypeBundle validates a set of datatypes that reference each other, and broadcasts them in a single
+// definition message. The datatypes are re-ordered so each follows the datatypes it references, and they are
+// confirmed together by all members - or all rejected, if any one of them is invalid.
+func (bm *broadcastManager) BroadcastDatatypeBundle(ctx context.Context, ns string, bundle *fftypes.DatatypeBundle, waitConfirm bool) (*fftypes.Message, error) {
+
+	// Validate the input data definition data
+	if len(bundle.Datatypes) == 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgDatatypeBundleEmpty)
+	}
+	byRef := make(map[string]*fftypes.Datatype, len(bundle.Datatypes))
+	for _, datatype := range bundle.Datatypes {
+		if err := bm.prepareDatatype(ctx, ns, datatype); err != nil {
+			return nil, err
+		}
+		ref := (&fftypes.DatatypeRef{Name: datatype.Name, Version: datatype.Version}).String()
+		if byRef[ref] != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgDatatypeBundleDuplicate, ref)
+		}
+		byRef[ref] = datatype
+	}
+	if err := bm.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	ordered, err := orderDatatypes(ctx, bundle.Datatypes, byRef)
+	if err != nil {
+		return nil, err
+	}
+	bundle.Datatypes = ordered
+	for _, datatype := range bundle.Datatypes {
+		datatype.Hash = datatype.Value.Hash()
+		existing, err := bm.database.GetDatatypeByName(ctx, ns, datatype.Name, datatype.Version)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgDatatypeExists, &fftypes.DatatypeRef{Name: datatype.Name, Version: datatype.Version})
+		}
+	}
+
+	// Verify the data types are now all valid, before we broadcast them
+	if err := bm.data.CheckDatatypes(ctx, ns, bundle.Datatypes); err != nil {
+		return nil, err
+	}
+	msg, err := bm.BroadcastDefinitionAsNode(ctx, ns, bundle, fftypes.SystemTagDefineDatatypeBundle, waitConfirm)
+	if msg != nil {
+		bundle.SetBroadcastMessage(msg.Header.ID)
+	}
+	return msg, err
+}
+
+// orderDatatypes sorts the datatypes so that each comes after the datatypes it references in the bundle,
+// otherwise keeping the order they were supplied in
+func orderDatatypes(ctx context.Context, datatypes []*fftypes.Datatype, byRef map[string]*fftypes.Datatype) ([]*fftypes.Datatype, error) {
+	ordered := make([]*fftypes.Datatype, 0, len(datatypes))
+	added := make(map[*fftypes.Datatype]bool, len(datatypes))
+	visiting := make(map[*fftypes.Datatype]bool)
+	var visit func(datatype *fftypes.Datatype) error
+	visit = func(datatype *fftypes.Datatype) error {
+		if added[datatype] {
+			return nil
+		}
+		if visiting[datatype] {
+			return i18n.NewError(ctx, i18n.MsgDatatypeBundleCycle, &fftypes.DatatypeRef{Name: datatype.Name, Version: datatype.Version})
+		}
+		visiting[datatype] = true
+		for _, ref := range datatype.References() {
+			if dep := byRef[ref.String()]; dep != nil {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		added[datatype] = true
+		ordered = append(ordered, datatype)
+		return nil
+	}
+	for _, datatype := range datatypes {
+		if err := visit(datatype); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -136,3 +136,138 @@ func TestBroadcastOk(t *testing.T) {
 	mdm.AssertExpectations(t)
 	mim.AssertExpectations(t)
 }
+
+func testDatatypeBundle() *fftypes.DatatypeBundle {
+	return &fftypes.DatatypeBundle{
+		Datatypes: []*fftypes.Datatype{
+			{
+				Name:    "customer",
+				Version: "1.0",
+				Value:   fftypes.JSONAnyPtr(`{"properties": {"address": {"$ref": "ff://datatypes/address/1.0"}, "country": {"$ref": "ff://datatypes/country/1.0"}}}`),
+			},
+			{
+				Name:    "address",
+				Version: "1.0",
+				Value:   fftypes.JSONAnyPtr(`{"properties": {"country": {"$ref": "ff://datatypes/country/1.0"}, "region": {"$ref": "ff://datatypes/region/1.0"}}}`),
+			},
+			{
+				Name:    "country",
+				Version: "1.0",
+				Value:   fftypes.JSONAnyPtr(`{"type": "string"}`),
+			},
+		},
+	}
+}
+
+func TestBroadcastDatatypeBundleOk(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	mim.On("ResolveInputSigningIdentity", mock.Anything, "ns1", mock.Anything).Return(nil)
+	mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", mock.Anything, "1.0").Return(nil, nil)
+	mdm.On("CheckDatatypes", mock.Anything, "ns1", mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	bundle := testDatatypeBundle()
+	msg, err := bm.BroadcastDatatypeBundle(context.Background(), "ns1", bundle, false)
+	assert.NoError(t, err)
+
+	assert.Len(t, bundle.Datatypes, 3)
+	assert.Equal(t, "country", bundle.Datatypes[0].Name)
+	assert.Equal(t, "address", bundle.Datatypes[1].Name)
+	assert.Equal(t, "customer", bundle.Datatypes[2].Name)
+	for _, dt := range bundle.Datatypes {
+		assert.Equal(t, "ns1", dt.Namespace)
+		assert.Equal(t, fftypes.ValidatorTypeJSON, dt.Validator)
+		assert.NotNil(t, dt.Hash)
+		assert.Equal(t, msg.Header.ID, dt.Message)
+	}
+	assert.Equal(t, fftypes.SystemTagDefineDatatypeBundle, msg.Header.Tag)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestBroadcastDatatypeBundleEmpty(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	_, err := bm.BroadcastDatatypeBundle(context.Background(), "ns1", &fftypes.DatatypeBundle{}, false)
+	assert.Regexp(t, "FF10508", err)
+}
+
+func TestBroadcastDatatypeBundleBadType(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	_, err := bm.BroadcastDatatypeBundle(context.Background(), "ns1", &fftypes.DatatypeBundle{
+		Datatypes: []*fftypes.Datatype{{Validator: fftypes.ValidatorType("wrong")}},
+	}, false)
+	assert.Regexp(t, "FF10132.*validator", err)
+}
+
+func TestBroadcastDatatypeBundleDuplicate(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	bundle := testDatatypeBundle()
+	bundle.Datatypes[1].Name = "customer"
+	_, err := bm.BroadcastDatatypeBundle(context.Background(), "ns1", bundle, false)
+	assert.Regexp(t, "FF10509.*customer/1.0", err)
+}
+
+func TestBroadcastDatatypeBundleNSFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(fmt.Errorf("pop"))
+	_, err := bm.BroadcastDatatypeBundle(context.Background(), "ns1", testDatatypeBundle(), false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestBroadcastDatatypeBundleCycle(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	bundle := testDatatypeBundle()
+	bundle.Datatypes[2].Value = fftypes.JSONAnyPtr(`{"properties": {"customer": {"$ref": "ff://datatypes/customer/1.0"}}}`)
+	_, err := bm.BroadcastDatatypeBundle(context.Background(), "ns1", bundle, false)
+	assert.Regexp(t, "FF10510.*customer/1.0", err)
+}
+
+func TestBroadcastDatatypeBundleGetExistingFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "country", "1.0").Return(nil, fmt.Errorf("pop"))
+	_, err := bm.BroadcastDatatypeBundle(context.Background(), "ns1", testDatatypeBundle(), false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestBroadcastDatatypeBundleExists(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "country", "1.0").Return(&fftypes.Datatype{}, nil)
+	_, err := bm.BroadcastDatatypeBundle(context.Background(), "ns1", testDatatypeBundle(), false)
+	assert.Regexp(t, "FF10511.*country/1.0", err)
+}
+
+func TestBroadcastDatatypeBundleInvalid(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdi := bm.database.(*databasemocks.Plugin)
+	mdm := bm.data.(*datamocks.Manager)
+	mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	mdi.On("GetDatatypeByName", mock.Anything, "ns1", mock.Anything, "1.0").Return(nil, nil)
+	mdm.On("CheckDatatypes", mock.Anything, "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := bm.BroadcastDatatypeBundle(context.Background(), "ns1", testDatatypeBundle(), false)
+	assert.EqualError(t, err, "pop")
+}
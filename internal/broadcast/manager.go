@@ -18,7 +18,9 @@ package broadcast
 
 import (
 	"context"
+	"time"
 
+	"github.com/hyperledger/firefly/internal/approvals"
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/batchpin"
 	"github.com/hyperledger/firefly/internal/config"
@@ -28,6 +30,7 @@ import (
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/internal/residency"
 	"github.com/hyperledger/firefly/internal/syncasync"
 	"github.com/hyperledger/firefly/internal/sysmessaging"
 	"github.com/hyperledger/firefly/pkg/blockchain"
@@ -44,8 +47,12 @@ type Manager interface {
 
 	NewBroadcast(ns string, in *fftypes.MessageInOut) sysmessaging.MessageSender
 	BroadcastDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype, waitConfirm bool) (msg *fftypes.Message, err error)
+	BroadcastDatatypeBundle(ctx context.Context, ns string, bundle *fftypes.DatatypeBundle, waitConfirm bool) (msg *fftypes.Message, err error)
 	BroadcastNamespace(ctx context.Context, ns *fftypes.Namespace, waitConfirm bool) (msg *fftypes.Message, err error)
 	BroadcastMessage(ctx context.Context, ns string, in *fftypes.MessageInOut, waitConfirm bool) (out *fftypes.Message, err error)
+	RequestReply(ctx context.Context, ns string, request *fftypes.MessageInOut, timeout time.Duration) (reply *fftypes.MessageInOut, err error)
+	ValidateMessage(ctx context.Context, ns string, in *fftypes.MessageInOut) *fftypes.MessageValidationReport
+	GetAnonymousAuthor(ctx context.Context, ns, id string) (*fftypes.SignerRef, error)
 	BroadcastDefinitionAsNode(ctx context.Context, ns string, def fftypes.Definition, tag string, waitConfirm bool) (msg *fftypes.Message, err error)
 	BroadcastDefinition(ctx context.Context, ns string, def fftypes.Definition, signingIdentity *fftypes.SignerRef, tag string, waitConfirm bool) (msg *fftypes.Message, err error)
 	BroadcastIdentityClaim(ctx context.Context, ns string, def *fftypes.IdentityClaim, signingIdentity *fftypes.SignerRef, tag string, waitConfirm bool) (msg *fftypes.Message, err error)
@@ -72,12 +79,23 @@ type broadcastManager struct {
 	maxBatchPayloadLength int64
 	metrics               metrics.Manager
 	operations            operations.Manager
+	approvals             approvals.Manager
+	residency             *residency.Policy
+	anonymous             *anonymousRelay
 }
 
-func NewBroadcastManager(ctx context.Context, di database.Plugin, im identity.Manager, dm data.Manager, bi blockchain.Plugin, dx dataexchange.Plugin, si sharedstorage.Plugin, ba batch.Manager, sa syncasync.Bridge, bp batchpin.Submitter, mm metrics.Manager, om operations.Manager) (Manager, error) {
-	if di == nil || im == nil || dm == nil || bi == nil || dx == nil || si == nil || ba == nil || mm == nil || om == nil {
+func NewBroadcastManager(ctx context.Context, di database.Plugin, im identity.Manager, dm data.Manager, bi blockchain.Plugin, dx dataexchange.Plugin, si sharedstorage.Plugin, ba batch.Manager, sa syncasync.Bridge, bp batchpin.Submitter, mm metrics.Manager, om operations.Manager, am approvals.Manager) (Manager, error) {
+	if di == nil || im == nil || dm == nil || bi == nil || dx == nil || si == nil || ba == nil || mm == nil || om == nil || am == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
+	rp, err := residency.NewPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ar, err := newAnonymousRelay(ctx)
+	if err != nil {
+		return nil, err
+	}
 	bm := &broadcastManager{
 		ctx:                   ctx,
 		database:              di,
@@ -92,6 +110,9 @@ func NewBroadcastManager(ctx context.Context, di database.Plugin, im identity.Ma
 		maxBatchPayloadLength: config.GetByteSize(config.BroadcastBatchPayloadLimit),
 		metrics:               mm,
 		operations:            om,
+		approvals:             am,
+		residency:             rp,
+		anonymous:             ar,
 	}
 
 	bo := batch.DispatcherOptions{
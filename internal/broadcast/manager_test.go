@@ -27,6 +27,7 @@ import (
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
 	"github.com/hyperledger/firefly/mocks/batchmocks"
 	"github.com/hyperledger/firefly/mocks/batchpinmocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
@@ -56,7 +57,9 @@ func newTestBroadcastCommon(t *testing.T, metricsEnabled bool) (*broadcastManage
 	mbp := &batchpinmocks.Submitter{}
 	mmi := &metricsmocks.Manager{}
 	mom := &operationmocks.Manager{}
+	mam := &approvalmocks.Manager{}
 	mmi.On("IsMetricsEnabled").Return(metricsEnabled)
+	mim.On("VerifyNodeOwnerEndorsed", mock.Anything).Return(nil).Maybe()
 	mbi.On("Name").Return("ut_blockchain").Maybe()
 	mpi.On("Name").Return("ut_sharedstorage").Maybe()
 	mba.On("RegisterDispatcher",
@@ -68,6 +71,7 @@ func newTestBroadcastCommon(t *testing.T, metricsEnabled bool) (*broadcastManage
 			fftypes.MessageTypeTransferBroadcast,
 		}, mock.Anything, mock.Anything).Return()
 	mom.On("RegisterHandler", mock.Anything, mock.Anything, mock.Anything)
+	mam.On("ApplyPolicy", mock.Anything, mock.Anything).Return().Maybe()
 
 	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
 	rag.RunFn = func(a mock.Arguments) {
@@ -77,7 +81,7 @@ func newTestBroadcastCommon(t *testing.T, metricsEnabled bool) (*broadcastManage
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
-	b, err := NewBroadcastManager(ctx, mdi, mim, mdm, mbi, mdx, mpi, mba, msa, mbp, mmi, mom)
+	b, err := NewBroadcastManager(ctx, mdi, mim, mdm, mbi, mdx, mpi, mba, msa, mbp, mmi, mom, mam)
 	assert.NoError(t, err)
 	return b.(*broadcastManager), cancel
 }
@@ -94,10 +98,19 @@ func newTestBroadcastWithMetrics(t *testing.T) (*broadcastManager, func()) {
 }
 
 func TestInitFail(t *testing.T) {
-	_, err := NewBroadcastManager(context.Background(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	_, err := NewBroadcastManager(context.Background(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitBadResidency(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "residency": fftypes.JSONObject{"sharedStorage": "ipfs"}},
+	})
+	_, err := NewBroadcastManager(context.Background(), &databasemocks.Plugin{}, &identitymanagermocks.Manager{}, &datamocks.Manager{}, &blockchainmocks.Plugin{}, &dataexchangemocks.Plugin{}, &sharedstoragemocks.Plugin{}, &batchmocks.Manager{}, &syncasyncmocks.Bridge{}, &batchpinmocks.Submitter{}, &metricsmocks.Manager{}, &operationmocks.Manager{}, &approvalmocks.Manager{})
+	assert.Regexp(t, "FF10409", err)
+}
+
 func TestName(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
@@ -18,6 +18,7 @@ package broadcast
 
 import (
 	"context"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/i18n"
@@ -51,6 +52,23 @@ func (bm *broadcastManager) BroadcastMessage(ctx context.Context, ns string, in
 	return &in.Message, err
 }
 
+func (bm *broadcastManager) RequestReply(ctx context.Context, ns string, in *fftypes.MessageInOut, timeout time.Duration) (*fftypes.MessageInOut, error) {
+	if in.Header.Tag == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgRequestReplyTagRequired)
+	}
+	if in.Header.CID != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgRequestCannotHaveCID)
+	}
+	broadcast := bm.NewBroadcast(ns, in)
+	return bm.syncasync.WaitForReply(ctx, ns, in.Header.ID, timeout, broadcast.Send)
+}
+
+// ValidateMessage runs the checks that would be performed when sending the message, without writing anything.
+// Every check is reported, rather than stopping at the first failure.
+func (bm *broadcastManager) ValidateMessage(ctx context.Context, ns string, in *fftypes.MessageInOut) *fftypes.MessageValidationReport {
+	return bm.NewBroadcast(ns, in).(*broadcastSender).validate(ctx)
+}
+
 type broadcastSender struct {
 	mgr       *broadcastManager
 	namespace string
@@ -97,6 +115,13 @@ func (s *broadcastSender) setDefaults() {
 
 func (s *broadcastSender) resolveAndSend(ctx context.Context, method sendMethod) error {
 
+	// Until the org that owns this node is endorsed by the network, it can only broadcast system definitions
+	if s.namespace != fftypes.SystemNamespace {
+		if err := s.mgr.identity.VerifyNodeOwnerEndorsed(ctx); err != nil {
+			return err
+		}
+	}
+
 	if !s.resolved {
 		if err := s.resolve(ctx); err != nil {
 			return err
@@ -113,6 +138,13 @@ func (s *broadcastSender) resolveAndSend(ctx context.Context, method sendMethod)
 func (s *broadcastSender) resolve(ctx context.Context) error {
 	msg := s.msg.Message
 
+	// Fill in anything not supplied on the send from the template
+	if msg.Template != "" {
+		if err := s.mgr.data.ApplyMessageTemplate(ctx, msg); err != nil {
+			return err
+		}
+	}
+
 	// Resolve the sending identity
 	if msg.Header.Type != fftypes.MessageTypeDefinition || msg.Header.Tag != fftypes.SystemTagIdentityClaim {
 		if err := s.mgr.identity.ResolveInputSigningIdentity(ctx, msg.Header.Namespace, &msg.Header.SignerRef); err != nil {
@@ -120,11 +152,49 @@ func (s *broadcastSender) resolve(ctx context.Context) error {
 		}
 	}
 
+	// Anonymous broadcasts are sent as the relay identity, with the real author encrypted in an attachment
+	if msg.Anonymous {
+		if err := s.anonymize(ctx); err != nil {
+			return err
+		}
+	}
+
 	// The data manager is responsible for the heavy lifting of storing/validating all our in-line data elements
 	err := s.mgr.data.ResolveInlineData(ctx, s.msg)
 	return err
 }
 
+func (s *broadcastSender) validate(ctx context.Context) *fftypes.MessageValidationReport {
+	msg := s.msg.Message
+	report := fftypes.NewMessageValidationReport(msg.Header.Type)
+
+	if s.namespace != fftypes.SystemNamespace {
+		report.AddCheck(fftypes.MessageValidationCheckEndorsement, s.mgr.identity.VerifyNodeOwnerEndorsed(ctx))
+	}
+	// The remaining checks depend on the template being applied
+	if msg.Template != "" && !report.AddCheck(fftypes.MessageValidationCheckTemplate, s.mgr.data.ApplyMessageTemplate(ctx, msg)) {
+		return report
+	}
+	if msg.Header.Type != fftypes.MessageTypeDefinition || msg.Header.Tag != fftypes.SystemTagIdentityClaim {
+		err := s.mgr.identity.ResolveInputSigningIdentity(ctx, msg.Header.Namespace, &msg.Header.SignerRef)
+		if err != nil {
+			err = i18n.WrapError(ctx, err, i18n.MsgAuthorInvalid)
+		}
+		report.AddCheck(fftypes.MessageValidationCheckIdentity, err)
+	}
+	if report.AddCheck(fftypes.MessageValidationCheckData, s.mgr.data.ResolveInlineData(ctx, s.msg)) {
+		var err error
+		report.Size = msg.EstimateSize(true)
+		report.MaxSize = s.mgr.maxBatchPayloadLength
+		if report.Size > report.MaxSize {
+			err = i18n.NewError(ctx, i18n.MsgTooLargeBroadcast, float64(report.Size)/1024, float64(report.MaxSize)/1024)
+		}
+		report.AddCheck(fftypes.MessageValidationCheckSize, err)
+	}
+	report.AddCheck(fftypes.MessageValidationCheckResidency, s.mgr.residency.CheckSharedStorage(ctx, msg.Header.Namespace, s.mgr.sharedstorage.Name()))
+	return report
+}
+
 func (s *broadcastSender) sendInternal(ctx context.Context, method sendMethod) (err error) {
 	if method == methodSendAndWait {
 		out, err := s.mgr.syncasync.WaitForMessage(ctx, s.namespace, s.msg.Message.Header.ID, s.Send)
@@ -134,8 +204,11 @@ func (s *broadcastSender) sendInternal(ctx context.Context, method sendMethod) (
 		return err
 	}
 
-	// Seal the message
+	// Check the payload is permitted to be published to our shared storage, then seal the message
 	msg := s.msg.Message
+	if err := s.mgr.residency.CheckSharedStorage(ctx, msg.Header.Namespace, s.mgr.sharedstorage.Name()); err != nil {
+		return err
+	}
 	if err := msg.Seal(ctx); err != nil {
 		return err
 	}
@@ -143,7 +216,8 @@ func (s *broadcastSender) sendInternal(ctx context.Context, method sendMethod) (
 		return nil
 	}
 
-	// Write the message
+	// Hold back the message if it requires approval, then write it
+	s.mgr.approvals.ApplyPolicy(ctx, &msg.Message)
 	if err := s.mgr.data.WriteNewMessage(ctx, s.msg); err != nil {
 		return err
 	}
@@ -20,9 +20,13 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/residency"
 	"github.com/hyperledger/firefly/internal/syncasync"
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
@@ -62,6 +66,197 @@ func TestBroadcastMessageOk(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestBroadcastMessageTemplateOk(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ApplyMessageTemplate", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		in := args[1].(*fftypes.MessageInOut)
+		in.Header.Tag = "tag1"
+		in.InlineData = fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		}
+	})
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	msg, err := bm.BroadcastMessage(ctx, "ns1", &fftypes.MessageInOut{
+		Template: "template1",
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "tag1", msg.Header.Tag)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestBroadcastMessageTemplateFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ApplyMessageTemplate", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := bm.BroadcastMessage(ctx, "ns1", &fftypes.MessageInOut{
+		Template: "template1",
+	}, false)
+	assert.EqualError(t, err, "pop")
+
+	mdm.AssertExpectations(t)
+}
+
+func TestBroadcastMessageNodeOwnerNotEndorsed(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mim := &identitymanagermocks.Manager{}
+	bm.identity = mim
+
+	ctx := context.Background()
+	mim.On("VerifyNodeOwnerEndorsed", ctx).Return(fmt.Errorf("pop"))
+
+	_, err := bm.BroadcastMessage(ctx, "ns1", &fftypes.MessageInOut{
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+	}, false)
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+}
+
+func TestBroadcastMessageAwaitingApproval(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+	mam := &approvalmocks.Manager{}
+	bm.approvals = mam
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", mock.Anything, mock.MatchedBy(func(newMsg *data.NewMessage) bool {
+		return newMsg.Message.State == fftypes.MessageStateAwaitingApproval
+	})).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+	mam.On("ApplyPolicy", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		args[1].(*fftypes.Message).State = fftypes.MessageStateAwaitingApproval
+	}).Return()
+
+	msg, err := bm.BroadcastMessage(ctx, "ns1", &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Tag: "payments",
+			},
+		},
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateAwaitingApproval, msg.State)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mam.AssertExpectations(t)
+}
+
+func TestBroadcastMessageSharedStorageNotPermitted(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "residency": fftypes.JSONObject{"sharedStorage": []interface{}{"ipfs_eu"}}},
+	})
+	rp, err := residency.NewPolicy(context.Background())
+	assert.NoError(t, err)
+	bm.residency = rp
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	_, err = bm.BroadcastMessage(ctx, "ns1", &fftypes.MessageInOut{
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+	}, false)
+	assert.Regexp(t, "FF10410.*ut_sharedstorage", err)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestRequestReplyMissingTag(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	_, err := bm.RequestReply(context.Background(), "ns1", &fftypes.MessageInOut{}, 0)
+	assert.Regexp(t, "FF10261", err)
+}
+
+func TestRequestReplyInvalidCID(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+
+	_, err := bm.RequestReply(context.Background(), "ns1", &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Tag: "mytag",
+				CID: fftypes.NewUUID(),
+			},
+		},
+	}, 0)
+	assert.Regexp(t, "FF10262", err)
+}
+
+func TestRequestReplySuccess(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	msa := bm.syncasync.(*syncasyncmocks.Bridge)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", ctx, mock.Anything, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	reply := &fftypes.MessageInOut{}
+	msa.On("WaitForReply", ctx, "ns1", mock.Anything, time.Duration(0), mock.Anything).
+		Run(func(args mock.Arguments) {
+			send := args[4].(syncasync.RequestSender)
+			send(ctx)
+		}).
+		Return(reply, nil)
+
+	res, err := bm.RequestReply(ctx, "ns1", &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Tag: "mytag",
+				SignerRef: fftypes.SignerRef{
+					Author: "did:firefly:org/abcd",
+					Key:    "0x12345",
+				},
+			},
+		},
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+	}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, reply, res)
+
+	msa.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
 func TestBroadcastMessageWaitConfirmOk(t *testing.T) {
 	bm, cancel := newTestBroadcast(t)
 	defer cancel()
@@ -213,3 +408,140 @@ func TestBroadcastPrepare(t *testing.T) {
 
 	mdm.AssertExpectations(t)
 }
+
+func TestBroadcastValidateMessageOk(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ApplyMessageTemplate", ctx, mock.Anything).Return(nil)
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	report := bm.ValidateMessage(ctx, "ns1", &fftypes.MessageInOut{
+		Template: "template1",
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+	})
+	assert.True(t, report.Valid)
+	assert.Equal(t, fftypes.MessageTypeBroadcast, report.Type)
+	assert.Equal(t, bm.maxBatchPayloadLength, report.MaxSize)
+	assert.Positive(t, report.Size)
+	checks := make([]fftypes.MessageValidationCheck, len(report.Checks))
+	for i, c := range report.Checks {
+		checks[i] = c.Check
+	}
+	assert.Equal(t, []fftypes.MessageValidationCheck{
+		fftypes.MessageValidationCheckEndorsement,
+		fftypes.MessageValidationCheckTemplate,
+		fftypes.MessageValidationCheckIdentity,
+		fftypes.MessageValidationCheckData,
+		fftypes.MessageValidationCheckSize,
+		fftypes.MessageValidationCheckResidency,
+	}, checks)
+
+	mdm.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestBroadcastValidateMessageIdentityClaim(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+
+	report := bm.ValidateMessage(ctx, fftypes.SystemNamespace, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Type: fftypes.MessageTypeDefinition,
+				Tag:  fftypes.SystemTagIdentityClaim,
+			},
+		},
+	})
+	assert.Len(t, report.Checks, 3)
+	assert.Equal(t, fftypes.MessageValidationCheckData, report.Checks[0].Check)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestBroadcastValidateMessageTemplateFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ApplyMessageTemplate", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	report := bm.ValidateMessage(ctx, "ns1", &fftypes.MessageInOut{
+		Template: "template1",
+	})
+	assert.False(t, report.Valid)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, fftypes.MessageValidationCheckTemplate, report.Checks[1].Check)
+	assert.Equal(t, "pop", report.Checks[1].Error)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestBroadcastValidateMessageAllFail(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := &identitymanagermocks.Manager{}
+	bm.identity = mim
+
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "residency": fftypes.JSONObject{"sharedStorage": []interface{}{"ipfs_eu"}}},
+	})
+	rp, err := residency.NewPolicy(context.Background())
+	assert.NoError(t, err)
+	bm.residency = rp
+
+	ctx := context.Background()
+	mim.On("VerifyNodeOwnerEndorsed", ctx).Return(fmt.Errorf("pop1"))
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(fmt.Errorf("pop2"))
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(fmt.Errorf("pop3"))
+
+	report := bm.ValidateMessage(ctx, "ns1", &fftypes.MessageInOut{})
+	assert.False(t, report.Valid)
+	assert.Len(t, report.Checks, 4)
+	for _, c := range report.Checks {
+		assert.False(t, c.Valid)
+	}
+	assert.Regexp(t, "FF10206.*pop2", report.Checks[1].Error)
+	assert.Regexp(t, "FF10410", report.Checks[3].Error)
+
+	mdm.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestBroadcastValidateMessageTooLarge(t *testing.T) {
+	bm, cancel := newTestBroadcast(t)
+	bm.maxBatchPayloadLength = 1000000
+	defer cancel()
+	mdm := bm.data.(*datamocks.Manager)
+	mim := bm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Run(
+		func(args mock.Arguments) {
+			newMsg := args[1].(*data.NewMessage)
+			newMsg.Message.Data = fftypes.DataRefs{
+				{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32(), ValueSize: 1000001},
+			}
+		}).
+		Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	report := bm.ValidateMessage(ctx, "ns1", &fftypes.MessageInOut{})
+	assert.False(t, report.Valid)
+	assert.Equal(t, fftypes.MessageValidationCheckSize, report.Checks[3].Check)
+	assert.Regexp(t, "FF10327", report.Checks[3].Error)
+
+	mdm.AssertExpectations(t)
+}
@@ -0,0 +1,155 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/broadcast"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/sysmessaging"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Manager sends a broadcast, and a private message to the org that owns this node, on an interval.
+// Each check passes if the message is confirmed within the SLA, and the result is recorded in the
+// metrics and as an event, so that operators can continuously validate the network end-to-end.
+type Manager interface {
+	WaitStop()
+}
+
+type canaryManager struct {
+	ctx       context.Context
+	database  database.Plugin
+	identity  identity.Manager
+	broadcast broadcast.Manager
+	messaging privatemessaging.Manager
+	metrics   metrics.Manager
+	namespace string
+	interval  time.Duration
+	sla       time.Duration
+	done      chan struct{}
+}
+
+func NewCanaryManager(ctx context.Context, di database.Plugin, im identity.Manager, bm broadcast.Manager, pm privatemessaging.Manager, mm metrics.Manager) (Manager, error) {
+	if di == nil || im == nil || bm == nil || pm == nil || mm == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	cm := &canaryManager{
+		ctx:       log.WithLogger(ctx, log.L(ctx).WithField("role", "canary")),
+		database:  di,
+		identity:  im,
+		broadcast: bm,
+		messaging: pm,
+		metrics:   mm,
+		namespace: config.GetString(config.CanaryNamespace),
+		interval:  config.GetDuration(config.CanaryInterval),
+		sla:       config.GetDuration(config.CanarySLA),
+		done:      make(chan struct{}),
+	}
+	if cm.namespace == "" {
+		cm.namespace = config.GetString(config.NamespacesDefault)
+	}
+	if config.GetBool(config.CanaryEnabled) {
+		log.L(ctx).Infof("Canary enabled in namespace '%s' every %s, with SLA %s", cm.namespace, cm.interval, cm.sla)
+		go cm.canaryLoop()
+	} else {
+		close(cm.done)
+	}
+	return cm, nil
+}
+
+func (cm *canaryManager) WaitStop() {
+	<-cm.done
+}
+
+func (cm *canaryManager) canaryLoop() {
+	defer close(cm.done)
+	ticker := time.NewTicker(cm.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cm.ctx.Done():
+			log.L(cm.ctx).Debugf("Canary loop exiting")
+			return
+		case <-ticker.C:
+		}
+		cm.runCheck(fftypes.MessageTypeBroadcast, cm.newBroadcast)
+		cm.runCheck(fftypes.MessageTypePrivate, cm.newPrivate)
+	}
+}
+
+func (cm *canaryManager) newBroadcast(ctx context.Context, in *fftypes.MessageInOut) (sysmessaging.MessageSender, error) {
+	return cm.broadcast.NewBroadcast(cm.namespace, in), nil
+}
+
+func (cm *canaryManager) newPrivate(ctx context.Context, in *fftypes.MessageInOut) (sysmessaging.MessageSender, error) {
+	localOrg, err := cm.identity.GetNodeOwnerOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+	in.Group = &fftypes.InputGroup{
+		Members: []fftypes.MemberInput{
+			{Identity: localOrg.DID},
+		},
+	}
+	return cm.messaging.NewMessage(cm.namespace, in), nil
+}
+
+// runCheck sends a single canary message and waits for it to be confirmed. A message that cannot be
+// sent, that is rejected, or that is not confirmed before the SLA expires, fails the check.
+func (cm *canaryManager) runCheck(msgType fftypes.MessageType, newSender func(context.Context, *fftypes.MessageInOut) (sysmessaging.MessageSender, error)) {
+	ctx, cancel := context.WithTimeout(cm.ctx, cm.sla)
+	defer cancel()
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Tag:    fftypes.SystemTagCanary,
+				Topics: fftypes.FFStringArray{fftypes.SystemTopicCanary},
+			},
+		},
+	}
+	start := time.Now()
+	sender, err := newSender(ctx, in)
+	if err == nil {
+		err = sender.SendAndWait(ctx)
+	}
+	elapsed := time.Since(start)
+
+	eventType := fftypes.EventTypeCanaryPassed
+	if err != nil {
+		log.L(cm.ctx).Errorf("Canary %s message '%s' failed after %s: %s", msgType, in.Header.ID, elapsed, err)
+		eventType = fftypes.EventTypeCanaryFailed
+	} else {
+		log.L(cm.ctx).Infof("Canary %s message '%s' confirmed after %s", msgType, in.Header.ID, elapsed)
+	}
+	if cm.metrics.IsMetricsEnabled() {
+		cm.metrics.CanaryResult(msgType, err == nil, elapsed)
+	}
+	event := fftypes.NewEvent(eventType, cm.namespace, in.Header.ID, nil, fftypes.SystemTopicCanary)
+	if err := cm.database.InsertEvent(cm.ctx, event); err != nil {
+		log.L(cm.ctx).Errorf("Failed to record result of canary %s message '%s': %s", msgType, in.Header.ID, err)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/mocks/sysmessagingmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testMocks struct {
+	mdi *databasemocks.Plugin
+	mim *identitymanagermocks.Manager
+	mbm *broadcastmocks.Manager
+	mpm *privatemessagingmocks.Manager
+	mmm *metricsmocks.Manager
+}
+
+func newTestCanary(t *testing.T) (*canaryManager, *testMocks, func()) {
+	config.Reset()
+	tm := &testMocks{
+		mdi: &databasemocks.Plugin{},
+		mim: &identitymanagermocks.Manager{},
+		mbm: &broadcastmocks.Manager{},
+		mpm: &privatemessagingmocks.Manager{},
+		mmm: &metricsmocks.Manager{},
+	}
+	cm, err := NewCanaryManager(context.Background(), tm.mdi, tm.mim, tm.mbm, tm.mpm, tm.mmm)
+	assert.NoError(t, err)
+	return cm.(*canaryManager), tm, func() {
+		tm.mdi.AssertExpectations(t)
+		tm.mim.AssertExpectations(t)
+		tm.mbm.AssertExpectations(t)
+		tm.mpm.AssertExpectations(t)
+		tm.mmm.AssertExpectations(t)
+	}
+}
+
+func TestNewCanaryManagerMissingDeps(t *testing.T) {
+	_, err := NewCanaryManager(context.Background(), nil, nil, nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestCanaryDisabled(t *testing.T) {
+	cm, _, done := newTestCanary(t)
+	defer done()
+	assert.Equal(t, "default", cm.namespace)
+	cm.WaitStop()
+}
+
+func TestCanaryLoop(t *testing.T) {
+	config.Reset()
+	config.Set(config.CanaryEnabled, true)
+	config.Set(config.CanaryInterval, "1ms")
+	config.Set(config.CanaryNamespace, "ns1")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mdi := &databasemocks.Plugin{}
+	mim := &identitymanagermocks.Manager{}
+	mbm := &broadcastmocks.Manager{}
+	mpm := &privatemessagingmocks.Manager{}
+	mmm := &metricsmocks.Manager{}
+	mms := &sysmessagingmocks.MessageSender{}
+
+	mbm.On("NewBroadcast", "ns1", mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		in.Header.ID = fftypes.NewUUID()
+		return in.Header.Tag == fftypes.SystemTagCanary && in.Header.Topics[0] == fftypes.SystemTopicCanary
+	})).Return(mms)
+	mim.On("GetNodeOwnerOrg", mock.Anything).Return(&fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{DID: "did:firefly:org/org1"},
+	}, nil)
+	mpm.On("NewMessage", "ns1", mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		in.Header.ID = fftypes.NewUUID()
+		return in.Group.Members[0].Identity == "did:firefly:org/org1"
+	})).Return(mms)
+	mms.On("SendAndWait", mock.Anything).Return(nil)
+	mmm.On("IsMetricsEnabled").Return(true)
+	mmm.On("CanaryResult", fftypes.MessageTypeBroadcast, true, mock.Anything).Return()
+	mmm.On("CanaryResult", fftypes.MessageTypePrivate, true, mock.Anything).Return()
+	insertCount := 0
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeCanaryPassed && e.Namespace == "ns1" && e.Reference != nil
+	})).Return(nil).Run(func(args mock.Arguments) {
+		insertCount++
+		if insertCount == 2 {
+			cancel()
+		}
+	})
+
+	cm, err := NewCanaryManager(ctx, mdi, mim, mbm, mpm, mmm)
+	assert.NoError(t, err)
+	cm.WaitStop()
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+	mpm.AssertExpectations(t)
+	mms.AssertExpectations(t)
+	mmm.AssertExpectations(t)
+}
+
+func TestRunCheckPrivateNoLocalOrg(t *testing.T) {
+	cm, tm, done := newTestCanary(t)
+	defer done()
+
+	tm.mim.On("GetNodeOwnerOrg", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	tm.mmm.On("IsMetricsEnabled").Return(false)
+	tm.mdi.On("InsertEvent", cm.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeCanaryFailed
+	})).Return(nil)
+
+	cm.runCheck(fftypes.MessageTypePrivate, cm.newPrivate)
+}
+
+func TestRunCheckSendFailInsertEventFail(t *testing.T) {
+	cm, tm, done := newTestCanary(t)
+	defer done()
+
+	mms := &sysmessagingmocks.MessageSender{}
+	tm.mbm.On("NewBroadcast", "default", mock.Anything).Return(mms)
+	mms.On("SendAndWait", mock.Anything).Return(fmt.Errorf("FF10260 timeout"))
+	tm.mmm.On("IsMetricsEnabled").Return(true)
+	tm.mmm.On("CanaryResult", fftypes.MessageTypeBroadcast, false, mock.Anything).Return()
+	tm.mdi.On("InsertEvent", cm.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeCanaryFailed
+	})).Return(fmt.Errorf("pop"))
+
+	cm.runCheck(fftypes.MessageTypeBroadcast, cm.newBroadcast)
+	mms.AssertExpectations(t)
+}
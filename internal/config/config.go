@@ -52,22 +52,44 @@ var (
 	APIRequestTimeout = rootKey("api.requestTimeout")
 	// APIRequestMaxTimeout is the maximum timeout an application can set using a Request-Timeout header
 	APIRequestMaxTimeout = rootKey("api.requestMaxTimeout")
+	// APIRoutes is a list of per-route limits, each with the name of a route and its own timeout and maximum number of concurrent requests
+	APIRoutes = rootKey("api.routes")
 	// APIShutdownTimeout is the amount of time to wait for any in-flight requests to finish before killing the HTTP server
 	APIShutdownTimeout = rootKey("api.shutdownTimeout")
+	// ApprovalsPolicies is a list of approval policies, each requiring a number of approvals from a set of approvers before a message tagged with the policy name is sent
+	ApprovalsPolicies = rootKey("approvals.policies")
 	// BatchManagerReadPageSize is the size of each page of messages read from the database into memory when assembling batches
 	BatchManagerReadPageSize = rootKey("batch.manager.readPageSize")
 	// BatchManagerReadPollTimeout is how long without any notifications of new messages to wait, before doing a page query
 	BatchManagerReadPollTimeout = rootKey("batch.manager.pollTimeout")
 	// BatchManagerMinimumPollTime is the minimum duration between polls, to avoid continual polling at high throughput
 	BatchManagerMinimumPollTime = rootKey("batch.manager.minimumPollTime")
+	// BatchLifecycleEvents emits an event for each stage a batch passes through, from assembly to confirmation
+	BatchLifecycleEvents = rootKey("batch.lifecycleEvents")
 	// BatchRetryFactor is the retry backoff factor for database operations performed by the batch manager
 	BatchRetryFactor = rootKey("batch.retry.factor")
 	// BatchRetryInitDelay is the retry initial delay for database operations
 	BatchRetryInitDelay = rootKey("batch.retry.initDelay")
 	// BatchRetryMaxDelay is the maximum delay between retry attempts
 	BatchRetryMaxDelay = rootKey("batch.retry.maxDelay")
+	// BatchPinRollupEnabled accumulates batch pins, and submits them to the blockchain as a single transaction pinning their Merkle root
+	BatchPinRollupEnabled = rootKey("batchpin.rollup.enabled")
+	// BatchPinRollupInterval is how often to submit the accumulated batch pins
+	BatchPinRollupInterval = rootKey("batchpin.rollup.interval")
+	// BatchPinRollupMaxPins is the maximum number of batch pins in a single rollup, which is submitted immediately once it is full
+	BatchPinRollupMaxPins = rootKey("batchpin.rollup.maxPins")
+	// BatchPinDeferredInterval is how often the pins of private batches are anchored, in namespaces configured for deferred pinning
+	BatchPinDeferredInterval = rootKey("batchpin.deferred.interval")
 	// BlockchainType is the name of the blockchain interface plugin being used by this firefly node
 	BlockchainType = rootKey("blockchain.type")
+	// BridgesRules is a list of rules, each copying the confirmed messages selected by a filter from one namespace to another
+	BridgesRules = rootKey("bridges.rules")
+	// BroadcastAnonymousAuthor is the DID of the relay identity that anonymous broadcasts are sent as
+	BroadcastAnonymousAuthor = rootKey("broadcast.anonymous.author")
+	// BroadcastAnonymousKey is the relay key, registered to the relay identity, that anonymous broadcasts are pinned with
+	BroadcastAnonymousKey = rootKey("broadcast.anonymous.key")
+	// BroadcastAnonymousIdentityKey is a hex encoded AES-256 key, shared privately between the authorized members, that encrypts the real author of anonymous broadcasts
+	BroadcastAnonymousIdentityKey = rootKey("broadcast.anonymous.identityKey")
 	// BroadcastBatchAgentTimeout how long to keep around a batching agent for a sending identity before disposal
 	BroadcastBatchAgentTimeout = rootKey("broadcast.batch.agentTimeout")
 	// BroadcastBatchSize is the maximum number of messages that can be packed into a batch
@@ -76,6 +98,42 @@ var (
 	BroadcastBatchPayloadLimit = rootKey("broadcast.batch.payloadLimit")
 	// BroadcastBatchTimeout is the timeout to wait for a batch to fill, before sending
 	BroadcastBatchTimeout = rootKey("broadcast.batch.timeout")
+	// CanaryEnabled periodically sends a broadcast and a private message to the local org, to check confirmation end-to-end
+	CanaryEnabled = rootKey("canary.enabled")
+	// CanaryInterval is how often the canary messages are sent
+	CanaryInterval = rootKey("canary.interval")
+	// CanarySLA is how long each canary message has to be confirmed, before the check is failed
+	CanarySLA = rootKey("canary.sla")
+	// CanaryNamespace is the namespace the canary messages are sent in, which is the default namespace if not set
+	CanaryNamespace = rootKey("canary.namespace")
+	// EmailRepliesTag is the tag set on the messages ingested from replies to the emails of the email event transport
+	EmailRepliesTag = rootKey("email.replies.tag")
+	// EmailRepliesRequireSignature rejects replies that are not signed with S/MIME. Signed replies are always verified.
+	EmailRepliesRequireSignature = rootKey("email.replies.requireSignature")
+	// EmailRepliesCAFile is a PEM file of the CA certificates trusted to issue S/MIME certificates, instead of the system CAs
+	EmailRepliesCAFile = rootKey("email.replies.caFile")
+	// EmailRepliesIMAPURL is an imaps:// (or imap://) URL of a mailbox to poll for replies, such as imaps://mail.example.com/INBOX
+	EmailRepliesIMAPURL = rootKey("email.replies.imap.url")
+	// EmailRepliesIMAPUsername is the username to log in to the IMAP server with
+	EmailRepliesIMAPUsername = rootKey("email.replies.imap.username")
+	// EmailRepliesIMAPPassword is the password to log in to the IMAP server with
+	EmailRepliesIMAPPassword = rootKey("email.replies.imap.password")
+	// EmailRepliesIMAPPollInterval is how often the IMAP mailbox is checked for unseen replies
+	EmailRepliesIMAPPollInterval = rootKey("email.replies.imap.pollInterval")
+	// EmailRepliesIMAPTimeout is the timeout for each command sent to the IMAP server
+	EmailRepliesIMAPTimeout = rootKey("email.replies.imap.timeout")
+	// GatewayNetwork is the name by which this network is known in the provenance of the messages relayed to other networks
+	GatewayNetwork = rootKey("gateway.network")
+	// GatewayRelays is a list of relays, each copying the confirmed messages selected by a filter to a namespace in another FireFly network
+	GatewayRelays = rootKey("gateway.relays")
+	// IngestionDirectories is a list of directories to watch for arriving files, each of which is sent as blob data plus a message
+	IngestionDirectories = rootKey("ingestion.directories")
+	// IngestionPollInterval is how often the ingestion directories are scanned for new files
+	IngestionPollInterval = rootKey("ingestion.pollInterval")
+	// IngestionSettleTime is how long a file must be unmodified before it is ingested, so partially written files are not picked up
+	IngestionSettleTime = rootKey("ingestion.settleTime")
+	// InboundEndpoints is a list of custom inbound endpoints, each mapping a plain JSON body onto a message
+	InboundEndpoints = rootKey("inbound.endpoints")
 	// PrivateMessagingBatchAgentTimeout how long to keep around a batching agent for a sending identity before disposal
 	PrivateMessagingBatchAgentTimeout = rootKey("privatemessaging.batch.agentTimeout")
 	// PrivateMessagingBatchSize is the maximum size of a batch for broadcast messages
@@ -84,15 +142,35 @@ var (
 	PrivateMessagingBatchPayloadLimit = rootKey("privatemessaging.batch.payloadLimit")
 	// PrivateMessagingBatchTimeout is the timeout to wait for a batch to fill, before sending
 	PrivateMessagingBatchTimeout = rootKey("privatemessaging.batch.timeout")
+	// LocalMessagingBatchAgentTimeout how long to keep around a batching agent for a sending identity before disposal
+	LocalMessagingBatchAgentTimeout = rootKey("localmessaging.batch.agentTimeout")
+	// LocalMessagingBatchSize is the maximum number of local messages that are confirmed together in a batch
+	LocalMessagingBatchSize = rootKey("localmessaging.batch.size")
+	// LocalMessagingBatchPayloadLimit is the maximum payload size of a batch of local messages
+	LocalMessagingBatchPayloadLimit = rootKey("localmessaging.batch.payloadLimit")
+	// LocalMessagingBatchTimeout is the timeout to wait for a batch to fill, before confirming it
+	LocalMessagingBatchTimeout = rootKey("localmessaging.batch.timeout")
 	// PrivateMessagingOpCorrelationRetries how many times to correlate an event for an operation (such as tx submission) back to an operation.
 	// Needed because the operation update might come back before we are finished persisting the ID of the request
 	PrivateMessagingOpCorrelationRetries = rootKey("privatemessaging.opCorrelationRetries")
+	// PrivateMessagingRelayFanout the number of nodes each node relays a private batch to, when relaying is used for a group
+	PrivateMessagingRelayFanout = rootKey("privatemessaging.relay.fanout")
+	// PrivateMessagingRelayMinGroupSize the minimum number of remote nodes in a group for pinned batches to be relayed via a tree of the member nodes, or zero to disable relaying
+	PrivateMessagingRelayMinGroupSize = rootKey("privatemessaging.relay.minGroupSize")
 	// PrivateMessagingRetryFactor the backoff factor to use for retry of database operations
 	PrivateMessagingRetryFactor = rootKey("privatemessaging.retry.factor")
 	// PrivateMessagingRetryInitDelay the initial delay to use for retry of data base operations
 	PrivateMessagingRetryInitDelay = rootKey("privatemessaging.retry.initDelay")
 	// PrivateMessagingRetryMaxDelay the maximum delay to use for retry of data base operations
 	PrivateMessagingRetryMaxDelay = rootKey("privatemessaging.retry.maxDelay")
+	// PrivateMessagingTransferParallelism the maximum number of nodes a private batch is sent to in parallel
+	PrivateMessagingTransferParallelism = rootKey("privatemessaging.transfer.parallelism")
+	// PrivateMessagingTransferPeerConcurrency the maximum number of batches being sent to any single node at the same time
+	PrivateMessagingTransferPeerConcurrency = rootKey("privatemessaging.transfer.peerConcurrency")
+	// PrivateMessagingTransferPeerBandwidth the maximum bytes per second initiated for transfer to any single node, or zero for no limit
+	PrivateMessagingTransferPeerBandwidth = rootKey("privatemessaging.transfer.peerBandwidth")
+	// ConfigStrict causes startup to fail if the configuration contains unknown keys, invalid values, or incomplete plugin sections (otherwise these are logged as warnings)
+	ConfigStrict = rootKey("config.strict")
 	// CorsAllowCredentials CORS setting to control whether a browser allows credentials to be sent to this API
 	CorsAllowCredentials = rootKey("cors.credentials")
 	// CorsAllowedHeaders CORS setting to control the allowed headers
@@ -105,10 +183,18 @@ var (
 	CorsDebug = rootKey("cors.debug")
 	// CorsEnabled is whether cors is enabled
 	CorsEnabled = rootKey("cors.enabled")
+	// CorsExposedHeaders CORS setting to control the response headers that are made available to browser scripts
+	CorsExposedHeaders = rootKey("cors.exposedHeaders")
 	// CorsMaxAge is the maximum age a browser should rely on CORS checks
 	CorsMaxAge = rootKey("cors.maxAge")
+	// CorsOptionsPassthrough whether OPTIONS requests are passed through to the router after CORS processing, rather than being answered directly
+	CorsOptionsPassthrough = rootKey("cors.optionsPassthrough")
 	// DataexchangeType is the name of the data exchange plugin being used by this firefly node
 	DataexchangeType = rootKey("dataexchange.type")
+	// DataexchangeStreamingChunkSize is the size of each chunk, when streaming blobs to and from a data exchange plugin that supports it
+	DataexchangeStreamingChunkSize = rootKey("dataexchange.streaming.chunkSize")
+	// DataexchangeStreamingResumeAttempts is the number of times a failed blob stream is resumed from the last acknowledged chunk, before the transfer fails
+	DataexchangeStreamingResumeAttempts = rootKey("dataexchange.streaming.resumeAttempts")
 	// DatabaseType the type of the database interface plugin to use
 	DatabaseType = rootKey("database.type")
 	// TokensList is the root key containing a list of supported token connectors
@@ -142,32 +228,58 @@ var (
 	EventDispatcherBufferLength = rootKey("event.dispatcher.bufferLength")
 	// EventDispatcherBatchTimeout a short time to wait for new events to arrive before re-polling for new events
 	EventDispatcherBatchTimeout = rootKey("event.dispatcher.batchTimeout")
+	// EventDispatcherOffsetCommitCount the number of acknowledged events after which a durable subscription's offset is written to the database (up to count-1 events may be redelivered after a crash)
+	EventDispatcherOffsetCommitCount = rootKey("event.dispatcher.offsetCommit.count")
+	// EventDispatcherOffsetCommitInterval the maximum time an acknowledged event waits before a durable subscription's offset is written to the database, when the count has not been reached (0 to only commit on count)
+	EventDispatcherOffsetCommitInterval = rootKey("event.dispatcher.offsetCommit.interval")
 	// EventDispatcherRetryFactor the backoff factor to use for retry of database operations
 	EventDispatcherRetryFactor = rootKey("event.dispatcher.retry.factor")
 	// EventDispatcherRetryInitDelay he initial delay to use for retry of data base operations
 	EventDispatcherRetryInitDelay = rootKey("event.dispatcher.retry.initDelay")
 	// EventDispatcherRetryMaxDelay he maximum delay to use for retry of data base operations
 	EventDispatcherRetryMaxDelay = rootKey("event.dispatcher.retry.maxDelay")
+	// EventsWorkerPoolSize the number of workers shared by all subscriptions to deliver events. When 0, each subscription has its own delivery goroutine
+	EventsWorkerPoolSize = rootKey("events.workerPool.size")
+	// EventCompactionEnabled whether older events of the compaction types are periodically compacted, to retain only the latest event for each reference
+	EventCompactionEnabled = rootKey("event.compaction.enabled")
+	// EventCompactionTypes the event types that are compacted, which should be high-churn types where only the latest state of the reference matters
+	EventCompactionTypes = rootKey("event.compaction.types")
+	// EventCompactionHorizon the age beyond which events are compacted. Events newer than this are always retained
+	EventCompactionHorizon = rootKey("event.compaction.horizon")
+	// EventCompactionInterval how often compaction runs
+	EventCompactionInterval = rootKey("event.compaction.interval")
+	// EventCompactionBatchSize the number of events read from the database in each page during compaction
+	EventCompactionBatchSize = rootKey("event.compaction.batchSize")
 	// EventDBEventsBufferSize the size of the buffer of change events
 	EventDBEventsBufferSize = rootKey("event.dbevents.bufferSize")
+	// EventEnrichmentPlugins the paths of Go plugins that compute additional fields for each event, before it is delivered to an application
+	EventEnrichmentPlugins = rootKey("event.enrichment.plugins")
 	// EventListenerTopicCacheSize cache size for blockchain listeners addresses
 	EventListenerTopicCacheSize = rootKey("event.listenerToipc.cache.size")
 	// EventListenerTopicCacheTTL cache time-to-live for private group addresses
 	EventListenerTopicCacheTTL = rootKey("event.listenerToipc.cache.ttl")
+	// FaultsRules is a list of rules that delay or fail calls to the database, data exchange and blockchain plugins, in builds with the "faults" tag
+	FaultsRules = rootKey("faults.rules")
 	// GroupCacheSize cache size for private group addresses
 	GroupCacheSize = rootKey("group.cache.size")
 	// GroupCacheTTL cache time-to-live for private group addresses
 	GroupCacheTTL = rootKey("group.cache.ttl")
 	// AdminEnabled determines whether the admin interface will be enabled or not
 	AdminEnabled = rootKey("admin.enabled")
+	// AdminExclusive restricts administrative routes (such as subscription management) to the admin listener, removing them from the main API
+	AdminExclusive = rootKey("admin.exclusive")
 	// AdminPreinit waits for at least one ConfigREcord to be posted to the server before it starts (the database must be available on startup)
 	AdminPreinit = rootKey("admin.preinit")
+	// AdminDebugEnabled exposes pprof, goroutine dumps and internal queue depths under /admin/debug, when the admin listener requires authentication
+	AdminDebugEnabled = rootKey("admin.debug.enabled")
 	// IdentityType the type of the identity plugin in use
 	IdentityType = rootKey("identity.type")
 	// IdentityManagerCacheTTL the identity manager cache time to live
 	IdentityManagerCacheTTL = rootKey("identity.manager.cache.ttl")
 	// IdentityManagerCacheLimit the identity manager cache limit in count of items
 	IdentityManagerCacheLimit = rootKey("identity.manager.cache.limit")
+	// IdentityEndorsementQuorum is how many existing members must endorse a new member org, before its messages outside of the system namespace are accepted. Must be the same on every member of the network
+	IdentityEndorsementQuorum = rootKey("identity.endorsement.quorum")
 	// Lang is the language to use for translation
 	Lang = rootKey("lang")
 	// LogForceColor forces color to be enabled, even if we do not detect a TTY
@@ -194,6 +306,8 @@ var (
 	MessageCacheSize = rootKey("message.cache.size")
 	// MessageCacheTTL
 	MessageCacheTTL = rootKey("message.cache.ttl")
+	// MessageConfirmTimeout is the default time to wait for a reply to a request/reply message, when the request does not specify one
+	MessageConfirmTimeout = rootKey("message.confirm.timeout")
 	// MessageWriterCount
 	MessageWriterCount = rootKey("message.writer.count")
 	// MessageWriterBatchTimeout
@@ -212,6 +326,18 @@ var (
 	NodeName = rootKey("node.name")
 	// NodeDescription is a description for the node
 	NodeDescription = rootKey("node.description")
+	// NodePrivateTransactionKey is the key of this node in the private transaction manager of the blockchain (such as Tessera), which is published in the node profile
+	NodePrivateTransactionKey = rootKey("node.privateTransactionKey")
+	// NodeRegion is the region in which this node stores and processes data, which is published in the node profile for data residency rules
+	NodeRegion = rootKey("node.region")
+	// NotificationsChannels is a list of named channels (email, slack or pagerduty) that notification rules send to
+	NotificationsChannels = rootKey("notifications.channels")
+	// NotificationsRules is a list of rules, each sending notifications to a set of channels while a condition is met in a namespace
+	NotificationsRules = rootKey("notifications.rules")
+	// NotificationsInterval is how often the notification rules are evaluated
+	NotificationsInterval = rootKey("notifications.interval")
+	// NotificationsRepeatInterval is the minimum time before a notification is repeated, for a condition that is still met
+	NotificationsRepeatInterval = rootKey("notifications.repeatInterval")
 	// OrgName is the short name o the org
 	OrgName = rootKey("org.name")
 	// OrgIdentityDeprecated deprecated synonym to org.key
@@ -220,12 +346,58 @@ var (
 	OrgKey = rootKey("org.key")
 	// OrgDescription is a description for the org
 	OrgDescription = rootKey("org.description")
+	// OrgInvitationExpiry is how long an invitation for a new member to join the network is valid, if no expiry is specified when it is issued
+	OrgInvitationExpiry = rootKey("org.invitationExpiry")
 	// OrchestratorStartupAttempts is how many time to attempt to connect to core infrastructure on startup
 	OrchestratorStartupAttempts = rootKey("orchestrator.startupAttempts")
+	// ReplayLogEnabled records every inbound batch pin, data exchange message and blob, and shared storage payload, to an append-only replay log
+	ReplayLogEnabled = rootKey("replay.log.enabled")
+	// ReplayLogPath is the file the replay log is appended to
+	ReplayLogPath = rootKey("replay.log.path")
+	// ReplayDebugSource if set, the node runs in debug mode, processing only the inbound events from this replay log (live inbound events are dropped)
+	ReplayDebugSource = rootKey("replay.debug.source")
+	// ReplayDebugRealtime preserves the intervals between the events in the replay log when replaying, rather than replaying as quickly as possible
+	ReplayDebugRealtime = rootKey("replay.debug.realtime")
+	// ReportsEnabled generates a report for each namespace at the end of every period
+	ReportsEnabled = rootKey("reports.enabled")
+	// ReportsPeriod is the period covered by each report - daily, weekly or monthly
+	ReportsPeriod = rootKey("reports.period")
+	// ReportsCheckInterval is how often to check for a completed period, for which a report has not yet been generated
+	ReportsCheckInterval = rootKey("reports.checkInterval")
+	// ReportsSinkDirectory if set, each report is also written as a file into this directory
+	ReportsSinkDirectory = rootKey("reports.sink.directory")
+	// ReportsSinkFormats are the formats in which each report is written to the sink directory - json and/or csv
+	ReportsSinkFormats = rootKey("reports.sink.formats")
+	// RetryJitter is the fraction of each retry delay that is randomized, so retry loops that failed together do not all retry together
+	RetryJitter = rootKey("retry.jitter")
+	// RetryMaxElapsed is the total time a retry loop keeps retrying database operations, before returning the last error (0 to retry indefinitely)
+	RetryMaxElapsed = rootKey("retry.maxElapsed")
+	// RetryBreakerEnabled enables a circuit breaker shared by all the retry loops for database operations
+	RetryBreakerEnabled = rootKey("retry.breaker.enabled")
+	// RetryBreakerFailureThreshold is the number of consecutive failed attempts that opens the circuit breaker
+	RetryBreakerFailureThreshold = rootKey("retry.breaker.failureThreshold")
+	// RetryBreakerResetTimeout is how long the circuit breaker stays open, before a single trial attempt is allowed
+	RetryBreakerResetTimeout = rootKey("retry.breaker.resetTimeout")
+	// ScanningType specifies which blob scanning plugin to use to check blobs received from the network for malware (empty to disable)
+	ScanningType = rootKey("scanning.type")
+	// SchemaDriftEnabled enables inference of the JSON schema of the payloads on each topic, and emits an event when a message drifts from it
+	SchemaDriftEnabled = rootKey("schemaDrift.enabled")
+	// SchemaDriftInterval is how often newly confirmed messages are analyzed
+	SchemaDriftInterval = rootKey("schemaDrift.interval")
+	// SchemaDriftBatchSize is the maximum number of confirmed messages analyzed in each pass
+	SchemaDriftBatchSize = rootKey("schemaDrift.batchSize")
+	// SchemaDriftMinSamples is the number of messages on a topic the schema is learned from, before drift is reported
+	SchemaDriftMinSamples = rootKey("schemaDrift.minSamples")
+	// SchemaDriftMaxTopics is the maximum number of topics a schema is inferred for, across all namespaces
+	SchemaDriftMaxTopics = rootKey("schemaDrift.maxTopics")
+	// SecretsRefreshInterval is how often secrets referenced from secrets providers are re-fetched, restarting the node if they have been rotated (0 to disable)
+	SecretsRefreshInterval = rootKey("secrets.refreshInterval")
 	// SharedStorageType specifies which shared storage interface plugin to use
 	SharedStorageType = rootKey("sharedstorage.type")
 	// PublicStorageType specifies which shared storage interface plugin to use - deprecated in favor of SharedStorageType
 	PublicStorageType = rootKey("publicstorage.type")
+	// StatusSummaryInterval is how often the pre-aggregated status summary of each namespace is refreshed
+	StatusSummaryInterval = rootKey("status.summary.interval")
 	// SubscriptionDefaultsReadAhead default read ahead to enable for subscriptions that do not explicitly configure readahead
 	SubscriptionDefaultsReadAhead = rootKey("subscription.defaults.batchSize")
 	// SubscriptionMax maximum number of pre-defined subscriptions that can exist (note for high fan-out consider connecting a dedicated pub/sub broker to the dispatcher)
@@ -236,6 +408,18 @@ var (
 	SubscriptionsRetryMaxDelay = rootKey("subscription.retry.maxDelay")
 	// SubscriptionsRetryFactor the backoff factor to use for retry of database operations
 	SubscriptionsRetryFactor = rootKey("subscription.retry.factor")
+	// SubscriptionDryRunMaxEvents the maximum number of events a candidate subscription filter can be tested against
+	SubscriptionDryRunMaxEvents = rootKey("subscription.dryRun.maxEvents")
+	// SubscriptionTapMaxEvents the maximum number of events a debug tap on a subscription can trace
+	SubscriptionTapMaxEvents = rootKey("subscription.tap.maxEvents")
+	// SubscriptionTapTimeout the maximum time a debug tap on a subscription stays attached
+	SubscriptionTapTimeout = rootKey("subscription.tap.timeout")
+	// SwapsEscrowKey is the key that holds both legs of a swap in escrow, which defaults to the blockchain key of the node owner org
+	SwapsEscrowKey = rootKey("swaps.escrowKey")
+	// SwapsPollInterval is how often active swaps are checked for deposits, releases, refunds and timeouts
+	SwapsPollInterval = rootKey("swaps.pollInterval")
+	// SwapsDefaultTimeout is how long a swap waits for both legs to be deposited, if no timeout is specified when it is created
+	SwapsDefaultTimeout = rootKey("swaps.defaultTimeout")
 	// TransactionCacheSize
 	TransactionCacheSize = rootKey("transaction.cache.size")
 	// TransactionCacheTTL
@@ -299,6 +483,7 @@ func Reset() {
 	defer keysMutex.Unlock()
 
 	viper.Reset()
+	secretRefs = map[string]interface{}{}
 
 	// Set defaults
 	viper.SetDefault(string(APIDefaultFilterLimit), 25)
@@ -308,27 +493,47 @@ func Reset() {
 	viper.SetDefault(string(APIMaxFilterSkip), 1000) // protects database (skip+limit pagination is not for bulk operations)
 	viper.SetDefault(string(APIRequestTimeout), "120s")
 	viper.SetDefault(string(APIShutdownTimeout), "10s")
+	viper.SetDefault(string(ApprovalsPolicies), fftypes.JSONObjectArray{})
 	viper.SetDefault(string(AssetManagerKeyNormalization), "blockchain_plugin")
 	viper.SetDefault(string(BatchManagerReadPageSize), 100)
 	viper.SetDefault(string(BatchManagerReadPollTimeout), "30s")
 	viper.SetDefault(string(BatchManagerMinimumPollTime), "50ms")
+	viper.SetDefault(string(BatchLifecycleEvents), false)
 	viper.SetDefault(string(BatchRetryFactor), 2.0)
 	viper.SetDefault(string(BatchRetryFactor), 2.0)
 	viper.SetDefault(string(BatchRetryInitDelay), "250ms")
 	viper.SetDefault(string(BatchRetryInitDelay), "250ms")
 	viper.SetDefault(string(BatchRetryMaxDelay), "30s")
 	viper.SetDefault(string(BatchRetryMaxDelay), "30s")
+	viper.SetDefault(string(BatchPinRollupEnabled), false)
+	viper.SetDefault(string(BatchPinRollupInterval), "5s")
+	viper.SetDefault(string(BatchPinRollupMaxPins), 100)
+	viper.SetDefault(string(BatchPinDeferredInterval), "1m")
 	viper.SetDefault(string(BroadcastBatchAgentTimeout), "2m")
 	viper.SetDefault(string(BroadcastBatchSize), 200)
 	viper.SetDefault(string(BroadcastBatchPayloadLimit), "800Kb")
 	viper.SetDefault(string(BroadcastBatchTimeout), "1s")
+	viper.SetDefault(string(CanaryEnabled), false)
+	viper.SetDefault(string(CanaryInterval), "5m")
+	viper.SetDefault(string(CanarySLA), "1m")
+	viper.SetDefault(string(EmailRepliesTag), "email_reply")
+	viper.SetDefault(string(EmailRepliesRequireSignature), true)
+	viper.SetDefault(string(EmailRepliesIMAPPollInterval), "30s")
+	viper.SetDefault(string(EmailRepliesIMAPTimeout), "30s")
+	viper.SetDefault(string(IngestionPollInterval), "5s")
+	viper.SetDefault(string(IngestionSettleTime), "2s")
+	viper.SetDefault(string(ConfigStrict), false)
 	viper.SetDefault(string(CorsAllowCredentials), true)
 	viper.SetDefault(string(CorsAllowedHeaders), []string{"*"})
 	viper.SetDefault(string(CorsAllowedMethods), []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete})
 	viper.SetDefault(string(CorsAllowedOrigins), []string{"*"})
 	viper.SetDefault(string(CorsEnabled), true)
+	viper.SetDefault(string(CorsExposedHeaders), []string{})
 	viper.SetDefault(string(CorsMaxAge), 600)
+	viper.SetDefault(string(CorsOptionsPassthrough), false)
 	viper.SetDefault(string(DataexchangeType), "https")
+	viper.SetDefault(string(DataexchangeStreamingChunkSize), "1Mb")
+	viper.SetDefault(string(DataexchangeStreamingResumeAttempts), 3)
 	viper.SetDefault(string(DebugPort), -1)
 	viper.SetDefault(string(EventAggregatorFirstEvent), fftypes.SubOptsFirstEventOldest)
 	viper.SetDefault(string(EventAggregatorBatchSize), 200)
@@ -338,17 +543,28 @@ func Reset() {
 	viper.SetDefault(string(EventAggregatorRetryInitDelay), "100ms")
 	viper.SetDefault(string(EventAggregatorRetryMaxDelay), "30s")
 	viper.SetDefault(string(EventAggregatorOpCorrelationRetries), 3)
+	viper.SetDefault(string(EventCompactionEnabled), false)
+	viper.SetDefault(string(EventCompactionTypes), []string{})
+	viper.SetDefault(string(EventCompactionHorizon), "168h")
+	viper.SetDefault(string(EventCompactionInterval), "1h")
+	viper.SetDefault(string(EventCompactionBatchSize), 500)
 	viper.SetDefault(string(EventDBEventsBufferSize), 100)
 	viper.SetDefault(string(EventDispatcherBufferLength), 5)
 	viper.SetDefault(string(EventDispatcherBatchTimeout), "250ms")
 	viper.SetDefault(string(EventDispatcherPollTimeout), "30s")
+	viper.SetDefault(string(EventDispatcherOffsetCommitCount), 1)
+	viper.SetDefault(string(EventDispatcherOffsetCommitInterval), "0")
+	viper.SetDefault(string(EventsWorkerPoolSize), 0)
 	viper.SetDefault(string(EventTransportsEnabled), []string{"websockets", "webhooks"})
 	viper.SetDefault(string(EventTransportsDefault), "websockets")
 	viper.SetDefault(string(EventListenerTopicCacheSize), "100Kb")
 	viper.SetDefault(string(EventListenerTopicCacheTTL), "5m")
+	viper.SetDefault(string(FaultsRules), fftypes.JSONObjectArray{})
 	viper.SetDefault(string(GroupCacheSize), "1Mb")
 	viper.SetDefault(string(GroupCacheTTL), "1h")
 	viper.SetDefault(string(AdminEnabled), false)
+	viper.SetDefault(string(AdminExclusive), false)
+	viper.SetDefault(string(AdminDebugEnabled), false)
 	viper.SetDefault(string(IdentityType), "onchain")
 	viper.SetDefault(string(Lang), "en")
 	viper.SetDefault(string(LogLevel), "info")
@@ -359,38 +575,93 @@ func Reset() {
 	viper.SetDefault(string(LogMaxBackups), 2)
 	viper.SetDefault(string(MessageCacheSize), "50Mb")
 	viper.SetDefault(string(MessageCacheTTL), "5m")
+	viper.SetDefault(string(MessageConfirmTimeout), "60s")
 	viper.SetDefault(string(MessageWriterBatchMaxInserts), 200)
 	viper.SetDefault(string(MessageWriterBatchTimeout), "10ms")
 	viper.SetDefault(string(MessageWriterCount), 5)
 	viper.SetDefault(string(NamespacesDefault), "default")
+	viper.SetDefault(string(NotificationsChannels), fftypes.JSONObjectArray{})
+	viper.SetDefault(string(NotificationsRules), fftypes.JSONObjectArray{})
+	viper.SetDefault(string(NotificationsInterval), "30s")
+	viper.SetDefault(string(NotificationsRepeatInterval), "1h")
 	viper.SetDefault(string(NamespacesPredefined), fftypes.JSONObjectArray{{"name": "default", "description": "Default predefined namespace"}})
+	viper.SetDefault(string(OrgInvitationExpiry), "24h")
 	viper.SetDefault(string(OrchestratorStartupAttempts), 5)
+	viper.SetDefault(string(ReplayLogEnabled), false)
+	viper.SetDefault(string(ReplayDebugRealtime), false)
+	viper.SetDefault(string(ReportsEnabled), false)
+	viper.SetDefault(string(ReportsPeriod), "monthly")
+	viper.SetDefault(string(ReportsCheckInterval), "1h")
+	viper.SetDefault(string(ReportsSinkFormats), []string{"json"})
+	viper.SetDefault(string(RetryJitter), 0.2)
+	viper.SetDefault(string(RetryMaxElapsed), 0)
+	viper.SetDefault(string(RetryBreakerEnabled), false)
+	viper.SetDefault(string(RetryBreakerFailureThreshold), 10)
+	viper.SetDefault(string(RetryBreakerResetTimeout), "5s")
+	viper.SetDefault(string(SchemaDriftEnabled), false)
+	viper.SetDefault(string(SchemaDriftInterval), "5s")
+	viper.SetDefault(string(SchemaDriftBatchSize), 50)
+	viper.SetDefault(string(SchemaDriftMinSamples), 10)
+	viper.SetDefault(string(SchemaDriftMaxTopics), 1000)
+	viper.SetDefault(string(SecretsRefreshInterval), "5m")
+	viper.SetDefault(string(PrivateMessagingRelayFanout), 4)
+	viper.SetDefault(string(PrivateMessagingRelayMinGroupSize), 0)
 	viper.SetDefault(string(PrivateMessagingRetryFactor), 2.0)
 	viper.SetDefault(string(PrivateMessagingRetryInitDelay), "100ms")
 	viper.SetDefault(string(PrivateMessagingRetryMaxDelay), "30s")
+	viper.SetDefault(string(PrivateMessagingTransferParallelism), 10)
+	viper.SetDefault(string(PrivateMessagingTransferPeerConcurrency), 2)
+	viper.SetDefault(string(PrivateMessagingTransferPeerBandwidth), "0")
 	viper.SetDefault(string(PrivateMessagingOpCorrelationRetries), 3)
 	viper.SetDefault(string(PrivateMessagingBatchAgentTimeout), "2m")
 	viper.SetDefault(string(PrivateMessagingBatchSize), 200)
 	viper.SetDefault(string(PrivateMessagingBatchTimeout), "1s")
 	viper.SetDefault(string(PrivateMessagingBatchPayloadLimit), "800Kb")
+	viper.SetDefault(string(LocalMessagingBatchAgentTimeout), "2m")
+	viper.SetDefault(string(LocalMessagingBatchSize), 200)
+	viper.SetDefault(string(LocalMessagingBatchTimeout), "100ms")
+	viper.SetDefault(string(LocalMessagingBatchPayloadLimit), "800Kb")
+	viper.SetDefault(string(StatusSummaryInterval), "30s")
 	viper.SetDefault(string(SubscriptionDefaultsReadAhead), 0)
 	viper.SetDefault(string(SubscriptionMax), 500)
 	viper.SetDefault(string(SubscriptionsRetryInitialDelay), "250ms")
 	viper.SetDefault(string(SubscriptionsRetryMaxDelay), "30s")
 	viper.SetDefault(string(SubscriptionsRetryFactor), 2.0)
+	viper.SetDefault(string(SubscriptionDryRunMaxEvents), 100)
+	viper.SetDefault(string(SubscriptionTapMaxEvents), 100)
+	viper.SetDefault(string(SubscriptionTapTimeout), "5m")
+	viper.SetDefault(string(SwapsPollInterval), "5s")
+	viper.SetDefault(string(SwapsDefaultTimeout), "1h")
 	viper.SetDefault(string(TransactionCacheSize), "1Mb")
 	viper.SetDefault(string(TransactionCacheTTL), "5m")
 	viper.SetDefault(string(UIEnabled), true)
 	viper.SetDefault(string(ValidatorCacheSize), "1Mb")
 	viper.SetDefault(string(ValidatorCacheTTL), "1h")
+	viper.SetDefault(string(IdentityEndorsementQuorum), 0)
 	viper.SetDefault(string(IdentityManagerCacheLimit), 100 /* items */)
 	viper.SetDefault(string(IdentityManagerCacheTTL), "1h")
 
+	// Record the root defaults, so they can be used to type-check supplied configuration
+	for k := range knownKeys {
+		if v := viper.Get(k); v != nil {
+			keyDefaults[k] = v
+		}
+	}
+
 	i18n.SetLang(viper.GetString(string(Lang)))
 }
 
-// ReadConfig initializes the config
+// ReadConfig initializes the config, resolving any references to environment variables,
+// secret files, and secrets providers
 func ReadConfig(cfgFile string) error {
+	if err := readConfig(cfgFile); err != nil {
+		return err
+	}
+	_, err := resolveSecrets(context.Background())
+	return err
+}
+
+func readConfig(cfgFile string) error {
 	keysMutex.Lock() // must only call viper directly here (as we already hold the lock)
 	defer keysMutex.Unlock()
 
@@ -399,19 +670,25 @@ func ReadConfig(cfgFile string) error {
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 	viper.SetConfigType("yaml")
+	var err error
 	if cfgFile != "" {
-		f, err := os.Open(cfgFile)
+		var f *os.File
+		f, err = os.Open(cfgFile)
 		if err == nil {
 			defer f.Close()
 			err = viper.ReadConfig(f)
 		}
+	} else {
+		viper.SetConfigName("firefly.core")
+		viper.AddConfigPath("/etc/firefly/")
+		viper.AddConfigPath("$HOME/.firefly")
+		viper.AddConfigPath(".")
+		err = viper.ReadInConfig()
+	}
+	if err != nil {
 		return err
 	}
-	viper.SetConfigName("firefly.core")
-	viper.AddConfigPath("/etc/firefly/")
-	viper.AddConfigPath("$HOME/.firefly")
-	viper.AddConfigPath(".")
-	return viper.ReadInConfig()
+	return expandConfig(context.Background())
 }
 
 func MergeConfig(configRecords []*fftypes.ConfigRecord) error {
@@ -454,6 +731,7 @@ func MergeConfig(configRecords []*fftypes.ConfigRecord) error {
 }
 
 var knownKeys = map[string]bool{} // All keys go here, including those defined in sub prefixies
+var keyDefaults = map[string]interface{}{}
 var keysMutex sync.Mutex
 var root = &configPrefix{}
 
@@ -553,8 +831,10 @@ func (c *configPrefixArray) AddKnownKey(k string, defValue ...interface{}) {
 	defer keysMutex.Unlock()
 
 	// Put a simulated key in the known keys array, to pop into the help info.
-	knownKeys[fmt.Sprintf("%s[].%s", c.base, k)] = true
+	arrayKey := fmt.Sprintf("%s[].%s", c.base, k)
+	knownKeys[arrayKey] = true
 	c.defaults[k] = defValue
+	recordDefault(arrayKey, defValue)
 }
 
 func (c *configPrefix) AddKnownKey(k string, defValue ...interface{}) {
@@ -567,6 +847,16 @@ func (c *configPrefix) AddKnownKey(k string, defValue ...interface{}) {
 	keysMutex.Lock()
 	defer keysMutex.Unlock()
 	knownKeys[key] = true
+	recordDefault(key, defValue)
+}
+
+// recordDefault must be called with the keysMutex held
+func recordDefault(key string, defValue []interface{}) {
+	if len(defValue) == 1 {
+		keyDefaults[key] = defValue[0]
+	} else if len(defValue) > 0 {
+		keyDefaults[key] = defValue
+	}
 }
 
 func (c *configPrefix) SetDefault(k string, defValue interface{}) {
@@ -716,6 +1006,24 @@ func (c *configPrefix) Get(key string) interface{} {
 	return viper.Get(c.prefixKey(key))
 }
 
+// HasSection returns whether any configuration has been supplied for a key, or a section
+// beneath it, either in the config file or via environment variables (defaults are ignored)
+func HasSection(key string) bool {
+	keysMutex.Lock()
+	defer keysMutex.Unlock()
+
+	if viper.InConfig(key) {
+		return true
+	}
+	envPrefix := strings.ToUpper("firefly_" + strings.ReplaceAll(key, ".", "_") + "_")
+	for _, env := range os.Environ() {
+		if strings.HasPrefix(strings.ToUpper(env), envPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // Set allows runtime setting of config (used in unit tests)
 func Set(key RootKey, value interface{}) {
 	root.Set(string(key), value)
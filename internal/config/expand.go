@@ -0,0 +1,168 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/spf13/viper"
+)
+
+const secretFilePrefix = "file://"
+
+var envVarRegex = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandConfig resolves ${ENV_VAR} references, and file:// secret references (such as a
+// mounted Kubernetes secret), in every value of the loaded configuration.
+// It also records every value that references a secrets provider, for resolveSecrets.
+// Must be called with the keysMutex held.
+func expandConfig(ctx context.Context) error {
+	secretRefs = map[string]interface{}{}
+	for _, key := range viper.AllKeys() {
+		value := viper.Get(key)
+		expanded, changed, err := expandValue(ctx, key, value, expandString)
+		if err != nil {
+			return err
+		}
+		if changed {
+			applyValue(key, expanded)
+		}
+		if _, hasSecretRef, _ := expandValue(ctx, key, expanded, detectSecretRef); hasSecretRef {
+			secretRefs[key] = expanded
+		}
+	}
+	return nil
+}
+
+// applyValue stores an expanded value. Must be called with the keysMutex held.
+func applyValue(key string, value interface{}) {
+	switch value.(type) {
+	case string, []string:
+		viper.Set(key, value)
+	default:
+		// Viper cannot look up array indexes (such as "tokens.0.name") within values set
+		// as overrides, so arrays and objects are merged back into the loaded config
+		_ = viper.MergeConfigMap(nestedConfigMap(key, value)) // cannot fail when merging a map
+	}
+}
+
+func nestedConfigMap(key string, value interface{}) map[string]interface{} {
+	parts := strings.Split(key, ".")
+	m := map[string]interface{}{parts[len(parts)-1]: value}
+	for i := len(parts) - 2; i >= 0; i-- {
+		m = map[string]interface{}{parts[i]: m}
+	}
+	return m
+}
+
+// stringExpander is applied to every string within a configuration value
+type stringExpander func(ctx context.Context, key, value string) (string, bool, error)
+
+func expandValue(ctx context.Context, key string, value interface{}, expand stringExpander) (interface{}, bool, error) {
+	switch v := value.(type) {
+	case string:
+		return expand(ctx, key, v)
+	case []interface{}:
+		changed := false
+		expanded := make([]interface{}, len(v))
+		for i, entry := range v {
+			e, entryChanged, err := expandValue(ctx, key, entry, expand)
+			if err != nil {
+				return nil, false, err
+			}
+			expanded[i] = e
+			changed = changed || entryChanged
+		}
+		return expanded, changed, nil
+	case []string:
+		changed := false
+		expanded := make([]string, len(v))
+		for i, entry := range v {
+			e, entryChanged, err := expand(ctx, key, entry)
+			if err != nil {
+				return nil, false, err
+			}
+			expanded[i] = e
+			changed = changed || entryChanged
+		}
+		return expanded, changed, nil
+	case map[string]interface{}:
+		changed := false
+		expanded := make(map[string]interface{}, len(v))
+		for k, entry := range v {
+			e, entryChanged, err := expandValue(ctx, key+"."+k, entry, expand)
+			if err != nil {
+				return nil, false, err
+			}
+			expanded[k] = e
+			changed = changed || entryChanged
+		}
+		return expanded, changed, nil
+	case map[interface{}]interface{}:
+		changed := false
+		expanded := make(map[interface{}]interface{}, len(v))
+		for k, entry := range v {
+			e, entryChanged, err := expandValue(ctx, key, entry, expand)
+			if err != nil {
+				return nil, false, err
+			}
+			expanded[k] = e
+			changed = changed || entryChanged
+		}
+		return expanded, changed, nil
+	default:
+		return value, false, nil
+	}
+}
+
+// expandString substitutes environment variables, using "$${" as an escape for a literal "${",
+// then loads the content of the file if the resulting value is a file:// reference
+func expandString(ctx context.Context, key, value string) (string, bool, error) {
+	if !strings.Contains(value, "${") && !strings.HasPrefix(value, secretFilePrefix) {
+		return value, false, nil
+	}
+	var err error
+	expanded := envVarRegex.ReplaceAllStringFunc(value, func(ref string) string {
+		if ref == "$${" {
+			return "${"
+		}
+		varName := ref[2 : len(ref)-1]
+		envValue, ok := os.LookupEnv(varName)
+		if !ok && err == nil {
+			err = i18n.NewError(ctx, i18n.MsgConfigEnvVarNotSet, varName, key)
+		}
+		return envValue
+	})
+	if err != nil {
+		return "", false, err
+	}
+	if strings.HasPrefix(expanded, secretFilePrefix) {
+		filename := strings.TrimPrefix(expanded, secretFilePrefix)
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return "", false, i18n.WrapError(ctx, err, i18n.MsgConfigSecretFileRead, filename, key)
+		}
+		// Secrets mounted from files commonly have a trailing newline, which is never significant
+		expanded = strings.TrimRight(string(b), "\r\n")
+	}
+	return expanded, expanded != value, nil
+}
@@ -0,0 +1,138 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestConfigFile(t *testing.T, dir, content string) string {
+	cfgFile := path.Join(dir, "firefly.core.yaml")
+	err := ioutil.WriteFile(cfgFile, []byte(content), 0600)
+	assert.NoError(t, err)
+	return cfgFile
+}
+
+func TestReadConfigExpansion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ff")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	secretFile := path.Join(dir, "db-password")
+	err = ioutil.WriteFile(secretFile, []byte("s3cret\n"), 0600)
+	assert.NoError(t, err)
+	os.Setenv("UT_FF_DB_HOST", "db.example.com")
+	defer os.Unsetenv("UT_FF_DB_HOST")
+	os.Setenv("UT_FF_SECRET_DIR", dir)
+	defer os.Unsetenv("UT_FF_SECRET_DIR")
+
+	Reset()
+	ut := NewPluginConfig("utexpand")
+	ut.AddKnownKey("url")
+	ut.AddKnownKey("password")
+	ut.AddKnownKey("literal")
+	ut.AddKnownKey("hosts")
+	cfgFile := writeTestConfigFile(t, dir, `
+utexpand:
+  url: postgres://${UT_FF_DB_HOST}:5432/firefly
+  password: file://${UT_FF_SECRET_DIR}/db-password
+  literal: $${UT_FF_DB_HOST} pa$$word
+  hosts:
+  - ${UT_FF_DB_HOST}
+tokens:
+- name: tokens1
+  url: http://${UT_FF_DB_HOST}:3000
+  headers:
+    authorization: file://`+secretFile+`
+`)
+	err = ReadConfig(cfgFile)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "postgres://db.example.com:5432/firefly", ut.GetString("url"))
+	assert.Equal(t, "s3cret", ut.GetString("password"))
+	assert.Equal(t, "${UT_FF_DB_HOST} pa$$word", ut.GetString("literal"))
+	assert.Equal(t, []string{"db.example.com"}, ut.GetStringSlice("hosts"))
+	assert.Equal(t, "tokens1", viper.GetString("tokens.0.name"))
+	tokens := viper.Get("tokens").([]interface{})
+	assert.Equal(t, "http://db.example.com:3000", tokens[0].(map[interface{}]interface{})["url"])
+	assert.Equal(t, "s3cret", tokens[0].(map[interface{}]interface{})["headers"].(map[interface{}]interface{})["authorization"])
+}
+
+func TestReadConfigExpansionMissingEnvVar(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ff")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	Reset()
+	cfgFile := writeTestConfigFile(t, dir, `
+database:
+  postgres:
+    url: postgres://${UT_FF_NOT_SET}/firefly
+`)
+	err = ReadConfig(cfgFile)
+	assert.Regexp(t, "FF10384.*UT_FF_NOT_SET.*database.postgres.url", err)
+}
+
+func TestReadConfigExpansionMissingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ff")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	Reset()
+	cfgFile := writeTestConfigFile(t, dir, `
+tokens:
+- name: tokens1
+  password: file://`+dir+`/missing
+`)
+	err = ReadConfig(cfgFile)
+	assert.Regexp(t, "FF10385.*missing.*tokens", err)
+}
+
+func TestExpandValueNested(t *testing.T) {
+	ctx := context.Background()
+	os.Setenv("UT_FF_VALUE", "expanded")
+	defer os.Unsetenv("UT_FF_VALUE")
+
+	v, changed, err := expandValue(ctx, "ut", []string{"${UT_FF_VALUE}", "unchanged"}, expandString)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []string{"expanded", "unchanged"}, v)
+
+	v, changed, err = expandValue(ctx, "ut", map[string]interface{}{"a": []interface{}{"${UT_FF_VALUE}"}, "b": 12345}, expandString)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, map[string]interface{}{"a": []interface{}{"expanded"}, "b": 12345}, v)
+
+	_, changed, err = expandValue(ctx, "ut", []interface{}{"no refs", true}, expandString)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	_, _, err = expandValue(ctx, "ut", []string{"${UT_FF_NOT_SET}"}, expandString)
+	assert.Regexp(t, "FF10384", err)
+	_, _, err = expandValue(ctx, "ut", []interface{}{"${UT_FF_NOT_SET}"}, expandString)
+	assert.Regexp(t, "FF10384", err)
+	_, _, err = expandValue(ctx, "ut", map[string]interface{}{"a": "${UT_FF_NOT_SET}"}, expandString)
+	assert.Regexp(t, "FF10384.*ut.a", err)
+	_, _, err = expandValue(ctx, "ut", map[interface{}]interface{}{"a": "${UT_FF_NOT_SET}"}, expandString)
+	assert.Regexp(t, "FF10384", err)
+}
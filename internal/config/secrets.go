@@ -0,0 +1,159 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/spf13/viper"
+)
+
+// SecretsProvider is implemented by plugins that fetch secrets from an external store, such
+// as HashiCorp Vault. Any configuration value of the form "<name>://<reference>" is replaced
+// with the secret returned by the provider with that name.
+type SecretsProvider interface {
+	// Name is the scheme used to reference secrets from this provider
+	Name() string
+
+	// InitPrefix initializes the set of configuration options that are valid, with defaults
+	InitPrefix(prefix Prefix)
+
+	// GetSecret fetches the current value of a secret
+	GetSecret(ctx context.Context, prefix Prefix, ref string) (string, error)
+}
+
+type secretsProviderEntry struct {
+	provider SecretsProvider
+	prefix   Prefix
+}
+
+type secretsProviderSet map[string]*secretsProviderEntry
+
+var (
+	secretsConfig    = NewPluginConfig("secrets")
+	secretsProviders = secretsProviderSet{}
+	secretRefs       = map[string]interface{}{}
+)
+
+// InitSecretsProviders registers the configuration of each secrets provider, under "secrets.<name>",
+// and enables references to those providers in configuration values.
+// Must be called after Reset, and before ReadConfig.
+func InitSecretsProviders(providers ...SecretsProvider) {
+	set := make(secretsProviderSet, len(providers))
+	for _, p := range providers {
+		prefix := secretsConfig.SubPrefix(p.Name())
+		p.InitPrefix(prefix)
+		set[strings.ToLower(p.Name())] = &secretsProviderEntry{provider: p, prefix: prefix}
+	}
+	keysMutex.Lock()
+	defer keysMutex.Unlock()
+	secretsProviders = set
+}
+
+func (sps secretsProviderSet) lookup(value string) (*secretsProviderEntry, string) {
+	idx := strings.Index(value, "://")
+	if idx <= 0 {
+		return nil, ""
+	}
+	return sps[strings.ToLower(value[0:idx])], value[idx+3:]
+}
+
+// detectSecretRef is a stringExpander that does not change the value, but reports whether it
+// references a secrets provider. Must be called with the keysMutex held.
+func detectSecretRef(ctx context.Context, key, value string) (string, bool, error) {
+	sp, _ := secretsProviders.lookup(value)
+	return value, sp != nil, nil
+}
+
+// resolveSecrets fetches every secret referenced by the configuration from its provider, returning
+// true if any value has changed. Must be called without the keysMutex held, as each provider reads
+// its own configuration.
+func resolveSecrets(ctx context.Context) (changed bool, err error) {
+	keysMutex.Lock()
+	providers := secretsProviders
+	refs := make(map[string]interface{}, len(secretRefs))
+	keys := make([]string, 0, len(secretRefs))
+	for k, v := range secretRefs {
+		refs[k] = v
+		keys = append(keys, k)
+	}
+	keysMutex.Unlock()
+	sort.Strings(keys)
+
+	fetchSecret := func(ctx context.Context, key, value string) (string, bool, error) {
+		sp, ref := providers.lookup(value)
+		if sp == nil {
+			return value, false, nil
+		}
+		secret, err := sp.provider.GetSecret(ctx, sp.prefix, ref)
+		if err != nil {
+			return "", false, i18n.WrapError(ctx, err, i18n.MsgConfigSecretResolve, key, sp.provider.Name())
+		}
+		return secret, true, nil
+	}
+	for _, key := range keys {
+		resolved, _, err := expandValue(ctx, key, refs[key], fetchSecret)
+		if err != nil {
+			return false, err
+		}
+		keysMutex.Lock()
+		if !reflect.DeepEqual(viper.Get(key), resolved) {
+			applyValue(key, resolved)
+			changed = true
+		}
+		keysMutex.Unlock()
+	}
+	return changed, nil
+}
+
+// WatchSecrets periodically re-fetches every secret referenced by the configuration, until the
+// context is cancelled. If any secret has been rotated, the new value is stored in the configuration
+// and onRotate is called - so that components can be restarted to pick up the new credentials.
+func WatchSecrets(ctx context.Context, onRotate func()) {
+	keysMutex.Lock()
+	refCount := len(secretRefs)
+	keysMutex.Unlock()
+	interval := GetDuration(SecretsRefreshInterval)
+	if refCount == 0 || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				changed, err := resolveSecrets(ctx)
+				if err != nil {
+					log.L(ctx).Errorf("Failed to refresh secrets: %s", err)
+				} else if changed {
+					log.L(ctx).Infof("Secrets have been rotated")
+					onRotate()
+					return
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,176 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSecretsProvider struct {
+	mux     sync.Mutex
+	secrets map[string]string
+	err     error
+}
+
+func (tsp *testSecretsProvider) Name() string {
+	return "utsecrets"
+}
+
+func (tsp *testSecretsProvider) InitPrefix(prefix Prefix) {
+	prefix.AddKnownKey("url")
+}
+
+func (tsp *testSecretsProvider) GetSecret(ctx context.Context, prefix Prefix, ref string) (string, error) {
+	tsp.mux.Lock()
+	defer tsp.mux.Unlock()
+	if tsp.err != nil {
+		return "", tsp.err
+	}
+	return tsp.secrets[ref] + prefix.GetString("url"), nil
+}
+
+func (tsp *testSecretsProvider) setSecret(ref, value string) {
+	tsp.mux.Lock()
+	defer tsp.mux.Unlock()
+	tsp.secrets[ref] = value
+}
+
+func (tsp *testSecretsProvider) setErr(err error) {
+	tsp.mux.Lock()
+	defer tsp.mux.Unlock()
+	tsp.err = err
+}
+
+func readSecretsTestConfig(t *testing.T, tsp *testSecretsProvider, yaml string) error {
+	dir, err := ioutil.TempDir("", "ff")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	Reset()
+	InitSecretsProviders(tsp)
+	ut := NewPluginConfig("utsecretsconf")
+	ut.AddKnownKey("password")
+	ut.AddKnownKey("other")
+	return ReadConfig(writeTestConfigFile(t, dir, yaml))
+}
+
+func TestReadConfigResolvesSecrets(t *testing.T) {
+	tsp := &testSecretsProvider{secrets: map[string]string{"db#password": "s3cret", "tokens#key": "k3y"}}
+	os.Setenv("UT_FF_SECRET_FIELD", "password")
+	defer os.Unsetenv("UT_FF_SECRET_FIELD")
+	err := readSecretsTestConfig(t, tsp, `
+utsecretsconf:
+  password: utsecrets://db#${UT_FF_SECRET_FIELD}
+  other: http://localhost:12345
+secrets:
+  utsecrets:
+    url: ""
+tokens:
+- name: tokens1
+  key: UTSecrets://tokens#key
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", GetString("utsecretsconf.password"))
+	assert.Equal(t, "http://localhost:12345", GetString("utsecretsconf.other"))
+	assert.Equal(t, "tokens1", viper.GetString("tokens.0.name"))
+	assert.Equal(t, "k3y", viper.GetString("tokens.0.key"))
+}
+
+func TestReadConfigSecretsProviderFail(t *testing.T) {
+	tsp := &testSecretsProvider{err: fmt.Errorf("pop")}
+	err := readSecretsTestConfig(t, tsp, `
+utsecretsconf:
+  password: utsecrets://db#password
+`)
+	assert.Regexp(t, "FF10386.*utsecretsconf.password.*utsecrets.*pop", err)
+}
+
+func TestReadConfigFailBeforeSecrets(t *testing.T) {
+	Reset()
+	err := ReadConfig("!!!missing")
+	assert.Error(t, err)
+}
+
+func TestWatchSecretsRotate(t *testing.T) {
+	tsp := &testSecretsProvider{secrets: map[string]string{"db#password": "s3cret"}}
+	err := readSecretsTestConfig(t, tsp, `
+utsecretsconf:
+  password: utsecrets://db#password
+secrets:
+  refreshInterval: 1ms
+`)
+	assert.NoError(t, err)
+
+	// An error, and an unchanged value, do not trigger a rotation
+	tsp.setErr(fmt.Errorf("pop"))
+	changed, err := resolveSecrets(context.Background())
+	assert.Regexp(t, "pop", err)
+	assert.False(t, changed)
+	tsp.setErr(nil)
+	changed, err = resolveSecrets(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	tsp.setErr(fmt.Errorf("pop"))
+	rotated := make(chan struct{})
+	WatchSecrets(context.Background(), func() { close(rotated) })
+	time.Sleep(5 * time.Millisecond)
+	tsp.setErr(nil)
+	tsp.setSecret("db#password", "n3w")
+	<-rotated
+	assert.Equal(t, "n3w", GetString("utsecretsconf.password"))
+}
+
+func TestWatchSecretsCancelled(t *testing.T) {
+	tsp := &testSecretsProvider{secrets: map[string]string{"db#password": "s3cret"}}
+	err := readSecretsTestConfig(t, tsp, `
+utsecretsconf:
+  password: utsecrets://db#password
+secrets:
+  refreshInterval: 1ms
+`)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	WatchSecrets(ctx, func() { panic("should not rotate") })
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+}
+
+func TestWatchSecretsNoRefs(t *testing.T) {
+	tsp := &testSecretsProvider{}
+	err := readSecretsTestConfig(t, tsp, `
+utsecretsconf:
+  password: plain
+`)
+	assert.NoError(t, err)
+	WatchSecrets(context.Background(), func() { panic("should not rotate") })
+}
+
+func TestSecretsProviderLookupNoScheme(t *testing.T) {
+	sp, _ := secretsProviderSet{}.lookup("://nothing")
+	assert.Nil(t, sp)
+}
@@ -0,0 +1,213 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/spf13/viper"
+)
+
+// Validate checks the loaded configuration against the keys that have been registered by the core
+// and all plugins, returning a precise error for every unknown key, and every value that does not
+// match the type of the default for that key.
+// All plugins must have registered their keys (via InitPrefix) before this is called.
+func Validate(ctx context.Context) []error {
+	keysMutex.Lock()
+	defer keysMutex.Unlock()
+
+	known := make(map[string]string, len(knownKeys))
+	for k := range knownKeys {
+		known[strings.ToLower(k)] = k
+	}
+
+	var problems []error
+	reported := map[string]bool{}
+	allKeys := viper.AllKeys()
+	sort.Strings(allKeys)
+	for _, key := range allKeys {
+		if origKey, ok := known[key]; ok {
+			problems = append(problems, checkValue(ctx, origKey, viper.Get(key))...)
+			problems = append(problems, checkArrayEntries(ctx, known, origKey, viper.Get(key))...)
+			continue
+		}
+		problems = append(problems, checkUnknown(ctx, known, reported, key, key)...)
+	}
+	return problems
+}
+
+// checkUnknown handles a key that is not registered, which is valid if it is nested inside a
+// known key that holds an object (such as a set of HTTP headers)
+func checkUnknown(ctx context.Context, known map[string]string, reported map[string]bool, key, displayKey string) []error {
+	for ancestor := parentKey(key); ancestor != ""; ancestor = parentKey(ancestor) {
+		if origKey, ok := known[ancestor]; ok {
+			if !expectsObject(keyDefaults[origKey]) && !reported[origKey] {
+				reported[origKey] = true
+				return []error{i18n.NewError(ctx, i18n.MsgConfigTypeMismatch, origKey, describeType(keyDefaults[origKey]))}
+			}
+			return nil
+		}
+	}
+	return []error{i18n.NewError(ctx, i18n.MsgConfigUnknownKey, displayKey)}
+}
+
+// checkArrayEntries validates each entry in an array of plugin configurations, such as the "tokens" list
+func checkArrayEntries(ctx context.Context, known map[string]string, key string, value interface{}) []error {
+	entries, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	arrayPrefix := strings.ToLower(key) + "[]."
+	isPluginArray := false
+	for k := range known {
+		if strings.HasPrefix(k, arrayPrefix) {
+			isPluginArray = true
+			break
+		}
+	}
+	if !isPluginArray {
+		return nil
+	}
+	var problems []error
+	reported := map[string]bool{}
+	for i, entry := range entries {
+		flattened := map[string]interface{}{}
+		flattenEntry("", entry, flattened)
+		entryKeys := make([]string, 0, len(flattened))
+		for k := range flattened {
+			entryKeys = append(entryKeys, k)
+		}
+		sort.Strings(entryKeys)
+		for _, k := range entryKeys {
+			arrayKey := arrayPrefix + k
+			displayKey := fmt.Sprintf("%s[%d].%s", key, i, k)
+			if origKey, ok := known[arrayKey]; ok {
+				problems = append(problems, checkValue(ctx, origKey, flattened[k])...)
+				continue
+			}
+			problems = append(problems, checkUnknown(ctx, known, reported, arrayKey, displayKey)...)
+		}
+	}
+	return problems
+}
+
+func flattenEntry(prefix string, value interface{}, into map[string]interface{}) {
+	var m map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m = v
+	case map[interface{}]interface{}:
+		m = make(map[string]interface{}, len(v))
+		for k, mv := range v {
+			m[fmt.Sprintf("%v", k)] = mv
+		}
+	default:
+		if prefix != "" {
+			into[prefix] = value
+		}
+		return
+	}
+	for k, mv := range m {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		flattenEntry(key, mv, into)
+	}
+}
+
+func parentKey(key string) string {
+	idx := strings.LastIndex(key, ".")
+	if idx < 0 {
+		return ""
+	}
+	return key[0:idx]
+}
+
+func expectsObject(defValue interface{}) bool {
+	return defValue == nil || reflect.TypeOf(defValue).Kind() == reflect.Map
+}
+
+func describeType(defValue interface{}) string {
+	switch defValue.(type) {
+	case bool:
+		return "boolean"
+	case int, int32, int64, uint, uint32, uint64:
+		return "integer"
+	case float32, float64:
+		return "number"
+	case string:
+		return "string"
+	default:
+		return "array"
+	}
+}
+
+// checkValue compares a supplied value against the type of the default for the key.
+// Keys without a default are not type checked.
+func checkValue(ctx context.Context, key string, value interface{}) []error {
+	defValue, hasDefault := keyDefaults[key]
+	if !hasDefault || value == nil {
+		return nil
+	}
+	valid := true
+	vKind := reflect.TypeOf(value).Kind()
+	strValue, isString := value.(string)
+	switch defValue.(type) {
+	case bool:
+		if !isString {
+			valid = vKind == reflect.Bool
+		} else {
+			_, err := strconv.ParseBool(strValue)
+			valid = err == nil
+		}
+	case int, int32, int64, uint, uint32, uint64:
+		switch {
+		case isString:
+			_, err := strconv.ParseInt(strings.TrimSpace(strValue), 10, 64)
+			valid = err == nil
+		case vKind == reflect.Float32 || vKind == reflect.Float64:
+			f := reflect.ValueOf(value).Float()
+			valid = f == float64(int64(f))
+		default:
+			valid = vKind >= reflect.Int && vKind <= reflect.Uint64
+		}
+	case float32, float64:
+		if isString {
+			_, err := strconv.ParseFloat(strings.TrimSpace(strValue), 64)
+			valid = err == nil
+		} else {
+			valid = vKind >= reflect.Int && vKind <= reflect.Float64
+		}
+	case string:
+		valid = vKind != reflect.Map && vKind != reflect.Slice
+	default:
+		if reflect.TypeOf(defValue).Kind() == reflect.Slice {
+			valid = vKind == reflect.Slice || isString
+		}
+	}
+	if !valid {
+		return []error{i18n.NewError(ctx, i18n.MsgConfigTypeMismatch, key, describeType(defValue))}
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func readTestYAML(t *testing.T, yaml string) {
+	Reset()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(yaml))
+	assert.NoError(t, err)
+}
+
+func errorStrings(errs []error) []string {
+	s := make([]string, len(errs))
+	for i, e := range errs {
+		s[i] = e.Error()
+	}
+	return s
+}
+
+func TestValidateOk(t *testing.T) {
+	ut := NewPluginConfig("utvalidate")
+	ut.AddKnownKey("headers")
+	ut.AddKnownKey("enabled", false)
+	ut.AddKnownKey("count", 5)
+	ut.AddKnownKey("factor", 2.0)
+	ut.AddKnownKey("list", "a", "b")
+	readTestYAML(t, `
+log:
+  level: debug
+api:
+  defaultFilterLimit: "50"
+utvalidate:
+  enabled: "true"
+  count: 10.0
+  factor: 3
+  list: a b c
+  headers:
+    x-custom: value
+`)
+	assert.Empty(t, Validate(context.Background()))
+}
+
+func TestValidateUnknownKeyAndTypes(t *testing.T) {
+	ut := NewPluginConfig("utvalidate2")
+	ut.AddKnownKey("enabled", false)
+	ut.AddKnownKey("count", 5)
+	ut.AddKnownKey("factor", 2.0)
+	ut.AddKnownKey("name", "default")
+	ut.AddKnownKey("list", "a", "b")
+	ut.AddKnownKey("long", int64(1))
+	readTestYAML(t, `
+subscription:
+  defaults:
+    readAhead: 50
+utvalidate2:
+  enabled: maybe
+  count: 1.5
+  factor: fast
+  name:
+  - a
+  list:
+    a: b
+  long:
+    nested: true
+`)
+	problems := errorStrings(Validate(context.Background()))
+	assert.Len(t, problems, 7)
+	assert.Regexp(t, "FF10379.*subscription.defaults.readahead", strings.Join(problems, "\n"))
+	assert.Regexp(t, "FF10380.*utvalidate2.enabled.*boolean", strings.Join(problems, "\n"))
+	assert.Regexp(t, "FF10380.*utvalidate2.count.*integer", strings.Join(problems, "\n"))
+	assert.Regexp(t, "FF10380.*utvalidate2.factor.*number", strings.Join(problems, "\n"))
+	assert.Regexp(t, "FF10380.*utvalidate2.name.*string", strings.Join(problems, "\n"))
+	assert.Regexp(t, "FF10380.*utvalidate2.list.*array", strings.Join(problems, "\n"))
+	assert.Regexp(t, "FF10380.*utvalidate2.long.*integer", strings.Join(problems, "\n"))
+}
+
+func TestValidateNumericTypes(t *testing.T) {
+	ut := NewPluginConfig("utvalidate3")
+	ut.AddKnownKey("count", 5)
+	ut.AddKnownKey("factor", 2.0)
+	readTestYAML(t, `
+utvalidate3:
+  count: abc
+  factor: true
+`)
+	problems := errorStrings(Validate(context.Background()))
+	assert.Len(t, problems, 2)
+}
+
+func TestValidateArrayEntries(t *testing.T) {
+	ut := NewPluginConfig("utplugins").Array()
+	ut.AddKnownKey("name")
+	ut.AddKnownKey("enabled", true)
+	ut.AddKnownKey("headers")
+	ut.AddKnownKey("retry", 3)
+	root.AddKnownKey("utplugins")
+	readTestYAML(t, `
+utplugins:
+- name: first
+  enabled: false
+  headers:
+    a: b
+- name: second
+  enabled: notabool
+  unknown: value
+  retry:
+    count: 5
+`)
+	problems := errorStrings(Validate(context.Background()))
+	assert.Equal(t, []string{
+		"FF10380: Invalid value for configuration key 'utplugins[].enabled' - expected boolean",
+		"FF10380: Invalid value for configuration key 'utplugins[].retry' - expected integer",
+		"FF10379: Unknown configuration key 'utplugins[1].unknown'",
+	}, problems)
+}
+
+func TestValidateNonPluginArray(t *testing.T) {
+	Reset()
+	Set(NamespacesPredefined, []interface{}{map[string]interface{}{"name": "ns1"}})
+	assert.Empty(t, Validate(context.Background()))
+}
+
+func TestFlattenEntry(t *testing.T) {
+	into := map[string]interface{}{}
+	flattenEntry("", "scalar", into)
+	assert.Empty(t, into)
+	flattenEntry("", map[interface{}]interface{}{"Key": map[string]interface{}{"sub": 1}}, into)
+	assert.Equal(t, map[string]interface{}{"key.sub": 1}, into)
+}
+
+func TestHasSection(t *testing.T) {
+	readTestYAML(t, `
+database:
+  postgres:
+    url: postgres://
+`)
+	assert.True(t, HasSection("database.postgres"))
+	assert.False(t, HasSection("database.sqlite3"))
+	os.Setenv("FIREFLY_DATABASE_SQLITE3_URL", "file://")
+	defer os.Unsetenv("FIREFLY_DATABASE_SQLITE3_URL")
+	assert.True(t, HasSection("database.sqlite3"))
+}
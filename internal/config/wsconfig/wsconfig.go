@@ -67,5 +67,7 @@ func GenerateConfigFromPrefix(prefix config.Prefix) *wsclient.WSConfig {
 		AuthUsername:           prefix.GetString(restclient.HTTPConfigAuthUsername),
 		AuthPassword:           prefix.GetString(restclient.HTTPConfigAuthPassword),
 		HeartbeatInterval:      prefix.GetDuration(WSConfigHeartbeatInterval),
+		SigningKey:             prefix.GetString(restclient.HTTPConfigSigningKey),
+		SigningMaxSkew:         prefix.GetDuration(restclient.HTTPConfigSigningMaxSkew),
 	}
 }
@@ -31,6 +31,7 @@ func TestWSConfigGeneration(t *testing.T) {
 	utConfPrefix.Set(WSConfigKeyWriteBufferSize, 1024)
 	utConfPrefix.Set(WSConfigKeyInitialConnectAttempts, 1)
 	utConfPrefix.Set(WSConfigKeyPath, "/websocket")
+	utConfPrefix.Set(restclient.HTTPConfigSigningKey, "key1")
 
 	wsConfig := GenerateConfigFromPrefix(utConfPrefix)
 
@@ -41,6 +42,8 @@ func TestWSConfigGeneration(t *testing.T) {
 	assert.Equal(t, time.Duration(1000000), wsConfig.MaximumDelay)
 	assert.Equal(t, 1, wsConfig.InitialConnectAttempts)
 	assert.Equal(t, "/websocket", wsConfig.WSKeyPath)
+	assert.Equal(t, "key1", wsConfig.SigningKey)
+	assert.Equal(t, 1*time.Minute, wsConfig.SigningMaxSkew)
 	assert.Equal(t, "custom value", wsConfig.HTTPHeaders.GetString("custom-header"))
 	assert.Equal(t, 1024, wsConfig.ReadBufferSize)
 	assert.Equal(t, 1024, wsConfig.WriteBufferSize)
@@ -19,11 +19,13 @@ package contracts
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/hyperledger/firefly/internal/broadcast"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/operations"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/blockchain"
@@ -53,6 +55,7 @@ type Manager interface {
 	GetContractListenerByNameOrID(ctx context.Context, ns, nameOrID string) (*fftypes.ContractListener, error)
 	GetContractListeners(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.ContractListener, *database.FilterResult, error)
 	DeleteContractListenerByNameOrID(ctx context.Context, ns, nameOrID string) error
+	RewindContractListener(ctx context.Context, ns, nameOrID string, rewind *fftypes.ContractListenerRewind) (*fftypes.ContractListener, error)
 	GenerateFFI(ctx context.Context, ns string, generationRequest *fftypes.FFIGenerationRequest) (*fftypes.FFI, error)
 
 	// From operations.OperationHandler
@@ -208,6 +211,9 @@ func (cm *contractManager) InvokeContract(ctx context.Context, ns string, req *f
 			return err
 		}
 		if req.Type == fftypes.CallTypeInvoke {
+			if err = cm.resolveInvokeContractErrors(ctx, req); err != nil {
+				return err
+			}
 			op, err = cm.writeInvokeTransaction(ctx, ns, req)
 			if err != nil {
 				return err
@@ -270,6 +276,22 @@ func (cm *contractManager) resolveInvokeContractRequest(ctx context.Context, ns
 	return method, nil
 }
 
+// resolveInvokeContractErrors looks up the custom errors declared on the interface, so the blockchain
+// plugin can decode them if the transaction is reverted
+func (cm *contractManager) resolveInvokeContractErrors(ctx context.Context, req *fftypes.ContractCallRequest) error {
+	if req.Interface == nil || req.Errors != nil {
+		return nil
+	}
+	ffi, err := cm.database.GetFFIByID(ctx, req.Interface)
+	if err != nil {
+		return err
+	}
+	if ffi != nil {
+		req.Errors = ffi.Errors
+	}
+	return nil
+}
+
 func (cm *contractManager) addContractURLs(httpServerURL string, api *fftypes.ContractAPI) {
 	if api != nil {
 		// These URLs must match the actual routes in apiserver.createMuxRouter()!
@@ -389,6 +411,12 @@ func (cm *contractManager) ValidateFFIAndSetPathnames(ctx context.Context, ffi *
 			return err
 		}
 	}
+
+	for _, ffiError := range ffi.Errors {
+		if err := cm.validateFFIError(ctx, ffiError); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -432,6 +460,18 @@ func (cm *contractManager) validateFFIEvent(ctx context.Context, event *fftypes.
 	return nil
 }
 
+func (cm *contractManager) validateFFIError(ctx context.Context, ffiError *fftypes.FFIError) error {
+	if ffiError.Name == "" {
+		return i18n.NewError(ctx, i18n.MsgErrorNameMustBeSet)
+	}
+	for _, param := range ffiError.Params {
+		if err := cm.validateFFIParam(ctx, param); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (cm *contractManager) validateInvokeContractRequest(ctx context.Context, req *fftypes.ContractCallRequest) error {
 	if err := cm.validateFFIMethod(ctx, req.Method); err != nil {
 		return err
@@ -460,6 +500,11 @@ func (cm *contractManager) AddContractListener(ctx context.Context, ns string, l
 
 	if listener.Options == nil {
 		listener.Options = cm.getDefaultContractListenerOptions()
+	} else if listener.Options.FromBlock != nil {
+		// The connector delivers the history from this block in order, before any new events
+		if listener.Options.FirstEvent != "" {
+			return nil, i18n.NewError(ctx, i18n.MsgContractListenerFromBlock)
+		}
 	} else if listener.Options.FirstEvent == "" {
 		listener.Options.FirstEvent = cm.getDefaultContractListenerOptions().FirstEvent
 	}
@@ -532,7 +577,42 @@ func (cm *contractManager) AddContractListener(ctx context.Context, ns string, l
 	return &listener.ContractListener, err
 }
 
-func (cm *contractManager) GetContractListenerByNameOrID(ctx context.Context, ns, nameOrID string) (listener *fftypes.ContractListener, err error) {
+func (cm *contractManager) GetContractListenerByNameOrID(ctx context.Context, ns, nameOrID string) (*fftypes.ContractListener, error) {
+	listener, err := cm.getContractListenerByNameOrID(ctx, ns, nameOrID)
+	if err != nil {
+		return nil, err
+	}
+	if listener.Options != nil && listener.Options.FromBlock != nil {
+		if listener.Backfill, err = cm.getContractListenerBackfill(ctx, listener); err != nil {
+			return nil, err
+		}
+	}
+	return listener, nil
+}
+
+// getContractListenerBackfill reports how far through the history a listener has been delivered,
+// from the block number at the start of the checkpoint and the number of events received
+func (cm *contractManager) getContractListenerBackfill(ctx context.Context, listener *fftypes.ContractListener) (*fftypes.ContractListenerBackfill, error) {
+	fb := database.BlockchainEventQueryFactory.NewFilter(ctx)
+	_, res, err := cm.database.GetBlockchainEvents(ctx, fb.And(
+		fb.Eq("listener", listener.ID),
+	).Limit(1).Count(true))
+	if err != nil {
+		return nil, err
+	}
+	backfill := &fftypes.ContractListenerBackfill{
+		FromBlock: *listener.Options.FromBlock,
+	}
+	if res.TotalCount != nil {
+		backfill.Events = *res.TotalCount
+	}
+	if blockNumber, err := strconv.ParseUint(strings.Split(listener.Checkpoint, "/")[0], 10, 64); err == nil {
+		backfill.CurrentBlock = &blockNumber
+	}
+	return backfill, nil
+}
+
+func (cm *contractManager) getContractListenerByNameOrID(ctx context.Context, ns, nameOrID string) (listener *fftypes.ContractListener, err error) {
 	id, err := fftypes.ParseUUID(ctx, nameOrID)
 	if err != nil {
 		if err := fftypes.ValidateFFNameField(ctx, nameOrID, "name"); err != nil {
@@ -556,7 +636,7 @@ func (cm *contractManager) GetContractListeners(ctx context.Context, ns string,
 
 func (cm *contractManager) DeleteContractListenerByNameOrID(ctx context.Context, ns, nameOrID string) error {
 	return cm.database.RunAsGroup(ctx, func(ctx context.Context) (err error) {
-		listener, err := cm.GetContractListenerByNameOrID(ctx, ns, nameOrID)
+		listener, err := cm.getContractListenerByNameOrID(ctx, ns, nameOrID)
 		if err != nil {
 			return err
 		}
@@ -567,6 +647,30 @@ func (cm *contractManager) DeleteContractListenerByNameOrID(ctx context.Context,
 	})
 }
 
+func (cm *contractManager) RewindContractListener(ctx context.Context, ns, nameOrID string, rewind *fftypes.ContractListenerRewind) (*fftypes.ContractListener, error) {
+	if rewind.BlockNumber == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgContractListenerRewindBlock)
+	}
+	listener, err := cm.getContractListenerByNameOrID(ctx, ns, nameOrID)
+	if err != nil {
+		return nil, err
+	}
+	// Clear the checkpoint before asking the connector to redeliver, so that no redelivered event can be
+	// skipped. Then move it to the block we rewound to, unless redelivered events have already moved it on.
+	if _, err = cm.database.UpdateContractListenerCheckpoint(ctx, listener.ID, "", false); err != nil {
+		return nil, err
+	}
+	checkpoint, err := cm.blockchain.RewindContractListener(ctx, listener, *rewind.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = cm.database.UpdateContractListenerCheckpoint(ctx, listener.ID, checkpoint, true); err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Rewound contract listener %s to block %d", listener.ID, *rewind.BlockNumber)
+	return cm.database.GetContractListenerByID(ctx, listener.ID)
+}
+
 func (cm *contractManager) checkParamSchema(ctx context.Context, input interface{}, param *fftypes.FFIParam) error {
 	// TODO: Cache the compiled schema?
 	c := jsonschema.NewCompiler()
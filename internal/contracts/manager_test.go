@@ -555,6 +555,67 @@ func TestValidateFFIBadEventParam(t *testing.T) {
 	assert.Regexp(t, "FF10319", err)
 }
 
+func TestValidateFFIErrors(t *testing.T) {
+	cm := newTestContractManager()
+	ffi := &fftypes.FFI{
+		Name:      "math",
+		Version:   "1.0.0",
+		Namespace: "default",
+		Errors: fftypes.FFIErrors{
+			{
+				Name: "Overflow",
+				Params: fftypes.FFIParams{
+					{
+						Name:   "limit",
+						Schema: fftypes.JSONAnyPtr(`{"type": "integer", "details": {"type": "uint256"}}`),
+					},
+				},
+			},
+		},
+	}
+
+	err := cm.ValidateFFIAndSetPathnames(context.Background(), ffi)
+	assert.NoError(t, err)
+}
+
+func TestValidateFFIBadErrorName(t *testing.T) {
+	cm := newTestContractManager()
+	ffi := &fftypes.FFI{
+		Name:      "math",
+		Version:   "1.0.0",
+		Namespace: "default",
+		Errors: fftypes.FFIErrors{
+			{Name: ""},
+		},
+	}
+
+	err := cm.ValidateFFIAndSetPathnames(context.Background(), ffi)
+	assert.Regexp(t, "FF10436", err)
+}
+
+func TestValidateFFIBadErrorParam(t *testing.T) {
+	cm := newTestContractManager()
+	ffi := &fftypes.FFI{
+		Name:      "math",
+		Version:   "1.0.0",
+		Namespace: "default",
+		Errors: fftypes.FFIErrors{
+			{
+				Name: "Overflow",
+				Params: fftypes.FFIParams{
+					{
+						Name:   "limit",
+						Schema: fftypes.JSONAnyPtr(`{"type": "integer"`),
+					},
+				},
+			},
+		},
+	}
+
+	err := cm.ValidateFFIAndSetPathnames(context.Background(), ffi)
+	assert.Regexp(t, "unexpected EOF", err)
+}
+
 func TestAddContractListenerInline(t *testing.T) {
 	cm := newTestContractManager()
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
@@ -592,6 +653,57 @@ func TestAddContractListenerInline(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestAddContractListenerFromBlock(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	fromBlock := uint64(1000)
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Location: fftypes.JSONAnyPtr(fftypes.JSONObject{
+				"address": "0x123",
+			}.String()),
+			Event: &fftypes.FFISerializedEvent{
+				FFIEventDefinition: fftypes.FFIEventDefinition{
+					Name: "changed",
+				},
+			},
+			Options: &fftypes.ContractListenerOptions{
+				FromBlock: &fromBlock,
+			},
+		},
+	}
+
+	mbi.On("AddContractListener", context.Background(), sub).Return(nil)
+	mdi.On("UpsertContractListener", context.Background(), &sub.ContractListener).Return(nil)
+
+	result, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Options.FirstEvent)
+	assert.Equal(t, uint64(1000), *result.Options.FromBlock)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAddContractListenerFromBlockAndFirstEvent(t *testing.T) {
+	cm := newTestContractManager()
+
+	fromBlock := uint64(1000)
+	sub := &fftypes.ContractListenerInput{
+		ContractListener: fftypes.ContractListener{
+			Options: &fftypes.ContractListenerOptions{
+				FirstEvent: string(fftypes.SubOptsFirstEventOldest),
+				FromBlock:  &fromBlock,
+			},
+		},
+	}
+
+	_, err := cm.AddContractListener(context.Background(), "ns", sub)
+	assert.Regexp(t, "FF10435", err)
+}
+
 func TestAddContractListenerByRef(t *testing.T) {
 	cm := newTestContractManager()
 	mbi := cm.blockchain.(*blockchainmocks.Plugin)
@@ -1156,6 +1268,9 @@ func TestInvokeContract(t *testing.T) {
 
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
 	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(&fftypes.FFI{
+		Errors: fftypes.FFIErrors{{Name: "Unauthorized"}},
+	}, nil)
 	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
 		return op.Namespace == "ns1" && op.Type == fftypes.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
 	})).Return(nil)
@@ -1167,6 +1282,7 @@ func TestInvokeContract(t *testing.T) {
 	_, err := cm.InvokeContract(context.Background(), "ns1", req)
 
 	assert.NoError(t, err)
+	assert.Equal(t, "Unauthorized", req.Errors[0].Name)
 
 	mth.AssertExpectations(t)
 	mim.AssertExpectations(t)
@@ -1174,6 +1290,67 @@ func TestInvokeContract(t *testing.T) {
 	mom.AssertExpectations(t)
 }
 
+func TestInvokeContractFailGetFFIErrors(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	req := &fftypes.ContractCallRequest{
+		Type:      fftypes.CallTypeInvoke,
+		Interface: fftypes.NewUUID(),
+		Ledger:    fftypes.JSONAnyPtr(""),
+		Location:  fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name:    "doStuff",
+			ID:      fftypes.NewUUID(),
+			Params:  fftypes.FFIParams{},
+			Returns: fftypes.FFIParams{},
+		},
+	}
+
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(nil, fmt.Errorf("pop"))
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
+func TestInvokeContractErrorsSupplied(t *testing.T) {
+	cm := newTestContractManager()
+	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
+	mth := cm.txHelper.(*txcommonmocks.Helper)
+	mom := cm.operations.(*operationmocks.Manager)
+
+	req := &fftypes.ContractCallRequest{
+		Type:     fftypes.CallTypeInvoke,
+		Ledger:   fftypes.JSONAnyPtr(""),
+		Location: fftypes.JSONAnyPtr(""),
+		Method: &fftypes.FFIMethod{
+			Name:    "doStuff",
+			Params:  fftypes.FFIParams{},
+			Returns: fftypes.FFIParams{},
+		},
+		Errors: fftypes.FFIErrors{{Name: "Unauthorized"}},
+	}
+
+	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
+	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(nil)
+	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		data := op.Data.(blockchainInvokeData)
+		return data.Request.Errors[0].Name == "Unauthorized"
+	})).Return(nil)
+
+	_, err := cm.InvokeContract(context.Background(), "ns1", req)
+
+	assert.NoError(t, err)
+	mdi.AssertNotCalled(t, "GetFFIByID", mock.Anything, mock.Anything)
+	mom.AssertExpectations(t)
+}
+
 func TestInvokeContractFail(t *testing.T) {
 	cm := newTestContractManager()
 	mim := cm.identity.(*identitymanagermocks.Manager)
@@ -1196,6 +1373,7 @@ func TestInvokeContractFail(t *testing.T) {
 
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
 	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(&fftypes.FFI{}, nil)
 	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
 		return op.Namespace == "ns1" && op.Type == fftypes.OpTypeBlockchainInvoke && op.Plugin == "mockblockchain"
 	})).Return(nil)
@@ -1245,7 +1423,7 @@ func TestInvokeContractFailResolve(t *testing.T) {
 	}
 
 	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
-	mbi.On("InvokeContract", mock.Anything, mock.AnythingOfType("*fftypes.UUID"), "key-resolved", req.Location, req.Method, req.Input).Return(nil)
+	mbi.On("InvokeContract", mock.Anything, mock.AnythingOfType("*fftypes.UUID"), "key-resolved", req.Location, req.Method, req.Input, req.Errors).Return(nil)
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req)
 
@@ -1255,6 +1433,7 @@ func TestInvokeContractFailResolve(t *testing.T) {
 func TestInvokeContractTXFail(t *testing.T) {
 	cm := newTestContractManager()
 	mim := cm.identity.(*identitymanagermocks.Manager)
+	mdi := cm.database.(*databasemocks.Plugin)
 	mth := cm.txHelper.(*txcommonmocks.Helper)
 
 	req := &fftypes.ContractCallRequest{
@@ -1271,6 +1450,7 @@ func TestInvokeContractTXFail(t *testing.T) {
 	}
 
 	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdi.On("GetFFIByID", mock.Anything, req.Interface).Return(&fftypes.FFI{}, nil)
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(nil, fmt.Errorf("pop"))
 
 	_, err := cm.InvokeContract(context.Background(), "ns1", req)
@@ -1477,6 +1657,69 @@ func TestGetContractListenerNotFound(t *testing.T) {
 	assert.Regexp(t, "FF10109", err)
 }
 
+func TestGetContractListenerBackfill(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	fromBlock := uint64(1000)
+	listener := &fftypes.ContractListener{
+		ID:         fftypes.NewUUID(),
+		Options:    &fftypes.ContractListenerOptions{FromBlock: &fromBlock},
+		Checkpoint: "000000001234/000001/000002",
+	}
+	totalCount := int64(42)
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(listener, nil)
+	mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return([]*fftypes.BlockchainEvent{}, &database.FilterResult{
+		TotalCount: &totalCount,
+	}, nil)
+
+	result, err := cm.GetContractListenerByNameOrID(context.Background(), "ns", "sub1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), result.Backfill.FromBlock)
+	assert.Equal(t, uint64(1234), *result.Backfill.CurrentBlock)
+	assert.Equal(t, int64(42), result.Backfill.Events)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetContractListenerBackfillNoEvents(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	fromBlock := uint64(1000)
+	listener := &fftypes.ContractListener{
+		ID:      fftypes.NewUUID(),
+		Options: &fftypes.ContractListenerOptions{FromBlock: &fromBlock},
+	}
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(listener, nil)
+	mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return([]*fftypes.BlockchainEvent{}, &database.FilterResult{}, nil)
+
+	result, err := cm.GetContractListenerByNameOrID(context.Background(), "ns", "sub1")
+	assert.NoError(t, err)
+	assert.Nil(t, result.Backfill.CurrentBlock)
+	assert.Zero(t, result.Backfill.Events)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetContractListenerBackfillFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	fromBlock := uint64(1000)
+	listener := &fftypes.ContractListener{
+		ID:      fftypes.NewUUID(),
+		Options: &fftypes.ContractListenerOptions{FromBlock: &fromBlock},
+	}
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(listener, nil)
+	mdi.On("GetBlockchainEvents", context.Background(), mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := cm.GetContractListenerByNameOrID(context.Background(), "ns", "sub1")
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
 func TestGetContractListeners(t *testing.T) {
 	cm := newTestContractManager()
 	mdi := cm.database.(*databasemocks.Plugin)
@@ -1532,6 +1775,111 @@ func TestDeleteContractListenerNotFound(t *testing.T) {
 	assert.Regexp(t, "FF10109", err)
 }
 
+func TestRewindContractListener(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID: fftypes.NewUUID(),
+	}
+	blockNumber := uint64(12345)
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+	mdi.On("UpdateContractListenerCheckpoint", context.Background(), sub.ID, "", false).Return(true, nil)
+	mbi.On("RewindContractListener", context.Background(), sub, blockNumber).Return("000000012345", nil)
+	mdi.On("UpdateContractListenerCheckpoint", context.Background(), sub.ID, "000000012345", true).Return(true, nil)
+	mdi.On("GetContractListenerByID", context.Background(), sub.ID).Return(sub, nil)
+
+	res, err := cm.RewindContractListener(context.Background(), "ns", "sub1", &fftypes.ContractListenerRewind{
+		BlockNumber: &blockNumber,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, sub, res)
+
+	mbi.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestRewindContractListenerNoBlock(t *testing.T) {
+	cm := newTestContractManager()
+
+	_, err := cm.RewindContractListener(context.Background(), "ns", "sub1", &fftypes.ContractListenerRewind{})
+	assert.Regexp(t, "FF10397", err)
+}
+
+func TestRewindContractListenerNotFound(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+	blockNumber := uint64(12345)
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(nil, nil)
+
+	_, err := cm.RewindContractListener(context.Background(), "ns", "sub1", &fftypes.ContractListenerRewind{
+		BlockNumber: &blockNumber,
+	})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestRewindContractListenerClearCheckpointFail(t *testing.T) {
+	cm := newTestContractManager()
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID: fftypes.NewUUID(),
+	}
+	blockNumber := uint64(12345)
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+	mdi.On("UpdateContractListenerCheckpoint", context.Background(), sub.ID, "", false).Return(false, fmt.Errorf("pop"))
+
+	_, err := cm.RewindContractListener(context.Background(), "ns", "sub1", &fftypes.ContractListenerRewind{
+		BlockNumber: &blockNumber,
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRewindContractListenerBlockchainFail(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID: fftypes.NewUUID(),
+	}
+	blockNumber := uint64(12345)
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+	mdi.On("UpdateContractListenerCheckpoint", context.Background(), sub.ID, "", false).Return(true, nil)
+	mbi.On("RewindContractListener", context.Background(), sub, blockNumber).Return("", fmt.Errorf("pop"))
+
+	_, err := cm.RewindContractListener(context.Background(), "ns", "sub1", &fftypes.ContractListenerRewind{
+		BlockNumber: &blockNumber,
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRewindContractListenerSetCheckpointFail(t *testing.T) {
+	cm := newTestContractManager()
+	mbi := cm.blockchain.(*blockchainmocks.Plugin)
+	mdi := cm.database.(*databasemocks.Plugin)
+
+	sub := &fftypes.ContractListener{
+		ID: fftypes.NewUUID(),
+	}
+	blockNumber := uint64(12345)
+
+	mdi.On("GetContractListener", context.Background(), "ns", "sub1").Return(sub, nil)
+	mdi.On("UpdateContractListenerCheckpoint", context.Background(), sub.ID, "", false).Return(true, nil)
+	mbi.On("RewindContractListener", context.Background(), sub, blockNumber).Return("000000012345", nil)
+	mdi.On("UpdateContractListenerCheckpoint", context.Background(), sub.ID, "000000012345", true).Return(false, fmt.Errorf("pop"))
+
+	_, err := cm.RewindContractListener(context.Background(), "ns", "sub1", &fftypes.ContractListenerRewind{
+		BlockNumber: &blockNumber,
+	})
+	assert.EqualError(t, err, "pop")
+}
+
 func TestInvokeContractAPI(t *testing.T) {
 	cm := newTestContractManager()
 	mdb := cm.database.(*databasemocks.Plugin)
@@ -1558,6 +1906,7 @@ func TestInvokeContractAPI(t *testing.T) {
 	}
 
 	mim.On("NormalizeSigningKey", mock.Anything, "", identity.KeyNormalizationBlockchainPlugin).Return("key-resolved", nil)
+	mdb.On("GetFFIByID", mock.Anything, api.Interface.ID).Return(&fftypes.FFI{}, nil)
 	mdb.On("GetContractAPIByName", mock.Anything, "ns1", "banana").Return(api, nil)
 	mdb.On("GetFFIMethod", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(&fftypes.FFIMethod{Name: "peel"}, nil)
 	mth.On("SubmitNewTransaction", mock.Anything, "ns1", fftypes.TransactionTypeContractInvoke).Return(fftypes.NewUUID(), nil)
@@ -63,7 +63,7 @@ func (cm *contractManager) RunOperation(ctx context.Context, op *fftypes.Prepare
 	switch data := op.Data.(type) {
 	case blockchainInvokeData:
 		req := data.Request
-		return false, cm.blockchain.InvokeContract(ctx, op.ID, req.Key, req.Location, req.Method, req.Input)
+		return false, cm.blockchain.InvokeContract(ctx, op.ID, req.Key, req.Location, req.Method, req.Input, req.Errors)
 
 	default:
 		return false, i18n.NewError(ctx, i18n.MsgOperationNotSupported)
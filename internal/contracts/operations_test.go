@@ -40,6 +40,9 @@ func TestPrepareAndRunBlockchainInvoke(t *testing.T) {
 		Input: map[string]interface{}{
 			"value": "1",
 		},
+		Errors: fftypes.FFIErrors{
+			{Name: "Unauthorized", Params: fftypes.FFIParams{}},
+		},
 	}
 	err := addBlockchainInvokeInputs(op, req)
 	assert.NoError(t, err)
@@ -49,7 +52,9 @@ func TestPrepareAndRunBlockchainInvoke(t *testing.T) {
 		return loc.String() == req.Location.String()
 	}), mock.MatchedBy(func(method *fftypes.FFIMethod) bool {
 		return method.Name == req.Method.Name
-	}), req.Input).Return(nil)
+	}), req.Input, mock.MatchedBy(func(errors fftypes.FFIErrors) bool {
+		return len(errors) == 1 && errors[0].Name == "Unauthorized"
+	})).Return(nil)
 
 	po, err := cm.PrepareOperation(context.Background(), op)
 	assert.NoError(t, err)
@@ -28,14 +28,18 @@ import (
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/scanning"
 	"github.com/hyperledger/firefly/pkg/sharedstorage"
 )
 
 type blobStore struct {
-	dm            *dataManager
-	sharedstorage sharedstorage.Plugin
-	database      database.Plugin
-	exchange      dataexchange.Plugin
+	dm             *dataManager
+	sharedstorage  sharedstorage.Plugin
+	database       database.Plugin
+	exchange       dataexchange.Plugin
+	scanner        scanning.Plugin
+	chunkSize      int64
+	resumeAttempts int
 }
 
 func (bs *blobStore) uploadVerifyBLOB(ctx context.Context, ns string, id *fftypes.UUID, expectedHash *fftypes.Bytes32, reader io.Reader) (hash *fftypes.Bytes32, written int64, payloadRef string, err error) {
@@ -52,7 +56,14 @@ func (bs *blobStore) uploadVerifyBLOB(ctx context.Context, ns string, id *fftype
 		copyDone <- err
 	}()
 
-	payloadRef, uploadHash, uploadSize, dxErr := bs.exchange.UploadBLOB(ctx, ns, *id, dxReader)
+	var uploadHash *fftypes.Bytes32
+	var uploadSize int64
+	var dxErr error
+	if sp, ok := bs.streamingExchange(); ok {
+		payloadRef, uploadHash, uploadSize, dxErr = bs.uploadStreamed(ctx, sp, ns, *id, dxReader)
+	} else {
+		payloadRef, uploadHash, uploadSize, dxErr = bs.exchange.UploadBLOB(ctx, ns, *id, dxReader)
+	}
 	dxReader.Close()
 	copyErr := <-copyDone
 	if dxErr != nil {
@@ -167,6 +178,9 @@ func (bs *blobStore) CopyBlobPStoDX(ctx context.Context, data *fftypes.Data) (bl
 		PayloadRef: payloadRef,
 		Created:    fftypes.Now(),
 	}
+	if err = bs.ScanBlob(ctx, blob); err != nil {
+		return nil, err
+	}
 	err = bs.database.InsertBlob(ctx, blob)
 	if err != nil {
 		return nil, err
@@ -174,6 +188,36 @@ func (bs *blobStore) CopyBlobPStoDX(ctx context.Context, data *fftypes.Data) (bl
 	return blob, nil
 }
 
+// ScanBlob passes a blob received from the network through the scanning plugin, if one is configured.
+// If a threat is found it is recorded on the blob, which quarantines it - it cannot be downloaded, and
+// the aggregator quarantines any message that refers to it rather than confirming it to applications.
+func (bs *blobStore) ScanBlob(ctx context.Context, blob *fftypes.Blob) error {
+	if bs.scanner == nil {
+		return nil
+	}
+	reader, err := bs.openBlob(ctx, blob.PayloadRef)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	threat, err := bs.scanner.ScanBlob(ctx, reader)
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgBlobScanFailed, blob.Hash)
+	}
+	if threat != "" {
+		log.L(ctx).Errorf("Blob '%s' quarantined - threat '%s' found by the %s scanner", blob.Hash, threat, bs.scanner.Name())
+		blob.Threat = threat
+	}
+	return nil
+}
+
+func (bs *blobStore) openBlob(ctx context.Context, payloadRef string) (io.ReadCloser, error) {
+	if sp, ok := bs.streamingExchange(); ok {
+		return bs.downloadStreamed(ctx, sp, payloadRef)
+	}
+	return bs.exchange.DownloadBLOB(ctx, payloadRef)
+}
+
 func (bs *blobStore) DownloadBLOB(ctx context.Context, ns, dataID string) (*fftypes.Blob, io.ReadCloser, error) {
 
 	if err := fftypes.ValidateFFNameField(ctx, ns, "namespace"); err != nil {
@@ -202,7 +246,10 @@ func (bs *blobStore) DownloadBLOB(ctx context.Context, ns, dataID string) (*ffty
 	if blob == nil {
 		return nil, nil, i18n.NewError(ctx, i18n.MsgBlobNotFound, data.Blob.Hash)
 	}
+	if blob.Threat != "" {
+		return nil, nil, i18n.NewError(ctx, i18n.MsgBlobQuarantined, blob.Hash, blob.Threat)
+	}
 
-	reader, err := bs.exchange.DownloadBLOB(ctx, blob.PayloadRef)
+	reader, err := bs.openBlob(ctx, blob.PayloadRef)
 	return blob, reader, err
 }
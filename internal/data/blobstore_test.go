@@ -29,6 +29,7 @@ import (
 
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/mocks/scanningmocks"
 	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -613,3 +614,133 @@ func TestDownloadBlobBadID(t *testing.T) {
 	assert.Regexp(t, "FF10142", err)
 
 }
+
+func TestCopyBlobPStoDXScanFail(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	payload := []byte(`some data`)
+	var hash fftypes.Bytes32 = sha256.Sum256(payload)
+
+	mpi := dm.sharedstorage.(*sharedstoragemocks.Plugin)
+	mpi.On("RetrieveData", ctx, "public-ref").Return(io.NopCloser(bytes.NewReader(payload)), nil)
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	dxUpload := mdx.On("UploadBLOB", ctx, "ns1", mock.Anything, mock.Anything).Return("/private/loc", &hash, int64(len(payload)), nil)
+	dxUpload.RunFn = func(a mock.Arguments) {
+		_, err := ioutil.ReadAll(a[3].(io.Reader))
+		assert.Nil(t, err)
+	}
+	mdx.On("DownloadBLOB", ctx, "/private/loc").Return(io.NopCloser(bytes.NewReader(payload)), nil)
+
+	msc := &scanningmocks.Plugin{}
+	msc.On("ScanBlob", ctx, mock.Anything).Return("", fmt.Errorf("pop"))
+	dm.scanner = msc
+
+	_, err := dm.CopyBlobPStoDX(ctx, &fftypes.Data{
+		Namespace: "ns1",
+		ID:        fftypes.NewUUID(),
+		Blob: &fftypes.BlobRef{
+			Hash:   &hash,
+			Public: "public-ref",
+		},
+	})
+	assert.Regexp(t, "FF10540.*pop", err)
+
+}
+
+func TestScanBlobNoScanner(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	blob := &fftypes.Blob{Hash: fftypes.NewRandB32(), PayloadRef: "ns1/blob1"}
+	err := dm.ScanBlob(ctx, blob)
+	assert.NoError(t, err)
+	assert.Empty(t, blob.Threat)
+
+}
+
+func TestScanBlobClean(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBLOB", ctx, "ns1/blob1").Return(ioutil.NopCloser(bytes.NewReader([]byte("some blob"))), nil)
+
+	msc := &scanningmocks.Plugin{}
+	msc.On("ScanBlob", ctx, mock.Anything).Return("", nil)
+	dm.scanner = msc
+
+	blob := &fftypes.Blob{Hash: fftypes.NewRandB32(), PayloadRef: "ns1/blob1"}
+	err := dm.ScanBlob(ctx, blob)
+	assert.NoError(t, err)
+	assert.Empty(t, blob.Threat)
+
+	msc.AssertExpectations(t)
+}
+
+func TestScanBlobInfected(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBLOB", ctx, "ns1/blob1").Return(ioutil.NopCloser(bytes.NewReader([]byte("some blob"))), nil)
+
+	msc := &scanningmocks.Plugin{}
+	msc.On("ScanBlob", ctx, mock.Anything).Return("Eicar-Test-Signature", nil)
+	msc.On("Name").Return("utscanner")
+	dm.scanner = msc
+
+	blob := &fftypes.Blob{Hash: fftypes.NewRandB32(), PayloadRef: "ns1/blob1"}
+	err := dm.ScanBlob(ctx, blob)
+	assert.NoError(t, err)
+	assert.Equal(t, "Eicar-Test-Signature", blob.Threat)
+
+	msc.AssertExpectations(t)
+}
+
+func TestScanBlobDownloadFail(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	mdx := dm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("DownloadBLOB", ctx, "ns1/blob1").Return(nil, fmt.Errorf("pop"))
+
+	dm.scanner = &scanningmocks.Plugin{}
+
+	err := dm.ScanBlob(ctx, &fftypes.Blob{Hash: fftypes.NewRandB32(), PayloadRef: "ns1/blob1"})
+	assert.Regexp(t, "pop", err)
+
+}
+
+func TestDownloadBlobQuarantined(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+
+	blobHash := fftypes.NewRandB32()
+	dataID := fftypes.NewUUID()
+
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDataByID", ctx, dataID, false).Return(&fftypes.Data{
+		ID:        dataID,
+		Namespace: "ns1",
+		Blob: &fftypes.BlobRef{
+			Hash: blobHash,
+		},
+	}, nil)
+	mdi.On("GetBlobMatchingHash", ctx, blobHash).Return(&fftypes.Blob{
+		Hash:       blobHash,
+		PayloadRef: "ns1/blob1",
+		Threat:     "Eicar-Test-Signature",
+	}, nil)
+
+	_, _, err := dm.DownloadBLOB(ctx, "ns1", dataID.String())
+	assert.Regexp(t, "FF10541.*Eicar-Test-Signature", err)
+
+}
@@ -0,0 +1,134 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"io"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/dataexchange"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// streamingExchange returns the data exchange plugin as a StreamingPlugin, if it supports chunked transfer of blobs
+func (bs *blobStore) streamingExchange() (dataexchange.StreamingPlugin, bool) {
+	sp, ok := bs.exchange.(dataexchange.StreamingPlugin)
+	return sp, ok && sp.Capabilities().Streaming
+}
+
+func resumeToken(ack *dataexchange.ChunkAck) string {
+	if ack == nil {
+		return ""
+	}
+	return ack.ResumeToken
+}
+
+// uploadStreamed uploads a blob in chunks, re-opening the stream from the last acknowledged chunk if a chunk fails
+func (bs *blobStore) uploadStreamed(ctx context.Context, sp dataexchange.StreamingPlugin, ns string, id fftypes.UUID, reader io.Reader) (payloadRef string, hash *fftypes.Bytes32, size int64, err error) {
+	stream, err := sp.OpenUploadStream(ctx, ns, id, "")
+	if err != nil {
+		return "", nil, -1, err
+	}
+	var lastAck *dataexchange.ChunkAck
+	chunk := make([]byte, bs.chunkSize)
+	for {
+		n, readErr := io.ReadFull(reader, chunk)
+		if n > 0 {
+			failures := 0
+			for {
+				ack, err := stream.SendChunk(ctx, chunk[:n])
+				if err == nil {
+					lastAck = ack
+					break
+				}
+				if failures >= bs.resumeAttempts {
+					return "", nil, -1, err
+				}
+				failures++
+				log.L(ctx).Warnf("Resuming upload of blob %s/%s after chunk failure (attempt=%d): %s", ns, &id, failures, err)
+				if stream, err = sp.OpenUploadStream(ctx, ns, id, resumeToken(lastAck)); err != nil {
+					return "", nil, -1, err
+				}
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return "", nil, -1, readErr
+		}
+	}
+	return stream.Complete(ctx)
+}
+
+// downloadStreamed returns a reader for a blob that is downloaded in chunks
+func (bs *blobStore) downloadStreamed(ctx context.Context, sp dataexchange.StreamingPlugin, payloadRef string) (io.ReadCloser, error) {
+	stream, err := sp.OpenDownloadStream(ctx, payloadRef, "")
+	if err != nil {
+		return nil, err
+	}
+	return &chunkReader{
+		ctx:        ctx,
+		bs:         bs,
+		sp:         sp,
+		payloadRef: payloadRef,
+		stream:     stream,
+	}, nil
+}
+
+// chunkReader reads a blob from a download stream, re-opening the stream after the last acknowledged
+// chunk if reading a chunk fails
+type chunkReader struct {
+	ctx        context.Context
+	bs         *blobStore
+	sp         dataexchange.StreamingPlugin
+	payloadRef string
+	stream     dataexchange.DownloadStream
+	lastAck    *dataexchange.ChunkAck
+	failures   int
+	buf        []byte
+}
+
+func (cr *chunkReader) Read(p []byte) (int, error) {
+	for len(cr.buf) == 0 {
+		chunk, ack, err := cr.stream.NextChunk(cr.ctx)
+		switch {
+		case err == io.EOF:
+			return 0, io.EOF
+		case err != nil:
+			if cr.failures >= cr.bs.resumeAttempts {
+				return 0, err
+			}
+			cr.failures++
+			log.L(cr.ctx).Warnf("Resuming download of blob %s after chunk failure (attempt=%d): %s", cr.payloadRef, cr.failures, err)
+			_ = cr.stream.Close()
+			if cr.stream, err = cr.sp.OpenDownloadStream(cr.ctx, cr.payloadRef, resumeToken(cr.lastAck)); err != nil {
+				return 0, err
+			}
+		default:
+			cr.buf, cr.lastAck, cr.failures = chunk, ack, 0
+		}
+	}
+	n := copy(p, cr.buf)
+	cr.buf = cr.buf[n:]
+	return n, nil
+}
+
+func (cr *chunkReader) Close() error {
+	return cr.stream.Close()
+}
@@ -0,0 +1,211 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package data
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/pkg/dataexchange"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestStreamingDataManager(t *testing.T) (*dataManager, *dataexchangemocks.StreamingPlugin, context.Context, func()) {
+	dm, ctx, cancel := newTestDataManager(t)
+	msp := &dataexchangemocks.StreamingPlugin{}
+	msp.On("Capabilities").Return(&dataexchange.Capabilities{Streaming: true})
+	dm.blobStore.exchange = msp
+	dm.blobStore.chunkSize = 4
+	return dm, msp, ctx, func() {
+		cancel()
+		msp.AssertExpectations(t)
+	}
+}
+
+func TestUploadStreamedResume(t *testing.T) {
+	dm, msp, ctx, done := newTestStreamingDataManager(t)
+	defer done()
+
+	id := fftypes.NewUUID()
+	content := "some blob!"
+	hash := fftypes.HashString(content)
+
+	us1 := &dataexchangemocks.UploadStream{}
+	us1.On("SendChunk", ctx, []byte("some")).Return(&dataexchange.ChunkAck{Offset: 4, ResumeToken: "t1"}, nil)
+	us1.On("SendChunk", ctx, []byte(" blo")).Return(nil, fmt.Errorf("pop"))
+	us2 := &dataexchangemocks.UploadStream{}
+	us2.On("SendChunk", ctx, []byte(" blo")).Return(&dataexchange.ChunkAck{Offset: 8, ResumeToken: "t2"}, nil)
+	us2.On("SendChunk", ctx, []byte("b!")).Return(&dataexchange.ChunkAck{Offset: 10, ResumeToken: "t3"}, nil)
+	us2.On("Complete", ctx).Return("ns1/blob1", hash, int64(10), nil)
+	msp.On("OpenUploadStream", ctx, "ns1", *id, "").Return(us1, nil)
+	msp.On("OpenUploadStream", ctx, "ns1", *id, "t1").Return(us2, nil)
+
+	hashReturned, written, payloadRef, err := dm.uploadVerifyBLOB(ctx, "ns1", id, hash, strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, *hash, *hashReturned)
+	assert.Equal(t, int64(10), written)
+	assert.Equal(t, "ns1/blob1", payloadRef)
+
+	us1.AssertExpectations(t)
+	us2.AssertExpectations(t)
+}
+
+func TestUploadStreamedOpenFail(t *testing.T) {
+	dm, msp, ctx, done := newTestStreamingDataManager(t)
+	defer done()
+
+	msp.On("OpenUploadStream", ctx, "ns1", mock.Anything, "").Return(nil, fmt.Errorf("pop"))
+
+	_, _, _, err := dm.uploadVerifyBLOB(ctx, "ns1", fftypes.NewUUID(), nil, strings.NewReader("some blob"))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestUploadStreamedResumeExhausted(t *testing.T) {
+	dm, msp, ctx, done := newTestStreamingDataManager(t)
+	defer done()
+	dm.blobStore.resumeAttempts = 0
+
+	us := &dataexchangemocks.UploadStream{}
+	us.On("SendChunk", ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	msp.On("OpenUploadStream", ctx, "ns1", mock.Anything, "").Return(us, nil)
+
+	_, _, _, err := dm.uploadVerifyBLOB(ctx, "ns1", fftypes.NewUUID(), nil, strings.NewReader("some blob"))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestUploadStreamedReopenFail(t *testing.T) {
+	dm, msp, ctx, done := newTestStreamingDataManager(t)
+	defer done()
+
+	us := &dataexchangemocks.UploadStream{}
+	us.On("SendChunk", ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	msp.On("OpenUploadStream", ctx, "ns1", mock.Anything, "").Return(us, nil).Once()
+	msp.On("OpenUploadStream", ctx, "ns1", mock.Anything, "").Return(nil, fmt.Errorf("reopen"))
+
+	_, _, _, err := dm.uploadVerifyBLOB(ctx, "ns1", fftypes.NewUUID(), nil, strings.NewReader("some blob"))
+	assert.EqualError(t, err, "reopen")
+}
+
+func TestUploadStreamedReadFail(t *testing.T) {
+	dm, msp, ctx, done := newTestStreamingDataManager(t)
+	defer done()
+
+	us := &dataexchangemocks.UploadStream{}
+	us.On("Complete", ctx).Return("ns1/blob1", fftypes.NewRandB32(), int64(0), nil)
+	msp.On("OpenUploadStream", ctx, "ns1", mock.Anything, "").Return(us, nil)
+
+	_, _, _, err := dm.uploadVerifyBLOB(ctx, "ns1", fftypes.NewUUID(), nil, iotest.ErrReader(fmt.Errorf("pop")))
+	assert.Regexp(t, "FF10217", err)
+
+	_, _, _, err = dm.uploadStreamed(ctx, msp, "ns1", *fftypes.NewUUID(), iotest.ErrReader(fmt.Errorf("pop")))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDownloadBlobStreamedResume(t *testing.T) {
+	dm, msp, ctx, done := newTestStreamingDataManager(t)
+	defer done()
+
+	blobHash := fftypes.NewRandB32()
+	dataID := fftypes.NewUUID()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDataByID", ctx, dataID, false).Return(&fftypes.Data{
+		ID:        dataID,
+		Namespace: "ns1",
+		Blob:      &fftypes.BlobRef{Hash: blobHash},
+	}, nil)
+	mdi.On("GetBlobMatchingHash", ctx, blobHash).Return(&fftypes.Blob{
+		Hash:       blobHash,
+		PayloadRef: "ns1/blob1",
+	}, nil)
+
+	ds1 := &dataexchangemocks.DownloadStream{}
+	ds1.On("NextChunk", ctx).Return([]byte("some "), &dataexchange.ChunkAck{Offset: 5, ResumeToken: "t1"}, nil).Once()
+	ds1.On("NextChunk", ctx).Return(nil, nil, fmt.Errorf("pop"))
+	ds1.On("Close").Return(nil)
+	ds2 := &dataexchangemocks.DownloadStream{}
+	ds2.On("NextChunk", ctx).Return([]byte("blob"), &dataexchange.ChunkAck{Offset: 9, ResumeToken: "t2"}, nil).Once()
+	ds2.On("NextChunk", ctx).Return(nil, nil, io.EOF)
+	ds2.On("Close").Return(nil)
+	msp.On("OpenDownloadStream", ctx, "ns1/blob1", "").Return(ds1, nil)
+	msp.On("OpenDownloadStream", ctx, "ns1/blob1", "t1").Return(ds2, nil)
+
+	_, reader, err := dm.DownloadBLOB(ctx, "ns1", dataID.String())
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "some blob", string(b))
+	assert.NoError(t, reader.Close())
+
+	ds1.AssertExpectations(t)
+	ds2.AssertExpectations(t)
+}
+
+func TestDownloadStreamedOpenFail(t *testing.T) {
+	dm, msp, ctx, done := newTestStreamingDataManager(t)
+	defer done()
+
+	msp.On("OpenDownloadStream", ctx, "ns1/blob1", "").Return(nil, fmt.Errorf("pop"))
+
+	sp, ok := dm.streamingExchange()
+	assert.True(t, ok)
+	_, err := dm.downloadStreamed(ctx, sp, "ns1/blob1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDownloadStreamedResumeExhausted(t *testing.T) {
+	dm, msp, ctx, done := newTestStreamingDataManager(t)
+	defer done()
+	dm.blobStore.resumeAttempts = 0
+
+	ds := &dataexchangemocks.DownloadStream{}
+	ds.On("NextChunk", ctx).Return(nil, nil, fmt.Errorf("pop"))
+	msp.On("OpenDownloadStream", ctx, "ns1/blob1", "").Return(ds, nil)
+
+	sp, ok := dm.streamingExchange()
+	assert.True(t, ok)
+	reader, err := dm.downloadStreamed(ctx, sp, "ns1/blob1")
+	assert.NoError(t, err)
+	_, err = ioutil.ReadAll(reader)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDownloadStreamedReopenFail(t *testing.T) {
+	dm, msp, ctx, done := newTestStreamingDataManager(t)
+	defer done()
+
+	ds := &dataexchangemocks.DownloadStream{}
+	ds.On("NextChunk", ctx).Return(nil, nil, fmt.Errorf("pop"))
+	ds.On("Close").Return(nil)
+	msp.On("OpenDownloadStream", ctx, "ns1/blob1", "").Return(ds, nil).Once()
+	msp.On("OpenDownloadStream", ctx, "ns1/blob1", "").Return(nil, fmt.Errorf("reopen"))
+
+	sp, ok := dm.streamingExchange()
+	assert.True(t, ok)
+	reader, err := dm.downloadStreamed(ctx, sp, "ns1/blob1")
+	assert.NoError(t, err)
+	_, err = ioutil.ReadAll(reader)
+	assert.EqualError(t, err, "reopen")
+}
@@ -18,35 +18,42 @@ package data
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/edi"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/scanning"
 	"github.com/hyperledger/firefly/pkg/sharedstorage"
 	"github.com/karlseguin/ccache"
 )
 
 type Manager interface {
 	CheckDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype) error
-	ValidateAll(ctx context.Context, data fftypes.DataArray) (valid bool, err error)
+	CheckDatatypes(ctx context.Context, ns string, datatypes []*fftypes.Datatype) error
+	ValidateAll(ctx context.Context, data fftypes.DataArray) (valid bool, reason string, err error)
 	GetMessageWithDataCached(ctx context.Context, msgID *fftypes.UUID, options ...CacheReadOption) (msg *fftypes.Message, data fftypes.DataArray, foundAllData bool, err error)
 	GetMessageDataCached(ctx context.Context, msg *fftypes.Message, options ...CacheReadOption) (data fftypes.DataArray, foundAll bool, err error)
 	PeekMessageCache(ctx context.Context, id *fftypes.UUID, options ...CacheReadOption) (msg *fftypes.Message, data fftypes.DataArray)
 	UpdateMessageCache(msg *fftypes.Message, data fftypes.DataArray)
 	UpdateMessageIfCached(ctx context.Context, msg *fftypes.Message)
 	ResolveInlineData(ctx context.Context, msg *NewMessage) error
+	ApplyMessageTemplate(ctx context.Context, in *fftypes.MessageInOut) error
 	WriteNewMessage(ctx context.Context, newMsg *NewMessage) error
 	VerifyNamespaceExists(ctx context.Context, ns string) error
 
 	UploadJSON(ctx context.Context, ns string, inData *fftypes.DataRefOrValue) (*fftypes.Data, error)
 	UploadBLOB(ctx context.Context, ns string, inData *fftypes.DataRefOrValue, blob *fftypes.Multipart, autoMeta bool) (*fftypes.Data, error)
+	UploadEDI(ctx context.Context, ns string, raw string) (fftypes.DataArray, error)
 	CopyBlobPStoDX(ctx context.Context, data *fftypes.Data) (blob *fftypes.Blob, err error)
+	ScanBlob(ctx context.Context, blob *fftypes.Blob) error
 	DownloadBLOB(ctx context.Context, ns, dataID string) (*fftypes.Blob, io.ReadCloser, error)
 	HydrateBatch(ctx context.Context, persistedBatch *fftypes.BatchPersisted) (*fftypes.Batch, error)
 	WaitStop()
@@ -91,7 +98,8 @@ const (
 	CRORequireBatchID
 )
 
-func NewDataManager(ctx context.Context, di database.Plugin, pi sharedstorage.Plugin, dx dataexchange.Plugin) (Manager, error) {
+// NewDataManager creates the data manager. The scanning plugin is optional, and is nil unless blob scanning is configured
+func NewDataManager(ctx context.Context, di database.Plugin, pi sharedstorage.Plugin, dx dataexchange.Plugin, sc scanning.Plugin) (Manager, error) {
 	if di == nil || pi == nil || dx == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
@@ -102,10 +110,13 @@ func NewDataManager(ctx context.Context, di database.Plugin, pi sharedstorage.Pl
 		messageCacheTTL:   config.GetDuration(config.MessageCacheTTL),
 	}
 	dm.blobStore = blobStore{
-		dm:            dm,
-		database:      di,
-		sharedstorage: pi,
-		exchange:      dx,
+		dm:             dm,
+		database:       di,
+		sharedstorage:  pi,
+		exchange:       dx,
+		scanner:        sc,
+		chunkSize:      config.GetByteSize(config.DataexchangeStreamingChunkSize),
+		resumeAttempts: config.GetInt(config.DataexchangeStreamingResumeAttempts),
 	}
 	dm.validatorCache = ccache.New(
 		// We use a LRU cache with a size-aware max
@@ -127,10 +138,32 @@ func NewDataManager(ctx context.Context, di database.Plugin, pi sharedstorage.Pl
 }
 
 func (dm *dataManager) CheckDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype) error {
-	_, err := newJSONValidator(ctx, ns, datatype)
+	_, err := newJSONValidator(ctx, ns, datatype, dm.resolveDatatype(ns, nil))
 	return err
 }
 
+// CheckDatatypes checks a set of datatypes in order, where each can reference the datatypes before it in the set
+func (dm *dataManager) CheckDatatypes(ctx context.Context, ns string, datatypes []*fftypes.Datatype) error {
+	for i, datatype := range datatypes {
+		if _, err := newJSONValidator(ctx, ns, datatype, dm.resolveDatatype(ns, datatypes[0:i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveDatatype resolves references to datatypes from a set of datatypes yet to be stored, or from the database
+func (dm *dataManager) resolveDatatype(ns string, pending []*fftypes.Datatype) datatypeResolver {
+	return func(ctx context.Context, ref *fftypes.DatatypeRef) (*fftypes.Datatype, error) {
+		for _, datatype := range pending {
+			if datatype.Name == ref.Name && datatype.Version == ref.Version {
+				return datatype, nil
+			}
+		}
+		return dm.database.GetDatatypeByName(ctx, ns, ref.Name, ref.Version)
+	}
+}
+
 func (dm *dataManager) VerifyNamespaceExists(ctx context.Context, ns string) error {
 	err := fftypes.ValidateFFNameField(ctx, ns, "namespace")
 	if err != nil {
@@ -170,7 +203,7 @@ func (dm *dataManager) getValidatorForDatatype(ctx context.Context, ns string, v
 	if datatype == nil {
 		return nil, nil
 	}
-	v, err := newJSONValidator(ctx, ns, datatype)
+	v, err := newJSONValidator(ctx, ns, datatype, dm.resolveDatatype(ns, nil))
 	if err != nil {
 		log.L(ctx).Errorf("Invalid validator stored for '%s:%s:%s': %s", validator, ns, datatypeRef, err)
 		return nil, nil
@@ -300,24 +333,35 @@ func (dm *dataManager) getMessageData(ctx context.Context, msg *fftypes.Message)
 	return data, foundAll, nil
 }
 
-func (dm *dataManager) ValidateAll(ctx context.Context, data fftypes.DataArray) (valid bool, err error) {
+// ValidateAll checks the hash of each data item matches its content, and validates the value against its datatype.
+// For data that fails these checks, valid is returned false along with the reason - for example a value that does
+// not conform to the schema, or a datatype that is not known.
+// An error is returned only for failures that can be retried, such as a failure to read the datatype.
+func (dm *dataManager) ValidateAll(ctx context.Context, data fftypes.DataArray) (valid bool, reason string, err error) {
 	for _, d := range data {
+		hash, err := d.CalcHash(ctx)
+		if err != nil {
+			return false, err.Error(), nil
+		}
+		if !hash.Equals(d.Hash) {
+			log.L(ctx).Errorf("Data %s hash mismatch: computed=%s stored=%s", d.ID, hash, d.Hash)
+			return false, i18n.NewError(ctx, i18n.MsgDataHashInvalid, hash, d.Hash).Error(), nil
+		}
 		if d.Datatype != nil && d.Validator != fftypes.ValidatorTypeNone {
 			v, err := dm.getValidatorForDatatype(ctx, d.Namespace, d.Validator, d.Datatype)
 			if err != nil {
-				return false, err
+				return false, "", err
 			}
 			if v == nil {
 				log.L(ctx).Errorf("Datatype %s:%s:%s not found", d.Validator, d.Namespace, d.Datatype)
-				return false, err
+				return false, i18n.NewError(ctx, i18n.MsgDatatypeNotFound, d.Datatype).Error(), nil
 			}
-			err = v.ValidateValue(ctx, d.Value, d.Hash)
-			if err != nil {
-				return false, err
+			if invalid := v.ValidateValue(ctx, d.Value, d.Hash); invalid != nil {
+				return false, invalid.Error(), nil
 			}
 		}
 	}
-	return true, nil
+	return true, "", nil
 }
 
 func (dm *dataManager) resolveRef(ctx context.Context, ns string, dataRef *fftypes.DataRef) (*fftypes.Data, error) {
@@ -350,6 +394,9 @@ func (dm *dataManager) resolveBlob(ctx context.Context, blobRef *fftypes.BlobRef
 		if blob == nil {
 			return nil, i18n.NewError(ctx, i18n.MsgBlobNotFound, blobRef.Hash)
 		}
+		if blob.Threat != "" {
+			return nil, i18n.NewError(ctx, i18n.MsgBlobQuarantined, blob.Hash, blob.Threat)
+		}
 		return blob, nil
 	}
 	return nil, nil
@@ -426,6 +473,33 @@ func (dm *dataManager) UploadJSON(ctx context.Context, ns string, inData *fftype
 	return data, err
 }
 
+// UploadEDI parses an X12 or EDIFACT interchange, and stores each transaction set / message within it
+// as a separate data record. The records are tagged with a datatype for the document type (such as
+// x12_850 or edifact_ORDERS), without validation - so the datatypes do not need to be defined.
+func (dm *dataManager) UploadEDI(ctx context.Context, ns string, raw string) (fftypes.DataArray, error) {
+	docs, err := edi.Parse(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	data := make(fftypes.DataArray, len(docs))
+	for i, doc := range docs {
+		b, _ := json.Marshal(doc)
+		if data[i], err = dm.validateInputData(ctx, ns, &fftypes.DataRefOrValue{
+			Validator: fftypes.ValidatorTypeNone,
+			Datatype:  doc.Datatype(),
+			Value:     fftypes.JSONAnyPtrBytes(b),
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, d := range data {
+		if err = dm.messageWriter.WriteData(ctx, d); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
 // ResolveInlineData processes an input message that is going to be stored, to see which of the data
 // elements are new, and which are existing. It verifies everything that points to an existing
 // reference, and returns a list of what data is new separately - so that it can be stored by the
@@ -468,10 +542,49 @@ func (dm *dataManager) ResolveInlineData(ctx context.Context, newMessage *NewMes
 
 	}
 	newMessage.Message.Data = newMessage.AllData.Refs()
+	return dm.resolveAttachments(ctx, msg, newMessage.AllData)
+}
+
+// resolveAttachments checks each entry in the attachments manifest of a message refers to a blob that is
+// attached to the message data, and fills in the size from the blob where it was not supplied
+func (dm *dataManager) resolveAttachments(ctx context.Context, msg *fftypes.MessageInOut, data fftypes.DataArray) error {
+	if err := msg.Header.Attachments.Validate(ctx); err != nil {
+		return err
+	}
+	blobs := make(map[fftypes.Bytes32]*fftypes.BlobRef)
+	for _, d := range data {
+		if d.Blob != nil && d.Blob.Hash != nil {
+			blobs[*d.Blob.Hash] = d.Blob
+		}
+	}
+	for _, a := range msg.Header.Attachments {
+		blob := blobs[*a.Hash]
+		switch {
+		case blob == nil:
+			return i18n.NewError(ctx, i18n.MsgAttachmentBlobNotInMessage, a.Filename, a.Hash)
+		case a.Size == 0:
+			a.Size = blob.Size
+		case a.Size != blob.Size:
+			return i18n.NewError(ctx, i18n.MsgAttachmentSizeMismatch, a.Filename, a.Size, a.Hash, blob.Size)
+		}
+	}
 	return nil
 }
 
 // HydrateBatch fetches the full messages for a persisted batch, ready for transmission
+// ApplyMessageTemplate fills in the fields of the message that are not set, from the template it references
+func (dm *dataManager) ApplyMessageTemplate(ctx context.Context, in *fftypes.MessageInOut) error {
+	template, err := dm.database.GetMessageTemplateByName(ctx, in.Header.Namespace, in.Template)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		return i18n.NewError(ctx, i18n.MsgMessageTemplateNotFound, in.Template)
+	}
+	template.Apply(in)
+	return nil
+}
+
 func (dm *dataManager) HydrateBatch(ctx context.Context, persistedBatch *fftypes.BatchPersisted) (*fftypes.Batch, error) {
 
 	var manifest fftypes.BatchManifest
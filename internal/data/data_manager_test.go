@@ -41,7 +41,7 @@ func newTestDataManager(t *testing.T) (*dataManager, context.Context, func()) {
 	})
 	mdx := &dataexchangemocks.Plugin{}
 	mps := &sharedstoragemocks.Plugin{}
-	dm, err := NewDataManager(ctx, mdi, mps, mdx)
+	dm, err := NewDataManager(ctx, mdi, mps, mdx, nil)
 	assert.NoError(t, err)
 	return dm.(*dataManager), ctx, func() {
 		cancel()
@@ -99,9 +99,10 @@ func TestValidateE2E(t *testing.T) {
 		Version:   "0.0.1",
 	}
 	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "customer", "0.0.1").Return(dt, nil)
-	isValid, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
-	assert.Regexp(t, "FF10198", err)
-	assert.False(t, isValid)
+	valid, reason, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+	assert.Regexp(t, "FF10198", reason)
 
 	v, err := dm.getValidatorForDatatype(ctx, data.Namespace, data.Validator, data.Datatype)
 	err = v.Validate(ctx, data)
@@ -112,9 +113,10 @@ func TestValidateE2E(t *testing.T) {
 	err = v.Validate(ctx, data)
 	assert.NoError(t, err)
 
-	isValid, err = dm.ValidateAll(ctx, fftypes.DataArray{data})
+	valid, reason, err = dm.ValidateAll(ctx, fftypes.DataArray{data})
 	assert.NoError(t, err)
-	assert.True(t, isValid)
+	assert.True(t, valid)
+	assert.Empty(t, reason)
 
 }
 
@@ -211,7 +213,7 @@ func TestWriteNewMessageE2E(t *testing.T) {
 }
 
 func TestInitBadDeps(t *testing.T) {
-	_, err := NewDataManager(context.Background(), nil, nil, nil)
+	_, err := NewDataManager(context.Background(), nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 }
 
@@ -267,8 +269,10 @@ func TestValidateBadHash(t *testing.T) {
 		Namespace: "0.0.1",
 	}
 	mdi.On("GetDatatypeByName", mock.Anything, "ns1", "customer", "0.0.1").Return(dt, nil).Once()
-	_, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
-	assert.Regexp(t, "FF10201", err)
+	valid, reason, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+	assert.Regexp(t, "FF10519", reason)
 
 }
 
@@ -380,6 +384,53 @@ func TestCheckDatatypeVerifiesTheSchema(t *testing.T) {
 	assert.Regexp(t, "FF10196", err)
 }
 
+func TestCheckDatatypesWithReferences(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", ctx, "ns1", "country", "1.0").Return(&fftypes.Datatype{
+		Name:    "country",
+		Version: "1.0",
+		Value:   fftypes.JSONAnyPtr(`{"type": "string"}`),
+	}, nil)
+
+	err := dm.CheckDatatypes(ctx, "ns1", []*fftypes.Datatype{
+		{
+			Name:    "address",
+			Version: "1.0",
+			Value:   fftypes.JSONAnyPtr(`{"properties": {"country": {"$ref": "ff://datatypes/country/1.0"}}}`),
+		},
+		{
+			Name:    "customer",
+			Version: "1.0",
+			Value:   fftypes.JSONAnyPtr(`{"properties": {"address": {"$ref": "ff://datatypes/address/1.0"}}}`),
+		},
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCheckDatatypesFail(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetDatatypeByName", ctx, "ns1", "address", "1.0").Return(nil, nil)
+
+	err := dm.CheckDatatypes(ctx, "ns1", []*fftypes.Datatype{
+		{
+			Name:    "customer",
+			Version: "1.0",
+			Value:   fftypes.JSONAnyPtr(`{"properties": {"address": {"$ref": "ff://datatypes/address/1.0"}}}`),
+		},
+	})
+	assert.Regexp(t, "FF10512", err)
+
+	mdi.AssertExpectations(t)
+}
+
 func TestResolveInlineDataEmpty(t *testing.T) {
 
 	dm, ctx, cancel := newTestDataManager(t)
@@ -478,6 +529,131 @@ func TestResolveInlineDataResolveBlobFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestResolveInlineDataBlobQuarantined(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	dataID, dataHash, newMsg := testNewMessage()
+	blobHash := fftypes.NewRandB32()
+
+	mdi.On("GetDataByID", ctx, dataID, true).Return(&fftypes.Data{
+		ID:        dataID,
+		Namespace: "ns1",
+		Hash:      dataHash,
+		Blob: &fftypes.BlobRef{
+			Hash: blobHash,
+		},
+	}, nil)
+	mdi.On("GetBlobMatchingHash", ctx, blobHash).Return(&fftypes.Blob{
+		Hash:       blobHash,
+		PayloadRef: "blob/1",
+		Threat:     "Eicar-Test-Signature",
+	}, nil)
+
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.Regexp(t, "FF10541", err)
+}
+
+func TestResolveInlineDataAttachments(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	dataID, dataHash, newMsg := testNewMessage()
+	blobHash := fftypes.NewRandB32()
+	newMsg.Message.Header.Attachments = fftypes.AttachmentManifest{
+		{Filename: "invoice.pdf", MimeType: "application/pdf", Hash: blobHash},
+	}
+
+	mdi.On("GetDataByID", ctx, dataID, true).Return(&fftypes.Data{
+		ID:        dataID,
+		Namespace: "ns1",
+		Hash:      dataHash,
+		Blob: &fftypes.BlobRef{
+			Hash: blobHash,
+			Size: 12345,
+		},
+	}, nil)
+	mdi.On("GetBlobMatchingHash", ctx, blobHash).Return(&fftypes.Blob{
+		Hash:       blobHash,
+		PayloadRef: "blob/1",
+	}, nil)
+
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12345), newMsg.Message.Header.Attachments[0].Size)
+}
+
+func TestResolveInlineDataAttachmentSizeMismatch(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	dataID, dataHash, newMsg := testNewMessage()
+	blobHash := fftypes.NewRandB32()
+	newMsg.Message.Header.Attachments = fftypes.AttachmentManifest{
+		{Filename: "invoice.pdf", Size: 999, Hash: blobHash},
+	}
+
+	mdi.On("GetDataByID", ctx, dataID, true).Return(&fftypes.Data{
+		ID:        dataID,
+		Namespace: "ns1",
+		Hash:      dataHash,
+		Blob: &fftypes.BlobRef{
+			Hash: blobHash,
+			Size: 12345,
+		},
+	}, nil)
+	mdi.On("GetBlobMatchingHash", ctx, blobHash).Return(&fftypes.Blob{
+		Hash:       blobHash,
+		PayloadRef: "blob/1",
+	}, nil)
+
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.Regexp(t, "FF10537", err)
+}
+
+func TestResolveInlineDataAttachmentBlobNotInMessage(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	dataID, dataHash, newMsg := testNewMessage()
+	newMsg.Message.Header.Attachments = fftypes.AttachmentManifest{
+		{Filename: "invoice.pdf", Hash: fftypes.NewRandB32()},
+	}
+
+	mdi.On("GetDataByID", ctx, dataID, true).Return(&fftypes.Data{
+		ID:        dataID,
+		Namespace: "ns1",
+		Hash:      dataHash,
+	}, nil)
+
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.Regexp(t, "FF10536", err)
+}
+
+func TestResolveInlineDataAttachmentInvalid(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+
+	dataID, dataHash, newMsg := testNewMessage()
+	newMsg.Message.Header.Attachments = fftypes.AttachmentManifest{
+		{Filename: "invoice.pdf"},
+	}
+
+	mdi.On("GetDataByID", ctx, dataID, true).Return(&fftypes.Data{
+		ID:        dataID,
+		Namespace: "ns1",
+		Hash:      dataHash,
+	}, nil)
+
+	err := dm.ResolveInlineData(ctx, newMsg)
+	assert.Regexp(t, "FF10140.*hash", err)
+}
+
 func TestResolveInlineDataRefBadNamespace(t *testing.T) {
 	dm, ctx, cancel := newTestDataManager(t)
 	defer cancel()
@@ -645,6 +821,42 @@ func TestUploadJSONLoadInsertDataFail(t *testing.T) {
 	assert.Regexp(t, "FF10158", err)
 }
 
+func TestUploadEDIOk(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("RunAsGroup", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		err := args[1].(func(context.Context) error)(ctx)
+		assert.NoError(t, err)
+	}).Return(nil)
+	mdi.On("InsertDataArray", mock.Anything, mock.Anything).Return(nil)
+
+	data, err := dm.UploadEDI(ctx, "ns1", "UNB+UNOA:1'UNH+1+ORDERS:D:96A:UN'UNT+2+1'UNH+2+INVOIC:D:96A:UN'UNT+2+2'UNZ+2+1'")
+	assert.NoError(t, err)
+	assert.Len(t, data, 2)
+	assert.Equal(t, fftypes.ValidatorTypeNone, data[0].Validator)
+	assert.Equal(t, "edifact_ORDERS", data[0].Datatype.Name)
+	assert.Equal(t, "D96A", data[0].Datatype.Version)
+	assert.Equal(t, "ORDERS", data[0].Value.JSONObject().GetString("type"))
+	assert.Equal(t, "edifact_INVOIC", data[1].Datatype.Name)
+	assert.NotNil(t, data[1].Hash)
+}
+
+func TestUploadEDIParseFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	_, err := dm.UploadEDI(ctx, "ns1", "not edi")
+	assert.Regexp(t, "FF10473", err)
+}
+
+func TestUploadEDIInsertDataFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	dm.messageWriter.close()
+	_, err := dm.UploadEDI(ctx, "ns1", "UNB+UNOA:1'UNH+1+ORDERS'UNT+2+1'")
+	assert.Regexp(t, "FF10158", err)
+}
+
 func TestValidateAndStoreLoadNilRef(t *testing.T) {
 	dm, ctx, cancel := newTestDataManager(t)
 	defer cancel()
@@ -748,7 +960,7 @@ func TestValidateAllLookupError(t *testing.T) {
 		Value: fftypes.JSONAnyPtr(`anything`),
 	}
 	data.Seal(ctx, nil)
-	_, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
+	_, _, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
 	assert.Regexp(t, "pop", err)
 
 }
@@ -777,13 +989,48 @@ func TestValidateAllStoredValidatorInvalid(t *testing.T) {
 			Name:    "customer",
 			Version: "0.0.1",
 		},
+		Value: fftypes.JSONAnyPtr(`{}`),
 	}
-	isValid, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
-	assert.False(t, isValid)
+	data.Seal(ctx, nil)
+	valid, reason, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
+	assert.False(t, valid)
+	assert.Regexp(t, "FF10195", reason)
 	assert.NoError(t, err)
 	mdi.AssertExpectations(t)
 }
 
+func TestValidateAllHashMismatch(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	data := &fftypes.Data{
+		Namespace: "ns1",
+		Value:     fftypes.JSONAnyPtr(`{"some": "data"}`),
+	}
+	data.Seal(ctx, nil)
+	data.Value = fftypes.JSONAnyPtr(`{"some": "tampered"}`)
+	valid, reason, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+	assert.Regexp(t, "FF10519", reason)
+
+}
+
+func TestValidateAllNullValue(t *testing.T) {
+
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	data := &fftypes.Data{
+		Namespace: "ns1",
+		Hash:      fftypes.NewRandB32(),
+	}
+	valid, reason, err := dm.ValidateAll(ctx, fftypes.DataArray{data})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+	assert.Regexp(t, "FF10199", reason)
+
+}
+
 func TestVerifyNamespaceExistsInvalidFFName(t *testing.T) {
 	dm, ctx, cancel := newTestDataManager(t)
 	defer cancel()
@@ -1153,3 +1400,60 @@ func TestWriteNewMessageFailClosed(t *testing.T) {
 	})
 	assert.Regexp(t, "FF10158", err)
 }
+
+func TestApplyMessageTemplate(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageTemplateByName", ctx, "ns1", "template1").Return(&fftypes.MessageTemplate{
+		Header: fftypes.MessageTemplateHeader{Tag: "tag1"},
+	}, nil)
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{Namespace: "ns1"},
+		},
+		Template: "template1",
+	}
+	err := dm.ApplyMessageTemplate(ctx, in)
+	assert.NoError(t, err)
+	assert.Equal(t, "tag1", in.Header.Tag)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestApplyMessageTemplateNotFound(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageTemplateByName", ctx, "ns1", "template1").Return(nil, nil)
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{Namespace: "ns1"},
+		},
+		Template: "template1",
+	}
+	err := dm.ApplyMessageTemplate(ctx, in)
+	assert.Regexp(t, "FF10457", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestApplyMessageTemplateLookupFail(t *testing.T) {
+	dm, ctx, cancel := newTestDataManager(t)
+	defer cancel()
+	mdi := dm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageTemplateByName", ctx, "ns1", "template1").Return(nil, fmt.Errorf("pop"))
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{Namespace: "ns1"},
+		},
+		Template: "template1",
+	}
+	err := dm.ApplyMessageTemplate(ctx, in)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
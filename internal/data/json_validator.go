@@ -19,6 +19,7 @@ package data
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"strings"
 
 	"github.com/hyperledger/firefly/internal/i18n"
@@ -35,7 +36,10 @@ type jsonValidator struct {
 	schema   *jsonschema.Schema
 }
 
-func newJSONValidator(ctx context.Context, ns string, datatype *fftypes.Datatype) (*jsonValidator, error) {
+// datatypeResolver looks up a datatype referenced from a JSON schema, returning nil if it does not exist
+type datatypeResolver func(ctx context.Context, ref *fftypes.DatatypeRef) (*fftypes.Datatype, error)
+
+func newJSONValidator(ctx context.Context, ns string, datatype *fftypes.Datatype, resolver datatypeResolver) (*jsonValidator, error) {
 	jv := &jsonValidator{
 		id: datatype.ID,
 		ns: ns,
@@ -51,6 +55,20 @@ func newJSONValidator(ctx context.Context, ns string, datatype *fftypes.Datatype
 	}
 	c := jsonschema.NewCompiler()
 	c.Draft = jsonschema.Draft2020
+	c.LoadURL = func(url string) (io.ReadCloser, error) {
+		ref := fftypes.ParseDatatypeRefURL(url)
+		if ref == nil {
+			return jsonschema.LoadURL(url)
+		}
+		refDatatype, err := resolver(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		if refDatatype == nil {
+			return nil, i18n.NewError(ctx, i18n.MsgDatatypeRefNotFound, ref)
+		}
+		return io.NopCloser(strings.NewReader(refDatatype.Value.String())), nil
+	}
 	err := c.AddResource(datatype.Name, strings.NewReader(datatype.Value.String()))
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgSchemaLoadFailed, jv.datatype)
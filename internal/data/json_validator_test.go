@@ -18,6 +18,7 @@ package data
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -42,7 +43,7 @@ func TestJSONValidator(t *testing.T) {
 		Value:     fftypes.JSONAnyPtrBytes(schemaBinary),
 	}
 
-	jv, err := newJSONValidator(context.Background(), "ns1", dt)
+	jv, err := newJSONValidator(context.Background(), "ns1", dt, nil)
 	assert.NoError(t, err)
 
 	err = jv.validateJSONString(context.Background(), `{}`)
@@ -67,7 +68,7 @@ func TestJSONValidatorParseSchemaFail(t *testing.T) {
 		Value:     fftypes.JSONAnyPtr(`{!json`),
 	}
 
-	_, err := newJSONValidator(context.Background(), "ns1", dt)
+	_, err := newJSONValidator(context.Background(), "ns1", dt, nil)
 	assert.Regexp(t, "FF10196", err)
 
 }
@@ -79,3 +80,88 @@ func TestJSONValidatorNilData(t *testing.T) {
 	assert.Regexp(t, "FF10199", err)
 
 }
+
+func TestJSONValidatorDatatypeRef(t *testing.T) {
+
+	address := &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeJSON,
+		Name:      "address",
+		Version:   "0.0.1",
+		Value: fftypes.JSONAnyPtr(`{
+			"properties": {
+				"street": {"type": "string"}
+			},
+			"required": ["street"]
+		}`),
+	}
+	dt := &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeJSON,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value: fftypes.JSONAnyPtr(`{
+			"properties": {
+				"address": {"$ref": "ff://datatypes/address/0.0.1"}
+			},
+			"required": ["address"]
+		}`),
+	}
+
+	jv, err := newJSONValidator(context.Background(), "ns1", dt, func(ctx context.Context, ref *fftypes.DatatypeRef) (*fftypes.Datatype, error) {
+		assert.Equal(t, "address/0.0.1", ref.String())
+		return address, nil
+	})
+	assert.NoError(t, err)
+
+	err = jv.validateJSONString(context.Background(), `{"address": {}}`)
+	assert.Regexp(t, "FF10198.*street", err)
+
+	err = jv.validateJSONString(context.Background(), `{"address": {"street": "1 Main St"}}`)
+	assert.NoError(t, err)
+
+}
+
+func TestJSONValidatorDatatypeRefNotFound(t *testing.T) {
+
+	dt := &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeJSON,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtr(`{"$ref": "ff://datatypes/address/0.0.1"}`),
+	}
+
+	_, err := newJSONValidator(context.Background(), "ns1", dt, func(ctx context.Context, ref *fftypes.DatatypeRef) (*fftypes.Datatype, error) {
+		return nil, nil
+	})
+	assert.Regexp(t, "FF10196.*FF10512", err)
+
+}
+
+func TestJSONValidatorDatatypeRefFail(t *testing.T) {
+
+	dt := &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeJSON,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtr(`{"$ref": "ff://datatypes/address/0.0.1"}`),
+	}
+
+	_, err := newJSONValidator(context.Background(), "ns1", dt, func(ctx context.Context, ref *fftypes.DatatypeRef) (*fftypes.Datatype, error) {
+		return nil, fmt.Errorf("pop")
+	})
+	assert.Regexp(t, "FF10196.*pop", err)
+
+}
+
+func TestJSONValidatorOtherRef(t *testing.T) {
+
+	dt := &fftypes.Datatype{
+		Validator: fftypes.ValidatorTypeJSON,
+		Name:      "customer",
+		Version:   "0.0.1",
+		Value:     fftypes.JSONAnyPtr(`{"$ref": "unknown://other"}`),
+	}
+
+	_, err := newJSONValidator(context.Background(), "ns1", dt, nil)
+	assert.Regexp(t, "FF10196", err)
+
+}
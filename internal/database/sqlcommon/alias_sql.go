@@ -0,0 +1,202 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	aliasColumns = []string{
+		"id",
+		"namespace",
+		"name",
+		"description",
+		"identity",
+		"key",
+		"created",
+		"updated",
+	}
+	aliasFilterFieldMap = map[string]string{}
+)
+
+func (s *SQLCommon) UpsertAlias(ctx context.Context, alias *fftypes.Alias) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	// Do a select within the transaction to determine if the name is already in use
+	aliasRows, _, err := s.queryTx(ctx, tx,
+		sq.Select("id", "created").
+			From("aliases").
+			Where(sq.Eq{
+				"namespace": alias.Namespace,
+				"name":      alias.Name,
+			}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := aliasRows.Next()
+	if existing {
+		alias.ID = &fftypes.UUID{}
+		alias.Created = &fftypes.FFTime{}
+		_ = aliasRows.Scan(alias.ID, alias.Created)
+	}
+	aliasRows.Close()
+
+	if existing {
+		alias.Updated = fftypes.Now()
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("aliases").
+				// Note we do not update ID or created
+				Set("description", alias.Description).
+				Set("identity", alias.Identity).
+				Set("key", alias.Key).
+				Set("updated", alias.Updated).
+				Where(sq.Eq{"id": alias.ID}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionAliases, fftypes.ChangeEventTypeUpdated, alias.Namespace, alias.ID)
+			},
+		); err != nil {
+			return err
+		}
+	} else {
+		alias.ID = fftypes.NewUUID()
+		alias.Created = fftypes.Now()
+		alias.Updated = nil
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("aliases").
+				Columns(aliasColumns...).
+				Values(
+					alias.ID,
+					alias.Namespace,
+					alias.Name,
+					alias.Description,
+					alias.Identity,
+					alias.Key,
+					alias.Created,
+					alias.Updated,
+				),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionAliases, fftypes.ChangeEventTypeCreated, alias.Namespace, alias.ID)
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) aliasResult(ctx context.Context, row *sql.Rows) (*fftypes.Alias, error) {
+	var alias fftypes.Alias
+	err := row.Scan(
+		&alias.ID,
+		&alias.Namespace,
+		&alias.Name,
+		&alias.Description,
+		&alias.Identity,
+		&alias.Key,
+		&alias.Created,
+		&alias.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "aliases")
+	}
+	return &alias, nil
+}
+
+func (s *SQLCommon) getAliasEq(ctx context.Context, eq sq.Eq, textName string) (*fftypes.Alias, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(aliasColumns...).
+			From("aliases").
+			Where(eq),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Alias '%s' not found", textName)
+		return nil, nil
+	}
+
+	return s.aliasResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetAliasByName(ctx context.Context, ns, name string) (*fftypes.Alias, error) {
+	return s.getAliasEq(ctx, sq.Eq{"namespace": ns, "name": name}, fmt.Sprintf("%s:%s", ns, name))
+}
+
+func (s *SQLCommon) GetAliases(ctx context.Context, filter database.Filter) ([]*fftypes.Alias, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(aliasColumns...).From("aliases"),
+		filter, aliasFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	aliases := []*fftypes.Alias{}
+	for rows.Next() {
+		q, err := s.aliasResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		aliases = append(aliases, q)
+	}
+
+	return aliases, s.queryRes(ctx, tx, "aliases", fop, fi), err
+}
+
+func (s *SQLCommon) DeleteAlias(ctx context.Context, id *fftypes.UUID) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	alias, err := s.getAliasEq(ctx, sq.Eq{"id": id}, id.String())
+	if err == nil && alias != nil {
+		err = s.deleteTx(ctx, tx, sq.Delete("aliases").Where(sq.Eq{"id": id}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionAliases, fftypes.ChangeEventTypeDeleted, alias.Namespace, alias.ID)
+			})
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
@@ -0,0 +1,224 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAliasesE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new alias
+	alias := &fftypes.Alias{
+		Namespace:   "ns1",
+		Name:        "acme",
+		Description: "Acme Corp",
+		Identity:    "did:firefly:org/acme",
+		Key:         "0x12345",
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionAliases, fftypes.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionAliases, fftypes.ChangeEventTypeUpdated, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionAliases, fftypes.ChangeEventTypeDeleted, "ns1", mock.Anything, mock.Anything).Return()
+
+	err := s.UpsertAlias(ctx, alias)
+	assert.NoError(t, err)
+	assert.NotNil(t, alias.ID)
+	assert.NotNil(t, alias.Created)
+	assert.Nil(t, alias.Updated)
+	aliasJson, _ := json.Marshal(&alias)
+
+	// Query back the alias
+	aliasRead, err := s.GetAliasByName(ctx, "ns1", "acme")
+	assert.NoError(t, err)
+	aliasReadJson, _ := json.Marshal(&aliasRead)
+	assert.Equal(t, string(aliasJson), string(aliasReadJson))
+
+	// Replace the alias, keeping the ID
+	alias2 := &fftypes.Alias{
+		Namespace: "ns1",
+		Name:      "acme",
+		Identity:  "did:firefly:org/acme",
+		Key:       "0x67890",
+	}
+	err = s.UpsertAlias(ctx, alias2)
+	assert.NoError(t, err)
+	assert.Equal(t, *alias.ID, *alias2.ID)
+	assert.Equal(t, alias.Created.String(), alias2.Created.String())
+	assert.NotNil(t, alias2.Updated)
+
+	// Query back with a filter
+	fb := database.AliasQueryFactory.NewFilter(ctx)
+	aliases, res, err := s.GetAliases(ctx, fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("identity", "did:firefly:org/acme"),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	alias2Json, _ := json.Marshal(&alias2)
+	aliasReadJson, _ = json.Marshal(aliases[0])
+	assert.Equal(t, string(alias2Json), string(aliasReadJson))
+
+	// Delete the alias
+	err = s.DeleteAlias(ctx, alias.ID)
+	assert.NoError(t, err)
+	aliasRead, err = s.GetAliasByName(ctx, "ns1", "acme")
+	assert.NoError(t, err)
+	assert.Nil(t, aliasRead)
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestUpsertAliasFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertAlias(context.Background(), &fftypes.Alias{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertAliasFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertAlias(context.Background(), &fftypes.Alias{Name: "acme"})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertAliasFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertAlias(context.Background(), &fftypes.Alias{Name: "acme"})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertAliasFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).
+		AddRow(fftypes.NewUUID(), fftypes.Now()))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertAlias(context.Background(), &fftypes.Alias{Name: "acme"})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertAliasFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertAlias(context.Background(), &fftypes.Alias{Name: "acme"})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAliasByNameSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetAliasByName(context.Background(), "ns1", "acme")
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAliasByNameNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(aliasColumns))
+	alias, err := s.GetAliasByName(context.Background(), "ns1", "acme")
+	assert.NoError(t, err)
+	assert.Nil(t, alias)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAliasByNameScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetAliasByName(context.Background(), "ns1", "acme")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAliasesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.AliasQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetAliases(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAliasesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.AliasQueryFactory.NewFilter(context.Background()).Eq("name", map[bool]bool{true: false})
+	_, _, err := s.GetAliases(context.Background(), f)
+	assert.Regexp(t, "FF10149.*name", err)
+}
+
+func TestGetAliasesScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.AliasQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetAliases(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteAliasBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteAlias(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeleteAliasSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteAlias(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+}
+
+func TestDeleteAliasFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(aliasColumns).AddRow(
+		fftypes.NewUUID(), "ns1", "acme", "", "did:firefly:org/acme", "", fftypes.Now(), nil),
+	)
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteAlias(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+}
@@ -34,6 +34,7 @@ var (
 		"peer",
 		"created",
 		"size",
+		"threat",
 	}
 	blobFilterFieldMap = map[string]string{
 		"payloadref": "payload_ref",
@@ -56,6 +57,7 @@ func (s *SQLCommon) InsertBlob(ctx context.Context, blob *fftypes.Blob) (err err
 				blob.Peer,
 				blob.Created,
 				blob.Size,
+				blob.Threat,
 			),
 		nil, // no change events for blobs
 	)
@@ -75,6 +77,7 @@ func (s *SQLCommon) blobResult(ctx context.Context, row *sql.Rows) (*fftypes.Blo
 		&blob.Peer,
 		&blob.Created,
 		&blob.Size,
+		&blob.Threat,
 		&blob.Sequence,
 	)
 	if err != nil {
@@ -43,6 +43,7 @@ func TestBlobsE2EWithDB(t *testing.T) {
 		PayloadRef: fftypes.NewRandB32().String(),
 		Peer:       "peer1",
 		Created:    fftypes.Now(),
+		Threat:     "Eicar-Test-Signature",
 	}
 	err := s.InsertBlob(ctx, blob)
 	assert.NoError(t, err)
@@ -61,6 +62,7 @@ func TestBlobsE2EWithDB(t *testing.T) {
 		fb.Eq("hash", blob.Hash),
 		fb.Eq("payloadref", blob.PayloadRef),
 		fb.Eq("created", blob.Created),
+		fb.Eq("threat", blob.Threat),
 	)
 	blobRes, res, err := s.GetBlobs(ctx, filter.Count(true))
 	assert.NoError(t, err)
@@ -39,6 +39,7 @@ var (
 		"location",
 		"topic",
 		"options",
+		"checkpoint",
 		"created",
 	}
 	contractListenerFilterFieldMap = map[string]string{
@@ -103,6 +104,7 @@ func (s *SQLCommon) UpsertContractListener(ctx context.Context, sub *fftypes.Con
 					sub.Location,
 					sub.Topic,
 					sub.Options,
+					sub.Checkpoint,
 					sub.Created,
 				),
 			func() {
@@ -130,6 +132,7 @@ func (s *SQLCommon) contractListenerResult(ctx context.Context, row *sql.Rows) (
 		&sub.Location,
 		&sub.Topic,
 		&sub.Options,
+		&sub.Checkpoint,
 		&sub.Created,
 	)
 	if err != nil {
@@ -200,6 +203,30 @@ func (s *SQLCommon) GetContractListeners(ctx context.Context, filter database.Fi
 	return subs, s.queryRes(ctx, tx, "contractlisteners", fop, fi), err
 }
 
+func (s *SQLCommon) UpdateContractListenerCheckpoint(ctx context.Context, id *fftypes.UUID, checkpoint string, advanceOnly bool) (advanced bool, err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	var where sq.Sqlizer = sq.Eq{"id": id}
+	if advanceOnly {
+		where = sq.And{where, sq.Lt{"checkpoint": checkpoint}}
+	}
+	updated, err := s.updateTx(ctx, tx,
+		sq.Update("contractlisteners").
+			Set("checkpoint", checkpoint).
+			Where(where),
+		nil, /* no change events for checkpoint updates */
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return updated > 0, s.commitTx(ctx, tx, autoCommit)
+}
+
 func (s *SQLCommon) DeleteContractListenerByID(ctx context.Context, id *fftypes.UUID) (err error) {
 	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
 	if err != nil {
@@ -112,6 +112,27 @@ func TestContractListenerE2EWithDB(t *testing.T) {
 	subReadJson, _ = json.Marshal(subs[0])
 	assert.Equal(t, string(subJson), string(subReadJson))
 
+	// Advance the checkpoint, and check it cannot move backwards
+	advanced, err := s.UpdateContractListenerCheckpoint(ctx, sub.ID, "000000000002/000000/000000", true)
+	assert.NoError(t, err)
+	assert.True(t, advanced)
+	advanced, err = s.UpdateContractListenerCheckpoint(ctx, sub.ID, "000000000001/000000/000000", true)
+	assert.NoError(t, err)
+	assert.False(t, advanced)
+	subRead, err = s.GetContractListenerByID(ctx, sub.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "000000000002/000000/000000", subRead.Checkpoint)
+
+	// Rewind the checkpoint, and check an upsert does not overwrite it
+	advanced, err = s.UpdateContractListenerCheckpoint(ctx, sub.ID, "000000000001", false)
+	assert.NoError(t, err)
+	assert.True(t, advanced)
+	err = s.UpsertContractListener(ctx, sub)
+	assert.NoError(t, err)
+	subRead, err = s.GetContractListenerByID(ctx, sub.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "000000000001", subRead.Checkpoint)
+
 	// Test delete, and refind no return
 	err = s.DeleteContractListenerByID(ctx, sub.ID)
 	assert.NoError(t, err)
@@ -220,6 +241,24 @@ func TestGetContractListenersScanFail(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpdateContractListenerCheckpointBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	_, err := s.UpdateContractListenerCheckpoint(context.Background(), fftypes.NewUUID(), "000000000001", true)
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateContractListenerCheckpointFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	_, err := s.UpdateContractListenerCheckpoint(context.Background(), fftypes.NewUUID(), "000000000001", true)
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestContractListenerDeleteBeginFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
@@ -231,7 +270,7 @@ func TestContractListenerDeleteFail(t *testing.T) {
 	s, mock := newMockProvider().init()
 	mock.ExpectBegin()
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(contractListenerColumns).AddRow(
-		fftypes.NewUUID(), nil, []byte("{}"), "ns1", "sub1", "123", "{}", "topic1", nil, fftypes.Now()),
+		fftypes.NewUUID(), nil, []byte("{}"), "ns1", "sub1", "123", "{}", "topic1", nil, "", fftypes.Now()),
 	)
 	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
 	err := s.DeleteContractListenerByID(context.Background(), fftypes.NewUUID())
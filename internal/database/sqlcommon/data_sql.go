@@ -41,6 +41,7 @@ var (
 		"blob_name",
 		"blob_size",
 		"value_size",
+		"labels",
 	}
 	dataColumnsWithValue = append(append([]string{}, dataColumnsNoValue...), "value")
 	dataFilterFieldMap   = map[string]string{
@@ -109,6 +110,7 @@ func (s *SQLCommon) setDataInsertValues(query sq.InsertBuilder, data *fftypes.Da
 		blob.Name,
 		blob.Size,
 		data.ValueSize,
+		data.Labels,
 		data.Value,
 	)
 }
@@ -234,6 +236,7 @@ func (s *SQLCommon) dataResult(ctx context.Context, row *sql.Rows, withValue boo
 		&data.Blob.Name,
 		&data.Blob.Size,
 		&data.ValueSize,
+		&data.Labels,
 	}
 	if withValue {
 		results = append(results, &data.Value)
@@ -149,7 +149,9 @@ func TestDataE2EWithDB(t *testing.T) {
 
 	// Update
 	v2 := "2.0.0"
-	up := database.DataQueryFactory.NewUpdate(ctx).Set("datatype.version", v2)
+	up := database.DataQueryFactory.NewUpdate(ctx).
+		Set("datatype.version", v2).
+		Set("labels", fftypes.FFStringArray{"settled"})
 	err = s.UpdateData(ctx, dataID, up)
 	assert.NoError(t, err)
 
@@ -157,11 +159,13 @@ func TestDataE2EWithDB(t *testing.T) {
 	filter = fb.And(
 		fb.Eq("id", dataUpdated.ID.String()),
 		fb.Eq("datatype.version", v2),
+		fb.Contains("labels", "settled"),
 	)
 	dataRes, res, err := s.GetData(ctx, filter.Count(true))
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(dataRes))
 	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Equal(t, fftypes.FFStringArray{"settled"}, dataRes[0].Labels)
 
 	s.callbacks.AssertExpectations(t)
 }
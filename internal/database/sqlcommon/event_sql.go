@@ -37,6 +37,7 @@ var (
 		"tx_id",
 		"topic",
 		"created",
+		"compacted",
 	}
 	eventFilterFieldMap = map[string]string{
 		"type":       "etype",
@@ -79,6 +80,7 @@ func (s *SQLCommon) setEventInsertValues(query sq.InsertBuilder, event *fftypes.
 		event.Transaction,
 		event.Topic,
 		event.Created,
+		event.Compacted,
 	)
 }
 
@@ -138,6 +140,7 @@ func (s *SQLCommon) eventResult(ctx context.Context, row *sql.Rows) (*fftypes.Ev
 		&event.Transaction,
 		&event.Topic,
 		&event.Created,
+		&event.Compacted,
 		// Must be added to the list of columns in all selects
 		&event.Sequence,
 	)
@@ -223,3 +226,19 @@ func (s *SQLCommon) UpdateEvent(ctx context.Context, id *fftypes.UUID, update da
 
 	return s.commitTx(ctx, tx, autoCommit)
 }
+
+func (s *SQLCommon) DeleteEvents(ctx context.Context, ids []*fftypes.UUID) (err error) {
+
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	err = s.deleteTx(ctx, tx, sq.Delete("events").Where(sq.Eq{"id": ids}), nil /* no change events for compaction */)
+	if err != nil && err != database.DeleteRecordNotFound {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
@@ -101,6 +101,24 @@ func TestEventE2EWithDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(events))
 
+	// Compact
+	up = database.EventQueryFactory.NewUpdate(ctx).
+		Set("compacted", 5)
+	err = s.UpdateEvent(ctx, eventRead.ID, up)
+	assert.NoError(t, err)
+	eventRead, err = s.GetEventByID(ctx, eventID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), eventRead.Compacted)
+
+	// Delete, which is not an error when the events are already gone
+	err = s.DeleteEvents(ctx, []*fftypes.UUID{eventID, fftypes.NewUUID()})
+	assert.NoError(t, err)
+	eventRead, err = s.GetEventByID(ctx, eventID)
+	assert.NoError(t, err)
+	assert.Nil(t, eventRead)
+	err = s.DeleteEvents(ctx, []*fftypes.UUID{eventID})
+	assert.NoError(t, err)
+
 	s.callbacks.AssertExpectations(t)
 }
 
@@ -283,3 +301,57 @@ func TestEventUpdateFail(t *testing.T) {
 	err := s.UpdateEvent(context.Background(), fftypes.NewUUID(), u)
 	assert.Regexp(t, "FF10117", err)
 }
+
+func TestDeleteEventsBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteEvents(context.Background(), []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeleteEventsFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.DeleteEvents(context.Background(), []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF10118", err)
+}
+
+func TestEventTopicMatchWithDB(t *testing.T) {
+
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	s.callbacks.On("OrderedUUIDCollectionNSEvent", database.CollectionEvents, fftypes.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	for _, topic := range []string{"orders", "orders.emea", "orders.emea.uk", "orders_x.emea", "ordersyx.emea", "other.emea"} {
+		err := s.InsertEvent(ctx, &fftypes.Event{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Type:      fftypes.EventTypeMessageConfirmed,
+			Reference: fftypes.NewUUID(),
+			Topic:     topic,
+			Created:   fftypes.Now(),
+		})
+		assert.NoError(t, err)
+	}
+
+	topicsMatching := func(pattern string) []string {
+		fb := database.EventQueryFactory.NewFilter(ctx)
+		events, _, err := s.GetEvents(ctx, fb.TopicMatch("topic", pattern).Sort("sequence").Ascending())
+		assert.NoError(t, err)
+		topics := make([]string, len(events))
+		for i, e := range events {
+			topics[i] = e.Topic
+		}
+		return topics
+	}
+
+	assert.Equal(t, []string{"orders.emea"}, topicsMatching("orders.*"))
+	assert.Equal(t, []string{"orders", "orders.emea", "orders.emea.uk"}, topicsMatching("orders.#"))
+	assert.Equal(t, []string{"orders.emea", "orders_x.emea", "ordersyx.emea", "other.emea"}, topicsMatching("*.emea"))
+	assert.Equal(t, []string{"orders_x.emea"}, topicsMatching("orders_x.*"))
+	assert.Equal(t, []string{"orders_x.emea"}, topicsMatching("orders_x.#"))
+
+}
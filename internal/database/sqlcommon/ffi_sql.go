@@ -35,6 +35,7 @@ var (
 		"version",
 		"description",
 		"message_id",
+		"errors",
 	}
 	ffiFilterFieldMap = map[string]string{
 		"message": "message_id",
@@ -66,7 +67,8 @@ func (s *SQLCommon) UpsertFFI(ctx context.Context, ffi *fftypes.FFI) (err error)
 				Set("name", ffi.Name).
 				Set("version", ffi.Version).
 				Set("description", ffi.Description).
-				Set("message_id", ffi.Message),
+				Set("message_id", ffi.Message).
+				Set("errors", ffi.Errors),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionFFIs, fftypes.ChangeEventTypeUpdated, ffi.Namespace, ffi.ID)
 			},
@@ -84,6 +86,7 @@ func (s *SQLCommon) UpsertFFI(ctx context.Context, ffi *fftypes.FFI) (err error)
 					ffi.Version,
 					ffi.Description,
 					ffi.Message,
+					ffi.Errors,
 				),
 			func() {
 				s.callbacks.UUIDCollectionNSEvent(database.CollectionFFIs, fftypes.ChangeEventTypeCreated, ffi.Namespace, ffi.ID)
@@ -105,6 +108,7 @@ func (s *SQLCommon) ffiResult(ctx context.Context, row *sql.Rows) (*fftypes.FFI,
 		&ffi.Version,
 		&ffi.Description,
 		&ffi.Message,
+		&ffi.Errors,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "ffi")
@@ -66,6 +66,17 @@ func TestFFIE2EWithDB(t *testing.T) {
 				},
 			},
 		},
+		Errors: fftypes.FFIErrors{
+			{
+				Name: "Overflow",
+				Params: fftypes.FFIParams{
+					{
+						Name:   "limit",
+						Schema: fftypes.JSONAnyPtr(`{"type": "integer"}`),
+					},
+				},
+			},
+		},
 	}
 
 	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionFFIs, fftypes.ChangeEventTypeCreated, "ns1", ffi.ID).Return()
@@ -83,6 +94,8 @@ func TestFFIE2EWithDB(t *testing.T) {
 	assert.Equal(t, ffi.Name, dataRead.Name)
 	assert.Equal(t, ffi.Version, dataRead.Version)
 	assert.Equal(t, ffi.Message, dataRead.Message)
+	assert.Equal(t, "Overflow", dataRead.Errors[0].Name)
+	assert.Equal(t, "limit", dataRead.Errors[0].Params[0].Name)
 
 	ffi.Version = "v1.1.0"
 
@@ -175,7 +188,7 @@ func TestGetFFIs(t *testing.T) {
 	fb := database.FFIQueryFactory.NewFilter(context.Background())
 	s, mock := newMockProvider().init()
 	rows := sqlmock.NewRows(ffiColumns).
-		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "ns1", "math", "v1.0.0", "super mathy things", "acfe07a2-117f-46b7-8d47-e3beb7cc382f")
+		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "ns1", "math", "v1.0.0", "super mathy things", "acfe07a2-117f-46b7-8d47-e3beb7cc382f", nil)
 	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
 	_, _, err := s.GetFFIs(context.Background(), "ns1", fb.And())
 	assert.NoError(t, err)
@@ -213,7 +226,7 @@ func TestGetFFIsQueryResultFail(t *testing.T) {
 func TestGetFFI(t *testing.T) {
 	s, mock := newMockProvider().init()
 	rows := sqlmock.NewRows(ffiColumns).
-		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "ns1", "math", "v1.0.0", "super mathy things", "acfe07a2-117f-46b7-8d47-e3beb7cc382f")
+		AddRow("7e2c001c-e270-4fd7-9e82-9dacee843dc2", "ns1", "math", "v1.0.0", "super mathy things", "acfe07a2-117f-46b7-8d47-e3beb7cc382f", nil)
 	mock.ExpectQuery("SELECT .*").WillReturnRows(rows)
 	ffi, err := s.GetFFI(context.Background(), "ns1", "math", "v1.0.0")
 	assert.NoError(t, err)
@@ -24,6 +24,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
 func (s *SQLCommon) filterSelect(ctx context.Context, tableName string, sel sq.SelectBuilder, filter database.Filter, typeMap map[string]string, defaultSort []interface{}, preconditions ...sq.Sqlizer) (sq.SelectBuilder, sq.Sqlizer, *database.FilterInfo, error) {
@@ -122,6 +123,19 @@ func (s *SQLCommon) escapeLike(value database.FieldSerialization) string {
 	return vs
 }
 
+// escapeLikeBackslash escapes the LIKE wildcards in a value, for use with an explicit ESCAPE '\' clause
+func escapeLikeBackslash(vs string) string {
+	vs = strings.ReplaceAll(vs, `\`, `\\`)
+	vs = strings.ReplaceAll(vs, "%", `\%`)
+	vs = strings.ReplaceAll(vs, "_", `\_`)
+	return vs
+}
+
+// likeEscaped builds a LIKE with an explicit escape character, which is supported consistently across databases
+func likeEscaped(field, pattern string) sq.Sqlizer {
+	return sq.Expr(field+` LIKE ? ESCAPE '\'`, pattern)
+}
+
 func (s *SQLCommon) mapField(tableName, fieldName string, tm map[string]string) string {
 	if fieldName == "sequence" {
 		if tableName == "" {
@@ -157,6 +171,48 @@ func (s *SQLCommon) newNotILike(field, value string) sq.Sqlizer {
 	return sq.NotLike{fmt.Sprintf("lower(%s)", field): strings.ToLower(value)}
 }
 
+// topicMatch builds a condition for an MQTT-style topic pattern, against a column holding a single topic. The literal
+// levels at the start of the pattern form the prefix of a LIKE, so an index on the column can be used, and the number
+// of levels is checked by counting the separators only when the pattern contains single-level wildcards.
+func (s *SQLCommon) topicMatch(ctx context.Context, field string, value database.FieldSerialization) (sq.Sqlizer, error) {
+	v, _ := value.Value()
+	pattern, _ := v.(string)
+	tp, err := fftypes.ParseTopicPattern(ctx, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if tp.MultiLevel && tp.LiteralAfterWildcard() {
+		return nil, i18n.NewError(ctx, i18n.MsgTopicPatternNotQueryable, pattern)
+	}
+	if len(tp.Levels) == 0 {
+		// A lone "#" matches every topic
+		return sq.Expr("1=1"), nil
+	}
+
+	wildcards := false
+	likeLevels := make([]string, len(tp.Levels))
+	for i, level := range tp.Levels {
+		if level == fftypes.TopicWildcardSingle {
+			likeLevels[i] = "_%"
+			wildcards = true
+		} else {
+			likeLevels[i] = escapeLikeBackslash(level)
+		}
+	}
+	like := strings.Join(likeLevels, fftypes.TopicLevelSeparator)
+	separators := fmt.Sprintf("(LENGTH(%s) - LENGTH(REPLACE(%s, '%s', '')))", field, field, fftypes.TopicLevelSeparator)
+
+	var exact sq.Sqlizer = sq.Eq{field: strings.Join(tp.Levels, fftypes.TopicLevelSeparator)}
+	if wildcards {
+		exact = sq.And{likeEscaped(field, like), sq.Expr(separators+" = ?", len(tp.Levels)-1)}
+	}
+	if !tp.MultiLevel {
+		return exact, nil
+	}
+	// Wildcards are only at the end of the levels here, so the LIKE alone matches the deeper topics
+	return sq.Or{exact, likeEscaped(field, like+fftypes.TopicLevelSeparator+"%")}, nil
+}
+
 func (s *SQLCommon) filterOp(ctx context.Context, tableName string, op *database.FilterInfo, tm map[string]string) (sq.Sqlizer, error) {
 	switch op.Op {
 	case database.FilterOpOr:
@@ -199,6 +255,8 @@ func (s *SQLCommon) filterOp(ctx context.Context, tableName string, op *database
 		return s.newILike(s.mapField(tableName, op.Field, tm), fmt.Sprintf("%%%s", s.escapeLike(op.Value))), nil
 	case database.FilterOpNotIEndsWith:
 		return s.newNotILike(s.mapField(tableName, op.Field, tm), fmt.Sprintf("%%%s", s.escapeLike(op.Value))), nil
+	case database.FilterOpTopicMatch:
+		return s.topicMatch(ctx, s.mapField(tableName, op.Field, tm), op.Value)
 	case database.FilterOpGt:
 		return sq.Gt{s.mapField(tableName, op.Field, tm): op.Value}, nil
 	case database.FilterOpGte:
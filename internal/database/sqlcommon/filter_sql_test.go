@@ -121,6 +121,56 @@ func TestSQLQueryFactoryEvenMoreOps(t *testing.T) {
 	assert.Equal(t, "SELECT * FROM mytable AS mt WHERE (mt.id ILIKE ? AND mt.id NOT ILIKE ? AND mt.topics LIKE ? AND mt.topics NOT LIKE ? AND mt.topics ILIKE ? AND mt.topics NOT ILIKE ? AND mt.topics LIKE ? AND mt.topics NOT LIKE ? AND mt.topics ILIKE ? AND mt.topics NOT ILIKE ?) ORDER BY mt.seq DESC", sqlFilter)
 }
 
+func TestSQLQueryFactoryTopicMatch(t *testing.T) {
+
+	s, _ := newMockProvider().init()
+	fb := database.EventQueryFactory.NewFilter(context.Background())
+	f := fb.And(
+		fb.TopicMatch("topic", "a.b_c"),
+		fb.TopicMatch("topic", "a_%.*.c"),
+		fb.TopicMatch("topic", "a.#"),
+		fb.TopicMatch("topic", "a.*.#"),
+		fb.TopicMatch("topic", "#"),
+	)
+
+	sel := squirrel.Select("*").From("mytable AS mt")
+	sel, _, _, err := s.filterSelect(context.Background(), "mt", sel, f, nil, []interface{}{"sequence"})
+	assert.NoError(t, err)
+
+	sqlFilter, args, err := sel.ToSql()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM mytable AS mt WHERE (mt.topic = ? AND (mt.topic LIKE ? ESCAPE '\\' AND (LENGTH(mt.topic) - LENGTH(REPLACE(mt.topic, '.', ''))) = ?) AND (mt.topic = ? OR mt.topic LIKE ? ESCAPE '\\') AND ((mt.topic LIKE ? ESCAPE '\\' AND (LENGTH(mt.topic) - LENGTH(REPLACE(mt.topic, '.', ''))) = ?) OR mt.topic LIKE ? ESCAPE '\\') AND 1=1) ORDER BY mt.seq DESC", sqlFilter)
+	assert.Equal(t, []interface{}{"a.b_c", `a\_\%._%.c`, 2, "a", "a.%", "a._%", 1, "a._%.%"}, args)
+}
+
+func TestSQLQueryFactoryTopicMatchMultiValueField(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.MessageQueryFactory.NewFilter(context.Background())
+	sel := squirrel.Select("*").From("mytable")
+	_, _, _, err := s.filterSelect(context.Background(), "", sel, fb.TopicMatch("topics", "a.#"), nil, []interface{}{"sequence"})
+	assert.Regexp(t, "FF10559", err)
+}
+
+func TestEscapeLikeBackslash(t *testing.T) {
+	assert.Equal(t, `a\_b\%c\\d`, escapeLikeBackslash(`a_b%c\d`))
+}
+
+func TestSQLQueryFactoryTopicMatchBadPattern(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.EventQueryFactory.NewFilter(context.Background())
+	sel := squirrel.Select("*").From("mytable")
+	_, _, _, err := s.filterSelect(context.Background(), "", sel, fb.TopicMatch("topic", "a.#.b"), nil, []interface{}{"sequence"})
+	assert.Regexp(t, "FF10528", err)
+}
+
+func TestSQLQueryFactoryTopicMatchNotQueryable(t *testing.T) {
+	s, _ := newMockProvider().init()
+	fb := database.EventQueryFactory.NewFilter(context.Background())
+	sel := squirrel.Select("*").From("mytable")
+	_, _, _, err := s.filterSelect(context.Background(), "", sel, fb.TopicMatch("topic", "a.*.b.#"), nil, []interface{}{"sequence"})
+	assert.Regexp(t, "FF10529", err)
+}
+
 func TestSQLQueryFactoryFinalizeFail(t *testing.T) {
 	s, _ := newMockProvider().init()
 	fb := database.MessageQueryFactory.NewFilter(context.Background())
@@ -0,0 +1,256 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	governanceProposalColumns = []string{
+		"id",
+		"ptype",
+		"description",
+		"change",
+		"author",
+		"message_id",
+		"state",
+		"approvals",
+		"rejections",
+		"created",
+		"decided",
+	}
+	governanceProposalFilterFieldMap = map[string]string{
+		"type":    "ptype",
+		"message": "message_id",
+	}
+	governanceVoteColumns = []string{
+		"id",
+		"proposal_id",
+		"approve",
+		"comment",
+		"author",
+		"message_id",
+		"created",
+	}
+	governanceVoteFilterFieldMap = map[string]string{
+		"proposal": "proposal_id",
+		"message":  "message_id",
+	}
+)
+
+func (s *SQLCommon) InsertGovernanceProposal(ctx context.Context, proposal *fftypes.GovernanceProposal) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("governanceproposals").
+			Columns(governanceProposalColumns...).
+			Values(
+				proposal.ID,
+				proposal.Type,
+				proposal.Description,
+				proposal.Change,
+				proposal.Author,
+				proposal.Message,
+				proposal.State,
+				proposal.Approvals,
+				proposal.Rejections,
+				proposal.Created,
+				proposal.Decided,
+			),
+		func() {
+			s.callbacks.UUIDCollectionEvent(database.CollectionGovernanceProposals, fftypes.ChangeEventTypeCreated, proposal.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) UpdateGovernanceProposal(ctx context.Context, proposal *fftypes.GovernanceProposal) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.updateTx(ctx, tx,
+		sq.Update("governanceproposals").
+			Set("state", proposal.State).
+			Set("approvals", proposal.Approvals).
+			Set("rejections", proposal.Rejections).
+			Set("decided", proposal.Decided).
+			Where(sq.Eq{"id": proposal.ID}),
+		func() {
+			s.callbacks.UUIDCollectionEvent(database.CollectionGovernanceProposals, fftypes.ChangeEventTypeUpdated, proposal.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) governanceProposalResult(ctx context.Context, row *sql.Rows) (*fftypes.GovernanceProposal, error) {
+	var proposal fftypes.GovernanceProposal
+	err := row.Scan(
+		&proposal.ID,
+		&proposal.Type,
+		&proposal.Description,
+		&proposal.Change,
+		&proposal.Author,
+		&proposal.Message,
+		&proposal.State,
+		&proposal.Approvals,
+		&proposal.Rejections,
+		&proposal.Created,
+		&proposal.Decided,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "governanceproposals")
+	}
+	return &proposal, nil
+}
+
+func (s *SQLCommon) GetGovernanceProposalByID(ctx context.Context, id *fftypes.UUID) (*fftypes.GovernanceProposal, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(governanceProposalColumns...).
+			From("governanceproposals").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Governance proposal '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.governanceProposalResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetGovernanceProposals(ctx context.Context, filter database.Filter) ([]*fftypes.GovernanceProposal, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(governanceProposalColumns...).From("governanceproposals"),
+		filter, governanceProposalFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	proposals := []*fftypes.GovernanceProposal{}
+	for rows.Next() {
+		proposal, err := s.governanceProposalResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		proposals = append(proposals, proposal)
+	}
+
+	return proposals, s.queryRes(ctx, tx, "governanceproposals", fop, fi), err
+}
+
+func (s *SQLCommon) InsertGovernanceVote(ctx context.Context, vote *fftypes.GovernanceVote) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("governancevotes").
+			Columns(governanceVoteColumns...).
+			Values(
+				vote.ID,
+				vote.Proposal,
+				vote.Approve,
+				vote.Comment,
+				vote.Author,
+				vote.Message,
+				vote.Created,
+			),
+		func() {
+			s.callbacks.UUIDCollectionEvent(database.CollectionGovernanceVotes, fftypes.ChangeEventTypeCreated, vote.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) governanceVoteResult(ctx context.Context, row *sql.Rows) (*fftypes.GovernanceVote, error) {
+	var vote fftypes.GovernanceVote
+	err := row.Scan(
+		&vote.ID,
+		&vote.Proposal,
+		&vote.Approve,
+		&vote.Comment,
+		&vote.Author,
+		&vote.Message,
+		&vote.Created,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "governancevotes")
+	}
+	return &vote, nil
+}
+
+func (s *SQLCommon) GetGovernanceVotes(ctx context.Context, filter database.Filter) ([]*fftypes.GovernanceVote, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(governanceVoteColumns...).From("governancevotes"),
+		filter, governanceVoteFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	votes := []*fftypes.GovernanceVote{}
+	for rows.Next() {
+		vote, err := s.governanceVoteResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		votes = append(votes, vote)
+	}
+
+	return votes, s.queryRes(ctx, tx, "governancevotes", fop, fi), err
+}
@@ -0,0 +1,265 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGovernanceE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Insert a proposal
+	proposal := &fftypes.GovernanceProposal{
+		ID:          fftypes.NewUUID(),
+		Type:        fftypes.GovernanceChangeProtocolVersion,
+		Description: "Move to version 2",
+		Change:      fftypes.JSONAnyPtr(`{"version":2}`),
+		Author:      "did:firefly:org/org1",
+		Message:     fftypes.NewUUID(),
+		State:       fftypes.GovernanceProposalStatePending,
+		Created:     fftypes.Now(),
+	}
+
+	s.callbacks.On("UUIDCollectionEvent", database.CollectionGovernanceProposals, fftypes.ChangeEventTypeCreated, proposal.ID, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionEvent", database.CollectionGovernanceProposals, fftypes.ChangeEventTypeUpdated, proposal.ID, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionEvent", database.CollectionGovernanceVotes, fftypes.ChangeEventTypeCreated, mock.Anything, mock.Anything).Return()
+
+	err := s.InsertGovernanceProposal(ctx, proposal)
+	assert.NoError(t, err)
+	proposalJson, _ := json.Marshal(&proposal)
+
+	// Query back the proposal
+	proposalRead, err := s.GetGovernanceProposalByID(ctx, proposal.ID)
+	assert.NoError(t, err)
+	proposalReadJson, _ := json.Marshal(&proposalRead)
+	assert.Equal(t, string(proposalJson), string(proposalReadJson))
+
+	// Vote on the proposal
+	vote := &fftypes.GovernanceVote{
+		ID:       fftypes.NewUUID(),
+		Proposal: proposal.ID,
+		Approve:  true,
+		Comment:  "agreed",
+		Author:   "did:firefly:org/org1",
+		Message:  fftypes.NewUUID(),
+		Created:  fftypes.Now(),
+	}
+	err = s.InsertGovernanceVote(ctx, vote)
+	assert.NoError(t, err)
+	voteJson, _ := json.Marshal(&vote)
+
+	// A second vote from the same author is not allowed
+	err = s.InsertGovernanceVote(ctx, &fftypes.GovernanceVote{
+		ID:       fftypes.NewUUID(),
+		Proposal: proposal.ID,
+		Author:   "did:firefly:org/org1",
+		Created:  fftypes.Now(),
+	})
+	assert.Regexp(t, "FF10116", err)
+
+	fb := database.GovernanceVoteQueryFactory.NewFilter(ctx)
+	votes, res, err := s.GetGovernanceVotes(ctx, fb.And(
+		fb.Eq("proposal", proposal.ID),
+		fb.Eq("approve", true),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	voteReadJson, _ := json.Marshal(votes[0])
+	assert.Equal(t, string(voteJson), string(voteReadJson))
+
+	// Update the proposal
+	proposal.State = fftypes.GovernanceProposalStateApproved
+	proposal.Approvals = 1
+	proposal.Decided = fftypes.Now()
+	err = s.UpdateGovernanceProposal(ctx, proposal)
+	assert.NoError(t, err)
+	proposalJson, _ = json.Marshal(&proposal)
+
+	// Query back with a filter
+	pfb := database.GovernanceProposalQueryFactory.NewFilter(ctx)
+	proposals, res, err := s.GetGovernanceProposals(ctx, pfb.And(
+		pfb.Eq("type", fftypes.GovernanceChangeProtocolVersion),
+		pfb.Eq("state", fftypes.GovernanceProposalStateApproved),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	proposalReadJson, _ = json.Marshal(proposals[0])
+	assert.Equal(t, string(proposalJson), string(proposalReadJson))
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestInsertGovernanceProposalFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertGovernanceProposal(context.Background(), &fftypes.GovernanceProposal{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertGovernanceProposalFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertGovernanceProposal(context.Background(), &fftypes.GovernanceProposal{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertGovernanceProposalFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertGovernanceProposal(context.Background(), &fftypes.GovernanceProposal{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateGovernanceProposalFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpdateGovernanceProposal(context.Background(), &fftypes.GovernanceProposal{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateGovernanceProposalFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpdateGovernanceProposal(context.Background(), &fftypes.GovernanceProposal{})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateGovernanceProposalFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpdateGovernanceProposal(context.Background(), &fftypes.GovernanceProposal{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGovernanceProposalByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetGovernanceProposalByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGovernanceProposalByIDNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(governanceProposalColumns))
+	proposal, err := s.GetGovernanceProposalByID(context.Background(), fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, proposal)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGovernanceProposalByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetGovernanceProposalByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGovernanceProposalsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.GovernanceProposalQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetGovernanceProposals(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGovernanceProposalsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.GovernanceProposalQueryFactory.NewFilter(context.Background()).Eq("state", map[bool]bool{true: false})
+	_, _, err := s.GetGovernanceProposals(context.Background(), f)
+	assert.Regexp(t, "FF10149.*state", err)
+}
+
+func TestGetGovernanceProposalsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.GovernanceProposalQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetGovernanceProposals(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertGovernanceVoteFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertGovernanceVote(context.Background(), &fftypes.GovernanceVote{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertGovernanceVoteFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertGovernanceVote(context.Background(), &fftypes.GovernanceVote{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGovernanceVotesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.GovernanceVoteQueryFactory.NewFilter(context.Background()).Eq("author", "")
+	_, _, err := s.GetGovernanceVotes(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGovernanceVotesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.GovernanceVoteQueryFactory.NewFilter(context.Background()).Eq("author", map[bool]bool{true: false})
+	_, _, err := s.GetGovernanceVotes(context.Background(), f)
+	assert.Regexp(t, "FF10149.*author", err)
+}
+
+func TestGetGovernanceVotesScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.GovernanceVoteQueryFactory.NewFilter(context.Background()).Eq("author", "")
+	_, _, err := s.GetGovernanceVotes(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
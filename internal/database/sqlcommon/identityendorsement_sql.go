@@ -0,0 +1,112 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	identityEndorsementColumns = []string{
+		"id",
+		"identity_id",
+		"did",
+		"author",
+		"message_id",
+		"created",
+	}
+	identityEndorsementFilterFieldMap = map[string]string{
+		"identity": "identity_id",
+		"message":  "message_id",
+	}
+)
+
+func (s *SQLCommon) InsertIdentityEndorsement(ctx context.Context, endorsement *fftypes.IdentityEndorsement) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("identityendorsements").
+			Columns(identityEndorsementColumns...).
+			Values(
+				endorsement.ID,
+				endorsement.Identity,
+				endorsement.DID,
+				endorsement.Author,
+				endorsement.Message,
+				endorsement.Created,
+			),
+		func() {
+			s.callbacks.UUIDCollectionEvent(database.CollectionIdentityEndorsements, fftypes.ChangeEventTypeCreated, endorsement.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) identityEndorsementResult(ctx context.Context, row *sql.Rows) (*fftypes.IdentityEndorsement, error) {
+	var endorsement fftypes.IdentityEndorsement
+	err := row.Scan(
+		&endorsement.ID,
+		&endorsement.Identity,
+		&endorsement.DID,
+		&endorsement.Author,
+		&endorsement.Message,
+		&endorsement.Created,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "identityendorsements")
+	}
+	return &endorsement, nil
+}
+
+func (s *SQLCommon) GetIdentityEndorsements(ctx context.Context, filter database.Filter) ([]*fftypes.IdentityEndorsement, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(identityEndorsementColumns...).From("identityendorsements"),
+		filter, identityEndorsementFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	endorsements := []*fftypes.IdentityEndorsement{}
+	for rows.Next() {
+		endorsement, err := s.identityEndorsementResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		endorsements = append(endorsements, endorsement)
+	}
+
+	return endorsements, s.queryRes(ctx, tx, "identityendorsements", fop, fi), err
+}
@@ -0,0 +1,118 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdentityEndorsementsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Insert an endorsement
+	endorsement := &fftypes.IdentityEndorsement{
+		ID:       fftypes.NewUUID(),
+		Identity: fftypes.NewUUID(),
+		DID:      "did:firefly:org/org2",
+		Author:   "did:firefly:org/org1",
+		Message:  fftypes.NewUUID(),
+		Created:  fftypes.Now(),
+	}
+
+	s.callbacks.On("UUIDCollectionEvent", database.CollectionIdentityEndorsements, fftypes.ChangeEventTypeCreated, endorsement.ID, mock.Anything).Return()
+
+	err := s.InsertIdentityEndorsement(ctx, endorsement)
+	assert.NoError(t, err)
+	endorsementJson, _ := json.Marshal(&endorsement)
+
+	// A second endorsement from the same author is not allowed
+	err = s.InsertIdentityEndorsement(ctx, &fftypes.IdentityEndorsement{
+		ID:       fftypes.NewUUID(),
+		Identity: endorsement.Identity,
+		DID:      endorsement.DID,
+		Author:   endorsement.Author,
+		Created:  fftypes.Now(),
+	})
+	assert.Regexp(t, "FF10116", err)
+
+	// Query back with a filter
+	fb := database.IdentityEndorsementQueryFactory.NewFilter(ctx)
+	endorsements, res, err := s.GetIdentityEndorsements(ctx, fb.And(
+		fb.Eq("identity", endorsement.Identity),
+		fb.Eq("message", endorsement.Message),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	endorsementReadJson, _ := json.Marshal(endorsements[0])
+	assert.Equal(t, string(endorsementJson), string(endorsementReadJson))
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestInsertIdentityEndorsementFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertIdentityEndorsement(context.Background(), &fftypes.IdentityEndorsement{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertIdentityEndorsementFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertIdentityEndorsement(context.Background(), &fftypes.IdentityEndorsement{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetIdentityEndorsementsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.IdentityEndorsementQueryFactory.NewFilter(context.Background()).Eq("author", "")
+	_, _, err := s.GetIdentityEndorsements(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetIdentityEndorsementsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.IdentityEndorsementQueryFactory.NewFilter(context.Background()).Eq("author", map[bool]bool{true: false})
+	_, _, err := s.GetIdentityEndorsements(context.Background(), f)
+	assert.Regexp(t, "FF10149.*author", err)
+}
+
+func TestGetIdentityEndorsementsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.IdentityEndorsementQueryFactory.NewFilter(context.Background()).Eq("author", "")
+	_, _, err := s.GetIdentityEndorsements(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
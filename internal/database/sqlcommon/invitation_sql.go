@@ -0,0 +1,170 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	invitationColumns = []string{
+		"id",
+		"description",
+		"inviter",
+		"node_id",
+		"peer",
+		"state",
+		"redeemed_by",
+		"created",
+		"expires",
+		"redeemed",
+		"secret",
+	}
+	invitationFilterFieldMap = map[string]string{
+		"node":       "node_id",
+		"redeemedby": "redeemed_by",
+	}
+)
+
+func (s *SQLCommon) InsertInvitation(ctx context.Context, invitation *fftypes.Invitation) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("invitations").
+			Columns(invitationColumns...).
+			Values(
+				invitation.ID,
+				invitation.Description,
+				invitation.Inviter,
+				invitation.Node,
+				invitation.Peer,
+				invitation.State,
+				invitation.RedeemedBy,
+				invitation.Created,
+				invitation.Expires,
+				invitation.Redeemed,
+				invitation.Secret,
+			),
+		func() {
+			s.callbacks.UUIDCollectionEvent(database.CollectionInvitations, fftypes.ChangeEventTypeCreated, invitation.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) UpdateInvitation(ctx context.Context, invitation *fftypes.Invitation) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.updateTx(ctx, tx,
+		sq.Update("invitations").
+			Set("state", invitation.State).
+			Set("redeemed_by", invitation.RedeemedBy).
+			Set("redeemed", invitation.Redeemed).
+			Where(sq.Eq{"id": invitation.ID}),
+		func() {
+			s.callbacks.UUIDCollectionEvent(database.CollectionInvitations, fftypes.ChangeEventTypeUpdated, invitation.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) invitationResult(ctx context.Context, row *sql.Rows) (*fftypes.Invitation, error) {
+	var invitation fftypes.Invitation
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.Description,
+		&invitation.Inviter,
+		&invitation.Node,
+		&invitation.Peer,
+		&invitation.State,
+		&invitation.RedeemedBy,
+		&invitation.Created,
+		&invitation.Expires,
+		&invitation.Redeemed,
+		&invitation.Secret,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "invitations")
+	}
+	return &invitation, nil
+}
+
+func (s *SQLCommon) GetInvitationByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Invitation, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(invitationColumns...).
+			From("invitations").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Invitation '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.invitationResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetInvitations(ctx context.Context, filter database.Filter) ([]*fftypes.Invitation, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(invitationColumns...).From("invitations"),
+		filter, invitationFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	invitations := []*fftypes.Invitation{}
+	for rows.Next() {
+		invitation, err := s.invitationResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, s.queryRes(ctx, tx, "invitations", fop, fi), err
+}
@@ -0,0 +1,191 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInvitationsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Insert an invitation
+	invitation := &fftypes.Invitation{
+		ID:          fftypes.NewUUID(),
+		Description: "Invitation for org2",
+		Inviter:     "did:firefly:org/org1",
+		Node:        fftypes.NewUUID(),
+		Peer:        fftypes.JSONObject{"id": "peer1"},
+		State:       fftypes.InvitationStatePending,
+		Created:     fftypes.Now(),
+		Expires:     fftypes.Now(),
+		Secret:      fftypes.NewRandB32(),
+	}
+
+	s.callbacks.On("UUIDCollectionEvent", database.CollectionInvitations, fftypes.ChangeEventTypeCreated, invitation.ID, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionEvent", database.CollectionInvitations, fftypes.ChangeEventTypeUpdated, invitation.ID, mock.Anything).Return()
+
+	err := s.InsertInvitation(ctx, invitation)
+	assert.NoError(t, err)
+	invitationJson, _ := json.Marshal(&invitation)
+
+	// Query back the invitation
+	invitationRead, err := s.GetInvitationByID(ctx, invitation.ID)
+	assert.NoError(t, err)
+	invitationReadJson, _ := json.Marshal(&invitationRead)
+	assert.Equal(t, string(invitationJson), string(invitationReadJson))
+	assert.Equal(t, invitation.Secret, invitationRead.Secret)
+
+	// Update the invitation
+	invitation.State = fftypes.InvitationStateRedeemed
+	invitation.RedeemedBy = "did:firefly:org/org2"
+	invitation.Redeemed = fftypes.Now()
+	err = s.UpdateInvitation(ctx, invitation)
+	assert.NoError(t, err)
+	invitationJson, _ = json.Marshal(&invitation)
+
+	// Query back with a filter
+	fb := database.InvitationQueryFactory.NewFilter(ctx)
+	invitations, res, err := s.GetInvitations(ctx, fb.And(
+		fb.Eq("state", fftypes.InvitationStateRedeemed),
+		fb.Eq("redeemedby", "did:firefly:org/org2"),
+		fb.Eq("node", invitation.Node),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	invitationReadJson, _ = json.Marshal(invitations[0])
+	assert.Equal(t, string(invitationJson), string(invitationReadJson))
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestInsertInvitationFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertInvitation(context.Background(), &fftypes.Invitation{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertInvitationFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertInvitation(context.Background(), &fftypes.Invitation{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertInvitationFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertInvitation(context.Background(), &fftypes.Invitation{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateInvitationFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpdateInvitation(context.Background(), &fftypes.Invitation{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateInvitationFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpdateInvitation(context.Background(), &fftypes.Invitation{})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateInvitationFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpdateInvitation(context.Background(), &fftypes.Invitation{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInvitationByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetInvitationByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInvitationByIDNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(invitationColumns))
+	invitation, err := s.GetInvitationByID(context.Background(), fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, invitation)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInvitationByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetInvitationByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInvitationsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.InvitationQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetInvitations(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetInvitationsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.InvitationQueryFactory.NewFilter(context.Background()).Eq("state", map[bool]bool{true: false})
+	_, _, err := s.GetInvitations(context.Background(), f)
+	assert.Regexp(t, "FF10149.*state", err)
+}
+
+func TestGetInvitationsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.InvitationQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetInvitations(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
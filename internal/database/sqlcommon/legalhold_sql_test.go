@@ -0,0 +1,207 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLegalHoldsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new legal hold
+	hold := &fftypes.LegalHold{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Type:      fftypes.LegalHoldTypeMessage,
+		Ref:       fftypes.NewUUID(),
+		Reason:    "case 1234",
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionLegalHolds, fftypes.ChangeEventTypeCreated, "ns1", hold.ID, mock.Anything).Return()
+
+	err := s.InsertLegalHold(ctx, hold)
+	assert.NoError(t, err)
+	assert.NotNil(t, hold.Created)
+	holdJson, _ := json.Marshal(&hold)
+
+	// Query back the hold
+	holdRead, err := s.GetLegalHoldByID(ctx, hold.ID)
+	assert.NoError(t, err)
+	holdReadJson, _ := json.Marshal(&holdRead)
+	assert.Equal(t, string(holdJson), string(holdReadJson))
+
+	// Query back the hold by ref
+	fb := database.LegalHoldQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("ref", hold.Ref),
+		fb.Eq("type", fftypes.LegalHoldTypeMessage),
+	)
+	holds, res, err := s.GetLegalHolds(ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(holds))
+	assert.Equal(t, int64(1), *res.TotalCount)
+	holdReadJson, _ = json.Marshal(holds[0])
+	assert.Equal(t, string(holdJson), string(holdReadJson))
+
+	// Release the hold
+	hold.Released = fftypes.Now()
+	hold.ReleaseReason = "case closed"
+	up := database.LegalHoldQueryFactory.NewUpdate(ctx).
+		Set("released", hold.Released).
+		Set("releasereason", hold.ReleaseReason)
+	err = s.UpdateLegalHold(ctx, hold.ID, up)
+	assert.NoError(t, err)
+
+	holds, _, err = s.GetLegalHolds(ctx, fb.And(
+		fb.Eq("ref", hold.Ref),
+		fb.Eq("released", nil),
+	))
+	assert.NoError(t, err)
+	assert.Empty(t, holds)
+
+	holdRead, err = s.GetLegalHoldByID(ctx, hold.ID)
+	assert.NoError(t, err)
+	holdJson, _ = json.Marshal(&hold)
+	holdReadJson, _ = json.Marshal(&holdRead)
+	assert.Equal(t, string(holdJson), string(holdReadJson))
+}
+
+func TestInsertLegalHoldFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertLegalHold(context.Background(), &fftypes.LegalHold{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertLegalHoldFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertLegalHold(context.Background(), &fftypes.LegalHold{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertLegalHoldFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertLegalHold(context.Background(), &fftypes.LegalHold{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateLegalHoldBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	u := database.LegalHoldQueryFactory.NewUpdate(context.Background()).Set("released", fftypes.Now())
+	err := s.UpdateLegalHold(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestUpdateLegalHoldBuildQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	u := database.LegalHoldQueryFactory.NewUpdate(context.Background()).Set("id", map[bool]bool{true: false})
+	err := s.UpdateLegalHold(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10149.*id", err)
+}
+
+func TestUpdateLegalHoldFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	u := database.LegalHoldQueryFactory.NewUpdate(context.Background()).Set("released", fftypes.Now())
+	err := s.UpdateLegalHold(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10117", err)
+}
+
+func TestUpdateLegalHoldCommitFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	u := database.LegalHoldQueryFactory.NewUpdate(context.Background()).Set("released", fftypes.Now())
+	err := s.UpdateLegalHold(context.Background(), fftypes.NewUUID(), u)
+	assert.Regexp(t, "FF10119", err)
+}
+
+func TestGetLegalHoldByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetLegalHoldByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLegalHoldByIDNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	hold, err := s.GetLegalHoldByID(context.Background(), fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, hold)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLegalHoldByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetLegalHoldByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLegalHoldsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.LegalHoldQueryFactory.NewFilter(context.Background()).Eq("id", "")
+	_, _, err := s.GetLegalHolds(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetLegalHoldsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.LegalHoldQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
+	_, _, err := s.GetLegalHolds(context.Background(), f)
+	assert.Regexp(t, "FF10149.*id", err)
+}
+
+func TestGetLegalHoldsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.LegalHoldQueryFactory.NewFilter(context.Background()).Eq("id", "")
+	_, _, err := s.GetLegalHolds(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -47,12 +47,17 @@ var (
 		"confirmed",
 		"tx_type",
 		"batch_id",
+		"labels",
+		"priority",
+		"ordering_key",
+		"attachments",
 	}
 	msgFilterFieldMap = map[string]string{
-		"type":   "mtype",
-		"txtype": "tx_type",
-		"batch":  "batch_id",
-		"group":  "group_hash",
+		"type":        "mtype",
+		"txtype":      "tx_type",
+		"batch":       "batch_id",
+		"group":       "group_hash",
+		"orderingkey": "ordering_key",
 	}
 )
 
@@ -75,6 +80,9 @@ func (s *SQLCommon) attemptMessageUpdate(ctx context.Context, tx *txWrapper, mes
 			Set("confirmed", message.Confirmed).
 			Set("tx_type", message.Header.TxType).
 			Set("batch_id", message.BatchID).
+			Set("priority", message.Header.Priority).
+			Set("ordering_key", message.Header.OrderingKey).
+			Set("attachments", message.Header.Attachments).
 			Where(sq.Eq{
 				"id":   message.Header.ID,
 				"hash": message.Hash,
@@ -103,6 +111,10 @@ func (s *SQLCommon) setMessageInsertValues(query sq.InsertBuilder, message *ffty
 		message.Confirmed,
 		message.Header.TxType,
 		message.BatchID,
+		message.Labels,
+		message.Header.Priority,
+		message.Header.OrderingKey,
+		message.Header.Attachments,
 	)
 }
 
@@ -400,6 +412,10 @@ func (s *SQLCommon) msgResult(ctx context.Context, row *sql.Rows) (*fftypes.Mess
 		&msg.Confirmed,
 		&msg.Header.TxType,
 		&msg.BatchID,
+		&msg.Labels,
+		&msg.Header.Priority,
+		&msg.Header.OrderingKey,
+		&msg.Header.Attachments,
 		// Must be added to the list of columns in all selects
 		&msg.Sequence,
 	)
@@ -99,13 +99,18 @@ func TestUpsertE2EWithDB(t *testing.T) {
 				Key:    "0x12345",
 				Author: "did:firefly:org/abcd",
 			},
-			Created:   fftypes.Now(),
-			Namespace: "ns12345",
-			Topics:    []string{"topic1", "topic2"},
-			Tag:       "tag1",
-			Group:     gid,
-			DataHash:  fftypes.NewRandB32(),
-			TxType:    fftypes.TransactionTypeBatchPin,
+			Created:     fftypes.Now(),
+			Namespace:   "ns12345",
+			Topics:      []string{"topic1", "topic2"},
+			OrderingKey: "account1",
+			Tag:         "tag1",
+			Priority:    10,
+			Attachments: fftypes.AttachmentManifest{
+				{Filename: "invoice.pdf", MimeType: "application/pdf", Size: 12345, Hash: fftypes.NewRandB32()},
+			},
+			Group:    gid,
+			DataHash: fftypes.NewRandB32(),
+			TxType:   fftypes.TransactionTypeBatchPin,
 		},
 		Hash:      fftypes.NewRandB32(),
 		Pins:      []string{fftypes.NewRandB32().String(), fftypes.NewRandB32().String()},
@@ -149,6 +154,7 @@ func TestUpsertE2EWithDB(t *testing.T) {
 		fb.Eq("topics", msgUpdated.Header.Topics),
 		fb.Eq("group", msgUpdated.Header.Group),
 		fb.Eq("cid", msgUpdated.Header.CID),
+		fb.Contains("attachments", "invoice.pdf"),
 		fb.Gt("created", "0"),
 		fb.Gt("confirmed", "0"),
 	)
@@ -188,7 +194,8 @@ func TestUpsertE2EWithDB(t *testing.T) {
 	bid2 := fftypes.NewUUID()
 	up := database.MessageQueryFactory.NewUpdate(ctx).
 		Set("group", gid2).
-		Set("batch", bid2)
+		Set("batch", bid2).
+		Set("labels", fftypes.FFStringArray{"reviewed", "disputed"})
 	err = s.UpdateMessage(ctx, msgID, up)
 	assert.NoError(t, err)
 
@@ -196,11 +203,21 @@ func TestUpsertE2EWithDB(t *testing.T) {
 	filter = fb.And(
 		fb.Eq("id", msgUpdated.Header.ID.String()),
 		fb.Eq("group", gid2),
+		fb.Contains("labels", "disputed"),
 	)
 	msgs, _, err = s.GetMessages(ctx, filter)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(msgs))
 	assert.Equal(t, *bid2, *msgs[0].BatchID)
+	assert.Equal(t, fftypes.FFStringArray{"reviewed", "disputed"}, msgs[0].Labels)
+
+	// Labels are not changed by an upsert of the message, such as when it is received back in a batch
+	err = s.UpsertMessage(context.Background(), msgUpdated, database.UpsertOptimizationExisting)
+	assert.NoError(t, err)
+	msgRead, err = s.GetMessageByID(ctx, msgUpdated.Header.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.FFStringArray{"reviewed", "disputed"}, msgRead.Labels)
+	msgUpdated.Labels = msgRead.Labels
 
 	// Bump and Update - this is for a ready transition
 	msgUpdated.State = fftypes.MessageStateReady
@@ -528,7 +545,7 @@ func TestGetMessageByIDLoadRefsFail(t *testing.T) {
 	cols := append([]string{}, msgColumns...)
 	cols = append(cols, "id()")
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(cols).
-		AddRow(msgID.String(), nil, fftypes.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "pin", nil, 0))
+		AddRow(msgID.String(), nil, fftypes.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "pin", nil, "", 0, "", nil, 0))
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	_, err := s.GetMessageByID(context.Background(), msgID)
 	assert.Regexp(t, "FF10115", err)
@@ -575,7 +592,7 @@ func TestGetMessagesLoadRefsFail(t *testing.T) {
 	cols := append([]string{}, msgColumns...)
 	cols = append(cols, "id()")
 	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(cols).
-		AddRow(msgID.String(), nil, fftypes.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "pin", nil, 0))
+		AddRow(msgID.String(), nil, fftypes.MessageTypeBroadcast, "author1", "0x12345", 0, "ns1", "t1", "c1", nil, b32.String(), b32.String(), b32.String(), "confirmed", 0, "pin", nil, "", 0, "", nil, 0))
 	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
 	f := database.MessageQueryFactory.NewFilter(context.Background()).Gt("confirmed", "0")
 	_, _, err := s.GetMessages(context.Background(), f)
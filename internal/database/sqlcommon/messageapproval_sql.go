@@ -0,0 +1,115 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	messageApprovalColumns = []string{
+		"id",
+		"namespace",
+		"message_id",
+		"policy",
+		"approver",
+		"comment",
+		"created",
+	}
+	messageApprovalFilterFieldMap = map[string]string{
+		"message": "message_id",
+	}
+)
+
+func (s *SQLCommon) InsertMessageApproval(ctx context.Context, approval *fftypes.MessageApproval) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	approval.Created = fftypes.Now()
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("messageapprovals").
+			Columns(messageApprovalColumns...).
+			Values(
+				approval.ID,
+				approval.Namespace,
+				approval.Message,
+				approval.Policy,
+				approval.Approver,
+				approval.Comment,
+				approval.Created,
+			),
+		func() {
+			s.callbacks.UUIDCollectionNSEvent(database.CollectionMessageApprovals, fftypes.ChangeEventTypeCreated, approval.Namespace, approval.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) messageApprovalResult(ctx context.Context, row *sql.Rows) (*fftypes.MessageApproval, error) {
+	var approval fftypes.MessageApproval
+	err := row.Scan(
+		&approval.ID,
+		&approval.Namespace,
+		&approval.Message,
+		&approval.Policy,
+		&approval.Approver,
+		&approval.Comment,
+		&approval.Created,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "messageapprovals")
+	}
+	return &approval, nil
+}
+
+func (s *SQLCommon) GetMessageApprovals(ctx context.Context, filter database.Filter) ([]*fftypes.MessageApproval, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(messageApprovalColumns...).From("messageapprovals"),
+		filter, messageApprovalFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	approvals := []*fftypes.MessageApproval{}
+	for rows.Next() {
+		approval, err := s.messageApprovalResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		approvals = append(approvals, approval)
+	}
+
+	return approvals, s.queryRes(ctx, tx, "messageapprovals", fop, fi), err
+}
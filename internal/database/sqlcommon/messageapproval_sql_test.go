@@ -0,0 +1,129 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMessageApprovalsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new approval entry
+	approval := &fftypes.MessageApproval{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Message:   fftypes.NewUUID(),
+		Policy:    "payments",
+		Approver:  "alice",
+		Comment:   "looks good",
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionMessageApprovals, fftypes.ChangeEventTypeCreated, "ns1", approval.ID, mock.Anything).Return()
+
+	err := s.InsertMessageApproval(ctx, approval)
+	assert.NoError(t, err)
+	assert.NotNil(t, approval.Created)
+	approvalJson, _ := json.Marshal(&approval)
+
+	// Query back the approval
+	fb := database.MessageApprovalQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("message", approval.Message),
+		fb.Eq("approver", "alice"),
+	)
+	approvals, res, err := s.GetMessageApprovals(ctx, filter.Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(approvals))
+	assert.Equal(t, int64(1), *res.TotalCount)
+	approvalReadJson, _ := json.Marshal(approvals[0])
+	assert.Equal(t, string(approvalJson), string(approvalReadJson))
+
+	// The same approver cannot approve the same message twice
+	err = s.InsertMessageApproval(ctx, &fftypes.MessageApproval{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Message:   approval.Message,
+		Policy:    "payments",
+		Approver:  "alice",
+	})
+	assert.Regexp(t, "FF10116", err)
+}
+
+func TestInsertMessageApprovalFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertMessageApproval(context.Background(), &fftypes.MessageApproval{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertMessageApprovalFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertMessageApproval(context.Background(), &fftypes.MessageApproval{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertMessageApprovalFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertMessageApproval(context.Background(), &fftypes.MessageApproval{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageApprovalsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.MessageApprovalQueryFactory.NewFilter(context.Background()).Eq("id", "")
+	_, _, err := s.GetMessageApprovals(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageApprovalsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.MessageApprovalQueryFactory.NewFilter(context.Background()).Eq("id", map[bool]bool{true: false})
+	_, _, err := s.GetMessageApprovals(context.Background(), f)
+	assert.Regexp(t, "FF10149.*id", err)
+}
+
+func TestGetMessageApprovalsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.MessageApprovalQueryFactory.NewFilter(context.Background()).Eq("id", "")
+	_, _, err := s.GetMessageApprovals(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,147 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	messageStateColumns = []string{
+		"id",
+		"namespace",
+		"state",
+		"confirmed",
+		"batch_id",
+		"tx_id",
+		"reply_count",
+		"updated",
+	}
+	messageStateFilterFieldMap = map[string]string{
+		"batch":      "batch_id",
+		"tx":         "tx_id",
+		"replycount": "reply_count",
+	}
+)
+
+// UpsertMessageState writes the whole record, as the aggregator calculates the reply count from the existing
+// record within the same transaction. There are no change events, as the record is derived from the message.
+func (s *SQLCommon) UpsertMessageState(ctx context.Context, state *fftypes.MessageStateRecord) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	rows, _, err := s.queryTx(ctx, tx,
+		sq.Select("id").
+			From("messagestates").
+			Where(sq.Eq{"id": state.ID}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := rows.Next()
+	rows.Close()
+
+	state.Updated = fftypes.Now()
+	if existing {
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("messagestates").
+				Set("state", state.State).
+				Set("confirmed", state.Confirmed).
+				Set("batch_id", state.Batch).
+				Set("tx_id", state.TX).
+				Set("reply_count", state.ReplyCount).
+				Set("updated", state.Updated).
+				Where(sq.Eq{"id": state.ID}),
+			nil,
+		); err != nil {
+			return err
+		}
+	} else {
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("messagestates").
+				Columns(messageStateColumns...).
+				Values(
+					state.ID,
+					state.Namespace,
+					state.State,
+					state.Confirmed,
+					state.Batch,
+					state.TX,
+					state.ReplyCount,
+					state.Updated,
+				),
+			nil,
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) messageStateResult(ctx context.Context, row *sql.Rows) (*fftypes.MessageStateRecord, error) {
+	var state fftypes.MessageStateRecord
+	err := row.Scan(
+		&state.ID,
+		&state.Namespace,
+		&state.State,
+		&state.Confirmed,
+		&state.Batch,
+		&state.TX,
+		&state.ReplyCount,
+		&state.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "messagestates")
+	}
+	return &state, nil
+}
+
+func (s *SQLCommon) GetMessageStates(ctx context.Context, filter database.Filter) ([]*fftypes.MessageStateRecord, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(messageStateColumns...).From("messagestates"),
+		filter, messageStateFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	states := []*fftypes.MessageStateRecord{}
+	for rows.Next() {
+		state, err := s.messageStateResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		states = append(states, state)
+	}
+
+	return states, s.queryRes(ctx, tx, "messagestates", fop, fi), err
+}
@@ -0,0 +1,144 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageStateE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// A reply confirmed before its request creates the record with just a reply count
+	state := &fftypes.MessageStateRecord{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns1",
+		ReplyCount: 1,
+	}
+	err := s.UpsertMessageState(ctx, state)
+	assert.NoError(t, err)
+	assert.NotNil(t, state.Updated)
+
+	// Then the request is confirmed
+	state.State = fftypes.MessageStateConfirmed
+	state.Confirmed = fftypes.Now()
+	state.Batch = fftypes.NewUUID()
+	state.TX = fftypes.NewUUID()
+	err = s.UpsertMessageState(ctx, state)
+	assert.NoError(t, err)
+	stateJson, _ := json.Marshal(&state)
+
+	// Query back with a filter
+	fb := database.MessageStateQueryFactory.NewFilter(ctx)
+	results, res, err := s.GetMessageStates(ctx, fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("state", fftypes.MessageStateConfirmed),
+		fb.Eq("batch", state.Batch),
+		fb.Eq("tx", state.TX),
+		fb.Eq("replycount", 1),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	stateReadJson, _ := json.Marshal(results[0])
+	assert.Equal(t, string(stateJson), string(stateReadJson))
+}
+
+func TestUpsertMessageStateFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertMessageState(context.Background(), &fftypes.MessageStateRecord{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageStateFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertMessageState(context.Background(), &fftypes.MessageStateRecord{})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageStateFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertMessageState(context.Background(), &fftypes.MessageStateRecord{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageStateFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(fftypes.NewUUID()))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertMessageState(context.Background(), &fftypes.MessageStateRecord{})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageStateFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertMessageState(context.Background(), &fftypes.MessageStateRecord{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageStatesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.MessageStateQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetMessageStates(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageStatesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.MessageStateQueryFactory.NewFilter(context.Background()).Eq("state", map[bool]bool{true: false})
+	_, _, err := s.GetMessageStates(context.Background(), f)
+	assert.Regexp(t, "FF10149.*state", err)
+}
+
+func TestGetMessageStatesScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.MessageStateQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetMessageStates(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
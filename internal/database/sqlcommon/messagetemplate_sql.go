@@ -0,0 +1,206 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	messageTemplateColumns = []string{
+		"id",
+		"namespace",
+		"name",
+		"description",
+		"header",
+		"msg_group",
+		"data",
+		"created",
+		"updated",
+	}
+	messageTemplateFilterFieldMap = map[string]string{}
+)
+
+func (s *SQLCommon) UpsertMessageTemplate(ctx context.Context, template *fftypes.MessageTemplate) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	// Do a select within the transaction to determine if the name is already in use
+	templateRows, _, err := s.queryTx(ctx, tx,
+		sq.Select("id", "created").
+			From("messagetemplates").
+			Where(sq.Eq{
+				"namespace": template.Namespace,
+				"name":      template.Name,
+			}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := templateRows.Next()
+	if existing {
+		template.ID = &fftypes.UUID{}
+		template.Created = &fftypes.FFTime{}
+		_ = templateRows.Scan(template.ID, template.Created)
+	}
+	templateRows.Close()
+
+	if existing {
+		template.Updated = fftypes.Now()
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("messagetemplates").
+				// Note we do not update ID or created
+				Set("description", template.Description).
+				Set("header", template.Header).
+				Set("msg_group", template.Group).
+				Set("data", template.Data).
+				Set("updated", template.Updated).
+				Where(sq.Eq{"id": template.ID}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionMessageTemplates, fftypes.ChangeEventTypeUpdated, template.Namespace, template.ID)
+			},
+		); err != nil {
+			return err
+		}
+	} else {
+		template.ID = fftypes.NewUUID()
+		template.Created = fftypes.Now()
+		template.Updated = nil
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("messagetemplates").
+				Columns(messageTemplateColumns...).
+				Values(
+					template.ID,
+					template.Namespace,
+					template.Name,
+					template.Description,
+					template.Header,
+					template.Group,
+					template.Data,
+					template.Created,
+					template.Updated,
+				),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionMessageTemplates, fftypes.ChangeEventTypeCreated, template.Namespace, template.ID)
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) messageTemplateResult(ctx context.Context, row *sql.Rows) (*fftypes.MessageTemplate, error) {
+	var template fftypes.MessageTemplate
+	err := row.Scan(
+		&template.ID,
+		&template.Namespace,
+		&template.Name,
+		&template.Description,
+		&template.Header,
+		&template.Group,
+		&template.Data,
+		&template.Created,
+		&template.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "messagetemplates")
+	}
+	return &template, nil
+}
+
+func (s *SQLCommon) getMessageTemplateEq(ctx context.Context, eq sq.Eq, textName string) (*fftypes.MessageTemplate, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(messageTemplateColumns...).
+			From("messagetemplates").
+			Where(eq),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Message template '%s' not found", textName)
+		return nil, nil
+	}
+
+	return s.messageTemplateResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetMessageTemplateByName(ctx context.Context, ns, name string) (*fftypes.MessageTemplate, error) {
+	return s.getMessageTemplateEq(ctx, sq.Eq{"namespace": ns, "name": name}, fmt.Sprintf("%s:%s", ns, name))
+}
+
+func (s *SQLCommon) GetMessageTemplates(ctx context.Context, filter database.Filter) ([]*fftypes.MessageTemplate, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(messageTemplateColumns...).From("messagetemplates"),
+		filter, messageTemplateFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	templates := []*fftypes.MessageTemplate{}
+	for rows.Next() {
+		t, err := s.messageTemplateResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, s.queryRes(ctx, tx, "messagetemplates", fop, fi), err
+}
+
+func (s *SQLCommon) DeleteMessageTemplate(ctx context.Context, id *fftypes.UUID) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	template, err := s.getMessageTemplateEq(ctx, sq.Eq{"id": id}, id.String())
+	if err == nil && template != nil {
+		err = s.deleteTx(ctx, tx, sq.Delete("messagetemplates").Where(sq.Eq{"id": id}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionMessageTemplates, fftypes.ChangeEventTypeDeleted, template.Namespace, template.ID)
+			})
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
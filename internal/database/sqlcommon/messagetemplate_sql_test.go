@@ -0,0 +1,236 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMessageTemplatesE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new message template
+	template := &fftypes.MessageTemplate{
+		Namespace:   "ns1",
+		Name:        "widget",
+		Description: "Widget updates",
+		Header: fftypes.MessageTemplateHeader{
+			Topics: fftypes.FFStringArray{"widgets"},
+			Tag:    "widget_update",
+		},
+		Data: fftypes.InlineData{
+			{
+				Datatype: &fftypes.DatatypeRef{Name: "widget", Version: "1.0"},
+				Value:    fftypes.JSONAnyPtr(`{"color":"red"}`),
+			},
+		},
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionMessageTemplates, fftypes.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionMessageTemplates, fftypes.ChangeEventTypeUpdated, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionMessageTemplates, fftypes.ChangeEventTypeDeleted, "ns1", mock.Anything, mock.Anything).Return()
+
+	err := s.UpsertMessageTemplate(ctx, template)
+	assert.NoError(t, err)
+	assert.NotNil(t, template.ID)
+	assert.NotNil(t, template.Created)
+	assert.Nil(t, template.Updated)
+	templateJson, _ := json.Marshal(&template)
+
+	// Query back the message template
+	templateRead, err := s.GetMessageTemplateByName(ctx, "ns1", "widget")
+	assert.NoError(t, err)
+	templateReadJson, _ := json.Marshal(&templateRead)
+	assert.Equal(t, string(templateJson), string(templateReadJson))
+
+	// Replace the message template, keeping the ID
+	template2 := &fftypes.MessageTemplate{
+		Namespace: "ns1",
+		Name:      "widget",
+		Header: fftypes.MessageTemplateHeader{
+			Tag: "widget_update",
+		},
+		Group: &fftypes.InputGroup{
+			Members: []fftypes.MemberInput{{Identity: "org1"}},
+		},
+	}
+	err = s.UpsertMessageTemplate(ctx, template2)
+	assert.NoError(t, err)
+	assert.Equal(t, *template.ID, *template2.ID)
+	assert.Equal(t, template.Created.String(), template2.Created.String())
+	assert.NotNil(t, template2.Updated)
+
+	// Query back with a filter
+	fb := database.MessageTemplateQueryFactory.NewFilter(ctx)
+	templates, res, err := s.GetMessageTemplates(ctx, fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("name", "widget"),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	template2Json, _ := json.Marshal(&template2)
+	templateReadJson, _ = json.Marshal(templates[0])
+	assert.Equal(t, string(template2Json), string(templateReadJson))
+
+	// Delete the message template
+	err = s.DeleteMessageTemplate(ctx, template.ID)
+	assert.NoError(t, err)
+	templateRead, err = s.GetMessageTemplateByName(ctx, "ns1", "widget")
+	assert.NoError(t, err)
+	assert.Nil(t, templateRead)
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestUpsertMessageTemplateFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertMessageTemplate(context.Background(), &fftypes.MessageTemplate{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageTemplateFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertMessageTemplate(context.Background(), &fftypes.MessageTemplate{Name: "template1"})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageTemplateFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertMessageTemplate(context.Background(), &fftypes.MessageTemplate{Name: "template1"})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageTemplateFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).
+		AddRow(fftypes.NewUUID(), fftypes.Now()))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertMessageTemplate(context.Background(), &fftypes.MessageTemplate{Name: "template1"})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertMessageTemplateFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertMessageTemplate(context.Background(), &fftypes.MessageTemplate{Name: "template1"})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageTemplateByNameSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetMessageTemplateByName(context.Background(), "ns1", "template1")
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageTemplateByNameNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(messageTemplateColumns))
+	template, err := s.GetMessageTemplateByName(context.Background(), "ns1", "template1")
+	assert.NoError(t, err)
+	assert.Nil(t, template)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageTemplateByNameScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetMessageTemplateByName(context.Background(), "ns1", "template1")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageTemplatesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.MessageTemplateQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetMessageTemplates(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMessageTemplatesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.MessageTemplateQueryFactory.NewFilter(context.Background()).Eq("name", map[bool]bool{true: false})
+	_, _, err := s.GetMessageTemplates(context.Background(), f)
+	assert.Regexp(t, "FF10149.*name", err)
+}
+
+func TestGetMessageTemplatesScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.MessageTemplateQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetMessageTemplates(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteMessageTemplateBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteMessageTemplate(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeleteMessageTemplateSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteMessageTemplate(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+}
+
+func TestDeleteMessageTemplateFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(messageTemplateColumns).AddRow(
+		fftypes.NewUUID(), "ns1", "template1", "", "{}", nil, "[]", fftypes.Now(), nil),
+	)
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteMessageTemplate(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+}
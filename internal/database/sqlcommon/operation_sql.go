@@ -19,6 +19,7 @@ package sqlcommon
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/hyperledger/firefly/internal/i18n"
@@ -41,12 +42,15 @@ var (
 		"input",
 		"output",
 		"retry_id",
+		"receipt",
+		"blockchain_tx",
 	}
 	opFilterFieldMap = map[string]string{
-		"tx":     "tx_id",
-		"type":   "optype",
-		"status": "opstatus",
-		"retry":  "retry_id",
+		"tx":           "tx_id",
+		"type":         "optype",
+		"status":       "opstatus",
+		"retry":        "retry_id",
+		"blockchaintx": "blockchain_tx",
 	}
 )
 
@@ -73,6 +77,8 @@ func (s *SQLCommon) InsertOperation(ctx context.Context, operation *fftypes.Oper
 				operation.Input,
 				operation.Output,
 				operation.Retry,
+				operation.Receipt,
+				operation.BlockchainTX,
 			),
 		func() {
 			s.callbacks.UUIDCollectionNSEvent(database.CollectionOperations, fftypes.ChangeEventTypeCreated, operation.Namespace, operation.ID)
@@ -99,6 +105,8 @@ func (s *SQLCommon) opResult(ctx context.Context, row *sql.Rows) (*fftypes.Opera
 		&op.Input,
 		&op.Output,
 		&op.Retry,
+		&op.Receipt,
+		&op.BlockchainTX,
 	)
 	if err != nil {
 		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "operations")
@@ -188,3 +196,13 @@ func (s *SQLCommon) ResolveOperation(ctx context.Context, id *fftypes.UUID, stat
 	}
 	return s.UpdateOperation(ctx, id, update)
 }
+
+func (s *SQLCommon) SetOperationReceipt(ctx context.Context, id *fftypes.UUID, receipt *fftypes.BlockchainReceipt) (err error) {
+	b, _ := json.Marshal(receipt)
+	return s.UpdateOperation(ctx, id, database.OperationQueryFactory.NewUpdate(ctx).Set("receipt", b))
+}
+
+func (s *SQLCommon) SetOperationBlockchainTX(ctx context.Context, id *fftypes.UUID, blockchainTX *fftypes.BlockchainTransaction) (err error) {
+	b, _ := json.Marshal(blockchainTX)
+	return s.UpdateOperation(ctx, id, database.OperationQueryFactory.NewUpdate(ctx).Set("blockchaintx", b))
+}
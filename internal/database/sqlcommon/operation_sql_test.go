@@ -103,6 +103,40 @@ func TestOperationE2EWithDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(operations))
 
+	// Record the receipt
+	blockNumber := uint64(12345)
+	receipt := &fftypes.BlockchainReceipt{
+		BlockNumber:       &blockNumber,
+		GasUsed:           fftypes.NewFFBigInt(21000),
+		EffectiveGasPrice: fftypes.NewFFBigInt(10),
+		Fee:               fftypes.NewFFBigInt(210000),
+	}
+	err = s.SetOperationReceipt(ctx, operation.ID, receipt)
+	assert.NoError(t, err)
+	operationRead, err = s.GetOperationByID(ctx, operation.ID)
+	assert.NoError(t, err)
+	receiptJson, _ := json.Marshal(&receipt)
+	receiptReadJson, _ := json.Marshal(operationRead.Receipt)
+	assert.Equal(t, string(receiptJson), string(receiptReadJson))
+
+	// Record the blockchain transaction
+	blockchainTX := &fftypes.BlockchainTransaction{
+		ID:     "0x12345",
+		Signer: "0xabcde",
+		Status: fftypes.OpStatusSucceeded,
+		Finality: &fftypes.BlockchainFinality{
+			BlockNumber: &blockNumber,
+			Final:       true,
+		},
+	}
+	err = s.SetOperationBlockchainTX(ctx, operation.ID, blockchainTX)
+	assert.NoError(t, err)
+	operationRead, err = s.GetOperationByID(ctx, operation.ID)
+	assert.NoError(t, err)
+	blockchainTXJson, _ := json.Marshal(&blockchainTX)
+	blockchainTXReadJson, _ := json.Marshal(operationRead.BlockchainTX)
+	assert.Equal(t, string(blockchainTXJson), string(blockchainTXReadJson))
+
 	s.callbacks.AssertExpectations(t)
 }
 
@@ -0,0 +1,166 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	pingColumns = []string{
+		"id",
+		"target",
+		"state",
+		"reply_id",
+		"created",
+		"confirmed",
+		"completed",
+		"chain_latency",
+		"round_trip",
+	}
+	pingFilterFieldMap = map[string]string{
+		"reply": "reply_id",
+	}
+)
+
+func (s *SQLCommon) InsertPing(ctx context.Context, ping *fftypes.Ping) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("pings").
+			Columns(pingColumns...).
+			Values(
+				ping.ID,
+				ping.Target,
+				ping.State,
+				ping.Reply,
+				ping.Created,
+				ping.Confirmed,
+				ping.Completed,
+				&ping.ChainLatency,
+				&ping.RoundTrip,
+			),
+		func() {
+			s.callbacks.UUIDCollectionEvent(database.CollectionPings, fftypes.ChangeEventTypeCreated, ping.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) UpdatePing(ctx context.Context, ping *fftypes.Ping) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	if _, err = s.updateTx(ctx, tx,
+		sq.Update("pings").
+			Set("state", ping.State).
+			Set("reply_id", ping.Reply).
+			Set("confirmed", ping.Confirmed).
+			Set("completed", ping.Completed).
+			Set("chain_latency", &ping.ChainLatency).
+			Set("round_trip", &ping.RoundTrip).
+			Where(sq.Eq{"id": ping.ID}),
+		func() {
+			s.callbacks.UUIDCollectionEvent(database.CollectionPings, fftypes.ChangeEventTypeUpdated, ping.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) pingResult(ctx context.Context, row *sql.Rows) (*fftypes.Ping, error) {
+	var ping fftypes.Ping
+	err := row.Scan(
+		&ping.ID,
+		&ping.Target,
+		&ping.State,
+		&ping.Reply,
+		&ping.Created,
+		&ping.Confirmed,
+		&ping.Completed,
+		&ping.ChainLatency,
+		&ping.RoundTrip,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "pings")
+	}
+	return &ping, nil
+}
+
+func (s *SQLCommon) GetPingByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Ping, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(pingColumns...).
+			From("pings").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Ping '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.pingResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetPings(ctx context.Context, filter database.Filter) ([]*fftypes.Ping, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(pingColumns...).From("pings"),
+		filter, pingFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	pings := []*fftypes.Ping{}
+	for rows.Next() {
+		ping, err := s.pingResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		pings = append(pings, ping)
+	}
+
+	return pings, s.queryRes(ctx, tx, "pings", fop, fi), err
+}
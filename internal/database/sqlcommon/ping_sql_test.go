@@ -0,0 +1,189 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPingsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Insert a ping
+	ping := &fftypes.Ping{
+		ID:      fftypes.NewUUID(),
+		Target:  "did:firefly:org/org2",
+		State:   fftypes.PingStatePending,
+		Created: fftypes.Now(),
+	}
+
+	s.callbacks.On("UUIDCollectionEvent", database.CollectionPings, fftypes.ChangeEventTypeCreated, ping.ID, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionEvent", database.CollectionPings, fftypes.ChangeEventTypeUpdated, ping.ID, mock.Anything).Return()
+
+	err := s.InsertPing(ctx, ping)
+	assert.NoError(t, err)
+	pingJson, _ := json.Marshal(&ping)
+
+	// Query back the ping
+	pingRead, err := s.GetPingByID(ctx, ping.ID)
+	assert.NoError(t, err)
+	pingReadJson, _ := json.Marshal(&pingRead)
+	assert.Equal(t, string(pingJson), string(pingReadJson))
+
+	// Update the ping
+	ping.State = fftypes.PingStateCompleted
+	ping.Reply = fftypes.NewUUID()
+	ping.Confirmed = fftypes.Now()
+	ping.Completed = fftypes.Now()
+	ping.ChainLatency = fftypes.FFDuration(1500 * time.Millisecond)
+	ping.RoundTrip = fftypes.FFDuration(4 * time.Second)
+	err = s.UpdatePing(ctx, ping)
+	assert.NoError(t, err)
+	pingJson, _ = json.Marshal(&ping)
+
+	// Query back with a filter
+	fb := database.PingQueryFactory.NewFilter(ctx)
+	pings, res, err := s.GetPings(ctx, fb.And(
+		fb.Eq("state", fftypes.PingStateCompleted),
+		fb.Eq("target", "did:firefly:org/org2"),
+		fb.Eq("reply", ping.Reply),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	pingReadJson, _ = json.Marshal(pings[0])
+	assert.Equal(t, string(pingJson), string(pingReadJson))
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestInsertPingFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertPing(context.Background(), &fftypes.Ping{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertPingFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertPing(context.Background(), &fftypes.Ping{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertPingFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertPing(context.Background(), &fftypes.Ping{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePingFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpdatePing(context.Background(), &fftypes.Ping{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePingFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpdatePing(context.Background(), &fftypes.Ping{})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePingFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpdatePing(context.Background(), &fftypes.Ping{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPingByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetPingByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPingByIDNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(pingColumns))
+	invitation, err := s.GetPingByID(context.Background(), fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, invitation)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPingByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetPingByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPingsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.PingQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetPings(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPingsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.PingQueryFactory.NewFilter(context.Background()).Eq("state", map[bool]bool{true: false})
+	_, _, err := s.GetPings(context.Background(), f)
+	assert.Regexp(t, "FF10149.*state", err)
+}
+
+func TestGetPingsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.PingQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetPings(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
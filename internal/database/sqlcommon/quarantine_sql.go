@@ -0,0 +1,154 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	quarantineColumns = []string{
+		"id",
+		"namespace",
+		"message_id",
+		"batch_id",
+		"reason",
+		"created",
+	}
+	quarantineFilterFieldMap = map[string]string{
+		"message": "message_id",
+		"batch":   "batch_id",
+	}
+)
+
+func (s *SQLCommon) InsertQuarantinedMessage(ctx context.Context, quarantined *fftypes.QuarantinedMessage) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	quarantined.Created = fftypes.Now()
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("quarantine").
+			Columns(quarantineColumns...).
+			Values(
+				quarantined.ID,
+				quarantined.Namespace,
+				quarantined.Message,
+				quarantined.Batch,
+				quarantined.Reason,
+				quarantined.Created,
+			),
+		func() {
+			s.callbacks.UUIDCollectionNSEvent(database.CollectionQuarantine, fftypes.ChangeEventTypeCreated, quarantined.Namespace, quarantined.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) quarantineResult(ctx context.Context, row *sql.Rows) (*fftypes.QuarantinedMessage, error) {
+	var quarantined fftypes.QuarantinedMessage
+	err := row.Scan(
+		&quarantined.ID,
+		&quarantined.Namespace,
+		&quarantined.Message,
+		&quarantined.Batch,
+		&quarantined.Reason,
+		&quarantined.Created,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "quarantine")
+	}
+	return &quarantined, nil
+}
+
+func (s *SQLCommon) GetQuarantinedMessageByID(ctx context.Context, id *fftypes.UUID) (*fftypes.QuarantinedMessage, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(quarantineColumns...).
+			From("quarantine").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Quarantined message '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.quarantineResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetQuarantinedMessages(ctx context.Context, filter database.Filter) ([]*fftypes.QuarantinedMessage, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(quarantineColumns...).From("quarantine"),
+		filter, quarantineFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	quarantined := []*fftypes.QuarantinedMessage{}
+	for rows.Next() {
+		q, err := s.quarantineResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		quarantined = append(quarantined, q)
+	}
+
+	return quarantined, s.queryRes(ctx, tx, "quarantine", fop, fi), err
+}
+
+func (s *SQLCommon) DeleteQuarantinedMessage(ctx context.Context, id *fftypes.UUID) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	quarantined, err := s.GetQuarantinedMessageByID(ctx, id)
+	if err == nil && quarantined != nil {
+		err = s.deleteTx(ctx, tx, sq.Delete("quarantine").Where(sq.Eq{"id": id}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionQuarantine, fftypes.ChangeEventTypeDeleted, quarantined.Namespace, quarantined.ID)
+			})
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
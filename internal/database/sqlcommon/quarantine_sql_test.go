@@ -0,0 +1,175 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestQuarantineE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Quarantine a message
+	quarantined := &fftypes.QuarantinedMessage{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Message:   fftypes.NewUUID(),
+		Batch:     fftypes.NewUUID(),
+		Reason:    "FF10198: Datatype validation failed",
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionQuarantine, fftypes.ChangeEventTypeCreated, "ns1", quarantined.ID, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionQuarantine, fftypes.ChangeEventTypeDeleted, "ns1", quarantined.ID, mock.Anything).Return()
+
+	err := s.InsertQuarantinedMessage(ctx, quarantined)
+	assert.NoError(t, err)
+	assert.NotNil(t, quarantined.Created)
+	quarantinedJson, _ := json.Marshal(&quarantined)
+
+	// Query back by ID
+	quarantinedRead, err := s.GetQuarantinedMessageByID(ctx, quarantined.ID)
+	assert.NoError(t, err)
+	quarantinedReadJson, _ := json.Marshal(&quarantinedRead)
+	assert.Equal(t, string(quarantinedJson), string(quarantinedReadJson))
+
+	// Query back with a filter
+	fb := database.QuarantineQueryFactory.NewFilter(ctx)
+	results, res, err := s.GetQuarantinedMessages(ctx, fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("message", quarantined.Message),
+		fb.Eq("batch", quarantined.Batch),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	quarantinedReadJson, _ = json.Marshal(results[0])
+	assert.Equal(t, string(quarantinedJson), string(quarantinedReadJson))
+
+	// Delete once reprocessed
+	err = s.DeleteQuarantinedMessage(ctx, quarantined.ID)
+	assert.NoError(t, err)
+	quarantinedRead, err = s.GetQuarantinedMessageByID(ctx, quarantined.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, quarantinedRead)
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestInsertQuarantinedMessageFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertQuarantinedMessage(context.Background(), &fftypes.QuarantinedMessage{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertQuarantinedMessageFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertQuarantinedMessage(context.Background(), &fftypes.QuarantinedMessage{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertQuarantinedMessageFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertQuarantinedMessage(context.Background(), &fftypes.QuarantinedMessage{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetQuarantinedMessageByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetQuarantinedMessageByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetQuarantinedMessageByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetQuarantinedMessageByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetQuarantinedMessagesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.QuarantineQueryFactory.NewFilter(context.Background()).Eq("reason", "")
+	_, _, err := s.GetQuarantinedMessages(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetQuarantinedMessagesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.QuarantineQueryFactory.NewFilter(context.Background()).Eq("reason", map[bool]bool{true: false})
+	_, _, err := s.GetQuarantinedMessages(context.Background(), f)
+	assert.Regexp(t, "FF10149.*reason", err)
+}
+
+func TestGetQuarantinedMessagesScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.QuarantineQueryFactory.NewFilter(context.Background()).Eq("reason", "")
+	_, _, err := s.GetQuarantinedMessages(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteQuarantinedMessageBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteQuarantinedMessage(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeleteQuarantinedMessageSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteQuarantinedMessage(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+}
+
+func TestDeleteQuarantinedMessageFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(quarantineColumns).AddRow(
+		fftypes.NewUUID(), "ns1", fftypes.NewUUID(), nil, "reason", fftypes.Now()),
+	)
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteQuarantinedMessage(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+}
@@ -0,0 +1,136 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	reportColumns = []string{
+		"id",
+		"namespace",
+		"period",
+		"period_start",
+		"period_end",
+		"created",
+		"metrics",
+	}
+	reportFilterFieldMap = map[string]string{
+		"start": "period_start",
+		"end":   "period_end",
+	}
+)
+
+func (s *SQLCommon) InsertReport(ctx context.Context, report *fftypes.Report) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	report.Created = fftypes.Now()
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("reports").
+			Columns(reportColumns...).
+			Values(
+				report.ID,
+				report.Namespace,
+				report.Period,
+				report.Start,
+				report.End,
+				report.Created,
+				report.Metrics,
+			),
+		func() {
+			s.callbacks.UUIDCollectionNSEvent(database.CollectionReports, fftypes.ChangeEventTypeCreated, report.Namespace, report.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) reportResult(ctx context.Context, row *sql.Rows) (*fftypes.Report, error) {
+	var report fftypes.Report
+	err := row.Scan(
+		&report.ID,
+		&report.Namespace,
+		&report.Period,
+		&report.Start,
+		&report.End,
+		&report.Created,
+		&report.Metrics,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "reports")
+	}
+	return &report, nil
+}
+
+func (s *SQLCommon) GetReportByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Report, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(reportColumns...).
+			From("reports").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Report '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.reportResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetReports(ctx context.Context, filter database.Filter) ([]*fftypes.Report, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(reportColumns...).From("reports"),
+		filter, reportFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	reports := []*fftypes.Report{}
+	for rows.Next() {
+		r, err := s.reportResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		reports = append(reports, r)
+	}
+
+	return reports, s.queryRes(ctx, tx, "reports", fop, fi), err
+}
@@ -0,0 +1,167 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReportsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Insert a report
+	report := &fftypes.Report{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Period:    fftypes.ReportPeriodMonthly,
+		Start:     fftypes.UnixTime(1643673600),
+		End:       fftypes.UnixTime(1646092800),
+		Metrics: &fftypes.ReportMetrics{
+			Counterparties: []*fftypes.ReportCounterparty{
+				{Identity: "did:firefly:org/org1", Name: "org1", Messages: 10},
+			},
+			Operations: []*fftypes.ReportOperations{},
+			Storage:    fftypes.ReportStorage{Messages: 10, Data: 10},
+		},
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionReports, fftypes.ChangeEventTypeCreated, "ns1", report.ID, mock.Anything).Return()
+
+	err := s.InsertReport(ctx, report)
+	assert.NoError(t, err)
+	assert.NotNil(t, report.Created)
+	reportJson, _ := json.Marshal(&report)
+
+	// Query back the report
+	reportRead, err := s.GetReportByID(ctx, report.ID)
+	assert.NoError(t, err)
+	reportReadJson, _ := json.Marshal(&reportRead)
+	assert.Equal(t, string(reportJson), string(reportReadJson))
+
+	// Query back with a filter
+	fb := database.ReportQueryFactory.NewFilter(ctx)
+	reports, res, err := s.GetReports(ctx, fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("period", fftypes.ReportPeriodMonthly),
+		fb.Eq("start", report.Start),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	reportReadJson, _ = json.Marshal(reports[0])
+	assert.Equal(t, string(reportJson), string(reportReadJson))
+
+	// A second report for the same period is rejected
+	err = s.InsertReport(ctx, &fftypes.Report{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Period:    fftypes.ReportPeriodMonthly,
+		Start:     report.Start,
+		End:       report.End,
+	})
+	assert.Regexp(t, "FF10116", err)
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestInsertReportFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertReport(context.Background(), &fftypes.Report{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertReportFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertReport(context.Background(), &fftypes.Report{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertReportFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertReport(context.Background(), &fftypes.Report{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReportByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetReportByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReportByIDNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(reportColumns))
+	report, err := s.GetReportByID(context.Background(), fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, report)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReportByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetReportByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReportsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.ReportQueryFactory.NewFilter(context.Background()).Eq("period", "")
+	_, _, err := s.GetReports(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReportsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.ReportQueryFactory.NewFilter(context.Background()).Eq("period", map[bool]bool{true: false})
+	_, _, err := s.GetReports(context.Background(), f)
+	assert.Regexp(t, "FF10149.*period", err)
+}
+
+func TestGetReportsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.ReportQueryFactory.NewFilter(context.Background()).Eq("period", "")
+	_, _, err := s.GetReports(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,202 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	savedQueryColumns = []string{
+		"id",
+		"namespace",
+		"name",
+		"description",
+		"collection",
+		"filter",
+		"created",
+		"updated",
+	}
+	savedQueryFilterFieldMap = map[string]string{}
+)
+
+func (s *SQLCommon) UpsertSavedQuery(ctx context.Context, query *fftypes.SavedQuery) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	// Do a select within the transaction to determine if the name is already in use
+	queryRows, _, err := s.queryTx(ctx, tx,
+		sq.Select("id", "created").
+			From("savedqueries").
+			Where(sq.Eq{
+				"namespace": query.Namespace,
+				"name":      query.Name,
+			}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := queryRows.Next()
+	if existing {
+		query.ID = &fftypes.UUID{}
+		query.Created = &fftypes.FFTime{}
+		_ = queryRows.Scan(query.ID, query.Created)
+	}
+	queryRows.Close()
+
+	if existing {
+		query.Updated = fftypes.Now()
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("savedqueries").
+				// Note we do not update ID or created
+				Set("description", query.Description).
+				Set("collection", query.Collection).
+				Set("filter", query.Filter).
+				Set("updated", query.Updated).
+				Where(sq.Eq{"id": query.ID}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionSavedQueries, fftypes.ChangeEventTypeUpdated, query.Namespace, query.ID)
+			},
+		); err != nil {
+			return err
+		}
+	} else {
+		query.ID = fftypes.NewUUID()
+		query.Created = fftypes.Now()
+		query.Updated = nil
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("savedqueries").
+				Columns(savedQueryColumns...).
+				Values(
+					query.ID,
+					query.Namespace,
+					query.Name,
+					query.Description,
+					query.Collection,
+					query.Filter,
+					query.Created,
+					query.Updated,
+				),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionSavedQueries, fftypes.ChangeEventTypeCreated, query.Namespace, query.ID)
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) savedQueryResult(ctx context.Context, row *sql.Rows) (*fftypes.SavedQuery, error) {
+	var query fftypes.SavedQuery
+	err := row.Scan(
+		&query.ID,
+		&query.Namespace,
+		&query.Name,
+		&query.Description,
+		&query.Collection,
+		&query.Filter,
+		&query.Created,
+		&query.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "savedqueries")
+	}
+	return &query, nil
+}
+
+func (s *SQLCommon) getSavedQueryEq(ctx context.Context, eq sq.Eq, textName string) (*fftypes.SavedQuery, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(savedQueryColumns...).
+			From("savedqueries").
+			Where(eq),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Saved query '%s' not found", textName)
+		return nil, nil
+	}
+
+	return s.savedQueryResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetSavedQueryByName(ctx context.Context, ns, name string) (*fftypes.SavedQuery, error) {
+	return s.getSavedQueryEq(ctx, sq.Eq{"namespace": ns, "name": name}, fmt.Sprintf("%s:%s", ns, name))
+}
+
+func (s *SQLCommon) GetSavedQueries(ctx context.Context, filter database.Filter) ([]*fftypes.SavedQuery, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(savedQueryColumns...).From("savedqueries"),
+		filter, savedQueryFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	queries := []*fftypes.SavedQuery{}
+	for rows.Next() {
+		q, err := s.savedQueryResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		queries = append(queries, q)
+	}
+
+	return queries, s.queryRes(ctx, tx, "savedqueries", fop, fi), err
+}
+
+func (s *SQLCommon) DeleteSavedQuery(ctx context.Context, id *fftypes.UUID) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	query, err := s.getSavedQueryEq(ctx, sq.Eq{"id": id}, id.String())
+	if err == nil && query != nil {
+		err = s.deleteTx(ctx, tx, sq.Delete("savedqueries").Where(sq.Eq{"id": id}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionSavedQueries, fftypes.ChangeEventTypeDeleted, query.Namespace, query.ID)
+			})
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
@@ -0,0 +1,224 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSavedQueriesE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Create a new saved query
+	query := &fftypes.SavedQuery{
+		Namespace:   "ns1",
+		Name:        "unconfirmed",
+		Description: "Unconfirmed messages",
+		Collection:  "messages",
+		Filter:      "confirmed=?",
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionSavedQueries, fftypes.ChangeEventTypeCreated, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionSavedQueries, fftypes.ChangeEventTypeUpdated, "ns1", mock.Anything, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionSavedQueries, fftypes.ChangeEventTypeDeleted, "ns1", mock.Anything, mock.Anything).Return()
+
+	err := s.UpsertSavedQuery(ctx, query)
+	assert.NoError(t, err)
+	assert.NotNil(t, query.ID)
+	assert.NotNil(t, query.Created)
+	assert.Nil(t, query.Updated)
+	queryJson, _ := json.Marshal(&query)
+
+	// Query back the saved query
+	queryRead, err := s.GetSavedQueryByName(ctx, "ns1", "unconfirmed")
+	assert.NoError(t, err)
+	queryReadJson, _ := json.Marshal(&queryRead)
+	assert.Equal(t, string(queryJson), string(queryReadJson))
+
+	// Replace the saved query, keeping the ID
+	query2 := &fftypes.SavedQuery{
+		Namespace:  "ns1",
+		Name:       "unconfirmed",
+		Collection: "messages",
+		Filter:     "confirmed=?&created=<now-1h",
+	}
+	err = s.UpsertSavedQuery(ctx, query2)
+	assert.NoError(t, err)
+	assert.Equal(t, *query.ID, *query2.ID)
+	assert.Equal(t, query.Created.String(), query2.Created.String())
+	assert.NotNil(t, query2.Updated)
+
+	// Query back with a filter
+	fb := database.SavedQueryQueryFactory.NewFilter(ctx)
+	queries, res, err := s.GetSavedQueries(ctx, fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("collection", "messages"),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	query2Json, _ := json.Marshal(&query2)
+	queryReadJson, _ = json.Marshal(queries[0])
+	assert.Equal(t, string(query2Json), string(queryReadJson))
+
+	// Delete the saved query
+	err = s.DeleteSavedQuery(ctx, query.ID)
+	assert.NoError(t, err)
+	queryRead, err = s.GetSavedQueryByName(ctx, "ns1", "unconfirmed")
+	assert.NoError(t, err)
+	assert.Nil(t, queryRead)
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestUpsertSavedQueryFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertSavedQuery(context.Background(), &fftypes.SavedQuery{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertSavedQueryFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertSavedQuery(context.Background(), &fftypes.SavedQuery{Name: "query1"})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertSavedQueryFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertSavedQuery(context.Background(), &fftypes.SavedQuery{Name: "query1"})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertSavedQueryFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "created"}).
+		AddRow(fftypes.NewUUID(), fftypes.Now()))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertSavedQuery(context.Background(), &fftypes.SavedQuery{Name: "query1"})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertSavedQueryFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertSavedQuery(context.Background(), &fftypes.SavedQuery{Name: "query1"})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSavedQueryByNameSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetSavedQueryByName(context.Background(), "ns1", "query1")
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSavedQueryByNameNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(savedQueryColumns))
+	query, err := s.GetSavedQueryByName(context.Background(), "ns1", "query1")
+	assert.NoError(t, err)
+	assert.Nil(t, query)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSavedQueryByNameScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetSavedQueryByName(context.Background(), "ns1", "query1")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSavedQueriesQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.SavedQueryQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetSavedQueries(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSavedQueriesBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.SavedQueryQueryFactory.NewFilter(context.Background()).Eq("name", map[bool]bool{true: false})
+	_, _, err := s.GetSavedQueries(context.Background(), f)
+	assert.Regexp(t, "FF10149.*name", err)
+}
+
+func TestGetSavedQueriesScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.SavedQueryQueryFactory.NewFilter(context.Background()).Eq("name", "")
+	_, _, err := s.GetSavedQueries(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSavedQueryBeginFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteSavedQuery(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10114", err)
+}
+
+func TestDeleteSavedQuerySelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteSavedQuery(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+}
+
+func TestDeleteSavedQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(savedQueryColumns).AddRow(
+		fftypes.NewUUID(), "ns1", "query1", "", "messages", "", fftypes.Now(), nil),
+	)
+	mock.ExpectExec("DELETE .*").WillReturnError(fmt.Errorf("pop"))
+	err := s.DeleteSavedQuery(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10118", err)
+}
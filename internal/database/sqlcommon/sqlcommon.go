@@ -25,6 +25,7 @@ import (
 	sq "github.com/Masterminds/squirrel"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/faults"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -197,10 +198,12 @@ func (s *SQLCommon) queryTx(ctx context.Context, tx *txWrapper, q sq.SelectBuild
 	l.Debugf(`SQL-> query: %s`, shortenSQL(sqlQuery))
 	l.Tracef(`SQL-> query args: %+v`, args)
 	var rows *sql.Rows
-	if tx != nil {
-		rows, err = tx.sqlTX.QueryContext(ctx, sqlQuery, args...)
-	} else {
-		rows, err = s.db.QueryContext(ctx, sqlQuery, args...)
+	if err = faults.Inject(ctx, faults.PluginDatabase, sqlQuery); err == nil {
+		if tx != nil {
+			rows, err = tx.sqlTX.QueryContext(ctx, sqlQuery, args...)
+		} else {
+			rows, err = s.db.QueryContext(ctx, sqlQuery, args...)
+		}
 	}
 	if err != nil {
 		l.Errorf(`SQL query failed: %s sql=[ %s ]`, err, sqlQuery)
@@ -286,7 +289,10 @@ func (s *SQLCommon) insertTxRows(ctx context.Context, tx *txWrapper, q sq.Insert
 	l.Debugf(`SQL-> insert %s`, shortenSQL(sqlQuery))
 	l.Tracef(`SQL-> insert query: %s (args: %+v)`, sqlQuery, args)
 	if useQuery {
-		result, err := tx.sqlTX.QueryContext(ctx, sqlQuery, args...)
+		var result *sql.Rows
+		if err = faults.Inject(ctx, faults.PluginDatabase, sqlQuery); err == nil {
+			result, err = tx.sqlTX.QueryContext(ctx, sqlQuery, args...)
+		}
 		for i := 0; i < len(sequences) && err == nil; i++ {
 			if result.Next() {
 				err = result.Scan(&sequences[i])
@@ -309,7 +315,10 @@ func (s *SQLCommon) insertTxRows(ctx context.Context, tx *txWrapper, q sq.Insert
 		if len(sequences) > 1 {
 			return i18n.WrapError(ctx, err, i18n.MsgDBMultiRowConfigError)
 		}
-		res, err := tx.sqlTX.ExecContext(ctx, sqlQuery, args...)
+		var res sql.Result
+		if err = faults.Inject(ctx, faults.PluginDatabase, sqlQuery); err == nil {
+			res, err = tx.sqlTX.ExecContext(ctx, sqlQuery, args...)
+		}
 		if err != nil {
 			l.Errorf(`SQL insert failed: %s sql=[ %s ]: %s`, err, sqlQuery, err)
 			return i18n.WrapError(ctx, err, i18n.MsgDBInsertFailed)
@@ -332,7 +341,10 @@ func (s *SQLCommon) deleteTx(ctx context.Context, tx *txWrapper, q sq.DeleteBuil
 	}
 	l.Debugf(`SQL-> delete: %s`, shortenSQL(sqlQuery))
 	l.Tracef(`SQL-> delete query: %s args: %+v`, sqlQuery, args)
-	res, err := tx.sqlTX.ExecContext(ctx, sqlQuery, args...)
+	var res sql.Result
+	if err = faults.Inject(ctx, faults.PluginDatabase, sqlQuery); err == nil {
+		res, err = tx.sqlTX.ExecContext(ctx, sqlQuery, args...)
+	}
 	if err != nil {
 		l.Errorf(`SQL delete failed: %s sql=[ %s ]: %s`, err, sqlQuery, err)
 		return i18n.WrapError(ctx, err, i18n.MsgDBDeleteFailed)
@@ -357,7 +369,10 @@ func (s *SQLCommon) updateTx(ctx context.Context, tx *txWrapper, q sq.UpdateBuil
 	}
 	l.Debugf(`SQL-> update: %s`, shortenSQL(sqlQuery))
 	l.Tracef(`SQL-> update query: %s (args: %+v)`, sqlQuery, args)
-	res, err := tx.sqlTX.ExecContext(ctx, sqlQuery, args...)
+	var res sql.Result
+	if err = faults.Inject(ctx, faults.PluginDatabase, sqlQuery); err == nil {
+		res, err = tx.sqlTX.ExecContext(ctx, sqlQuery, args...)
+	}
 	if err != nil {
 		l.Errorf(`SQL update failed: %s sql=[ %s ]`, err, sqlQuery)
 		return -1, i18n.WrapError(ctx, err, i18n.MsgDBUpdateFailed)
@@ -0,0 +1,168 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	swapColumns = []string{
+		"id",
+		"namespace",
+		"state",
+		"escrow",
+		"initiator",
+		"counterparty",
+		"error",
+		"created",
+		"updated",
+		"expires",
+	}
+	swapFilterFieldMap = map[string]string{}
+)
+
+func (s *SQLCommon) InsertSwap(ctx context.Context, swap *fftypes.Swap) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	swap.Created = fftypes.Now()
+	if _, err = s.insertTx(ctx, tx,
+		sq.Insert("swaps").
+			Columns(swapColumns...).
+			Values(
+				swap.ID,
+				swap.Namespace,
+				swap.State,
+				swap.Escrow,
+				swap.Initiator,
+				swap.Counterparty,
+				swap.Error,
+				swap.Created,
+				swap.Updated,
+				swap.Expires,
+			),
+		func() {
+			s.callbacks.UUIDCollectionNSEvent(database.CollectionSwaps, fftypes.ChangeEventTypeCreated, swap.Namespace, swap.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) UpdateSwap(ctx context.Context, swap *fftypes.Swap) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	swap.Updated = fftypes.Now()
+	if _, err = s.updateTx(ctx, tx,
+		sq.Update("swaps").
+			Set("state", swap.State).
+			Set("initiator", swap.Initiator).
+			Set("counterparty", swap.Counterparty).
+			Set("error", swap.Error).
+			Set("updated", swap.Updated).
+			Where(sq.Eq{"id": swap.ID}),
+		func() {
+			s.callbacks.UUIDCollectionNSEvent(database.CollectionSwaps, fftypes.ChangeEventTypeUpdated, swap.Namespace, swap.ID)
+		},
+	); err != nil {
+		return err
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) swapResult(ctx context.Context, row *sql.Rows) (*fftypes.Swap, error) {
+	var swap fftypes.Swap
+	err := row.Scan(
+		&swap.ID,
+		&swap.Namespace,
+		&swap.State,
+		&swap.Escrow,
+		&swap.Initiator,
+		&swap.Counterparty,
+		&swap.Error,
+		&swap.Created,
+		&swap.Updated,
+		&swap.Expires,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "swaps")
+	}
+	return &swap, nil
+}
+
+func (s *SQLCommon) GetSwapByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Swap, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(swapColumns...).
+			From("swaps").
+			Where(sq.Eq{"id": id}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Swap '%s' not found", id)
+		return nil, nil
+	}
+
+	return s.swapResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetSwaps(ctx context.Context, filter database.Filter) ([]*fftypes.Swap, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(swapColumns...).From("swaps"),
+		filter, swapFilterFieldMap, []interface{}{"sequence"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	swaps := []*fftypes.Swap{}
+	for rows.Next() {
+		sw, err := s.swapResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		swaps = append(swaps, sw)
+	}
+
+	return swaps, s.queryRes(ctx, tx, "swaps", fop, fi), err
+}
@@ -0,0 +1,203 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSwapsE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Insert a swap
+	swap := &fftypes.Swap{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		State:     fftypes.SwapStateAwaitingDeposits,
+		Escrow:    "0xescrow",
+		Initiator: &fftypes.SwapLeg{
+			Pool:      fftypes.NewUUID(),
+			Connector: "erc20",
+			Amount:    *fftypes.NewFFBigInt(10),
+			From:      "0x1",
+			To:        "0x2",
+			Deposit:   fftypes.NewUUID(),
+			DepositTX: fftypes.NewUUID(),
+		},
+		Counterparty: &fftypes.SwapLeg{
+			Pool:       fftypes.NewUUID(),
+			Connector:  "erc1155",
+			TokenIndex: "1",
+			Amount:     *fftypes.NewFFBigInt(1),
+			From:       "0x2",
+			To:         "0x1",
+		},
+		Expires: fftypes.Now(),
+	}
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionSwaps, fftypes.ChangeEventTypeCreated, "ns1", swap.ID, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionSwaps, fftypes.ChangeEventTypeUpdated, "ns1", swap.ID, mock.Anything).Return()
+
+	err := s.InsertSwap(ctx, swap)
+	assert.NoError(t, err)
+	assert.NotNil(t, swap.Created)
+	swapJson, _ := json.Marshal(&swap)
+
+	// Query back the swap
+	swapRead, err := s.GetSwapByID(ctx, swap.ID)
+	assert.NoError(t, err)
+	swapReadJson, _ := json.Marshal(&swapRead)
+	assert.Equal(t, string(swapJson), string(swapReadJson))
+
+	// Update the swap
+	swap.State = fftypes.SwapStateReleasing
+	swap.Counterparty.Deposit = fftypes.NewUUID()
+	err = s.UpdateSwap(ctx, swap)
+	assert.NoError(t, err)
+	assert.NotNil(t, swap.Updated)
+	swapJson, _ = json.Marshal(&swap)
+
+	// Query back with a filter
+	fb := database.SwapQueryFactory.NewFilter(ctx)
+	swaps, res, err := s.GetSwaps(ctx, fb.And(
+		fb.Eq("namespace", "ns1"),
+		fb.Eq("state", fftypes.SwapStateReleasing),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	swapReadJson, _ = json.Marshal(swaps[0])
+	assert.Equal(t, string(swapJson), string(swapReadJson))
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestInsertSwapFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertSwap(context.Background(), &fftypes.Swap{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertSwapFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.InsertSwap(context.Background(), &fftypes.Swap{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertSwapFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.InsertSwap(context.Background(), &fftypes.Swap{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateSwapFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpdateSwap(context.Background(), &fftypes.Swap{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateSwapFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpdateSwap(context.Background(), &fftypes.Swap{})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateSwapFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpdateSwap(context.Background(), &fftypes.Swap{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSwapByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetSwapByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSwapByIDNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(swapColumns))
+	swap, err := s.GetSwapByID(context.Background(), fftypes.NewUUID())
+	assert.NoError(t, err)
+	assert.Nil(t, swap)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSwapByIDScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetSwapByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSwapsQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.SwapQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetSwaps(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSwapsBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.SwapQueryFactory.NewFilter(context.Background()).Eq("state", map[bool]bool{true: false})
+	_, _, err := s.GetSwaps(context.Background(), f)
+	assert.Regexp(t, "FF10149.*state", err)
+}
+
+func TestGetSwapsScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.SwapQueryFactory.NewFilter(context.Background()).Eq("state", "")
+	_, _, err := s.GetSwaps(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
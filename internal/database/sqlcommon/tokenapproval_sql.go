@@ -37,6 +37,7 @@ var (
 		"connector",
 		"namespace",
 		"approved",
+		"allowance",
 		"info",
 		"tx_type",
 		"tx_id",
@@ -87,6 +88,7 @@ func (s *SQLCommon) UpsertTokenApproval(ctx context.Context, approval *fftypes.T
 				Set("connector", approval.Connector).
 				Set("namespace", approval.Namespace).
 				Set("approved", approval.Approved).
+				Set("allowance", approval.Allowance).
 				Set("info", approval.Info).
 				Set("tx_type", approval.TX.Type).
 				Set("tx_id", approval.TX.ID).
@@ -113,6 +115,7 @@ func (s *SQLCommon) UpsertTokenApproval(ctx context.Context, approval *fftypes.T
 					approval.Connector,
 					approval.Namespace,
 					approval.Approved,
+					approval.Allowance,
 					approval.Info,
 					approval.TX.Type,
 					approval.TX.ID,
@@ -140,6 +143,7 @@ func (s *SQLCommon) tokenApprovalResult(ctx context.Context, row *sql.Rows) (*ff
 		&approval.Connector,
 		&approval.Namespace,
 		&approval.Approved,
+		&approval.Allowance,
 		&approval.Info,
 		&approval.TX.Type,
 		&approval.TX.ID,
@@ -93,6 +93,7 @@ func TestApprovalE2EWithDB(t *testing.T) {
 
 	// Update the token approval
 	approval.Approved = false
+	approval.Allowance = fftypes.NewFFBigInt(1000)
 	err = s.UpsertTokenApproval(ctx, approval)
 	assert.NoError(t, err)
 
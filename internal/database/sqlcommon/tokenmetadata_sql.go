@@ -0,0 +1,180 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var (
+	tokenMetadataColumns = []string{
+		"id",
+		"namespace",
+		"pool_id",
+		"token_index",
+		"data_id",
+		"created",
+		"updated",
+	}
+	tokenMetadataFilterFieldMap = map[string]string{
+		"pool":       "pool_id",
+		"tokenindex": "token_index",
+		"data":       "data_id",
+	}
+)
+
+func (s *SQLCommon) UpsertTokenMetadata(ctx context.Context, metadata *fftypes.TokenMetadata) (err error) {
+	ctx, tx, autoCommit, err := s.beginOrUseTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.rollbackTx(ctx, tx, autoCommit)
+
+	rows, _, err := s.queryTx(ctx, tx,
+		sq.Select("id").
+			From("tokenmetadata").
+			Where(sq.Eq{"pool_id": metadata.Pool}).
+			Where(sq.Eq{"token_index": metadata.TokenIndex}),
+	)
+	if err != nil {
+		return err
+	}
+	existing := rows.Next()
+	if existing {
+		var id fftypes.UUID
+		_ = rows.Scan(&id)
+		metadata.ID = &id
+	}
+	rows.Close()
+
+	if existing {
+		metadata.Updated = fftypes.Now()
+		if _, err = s.updateTx(ctx, tx,
+			sq.Update("tokenmetadata").
+				Set("data_id", metadata.Data).
+				Set("updated", metadata.Updated).
+				Where(sq.Eq{"id": metadata.ID}),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionTokenMetadata, fftypes.ChangeEventTypeUpdated, metadata.Namespace, metadata.ID)
+			},
+		); err != nil {
+			return err
+		}
+	} else {
+		metadata.Created = fftypes.Now()
+		if _, err = s.insertTx(ctx, tx,
+			sq.Insert("tokenmetadata").
+				Columns(tokenMetadataColumns...).
+				Values(
+					metadata.ID,
+					metadata.Namespace,
+					metadata.Pool,
+					metadata.TokenIndex,
+					metadata.Data,
+					metadata.Created,
+					metadata.Updated,
+				),
+			func() {
+				s.callbacks.UUIDCollectionNSEvent(database.CollectionTokenMetadata, fftypes.ChangeEventTypeCreated, metadata.Namespace, metadata.ID)
+			},
+		); err != nil {
+			return err
+		}
+	}
+
+	return s.commitTx(ctx, tx, autoCommit)
+}
+
+func (s *SQLCommon) tokenMetadataResult(ctx context.Context, row *sql.Rows) (*fftypes.TokenMetadata, error) {
+	var metadata fftypes.TokenMetadata
+	err := row.Scan(
+		&metadata.ID,
+		&metadata.Namespace,
+		&metadata.Pool,
+		&metadata.TokenIndex,
+		&metadata.Data,
+		&metadata.Created,
+		&metadata.Updated,
+	)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgDBReadErr, "tokenmetadata")
+	}
+	return &metadata, nil
+}
+
+func (s *SQLCommon) getTokenMetadataPred(ctx context.Context, desc string, pred interface{}) (*fftypes.TokenMetadata, error) {
+	rows, _, err := s.query(ctx,
+		sq.Select(tokenMetadataColumns...).
+			From("tokenmetadata").
+			Where(pred),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		log.L(ctx).Debugf("Token metadata '%s' not found", desc)
+		return nil, nil
+	}
+
+	return s.tokenMetadataResult(ctx, rows)
+}
+
+func (s *SQLCommon) GetTokenMetadata(ctx context.Context, poolID *fftypes.UUID, tokenIndex string) (*fftypes.TokenMetadata, error) {
+	return s.getTokenMetadataPred(ctx, poolID.String()+":"+tokenIndex, sq.And{
+		sq.Eq{"pool_id": poolID},
+		sq.Eq{"token_index": tokenIndex},
+	})
+}
+
+func (s *SQLCommon) GetTokenMetadataByID(ctx context.Context, id *fftypes.UUID) (*fftypes.TokenMetadata, error) {
+	return s.getTokenMetadataPred(ctx, id.String(), sq.Eq{"id": id})
+}
+
+func (s *SQLCommon) GetTokenMetadataList(ctx context.Context, filter database.Filter) ([]*fftypes.TokenMetadata, *database.FilterResult, error) {
+	query, fop, fi, err := s.filterSelect(ctx, "",
+		sq.Select(tokenMetadataColumns...).From("tokenmetadata"),
+		filter, tokenMetadataFilterFieldMap, []interface{}{"seq"})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, tx, err := s.query(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	metadataList := []*fftypes.TokenMetadata{}
+	for rows.Next() {
+		metadata, err := s.tokenMetadataResult(ctx, rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		metadataList = append(metadataList, metadata)
+	}
+
+	return metadataList, s.queryRes(ctx, tx, "tokenmetadata", fop, fi), err
+}
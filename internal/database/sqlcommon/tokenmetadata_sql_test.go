@@ -0,0 +1,192 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTokenMetadataE2EWithDB(t *testing.T) {
+	s, cleanup := newSQLiteTestProvider(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// Link a token to its metadata
+	metadata := &fftypes.TokenMetadata{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns1",
+		Pool:       fftypes.NewUUID(),
+		TokenIndex: "1",
+		Data:       fftypes.NewUUID(),
+	}
+	origID := metadata.ID
+
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTokenMetadata, fftypes.ChangeEventTypeCreated, "ns1", metadata.ID, mock.Anything).Return()
+	s.callbacks.On("UUIDCollectionNSEvent", database.CollectionTokenMetadata, fftypes.ChangeEventTypeUpdated, "ns1", metadata.ID, mock.Anything).Return()
+
+	err := s.UpsertTokenMetadata(ctx, metadata)
+	assert.NoError(t, err)
+	assert.NotNil(t, metadata.Created)
+	metadataJson, _ := json.Marshal(&metadata)
+
+	// Query back by token
+	metadataRead, err := s.GetTokenMetadata(ctx, metadata.Pool, "1")
+	assert.NoError(t, err)
+	metadataReadJson, _ := json.Marshal(&metadataRead)
+	assert.Equal(t, string(metadataJson), string(metadataReadJson))
+
+	// Re-link the token to new metadata, which keeps the existing ID
+	metadataUpdate := &fftypes.TokenMetadata{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns1",
+		Pool:       metadata.Pool,
+		TokenIndex: "1",
+		Data:       fftypes.NewUUID(),
+	}
+	err = s.UpsertTokenMetadata(ctx, metadataUpdate)
+	assert.NoError(t, err)
+	assert.Equal(t, origID, metadataUpdate.ID)
+	assert.NotNil(t, metadataUpdate.Updated)
+
+	// Query back by ID
+	metadataRead, err = s.GetTokenMetadataByID(ctx, origID)
+	assert.NoError(t, err)
+	assert.Equal(t, metadataUpdate.Data, metadataRead.Data)
+	assert.Equal(t, metadata.Created.String(), metadataRead.Created.String())
+
+	// Query back with a filter
+	fb := database.TokenMetadataQueryFactory.NewFilter(ctx)
+	metadataList, res, err := s.GetTokenMetadataList(ctx, fb.And(
+		fb.Eq("pool", metadata.Pool),
+		fb.Eq("data", metadataUpdate.Data),
+	).Count(true))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), *res.TotalCount)
+	assert.Equal(t, origID, metadataList[0].ID)
+
+	s.callbacks.AssertExpectations(t)
+}
+
+func TestUpsertTokenMetadataFailBegin(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertTokenMetadata(context.Background(), &fftypes.TokenMetadata{})
+	assert.Regexp(t, "FF10114", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertTokenMetadataFailSelect(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertTokenMetadata(context.Background(), &fftypes.TokenMetadata{})
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertTokenMetadataFailInsert(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertTokenMetadata(context.Background(), &fftypes.TokenMetadata{})
+	assert.Regexp(t, "FF10116", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertTokenMetadataFailUpdate(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(fftypes.NewUUID().String()))
+	mock.ExpectExec("UPDATE .*").WillReturnError(fmt.Errorf("pop"))
+	mock.ExpectRollback()
+	err := s.UpsertTokenMetadata(context.Background(), &fftypes.TokenMetadata{})
+	assert.Regexp(t, "FF10117", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertTokenMetadataFailCommit(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectExec("INSERT .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit().WillReturnError(fmt.Errorf("pop"))
+	err := s.UpsertTokenMetadata(context.Background(), &fftypes.TokenMetadata{})
+	assert.Regexp(t, "FF10119", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTokenMetadataByIDSelectFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	_, err := s.GetTokenMetadataByID(context.Background(), fftypes.NewUUID())
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTokenMetadataNotFound(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows(tokenMetadataColumns))
+	metadata, err := s.GetTokenMetadata(context.Background(), fftypes.NewUUID(), "1")
+	assert.NoError(t, err)
+	assert.Nil(t, metadata)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTokenMetadataScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	_, err := s.GetTokenMetadata(context.Background(), fftypes.NewUUID(), "1")
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTokenMetadataListQueryFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnError(fmt.Errorf("pop"))
+	f := database.TokenMetadataQueryFactory.NewFilter(context.Background()).Eq("tokenindex", "")
+	_, _, err := s.GetTokenMetadataList(context.Background(), f)
+	assert.Regexp(t, "FF10115", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTokenMetadataListBuildQueryFail(t *testing.T) {
+	s, _ := newMockProvider().init()
+	f := database.TokenMetadataQueryFactory.NewFilter(context.Background()).Eq("tokenindex", map[bool]bool{true: false})
+	_, _, err := s.GetTokenMetadataList(context.Background(), f)
+	assert.Regexp(t, "FF10149.*tokenindex", err)
+}
+
+func TestGetTokenMetadataListScanFail(t *testing.T) {
+	s, mock := newMockProvider().init()
+	mock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("only one"))
+	f := database.TokenMetadataQueryFactory.NewFilter(context.Background()).Eq("tokenindex", "")
+	_, _, err := s.GetTokenMetadataList(context.Background(), f)
+	assert.Regexp(t, "FF10121", err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
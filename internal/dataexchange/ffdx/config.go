@@ -26,10 +26,13 @@ const (
 	DataExchangeManifestEnabled = "manifestEnabled"
 	// DataExchangeInitEnabled instructs FireFly to always post all current nodes to the /init API before connecting or reconnecting to the connector
 	DataExchangeInitEnabled = "initEnabled"
+	// DataExchangeStreamingEnabled instructs FireFly to upload and download blobs in chunks, using the chunk APIs of the connector. Must be supported by the connector
+	DataExchangeStreamingEnabled = "streamingEnabled"
 )
 
 func (h *FFDX) InitPrefix(prefix config.Prefix) {
 	wsconfig.InitPrefix(prefix)
 	prefix.AddKnownKey(DataExchangeManifestEnabled, false)
 	prefix.AddKnownKey(DataExchangeInitEnabled, false)
+	prefix.AddKnownKey(DataExchangeStreamingEnabled, false)
 }
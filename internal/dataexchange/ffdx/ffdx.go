@@ -28,6 +28,7 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/config/wsconfig"
+	"github.com/hyperledger/firefly/internal/faults"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/restclient"
@@ -63,8 +64,10 @@ type wsEvent struct {
 }
 
 const (
-	dxHTTPHeaderHash = "dx-hash"
-	dxHTTPHeaderSize = "dx-size"
+	dxHTTPHeaderHash        = "dx-hash"
+	dxHTTPHeaderSize        = "dx-size"
+	dxHTTPHeaderOffset      = "dx-offset"
+	dxHTTPHeaderResumeToken = "dx-resume-token"
 )
 
 type msgType string
@@ -128,8 +131,10 @@ func (h *FFDX) Init(ctx context.Context, prefix config.Prefix, nodes []fftypes.J
 	h.nodes = nodes
 
 	h.client = restclient.New(h.ctx, prefix)
+	faults.WrapClient(h.client, faults.PluginDataExchange)
 	h.capabilities = &dataexchange.Capabilities{
-		Manifest: prefix.GetBool(DataExchangeManifestEnabled),
+		Manifest:  prefix.GetBool(DataExchangeManifestEnabled),
+		Streaming: prefix.GetBool(DataExchangeStreamingEnabled),
 	}
 
 	wsConfig := wsconfig.GenerateConfigFromPrefix(prefix)
@@ -234,7 +239,7 @@ func (h *FFDX) UploadBLOB(ctx context.Context, ns string, id fftypes.UUID, conte
 }
 
 func (h *FFDX) DownloadBLOB(ctx context.Context, payloadRef string) (content io.ReadCloser, err error) {
-	res, err := h.client.R().SetContext(ctx).
+	res, err := h.client.R().SetContext(restclient.WithoutCallTimeout(ctx)).
 		SetDoNotParseResponse(true).
 		Get(fmt.Sprintf("/api/v1/blobs/%s", payloadRef))
 	if err != nil || !res.IsSuccess() {
@@ -0,0 +1,128 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ffdx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/dataexchange"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+type chunkAck struct {
+	Offset      int64  `json:"offset"`
+	ResumeToken string `json:"resumeToken"`
+}
+
+type uploadStream struct {
+	h           *FFDX
+	payloadRef  string
+	resumeToken string
+}
+
+type downloadStream struct {
+	h           *FFDX
+	payloadRef  string
+	resumeToken string
+}
+
+func (h *FFDX) chunkRequest(ctx context.Context, resumeToken string) *resty.Request {
+	req := h.client.R().SetContext(ctx)
+	if resumeToken != "" {
+		req.SetHeader(dxHTTPHeaderResumeToken, resumeToken)
+	}
+	return req
+}
+
+func (h *FFDX) OpenUploadStream(ctx context.Context, ns string, id fftypes.UUID, resumeToken string) (dataexchange.UploadStream, error) {
+	return &uploadStream{
+		h:           h,
+		payloadRef:  fmt.Sprintf("%s/%s", ns, &id),
+		resumeToken: resumeToken,
+	}, nil
+}
+
+func (us *uploadStream) SendChunk(ctx context.Context, chunk []byte) (*dataexchange.ChunkAck, error) {
+	var ack chunkAck
+	res, err := us.h.chunkRequest(ctx, us.resumeToken).
+		SetHeader("Content-Type", "application/octet-stream").
+		SetBody(chunk).
+		SetResult(&ack).
+		Put(fmt.Sprintf("/api/v1/blobs/%s/chunks", us.payloadRef))
+	if err != nil || !res.IsSuccess() {
+		return nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgDXRESTErr)
+	}
+	us.resumeToken = ack.ResumeToken
+	return &dataexchange.ChunkAck{
+		Offset:      ack.Offset,
+		ResumeToken: ack.ResumeToken,
+	}, nil
+}
+
+func (us *uploadStream) Complete(ctx context.Context) (payloadRef string, hash *fftypes.Bytes32, size int64, err error) {
+	var upload uploadBlob
+	res, err := us.h.chunkRequest(ctx, us.resumeToken).
+		SetResult(&upload).
+		Post(fmt.Sprintf("/api/v1/blobs/%s/chunks/complete", us.payloadRef))
+	if err != nil || !res.IsSuccess() {
+		return "", nil, -1, restclient.WrapRestErr(ctx, res, err, i18n.MsgDXRESTErr)
+	}
+	if hash, err = fftypes.ParseBytes32(ctx, upload.Hash); err != nil {
+		return "", nil, -1, i18n.WrapError(ctx, err, i18n.MsgDXBadResponse, "hash", upload.Hash)
+	}
+	return us.payloadRef, hash, upload.Size, nil
+}
+
+func (h *FFDX) OpenDownloadStream(ctx context.Context, payloadRef string, resumeToken string) (dataexchange.DownloadStream, error) {
+	return &downloadStream{
+		h:           h,
+		payloadRef:  payloadRef,
+		resumeToken: resumeToken,
+	}, nil
+}
+
+func (ds *downloadStream) NextChunk(ctx context.Context) ([]byte, *dataexchange.ChunkAck, error) {
+	res, err := ds.h.chunkRequest(ctx, ds.resumeToken).
+		Get(fmt.Sprintf("/api/v1/blobs/%s/chunks", ds.payloadRef))
+	if err != nil || !res.IsSuccess() {
+		return nil, nil, restclient.WrapRestErr(ctx, res, err, i18n.MsgDXRESTErr)
+	}
+	if res.StatusCode() == http.StatusNoContent {
+		return nil, nil, io.EOF
+	}
+	offsetString := res.Header().Get(dxHTTPHeaderOffset)
+	offset, err := strconv.ParseInt(offsetString, 10, 64)
+	if err != nil {
+		return nil, nil, i18n.WrapError(ctx, err, i18n.MsgDXBadResponse, "offset", offsetString)
+	}
+	ds.resumeToken = res.Header().Get(dxHTTPHeaderResumeToken)
+	return res.Body(), &dataexchange.ChunkAck{
+		Offset:      offset,
+		ResumeToken: ds.resumeToken,
+	}, nil
+}
+
+func (ds *downloadStream) Close() error {
+	return nil
+}
@@ -0,0 +1,177 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ffdx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/dataexchange"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadStream(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	var sp dataexchange.StreamingPlugin = h
+	u := fftypes.NewUUID()
+	hash := fftypes.NewRandB32()
+	httpmock.RegisterResponder("PUT", fmt.Sprintf("%s/api/v1/blobs/ns1/%s/chunks", httpURL, u),
+		func(r *http.Request) (*http.Response, error) {
+			assert.Equal(t, "token1", r.Header.Get("dx-resume-token"))
+			b, _ := ioutil.ReadAll(r.Body)
+			assert.Equal(t, "chunk2", string(b))
+			return httpmock.NewJsonResponse(200, fftypes.JSONObject{
+				"offset":      12,
+				"resumeToken": "token2",
+			})
+		})
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/blobs/ns1/%s/chunks/complete", httpURL, u),
+		func(r *http.Request) (*http.Response, error) {
+			assert.Equal(t, "token2", r.Header.Get("dx-resume-token"))
+			return httpmock.NewJsonResponse(200, fftypes.JSONObject{
+				"hash": hash.String(),
+				"size": 12,
+			})
+		})
+
+	stream, err := sp.OpenUploadStream(context.Background(), "ns1", *u, "token1")
+	assert.NoError(t, err)
+	ack, err := stream.SendChunk(context.Background(), []byte("chunk2"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), ack.Offset)
+	assert.Equal(t, "token2", ack.ResumeToken)
+
+	payloadRef, hashReturned, size, err := stream.Complete(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("ns1/%s", u), payloadRef)
+	assert.Equal(t, *hash, *hashReturned)
+	assert.Equal(t, int64(12), size)
+}
+
+func TestUploadStreamChunkFail(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	u := fftypes.NewUUID()
+	httpmock.RegisterResponder("PUT", fmt.Sprintf("%s/api/v1/blobs/ns1/%s/chunks", httpURL, u),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	stream, err := h.OpenUploadStream(context.Background(), "ns1", *u, "")
+	assert.NoError(t, err)
+	_, err = stream.SendChunk(context.Background(), []byte("chunk1"))
+	assert.Regexp(t, "FF10229", err)
+}
+
+func TestUploadStreamCompleteFail(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	u := fftypes.NewUUID()
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/blobs/ns1/%s/chunks/complete", httpURL, u),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	stream, err := h.OpenUploadStream(context.Background(), "ns1", *u, "")
+	assert.NoError(t, err)
+	_, _, _, err = stream.Complete(context.Background())
+	assert.Regexp(t, "FF10229", err)
+}
+
+func TestUploadStreamCompleteBadHash(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	u := fftypes.NewUUID()
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/blobs/ns1/%s/chunks/complete", httpURL, u),
+		httpmock.NewJsonResponderOrPanic(200, fftypes.JSONObject{"hash": "!hash"}))
+
+	stream, err := h.OpenUploadStream(context.Background(), "ns1", *u, "")
+	assert.NoError(t, err)
+	_, _, _, err = stream.Complete(context.Background())
+	assert.Regexp(t, "FF10237", err)
+}
+
+func TestDownloadStream(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	chunks := 0
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/blobs/ns1/id1/chunks", httpURL),
+		func(r *http.Request) (*http.Response, error) {
+			chunks++
+			if chunks == 1 {
+				assert.Empty(t, r.Header.Get("dx-resume-token"))
+				res := httpmock.NewStringResponse(200, "chunk1")
+				res.Header.Set("dx-offset", "6")
+				res.Header.Set("dx-resume-token", "token1")
+				return res, nil
+			}
+			assert.Equal(t, "token1", r.Header.Get("dx-resume-token"))
+			return httpmock.NewStringResponse(204, ""), nil
+		})
+
+	stream, err := h.OpenDownloadStream(context.Background(), "ns1/id1", "")
+	assert.NoError(t, err)
+	chunk, ack, err := stream.NextChunk(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "chunk1", string(chunk))
+	assert.Equal(t, int64(6), ack.Offset)
+	assert.Equal(t, "token1", ack.ResumeToken)
+	_, _, err = stream.NextChunk(context.Background())
+	assert.Equal(t, io.EOF, err)
+	assert.NoError(t, stream.Close())
+}
+
+func TestDownloadStreamFail(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/blobs/ns1/id1/chunks", httpURL),
+		httpmock.NewStringResponder(500, `{"error":"pop"}`))
+
+	stream, err := h.OpenDownloadStream(context.Background(), "ns1/id1", "token1")
+	assert.NoError(t, err)
+	_, _, err = stream.NextChunk(context.Background())
+	assert.Regexp(t, "FF10229", err)
+}
+
+func TestDownloadStreamBadOffset(t *testing.T) {
+
+	h, _, _, httpURL, done := newTestFFDX(t, false)
+	defer done()
+
+	httpmock.RegisterResponder("GET", fmt.Sprintf("%s/api/v1/blobs/ns1/id1/chunks", httpURL),
+		httpmock.NewStringResponder(200, "chunk1"))
+
+	stream, err := h.OpenDownloadStream(context.Background(), "ns1/id1", "")
+	assert.NoError(t, err)
+	_, _, err = stream.NextChunk(context.Background())
+	assert.Regexp(t, "FF10237.*offset", err)
+}
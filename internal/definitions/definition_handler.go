@@ -24,6 +24,7 @@ import (
 	"github.com/hyperledger/firefly/internal/broadcast"
 	"github.com/hyperledger/firefly/internal/contracts"
 	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/governance"
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
@@ -103,9 +104,10 @@ type definitionHandlers struct {
 	messaging  privatemessaging.Manager
 	assets     assets.Manager
 	contracts  contracts.Manager
+	governance governance.Manager
 }
 
-func NewDefinitionHandlers(di database.Plugin, bi blockchain.Plugin, dx dataexchange.Plugin, dm data.Manager, im identity.Manager, bm broadcast.Manager, pm privatemessaging.Manager, am assets.Manager, cm contracts.Manager) DefinitionHandlers {
+func NewDefinitionHandlers(di database.Plugin, bi blockchain.Plugin, dx dataexchange.Plugin, dm data.Manager, im identity.Manager, bm broadcast.Manager, pm privatemessaging.Manager, am assets.Manager, cm contracts.Manager, gm governance.Manager) DefinitionHandlers {
 	return &definitionHandlers{
 		database:   di,
 		blockchain: bi,
@@ -116,6 +118,7 @@ func NewDefinitionHandlers(di database.Plugin, bi blockchain.Plugin, dx dataexch
 		messaging:  pm,
 		assets:     am,
 		contracts:  cm,
+		governance: gm,
 	}
 }
 
@@ -138,9 +141,22 @@ func (dh *definitionHandlers) EnsureLocalGroup(ctx context.Context, group *fftyp
 func (dh *definitionHandlers) HandleDefinitionBroadcast(ctx context.Context, state DefinitionBatchState, msg *fftypes.Message, data fftypes.DataArray, tx *fftypes.UUID) (msgAction HandlerResult, err error) {
 	l := log.L(ctx)
 	l.Infof("Processing system definition broadcast '%s' [%s]", msg.Header.Tag, msg.Header.ID)
+	if msg.Header.Namespace != fftypes.SystemNamespace {
+		// Quarantined orgs can only broadcast definitions to the system namespace, until they are endorsed
+		endorsed, err := dh.identity.VerifyAuthorEndorsed(ctx, msg.Header.Author)
+		if err != nil {
+			return HandlerResult{Action: ActionRetry}, err
+		}
+		if !endorsed {
+			l.Warnf("Unable to process definition %s - author '%s' has not been endorsed", msg.Header.ID, msg.Header.Author)
+			return HandlerResult{Action: ActionReject}, nil
+		}
+	}
 	switch msg.Header.Tag {
 	case fftypes.SystemTagDefineDatatype:
 		return dh.handleDatatypeBroadcast(ctx, state, msg, data, tx)
+	case fftypes.SystemTagDefineDatatypeBundle:
+		return dh.handleDatatypeBundleBroadcast(ctx, state, msg, data, tx)
 	case fftypes.SystemTagDefineNamespace:
 		return dh.handleNamespaceBroadcast(ctx, state, msg, data, tx)
 	case fftypes.DeprecatedSystemTagDefineOrganization:
@@ -153,12 +169,18 @@ func (dh *definitionHandlers) HandleDefinitionBroadcast(ctx context.Context, sta
 		return dh.handleIdentityVerificationBroadcast(ctx, state, msg, data)
 	case fftypes.SystemTagIdentityUpdate:
 		return dh.handleIdentityUpdateBroadcast(ctx, state, msg, data)
+	case fftypes.SystemTagIdentityEndorsement:
+		return dh.handleIdentityEndorsementBroadcast(ctx, state, msg, data)
 	case fftypes.SystemTagDefinePool:
 		return dh.handleTokenPoolBroadcast(ctx, state, msg, data)
 	case fftypes.SystemTagDefineFFI:
 		return dh.handleFFIBroadcast(ctx, state, msg, data, tx)
 	case fftypes.SystemTagDefineContractAPI:
 		return dh.handleContractAPIBroadcast(ctx, state, msg, data, tx)
+	case fftypes.SystemTagGovernanceProposal:
+		return dh.handleGovernanceProposalBroadcast(ctx, state, msg, data, tx)
+	case fftypes.SystemTagGovernanceVote:
+		return dh.handleGovernanceVoteBroadcast(ctx, state, msg, data, tx)
 	default:
 		l.Warnf("Unknown SystemTag '%s' for definition ID '%s'", msg.Header.Tag, msg.Header.ID)
 		return HandlerResult{Action: ActionReject}, nil
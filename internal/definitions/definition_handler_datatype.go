@@ -61,3 +61,60 @@ func (dh *definitionHandlers) handleDatatypeBroadcast(ctx context.Context, state
 	})
 	return HandlerResult{Action: ActionConfirm}, nil
 }
+
+func (dh *definitionHandlers) handleDatatypeBundleBroadcast(ctx context.Context, state DefinitionBatchState, msg *fftypes.Message, data fftypes.DataArray, tx *fftypes.UUID) (HandlerResult, error) {
+	l := log.L(ctx)
+
+	var bundle fftypes.DatatypeBundle
+	valid := dh.getSystemBroadcastPayload(ctx, msg, data, &bundle)
+	if !valid || len(bundle.Datatypes) == 0 {
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	// All of the datatypes are confirmed, or the whole bundle is rejected
+	ns := bundle.Datatypes[0].Namespace
+	refs := make(map[string]bool, len(bundle.Datatypes))
+	for _, dt := range bundle.Datatypes {
+		if err := dt.Validate(ctx, true); err != nil {
+			l.Warnf("Unable to process datatype bundle broadcast %s - validate failed: %s", msg.Header.ID, err)
+			return HandlerResult{Action: ActionReject}, nil
+		}
+		ref := (&fftypes.DatatypeRef{Name: dt.Name, Version: dt.Version}).String()
+		if dt.Namespace != ns || refs[ref] {
+			l.Warnf("Unable to process datatype bundle broadcast %s - invalid or duplicate datatype %s:%s", msg.Header.ID, dt.Namespace, ref)
+			return HandlerResult{Action: ActionReject}, nil
+		}
+		refs[ref] = true
+
+		existing, err := dh.database.GetDatatypeByName(ctx, dt.Namespace, dt.Name, dt.Version)
+		if err != nil {
+			return HandlerResult{Action: ActionRetry}, err // We only return database errors
+		}
+		if existing != nil {
+			l.Warnf("Unable to process datatype bundle broadcast %s (%s:%s) - duplicate of %v", msg.Header.ID, dt.Namespace, dt, existing.ID)
+			return HandlerResult{Action: ActionReject}, nil
+		}
+	}
+
+	if err := dh.data.CheckDatatypes(ctx, ns, bundle.Datatypes); err != nil {
+		l.Warnf("Unable to process datatype bundle broadcast %s - schema check: %s", msg.Header.ID, err)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	for _, dt := range bundle.Datatypes {
+		if err := dh.database.UpsertDatatype(ctx, dt, false); err != nil {
+			return HandlerResult{Action: ActionRetry}, err
+		}
+	}
+
+	state.AddFinalize(func(ctx context.Context) error {
+		for _, dt := range bundle.Datatypes {
+			event := fftypes.NewEvent(fftypes.EventTypeDatatypeConfirmed, dt.Namespace, dt.ID, tx, fftypes.SystemTopicDefinitions)
+			if err := dh.database.InsertEvent(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return HandlerResult{Action: ActionConfirm}, nil
+}
@@ -293,3 +293,207 @@ func TestHandleDefinitionBroadcastDatatypeDuplicate(t *testing.T) {
 	mbi.AssertExpectations(t)
 	bs.assertNoFinalizers()
 }
+
+func testDatatypeBundleData(t *testing.T, datatypes ...*fftypes.Datatype) *fftypes.Data {
+	for _, dt := range datatypes {
+		dt.Hash = dt.Value.Hash()
+	}
+	b, err := json.Marshal(&fftypes.DatatypeBundle{Datatypes: datatypes})
+	assert.NoError(t, err)
+	return &fftypes.Data{
+		Value: fftypes.JSONAnyPtrBytes(b),
+	}
+}
+
+func testBundleDatatype(name string) *fftypes.Datatype {
+	return &fftypes.Datatype{
+		ID:        fftypes.NewUUID(),
+		Validator: fftypes.ValidatorTypeJSON,
+		Namespace: "ns1",
+		Name:      name,
+		Version:   "ver1",
+		Value:     fftypes.JSONAnyPtr(`{}`),
+	}
+}
+
+func TestHandleDefinitionBroadcastDatatypeBundleOk(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+
+	data := testDatatypeBundleData(t, testBundleDatatype("name1"), testBundleDatatype("name2"))
+
+	mdm := dh.data.(*datamocks.Manager)
+	mdm.On("CheckDatatypes", mock.Anything, "ns1", mock.MatchedBy(func(datatypes []*fftypes.Datatype) bool {
+		return len(datatypes) == 2 && datatypes[0].Name == "name1" && datatypes[1].Name == "name2"
+	})).Return(nil)
+	mbi := dh.database.(*databasemocks.Plugin)
+	mbi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver1").Return(nil, nil)
+	mbi.On("GetDatatypeByName", mock.Anything, "ns1", "name2", "ver1").Return(nil, nil)
+	mbi.On("UpsertDatatype", mock.Anything, mock.Anything, false).Return(nil).Twice()
+	mbi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil).Twice()
+	msgID := fftypes.NewUUID()
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:  msgID,
+			Tag: fftypes.SystemTagDefineDatatypeBundle,
+		},
+	}, fftypes.DataArray{data}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionConfirm}, action)
+	assert.NoError(t, err)
+	err = bs.finalizers[0](context.Background())
+	assert.NoError(t, err)
+
+	mdm.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestHandleDefinitionBroadcastDatatypeBundleEventFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+
+	data := testDatatypeBundleData(t, testBundleDatatype("name1"))
+
+	mdm := dh.data.(*datamocks.Manager)
+	mdm.On("CheckDatatypes", mock.Anything, "ns1", mock.Anything).Return(nil)
+	mbi := dh.database.(*databasemocks.Plugin)
+	mbi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver1").Return(nil, nil)
+	mbi.On("UpsertDatatype", mock.Anything, mock.Anything, false).Return(nil)
+	mbi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Tag: fftypes.SystemTagDefineDatatypeBundle,
+		},
+	}, fftypes.DataArray{data}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionConfirm}, action)
+	assert.NoError(t, err)
+	err = bs.finalizers[0](context.Background())
+	assert.EqualError(t, err, "pop")
+
+	mdm.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestHandleDefinitionBroadcastDatatypeBundleEmpty(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Tag: fftypes.SystemTagDefineDatatypeBundle,
+		},
+	}, fftypes.DataArray{testDatatypeBundleData(t)}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+}
+
+func TestHandleDefinitionBroadcastDatatypeBundleValidateFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+
+	dt := testBundleDatatype("name1")
+	data := testDatatypeBundleData(t, dt)
+	dt.ID = nil
+	b, _ := json.Marshal(&fftypes.DatatypeBundle{Datatypes: []*fftypes.Datatype{dt}})
+	data.Value = fftypes.JSONAnyPtrBytes(b)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Tag: fftypes.SystemTagDefineDatatypeBundle,
+		},
+	}, fftypes.DataArray{data}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+}
+
+func TestHandleDefinitionBroadcastDatatypeBundleDuplicateInBundle(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+
+	data := testDatatypeBundleData(t, testBundleDatatype("name1"), testBundleDatatype("name1"))
+
+	mbi := dh.database.(*databasemocks.Plugin)
+	mbi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver1").Return(nil, nil).Once()
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Tag: fftypes.SystemTagDefineDatatypeBundle,
+		},
+	}, fftypes.DataArray{data}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+}
+
+func TestHandleDefinitionBroadcastDatatypeBundleGetFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+
+	data := testDatatypeBundleData(t, testBundleDatatype("name1"))
+
+	mbi := dh.database.(*databasemocks.Plugin)
+	mbi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver1").Return(nil, fmt.Errorf("pop"))
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Tag: fftypes.SystemTagDefineDatatypeBundle,
+		},
+	}, fftypes.DataArray{data}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.EqualError(t, err, "pop")
+
+	mbi.AssertExpectations(t)
+}
+
+func TestHandleDefinitionBroadcastDatatypeBundleExists(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+
+	data := testDatatypeBundleData(t, testBundleDatatype("name1"))
+
+	mbi := dh.database.(*databasemocks.Plugin)
+	mbi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver1").Return(&fftypes.Datatype{}, nil)
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Tag: fftypes.SystemTagDefineDatatypeBundle,
+		},
+	}, fftypes.DataArray{data}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+
+	mbi.AssertExpectations(t)
+}
+
+func TestHandleDefinitionBroadcastDatatypeBundleCheckFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+
+	data := testDatatypeBundleData(t, testBundleDatatype("name1"))
+
+	mdm := dh.data.(*datamocks.Manager)
+	mdm.On("CheckDatatypes", mock.Anything, "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+	mbi := dh.database.(*databasemocks.Plugin)
+	mbi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver1").Return(nil, nil)
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Tag: fftypes.SystemTagDefineDatatypeBundle,
+		},
+	}, fftypes.DataArray{data}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+
+	mdm.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
+
+func TestHandleDefinitionBroadcastDatatypeBundleUpsertFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+
+	data := testDatatypeBundleData(t, testBundleDatatype("name1"))
+
+	mdm := dh.data.(*datamocks.Manager)
+	mdm.On("CheckDatatypes", mock.Anything, "ns1", mock.Anything).Return(nil)
+	mbi := dh.database.(*databasemocks.Plugin)
+	mbi.On("GetDatatypeByName", mock.Anything, "ns1", "name1", "ver1").Return(nil, nil)
+	mbi.On("UpsertDatatype", mock.Anything, mock.Anything, false).Return(fmt.Errorf("pop"))
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Tag: fftypes.SystemTagDefineDatatypeBundle,
+		},
+	}, fftypes.DataArray{data}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.EqualError(t, err, "pop")
+
+	mdm.AssertExpectations(t)
+	mbi.AssertExpectations(t)
+}
@@ -0,0 +1,195 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// resolveGovernanceMember checks the author of a governance message is a member organization of the network
+func (dh *definitionHandlers) resolveGovernanceMember(ctx context.Context, msg *fftypes.Message) (valid bool, err error) {
+	member, retryable, err := dh.identity.CachedIdentityLookup(ctx, msg.Header.Author)
+	if err != nil {
+		if retryable {
+			return false, err
+		}
+		log.L(ctx).Warnf("Unable to process governance broadcast %s - author lookup failed: %s", msg.Header.ID, err)
+		return false, nil
+	}
+	if member == nil || member.Type != fftypes.IdentityTypeOrg {
+		log.L(ctx).Warnf("Unable to process governance broadcast %s - author '%s' is not an organization", msg.Header.ID, msg.Header.Author)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (dh *definitionHandlers) handleGovernanceProposalBroadcast(ctx context.Context, state DefinitionBatchState, msg *fftypes.Message, data fftypes.DataArray, tx *fftypes.UUID) (HandlerResult, error) {
+	l := log.L(ctx)
+
+	var proposal fftypes.GovernanceProposal
+	valid := dh.getSystemBroadcastPayload(ctx, msg, data, &proposal)
+	if !valid {
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	if err := proposal.Validate(ctx, true); err != nil {
+		l.Warnf("Unable to process governance proposal %s - validate failed: %s", msg.Header.ID, err)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+	if err := dh.governance.ValidateChange(ctx, &proposal); err != nil {
+		l.Warnf("Unable to process governance proposal %s - invalid change: %s", msg.Header.ID, err)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	if valid, err := dh.resolveGovernanceMember(ctx, msg); !valid {
+		if err != nil {
+			return HandlerResult{Action: ActionRetry}, err
+		}
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	existing, err := dh.database.GetGovernanceProposalByID(ctx, proposal.ID)
+	if err != nil {
+		return HandlerResult{Action: ActionRetry}, err // We only return database errors
+	}
+	if existing != nil {
+		l.Warnf("Unable to process governance proposal %s - duplicate of %s", msg.Header.ID, proposal.ID)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	// Voting always starts afresh, regardless of what the proposer sent
+	proposal.Author = msg.Header.Author
+	proposal.State = fftypes.GovernanceProposalStatePending
+	proposal.Approvals = 0
+	proposal.Rejections = 0
+	proposal.Decided = nil
+	if err = dh.database.InsertGovernanceProposal(ctx, &proposal); err != nil {
+		return HandlerResult{Action: ActionRetry}, err
+	}
+
+	state.AddFinalize(func(ctx context.Context) error {
+		event := fftypes.NewEvent(fftypes.EventTypeGovernanceProposalConfirmed, fftypes.SystemNamespace, proposal.ID, tx, fftypes.SystemTopicDefinitions)
+		return dh.database.InsertEvent(ctx, event)
+	})
+	return HandlerResult{Action: ActionConfirm}, nil
+}
+
+func (dh *definitionHandlers) handleGovernanceVoteBroadcast(ctx context.Context, state DefinitionBatchState, msg *fftypes.Message, data fftypes.DataArray, tx *fftypes.UUID) (HandlerResult, error) {
+	l := log.L(ctx)
+
+	var vote fftypes.GovernanceVote
+	valid := dh.getSystemBroadcastPayload(ctx, msg, data, &vote)
+	if !valid {
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	if err := vote.Validate(ctx, true); err != nil {
+		l.Warnf("Unable to process governance vote %s - validate failed: %s", msg.Header.ID, err)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	if valid, err := dh.resolveGovernanceMember(ctx, msg); !valid {
+		if err != nil {
+			return HandlerResult{Action: ActionRetry}, err
+		}
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	// The vote is on the same topic as the proposal, so the proposal must already have been processed
+	proposal, err := dh.database.GetGovernanceProposalByID(ctx, vote.Proposal)
+	if err != nil {
+		return HandlerResult{Action: ActionRetry}, err
+	}
+	if proposal == nil {
+		l.Warnf("Unable to process governance vote %s - proposal %s not found", msg.Header.ID, vote.Proposal)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+	if proposal.State != fftypes.GovernanceProposalStatePending {
+		l.Warnf("Unable to process governance vote %s - proposal %s is %s", msg.Header.ID, vote.Proposal, proposal.State)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	fb := database.GovernanceVoteQueryFactory.NewFilter(ctx)
+	existing, _, err := dh.database.GetGovernanceVotes(ctx, fb.And(
+		fb.Eq("proposal", vote.Proposal),
+		fb.Eq("author", msg.Header.Author),
+	))
+	if err != nil {
+		return HandlerResult{Action: ActionRetry}, err
+	}
+	if len(existing) > 0 {
+		l.Warnf("Unable to process governance vote %s - '%s' has already voted on proposal %s", msg.Header.ID, msg.Header.Author, vote.Proposal)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	vote.Author = msg.Header.Author
+	if err = dh.database.InsertGovernanceVote(ctx, &vote); err != nil {
+		return HandlerResult{Action: ActionRetry}, err
+	}
+
+	// Tally the votes against the current set of member organizations. A proposal is approved by a majority,
+	// and rejected once enough members have voted against it that a majority is no longer possible.
+	ifb := database.IdentityQueryFactory.NewFilter(ctx)
+	members, _, err := dh.database.GetIdentities(ctx, ifb.And(
+		ifb.Eq("type", fftypes.IdentityTypeOrg),
+		ifb.Eq("namespace", fftypes.SystemNamespace),
+	))
+	if err != nil {
+		return HandlerResult{Action: ActionRetry}, err
+	}
+	if vote.Approve {
+		proposal.Approvals++
+	} else {
+		proposal.Rejections++
+	}
+	var decision fftypes.EventType
+	switch {
+	case proposal.Approvals*2 > len(members):
+		proposal.State = fftypes.GovernanceProposalStateApproved
+		decision = fftypes.EventTypeGovernanceProposalApproved
+	case proposal.Rejections*2 >= len(members):
+		proposal.State = fftypes.GovernanceProposalStateRejected
+		decision = fftypes.EventTypeGovernanceProposalRejected
+	}
+	if decision != "" {
+		l.Infof("Governance proposal %s %s with %d approvals and %d rejections from %d members", proposal.ID, proposal.State, proposal.Approvals, proposal.Rejections, len(members))
+		proposal.Decided = fftypes.Now()
+	}
+	if err = dh.database.UpdateGovernanceProposal(ctx, proposal); err != nil {
+		return HandlerResult{Action: ActionRetry}, err
+	}
+
+	if proposal.State == fftypes.GovernanceProposalStateApproved {
+		state.AddPreFinalize(func(ctx context.Context) error {
+			dh.governance.ApplyChange(ctx, proposal)
+			return nil
+		})
+	}
+	state.AddFinalize(func(ctx context.Context) error {
+		event := fftypes.NewEvent(fftypes.EventTypeGovernanceVoteConfirmed, fftypes.SystemNamespace, vote.ID, tx, fftypes.SystemTopicDefinitions)
+		if err := dh.database.InsertEvent(ctx, event); err != nil || decision == "" {
+			return err
+		}
+		event = fftypes.NewEvent(decision, fftypes.SystemNamespace, proposal.ID, tx, fftypes.SystemTopicDefinitions)
+		return dh.database.InsertEvent(ctx, event)
+	})
+	return HandlerResult{Action: ActionConfirm}, nil
+}
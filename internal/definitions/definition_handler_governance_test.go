@@ -0,0 +1,540 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/governancemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testGovernanceBroadcast(t *testing.T, tag string, def interface{}) (*fftypes.Message, fftypes.DataArray) {
+	b, err := json.Marshal(def)
+	assert.NoError(t, err)
+	return &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:  fftypes.NewUUID(),
+			Tag: tag,
+			SignerRef: fftypes.SignerRef{
+				Author: "did:firefly:org/org1",
+				Key:    "0x12345",
+			},
+		},
+	}, fftypes.DataArray{
+		{Value: fftypes.JSONAnyPtrBytes(b)},
+	}
+}
+
+func testGovernanceProposal() *fftypes.GovernanceProposal {
+	return &fftypes.GovernanceProposal{
+		ID:     fftypes.NewUUID(),
+		Type:   fftypes.GovernanceChangeProtocolVersion,
+		Change: fftypes.JSONAnyPtr(`{"version":2}`),
+		State:  fftypes.GovernanceProposalStateApproved, // ignored
+	}
+}
+
+func testGovernanceMember() *fftypes.Identity {
+	return &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID:   fftypes.NewUUID(),
+			DID:  "did:firefly:org/org1",
+			Type: fftypes.IdentityTypeOrg,
+		},
+	}
+}
+
+func testGovernanceMembers(count int) []*fftypes.Identity {
+	members := make([]*fftypes.Identity, count)
+	for i := range members {
+		members[i] = testGovernanceMember()
+	}
+	return members
+}
+
+func TestHandleGovernanceProposalOk(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	proposal := testGovernanceProposal()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceProposal, proposal)
+
+	mgv := dh.governance.(*governancemocks.Manager)
+	mgv.On("ValidateChange", mock.Anything, mock.Anything).Return(nil)
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, proposal.ID).Return(nil, nil)
+	mdi.On("InsertGovernanceProposal", mock.Anything, mock.MatchedBy(func(p *fftypes.GovernanceProposal) bool {
+		return p.State == fftypes.GovernanceProposalStatePending && p.Author == "did:firefly:org/org1" && p.Message.Equals(msg.Header.ID)
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeGovernanceProposalConfirmed && e.Reference.Equals(proposal.ID)
+	})).Return(nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionConfirm}, action)
+	assert.NoError(t, err)
+	err = bs.finalizers[0](context.Background())
+	assert.NoError(t, err)
+
+	mgv.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleGovernanceProposalBadPayload(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	msg, _ := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceProposal, testGovernanceProposal())
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, fftypes.DataArray{}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleGovernanceProposalValidateFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	proposal := testGovernanceProposal()
+	proposal.Type = ""
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceProposal, proposal)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleGovernanceProposalBadChange(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceProposal, testGovernanceProposal())
+
+	mgv := dh.governance.(*governancemocks.Manager)
+	mgv.On("ValidateChange", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mgv.AssertExpectations(t)
+}
+
+func TestHandleGovernanceProposalMemberLookupRetry(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceProposal, testGovernanceProposal())
+
+	mgv := dh.governance.(*governancemocks.Manager)
+	mgv.On("ValidateChange", mock.Anything, mock.Anything).Return(nil)
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(nil, true, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+}
+
+func TestHandleGovernanceProposalMemberLookupFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceProposal, testGovernanceProposal())
+
+	mgv := dh.governance.(*governancemocks.Manager)
+	mgv.On("ValidateChange", mock.Anything, mock.Anything).Return(nil)
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(nil, false, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+}
+
+func TestHandleGovernanceProposalNotOrg(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceProposal, testGovernanceProposal())
+
+	mgv := dh.governance.(*governancemocks.Manager)
+	mgv.On("ValidateChange", mock.Anything, mock.Anything).Return(nil)
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	custom := testGovernanceMember()
+	custom.Type = fftypes.IdentityTypeCustom
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(custom, false, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+}
+
+func TestHandleGovernanceProposalLookupFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	proposal := testGovernanceProposal()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceProposal, proposal)
+
+	mgv := dh.governance.(*governancemocks.Manager)
+	mgv.On("ValidateChange", mock.Anything, mock.Anything).Return(nil)
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, proposal.ID).Return(nil, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleGovernanceProposalDuplicate(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	proposal := testGovernanceProposal()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceProposal, proposal)
+
+	mgv := dh.governance.(*governancemocks.Manager)
+	mgv.On("ValidateChange", mock.Anything, mock.Anything).Return(nil)
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, proposal.ID).Return(proposal, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleGovernanceProposalInsertFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	proposal := testGovernanceProposal()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceProposal, proposal)
+
+	mgv := dh.governance.(*governancemocks.Manager)
+	mgv.On("ValidateChange", mock.Anything, mock.Anything).Return(nil)
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, proposal.ID).Return(nil, nil)
+	mdi.On("InsertGovernanceProposal", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+
+	mdi.AssertExpectations(t)
+}
+
+func testGovernanceVoteSetup(t *testing.T, approve bool, members int) (*definitionHandlers, *testDefinitionBatchState, *fftypes.GovernanceProposal, *fftypes.Message, fftypes.DataArray) {
+	dh, bs := newTestDefinitionHandlers(t)
+	proposal := testGovernanceProposal()
+	proposal.State = fftypes.GovernanceProposalStatePending
+	vote := &fftypes.GovernanceVote{
+		ID:       fftypes.NewUUID(),
+		Proposal: proposal.ID,
+		Approve:  approve,
+	}
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceVote, vote)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, proposal.ID).Return(proposal, nil)
+	mdi.On("GetGovernanceVotes", mock.Anything, mock.Anything).Return([]*fftypes.GovernanceVote{}, nil, nil)
+	mdi.On("InsertGovernanceVote", mock.Anything, mock.MatchedBy(func(v *fftypes.GovernanceVote) bool {
+		return v.Author == "did:firefly:org/org1" && v.Message.Equals(msg.Header.ID)
+	})).Return(nil)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return(testGovernanceMembers(members), nil, nil)
+	return dh, bs, proposal, msg, data
+}
+
+func TestHandleGovernanceVoteApproved(t *testing.T) {
+	dh, bs, proposal, msg, data := testGovernanceVoteSetup(t, true, 1)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("UpdateGovernanceProposal", mock.Anything, mock.MatchedBy(func(p *fftypes.GovernanceProposal) bool {
+		return p.State == fftypes.GovernanceProposalStateApproved && p.Approvals == 1 && p.Decided != nil
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeGovernanceVoteConfirmed
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeGovernanceProposalApproved && e.Reference.Equals(proposal.ID)
+	})).Return(nil)
+	mgv := dh.governance.(*governancemocks.Manager)
+	mgv.On("ApplyChange", mock.Anything, proposal).Return()
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionConfirm}, action)
+	assert.NoError(t, err)
+	err = bs.preFinalizers[0](context.Background())
+	assert.NoError(t, err)
+	err = bs.finalizers[0](context.Background())
+	assert.NoError(t, err)
+
+	mgv.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleGovernanceVoteRejected(t *testing.T) {
+	dh, bs, proposal, msg, data := testGovernanceVoteSetup(t, false, 2)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("UpdateGovernanceProposal", mock.Anything, mock.MatchedBy(func(p *fftypes.GovernanceProposal) bool {
+		return p.State == fftypes.GovernanceProposalStateRejected && p.Rejections == 1 && p.Decided != nil
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeGovernanceVoteConfirmed
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeGovernanceProposalRejected && e.Reference.Equals(proposal.ID)
+	})).Return(nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionConfirm}, action)
+	assert.NoError(t, err)
+	assert.Empty(t, bs.preFinalizers)
+	err = bs.finalizers[0](context.Background())
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleGovernanceVoteStillPending(t *testing.T) {
+	dh, bs, _, msg, data := testGovernanceVoteSetup(t, true, 3)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("UpdateGovernanceProposal", mock.Anything, mock.MatchedBy(func(p *fftypes.GovernanceProposal) bool {
+		return p.State == fftypes.GovernanceProposalStatePending && p.Approvals == 1 && p.Decided == nil
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeGovernanceVoteConfirmed
+	})).Return(nil).Once()
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionConfirm}, action)
+	assert.NoError(t, err)
+	err = bs.finalizers[0](context.Background())
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleGovernanceVoteEventFail(t *testing.T) {
+	dh, bs, _, msg, data := testGovernanceVoteSetup(t, true, 1)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("UpdateGovernanceProposal", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Once()
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionConfirm}, action)
+	assert.NoError(t, err)
+	err = bs.finalizers[0](context.Background())
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleGovernanceVoteBadPayload(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	msg, _ := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceVote, &fftypes.GovernanceVote{})
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, fftypes.DataArray{}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleGovernanceVoteValidateFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceVote, &fftypes.GovernanceVote{
+		ID: fftypes.NewUUID(),
+	})
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleGovernanceVoteMemberLookupRetry(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceVote, &fftypes.GovernanceVote{
+		ID:       fftypes.NewUUID(),
+		Proposal: fftypes.NewUUID(),
+	})
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(nil, true, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+}
+
+func TestHandleGovernanceVoteNotOrg(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceVote, &fftypes.GovernanceVote{
+		ID:       fftypes.NewUUID(),
+		Proposal: fftypes.NewUUID(),
+	})
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(nil, false, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+}
+
+func testGovernanceVoteLookupSetup(t *testing.T) (*definitionHandlers, *testDefinitionBatchState, *databasemocks.Plugin, *fftypes.GovernanceVote, *fftypes.Message, fftypes.DataArray) {
+	dh, bs := newTestDefinitionHandlers(t)
+	vote := &fftypes.GovernanceVote{
+		ID:       fftypes.NewUUID(),
+		Proposal: fftypes.NewUUID(),
+	}
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagGovernanceVote, vote)
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	return dh, bs, dh.database.(*databasemocks.Plugin), vote, msg, data
+}
+
+func TestHandleGovernanceVoteProposalLookupFail(t *testing.T) {
+	dh, bs, mdi, vote, msg, data := testGovernanceVoteLookupSetup(t)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, vote.Proposal).Return(nil, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleGovernanceVoteProposalNotFound(t *testing.T) {
+	dh, bs, mdi, vote, msg, data := testGovernanceVoteLookupSetup(t)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, vote.Proposal).Return(nil, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleGovernanceVoteProposalDecided(t *testing.T) {
+	dh, bs, mdi, vote, msg, data := testGovernanceVoteLookupSetup(t)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, vote.Proposal).Return(&fftypes.GovernanceProposal{
+		ID:    vote.Proposal,
+		State: fftypes.GovernanceProposalStateRejected,
+	}, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleGovernanceVoteGetVotesFail(t *testing.T) {
+	dh, bs, mdi, vote, msg, data := testGovernanceVoteLookupSetup(t)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, vote.Proposal).Return(&fftypes.GovernanceProposal{
+		ID:    vote.Proposal,
+		State: fftypes.GovernanceProposalStatePending,
+	}, nil)
+	mdi.On("GetGovernanceVotes", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleGovernanceVoteAlreadyVoted(t *testing.T) {
+	dh, bs, mdi, vote, msg, data := testGovernanceVoteLookupSetup(t)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, vote.Proposal).Return(&fftypes.GovernanceProposal{
+		ID:    vote.Proposal,
+		State: fftypes.GovernanceProposalStatePending,
+	}, nil)
+	mdi.On("GetGovernanceVotes", mock.Anything, mock.Anything).Return([]*fftypes.GovernanceVote{{}}, nil, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleGovernanceVoteInsertFail(t *testing.T) {
+	dh, bs, mdi, vote, msg, data := testGovernanceVoteLookupSetup(t)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, vote.Proposal).Return(&fftypes.GovernanceProposal{
+		ID:    vote.Proposal,
+		State: fftypes.GovernanceProposalStatePending,
+	}, nil)
+	mdi.On("GetGovernanceVotes", mock.Anything, mock.Anything).Return([]*fftypes.GovernanceVote{}, nil, nil)
+	mdi.On("InsertGovernanceVote", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleGovernanceVoteGetMembersFail(t *testing.T) {
+	dh, bs, mdi, vote, msg, data := testGovernanceVoteLookupSetup(t)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, vote.Proposal).Return(&fftypes.GovernanceProposal{
+		ID:    vote.Proposal,
+		State: fftypes.GovernanceProposalStatePending,
+	}, nil)
+	mdi.On("GetGovernanceVotes", mock.Anything, mock.Anything).Return([]*fftypes.GovernanceVote{}, nil, nil)
+	mdi.On("InsertGovernanceVote", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleGovernanceVoteUpdateFail(t *testing.T) {
+	dh, bs, _, msg, data := testGovernanceVoteSetup(t, true, 1)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("UpdateGovernanceProposal", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+}
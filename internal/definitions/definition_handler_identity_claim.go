@@ -201,6 +201,15 @@ func (dh *definitionHandlers) handleIdentityClaim(ctx context.Context, state Def
 		}
 	}
 
+	// If the org was registered with an invitation issued by this node, the invitation has now been redeemed
+	if identity.Type == fftypes.IdentityTypeOrg {
+		if ref := fftypes.ParseInvitationReference(identity.Profile); ref != nil {
+			if err = dh.redeemInvitation(ctx, state, msg, identity, ref); err != nil {
+				return HandlerResult{Action: ActionRetry}, err
+			}
+		}
+	}
+
 	// If this is a node, we need to add that peer
 	if identity.Type == fftypes.IdentityTypeNode {
 		state.AddPreFinalize(
@@ -0,0 +1,90 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (dh *definitionHandlers) handleIdentityEndorsementBroadcast(ctx context.Context, state DefinitionBatchState, msg *fftypes.Message, data fftypes.DataArray) (HandlerResult, error) {
+	l := log.L(ctx)
+
+	var endorsement fftypes.IdentityEndorsement
+	valid := dh.getSystemBroadcastPayload(ctx, msg, data, &endorsement)
+	if !valid || endorsement.ID == nil || endorsement.Identity == nil {
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	author, retryable, err := dh.identity.CachedIdentityLookup(ctx, msg.Header.Author)
+	if err != nil {
+		if retryable {
+			return HandlerResult{Action: ActionRetry}, err
+		}
+		l.Warnf("Unable to process identity endorsement %s - author lookup failed: %s", msg.Header.ID, err)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+	if author == nil || author.Type != fftypes.IdentityTypeOrg {
+		l.Warnf("Unable to process identity endorsement %s - author '%s' is not an organization", msg.Header.ID, msg.Header.Author)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	// The endorsement is on the same topic as the identity claim, so the identity must already have been confirmed
+	identity, err := dh.database.GetIdentityByID(ctx, endorsement.Identity)
+	if err != nil {
+		return HandlerResult{Action: ActionRetry}, err // We only return database errors
+	}
+	switch {
+	case identity == nil || identity.DID != endorsement.DID:
+		l.Warnf("Unable to process identity endorsement %s - identity %s '%s' not found", msg.Header.ID, endorsement.Identity, endorsement.DID)
+		return HandlerResult{Action: ActionReject}, nil
+	case identity.Type != fftypes.IdentityTypeOrg:
+		l.Warnf("Unable to process identity endorsement %s - identity '%s' is not an organization", msg.Header.ID, identity.DID)
+		return HandlerResult{Action: ActionReject}, nil
+	case identity.DID == author.DID:
+		l.Warnf("Unable to process identity endorsement %s - organization '%s' cannot endorse itself", msg.Header.ID, identity.DID)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	fb := database.IdentityEndorsementQueryFactory.NewFilter(ctx)
+	existing, _, err := dh.database.GetIdentityEndorsements(ctx, fb.And(
+		fb.Eq("identity", identity.ID),
+		fb.Eq("author", author.DID),
+	))
+	if err != nil {
+		return HandlerResult{Action: ActionRetry}, err
+	}
+	if len(existing) > 0 {
+		l.Warnf("Unable to process identity endorsement %s - '%s' has already endorsed '%s'", msg.Header.ID, author.DID, identity.DID)
+		return HandlerResult{Action: ActionReject}, nil
+	}
+
+	endorsement.Author = author.DID
+	endorsement.Created = fftypes.Now()
+	if err = dh.database.InsertIdentityEndorsement(ctx, &endorsement); err != nil {
+		return HandlerResult{Action: ActionRetry}, err
+	}
+
+	state.AddFinalize(func(ctx context.Context) error {
+		event := fftypes.NewEvent(fftypes.EventTypeIdentityEndorsed, fftypes.SystemNamespace, identity.ID, nil, fftypes.SystemTopicDefinitions)
+		return dh.database.InsertEvent(ctx, event)
+	})
+	return HandlerResult{Action: ActionConfirm}, nil
+}
@@ -0,0 +1,273 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testIdentityEndorsement() (*fftypes.Identity, *fftypes.IdentityEndorsement) {
+	identity := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID:        fftypes.NewUUID(),
+			DID:       "did:firefly:org/org2",
+			Type:      fftypes.IdentityTypeOrg,
+			Namespace: fftypes.SystemNamespace,
+		},
+	}
+	return identity, &fftypes.IdentityEndorsement{
+		ID:       fftypes.NewUUID(),
+		Identity: identity.ID,
+		DID:      identity.DID,
+		Author:   "did:firefly:org/ignored",
+	}
+}
+
+func TestHandleIdentityEndorsementOk(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	identity, endorsement := testIdentityEndorsement()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, endorsement)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", mock.Anything, identity.ID).Return(identity, nil)
+	mdi.On("GetIdentityEndorsements", mock.Anything, mock.Anything).Return([]*fftypes.IdentityEndorsement{}, nil, nil)
+	mdi.On("InsertIdentityEndorsement", mock.Anything, mock.MatchedBy(func(e *fftypes.IdentityEndorsement) bool {
+		return e.Author == "did:firefly:org/org1" && e.Message.Equals(msg.Header.ID) && e.Created != nil
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeIdentityEndorsed && e.Reference.Equals(identity.ID)
+	})).Return(nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionConfirm}, action)
+	assert.NoError(t, err)
+	err = bs.finalizers[0](context.Background())
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleIdentityEndorsementBadPayload(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, &fftypes.IdentityEndorsement{})
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestHandleIdentityEndorsementAuthorLookupRetry(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	_, endorsement := testIdentityEndorsement()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, endorsement)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(nil, true, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+}
+
+func TestHandleIdentityEndorsementAuthorLookupFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	_, endorsement := testIdentityEndorsement()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, endorsement)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(nil, false, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+}
+
+func TestHandleIdentityEndorsementAuthorNotOrg(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	_, endorsement := testIdentityEndorsement()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, endorsement)
+
+	author := testGovernanceMember()
+	author.Type = fftypes.IdentityTypeCustom
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(author, false, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+}
+
+func TestHandleIdentityEndorsementGetIdentityFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	identity, endorsement := testIdentityEndorsement()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, endorsement)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", mock.Anything, identity.ID).Return(nil, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleIdentityEndorsementIdentityNotFound(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	identity, endorsement := testIdentityEndorsement()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, endorsement)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", mock.Anything, identity.ID).Return(nil, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleIdentityEndorsementIdentityNotOrg(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	identity, endorsement := testIdentityEndorsement()
+	identity.Type = fftypes.IdentityTypeNode
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, endorsement)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", mock.Anything, identity.ID).Return(identity, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleIdentityEndorsementSelf(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	identity, endorsement := testIdentityEndorsement()
+	identity.DID = "did:firefly:org/org1"
+	endorsement.DID = identity.DID
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, endorsement)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", mock.Anything, identity.ID).Return(identity, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleIdentityEndorsementGetEndorsementsFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	identity, endorsement := testIdentityEndorsement()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, endorsement)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", mock.Anything, identity.ID).Return(identity, nil)
+	mdi.On("GetIdentityEndorsements", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleIdentityEndorsementDuplicate(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	identity, endorsement := testIdentityEndorsement()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, endorsement)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", mock.Anything, identity.ID).Return(identity, nil)
+	mdi.On("GetIdentityEndorsements", mock.Anything, mock.Anything).Return([]*fftypes.IdentityEndorsement{{}}, nil, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleIdentityEndorsementInsertFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	identity, endorsement := testIdentityEndorsement()
+	msg, data := testGovernanceBroadcast(t, fftypes.SystemTagIdentityEndorsement, endorsement)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", mock.Anything, "did:firefly:org/org1").Return(testGovernanceMember(), false, nil)
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", mock.Anything, identity.ID).Return(identity, nil)
+	mdi.On("GetIdentityEndorsements", mock.Anything, mock.Anything).Return([]*fftypes.IdentityEndorsement{}, nil, nil)
+	mdi.On("InsertIdentityEndorsement", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, msg, data, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
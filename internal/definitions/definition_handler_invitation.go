@@ -0,0 +1,60 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// redeemInvitation marks an invitation issued by this node redeemed, once the org registered by the new member
+// is confirmed. Invitations are only stored on the node that issued them, so all other nodes ignore the reference.
+func (dh *definitionHandlers) redeemInvitation(ctx context.Context, state DefinitionBatchState, msg *fftypes.Message, identity *fftypes.Identity, ref *fftypes.InvitationReference) error {
+	l := log.L(ctx)
+
+	invitation, err := dh.database.GetInvitationByID(ctx, ref.ID)
+	if err != nil || invitation == nil {
+		return err
+	}
+	switch {
+	case invitation.State != fftypes.InvitationStatePending:
+		l.Warnf("Org '%s' registered with invitation '%s' that is not pending - state=%s", identity.DID, invitation.ID, invitation.State)
+		return nil
+	case !invitation.VerifySignature(ref.Signature):
+		l.Warnf("Org '%s' registered with invitation '%s' - signature mismatch", identity.DID, invitation.ID)
+		return nil
+	case msg.Header.Created != nil && msg.Header.Created.Time().After(*invitation.Expires.Time()):
+		l.Warnf("Org '%s' registered with invitation '%s' after it expired at %s", identity.DID, invitation.ID, invitation.Expires)
+		return nil
+	}
+
+	invitation.State = fftypes.InvitationStateRedeemed
+	invitation.RedeemedBy = identity.DID
+	invitation.Redeemed = fftypes.Now()
+	if err = dh.database.UpdateInvitation(ctx, invitation); err != nil {
+		return err
+	}
+	l.Infof("Invitation '%s' redeemed by org '%s'", invitation.ID, identity.DID)
+
+	state.AddFinalize(func(ctx context.Context) error {
+		event := fftypes.NewEvent(fftypes.EventTypeInvitationRedeemed, fftypes.SystemNamespace, invitation.ID, nil, fftypes.SystemTopicDefinitions)
+		return dh.database.InsertEvent(ctx, event)
+	})
+	return nil
+}
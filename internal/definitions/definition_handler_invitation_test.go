@@ -0,0 +1,221 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package definitions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testInvitation() *fftypes.Invitation {
+	expires := fftypes.FFTime(time.Now().Add(time.Hour))
+	return &fftypes.Invitation{
+		ID:      fftypes.NewUUID(),
+		Inviter: "did:firefly:org/org1",
+		Peer:    fftypes.JSONObject{"id": "peer1"},
+		State:   fftypes.InvitationStatePending,
+		Created: fftypes.Now(),
+		Expires: &expires,
+		Secret:  fftypes.NewRandB32(),
+	}
+}
+
+func testOrgClaimWithInvitation(t *testing.T, invitation *fftypes.Invitation) (*fftypes.Identity, *fftypes.Message, *fftypes.Data) {
+	org2 := testOrgIdentity(t, "org2")
+	org2.Profile = fftypes.JSONObject{
+		fftypes.IdentityProfileInvitation: invitation.CodeInfo().Reference(),
+	}
+
+	b, err := json.Marshal(&fftypes.IdentityClaim{Identity: org2})
+	assert.NoError(t, err)
+	claimData := &fftypes.Data{
+		ID:    fftypes.NewUUID(),
+		Value: fftypes.JSONAnyPtrBytes(b),
+	}
+	claimMsg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:      org2.Messages.Claim,
+			Type:    fftypes.MessageTypeDefinition,
+			Tag:     fftypes.SystemTagIdentityClaim,
+			Topics:  fftypes.FFStringArray{org2.Topic()},
+			Created: fftypes.Now(),
+			SignerRef: fftypes.SignerRef{
+				Author: org2.DID,
+				Key:    "0x12345",
+			},
+		},
+	}
+	return org2, claimMsg, claimData
+}
+
+func TestHandleDefinitionIdentityClaimRedeemsInvitation(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	invitation := testInvitation()
+	org2, claimMsg, claimData := testOrgClaimWithInvitation(t, invitation)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("VerifyIdentityChain", ctx, mock.Anything).Return(nil, false, nil)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", ctx, org2.Type, org2.Namespace, org2.Name).Return(nil, nil)
+	mdi.On("GetIdentityByID", ctx, org2.ID).Return(nil, nil)
+	mdi.On("GetVerifierByValue", ctx, fftypes.VerifierTypeEthAddress, fftypes.SystemNamespace, "0x12345").Return(nil, nil)
+	mdi.On("UpsertVerifier", ctx, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertIdentity", ctx, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("GetInvitationByID", ctx, invitation.ID).Return(invitation, nil)
+	mdi.On("UpdateInvitation", ctx, mock.MatchedBy(func(inv *fftypes.Invitation) bool {
+		return inv.State == fftypes.InvitationStateRedeemed && inv.RedeemedBy == org2.DID && inv.Redeemed != nil
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeInvitationRedeemed && event.Reference.Equals(invitation.ID)
+	})).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeIdentityConfirmed
+	})).Return(nil)
+
+	action, err := dh.HandleDefinitionBroadcast(ctx, bs, claimMsg, fftypes.DataArray{claimData}, nil)
+	assert.Equal(t, HandlerResult{Action: ActionConfirm}, action)
+	assert.NoError(t, err)
+
+	assert.Len(t, bs.finalizers, 2)
+	for _, f := range bs.finalizers {
+		err = f(ctx)
+		assert.NoError(t, err)
+	}
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestHandleDefinitionIdentityClaimInvitationLookupFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	invitation := testInvitation()
+	org2, claimMsg, claimData := testOrgClaimWithInvitation(t, invitation)
+
+	mim := dh.identity.(*identitymanagermocks.Manager)
+	mim.On("VerifyIdentityChain", ctx, mock.Anything).Return(nil, false, nil)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", ctx, org2.Type, org2.Namespace, org2.Name).Return(nil, nil)
+	mdi.On("GetIdentityByID", ctx, org2.ID).Return(nil, nil)
+	mdi.On("GetVerifierByValue", ctx, fftypes.VerifierTypeEthAddress, fftypes.SystemNamespace, "0x12345").Return(nil, nil)
+	mdi.On("UpsertVerifier", ctx, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("UpsertIdentity", ctx, mock.Anything, database.UpsertOptimizationNew).Return(nil)
+	mdi.On("GetInvitationByID", ctx, invitation.ID).Return(nil, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(ctx, bs, claimMsg, fftypes.DataArray{claimData}, nil)
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.Regexp(t, "pop", err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	bs.assertNoFinalizers()
+}
+
+func TestRedeemInvitationNotIssuedByThisNode(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	invitation := testInvitation()
+	org2, claimMsg, _ := testOrgClaimWithInvitation(t, invitation)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetInvitationByID", ctx, invitation.ID).Return(nil, nil)
+
+	err := dh.redeemInvitation(ctx, bs, claimMsg, org2, fftypes.ParseInvitationReference(org2.Profile))
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestRedeemInvitationNotPending(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	invitation := testInvitation()
+	org2, claimMsg, _ := testOrgClaimWithInvitation(t, invitation)
+	invitation.State = fftypes.InvitationStateRedeemed
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetInvitationByID", ctx, invitation.ID).Return(invitation, nil)
+
+	err := dh.redeemInvitation(ctx, bs, claimMsg, org2, fftypes.ParseInvitationReference(org2.Profile))
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestRedeemInvitationBadSignature(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	invitation := testInvitation()
+	org2, claimMsg, _ := testOrgClaimWithInvitation(t, invitation)
+	invitation.Secret = fftypes.NewRandB32()
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetInvitationByID", ctx, invitation.ID).Return(invitation, nil)
+
+	err := dh.redeemInvitation(ctx, bs, claimMsg, org2, fftypes.ParseInvitationReference(org2.Profile))
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestRedeemInvitationExpired(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	invitation := testInvitation()
+	org2, claimMsg, _ := testOrgClaimWithInvitation(t, invitation)
+	sent := fftypes.FFTime(time.Now().Add(2 * time.Hour))
+	claimMsg.Header.Created = &sent
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetInvitationByID", ctx, invitation.ID).Return(invitation, nil)
+
+	err := dh.redeemInvitation(ctx, bs, claimMsg, org2, fftypes.ParseInvitationReference(org2.Profile))
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+}
+
+func TestRedeemInvitationUpdateFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	ctx := context.Background()
+
+	invitation := testInvitation()
+	org2, claimMsg, _ := testOrgClaimWithInvitation(t, invitation)
+
+	mdi := dh.database.(*databasemocks.Plugin)
+	mdi.On("GetInvitationByID", ctx, invitation.ID).Return(invitation, nil)
+	mdi.On("UpdateInvitation", ctx, invitation).Return(fmt.Errorf("pop"))
+
+	err := dh.redeemInvitation(ctx, bs, claimMsg, org2, fftypes.ParseInvitationReference(org2.Profile))
+	assert.Regexp(t, "pop", err)
+	bs.assertNoFinalizers()
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/governancemocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -45,8 +46,10 @@ func newTestDefinitionHandlers(t *testing.T) (*definitionHandlers, *testDefiniti
 	mpm := &privatemessagingmocks.Manager{}
 	mam := &assetmocks.Manager{}
 	mcm := &contractmocks.Manager{}
+	mgv := &governancemocks.Manager{}
 	mbi.On("VerifierType").Return(fftypes.VerifierTypeEthAddress).Maybe()
-	return NewDefinitionHandlers(mdi, mbi, mdx, mdm, mim, mbm, mpm, mam, mcm).(*definitionHandlers), newTestDefinitionBatchState(t)
+	mim.On("VerifyAuthorEndorsed", mock.Anything, mock.Anything).Return(true, nil).Maybe()
+	return NewDefinitionHandlers(mdi, mbi, mdx, mdm, mim, mbm, mpm, mam, mcm, mgv).(*definitionHandlers), newTestDefinitionBatchState(t)
 }
 
 type testDefinitionBatchState struct {
@@ -92,6 +95,45 @@ func TestHandleDefinitionBroadcastUnknown(t *testing.T) {
 	bs.assertNoFinalizers()
 }
 
+func TestHandleDefinitionBroadcastAuthorNotEndorsed(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	mim := &identitymanagermocks.Manager{}
+	dh.identity = mim
+	mim.On("VerifyAuthorEndorsed", mock.Anything, "did:firefly:org/org1").Return(false, nil)
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Namespace: "ns1",
+			Tag:       fftypes.SystemTagDefineDatatype,
+			SignerRef: fftypes.SignerRef{Author: "did:firefly:org/org1"},
+		},
+	}, fftypes.DataArray{}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionReject}, action)
+	assert.NoError(t, err)
+	bs.assertNoFinalizers()
+
+	mim.AssertExpectations(t)
+}
+
+func TestHandleDefinitionBroadcastAuthorEndorsedFail(t *testing.T) {
+	dh, bs := newTestDefinitionHandlers(t)
+	mim := &identitymanagermocks.Manager{}
+	dh.identity = mim
+	mim.On("VerifyAuthorEndorsed", mock.Anything, "did:firefly:org/org1").Return(false, fmt.Errorf("pop"))
+
+	action, err := dh.HandleDefinitionBroadcast(context.Background(), bs, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Namespace: "ns1",
+			Tag:       fftypes.SystemTagDefineDatatype,
+			SignerRef: fftypes.SignerRef{Author: "did:firefly:org/org1"},
+		},
+	}, fftypes.DataArray{}, fftypes.NewUUID())
+	assert.Equal(t, HandlerResult{Action: ActionRetry}, action)
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+}
+
 func TestGetSystemBroadcastPayloadMissingData(t *testing.T) {
 	dh, _ := newTestDefinitionHandlers(t)
 	valid := dh.getSystemBroadcastPayload(context.Background(), &fftypes.Message{
@@ -0,0 +1,239 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const (
+	StandardX12     = "x12"
+	StandardEDIFACT = "edifact"
+)
+
+// Document is a single X12 transaction set (ST to SE), or EDIFACT message (UNH to UNT), from an interchange.
+// Each element of a segment is a string, or an array of strings if the element has multiple components.
+type Document struct {
+	Standard string     `json:"standard"`
+	Type     string     `json:"type"`
+	Version  string     `json:"version,omitempty"`
+	Control  string     `json:"control"`
+	Segments []*Segment `json:"segments"`
+}
+
+type Segment struct {
+	Tag      string        `json:"tag"`
+	Elements []interface{} `json:"elements"`
+}
+
+// Datatype returns the datatype the document is tagged with when stored as data, such as x12_850 or edifact_ORDERS
+func (d *Document) Datatype() *fftypes.DatatypeRef {
+	return &fftypes.DatatypeRef{
+		Name:    d.Standard + "_" + d.Type,
+		Version: d.Version,
+	}
+}
+
+type delimiters struct {
+	element   byte
+	component byte
+	segment   byte
+	release   byte
+}
+
+// Parse splits an X12 or EDIFACT interchange into its documents
+func Parse(ctx context.Context, raw string) ([]*Document, error) {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(raw, "ISA"):
+		return parseX12(ctx, raw)
+	case strings.HasPrefix(raw, "UNA"), strings.HasPrefix(raw, "UNB"):
+		return parseEDIFACT(ctx, raw)
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgEDIFormatUnknown)
+	}
+}
+
+func parseX12(ctx context.Context, raw string) ([]*Document, error) {
+	// The ISA segment is fixed length, and defines the delimiters by position
+	if len(raw) < 106 {
+		return nil, i18n.NewError(ctx, i18n.MsgEDIInvalid, "ISA segment is truncated")
+	}
+	d := &delimiters{
+		element:   raw[3],
+		component: raw[104],
+		segment:   raw[105],
+	}
+	var groupVersion string
+	return collectDocuments(ctx, tokenize(raw, d), "ST", "SE", func(seg *Segment) {
+		if seg.Tag == "GS" {
+			groupVersion = elementString(seg, 7)
+		}
+	}, func(st *Segment) *Document {
+		version := elementString(st, 2)
+		if version == "" {
+			version = groupVersion
+		}
+		return &Document{
+			Standard: StandardX12,
+			Type:     elementString(st, 0),
+			Control:  elementString(st, 1),
+			Version:  version,
+		}
+	})
+}
+
+func parseEDIFACT(ctx context.Context, raw string) ([]*Document, error) {
+	d := &delimiters{
+		component: ':',
+		element:   '+',
+		release:   '?',
+		segment:   '\'',
+	}
+	// The optional UNA service string advice overrides the default delimiters
+	if strings.HasPrefix(raw, "UNA") {
+		if len(raw) < 9 {
+			return nil, i18n.NewError(ctx, i18n.MsgEDIInvalid, "UNA segment is truncated")
+		}
+		d.component, d.element, d.release, d.segment = raw[3], raw[4], raw[6], raw[8]
+		raw = raw[9:]
+	}
+	return collectDocuments(ctx, tokenize(raw, d), "UNH", "UNT", nil, func(unh *Segment) *Document {
+		// The message identifier is a composite of type, version, release and agency (such as ORDERS:D:96A:UN)
+		identifier := elementComponents(unh, 1)
+		doc := &Document{
+			Standard: StandardEDIFACT,
+			Type:     identifier[0],
+			Control:  elementString(unh, 0),
+		}
+		if len(identifier) > 2 {
+			doc.Version = identifier[1] + identifier[2]
+		}
+		return doc
+	})
+}
+
+// tokenize splits the raw interchange into segments, elements and components - honoring the release
+// (escape) character if there is one
+func tokenize(raw string, d *delimiters) []*Segment {
+	var segments []*Segment
+	var components []string
+	var elements [][]string
+	current := &strings.Builder{}
+	endElement := func() {
+		components = append(components, current.String())
+		current.Reset()
+		elements = append(elements, components)
+		components = nil
+	}
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case d.release != 0 && c == d.release && i+1 < len(raw):
+			i++
+			current.WriteByte(raw[i])
+		case c == d.component:
+			components = append(components, current.String())
+			current.Reset()
+		case c == d.element:
+			endElement()
+		case c == d.segment:
+			endElement()
+			if seg := newSegment(elements); seg != nil {
+				segments = append(segments, seg)
+			}
+			elements = nil
+		default:
+			current.WriteByte(c)
+		}
+	}
+	endElement()
+	if seg := newSegment(elements); seg != nil {
+		segments = append(segments, seg)
+	}
+	return segments
+}
+
+func newSegment(elements [][]string) *Segment {
+	tag := strings.TrimSpace(elements[0][0])
+	if tag == "" {
+		return nil
+	}
+	seg := &Segment{
+		Tag:      tag,
+		Elements: make([]interface{}, 0, len(elements)-1),
+	}
+	for _, components := range elements[1:] {
+		if len(components) == 1 {
+			seg.Elements = append(seg.Elements, components[0])
+		} else {
+			seg.Elements = append(seg.Elements, components)
+		}
+	}
+	return seg
+}
+
+func elementComponents(seg *Segment, idx int) []string {
+	if idx >= len(seg.Elements) {
+		return []string{""}
+	}
+	switch e := seg.Elements[idx].(type) {
+	case []string:
+		return e
+	default:
+		return []string{e.(string)}
+	}
+}
+
+func elementString(seg *Segment, idx int) string {
+	return elementComponents(seg, idx)[0]
+}
+
+// collectDocuments groups the segments between each header and trailer into a document
+func collectDocuments(ctx context.Context, segments []*Segment, header, trailer string, envelope func(*Segment), newDocument func(*Segment) *Document) ([]*Document, error) {
+	var docs []*Document
+	var doc *Document
+	for _, seg := range segments {
+		switch {
+		case seg.Tag == header:
+			if doc != nil {
+				return nil, i18n.NewError(ctx, i18n.MsgEDIInvalid, header+" segment before "+trailer)
+			}
+			doc = newDocument(seg)
+			doc.Segments = []*Segment{seg}
+		case doc != nil:
+			doc.Segments = append(doc.Segments, seg)
+			if seg.Tag == trailer {
+				docs = append(docs, doc)
+				doc = nil
+			}
+		case envelope != nil:
+			envelope(seg)
+		}
+	}
+	if doc != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgEDIInvalid, "missing "+trailer+" segment")
+	}
+	if len(docs) == 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgEDIInvalid, "no "+header+" segments")
+	}
+	return docs, nil
+}
@@ -0,0 +1,108 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testX12 = "ISA*00*          *00*          *ZZ*SENDER         *ZZ*RECEIVER       *220101*1200*U*00401*000000001*0*P*>~\n" +
+	"GS*PO*SENDER*RECEIVER*20220101*1200*1*X*004010~\n" +
+	"ST*850*0001~\n" +
+	"BEG*00*SA*PO123**20220101~\n" +
+	"PO1*1*10*EA*9.99**VP*WIDGET>BLUE~\n" +
+	"SE*4*0001~\n" +
+	"ST*810*0002*005010~\n" +
+	"BIG*20220101*INV1~\n" +
+	"SE*3*0002~\n" +
+	"GE*2*1~\n" +
+	"IEA*1*000000001~\n"
+
+const testEDIFACT = "UNA:+.? '" +
+	"UNB+UNOA:1+SENDER+RECEIVER+220101:1200+1'" +
+	"UNH+1+ORDERS:D:96A:UN'" +
+	"BGM+220+PO123?+A+9'" +
+	"UNT+3+1'" +
+	"UNZ+1+1'"
+
+func TestParseX12(t *testing.T) {
+	docs, err := Parse(context.Background(), testX12)
+	assert.NoError(t, err)
+	assert.Len(t, docs, 2)
+
+	assert.Equal(t, "x12", docs[0].Standard)
+	assert.Equal(t, "850", docs[0].Type)
+	assert.Equal(t, "0001", docs[0].Control)
+	assert.Equal(t, "004010", docs[0].Version)
+	assert.Len(t, docs[0].Segments, 4)
+	assert.Equal(t, "PO1", docs[0].Segments[2].Tag)
+	assert.Equal(t, []string{"WIDGET", "BLUE"}, docs[0].Segments[2].Elements[6])
+	assert.Equal(t, "x12_850", docs[0].Datatype().Name)
+
+	assert.Equal(t, "810", docs[1].Type)
+	assert.Equal(t, "005010", docs[1].Version)
+}
+
+func TestParseEDIFACT(t *testing.T) {
+	docs, err := Parse(context.Background(), testEDIFACT)
+	assert.NoError(t, err)
+	assert.Len(t, docs, 1)
+	assert.Equal(t, "edifact", docs[0].Standard)
+	assert.Equal(t, "ORDERS", docs[0].Type)
+	assert.Equal(t, "1", docs[0].Control)
+	assert.Equal(t, "D96A", docs[0].Version)
+	assert.Equal(t, "PO123+A", docs[0].Segments[1].Elements[1])
+	assert.Equal(t, "edifact_ORDERS", docs[0].Datatype().Name)
+	assert.Equal(t, "D96A", docs[0].Datatype().Version)
+}
+
+func TestParseEDIFACTDefaultDelimiters(t *testing.T) {
+	docs, err := Parse(context.Background(), "UNB+UNOA:1'UNH+1+INVOIC'UNT+2+1'UNZ+1+1")
+	assert.NoError(t, err)
+	assert.Equal(t, "INVOIC", docs[0].Type)
+	assert.Empty(t, docs[0].Version)
+}
+
+func TestParseUnknownFormat(t *testing.T) {
+	_, err := Parse(context.Background(), `{"not":"edi"}`)
+	assert.Regexp(t, "FF10473", err)
+}
+
+func TestParseTruncated(t *testing.T) {
+	_, err := Parse(context.Background(), "ISA*00")
+	assert.Regexp(t, "FF10474.*ISA", err)
+	_, err = Parse(context.Background(), "UNA:+")
+	assert.Regexp(t, "FF10474.*UNA", err)
+}
+
+func TestParseMissingTrailer(t *testing.T) {
+	_, err := Parse(context.Background(), "UNB+UNOA:1'UNH+1+ORDERS:D:96A:UN'BGM+220'")
+	assert.Regexp(t, "FF10474.*missing UNT", err)
+}
+
+func TestParseNestedHeader(t *testing.T) {
+	_, err := Parse(context.Background(), "UNB+UNOA:1'UNH+1+ORDERS'UNH+2+ORDERS'UNT+2+2'")
+	assert.Regexp(t, "FF10474.*UNH segment before UNT", err)
+}
+
+func TestParseNoDocuments(t *testing.T) {
+	_, err := Parse(context.Background(), "UNB+UNOA:1'UNZ+0+1'")
+	assert.Regexp(t, "FF10474.*no UNH", err)
+}
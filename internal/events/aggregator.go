@@ -18,16 +18,17 @@ package events
 
 import (
 	"context"
-	"crypto/sha256"
 	"database/sql/driver"
 	"strings"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/definitions"
+	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/internal/pinning"
 	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -51,9 +52,10 @@ type aggregator struct {
 	queuedRewinds chan *fftypes.UUID
 	retry         *retry.Retry
 	metrics       metrics.Manager
+	pinning       *pinning.Policy
 }
 
-func newAggregator(ctx context.Context, di database.Plugin, bi blockchain.Plugin, sh definitions.DefinitionHandlers, im identity.Manager, dm data.Manager, en *eventNotifier, mm metrics.Manager) *aggregator {
+func newAggregator(ctx context.Context, di database.Plugin, bi blockchain.Plugin, sh definitions.DefinitionHandlers, im identity.Manager, dm data.Manager, en *eventNotifier, mm metrics.Manager, pp *pinning.Policy) *aggregator {
 	batchSize := config.GetInt(config.EventAggregatorBatchSize)
 	ag := &aggregator{
 		ctx:           log.WithLogField(ctx, "role", "aggregator"),
@@ -66,6 +68,7 @@ func newAggregator(ctx context.Context, di database.Plugin, bi blockchain.Plugin
 		rewindBatches: make(chan *fftypes.UUID, 1), // hops to queuedRewinds with a shouldertab on the event poller
 		queuedRewinds: make(chan *fftypes.UUID, batchSize),
 		metrics:       mm,
+		pinning:       pp,
 	}
 	firstEvent := fftypes.SubOptsFirstEvent(config.GetString(config.EventAggregatorFirstEvent))
 	ag.eventPoller = newEventPoller(ctx, di, en, &eventPollerConf{
@@ -73,18 +76,14 @@ func newAggregator(ctx context.Context, di database.Plugin, bi blockchain.Plugin
 		eventBatchTimeout:          config.GetDuration(config.EventAggregatorBatchTimeout),
 		eventPollTimeout:           config.GetDuration(config.EventAggregatorPollTimeout),
 		startupOffsetRetryAttempts: config.GetInt(config.OrchestratorStartupAttempts),
-		retry: retry.Retry{
-			InitialDelay: config.GetDuration(config.EventAggregatorRetryInitDelay),
-			MaximumDelay: config.GetDuration(config.EventAggregatorRetryMaxDelay),
-			Factor:       config.GetFloat64(config.EventAggregatorRetryFactor),
-		},
-		firstEvent:       &firstEvent,
-		namespace:        fftypes.SystemNamespace,
-		offsetType:       fftypes.OffsetTypeAggregator,
-		offsetName:       aggregatorOffsetName,
-		newEventsHandler: ag.processPinsEventsHandler,
-		getItems:         ag.getPins,
-		queryFactory:     database.PinQueryFactory,
+		retry:                      *retry.NewFromConfig(config.EventAggregatorRetryInitDelay, config.EventAggregatorRetryMaxDelay, config.EventAggregatorRetryFactor),
+		firstEvent:                 &firstEvent,
+		namespace:                  fftypes.SystemNamespace,
+		offsetType:                 fftypes.OffsetTypeAggregator,
+		offsetName:                 aggregatorOffsetName,
+		newEventsHandler:           ag.processPinsEventsHandler,
+		getItems:                   ag.getPins,
+		queryFactory:               database.PinQueryFactory,
 		addCriteria: func(af database.AndFilter) database.AndFilter {
 			return af.Condition(af.Builder().Eq("dispatched", false))
 		},
@@ -377,7 +376,7 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *fftypes.Batc
 				l.Errorf("Message '%s' in batch '%s' has invalid pin at index %d: '%s'", msg.Header.ID, manifest.ID, i, pinStr)
 				return nil
 			}
-			nextPin, err := state.CheckMaskedContextReady(ctx, msg, msg.Header.Topics[i], pin.Sequence, &msgContext, nonceStr)
+			nextPin, err := state.CheckMaskedContextReady(ctx, msg, msg.Header.PinContext(msg.Header.Topics[i]), pin.Sequence, &msgContext, nonceStr)
 			if err != nil || nextPin == nil {
 				return err
 			}
@@ -385,8 +384,8 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *fftypes.Batc
 		}
 	} else {
 		for i, topic := range msg.Header.Topics {
-			h := sha256.New()
-			h.Write([]byte(topic))
+			h := pinning.NewContextHash(ag.pinning.ContextSalt(msg.Header.Namespace))
+			h.Write([]byte(msg.Header.PinContext(topic)))
 			msgContext := fftypes.HashResult(h)
 			unmaskedContexts = append(unmaskedContexts, msgContext)
 			ready, err := state.CheckUnmaskedContextReady(ctx, msgContext, msg, msg.Header.Topics[i], pin.Sequence)
@@ -414,7 +413,7 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *fftypes.Batc
 		for _, np := range nextPins {
 			np.IncrementNextPin(ctx)
 		}
-		state.MarkMessageDispatched(ctx, manifest.ID, msg, msgBaseIndex, newState)
+		state.MarkMessageDispatched(ctx, manifest.ID, manifest.TX.ID, msg, msgBaseIndex, newState)
 	} else {
 		for _, unmaskedContext := range unmaskedContexts {
 			state.SetContextBlockedBy(ctx, *unmaskedContext, pin.Sequence)
@@ -426,13 +425,19 @@ func (ag *aggregator) processMessage(ctx context.Context, manifest *fftypes.Batc
 
 func (ag *aggregator) attemptMessageDispatch(ctx context.Context, msg *fftypes.Message, data fftypes.DataArray, tx *fftypes.UUID, state *batchState, pin *fftypes.Pin) (newState fftypes.MessageState, valid bool, err error) {
 
+	// In a namespace with deferred pinning, the message might already have been confirmed off-chain
+	if pin.Masked && msg.State == fftypes.MessageStateConfirmed && ag.pinning.IsDeferred(msg.Header.Namespace) {
+		return ag.reconcileDeferredMessage(ctx, msg, tx, state, pin)
+	}
+
 	// Check the pin signer is valid for the message
 	if valid, err := ag.checkOnchainConsistency(ctx, msg, pin); err != nil || !valid {
 		return "", false, err
 	}
 
-	// Verify we have all the blobs for the data
-	if resolved, err := ag.resolveBlobs(ctx, data); err != nil || !resolved {
+	// Verify we have all the blobs for the data, and that the scanner has not quarantined any of them
+	resolved, quarantined, err := ag.resolveBlobs(ctx, data)
+	if err != nil || !resolved {
 		return "", false, err
 	}
 
@@ -451,10 +456,26 @@ func (ag *aggregator) attemptMessageDispatch(ctx context.Context, msg *fftypes.M
 		}
 	}
 
-	// Validate the message data
+	// Outside of the system namespace, messages are only accepted from orgs that have been endorsed by the network.
+	// Definitions are checked by the definition handlers.
 	valid = true
+	if msg.Header.Type != fftypes.MessageTypeDefinition && msg.Header.Namespace != fftypes.SystemNamespace {
+		if valid, err = ag.identity.VerifyAuthorEndorsed(ctx, msg.Header.Author); err != nil {
+			return "", false, err
+		}
+	}
+
+	// Validate the message data
 	var customCorrelator *fftypes.UUID
+	var quarantineReason string
 	switch {
+	case !valid:
+		log.L(ctx).Errorf("Message '%s' rejected - author '%s' has not been endorsed", msg.Header.ID, msg.Header.Author)
+
+	case quarantined != nil:
+		// The content of an infected blob must never reach an application, including via a definition handler
+		quarantineReason = quarantined.Error()
+
 	case msg.Header.Type == fftypes.MessageTypeDefinition:
 		// We handle definition events in-line on the aggregator, as it would be confusing for apps to be
 		// dispatched subsequent events before we have processed the definition events they depend on.
@@ -473,24 +494,51 @@ func (ag *aggregator) attemptMessageDispatch(ctx context.Context, msg *fftypes.M
 		// Already handled as part of resolving the context - do nothing.
 
 	case len(msg.Data) > 0:
-		valid, err = ag.data.ValidateAll(ctx, data)
+		dataValid, reason, err := ag.data.ValidateAll(ctx, data)
 		if err != nil {
 			return "", false, err
 		}
+		if !dataValid {
+			quarantineReason = reason
+		}
 	}
 
 	newState = fftypes.MessageStateConfirmed
 	eventType := fftypes.EventTypeMessageConfirmed
-	if valid {
+	switch {
+	case quarantineReason != "":
+		// The message is quarantined rather than rejected, so it can be reprocessed once the cause is fixed.
+		// Either way the topic moves on past it.
+		log.L(ctx).Errorf("Message '%s' quarantined - data failed validation: %s", msg.Header.ID, quarantineReason)
+		newState = fftypes.MessageStateQuarantined
+		eventType = fftypes.EventTypeMessageQuarantined
+	case valid:
 		state.pendingConfirms[*msg.Header.ID] = msg
-	} else {
+	default:
 		newState = fftypes.MessageStateRejected
 		eventType = fftypes.EventTypeMessageRejected
 	}
 
 	state.AddFinalize(func(ctx context.Context) error {
+		if quarantineReason != "" {
+			if err := ag.database.InsertQuarantinedMessage(ctx, &fftypes.QuarantinedMessage{
+				ID:        fftypes.NewUUID(),
+				Namespace: msg.Header.Namespace,
+				Message:   msg.Header.ID,
+				Batch:     msg.BatchID,
+				Reason:    quarantineReason,
+			}); err != nil {
+				return err
+			}
+		}
 		// Generate the appropriate event - one per topic (events cover a single topic)
 		for _, topic := range msg.Header.Topics {
+			if quarantined != nil {
+				// Security event for the infected blob, in addition to the quarantine of the message
+				if err := ag.database.InsertEvent(ctx, fftypes.NewEvent(fftypes.EventTypeBlobQuarantined, msg.Header.Namespace, msg.Header.ID, tx, topic)); err != nil {
+					return err
+				}
+			}
 			event := fftypes.NewEvent(eventType, msg.Header.Namespace, msg.Header.ID, tx, topic)
 			event.Correlator = msg.Header.CID
 			if customCorrelator != nil {
@@ -510,9 +558,34 @@ func (ag *aggregator) attemptMessageDispatch(ctx context.Context, msg *fftypes.M
 	return newState, true, nil
 }
 
+// reconcileDeferredMessage moves a message that was confirmed off-chain to anchored, once its pin has landed on the blockchain.
+// The message cannot be un-confirmed, so the context moves past the pin even if the anchor does not match the message.
+func (ag *aggregator) reconcileDeferredMessage(ctx context.Context, msg *fftypes.Message, tx *fftypes.UUID, state *batchState, pin *fftypes.Pin) (newState fftypes.MessageState, valid bool, err error) {
+	if valid, err := ag.checkOnchainConsistency(ctx, msg, pin); err != nil {
+		return "", false, err
+	} else if !valid {
+		log.L(ctx).Errorf("Message '%s' was confirmed off-chain, but does not match its anchored pin %.10d", msg.Header.ID, pin.Sequence)
+		return fftypes.MessageStateConfirmed, true, nil
+	}
+
+	log.L(ctx).Infof("Message '%s' confirmed off-chain has been anchored by pin %.10d", msg.Header.ID, pin.Sequence)
+	state.AddFinalize(func(ctx context.Context) error {
+		for _, topic := range msg.Header.Topics {
+			event := fftypes.NewEvent(fftypes.EventTypeMessageAnchored, msg.Header.Namespace, msg.Header.ID, tx, topic)
+			event.Correlator = msg.Header.CID
+			if err := ag.database.InsertEvent(ctx, event); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return fftypes.MessageStateAnchored, true, nil
+}
+
 // resolveBlobs ensures that the blobs for all the attachments in the data array, have been received into the
-// local data exchange blob store. Either because of a private transfer, or by downloading them from the shared storage
-func (ag *aggregator) resolveBlobs(ctx context.Context, data fftypes.DataArray) (resolved bool, err error) {
+// local data exchange blob store. Either because of a private transfer, or by downloading them from the shared storage.
+// If the scanner found a threat in any of the blobs, an error describing it is returned as quarantined.
+func (ag *aggregator) resolveBlobs(ctx context.Context, data fftypes.DataArray) (resolved bool, quarantined error, err error) {
 	l := log.L(ctx)
 
 	for _, d := range data {
@@ -523,33 +596,32 @@ func (ag *aggregator) resolveBlobs(ctx context.Context, data fftypes.DataArray)
 		// See if we already have the data
 		blob, err := ag.database.GetBlobMatchingHash(ctx, d.Blob.Hash)
 		if err != nil {
-			return false, err
+			return false, nil, err
 		}
 		if blob != nil {
 			l.Debugf("Blob '%s' found in local DX with ref '%s'", blob.Hash, blob.PayloadRef)
-			continue
-		}
-
-		// If there's a public reference, download it from there and stream it into the blob store
-		// We double check the hash on the way, to ensure the streaming from A->B worked ok.
-		if d.Blob.Public != "" {
+		} else if d.Blob.Public != "" {
+			// If there's a public reference, download it from there and stream it into the blob store
+			// We double check the hash on the way, to ensure the streaming from A->B worked ok.
 			blob, err = ag.data.CopyBlobPStoDX(ctx, d)
 			if err != nil {
-				return false, err
+				return false, nil, err
 			}
 			if blob != nil {
 				l.Debugf("Blob '%s' for data %s downloaded from shared storage to local DX with ref '%s'", blob.Hash, d.ID, blob.PayloadRef)
-				continue
 			}
 		}
 
-		// If we've reached here, the data isn't available yet.
-		// This isn't an error, we just need to wait for it to arrive.
-		l.Debugf("Blob '%s' not available for data %s", d.Blob.Hash, d.ID)
-		return false, nil
-
+		if blob == nil {
+			// If we've reached here, the data isn't available yet.
+			// This isn't an error, we just need to wait for it to arrive.
+			l.Debugf("Blob '%s' not available for data %s", d.Blob.Hash, d.ID)
+			return false, nil, nil
+		}
+		if blob.Threat != "" && quarantined == nil {
+			quarantined = i18n.NewError(ctx, i18n.MsgBlobQuarantined, blob.Hash, blob.Threat)
+		}
 	}
 
-	return true, nil
-
+	return true, quarantined, nil
 }
@@ -18,12 +18,12 @@ package events
 
 import (
 	"context"
-	"crypto/sha256"
 	"database/sql/driver"
 	"encoding/binary"
 
 	"github.com/hyperledger/firefly/internal/definitions"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/pinning"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/sirupsen/logrus"
@@ -37,6 +37,7 @@ func newBatchState(ag *aggregator) *batchState {
 		unmaskedContexts:   make(map[fftypes.Bytes32]*contextState),
 		dispatchedMessages: make([]*dispatchedMessage, 0),
 		pendingConfirms:    make(map[fftypes.UUID]*fftypes.Message),
+		pinning:            ag.pinning,
 
 		PreFinalize: make([]func(ctx context.Context) error, 0),
 		Finalize:    make([]func(ctx context.Context) error, 0),
@@ -50,6 +51,7 @@ func newBatchState(ag *aggregator) *batchState {
 type nextPinGroupState struct {
 	groupID           *fftypes.Bytes32
 	topic             string
+	salt              string
 	nextPins          []*fftypes.NextPin
 	new               bool
 	identitiesChanged map[string]bool
@@ -70,7 +72,10 @@ type contextState struct {
 // so that all pins associated to the message can be marked dispatched at the end of the batch.
 type dispatchedMessage struct {
 	batchID       *fftypes.UUID
+	txID          *fftypes.UUID
 	msgID         *fftypes.UUID
+	namespace     string
+	cid           *fftypes.UUID
 	firstPinIndex int64
 	topicCount    int
 	msgPins       fftypes.FFStringArray
@@ -98,6 +103,7 @@ type batchState struct {
 	unmaskedContexts   map[fftypes.Bytes32]*contextState
 	dispatchedMessages []*dispatchedMessage
 	pendingConfirms    map[fftypes.UUID]*fftypes.Message
+	pinning            *pinning.Policy
 
 	// PreFinalize callbacks may perform blocking actions (possibly to an external connector)
 	// - Will execute after all batch messages have been processed
@@ -184,11 +190,12 @@ func (bs *batchState) CheckMaskedContextReady(ctx context.Context, msg *fftypes.
 	// For masked pins, we can only process if:
 	// - it is the next sequence on this context for one of the members of the group
 	// - there are no undispatched messages on this context earlier in the stream
-	h := sha256.New()
+	salt := bs.pinning.ContextSalt(msg.Header.Namespace)
+	h := pinning.NewContextHash(salt)
 	h.Write([]byte(topic))
 	h.Write((*msg.Header.Group)[:])
 	contextUnmasked := fftypes.HashResult(h)
-	npg, err := bs.stateForMaskedContext(ctx, msg.Header.Group, topic, *contextUnmasked)
+	npg, err := bs.stateForMaskedContext(ctx, msg.Header.Group, topic, salt, *contextUnmasked)
 	if err != nil {
 		return nil, err
 	}
@@ -196,7 +203,7 @@ func (bs *batchState) CheckMaskedContextReady(ctx context.Context, msg *fftypes.
 		// If this is the first time we've seen the context, then this message is read as long as it is
 		// the first (nonce=0) message on the context, for one of the members, and there aren't any earlier
 		// messages that are nonce=0.
-		return bs.attemptContextInit(ctx, msg, topic, firstMsgPinSequence, contextUnmasked, pin)
+		return bs.attemptContextInit(ctx, msg, topic, salt, firstMsgPinSequence, contextUnmasked, pin)
 	}
 
 	// This message must be the next hash for the author
@@ -223,10 +230,13 @@ func (bs *batchState) CheckMaskedContextReady(ctx context.Context, msg *fftypes.
 	}, err
 }
 
-func (bs *batchState) MarkMessageDispatched(ctx context.Context, batchID *fftypes.UUID, msg *fftypes.Message, msgBaseIndex int64, newState fftypes.MessageState) {
+func (bs *batchState) MarkMessageDispatched(ctx context.Context, batchID, txID *fftypes.UUID, msg *fftypes.Message, msgBaseIndex int64, newState fftypes.MessageState) {
 	bs.dispatchedMessages = append(bs.dispatchedMessages, &dispatchedMessage{
 		batchID:       batchID,
+		txID:          txID,
 		msgID:         msg.Header.ID,
+		namespace:     msg.Header.Namespace,
+		cid:           msg.Header.CID,
 		firstPinIndex: msgBaseIndex,
 		topicCount:    len(msg.Header.Topics),
 		msgPins:       msg.Pins,
@@ -314,6 +324,63 @@ func (bs *batchState) flushPins(ctx context.Context) error {
 		}
 	}
 
+	return bs.flushMessageStates(ctx, confirmTime)
+}
+
+// flushMessageStates maintains the state record of each dispatched message, and the reply count in the
+// state record of each request that a confirmed message correlates to
+func (bs *batchState) flushMessageStates(ctx context.Context, confirmTime *fftypes.FFTime) error {
+	if len(bs.dispatchedMessages) == 0 {
+		return nil
+	}
+
+	ids := make([]driver.Value, 0, len(bs.dispatchedMessages))
+	for _, dm := range bs.dispatchedMessages {
+		ids = append(ids, dm.msgID)
+		if dm.cid != nil && dm.newState == fftypes.MessageStateConfirmed {
+			ids = append(ids, dm.cid)
+		}
+	}
+	fb := database.MessageStateQueryFactory.NewFilter(ctx)
+	existing, _, err := bs.database.GetMessageStates(ctx, fb.In("id", ids))
+	if err != nil {
+		return err
+	}
+	states := make(map[fftypes.UUID]*fftypes.MessageStateRecord)
+	for _, state := range existing {
+		states[*state.ID] = state
+	}
+
+	var updated []*fftypes.MessageStateRecord
+	isUpdated := make(map[fftypes.UUID]bool)
+	stateFor := func(ns string, id *fftypes.UUID) *fftypes.MessageStateRecord {
+		state, ok := states[*id]
+		if !ok {
+			state = &fftypes.MessageStateRecord{ID: id, Namespace: ns}
+			states[*id] = state
+		}
+		if !isUpdated[*id] {
+			isUpdated[*id] = true
+			updated = append(updated, state)
+		}
+		return state
+	}
+	for _, dm := range bs.dispatchedMessages {
+		state := stateFor(dm.namespace, dm.msgID)
+		state.State = dm.newState
+		state.Confirmed = confirmTime
+		state.Batch = dm.batchID
+		state.TX = dm.txID
+		if dm.cid != nil && dm.newState == fftypes.MessageStateConfirmed {
+			stateFor(dm.namespace, dm.cid).ReplyCount++
+		}
+	}
+
+	for _, state := range updated {
+		if err := bs.database.UpsertMessageState(ctx, state); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -340,7 +407,7 @@ func (nps *nextPinState) IncrementNextPin(ctx context.Context) {
 }
 
 func (npg *nextPinGroupState) calcPinHash(identity string, nonce int64) *fftypes.Bytes32 {
-	h := sha256.New()
+	h := pinning.NewContextHash(npg.salt)
 	h.Write([]byte(npg.topic))
 	h.Write((*npg.groupID)[:])
 	h.Write([]byte(identity))
@@ -350,7 +417,7 @@ func (npg *nextPinGroupState) calcPinHash(identity string, nonce int64) *fftypes
 	return fftypes.HashResult(h)
 }
 
-func (bs *batchState) stateForMaskedContext(ctx context.Context, groupID *fftypes.Bytes32, topic string, contextUnmasked fftypes.Bytes32) (*nextPinGroupState, error) {
+func (bs *batchState) stateForMaskedContext(ctx context.Context, groupID *fftypes.Bytes32, topic, salt string, contextUnmasked fftypes.Bytes32) (*nextPinGroupState, error) {
 
 	if npg, exists := bs.maskedContexts[contextUnmasked]; exists {
 		return npg, nil
@@ -369,6 +436,7 @@ func (bs *batchState) stateForMaskedContext(ctx context.Context, groupID *fftype
 	npg := &nextPinGroupState{
 		groupID:           groupID,
 		topic:             topic,
+		salt:              salt,
 		identitiesChanged: make(map[string]bool),
 		nextPins:          nextPins,
 	}
@@ -377,7 +445,7 @@ func (bs *batchState) stateForMaskedContext(ctx context.Context, groupID *fftype
 
 }
 
-func (bs *batchState) attemptContextInit(ctx context.Context, msg *fftypes.Message, topic string, pinnedSequence int64, contextUnmasked, pin *fftypes.Bytes32) (*nextPinState, error) {
+func (bs *batchState) attemptContextInit(ctx context.Context, msg *fftypes.Message, topic, salt string, pinnedSequence int64, contextUnmasked, pin *fftypes.Bytes32) (*nextPinState, error) {
 	l := log.L(ctx)
 
 	// It might be the system topic/context initializing the group
@@ -390,6 +458,7 @@ func (bs *batchState) attemptContextInit(ctx context.Context, msg *fftypes.Messa
 	npg := &nextPinGroupState{
 		groupID:           msg.Header.Group,
 		topic:             topic,
+		salt:              salt,
 		new:               true,
 		identitiesChanged: make(map[string]bool),
 		nextPins:          make([]*fftypes.NextPin, len(group.Members)),
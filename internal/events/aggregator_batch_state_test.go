@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -34,7 +35,7 @@ func TestFlushPinsFailUpdatePins(t *testing.T) {
 	mdi := ag.database.(*databasemocks.Plugin)
 	mdi.On("UpdatePins", ag.ctx, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 
-	bs.MarkMessageDispatched(ag.ctx, fftypes.NewUUID(), &fftypes.Message{
+	bs.MarkMessageDispatched(ag.ctx, fftypes.NewUUID(), fftypes.NewUUID(), &fftypes.Message{
 		Header: fftypes.MessageHeader{
 			ID:     fftypes.NewUUID(),
 			Topics: fftypes.FFStringArray{"topic1"},
@@ -55,7 +56,7 @@ func TestFlushPinsFailUpdateMessages(t *testing.T) {
 	mdi.On("UpdatePins", ag.ctx, mock.Anything, mock.Anything).Return(nil)
 	mdi.On("UpdateMessages", ag.ctx, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 
-	bs.MarkMessageDispatched(ag.ctx, fftypes.NewUUID(), &fftypes.Message{
+	bs.MarkMessageDispatched(ag.ctx, fftypes.NewUUID(), fftypes.NewUUID(), &fftypes.Message{
 		Header: fftypes.MessageHeader{
 			ID:     fftypes.NewUUID(),
 			Topics: fftypes.FFStringArray{"topic1"},
@@ -67,6 +68,103 @@ func TestFlushPinsFailUpdateMessages(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 }
 
+func TestFlushMessageStates(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+
+	batchID := fftypes.NewUUID()
+	txID := fftypes.NewUUID()
+	knownRequest := &fftypes.MessageStateRecord{
+		ID:         fftypes.NewUUID(),
+		Namespace:  "ns1",
+		State:      fftypes.MessageStateConfirmed,
+		ReplyCount: 2,
+	}
+	unknownRequest := fftypes.NewUUID()
+	newMsg := func(cid *fftypes.UUID) *fftypes.Message {
+		return &fftypes.Message{
+			Header: fftypes.MessageHeader{
+				ID:        fftypes.NewUUID(),
+				CID:       cid,
+				Namespace: "ns1",
+			},
+		}
+	}
+	reply1 := newMsg(knownRequest.ID)
+	reply2 := newMsg(unknownRequest)
+	rejected := newMsg(knownRequest.ID)
+	bs.MarkMessageDispatched(ag.ctx, batchID, txID, reply1, 0, fftypes.MessageStateConfirmed)
+	bs.MarkMessageDispatched(ag.ctx, batchID, txID, reply2, 0, fftypes.MessageStateConfirmed)
+	bs.MarkMessageDispatched(ag.ctx, batchID, txID, rejected, 0, fftypes.MessageStateRejected)
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("UpdateMessages", ag.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetMessageStates", ag.ctx, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Field == "id" && len(fi.Values) == 5
+	})).Return([]*fftypes.MessageStateRecord{knownRequest}, nil, nil)
+	var upserted []*fftypes.MessageStateRecord
+	mdi.On("UpsertMessageState", ag.ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		upserted = append(upserted, args[1].(*fftypes.MessageStateRecord))
+	})
+
+	err := bs.flushPins(ag.ctx)
+	assert.NoError(t, err)
+
+	assert.Len(t, upserted, 5)
+	assert.Equal(t, reply1.Header.ID, upserted[0].ID)
+	assert.Equal(t, fftypes.MessageStateConfirmed, upserted[0].State)
+	assert.Equal(t, batchID, upserted[0].Batch)
+	assert.Equal(t, txID, upserted[0].TX)
+	assert.NotNil(t, upserted[0].Confirmed)
+	assert.Equal(t, knownRequest, upserted[1])
+	assert.Equal(t, int64(3), knownRequest.ReplyCount)
+	assert.Equal(t, reply2.Header.ID, upserted[2].ID)
+	assert.Equal(t, unknownRequest, upserted[3].ID)
+	assert.Equal(t, fftypes.MessageState(""), upserted[3].State)
+	assert.Equal(t, int64(1), upserted[3].ReplyCount)
+	assert.Equal(t, rejected.Header.ID, upserted[4].ID)
+	assert.Equal(t, fftypes.MessageStateRejected, upserted[4].State)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestFlushMessageStatesGetFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+
+	bs.MarkMessageDispatched(ag.ctx, fftypes.NewUUID(), fftypes.NewUUID(), &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID()},
+	}, 0, fftypes.MessageStateConfirmed)
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("UpdateMessages", ag.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetMessageStates", ag.ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := bs.flushPins(ag.ctx)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestFlushMessageStatesUpsertFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+
+	bs.MarkMessageDispatched(ag.ctx, fftypes.NewUUID(), fftypes.NewUUID(), &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID()},
+	}, 0, fftypes.MessageStateConfirmed)
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("UpdateMessages", ag.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetMessageStates", ag.ctx, mock.Anything).Return([]*fftypes.MessageStateRecord{}, nil, nil)
+	mdi.On("UpsertMessageState", ag.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := bs.flushPins(ag.ctx)
+	assert.Regexp(t, "pop", err)
+}
+
 func TestSetContextBlockedByNoState(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	defer cancel()
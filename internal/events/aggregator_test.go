@@ -18,6 +18,7 @@ package events
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
@@ -26,7 +27,9 @@ import (
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/definitions"
+	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/pinning"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
@@ -51,8 +54,10 @@ func newTestAggregatorCommon(metrics bool) (*aggregator, func()) {
 	}
 	mmi.On("IsMetricsEnabled").Return(metrics)
 	mbi.On("VerifierType").Return(fftypes.VerifierTypeEthAddress)
+	mim.On("VerifyAuthorEndorsed", mock.Anything, mock.Anything).Return(true, nil).Maybe()
 	ctx, cancel := context.WithCancel(context.Background())
-	ag := newAggregator(ctx, mdi, mbi, msh, mim, mdm, newEventNotifier(ctx, "ut"), mmi)
+	pp, _ := pinning.NewPolicy(ctx)
+	ag := newAggregator(ctx, mdi, mbi, msh, mim, mdm, newEventNotifier(ctx, "ut"), mmi, pp)
 	return ag, cancel
 }
 
@@ -208,7 +213,7 @@ func TestAggregationMaskedZeroNonceMatch(t *testing.T) {
 	})).Return(nil).Once()
 	// Validate the message is ok
 	mdm.On("GetMessageWithDataCached", ag.ctx, batch.Payload.Messages[0].Header.ID, data.CRORequirePins).Return(batch.Payload.Messages[0], fftypes.DataArray{}, true, nil)
-	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, "", nil)
 	// Insert the confirmed event
 	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
 		return *e.Reference == *msgID && e.Type == fftypes.EventTypeMessageConfirmed
@@ -233,6 +238,8 @@ func TestAggregationMaskedZeroNonceMatch(t *testing.T) {
 
 		return true
 	})).Return(nil)
+	mdi.On("GetMessageStates", ag.ctx, mock.Anything).Return([]*fftypes.MessageStateRecord{}, nil, nil)
+	mdi.On("UpsertMessageState", ag.ctx, mock.Anything).Return(nil)
 
 	err := ag.processPins(ag.ctx, []*fftypes.Pin{
 		{
@@ -332,7 +339,7 @@ func TestAggregationMaskedNextSequenceMatch(t *testing.T) {
 	}, nil, nil).Once()
 	// Validate the message is ok
 	mdm.On("GetMessageWithDataCached", ag.ctx, batch.Payload.Messages[0].Header.ID, data.CRORequirePins).Return(batch.Payload.Messages[0], fftypes.DataArray{}, true, nil)
-	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, "", nil)
 	// Insert the confirmed event
 	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
 		return *e.Reference == *msgID && e.Type == fftypes.EventTypeMessageConfirmed
@@ -354,6 +361,8 @@ func TestAggregationMaskedNextSequenceMatch(t *testing.T) {
 	mdi.On("UpdatePins", ag.ctx, mock.Anything, mock.Anything).Return(nil)
 	// Update the message
 	mdi.On("UpdateMessages", ag.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetMessageStates", ag.ctx, mock.Anything).Return([]*fftypes.MessageStateRecord{}, nil, nil)
+	mdi.On("UpsertMessageState", ag.ctx, mock.Anything).Return(nil)
 
 	_, err := ag.processPinsEventsHandler([]fftypes.LocallySequenced{
 		&fftypes.Pin{
@@ -431,7 +440,7 @@ func TestAggregationBroadcast(t *testing.T) {
 	mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
 	// Validate the message is ok
 	mdm.On("GetMessageWithDataCached", ag.ctx, batch.Payload.Messages[0].Header.ID, data.CRORequirePublicBlobRefs).Return(batch.Payload.Messages[0], fftypes.DataArray{}, true, nil)
-	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, "", nil)
 	// Insert the confirmed event
 	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
 		return *e.Reference == *msgID && e.Type == fftypes.EventTypeMessageConfirmed
@@ -440,6 +449,8 @@ func TestAggregationBroadcast(t *testing.T) {
 	mdi.On("UpdatePins", ag.ctx, mock.Anything, mock.Anything).Return(nil)
 	// Update the message
 	mdi.On("UpdateMessages", ag.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetMessageStates", ag.ctx, mock.Anything).Return([]*fftypes.MessageStateRecord{}, nil, nil)
+	mdi.On("UpsertMessageState", ag.ctx, mock.Anything).Return(nil)
 
 	err := ag.processPins(ag.ctx, []*fftypes.Pin{
 		{
@@ -525,7 +536,7 @@ func TestAggregationMigratedBroadcast(t *testing.T) {
 	mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
 	// Validate the message is ok
 	mdm.On("GetMessageWithDataCached", ag.ctx, batch.Payload.Messages[0].Header.ID, data.CRORequirePublicBlobRefs).Return(batch.Payload.Messages[0], fftypes.DataArray{}, true, nil)
-	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, "", nil)
 	// Insert the confirmed event
 	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
 		return *e.Reference == *msgID && e.Type == fftypes.EventTypeMessageConfirmed
@@ -534,6 +545,8 @@ func TestAggregationMigratedBroadcast(t *testing.T) {
 	mdi.On("UpdatePins", ag.ctx, mock.Anything, mock.Anything).Return(nil)
 	// Update the message
 	mdi.On("UpdateMessages", ag.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetMessageStates", ag.ctx, mock.Anything).Return([]*fftypes.MessageStateRecord{}, nil, nil)
+	mdi.On("UpsertMessageState", ag.ctx, mock.Anything).Return(nil)
 
 	err = ag.processPins(ag.ctx, []*fftypes.Pin{
 		{
@@ -1080,9 +1093,11 @@ func TestProcessMsgFailPinUpdate(t *testing.T) {
 		{Context: fftypes.NewRandB32(), Hash: pin, Identity: org1.DID},
 	}, nil, nil)
 	mdm.On("GetMessageWithDataCached", ag.ctx, mock.Anything, data.CRORequirePins).Return(msg, nil, true, nil)
-	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(false, nil)
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, "", nil)
 	mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(nil)
 	mdi.On("UpdateMessages", ag.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetMessageStates", ag.ctx, mock.Anything).Return([]*fftypes.MessageStateRecord{}, nil, nil)
+	mdi.On("UpsertMessageState", ag.ctx, mock.Anything).Return(nil)
 	mdi.On("UpdateNextPin", ag.ctx, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
 
 	err := ag.processMessage(ag.ctx, &fftypes.BatchManifest{
@@ -1145,7 +1160,7 @@ func TestAttemptContextInitGetGroupByIDFail(t *testing.T) {
 				Key:    "0x12345",
 			},
 		},
-	}, "topic1", 12345, fftypes.NewRandB32(), fftypes.NewRandB32())
+	}, "topic1", "", 12345, fftypes.NewRandB32(), fftypes.NewRandB32())
 	assert.EqualError(t, err, "pop")
 
 }
@@ -1167,7 +1182,7 @@ func TestAttemptContextInitGroupNotFound(t *testing.T) {
 				Key:    "0x12345",
 			},
 		},
-	}, "topic1", 12345, fftypes.NewRandB32(), fftypes.NewRandB32())
+	}, "topic1", "", 12345, fftypes.NewRandB32(), fftypes.NewRandB32())
 	assert.NoError(t, err)
 
 }
@@ -1198,7 +1213,7 @@ func TestAttemptContextInitAuthorMismatch(t *testing.T) {
 				Key:    "0x12345",
 			},
 		},
-	}, "topic1", 12345, fftypes.NewRandB32(), zeroHash)
+	}, "topic1", "", 12345, fftypes.NewRandB32(), zeroHash)
 	assert.NoError(t, err)
 
 }
@@ -1227,7 +1242,7 @@ func TestAttemptContextInitNoMatch(t *testing.T) {
 				Key:    "0x12345",
 			},
 		},
-	}, "topic1", 12345, fftypes.NewRandB32(), fftypes.NewRandB32())
+	}, "topic1", "", 12345, fftypes.NewRandB32(), fftypes.NewRandB32())
 	assert.NoError(t, err)
 
 }
@@ -1260,7 +1275,7 @@ func TestAttemptContextInitGetPinsFail(t *testing.T) {
 				Key:    "0x12345",
 			},
 		},
-	}, "topic1", 12345, fftypes.NewRandB32(), zeroHash)
+	}, "topic1", "", 12345, fftypes.NewRandB32(), zeroHash)
 	assert.EqualError(t, err, "pop")
 
 }
@@ -1295,7 +1310,7 @@ func TestAttemptContextInitGetPinsBlocked(t *testing.T) {
 				Key:    "0x12345",
 			},
 		},
-	}, "topic1", 12345, fftypes.NewRandB32(), zeroHash)
+	}, "topic1", "", 12345, fftypes.NewRandB32(), zeroHash)
 	assert.NoError(t, err)
 	assert.Nil(t, np)
 
@@ -1330,7 +1345,7 @@ func TestAttemptContextInitInsertPinsFail(t *testing.T) {
 				Key:    "0x12345",
 			},
 		},
-	}, "topic1", 12345, fftypes.NewRandB32(), zeroHash)
+	}, "topic1", "", 12345, fftypes.NewRandB32(), zeroHash)
 	assert.NoError(t, err)
 	assert.NotNil(t, np)
 	err = bs.RunFinalize(ag.ctx)
@@ -1348,7 +1363,7 @@ func TestAttemptMessageDispatchFailValidateData(t *testing.T) {
 	org1 := newTestOrg("org1")
 	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
 	mdm.On("GetMessageData", ag.ctx, mock.Anything, true).Return(fftypes.DataArray{}, true, nil)
-	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(false, fmt.Errorf("pop"))
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(false, "", fmt.Errorf("pop"))
 
 	_, _, err := ag.attemptMessageDispatch(ag.ctx, &fftypes.Message{
 		Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), SignerRef: fftypes.SignerRef{Key: "0x12345", Author: org1.DID}},
@@ -1516,6 +1531,8 @@ func TestDefinitionBroadcastActionRejectCustomCorrelator(t *testing.T) {
 
 		return true
 	})).Return(nil)
+	mdi.On("GetMessageStates", ag.ctx, mock.Anything).Return([]*fftypes.MessageStateRecord{}, nil, nil)
+	mdi.On("UpsertMessageState", ag.ctx, mock.Anything).Return(nil)
 	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(event *fftypes.Event) bool {
 		return event.Correlator.Equals(customCorrelator)
 	})).Return(nil)
@@ -1569,6 +1586,8 @@ func TestDefinitionBroadcastInvalidSigner(t *testing.T) {
 
 		return true
 	})).Return(nil)
+	mdi.On("GetMessageStates", ag.ctx, mock.Anything).Return([]*fftypes.MessageStateRecord{}, nil, nil)
+	mdi.On("UpsertMessageState", ag.ctx, mock.Anything).Return(nil)
 	mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(nil)
 
 	_, _, err := ag.attemptMessageDispatch(ag.ctx, &fftypes.Message{
@@ -1670,7 +1689,7 @@ func TestDispatchPrivateNextPinIncremented(t *testing.T) {
 	mdm := ag.data.(*datamocks.Manager)
 	mdm.On("GetMessageWithDataCached", ag.ctx, msg1.Header.ID, data.CRORequirePins).Return(msg1, fftypes.DataArray{}, true, nil).Once()
 	mdm.On("GetMessageWithDataCached", ag.ctx, msg2.Header.ID, data.CRORequirePins).Return(msg2, fftypes.DataArray{}, true, nil).Once()
-	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, "", nil)
 
 	initNPG := &nextPinGroupState{topic: "topic1", groupID: groupID}
 	member1NonceOne := initNPG.calcPinHash(org1.DID, 1)
@@ -1699,6 +1718,88 @@ func TestDispatchPrivateNextPinIncremented(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestDispatchPrivateOrderingKey(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+
+	groupID := fftypes.NewRandB32()
+	msg1, _, org1, manifest := newTestManifest(fftypes.MessageTypePrivate, groupID)
+	msg1.Header.OrderingKey = "account1"
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+
+	mdm := ag.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", ag.ctx, msg1.Header.ID, data.CRORequirePins).Return(msg1, fftypes.DataArray{}, true, nil).Once()
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, "", nil)
+
+	// The pins are calculated on the combination of the topic and the ordering key
+	initNPG := &nextPinGroupState{topic: "topic1/account1", groupID: groupID}
+	member1NonceOne := initNPG.calcPinHash(org1.DID, 1)
+	h := sha256.New()
+	h.Write([]byte("topic1/account1"))
+	h.Write((*groupID)[:])
+	context := fftypes.HashResult(h)
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("GetNextPins", ag.ctx, mock.Anything).Return([]*fftypes.NextPin{
+		{Context: context, Nonce: 1 /* match member1NonceOne */, Identity: org1.DID, Hash: member1NonceOne},
+	}, nil, nil)
+
+	msg1.Pins = fftypes.FFStringArray{member1NonceOne.String()}
+
+	err := ag.processMessage(ag.ctx, manifest, &fftypes.Pin{Masked: true, Sequence: 12345, Signer: "0x12345"}, 0, manifest.Messages[0], bs)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestDispatchPrivateSaltedContext(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "pinning": fftypes.JSONObject{"contextSalt": "secret"}},
+	})
+	defer config.Reset()
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+
+	groupID := fftypes.NewRandB32()
+	msg1, _, org1, manifest := newTestManifest(fftypes.MessageTypePrivate, groupID)
+	msg1.Header.Namespace = "ns1"
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+
+	mdm := ag.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", ag.ctx, msg1.Header.ID, data.CRORequirePins).Return(msg1, fftypes.DataArray{}, true, nil).Once()
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, "", nil)
+
+	// The pins are calculated with an HMAC keyed by the salt of the namespace
+	initNPG := &nextPinGroupState{topic: "topic1", salt: "secret", groupID: groupID}
+	member1NonceOne := initNPG.calcPinHash(org1.DID, 1)
+	h := hmac.New(sha256.New, []byte("secret"))
+	h.Write([]byte("topic1"))
+	h.Write((*groupID)[:])
+	context := fftypes.HashResult(h)
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("GetNextPins", ag.ctx, mock.Anything).Return([]*fftypes.NextPin{
+		{Context: context, Nonce: 1 /* match member1NonceOne */, Identity: org1.DID, Hash: member1NonceOne},
+	}, nil, nil)
+
+	msg1.Pins = fftypes.FFStringArray{member1NonceOne.String()}
+
+	err := ag.processMessage(ag.ctx, manifest, &fftypes.Pin{Masked: true, Sequence: 12345, Signer: "0x12345"}, 0, manifest.Messages[0], bs)
+	assert.NoError(t, err)
+	assert.Contains(t, bs.maskedContexts, *context)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
 func TestDefinitionBroadcastActionRetry(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	defer cancel()
@@ -1832,7 +1933,7 @@ func TestAttemptMessageDispatchEventFail(t *testing.T) {
 	mim := ag.identity.(*identitymanagermocks.Manager)
 
 	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
-	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, "", nil)
 	mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(fmt.Errorf("pop"))
 
 	_, _, err := ag.attemptMessageDispatch(ag.ctx, msg1, fftypes.DataArray{
@@ -1849,6 +1950,179 @@ func TestAttemptMessageDispatchEventFail(t *testing.T) {
 
 }
 
+func TestAttemptMessageDispatchQuarantined(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+	msg1.BatchID = fftypes.NewUUID()
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdm := ag.data.(*datamocks.Manager)
+	mim := ag.identity.(*identitymanagermocks.Manager)
+
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(false, "invalid", nil)
+	mdi.On("InsertQuarantinedMessage", ag.ctx, mock.MatchedBy(func(q *fftypes.QuarantinedMessage) bool {
+		return q.Message.Equals(msg1.Header.ID) && q.Batch.Equals(msg1.BatchID) && q.Reason == "invalid"
+	})).Return(nil)
+	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeMessageQuarantined
+	})).Return(nil)
+
+	newState, dispatched, err := ag.attemptMessageDispatch(ag.ctx, msg1, fftypes.DataArray{
+		&fftypes.Data{ID: msg1.Data[0].ID},
+	}, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.NoError(t, err)
+	assert.True(t, dispatched)
+	assert.Equal(t, fftypes.MessageStateQuarantined, newState)
+	assert.Empty(t, bs.pendingConfirms)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+
+}
+
+func TestAttemptMessageDispatchQuarantinedHashMismatch(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdm := ag.data.(*datamocks.Manager)
+	mim := ag.identity.(*identitymanagermocks.Manager)
+
+	data := &fftypes.Data{ID: msg1.Data[0].ID, Value: fftypes.JSONAnyPtr(`"tampered"`), Hash: fftypes.NewRandB32()}
+	reason := i18n.NewError(ag.ctx, i18n.MsgDataHashInvalid, data.Value.Hash(), data.Hash).Error()
+
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	mdm.On("ValidateAll", ag.ctx, fftypes.DataArray{data}).Return(false, reason, nil)
+	mdi.On("InsertQuarantinedMessage", ag.ctx, mock.MatchedBy(func(q *fftypes.QuarantinedMessage) bool {
+		return q.Message.Equals(msg1.Header.ID) && q.Reason == reason
+	})).Return(nil)
+	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeMessageQuarantined
+	})).Return(nil)
+
+	newState, dispatched, err := ag.attemptMessageDispatch(ag.ctx, msg1, fftypes.DataArray{data}, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.NoError(t, err)
+	assert.True(t, dispatched)
+	assert.Equal(t, fftypes.MessageStateQuarantined, newState)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+
+}
+
+func TestAttemptMessageDispatchQuarantineFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdm := ag.data.(*datamocks.Manager)
+	mim := ag.identity.(*identitymanagermocks.Manager)
+
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(false, "invalid", nil)
+	mdi.On("InsertQuarantinedMessage", ag.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, _, err := ag.attemptMessageDispatch(ag.ctx, msg1, fftypes.DataArray{
+		&fftypes.Data{ID: msg1.Data[0].ID},
+	}, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.NoError(t, err)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+
+}
+
+func TestAttemptMessageDispatchBlobQuarantined(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+	blobHash := fftypes.NewRandB32()
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mim := ag.identity.(*identitymanagermocks.Manager)
+
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	mdi.On("GetBlobMatchingHash", ag.ctx, blobHash).Return(&fftypes.Blob{
+		Hash:   blobHash,
+		Threat: "Eicar-Test-Signature",
+	}, nil)
+	mdi.On("InsertQuarantinedMessage", ag.ctx, mock.MatchedBy(func(q *fftypes.QuarantinedMessage) bool {
+		return q.Message.Equals(msg1.Header.ID)
+	})).Return(nil)
+	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeBlobQuarantined && event.Reference.Equals(msg1.Header.ID)
+	})).Return(nil)
+	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeMessageQuarantined
+	})).Return(nil)
+
+	newState, dispatched, err := ag.attemptMessageDispatch(ag.ctx, msg1, fftypes.DataArray{
+		&fftypes.Data{ID: msg1.Data[0].ID, Blob: &fftypes.BlobRef{Hash: blobHash}},
+	}, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.NoError(t, err)
+	assert.True(t, dispatched)
+	assert.Equal(t, fftypes.MessageStateQuarantined, newState)
+	assert.Empty(t, bs.pendingConfirms)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+
+}
+
+func TestAttemptMessageDispatchBlobQuarantinedEventFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypeBroadcast, nil)
+	blobHash := fftypes.NewRandB32()
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mim := ag.identity.(*identitymanagermocks.Manager)
+
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	mdi.On("GetBlobMatchingHash", ag.ctx, blobHash).Return(&fftypes.Blob{
+		Hash:   blobHash,
+		Threat: "Eicar-Test-Signature",
+	}, nil)
+	mdi.On("InsertQuarantinedMessage", ag.ctx, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, _, err := ag.attemptMessageDispatch(ag.ctx, msg1, fftypes.DataArray{
+		&fftypes.Data{ID: msg1.Data[0].ID, Blob: &fftypes.BlobRef{Hash: blobHash}},
+	}, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.NoError(t, err)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+
+}
+
 func TestAttemptMessageDispatchGroupInit(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	defer cancel()
@@ -1861,7 +2135,7 @@ func TestAttemptMessageDispatchGroupInit(t *testing.T) {
 
 	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
 	mdm.On("GetMessageData", ag.ctx, mock.Anything, true).Return(fftypes.DataArray{}, true, nil)
-	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, nil)
+	mdm.On("ValidateAll", ag.ctx, mock.Anything).Return(true, "", nil)
 	mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(nil)
 
 	_, _, err := ag.attemptMessageDispatch(ag.ctx, &fftypes.Message{
@@ -1875,6 +2149,65 @@ func TestAttemptMessageDispatchGroupInit(t *testing.T) {
 
 }
 
+func TestAttemptMessageDispatchAuthorNotEndorsed(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	bs := newBatchState(ag)
+	org1 := newTestOrg("org1")
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mim := &identitymanagermocks.Manager{}
+	ag.identity = mim
+
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	mim.On("VerifyAuthorEndorsed", ag.ctx, org1.DID).Return(false, nil)
+	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeMessageRejected
+	})).Return(nil)
+
+	newState, dispatched, err := ag.attemptMessageDispatch(ag.ctx, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Type:      fftypes.MessageTypeBroadcast,
+			SignerRef: fftypes.SignerRef{Key: "0x12345", Author: org1.DID},
+			Topics:    fftypes.FFStringArray{"topic1"},
+		},
+	}, nil, nil, bs, &fftypes.Pin{Signer: "0x12345"})
+	assert.NoError(t, err)
+	assert.True(t, dispatched)
+	assert.Equal(t, fftypes.MessageStateRejected, newState)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAttemptMessageDispatchAuthorEndorsedFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	org1 := newTestOrg("org1")
+
+	mim := &identitymanagermocks.Manager{}
+	ag.identity = mim
+
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	mim.On("VerifyAuthorEndorsed", ag.ctx, org1.DID).Return(false, fmt.Errorf("pop"))
+
+	_, _, err := ag.attemptMessageDispatch(ag.ctx, &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			SignerRef: fftypes.SignerRef{Key: "0x12345", Author: org1.DID},
+		},
+	}, nil, nil, &batchState{}, &fftypes.Pin{Signer: "0x12345"})
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+}
+
 func TestRewindOffchainBatchesNoBatches(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	defer cancel()
@@ -1948,7 +2281,7 @@ func TestResolveBlobsNoop(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	defer cancel()
 
-	resolved, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
+	resolved, _, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
 		{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{}},
 	})
 
@@ -1963,7 +2296,7 @@ func TestResolveBlobsErrorGettingHash(t *testing.T) {
 	mdi := ag.database.(*databasemocks.Plugin)
 	mdi.On("GetBlobMatchingHash", ag.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
 
-	resolved, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
+	resolved, _, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
 		{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{
 			Hash: fftypes.NewRandB32(),
 		}},
@@ -1980,7 +2313,7 @@ func TestResolveBlobsNotFoundPrivate(t *testing.T) {
 	mdi := ag.database.(*databasemocks.Plugin)
 	mdi.On("GetBlobMatchingHash", ag.ctx, mock.Anything).Return(nil, nil)
 
-	resolved, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
+	resolved, _, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
 		{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{
 			Hash: fftypes.NewRandB32(),
 		}},
@@ -1997,7 +2330,7 @@ func TestResolveBlobsFoundPrivate(t *testing.T) {
 	mdi := ag.database.(*databasemocks.Plugin)
 	mdi.On("GetBlobMatchingHash", ag.ctx, mock.Anything).Return(&fftypes.Blob{}, nil)
 
-	resolved, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
+	resolved, _, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
 		{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{
 			Hash: fftypes.NewRandB32(),
 		}},
@@ -2007,6 +2340,30 @@ func TestResolveBlobsFoundPrivate(t *testing.T) {
 	assert.True(t, resolved)
 }
 
+func TestResolveBlobsCopyQuarantined(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mdi.On("GetBlobMatchingHash", ag.ctx, mock.Anything).Return(nil, nil)
+
+	mdm := ag.data.(*datamocks.Manager)
+	mdm.On("CopyBlobPStoDX", ag.ctx, mock.Anything).Return(&fftypes.Blob{
+		Threat: "Eicar-Test-Signature",
+	}, nil)
+
+	resolved, quarantined, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
+		{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{
+			Hash:   fftypes.NewRandB32(),
+			Public: "public-ref",
+		}},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, resolved)
+	assert.Regexp(t, "FF10541.*Eicar-Test-Signature", quarantined)
+}
+
 func TestResolveBlobsCopyNotFound(t *testing.T) {
 	ag, cancel := newTestAggregator()
 	defer cancel()
@@ -2017,7 +2374,7 @@ func TestResolveBlobsCopyNotFound(t *testing.T) {
 	mdm := ag.data.(*datamocks.Manager)
 	mdm.On("CopyBlobPStoDX", ag.ctx, mock.Anything).Return(nil, nil)
 
-	resolved, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
+	resolved, _, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
 		{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{
 			Hash:   fftypes.NewRandB32(),
 			Public: "public-ref",
@@ -2038,7 +2395,7 @@ func TestResolveBlobsCopyFail(t *testing.T) {
 	mdm := ag.data.(*datamocks.Manager)
 	mdm.On("CopyBlobPStoDX", ag.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
 
-	resolved, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
+	resolved, _, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
 		{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{
 			Hash:   fftypes.NewRandB32(),
 			Public: "public-ref",
@@ -2059,7 +2416,7 @@ func TestResolveBlobsCopyOk(t *testing.T) {
 	mdm := ag.data.(*datamocks.Manager)
 	mdm.On("CopyBlobPStoDX", ag.ctx, mock.Anything).Return(&fftypes.Blob{}, nil)
 
-	resolved, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
+	resolved, _, err := ag.resolveBlobs(ag.ctx, fftypes.DataArray{
 		{ID: fftypes.NewUUID(), Blob: &fftypes.BlobRef{
 			Hash:   fftypes.NewRandB32(),
 			Public: "public-ref",
@@ -2182,3 +2539,98 @@ func TestMigrateManifestFail(t *testing.T) {
 
 	assert.Nil(t, manifest)
 }
+
+func newTestDeferredPinningPolicy(t *testing.T, ns string) *pinning.Policy {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": ns, "pinning": fftypes.JSONObject{"deferred": true}},
+	})
+	defer config.Reset()
+	pp, err := pinning.NewPolicy(context.Background())
+	assert.NoError(t, err)
+	return pp
+}
+
+func TestAttemptMessageDispatchDeferredAnchored(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	ag.pinning = newTestDeferredPinningPolicy(t, "any")
+	bs := newBatchState(ag)
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypePrivate, fftypes.NewRandB32())
+	msg1.Header.Topics = fftypes.FFStringArray{"topic1", "topic2"}
+	msg1.State = fftypes.MessageStateConfirmed
+	txID := fftypes.NewUUID()
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mim := ag.identity.(*identitymanagermocks.Manager)
+
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	mdi.On("InsertEvent", ag.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeMessageAnchored && e.Reference.Equals(msg1.Header.ID) && e.Transaction.Equals(txID)
+	})).Return(nil).Twice()
+
+	newState, dispatched, err := ag.attemptMessageDispatch(ag.ctx, msg1, fftypes.DataArray{}, txID, bs, &fftypes.Pin{Signer: "0x12345", Masked: true})
+	assert.NoError(t, err)
+	assert.True(t, dispatched)
+	assert.Equal(t, fftypes.MessageStateAnchored, newState)
+	assert.Empty(t, bs.pendingConfirms)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+}
+
+func TestAttemptMessageDispatchDeferredEventFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	ag.pinning = newTestDeferredPinningPolicy(t, "any")
+	bs := newBatchState(ag)
+	msg1, _, org1, _ := newTestManifest(fftypes.MessageTypePrivate, fftypes.NewRandB32())
+	msg1.State = fftypes.MessageStateConfirmed
+
+	mdi := ag.database.(*databasemocks.Plugin)
+	mim := ag.identity.(*identitymanagermocks.Manager)
+
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(org1, nil)
+	mdi.On("InsertEvent", ag.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, _, err := ag.attemptMessageDispatch(ag.ctx, msg1, fftypes.DataArray{}, nil, bs, &fftypes.Pin{Signer: "0x12345", Masked: true})
+	assert.NoError(t, err)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestAttemptMessageDispatchDeferredMismatch(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	ag.pinning = newTestDeferredPinningPolicy(t, "any")
+	bs := newBatchState(ag)
+	msg1, _, _, _ := newTestManifest(fftypes.MessageTypePrivate, fftypes.NewRandB32())
+	msg1.State = fftypes.MessageStateConfirmed
+
+	newState, dispatched, err := ag.attemptMessageDispatch(ag.ctx, msg1, fftypes.DataArray{}, nil, bs, &fftypes.Pin{Signer: "0x99999", Masked: true})
+	assert.NoError(t, err)
+	assert.True(t, dispatched)
+	assert.Equal(t, fftypes.MessageStateConfirmed, newState)
+
+	err = bs.RunFinalize(ag.ctx)
+	assert.NoError(t, err)
+}
+
+func TestAttemptMessageDispatchDeferredIdentityFail(t *testing.T) {
+	ag, cancel := newTestAggregator()
+	defer cancel()
+	ag.pinning = newTestDeferredPinningPolicy(t, "any")
+	bs := newBatchState(ag)
+	msg1, _, _, _ := newTestManifest(fftypes.MessageTypePrivate, fftypes.NewRandB32())
+	msg1.State = fftypes.MessageStateConfirmed
+
+	mim := ag.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", ag.ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, _, err := ag.attemptMessageDispatch(ag.ctx, msg1, fftypes.DataArray{}, nil, bs, &fftypes.Pin{Signer: "0x12345", Masked: true})
+	assert.EqualError(t, err, "pop")
+}
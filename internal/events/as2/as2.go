@@ -0,0 +1,422 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package as2
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/internal/smime"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// AS2 delivers the data of the messages matched by a subscription to a trading partner, in AS2 envelopes
+// over HTTP(S). A synchronous MDN receipt is requested for each message, and the event is only acknowledged
+// once the partner returns a "processed" disposition - so a failed or missing receipt results in redelivery.
+// Payloads are S/MIME signed if a signing certificate is configured, and encrypted for partners that have a
+// configured certificate. MDNs from those partners must be signed, and must return the MIC of the payload.
+type AS2 struct {
+	ctx          context.Context
+	capabilities *events.Capabilities
+	callbacks    events.Callbacks
+	client       *resty.Client
+	connID       string
+	id           string
+	signingCert  *x509.Certificate
+	signingKey   crypto.Signer
+	partners     map[string]*x509.Certificate
+}
+
+// entity is a MIME entity, whose headers are sent as the HTTP headers of the AS2 request
+type entity struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// bytes returns the entity with its headers, as it is signed or encrypted
+func (e *entity) bytes() []byte {
+	buf := &bytes.Buffer{}
+	keys := make([]string, 0, len(e.header))
+	for k := range e.header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(buf, "%s: %s\r\n", k, e.header.Get(k))
+	}
+	buf.WriteString("\r\n")
+	buf.Write(e.body)
+	return buf.Bytes()
+}
+
+type as2Request struct {
+	url  string
+	to   string
+	from string
+}
+
+func (a *AS2) Name() string { return "as2" }
+
+func (a *AS2) Init(ctx context.Context, prefix config.Prefix, callbacks events.Callbacks) (err error) {
+	*a = AS2{
+		ctx:          ctx,
+		capabilities: &events.Capabilities{},
+		callbacks:    callbacks,
+		client:       restclient.New(ctx, prefix),
+		connID:       fftypes.ShortID(),
+		id:           prefix.GetString(ID),
+	}
+	if a.id == "" {
+		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, prefix.Resolve(ID), "as2")
+	}
+	if certFile := prefix.GetString(SigningCertFile); certFile != "" {
+		pair, err := tls.LoadX509KeyPair(certFile, prefix.GetString(SigningKeyFile))
+		if err != nil {
+			return i18n.NewError(ctx, i18n.MsgAS2SigningInvalid, err)
+		}
+		a.signingCert, _ = x509.ParseCertificate(pair.Certificate[0])
+		a.signingKey = pair.PrivateKey.(crypto.Signer)
+	}
+	a.partners = make(map[string]*x509.Certificate)
+	for i, partner := range prefix.GetObjectArray(Partners) {
+		cert, err := loadCertificate(partner.GetString("certFile"))
+		if err != nil {
+			return i18n.NewError(ctx, i18n.MsgAS2PartnerInvalid, prefix.Resolve(Partners), i, err)
+		}
+		a.partners[partner.GetString("id")] = cert
+	}
+	// We have a single logical connection, that matches all subscriptions
+	return callbacks.RegisterConnection(a.connID, func(sr fftypes.SubscriptionRef) bool { return true })
+}
+
+func loadCertificate(certFile string) (*x509.Certificate, error) {
+	b, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM certificate in '%s'", certFile)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func (a *AS2) Capabilities() *events.Capabilities {
+	return a.capabilities
+}
+
+func (a *AS2) GetOptionsSchema(ctx context.Context) string {
+	return fmt.Sprintf(`{
+		"properties": {
+			"url": {
+				"type": "string",
+				"description": "%s"
+			},
+			"as2to": {
+				"type": "string",
+				"description": "%s"
+			},
+			"as2from": {
+				"type": "string",
+				"description": "%s"
+			}
+		}
+	}`,
+		i18n.Expand(ctx, i18n.MsgAS2OptURL),
+		i18n.Expand(ctx, i18n.MsgAS2OptTo),
+		i18n.Expand(ctx, i18n.MsgAS2OptFrom),
+	)
+}
+
+func (a *AS2) buildRequest(options fftypes.JSONObject) (*as2Request, error) {
+	req := &as2Request{
+		url:  options.GetString("url"),
+		to:   options.GetString("as2to"),
+		from: options.GetString("as2from"),
+	}
+	if u, err := url.Parse(req.url); err != nil || u.Host == "" {
+		return nil, i18n.NewError(a.ctx, i18n.MsgAS2URLInvalid, req.url)
+	}
+	if req.to == "" {
+		return nil, i18n.NewError(a.ctx, i18n.MsgAS2ToEmpty)
+	}
+	if req.from == "" {
+		req.from = a.id
+	}
+	return req, nil
+}
+
+func (a *AS2) ValidateOptions(options *fftypes.SubscriptionOptions) error {
+	if options.WithData == nil {
+		defaultTrue := true
+		options.WithData = &defaultTrue
+	}
+	_, err := a.buildRequest(options.TransportOptions())
+	return err
+}
+
+// payload returns the body to send for the first data item of the message. A JSON string value is
+// sent as-is, so EDI documents stored as strings are delivered in their native format.
+func payload(d *fftypes.Data) (body []byte, contentType string) {
+	body = d.Value.Bytes()
+	var s string
+	if err := json.Unmarshal(body, &s); err == nil {
+		body = []byte(s)
+	}
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("ISA")):
+		return body, "application/edi-x12"
+	case bytes.HasPrefix(trimmed, []byte("UNA")), bytes.HasPrefix(trimmed, []byte("UNB")):
+		return body, "application/edifact"
+	default:
+		return body, "application/json"
+	}
+}
+
+// findDisposition walks an MDN, to find the fields of the disposition notification
+func findDisposition(contentType string, body io.Reader) (textproto.MIMEHeader, bool) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	switch {
+	case mediaType == "message/disposition-notification":
+		fields, err := textproto.NewReader(bufio.NewReader(body)).ReadMIMEHeader()
+		if err != nil && err != io.EOF {
+			return nil, false
+		}
+		return fields, fields.Get("Disposition") != ""
+	case strings.HasPrefix(mediaType, "multipart/"):
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err != nil {
+				return nil, false
+			}
+			if fields, ok := findDisposition(part.Header.Get("Content-Type"), part); ok {
+				return fields, true
+			}
+		}
+	default:
+		return nil, false
+	}
+}
+
+// processed checks a disposition such as "automatic-action/MDN-sent-automatically; processed" reports
+// successful processing, without any error or warning modifiers
+func processed(disposition string) bool {
+	parts := strings.SplitN(disposition, ";", 2)
+	return len(parts) == 2 && strings.TrimSpace(parts[1]) == "processed"
+}
+
+// sign wraps the entity in a multipart/signed entity, and returns it with the MIC the partner must return in the MDN
+func (a *AS2) sign(content *entity) (*entity, string, error) {
+	signed := content.bytes()
+	signature, err := smime.Sign(a.ctx, signed, a.signingCert, a.signingKey)
+	if err != nil {
+		return nil, "", err
+	}
+	digest := crypto.SHA256.New()
+	digest.Write(signed)
+	mic := base64.StdEncoding.EncodeToString(digest.Sum(nil)) + ", " + smime.MICAlgorithms[crypto.SHA256]
+
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	boundary := "----=_" + hex.EncodeToString(random)
+	encoded := base64.StdEncoding.EncodeToString(signature)
+	body := &bytes.Buffer{}
+	fmt.Fprintf(body, "--%s\r\n", boundary)
+	body.Write(signed)
+	fmt.Fprintf(body, "\r\n--%s\r\n", boundary)
+	body.WriteString("Content-Type: application/pkcs7-signature; name=smime.p7s\r\n")
+	body.WriteString("Content-Transfer-Encoding: base64\r\n")
+	body.WriteString("Content-Disposition: attachment; filename=smime.p7s\r\n\r\n")
+	for len(encoded) > 76 {
+		body.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	body.WriteString(encoded + "\r\n")
+	fmt.Fprintf(body, "--%s--\r\n", boundary)
+	return &entity{
+		header: textproto.MIMEHeader{
+			"Content-Type": {fmt.Sprintf(`multipart/signed; protocol="application/pkcs7-signature"; micalg=%s; boundary="%s"`, smime.MICAlgorithms[crypto.SHA256], boundary)},
+		},
+		body: body.Bytes(),
+	}, mic, nil
+}
+
+// encrypt wraps the entity in an application/pkcs7-mime enveloped-data entity for the partner
+func (a *AS2) encrypt(content *entity, partnerCert *x509.Certificate) (*entity, error) {
+	enveloped, err := smime.Encrypt(a.ctx, content.bytes(), partnerCert)
+	if err != nil {
+		return nil, err
+	}
+	return &entity{
+		header: textproto.MIMEHeader{
+			"Content-Type":              {`application/pkcs7-mime; smime-type=enveloped-data; name=smime.p7m`},
+			"Content-Transfer-Encoding": {"binary"},
+		},
+		body: enveloped,
+	}, nil
+}
+
+// readMDN returns the fields of the disposition notification in the MDN. When the partner has a certificate
+// the MDN must be signed, and the signature is verified before the fields are read.
+func (a *AS2) readMDN(req *as2Request, res *resty.Response, partnerCert *x509.Certificate) (textproto.MIMEHeader, error) {
+	contentType := res.Header().Get("Content-Type")
+	var body io.Reader = bytes.NewReader(res.Body())
+	if partnerCert != nil {
+		mediaType, params, _ := mime.ParseMediaType(contentType)
+		if mediaType != "multipart/signed" {
+			return nil, i18n.NewError(a.ctx, i18n.MsgAS2MDNUnsigned, req.to)
+		}
+		content, signature, err := smime.ParseSigned(a.ctx, res.Body(), params["boundary"])
+		if err != nil {
+			return nil, err
+		}
+		roots := x509.NewCertPool()
+		roots.AddCert(partnerCert)
+		if _, _, err := smime.Verify(a.ctx, signature, content, x509.VerifyOptions{
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err != nil {
+			return nil, err
+		}
+		r := textproto.NewReader(bufio.NewReader(bytes.NewReader(content)))
+		h, err := r.ReadMIMEHeader()
+		if err != nil {
+			return nil, i18n.WrapError(a.ctx, err, i18n.MsgAS2MDNMissing, res.StatusCode())
+		}
+		contentType, body = h.Get("Content-Type"), r.R
+	}
+	fields, ok := findDisposition(contentType, body)
+	if !res.IsSuccess() || !ok {
+		return nil, i18n.NewError(a.ctx, i18n.MsgAS2MDNMissing, res.StatusCode())
+	}
+	return fields, nil
+}
+
+// micMatches compares a Received-Content-MIC field, such as "base64digest, sha-256", to the expected MIC
+func micMatches(received, expected string) bool {
+	receivedParts := strings.SplitN(received, ",", 2)
+	expectedParts := strings.SplitN(expected, ",", 2)
+	return len(receivedParts) == 2 &&
+		strings.TrimSpace(receivedParts[0]) == expectedParts[0] &&
+		strings.EqualFold(strings.TrimSpace(receivedParts[1]), strings.TrimSpace(expectedParts[1]))
+}
+
+func (a *AS2) send(req *as2Request, event *fftypes.EventDelivery, d *fftypes.Data) (err error) {
+	body, contentType := payload(d)
+	e := &entity{
+		header: textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"binary"},
+		},
+		body: body,
+	}
+	var mic string
+	if a.signingCert != nil {
+		if e, mic, err = a.sign(e); err != nil {
+			return err
+		}
+	}
+	partnerCert := a.partners[req.to]
+	if partnerCert != nil {
+		if e, err = a.encrypt(e, partnerCert); err != nil {
+			return err
+		}
+	}
+
+	r := a.client.R().
+		SetContext(a.ctx).
+		SetHeader("AS2-Version", "1.2").
+		SetHeader("AS2-From", req.from).
+		SetHeader("AS2-To", req.to).
+		SetHeader("Message-ID", fmt.Sprintf("<%s.%s@%s>", event.Message.Header.ID, event.ID, req.from)).
+		SetHeader("Subject", fmt.Sprintf("FireFly message %s", event.Message.Header.ID)).
+		SetHeader("Disposition-Notification-To", req.from)
+	if partnerCert != nil {
+		r.SetHeader("Disposition-Notification-Options", "signed-receipt-protocol=required, pkcs7-signature; signed-receipt-micalg=required, sha-256")
+	}
+	for k := range e.header {
+		r.SetHeader(k, e.header.Get(k))
+	}
+	res, err := r.SetBody(e.body).Post(req.url)
+	if err != nil {
+		return err
+	}
+	fields, err := a.readMDN(req, res, partnerCert)
+	if err != nil {
+		return err
+	}
+	if disposition := fields.Get("Disposition"); !processed(disposition) {
+		return i18n.NewError(a.ctx, i18n.MsgAS2MDNFailed, disposition)
+	}
+	if mic != "" && partnerCert != nil && !micMatches(fields.Get("Received-Content-MIC"), mic) {
+		// The signed MDN is only proof of receipt, if it is for the content that was signed
+		return i18n.NewError(a.ctx, i18n.MsgAS2MDNMICMismatch, fields.Get("Received-Content-MIC"), mic)
+	}
+	return nil
+}
+
+func (a *AS2) DeliveryRequest(connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
+	var sendErr error
+	req, err := a.buildRequest(sub.Options.TransportOptions())
+	switch {
+	case err != nil:
+		// The event cannot ever be sent with these options, so we skip it rather than block the subscription
+		log.L(a.ctx).Errorf("Skipping AS2 delivery for event '%s': %s", event.ID, err)
+	case event.Message == nil || len(data) == 0 || data[0].Value == nil:
+		log.L(a.ctx).Debugf("Skipping AS2 delivery for event '%s': no message data", event.ID)
+	default:
+		if sendErr = a.send(req, event, data[0]); sendErr != nil {
+			// Rejecting the event means it is redelivered, until the partner confirms receipt with an MDN
+			log.L(a.ctx).Errorf("Failed AS2 delivery for event '%s': %s", event.ID, sendErr)
+		}
+	}
+	a.callbacks.DeliveryResponse(connID, &fftypes.EventDeliveryResponse{
+		ID:           event.ID,
+		Rejected:     sendErr != nil,
+		Subscription: event.Subscription,
+	})
+	return nil
+}
@@ -0,0 +1,590 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package as2
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/smime"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mozilla.org/pkcs7"
+)
+
+var utConfPrefix = config.NewPluginConfig("ut.as2")
+
+func newTestAS2(t *testing.T) (*AS2, *eventsmocks.Callbacks) {
+	return newTestAS2WithConfig(t, func() {})
+}
+
+func newTestAS2WithConfig(t *testing.T, setup func()) (*AS2, *eventsmocks.Callbacks) {
+	config.Reset()
+	a := &AS2{}
+	a.InitPrefix(utConfPrefix)
+	utConfPrefix.Set(ID, "FIREFLY")
+	setup()
+
+	cbs := &eventsmocks.Callbacks{}
+	rc := cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	rc.RunFn = func(a mock.Arguments) {
+		assert.Equal(t, true, a[1].(events.SubscriptionMatcher)(fftypes.SubscriptionRef{}))
+	}
+	err := a.Init(context.Background(), utConfPrefix, cbs)
+	assert.NoError(t, err)
+	assert.Equal(t, "as2", a.Name())
+	assert.NotNil(t, a.Capabilities())
+	assert.NotNil(t, a.GetOptionsSchema(a.ctx))
+	return a, cbs
+}
+
+func newTestSubscription(options fftypes.JSONObject) *fftypes.Subscription {
+	sub := &fftypes.Subscription{}
+	for k, v := range options {
+		sub.Options.TransportOptions()[k] = v
+	}
+	return sub
+}
+
+func newTestEvent() *fftypes.EventDelivery {
+	return &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{ID: fftypes.NewUUID()},
+			Message: &fftypes.Message{
+				Header: fftypes.MessageHeader{ID: fftypes.NewUUID()},
+			},
+		},
+	}
+}
+
+func mdnServer(t *testing.T, status int, contentType, body string, check func(r *http.Request)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			check(r)
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+const mdnReport = "--b1\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"The message was received\r\n" +
+	"--b1\r\n" +
+	"Content-Type: message/disposition-notification\r\n" +
+	"\r\n" +
+	"Reporting-UA: partner\r\n" +
+	"Final-Recipient: rfc822; PARTNER\r\n" +
+	"Disposition: automatic-action/MDN-sent-automatically; %s\r\n" +
+	"\r\n" +
+	"--b1--\r\n"
+
+func TestInitMissingID(t *testing.T) {
+	config.Reset()
+	a := &AS2{}
+	a.InitPrefix(utConfPrefix)
+	err := a.Init(context.Background(), utConfPrefix, &eventsmocks.Callbacks{})
+	assert.Regexp(t, "FF10138.*id", err)
+}
+
+func TestValidateOptions(t *testing.T) {
+	a, _ := newTestAS2(t)
+
+	opts := &fftypes.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "https://partner.example.com/as2"
+	opts.TransportOptions()["as2to"] = "PARTNER"
+	err := a.ValidateOptions(opts)
+	assert.NoError(t, err)
+	assert.True(t, *opts.WithData)
+
+	opts = &fftypes.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "not a url"
+	err = a.ValidateOptions(opts)
+	assert.Regexp(t, "FF10478", err)
+
+	opts = &fftypes.SubscriptionOptions{}
+	opts.TransportOptions()["url"] = "https://partner.example.com/as2"
+	err = a.ValidateOptions(opts)
+	assert.Regexp(t, "FF10479", err)
+}
+
+func TestDeliveryRequestEDIProcessed(t *testing.T) {
+	a, cbs := newTestAS2(t)
+
+	event := newTestEvent()
+	svr := mdnServer(t, 200, `multipart/signed; protocol="application/pkcs7-signature"; boundary="outer"`,
+		"--outer\r\nContent-Type: multipart/report; report-type=disposition-notification; boundary=\"b1\"\r\n\r\n"+
+			fmt.Sprintf(mdnReport, "processed")+
+			"\r\n--outer\r\nContent-Type: application/pkcs7-signature\r\n\r\nsignature\r\n--outer--\r\n",
+		func(r *http.Request) {
+			assert.Equal(t, "1.2", r.Header.Get("AS2-Version"))
+			assert.Equal(t, "OTHER", r.Header.Get("AS2-From"))
+			assert.Equal(t, "PARTNER", r.Header.Get("AS2-To"))
+			assert.Equal(t, "OTHER", r.Header.Get("Disposition-Notification-To"))
+			assert.Equal(t, fmt.Sprintf("<%s.%s@OTHER>", event.Message.Header.ID, event.ID), r.Header.Get("Message-ID"))
+			assert.Equal(t, "application/edi-x12", r.Header.Get("Content-Type"))
+			b, _ := ioutil.ReadAll(r.Body)
+			assert.Equal(t, "ISA*00*~", string(b))
+		})
+	defer svr.Close()
+
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(r *fftypes.EventDeliveryResponse) bool {
+		return r.ID.Equals(event.ID) && !r.Rejected
+	})).Return()
+
+	err := a.DeliveryRequest("conn1", newTestSubscription(fftypes.JSONObject{
+		"url":     svr.URL,
+		"as2to":   "PARTNER",
+		"as2from": "OTHER",
+	}), event, fftypes.DataArray{{Value: fftypes.JSONAnyPtr(`"ISA*00*~"`)}})
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDeliveryRequestProcessedWithError(t *testing.T) {
+	a, cbs := newTestAS2(t)
+
+	svr := mdnServer(t, 200, `multipart/report; report-type=disposition-notification; boundary="b1"`,
+		fmt.Sprintf(mdnReport, "processed/error: authentication-failed"), func(r *http.Request) {
+			assert.Equal(t, "FIREFLY", r.Header.Get("AS2-From"))
+			assert.Equal(t, "application/edifact", r.Header.Get("Content-Type"))
+		})
+	defer svr.Close()
+
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(r *fftypes.EventDeliveryResponse) bool {
+		return r.Rejected
+	})).Return()
+
+	err := a.DeliveryRequest("conn1", newTestSubscription(fftypes.JSONObject{
+		"url":   svr.URL,
+		"as2to": "PARTNER",
+	}), newTestEvent(), fftypes.DataArray{{Value: fftypes.JSONAnyPtr(`"UNB+UNOA:1'"`)}})
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDeliveryRequestMDNMissing(t *testing.T) {
+	a, cbs := newTestAS2(t)
+
+	svr := mdnServer(t, 500, "text/plain", "pop", func(r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	})
+	defer svr.Close()
+
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(r *fftypes.EventDeliveryResponse) bool {
+		return r.Rejected
+	})).Return()
+
+	err := a.DeliveryRequest("conn1", newTestSubscription(fftypes.JSONObject{
+		"url":   svr.URL,
+		"as2to": "PARTNER",
+	}), newTestEvent(), fftypes.DataArray{{Value: fftypes.JSONAnyPtr(`{"order":1}`)}})
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDeliveryRequestBadMDN(t *testing.T) {
+	a, cbs := newTestAS2(t)
+
+	svr := mdnServer(t, 200, `multipart/report; boundary="b1"`, "--b1\r\nContent-Type: message/disposition-notification\r\n\r\nbad header\r\n\r\n--b1--\r\n", nil)
+	defer svr.Close()
+
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(r *fftypes.EventDeliveryResponse) bool {
+		return r.Rejected
+	})).Return()
+
+	err := a.DeliveryRequest("conn1", newTestSubscription(fftypes.JSONObject{
+		"url":   svr.URL,
+		"as2to": "PARTNER",
+	}), newTestEvent(), fftypes.DataArray{{Value: fftypes.JSONAnyPtr(`{}`)}})
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDeliveryRequestSendFail(t *testing.T) {
+	a, cbs := newTestAS2(t)
+
+	svr := mdnServer(t, 200, "text/plain", "", nil)
+	svr.Close()
+
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(r *fftypes.EventDeliveryResponse) bool {
+		return r.Rejected
+	})).Return()
+
+	err := a.DeliveryRequest("conn1", newTestSubscription(fftypes.JSONObject{
+		"url":   svr.URL,
+		"as2to": "PARTNER",
+	}), newTestEvent(), fftypes.DataArray{{Value: fftypes.JSONAnyPtr(`{}`)}})
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDeliveryRequestSkipped(t *testing.T) {
+	a, cbs := newTestAS2(t)
+
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(r *fftypes.EventDeliveryResponse) bool {
+		return !r.Rejected
+	})).Return()
+
+	err := a.DeliveryRequest("conn1", newTestSubscription(fftypes.JSONObject{}), newTestEvent(), nil)
+	assert.NoError(t, err)
+	err = a.DeliveryRequest("conn1", newTestSubscription(fftypes.JSONObject{
+		"url":   "https://partner.example.com/as2",
+		"as2to": "PARTNER",
+	}), newTestEvent(), nil)
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestFindDispositionBadContentType(t *testing.T) {
+	_, ok := findDisposition("bad;;", nil)
+	assert.False(t, ok)
+}
+
+// testCredentials is a self-signed certificate and its key, written as PEM files
+type testCredentials struct {
+	cert     *x509.Certificate
+	key      crypto.Signer
+	certFile string
+	keyFile  string
+}
+
+func newTestCredentials(t *testing.T, key crypto.Signer) *testCredentials {
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: fmt.Sprintf("test %s", serial)},
+		NotBefore:    time.Now().Add(-1 * time.Hour),
+		NotAfter:     time.Now().Add(1 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		SubjectKeyId: serial.Bytes(),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	assert.NoError(t, err)
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+	dir := t.TempDir()
+	creds := &testCredentials{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, "cert.pem"),
+		keyFile:  filepath.Join(dir, "key.pem"),
+	}
+	ioutil.WriteFile(creds.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(creds.keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600)
+	return creds
+}
+
+func newTestRSACredentials(t *testing.T) *testCredentials {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	return newTestCredentials(t, key)
+}
+
+// decrypt reverses the enveloped-data encryption of an AS2 payload, as the partner would
+func decrypt(t *testing.T, enveloped []byte, creds *testCredentials) []byte {
+	p7, err := pkcs7.Parse(enveloped)
+	assert.NoError(t, err)
+	content, err := p7.Decrypt(creds.cert, creds.key)
+	assert.NoError(t, err)
+	return content
+}
+
+// signedMDN returns a multipart/signed MDN, reporting the disposition and MIC
+func signedMDN(t *testing.T, creds *testCredentials, disposition, mic string) (string, string) {
+	content := "Content-Type: multipart/report; report-type=disposition-notification; boundary=\"b1\"\r\n\r\n" +
+		strings.Replace(fmt.Sprintf(mdnReport, disposition), "Disposition:", "Received-Content-MIC: "+mic+"\r\nDisposition:", 1)
+	signature, err := smime.Sign(context.Background(), []byte(content), creds.cert, creds.key)
+	assert.NoError(t, err)
+	return `multipart/signed; protocol="application/pkcs7-signature"; micalg=sha-256; boundary="outer"`,
+		"--outer\r\n" + content + "\r\n--outer\r\n" +
+			"Content-Type: application/pkcs7-signature\r\nContent-Transfer-Encoding: base64\r\n\r\n" +
+			base64.StdEncoding.EncodeToString(signature) + "\r\n--outer--\r\n"
+}
+
+// partnerServer is an AS2 partner, that decrypts and verifies each payload and returns a signed MDN
+func partnerServer(t *testing.T, partner, signer *testCredentials, mdn func(content []byte, mic string) (string, string)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/pkcs7-mime; smime-type=enveloped-data; name=smime.p7m", r.Header.Get("Content-Type"))
+		assert.Contains(t, r.Header.Get("Disposition-Notification-Options"), "signed-receipt-micalg=required, sha-256")
+		b, _ := ioutil.ReadAll(r.Body)
+		decrypted := decrypt(t, b, partner)
+		headerEnd := bytes.Index(decrypted, []byte("\r\n\r\n"))
+		_, params, err := mime.ParseMediaType(strings.TrimPrefix(string(decrypted[:headerEnd]), "Content-Type: "))
+		assert.NoError(t, err)
+		content, signature, err := smime.ParseSigned(context.Background(), decrypted[headerEnd+4:], params["boundary"])
+		assert.NoError(t, err)
+		roots := x509.NewCertPool()
+		roots.AddCert(signer.cert)
+		_, _, err = smime.Verify(context.Background(), signature, content, x509.VerifyOptions{
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		assert.NoError(t, err)
+		digest := sha256.Sum256(content)
+		contentType, body := mdn(content, base64.StdEncoding.EncodeToString(digest[:])+", sha-256")
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(200)
+		w.Write([]byte(body))
+	}))
+}
+
+func newTestSecureAS2(t *testing.T, signer, partner *testCredentials) (*AS2, *eventsmocks.Callbacks) {
+	return newTestAS2WithConfig(t, func() {
+		utConfPrefix.Set(SigningCertFile, signer.certFile)
+		utConfPrefix.Set(SigningKeyFile, signer.keyFile)
+		utConfPrefix.Set(Partners, fftypes.JSONObjectArray{
+			{"id": "PARTNER", "certFile": partner.certFile},
+		})
+	})
+}
+
+func TestInitSigningInvalid(t *testing.T) {
+	config.Reset()
+	a := &AS2{}
+	a.InitPrefix(utConfPrefix)
+	utConfPrefix.Set(ID, "FIREFLY")
+	utConfPrefix.Set(SigningCertFile, "/missing/cert.pem")
+	err := a.Init(context.Background(), utConfPrefix, &eventsmocks.Callbacks{})
+	assert.Regexp(t, "FF10494", err)
+}
+
+func TestInitPartnerInvalid(t *testing.T) {
+	notPEM := filepath.Join(t.TempDir(), "cert.pem")
+	ioutil.WriteFile(notPEM, []byte("not a certificate"), 0600)
+	for _, certFile := range []string{"/missing/cert.pem", notPEM} {
+		config.Reset()
+		a := &AS2{}
+		a.InitPrefix(utConfPrefix)
+		utConfPrefix.Set(ID, "FIREFLY")
+		utConfPrefix.Set(Partners, fftypes.JSONObjectArray{{"id": "PARTNER", "certFile": certFile}})
+		err := a.Init(context.Background(), utConfPrefix, &eventsmocks.Callbacks{})
+		assert.Regexp(t, "FF10495.*partners\\[0\\]", err)
+	}
+}
+
+func testSecureDelivery(t *testing.T, a *AS2, cbs *eventsmocks.Callbacks, url string, rejected bool) {
+	event := newTestEvent()
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(r *fftypes.EventDeliveryResponse) bool {
+		return r.ID.Equals(event.ID) && r.Rejected == rejected
+	})).Return()
+
+	err := a.DeliveryRequest("conn1", newTestSubscription(fftypes.JSONObject{
+		"url":   url,
+		"as2to": "PARTNER",
+	}), event, fftypes.DataArray{{Value: fftypes.JSONAnyPtr(`"ISA*00*~"`)}})
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDeliveryRequestSignedEncrypted(t *testing.T) {
+	signer, partner := newTestRSACredentials(t), newTestRSACredentials(t)
+	a, cbs := newTestSecureAS2(t, signer, partner)
+
+	svr := partnerServer(t, partner, signer, func(content []byte, mic string) (string, string) {
+		assert.Equal(t, "Content-Transfer-Encoding: binary\r\nContent-Type: application/edi-x12\r\n\r\nISA*00*~", string(content))
+		return signedMDN(t, partner, "processed", strings.Replace(mic, ", sha-256", " , SHA-256", 1))
+	})
+	defer svr.Close()
+
+	testSecureDelivery(t, a, cbs, svr.URL, false)
+}
+
+func TestDeliveryRequestSignedECDSA(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	signer, partner := newTestCredentials(t, key), newTestRSACredentials(t)
+	a, cbs := newTestSecureAS2(t, signer, partner)
+
+	svr := partnerServer(t, partner, signer, func(content []byte, mic string) (string, string) {
+		return signedMDN(t, partner, "processed", mic)
+	})
+	defer svr.Close()
+
+	testSecureDelivery(t, a, cbs, svr.URL, false)
+}
+
+func TestDeliveryRequestMICMismatch(t *testing.T) {
+	signer, partner := newTestRSACredentials(t), newTestRSACredentials(t)
+	a, cbs := newTestSecureAS2(t, signer, partner)
+
+	svr := partnerServer(t, partner, signer, func(content []byte, mic string) (string, string) {
+		return signedMDN(t, partner, "processed", "bad, sha-256")
+	})
+	defer svr.Close()
+
+	testSecureDelivery(t, a, cbs, svr.URL, true)
+}
+
+func TestDeliveryRequestMDNFailedSigned(t *testing.T) {
+	signer, partner := newTestRSACredentials(t), newTestRSACredentials(t)
+	a, cbs := newTestSecureAS2(t, signer, partner)
+
+	svr := partnerServer(t, partner, signer, func(content []byte, mic string) (string, string) {
+		return signedMDN(t, partner, "processed/error: decryption-failed", mic)
+	})
+	defer svr.Close()
+
+	testSecureDelivery(t, a, cbs, svr.URL, true)
+}
+
+func TestDeliveryRequestMDNUnsigned(t *testing.T) {
+	signer, partner := newTestRSACredentials(t), newTestRSACredentials(t)
+	a, cbs := newTestSecureAS2(t, signer, partner)
+
+	svr := partnerServer(t, partner, signer, func(content []byte, mic string) (string, string) {
+		return `multipart/report; report-type=disposition-notification; boundary="b1"`, fmt.Sprintf(mdnReport, "processed")
+	})
+	defer svr.Close()
+
+	testSecureDelivery(t, a, cbs, svr.URL, true)
+}
+
+func TestDeliveryRequestMDNWrongSigner(t *testing.T) {
+	signer, partner := newTestRSACredentials(t), newTestRSACredentials(t)
+	a, cbs := newTestSecureAS2(t, signer, partner)
+
+	svr := partnerServer(t, partner, signer, func(content []byte, mic string) (string, string) {
+		return signedMDN(t, signer, "processed", mic)
+	})
+	defer svr.Close()
+
+	testSecureDelivery(t, a, cbs, svr.URL, true)
+}
+
+func TestDeliveryRequestMDNBadSignedContent(t *testing.T) {
+	signer, partner := newTestRSACredentials(t), newTestRSACredentials(t)
+	a, cbs := newTestSecureAS2(t, signer, partner)
+
+	for _, mdn := range [][2]string{
+		{`multipart/signed; boundary="outer"`, "not multipart"},
+		{`multipart/signed; boundary="outer"`, "--outer\r\nContent-Type: text/plain\r\n\r\ncontent\r\n--outer\r\nContent-Type: application/pkcs7-signature\r\nContent-Transfer-Encoding: base64\r\n\r\nbm90IGEgc2lnbmF0dXJl\r\n--outer--\r\n"},
+	} {
+		svr := partnerServer(t, partner, signer, func(content []byte, mic string) (string, string) {
+			return mdn[0], mdn[1]
+		})
+		testSecureDelivery(t, a, cbs, svr.URL, true)
+		svr.Close()
+	}
+}
+
+func TestDeliveryRequestMDNBadSignedHeaders(t *testing.T) {
+	signer, partner := newTestRSACredentials(t), newTestRSACredentials(t)
+	a, cbs := newTestSecureAS2(t, signer, partner)
+
+	svr := partnerServer(t, partner, signer, func(content []byte, mic string) (string, string) {
+		signature, err := smime.Sign(context.Background(), []byte("bad header\r\n\r\n"), partner.cert, partner.key)
+		assert.NoError(t, err)
+		return `multipart/signed; boundary="outer"`,
+			"--outer\r\nbad header\r\n\r\n\r\n--outer\r\nContent-Type: application/pkcs7-signature\r\nContent-Transfer-Encoding: base64\r\n\r\n" +
+				base64.StdEncoding.EncodeToString(signature) + "\r\n--outer--\r\n"
+	})
+	defer svr.Close()
+
+	testSecureDelivery(t, a, cbs, svr.URL, true)
+}
+
+func TestDeliveryRequestSignedOnly(t *testing.T) {
+	signer := newTestRSACredentials(t)
+	a, cbs := newTestAS2WithConfig(t, func() {
+		utConfPrefix.Set(SigningCertFile, signer.certFile)
+		utConfPrefix.Set(SigningKeyFile, signer.keyFile)
+	})
+
+	svr := mdnServer(t, 200, `multipart/report; report-type=disposition-notification; boundary="b1"`,
+		fmt.Sprintf(mdnReport, "processed"), func(r *http.Request) {
+			mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			assert.NoError(t, err)
+			assert.Equal(t, "multipart/signed", mediaType)
+			assert.Equal(t, "sha-256", params["micalg"])
+			assert.Empty(t, r.Header.Get("Disposition-Notification-Options"))
+			b, _ := ioutil.ReadAll(r.Body)
+			content, signature, err := smime.ParseSigned(context.Background(), b, params["boundary"])
+			assert.NoError(t, err)
+			roots := x509.NewCertPool()
+			roots.AddCert(signer.cert)
+			_, _, err = smime.Verify(context.Background(), signature, content, x509.VerifyOptions{
+				Roots:     roots,
+				KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+			})
+			assert.NoError(t, err)
+		})
+	defer svr.Close()
+
+	testSecureDelivery(t, a, cbs, svr.URL, false)
+}
+
+func TestDeliveryRequestSignFail(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	signer := newTestCredentials(t, key)
+	a, cbs := newTestAS2WithConfig(t, func() {
+		utConfPrefix.Set(SigningCertFile, signer.certFile)
+		utConfPrefix.Set(SigningKeyFile, signer.keyFile)
+	})
+
+	testSecureDelivery(t, a, cbs, "http://localhost:0", true)
+}
+
+func TestDeliveryRequestEncryptFail(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	partner := newTestCredentials(t, key)
+	a, cbs := newTestAS2WithConfig(t, func() {
+		utConfPrefix.Set(Partners, fftypes.JSONObjectArray{{"id": "PARTNER", "certFile": partner.certFile}})
+	})
+
+	testSecureDelivery(t, a, cbs, "http://localhost:0", true)
+}
+
+func TestMICMatches(t *testing.T) {
+	assert.True(t, micMatches("abc= , SHA-256", "abc=, sha-256"))
+	assert.False(t, micMatches("abc=", "abc=, sha-256"))
+	assert.False(t, micMatches("abd=, sha-256", "abc=, sha-256"))
+	assert.False(t, micMatches("abc=, sha-1", "abc=, sha-256"))
+}
@@ -0,0 +1,43 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package as2
+
+import (
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+)
+
+const (
+	// ID is the AS2 identifier of this node, sent in the AS2-From header
+	ID = "id"
+	// SigningCertFile is a PEM certificate for this node's AS2 identity. When set, payloads are signed.
+	SigningCertFile = "signing.certFile"
+	// SigningKeyFile is the PEM private key for the signing certificate
+	SigningKeyFile = "signing.keyFile"
+	// Partners is a list of trading partners, each with the "id" of the partner and the "certFile" of its PEM
+	// certificate. Payloads sent to these partners are encrypted for the certificate, and only MDNs signed
+	// with it are accepted.
+	Partners = "partners"
+)
+
+func (a *AS2) InitPrefix(prefix config.Prefix) {
+	restclient.InitPrefix(prefix)
+	prefix.AddKnownKey(ID)
+	prefix.AddKnownKey(SigningCertFile)
+	prefix.AddKnownKey(SigningKeyFile)
+	prefix.AddKnownKey(Partners)
+}
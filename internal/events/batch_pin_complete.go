@@ -48,6 +48,9 @@ func (em *eventManager) BatchPinComplete(bi blockchain.Plugin, batchPin *blockch
 	}()
 	log.L(em.ctx).Tracef("BatchPinComplete batch=%s info: %+v", batchPin.BatchID, batchPin.Event.Info)
 
+	if isPinRollup(batchPin) {
+		return em.handlePinRollupComplete(bi, batchPin, signingKey)
+	}
 	if batchPin.BatchPayloadRef != "" {
 		return em.handleBroadcastPinComplete(batchPin, signingKey)
 	}
@@ -92,6 +95,10 @@ func (em *eventManager) persistContexts(ctx context.Context, batchPin *blockchai
 	// First attempt a single batch insert
 	err := em.database.InsertPins(ctx, pins)
 	if err == nil {
+		if em.lifecycleEvents {
+			event := fftypes.NewEvent(fftypes.EventTypeBatchConfirmed, batchPin.Namespace, batchPin.BatchID, batchPin.TransactionID, "")
+			return em.database.InsertEvent(ctx, event)
+		}
 		return nil
 	}
 	log.L(ctx).Warnf("Batch insert of pins failed - assuming replay and performing upserts: %s", err)
@@ -102,7 +109,9 @@ func (em *eventManager) persistContexts(ctx context.Context, batchPin *blockchai
 			return err
 		}
 	}
-	return nil
+
+	// A replayed pin might be the re-delivery of a pin from the canonical chain, after a reorg
+	return em.reconfirmReorgedMessages(ctx, batchPin)
 }
 
 func (em *eventManager) handleBroadcastPinComplete(batchPin *blockchain.BatchPin, signingKey *fftypes.VerifierRef) error {
@@ -176,6 +176,7 @@ func TestBatchPinCompleteOkPrivate(t *testing.T) {
 	mdi.On("RunAsGroup", mock.Anything, mock.Anything).Return(nil)
 	mdi.On("InsertPins", mock.Anything, mock.Anything).Return(fmt.Errorf("These pins have been seen before")) // simulate replay fallback
 	mdi.On("UpsertPin", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
 	mbi := &blockchainmocks.Plugin{}
 
 	err := em.BatchPinComplete(mbi, batchPin, &fftypes.VerifierRef{
@@ -593,6 +594,35 @@ func TestPersistBatchMessageOK(t *testing.T) {
 	assert.True(t, valid)
 }
 
+func TestPersistContextsLifecycleEvent(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	em.lifecycleEvents = true
+
+	batchPin := &blockchain.BatchPin{
+		Namespace:     "ns1",
+		TransactionID: fftypes.NewUUID(),
+		BatchID:       fftypes.NewUUID(),
+		Contexts: []*fftypes.Bytes32{
+			fftypes.NewRandB32(),
+		},
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("InsertPins", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeBatchConfirmed && e.Namespace == "ns1" &&
+			e.Reference.Equals(batchPin.BatchID) && e.Transaction.Equals(batchPin.TransactionID)
+	})).Return(fmt.Errorf("pop"))
+
+	err := em.persistContexts(em.ctx, batchPin, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeEthAddress,
+		Value: "0x12345",
+	}, false)
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
 func TestPersistContextsFail(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -113,6 +113,20 @@ func (em *eventManager) BlockchainEvent(event *blockchain.EventWithSubscription)
 				return nil // no retry
 			}
 
+			if event.ProtocolID != "" {
+				// Protocol IDs sort in the order events occurred on the chain, so only process events
+				// beyond the checkpoint. This makes redelivery by the connector after a restart or a
+				// rewind idempotent, up to the point the listener was rewound to.
+				advanced, err := em.database.UpdateContractListenerCheckpoint(ctx, sub.ID, event.ProtocolID, true)
+				if err != nil {
+					return err
+				}
+				if !advanced {
+					log.L(ctx).Infof("Skipping event %s for listener %s, which is not beyond the checkpoint", event.ProtocolID, sub.ID)
+					return nil
+				}
+			}
+
 			chainEvent := buildBlockchainEvent(sub.Namespace, sub.ID, &event.Event, nil)
 			if err := em.persistBlockchainEvent(ctx, chainEvent); err != nil {
 				return err
@@ -35,6 +35,7 @@ func TestContractEventWithRetries(t *testing.T) {
 		Subscription: "sb-1",
 		Event: blockchain.Event{
 			BlockchainTXID: "0xabcd1234",
+			ProtocolID:     "000000000010/000001/000000",
 			Name:           "Changed",
 			Output: fftypes.JSONObject{
 				"value": "1",
@@ -54,6 +55,8 @@ func TestContractEventWithRetries(t *testing.T) {
 	mdi := em.database.(*databasemocks.Plugin)
 	mdi.On("GetContractListenerByProtocolID", mock.Anything, "sb-1").Return(nil, fmt.Errorf("pop")).Once()
 	mdi.On("GetContractListenerByProtocolID", mock.Anything, "sb-1").Return(sub, nil).Times(1) // cached
+	mdi.On("UpdateContractListenerCheckpoint", mock.Anything, sub.ID, "000000000010/000001/000000", true).Return(false, fmt.Errorf("pop")).Once()
+	mdi.On("UpdateContractListenerCheckpoint", mock.Anything, sub.ID, "000000000010/000001/000000", true).Return(true, nil).Times(3)
 	mdi.On("InsertBlockchainEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop")).Once()
 	mdi.On("InsertBlockchainEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.BlockchainEvent) bool {
 		eventID = e.ID
@@ -71,6 +74,34 @@ func TestContractEventWithRetries(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestContractEventBeforeCheckpoint(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev := &blockchain.EventWithSubscription{
+		Subscription: "sb-1",
+		Event: blockchain.Event{
+			BlockchainTXID: "0xabcd1234",
+			ProtocolID:     "000000000010/000001/000000",
+			Name:           "Changed",
+		},
+	}
+	sub := &fftypes.ContractListener{
+		Namespace: "ns",
+		ID:        fftypes.NewUUID(),
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetContractListenerByProtocolID", mock.Anything, "sb-1").Return(sub, nil)
+	mdi.On("UpdateContractListenerCheckpoint", mock.Anything, sub.ID, "000000000010/000001/000000", true).Return(false, nil)
+
+	err := em.BlockchainEvent(ev)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdi.AssertNotCalled(t, "InsertBlockchainEvent", mock.Anything, mock.Anything)
+}
+
 func TestContractEventUnknownSubscription(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -0,0 +1,122 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// ChainReorg is called when the blockchain plugin finds that blocks containing batch pins we have
+// already processed, are no longer on the canonical chain.
+//
+// Confirmed messages in the affected batches are marked unconfirmed, with a message_reorged event
+// emitted for each topic, so that applications can compensate. Pins in the affected batches that the
+// aggregator has not yet dispatched are removed, so messages cannot be confirmed by a transaction that
+// is no longer on the chain - the aggregator sequences them again when they are re-delivered.
+//
+// When the connector re-delivers the batch pin from the canonical chain, the unconfirmed messages are
+// confirmed again.
+func (em *eventManager) ChainReorg(bi blockchain.Plugin, reorg *blockchain.Reorg) error {
+	if len(reorg.BatchIDs) == 0 {
+		return nil
+	}
+	log.L(em.ctx).Warnf("Chain reorganization reported by '%s' from block %d, affecting %d batches", bi.Name(), reorg.BlockNumber, len(reorg.BatchIDs))
+
+	batchIDs := make([]driver.Value, len(reorg.BatchIDs))
+	for i, batchID := range reorg.BatchIDs {
+		batchIDs[i] = batchID
+	}
+	return em.retry.Do(em.ctx, "handle chain reorg", func(attempt int) (bool, error) {
+		err := em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
+			if err := em.updateBatchMessageStates(ctx, batchIDs, nil, fftypes.MessageStateConfirmed, fftypes.MessageStateUnconfirmed, fftypes.EventTypeMessageReorged); err != nil {
+				return err
+			}
+			return em.removeUndispatchedPins(ctx, batchIDs)
+		})
+		return err != nil, err // retry indefinitely (until context closes)
+	})
+}
+
+// reconfirmReorgedMessages confirms any messages that were unconfirmed by a reorg, when the batch pin is re-delivered
+func (em *eventManager) reconfirmReorgedMessages(ctx context.Context, batchPin *blockchain.BatchPin) error {
+	return em.updateBatchMessageStates(ctx, []driver.Value{batchPin.BatchID}, batchPin.TransactionID, fftypes.MessageStateUnconfirmed, fftypes.MessageStateConfirmed, fftypes.EventTypeMessageConfirmed)
+}
+
+func (em *eventManager) updateBatchMessageStates(ctx context.Context, batchIDs []driver.Value, tx *fftypes.UUID, fromState, toState fftypes.MessageState, eventType fftypes.EventType) error {
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	msgs, _, err := em.database.GetMessages(ctx, fb.And(
+		fb.In("batch", batchIDs),
+		fb.Eq("state", fromState),
+	))
+	if err != nil || len(msgs) == 0 {
+		return err
+	}
+
+	var confirmed *fftypes.FFTime
+	if toState == fftypes.MessageStateConfirmed {
+		confirmed = fftypes.Now()
+	}
+	msgIDs := make([]driver.Value, len(msgs))
+	for i, msg := range msgs {
+		msgIDs[i] = msg.Header.ID
+	}
+	update := database.MessageQueryFactory.NewUpdate(ctx).
+		Set("state", toState).
+		Set("confirmed", confirmed)
+	if err := em.database.UpdateMessages(ctx, fb.And(fb.In("id", msgIDs), fb.Eq("state", fromState)), update); err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		log.L(ctx).Infof("Message %s in batch %s is now %s", msg.Header.ID, msg.BatchID, toState)
+		msg.State = toState
+		msg.Confirmed = confirmed
+		em.data.UpdateMessageIfCached(ctx, msg)
+		for _, topic := range msg.Header.Topics {
+			// One event per topic
+			event := fftypes.NewEvent(eventType, msg.Header.Namespace, msg.Header.ID, tx, topic)
+			event.Correlator = msg.Header.CID
+			if err := em.database.InsertEvent(ctx, event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (em *eventManager) removeUndispatchedPins(ctx context.Context, batchIDs []driver.Value) error {
+	fb := database.PinQueryFactory.NewFilter(ctx)
+	pins, _, err := em.database.GetPins(ctx, fb.And(
+		fb.In("batch", batchIDs),
+		fb.Eq("dispatched", false),
+	))
+	if err != nil {
+		return err
+	}
+	for _, pin := range pins {
+		if err := em.database.DeletePin(ctx, pin.Sequence); err != nil {
+			return err
+		}
+	}
+	return nil
+}
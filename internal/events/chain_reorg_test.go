@@ -0,0 +1,174 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChainReorgNoBatches(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	err := em.ChainReorg(&blockchainmocks.Plugin{}, &blockchain.Reorg{BlockNumber: 12345})
+	assert.NoError(t, err)
+}
+
+func TestChainReorgUnconfirmsMessages(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	batchID := fftypes.NewUUID()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			CID:       fftypes.NewUUID(),
+			Namespace: "ns1",
+			Topics:    fftypes.FFStringArray{"topic1", "topic2"},
+		},
+		BatchID: batchID,
+		State:   fftypes.MessageStateConfirmed,
+	}
+
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("Name").Return("utblockchain")
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg}, nil, nil).Once()
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeMessageReorged && *e.Reference == *msg.Header.ID && *e.Correlator == *msg.Header.CID
+	})).Return(nil).Twice()
+	mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{{Sequence: 10}, {Sequence: 11}}, nil, nil)
+	mdi.On("DeletePin", mock.Anything, int64(10)).Return(nil)
+	mdi.On("DeletePin", mock.Anything, int64(11)).Return(nil)
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageIfCached", mock.Anything, msg).Return()
+
+	err := em.ChainReorg(mbi, &blockchain.Reorg{
+		BlockNumber: 12345,
+		BatchIDs:    []*fftypes.UUID{batchID},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateUnconfirmed, msg.State)
+	assert.Nil(t, msg.Confirmed)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestReconfirmReorgedMessages(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	batchPin := &blockchain.BatchPin{
+		BatchID:       fftypes.NewUUID(),
+		TransactionID: fftypes.NewUUID(),
+	}
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Topics:    fftypes.FFStringArray{"topic1"},
+		},
+		BatchID: batchPin.BatchID,
+		State:   fftypes.MessageStateUnconfirmed,
+	}
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg}, nil, nil)
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeMessageConfirmed && *e.Reference == *msg.Header.ID && *e.Transaction == *batchPin.TransactionID
+	})).Return(nil)
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageIfCached", mock.Anything, msg).Return()
+
+	err := em.reconfirmReorgedMessages(em.ctx, batchPin)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateConfirmed, msg.State)
+	assert.NotNil(t, msg.Confirmed)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestUpdateBatchMessageStatesUpdateFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{{}}, nil, nil)
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := em.updateBatchMessageStates(em.ctx, []driver.Value{fftypes.NewUUID()}, nil, fftypes.MessageStateConfirmed, fftypes.MessageStateUnconfirmed, fftypes.EventTypeMessageReorged)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestUpdateBatchMessageStatesInsertEventFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:     fftypes.NewUUID(),
+			Topics: fftypes.FFStringArray{"topic1"},
+		},
+	}
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{msg}, nil, nil)
+	mdi.On("UpdateMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageIfCached", mock.Anything, msg).Return()
+
+	err := em.updateBatchMessageStates(em.ctx, []driver.Value{fftypes.NewUUID()}, nil, fftypes.MessageStateConfirmed, fftypes.MessageStateUnconfirmed, fftypes.EventTypeMessageReorged)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRemoveUndispatchedPinsGetFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetPins", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := em.removeUndispatchedPins(em.ctx, []driver.Value{fftypes.NewUUID()})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRemoveUndispatchedPinsDeleteFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetPins", mock.Anything, mock.Anything).Return([]*fftypes.Pin{{Sequence: 10}}, nil, nil)
+	mdi.On("DeletePin", mock.Anything, int64(10)).Return(fmt.Errorf("pop"))
+
+	err := em.removeUndispatchedPins(em.ctx, []driver.Value{fftypes.NewUUID()})
+	assert.EqualError(t, err, "pop")
+}
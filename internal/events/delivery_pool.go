@@ -0,0 +1,116 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/metrics"
+)
+
+// deliveryPool is a fixed set of workers shared by all the dispatchers of a node, so that the number of goroutines
+// delivering events does not grow with the number of subscriptions.
+//
+// A dispatcher is queued at most once, and is only ever processed by one worker at a time, so events for a
+// subscription are still delivered in the order they were dispatched.
+type deliveryPool struct {
+	ctx   context.Context
+	size  int
+	mux   sync.Mutex
+	ready []*eventDispatcher
+	wake  chan struct{}
+}
+
+func newDeliveryPool(ctx context.Context, size int) *deliveryPool {
+	return &deliveryPool{
+		ctx:  ctx,
+		size: size,
+		wake: make(chan struct{}, size),
+	}
+}
+
+func (dp *deliveryPool) start() {
+	log.L(dp.ctx).Infof("Starting %d event delivery workers", dp.size)
+	metrics.DeliveryPoolStarted(dp.size)
+	for i := 0; i < dp.size; i++ {
+		go dp.worker()
+	}
+}
+
+// schedule queues a dispatcher that has events waiting for delivery, unless it is already queued or being processed
+func (dp *deliveryPool) schedule(ed *eventDispatcher) {
+	dp.mux.Lock()
+	if ed.deliveryScheduled {
+		dp.mux.Unlock()
+		return
+	}
+	ed.deliveryScheduled = true
+	dp.ready = append(dp.ready, ed)
+	metrics.DeliveryPoolQueueLength(len(dp.ready))
+	dp.mux.Unlock()
+
+	// If every worker already has a wake-up pending, one of them will find this dispatcher
+	select {
+	case dp.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (dp *deliveryPool) next() *eventDispatcher {
+	for {
+		dp.mux.Lock()
+		if len(dp.ready) > 0 {
+			ed := dp.ready[0]
+			dp.ready = dp.ready[1:]
+			metrics.DeliveryPoolQueueLength(len(dp.ready))
+			dp.mux.Unlock()
+			return ed
+		}
+		dp.mux.Unlock()
+		select {
+		case <-dp.wake:
+		case <-dp.ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (dp *deliveryPool) worker() {
+	for {
+		ed := dp.next()
+		if ed == nil {
+			log.L(dp.ctx).Debugf("Event delivery worker exiting")
+			return
+		}
+		metrics.DeliveryWorkerBusy()
+		// Limit how much we deliver for one subscription, before giving the other subscriptions a turn
+		ed.deliverQueued(ed.readAhead + 1)
+		metrics.DeliveryWorkerIdle()
+
+		// Anything that arrived after we stopped reading did not queue the dispatcher, as it was still
+		// marked scheduled - so we check under the lock, and queue it again ourselves
+		dp.mux.Lock()
+		ed.deliveryScheduled = false
+		more := ed.ctx.Err() == nil && len(ed.eventDelivery) > 0
+		dp.mux.Unlock()
+		if more {
+			dp.schedule(ed)
+		}
+	}
+}
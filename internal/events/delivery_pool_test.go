@@ -0,0 +1,181 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestPooledDispatcher(pool *deliveryPool, readAhead uint16, changeEvents bool) (*eventDispatcher, func()) {
+	ed, cancel := newTestEventDispatcher(&subscription{
+		dispatcherElection: make(chan bool, 1),
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Ephemeral:       true,
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					ReadAhead: &readAhead,
+				},
+				ChangeEvents: changeEvents,
+			},
+		},
+	})
+	ed.pool = pool
+	return ed, cancel
+}
+
+func TestDeliveryPoolBufferedDeliveryInOrder(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+	dp := newDeliveryPool(ctx, 2)
+	dp.start()
+
+	ed, cancel := newTestPooledDispatcher(dp, 10, false)
+	defer cancel()
+
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	eventDeliveries := make(chan *fftypes.EventDelivery)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock.RunFn = func(a mock.Arguments) {
+		eventDeliveries <- a.Get(2).(*fftypes.EventDelivery)
+	}
+
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	ev3 := fftypes.NewUUID()
+	batchDone := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{
+			&fftypes.Event{ID: ev1, Sequence: 10000001, Type: fftypes.EventTypeIdentityConfirmed},
+			&fftypes.Event{ID: ev2, Sequence: 10000002, Type: fftypes.EventTypeIdentityConfirmed},
+			&fftypes.Event{ID: ev3, Sequence: 10000003, Type: fftypes.EventTypeIdentityConfirmed},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(batchDone)
+	}()
+
+	for _, id := range []*fftypes.UUID{ev1, ev2, ev3} {
+		event := <-eventDeliveries
+		assert.Equal(t, *id, *event.ID)
+		ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: event.ID})
+	}
+	<-batchDone
+
+	mei.AssertExpectations(t)
+}
+
+func TestDeliveryPoolReschedulesRemaining(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	defer cancelCtx()
+	dp := newDeliveryPool(ctx, 1)
+
+	// With no read-ahead, a worker only delivers one event per turn
+	ed1, cancel1 := newTestPooledDispatcher(dp, 0, false)
+	defer cancel1()
+	ed2, cancel2 := newTestPooledDispatcher(dp, 0, false)
+	defer cancel2()
+
+	delivered := make(chan *fftypes.UUID)
+	for _, ed := range []*eventDispatcher{ed1, ed2} {
+		ed.eventDelivery = make(chan *fftypes.EventDelivery, 2)
+		mei := ed.transport.(*eventsmocks.PluginAll)
+		mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil).
+			Run(func(a mock.Arguments) {
+				delivered <- a.Get(2).(*fftypes.EventDelivery).ID
+			})
+	}
+	ev1 := fftypes.NewUUID()
+	ev2 := fftypes.NewUUID()
+	ev3 := fftypes.NewUUID()
+	ed1.eventDelivery <- &fftypes.EventDelivery{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: ev1}}}
+	ed1.eventDelivery <- &fftypes.EventDelivery{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: ev3}}}
+	ed2.eventDelivery <- &fftypes.EventDelivery{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: ev2}}}
+
+	// Scheduling twice only queues once, and the second wake-up is dropped as the pool has one worker
+	dp.schedule(ed1)
+	dp.schedule(ed1)
+	dp.schedule(ed2)
+	assert.Len(t, dp.ready, 2)
+	assert.Len(t, dp.wake, 1)
+
+	dp.start()
+	assert.Equal(t, *ev1, *<-delivered)
+	assert.Equal(t, *ev2, *<-delivered)
+	assert.Equal(t, *ev3, *<-delivered)
+}
+
+func TestDeliveryPoolWorkerExits(t *testing.T) {
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	dp := newDeliveryPool(ctx, 1)
+	cancelCtx()
+	dp.worker()
+}
+
+func TestDeliverQueuedEmpty(t *testing.T) {
+	ed, cancel := newTestPooledDispatcher(nil, 0, false)
+	defer cancel()
+	ed.deliverQueued(1)
+}
+
+func TestDeliverQueuedClosed(t *testing.T) {
+	ed, cancel := newTestPooledDispatcher(nil, 0, false)
+	defer cancel()
+	close(ed.eventDelivery)
+	ed.deliverQueued(1)
+}
+
+func TestDeliverQueuedContextDone(t *testing.T) {
+	ed, cancel := newTestPooledDispatcher(nil, 0, false)
+	ed.eventDelivery <- &fftypes.EventDelivery{}
+	cancel()
+	ed.deliverQueued(1)
+	assert.Len(t, ed.eventDelivery, 1)
+}
+
+func TestElectAndStartPooled(t *testing.T) {
+	dp := newDeliveryPool(context.Background(), 1)
+
+	ed1, cancel1 := newTestPooledDispatcher(dp, 0, false)
+	defer cancel1()
+	ed2, cancel2 := newTestPooledDispatcher(dp, 0, true)
+	defer cancel2()
+
+	for _, ed := range []*eventDispatcher{ed1, ed2} {
+		mdi := ed.database.(*databasemocks.Plugin)
+		polled := make(chan struct{})
+		mdi.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil).Run(func(a mock.Arguments) {
+			close(polled)
+		}).Once()
+		mdi.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil).Maybe()
+		ed.start()
+		<-polled
+		ed.close()
+	}
+
+	// Only the dispatcher without change events delivers on the pool
+	assert.Equal(t, dp, ed1.pool)
+	assert.Nil(t, ed2.pool)
+}
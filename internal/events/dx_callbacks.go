@@ -39,11 +39,20 @@ func (em *eventManager) MessageReceived(dx dataexchange.Plugin, peerID string, d
 		l.Errorf("Invalid transmission from '%s': %s", peerID, err)
 		return "", nil
 	}
+	if wrapper.Tombstone != nil {
+		l.Infof("Private batch tombstone received from '%s' for batch '%s'", peerID, wrapper.Tombstone)
+		return "", em.privateBatchTombstoned(peerID, wrapper.Tombstone)
+	}
 	if wrapper.Batch == nil {
 		l.Errorf("Invalid transmission: nil batch")
 		return "", nil
 	}
-	l.Infof("Private batch received from '%s' (len=%d)", peerID, len(data))
+	l.Infof("Private batch received from '%s' (len=%d relayed=%t)", peerID, len(data), wrapper.Relayed)
+
+	if wrapper.Relayed && wrapper.Batch.Payload.TX.Type != fftypes.TransactionTypeBatchPin {
+		l.Errorf("Invalid transmission: only pinned batches can be relayed")
+		return "", nil
+	}
 
 	if wrapper.Batch.Payload.TX.Type == fftypes.TransactionTypeUnpinned {
 		valid, err := em.definitions.EnsureLocalGroup(em.ctx, wrapper.Group)
@@ -56,7 +65,11 @@ func (em *eventManager) MessageReceived(dx dataexchange.Plugin, peerID string, d
 		}
 	}
 
-	manifestString, err := em.privateBatchReceived(peerID, wrapper.Batch)
+	manifestString, err := em.privateBatchReceived(peerID, wrapper.Batch, wrapper.Relayed)
+	if err == nil && manifestString != "" && len(wrapper.Relay) > 0 {
+		// This node is part of the relay tree for the group, so must pass the batch on
+		em.messaging.RelayBatch(wrapper)
+	}
 	return manifestString, err
 }
 
@@ -109,14 +122,47 @@ func (em *eventManager) checkReceivedOffchainIdentity(ctx context.Context, peerI
 	return node, nil
 }
 
-func (em *eventManager) privateBatchReceived(peerID string, batch *fftypes.Batch) (manifest string, err error) {
+// A relayed batch is accepted from any node that is a member of the group. The author of the batch is then
+// verified by the aggregator against the pin on the blockchain, which is why only pinned batches are relayed.
+func (em *eventManager) checkRelayingMember(ctx context.Context, peerID string, batch *fftypes.Batch) (node *fftypes.Identity, err error) {
+	l := log.L(em.ctx)
+
+	node, err = em.identity.FindIdentityForVerifier(ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: peerID,
+	})
+	if err != nil || node == nil {
+		return nil, err
+	}
+
+	group, err := em.database.GetGroupByHash(ctx, batch.Group)
+	if err != nil {
+		return nil, err
+	}
+	if group != nil {
+		for _, member := range group.Members {
+			if member.Node.Equals(node.ID) {
+				return node, nil
+			}
+		}
+	}
+	l.Errorf("Relaying node '%s' is not a member of group '%s'", node.ID, batch.Group)
+	return nil, nil
+}
+
+func (em *eventManager) privateBatchReceived(peerID string, batch *fftypes.Batch, relayed bool) (manifest string, err error) {
 
 	// Retry for persistence errors (not validation errors)
 	err = em.retry.Do(em.ctx, "private batch received", func(attempt int) (bool, error) {
 		return true, em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
 			l := log.L(ctx)
 
-			node, err := em.checkReceivedOffchainIdentity(ctx, peerID, batch.Author)
+			var node *fftypes.Identity
+			if relayed {
+				node, err = em.checkRelayingMember(ctx, peerID, batch)
+			} else {
+				node, err = em.checkReceivedOffchainIdentity(ctx, peerID, batch.Author)
+			}
 			if err != nil {
 				return err
 			}
@@ -124,6 +170,17 @@ func (em *eventManager) privateBatchReceived(peerID string, batch *fftypes.Batch
 				l.Errorf("Batch received from invalid author '%s' for peer ID '%s'", batch.Author, peerID)
 				return nil
 			}
+			if !relayed {
+				// Relayed batches are pinned, so the aggregator checks the author when dispatching the messages
+				endorsed, err := em.identity.VerifyAuthorEndorsed(ctx, batch.Author)
+				if err != nil {
+					return err
+				}
+				if !endorsed {
+					l.Errorf("Batch received from author '%s' that has not been endorsed", batch.Author)
+					return nil
+				}
+			}
 
 			persistedBatch, valid, err := em.persistBatch(ctx, batch)
 			if err != nil || !valid {
@@ -132,6 +189,13 @@ func (em *eventManager) privateBatchReceived(peerID string, batch *fftypes.Batch
 			}
 
 			if batch.Payload.TX.Type == fftypes.TransactionTypeBatchPin {
+				if !relayed && em.pinning.IsDeferred(batch.Namespace) {
+					// Private messages are confirmed on delivery, and reconciled by the aggregator once the pin is anchored.
+					// Relayed batches wait for the pin, as that is what verifies their author.
+					if err := em.markDeferredMessagesConfirmed(ctx, batch); err != nil {
+						return err
+					}
+				}
 				// Poke the aggregator to do its stuff
 				em.aggregator.rewindBatches <- batch.ID
 			} else if batch.Payload.TX.Type == fftypes.TransactionTypeUnpinned {
@@ -148,11 +212,88 @@ func (em *eventManager) privateBatchReceived(peerID string, batch *fftypes.Batch
 
 }
 
+// privateBatchTombstoned rejects the messages of a pinned batch that are still waiting for their pin, when the
+// sender reports the pin failed. Only the node of the author of the batch can withdraw it.
+func (em *eventManager) privateBatchTombstoned(peerID string, batchID *fftypes.UUID) error {
+	return em.retry.Do(em.ctx, "private batch tombstone", func(attempt int) (bool, error) {
+		return true, em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
+			l := log.L(ctx)
+
+			batch, err := em.database.GetBatchByID(ctx, batchID)
+			if err != nil {
+				return err
+			}
+			if batch == nil || batch.TX.Type != fftypes.TransactionTypeBatchPin {
+				l.Errorf("Tombstone received for unknown pinned batch '%s'", batchID)
+				return nil
+			}
+			node, err := em.checkReceivedOffchainIdentity(ctx, peerID, batch.Author)
+			if err != nil {
+				return err
+			}
+			if node == nil {
+				l.Errorf("Tombstone for batch '%s' received from peer ID '%s' that is not the author '%s'", batchID, peerID, batch.Author)
+				return nil
+			}
+
+			fb := database.MessageQueryFactory.NewFilter(ctx)
+			filter := fb.And(
+				fb.Eq("batch", batchID),
+				fb.Eq("state", fftypes.MessageStatePending),
+			)
+			msgs, _, err := em.database.GetMessages(ctx, filter)
+			if err != nil || len(msgs) == 0 {
+				return err
+			}
+			update := database.MessageQueryFactory.NewUpdate(ctx).
+				Set("state", fftypes.MessageStateRejected).
+				Set("confirmed", fftypes.Now())
+			if err := em.database.UpdateMessages(ctx, filter, update); err != nil {
+				return err
+			}
+			for _, msg := range msgs {
+				for _, topic := range msg.Header.Topics {
+					// One event per topic
+					event := fftypes.NewEvent(fftypes.EventTypeMessageRejected, batch.Namespace, msg.Header.ID, batch.TX.ID, topic)
+					event.Correlator = msg.Header.CID
+					if err := em.database.InsertEvent(ctx, event); err != nil {
+						return err
+					}
+				}
+			}
+			l.Infof("Rejected %d messages of tombstoned batch '%s'", len(msgs), batchID)
+			return nil
+		})
+	})
+}
+
 func (em *eventManager) markUnpinnedMessagesConfirmed(ctx context.Context, batch *fftypes.Batch) error {
+	return em.markMessagesConfirmed(ctx, batch, batch.Payload.Messages)
+}
+
+// markDeferredMessagesConfirmed confirms the private messages in a pinned batch, in a namespace with deferred pinning.
+// Other messages in the batch, such as group inits, are confirmed by the aggregator once the pin is anchored.
+func (em *eventManager) markDeferredMessagesConfirmed(ctx context.Context, batch *fftypes.Batch) error {
+	msgs := make([]*fftypes.Message, 0, len(batch.Payload.Messages))
+	for _, msg := range batch.Payload.Messages {
+		if msg.Header.Type == fftypes.MessageTypePrivate {
+			// The aggregator decides to reconcile, rather than confirm, from the state of the cached message
+			msg.State = fftypes.MessageStateConfirmed
+			em.data.UpdateMessageIfCached(ctx, msg)
+			msgs = append(msgs, msg)
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return em.markMessagesConfirmed(ctx, batch, msgs)
+}
+
+func (em *eventManager) markMessagesConfirmed(ctx context.Context, batch *fftypes.Batch, msgs []*fftypes.Message) error {
 
 	// Update all the messages in the batch with the batch ID
-	msgIDs := make([]driver.Value, len(batch.Payload.Messages))
-	for i, msg := range batch.Payload.Messages {
+	msgIDs := make([]driver.Value, len(msgs))
+	for i, msg := range msgs {
 		msgIDs[i] = msg.Header.ID
 	}
 	fb := database.MessageQueryFactory.NewFilter(ctx)
@@ -171,7 +312,7 @@ func (em *eventManager) markUnpinnedMessagesConfirmed(ctx context.Context, batch
 		return err
 	}
 
-	for _, msg := range batch.Payload.Messages {
+	for _, msg := range msgs {
 		for _, topic := range msg.Header.Topics {
 			// One event per topic
 			event := fftypes.NewEvent(fftypes.EventTypeMessageConfirmed, batch.Namespace, msg.Header.ID, batch.Payload.TX.ID, topic)
@@ -200,15 +341,22 @@ func (em *eventManager) BLOBReceived(dx dataexchange.Plugin, peerID string, hash
 
 		batchIDs := make(map[fftypes.UUID]bool)
 
+		// Check the blob with the scanner (if configured) before we record it. An infected blob is still
+		// recorded, so that the messages waiting for it are quarantined rather than blocking their topics.
+		blob := &fftypes.Blob{
+			Peer:       peerID,
+			PayloadRef: payloadRef,
+			Hash:       &hash,
+			Size:       size,
+			Created:    fftypes.Now(),
+		}
+		if err := em.data.ScanBlob(em.ctx, blob); err != nil {
+			return true, err
+		}
+
 		err = em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
 			// Insert the blob into the detabase
-			err := em.database.InsertBlob(ctx, &fftypes.Blob{
-				Peer:       peerID,
-				PayloadRef: payloadRef,
-				Hash:       &hash,
-				Size:       size,
-				Created:    fftypes.Now(),
-			})
+			err := em.database.InsertBlob(ctx, blob)
 			if err != nil {
 				return err
 			}
@@ -327,6 +475,12 @@ func (em *eventManager) TransferResult(dx dataexchange.Plugin, trackingID string
 		if err := em.database.ResolveOperation(em.ctx, op.ID, status, update.Error, update.Info); err != nil {
 			return true, err // this is always retryable
 		}
+		if status == fftypes.OpStatusFailed {
+			// Undo any other steps of the transaction that leave it half-complete
+			if err := em.operations.StepFailed(em.ctx, op.ID); err != nil {
+				return true, err
+			}
+		}
 		return false, nil
 	})
 
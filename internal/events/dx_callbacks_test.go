@@ -28,6 +28,8 @@ import (
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/definitionsmocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -112,6 +114,183 @@ func TestPinnedReceiveOK(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestPinnedReceiveAuthorNotEndorsed(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	_, b := sampleBatchTransfer(t, fftypes.TransactionTypeBatchPin)
+
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := &identitymanagermocks.Manager{}
+	em.identity = mim
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookup", em.ctx, "signingOrg").Return(org1, false, nil)
+	mim.On("VerifyAuthorEndorsed", em.ctx, "signingOrg").Return(false, nil)
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	mim.AssertExpectations(t)
+}
+
+func TestPinnedReceiveAuthorEndorsedFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // retryable error so we need to break the loop
+
+	_, b := sampleBatchTransfer(t, fftypes.TransactionTypeBatchPin)
+
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := &identitymanagermocks.Manager{}
+	em.identity = mim
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookup", em.ctx, "signingOrg").Return(org1, false, nil)
+	mim.On("VerifyAuthorEndorsed", em.ctx, "signingOrg").Return(false, fmt.Errorf("pop"))
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.Regexp(t, "FF10158", err)
+	assert.Empty(t, m)
+
+	mim.AssertExpectations(t)
+}
+
+func sampleRelayedTransfer(t *testing.T, txType fftypes.TransactionType) (*fftypes.Batch, *fftypes.TransportWrapper, []byte) {
+	data := &fftypes.Data{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"test"`)}
+	batch := sampleBatch(t, fftypes.BatchTypePrivate, txType, fftypes.DataArray{data})
+	tw := &fftypes.TransportWrapper{
+		Batch:   batch,
+		Relayed: true,
+		Relay:   []*fftypes.RelayBranch{{Node: fftypes.NewUUID()}},
+	}
+	b, _ := json.Marshal(tw)
+	return batch, tw, b
+}
+
+func TestRelayedReceiveOK(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	batch, tw, b := sampleRelayedTransfer(t, fftypes.TransactionTypeBatchPin)
+
+	node1 := newTestNode("node1", newTestOrg("relayorg"))
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mdi.On("GetGroupByHash", em.ctx, batch.Group).Return(&fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{
+			Members: fftypes.Members{
+				{Identity: "org2", Node: fftypes.NewUUID()},
+				{Identity: "relayorg", Node: node1.ID},
+			},
+		},
+	}, nil)
+	mdi.On("UpsertBatch", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertDataArray", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertMessages", em.ctx, mock.Anything).Return(nil, nil)
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageCache", mock.Anything, mock.Anything).Return()
+	mpm := em.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("RelayBatch", mock.MatchedBy(func(relay *fftypes.TransportWrapper) bool {
+		return relay.Batch.ID.Equals(batch.ID) && relay.Relay[0].Node.Equals(tw.Relay[0].Node)
+	})).Return()
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mpm.AssertExpectations(t)
+}
+
+func TestRelayedReceiveUnpinnedIgnored(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	_, _, b := sampleRelayedTransfer(t, fftypes.TransactionTypeUnpinned)
+
+	mdx := &dataexchangemocks.Plugin{}
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+}
+
+func TestRelayedReceiveNotMember(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	batch, _, b := sampleRelayedTransfer(t, fftypes.TransactionTypeBatchPin)
+
+	node1 := newTestNode("node1", newTestOrg("relayorg"))
+	mdi := em.database.(*databasemocks.Plugin)
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mdi.On("GetGroupByHash", em.ctx, batch.Group).Return(nil, nil)
+
+	mdx := &dataexchangemocks.Plugin{}
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRelayedReceiveNodeNotFound(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	_, _, b := sampleRelayedTransfer(t, fftypes.TransactionTypeBatchPin)
+
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(nil, nil)
+
+	mdx := &dataexchangemocks.Plugin{}
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+}
+
+func TestRelayedReceiveGroupLookupFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // to stop retry
+
+	batch, _, b := sampleRelayedTransfer(t, fftypes.TransactionTypeBatchPin)
+
+	node1 := newTestNode("node1", newTestOrg("relayorg"))
+	mdi := em.database.(*databasemocks.Plugin)
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mdi.On("GetGroupByHash", em.ctx, batch.Group).Return(nil, fmt.Errorf("pop"))
+
+	mdx := &dataexchangemocks.Plugin{}
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.Regexp(t, "FF10158", err)
+	assert.Empty(t, m)
+}
+
 func TestMessageReceiveOkBadBatchIgnored(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -335,6 +514,8 @@ func TestBLOBReceivedTriggersRewindOk(t *testing.T) {
 
 	mdx := &dataexchangemocks.Plugin{}
 
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("ScanBlob", em.ctx, mock.Anything).Return(nil)
 	mdi := em.database.(*databasemocks.Plugin)
 	mdi.On("InsertBlob", em.ctx, mock.Anything).Return(nil)
 	mdi.On("GetDataRefs", em.ctx, mock.Anything).Return(fftypes.DataRefs{
@@ -353,6 +534,47 @@ func TestBLOBReceivedTriggersRewindOk(t *testing.T) {
 	mdi.AssertExpectations(t)
 }
 
+func TestBLOBReceivedQuarantined(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	hash := fftypes.NewRandB32()
+
+	mdx := &dataexchangemocks.Plugin{}
+
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("ScanBlob", em.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		args[1].(*fftypes.Blob).Threat = "Eicar-Test-Signature"
+	}).Return(nil)
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("InsertBlob", em.ctx, mock.MatchedBy(func(blob *fftypes.Blob) bool {
+		return blob.Hash.Equals(hash) && blob.Threat == "Eicar-Test-Signature"
+	})).Return(nil)
+	mdi.On("GetDataRefs", em.ctx, mock.Anything).Return(fftypes.DataRefs{}, nil, nil)
+
+	err := em.BLOBReceived(mdx, "peer1", *hash, 12345, "ns1/path1")
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestBLOBReceivedScanFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // retryable error
+	hash := fftypes.NewRandB32()
+
+	mdx := &dataexchangemocks.Plugin{}
+
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("ScanBlob", em.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := em.BLOBReceived(mdx, "peer1", *hash, 12345, "ns1/path1")
+	assert.Regexp(t, "FF10158", err)
+
+	mdm.AssertExpectations(t)
+}
+
 func TestBLOBReceivedBadEvent(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -369,6 +591,8 @@ func TestBLOBReceivedGetMessagesFail(t *testing.T) {
 
 	mdx := &dataexchangemocks.Plugin{}
 
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("ScanBlob", em.ctx, mock.Anything).Return(nil)
 	mdi := em.database.(*databasemocks.Plugin)
 	mdi.On("InsertBlob", em.ctx, mock.Anything).Return(nil)
 	mdi.On("GetDataRefs", em.ctx, mock.Anything).Return(fftypes.DataRefs{
@@ -389,6 +613,8 @@ func TestBLOBReceivedGetDataRefsFail(t *testing.T) {
 
 	mdx := &dataexchangemocks.Plugin{}
 
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("ScanBlob", em.ctx, mock.Anything).Return(nil)
 	mdi := em.database.(*databasemocks.Plugin)
 	mdi.On("InsertBlob", em.ctx, mock.Anything).Return(nil)
 	mdi.On("GetDataRefs", em.ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
@@ -406,6 +632,8 @@ func TestBLOBReceivedInsertBlobFails(t *testing.T) {
 
 	mdx := &dataexchangemocks.Plugin{}
 
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("ScanBlob", em.ctx, mock.Anything).Return(nil)
 	mdi := em.database.(*databasemocks.Plugin)
 	mdi.On("InsertBlob", em.ctx, mock.Anything).Return(fmt.Errorf("pop"))
 
@@ -430,6 +658,9 @@ func TestTransferResultOk(t *testing.T) {
 		"extra": "info",
 	}).Return(nil)
 
+	mom := em.operations.(*operationmocks.Manager)
+	mom.On("StepFailed", mock.Anything, id).Return(nil)
+
 	mdx := &dataexchangemocks.Plugin{}
 	mdx.On("Name").Return("utdx")
 	err := em.TransferResult(mdx, id.String(), fftypes.OpStatusFailed, fftypes.TransportStatusUpdate{
@@ -439,6 +670,29 @@ func TestTransferResultOk(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestTransferResultStepFailedFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel()
+
+	mdi := em.database.(*databasemocks.Plugin)
+	id := fftypes.NewUUID()
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
+		{
+			ID: id,
+		},
+	}, nil, nil)
+	mdi.On("ResolveOperation", mock.Anything, id, fftypes.OpStatusFailed, "error info", fftypes.JSONObject(nil)).Return(nil)
+	mom := em.operations.(*operationmocks.Manager)
+	mom.On("StepFailed", mock.Anything, id).Return(fmt.Errorf("pop"))
+
+	mdx := &dataexchangemocks.Plugin{}
+	mdx.On("Name").Return("utdx")
+	err := em.TransferResult(mdx, id.String(), fftypes.OpStatusFailed, fftypes.TransportStatusUpdate{
+		Error: "error info",
+	})
+	assert.Regexp(t, "FF10158", err)
+}
+
 func TestTransferResultManifestMismatch(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	defer cancel()
@@ -463,6 +717,9 @@ func TestTransferResultManifestMismatch(t *testing.T) {
 		"extra": "info",
 	}).Return(nil)
 
+	mom := em.operations.(*operationmocks.Manager)
+	mom.On("StepFailed", mock.Anything, id).Return(nil)
+
 	mdx := &dataexchangemocks.Plugin{}
 	mdx.On("Name").Return("utdx")
 	mdx.On("Capabilities").Return(&dataexchange.Capabilities{
@@ -532,6 +789,9 @@ func TestTransferResultHashtMismatch(t *testing.T) {
 		"extra": "info",
 	}).Return(nil)
 
+	mom := em.operations.(*operationmocks.Manager)
+	mom.On("StepFailed", mock.Anything, id).Return(nil)
+
 	mdx := &dataexchangemocks.Plugin{}
 	mdx.On("Name").Return("utdx")
 	mdx.On("Capabilities").Return(&dataexchange.Capabilities{
@@ -929,3 +1189,279 @@ func TestMessageReceiveMessageEnsureLocalGroupReject(t *testing.T) {
 	mdi.AssertExpectations(t)
 	mdx.AssertExpectations(t)
 }
+
+func sampleTombstone(t *testing.T) (*fftypes.BatchPersisted, []byte) {
+	bp := &fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			SignerRef: fftypes.SignerRef{Author: "signingOrg"},
+		},
+		TX: fftypes.TransactionRef{
+			Type: fftypes.TransactionTypeBatchPin,
+			ID:   fftypes.NewUUID(),
+		},
+	}
+	b, _ := json.Marshal(&fftypes.TransportWrapper{Tombstone: bp.ID})
+	return bp, b
+}
+
+func TestTombstoneReceiveOK(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	bp, b := sampleTombstone(t)
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:     fftypes.NewUUID(),
+			CID:    fftypes.NewUUID(),
+			Topics: fftypes.FFStringArray{"topic1", "topic2"},
+		},
+	}
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mdi.On("GetBatchByID", em.ctx, bp.ID).Return(bp, nil)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookup", em.ctx, "signingOrg").Return(org1, false, nil)
+	mdi.On("GetMessages", em.ctx, mock.Anything).Return([]*fftypes.Message{msg}, nil, nil)
+	mdi.On("UpdateMessages", em.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", em.ctx, mock.MatchedBy(func(event *fftypes.Event) bool {
+		return event.Type == fftypes.EventTypeMessageRejected &&
+			event.Reference.Equals(msg.Header.ID) &&
+			event.Correlator.Equals(msg.Header.CID) &&
+			event.Transaction.Equals(bp.TX.ID)
+	})).Return(nil).Twice()
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestTombstoneReceiveUnknownBatch(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	bp, b := sampleTombstone(t)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mdi.On("GetBatchByID", em.ctx, bp.ID).Return(nil, nil)
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestTombstoneReceiveGetBatchFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // retryable error so we need to break the loop
+
+	bp, b := sampleTombstone(t)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mdi.On("GetBatchByID", em.ctx, bp.ID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := em.MessageReceived(mdx, "peer1", b)
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestTombstoneReceiveNotAuthor(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	bp, b := sampleTombstone(t)
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", newTestOrg("org2"))
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mdi.On("GetBatchByID", em.ctx, bp.ID).Return(bp, nil)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookup", em.ctx, "signingOrg").Return(org1, false, nil)
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestTombstoneReceiveIdentityFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // retryable error so we need to break the loop
+
+	bp, b := sampleTombstone(t)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mdi.On("GetBatchByID", em.ctx, bp.ID).Return(bp, nil)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := em.MessageReceived(mdx, "peer1", b)
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestTombstoneReceiveNoPendingMessages(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	bp, b := sampleTombstone(t)
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mdi.On("GetBatchByID", em.ctx, bp.ID).Return(bp, nil)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, mock.Anything).Return(node1, nil)
+	mim.On("CachedIdentityLookup", em.ctx, "signingOrg").Return(org1, false, nil)
+	mdi.On("GetMessages", em.ctx, mock.Anything).Return([]*fftypes.Message{}, nil, nil)
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.Empty(t, m)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestTombstoneReceiveUpdateFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // retryable error so we need to break the loop
+
+	bp, b := sampleTombstone(t)
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mdi.On("GetBatchByID", em.ctx, bp.ID).Return(bp, nil)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, mock.Anything).Return(node1, nil)
+	mim.On("CachedIdentityLookup", em.ctx, "signingOrg").Return(org1, false, nil)
+	mdi.On("GetMessages", em.ctx, mock.Anything).Return([]*fftypes.Message{{}}, nil, nil)
+	mdi.On("UpdateMessages", em.ctx, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := em.MessageReceived(mdx, "peer1", b)
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestTombstoneReceiveInsertEventFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // retryable error so we need to break the loop
+
+	bp, b := sampleTombstone(t)
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mdi.On("GetBatchByID", em.ctx, bp.ID).Return(bp, nil)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, mock.Anything).Return(node1, nil)
+	mim.On("CachedIdentityLookup", em.ctx, "signingOrg").Return(org1, false, nil)
+	mdi.On("GetMessages", em.ctx, mock.Anything).Return([]*fftypes.Message{{
+		Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Topics: fftypes.FFStringArray{"topic1"}},
+	}}, nil, nil)
+	mdi.On("UpdateMessages", em.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", em.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := em.MessageReceived(mdx, "peer1", b)
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestPinnedReceiveDeferredConfirmed(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	batch, b := sampleBatchTransfer(t, fftypes.TransactionTypeBatchPin)
+	em.pinning = newTestDeferredPinningPolicy(t, batch.Namespace)
+
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookup", em.ctx, "signingOrg").Return(org1, false, nil)
+	mdi.On("UpsertBatch", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertDataArray", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertMessages", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("UpdateMessages", em.ctx, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("InsertEvent", em.ctx, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeMessageConfirmed && e.Reference.Equals(batch.Payload.Messages[0].Header.ID)
+	})).Return(nil)
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageCache", mock.Anything, mock.Anything).Return()
+	mdm.On("UpdateMessageIfCached", em.ctx, mock.MatchedBy(func(msg *fftypes.Message) bool {
+		return msg.State == fftypes.MessageStateConfirmed
+	})).Return()
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.NoError(t, err)
+	assert.NotNil(t, m)
+	assert.Equal(t, batch.ID, <-em.aggregator.rewindBatches)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestPinnedReceiveDeferredConfirmFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // to avoid infinite retry
+
+	batch, b := sampleBatchTransfer(t, fftypes.TransactionTypeBatchPin)
+	em.pinning = newTestDeferredPinningPolicy(t, batch.Namespace)
+
+	org1 := newTestOrg("org1")
+	node1 := newTestNode("node1", org1)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdx := &dataexchangemocks.Plugin{}
+	mim := em.identity.(*identitymanagermocks.Manager)
+	mim.On("FindIdentityForVerifier", em.ctx, []fftypes.IdentityType{fftypes.IdentityTypeNode}, fftypes.SystemNamespace, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeFFDXPeerID,
+		Value: "peer1",
+	}).Return(node1, nil)
+	mim.On("CachedIdentityLookup", em.ctx, "signingOrg").Return(org1, false, nil)
+	mdi.On("UpsertBatch", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertDataArray", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("InsertMessages", em.ctx, mock.Anything).Return(nil, nil)
+	mdi.On("UpdateMessages", em.ctx, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mdm := em.data.(*datamocks.Manager)
+	mdm.On("UpdateMessageCache", mock.Anything, mock.Anything).Return()
+	mdm.On("UpdateMessageIfCached", em.ctx, mock.Anything).Return()
+
+	m, err := em.MessageReceived(mdx, "peer1", b)
+	assert.Regexp(t, "FF10158", err)
+	assert.Empty(t, m)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestMarkDeferredMessagesConfirmedNoPrivate(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	err := em.markDeferredMessagesConfirmed(em.ctx, &fftypes.Batch{
+		Payload: fftypes.BatchPayload{
+			Messages: []*fftypes.Message{
+				{Header: fftypes.MessageHeader{ID: fftypes.NewUUID(), Type: fftypes.MessageTypeGroupInit}},
+			},
+		},
+	})
+	assert.NoError(t, err)
+}
@@ -20,6 +20,8 @@ import (
 	"context"
 
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/events/as2"
+	"github.com/hyperledger/firefly/internal/events/email"
 	"github.com/hyperledger/firefly/internal/events/system"
 	"github.com/hyperledger/firefly/internal/events/webhooks"
 	"github.com/hyperledger/firefly/internal/events/websockets"
@@ -30,6 +32,8 @@ import (
 var plugins = []events.Plugin{
 	&websockets.WebSockets{},
 	&webhooks.WebHooks{},
+	&email.Email{},
+	&as2.AS2{},
 	&system.Events{},
 }
 
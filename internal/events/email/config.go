@@ -0,0 +1,47 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import "github.com/hyperledger/firefly/internal/config"
+
+const (
+	defaultSubject = "FireFly {{.Type}} event in namespace {{.Namespace}}"
+)
+
+const (
+	// SMTPHost is the host of the SMTP server that emails are delivered through
+	SMTPHost = "smtp.host"
+	// SMTPPort is the port of the SMTP server
+	SMTPPort = "smtp.port"
+	// SMTPUsername is the username used to authenticate to the SMTP server, if it requires authentication
+	SMTPUsername = "smtp.username"
+	// SMTPPassword is the password used to authenticate to the SMTP server
+	SMTPPassword = "smtp.password"
+	// From is the sender address of the emails
+	From = "from"
+	// Domain is the domain used in the Message-ID of each email, which replies reference in their In-Reply-To header
+	Domain = "domain"
+)
+
+func (e *Email) InitPrefix(prefix config.Prefix) {
+	prefix.AddKnownKey(SMTPHost, "localhost")
+	prefix.AddKnownKey(SMTPPort, 587)
+	prefix.AddKnownKey(SMTPUsername)
+	prefix.AddKnownKey(SMTPPassword)
+	prefix.AddKnownKey(From)
+	prefix.AddKnownKey(Domain, "firefly.local")
+}
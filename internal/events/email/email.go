@@ -0,0 +1,211 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Email delivers the events matched by a subscription as formatted emails, through an SMTP server.
+// This allows counterparties without any integration to receive events during transition periods.
+type Email struct {
+	ctx          context.Context
+	capabilities *events.Capabilities
+	callbacks    events.Callbacks
+	connID       string
+	addr         string
+	auth         smtp.Auth
+	from         string
+	domain       string
+	sendMail     func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+type emailRequest struct {
+	to      []string
+	subject *template.Template
+}
+
+func (e *Email) Name() string { return "email" }
+
+func (e *Email) Init(ctx context.Context, prefix config.Prefix, callbacks events.Callbacks) (err error) {
+	host := prefix.GetString(SMTPHost)
+	*e = Email{
+		ctx:          ctx,
+		capabilities: &events.Capabilities{},
+		callbacks:    callbacks,
+		connID:       fftypes.ShortID(),
+		addr:         net.JoinHostPort(host, prefix.GetString(SMTPPort)),
+		from:         prefix.GetString(From),
+		domain:       prefix.GetString(Domain),
+		sendMail:     smtp.SendMail,
+	}
+	if e.from == "" {
+		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, prefix.Resolve(From), "email")
+	}
+	if username := prefix.GetString(SMTPUsername); username != "" {
+		e.auth = smtp.PlainAuth("", username, prefix.GetString(SMTPPassword), host)
+	}
+	// We have a single logical connection, that matches all subscriptions
+	return callbacks.RegisterConnection(e.connID, func(sr fftypes.SubscriptionRef) bool { return true })
+}
+
+func (e *Email) Capabilities() *events.Capabilities {
+	return e.capabilities
+}
+
+func (e *Email) GetOptionsSchema(ctx context.Context) string {
+	return fmt.Sprintf(`{
+		"properties": {
+			"to": {
+				"type": "string",
+				"description": "%s"
+			},
+			"subject": {
+				"type": "string",
+				"description": "%s"
+			}
+		}
+	}`,
+		i18n.Expand(ctx, i18n.MsgEmailOptTo),
+		i18n.Expand(ctx, i18n.MsgEmailOptSubject),
+	)
+}
+
+func (e *Email) buildRequest(options fftypes.JSONObject) (*emailRequest, error) {
+	req := &emailRequest{}
+	for _, to := range strings.Split(options.GetString("to"), ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			req.to = append(req.to, to)
+		}
+	}
+	if len(req.to) == 0 {
+		return nil, i18n.NewError(e.ctx, i18n.MsgEmailToEmpty)
+	}
+	subject := options.GetString("subject")
+	if subject == "" {
+		subject = defaultSubject
+	}
+	var err error
+	if req.subject, err = template.New("subject").Parse(subject); err != nil {
+		return nil, i18n.WrapError(e.ctx, err, i18n.MsgEmailSubjectInvalid)
+	}
+	return req, nil
+}
+
+func (e *Email) ValidateOptions(options *fftypes.SubscriptionOptions) error {
+	if options.WithData == nil {
+		defaultTrue := true
+		options.WithData = &defaultTrue
+	}
+	_, err := e.buildRequest(options.TransportOptions())
+	return err
+}
+
+// MessageID returns the Message-ID header for the email of an event. The reference of the event (the ID
+// of the message, for message events) comes first, followed by the event and the subscription, so that
+// both the message and the recipients of the email can be recovered from the In-Reply-To header of a
+// reply using ParseMessageID.
+func MessageID(domain string, event *fftypes.EventDelivery) string {
+	return fmt.Sprintf("<%s.%s.%s@%s>", event.Reference, event.ID, event.Subscription.ID, domain)
+}
+
+// ParseMessageID returns the event reference and subscription ID from a Message-ID generated by MessageID
+func ParseMessageID(messageID string) (reference, subscription string) {
+	messageID = strings.TrimSpace(messageID)
+	messageID = strings.TrimSuffix(strings.TrimPrefix(messageID, "<"), ">")
+	messageID = strings.SplitN(messageID, "@", 2)[0]
+	parts := strings.Split(messageID, ".")
+	if len(parts) == 3 {
+		subscription = parts[2]
+	}
+	return parts[0], subscription
+}
+
+func (e *Email) formatEmail(req *emailRequest, event *fftypes.EventDelivery, data fftypes.DataArray) ([]byte, error) {
+	subject := &strings.Builder{}
+	if err := req.subject.Execute(subject, event); err != nil {
+		return nil, i18n.WrapError(e.ctx, err, i18n.MsgEmailSubjectInvalid)
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "From: %s\r\n", e.from)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(req.to, ", "))
+	fmt.Fprintf(buf, "Subject: %s\r\n", strings.ReplaceAll(subject.String(), "\n", " "))
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "Message-ID: %s\r\n", MessageID(e.domain, event))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+
+	fmt.Fprintf(buf, "Event:     %s\r\n", event.Type)
+	fmt.Fprintf(buf, "ID:        %s\r\n", event.ID)
+	fmt.Fprintf(buf, "Sequence:  %d\r\n", event.Sequence)
+	fmt.Fprintf(buf, "Namespace: %s\r\n", event.Namespace)
+	fmt.Fprintf(buf, "Reference: %s\r\n", event.Reference)
+	fmt.Fprintf(buf, "Topic:     %s\r\n", event.Topic)
+	if msg := event.Message; msg != nil {
+		fmt.Fprintf(buf, "\r\nMessage:   %s\r\n", msg.Header.ID)
+		fmt.Fprintf(buf, "Author:    %s\r\n", msg.Header.Author)
+		fmt.Fprintf(buf, "Tag:       %s\r\n", msg.Header.Tag)
+		fmt.Fprintf(buf, "Topics:    %s\r\n", msg.Header.Topics)
+	}
+	for i, d := range data {
+		fmt.Fprintf(buf, "\r\nData %d:    %s\r\n", i, d.ID)
+		if d.Value != nil {
+			b, _ := json.MarshalIndent(d.Value, "", "  ")
+			buf.Write(bytes.ReplaceAll(b, []byte("\n"), []byte("\r\n")))
+			buf.WriteString("\r\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (e *Email) DeliveryRequest(connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
+	var sendErr error
+	req, err := e.buildRequest(sub.Options.TransportOptions())
+	var msg []byte
+	if err == nil {
+		msg, err = e.formatEmail(req, event, data)
+	}
+	if err != nil {
+		// The event cannot ever be formatted with these options, so we skip it rather than block the subscription
+		log.L(e.ctx).Errorf("Skipping email for event '%s': %s", event.ID, err)
+	} else if sendErr = e.sendMail(e.addr, e.auth, e.from, req.to, msg); sendErr != nil {
+		// Rejecting the event means it is redelivered, so an outage of the SMTP server does not lose emails
+		log.L(e.ctx).Errorf("Failed to send email for event '%s': %s", event.ID, sendErr)
+	}
+	e.callbacks.DeliveryResponse(connID, &fftypes.EventDeliveryResponse{
+		ID:           event.ID,
+		Rejected:     sendErr != nil,
+		Subscription: event.Subscription,
+	})
+	return nil
+}
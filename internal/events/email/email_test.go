@@ -0,0 +1,194 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package email
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var utConfPrefix = config.NewPluginConfig("ut.email")
+
+func newTestEmail(t *testing.T) (*Email, *eventsmocks.Callbacks) {
+	config.Reset()
+	e := &Email{}
+	e.InitPrefix(utConfPrefix)
+	utConfPrefix.Set(From, "firefly@example.com")
+	utConfPrefix.Set(SMTPUsername, "user")
+	utConfPrefix.Set(SMTPPassword, "pass")
+
+	cbs := &eventsmocks.Callbacks{}
+	rc := cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	rc.RunFn = func(a mock.Arguments) {
+		assert.Equal(t, true, a[1].(events.SubscriptionMatcher)(fftypes.SubscriptionRef{}))
+	}
+	err := e.Init(context.Background(), utConfPrefix, cbs)
+	assert.NoError(t, err)
+	assert.Equal(t, "email", e.Name())
+	assert.Equal(t, "localhost:587", e.addr)
+	assert.NotNil(t, e.auth)
+	assert.NotNil(t, e.Capabilities())
+	assert.NotNil(t, e.GetOptionsSchema(e.ctx))
+	return e, cbs
+}
+
+func newTestSubscription(options fftypes.JSONObject) *fftypes.Subscription {
+	sub := &fftypes.Subscription{}
+	for k, v := range options {
+		sub.Options.TransportOptions()[k] = v
+	}
+	return sub
+}
+
+func TestInitMissingFrom(t *testing.T) {
+	config.Reset()
+	e := &Email{}
+	e.InitPrefix(utConfPrefix)
+	err := e.Init(context.Background(), utConfPrefix, &eventsmocks.Callbacks{})
+	assert.Regexp(t, "FF10138.*from", err)
+}
+
+func TestValidateOptions(t *testing.T) {
+	e, _ := newTestEmail(t)
+
+	opts := &fftypes.SubscriptionOptions{}
+	opts.TransportOptions()["to"] = "a@example.com, b@example.com"
+	err := e.ValidateOptions(opts)
+	assert.NoError(t, err)
+	assert.True(t, *opts.WithData)
+
+	opts = &fftypes.SubscriptionOptions{}
+	opts.TransportOptions()["to"] = " , "
+	err = e.ValidateOptions(opts)
+	assert.Regexp(t, "FF10466", err)
+
+	opts = &fftypes.SubscriptionOptions{}
+	opts.TransportOptions()["to"] = "a@example.com"
+	opts.TransportOptions()["subject"] = "{{.Type"
+	err = e.ValidateOptions(opts)
+	assert.Regexp(t, "FF10467", err)
+}
+
+func TestMessageIDRoundTrip(t *testing.T) {
+	msgID := fftypes.NewUUID()
+	subID := fftypes.NewUUID()
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{ID: fftypes.NewUUID(), Reference: msgID},
+		},
+		Subscription: fftypes.SubscriptionRef{ID: subID},
+	}
+	reference, subscription := ParseMessageID(" " + MessageID("example.com", event) + " ")
+	assert.Equal(t, msgID.String(), reference)
+	assert.Equal(t, subID.String(), subscription)
+
+	reference, subscription = ParseMessageID("<ref@example.com>")
+	assert.Equal(t, "ref", reference)
+	assert.Empty(t, subscription)
+}
+
+func TestDeliveryRequestSent(t *testing.T) {
+	e, cbs := newTestEmail(t)
+
+	msgID := fftypes.NewUUID()
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID:        fftypes.NewUUID(),
+				Type:      fftypes.EventTypeMessageConfirmed,
+				Namespace: "ns1",
+				Reference: msgID,
+			},
+			Message: &fftypes.Message{
+				Header: fftypes.MessageHeader{ID: msgID, Tag: "order"},
+			},
+		},
+		Subscription: fftypes.SubscriptionRef{ID: fftypes.NewUUID()},
+	}
+	data := fftypes.DataArray{
+		{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`{"total":10}`)},
+		{ID: fftypes.NewUUID()},
+	}
+
+	var sent string
+	e.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Equal(t, "firefly@example.com", from)
+		assert.Equal(t, []string{"a@example.com"}, to)
+		sent = string(msg)
+		return nil
+	}
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(r *fftypes.EventDeliveryResponse) bool {
+		return r.ID.Equals(event.ID) && !r.Rejected
+	})).Return()
+
+	err := e.DeliveryRequest("conn1", newTestSubscription(fftypes.JSONObject{"to": "a@example.com"}), event, data)
+	assert.NoError(t, err)
+	assert.Contains(t, sent, "Subject: FireFly message_confirmed event in namespace ns1\r\n")
+	assert.Contains(t, sent, fmt.Sprintf("Message-ID: <%s.%s.%s@firefly.local>\r\n", msgID, event.ID, event.Subscription.ID))
+	assert.Contains(t, sent, "Tag:       order\r\n")
+	assert.Contains(t, sent, "\"total\": 10")
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDeliveryRequestSendFail(t *testing.T) {
+	e, cbs := newTestEmail(t)
+
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{ID: fftypes.NewUUID()},
+		},
+	}
+	e.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		return fmt.Errorf("pop")
+	}
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(r *fftypes.EventDeliveryResponse) bool {
+		return r.Rejected
+	})).Return()
+
+	err := e.DeliveryRequest("conn1", newTestSubscription(fftypes.JSONObject{"to": "a@example.com"}), event, nil)
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDeliveryRequestBadSubjectSkipped(t *testing.T) {
+	e, cbs := newTestEmail(t)
+
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{ID: fftypes.NewUUID()},
+		},
+	}
+	cbs.On("DeliveryResponse", "conn1", mock.MatchedBy(func(r *fftypes.EventDeliveryResponse) bool {
+		return !r.Rejected
+	})).Return()
+
+	err := e.DeliveryRequest("conn1", newTestSubscription(fftypes.JSONObject{"to": "a@example.com", "subject": "{{.Unknown}}"}), event, nil)
+	assert.NoError(t, err)
+
+	cbs.AssertExpectations(t)
+}
@@ -0,0 +1,67 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"plugin"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/events"
+)
+
+// enricherSymbol is the name of the variable a Go plugin exports, that implements events.Enricher
+const enricherSymbol = "Enricher"
+
+type symbolLookup interface {
+	Lookup(symName string) (plugin.Symbol, error)
+}
+
+var openPlugin = func(path string) (symbolLookup, error) {
+	return plugin.Open(path)
+}
+
+// loadEnrichers loads each of the Go plugins (built with "go build -buildmode=plugin") configured to enrich events
+func loadEnrichers(ctx context.Context, paths []string) ([]events.Enricher, error) {
+	enrichers := make([]events.Enricher, 0, len(paths))
+	for _, path := range paths {
+		p, err := openPlugin(path)
+		var sym plugin.Symbol
+		if err == nil {
+			sym, err = p.Lookup(enricherSymbol)
+		}
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgEnrichmentPluginLoadFailed, path, err)
+		}
+		// The symbol is a pointer to the exported variable, which might be declared as the
+		// interface, or as the type that implements it
+		var enricher events.Enricher
+		switch s := sym.(type) {
+		case *events.Enricher:
+			enricher = *s
+		case events.Enricher:
+			enricher = s
+		}
+		if enricher == nil {
+			return nil, i18n.NewError(ctx, i18n.MsgEnrichmentPluginInvalid, path)
+		}
+		log.L(ctx).Infof("Loaded event enrichment plugin '%s' from %s", enricher.Name(), path)
+		enrichers = append(enrichers, enricher)
+	}
+	return enrichers, nil
+}
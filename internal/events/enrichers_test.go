@@ -0,0 +1,95 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"plugin"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/events"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPlugin struct {
+	symbols map[string]plugin.Symbol
+}
+
+func (tp *testPlugin) Lookup(symName string) (plugin.Symbol, error) {
+	sym, ok := tp.symbols[symName]
+	if !ok {
+		return nil, fmt.Errorf("symbol %s not found", symName)
+	}
+	return sym, nil
+}
+
+func withTestPlugin(symbols map[string]plugin.Symbol) func() {
+	origOpen := openPlugin
+	openPlugin = func(path string) (symbolLookup, error) {
+		return &testPlugin{symbols: symbols}, nil
+	}
+	return func() { openPlugin = origOpen }
+}
+
+func TestLoadEnrichersNone(t *testing.T) {
+	enrichers, err := loadEnrichers(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, enrichers)
+}
+
+func TestLoadEnrichersOpenFail(t *testing.T) {
+	_, err := loadEnrichers(context.Background(), []string{"/missing/enricher.so"})
+	assert.Regexp(t, "FF10526.*/missing/enricher.so", err)
+}
+
+func TestLoadEnrichersExportedInterface(t *testing.T) {
+	men := &eventsmocks.Enricher{}
+	men.On("Name").Return("orders")
+	var exported events.Enricher = men
+	defer withTestPlugin(map[string]plugin.Symbol{"Enricher": &exported})()
+
+	enrichers, err := loadEnrichers(context.Background(), []string{"orders.so"})
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Enricher{men}, enrichers)
+}
+
+func TestLoadEnrichersExportedType(t *testing.T) {
+	men := &eventsmocks.Enricher{}
+	men.On("Name").Return("orders")
+	defer withTestPlugin(map[string]plugin.Symbol{"Enricher": men})()
+
+	enrichers, err := loadEnrichers(context.Background(), []string{"orders.so"})
+	assert.NoError(t, err)
+	assert.Equal(t, []events.Enricher{men}, enrichers)
+}
+
+func TestLoadEnrichersMissingSymbol(t *testing.T) {
+	defer withTestPlugin(map[string]plugin.Symbol{})()
+
+	_, err := loadEnrichers(context.Background(), []string{"orders.so"})
+	assert.Regexp(t, "FF10526.*symbol Enricher not found", err)
+}
+
+func TestLoadEnrichersWrongType(t *testing.T) {
+	notEnricher := "orders"
+	defer withTestPlugin(map[string]plugin.Symbol{"Enricher": &notEnricher})()
+
+	_, err := loadEnrichers(context.Background(), []string{"orders.so"})
+	assert.Regexp(t, "FF10527", err)
+}
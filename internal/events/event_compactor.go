@@ -0,0 +1,166 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// eventCompactor periodically removes the older events of high-churn types, retaining only the
+// latest event of each type for each reference. The retained event records the number of events
+// that were removed in its favor, so the count of events for each reference is preserved.
+//
+// Only events older than the horizon are compacted, so subscriptions that are further behind
+// than the horizon will not be delivered the removed events.
+type eventCompactor struct {
+	ctx       context.Context
+	database  database.Plugin
+	types     []driver.Value
+	horizon   time.Duration
+	interval  time.Duration
+	batchSize int
+	done      chan struct{}
+}
+
+type compactionKey struct {
+	namespace string
+	eventType fftypes.EventType
+	reference fftypes.UUID
+}
+
+func newEventCompactor(ctx context.Context, di database.Plugin) (*eventCompactor, error) {
+	types := config.GetStringSlice(config.EventCompactionTypes)
+	if len(types) == 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgCompactionNoTypes)
+	}
+	ec := &eventCompactor{
+		ctx:       log.WithLogField(ctx, "role", "event-compactor"),
+		database:  di,
+		types:     make([]driver.Value, len(types)),
+		horizon:   config.GetDuration(config.EventCompactionHorizon),
+		interval:  config.GetDuration(config.EventCompactionInterval),
+		batchSize: config.GetInt(config.EventCompactionBatchSize),
+		done:      make(chan struct{}),
+	}
+	for i, t := range types {
+		ec.types[i] = t
+	}
+	return ec, nil
+}
+
+func (ec *eventCompactor) start() {
+	go ec.compactLoop()
+}
+
+func (ec *eventCompactor) compactLoop() {
+	defer close(ec.done)
+	ticker := time.NewTicker(ec.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ec.ctx.Done():
+			log.L(ec.ctx).Debugf("Event compactor loop exiting")
+			return
+		case <-ticker.C:
+		}
+		if err := ec.compact(ec.ctx, time.Now()); err != nil {
+			log.L(ec.ctx).Errorf("Event compaction failed: %s", err)
+		}
+	}
+}
+
+// compact pages backwards through the events older than the horizon, so the first event seen for each
+// key is the latest one, and is the one that is retained
+func (ec *eventCompactor) compact(ctx context.Context, now time.Time) error {
+	horizon := fftypes.FFTime(now.Add(-ec.horizon))
+	retained := make(map[compactionKey]*fftypes.Event)
+	removed := 0
+	var lastSequence int64 = -1
+	for {
+		fb := database.EventQueryFactory.NewFilterLimit(ctx, uint64(ec.batchSize))
+		conditions := []database.Filter{
+			fb.In("type", ec.types),
+			fb.Lt("created", horizon),
+		}
+		if lastSequence >= 0 {
+			conditions = append(conditions, fb.Lt("sequence", lastSequence))
+		}
+		events, _, err := ec.database.GetEvents(ctx, fb.And(conditions...).Sort("sequence").Descending())
+		if err != nil {
+			return err
+		}
+		count, err := ec.compactPage(ctx, retained, events)
+		if err != nil {
+			return err
+		}
+		removed += count
+		if len(events) < ec.batchSize {
+			break
+		}
+		lastSequence = events[len(events)-1].Sequence
+	}
+	log.L(ctx).Infof("Event compaction removed %d events older than %s", removed, horizon.String())
+	return nil
+}
+
+func (ec *eventCompactor) compactPage(ctx context.Context, retained map[compactionKey]*fftypes.Event, events []*fftypes.Event) (int, error) {
+	var removed []*fftypes.UUID
+	var updated []*fftypes.Event
+	isUpdated := make(map[compactionKey]bool)
+	for _, event := range events {
+		if event.Reference == nil {
+			continue
+		}
+		key := compactionKey{
+			namespace: event.Namespace,
+			eventType: event.Type,
+			reference: *event.Reference,
+		}
+		latest, ok := retained[key]
+		if !ok {
+			retained[key] = event
+			continue
+		}
+		latest.Compacted += event.Compacted + 1
+		removed = append(removed, event.ID)
+		if !isUpdated[key] {
+			isUpdated[key] = true
+			updated = append(updated, latest)
+		}
+	}
+	if len(removed) == 0 {
+		return 0, nil
+	}
+	err := ec.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		for _, event := range updated {
+			update := database.EventQueryFactory.NewUpdate(ctx).Set("compacted", event.Compacted)
+			if err := ec.database.UpdateEvent(ctx, event.ID, update); err != nil {
+				return err
+			}
+		}
+		return ec.database.DeleteEvents(ctx, removed)
+	})
+	return len(removed), err
+}
@@ -0,0 +1,169 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestEventCompactor(t *testing.T) (*eventCompactor, *databasemocks.Plugin, func()) {
+	config.Reset()
+	config.Set(config.EventCompactionTypes, []string{string(fftypes.EventTypeTransactionSubmitted)})
+	config.Set(config.EventCompactionBatchSize, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	mdi := &databasemocks.Plugin{}
+	ec, err := newEventCompactor(ctx, mdi)
+	assert.NoError(t, err)
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything).Maybe()
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{a[1].(func(context.Context) error)(a[0].(context.Context))}
+	}
+	return ec, mdi, func() {
+		cancel()
+		mdi.AssertExpectations(t)
+	}
+}
+
+func compactionTestEvent(seq int64, ref *fftypes.UUID, compacted int64) *fftypes.Event {
+	return &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Sequence:  seq,
+		Type:      fftypes.EventTypeTransactionSubmitted,
+		Namespace: "ns1",
+		Reference: ref,
+		Compacted: compacted,
+	}
+}
+
+func TestCompactRetainsLatestPerReference(t *testing.T) {
+	ec, mdi, cancel := newTestEventCompactor(t)
+	defer cancel()
+
+	ref1 := fftypes.NewUUID()
+	ref2 := fftypes.NewUUID()
+	// Pages are read latest first
+	page1 := []*fftypes.Event{
+		compactionTestEvent(10, ref1, 0),
+		compactionTestEvent(9, ref2, 2),
+		compactionTestEvent(8, ref1, 0),
+	}
+	page2 := []*fftypes.Event{
+		compactionTestEvent(7, ref1, 3),
+		compactionTestEvent(6, nil, 0),
+	}
+
+	mdi.On("GetEvents", mock.Anything, mock.MatchedBy(func(filter database.Filter) bool {
+		f, _ := filter.Finalize()
+		return len(f.Children) == 2
+	})).Return(page1, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, mock.MatchedBy(func(filter database.Filter) bool {
+		f, _ := filter.Finalize()
+		return len(f.Children) == 3 && strings.Contains(f.String(), "( sequence << 8 )")
+	})).Return(page2, nil, nil).Once()
+	mdi.On("UpdateEvent", mock.Anything, page1[0].ID, mock.MatchedBy(func(update database.Update) bool {
+		u, _ := update.Finalize()
+		return u.String() == "compacted=1"
+	})).Return(nil).Once()
+	mdi.On("DeleteEvents", mock.Anything, []*fftypes.UUID{page1[2].ID}).Return(nil).Once()
+	mdi.On("UpdateEvent", mock.Anything, page1[0].ID, mock.MatchedBy(func(update database.Update) bool {
+		u, _ := update.Finalize()
+		return u.String() == "compacted=5"
+	})).Return(nil).Once()
+	mdi.On("DeleteEvents", mock.Anything, []*fftypes.UUID{page2[0].ID}).Return(nil).Once()
+
+	err := ec.compact(ec.ctx, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), page1[0].Compacted)
+	assert.Equal(t, int64(2), page1[1].Compacted)
+}
+
+func TestCompactNothingToRemove(t *testing.T) {
+	ec, mdi, cancel := newTestEventCompactor(t)
+	defer cancel()
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{
+		compactionTestEvent(1, fftypes.NewUUID(), 0),
+	}, nil, nil).Once()
+
+	err := ec.compact(ec.ctx, time.Now())
+	assert.NoError(t, err)
+}
+
+func TestCompactGetEventsFail(t *testing.T) {
+	ec, mdi, cancel := newTestEventCompactor(t)
+	defer cancel()
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := ec.compact(ec.ctx, time.Now())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCompactUpdateFail(t *testing.T) {
+	ec, mdi, cancel := newTestEventCompactor(t)
+	defer cancel()
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{
+		compactionTestEvent(2, ref1, 0),
+		compactionTestEvent(1, ref1, 0),
+	}, nil, nil).Once()
+	mdi.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := ec.compact(ec.ctx, time.Now())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCompactDeleteFail(t *testing.T) {
+	ec, mdi, cancel := newTestEventCompactor(t)
+	defer cancel()
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{
+		compactionTestEvent(2, ref1, 0),
+		compactionTestEvent(1, ref1, 0),
+	}, nil, nil).Once()
+	mdi.On("UpdateEvent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mdi.On("DeleteEvents", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := ec.compact(ec.ctx, time.Now())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCompactLoopLogsErrors(t *testing.T) {
+	ec, mdi, cancel := newTestEventCompactor(t)
+	defer cancel()
+	ec.interval = 1 * time.Millisecond
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		cancel()
+	})
+
+	ec.start()
+	<-ec.done
+}
@@ -19,6 +19,7 @@ package events
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/hyperledger/firefly/internal/config"
@@ -56,17 +57,26 @@ type eventDispatcher struct {
 	elected       bool
 	eventPoller   *eventPoller
 	inflight      map[fftypes.UUID]*fftypes.Event
+	queued        []*fftypes.EventDelivery
+	prioritized   bool
 	eventDelivery chan *fftypes.EventDelivery
 	mux           sync.Mutex
 	namespace     string
 	readAhead     int
 	subscription  *subscription
 	cel           *changeEventListener
+	taps          *subscriptionTaps
 	changeEvents  chan *fftypes.ChangeEvent
 	txHelper      txcommon.Helper
+	enrichers     []events.Enricher
+	pool          *deliveryPool
+	rewindTo      *int64
+	rewound       chan struct{}
+	// deliveryScheduled is protected by the pool's lock
+	deliveryScheduled bool
 }
 
-func newEventDispatcher(ctx context.Context, ei events.Plugin, di database.Plugin, dm data.Manager, sh definitions.DefinitionHandlers, connID string, sub *subscription, en *eventNotifier, cel *changeEventListener, txHelper txcommon.Helper) *eventDispatcher {
+func newEventDispatcher(ctx context.Context, ei events.Plugin, di database.Plugin, dm data.Manager, sh definitions.DefinitionHandlers, connID string, sub *subscription, en *eventNotifier, cel *changeEventListener, taps *subscriptionTaps, txHelper txcommon.Helper, enrichers []events.Enricher, pool *deliveryPool) *eventDispatcher {
 	ctx, cancelCtx := context.WithCancel(ctx)
 	readAhead := config.GetUint(config.SubscriptionDefaultsReadAhead)
 	if sub.definition.Options.ReadAhead != nil {
@@ -88,13 +98,18 @@ func newEventDispatcher(ctx context.Context, ei events.Plugin, di database.Plugi
 		subscription:  sub,
 		namespace:     sub.definition.Namespace,
 		inflight:      make(map[fftypes.UUID]*fftypes.Event),
+		prioritized:   sub.definition.Options.Ordering != nil && *sub.definition.Options.Ordering == fftypes.SubOptsOrderingPriority,
 		eventDelivery: make(chan *fftypes.EventDelivery, readAhead+1),
 		changeEvents:  make(chan *fftypes.ChangeEvent),
 		readAhead:     int(readAhead),
 		acksNacks:     make(chan ackNack),
 		closed:        make(chan struct{}),
 		cel:           cel,
+		taps:          taps,
 		txHelper:      txHelper,
+		enrichers:     enrichers,
+		pool:          pool,
+		rewound:       make(chan struct{}, 1),
 	}
 
 	pollerConf := &eventPollerConf{
@@ -102,20 +117,19 @@ func newEventDispatcher(ctx context.Context, ei events.Plugin, di database.Plugi
 		eventBatchTimeout:          config.GetDuration(config.EventDispatcherBatchTimeout),
 		eventPollTimeout:           config.GetDuration(config.EventDispatcherPollTimeout),
 		startupOffsetRetryAttempts: 0, // We need to keep trying to start indefinitely
-		retry: retry.Retry{
-			InitialDelay: config.GetDuration(config.EventDispatcherRetryInitDelay),
-			MaximumDelay: config.GetDuration(config.EventDispatcherRetryMaxDelay),
-			Factor:       config.GetFloat64(config.EventDispatcherRetryFactor),
-		},
-		namespace:  sub.definition.Namespace,
-		offsetType: fftypes.OffsetTypeSubscription,
-		offsetName: sub.definition.ID.String(),
+		offsetCommitCount:          config.GetInt(config.EventDispatcherOffsetCommitCount),
+		offsetCommitInterval:       config.GetDuration(config.EventDispatcherOffsetCommitInterval),
+		retry:                      *retry.NewFromConfig(config.EventDispatcherRetryInitDelay, config.EventDispatcherRetryMaxDelay, config.EventDispatcherRetryFactor),
+		namespace:                  sub.definition.Namespace,
+		offsetType:                 fftypes.OffsetTypeSubscription,
+		offsetName:                 sub.definition.ID.String(),
 		addCriteria: func(af database.AndFilter) database.AndFilter {
-			return af.Condition(af.Builder().Eq("namespace", sub.definition.Namespace))
+			return addNamespaceCriteria(af, sub.definition.Namespace, &sub.definition.Filter)
 		},
 		queryFactory:     database.EventQueryFactory,
 		getItems:         ed.getEvents,
 		newEventsHandler: ed.bufferedDelivery,
+		maybeRewind:      ed.pendingRewind,
 		ephemeral:        sub.definition.Ephemeral,
 		firstEvent:       sub.definition.Options.FirstEvent,
 	}
@@ -144,9 +158,17 @@ func (ed *eventDispatcher) electAndStart() {
 		return
 	}
 	// We're ready to go - not
+	ed.mux.Lock()
 	ed.elected = true
+	ed.mux.Unlock()
+	if ed.pool != nil && ed.changeEventsEnabled() {
+		// Change events are pushed to the dispatcher as they happen, so it keeps its own delivery goroutine
+		ed.pool = nil
+	}
 	ed.eventPoller.start()
-	go ed.deliverEvents()
+	if ed.pool == nil {
+		go ed.deliverEvents()
+	}
 	// Wait until the event poller closes
 	<-ed.eventPoller.closed
 }
@@ -161,6 +183,7 @@ func (ed *eventDispatcher) getEvents(ctx context.Context, filter database.Filter
 }
 
 func (ed *eventDispatcher) enrichEvents(events []fftypes.LocallySequenced) ([]*fftypes.EventDelivery, error) {
+	withData := ed.subscription.definition.Options.WithData != nil && *ed.subscription.definition.Options.WithData
 	enriched := make([]*fftypes.EventDelivery, len(events))
 	for i, ls := range events {
 		e := ls.(*fftypes.Event)
@@ -172,86 +195,153 @@ func (ed *eventDispatcher) enrichEvents(events []fftypes.LocallySequenced) ([]*f
 			EnrichedEvent: *enrichedEvent,
 			Subscription:  ed.subscription.definition.SubscriptionRef,
 		}
+		// Inline the full data values, so the application does not need to query them separately
+		if withData && enrichedEvent.Message != nil {
+			if enriched[i].Data, _, err = ed.data.GetMessageDataCached(ed.ctx, enrichedEvent.Message); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return enriched, nil
 }
 
+// applyEnrichers invokes the enrichment plugins on each event that is to be delivered.
+// A plugin that fails is logged and skipped, so that it cannot block delivery of the event.
+func (ed *eventDispatcher) applyEnrichers(matching []*fftypes.EventDelivery) error {
+	if len(ed.enrichers) == 0 {
+		return nil
+	}
+	for _, event := range matching {
+		data := event.Data
+		if data == nil && event.Message != nil {
+			var err error
+			if data, _, err = ed.data.GetMessageDataCached(ed.ctx, event.Message); err != nil {
+				return err
+			}
+		}
+		for _, enricher := range ed.enrichers {
+			fields, err := enricher.Enrich(ed.ctx, event, data)
+			if err != nil {
+				log.L(ed.ctx).Warnf("Enrichment plugin '%s' failed for event %s: %s", enricher.Name(), event.ID, err)
+				continue
+			}
+			if fields != nil {
+				if event.Enrichments == nil {
+					event.Enrichments = fftypes.JSONObject{}
+				}
+				event.Enrichments[enricher.Name()] = fields
+			}
+		}
+	}
+	return nil
+}
+
 func (ed *eventDispatcher) filterEvents(candidates []*fftypes.EventDelivery) []*fftypes.EventDelivery {
 	matchingEvents := make([]*fftypes.EventDelivery, 0, len(candidates))
 	for _, event := range candidates {
-		filter := ed.subscription
-		if filter.eventMatcher != nil && !filter.eventMatcher.MatchString(string(event.Type)) {
+		if mismatch := ed.subscription.filterMismatch(&event.EnrichedEvent); mismatch != "" {
+			ed.tap(&event.Event, fftypes.TapDecisionFiltered, mismatch)
 			continue
 		}
+		ed.tap(&event.Event, fftypes.TapDecisionMatched, "")
+		matchingEvents = append(matchingEvents, event)
+	}
+	return matchingEvents
+}
 
-		msg := event.Message
-		tx := event.Transaction
-		be := event.BlockchainEvent
-		tag := ""
-		topic := event.Topic
-		group := ""
-		author := ""
-		txType := ""
-		beName := ""
-		beListener := ""
-
-		if msg != nil {
-			tag = msg.Header.Tag
-			author = msg.Header.Author
-			if msg.Header.Group != nil {
-				group = msg.Header.Group.String()
-			}
-		}
+// filterMismatch returns the name of the first filter of the subscription the event does not match,
+// or an empty string if the event matches
+func (sub *subscription) filterMismatch(event *fftypes.EnrichedEvent) string {
+	if sub.eventMatcher != nil && !sub.eventMatcher.MatchString(string(event.Type)) {
+		return "events"
+	}
 
-		if tx != nil {
-			txType = tx.Type.String()
+	msg := event.Message
+	tx := event.Transaction
+	be := event.BlockchainEvent
+	tag := ""
+	topic := event.Topic
+	group := ""
+	author := ""
+	txType := ""
+	beName := ""
+	beListener := ""
+
+	if msg != nil {
+		tag = msg.Header.Tag
+		author = msg.Header.Author
+		if msg.Header.Group != nil {
+			group = msg.Header.Group.String()
 		}
+	}
 
-		if be != nil {
-			beName = be.Name
-			beListener = be.Listener.String()
-		}
+	if tx != nil {
+		txType = tx.Type.String()
+	}
 
-		if filter.topicFilter != nil {
-			topicsMatch := false
-			if filter.topicFilter.MatchString(topic) {
-				topicsMatch = true
-			}
-			if !topicsMatch {
-				continue
-			}
-		}
+	if be != nil {
+		beName = be.Name
+		beListener = be.Listener.String()
+	}
 
-		if filter.messageFilter != nil {
-			if filter.messageFilter.tagFilter != nil && !filter.messageFilter.tagFilter.MatchString(tag) {
-				continue
-			}
-			if filter.messageFilter.authorFilter != nil && !filter.messageFilter.authorFilter.MatchString(author) {
-				continue
-			}
-			if filter.messageFilter.groupFilter != nil && !filter.messageFilter.groupFilter.MatchString(group) {
-				continue
-			}
+	if sub.topicFilter != nil && !sub.topicFilter.MatchString(topic) {
+		return "topic"
+	}
+
+	if sub.topicPattern != nil && !sub.topicPattern.Matches(topic) {
+		return "topicPattern"
+	}
+
+	if sub.messageFilter != nil {
+		if sub.messageFilter.tagFilter != nil && !sub.messageFilter.tagFilter.MatchString(tag) {
+			return "message.tag"
 		}
+		if sub.messageFilter.authorFilter != nil && !sub.messageFilter.authorFilter.MatchString(author) {
+			return "message.author"
+		}
+		if sub.messageFilter.groupFilter != nil && !sub.messageFilter.groupFilter.MatchString(group) {
+			return "message.group"
+		}
+	}
 
-		if filter.transactionFilter != nil {
-			if filter.transactionFilter.typeFilter != nil && !filter.transactionFilter.typeFilter.MatchString(txType) {
-				continue
-			}
+	if sub.transactionFilter != nil {
+		if sub.transactionFilter.typeFilter != nil && !sub.transactionFilter.typeFilter.MatchString(txType) {
+			return "transaction.type"
 		}
+	}
 
-		if filter.blockchainFilter != nil {
-			if filter.blockchainFilter.nameFilter != nil && !filter.blockchainFilter.nameFilter.MatchString(beName) {
-				continue
-			}
-			if filter.blockchainFilter.listenerFilter != nil && !filter.blockchainFilter.listenerFilter.MatchString(beListener) {
-				continue
-			}
+	if sub.blockchainFilter != nil {
+		if sub.blockchainFilter.nameFilter != nil && !sub.blockchainFilter.nameFilter.MatchString(beName) {
+			return "blockchainevent.name"
+		}
+		if sub.blockchainFilter.listenerFilter != nil && !sub.blockchainFilter.listenerFilter.MatchString(beListener) {
+			return "blockchainevent.listener"
 		}
+	}
 
-		matchingEvents = append(matchingEvents, event)
+	return ""
+}
+
+// prioritize orders a page of events by the priority of their messages (highest first), then by sequence.
+// Events that do not relate to a message have the default priority of zero.
+func prioritize(events []*fftypes.EventDelivery) {
+	priority := func(event *fftypes.EventDelivery) int64 {
+		if event.Message != nil {
+			return event.Message.Header.Priority
+		}
+		return 0
 	}
-	return matchingEvents
+	sort.Slice(events, func(i, j int) bool {
+		pi, pj := priority(events[i]), priority(events[j])
+		if pi != pj {
+			return pi > pj
+		}
+		return events[i].Sequence < events[j].Sequence
+	})
+}
+
+func (ed *eventDispatcher) tap(event *fftypes.Event, decision fftypes.TapDecision, info string) {
+	ed.taps.record(ed.subscription.definition.ID, ed.connID, event, decision, info)
 }
 
 func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (bool, error) {
@@ -273,8 +363,15 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 	}
 
 	matching := ed.filterEvents(candidates)
+	if err := ed.applyEnrichers(matching); err != nil {
+		return false, err
+	}
+	if ed.prioritized {
+		prioritize(matching)
+	}
 	matchCount := len(matching)
 	dispatched := 0
+	heldReported := false
 
 	// We stay here blocked until we've consumed all the messages in the buffer,
 	// or a reset event happens
@@ -290,6 +387,7 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 			disapatchable = matching[0:maxDispatch]
 			matching = matching[maxDispatch:]
 		}
+		ed.queued = matching
 		ed.mux.Unlock()
 
 		l.Debugf("Dispatcher event state: readahead=%d candidates=%d matched=%d inflight=%d queued=%d dispatched=%d dispatchable=%d lastAck=%d nacks=%d highest=%d",
@@ -302,8 +400,21 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 			ed.mux.Unlock()
 
 			dispatched++
+			ed.tap(&event.Event, fftypes.TapDecisionDispatched, "")
 			ed.eventDelivery <- event
 		}
+		if ed.pool != nil && len(disapatchable) > 0 {
+			ed.pool.schedule(ed)
+		}
+
+		// Events that have to wait for in-flight events to be acknowledged only get reported once,
+		// as the set held back only shrinks while we are processing this page
+		if !heldReported && len(matching) > 0 {
+			heldReported = true
+			for _, event := range matching {
+				ed.tap(&event.Event, fftypes.TapDecisionHeld, fmt.Sprintf("readahead=%d inflight=%d", ed.readAhead, inflightCount))
+			}
+		}
 
 		if inflightCount == 0 {
 			// We've cleared the decks. Time to look for more messages
@@ -322,6 +433,12 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 				ed.handleAckOffsetUpdate(an)
 				lastAck = an.offset
 			}
+		case <-ed.rewound:
+			if ed.abandonForRewind() {
+				// Treated like a nack, so no acknowledgement for this page can commit the offset
+				nacks++
+				matching = nil
+			}
 		}
 	}
 	if nacks == 0 && lastAck != highestOffset {
@@ -330,6 +447,49 @@ func (ed *eventDispatcher) bufferedDelivery(events []fftypes.LocallySequenced) (
 	return true, nil // poll again straight away for more messages
 }
 
+// rewind asks an elected dispatcher to deliver the events after the offset again. The page being delivered
+// is abandoned, and the poller applies the rewind again before it reads the next page, in case any
+// acknowledgement for the abandoned page moved the offset forwards in the meantime.
+func (ed *eventDispatcher) rewind(offset int64) bool {
+	ed.mux.Lock()
+	if !ed.elected {
+		ed.mux.Unlock()
+		return false
+	}
+	ed.rewindTo = &offset
+	ed.mux.Unlock()
+	// Also rewind straight away, so an offset flushed as the dispatcher closes does not lose the rewind
+	ed.eventPoller.rewindPollingOffset(offset)
+	select {
+	case ed.rewound <- struct{}{}:
+	default:
+	}
+	ed.eventPoller.shoulderTap()
+	return true
+}
+
+func (ed *eventDispatcher) pendingRewind() (bool, int64) {
+	ed.mux.Lock()
+	defer ed.mux.Unlock()
+	if ed.rewindTo == nil {
+		return false, -1
+	}
+	offset := *ed.rewindTo
+	ed.rewindTo = nil
+	return true, offset
+}
+
+func (ed *eventDispatcher) abandonForRewind() bool {
+	ed.mux.Lock()
+	defer ed.mux.Unlock()
+	if ed.rewindTo == nil {
+		// The poller has already applied the rewind
+		return false
+	}
+	ed.inflight = map[fftypes.UUID]*fftypes.Event{}
+	return true
+}
+
 func (ed *eventDispatcher) handleNackOffsetUpdate(nack ackNack) {
 	ed.mux.Lock()
 	defer ed.mux.Unlock()
@@ -346,6 +506,11 @@ func (ed *eventDispatcher) handleNackOffsetUpdate(nack ackNack) {
 func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack) {
 	oldOffset := ed.eventPoller.getPollingOffset()
 	ed.mux.Lock()
+	if _, ok := ed.inflight[ack.id]; !ok {
+		// The event was in flight when the ack arrived, but has since been abandoned by a rewind
+		ed.mux.Unlock()
+		return
+	}
 	delete(ed.inflight, ack.id)
 	lowestInflight := int64(-1)
 	for _, inflight := range ed.inflight {
@@ -353,6 +518,12 @@ func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack) {
 			lowestInflight = inflight.Sequence
 		}
 	}
+	// When events are prioritized, queued events can be earlier in the sequence than those in-flight
+	for _, queued := range ed.queued {
+		if lowestInflight < 0 || queued.Sequence < lowestInflight {
+			lowestInflight = queued.Sequence
+		}
+	}
 	ed.mux.Unlock()
 	if (lowestInflight == -1 || lowestInflight > ack.offset) && ack.offset > oldOffset {
 		// This was the lowest in flight, and we can move the offset forwards
@@ -360,6 +531,33 @@ func (ed *eventDispatcher) handleAckOffsetUpdate(ack ackNack) {
 	}
 }
 
+// batchDeliveryResponse responds to each event in flight up to a sequence, in sequence order, so the offset moves
+// forwards exactly as it would for individual responses
+func (ed *eventDispatcher) batchDeliveryResponse(response *fftypes.EventDeliveryResponse) {
+	ed.mux.Lock()
+	var events []*fftypes.Event
+	for _, event := range ed.inflight {
+		if event.Sequence <= response.UpToSequence {
+			events = append(events, event)
+		}
+	}
+	ed.mux.Unlock()
+
+	if len(events) == 0 {
+		log.L(ed.ctx).Warnf("Response for events up to %d, but none are in flight", response.UpToSequence)
+		return
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Sequence < events[j].Sequence })
+	for _, event := range events {
+		ed.deliveryResponse(&fftypes.EventDeliveryResponse{
+			ID:           event.ID,
+			Rejected:     response.Rejected,
+			Info:         response.Info,
+			Subscription: response.Subscription,
+		})
+	}
+}
+
 func (ed *eventDispatcher) dispatchChangeEvent(ce *fftypes.ChangeEvent) {
 	select {
 	case ed.changeEvents <- ce:
@@ -370,30 +568,46 @@ func (ed *eventDispatcher) dispatchChangeEvent(ce *fftypes.ChangeEvent) {
 	}
 }
 
+func (ed *eventDispatcher) changeEventsEnabled() bool {
+	return ed.transport.Capabilities().ChangeEvents && ed.subscription.definition.Options.ChangeEvents
+}
+
+func (ed *eventDispatcher) deliver(event *fftypes.EventDelivery) {
+	log.L(ed.ctx).Debugf("Dispatching %s event: %.10d/%s [%s]: ref=%s/%s", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference)
+	err := ed.transport.DeliveryRequest(ed.connID, ed.subscription.definition, event, event.Data)
+	if err != nil {
+		ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: event.ID, Rejected: true})
+	}
+}
+
+// deliverQueued is called on a worker from the delivery pool, and delivers up to the limit of the events
+// that are waiting, without blocking
+func (ed *eventDispatcher) deliverQueued(limit int) {
+	for i := 0; i < limit && ed.ctx.Err() == nil; i++ {
+		select {
+		case event, ok := <-ed.eventDelivery:
+			if !ok {
+				return
+			}
+			ed.deliver(event)
+		default:
+			return
+		}
+	}
+}
+
 func (ed *eventDispatcher) deliverEvents() {
-	if ed.transport.Capabilities().ChangeEvents && ed.subscription.definition.Options.ChangeEvents {
+	if ed.changeEventsEnabled() {
 		ed.cel.addDispatcher(*ed.subscription.definition.ID, ed)
 		defer ed.cel.removeDispatcher(*ed.subscription.definition.ID)
 	}
-	withData := ed.subscription.definition.Options.WithData != nil && *ed.subscription.definition.Options.WithData
 	for {
 		select {
 		case event, ok := <-ed.eventDelivery:
 			if !ok {
 				return
 			}
-			log.L(ed.ctx).Debugf("Dispatching %s event: %.10d/%s [%s]: ref=%s/%s", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference)
-			var data []*fftypes.Data
-			var err error
-			if withData && event.Message != nil {
-				data, _, err = ed.data.GetMessageDataCached(ed.ctx, event.Message)
-			}
-			if err == nil {
-				err = ed.transport.DeliveryRequest(ed.connID, ed.subscription.definition, event, data)
-			}
-			if err != nil {
-				ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: event.ID, Rejected: true})
-			}
+			ed.deliver(event)
 		case changeEvent := <-ed.changeEvents:
 			ws, ok := ed.transport.(events.ChangeEventListener)
 			if !ok {
@@ -406,9 +620,27 @@ func (ed *eventDispatcher) deliverEvents() {
 		}
 	}
 }
+
+func (ed *eventDispatcher) queueDepth() *fftypes.DispatcherQueueDepth {
+	ed.mux.Lock()
+	defer ed.mux.Unlock()
+	return &fftypes.DispatcherQueueDepth{
+		Subscription: ed.subscription.definition.SubscriptionRef,
+		Connection:   ed.connID,
+		Transport:    ed.transport.Name(),
+		Inflight:     len(ed.inflight),
+		ReadAhead:    ed.readAhead,
+	}
+}
+
 func (ed *eventDispatcher) deliveryResponse(response *fftypes.EventDeliveryResponse) {
 	l := log.L(ed.ctx)
 
+	if response.UpToSequence > 0 {
+		ed.batchDeliveryResponse(response)
+		return
+	}
+
 	ed.mux.Lock()
 	var an ackNack
 	event, found := ed.inflight[*response.ID]
@@ -432,6 +664,11 @@ func (ed *eventDispatcher) deliveryResponse(response *fftypes.EventDeliveryRespo
 	}
 
 	l.Debugf("Response for %s event: %.10d/%s [%s]: ref=%s/%s rejected=%t info='%s'", ed.transport.Name(), event.Sequence, event.ID, event.Type, event.Namespace, event.Reference, response.Rejected, response.Info)
+	if response.Rejected {
+		ed.tap(event, fftypes.TapDecisionRejected, response.Info)
+	} else {
+		ed.tap(event, fftypes.TapDecisionAcked, response.Info)
+	}
 	// We don't do any meaningful work in this call, we just set things up so the right thing
 	// will happen when the poller wakes up. So we need to pass it over
 	select {
@@ -446,8 +683,11 @@ func (ed *eventDispatcher) close() {
 	log.L(ed.ctx).Infof("Dispatcher closing for conn=%s subscription=%s", ed.connID, ed.subscription.definition.ID)
 	ed.cancelCtx()
 	<-ed.closed
-	if ed.elected {
+	ed.mux.Lock()
+	elected := ed.elected
+	ed.elected = false
+	ed.mux.Unlock()
+	if elected {
 		close(ed.eventDelivery)
-		ed.elected = false
 	}
 }
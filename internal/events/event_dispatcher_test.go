@@ -45,7 +45,7 @@ func newTestEventDispatcher(sub *subscription) (*eventDispatcher, func()) {
 	msh := &definitionsmocks.DefinitionHandlers{}
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	ctx, cancel := context.WithCancel(context.Background())
-	return newEventDispatcher(ctx, mei, mdi, mdm, msh, fftypes.NewUUID().String(), sub, newEventNotifier(ctx, "ut"), newChangeEventListener(ctx), txHelper), func() {
+	return newEventDispatcher(ctx, mei, mdi, mdm, msh, fftypes.NewUUID().String(), sub, newEventNotifier(ctx, "ut"), newChangeEventListener(ctx), newSubscriptionTaps(), txHelper, nil, nil), func() {
 		cancel()
 		config.Reset()
 	}
@@ -181,15 +181,19 @@ func TestEventDispatcherReadAheadOutOfOrderAcks(t *testing.T) {
 	// Setup enrichment
 	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(&fftypes.Message{
 		Header: fftypes.MessageHeader{ID: ref1},
+		State:  fftypes.MessageStateConfirmed,
 	}, nil, true, nil)
 	mdm.On("GetMessageWithDataCached", mock.Anything, ref2).Return(&fftypes.Message{
 		Header: fftypes.MessageHeader{ID: ref2},
+		State:  fftypes.MessageStateRejected,
 	}, nil, true, nil)
 	mdm.On("GetMessageWithDataCached", mock.Anything, ref3).Return(&fftypes.Message{
 		Header: fftypes.MessageHeader{ID: ref3},
+		State:  fftypes.MessageStateConfirmed,
 	}, nil, true, nil)
 	mdm.On("GetMessageWithDataCached", mock.Anything, ref4).Return(&fftypes.Message{
 		Header: fftypes.MessageHeader{ID: ref4},
+		State:  fftypes.MessageStateConfirmed,
 	}, nil, true, nil)
 
 	// Deliver a batch of messages
@@ -238,6 +242,70 @@ func TestEventDispatcherReadAheadOutOfOrderAcks(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestEventDispatcherReadAheadBatchAck(t *testing.T) {
+	var five = uint16(5)
+	subID := fftypes.NewUUID()
+	sub := &subscription{
+		dispatcherElection: make(chan bool, 1),
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: subID, Namespace: "ns1", Name: "sub1"},
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					ReadAhead: &five,
+				},
+			},
+		},
+		eventMatcher: regexp.MustCompile(fmt.Sprintf("^%s$", fftypes.EventTypeMessageConfirmed)),
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+	ed.eventPoller.offsetCommitted = make(chan int64, 3)
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	mdm := ed.data.(*datamocks.Manager)
+
+	eventDeliveries := make(chan *fftypes.EventDelivery)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock.RunFn = func(a mock.Arguments) {
+		eventDeliveries <- a.Get(2).(*fftypes.EventDelivery)
+	}
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(&fftypes.Message{
+		State: fftypes.MessageStateConfirmed,
+	}, nil, true, nil)
+
+	batch1Done := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{
+			&fftypes.Event{ID: fftypes.NewUUID(), Sequence: 10000001, Reference: fftypes.NewUUID(), Type: fftypes.EventTypeMessageConfirmed},
+			&fftypes.Event{ID: fftypes.NewUUID(), Sequence: 10000002, Reference: fftypes.NewUUID(), Type: fftypes.EventTypeMessageConfirmed},
+			&fftypes.Event{ID: fftypes.NewUUID(), Sequence: 10000003, Reference: fftypes.NewUUID(), Type: fftypes.EventTypeMessageConfirmed},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(batch1Done)
+	}()
+
+	<-eventDeliveries
+	event2 := <-eventDeliveries
+	event3 := <-eventDeliveries
+
+	// One response acknowledges the first two events
+	go func() {
+		ed.deliveryResponse(&fftypes.EventDeliveryResponse{UpToSequence: event2.Sequence})
+		ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: event3.ID})
+	}()
+
+	assert.Equal(t, int64(10000001), <-ed.eventPoller.offsetCommitted)
+	assert.Equal(t, int64(10000002), <-ed.eventPoller.offsetCommitted)
+	assert.Equal(t, int64(10000003), <-ed.eventPoller.offsetCommitted)
+
+	<-batch1Done
+
+	mei.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
 func TestEventDispatcherNoReadAheadInOrder(t *testing.T) {
 	log.SetLevel("debug")
 	sub := &subscription{
@@ -276,15 +344,19 @@ func TestEventDispatcherNoReadAheadInOrder(t *testing.T) {
 	// Setup enrichment
 	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(&fftypes.Message{
 		Header: fftypes.MessageHeader{ID: ref1},
+		State:  fftypes.MessageStateConfirmed,
 	}, nil, true, nil)
 	mdm.On("GetMessageWithDataCached", mock.Anything, ref2).Return(&fftypes.Message{
 		Header: fftypes.MessageHeader{ID: ref2},
+		State:  fftypes.MessageStateConfirmed,
 	}, nil, true, nil)
 	mdm.On("GetMessageWithDataCached", mock.Anything, ref3).Return(&fftypes.Message{
 		Header: fftypes.MessageHeader{ID: ref3},
+		State:  fftypes.MessageStateConfirmed,
 	}, nil, true, nil)
 	mdm.On("GetMessageWithDataCached", mock.Anything, ref4).Return(&fftypes.Message{
 		Header: fftypes.MessageHeader{ID: ref4},
+		State:  fftypes.MessageStateConfirmed,
 	}, nil, true, nil)
 
 	// Deliver a batch of messages
@@ -329,6 +401,84 @@ func TestEventDispatcherNoReadAheadInOrder(t *testing.T) {
 	mdm.AssertExpectations(t)
 }
 
+func TestEventDispatcherPriorityOrdered(t *testing.T) {
+	priority := fftypes.SubOptsOrderingPriority
+	sub := &subscription{
+		dispatcherElection: make(chan bool, 1),
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Ephemeral:       true,
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					Ordering: &priority,
+				},
+			},
+		},
+	}
+
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	assert.True(t, ed.prioritized)
+	go ed.deliverEvents()
+
+	mdm := ed.data.(*datamocks.Manager)
+	mei := ed.transport.(*eventsmocks.PluginAll)
+
+	eventDeliveries := make(chan *fftypes.EventDelivery)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock.RunFn = func(a mock.Arguments) {
+		eventDeliveries <- a.Get(2).(*fftypes.EventDelivery)
+	}
+
+	// Bulk events with the default priority, interleaved with urgent ones
+	priorities := []int64{0, 5, 5, 1}
+	events := make([]fftypes.LocallySequenced, len(priorities))
+	for i, p := range priorities {
+		ref := fftypes.NewUUID()
+		mdm.On("GetMessageWithDataCached", mock.Anything, ref).Return(&fftypes.Message{
+			Header: fftypes.MessageHeader{ID: ref, Priority: p},
+			State:  fftypes.MessageStateConfirmed,
+		}, nil, true, nil)
+		events[i] = &fftypes.Event{ID: fftypes.NewUUID(), Sequence: int64(10000001 + i), Reference: ref, Type: fftypes.EventTypeMessageConfirmed}
+	}
+	ed.eventPoller.pollingOffset = 10000000
+
+	batch1Done := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery(events)
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(batch1Done)
+	}()
+
+	for _, expected := range []int{1, 2, 3, 0} {
+		event := <-eventDeliveries
+		assert.Equal(t, events[expected].(*fftypes.Event).ID, event.ID)
+		// With no read-ahead, the previous ack has been processed before the next delivery.
+		// The offset cannot move past the bulk event that is still queued.
+		assert.Equal(t, int64(10000000), ed.eventPoller.getPollingOffset())
+		ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: event.ID})
+	}
+
+	<-batch1Done
+	assert.Equal(t, int64(10000004), ed.eventPoller.getPollingOffset())
+
+	mei.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestPrioritizeNonMessageEvents(t *testing.T) {
+	events := []*fftypes.EventDelivery{
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{Sequence: 2}}},
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{Sequence: 1}}},
+		{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{Sequence: 3}, Message: &fftypes.Message{Header: fftypes.MessageHeader{Priority: -1}}}},
+	}
+	prioritize(events)
+	assert.Equal(t, int64(1), events[0].Sequence)
+	assert.Equal(t, int64(2), events[1].Sequence)
+	assert.Equal(t, int64(3), events[2].Sequence)
+}
+
 func TestEventDispatcherChangeEvents(t *testing.T) {
 	log.SetLevel("debug")
 	sub := &subscription{
@@ -446,6 +596,55 @@ func TestEnrichEventsFailGetBlockchainEvents(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestEnrichEventsWithData(t *testing.T) {
+	yes := true
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					WithData: &yes,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	msg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}, State: fftypes.MessageStateConfirmed}
+	data := fftypes.DataArray{{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`"value1"`)}}
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(msg, nil, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, msg).Return(data, true, nil)
+
+	enriched, err := ed.enrichEvents([]fftypes.LocallySequenced{&fftypes.Event{ID: fftypes.NewUUID(), Type: fftypes.EventTypeMessageConfirmed}})
+	assert.NoError(t, err)
+	assert.Equal(t, data, enriched[0].Data)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestEnrichEventsWithDataFail(t *testing.T) {
+	yes := true
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					WithData: &yes,
+				},
+			},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(&fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}, State: fftypes.MessageStateConfirmed}, nil, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, mock.Anything).Return(nil, false, fmt.Errorf("pop"))
+
+	_, err := ed.enrichEvents([]fftypes.LocallySequenced{&fftypes.Event{ID: fftypes.NewUUID(), Type: fftypes.EventTypeMessageConfirmed}})
+	assert.EqualError(t, err, "pop")
+}
+
 func TestFilterEventsMatch(t *testing.T) {
 
 	sub := &subscription{
@@ -590,6 +789,15 @@ func TestFilterEventsMatch(t *testing.T) {
 	assert.Equal(t, *id2, *matched[1].ID)
 
 	ed.subscription.topicFilter = nil
+	ed.subscription.topicPattern, _ = fftypes.ParseTopicPattern(context.Background(), "*")
+	matched = ed.filterEvents(events)
+	assert.Equal(t, 3, len(matched))
+	ed.subscription.topicPattern, _ = fftypes.ParseTopicPattern(context.Background(), "topic2.#")
+	matched = ed.filterEvents(events)
+	assert.Equal(t, 1, len(matched))
+	assert.Equal(t, *id3, *matched[0].ID)
+
+	ed.subscription.topicPattern = nil
 	ed.subscription.messageFilter.tagFilter = regexp.MustCompile("tag2")
 	matched = ed.filterEvents(events)
 	assert.Equal(t, 1, len(matched))
@@ -851,6 +1059,64 @@ func TestBufferedDeliveryEnrichFail(t *testing.T) {
 
 }
 
+func TestBufferedDeliveryEnrichmentDataFail(t *testing.T) {
+
+	sub := &subscription{
+		definition:        &fftypes.Subscription{},
+		messageFilter:     &messageFilter{},
+		transactionFilter: &transactionFilter{},
+		blockchainFilter:  &blockchainFilter{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	ed.enrichers = []events.Enricher{&eventsmocks.Enricher{}}
+
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, mock.Anything).Return(&fftypes.Message{State: fftypes.MessageStateConfirmed}, nil, true, nil)
+	mdm.On("GetMessageDataCached", mock.Anything, mock.Anything).Return(nil, false, fmt.Errorf("pop"))
+
+	repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{&fftypes.Event{ID: fftypes.NewUUID(), Type: fftypes.EventTypeMessageConfirmed}})
+	assert.False(t, repoll)
+	assert.EqualError(t, err, "pop")
+
+}
+
+func TestApplyEnrichers(t *testing.T) {
+
+	sub := &subscription{
+		definition: &fftypes.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	data := fftypes.DataArray{{ID: fftypes.NewUUID(), Value: fftypes.JSONAnyPtr(`{"orderId":"12345"}`)}}
+	msg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	msgEvent := &fftypes.EventDelivery{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: fftypes.NewUUID()}, Message: msg}}
+	otherEvent := &fftypes.EventDelivery{EnrichedEvent: fftypes.EnrichedEvent{Event: fftypes.Event{ID: fftypes.NewUUID()}}}
+
+	mdm := ed.data.(*datamocks.Manager)
+	mdm.On("GetMessageDataCached", mock.Anything, msg).Return(data, true, nil)
+
+	men1 := &eventsmocks.Enricher{}
+	men1.On("Name").Return("orders")
+	men1.On("Enrich", mock.Anything, msgEvent, data).Return(fftypes.JSONObject{"orderId": "12345"}, nil)
+	men1.On("Enrich", mock.Anything, otherEvent, fftypes.DataArray(nil)).Return(nil, nil)
+	men2 := &eventsmocks.Enricher{}
+	men2.On("Name").Return("broken")
+	men2.On("Enrich", mock.Anything, mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	ed.enrichers = []events.Enricher{men1, men2}
+
+	err := ed.applyEnrichers([]*fftypes.EventDelivery{msgEvent, otherEvent})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.JSONObject{"orders": fftypes.JSONObject{"orderId": "12345"}}, msgEvent.Enrichments)
+	assert.Nil(t, otherEvent.Enrichments)
+
+	mdm.AssertExpectations(t)
+	men1.AssertExpectations(t)
+	men2.AssertExpectations(t)
+
+}
+
 func TestBufferedDeliveryClosedContext(t *testing.T) {
 
 	sub := &subscription{
@@ -972,6 +1238,20 @@ func TestAckNotInFlightNoop(t *testing.T) {
 	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: fftypes.NewUUID()})
 }
 
+func TestBatchAckNoneInFlightNoop(t *testing.T) {
+
+	sub := &subscription{
+		definition: &fftypes.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+
+	id1 := fftypes.NewUUID()
+	ed.inflight[*id1] = &fftypes.Event{ID: id1, Sequence: 100}
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{UpToSequence: 99})
+	assert.Len(t, ed.inflight, 1)
+}
+
 func TestEventDeliveryClosed(t *testing.T) {
 
 	sub := &subscription{
@@ -984,6 +1264,74 @@ func TestEventDeliveryClosed(t *testing.T) {
 	cancel()
 }
 
+func TestBufferedDeliveryRewind(t *testing.T) {
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+		},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+	ed.elected = true
+	ed.eventPoller.pollingOffset = 10000000
+
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	eventDeliveries := make(chan *fftypes.EventDelivery)
+	deliveryRequestMock := mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deliveryRequestMock.RunFn = func(a mock.Arguments) {
+		eventDeliveries <- a.Get(2).(*fftypes.EventDelivery)
+	}
+
+	bdDone := make(chan struct{})
+	go func() {
+		repoll, err := ed.bufferedDelivery([]fftypes.LocallySequenced{
+			&fftypes.Event{ID: fftypes.NewUUID(), Sequence: 10000001, Type: fftypes.EventTypeIdentityConfirmed},
+			&fftypes.Event{ID: fftypes.NewUUID(), Sequence: 10000002, Type: fftypes.EventTypeIdentityConfirmed},
+		})
+		assert.NoError(t, err)
+		assert.True(t, repoll)
+		close(bdDone)
+	}()
+
+	// Rewind while the first event is in flight, racing an acknowledgement for it
+	event1 := <-eventDeliveries
+	assert.True(t, ed.rewind(500))
+	go ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: event1.ID})
+	<-bdDone
+	assert.Empty(t, ed.inflight)
+
+	// The rewind is applied again as the next page is read
+	mdi := ed.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	_, err := ed.eventPoller.readPage()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(500), ed.eventPoller.getPollingOffset())
+	rewind, _ := ed.pendingRewind()
+	assert.False(t, rewind)
+}
+
+func TestAckAbandonedByRewind(t *testing.T) {
+	sub := &subscription{
+		definition: &fftypes.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	ed.eventPoller.pollingOffset = 500
+	ed.handleAckOffsetUpdate(ackNack{id: *fftypes.NewUUID(), offset: 10000001})
+	assert.Equal(t, int64(500), ed.eventPoller.getPollingOffset())
+}
+
+func TestRewindNotElected(t *testing.T) {
+	sub := &subscription{
+		definition: &fftypes.Subscription{},
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	assert.False(t, ed.rewind(500))
+	assert.False(t, ed.abandonForRewind())
+}
+
 func TestAckClosed(t *testing.T) {
 
 	sub := &subscription{
@@ -1019,49 +1367,6 @@ func TestGetEvents(t *testing.T) {
 	assert.Equal(t, int64(12345), lc[0].LocalSequence())
 }
 
-func TestDeliverEventsWithDataFail(t *testing.T) {
-	yes := true
-	sub := &subscription{
-		definition: &fftypes.Subscription{
-			Options: fftypes.SubscriptionOptions{
-				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
-					WithData: &yes,
-				},
-			},
-		},
-	}
-
-	ed, cancel := newTestEventDispatcher(sub)
-	defer cancel()
-
-	mdm := ed.data.(*datamocks.Manager)
-	mdm.On("GetMessageDataCached", ed.ctx, mock.Anything).Return(nil, false, fmt.Errorf("pop"))
-
-	id1 := fftypes.NewUUID()
-	ed.eventDelivery <- &fftypes.EventDelivery{
-		EnrichedEvent: fftypes.EnrichedEvent{
-			Event: fftypes.Event{
-				ID: id1,
-			},
-			Message: &fftypes.Message{
-				Header: fftypes.MessageHeader{
-					ID: fftypes.NewUUID(),
-				},
-				Data: fftypes.DataRefs{
-					{ID: fftypes.NewUUID()},
-				},
-			},
-		},
-	}
-
-	ed.inflight[*id1] = &fftypes.Event{ID: id1}
-	go ed.deliverEvents()
-
-	an := <-ed.acksNacks
-	assert.True(t, an.isNack)
-
-}
-
 func TestEventDispatcherWithReply(t *testing.T) {
 	log.SetLevel("debug")
 	var two = uint16(5)
@@ -1148,3 +1453,66 @@ func TestDispatchChangeEventBlockedClose(t *testing.T) {
 
 	ed.dispatchChangeEvent(&fftypes.ChangeEvent{})
 }
+
+func TestEventDispatcherTap(t *testing.T) {
+	var zero = uint16(0)
+	sub := &subscription{
+		definition: &fftypes.Subscription{
+			SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"},
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					ReadAhead: &zero,
+				},
+			},
+		},
+		eventMatcher: regexp.MustCompile(fmt.Sprintf("^%s$", fftypes.EventTypeMessageConfirmed)),
+	}
+	ed, cancel := newTestEventDispatcher(sub)
+	defer cancel()
+	go ed.deliverEvents()
+
+	mdm := ed.data.(*datamocks.Manager)
+	mei := ed.transport.(*eventsmocks.PluginAll)
+	eventDeliveries := make(chan *fftypes.EventDelivery)
+	mei.On("DeliveryRequest", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(a mock.Arguments) {
+		eventDeliveries <- a.Get(2).(*fftypes.EventDelivery)
+	})
+	records, err := ed.taps.add(context.Background(), sub.definition.ID, 3)
+	assert.NoError(t, err)
+
+	ev1 := &fftypes.Event{ID: fftypes.NewUUID(), Sequence: 1, Reference: fftypes.NewUUID(), Type: fftypes.EventTypeMessageConfirmed}
+	ev2 := &fftypes.Event{ID: fftypes.NewUUID(), Sequence: 2, Reference: fftypes.NewUUID(), Type: fftypes.EventTypeMessageRejected}
+	ev3 := &fftypes.Event{ID: fftypes.NewUUID(), Sequence: 3, Reference: fftypes.NewUUID(), Type: fftypes.EventTypeMessageConfirmed}
+	mdm.On("GetMessageWithDataCached", mock.Anything, ev1.Reference).Return(&fftypes.Message{State: fftypes.MessageStateConfirmed}, nil, true, nil)
+	mdm.On("GetMessageWithDataCached", mock.Anything, ev2.Reference).Return(&fftypes.Message{State: fftypes.MessageStateRejected}, nil, true, nil)
+	mdm.On("GetMessageWithDataCached", mock.Anything, ev3.Reference).Return(&fftypes.Message{State: fftypes.MessageStateConfirmed}, nil, true, nil)
+	bdDone := make(chan struct{})
+	go func() {
+		_, err := ed.bufferedDelivery([]fftypes.LocallySequenced{ev1, ev2, ev3})
+		assert.NoError(t, err)
+		close(bdDone)
+	}()
+
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: (<-eventDeliveries).ID})
+	ed.deliveryResponse(&fftypes.EventDeliveryResponse{ID: (<-eventDeliveries).ID, Info: "done"})
+	<-bdDone
+
+	var decisions []string
+	for rec := range records {
+		assert.Equal(t, sub.definition.ID, rec.Subscription)
+		assert.Equal(t, ed.connID, rec.Connection)
+		decisions = append(decisions, fmt.Sprintf("%d:%s:%s", rec.Sequence, rec.Decision, rec.Info))
+	}
+	assert.Equal(t, []string{
+		"1:matched:",
+		"2:filtered:events",
+		"3:matched:",
+		"1:dispatched:",
+		"3:held:readahead=0 inflight=1",
+		"1:acked:",
+		"3:dispatched:",
+		"3:acked:done",
+	}, decisions)
+
+	mei.AssertExpectations(t)
+}
@@ -34,6 +34,8 @@ import (
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/metrics"
+	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/internal/pinning"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
 	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/internal/sysmessaging"
@@ -56,13 +58,20 @@ type EventManager interface {
 	ChangeEvents() chan<- *fftypes.ChangeEvent
 	DeleteDurableSubscription(ctx context.Context, subDef *fftypes.Subscription) (err error)
 	CreateUpdateDurableSubscription(ctx context.Context, subDef *fftypes.Subscription, mustNew bool) (err error)
+	TapSubscription(ctx context.Context, id *fftypes.UUID, eventCount int) (<-chan *fftypes.SubscriptionTapRecord, error)
+	RewindDurableSubscription(ctx context.Context, subDef *fftypes.Subscription, offset int64) (*fftypes.Offset, error)
+	DryRunSubscription(ctx context.Context, ns string, dryRun *fftypes.SubscriptionDryRun) ([]*fftypes.SubscriptionDryRunResult, error)
+	StreamEvents(ctx context.Context, ns string, firstEvent *fftypes.SubOptsFirstEvent) (<-chan *fftypes.EnrichedEvent, error)
+	QueueDepths() []*fftypes.DispatcherQueueDepth
 	Start() error
 	WaitStop()
 
 	// Bound blockchain callbacks
 	OperationUpdate(plugin fftypes.Named, operationID *fftypes.UUID, txState blockchain.TransactionStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error
+	BlockchainOpUpdate(plugin fftypes.Named, operationID *fftypes.UUID, tx *fftypes.BlockchainTransaction, errorMessage string, opOutput fftypes.JSONObject, receipt *fftypes.BlockchainReceipt) error
 	BatchPinComplete(bi blockchain.Plugin, batch *blockchain.BatchPin, signingKey *fftypes.VerifierRef) error
 	BlockchainEvent(event *blockchain.EventWithSubscription) error
+	ChainReorg(bi blockchain.Plugin, reorg *blockchain.Reorg) error
 
 	// Bound dataexchange callbacks
 	TransferResult(dx dataexchange.Plugin, trackingID string, status fftypes.OpStatus, update fftypes.TransportStatusUpdate) error
@@ -90,9 +99,11 @@ type eventManager struct {
 	subManager            *subscriptionManager
 	retry                 retry.Retry
 	aggregator            *aggregator
+	compactor             *eventCompactor
 	broadcast             broadcast.Manager
 	messaging             privatemessaging.Manager
 	assets                assets.Manager
+	operations            operations.Manager
 	newEventNotifier      *eventNotifier
 	newPinNotifier        *eventNotifier
 	opCorrelationRetries  int
@@ -101,36 +112,41 @@ type eventManager struct {
 	metrics               metrics.Manager
 	chainListenerCache    *ccache.Cache
 	chainListenerCacheTTL time.Duration
+	pinning               *pinning.Policy
+	lifecycleEvents       bool
 }
 
-func NewEventManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, si sharedstorage.Plugin, di database.Plugin, bi blockchain.Plugin, im identity.Manager, dh definitions.DefinitionHandlers, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, am assets.Manager, mm metrics.Manager, txHelper txcommon.Helper) (EventManager, error) {
-	if ni == nil || si == nil || di == nil || bi == nil || im == nil || dh == nil || dm == nil || bm == nil || pm == nil || am == nil {
+func NewEventManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, si sharedstorage.Plugin, di database.Plugin, bi blockchain.Plugin, im identity.Manager, dh definitions.DefinitionHandlers, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager, am assets.Manager, mm metrics.Manager, om operations.Manager, txHelper txcommon.Helper) (EventManager, error) {
+	if ni == nil || si == nil || di == nil || bi == nil || im == nil || dh == nil || dm == nil || bm == nil || pm == nil || am == nil || om == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
+	pp, err := pinning.NewPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
 	newPinNotifier := newEventNotifier(ctx, "pins")
 	newEventNotifier := newEventNotifier(ctx, "events")
 	em := &eventManager{
-		ctx:           log.WithLogField(ctx, "role", "event-manager"),
-		ni:            ni,
-		sharedstorage: si,
-		database:      di,
-		txHelper:      txHelper,
-		identity:      im,
-		definitions:   dh,
-		data:          dm,
-		broadcast:     bm,
-		messaging:     pm,
-		assets:        am,
-		retry: retry.Retry{
-			InitialDelay: config.GetDuration(config.EventAggregatorRetryInitDelay),
-			MaximumDelay: config.GetDuration(config.EventAggregatorRetryMaxDelay),
-			Factor:       config.GetFloat64(config.EventAggregatorRetryFactor),
-		},
+		ctx:                   log.WithLogField(ctx, "role", "event-manager"),
+		ni:                    ni,
+		sharedstorage:         si,
+		database:              di,
+		txHelper:              txHelper,
+		identity:              im,
+		definitions:           dh,
+		data:                  dm,
+		broadcast:             bm,
+		messaging:             pm,
+		assets:                am,
+		operations:            om,
+		retry:                 *retry.NewFromConfig(config.EventAggregatorRetryInitDelay, config.EventAggregatorRetryMaxDelay, config.EventAggregatorRetryFactor),
 		defaultTransport:      config.GetString(config.EventTransportsDefault),
 		opCorrelationRetries:  config.GetInt(config.EventAggregatorOpCorrelationRetries),
 		newEventNotifier:      newEventNotifier,
 		newPinNotifier:        newPinNotifier,
-		aggregator:            newAggregator(ctx, di, bi, dh, im, dm, newPinNotifier, mm),
+		aggregator:            newAggregator(ctx, di, bi, dh, im, dm, newPinNotifier, mm, pp),
+		pinning:               pp,
+		lifecycleEvents:       config.GetBool(config.BatchLifecycleEvents),
 		metrics:               mm,
 		chainListenerCache:    ccache.New(ccache.Configure().MaxSize(config.GetByteSize(config.EventListenerTopicCacheSize))),
 		chainListenerCacheTTL: config.GetDuration(config.EventListenerTopicCacheTTL),
@@ -138,11 +154,16 @@ func NewEventManager(ctx context.Context, ni sysmessaging.LocalNodeInfo, si shar
 	ie, _ := eifactory.GetPlugin(ctx, system.SystemEventsTransport)
 	em.internalEvents = ie.(*system.Events)
 
-	var err error
 	if em.subManager, err = newSubscriptionManager(ctx, di, dm, newEventNotifier, dh, txHelper); err != nil {
 		return nil, err
 	}
 
+	if config.GetBool(config.EventCompactionEnabled) {
+		if em.compactor, err = newEventCompactor(ctx, di); err != nil {
+			return nil, err
+		}
+	}
+
 	return em, nil
 }
 
@@ -150,6 +171,9 @@ func (em *eventManager) Start() (err error) {
 	err = em.subManager.start()
 	if err == nil {
 		em.aggregator.start()
+		if em.compactor != nil {
+			em.compactor.start()
+		}
 	}
 	return err
 }
@@ -181,6 +205,9 @@ func (em *eventManager) ChangeEvents() chan<- *fftypes.ChangeEvent {
 func (em *eventManager) WaitStop() {
 	em.subManager.close()
 	<-em.aggregator.eventPoller.closed
+	if em.compactor != nil {
+		<-em.compactor.done
+	}
 }
 
 func (em *eventManager) CreateUpdateDurableSubscription(ctx context.Context, subDef *fftypes.Subscription, mustNew bool) (err error) {
@@ -235,6 +262,93 @@ func (em *eventManager) DeleteDurableSubscription(ctx context.Context, subDef *f
 	return em.database.DeleteSubscriptionByID(ctx, subDef.ID)
 }
 
+func (em *eventManager) TapSubscription(ctx context.Context, id *fftypes.UUID, eventCount int) (<-chan *fftypes.SubscriptionTapRecord, error) {
+	return em.subManager.taps.add(ctx, id, eventCount)
+}
+
+func (em *eventManager) RewindDurableSubscription(ctx context.Context, subDef *fftypes.Subscription, offset int64) (*fftypes.Offset, error) {
+	return em.subManager.rewindDurableSubscription(ctx, subDef, offset)
+}
+
+func (em *eventManager) QueueDepths() []*fftypes.DispatcherQueueDepth {
+	return em.subManager.queueDepths()
+}
+
+func (em *eventManager) DryRunSubscription(ctx context.Context, ns string, dryRun *fftypes.SubscriptionDryRun) ([]*fftypes.SubscriptionDryRunResult, error) {
+	sub, err := parseSubscriptionFilter(ctx, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{Namespace: ns},
+		Filter:          dryRun.Filter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	maxEvents := config.GetInt(config.SubscriptionDryRunMaxEvents)
+	events := dryRun.Events
+	if len(events) > maxEvents {
+		return nil, i18n.NewError(ctx, i18n.MsgDryRunEventCountInvalid, len(events), maxEvents)
+	}
+	if events == nil {
+		// No sample provided, so evaluate against the most recent events in the namespace
+		limit := dryRun.Limit
+		if limit <= 0 {
+			limit = maxEvents
+		} else if limit > maxEvents {
+			return nil, i18n.NewError(ctx, i18n.MsgDryRunEventCountInvalid, limit, maxEvents)
+		}
+		fb := database.EventQueryFactory.NewFilter(ctx)
+		latest, _, err := em.database.GetEvents(ctx, addNamespaceCriteria(fb.And(), ns, &dryRun.Filter).Sort("sequence").Descending().Limit(uint64(limit)))
+		if err != nil {
+			return nil, err
+		}
+		events = make([]*fftypes.EnrichedEvent, len(latest))
+		for i, event := range latest {
+			if events[i], err = em.txHelper.EnrichEvent(ctx, event); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	results := make([]*fftypes.SubscriptionDryRunResult, 0, len(events))
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		mismatch := sub.filterMismatch(event)
+		results = append(results, &fftypes.SubscriptionDryRunResult{
+			Event:    event,
+			Matched:  mismatch == "",
+			Mismatch: mismatch,
+		})
+	}
+	return results, nil
+}
+
 func (em *eventManager) AddSystemEventListener(ns string, el system.EventListener) error {
 	return em.internalEvents.AddListener(ns, el)
 }
+
+// AddDurableSystemEventListener delivers the events matching the filter to the listener, through a durable subscription
+// on the system transport. The subscription is created starting from the newest event the first time, and resumes from
+// its offset thereafter - so events that arrive while the node is down are still delivered.
+func (em *eventManager) AddDurableSystemEventListener(ctx context.Context, ns, name string, filter *fftypes.SubscriptionFilter, el system.EventListener) error {
+	em.internalEvents.AddDurableListener(ns, name, el)
+	newest := fftypes.SubOptsFirstEventNewest
+	no := false
+	return em.CreateUpdateDurableSubscription(ctx, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Namespace: ns,
+			Name:      name,
+		},
+		Transport: system.SystemEventsTransport,
+		Filter:    *filter,
+		Options: fftypes.SubscriptionOptions{
+			SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+				FirstEvent: &newest,
+				WithData:   &no,
+			},
+		},
+		Created: fftypes.Now(),
+	}, false)
+}
@@ -33,10 +33,12 @@ import (
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/metricsmocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
 	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
 	"github.com/hyperledger/firefly/mocks/sysmessagingmocks"
 	"github.com/hyperledger/firefly/mocks/txcommonmocks"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -67,6 +69,7 @@ func newTestEventManagerCommon(t *testing.T, metrics bool) (*eventManager, func(
 	mam := &assetmocks.Manager{}
 	mni := &sysmessagingmocks.LocalNodeInfo{}
 	mmi := &metricsmocks.Manager{}
+	mom := &operationmocks.Manager{}
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	mmi.On("IsMetricsEnabled").Return(metrics)
 	if metrics {
@@ -75,7 +78,8 @@ func newTestEventManagerCommon(t *testing.T, metrics bool) (*eventManager, func(
 	mni.On("GetNodeUUID", mock.Anything).Return(testNodeID).Maybe()
 	met.On("Name").Return("ut").Maybe()
 	mbi.On("VerifierType").Return(fftypes.VerifierTypeEthAddress).Maybe()
-	emi, err := NewEventManager(ctx, mni, mpi, mdi, mbi, mim, msh, mdm, mbm, mpm, mam, mmi, txHelper)
+	mim.On("VerifyAuthorEndorsed", mock.Anything, mock.Anything).Return(true, nil).Maybe()
+	emi, err := NewEventManager(ctx, mni, mpi, mdi, mbi, mim, msh, mdm, mbm, mpm, mam, mmi, mom, txHelper)
 	em := emi.(*eventManager)
 	em.txHelper = &txcommonmocks.Helper{}
 	rag := mdi.On("RunAsGroup", em.ctx, mock.Anything).Maybe()
@@ -105,8 +109,44 @@ func TestStartStop(t *testing.T) {
 	em.WaitStop()
 }
 
+func TestStartStopWithCompaction(t *testing.T) {
+	config.Reset()
+	config.Set(config.EventCompactionEnabled, true)
+	config.Set(config.EventCompactionTypes, []string{string(fftypes.EventTypeTransactionSubmitted)})
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("VerifierType").Return(fftypes.VerifierTypeEthAddress).Maybe()
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeAggregator, aggregatorOffsetName).Return(&fftypes.Offset{
+		Type:    fftypes.OffsetTypeAggregator,
+		Name:    aggregatorOffsetName,
+		Current: 12345,
+	}, nil)
+	mdi.On("GetPins", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Pin{}, nil, nil)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	em, err := NewEventManager(ctx, &sysmessagingmocks.LocalNodeInfo{}, &sharedstoragemocks.Plugin{}, mdi, mbi, &identitymanagermocks.Manager{}, &definitionsmocks.DefinitionHandlers{}, mdm, &broadcastmocks.Manager{}, &privatemessagingmocks.Manager{}, &assetmocks.Manager{}, &metricsmocks.Manager{}, &operationmocks.Manager{}, txcommon.NewTransactionHelper(mdi, mdm))
+	assert.NoError(t, err)
+	assert.NotNil(t, em.(*eventManager).compactor)
+	assert.NoError(t, em.Start())
+	cancel()
+	em.WaitStop()
+}
+
+func TestStartStopCompactionNoTypes(t *testing.T) {
+	config.Reset()
+	config.Set(config.EventCompactionEnabled, true)
+	defer config.Reset()
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	mbi := &blockchainmocks.Plugin{}
+	mbi.On("VerifierType").Return(fftypes.VerifierTypeEthAddress).Maybe()
+	_, err := NewEventManager(context.Background(), &sysmessagingmocks.LocalNodeInfo{}, &sharedstoragemocks.Plugin{}, mdi, mbi, &identitymanagermocks.Manager{}, &definitionsmocks.DefinitionHandlers{}, mdm, &broadcastmocks.Manager{}, &privatemessagingmocks.Manager{}, &assetmocks.Manager{}, &metricsmocks.Manager{}, &operationmocks.Manager{}, txcommon.NewTransactionHelper(mdi, mdm))
+	assert.Regexp(t, "FF10554", err)
+}
+
 func TestStartStopBadDependencies(t *testing.T) {
-	_, err := NewEventManager(context.Background(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	_, err := NewEventManager(context.Background(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 
 }
@@ -125,12 +165,25 @@ func TestStartStopBadTransports(t *testing.T) {
 	mni := &sysmessagingmocks.LocalNodeInfo{}
 	mam := &assetmocks.Manager{}
 	mm := &metricsmocks.Manager{}
+	mom := &operationmocks.Manager{}
 	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
 	mbi.On("VerifierType").Return(fftypes.VerifierTypeEthAddress)
-	_, err := NewEventManager(context.Background(), mni, mpi, mdi, mbi, mim, msh, mdm, mbm, mpm, mam, mm, txHelper)
+	_, err := NewEventManager(context.Background(), mni, mpi, mdi, mbi, mim, msh, mdm, mbm, mpm, mam, mm, mom, txHelper)
 	assert.Regexp(t, "FF10172", err)
 }
 
+func TestStartStopBadPinningRules(t *testing.T) {
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "pinning": fftypes.JSONObject{"deferred": "yes"}},
+	})
+	defer config.Reset()
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
+	_, err := NewEventManager(context.Background(), &sysmessagingmocks.LocalNodeInfo{}, &sharedstoragemocks.Plugin{}, mdi, &blockchainmocks.Plugin{}, &identitymanagermocks.Manager{}, &definitionsmocks.DefinitionHandlers{}, mdm, &broadcastmocks.Manager{}, &privatemessagingmocks.Manager{}, &assetmocks.Manager{}, &metricsmocks.Manager{}, &operationmocks.Manager{}, txHelper)
+	assert.Regexp(t, "FF10515", err)
+}
+
 func TestEmitSubscriptionEventsNoops(t *testing.T) {
 	em, cancel := newTestEventManager(t)
 	mdi := em.database.(*databasemocks.Plugin)
@@ -386,3 +439,178 @@ func TestAddInternalListener(t *testing.T) {
 
 	cbs.AssertExpectations(t)
 }
+
+func TestAddDurableInternalListener(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	ie := &system.Events{}
+	cbs := &eventsmocks.Callbacks{}
+	cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	conf := config.NewPluginConfig("ut.events")
+	ie.InitPrefix(conf)
+	ie.Init(em.ctx, conf, cbs)
+	em.internalEvents = ie
+	em.subManager.transports[system.SystemEventsTransport] = ie
+
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptionByName", mock.Anything, "ns1", "sub1").Return(nil, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{
+		{Sequence: 12345},
+	}, nil, nil)
+	mdi.On("UpsertSubscription", mock.Anything, mock.MatchedBy(func(sub *fftypes.Subscription) bool {
+		return sub.Transport == system.SystemEventsTransport &&
+			sub.Filter.Events == "message_confirmed" &&
+			!*sub.Options.WithData &&
+			*sub.Options.FirstEvent == "12345"
+	}), true).Return(nil)
+
+	err := em.AddDurableSystemEventListener(em.ctx, "ns1", "sub1", &fftypes.SubscriptionFilter{
+		Events: "message_confirmed",
+	}, func(event *fftypes.EventDelivery) error { return nil })
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestTapSubscription(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	subID := fftypes.NewUUID()
+	records, err := em.TapSubscription(em.ctx, subID, 10)
+	assert.NoError(t, err)
+	assert.NotNil(t, records)
+	assert.Len(t, em.subManager.taps.taps[*subID], 1)
+}
+
+func TestRewindDurableSubscriptionPassthrough(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("UpsertOffset", mock.Anything, mock.Anything, true).Return(nil)
+	offset, err := em.RewindDurableSubscription(em.ctx, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()},
+	}, 99)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(99), offset.Current)
+}
+
+func TestQueueDepthsNoConnections(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	assert.Empty(t, em.QueueDepths())
+}
+
+func TestDryRunSubscriptionSample(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	results, err := em.DryRunSubscription(em.ctx, "ns1", &fftypes.SubscriptionDryRun{
+		Filter: fftypes.SubscriptionFilter{
+			Events: "message_confirmed",
+			Message: fftypes.MessageFilter{
+				Tag: "^tag1$",
+			},
+		},
+		Events: []*fftypes.EnrichedEvent{
+			{
+				Event:   fftypes.Event{Type: fftypes.EventTypeMessageConfirmed},
+				Message: &fftypes.Message{Header: fftypes.MessageHeader{Tag: "tag1"}},
+			},
+			{
+				Event:   fftypes.Event{Type: fftypes.EventTypeMessageConfirmed},
+				Message: &fftypes.Message{Header: fftypes.MessageHeader{Tag: "tag2"}},
+			},
+			nil,
+			{
+				Event: fftypes.Event{Type: fftypes.EventTypeTransactionSubmitted},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.True(t, results[0].Matched)
+	assert.False(t, results[1].Matched)
+	assert.Equal(t, "message.tag", results[1].Mismatch)
+	assert.False(t, results[2].Matched)
+	assert.Equal(t, "events", results[2].Mismatch)
+}
+
+func TestDryRunSubscriptionBadFilter(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	_, err := em.DryRunSubscription(em.ctx, "ns1", &fftypes.SubscriptionDryRun{
+		Filter: fftypes.SubscriptionFilter{
+			Topic: "[[[[! badness",
+		},
+	})
+	assert.Regexp(t, "FF10171.*topic", err)
+}
+
+func TestDryRunSubscriptionSampleTooLarge(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	config.Set(config.SubscriptionDryRunMaxEvents, 1)
+	_, err := em.DryRunSubscription(em.ctx, "ns1", &fftypes.SubscriptionDryRun{
+		Events: []*fftypes.EnrichedEvent{{}, {}},
+	})
+	assert.Regexp(t, "FF10456", err)
+}
+
+func TestDryRunSubscriptionLimitTooLarge(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	_, err := em.DryRunSubscription(em.ctx, "ns1", &fftypes.SubscriptionDryRun{
+		Limit: 101,
+	})
+	assert.Regexp(t, "FF10456", err)
+}
+
+func TestDryRunSubscriptionHistorical(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	ev1 := &fftypes.Event{ID: fftypes.NewUUID(), Type: fftypes.EventTypeMessageConfirmed, Topic: "topic1"}
+	ev2 := &fftypes.Event{ID: fftypes.NewUUID(), Type: fftypes.EventTypeMessageConfirmed, Topic: "topic2"}
+	mdi.On("GetEvents", em.ctx, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 10 && fi.Sort[0].Descending
+	})).Return([]*fftypes.Event{ev1, ev2}, nil, nil)
+	mth.On("EnrichEvent", em.ctx, ev1).Return(&fftypes.EnrichedEvent{Event: *ev1}, nil)
+	mth.On("EnrichEvent", em.ctx, ev2).Return(&fftypes.EnrichedEvent{Event: *ev2}, nil)
+
+	results, err := em.DryRunSubscription(em.ctx, "ns1", &fftypes.SubscriptionDryRun{
+		Filter: fftypes.SubscriptionFilter{
+			Topic: "topic1",
+		},
+		Limit: 10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, ev1.ID, results[0].Event.ID)
+	assert.True(t, results[0].Matched)
+	assert.False(t, results[1].Matched)
+	assert.Equal(t, "topic", results[1].Mismatch)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
+func TestDryRunSubscriptionHistoricalQueryFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", em.ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := em.DryRunSubscription(em.ctx, "ns1", &fftypes.SubscriptionDryRun{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDryRunSubscriptionHistoricalEnrichFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mdi.On("GetEvents", em.ctx, mock.Anything).Return([]*fftypes.Event{{ID: fftypes.NewUUID()}}, nil, nil)
+	mth.On("EnrichEvent", em.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := em.DryRunSubscription(em.ctx, "ns1", &fftypes.SubscriptionDryRun{})
+	assert.EqualError(t, err, "pop")
+}
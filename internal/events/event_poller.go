@@ -28,6 +28,9 @@ import (
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
+// finalOffsetCommitTimeout bounds the commit of outstanding acknowledgements when a poller closes
+const finalOffsetCommitTimeout = 5 * time.Second
+
 type eventPoller struct {
 	ctx             context.Context
 	database        database.Plugin
@@ -37,6 +40,7 @@ type eventPoller struct {
 	offsetCommitted chan int64
 	offsetID        int64
 	pollingOffset   int64
+	uncommitted     int
 	mux             sync.Mutex
 	conf            *eventPollerConf
 }
@@ -55,6 +59,8 @@ type eventPollerConf struct {
 	maybeRewind                func() (bool, int64)
 	newEventsHandler           newEventsHandler
 	namespace                  string
+	offsetCommitCount          int
+	offsetCommitInterval       time.Duration
 	offsetName                 string
 	offsetType                 fftypes.OffsetType
 	retry                      retry.Retry
@@ -145,6 +151,9 @@ func (ep *eventPoller) commitOffset(offset int64) {
 	// Next polling cycle should start one higher than this offset
 	ep.mux.Lock()
 	ep.pollingOffset = offset
+	if !ep.conf.ephemeral {
+		ep.uncommitted++
+	}
 	ep.mux.Unlock()
 
 	// No persistence for ephemeral (non-durable) subscriptions
@@ -224,23 +233,73 @@ func (ep *eventPoller) eventLoop() {
 	}
 }
 
+// offsetCommitLoop writes the polling offset to the database in the background. By default every
+// acknowledged event results in a commit, but high-throughput subscriptions can be configured to
+// commit only after a number of acks, and/or after an interval has passed since the first ack that
+// has not been committed. Any acknowledged events that are not committed are flushed when the poller
+// closes, so only a crash causes them to be redelivered.
 func (ep *eventPoller) offsetCommitLoop() {
-	l := log.L(ep.ctx)
-	for range ep.offsetCommitted {
-		_ = ep.conf.retry.Do(ep.ctx, "process events", func(attempt int) (retry bool, err error) {
-			ep.mux.Lock()
-			pollingOffset := ep.pollingOffset
-			ep.mux.Unlock()
-			u := database.OffsetQueryFactory.NewUpdate(ep.ctx).Set("current", pollingOffset)
-			if err := ep.database.UpdateOffset(ep.ctx, ep.offsetID, u); err != nil {
-				return true, err
+	var flushTimer <-chan time.Time
+	for {
+		select {
+		case _, ok := <-ep.offsetCommitted:
+			if !ok {
+				if ep.getUncommitted() > 0 {
+					ep.finalFlushOffset()
+				}
+				return
 			}
-			l.Debugf("Event polling offset committed %d", pollingOffset)
-			return false, nil
-		})
+			if ep.getUncommitted() < ep.conf.offsetCommitCount {
+				if ep.conf.offsetCommitInterval > 0 && flushTimer == nil {
+					flushTimer = time.After(ep.conf.offsetCommitInterval)
+				}
+				continue
+			}
+		case <-flushTimer:
+		}
+		flushTimer = nil
+		ep.flushOffset()
+	}
+}
+
+func (ep *eventPoller) getUncommitted() int {
+	ep.mux.Lock()
+	defer ep.mux.Unlock()
+	return ep.uncommitted
+}
+
+func (ep *eventPoller) flushOffset() {
+	_ = ep.conf.retry.Do(ep.ctx, "process events", func(attempt int) (retry bool, err error) {
+		return true, ep.updateOffset(ep.ctx)
+	})
+}
+
+// finalFlushOffset makes a single attempt to commit the offset as the poller closes. The poller context
+// is usually cancelled by this point, so the update runs on its own context.
+func (ep *eventPoller) finalFlushOffset() {
+	ctx, cancel := context.WithTimeout(context.Background(), finalOffsetCommitTimeout)
+	defer cancel()
+	if err := ep.updateOffset(ctx); err != nil {
+		log.L(ep.ctx).Warnf("Failed to commit event polling offset on close: %s", err)
 	}
 }
 
+func (ep *eventPoller) updateOffset(ctx context.Context) error {
+	ep.mux.Lock()
+	pollingOffset := ep.pollingOffset
+	uncommitted := ep.uncommitted
+	ep.mux.Unlock()
+	u := database.OffsetQueryFactory.NewUpdate(ctx).Set("current", pollingOffset)
+	if err := ep.database.UpdateOffset(ctx, ep.offsetID, u); err != nil {
+		return err
+	}
+	ep.mux.Lock()
+	ep.uncommitted -= uncommitted
+	ep.mux.Unlock()
+	log.L(ep.ctx).Debugf("Event polling offset committed %d", pollingOffset)
+	return nil
+}
+
 func (ep *eventPoller) dispatchEventsRetry(events []fftypes.LocallySequenced) (repoll bool, err error) {
 	err = ep.conf.retry.Do(ep.ctx, "process events", func(attempt int) (retry bool, err error) {
 		repoll, err = ep.conf.newEventsHandler(events)
@@ -265,6 +324,11 @@ func (ep *eventPoller) newEventNotifications() {
 			log.L(ep.ctx).Debugf("event notifier closing")
 			return
 		}
+		if ep.ctx.Err() != nil {
+			// The notifier can be shared with longer lived pollers, so we cannot rely on it closing
+			log.L(ep.ctx).Debugf("event poller closed")
+			return
+		}
 		ep.shoulderTap()
 		lastNotified = latestSequence
 	}
@@ -349,3 +349,92 @@ func TestOffsetCommitLoopFail(t *testing.T) {
 
 	mdi.AssertExpectations(t)
 }
+
+func TestOffsetCommitLoopCount(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+
+	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
+	defer cancel()
+	ep.conf.offsetCommitCount = 3
+
+	committed := make(chan bool)
+	mdi.On("UpdateOffset", mock.Anything, ep.offsetID, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		committed <- true
+	})
+	go ep.offsetCommitLoop()
+
+	for i := int64(1); i <= 3; i++ {
+		ep.commitOffset(i)
+		if i < 3 {
+			// Wait for the loop to see the ack, and confirm it did not commit
+			for len(ep.offsetCommitted) > 0 {
+				time.Sleep(1 * time.Millisecond)
+			}
+			assert.Equal(t, int(i), ep.getUncommitted())
+		}
+	}
+	<-committed
+	close(ep.offsetCommitted)
+
+	assert.Equal(t, 0, ep.getUncommitted())
+	mdi.AssertNumberOfCalls(t, "UpdateOffset", 1)
+}
+
+func TestOffsetCommitLoopInterval(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+
+	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
+	defer cancel()
+	ep.conf.offsetCommitCount = 100
+	ep.conf.offsetCommitInterval = 1 * time.Millisecond
+
+	committed := make(chan bool)
+	mdi.On("UpdateOffset", mock.Anything, ep.offsetID, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		committed <- true
+	})
+	go ep.offsetCommitLoop()
+
+	ep.commitOffset(12345)
+	<-committed
+	close(ep.offsetCommitted)
+
+	assert.Equal(t, int64(12345), ep.getPollingOffset())
+	assert.Equal(t, 0, ep.getUncommitted())
+}
+
+func TestOffsetCommitLoopFlushOnClose(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+
+	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
+	cancel()
+	ep.conf.offsetCommitCount = 10
+
+	mdi.On("UpdateOffset", mock.MatchedBy(func(ctx context.Context) bool {
+		// The final commit is not bound to the cancelled poller context
+		return ctx.Err() == nil
+	}), ep.offsetID, mock.Anything).Return(nil).Once()
+
+	ep.commitOffset(12345)
+	close(ep.offsetCommitted)
+	ep.offsetCommitLoop()
+
+	assert.Equal(t, 0, ep.getUncommitted())
+	mdi.AssertExpectations(t)
+}
+
+func TestOffsetCommitLoopFlushOnCloseFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+
+	ep, cancel := newTestEventPoller(t, mdi, nil, nil)
+	cancel()
+	ep.conf.offsetCommitCount = 10
+
+	mdi.On("UpdateOffset", mock.Anything, ep.offsetID, mock.Anything).Return(fmt.Errorf("pop")).Once()
+
+	ep.commitOffset(12345)
+	close(ep.offsetCommitted)
+	ep.offsetCommitLoop()
+
+	assert.Equal(t, 1, ep.getUncommitted())
+	mdi.AssertExpectations(t)
+}
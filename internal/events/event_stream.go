@@ -0,0 +1,90 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/retry"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// StreamEvents returns a stream of every event in the namespace, after the given first event (which defaults
+// to newest). Nothing is stored about the stream - the caller tracks the sequence of the last event it processed,
+// and supplies it as the first event to resume. The stream is closed when the context is cancelled.
+func (em *eventManager) StreamEvents(ctx context.Context, ns string, firstEvent *fftypes.SubOptsFirstEvent) (<-chan *fftypes.EnrichedEvent, error) {
+	// Resolve the starting point now, so we can report a bad input
+	firstOffset, err := calcFirstOffset(ctx, em.database, firstEvent)
+	if err != nil {
+		return nil, err
+	}
+	startAfter := fftypes.SubOptsFirstEvent(strconv.FormatInt(firstOffset, 10))
+
+	events := make(chan *fftypes.EnrichedEvent)
+	var ep *eventPoller
+	ep = newEventPoller(ctx, em.database, em.newEventNotifier, &eventPollerConf{
+		ephemeral:         true,
+		eventBatchSize:    config.GetInt(config.EventDispatcherBufferLength),
+		eventBatchTimeout: config.GetDuration(config.EventDispatcherBatchTimeout),
+		eventPollTimeout:  config.GetDuration(config.EventDispatcherPollTimeout),
+		firstEvent:        &startAfter,
+		retry:             *retry.NewFromConfig(config.EventDispatcherRetryInitDelay, config.EventDispatcherRetryMaxDelay, config.EventDispatcherRetryFactor),
+		namespace:         ns,
+		offsetName:        "stream",
+		queryFactory:      database.EventQueryFactory,
+		addCriteria: func(af database.AndFilter) database.AndFilter {
+			return af.Condition(af.Builder().Eq("namespace", ns))
+		},
+		getItems: func(ctx context.Context, filter database.Filter) ([]fftypes.LocallySequenced, error) {
+			page, _, err := em.database.GetEvents(ctx, filter)
+			ls := make([]fftypes.LocallySequenced, len(page))
+			for i, e := range page {
+				ls[i] = e
+			}
+			return ls, err
+		},
+		newEventsHandler: func(page []fftypes.LocallySequenced) (bool, error) {
+			for _, ls := range page {
+				// Skip anything we already streamed, before a retry
+				if ls.LocalSequence() <= ep.getPollingOffset() {
+					continue
+				}
+				enriched, err := em.txHelper.EnrichEvent(ctx, ls.(*fftypes.Event))
+				if err != nil {
+					return false, err
+				}
+				select {
+				case events <- enriched:
+				case <-ctx.Done():
+					return false, i18n.NewError(ctx, i18n.MsgContextCanceled)
+				}
+				ep.commitOffset(ls.LocalSequence())
+			}
+			return true, nil
+		},
+	})
+	ep.start()
+	go func() {
+		<-ep.closed
+		close(events)
+	}()
+	return events, nil
+}
@@ -0,0 +1,92 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/txcommonmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStreamEvents(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev1 := &fftypes.Event{ID: fftypes.NewUUID(), Namespace: "ns1", Sequence: 101}
+	ev2 := &fftypes.Event{ID: fftypes.NewUUID(), Namespace: "ns1", Sequence: 102}
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{ev1, ev2}, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("EnrichEvent", mock.Anything, ev1).Return(&fftypes.EnrichedEvent{Event: *ev1}, nil)
+	mth.On("EnrichEvent", mock.Anything, ev2).Return(nil, fmt.Errorf("pop")).Once()
+	mth.On("EnrichEvent", mock.Anything, ev2).Return(&fftypes.EnrichedEvent{Event: *ev2}, nil)
+
+	ctx, cancelStream := context.WithCancel(context.Background())
+	firstEvent := fftypes.SubOptsFirstEvent("100")
+	events, err := em.StreamEvents(ctx, "ns1", &firstEvent)
+	assert.NoError(t, err)
+
+	// The first event is not sent twice, when enriching the second one is retried
+	assert.Equal(t, int64(101), (<-events).Sequence)
+	assert.Equal(t, int64(102), (<-events).Sequence)
+
+	cancelStream()
+	for range events {
+	}
+	mth.AssertNumberOfCalls(t, "EnrichEvent", 3)
+
+	// The next event on the shared notifier ends the stream's listener
+	em.NewEvents() <- 103
+}
+
+func TestStreamEventsCancelledBeforeSend(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	ev1 := &fftypes.Event{ID: fftypes.NewUUID(), Namespace: "ns1", Sequence: 101}
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	ctx, cancelStream := context.WithCancel(context.Background())
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{ev1}, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	mth.On("EnrichEvent", mock.Anything, ev1).Return(&fftypes.EnrichedEvent{Event: *ev1}, nil).Run(func(args mock.Arguments) {
+		cancelStream()
+	})
+
+	firstEvent := fftypes.SubOptsFirstEvent("oldest")
+	events, err := em.StreamEvents(ctx, "ns1", &firstEvent)
+	assert.NoError(t, err)
+	for range events {
+	}
+	em.NewEvents() <- 102
+}
+
+func TestStreamEventsBadFirstEvent(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	firstEvent := fftypes.SubOptsFirstEvent("middle")
+	_, err := em.StreamEvents(em.ctx, "ns1", &firstEvent)
+	assert.Regexp(t, "FF10191", err)
+}
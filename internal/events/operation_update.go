@@ -24,7 +24,7 @@ import (
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
-func (em *eventManager) operationUpdateCtx(ctx context.Context, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
+func (em *eventManager) operationUpdateCtx(ctx context.Context, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject, blockchainTX *fftypes.BlockchainTransaction, receipt *fftypes.BlockchainReceipt) error {
 	op, err := em.database.GetOperationByID(ctx, operationID)
 	if err != nil || op == nil {
 		log.L(ctx).Warnf("Operation update '%s' ignored, as it was not submitted by this node", operationID)
@@ -34,6 +34,16 @@ func (em *eventManager) operationUpdateCtx(ctx context.Context, operationID *fft
 	if err := em.database.ResolveOperation(ctx, op.ID, txState, errorMessage, opOutput); err != nil {
 		return err
 	}
+	if blockchainTX != nil {
+		if err := em.database.SetOperationBlockchainTX(ctx, op.ID, blockchainTX); err != nil {
+			return err
+		}
+	}
+	if receipt != nil {
+		if err := em.database.SetOperationReceipt(ctx, op.ID, receipt); err != nil {
+			return err
+		}
+	}
 
 	// Special handling for OpTypeTokenTransfer, which writes an event when it fails
 	if op.Type == fftypes.OpTypeTokenTransfer && txState == fftypes.OpStatusFailed {
@@ -74,11 +84,59 @@ func (em *eventManager) operationUpdateCtx(ctx context.Context, operationID *fft
 		}
 	}
 
+	// Special handling for OpTypeBlockchainPinRollup, which resolves every batch pin in the rollup
+	if op.Type == fftypes.OpTypeBlockchainPinRollup {
+		if err := em.resolveRolledUpOperations(ctx, op, txState, blockchainTXID, errorMessage); err != nil {
+			return err
+		}
+	}
+
 	return em.txHelper.AddBlockchainTX(ctx, op.Transaction, blockchainTXID)
 }
 
+func (em *eventManager) resolveRolledUpOperations(ctx context.Context, rollupOp *fftypes.Operation, txState fftypes.OpStatus, blockchainTXID, errorMessage string) error {
+	for _, opIDStr := range rollupOp.Input.GetStringArray("operations") {
+		opID, err := fftypes.ParseUUID(ctx, opIDStr)
+		if err != nil {
+			log.L(ctx).Warnf("Invalid operation '%s' in rollup operation '%s'", opIDStr, rollupOp.ID)
+			continue
+		}
+		op, err := em.database.GetOperationByID(ctx, opID)
+		if err != nil {
+			return err
+		}
+		if op == nil {
+			log.L(ctx).Warnf("Operation '%s' in rollup operation '%s' not found", opID, rollupOp.ID)
+			continue
+		}
+		// The output of the batch pin operation retains the proof of inclusion in the rollup
+		if err := em.database.ResolveOperation(ctx, op.ID, txState, errorMessage, nil); err != nil {
+			return err
+		}
+		if err := em.txHelper.AddBlockchainTX(ctx, op.Transaction, blockchainTXID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (em *eventManager) OperationUpdate(plugin fftypes.Named, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
-	return em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
-		return em.operationUpdateCtx(ctx, operationID, txState, blockchainTXID, errorMessage, opOutput)
+	err := em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
+		return em.operationUpdateCtx(ctx, operationID, txState, blockchainTXID, errorMessage, opOutput, nil, nil)
+	})
+	if err == nil && txState == fftypes.OpStatusFailed {
+		err = em.operations.StepFailed(em.ctx, operationID)
+	}
+	return err
+}
+
+func (em *eventManager) BlockchainOpUpdate(plugin fftypes.Named, operationID *fftypes.UUID, tx *fftypes.BlockchainTransaction, errorMessage string, opOutput fftypes.JSONObject, receipt *fftypes.BlockchainReceipt) error {
+	err := em.database.RunAsGroup(em.ctx, func(ctx context.Context) error {
+		return em.operationUpdateCtx(ctx, operationID, tx.Status, tx.ID, errorMessage, opOutput, tx, receipt)
 	})
+	if err == nil && tx.Status == fftypes.OpStatusFailed {
+		// Undo any other steps of the transaction that leave it half-complete
+		err = em.operations.StepFailed(em.ctx, operationID)
+	}
+	return err
 }
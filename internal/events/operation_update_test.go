@@ -23,6 +23,7 @@ import (
 
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
 	"github.com/hyperledger/firefly/mocks/txcommonmocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -45,12 +46,101 @@ func TestOperationUpdateSuccess(t *testing.T) {
 	mdi.On("GetOperationByID", em.ctx, opID).Return(&fftypes.Operation{ID: opID, Transaction: txid}, nil)
 	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "some error", info).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, txid, "0x12345").Return(nil)
+	mom := em.operations.(*operationmocks.Manager)
+	mom.On("StepFailed", em.ctx, opID).Return(nil)
 
 	err := em.OperationUpdate(mdi, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
 	mbi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestBlockchainOpUpdateWithReceipt(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mbi := &blockchainmocks.Plugin{}
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	opID := fftypes.NewUUID()
+	txid := fftypes.NewUUID()
+	info := fftypes.JSONObject{"some": "info"}
+	receipt := &fftypes.BlockchainReceipt{GasUsed: fftypes.NewFFBigInt(21000)}
+	blockchainTX := &fftypes.BlockchainTransaction{ID: "0x12345", Status: fftypes.OpStatusSucceeded}
+	mdi.On("RunAsGroup", em.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		args[1].(func(ctx context.Context) error)(em.ctx)
+	}).Return(nil)
+	mdi.On("GetOperationByID", em.ctx, opID).Return(&fftypes.Operation{ID: opID, Transaction: txid}, nil)
+	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusSucceeded, "", info).Return(nil)
+	mdi.On("SetOperationBlockchainTX", mock.Anything, opID, blockchainTX).Return(nil)
+	mdi.On("SetOperationReceipt", mock.Anything, opID, receipt).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, txid, "0x12345").Return(nil)
+
+	err := em.BlockchainOpUpdate(mbi, opID, blockchainTX, "", info, receipt)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
+func TestBlockchainOpUpdateFailedCompensate(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mbi := &blockchainmocks.Plugin{}
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mom := em.operations.(*operationmocks.Manager)
+
+	opID := fftypes.NewUUID()
+	txid := fftypes.NewUUID()
+	blockchainTX := &fftypes.BlockchainTransaction{ID: "0x12345", Status: fftypes.OpStatusFailed}
+	mdi.On("GetOperationByID", em.ctx, opID).Return(&fftypes.Operation{ID: opID, Transaction: txid}, nil)
+	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "reverted", fftypes.JSONObject(nil)).Return(nil)
+	mdi.On("SetOperationBlockchainTX", mock.Anything, opID, blockchainTX).Return(nil)
+	mth.On("AddBlockchainTX", mock.Anything, txid, "0x12345").Return(nil)
+	mom.On("StepFailed", em.ctx, opID).Return(fmt.Errorf("pop"))
+
+	err := em.BlockchainOpUpdate(mbi, opID, blockchainTX, "reverted", nil, nil)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestBlockchainOpUpdateReceiptFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	opID := fftypes.NewUUID()
+	receipt := &fftypes.BlockchainReceipt{GasUsed: fftypes.NewFFBigInt(21000)}
+	mdi.On("GetOperationByID", em.ctx, opID).Return(&fftypes.Operation{ID: opID}, nil)
+	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusSucceeded, "", fftypes.JSONObject(nil)).Return(nil)
+	mdi.On("SetOperationReceipt", mock.Anything, opID, receipt).Return(fmt.Errorf("pop"))
+
+	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusSucceeded, "0x12345", "", nil, nil, receipt)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestBlockchainOpUpdateBlockchainTXFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	opID := fftypes.NewUUID()
+	blockchainTX := &fftypes.BlockchainTransaction{ID: "0x12345", Status: fftypes.OpStatusSucceeded}
+	mdi.On("GetOperationByID", em.ctx, opID).Return(&fftypes.Operation{ID: opID}, nil)
+	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusSucceeded, "", fftypes.JSONObject(nil)).Return(nil)
+	mdi.On("SetOperationBlockchainTX", mock.Anything, opID, blockchainTX).Return(fmt.Errorf("pop"))
+
+	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusSucceeded, "0x12345", "", nil, blockchainTX, nil)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
 }
 
 func TestOperationUpdateNotFound(t *testing.T) {
@@ -63,7 +153,7 @@ func TestOperationUpdateNotFound(t *testing.T) {
 	mdi.On("GetOperationByID", em.ctx, opID).Return(nil, fmt.Errorf("pop"))
 
 	info := fftypes.JSONObject{"some": "info"}
-	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "", "some error", info, nil, nil)
 	assert.NoError(t, err) // swallowed after logging
 
 	mdi.AssertExpectations(t)
@@ -82,7 +172,7 @@ func TestOperationUpdateError(t *testing.T) {
 	mdi.On("GetOperationByID", em.ctx, opID).Return(&fftypes.Operation{ID: opID, Transaction: txid}, nil)
 	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "some error", info).Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "0x12345", "some error", info, nil, nil)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
@@ -103,7 +193,7 @@ func TestOperationTXUpdateError(t *testing.T) {
 	mdi.On("ResolveOperation", mock.Anything, opID, fftypes.OpStatusFailed, "some error", info).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, txid, "0x12345").Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, opID, fftypes.OpStatusFailed, "0x12345", "some error", info, nil, nil)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
@@ -132,7 +222,7 @@ func TestOperationUpdateTransferFailBadData(t *testing.T) {
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info, nil, nil)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
@@ -166,7 +256,7 @@ func TestOperationUpdateTransferFail(t *testing.T) {
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info, nil, nil)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
@@ -193,7 +283,7 @@ func TestOperationUpdateTransferTransactionFail(t *testing.T) {
 	mdi.On("InsertEvent", em.ctx, mock.Anything).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info, nil, nil)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
@@ -219,7 +309,7 @@ func TestOperationUpdateTransferEventFail(t *testing.T) {
 		return e.Type == fftypes.EventTypeTransferOpFailed && e.Namespace == "ns1"
 	})).Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info, nil, nil)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
@@ -248,7 +338,7 @@ func TestOperationUpdateApprovalFailBadInput(t *testing.T) {
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info, nil, nil)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
@@ -281,7 +371,7 @@ func TestOperationUpdateApprovalFail(t *testing.T) {
 	})).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(nil)
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info, nil, nil)
 	assert.NoError(t, err)
 
 	mdi.AssertExpectations(t)
@@ -307,7 +397,7 @@ func TestOperationUpdateApprovalTransactionFail(t *testing.T) {
 	mdi.On("InsertEvent", em.ctx, mock.Anything).Return(nil)
 	mth.On("AddBlockchainTX", mock.Anything, op.Transaction, "0x12345").Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info, nil, nil)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
@@ -333,9 +423,87 @@ func TestOperationUpdateApprovalEventFail(t *testing.T) {
 		return e.Type == fftypes.EventTypeApprovalOpFailed && e.Namespace == "ns1"
 	})).Return(fmt.Errorf("pop"))
 
-	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info)
+	err := em.operationUpdateCtx(em.ctx, op.ID, fftypes.OpStatusFailed, "0x12345", "some error", info, nil, nil)
 	assert.EqualError(t, err, "pop")
 
 	mdi.AssertExpectations(t)
 	mbi.AssertExpectations(t)
 }
+
+func TestOperationUpdatePinRollup(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	rollupOp := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Type:        fftypes.OpTypeBlockchainPinRollup,
+		Transaction: fftypes.NewUUID(),
+	}
+	pinOp := &fftypes.Operation{
+		ID:          fftypes.NewUUID(),
+		Type:        fftypes.OpTypeBlockchainBatchPin,
+		Transaction: fftypes.NewUUID(),
+	}
+	missingOpID := fftypes.NewUUID()
+	rollupOp.Input = fftypes.JSONObject{
+		"operations": []string{pinOp.ID.String(), "bad", missingOpID.String()},
+	}
+	mdi.On("GetOperationByID", em.ctx, rollupOp.ID).Return(rollupOp, nil)
+	mdi.On("GetOperationByID", em.ctx, pinOp.ID).Return(pinOp, nil)
+	mdi.On("GetOperationByID", em.ctx, missingOpID).Return(nil, nil)
+	mdi.On("ResolveOperation", em.ctx, rollupOp.ID, fftypes.OpStatusSucceeded, "", fftypes.JSONObject(nil)).Return(nil)
+	mdi.On("ResolveOperation", em.ctx, pinOp.ID, fftypes.OpStatusSucceeded, "", fftypes.JSONObject(nil)).Return(nil)
+	mth.On("AddBlockchainTX", em.ctx, pinOp.Transaction, "0x12345").Return(nil)
+	mth.On("AddBlockchainTX", em.ctx, rollupOp.Transaction, "0x12345").Return(nil)
+
+	err := em.operationUpdateCtx(em.ctx, rollupOp.ID, fftypes.OpStatusSucceeded, "0x12345", "", nil, nil, nil)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
+func TestOperationUpdatePinRollupFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+
+	rollupOp := &fftypes.Operation{
+		ID:   fftypes.NewUUID(),
+		Type: fftypes.OpTypeBlockchainPinRollup,
+	}
+	pinOpID := fftypes.NewUUID()
+	rollupOp.Input = fftypes.JSONObject{
+		"operations": []string{pinOpID.String()},
+	}
+	mdi.On("GetOperationByID", em.ctx, rollupOp.ID).Return(rollupOp, nil)
+	mdi.On("GetOperationByID", em.ctx, pinOpID).Return(nil, fmt.Errorf("pop"))
+	mdi.On("ResolveOperation", em.ctx, rollupOp.ID, fftypes.OpStatusFailed, "err", fftypes.JSONObject(nil)).Return(nil)
+
+	err := em.operationUpdateCtx(em.ctx, rollupOp.ID, fftypes.OpStatusFailed, "", "err", nil, nil, nil)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestResolveRolledUpOperationsFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+	mdi := em.database.(*databasemocks.Plugin)
+	mth := em.txHelper.(*txcommonmocks.Helper)
+
+	pinOp := &fftypes.Operation{ID: fftypes.NewUUID(), Transaction: fftypes.NewUUID()}
+	rollupOp := &fftypes.Operation{
+		ID:    fftypes.NewUUID(),
+		Input: fftypes.JSONObject{"operations": []string{pinOp.ID.String()}},
+	}
+	mdi.On("GetOperationByID", em.ctx, pinOp.ID).Return(pinOp, nil)
+	mdi.On("ResolveOperation", em.ctx, pinOp.ID, fftypes.OpStatusSucceeded, "", fftypes.JSONObject(nil)).Return(fmt.Errorf("pop")).Once()
+	err := em.resolveRolledUpOperations(em.ctx, rollupOp, fftypes.OpStatusSucceeded, "0x12345", "")
+	assert.EqualError(t, err, "pop")
+
+	mdi.On("ResolveOperation", em.ctx, pinOp.ID, fftypes.OpStatusSucceeded, "", fftypes.JSONObject(nil)).Return(nil)
+	mth.On("AddBlockchainTX", em.ctx, pinOp.Transaction, "0x12345").Return(fmt.Errorf("pop2"))
+	err = em.resolveRolledUpOperations(em.ctx, rollupOp, fftypes.OpStatusSucceeded, "0x12345", "")
+	assert.EqualError(t, err, "pop2")
+}
@@ -0,0 +1,84 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// isPinRollup identifies the pin of a rollup of many batch pins. Every batch pins at least one context,
+// so a pin with no contexts that refers to a payload in shared storage can only be a rollup.
+func isPinRollup(batchPin *blockchain.BatchPin) bool {
+	return len(batchPin.Contexts) == 0 && batchPin.BatchPayloadRef != ""
+}
+
+// handlePinRollupComplete retrieves the rollup from shared storage, verifies it against the Merkle root
+// that was pinned on-chain, then processes each of the batch pins in the rollup in order.
+func (em *eventManager) handlePinRollupComplete(bi blockchain.Plugin, batchPin *blockchain.BatchPin, signingKey *fftypes.VerifierRef) error {
+	var body io.ReadCloser
+	if err := em.retry.Do(em.ctx, "retrieve rollup", func(attempt int) (retry bool, err error) {
+		body, err = em.sharedstorage.RetrieveData(em.ctx, batchPin.BatchPayloadRef)
+		return err != nil, err // retry indefinitely (until context closes)
+	}); err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var rollup *fftypes.PinRollup
+	if err := json.NewDecoder(body).Decode(&rollup); err != nil {
+		log.L(em.ctx).Errorf("Failed to parse pin rollup '%s' from transaction '%s': %s", batchPin.BatchID, batchPin.Event.ProtocolID, err)
+		return nil // log and swallow unprocessable data
+	}
+	body.Close()
+
+	if !rollup.ID.Equals(batchPin.BatchID) || rollup.Namespace != batchPin.Namespace {
+		log.L(em.ctx).Errorf("Invalid pin rollup '%s' from transaction '%s' - mismatched rollup ID '%s' or namespace '%s'", batchPin.BatchID, batchPin.Event.ProtocolID, rollup.ID, rollup.Namespace)
+		return nil // move on
+	}
+	if root := fftypes.MerkleRoot(rollup.Leaves()); !root.Equals(batchPin.BatchHash) {
+		log.L(em.ctx).Errorf("Invalid pin rollup '%s' from transaction '%s' - root '%s' does not match pinned root '%s'", batchPin.BatchID, batchPin.Event.ProtocolID, root, batchPin.BatchHash)
+		return nil // move on
+	}
+
+	log.L(em.ctx).Infof("Processing pin rollup '%s' containing %d batch pins", rollup.ID, len(rollup.Pins))
+	for i, pin := range rollup.Pins {
+		if len(pin.Contexts) == 0 {
+			log.L(em.ctx).Errorf("Skipping invalid pin %d in rollup '%s' - no contexts", i, rollup.ID)
+			continue
+		}
+		event := batchPin.Event
+		event.ProtocolID = fmt.Sprintf("%s/%.6d", batchPin.Event.ProtocolID, i)
+		if err := em.BatchPinComplete(bi, &blockchain.BatchPin{
+			Namespace:       batchPin.Namespace,
+			TransactionID:   pin.TransactionID,
+			BatchID:         pin.BatchID,
+			BatchHash:       pin.BatchHash,
+			BatchPayloadRef: pin.PayloadRef,
+			Contexts:        pin.Contexts,
+			Event:           event,
+		}, signingKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/mocks/txcommonmocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestPinRollup() (*fftypes.PinRollup, *blockchain.BatchPin) {
+	rollup := &fftypes.PinRollup{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Pins: []*fftypes.RolledUpPin{
+			{TransactionID: fftypes.NewUUID(), BatchID: fftypes.NewUUID(), BatchHash: fftypes.NewRandB32(), Contexts: []*fftypes.Bytes32{fftypes.NewRandB32()}},
+			{TransactionID: fftypes.NewUUID(), BatchID: fftypes.NewUUID(), BatchHash: fftypes.NewRandB32()},
+			{TransactionID: fftypes.NewUUID(), BatchID: fftypes.NewUUID(), BatchHash: fftypes.NewRandB32(), Contexts: []*fftypes.Bytes32{fftypes.NewRandB32()}},
+		},
+	}
+	rollup.Root = fftypes.MerkleRoot(rollup.Leaves())
+	batchPin := &blockchain.BatchPin{
+		Namespace:       "ns1",
+		TransactionID:   rollup.ID,
+		BatchID:         rollup.ID,
+		BatchHash:       rollup.Root,
+		BatchPayloadRef: "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD",
+		Event: blockchain.Event{
+			BlockchainTXID: "0x12345",
+			ProtocolID:     "10/20/30",
+		},
+	}
+	return rollup, batchPin
+}
+
+func mockRetrieveRollup(em *eventManager, rollup interface{}) {
+	b, _ := json.Marshal(rollup)
+	mpi := em.sharedstorage.(*sharedstoragemocks.Plugin)
+	mpi.On("RetrieveData", mock.Anything, "Qmf412jQZiuVUtdgnB36FXFX7xg5V6KEbSJ4dpQuhkLyfD").
+		Return(ioutil.NopCloser(bytes.NewReader(b)), nil)
+}
+
+func TestPinRollupComplete(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	rollup, batchPin := newTestPinRollup()
+	mockRetrieveRollup(em, rollup)
+
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("PersistTransaction", mock.Anything, "ns1", rollup.Pins[0].TransactionID, fftypes.TransactionTypeBatchPin, "0x12345").Return(true, nil)
+	mth.On("PersistTransaction", mock.Anything, "ns1", rollup.Pins[2].TransactionID, fftypes.TransactionTypeBatchPin, "0x12345").Return(true, nil)
+	mdi := em.database.(*databasemocks.Plugin)
+	mdi.On("InsertPins", mock.Anything, mock.MatchedBy(func(pins []*fftypes.Pin) bool {
+		return pins[0].Batch.Equals(rollup.Pins[0].BatchID) && pins[0].Signer == "0xffffeeee"
+	})).Return(nil)
+	mdi.On("InsertPins", mock.Anything, mock.MatchedBy(func(pins []*fftypes.Pin) bool {
+		return pins[0].Batch.Equals(rollup.Pins[2].BatchID)
+	})).Return(nil)
+
+	err := em.BatchPinComplete(&blockchainmocks.Plugin{}, batchPin, &fftypes.VerifierRef{
+		Type:  fftypes.VerifierTypeEthAddress,
+		Value: "0xffffeeee",
+	})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+	mth.AssertExpectations(t)
+}
+
+func TestPinRollupRetrieveFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	cancel() // to avoid retry
+
+	_, batchPin := newTestPinRollup()
+	mpi := em.sharedstorage.(*sharedstoragemocks.Plugin)
+	mpi.On("RetrieveData", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	err := em.handlePinRollupComplete(&blockchainmocks.Plugin{}, batchPin, &fftypes.VerifierRef{})
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestPinRollupBadJSON(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	_, batchPin := newTestPinRollup()
+	mockRetrieveRollup(em, "not a rollup")
+
+	err := em.handlePinRollupComplete(&blockchainmocks.Plugin{}, batchPin, &fftypes.VerifierRef{})
+	assert.NoError(t, err)
+}
+
+func TestPinRollupMismatchedID(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	rollup, batchPin := newTestPinRollup()
+	rollup.ID = fftypes.NewUUID()
+	mockRetrieveRollup(em, rollup)
+
+	err := em.handlePinRollupComplete(&blockchainmocks.Plugin{}, batchPin, &fftypes.VerifierRef{})
+	assert.NoError(t, err)
+}
+
+func TestPinRollupBadRoot(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+	defer cancel()
+
+	rollup, batchPin := newTestPinRollup()
+	batchPin.BatchHash = fftypes.NewRandB32()
+	mockRetrieveRollup(em, rollup)
+
+	err := em.handlePinRollupComplete(&blockchainmocks.Plugin{}, batchPin, &fftypes.VerifierRef{})
+	assert.NoError(t, err)
+}
+
+func TestPinRollupPinFail(t *testing.T) {
+	em, cancel := newTestEventManager(t)
+
+	rollup, batchPin := newTestPinRollup()
+	mockRetrieveRollup(em, rollup)
+
+	mth := em.txHelper.(*txcommonmocks.Helper)
+	mth.On("PersistTransaction", mock.Anything, "ns1", rollup.Pins[0].TransactionID, fftypes.TransactionTypeBatchPin, "0x12345").
+		Run(func(args mock.Arguments) { cancel() }).
+		Return(false, fmt.Errorf("pop"))
+
+	err := em.handlePinRollupComplete(&blockchainmocks.Plugin{}, batchPin, &fftypes.VerifierRef{Value: "0xffffeeee"})
+	assert.Regexp(t, "FF10158", err)
+}
@@ -18,7 +18,9 @@ package events
 
 import (
 	"context"
+	"database/sql/driver"
 	"regexp"
+	"sort"
 	"sync"
 
 	"github.com/hyperledger/firefly/internal/config"
@@ -29,6 +31,7 @@ import (
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/retry"
+	"github.com/hyperledger/firefly/internal/serialization"
 	"github.com/hyperledger/firefly/internal/txcommon"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/events"
@@ -44,6 +47,7 @@ type subscription struct {
 	blockchainFilter   *blockchainFilter
 	transactionFilter  *transactionFilter
 	topicFilter        *regexp.Regexp
+	topicPattern       *fftypes.TopicPattern
 }
 
 type messageFilter struct {
@@ -77,15 +81,19 @@ type subscriptionManager struct {
 	eventNotifier             *eventNotifier
 	definitions               definitions.DefinitionHandlers
 	transports                map[string]events.Plugin
+	enrichers                 []events.Enricher
 	connections               map[string]*connection
 	mux                       sync.Mutex
+	reloadMux                 sync.Mutex
 	maxSubs                   uint64
 	durableSubs               map[fftypes.UUID]*subscription
 	cancelCtx                 func()
 	newOrUpdatedSubscriptions chan *fftypes.UUID
 	deletedSubscriptions      chan *fftypes.UUID
 	cel                       *changeEventListener
+	taps                      *subscriptionTaps
 	retry                     retry.Retry
+	deliveryPool              *deliveryPool
 }
 
 func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Manager, en *eventNotifier, sh definitions.DefinitionHandlers, txHelper txcommon.Helper) (*subscriptionManager, error) {
@@ -104,18 +112,21 @@ func newSubscriptionManager(ctx context.Context, di database.Plugin, dm data.Man
 		eventNotifier:             en,
 		definitions:               sh,
 		txHelper:                  txHelper,
-		retry: retry.Retry{
-			InitialDelay: config.GetDuration(config.SubscriptionsRetryInitialDelay),
-			MaximumDelay: config.GetDuration(config.SubscriptionsRetryMaxDelay),
-			Factor:       config.GetFloat64(config.SubscriptionsRetryFactor),
-		},
+		retry:                     *retry.NewFromConfig(config.SubscriptionsRetryInitialDelay, config.SubscriptionsRetryMaxDelay, config.SubscriptionsRetryFactor),
 	}
 	sm.cel = newChangeEventListener(ctx)
+	sm.taps = newSubscriptionTaps()
+	if poolSize := config.GetInt(config.EventsWorkerPoolSize); poolSize > 0 {
+		sm.deliveryPool = newDeliveryPool(ctx, poolSize)
+	}
 
 	err := sm.loadTransports()
 	if err == nil {
 		err = sm.initTransports()
 	}
+	if err == nil {
+		sm.enrichers, err = loadEnrichers(ctx, config.GetStringSlice(config.EventEnrichmentPlugins))
+	}
 	return sm, err
 }
 
@@ -174,6 +185,9 @@ func (sm *subscriptionManager) start() error {
 	log.L(sm.ctx).Infof("Subscription manager started - loaded %d durable subscriptions", len(sm.durableSubs))
 	go sm.subscriptionEventListener()
 	go sm.cel.changeEventListener()
+	if sm.deliveryPool != nil {
+		sm.deliveryPool.start()
+	}
 	return nil
 }
 
@@ -190,7 +204,15 @@ func (sm *subscriptionManager) subscriptionEventListener() {
 	}
 }
 
+// newOrUpdatedDurableSubscription loads the latest definition of a subscription, and starts dispatchers for it.
+// When the subscription is already active with an older definition, its dispatchers are closed and restarted
+// with the new definition, on the same connections. Changes are processed one at a time, as the lock is
+// released while the old dispatchers close, so that a concurrent update or delete cannot be overwritten
+// with a stale definition.
 func (sm *subscriptionManager) newOrUpdatedDurableSubscription(id *fftypes.UUID) {
+	sm.reloadMux.Lock()
+	defer sm.reloadMux.Unlock()
+
 	var subDef *fftypes.Subscription
 	err := sm.retry.Do(sm.ctx, "retrieve subscription", func(attempt int) (retry bool, err error) {
 		subDef, err = sm.database.GetSubscriptionByID(sm.ctx, id)
@@ -202,8 +224,6 @@ func (sm *subscriptionManager) newOrUpdatedDurableSubscription(id *fftypes.UUID)
 		return
 	}
 
-	log.L(sm.ctx).Infof("Created subscription %s:%s [%s]", subDef.Namespace, subDef.Name, subDef.ID)
-
 	newSub, err := sm.parseSubscriptionDef(sm.ctx, subDef)
 	if err != nil {
 		// Swallow this, as the subscription is simply invalid
@@ -222,6 +242,7 @@ func (sm *subscriptionManager) newOrUpdatedDurableSubscription(id *fftypes.UUID)
 		}
 		// Need to close the old one
 		loaded, dispatchers := sm.closeDurabeSubscriptionLocked(subDef.ID)
+		log.L(sm.ctx).Infof("Reloading updated subscription %s:%s [%s] dispatchers=%d", subDef.Namespace, subDef.Name, subDef.ID, len(dispatchers))
 		if loaded {
 			// Outside the lock, close out the active dispatchers
 			sm.mux.Unlock()
@@ -230,6 +251,8 @@ func (sm *subscriptionManager) newOrUpdatedDurableSubscription(id *fftypes.UUID)
 			}
 			sm.mux.Lock()
 		}
+	} else {
+		log.L(sm.ctx).Infof("Created subscription %s:%s [%s]", subDef.Namespace, subDef.Name, subDef.ID)
 	}
 	sm.durableSubs[*subDef.ID] = newSub
 	for _, conn := range sm.connections {
@@ -256,6 +279,9 @@ func (sm *subscriptionManager) closeDurabeSubscriptionLocked(id *fftypes.UUID) (
 }
 
 func (sm *subscriptionManager) deletedDurableSubscription(id *fftypes.UUID) {
+	sm.reloadMux.Lock()
+	defer sm.reloadMux.Unlock()
+
 	sm.mux.Lock()
 	loaded, dispatchers := sm.closeDurabeSubscriptionLocked(id)
 	sm.mux.Unlock()
@@ -273,9 +299,42 @@ func (sm *subscriptionManager) deletedDurableSubscription(id *fftypes.UUID) {
 	}
 }
 
-func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef *fftypes.Subscription) (sub *subscription, err error) {
-	filter := subDef.Filter
+// rewindDurableSubscription persists a new offset for a durable subscription, and rewinds the elected dispatcher
+// if the subscription is active on this node. Reloads are held off, so a dispatcher closing for a reload cannot
+// flush its old offset over the new one.
+func (sm *subscriptionManager) rewindDurableSubscription(ctx context.Context, subDef *fftypes.Subscription, offset int64) (*fftypes.Offset, error) {
+	sm.reloadMux.Lock()
+	defer sm.reloadMux.Unlock()
 
+	newOffset := &fftypes.Offset{
+		Type:    fftypes.OffsetTypeSubscription,
+		Name:    subDef.ID.String(),
+		Current: offset,
+	}
+	if err := sm.database.UpsertOffset(ctx, newOffset, true); err != nil {
+		return nil, err
+	}
+
+	sm.mux.Lock()
+	var dispatchers []*eventDispatcher
+	for _, conn := range sm.connections {
+		if d, ok := conn.dispatchers[*subDef.ID]; ok {
+			dispatchers = append(dispatchers, d)
+		}
+	}
+	sm.mux.Unlock()
+
+	rewound := 0
+	for _, d := range dispatchers {
+		if d.rewind(offset) {
+			rewound++
+		}
+	}
+	log.L(ctx).Infof("Rewound subscription %s to offset %d active=%d", subDef.ID, offset, rewound)
+	return newOffset, nil
+}
+
+func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef *fftypes.Subscription) (sub *subscription, err error) {
 	transport, ok := sm.transports[subDef.Transport]
 	if !ok {
 		return nil, i18n.NewError(ctx, i18n.MsgUnknownEventTransportPlugin, subDef.Transport)
@@ -285,6 +344,46 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		return nil, err
 	}
 
+	if ordering := subDef.Options.Ordering; ordering != nil && *ordering != fftypes.SubOptsOrderingSequence && *ordering != fftypes.SubOptsOrderingPriority {
+		return nil, i18n.NewError(ctx, i18n.MsgInvalidSubscriptionOrdering, *ordering)
+	}
+
+	if format := subDef.Options.Serialization; format != nil {
+		if !serialization.Valid(*format) {
+			return nil, i18n.NewError(ctx, i18n.MsgInvalidSerialization, *format)
+		}
+		if serialization.Binary(format) && !transport.Capabilities().Serialization {
+			return nil, i18n.NewError(ctx, i18n.MsgSerializationNotSupported, subDef.Transport, *format)
+		}
+	}
+
+	return parseSubscriptionFilter(ctx, subDef)
+}
+
+// addNamespaceCriteria restricts a query of events to the namespaces a subscription spans. As events are sequenced
+// across all namespaces, a single offset tracks the progress of a subscription that spans multiple namespaces.
+func addNamespaceCriteria(af database.AndFilter, ns string, filter *fftypes.SubscriptionFilter) database.AndFilter {
+	fb := af.Builder()
+	namespaces := []driver.Value{ns}
+	for _, fns := range filter.Namespaces {
+		if fns == fftypes.SubscriptionAllNamespaces {
+			return af
+		}
+		if fns != ns {
+			namespaces = append(namespaces, fns)
+		}
+	}
+	if len(namespaces) == 1 {
+		return af.Condition(fb.Eq("namespace", ns))
+	}
+	return af.Condition(fb.In("namespace", namespaces))
+}
+
+// parseSubscriptionFilter compiles the regular expressions in the filter of a subscription,
+// independently of the transport it is delivered over
+func parseSubscriptionFilter(ctx context.Context, subDef *fftypes.Subscription) (sub *subscription, err error) {
+	filter := subDef.Filter
+
 	var eventFilter *regexp.Regexp
 	if filter.Events != "" {
 		eventFilter, err = regexp.Compile(filter.Events)
@@ -343,6 +442,13 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		}
 	}
 
+	var topicPattern *fftypes.TopicPattern
+	if filter.TopicPattern != "" {
+		if topicPattern, err = fftypes.ParseTopicPattern(ctx, filter.TopicPattern); err != nil {
+			return nil, err
+		}
+	}
+
 	var authorFilter *regexp.Regexp
 	if filter.DeprecatedAuthor != "" {
 		log.L(ctx).Warnf("Your subscription filter uses the deprecated 'author' key - please change to 'message.author' instead")
@@ -365,6 +471,7 @@ func (sm *subscriptionManager) parseSubscriptionDef(ctx context.Context, subDef
 		definition:         subDef,
 		eventMatcher:       eventFilter,
 		topicFilter:        topicFilter,
+		topicPattern:       topicPattern,
 		messageFilter: &messageFilter{
 			tagFilter:    tagFilter,
 			groupFilter:  groupFilter,
@@ -476,7 +583,7 @@ func (sm *subscriptionManager) matchSubToConnLocked(conn *connection, sub *subsc
 	}
 	if conn.transport == sub.definition.Transport && conn.matcher(sub.definition.SubscriptionRef) {
 		if _, ok := conn.dispatchers[*sub.definition.ID]; !ok {
-			dispatcher := newEventDispatcher(sm.ctx, conn.ei, sm.database, sm.data, sm.definitions, conn.id, sub, sm.eventNotifier, sm.cel, sm.txHelper)
+			dispatcher := newEventDispatcher(sm.ctx, conn.ei, sm.database, sm.data, sm.definitions, conn.id, sub, sm.eventNotifier, sm.cel, sm.taps, sm.txHelper, sm.enrichers, sm.deliveryPool)
 			conn.dispatchers[*sub.definition.ID] = dispatcher
 			dispatcher.start()
 		}
@@ -513,7 +620,7 @@ func (sm *subscriptionManager) ephemeralSubscription(ei events.Plugin, connID, n
 	}
 
 	// Create the dispatcher, and start immediately
-	dispatcher := newEventDispatcher(sm.ctx, ei, sm.database, sm.data, sm.definitions, connID, newSub, sm.eventNotifier, sm.cel, sm.txHelper)
+	dispatcher := newEventDispatcher(sm.ctx, ei, sm.database, sm.data, sm.definitions, connID, newSub, sm.eventNotifier, sm.cel, sm.taps, sm.txHelper, sm.enrichers, sm.deliveryPool)
 	dispatcher.start()
 
 	conn.dispatchers[*subID] = dispatcher
@@ -544,6 +651,30 @@ func (sm *subscriptionManager) connnectionClosed(ei events.Plugin, connID string
 	}
 }
 
+// queueDepths returns the events in flight on every active dispatcher, sorted by connection and subscription
+func (sm *subscriptionManager) queueDepths() []*fftypes.DispatcherQueueDepth {
+	sm.mux.Lock()
+	var dispatchers []*eventDispatcher
+	for _, conn := range sm.connections {
+		for _, d := range conn.dispatchers {
+			dispatchers = append(dispatchers, d)
+		}
+	}
+	sm.mux.Unlock()
+
+	depths := make([]*fftypes.DispatcherQueueDepth, len(dispatchers))
+	for i, d := range dispatchers {
+		depths[i] = d.queueDepth()
+	}
+	sort.Slice(depths, func(i, j int) bool {
+		if depths[i].Connection != depths[j].Connection {
+			return depths[i].Connection < depths[j].Connection
+		}
+		return depths[i].Subscription.ID.String() < depths[j].Subscription.ID.String()
+	})
+	return depths
+}
+
 func (sm *subscriptionManager) deliveryResponse(ei events.Plugin, connID string, inflight *fftypes.EventDeliveryResponse) {
 	sm.mux.Lock()
 	var dispatcher *eventDispatcher
@@ -27,6 +27,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/definitionsmocks"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -173,6 +174,17 @@ func TestSubManagerBadPlugin(t *testing.T) {
 	assert.Regexp(t, "FF10172", err)
 }
 
+func TestSubManagerBadEnricher(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := txcommon.NewTransactionHelper(mdi, mdm)
+	config.Reset()
+	config.Set(config.EventTransportsEnabled, []string{})
+	config.Set(config.EventEnrichmentPlugins, []string{"/missing/enricher.so"})
+	_, err := newSubscriptionManager(context.Background(), mdi, mdm, newEventNotifier(context.Background(), "ut"), nil, txHelper)
+	assert.Regexp(t, "FF10526", err)
+}
+
 func TestSubManagerTransportInitError(t *testing.T) {
 	mei := &eventsmocks.PluginAll{}
 	mei.On("Name").Return("ut")
@@ -245,6 +257,21 @@ func TestStartSubRestoreOkSubsOK(t *testing.T) {
 	assert.NoError(t, err) // swallowed and startup continues
 }
 
+func TestStartSubDeliveryPool(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+
+	config.Set(config.EventsWorkerPoolSize, 2)
+	sm, err := newSubscriptionManager(sm.ctx, mdi, sm.data, sm.eventNotifier, sm.definitions, sm.txHelper)
+	assert.NoError(t, err)
+	assert.NotNil(t, sm.deliveryPool)
+	err = sm.start()
+	assert.NoError(t, err)
+}
+
 func TestCreateSubscriptionBadTransport(t *testing.T) {
 	mei := &eventsmocks.PluginAll{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -269,6 +296,72 @@ func TestCreateSubscriptionBadTransportOptions(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 }
 
+func TestCreateSubscriptionBadOrdering(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	ordering := fftypes.SubOptsOrdering("random")
+	_, err := sm.parseSubscriptionDef(sm.ctx, &fftypes.Subscription{
+		Options: fftypes.SubscriptionOptions{
+			SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+				Ordering: &ordering,
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10482.*random", err)
+}
+
+func TestCreateSubscriptionSerialization(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	mei.On("Capabilities").Return(&events.Capabilities{Serialization: true})
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	for _, test := range []struct {
+		format fftypes.SubOptsSerialization
+		err    string
+	}{
+		{fftypes.SubOptsSerializationProtobuf, ""},
+		{fftypes.SubOptsSerializationMsgPack, ""},
+		{fftypes.SubOptsSerializationJSON, ""},
+		{"xml", "FF10498.*xml"},
+	} {
+		format := test.format
+		_, err := sm.parseSubscriptionDef(sm.ctx, &fftypes.Subscription{
+			Options: fftypes.SubscriptionOptions{
+				SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+					Serialization: &format,
+				},
+			},
+			Transport: "ut",
+		})
+		if test.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.Regexp(t, test.err, err)
+		}
+	}
+}
+
+func TestCreateSubscriptionSerializationNotSupported(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	msgpack := fftypes.SubOptsSerializationMsgPack
+	_, err := sm.parseSubscriptionDef(sm.ctx, &fftypes.Subscription{
+		Options: fftypes.SubscriptionOptions{
+			SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
+				Serialization: &msgpack,
+			},
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10499.*ut.*msgpack", err)
+}
+
 func TestCreateSubscriptionBadEventilter(t *testing.T) {
 	mei := &eventsmocks.PluginAll{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -297,6 +390,35 @@ func TestCreateSubscriptionBadTopicFilter(t *testing.T) {
 	assert.Regexp(t, "FF10171.*topic", err)
 }
 
+func TestCreateSubscriptionBadTopicPattern(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	_, err := sm.parseSubscriptionDef(sm.ctx, &fftypes.Subscription{
+		Filter: fftypes.SubscriptionFilter{
+			TopicPattern: "orders.#.uk",
+		},
+		Transport: "ut",
+	})
+	assert.Regexp(t, "FF10528", err)
+}
+
+func TestCreateSubscriptionTopicPattern(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+	sub, err := sm.parseSubscriptionDef(sm.ctx, &fftypes.Subscription{
+		Filter: fftypes.SubscriptionFilter{
+			TopicPattern: "orders.*.uk",
+		},
+		Transport: "ut",
+	})
+	assert.NoError(t, err)
+	assert.True(t, sub.topicPattern.Matches("orders.emea.uk"))
+}
+
 func TestCreateSubscriptionBadGroupFilter(t *testing.T) {
 	mei := &eventsmocks.PluginAll{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -763,6 +885,183 @@ func TestUpdatedDurableSubscriptionOK(t *testing.T) {
 	assert.NotEmpty(t, sm.durableSubs)
 }
 
+func TestUpdatedDurableSubscriptionConcurrentDelete(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+	mdi := sm.database.(*databasemocks.Plugin)
+	mei.On("ValidateOptions", mock.Anything).Return(nil)
+
+	subID := fftypes.NewUUID()
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        subID,
+			Namespace: "ns1",
+			Name:      "sub1",
+		},
+		Transport: "ut",
+	}
+	sub2 := *sub
+	sub2.Updated = fftypes.Now()
+	s := &subscription{
+		definition: sub,
+	}
+	sm.durableSubs[*subID] = s
+
+	ed, cancelEd := newTestEventDispatcher(s)
+	defer cancelEd()
+	sm.connections["conn1"] = &connection{
+		ei:        mei,
+		id:        "conn1",
+		transport: "ut",
+		matcher: func(sr fftypes.SubscriptionRef) bool {
+			return sr.Namespace == "ns1" && sr.Name == "sub1"
+		},
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subID: ed,
+		},
+	}
+
+	mdi.On("GetSubscriptionByID", mock.Anything, subID).Return(&sub2, nil)
+	mdi.On("DeleteOffset", mock.Anything, fftypes.OffsetTypeSubscription, subID.String()).Return(nil)
+
+	updated := make(chan struct{})
+	go func() {
+		sm.newOrUpdatedDurableSubscription(subID)
+		close(updated)
+	}()
+
+	// Delete while the update is waiting for the old dispatcher to close
+	<-ed.ctx.Done()
+	deleted := make(chan struct{})
+	go func() {
+		sm.deletedDurableSubscription(subID)
+		close(deleted)
+	}()
+	close(ed.closed)
+	<-updated
+	<-deleted
+
+	// The delete is applied after the update, rather than the update re-instating the subscription
+	assert.Empty(t, sm.durableSubs)
+	assert.Empty(t, sm.connections["conn1"].dispatchers)
+	mdi.AssertExpectations(t)
+}
+
+func TestRewindDurableSubscription(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	subID := fftypes.NewUUID()
+	newDispatcher := func(id *fftypes.UUID, elected bool) *eventDispatcher {
+		ed, cancelEd := newTestEventDispatcher(&subscription{
+			definition: &fftypes.Subscription{
+				SubscriptionRef: fftypes.SubscriptionRef{ID: id, Namespace: "ns1", Name: "sub1"},
+			},
+		})
+		defer cancelEd()
+		ed.elected = elected
+		ed.eventPoller.pollingOffset = 1000
+		return ed
+	}
+	ed1 := newDispatcher(subID, true)
+	ed2 := newDispatcher(subID, false)
+	ed3 := newDispatcher(fftypes.NewUUID(), true)
+	sm.connections["conn1"] = &connection{
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subID:                          ed1,
+			*ed3.subscription.definition.ID: ed3,
+		},
+	}
+	sm.connections["conn2"] = &connection{
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*subID: ed2,
+		},
+	}
+
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("UpsertOffset", mock.Anything, mock.MatchedBy(func(o *fftypes.Offset) bool {
+		return o.Type == fftypes.OffsetTypeSubscription && o.Name == subID.String() && o.Current == 99
+	}), true).Return(nil)
+
+	offset, err := sm.rewindDurableSubscription(sm.ctx, ed1.subscription.definition, 99)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(99), offset.Current)
+
+	// Only the elected dispatcher of the subscription is rewound
+	assert.Equal(t, int64(99), ed1.eventPoller.getPollingOffset())
+	assert.Equal(t, int64(99), *ed1.rewindTo)
+	assert.Nil(t, ed2.rewindTo)
+	assert.Equal(t, int64(1000), ed2.eventPoller.getPollingOffset())
+	assert.Nil(t, ed3.rewindTo)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRewindDurableSubscriptionFail(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	mdi := sm.database.(*databasemocks.Plugin)
+	mdi.On("UpsertOffset", mock.Anything, mock.Anything, true).Return(fmt.Errorf("pop"))
+
+	_, err := sm.rewindDurableSubscription(sm.ctx, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()},
+	}, 99)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestQueueDepths(t *testing.T) {
+	mei := &eventsmocks.PluginAll{}
+	sm, cancel := newTestSubManager(t, mei)
+	defer cancel()
+
+	newDispatcher := func(id string, inflight int) *eventDispatcher {
+		ed, cancelEd := newTestEventDispatcher(&subscription{
+			definition: &fftypes.Subscription{
+				SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.MustParseUUID(id), Namespace: "ns1", Name: "sub" + id[0:1]},
+			},
+		})
+		defer cancelEd()
+		for i := 0; i < inflight; i++ {
+			ed.inflight[*fftypes.NewUUID()] = &fftypes.Event{}
+		}
+		return ed
+	}
+	ed1 := newDispatcher("11111111-1111-1111-1111-111111111111", 2)
+	ed2 := newDispatcher("22222222-2222-2222-2222-222222222222", 0)
+	ed3 := newDispatcher("11111111-1111-1111-1111-111111111111", 1)
+	ed1.connID = "conn1"
+	ed2.connID = "conn1"
+	ed3.connID = "conn2"
+	sm.connections["conn1"] = &connection{
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*ed2.subscription.definition.ID: ed2,
+			*ed1.subscription.definition.ID: ed1,
+		},
+	}
+	sm.connections["conn2"] = &connection{
+		dispatchers: map[fftypes.UUID]*eventDispatcher{
+			*ed3.subscription.definition.ID: ed3,
+		},
+	}
+
+	depths := sm.queueDepths()
+	assert.Len(t, depths, 3)
+	assert.Equal(t, "conn1", depths[0].Connection)
+	assert.Equal(t, "sub1", depths[0].Subscription.Name)
+	assert.Equal(t, 2, depths[0].Inflight)
+	assert.Equal(t, "ut", depths[0].Transport)
+	assert.Equal(t, ed1.readAhead, depths[0].ReadAhead)
+	assert.Equal(t, "conn1", depths[1].Connection)
+	assert.Equal(t, "sub2", depths[1].Subscription.Name)
+	assert.Equal(t, 0, depths[1].Inflight)
+	assert.Equal(t, "conn2", depths[2].Connection)
+	assert.Equal(t, 1, depths[2].Inflight)
+}
+
 func TestMatchedSubscriptionWithLockUnknownTransport(t *testing.T) {
 	mei := &eventsmocks.PluginAll{}
 	sm, cancel := newTestSubManager(t, mei)
@@ -829,3 +1128,22 @@ func TestDeleteDurableSubscriptionOk(t *testing.T) {
 	assert.Empty(t, sm.durableSubs)
 	<-ed.closed
 }
+
+func TestAddNamespaceCriteria(t *testing.T) {
+	fb := database.EventQueryFactory.NewFilter(context.Background())
+
+	f := addNamespaceCriteria(fb.And(), "ns1", &fftypes.SubscriptionFilter{})
+	fi, err := f.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "( namespace == 'ns1' )", fi.String())
+
+	f = addNamespaceCriteria(fb.And(), "ns1", &fftypes.SubscriptionFilter{Namespaces: []string{"ns1", "ns2"}})
+	fi, err = f.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "( namespace IN ['ns1','ns2'] )", fi.String())
+
+	f = addNamespaceCriteria(fb.And(), "ns1", &fftypes.SubscriptionFilter{Namespaces: []string{"ns2", "*"}})
+	fi, err = f.Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "", fi.String())
+}
@@ -0,0 +1,161 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// tapRecordsPerEvent sizes the buffer of a tap, so a consumer reading the stream slightly
+// behind the dispatcher does not lose records (each event normally has up to four records)
+const tapRecordsPerEvent = 4
+
+// subscriptionTap traces the decisions made about the next N events on a subscription.
+// An event is admitted to the tap when it is first matched or filtered, and the tap
+// completes once every admitted event has been filtered out or acknowledged.
+type subscriptionTap struct {
+	ctx       context.Context
+	cancelCtx func()
+	subID     fftypes.UUID
+	remaining int
+	tracked   map[fftypes.UUID]bool
+	records   chan *fftypes.SubscriptionTapRecord
+	dropped   int
+	closed    bool
+}
+
+// subscriptionTaps is the set of taps attached to subscriptions across all dispatchers.
+// Taps are held here, rather than on the subscription, so they survive the subscription
+// being reloaded after an update.
+type subscriptionTaps struct {
+	mux  sync.Mutex
+	taps map[fftypes.UUID][]*subscriptionTap
+}
+
+func newSubscriptionTaps() *subscriptionTaps {
+	return &subscriptionTaps{
+		taps: make(map[fftypes.UUID][]*subscriptionTap),
+	}
+}
+
+func (st *subscriptionTaps) add(ctx context.Context, subID *fftypes.UUID, eventCount int) (<-chan *fftypes.SubscriptionTapRecord, error) {
+	maxEvents := config.GetInt(config.SubscriptionTapMaxEvents)
+	if eventCount < 1 || eventCount > maxEvents {
+		return nil, i18n.NewError(ctx, i18n.MsgTapEventCountInvalid, eventCount, maxEvents)
+	}
+	ctx, cancelCtx := context.WithTimeout(ctx, config.GetDuration(config.SubscriptionTapTimeout))
+	tap := &subscriptionTap{
+		ctx:       ctx,
+		cancelCtx: cancelCtx,
+		subID:     *subID,
+		remaining: eventCount,
+		tracked:   make(map[fftypes.UUID]bool),
+		records:   make(chan *fftypes.SubscriptionTapRecord, eventCount*tapRecordsPerEvent),
+	}
+
+	st.mux.Lock()
+	st.taps[*subID] = append(st.taps[*subID], tap)
+	st.mux.Unlock()
+	log.L(ctx).Infof("Tap attached to subscription %s for %d events", subID, eventCount)
+
+	go func() {
+		<-ctx.Done()
+		st.mux.Lock()
+		defer st.mux.Unlock()
+		st.removeLocked(tap)
+	}()
+	return tap.records, nil
+}
+
+func (st *subscriptionTaps) removeLocked(tap *subscriptionTap) {
+	if tap.closed {
+		return
+	}
+	tap.closed = true
+	tap.cancelCtx()
+	close(tap.records)
+	taps := st.taps[tap.subID]
+	for i, t := range taps {
+		if t == tap {
+			taps = append(taps[0:i], taps[i+1:]...)
+			break
+		}
+	}
+	if len(taps) == 0 {
+		delete(st.taps, tap.subID)
+	} else {
+		st.taps[tap.subID] = taps
+	}
+	log.L(tap.ctx).Infof("Tap detached from subscription %s (events_pending=%d records_dropped=%d)", &tap.subID, len(tap.tracked), tap.dropped)
+}
+
+func (st *subscriptionTaps) record(subID *fftypes.UUID, connID string, event *fftypes.Event, decision fftypes.TapDecision, info string) {
+	st.mux.Lock()
+	defer st.mux.Unlock()
+	if len(st.taps) == 0 {
+		return
+	}
+	taps := st.taps[*subID]
+	if len(taps) == 0 {
+		return
+	}
+	rec := &fftypes.SubscriptionTapRecord{
+		Timestamp:    fftypes.Now(),
+		Subscription: subID,
+		Connection:   connID,
+		Event:        event.ID,
+		Sequence:     event.Sequence,
+		EventType:    event.Type,
+		Decision:     decision,
+		Info:         info,
+	}
+	// Take a copy, as completing a tap modifies the list
+	for _, tap := range append([]*subscriptionTap{}, taps...) {
+		if st.recordTapLocked(tap, rec) {
+			st.removeLocked(tap)
+		}
+	}
+}
+
+func (st *subscriptionTaps) recordTapLocked(tap *subscriptionTap, rec *fftypes.SubscriptionTapRecord) (complete bool) {
+	if !tap.tracked[*rec.Event] {
+		// Only admit events at the start of their journey through the dispatcher
+		if tap.remaining == 0 || (rec.Decision != fftypes.TapDecisionMatched && rec.Decision != fftypes.TapDecisionFiltered) {
+			return false
+		}
+		tap.remaining--
+		tap.tracked[*rec.Event] = true
+	}
+
+	// Never block the dispatcher on a slow reader
+	select {
+	case tap.records <- rec:
+	default:
+		tap.dropped++
+	}
+
+	if rec.Decision == fftypes.TapDecisionFiltered || rec.Decision == fftypes.TapDecisionAcked {
+		delete(tap.tracked, *rec.Event)
+	}
+	return tap.remaining == 0 && len(tap.tracked) == 0
+}
@@ -0,0 +1,119 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionTapInvalidCount(t *testing.T) {
+	config.Reset()
+	st := newSubscriptionTaps()
+	_, err := st.add(context.Background(), fftypes.NewUUID(), 0)
+	assert.Regexp(t, "FF10455", err)
+	_, err = st.add(context.Background(), fftypes.NewUUID(), 101)
+	assert.Regexp(t, "FF10455", err)
+}
+
+func TestSubscriptionTapCancelled(t *testing.T) {
+	config.Reset()
+	st := newSubscriptionTaps()
+	subID := fftypes.NewUUID()
+	ctx, cancel := context.WithCancel(context.Background())
+	records1, err := st.add(ctx, subID, 1)
+	assert.NoError(t, err)
+	records2, err := st.add(context.Background(), subID, 1)
+	assert.NoError(t, err)
+
+	cancel()
+	_, ok := <-records1
+	assert.False(t, ok)
+
+	ev := &fftypes.Event{ID: fftypes.NewUUID()}
+	st.record(subID, "conn1", ev, fftypes.TapDecisionFiltered, "topic")
+	rec := <-records2
+	assert.Equal(t, fftypes.TapDecisionFiltered, rec.Decision)
+	assert.Equal(t, "topic", rec.Info)
+	_, ok = <-records2
+	assert.False(t, ok)
+	assert.Empty(t, st.taps)
+}
+
+func TestSubscriptionTapTimeout(t *testing.T) {
+	config.Reset()
+	config.Set(config.SubscriptionTapTimeout, "1ms")
+	st := newSubscriptionTaps()
+	records, err := st.add(context.Background(), fftypes.NewUUID(), 1)
+	assert.NoError(t, err)
+	_, ok := <-records
+	assert.False(t, ok)
+}
+
+func TestSubscriptionTapAdmission(t *testing.T) {
+	config.Reset()
+	st := newSubscriptionTaps()
+	subID := fftypes.NewUUID()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	records, err := st.add(ctx, subID, 1)
+	assert.NoError(t, err)
+
+	// Other subscriptions, and events already past the filter when the tap was attached, are ignored
+	ev1 := &fftypes.Event{ID: fftypes.NewUUID(), Sequence: 1}
+	ev2 := &fftypes.Event{ID: fftypes.NewUUID(), Sequence: 2}
+	st.record(fftypes.NewUUID(), "conn1", ev1, fftypes.TapDecisionMatched, "")
+	st.record(subID, "conn1", ev1, fftypes.TapDecisionAcked, "")
+	st.record(subID, "conn1", ev2, fftypes.TapDecisionMatched, "")
+	st.record(subID, "conn1", ev1, fftypes.TapDecisionMatched, "")
+	st.record(subID, "conn1", ev2, fftypes.TapDecisionRejected, "")
+	st.record(subID, "conn1", ev2, fftypes.TapDecisionMatched, "")
+	st.record(subID, "conn1", ev2, fftypes.TapDecisionAcked, "")
+
+	var decisions []fftypes.TapDecision
+	for rec := range records {
+		assert.Equal(t, int64(2), rec.Sequence)
+		decisions = append(decisions, rec.Decision)
+	}
+	assert.Equal(t, []fftypes.TapDecision{
+		fftypes.TapDecisionMatched,
+		fftypes.TapDecisionRejected,
+		fftypes.TapDecisionMatched,
+		fftypes.TapDecisionAcked,
+	}, decisions)
+}
+
+func TestSubscriptionTapDropsWhenFull(t *testing.T) {
+	config.Reset()
+	st := newSubscriptionTaps()
+	subID := fftypes.NewUUID()
+	_, err := st.add(context.Background(), subID, 1)
+	assert.NoError(t, err)
+
+	ev := &fftypes.Event{ID: fftypes.NewUUID()}
+	for i := 0; i < tapRecordsPerEvent+1; i++ {
+		st.record(subID, "conn1", ev, fftypes.TapDecisionMatched, "")
+	}
+	st.mux.Lock()
+	defer st.mux.Unlock()
+	assert.Equal(t, 1, st.taps[*subID][0].dropped)
+	st.removeLocked(st.taps[*subID][0])
+}
@@ -21,6 +21,7 @@ import (
 	"sync"
 
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
@@ -37,6 +38,7 @@ type Events struct {
 	callbacks    events.Callbacks
 	mux          sync.Mutex
 	listeners    map[string][]EventListener
+	durable      map[string]EventListener
 	connID       string
 	readAhead    uint16
 }
@@ -51,6 +53,7 @@ func (se *Events) Init(ctx context.Context, prefix config.Prefix, callbacks even
 		capabilities: &events.Capabilities{},
 		callbacks:    callbacks,
 		listeners:    make(map[string][]EventListener),
+		durable:      make(map[string]EventListener),
 		readAhead:    uint16(prefix.GetInt(SystemEventsConfReadAhead)),
 		connID:       fftypes.ShortID(),
 	}
@@ -71,6 +74,15 @@ func (se *Events) ValidateOptions(options *fftypes.SubscriptionOptions) error {
 }
 
 func (se *Events) AddListener(ns string, el EventListener) error {
+	se.mux.Lock()
+	defer se.mux.Unlock()
+
+	// Every listener on a namespace shares a single subscription, so events are only delivered once
+	if _, subscribed := se.listeners[ns]; subscribed {
+		se.listeners[ns] = append(se.listeners[ns], el)
+		return nil
+	}
+
 	no := false
 	newest := fftypes.SubOptsFirstEventNewest
 	err := se.callbacks.EphemeralSubscription(se.connID, ns, &fftypes.SubscriptionFilter{ /* all events */ }, &fftypes.SubscriptionOptions{
@@ -83,15 +95,24 @@ func (se *Events) AddListener(ns string, el EventListener) error {
 	if err != nil {
 		return err
 	}
-	se.mux.Lock()
 	se.listeners[ns] = append(se.listeners[ns], el)
-	se.mux.Unlock()
 	return nil
 }
 
+// AddDurableListener registers the listener for the events of a durable subscription on the system transport.
+// Unlike the shared ephemeral subscription, the durable subscription resumes from its offset after a restart.
+func (se *Events) AddDurableListener(ns, name string, el EventListener) {
+	se.mux.Lock()
+	defer se.mux.Unlock()
+	se.durable[ns+":"+name] = el
+}
+
 func (se *Events) DeliveryRequest(connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery, data fftypes.DataArray) error {
 	se.mux.Lock()
 	defer se.mux.Unlock()
+	if sub != nil && !sub.Ephemeral {
+		return se.durableDelivery(connID, sub, event)
+	}
 	for ns, listeners := range se.listeners {
 		if event.Event.Namespace == ns {
 			for _, el := range listeners {
@@ -109,3 +130,19 @@ func (se *Events) DeliveryRequest(connID string, sub *fftypes.Subscription, even
 	})
 	return nil
 }
+
+func (se *Events) durableDelivery(connID string, sub *fftypes.Subscription, event *fftypes.EventDelivery) error {
+	el, ok := se.durable[sub.Namespace+":"+sub.Name]
+	if !ok {
+		// The subscription can start before its listener is registered, so the event is redelivered later
+		return i18n.NewError(se.ctx, i18n.MsgSystemListenerNotRegistered, sub.Namespace, sub.Name)
+	}
+	if err := el(event); err != nil {
+		return err
+	}
+	se.callbacks.DeliveryResponse(connID, &fftypes.EventDeliveryResponse{
+		ID:           event.ID,
+		Subscription: event.Subscription,
+	})
+	return nil
+}
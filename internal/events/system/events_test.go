@@ -64,8 +64,13 @@ func TestDeliveryRequestOk(t *testing.T) {
 		return nil
 	})
 	assert.NoError(t, err)
+	err = se.AddListener("ns1", func(event *fftypes.EventDelivery) error {
+		called++
+		return nil
+	})
+	assert.NoError(t, err)
 
-	err = se.DeliveryRequest(se.connID, &fftypes.Subscription{}, &fftypes.EventDelivery{
+	err = se.DeliveryRequest(se.connID, &fftypes.Subscription{Ephemeral: true}, &fftypes.EventDelivery{
 		EnrichedEvent: fftypes.EnrichedEvent{
 			Event: fftypes.Event{
 				Namespace: "ns1",
@@ -74,7 +79,7 @@ func TestDeliveryRequestOk(t *testing.T) {
 	}, nil)
 	assert.NoError(t, err)
 
-	err = se.DeliveryRequest(se.connID, &fftypes.Subscription{}, &fftypes.EventDelivery{
+	err = se.DeliveryRequest(se.connID, &fftypes.Subscription{Ephemeral: true}, &fftypes.EventDelivery{
 		EnrichedEvent: fftypes.EnrichedEvent{
 			Event: fftypes.Event{
 				Namespace: "ns2",
@@ -83,7 +88,8 @@ func TestDeliveryRequestOk(t *testing.T) {
 	}, nil)
 	assert.NoError(t, err)
 
-	assert.Equal(t, 1, called)
+	assert.Equal(t, 2, called)
+	cbs.AssertNumberOfCalls(t, "EphemeralSubscription", 1)
 	cbs.AssertExpectations(t)
 
 }
@@ -101,7 +107,7 @@ func TestDeliveryRequestFail(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	err = se.DeliveryRequest(mock.Anything, &fftypes.Subscription{}, &fftypes.EventDelivery{
+	err = se.DeliveryRequest(mock.Anything, &fftypes.Subscription{Ephemeral: true}, &fftypes.EventDelivery{
 		EnrichedEvent: fftypes.EnrichedEvent{
 			Event: fftypes.Event{
 				Namespace: "ns1",
@@ -124,3 +130,61 @@ func TestAddListenerFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 
 }
+
+func TestDurableDeliveryRequestOk(t *testing.T) {
+
+	se, cancel := newTestEvents(t)
+	defer cancel()
+
+	cbs := se.callbacks.(*eventsmocks.Callbacks)
+	cbs.On("DeliveryResponse", se.connID, mock.Anything).Return(nil)
+
+	called := 0
+	se.AddDurableListener("ns1", "sub1", func(event *fftypes.EventDelivery) error {
+		called++
+		return nil
+	})
+
+	err := se.DeliveryRequest(se.connID, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"},
+	}, &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				Namespace: "ns1",
+			},
+		},
+	}, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, called)
+	cbs.AssertExpectations(t)
+
+}
+
+func TestDurableDeliveryRequestNoListener(t *testing.T) {
+
+	se, cancel := newTestEvents(t)
+	defer cancel()
+
+	err := se.DeliveryRequest(se.connID, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"},
+	}, &fftypes.EventDelivery{}, nil)
+	assert.Regexp(t, "FF10500", err)
+
+}
+
+func TestDurableDeliveryRequestFail(t *testing.T) {
+
+	se, cancel := newTestEvents(t)
+	defer cancel()
+
+	se.AddDurableListener("ns1", "sub1", func(event *fftypes.EventDelivery) error {
+		return fmt.Errorf("pop")
+	})
+
+	err := se.DeliveryRequest(se.connID, &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"},
+	}, &fftypes.EventDelivery{}, nil)
+	assert.EqualError(t, err, "pop")
+
+}
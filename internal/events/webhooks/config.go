@@ -21,6 +21,15 @@ import (
 	"github.com/hyperledger/firefly/internal/restclient"
 )
 
+const (
+	// DedupeWindow is how long after an event is delivered to a subscription, that a redelivery of the same event is suppressed (0 disables)
+	DedupeWindow = "dedupe.window"
+	// DedupeLimit is the maximum number of delivered events remembered for deduplication
+	DedupeLimit = "dedupe.limit"
+)
+
 func (wh *WebHooks) InitPrefix(prefix config.Prefix) {
 	restclient.InitPrefix(prefix)
+	prefix.AddKnownKey(DedupeWindow, "0")
+	prefix.AddKnownKey(DedupeLimit, 10000)
 }
@@ -26,14 +26,17 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/internal/serialization"
 	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/karlseguin/ccache"
 )
 
 type WebHooks struct {
@@ -42,6 +45,8 @@ type WebHooks struct {
 	callbacks    events.Callbacks
 	client       *resty.Client
 	connID       string
+	dedupeWindow time.Duration
+	dedupeCache  *ccache.Cache
 }
 
 type whRequest struct {
@@ -63,11 +68,19 @@ func (wh *WebHooks) Name() string { return "webhooks" }
 
 func (wh *WebHooks) Init(ctx context.Context, prefix config.Prefix, callbacks events.Callbacks) (err error) {
 	*wh = WebHooks{
-		ctx:          ctx,
-		capabilities: &events.Capabilities{},
+		ctx: ctx,
+		capabilities: &events.Capabilities{
+			Serialization: true,
+		},
 		callbacks:    callbacks,
 		client:       restclient.New(ctx, prefix),
 		connID:       fftypes.ShortID(),
+		dedupeWindow: prefix.GetDuration(DedupeWindow),
+	}
+	if wh.dedupeWindow > 0 {
+		wh.dedupeCache = ccache.New(
+			ccache.Configure().MaxSize(prefix.GetInt64(DedupeLimit)),
+		)
 	}
 	// We have a single logical connection, that matches all subscriptions
 	return callbacks.RegisterConnection(wh.connID, func(sr fftypes.SubscriptionRef) bool { return true })
@@ -170,7 +183,7 @@ func (wh *WebHooks) GetOptionsSchema(ctx context.Context) string {
 
 func (wh *WebHooks) buildRequest(options fftypes.JSONObject, firstData fftypes.JSONObject) (req *whRequest, err error) {
 	req = &whRequest{
-		r:         wh.client.R().SetDoNotParseResponse(true),
+		r:         wh.client.R().SetContext(restclient.WithoutCallTimeout(context.Background())).SetDoNotParseResponse(true),
 		url:       options.GetString("url"),
 		method:    options.GetString("method"),
 		forceJSON: options.GetBool("json"),
@@ -293,6 +306,14 @@ func (wh *WebHooks) attemptRequest(sub *fftypes.Subscription, event *fftypes.Eve
 
 	if req.method == http.MethodPost || req.method == http.MethodPatch || req.method == http.MethodPut {
 		switch {
+		case !withData && serialization.Binary(sub.Options.Serialization):
+			// We are just sending the event itself, in a binary format. Data is always sent as JSON.
+			body, err := serialization.Marshal(wh.ctx, *sub.Options.Serialization, event)
+			if err != nil {
+				return nil, nil, err
+			}
+			req.r.SetHeader("Content-Type", serialization.ContentType(*sub.Options.Serialization))
+			req.r.SetBody(body)
 		case !withData:
 			// We are just sending the event itself
 			req.r.SetBody(event)
@@ -420,6 +441,20 @@ func (wh *WebHooks) DeliveryRequest(connID string, sub *fftypes.Subscription, ev
 		return nil
 	}
 
+	if wh.isDuplicate(sub, event) {
+		// Redeliveries happen when the dispatcher rewinds to the last committed offset, such as after a
+		// restart. The event has already been sent to the webhook, so we do not send it (or a reply) again.
+		log.L(wh.ctx).Infof("Webhook skipping duplicate delivery of event '%s' within %s", event.ID, wh.dedupeWindow)
+		if reply {
+			wh.callbacks.DeliveryResponse(connID, &fftypes.EventDeliveryResponse{
+				ID:           event.ID,
+				Rejected:     false,
+				Subscription: event.Subscription,
+			})
+		}
+		return nil
+	}
+
 	// In fastack mode we drive calls in parallel to the backend, immediately acknowledging the event
 	if sub.Options.TransportOptions().GetBool("fastack") {
 		go func() {
@@ -431,3 +466,17 @@ func (wh *WebHooks) DeliveryRequest(connID string, sub *fftypes.Subscription, ev
 
 	return wh.doDelivery(connID, reply, sub, event, data)
 }
+
+// isDuplicate checks whether the event has already been delivered to the subscription within the dedupe window,
+// and records the delivery if not
+func (wh *WebHooks) isDuplicate(sub *fftypes.Subscription, event *fftypes.EventDelivery) bool {
+	if wh.dedupeCache == nil {
+		return false
+	}
+	key := fmt.Sprintf("%s:%s", sub.ID, event.ID)
+	if cached := wh.dedupeCache.Get(key); cached != nil && !cached.Expired() {
+		return true
+	}
+	wh.dedupeCache.Set(key, true, wh.dedupeWindow)
+	return false
+}
@@ -20,15 +20,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/mocks/eventsmocks"
 	"github.com/hyperledger/firefly/pkg/events"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/karlseguin/ccache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -387,6 +390,68 @@ func TestRequestNoBodyNoReply(t *testing.T) {
 	assert.True(t, called)
 }
 
+func TestRequestNoBodyProtobuf(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	eventID := fftypes.NewUUID()
+
+	called := false
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "application/x-protobuf", req.Header.Get("Content-Type"))
+		b, err := ioutil.ReadAll(req.Body)
+		assert.NoError(t, err)
+		// The first field is the 16 byte event ID
+		assert.Equal(t, append([]byte{0x0a, 0x10}, eventID[:]...), b[0:18])
+		res.WriteHeader(200)
+		called = true
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	protobuf := fftypes.SubOptsSerializationProtobuf
+	sub := &fftypes.Subscription{}
+	sub.Options.Serialization = &protobuf
+	sub.Options.TransportOptions()["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID: eventID,
+			},
+			Message: &fftypes.Message{
+				Header: fftypes.MessageHeader{
+					ID: fftypes.NewUUID(),
+				},
+			},
+		},
+	}
+
+	err := wh.DeliveryRequest(mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestRequestNoBodyBadSerialization(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+
+	format := fftypes.SubOptsSerialization("xml")
+	sub := &fftypes.Subscription{}
+	sub.Options.Serialization = &format
+	sub.Options.TransportOptions()["url"] = "http://localhost:0/myapi"
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID: fftypes.NewUUID(),
+			},
+		},
+	}
+
+	_, _, err := wh.attemptRequest(sub, event, nil)
+	assert.Regexp(t, "FF10498", err)
+}
+
 func TestRequestReplyEmptyData(t *testing.T) {
 	wh, cancel := newTestWebHooks(t)
 	defer cancel()
@@ -731,3 +796,100 @@ func TestDeliveryRequestReplyToReply(t *testing.T) {
 	err := wh.DeliveryRequest(mock.Anything, sub, event, nil)
 	assert.NoError(t, err)
 }
+
+func TestDeliveryRequestDedupe(t *testing.T) {
+	config.Reset()
+	cbs := &eventsmocks.Callbacks{}
+	cbs.On("RegisterConnection", mock.Anything, mock.Anything).Return(nil)
+	wh := &WebHooks{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svrPrefix := config.NewPluginConfig("ut.webhooks")
+	wh.InitPrefix(svrPrefix)
+	svrPrefix.Set(DedupeWindow, "1m")
+	err := wh.Init(ctx, svrPrefix, cbs)
+	assert.NoError(t, err)
+
+	requests := 0
+	r := mux.NewRouter()
+	r.HandleFunc("/myapi", func(res http.ResponseWriter, req *http.Request) {
+		requests++
+		res.WriteHeader(200)
+		res.Write([]byte(`{}`))
+	}).Methods(http.MethodPost)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID: fftypes.NewUUID(),
+		},
+	}
+	to := sub.Options.TransportOptions()
+	to["url"] = fmt.Sprintf("http://%s/myapi", server.Listener.Addr())
+	to["reply"] = true
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID: fftypes.NewUUID(),
+			},
+			Message: &fftypes.Message{
+				Header: fftypes.MessageHeader{
+					ID:   fftypes.NewUUID(),
+					Type: fftypes.MessageTypeBroadcast,
+				},
+			},
+		},
+		Subscription: sub.SubscriptionRef,
+	}
+
+	cbs.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *fftypes.EventDeliveryResponse) bool {
+		return response.Reply != nil
+	})).Return().Once()
+	cbs.On("DeliveryResponse", mock.Anything, mock.MatchedBy(func(response *fftypes.EventDeliveryResponse) bool {
+		return response.Reply == nil && !response.Rejected
+	})).Return().Once()
+
+	err = wh.DeliveryRequest(mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+	err = wh.DeliveryRequest(mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	// The same event for a different subscription is not a duplicate
+	sub2 := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID: fftypes.NewUUID(),
+		},
+	}
+	sub2.Options.TransportOptions()["url"] = to["url"]
+	err = wh.DeliveryRequest(mock.Anything, sub2, event, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	cbs.AssertExpectations(t)
+}
+
+func TestDeliveryRequestDedupeNoReply(t *testing.T) {
+	wh, cancel := newTestWebHooks(t)
+	defer cancel()
+	wh.dedupeWindow = time.Minute
+	wh.dedupeCache = ccache.New(ccache.Configure())
+
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID: fftypes.NewUUID(),
+		},
+	}
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID: fftypes.NewUUID(),
+			},
+		},
+	}
+	wh.dedupeCache.Set(fmt.Sprintf("%s:%s", sub.ID, event.ID), true, time.Minute)
+
+	err := wh.DeliveryRequest(mock.Anything, sub, event, nil)
+	assert.NoError(t, err)
+}
@@ -19,7 +19,8 @@ package websockets
 import "github.com/hyperledger/firefly/internal/config"
 
 const (
-	bufferSizeDefault = "16Kb"
+	bufferSizeDefault         = "16Kb"
+	sessionGracePeriodDefault = "0"
 )
 
 const (
@@ -27,10 +28,12 @@ const (
 	ReadBufferSize = "readBufferSize"
 	// WriteBufferSize is the write buffer size for the socket
 	WriteBufferSize = "writeBufferSize"
+	// SessionGracePeriod is how long the subscriptions of a closed connection are kept, for a client to resume them by reconnecting with its session token (0 disables sessions)
+	SessionGracePeriod = "sessionGracePeriod"
 )
 
 func (ws *WebSockets) InitPrefix(prefix config.Prefix) {
 	prefix.AddKnownKey(ReadBufferSize, bufferSizeDefault)
 	prefix.AddKnownKey(WriteBufferSize, bufferSizeDefault)
-
+	prefix.AddKnownKey(SessionGracePeriod, sessionGracePeriodDefault)
 }
@@ -0,0 +1,53 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package websockets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// A resume token lets an application reconnect an ephemeral subscription, and continue after the last
+// event it processed. It is opaque to the application, but simply encodes the namespace and the
+// sequence of the event it was delivered with.
+func newResumeToken(namespace string, sequence int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%d", namespace, sequence)))
+}
+
+func parseResumeToken(ctx context.Context, namespace, token string) (*fftypes.SubOptsFirstEvent, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgWSInvalidResumeToken, namespace)
+	}
+	split := strings.LastIndex(string(b), ":")
+	if split < 0 || string(b[0:split]) != namespace {
+		return nil, i18n.NewError(ctx, i18n.MsgWSInvalidResumeToken, namespace)
+	}
+	sequence, err := strconv.ParseInt(string(b[split+1:]), 10, 64)
+	if err != nil || sequence < 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgWSInvalidResumeToken, namespace)
+	}
+	// The first event option is exclusive, so delivery continues from the event after this sequence
+	firstEvent := fftypes.SubOptsFirstEvent(strconv.FormatInt(sequence, 10))
+	return &firstEvent, nil
+}
@@ -19,14 +19,18 @@ package websockets
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"regexp"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/serialization"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
@@ -36,6 +40,16 @@ type websocketStartedSub struct {
 	namespace string
 }
 
+// inflightEvent is an event delivered to the client, that can be redelivered if the session is resumed before it is acknowledged
+type inflightEvent struct {
+	*fftypes.EventDeliveryResponse
+	event  *fftypes.EventDelivery
+	format *fftypes.SubOptsSerialization
+}
+
+// binaryFrame is an event frame already encoded in one of the binary serialization formats
+type binaryFrame []byte
+
 type websocketConnection struct {
 	ctx                context.Context
 	ws                 *WebSockets
@@ -47,10 +61,14 @@ type websocketConnection struct {
 	receiverDone       chan struct{}
 	autoAck            bool
 	started            []*websocketStartedSub
-	inflight           []*fftypes.EventDeliveryResponse
+	inflight           []*inflightEvent
 	mux                sync.Mutex
 	closed             bool
 	changeEventMatcher *regexp.Regexp
+	session            string
+	sessionTimer       *time.Timer
+	detached           bool
+	resumedBy          *websocketConnection
 }
 
 func newConnection(pCtx context.Context, ws *WebSockets, wsConn *websocket.Conn) *websocketConnection {
@@ -89,6 +107,9 @@ func (wc *websocketConnection) processAutoStart(req *http.Request) {
 			Name:         query.Get("name"),
 			Filter:       filter,
 			ChangeEvents: query.Get("changeevents"),
+			ResumeToken:  query.Get("resumetoken"),
+			Session:      query.Get("session"),
+			Options:      autoStartOptions(query),
 		})
 		if err != nil {
 			wc.protocolError(err)
@@ -96,6 +117,19 @@ func (wc *websocketConnection) processAutoStart(req *http.Request) {
 	}
 }
 
+// autoStartOptions gives the subscription options that can be set in the auto-start query parameters
+func autoStartOptions(query url.Values) (options fftypes.SubscriptionOptions) {
+	if format := query.Get("serialization"); format != "" {
+		f := fftypes.SubOptsSerialization(format)
+		options.Serialization = &f
+	}
+	if withData, ok := query["withdata"]; ok {
+		b := len(withData) == 0 || withData[0] != "false"
+		options.WithData = &b
+	}
+	return options
+}
+
 func (wc *websocketConnection) sendLoop() {
 	l := log.L(wc.ctx)
 	defer close(wc.senderDone)
@@ -104,10 +138,16 @@ func (wc *websocketConnection) sendLoop() {
 		select {
 		case msg := <-wc.sendMessages:
 			l.Tracef("Sending: %+v", msg)
-			writer, err := wc.wsConn.NextWriter(websocket.TextMessage)
-			if err == nil {
-				err = json.NewEncoder(writer).Encode(msg)
-				_ = writer.Close()
+			var err error
+			if frame, ok := msg.(binaryFrame); ok {
+				err = wc.wsConn.WriteMessage(websocket.BinaryMessage, frame)
+			} else {
+				var writer io.WriteCloser
+				writer, err = wc.wsConn.NextWriter(websocket.TextMessage)
+				if err == nil {
+					err = json.NewEncoder(writer).Encode(msg)
+					_ = writer.Close()
+				}
 			}
 			if err != nil {
 				l.Errorf("Write failed on socket: %s", err)
@@ -182,32 +222,69 @@ func (wc *websocketConnection) dispatchChangeEvent(ce *fftypes.ChangeEvent) erro
 	})
 }
 
-func (wc *websocketConnection) dispatch(event *fftypes.EventDelivery) error {
-	inflight := &fftypes.EventDeliveryResponse{
-		ID:           event.ID,
-		Subscription: event.Subscription,
+func (wc *websocketConnection) dispatch(event *fftypes.EventDelivery, format *fftypes.SubOptsSerialization) error {
+	inflight := &inflightEvent{
+		EventDeliveryResponse: &fftypes.EventDeliveryResponse{
+			ID:           event.ID,
+			Subscription: event.Subscription,
+		},
+		event:  event,
+		format: format,
 	}
 
-	var autoAck bool
 	wc.mux.Lock()
-	autoAck = wc.autoAck
-	if !autoAck {
+	if wc.resumedBy != nil {
+		// Deliveries that were waiting for the session to be resumed go to the new connection
+		resumedBy := wc.resumedBy
+		wc.mux.Unlock()
+		return resumedBy.dispatch(event, format)
+	}
+	autoAck := wc.autoAck
+	detached := wc.detached
+	if !autoAck || detached {
 		wc.inflight = append(wc.inflight, inflight)
 	}
 	wc.mux.Unlock()
 
-	err := wc.send(event)
+	if detached {
+		// Held until the client resumes the session
+		return nil
+	}
+
+	err := wc.deliver(event, format)
 	if err != nil {
 		return err
 	}
 
 	if autoAck {
-		wc.ws.ack(wc.connID, inflight)
+		wc.ws.ack(wc.connID, inflight.EventDeliveryResponse)
 	}
 
 	return nil
 }
 
+func (wc *websocketConnection) deliver(event *fftypes.EventDelivery, format *fftypes.SubOptsSerialization) (err error) {
+	// Ephemeral subscriptions have no stored offset, so we give the application a token it
+	// can use to resume from this event if it reconnects
+	if !wc.durableSubMatcher(event.Subscription) {
+		resumable := *event
+		resumable.ResumeToken = newResumeToken(event.Subscription.Namespace, event.Sequence)
+		event = &resumable
+	}
+
+	if serialization.Binary(format) {
+		// Protocol errors and change events are still sent as JSON text frames, so
+		// binary frames on the socket are always events
+		var frame []byte
+		if frame, err = serialization.Marshal(wc.ctx, *format, event); err == nil {
+			err = wc.send(binaryFrame(frame))
+		}
+	} else {
+		err = wc.send(event)
+	}
+	return err
+}
+
 func (wc *websocketConnection) protocolError(err error) {
 	log.L(wc.ctx).Errorf("Sending protocol error to client: %s", err)
 	sendErr := wc.send(&fftypes.WSProtocolErrorPayload{
@@ -232,6 +309,10 @@ func (wc *websocketConnection) send(msg interface{}) error {
 }
 
 func (wc *websocketConnection) handleStart(start *fftypes.WSClientActionStartPayload) (err error) {
+	if start.Session != "" && wc.resumeSession(start.Session) {
+		return nil
+	}
+
 	wc.mux.Lock()
 	if start.AutoAck != nil {
 		if *start.AutoAck != wc.autoAck && len(wc.started) > 0 {
@@ -261,9 +342,74 @@ func (wc *websocketConnection) handleStart(start *fftypes.WSClientActionStartPay
 	if err != nil {
 		return err
 	}
+	if start.Ephemeral && wc.ws.sessionGracePeriod > 0 {
+		wc.mux.Lock()
+		if wc.session == "" {
+			wc.session = fftypes.NewUUID().String()
+		}
+		wc.mux.Unlock()
+		return wc.sendSession()
+	}
 	return nil
 }
 
+func (wc *websocketConnection) sendSession() error {
+	return wc.send(&fftypes.WSSessionPayload{
+		Type:    fftypes.WSSessionEventType,
+		Session: wc.session,
+	})
+}
+
+// resumeSession moves the subscriptions of a detached session onto this connection, and redelivers
+// the events that were not acknowledged before the previous connection closed
+func (wc *websocketConnection) resumeSession(token string) bool {
+	wc.mux.Lock()
+	started := len(wc.started) > 0
+	wc.mux.Unlock()
+	if started {
+		// Only the first start on a connection can resume a session
+		return false
+	}
+	prev := wc.ws.claimSession(token)
+	if prev == nil {
+		log.L(wc.ctx).Infof("WebSocket session not found, or expired - starting a new session")
+		return false
+	}
+
+	// Deliveries to the previous connection wait on its lock, so nothing overtakes the redelivered events
+	prev.mux.Lock()
+	defer prev.mux.Unlock()
+	connID := wc.connID
+	wc.mux.Lock()
+	wc.connID = prev.connID
+	wc.session = prev.session
+	wc.autoAck = prev.autoAck
+	wc.started = prev.started
+	wc.changeEventMatcher = prev.changeEventMatcher
+	wc.inflight = prev.inflight
+	redeliver := prev.inflight
+	wc.mux.Unlock()
+	prev.inflight = nil
+	prev.resumedBy = wc
+	defer wc.ws.replaceConnection(connID, wc)
+	log.L(wc.ctx).Infof("WebSocket session for connection '%s' resumed with %d events to redeliver", wc.connID, len(redeliver))
+
+	err := wc.sendSession()
+	for i := 0; err == nil && i < len(redeliver); i++ {
+		err = wc.deliver(redeliver[i].event, redeliver[i].format)
+		if err == nil && wc.autoAck {
+			wc.mux.Lock()
+			wc.inflight = wc.inflight[1:]
+			wc.mux.Unlock()
+			wc.ws.ack(wc.connID, redeliver[i].EventDeliveryResponse)
+		}
+	}
+	if err != nil {
+		log.L(wc.ctx).Errorf("Redelivery failed on resumed session: %s", err)
+	}
+	return true
+}
+
 func (wc *websocketConnection) durableSubMatcher(sr fftypes.SubscriptionRef) bool {
 	wc.mux.Lock()
 	defer wc.mux.Unlock()
@@ -275,9 +421,24 @@ func (wc *websocketConnection) durableSubMatcher(sr fftypes.SubscriptionRef) boo
 	return false
 }
 
+// ackMatchesSub checks an in-flight event is on the subscription an ack is for - which must be specified if there is more than one
+func (wc *websocketConnection) ackMatchesSub(ack *fftypes.WSClientActionAckPayload, candidate *inflightEvent) (bool, error) {
+	if ack.Subscription != nil {
+		// A subscription has been explicitly specified, so it must match
+		return (ack.Subscription.ID != nil && *ack.Subscription.ID == *candidate.Subscription.ID) ||
+			(ack.Subscription.Name == candidate.Subscription.Name && ack.Subscription.Namespace == candidate.Subscription.Namespace), nil
+	}
+	// If there's more than one started subscription, that's a problem
+	if len(wc.started) != 1 {
+		log.L(wc.ctx).Errorf("No subscription specified on ack, and there is not exactly one started subscription")
+		return false, i18n.NewError(wc.ctx, i18n.MsgWSMsgSubNotMatched)
+	}
+	return true, nil
+}
+
 func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (*fftypes.EventDeliveryResponse, error) {
 	l := log.L(wc.ctx)
-	var inflight *fftypes.EventDeliveryResponse
+	var inflight *inflightEvent
 	wc.mux.Lock()
 	defer wc.mux.Unlock()
 
@@ -285,24 +446,16 @@ func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (
 		return nil, i18n.NewError(wc.ctx, i18n.MsgWSAutoAckEnabled)
 	}
 
-	if ack.ID != nil {
-		newInflight := make([]*fftypes.EventDeliveryResponse, 0, len(wc.inflight))
+	switch {
+	case ack.ID != nil || ack.UpToSequence > 0:
+		newInflight := make([]*inflightEvent, 0, len(wc.inflight))
 		for _, candidate := range wc.inflight {
 			var match bool
-			if *candidate.ID == *ack.ID {
-				if ack.Subscription != nil {
-					// A subscription has been explicitly specified, so it must match
-					if (ack.Subscription.ID != nil && *ack.Subscription.ID == *candidate.Subscription.ID) ||
-						(ack.Subscription.Name == candidate.Subscription.Name && ack.Subscription.Namespace == candidate.Subscription.Namespace) {
-						match = true
-					}
-				} else {
-					// If there's more than one started subscription, that's a problem
-					if len(wc.started) != 1 {
-						l.Errorf("No subscription specified on ack, and there is not exactly one started subscription")
-						return nil, i18n.NewError(wc.ctx, i18n.MsgWSMsgSubNotMatched)
-					}
-					match = true
+			var err error
+			if (ack.ID != nil && *candidate.ID == *ack.ID) ||
+				(ack.ID == nil && candidate.event.Sequence <= ack.UpToSequence) {
+				if match, err = wc.ackMatchesSub(ack, candidate); err != nil {
+					return nil, err
 				}
 			}
 			// Remove from the inflight list
@@ -313,7 +466,7 @@ func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (
 			}
 		}
 		wc.inflight = newInflight
-	} else {
+	default:
 		// Just ack the front of the queue
 		if len(wc.inflight) == 0 {
 			l.Errorf("Ack received, but no messages in flight")
@@ -325,7 +478,14 @@ func (wc *websocketConnection) checkAck(ack *fftypes.WSClientActionAckPayload) (
 	if inflight == nil {
 		return nil, i18n.NewError(wc.ctx, i18n.MsgWSMsgSubNotMatched)
 	}
-	return inflight, nil
+	if ack.ID == nil && ack.UpToSequence > 0 {
+		// The core acknowledges every event in flight on the subscription, up to the sequence
+		return &fftypes.EventDeliveryResponse{
+			UpToSequence: ack.UpToSequence,
+			Subscription: inflight.Subscription,
+		}, nil
+	}
+	return inflight.EventDeliveryResponse, nil
 }
 
 func (wc *websocketConnection) handleAck(ack *fftypes.WSClientActionAckPayload) error {
@@ -348,11 +508,14 @@ func (wc *websocketConnection) close() {
 		wc.closed = true
 		_ = wc.wsConn.Close()
 		wc.cancelCtx()
+		// A connection with a session is kept for the client to resume, unless we are shutting down
+		wc.detached = wc.session != "" && wc.ws.ctx.Err() == nil
 	}
+	detached := wc.detached
 	wc.mux.Unlock()
 	// Drop lock before callback
 	if didClosed {
-		wc.ws.connClosed(wc.connID)
+		wc.ws.connClosed(wc, detached)
 	}
 }
 
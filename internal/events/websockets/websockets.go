@@ -20,6 +20,7 @@ import (
 	"context"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/hyperledger/firefly/internal/config"
@@ -30,12 +31,14 @@ import (
 )
 
 type WebSockets struct {
-	ctx          context.Context
-	capabilities *events.Capabilities
-	callbacks    events.Callbacks
-	connections  map[string]*websocketConnection
-	connMux      sync.Mutex
-	upgrader     websocket.Upgrader
+	ctx                context.Context
+	capabilities       *events.Capabilities
+	callbacks          events.Callbacks
+	connections        map[string]*websocketConnection
+	sessions           map[string]*websocketConnection
+	sessionGracePeriod time.Duration
+	connMux            sync.Mutex
+	upgrader           websocket.Upgrader
 }
 
 func (ws *WebSockets) Name() string { return "websockets" }
@@ -44,10 +47,13 @@ func (ws *WebSockets) Init(ctx context.Context, prefix config.Prefix, callbacks
 	*ws = WebSockets{
 		ctx:         ctx,
 		connections: make(map[string]*websocketConnection),
+		sessions:    make(map[string]*websocketConnection),
 		capabilities: &events.Capabilities{
-			ChangeEvents: true,
+			ChangeEvents:  true,
+			Serialization: true,
 		},
-		callbacks: callbacks,
+		callbacks:          callbacks,
+		sessionGracePeriod: prefix.GetDuration(SessionGracePeriod),
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  int(prefix.GetByteSize(ReadBufferSize)),
 			WriteBufferSize: int(prefix.GetByteSize(WriteBufferSize)),
@@ -69,12 +75,11 @@ func (ws *WebSockets) GetOptionsSchema(ctx context.Context) string {
 }
 
 func (ws *WebSockets) ValidateOptions(options *fftypes.SubscriptionOptions) error {
-	// We don't support streaming the full data over websockets
-	if options.WithData != nil && *options.WithData {
-		return i18n.NewError(ws.ctx, i18n.MsgWebsocketsNoData)
+	// Data is only inlined into each event when explicitly requested
+	if options.WithData == nil {
+		defaultFalse := false
+		options.WithData = &defaultFalse
 	}
-	forceFalse := false
-	options.WithData = &forceFalse
 	return nil
 }
 
@@ -85,7 +90,11 @@ func (ws *WebSockets) DeliveryRequest(connID string, sub *fftypes.Subscription,
 	if !ok {
 		return i18n.NewError(ws.ctx, i18n.MsgWSConnectionNotActive, connID)
 	}
-	return conn.dispatch(event)
+	var format *fftypes.SubOptsSerialization
+	if sub != nil {
+		format = sub.Options.Serialization
+	}
+	return conn.dispatch(event, format)
 }
 
 func (ws *WebSockets) ChangeEvent(connID string, ce *fftypes.ChangeEvent) {
@@ -124,6 +133,17 @@ func (ws *WebSockets) start(wc *websocketConnection, start *fftypes.WSClientActi
 		return i18n.NewError(ws.ctx, i18n.MsgWSInvalidStartAction)
 	}
 	if start.Ephemeral {
+		// The websocket is served on the main API, so cannot be used to match events across namespaces
+		if start.Filter.SpansNamespaces(start.Namespace) {
+			return i18n.NewError(ws.ctx, i18n.MsgSubscriptionNamespacesAdmin, start.Namespace)
+		}
+		if start.ResumeToken != "" {
+			firstEvent, err := parseResumeToken(ws.ctx, start.Namespace, start.ResumeToken)
+			if err != nil {
+				return err
+			}
+			start.Options.FirstEvent = firstEvent
+		}
 		return ws.callbacks.EphemeralSubscription(wc.connID, start.Namespace, &start.Filter, &start.Options)
 	}
 	// We can have multiple subscriptions on a single
@@ -132,12 +152,59 @@ func (ws *WebSockets) start(wc *websocketConnection, start *fftypes.WSClientActi
 	})
 }
 
-func (ws *WebSockets) connClosed(connID string) {
+func (ws *WebSockets) connClosed(wc *websocketConnection, detached bool) {
+	ws.connMux.Lock()
+	if detached {
+		// The connection stays registered, buffering deliveries until the session is resumed or expires
+		token := wc.session
+		ws.sessions[token] = wc
+		wc.sessionTimer = time.AfterFunc(ws.sessionGracePeriod, func() {
+			ws.sessionExpired(token)
+		})
+		ws.connMux.Unlock()
+		log.L(wc.ctx).Infof("WebSocket session detached - subscriptions are kept for %s", ws.sessionGracePeriod)
+		return
+	}
+	delete(ws.connections, wc.connID)
+	ws.connMux.Unlock()
+	// Drop lock before calling back
+	ws.callbacks.ConnnectionClosed(wc.connID)
+}
+
+func (ws *WebSockets) sessionExpired(token string) {
 	ws.connMux.Lock()
-	delete(ws.connections, connID)
+	wc, ok := ws.sessions[token]
+	if ok {
+		delete(ws.sessions, token)
+		delete(ws.connections, wc.connID)
+	}
 	ws.connMux.Unlock()
 	// Drop lock before calling back
-	ws.callbacks.ConnnectionClosed(connID)
+	if ok {
+		log.L(wc.ctx).Infof("WebSocket session expired")
+		ws.callbacks.ConnnectionClosed(wc.connID)
+	}
+}
+
+// claimSession removes a detached session, so that it can be resumed on a new connection
+func (ws *WebSockets) claimSession(token string) *websocketConnection {
+	ws.connMux.Lock()
+	defer ws.connMux.Unlock()
+	wc, ok := ws.sessions[token]
+	if !ok {
+		return nil
+	}
+	delete(ws.sessions, token)
+	wc.sessionTimer.Stop()
+	return wc
+}
+
+// replaceConnection moves a connection that resumed a session from the ID it connected with, to the ID of the session
+func (ws *WebSockets) replaceConnection(initialConnID string, wc *websocketConnection) {
+	ws.connMux.Lock()
+	delete(ws.connections, initialConnID)
+	ws.connections[wc.connID] = wc
+	ws.connMux.Unlock()
 }
 
 func (ws *WebSockets) WaitClosed() {
@@ -18,6 +18,7 @@ package websockets
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -25,7 +26,9 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/config/wsconfig"
 	"github.com/hyperledger/firefly/internal/log"
@@ -79,18 +82,29 @@ func newTestWebsockets(t *testing.T, cbs *eventsmocks.Callbacks, queryParams ...
 	}
 }
 
-func TestValidateOptionsFail(t *testing.T) {
+func TestValidateOptionsWithData(t *testing.T) {
 	cbs := &eventsmocks.Callbacks{}
 	ws, _, cancel := newTestWebsockets(t, cbs)
 	defer cancel()
 
 	yes := true
-	err := ws.ValidateOptions(&fftypes.SubscriptionOptions{
+	opts := &fftypes.SubscriptionOptions{
 		SubscriptionCoreOptions: fftypes.SubscriptionCoreOptions{
 			WithData: &yes,
 		},
-	})
-	assert.Regexp(t, "FF10244", err)
+	}
+	err := ws.ValidateOptions(opts)
+	assert.NoError(t, err)
+	assert.True(t, *opts.WithData)
+}
+
+func TestAutoStartOptionsWithData(t *testing.T) {
+	opts := autoStartOptions(url.Values{"withdata": []string{""}})
+	assert.True(t, *opts.WithData)
+	opts = autoStartOptions(url.Values{"withdata": []string{"false"}})
+	assert.False(t, *opts.WithData)
+	opts = autoStartOptions(url.Values{})
+	assert.Nil(t, opts.WithData)
 }
 
 func TestValidateOptionsOk(t *testing.T) {
@@ -323,6 +337,52 @@ func TestAutoStartReceiveAckEphemeral(t *testing.T) {
 	cbs.AssertExpectations(t)
 }
 
+func TestAutoStartSerializationMsgPack(t *testing.T) {
+	var connID string
+	cbs := &eventsmocks.Callbacks{}
+	sub := cbs.On("EphemeralSubscription",
+		mock.MatchedBy(func(s string) bool { connID = s; return true }),
+		"ns1", mock.Anything, mock.MatchedBy(func(o *fftypes.SubscriptionOptions) bool {
+			return *o.Serialization == fftypes.SubOptsSerializationMsgPack
+		})).Return(nil)
+	cbs.On("DeliveryResponse", mock.Anything, mock.Anything).Return(nil)
+
+	waitSubscribed := make(chan struct{})
+	sub.RunFn = func(a mock.Arguments) {
+		close(waitSubscribed)
+	}
+
+	ws, wsc, cancel := newTestWebsockets(t, cbs, "ephemeral", "namespace=ns1", "autoack", "serialization=msgpack")
+	defer cancel()
+
+	<-waitSubscribed
+	msgpack := fftypes.SubOptsSerializationMsgPack
+	subDef := &fftypes.Subscription{}
+	subDef.Options.Serialization = &msgpack
+	err := ws.DeliveryRequest(connID, subDef, &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{ID: fftypes.NewUUID(), Sequence: 12345},
+		},
+		Subscription: fftypes.SubscriptionRef{ID: fftypes.NewUUID()},
+	}, nil)
+	assert.NoError(t, err)
+
+	b := <-wsc.Receive()
+	assert.Equal(t, byte(0x80), b[0]&0xf0) // a fixmap
+	assert.Contains(t, string(b), "\xa8sequence\xd1\x30\x39")
+	cbs.AssertExpectations(t)
+}
+
+func TestDispatchSerializationInvalid(t *testing.T) {
+	wsc := &websocketConnection{
+		ctx:     context.Background(),
+		autoAck: true,
+	}
+	format := fftypes.SubOptsSerialization("xml")
+	err := wsc.dispatch(&fftypes.EventDelivery{}, &format)
+	assert.Regexp(t, "FF10498", err)
+}
+
 func TestAutoStartBadOptions(t *testing.T) {
 	cbs := &eventsmocks.Callbacks{}
 	_, wsc, cancel := newTestWebsockets(t, cbs, "name=missingnamespace")
@@ -342,8 +402,8 @@ func TestHandleAckWithAutoAck(t *testing.T) {
 		ctx:          context.Background(),
 		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*fftypes.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*inflightEvent{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: eventUUID}},
 		},
 		autoAck: true,
 	}
@@ -359,8 +419,8 @@ func TestHandleStartFlippingAutoAck(t *testing.T) {
 		ctx:          context.Background(),
 		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*fftypes.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*inflightEvent{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: eventUUID}},
 		},
 		autoAck: true,
 	}
@@ -446,8 +506,8 @@ func TestHandleStartWithBadChangeEventsRegex(t *testing.T) {
 		connID:       "conn1",
 		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*fftypes.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*inflightEvent{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: eventUUID}},
 		},
 		autoAck: true,
 		ws: &WebSockets{
@@ -480,8 +540,8 @@ func TestHandleAckMultipleStartedMissingSub(t *testing.T) {
 			{ephemeral: false, name: "name3", namespace: "ns1"},
 		},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*fftypes.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*inflightEvent{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: eventUUID}},
 		},
 	}
 	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{
@@ -503,8 +563,8 @@ func TestHandleAckMultipleStartedNoSubSingleMatch(t *testing.T) {
 		},
 		started:      []*websocketStartedSub{{ephemeral: false, name: "name1", namespace: "ns1"}},
 		sendMessages: make(chan interface{}, 1),
-		inflight: []*fftypes.EventDeliveryResponse{
-			{ID: eventUUID},
+		inflight: []*inflightEvent{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: eventUUID}},
 		},
 	}
 	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{
@@ -518,7 +578,7 @@ func TestHandleAckNoneInflight(t *testing.T) {
 	wsc := &websocketConnection{
 		ctx:          context.Background(),
 		sendMessages: make(chan interface{}, 1),
-		inflight:     []*fftypes.EventDeliveryResponse{},
+		inflight:     []*inflightEvent{},
 	}
 	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{})
 	assert.Regexp(t, "FF10175", err)
@@ -578,7 +638,7 @@ func TestConnectionDispatchAfterClose(t *testing.T) {
 	wsc := &websocketConnection{
 		ctx: ctx,
 	}
-	err := wsc.dispatch(&fftypes.EventDelivery{})
+	err := wsc.dispatch(&fftypes.EventDelivery{}, nil)
 	assert.Regexp(t, "FF10160", err)
 }
 
@@ -640,3 +700,391 @@ func TestWebsocketSendAfterClose(t *testing.T) {
 	err = connection.send(map[string]string{"foo": "bar"})
 	assert.Regexp(t, "FF10290", err)
 }
+
+func TestStartEphemeralResumeToken(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	ws, wsc, cancel := newTestWebsockets(t, cbs)
+	defer cancel()
+	var connID string
+	sub := cbs.On("EphemeralSubscription",
+		mock.MatchedBy(func(s string) bool { connID = s; return true }),
+		"ns1", mock.Anything, mock.Anything).Return(nil)
+
+	waitSubscribed := make(chan *fftypes.SubscriptionOptions, 2)
+	sub.RunFn = func(a mock.Arguments) {
+		waitSubscribed <- a[3].(*fftypes.SubscriptionOptions)
+	}
+
+	err := wsc.Send(context.Background(), []byte(`{"type":"start","namespace":"ns1","ephemeral":true,"autoack":true}`))
+	assert.NoError(t, err)
+
+	<-waitSubscribed
+	cbs.On("DeliveryResponse", connID, mock.Anything).Return(nil)
+	ws.DeliveryRequest(connID, nil, &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{ID: fftypes.NewUUID(), Sequence: 42},
+		},
+		Subscription: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1"},
+	}, nil)
+
+	b := <-wsc.Receive()
+	var res fftypes.EventDelivery
+	err = json.Unmarshal(b, &res)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, res.ResumeToken)
+
+	// Resuming with the token starts from after the delivered event
+	err = wsc.Send(context.Background(), []byte(`{"type":"start","namespace":"ns1","ephemeral":true,"resumeToken":"`+res.ResumeToken+`"}`))
+	assert.NoError(t, err)
+
+	opts := <-waitSubscribed
+	assert.Equal(t, fftypes.SubOptsFirstEvent("42"), *opts.FirstEvent)
+	cbs.AssertExpectations(t)
+}
+
+func TestStartEphemeralResumeTokenBad(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	_, wsc, cancel := newTestWebsockets(t, cbs, "ephemeral", "namespace=ns2", "resumetoken="+newResumeToken("ns1", 42))
+	defer cancel()
+
+	b := <-wsc.Receive()
+	var res fftypes.WSProtocolErrorPayload
+	err := json.Unmarshal(b, &res)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.WSProtocolErrorEventType, res.Type)
+	assert.Regexp(t, "FF10483", res.Error)
+}
+
+func TestParseResumeToken(t *testing.T) {
+	ctx := context.Background()
+
+	firstEvent, err := parseResumeToken(ctx, "ns1", newResumeToken("ns1", 12345))
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.SubOptsFirstEvent("12345"), *firstEvent)
+
+	_, err = parseResumeToken(ctx, "ns1", "!!!")
+	assert.Regexp(t, "FF10483", err)
+
+	_, err = parseResumeToken(ctx, "ns1", newResumeToken("ns2", 12345))
+	assert.Regexp(t, "FF10483", err)
+
+	_, err = parseResumeToken(ctx, "ns1", base64.RawURLEncoding.EncodeToString([]byte("ns1:abc")))
+	assert.Regexp(t, "FF10483", err)
+
+	_, err = parseResumeToken(ctx, "ns1", base64.RawURLEncoding.EncodeToString([]byte("ns1")))
+	assert.Regexp(t, "FF10483", err)
+}
+
+func dialTestSession(t *testing.T, wsc wsclient.WSClient, start string) (*websocket.Conn, string) {
+	conn, _, err := websocket.DefaultDialer.Dial(wsc.URL(), nil)
+	assert.NoError(t, err)
+	err = conn.WriteMessage(websocket.TextMessage, []byte(start))
+	assert.NoError(t, err)
+	var session fftypes.WSSessionPayload
+	err = conn.ReadJSON(&session)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.WSSessionEventType, session.Type)
+	assert.NotEmpty(t, session.Session)
+	return conn, session.Session
+}
+
+func waitSessionDetached(ws *WebSockets, token string) {
+	for {
+		ws.connMux.Lock()
+		_, ok := ws.sessions[token]
+		ws.connMux.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+}
+
+func readTestEvent(t *testing.T, conn *websocket.Conn) *fftypes.EventDelivery {
+	var event fftypes.EventDelivery
+	err := conn.ReadJSON(&event)
+	assert.NoError(t, err)
+	return &event
+}
+
+func newTestEvent(sequence int64) *fftypes.EventDelivery {
+	return &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{ID: fftypes.NewUUID(), Sequence: sequence},
+		},
+		Subscription: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1"},
+	}
+}
+
+func TestSessionResumeRedeliversInflight(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	ws, wsc, cancel := newTestWebsockets(t, cbs)
+	defer cancel()
+	ws.sessionGracePeriod = time.Minute
+
+	var connID string
+	cbs.On("EphemeralSubscription",
+		mock.MatchedBy(func(s string) bool { connID = s; return true }),
+		"ns1", mock.Anything, mock.Anything).Return(nil).Once()
+
+	conn1, token := dialTestSession(t, wsc, `{"type":"start","namespace":"ns1","ephemeral":true}`)
+	eventA := newTestEvent(1)
+	err := ws.DeliveryRequest(connID, nil, eventA, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, eventA.ID, readTestEvent(t, conn1).ID)
+
+	// Events delivered while the session is detached are held for the client
+	conn1.Close()
+	waitSessionDetached(ws, token)
+	eventB := newTestEvent(2)
+	err = ws.DeliveryRequest(connID, nil, eventB, nil)
+	assert.NoError(t, err)
+
+	conn2, token2 := dialTestSession(t, wsc, `{"type":"start","namespace":"ns1","ephemeral":true,"session":"`+token+`"}`)
+	defer conn2.Close()
+	assert.Equal(t, token, token2)
+	redeliveredA := readTestEvent(t, conn2)
+	assert.Equal(t, eventA.ID, redeliveredA.ID)
+	assert.Equal(t, newResumeToken("ns1", 1), redeliveredA.ResumeToken)
+	assert.Equal(t, eventB.ID, readTestEvent(t, conn2).ID)
+
+	// Acks go to the subscription of the original connection
+	acked := make(chan *fftypes.EventDeliveryResponse, 1)
+	cbs.On("DeliveryResponse", connID, mock.Anything).Return(nil).Run(func(a mock.Arguments) {
+		acked <- a[1].(*fftypes.EventDeliveryResponse)
+	})
+	err = conn2.WriteJSON(&fftypes.WSClientActionAckPayload{
+		WSClientActionBase: fftypes.WSClientActionBase{Type: fftypes.WSClientActionAck},
+		ID:                 eventA.ID,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, eventA.ID, (<-acked).ID)
+
+	eventC := newTestEvent(3)
+	err = ws.DeliveryRequest(connID, nil, eventC, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, eventC.ID, readTestEvent(t, conn2).ID)
+
+	ws.connMux.Lock()
+	assert.Len(t, ws.connections, 2) // the resumed session, and the test client
+	assert.Empty(t, ws.sessions)
+	ws.connMux.Unlock()
+	cbs.AssertExpectations(t)
+}
+
+func TestSessionResumeAutoAck(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	ws, wsc, cancel := newTestWebsockets(t, cbs)
+	defer cancel()
+	ws.sessionGracePeriod = time.Minute
+
+	var connID string
+	cbs.On("EphemeralSubscription",
+		mock.MatchedBy(func(s string) bool { connID = s; return true }),
+		"ns1", mock.Anything, mock.Anything).Return(nil).Once()
+
+	conn1, token := dialTestSession(t, wsc, `{"type":"start","namespace":"ns1","ephemeral":true,"autoack":true}`)
+	conn1.Close()
+	waitSessionDetached(ws, token)
+
+	event := newTestEvent(1)
+	err := ws.DeliveryRequest(connID, nil, event, nil)
+	assert.NoError(t, err)
+
+	// The held event is only acknowledged once it has been delivered on the resumed connection
+	acked := make(chan *fftypes.EventDeliveryResponse, 1)
+	cbs.On("DeliveryResponse", connID, mock.Anything).Return(nil).Run(func(a mock.Arguments) {
+		acked <- a[1].(*fftypes.EventDeliveryResponse)
+	})
+	conn2, _ := dialTestSession(t, wsc, `{"type":"start","namespace":"ns1","ephemeral":true,"session":"`+token+`"}`)
+	defer conn2.Close()
+	assert.Equal(t, event.ID, readTestEvent(t, conn2).ID)
+	assert.Equal(t, event.ID, (<-acked).ID)
+
+	ws.connMux.Lock()
+	assert.Empty(t, ws.connections[connID].inflight)
+	ws.connMux.Unlock()
+}
+
+func TestSessionExpires(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	config.Reset()
+	ws := &WebSockets{}
+	svrPrefix := config.NewPluginConfig("ut.websockets")
+	ws.InitPrefix(svrPrefix)
+	svrPrefix.Set(SessionGracePeriod, "1ms")
+	ws.Init(context.Background(), svrPrefix, cbs)
+	assert.Equal(t, time.Millisecond, ws.sessionGracePeriod)
+	svr := httptest.NewServer(ws)
+	defer svr.Close()
+
+	var connID string
+	cbs.On("EphemeralSubscription",
+		mock.MatchedBy(func(s string) bool { connID = s; return true }),
+		"ns1", mock.Anything, mock.Anything).Return(nil)
+	closed := make(chan string)
+	cbs.On("ConnnectionClosed", mock.Anything).Return(nil).Run(func(a mock.Arguments) {
+		closed <- a[0].(string)
+	})
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+svr.Listener.Addr().String(), nil)
+	assert.NoError(t, err)
+	err = conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"start","namespace":"ns1","ephemeral":true}`))
+	assert.NoError(t, err)
+	var session fftypes.WSSessionPayload
+	err = conn.ReadJSON(&session)
+	assert.NoError(t, err)
+	conn.Close()
+
+	assert.Equal(t, connID, <-closed)
+	ws.connMux.Lock()
+	assert.Empty(t, ws.connections)
+	assert.Empty(t, ws.sessions)
+	ws.connMux.Unlock()
+
+	// Expiry after the session was resumed is a no-op
+	ws.sessionExpired(session.Session)
+}
+
+func TestSessionResumeNotFound(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	ws, wsc, cancel := newTestWebsockets(t, cbs)
+	defer cancel()
+	ws.sessionGracePeriod = time.Minute
+
+	cbs.On("EphemeralSubscription", mock.Anything, "ns1", mock.Anything, mock.Anything).Return(nil)
+
+	conn, token := dialTestSession(t, wsc, `{"type":"start","namespace":"ns1","ephemeral":true,"session":"unknown"}`)
+	defer conn.Close()
+	assert.NotEqual(t, "unknown", token)
+
+	// A session cannot be resumed once a subscription is started, so this starts another subscription in the same session
+	err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"start","namespace":"ns1","ephemeral":true,"session":"`+token+`"}`))
+	assert.NoError(t, err)
+	var session fftypes.WSSessionPayload
+	err = conn.ReadJSON(&session)
+	assert.NoError(t, err)
+	assert.Equal(t, token, session.Session)
+	cbs.AssertNumberOfCalls(t, "EphemeralSubscription", 2)
+}
+
+func TestSessionDispatchAfterResume(t *testing.T) {
+	resumedBy := &websocketConnection{
+		ctx:          context.Background(),
+		sendMessages: make(chan interface{}, 1),
+	}
+	wsc := &websocketConnection{
+		ctx:       context.Background(),
+		resumedBy: resumedBy,
+	}
+	event := newTestEvent(1)
+	err := wsc.dispatch(event, nil)
+	assert.NoError(t, err)
+	assert.Len(t, resumedBy.inflight, 1)
+	assert.Empty(t, wsc.inflight)
+}
+
+func TestSessionResumeRedeliveryFail(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ws := &WebSockets{
+		ctx:         context.Background(),
+		connections: make(map[string]*websocketConnection),
+		sessions:    make(map[string]*websocketConnection),
+	}
+	prev := &websocketConnection{
+		ctx:          context.Background(),
+		connID:       "conn1",
+		session:      "session1",
+		sessionTimer: time.NewTimer(time.Minute),
+		autoAck:      true,
+		inflight: []*inflightEvent{
+			{EventDeliveryResponse: &fftypes.EventDeliveryResponse{}, event: newTestEvent(1)},
+		},
+	}
+	ws.sessions["session1"] = prev
+	wsc := &websocketConnection{
+		ctx:          ctx,
+		ws:           ws,
+		connID:       "conn2",
+		sendMessages: make(chan interface{}),
+	}
+	ws.connections["conn2"] = wsc
+
+	assert.True(t, wsc.resumeSession("session1"))
+	assert.Equal(t, "conn1", wsc.connID)
+	assert.Len(t, wsc.inflight, 1)
+	assert.Equal(t, wsc, ws.connections["conn1"])
+	assert.Equal(t, wsc, prev.resumedBy)
+}
+
+func TestHandleBatchAck(t *testing.T) {
+	cbs := &eventsmocks.Callbacks{}
+	sub1 := fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub1"}
+	sub2 := fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1", Name: "sub2"}
+	inflightOn := func(sub fftypes.SubscriptionRef, sequence int64) *inflightEvent {
+		event := newTestEvent(sequence)
+		event.Subscription = sub
+		return &inflightEvent{
+			EventDeliveryResponse: &fftypes.EventDeliveryResponse{ID: event.ID, Subscription: sub},
+			event:                 event,
+		}
+	}
+	wsc := &websocketConnection{
+		ctx:    context.Background(),
+		connID: "conn1",
+		ws: &WebSockets{
+			ctx:       context.Background(),
+			callbacks: cbs,
+		},
+		started: []*websocketStartedSub{
+			{ephemeral: false, name: "sub1", namespace: "ns1"},
+			{ephemeral: false, name: "sub2", namespace: "ns1"},
+		},
+		inflight: []*inflightEvent{
+			inflightOn(sub1, 1),
+			inflightOn(sub2, 2),
+			inflightOn(sub1, 3),
+			inflightOn(sub1, 4),
+		},
+	}
+	cbs.On("DeliveryResponse", "conn1", &fftypes.EventDeliveryResponse{UpToSequence: 3, Subscription: sub1}).Return(nil)
+
+	// The subscription must be specified, as more than one is started
+	err := wsc.handleAck(&fftypes.WSClientActionAckPayload{UpToSequence: 3})
+	assert.Regexp(t, "FF10175", err)
+
+	err = wsc.handleAck(&fftypes.WSClientActionAckPayload{UpToSequence: 3, Subscription: &fftypes.SubscriptionRef{ID: sub1.ID}})
+	assert.NoError(t, err)
+	assert.Len(t, wsc.inflight, 2)
+	assert.Equal(t, int64(2), wsc.inflight[0].event.Sequence)
+	assert.Equal(t, int64(4), wsc.inflight[1].event.Sequence)
+
+	// Nothing left in flight on sub1 up to this sequence
+	err = wsc.handleAck(&fftypes.WSClientActionAckPayload{UpToSequence: 3, Subscription: &fftypes.SubscriptionRef{ID: sub1.ID}})
+	assert.Regexp(t, "FF10175", err)
+	cbs.AssertExpectations(t)
+}
+
+func TestStartEphemeralCrossNamespaceRejected(t *testing.T) {
+	mcb := &eventsmocks.Callbacks{}
+	ws := &WebSockets{
+		ctx:       context.Background(),
+		callbacks: mcb,
+	}
+	wc := &websocketConnection{
+		ctx:    context.Background(),
+		connID: "conn1",
+		ws:     ws,
+	}
+	err := ws.start(wc, &fftypes.WSClientActionStartPayload{
+		Namespace: "ns1",
+		Ephemeral: true,
+		Filter: fftypes.SubscriptionFilter{
+			Namespaces: []string{fftypes.SubscriptionAllNamespaces},
+		},
+	})
+	assert.Regexp(t, "FF10558", err)
+
+	mcb.AssertExpectations(t)
+}
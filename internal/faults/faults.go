@@ -0,0 +1,32 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package faults provides hooks that delay or fail calls to plugins, according to the rules in the
+// "faults" section of the config, so that resilience behaviors (rewinds, retries, gap handling) can
+// be exercised in integration tests.
+//
+// The hooks are only active in a build with the "faults" tag. In all other builds they do nothing,
+// and any configured rules are ignored.
+package faults
+
+const (
+	// PluginDatabase is the plugin type for faults injected into database queries, where the operation is the SQL statement
+	PluginDatabase = "database"
+	// PluginDataExchange is the plugin type for faults injected into data exchange requests, where the operation is the HTTP method and URL
+	PluginDataExchange = "dataexchange"
+	// PluginBlockchain is the plugin type for faults injected into blockchain connector requests, where the operation is the HTTP method and URL
+	PluginBlockchain = "blockchain"
+)
@@ -0,0 +1,44 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !faults
+// +build !faults
+
+package faults
+
+import (
+	"context"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+)
+
+// Init warns if fault injection rules are configured, as they have no effect in this build
+func Init(ctx context.Context) error {
+	if len(config.GetObjectArray(config.FaultsRules)) > 0 {
+		log.L(ctx).Warnf("Fault injection rules are ignored, as this build does not have the 'faults' tag")
+	}
+	return nil
+}
+
+// Inject does nothing in this build
+func Inject(ctx context.Context, plugin, operation string) error {
+	return nil
+}
+
+// WrapClient does nothing in this build
+func WrapClient(client *resty.Client, plugin string) {}
@@ -0,0 +1,127 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build faults
+// +build faults
+
+package faults
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+type ruleConfig struct {
+	Plugin      string             `json:"plugin"`
+	Operation   string             `json:"operation,omitempty"`
+	Delay       fftypes.FFDuration `json:"delay,omitempty"`
+	Fail        bool               `json:"fail,omitempty"`
+	Probability float64            `json:"probability,omitempty"`
+	Count       int64              `json:"count,omitempty"`
+}
+
+type rule struct {
+	ruleConfig
+	index     int
+	operation *regexp.Regexp
+	injected  int64
+}
+
+var mux sync.Mutex
+var rules []*rule
+
+// Init loads the fault injection rules from the config, replacing any that were loaded previously
+func Init(ctx context.Context) error {
+	mux.Lock()
+	defer mux.Unlock()
+	rules = nil
+	for i, entry := range config.GetObjectArray(config.FaultsRules) {
+		r := &rule{index: i}
+		b, _ := json.Marshal(entry)
+		if err := json.Unmarshal(b, &r.ruleConfig); err != nil {
+			return i18n.NewError(ctx, i18n.MsgFaultRuleInvalid, i, err)
+		}
+		switch r.Plugin {
+		case PluginDatabase, PluginDataExchange, PluginBlockchain:
+		default:
+			return i18n.NewError(ctx, i18n.MsgFaultRuleInvalid, i, "plugin must be one of database, dataexchange or blockchain")
+		}
+		re, err := regexp.Compile(r.Operation)
+		if err != nil {
+			return i18n.NewError(ctx, i18n.MsgFaultRuleInvalid, i, err)
+		}
+		r.operation = re
+		if r.Probability <= 0 || r.Probability > 1 {
+			r.Probability = 1
+		}
+		log.L(ctx).Warnf("Fault injection rule faults.rules[%d] enabled: plugin=%s operation=%s delay=%s fail=%t probability=%.2f count=%d",
+			i, r.Plugin, r.Operation, &r.Delay, r.Fail, r.Probability, r.Count)
+		rules = append(rules, r)
+	}
+	return nil
+}
+
+// match returns the rules that apply to this call, counting each one as injected
+func match(plugin, operation string) []*rule {
+	mux.Lock()
+	defer mux.Unlock()
+	var matched []*rule
+	for _, r := range rules {
+		if r.Plugin != plugin || !r.operation.MatchString(operation) || (r.Count > 0 && r.injected >= r.Count) {
+			continue
+		}
+		if rand.Float64() < r.Probability { // nolint:gosec
+			r.injected++
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// Inject applies every matching rule to a call to a plugin, delaying the call and/or returning an error for it
+func Inject(ctx context.Context, plugin, operation string) error {
+	for _, r := range match(plugin, operation) {
+		log.L(ctx).Warnf("Injecting fault from rule faults.rules[%d] into %s operation: %s", r.index, plugin, operation)
+		if r.Delay > 0 {
+			select {
+			case <-time.After(time.Duration(r.Delay)):
+			case <-ctx.Done():
+				return i18n.NewError(ctx, i18n.MsgFaultInjected, r.index, plugin, operation)
+			}
+		}
+		if r.Fail {
+			return i18n.NewError(ctx, i18n.MsgFaultInjected, r.index, plugin, operation)
+		}
+	}
+	return nil
+}
+
+// WrapClient injects faults into each request sent by a REST client used by a plugin
+func WrapClient(client *resty.Client, plugin string) {
+	client.OnBeforeRequest(func(c *resty.Client, req *resty.Request) error {
+		return Inject(req.Context(), plugin, req.Method+" "+req.URL)
+	})
+}
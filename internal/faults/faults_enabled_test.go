@@ -0,0 +1,122 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build faults
+// +build faults
+
+package faults
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func initTestRules(t *testing.T, rules fftypes.JSONObjectArray) {
+	config.Reset()
+	config.Set(config.FaultsRules, rules)
+	assert.NoError(t, Init(context.Background()))
+}
+
+func TestInitBadRule(t *testing.T) {
+	config.Reset()
+	config.Set(config.FaultsRules, fftypes.JSONObjectArray{
+		{"plugin": "database", "fail": "sometimes"},
+	})
+	err := Init(context.Background())
+	assert.Regexp(t, "FF10449.*faults.rules\\[0\\]", err)
+}
+
+func TestInitBadPlugin(t *testing.T) {
+	config.Reset()
+	config.Set(config.FaultsRules, fftypes.JSONObjectArray{
+		{"plugin": "tokens", "fail": true},
+	})
+	err := Init(context.Background())
+	assert.Regexp(t, "FF10449.*plugin must be one of", err)
+}
+
+func TestInitBadOperation(t *testing.T) {
+	config.Reset()
+	config.Set(config.FaultsRules, fftypes.JSONObjectArray{
+		{"plugin": "database", "operation": "[", "fail": true},
+	})
+	err := Init(context.Background())
+	assert.Regexp(t, "FF10449", err)
+}
+
+func TestInjectFailWithCount(t *testing.T) {
+	initTestRules(t, fftypes.JSONObjectArray{
+		{"plugin": "database", "operation": "^INSERT INTO pins", "fail": true, "count": 2},
+	})
+	ctx := context.Background()
+	assert.NoError(t, Inject(ctx, PluginDatabase, "SELECT * FROM pins"))
+	assert.NoError(t, Inject(ctx, PluginBlockchain, "INSERT INTO pins"))
+	assert.Regexp(t, "FF10450.*database", Inject(ctx, PluginDatabase, "INSERT INTO pins (id) VALUES (?)"))
+	assert.Regexp(t, "FF10450", Inject(ctx, PluginDatabase, "INSERT INTO pins (id) VALUES (?)"))
+	assert.NoError(t, Inject(ctx, PluginDatabase, "INSERT INTO pins (id) VALUES (?)"))
+}
+
+func TestInjectDelay(t *testing.T) {
+	initTestRules(t, fftypes.JSONObjectArray{
+		{"plugin": "dataexchange", "delay": "1ms", "probability": 2},
+	})
+	assert.Equal(t, 1.0, rules[0].Probability)
+	assert.NoError(t, Inject(context.Background(), PluginDataExchange, "POST /api/v1/messages"))
+}
+
+func TestInjectDelayCancelled(t *testing.T) {
+	initTestRules(t, fftypes.JSONObjectArray{
+		{"plugin": "blockchain", "delay": "1h"},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Regexp(t, "FF10450", Inject(ctx, PluginBlockchain, "POST /"))
+}
+
+func TestInjectZeroProbability(t *testing.T) {
+	initTestRules(t, fftypes.JSONObjectArray{
+		{"plugin": "database", "fail": true},
+	})
+	rules[0].Probability = 0 // cannot be configured, as zero is treated as unset
+	assert.NoError(t, Inject(context.Background(), PluginDatabase, "SELECT 1"))
+}
+
+func TestWrapClient(t *testing.T) {
+	initTestRules(t, fftypes.JSONObjectArray{
+		{"plugin": "dataexchange", "operation": "^POST .*/fail$", "fail": true},
+	})
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		res.WriteHeader(204)
+	}))
+	defer server.Close()
+
+	client := resty.New().SetBaseURL(server.URL)
+	WrapClient(client, PluginDataExchange)
+
+	res, err := client.R().Post("/ok")
+	assert.NoError(t, err)
+	assert.Equal(t, 204, res.StatusCode())
+
+	_, err = client.R().Post("/fail")
+	assert.Regexp(t, "FF10450", err)
+}
@@ -0,0 +1,309 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/internal/sysmessaging"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var httpConfig = config.NewPluginConfig("gateway.http")
+
+// InitConfig registers the configuration of the HTTP client used to call the target networks
+func InitConfig() {
+	restclient.InitPrefix(httpConfig)
+}
+
+// Manager relays confirmed messages from this FireFly network to a namespace in another FireFly network,
+// with a different chain and membership, according to the relays configured in gateway.relays. Each message
+// is sent through the API of a node in the target network, where it is pinned again by that node. A
+// provenance record is added to the data of the relayed message, linking it back to the message, batch
+// and blockchain transaction that pinned it in this network.
+type Manager interface {
+	Start() error
+}
+
+// Relay is the configuration of a single relay, in the gateway.relays array
+type Relay struct {
+	Name      string                     `json:"name"`
+	Namespace string                     `json:"namespace"`
+	Filter    fftypes.SubscriptionFilter `json:"filter,omitempty"`
+	Target    Target                     `json:"target"`
+	Type      fftypes.MessageType        `json:"type,omitempty"`
+	Topic     string                     `json:"topic,omitempty"`
+	Tag       string                     `json:"tag,omitempty"`
+	Group     *fftypes.InputGroup        `json:"group,omitempty"`
+}
+
+// Target is the node in the other network that relayed messages are sent to
+type Target struct {
+	URL       string `json:"url"`
+	Namespace string `json:"namespace"`
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+}
+
+// Provenance links a relayed message back to the message, and the pin, in the network it came from
+type Provenance struct {
+	Network         string                `json:"network,omitempty"`
+	Namespace       string                `json:"namespace"`
+	Message         *fftypes.UUID         `json:"message"`
+	Hash            *fftypes.Bytes32      `json:"hash,omitempty"`
+	Batch           *fftypes.UUID         `json:"batch,omitempty"`
+	Transaction     *fftypes.UUID         `json:"transaction,omitempty"`
+	BlockchainEvent string                `json:"blockchainEvent,omitempty"`
+	Pins            fftypes.FFStringArray `json:"pins,omitempty"`
+}
+
+type gatewayManager struct {
+	ctx       context.Context
+	database  database.Plugin
+	data      data.Manager
+	sysevents sysmessaging.SystemEvents
+	network   string
+	client    *resty.Client
+	relays    []*Relay
+}
+
+func NewGatewayManager(ctx context.Context, di database.Plugin, dm data.Manager, se sysmessaging.SystemEvents) (Manager, error) {
+	if di == nil || dm == nil || se == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	gm := &gatewayManager{
+		ctx:       log.WithLogField(ctx, "role", "gateway-manager"),
+		database:  di,
+		data:      dm,
+		sysevents: se,
+		network:   config.GetString(config.GatewayNetwork),
+	}
+	names := make(map[string]bool)
+	for i, relayObject := range config.GetObjectArray(config.GatewayRelays) {
+		var relay *Relay
+		b, _ := json.Marshal(relayObject)
+		if err := json.Unmarshal(b, &relay); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgGatewayRelayInvalid, i, err)
+		}
+		if err := fftypes.ValidateFFNameField(ctx, relay.Name, "name"); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgGatewayRelayInvalid, i, err)
+		}
+		if names[relay.Name] {
+			return nil, i18n.NewError(ctx, i18n.MsgGatewayRelayInvalid, i, fmt.Sprintf("duplicate name '%s'", relay.Name))
+		}
+		if err := fftypes.ValidateFFNameField(ctx, relay.Namespace, "namespace"); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgGatewayRelayInvalid, i, err)
+		}
+		if relay.Target.URL == "" {
+			return nil, i18n.NewError(ctx, i18n.MsgGatewayRelayInvalid, i, "a target url is required")
+		}
+		if err := fftypes.ValidateFFNameField(ctx, relay.Target.Namespace, "target.namespace"); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgGatewayRelayInvalid, i, err)
+		}
+		switch relay.Type {
+		case "":
+			relay.Type = fftypes.MessageTypeBroadcast
+		case fftypes.MessageTypeBroadcast:
+		case fftypes.MessageTypePrivate:
+			if relay.Group == nil {
+				return nil, i18n.NewError(ctx, i18n.MsgGatewayRelayInvalid, i, "a group is required for private messages")
+			}
+		default:
+			return nil, i18n.NewError(ctx, i18n.MsgGatewayRelayInvalid, i, fmt.Sprintf("unsupported type '%s'", relay.Type))
+		}
+		relay.Target.URL = strings.TrimSuffix(relay.Target.URL, "/")
+		// Only confirmed messages are relayed, as only those have been pinned in this network
+		relay.Filter.Events = string(fftypes.EventTypeMessageConfirmed)
+		names[relay.Name] = true
+		gm.relays = append(gm.relays, relay)
+	}
+	return gm, nil
+}
+
+func (gm *gatewayManager) httpClient() *resty.Client {
+	if gm.client == nil {
+		gm.client = restclient.New(gm.ctx, httpConfig)
+	}
+	return gm.client
+}
+
+func (gm *gatewayManager) Start() error {
+	for _, relay := range gm.relays {
+		r := relay
+		listener := func(event *fftypes.EventDelivery) error {
+			return gm.relayMessage(gm.ctx, r, event.Reference)
+		}
+		if err := gm.sysevents.AddDurableSystemEventListener(gm.ctx, r.Namespace, "gateway-"+r.Name, &r.Filter, listener); err != nil {
+			return err
+		}
+		log.L(gm.ctx).Infof("Gateway relay '%s' sending %s messages from namespace '%s' to namespace '%s' at %s", r.Name, r.Type, r.Namespace, r.Target.Namespace, r.Target.URL)
+	}
+	return nil
+}
+
+// relayedIn checks whether the message was itself relayed from another network, by looking for its provenance
+func relayedIn(data fftypes.DataArray) bool {
+	for _, d := range data {
+		if obj, ok := d.Value.JSONObjectOk(true); ok {
+			if _, ok := obj["provenance"]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (gm *gatewayManager) provenance(ctx context.Context, msg *fftypes.Message) (*Provenance, error) {
+	p := &Provenance{
+		Network:   gm.network,
+		Namespace: msg.Header.Namespace,
+		Message:   msg.Header.ID,
+		Hash:      msg.Hash,
+		Batch:     msg.BatchID,
+		Pins:      msg.Pins,
+	}
+	if msg.BatchID == nil {
+		return p, nil
+	}
+	batch, err := gm.database.GetBatchByID(ctx, msg.BatchID)
+	if err != nil || batch == nil {
+		return nil, err
+	}
+	p.Transaction = batch.TX.ID
+	if batch.TX.ID != nil {
+		fb := database.BlockchainEventQueryFactory.NewFilterLimit(ctx, 1)
+		events, _, err := gm.database.GetBlockchainEvents(ctx, fb.Eq("tx.id", batch.TX.ID))
+		if err != nil {
+			return nil, err
+		}
+		if len(events) > 0 {
+			p.BlockchainEvent = events[0].ProtocolID
+		}
+	}
+	return p, nil
+}
+
+func (gm *gatewayManager) request(ctx context.Context, relay *Relay) *resty.Request {
+	req := gm.httpClient().R().SetContext(ctx)
+	if relay.Target.Username != "" {
+		req.SetBasicAuth(relay.Target.Username, relay.Target.Password)
+	}
+	return req
+}
+
+// alreadyRelayed checks the target network for a message relayed on a previous delivery of the event,
+// which happens if we fail between sending the message and acknowledging the event
+func (gm *gatewayManager) alreadyRelayed(ctx context.Context, relay *Relay, msgID *fftypes.UUID) (bool, error) {
+	var existing []*fftypes.Message
+	res, err := gm.request(ctx, relay).
+		SetQueryParam("cid", msgID.String()).
+		SetQueryParam("limit", "1").
+		SetResult(&existing).
+		Get(fmt.Sprintf("%s/api/v1/namespaces/%s/messages", relay.Target.URL, relay.Target.Namespace))
+	if err != nil || !res.IsSuccess() {
+		return false, restclient.WrapRestErr(ctx, res, err, i18n.MsgGatewayRESTErr)
+	}
+	return len(existing) > 0, nil
+}
+
+func (gm *gatewayManager) relayMessage(ctx context.Context, relay *Relay, msgID *fftypes.UUID) error {
+	msg, data, _, err := gm.data.GetMessageWithDataCached(ctx, msgID)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		log.L(ctx).Warnf("Gateway relay '%s' skipping message %s, which was not found", relay.Name, msgID)
+		return nil
+	}
+	// Loop prevention - a message that arrived from another network is never relayed out again
+	if relayedIn(data) {
+		log.L(ctx).Debugf("Gateway relay '%s' skipping message %s, which was relayed from another network", relay.Name, msgID)
+		return nil
+	}
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				CID:    msgID,
+				Tag:    msg.Header.Tag,
+				Topics: msg.Header.Topics,
+			},
+		},
+		Group: relay.Group,
+	}
+	if relay.Tag != "" {
+		in.Header.Tag = relay.Tag
+	}
+	if relay.Topic != "" {
+		in.Header.Topics = fftypes.FFStringArray{relay.Topic}
+	}
+	for _, d := range data {
+		if d.Blob != nil {
+			// Blobs are held off-chain by the members of this network, so cannot be referred to in another
+			log.L(ctx).Errorf("Gateway relay '%s' cannot relay message %s, which has blob data", relay.Name, msgID)
+			return nil
+		}
+		// Datatypes are local to this network, so only the values are relayed
+		in.InlineData = append(in.InlineData, &fftypes.DataRefOrValue{Value: d.Value})
+	}
+	provenance, err := gm.provenance(ctx, msg)
+	if err != nil {
+		return err
+	}
+	if provenance == nil {
+		log.L(ctx).Warnf("Gateway relay '%s' skipping message %s, as its batch %s was not found", relay.Name, msgID, msg.BatchID)
+		return nil
+	}
+	b, _ := json.Marshal(fftypes.JSONObject{"provenance": provenance})
+	in.InlineData = append(in.InlineData, &fftypes.DataRefOrValue{Value: fftypes.JSONAnyPtrBytes(b)})
+
+	relayed, err := gm.alreadyRelayed(ctx, relay, msgID)
+	if err != nil {
+		return err
+	}
+	if relayed {
+		log.L(ctx).Infof("Gateway relay '%s' already sent message %s", relay.Name, msgID)
+		return nil
+	}
+
+	var out fftypes.Message
+	res, err := gm.request(ctx, relay).
+		SetBody(in).
+		SetResult(&out).
+		Post(fmt.Sprintf("%s/api/v1/namespaces/%s/messages/%s", relay.Target.URL, relay.Target.Namespace, relay.Type))
+	if err != nil || !res.IsSuccess() {
+		err = restclient.WrapRestErr(ctx, res, err, i18n.MsgGatewayRESTErr)
+		if res != nil && res.StatusCode() >= 400 && res.StatusCode() < 500 {
+			// Retrying will not change the answer for a message the target network rejects
+			log.L(ctx).Errorf("Gateway relay '%s' could not send message %s: %s", relay.Name, msgID, err)
+			return nil
+		}
+		return err
+	}
+	log.L(ctx).Infof("Gateway relay '%s' sent message %s to %s in namespace '%s' at %s", relay.Name, msgID, out.Header.ID, relay.Target.Namespace, relay.Target.URL)
+	return nil
+}
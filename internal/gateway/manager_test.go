@@ -0,0 +1,419 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/events/system"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/sysmessagingmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testMessagesURL = "http://settlement.example.com/api/v1/namespaces/settlement/messages"
+
+func resetTestConfig(relays fftypes.JSONObjectArray) {
+	config.Reset()
+	InitConfig()
+	config.Set(config.GatewayNetwork, "intake-net")
+	config.Set(config.GatewayRelays, relays)
+}
+
+func newTestGateway(t *testing.T, relays fftypes.JSONObjectArray) *gatewayManager {
+	resetTestConfig(relays)
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	httpConfig.Set(restclient.HTTPCustomClient, mockedClient)
+	gm, err := NewGatewayManager(context.Background(), &databasemocks.Plugin{}, &datamocks.Manager{}, &sysmessagingmocks.SystemEvents{})
+	assert.NoError(t, err)
+	return gm.(*gatewayManager)
+}
+
+func newTestSettlementRelay(t *testing.T) (*gatewayManager, *Relay) {
+	gm := newTestGateway(t, fftypes.JSONObjectArray{
+		{"name": "settle", "namespace": "intake", "topic": "settle", "target": fftypes.JSONObject{
+			"url":       "http://settlement.example.com/",
+			"namespace": "settlement",
+			"username":  "user1",
+			"password":  "pass1",
+		}},
+	})
+	return gm, gm.relays[0]
+}
+
+func mockPinnedMessage(gm *gatewayManager, msgID *fftypes.UUID, data fftypes.DataArray) (batchID, txID *fftypes.UUID) {
+	batchID = fftypes.NewUUID()
+	txID = fftypes.NewUUID()
+	mdm := gm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        msgID,
+			Namespace: "intake",
+			Tag:       "order",
+			Topics:    fftypes.FFStringArray{"orders"},
+		},
+		BatchID: batchID,
+		Pins:    fftypes.FFStringArray{"pin1"},
+	}, data, true, nil)
+	mdi := gm.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", mock.Anything, batchID).Return(&fftypes.BatchPersisted{
+		TX: fftypes.TransactionRef{ID: txID},
+	}, nil)
+	mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return([]*fftypes.BlockchainEvent{
+		{ProtocolID: "000000000010/000020/000030"},
+	}, nil, nil)
+	return batchID, txID
+}
+
+func TestNewGatewayManagerMissingDeps(t *testing.T) {
+	_, err := NewGatewayManager(context.Background(), nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestNewGatewayManagerInvalid(t *testing.T) {
+	target := fftypes.JSONObject{"url": "http://settlement.example.com", "namespace": "settlement"}
+	for _, relays := range []fftypes.JSONObjectArray{
+		{{"name": "settle", "namespace": false}},
+		{{"name": "!bad", "namespace": "intake", "target": target}},
+		{{"name": "settle", "namespace": "intake", "target": target}, {"name": "settle", "namespace": "intake", "target": target}},
+		{{"name": "settle", "namespace": "!bad", "target": target}},
+		{{"name": "settle", "namespace": "intake", "target": fftypes.JSONObject{"namespace": "settlement"}}},
+		{{"name": "settle", "namespace": "intake", "target": fftypes.JSONObject{"url": "http://settlement.example.com"}}},
+		{{"name": "settle", "namespace": "intake", "target": target, "type": "private"}},
+		{{"name": "settle", "namespace": "intake", "target": target, "type": "transfer_broadcast"}},
+	} {
+		resetTestConfig(relays)
+		_, err := NewGatewayManager(context.Background(), &databasemocks.Plugin{}, &datamocks.Manager{}, &sysmessagingmocks.SystemEvents{})
+		assert.Regexp(t, "FF10502", err)
+	}
+}
+
+func TestStartGateway(t *testing.T) {
+	gm := newTestGateway(t, fftypes.JSONObjectArray{
+		{"name": "settle", "namespace": "intake", "filter": fftypes.JSONObject{"topic": "orders"}, "target": fftypes.JSONObject{
+			"url": "http://settlement.example.com", "namespace": "settlement",
+		}},
+		{"name": "audit", "namespace": "intake", "type": "private", "group": fftypes.JSONObject{
+			"members": []interface{}{fftypes.JSONObject{"identity": "auditor"}},
+		}, "target": fftypes.JSONObject{
+			"url": "http://audit.example.com", "namespace": "audit",
+		}},
+	})
+	defer httpmock.DeactivateAndReset()
+	assert.Equal(t, fftypes.MessageTypeBroadcast, gm.relays[0].Type)
+
+	msgID := fftypes.NewUUID()
+	mse := gm.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddDurableSystemEventListener", mock.Anything, "intake", "gateway-settle", mock.MatchedBy(func(filter *fftypes.SubscriptionFilter) bool {
+		return filter.Events == "message_confirmed" && filter.Topic == "orders"
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		mdm := gm.data.(*datamocks.Manager)
+		mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(nil, nil, false, nil)
+		err := args[4].(system.EventListener)(&fftypes.EventDelivery{
+			EnrichedEvent: fftypes.EnrichedEvent{
+				Event: fftypes.Event{Reference: msgID},
+			},
+		})
+		assert.NoError(t, err)
+	}).Return(nil)
+	mse.On("AddDurableSystemEventListener", mock.Anything, "intake", "gateway-audit", mock.Anything, mock.Anything).Return(nil)
+
+	err := gm.Start()
+	assert.NoError(t, err)
+
+	mse.AssertExpectations(t)
+}
+
+func TestStartGatewayFail(t *testing.T) {
+	gm, _ := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	mse := gm.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddDurableSystemEventListener", mock.Anything, "intake", "gateway-settle", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := gm.Start()
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRelayMessageBroadcast(t *testing.T) {
+	gm, relay := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	batchID, txID := mockPinnedMessage(gm, msgID, fftypes.DataArray{
+		{Validator: fftypes.ValidatorTypeJSON, Datatype: &fftypes.DatatypeRef{Name: "order", Version: "1"}, Value: fftypes.JSONAnyPtr(`{"id":1}`)},
+	})
+
+	httpmock.RegisterResponder("GET", testMessagesURL,
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, msgID.String(), req.URL.Query().Get("cid"))
+			username, password, _ := req.BasicAuth()
+			assert.Equal(t, "user1", username)
+			assert.Equal(t, "pass1", password)
+			return httpmock.NewJsonResponse(200, []*fftypes.Message{})
+		})
+	httpmock.RegisterResponder("POST", testMessagesURL+"/broadcast",
+		func(req *http.Request) (*http.Response, error) {
+			var in fftypes.MessageInOut
+			b, _ := ioutil.ReadAll(req.Body)
+			err := json.Unmarshal(b, &in)
+			assert.NoError(t, err)
+			assert.Equal(t, *msgID, *in.Header.CID)
+			assert.Equal(t, "order", in.Header.Tag)
+			assert.Equal(t, "settle", in.Header.Topics.String())
+			assert.Len(t, in.InlineData, 2)
+			assert.Nil(t, in.InlineData[0].Datatype)
+			assert.Equal(t, `{"id":1}`, in.InlineData[0].Value.String())
+			var provenance struct {
+				Provenance Provenance `json:"provenance"`
+			}
+			err = json.Unmarshal(in.InlineData[1].Value.Bytes(), &provenance)
+			assert.NoError(t, err)
+			assert.Equal(t, "intake-net", provenance.Provenance.Network)
+			assert.Equal(t, "intake", provenance.Provenance.Namespace)
+			assert.Equal(t, *msgID, *provenance.Provenance.Message)
+			assert.Equal(t, *batchID, *provenance.Provenance.Batch)
+			assert.Equal(t, *txID, *provenance.Provenance.Transaction)
+			assert.Equal(t, "000000000010/000020/000030", provenance.Provenance.BlockchainEvent)
+			assert.Equal(t, "pin1", provenance.Provenance.Pins.String())
+			return httpmock.NewJsonResponse(202, &fftypes.Message{
+				Header: fftypes.MessageHeader{ID: fftypes.NewUUID()},
+			})
+		})
+
+	err := gm.relayMessage(gm.ctx, relay, msgID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, httpmock.GetTotalCallCount())
+}
+
+func TestRelayMessagePrivateUnbatched(t *testing.T) {
+	gm := newTestGateway(t, fftypes.JSONObjectArray{
+		{"name": "audit", "namespace": "intake", "type": "private", "tag": "audit", "group": fftypes.JSONObject{
+			"members": []interface{}{fftypes.JSONObject{"identity": "auditor"}},
+		}, "target": fftypes.JSONObject{
+			"url": "http://settlement.example.com", "namespace": "settlement",
+		}},
+	})
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	mdm := gm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "intake"},
+	}, fftypes.DataArray{}, true, nil)
+
+	httpmock.RegisterResponder("GET", testMessagesURL,
+		func(req *http.Request) (*http.Response, error) {
+			_, _, ok := req.BasicAuth()
+			assert.False(t, ok)
+			return httpmock.NewJsonResponse(200, []*fftypes.Message{})
+		})
+	httpmock.RegisterResponder("POST", testMessagesURL+"/private",
+		func(req *http.Request) (*http.Response, error) {
+			var in fftypes.MessageInOut
+			b, _ := ioutil.ReadAll(req.Body)
+			err := json.Unmarshal(b, &in)
+			assert.NoError(t, err)
+			assert.Equal(t, "audit", in.Header.Tag)
+			assert.Equal(t, "auditor", in.Group.Members[0].Identity)
+			return httpmock.NewJsonResponse(202, &fftypes.Message{})
+		})
+
+	err := gm.relayMessage(gm.ctx, gm.relays[0], msgID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, httpmock.GetTotalCallCount())
+}
+
+func TestRelayMessageGetMessageFail(t *testing.T) {
+	gm, relay := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	mdm := gm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(nil, nil, false, fmt.Errorf("pop"))
+
+	err := gm.relayMessage(gm.ctx, relay, msgID)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRelayMessageSkipRelayedIn(t *testing.T) {
+	gm, relay := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	mdm := gm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "intake"},
+	}, fftypes.DataArray{
+		{Value: fftypes.JSONAnyPtr(`"text"`)},
+		{Value: fftypes.JSONAnyPtr(`{"provenance":{"network":"other"}}`)},
+	}, true, nil)
+
+	err := gm.relayMessage(gm.ctx, relay, msgID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, httpmock.GetTotalCallCount())
+}
+
+func TestRelayMessageSkipBlob(t *testing.T) {
+	gm, relay := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	mdm := gm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "intake"},
+	}, fftypes.DataArray{
+		{Blob: &fftypes.BlobRef{Hash: fftypes.NewRandB32()}},
+	}, true, nil)
+
+	err := gm.relayMessage(gm.ctx, relay, msgID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, httpmock.GetTotalCallCount())
+}
+
+func TestRelayMessageGetBatchFail(t *testing.T) {
+	gm, relay := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	batchID := fftypes.NewUUID()
+	mdm := gm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header:  fftypes.MessageHeader{ID: msgID, Namespace: "intake"},
+		BatchID: batchID,
+	}, fftypes.DataArray{}, true, nil)
+	mdi := gm.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", mock.Anything, batchID).Return(nil, fmt.Errorf("pop"))
+
+	err := gm.relayMessage(gm.ctx, relay, msgID)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRelayMessageBatchNotFound(t *testing.T) {
+	gm, relay := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	batchID := fftypes.NewUUID()
+	mdm := gm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header:  fftypes.MessageHeader{ID: msgID, Namespace: "intake"},
+		BatchID: batchID,
+	}, fftypes.DataArray{}, true, nil)
+	mdi := gm.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", mock.Anything, batchID).Return(nil, nil)
+
+	err := gm.relayMessage(gm.ctx, relay, msgID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, httpmock.GetTotalCallCount())
+}
+
+func TestRelayMessageGetBlockchainEventsFail(t *testing.T) {
+	gm, relay := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	batchID := fftypes.NewUUID()
+	mdm := gm.data.(*datamocks.Manager)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(&fftypes.Message{
+		Header:  fftypes.MessageHeader{ID: msgID, Namespace: "intake"},
+		BatchID: batchID,
+	}, fftypes.DataArray{}, true, nil)
+	mdi := gm.database.(*databasemocks.Plugin)
+	mdi.On("GetBatchByID", mock.Anything, batchID).Return(&fftypes.BatchPersisted{
+		TX: fftypes.TransactionRef{ID: fftypes.NewUUID()},
+	}, nil)
+	mdi.On("GetBlockchainEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := gm.relayMessage(gm.ctx, relay, msgID)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRelayMessageAlreadyRelayed(t *testing.T) {
+	gm, relay := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	mockPinnedMessage(gm, msgID, fftypes.DataArray{})
+
+	httpmock.RegisterResponder("GET", testMessagesURL,
+		httpmock.NewJsonResponderOrPanic(200, []*fftypes.Message{
+			{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}},
+		}))
+
+	err := gm.relayMessage(gm.ctx, relay, msgID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+}
+
+func TestRelayMessageQueryTargetFail(t *testing.T) {
+	gm, relay := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	mockPinnedMessage(gm, msgID, fftypes.DataArray{})
+
+	httpmock.RegisterResponder("GET", testMessagesURL,
+		httpmock.NewStringResponder(500, "pop"))
+
+	err := gm.relayMessage(gm.ctx, relay, msgID)
+	assert.Regexp(t, "FF10503.*pop", err)
+}
+
+func TestRelayMessageRejected(t *testing.T) {
+	gm, relay := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	mockPinnedMessage(gm, msgID, fftypes.DataArray{})
+
+	httpmock.RegisterResponder("GET", testMessagesURL,
+		httpmock.NewJsonResponderOrPanic(200, []*fftypes.Message{}))
+	httpmock.RegisterResponder("POST", testMessagesURL+"/broadcast",
+		httpmock.NewStringResponder(400, "bad message"))
+
+	err := gm.relayMessage(gm.ctx, relay, msgID)
+	assert.NoError(t, err)
+}
+
+func TestRelayMessageSendFail(t *testing.T) {
+	gm, relay := newTestSettlementRelay(t)
+	defer httpmock.DeactivateAndReset()
+
+	msgID := fftypes.NewUUID()
+	mockPinnedMessage(gm, msgID, fftypes.DataArray{})
+
+	httpmock.RegisterResponder("GET", testMessagesURL,
+		httpmock.NewJsonResponderOrPanic(200, []*fftypes.Message{}))
+	httpmock.RegisterResponder("POST", testMessagesURL+"/broadcast",
+		httpmock.NewStringResponder(503, "unavailable"))
+
+	err := gm.relayMessage(gm.ctx, relay, msgID)
+	assert.Regexp(t, "FF10503.*unavailable", err)
+}
@@ -0,0 +1,209 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package governance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/broadcast"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// ProtocolVersion is the version of the FireFly network protocol implemented by this node
+const ProtocolVersion = 1
+
+// Manager handles network governance, where network-wide configuration changes are proposed by a member
+// organization, and voted on by every member organization, via system broadcasts. A proposal is approved
+// once a majority of the member organizations have voted for it, at which point every node applies the
+// change automatically.
+type Manager interface {
+	ProposeChange(ctx context.Context, proposal *fftypes.GovernanceProposal, waitConfirm bool) (*fftypes.GovernanceProposal, error)
+	Vote(ctx context.Context, proposalID string, input *fftypes.GovernanceVoteInput, waitConfirm bool) (*fftypes.GovernanceVote, error)
+	GetProposals(ctx context.Context, filter database.AndFilter) ([]*fftypes.GovernanceProposal, *database.FilterResult, error)
+	GetProposalByID(ctx context.Context, id string) (*fftypes.GovernanceProposal, error)
+	GetVotes(ctx context.Context, proposalID string, filter database.AndFilter) ([]*fftypes.GovernanceVote, *database.FilterResult, error)
+
+	// ValidateChange checks the change in a proposal is valid for the type of the proposal
+	ValidateChange(ctx context.Context, proposal *fftypes.GovernanceProposal) error
+	// ApplyChange applies the change in an approved proposal to this node
+	ApplyChange(ctx context.Context, proposal *fftypes.GovernanceProposal)
+}
+
+type governanceManager struct {
+	database   database.Plugin
+	broadcast  broadcast.Manager
+	blockchain blockchain.Plugin
+}
+
+func NewGovernanceManager(ctx context.Context, di database.Plugin, bm broadcast.Manager, bi blockchain.Plugin) (Manager, error) {
+	if di == nil || bm == nil || bi == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	return &governanceManager{
+		database:   di,
+		broadcast:  bm,
+		blockchain: bi,
+	}, nil
+}
+
+func (gm *governanceManager) ProposeChange(ctx context.Context, proposal *fftypes.GovernanceProposal, waitConfirm bool) (*fftypes.GovernanceProposal, error) {
+	proposal.ID = fftypes.NewUUID()
+	proposal.Created = fftypes.Now()
+	proposal.State = fftypes.GovernanceProposalStatePending
+	proposal.Approvals = 0
+	proposal.Rejections = 0
+	proposal.Decided = nil
+	if err := proposal.Validate(ctx, false); err != nil {
+		return nil, err
+	}
+	if err := gm.ValidateChange(ctx, proposal); err != nil {
+		return nil, err
+	}
+	msg, err := gm.broadcast.BroadcastDefinitionAsNode(ctx, fftypes.SystemNamespace, proposal, fftypes.SystemTagGovernanceProposal, waitConfirm)
+	if msg != nil {
+		proposal.Message = msg.Header.ID
+		proposal.Author = msg.Header.Author
+	}
+	return proposal, err
+}
+
+func (gm *governanceManager) Vote(ctx context.Context, proposalID string, input *fftypes.GovernanceVoteInput, waitConfirm bool) (*fftypes.GovernanceVote, error) {
+	proposal, err := gm.GetProposalByID(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgGovernanceProposalNotFound, proposalID)
+	}
+	if proposal.State != fftypes.GovernanceProposalStatePending {
+		return nil, i18n.NewError(ctx, i18n.MsgGovernanceNotPending, proposal.ID, proposal.State)
+	}
+	vote := &fftypes.GovernanceVote{
+		ID:       fftypes.NewUUID(),
+		Proposal: proposal.ID,
+		Approve:  input.Approve,
+		Comment:  input.Comment,
+		Created:  fftypes.Now(),
+	}
+	if err := vote.Validate(ctx, false); err != nil {
+		return nil, err
+	}
+	msg, err := gm.broadcast.BroadcastDefinitionAsNode(ctx, fftypes.SystemNamespace, vote, fftypes.SystemTagGovernanceVote, waitConfirm)
+	if msg != nil {
+		vote.Message = msg.Header.ID
+		vote.Author = msg.Header.Author
+	}
+	return vote, err
+}
+
+func (gm *governanceManager) GetProposals(ctx context.Context, filter database.AndFilter) ([]*fftypes.GovernanceProposal, *database.FilterResult, error) {
+	return gm.database.GetGovernanceProposals(ctx, filter)
+}
+
+func (gm *governanceManager) GetProposalByID(ctx context.Context, id string) (*fftypes.GovernanceProposal, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return gm.database.GetGovernanceProposalByID(ctx, u)
+}
+
+func (gm *governanceManager) GetVotes(ctx context.Context, proposalID string, filter database.AndFilter) ([]*fftypes.GovernanceVote, *database.FilterResult, error) {
+	u, err := fftypes.ParseUUID(ctx, proposalID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gm.database.GetGovernanceVotes(ctx, filter.Condition(filter.Builder().Eq("proposal", u)))
+}
+
+func (gm *governanceManager) ValidateChange(ctx context.Context, proposal *fftypes.GovernanceProposal) error {
+	badChange := func(reason string) error {
+		return i18n.NewError(ctx, i18n.MsgGovernanceBadChange, proposal.Type, reason)
+	}
+	switch proposal.Type {
+	case fftypes.GovernanceChangeMemberApproval:
+		var change fftypes.GovernanceMemberApproval
+		if err := proposal.Change.Unmarshal(ctx, &change); err != nil {
+			return badChange(err.Error())
+		}
+		if !strings.HasPrefix(change.Identity, fftypes.FireFlyOrgDIDPrefix) {
+			return badChange(fmt.Sprintf("identity must be an organization DID beginning '%s'", fftypes.FireFlyOrgDIDPrefix))
+		}
+	case fftypes.GovernanceChangeContractMigration:
+		// The contract is optional, as each node is configured with the contract to migrate to
+		if !proposal.Change.IsNil() {
+			var change fftypes.GovernanceContractMigration
+			if err := proposal.Change.Unmarshal(ctx, &change); err != nil {
+				return badChange(err.Error())
+			}
+		}
+	case fftypes.GovernanceChangeProtocolVersion:
+		var change fftypes.GovernanceProtocolVersion
+		if err := proposal.Change.Unmarshal(ctx, &change); err != nil {
+			return badChange(err.Error())
+		}
+		if change.Version <= 0 {
+			return badChange("version must be a positive integer")
+		}
+	default:
+		return badChange("unknown type")
+	}
+	return nil
+}
+
+func (gm *governanceManager) ApplyChange(ctx context.Context, proposal *fftypes.GovernanceProposal) {
+	l := log.L(ctx)
+	switch proposal.Type {
+	case fftypes.GovernanceChangeMemberApproval:
+		var change fftypes.GovernanceMemberApproval
+		_ = proposal.Change.Unmarshal(ctx, &change)
+		l.Infof("Network governance proposal %s approved member '%s'", proposal.ID, change.Identity)
+	case fftypes.GovernanceChangeContractMigration:
+		var change fftypes.GovernanceContractMigration
+		if !proposal.Change.IsNil() {
+			_ = proposal.Change.Unmarshal(ctx, &change)
+		}
+		migration := gm.blockchain.GetBatchPinMigration(ctx)
+		if migration == nil {
+			l.Errorf("Network governance proposal %s approved a BatchPin contract migration, but no migration is configured on this node", proposal.ID)
+			return
+		}
+		if change.Contract != "" && !strings.EqualFold(change.Contract, migration.To) {
+			l.Errorf("Network governance proposal %s approved a migration to BatchPin contract '%s', but this node is configured to migrate to '%s'", proposal.ID, change.Contract, migration.To)
+			return
+		}
+		if _, err := gm.blockchain.ActivateBatchPinMigration(ctx); err != nil {
+			l.Errorf("Network governance proposal %s failed to activate BatchPin contract migration: %s", proposal.ID, err)
+			return
+		}
+		l.Infof("Network governance proposal %s activated BatchPin contract migration to '%s'", proposal.ID, migration.To)
+	case fftypes.GovernanceChangeProtocolVersion:
+		var change fftypes.GovernanceProtocolVersion
+		_ = proposal.Change.Unmarshal(ctx, &change)
+		if change.Version > ProtocolVersion {
+			l.Errorf("Network governance proposal %s moved the network to protocol version %d, but this node only supports version %d - the node must be upgraded", proposal.ID, change.Version, ProtocolVersion)
+			return
+		}
+		l.Infof("Network governance proposal %s moved the network to protocol version %d", proposal.ID, change.Version)
+	}
+}
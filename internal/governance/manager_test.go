@@ -0,0 +1,316 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package governance
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestGovernance(t *testing.T) (*governanceManager, func()) {
+	mdi := &databasemocks.Plugin{}
+	mbm := &broadcastmocks.Manager{}
+	mbi := &blockchainmocks.Plugin{}
+	gm, err := NewGovernanceManager(context.Background(), mdi, mbm, mbi)
+	assert.NoError(t, err)
+	return gm.(*governanceManager), func() {
+		mdi.AssertExpectations(t)
+		mbm.AssertExpectations(t)
+		mbi.AssertExpectations(t)
+	}
+}
+
+func TestNewGovernanceManagerFail(t *testing.T) {
+	_, err := NewGovernanceManager(context.Background(), nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestProposeChangeOk(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID: fftypes.NewUUID(),
+			SignerRef: fftypes.SignerRef{
+				Author: "did:firefly:org/org1",
+			},
+		},
+	}
+	mbm := gm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastDefinitionAsNode", mock.Anything, fftypes.SystemNamespace, mock.MatchedBy(func(p *fftypes.GovernanceProposal) bool {
+		return p.ID != nil && p.State == fftypes.GovernanceProposalStatePending && p.Approvals == 0
+	}), fftypes.SystemTagGovernanceProposal, true).Return(msg, nil)
+
+	proposal, err := gm.ProposeChange(context.Background(), &fftypes.GovernanceProposal{
+		Type:      fftypes.GovernanceChangeProtocolVersion,
+		Change:    fftypes.JSONAnyPtr(`{"version":2}`),
+		Approvals: 10,
+	}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, msg.Header.ID, proposal.Message)
+	assert.Equal(t, "did:firefly:org/org1", proposal.Author)
+}
+
+func TestProposeChangeValidateFail(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	_, err := gm.ProposeChange(context.Background(), &fftypes.GovernanceProposal{}, false)
+	assert.Regexp(t, "FF10438", err)
+}
+
+func TestProposeChangeBadChange(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	_, err := gm.ProposeChange(context.Background(), &fftypes.GovernanceProposal{
+		Type: "unknown",
+	}, false)
+	assert.Regexp(t, "FF10440", err)
+}
+
+func TestVoteOk(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	proposal := &fftypes.GovernanceProposal{
+		ID:    fftypes.NewUUID(),
+		State: fftypes.GovernanceProposalStatePending,
+	}
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID: fftypes.NewUUID(),
+			SignerRef: fftypes.SignerRef{
+				Author: "did:firefly:org/org1",
+			},
+		},
+	}
+	mdi := gm.database.(*databasemocks.Plugin)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, proposal.ID).Return(proposal, nil)
+	mbm := gm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastDefinitionAsNode", mock.Anything, fftypes.SystemNamespace, mock.MatchedBy(func(v *fftypes.GovernanceVote) bool {
+		return v.Proposal.Equals(proposal.ID) && v.Approve && v.Comment == "agreed"
+	}), fftypes.SystemTagGovernanceVote, false).Return(msg, nil)
+
+	vote, err := gm.Vote(context.Background(), proposal.ID.String(), &fftypes.GovernanceVoteInput{
+		Approve: true,
+		Comment: "agreed",
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, msg.Header.ID, vote.Message)
+	assert.Equal(t, "did:firefly:org/org1", vote.Author)
+}
+
+func TestVoteBadID(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	_, err := gm.Vote(context.Background(), "bad", &fftypes.GovernanceVoteInput{}, false)
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestVoteNotFound(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	mdi := gm.database.(*databasemocks.Plugin)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, mock.Anything).Return(nil, nil)
+
+	_, err := gm.Vote(context.Background(), fftypes.NewUUID().String(), &fftypes.GovernanceVoteInput{}, false)
+	assert.Regexp(t, "FF10441", err)
+}
+
+func TestVoteNotPending(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	mdi := gm.database.(*databasemocks.Plugin)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, mock.Anything).Return(&fftypes.GovernanceProposal{
+		ID:    fftypes.NewUUID(),
+		State: fftypes.GovernanceProposalStateApproved,
+	}, nil)
+
+	_, err := gm.Vote(context.Background(), fftypes.NewUUID().String(), &fftypes.GovernanceVoteInput{}, false)
+	assert.Regexp(t, "FF10442", err)
+}
+
+func TestVoteValidateFail(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	mdi := gm.database.(*databasemocks.Plugin)
+	mdi.On("GetGovernanceProposalByID", mock.Anything, mock.Anything).Return(&fftypes.GovernanceProposal{
+		ID:    fftypes.NewUUID(),
+		State: fftypes.GovernanceProposalStatePending,
+	}, nil)
+
+	_, err := gm.Vote(context.Background(), fftypes.NewUUID().String(), &fftypes.GovernanceVoteInput{
+		Comment: strings.Repeat("a", 1025),
+	}, false)
+	assert.Regexp(t, "FF10188", err)
+}
+
+func TestGetProposals(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	mdi := gm.database.(*databasemocks.Plugin)
+	mdi.On("GetGovernanceProposals", mock.Anything, mock.Anything).Return([]*fftypes.GovernanceProposal{}, nil, nil)
+
+	fb := database.GovernanceProposalQueryFactory.NewFilter(context.Background())
+	_, _, err := gm.GetProposals(context.Background(), fb.And())
+	assert.NoError(t, err)
+}
+
+func TestGetVotes(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	mdi := gm.database.(*databasemocks.Plugin)
+	mdi.On("GetGovernanceVotes", mock.Anything, mock.Anything).Return([]*fftypes.GovernanceVote{}, nil, nil)
+
+	fb := database.GovernanceVoteQueryFactory.NewFilter(context.Background())
+	_, _, err := gm.GetVotes(context.Background(), fftypes.NewUUID().String(), fb.And())
+	assert.NoError(t, err)
+}
+
+func TestGetVotesBadID(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	fb := database.GovernanceVoteQueryFactory.NewFilter(context.Background())
+	_, _, err := gm.GetVotes(context.Background(), "bad", fb.And())
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestValidateChange(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	check := func(changeType fftypes.GovernanceChangeType, change string) error {
+		proposal := &fftypes.GovernanceProposal{Type: changeType}
+		if change != "" {
+			proposal.Change = fftypes.JSONAnyPtr(change)
+		}
+		return gm.ValidateChange(context.Background(), proposal)
+	}
+
+	assert.NoError(t, check(fftypes.GovernanceChangeMemberApproval, `{"identity":"did:firefly:org/org2"}`))
+	assert.Regexp(t, "FF10440", check(fftypes.GovernanceChangeMemberApproval, ""))
+	assert.Regexp(t, "FF10440.*organization DID", check(fftypes.GovernanceChangeMemberApproval, `{"identity":"org2"}`))
+	assert.NoError(t, check(fftypes.GovernanceChangeContractMigration, ""))
+	assert.NoError(t, check(fftypes.GovernanceChangeContractMigration, `{"contract":"0x67890"}`))
+	assert.Regexp(t, "FF10440", check(fftypes.GovernanceChangeContractMigration, `"0x67890"`))
+	assert.NoError(t, check(fftypes.GovernanceChangeProtocolVersion, `{"version":2}`))
+	assert.Regexp(t, "FF10440", check(fftypes.GovernanceChangeProtocolVersion, `"2"`))
+	assert.Regexp(t, "FF10440.*positive", check(fftypes.GovernanceChangeProtocolVersion, `{"version":0}`))
+	assert.Regexp(t, "FF10440.*unknown", check("unknown", ""))
+}
+
+func TestApplyChangeMemberApproval(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	gm.ApplyChange(context.Background(), &fftypes.GovernanceProposal{
+		ID:     fftypes.NewUUID(),
+		Type:   fftypes.GovernanceChangeMemberApproval,
+		Change: fftypes.JSONAnyPtr(`{"identity":"did:firefly:org/org2"}`),
+	})
+}
+
+func TestApplyChangeContractMigration(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	mbi := gm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("GetBatchPinMigration", mock.Anything).Return(&fftypes.BatchPinMigration{To: "0x67890"})
+	mbi.On("ActivateBatchPinMigration", mock.Anything).Return(&fftypes.BatchPinMigration{To: "0x67890", Active: true}, nil)
+
+	gm.ApplyChange(context.Background(), &fftypes.GovernanceProposal{
+		ID:     fftypes.NewUUID(),
+		Type:   fftypes.GovernanceChangeContractMigration,
+		Change: fftypes.JSONAnyPtr(`{"contract":"0x67890"}`),
+	})
+}
+
+func TestApplyChangeContractMigrationNotConfigured(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	mbi := gm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("GetBatchPinMigration", mock.Anything).Return(nil)
+
+	gm.ApplyChange(context.Background(), &fftypes.GovernanceProposal{
+		ID:   fftypes.NewUUID(),
+		Type: fftypes.GovernanceChangeContractMigration,
+	})
+}
+
+func TestApplyChangeContractMigrationMismatch(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	mbi := gm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("GetBatchPinMigration", mock.Anything).Return(&fftypes.BatchPinMigration{To: "0x67890"})
+
+	gm.ApplyChange(context.Background(), &fftypes.GovernanceProposal{
+		ID:     fftypes.NewUUID(),
+		Type:   fftypes.GovernanceChangeContractMigration,
+		Change: fftypes.JSONAnyPtr(`{"contract":"0x11111"}`),
+	})
+}
+
+func TestApplyChangeContractMigrationActivateFail(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	mbi := gm.blockchain.(*blockchainmocks.Plugin)
+	mbi.On("GetBatchPinMigration", mock.Anything).Return(&fftypes.BatchPinMigration{To: "0x67890"})
+	mbi.On("ActivateBatchPinMigration", mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	gm.ApplyChange(context.Background(), &fftypes.GovernanceProposal{
+		ID:   fftypes.NewUUID(),
+		Type: fftypes.GovernanceChangeContractMigration,
+	})
+}
+
+func TestApplyChangeProtocolVersion(t *testing.T) {
+	gm, done := newTestGovernance(t)
+	defer done()
+
+	gm.ApplyChange(context.Background(), &fftypes.GovernanceProposal{
+		ID:     fftypes.NewUUID(),
+		Type:   fftypes.GovernanceChangeProtocolVersion,
+		Change: fftypes.JSONAnyPtr(`{"version":1}`),
+	})
+	gm.ApplyChange(context.Background(), &fftypes.GovernanceProposal{
+		ID:     fftypes.NewUUID(),
+		Type:   fftypes.GovernanceChangeProtocolVersion,
+		Change: fftypes.JSONAnyPtr(`{"version":2}`),
+	})
+}
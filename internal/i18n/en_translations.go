@@ -160,7 +160,6 @@ var (
 	MsgDataDoesNotHaveBlob          = ffm("FF10241", "Data does not have a blob attachment", 404)
 	MsgWebhookURLEmpty              = ffm("FF10242", "Webhook subscription option 'url' cannot be empty", 400)
 	MsgWebhookInvalidStringMap      = ffm("FF10243", "Webhook subscription option '%s' must be map of string values. %s=%T", 400)
-	MsgWebsocketsNoData             = ffm("FF10244", "Websockets subscriptions do not support streaming the full data payload, just the references (withData must be false)", 400)
 	MsgWebhooksWithData             = ffm("FF10245", "Webhook subscriptions require the full data payload (withData must be true)", 400)
 	MsgWebhooksOptURL               = ffm("FF10246", "Webhook url to invoke. Can be relative if a base URL is set in the webhook plugin config")
 	MsgWebhooksOptMethod            = ffm("FF10247", "Webhook method to invoke. Default=POST")
@@ -292,4 +291,187 @@ var (
 	MsgBlobMissingPublic            = ffm("FF10373", "Blob for data %s missing public payload reference while flushing batch", 500)
 	MsgDBMultiRowConfigError        = ffm("FF10374", "Database invalid configuration - using multi-row insert on DB plugin that does not support query syntax for input")
 	MsgDBNoSequence                 = ffm("FF10375", "Failed to retrieve sequence for insert row %d (could mean duplicate insert)", 500)
+	MsgUnauthorized                 = ffm("FF10376", "Unauthorized", 401)
+	MsgUnknownAuthType              = ffm("FF10377", "Unknown authentication type '%s' for %s listener", 500)
+	MsgAuthCredentialsMissing       = ffm("FF10378", "Authentication type '%s' for %s listener requires credentials to be configured", 500)
+	MsgConfigUnknownKey             = ffm("FF10379", "Unknown configuration key '%s'")
+	MsgConfigTypeMismatch           = ffm("FF10380", "Invalid value for configuration key '%s' - expected %s")
+	MsgConfigPluginTypeMissing      = ffm("FF10381", "Configuration key '%s' must be set to select a plugin")
+	MsgConfigPluginSectionMissing   = ffm("FF10382", "Missing configuration section '%s' for plugin '%s'")
+	MsgConfigInvalid                = ffm("FF10383", "Configuration is invalid - %d problem(s) found")
+	MsgConfigEnvVarNotSet           = ffm("FF10384", "Environment variable '%s' referenced by configuration key '%s' is not set")
+	MsgConfigSecretFileRead         = ffm("FF10385", "Failed to read secret file '%s' referenced by configuration key '%s'")
+	MsgConfigSecretResolve          = ffm("FF10386", "Failed to resolve secret for configuration key '%s' from secrets provider '%s'")
+	MsgVaultRESTErr                 = ffm("FF10387", "Error from HashiCorp Vault")
+	MsgSecretRefInvalid             = ffm("FF10388", "Invalid secret reference '%s' - expected %s")
+	MsgSecretFieldNotFound          = ffm("FF10389", "Field '%s' not found in secret '%s'")
+	MsgGCPSecretManagerRESTErr      = ffm("FF10390", "Error from Google Cloud Secret Manager")
+	MsgGCPMetadataTokenErr          = ffm("FF10391", "Failed to obtain an access token from the Google Cloud metadata server")
+	MsgSecretPayloadInvalid         = ffm("FF10392", "Invalid payload returned for secret '%s'")
+	MsgSignatureMissing             = ffm("FF10393", "Message is not signed")
+	MsgSignatureInvalid             = ffm("FF10394", "Message signature is invalid")
+	MsgSignatureExpired             = ffm("FF10395", "Message signature timestamp '%s' is outside the allowed skew of %s")
+	MsgSignatureReplayed            = ffm("FF10396", "Message signature has already been received")
+	MsgContractListenerRewindBlock  = ffm("FF10397", "A blockNumber must be supplied to rewind a contract listener", 400)
+	MsgEthereumRPCErr               = ffm("FF10398", "Error from Ethereum JSON-RPC endpoint: %s")
+	MsgEthereumBlockNotFinal        = ffm("FF10399", "Block %d is not yet final (finalized block: %d)")
+	MsgEthereumBlockNotFound        = ffm("FF10400", "Block '%s' not found")
+	MsgNodeNoPrivateTransactionKey  = ffm("FF10401", "Node '%s' does not have a private transaction key in its profile")
+	MsgApprovalPolicyInvalid        = ffm("FF10402", "Invalid approval policy approvals.policies[%d]")
+	MsgMessageNotAwaitingApproval   = ffm("FF10403", "Message '%s' is not awaiting approval", 409)
+	MsgApprovalPolicyNotFound       = ffm("FF10404", "Approval policy '%s' not found", 404)
+	MsgNotApprover                  = ffm("FF10405", "'%s' is not an approver for policy '%s'", 403)
+	MsgAlreadyApproved              = ffm("FF10406", "'%s' has already approved message '%s'", 409)
+	MsgApproverMissing              = ffm("FF10407", "An approver must be supplied", 400)
+	MsgApprovalPolicyRequired       = ffm("FF10408", "Approval policy approvals.policies[%d] must require between 1 and %d approvals")
+	MsgResidencyRulesInvalid        = ffm("FF10409", "Invalid data residency rules for namespace namespaces.predefined[%d]")
+	MsgSharedStorageNotPermitted    = ffm("FF10410", "Data residency rules for namespace '%s' do not permit shared storage plugin '%s'", 403)
+	MsgNodeRegionNotPermitted       = ffm("FF10411", "Data residency rules for namespace '%s' do not permit sending to node '%s' in region '%s'", 403)
+	MsgNodeEndpointNotPermitted     = ffm("FF10412", "Data residency rules for namespace '%s' do not permit sending to node '%s' at data exchange endpoint '%s'", 403)
+	MsgLegalHoldTypeInvalid         = ffm("FF10413", "Invalid legal hold type '%s'", 400)
+	MsgLegalHoldRefMissing          = ffm("FF10414", "The message or data to place under legal hold must be supplied", 400)
+	MsgLegalHoldReasonMissing       = ffm("FF10415", "A reason must be supplied for placing or releasing a legal hold", 400)
+	MsgAlreadyLegalHeld             = ffm("FF10416", "%s '%s' is already under legal hold '%s'", 409)
+	MsgLegalHoldReleased            = ffm("FF10417", "Legal hold '%s' has already been released", 409)
+	MsgRelativeTimeInvalid          = ffm("FF10418", "Invalid relative time '%s' - must be 'now', optionally followed by a duration such as 'now-1h'", 400)
+	MsgSavedQueryCollectionUnknown  = ffm("FF10419", "Unknown collection '%s' for a saved query - must be one of: %s", 400)
+	MsgSavedQueryFilterInvalid      = ffm("FF10420", "Invalid filter for saved query: %s", 400)
+	MsgNotificationConfigInvalid    = ffm("FF10421", "Invalid notification configuration in %s[%d]")
+	MsgNotificationChannelType      = ffm("FF10422", "Unknown type '%s' for notification channel '%s' - must be one of: email, slack, pagerduty")
+	MsgNotificationChannelMissing   = ffm("FF10423", "Notification channel '%s' of type '%s' requires '%s' to be set")
+	MsgNotificationRuleCondition    = ffm("FF10424", "Unknown condition '%s' for notification rule '%s' - must be one of: operation_failed, subscription_lag, peer_unreachable")
+	MsgNotificationChannelUnknown   = ffm("FF10425", "Notification rule '%s' refers to unknown channel '%s'")
+	MsgNotificationRESTErr          = ffm("FF10426", "Error from notification webhook: %s")
+	MsgNotificationRuleNoChannels   = ffm("FF10427", "Notification rule '%s' must send to at least one channel")
+	MsgReportPeriodInvalid          = ffm("FF10428", "Unknown report period '%s' - must be one of: daily, weekly, monthly")
+	MsgReportFormatInvalid          = ffm("FF10429", "Unknown report format '%s' - must be json or csv", 400)
+	MsgCounterpartyNotFound         = ffm("FF10430", "Counterparty '%s' could not be resolved to an identity", 404)
+	MsgTokenAllowanceNegative       = ffm("FF10431", "Token approval allowance must not be negative", 400)
+	MsgSwapLegInvalid               = ffm("FF10432", "The %s leg of a swap requires a pool, a positive amount, and from and to keys", 400)
+	MsgTokenMetadataNotNonFungible  = ffm("FF10433", "Token metadata can only be linked to tokens in a non-fungible pool", 400)
+	MsgTokenMetadataInvalidData     = ffm("FF10434", "Data '%s' cannot be used as token metadata - it must exist in this namespace, and have a JSON object value", 400)
+	MsgContractListenerFromBlock    = ffm("FF10435", "Only one of firstEvent and fromBlock can be set on a contract listener", 400)
+	MsgErrorNameMustBeSet           = ffm("FF10436", "Error name must be set", 400)
+	MsgNoBatchPinMigration          = ffm("FF10437", "No migration of the BatchPin contract is configured for the blockchain plugin", 404)
+	MsgGovernanceTypeRequired       = ffm("FF10438", "A governance proposal must have a type", 400)
+	MsgGovernanceProposalRequired   = ffm("FF10439", "A governance vote must reference a proposal", 400)
+	MsgGovernanceBadChange          = ffm("FF10440", "Invalid change for a '%s' governance proposal: %s", 400)
+	MsgGovernanceProposalNotFound   = ffm("FF10441", "Governance proposal '%s' not found", 404)
+	MsgGovernanceNotPending         = ffm("FF10442", "Governance proposal '%s' is not open for voting - state=%s", 409)
+	MsgInvitationCodeInvalid        = ffm("FF10443", "Invalid invitation code: %s", 400)
+	MsgInvitationExpired            = ffm("FF10444", "Invitation '%s' expired at %s", 400)
+	MsgInvitationNotFound           = ffm("FF10445", "Invitation '%s' not found", 404)
+	MsgNodeOwnerNotEndorsed         = ffm("FF10446", "Organization '%s' must be endorsed by %d existing members before this node can send messages - endorsements=%d", 403)
+	MsgCannotEndorseSelf            = ffm("FF10447", "An organization cannot endorse itself", 400)
+	MsgCannotPingSelf               = ffm("FF10448", "An organization cannot ping itself", 400)
+	MsgFaultRuleInvalid             = ffm("FF10449", "Invalid fault injection rule faults.rules[%d]: %s")
+	MsgFaultInjected                = ffm("FF10450", "Fault injected by rule faults.rules[%d] into %s operation: %s")
+	MsgReplayLogPathMissing         = ffm("FF10451", "A path must be configured with replay.log.path when the replay log is enabled")
+	MsgReplayLogOpenFailed          = ffm("FF10452", "Failed to open replay log '%s'")
+	MsgReplayLogInvalid             = ffm("FF10453", "Invalid entry at line %d of replay log '%s': %s")
+	MsgReplayRecordDuringReplay     = ffm("FF10454", "The replay log cannot be recorded while replaying a log in debug mode")
+	MsgTapEventCountInvalid         = ffm("FF10455", "Invalid event count %d for subscription tap - must be between 1 and %d", 400)
+	MsgDryRunEventCountInvalid      = ffm("FF10456", "Invalid event count %d for subscription dry run - must be no more than %d", 400)
+	MsgMessageTemplateNotFound      = ffm("FF10457", "Message template '%s' not found", 404)
+	MsgMessageTemplateDataMissing   = ffm("FF10458", "Data entry %d of the message template is empty", 400)
+	MsgInboundEndpointInvalid       = ffm("FF10459", "Invalid inbound endpoint inbound.endpoints[%d]: %s")
+	MsgInboundEndpointNotFound      = ffm("FF10460", "Inbound endpoint '%s' not found", 404)
+	MsgInboundMappingFailed         = ffm("FF10461", "Unable to map the %s of the message from path '%s' in the request body", 400)
+	MsgInboundBodyNotObject         = ffm("FF10462", "The request body of an inbound endpoint must be a JSON object", 400)
+	MsgIngestionDirectoryInvalid    = ffm("FF10463", "Invalid ingestion directory ingestion.directories[%d]: %s")
+	MsgEmailOptTo                   = ffm("FF10464", "Comma separated list of the addresses to send the emails to")
+	MsgEmailOptSubject              = ffm("FF10465", "Template for the subject of the emails, which can reference fields of the event such as {{.Type}}")
+	MsgEmailToEmpty                 = ffm("FF10466", "Email subscription option 'to' cannot be empty", 400)
+	MsgEmailSubjectInvalid          = ffm("FF10467", "Email subscription option 'subject' is not a valid template", 400)
+	MsgEmailReplyInvalid            = ffm("FF10468", "Invalid email", 400)
+	MsgEmailReplyNoReference        = ffm("FF10469", "The In-Reply-To header of the email does not reference a FireFly message: '%s'", 400)
+	MsgEmailReplyMessageNotFound    = ffm("FF10470", "The email replies to message '%s', which was not found in namespace '%s'", 404)
+	MsgEmailReplyNoAttachments      = ffm("FF10471", "The email does not contain any attachments to ingest", 400)
+	MsgEmailReplyAttachmentInvalid  = ffm("FF10472", "The JSON attachment '%s' of the email is invalid", 400)
+	MsgEDIFormatUnknown             = ffm("FF10473", "The payload is not an X12 or EDIFACT interchange", 400)
+	MsgEDIInvalid                   = ffm("FF10474", "Invalid EDI interchange: %s", 400)
+	MsgAS2OptURL                    = ffm("FF10475", "URL of the AS2 endpoint of the trading partner")
+	MsgAS2OptTo                     = ffm("FF10476", "AS2 identifier of the trading partner")
+	MsgAS2OptFrom                   = ffm("FF10477", "AS2 identifier to send as, overriding the identifier in the plugin config")
+	MsgAS2URLInvalid                = ffm("FF10478", "Invalid AS2 url '%s'", 400)
+	MsgAS2ToEmpty                   = ffm("FF10479", "The 'as2to' option must be set to the AS2 identifier of the trading partner", 400)
+	MsgAS2MDNMissing                = ffm("FF10480", "The AS2 response from the trading partner did not contain an MDN (status=%d)")
+	MsgAS2MDNFailed                 = ffm("FF10481", "The AS2 MDN from the trading partner reported disposition '%s'")
+	MsgInvalidSubscriptionOrdering  = ffm("FF10482", "Invalid subscription ordering '%s'", 400)
+	MsgWSInvalidResumeToken         = ffm("FF10483", "Invalid resume token for an ephemeral subscription in namespace '%s'")
+	MsgIngestionRemoteError         = ffm("FF10484", "Ingestion %s request '%s' failed: %s")
+	MsgSMIMEInvalid                 = ffm("FF10486", "Invalid S/MIME content: %s", 400)
+	MsgSMIMESignatureInvalid        = ffm("FF10487", "S/MIME signature verification failed: %s", 400)
+	MsgEmailReplyNotSigned          = ffm("FF10488", "The email is not signed with S/MIME", 400)
+	MsgEmailReplySenderInvalid      = ffm("FF10489", "The sender '%s' of the email was not a recipient of the original email", 403)
+	MsgEmailReplySignerMismatch     = ffm("FF10490", "The email is from '%s', but the signing certificate is for %v", 403)
+	MsgEmailRepliesCAInvalid        = ffm("FF10491", "Unable to load the CA certificates for email replies from '%s': %s")
+	MsgEmailRepliesIMAPInvalid      = ffm("FF10492", "Invalid IMAP url for email replies '%s'")
+	MsgEmailRepliesIMAPError        = ffm("FF10493", "IMAP command '%s' failed: %s")
+	MsgAS2SigningInvalid            = ffm("FF10494", "Unable to load the AS2 signing certificate and key: %s")
+	MsgAS2PartnerInvalid            = ffm("FF10495", "Invalid AS2 trading partner %s[%d]: %s")
+	MsgAS2MDNUnsigned               = ffm("FF10496", "The AS2 MDN from trading partner '%s' was not signed")
+	MsgAS2MDNMICMismatch            = ffm("FF10497", "The AS2 MDN from the trading partner reported MIC '%s', which does not match the MIC '%s' of the message sent")
+	MsgInvalidSerialization         = ffm("FF10498", "Invalid subscription serialization '%s'", 400)
+	MsgSerializationNotSupported    = ffm("FF10499", "The '%s' transport does not support serialization '%s'", 400)
+	MsgSystemListenerNotRegistered  = ffm("FF10500", "No system listener is registered for subscription '%s:%s'")
+	MsgBridgeRuleInvalid            = ffm("FF10501", "Invalid bridge rule bridges.rules[%d]: %s")
+	MsgGatewayRelayInvalid          = ffm("FF10502", "Invalid gateway relay gateway.relays[%d]: %s")
+	MsgGatewayRESTErr               = ffm("FF10503", "Error from gateway target network: %s")
+	MsgStepNotCompensable           = ffm("FF10504", "Operation '%s' of type '%s' can no longer be compensated")
+	MsgBatchPinWithdrawn            = ffm("FF10505", "Batch pin withdrawn by compensation operation '%s'")
+	MsgRetryBudgetExhausted         = ffm("FF10506", "Retry budget of %s exhausted after %d attempts")
+	MsgConnectorCallTimeout         = ffm("FF10507", "Timed out waiting for the connector", 504)
+	MsgDatatypeBundleEmpty          = ffm("FF10508", "Datatype bundle must contain at least one datatype", 400)
+	MsgDatatypeBundleDuplicate      = ffm("FF10509", "Datatype '%s' appears more than once in the bundle", 400)
+	MsgDatatypeBundleCycle          = ffm("FF10510", "Datatype '%s' has a circular reference to itself through other datatypes in the bundle", 400)
+	MsgDatatypeExists               = ffm("FF10511", "Datatype '%s' already exists", 409)
+	MsgDatatypeRefNotFound          = ffm("FF10512", "Referenced datatype '%s' not found", 400)
+	MsgTooLargeLocal                = ffm("FF10513", "Message size %.2fkb is too large for the max local message batch size of %.2fkb", 400)
+	MsgLocalMessageGroup            = ffm("FF10514", "Local messages cannot be sent to a group", 400)
+	MsgPinningRulesInvalid          = ffm("FF10515", "Invalid pinning rules for namespace namespaces.predefined[%d]")
+	MsgBatchManifestInvalid         = ffm("FF10516", "Batch '%s' does not have a valid manifest")
+	MsgManifestEntryNotFound        = ffm("FF10517", "Not found in the local database")
+	MsgManifestEntryHashMismatch    = ffm("FF10518", "Local hash '%s' does not match the hash '%s' in the manifest")
+	MsgDataHashInvalid              = ffm("FF10519", "Computed hash '%s' does not match the stored hash '%s'")
+	MsgQuarantineMessageIncomplete  = ffm("FF10520", "Quarantined message '%s' could not be loaded with all of its data", 409)
+	MsgQuarantineStillInvalid       = ffm("FF10521", "Quarantined message '%s' still fails validation: %s", 409)
+	MsgAliasTargetMissing           = ffm("FF10522", "An alias must map to an identity, a key, or both", 400)
+	MsgAliasNotFound                = ffm("FF10523", "Alias '%s' not found in the address book", 404)
+	MsgAliasKeyMissing              = ffm("FF10524", "Alias '%s' does not have a key", 400)
+	MsgAliasIdentityMissing         = ffm("FF10525", "Alias '%s' does not have an identity", 400)
+	MsgEnrichmentPluginLoadFailed   = ffm("FF10526", "Failed to load event enrichment plugin '%s': %s")
+	MsgEnrichmentPluginInvalid      = ffm("FF10527", "Event enrichment plugin '%s' does not export an 'Enricher' that implements the events.Enricher interface")
+	MsgInvalidTopicPattern          = ffm("FF10528", "Invalid topic pattern '%s' - levels must not be empty, and wildcards must be a whole level with '#' only at the end", 400)
+	MsgTopicPatternNotQueryable     = ffm("FF10529", "Topic pattern '%s' cannot be used in a query, as a literal level follows a '*' wildcard in a pattern ending with '#'", 400)
+	MsgAnonymousNotConfigured       = ffm("FF10530", "Anonymous broadcast is not configured - broadcast.anonymous.author or broadcast.anonymous.key, and broadcast.anonymous.identityKey must be set", 400)
+	MsgAnonymousIdentityKeyInvalid  = ffm("FF10531", "Invalid broadcast.anonymous.identityKey - must be a hex encoded 32 byte AES-256 key")
+	MsgAnonymousBroadcastType       = ffm("FF10532", "Only messages of type 'broadcast' can be sent anonymously - type is '%s'", 400)
+	MsgNotAnonymousBroadcast        = ffm("FF10533", "Message '%s' is not an anonymous broadcast", 404)
+	MsgAnonymousAuthorDecryptFailed = ffm("FF10534", "Unable to decrypt the author of anonymous broadcast '%s' with the configured identity key", 403)
+	MsgAttachmentInvalidMimeType    = ffm("FF10535", "Invalid mime type '%s' for attachment %d in the manifest", 400)
+	MsgAttachmentBlobNotInMessage   = ffm("FF10536", "Attachment '%s' refers to blob %s, which is not attached to any data in the message", 400)
+	MsgAttachmentSizeMismatch       = ffm("FF10537", "Attachment '%s' declares size %d, but blob %s has size %d", 400)
+	MsgUnknownScanningPlugin        = ffm("FF10538", "Unknown blob scanning plugin '%s'")
+	MsgScanningResponseInvalid      = ffm("FF10539", "Unexpected response from the %s scanner: %s")
+	MsgBlobScanFailed               = ffm("FF10540", "Failed to scan blob '%s' for malware")
+	MsgBlobQuarantined              = ffm("FF10541", "Blob '%s' has been quarantined - threat '%s' was found by the malware scanner", 403)
+	MsgClientAPIError               = ffm("FF10542", "FireFly API request %s %s failed [%d]: %s")
+	MsgClientURLRequired            = ffm("FF10543", "A URL is required to connect to the FireFly API")
+	MsgRouteLimitInvalid            = ffm("FF10544", "Invalid API route limit %d: %s")
+	MsgRouteSaturated               = ffm("FF10545", "Too many concurrent requests to route '%s' - retry later", 503)
+	MsgByIDsCount                   = ffm("FF10546", "Between 1 and %d IDs must be supplied", 400)
+	MsgIncludeDesc                  = ffm("FF10547", "Comma separated list of related records to embed in the response - data, transaction, events")
+	MsgUnknownInclude               = ffm("FF10548", "Unknown include '%s' - supported values are: data, transaction, events", 400)
+	MsgChangesSinceDesc             = ffm("FF10549", "Return changes after this sequence - use the next sequence from the previous response")
+	MsgChangesLimitDesc             = ffm("FF10550", "The maximum number of changes to return")
+	MsgRequestMustBeBroadcast       = ffm("FF10551", "For broadcast request messages a group of private recipients must not be specified", 400)
+	MsgReplyTimeout                 = ffm("FF10552", "No reply was received to the request with id '%s' within the timeout of %s", 408)
+	MsgReplyTimeoutDesc             = ffm("FF10553", "How long to wait for a reply to the request (defaults to message.confirm.timeout)")
+	MsgCompactionNoTypes            = ffm("FF10554", "Event compaction is enabled, but no event types are configured in event.compaction.types")
+	MsgBenchInvalidCount            = ffm("FF10555", "The number of messages to send must be greater than zero")
+	MsgBenchGroupTooLarge           = ffm("FF10556", "A group size of %d was requested, but only %d organizations are registered in the network")
+	MsgSubscriptionRewindSequence   = ffm("FF10557", "A sequence of zero or more must be supplied to rewind a subscription", 400)
+	MsgSubscriptionNamespacesAdmin  = ffm("FF10558", "A subscription filter that matches events in namespaces other than '%s' can only be used on the admin API", 403)
+	MsgTopicMatchMultiValueField    = ffm("FF10559", "Topic patterns cannot be matched against field '%s', as it holds multiple topics. Match against the 'topic' of the events instead", 400)
 )
@@ -0,0 +1,184 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// VerifyNodeOwnerEndorsed checks the org that owns this node has been endorsed by the configured quorum of
+// existing members. Until it has, the node is quarantined - it can receive broadcasts, but cannot send messages
+// outside of the system namespace.
+func (im *identityManager) VerifyNodeOwnerEndorsed(ctx context.Context) error {
+	if im.endorsementQuorum <= 0 {
+		return nil
+	}
+	org, err := im.GetNodeOwnerOrg(ctx)
+	if err != nil {
+		return err
+	}
+	endorsed, required, endorsements, err := im.checkOrgEndorsed(ctx, org)
+	if err != nil {
+		return err
+	}
+	if !endorsed {
+		return i18n.NewError(ctx, i18n.MsgNodeOwnerNotEndorsed, org.DID, required, endorsements)
+	}
+	return nil
+}
+
+// VerifyAuthorEndorsed is the receiving side of VerifyNodeOwnerEndorsed. It checks the org at the root of the
+// hierarchy of the author of a message has been endorsed, so that every member rejects messages outside of the
+// system namespace from a quarantined org - rather than relying on the sending node to police itself.
+// Only errors that can be retried are returned.
+func (im *identityManager) VerifyAuthorEndorsed(ctx context.Context, author string) (endorsed bool, err error) {
+	if im.endorsementQuorum <= 0 {
+		return true, nil
+	}
+	l := log.L(ctx)
+
+	identity, retryable, err := im.CachedIdentityLookup(ctx, author)
+	if err != nil && retryable {
+		return false, err
+	}
+	if identity == nil || err != nil {
+		l.Warnf("Author '%s' is not endorsed - identity not found: %v", author, err)
+		return false, nil
+	}
+	for identity.Parent != nil {
+		parentID := identity.Parent
+		if identity, err = im.CachedIdentityLookupByID(ctx, parentID); err != nil {
+			return false, err
+		}
+		if identity == nil {
+			l.Warnf("Author '%s' is not endorsed - parent identity '%s' not found", author, parentID)
+			return false, nil
+		}
+	}
+	if identity.Type != fftypes.IdentityTypeOrg {
+		l.Warnf("Author '%s' is not endorsed - root identity '%s' is not an organization", author, identity.DID)
+		return false, nil
+	}
+
+	endorsed, required, endorsements, err := im.checkOrgEndorsed(ctx, identity)
+	if err == nil && !endorsed {
+		l.Warnf("Author '%s' is not endorsed - organization '%s' has %d of the %d required endorsements", author, identity.DID, endorsements, required)
+	}
+	return endorsed, err
+}
+
+// checkOrgEndorsed counts the endorsements of an org against the quorum. Only the members that joined before the org
+// are required, so the first members of a network are never quarantined. The order members joined is taken from the
+// blockchain events that pinned their claims, rather than the local time each identity was inserted, so that every
+// node in the network comes to the same answer.
+func (im *identityManager) checkOrgEndorsed(ctx context.Context, org *fftypes.Identity) (endorsed bool, required, endorsements int64, err error) {
+	im.endorsementMux.Lock()
+	endorsed = im.endorsedOrgs[*org.ID]
+	im.endorsementMux.Unlock()
+	if endorsed {
+		return true, 0, 0, nil
+	}
+
+	required = im.endorsementQuorum
+	position, err := im.orgJoinPosition(ctx, org)
+	if err != nil {
+		return false, 0, 0, err
+	}
+	if position != "" {
+		fb := database.IdentityQueryFactory.NewFilter(ctx)
+		members, _, err := im.database.GetIdentities(ctx, fb.And(
+			fb.Eq("type", fftypes.IdentityTypeOrg),
+			fb.Eq("namespace", fftypes.SystemNamespace),
+			fb.Neq("id", org.ID),
+		))
+		if err != nil {
+			return false, 0, 0, err
+		}
+		var earlierMembers int64
+		for _, member := range members {
+			memberPosition, err := im.orgJoinPosition(ctx, member)
+			if err != nil {
+				return false, 0, 0, err
+			}
+			if memberPosition != "" && memberPosition < position {
+				earlierMembers++
+			}
+		}
+		if earlierMembers < required {
+			required = earlierMembers
+		}
+	}
+
+	efb := database.IdentityEndorsementQueryFactory.NewFilter(ctx)
+	_, res, err := im.database.GetIdentityEndorsements(ctx, efb.And(
+		efb.Eq("identity", org.ID),
+	).Count(true).Limit(1))
+	if err != nil {
+		return false, 0, 0, err
+	}
+	endorsements = *res.TotalCount
+	if endorsements < required {
+		return false, required, endorsements, nil
+	}
+
+	// Endorsements cannot be withdrawn, so there is no need to check again
+	im.endorsementMux.Lock()
+	im.endorsedOrgs[*org.ID] = true
+	im.endorsementMux.Unlock()
+	return true, required, endorsements, nil
+}
+
+// orgJoinPosition returns the protocol ID of the blockchain event that pinned the claim of an org, which orders it
+// consistently against the other members on every node. An empty string is returned if the claim has not been pinned.
+func (im *identityManager) orgJoinPosition(ctx context.Context, org *fftypes.Identity) (string, error) {
+	im.endorsementMux.Lock()
+	position, ok := im.orgJoinPositions[*org.ID]
+	im.endorsementMux.Unlock()
+	if ok {
+		return position, nil
+	}
+
+	if org.Messages.Claim == nil {
+		return "", nil
+	}
+	msg, err := im.database.GetMessageByID(ctx, org.Messages.Claim)
+	if err != nil || msg == nil || msg.BatchID == nil {
+		return "", err
+	}
+	batch, err := im.database.GetBatchByID(ctx, msg.BatchID)
+	if err != nil || batch == nil || batch.TX.ID == nil {
+		return "", err
+	}
+	fb := database.BlockchainEventQueryFactory.NewFilter(ctx)
+	events, _, err := im.database.GetBlockchainEvents(ctx, fb.And(
+		fb.Eq("tx.id", batch.TX.ID),
+	).Sort("protocolid").Limit(1))
+	if err != nil || len(events) == 0 {
+		return "", err
+	}
+
+	// The position of a confirmed claim never changes
+	im.endorsementMux.Lock()
+	im.orgJoinPositions[*org.ID] = events[0].ProtocolID
+	im.endorsementMux.Unlock()
+	return events[0].ProtocolID, nil
+}
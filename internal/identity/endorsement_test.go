@@ -0,0 +1,352 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func countResult(count int64) *database.FilterResult {
+	return &database.FilterResult{TotalCount: &count}
+}
+
+func testOrg(name string) *fftypes.Identity {
+	return &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID:        fftypes.NewUUID(),
+			DID:       "did:firefly:org/" + name,
+			Type:      fftypes.IdentityTypeOrg,
+			Namespace: fftypes.SystemNamespace,
+			Name:      name,
+		},
+		Messages: fftypes.IdentityMessages{
+			Claim: fftypes.NewUUID(),
+		},
+		Created: fftypes.Now(),
+	}
+}
+
+func testNodeOwnerOrg() *fftypes.Identity {
+	return testOrg("org2")
+}
+
+// testMembers returns orgs that joined at the supplied positions, with their positions already cached
+func testMembers(im *identityManager, positions ...string) []*fftypes.Identity {
+	members := make([]*fftypes.Identity, len(positions))
+	for i, position := range positions {
+		members[i] = testOrg(fmt.Sprintf("member%d", i))
+		im.orgJoinPositions[*members[i].ID] = position
+	}
+	return members
+}
+
+func TestVerifyNodeOwnerEndorsedDisabled(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+
+	err := im.VerifyNodeOwnerEndorsed(ctx)
+	assert.NoError(t, err)
+}
+
+func TestVerifyNodeOwnerEndorsedQuorumMet(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 2
+	im.nodeOwningOrgIdentity = testNodeOwnerOrg()
+
+	mdi := im.database.(*databasemocks.Plugin)
+	batchID := fftypes.NewUUID()
+	txID := fftypes.NewUUID()
+	mdi.On("GetMessageByID", ctx, im.nodeOwningOrgIdentity.Messages.Claim).Return(&fftypes.Message{BatchID: batchID}, nil).Once()
+	mdi.On("GetBatchByID", ctx, batchID).Return(&fftypes.BatchPersisted{TX: fftypes.TransactionRef{ID: txID}}, nil).Once()
+	mdi.On("GetBlockchainEvents", ctx, mock.Anything).Return([]*fftypes.BlockchainEvent{
+		{ProtocolID: "000000000010/000000/000000"},
+	}, nil, nil).Once()
+	mdi.On("GetIdentities", ctx, mock.Anything).Return(testMembers(im,
+		"000000000001/000000/000000",
+		"000000000002/000000/000000",
+		"000000000003/000000/000000",
+		"000000000020/000000/000000",
+	), nil, nil).Once()
+	mdi.On("GetIdentityEndorsements", ctx, mock.Anything).Return([]*fftypes.IdentityEndorsement{}, countResult(2), nil).Once()
+
+	err := im.VerifyNodeOwnerEndorsed(ctx)
+	assert.NoError(t, err)
+
+	// Cached once endorsed
+	err = im.VerifyNodeOwnerEndorsed(ctx)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestVerifyNodeOwnerEndorsedFirstMember(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 2
+	im.nodeOwningOrgIdentity = testNodeOwnerOrg()
+	im.orgJoinPositions[*im.nodeOwningOrgIdentity.ID] = "000000000001/000000/000000"
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", ctx, mock.Anything).Return(testMembers(im,
+		"000000000002/000000/000000",
+		"", // not yet pinned
+	), nil, nil)
+	mdi.On("GetIdentityEndorsements", ctx, mock.Anything).Return([]*fftypes.IdentityEndorsement{}, countResult(0), nil)
+
+	err := im.VerifyNodeOwnerEndorsed(ctx)
+	assert.NoError(t, err)
+}
+
+func TestVerifyNodeOwnerEndorsedQuarantined(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 3
+	im.nodeOwningOrgIdentity = testNodeOwnerOrg()
+	im.orgJoinPositions[*im.nodeOwningOrgIdentity.ID] = "000000000010/000000/000000"
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", ctx, mock.Anything).Return(testMembers(im,
+		"000000000001/000000/000000",
+		"000000000002/000000/000000",
+	), nil, nil)
+	mdi.On("GetIdentityEndorsements", ctx, mock.Anything).Return([]*fftypes.IdentityEndorsement{}, countResult(1), nil)
+
+	err := im.VerifyNodeOwnerEndorsed(ctx)
+	assert.Regexp(t, "FF10446.*org2.*2.*endorsements=1", err)
+	assert.Empty(t, im.endorsedOrgs)
+}
+
+func TestVerifyNodeOwnerEndorsedClaimNotPinned(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 3
+	im.nodeOwningOrgIdentity = testNodeOwnerOrg()
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", ctx, im.nodeOwningOrgIdentity.Messages.Claim).Return(&fftypes.Message{}, nil)
+	mdi.On("GetIdentityEndorsements", ctx, mock.Anything).Return([]*fftypes.IdentityEndorsement{}, countResult(2), nil)
+
+	err := im.VerifyNodeOwnerEndorsed(ctx)
+	assert.Regexp(t, "FF10446.*org2.*3.*endorsements=2", err)
+}
+
+func TestVerifyNodeOwnerEndorsedBatchNotFound(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 3
+	im.nodeOwningOrgIdentity = testNodeOwnerOrg()
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", ctx, im.nodeOwningOrgIdentity.Messages.Claim).Return(&fftypes.Message{BatchID: fftypes.NewUUID()}, nil)
+	mdi.On("GetBatchByID", ctx, mock.Anything).Return(nil, nil)
+	mdi.On("GetIdentityEndorsements", ctx, mock.Anything).Return([]*fftypes.IdentityEndorsement{}, countResult(3), nil)
+
+	err := im.VerifyNodeOwnerEndorsed(ctx)
+	assert.NoError(t, err)
+}
+
+func TestVerifyNodeOwnerEndorsedNoOrg(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+
+	err := im.VerifyNodeOwnerEndorsed(ctx)
+	assert.Regexp(t, "FF10354", err)
+}
+
+func TestVerifyNodeOwnerEndorsedPositionFail(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+	im.nodeOwningOrgIdentity = testNodeOwnerOrg()
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", ctx, im.nodeOwningOrgIdentity.Messages.Claim).Return(nil, fmt.Errorf("pop"))
+
+	err := im.VerifyNodeOwnerEndorsed(ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestVerifyNodeOwnerEndorsedMembersFail(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+	im.nodeOwningOrgIdentity = testNodeOwnerOrg()
+	im.orgJoinPositions[*im.nodeOwningOrgIdentity.ID] = "000000000010/000000/000000"
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := im.VerifyNodeOwnerEndorsed(ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestVerifyNodeOwnerEndorsedMemberPositionFail(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+	im.nodeOwningOrgIdentity = testNodeOwnerOrg()
+	im.orgJoinPositions[*im.nodeOwningOrgIdentity.ID] = "000000000010/000000/000000"
+	member := testOrg("member1")
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", ctx, mock.Anything).Return([]*fftypes.Identity{member}, nil, nil)
+	mdi.On("GetMessageByID", ctx, member.Messages.Claim).Return(&fftypes.Message{BatchID: fftypes.NewUUID()}, nil)
+	mdi.On("GetBatchByID", ctx, mock.Anything).Return(&fftypes.BatchPersisted{TX: fftypes.TransactionRef{ID: fftypes.NewUUID()}}, nil)
+	mdi.On("GetBlockchainEvents", ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := im.VerifyNodeOwnerEndorsed(ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestVerifyNodeOwnerEndorsedEndorsementsFail(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+	im.nodeOwningOrgIdentity = testNodeOwnerOrg()
+	im.nodeOwningOrgIdentity.Messages.Claim = nil
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityEndorsements", ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := im.VerifyNodeOwnerEndorsed(ctx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestVerifyAuthorEndorsedDisabled(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+
+	endorsed, err := im.VerifyAuthorEndorsed(ctx, "did:firefly:org/org1")
+	assert.NoError(t, err)
+	assert.True(t, endorsed)
+}
+
+func TestVerifyAuthorEndorsedCustomIdentity(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+	org := testOrg("org1")
+	im.endorsedOrgs[*org.ID] = true
+	custom := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID:     fftypes.NewUUID(),
+			DID:    "did:firefly:ns/ns1/custom1",
+			Type:   fftypes.IdentityTypeCustom,
+			Parent: org.ID,
+		},
+	}
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByDID", ctx, custom.DID).Return(custom, nil)
+	mdi.On("GetIdentityByID", ctx, org.ID).Return(org, nil)
+
+	endorsed, err := im.VerifyAuthorEndorsed(ctx, custom.DID)
+	assert.NoError(t, err)
+	assert.True(t, endorsed)
+}
+
+func TestVerifyAuthorEndorsedQuarantined(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+	org := testOrg("org1")
+	im.orgJoinPositions[*org.ID] = "000000000010/000000/000000"
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByDID", ctx, org.DID).Return(org, nil)
+	mdi.On("GetIdentities", ctx, mock.Anything).Return(testMembers(im, "000000000001/000000/000000"), nil, nil)
+	mdi.On("GetIdentityEndorsements", ctx, mock.Anything).Return([]*fftypes.IdentityEndorsement{}, countResult(0), nil)
+
+	endorsed, err := im.VerifyAuthorEndorsed(ctx, org.DID)
+	assert.NoError(t, err)
+	assert.False(t, endorsed)
+}
+
+func TestVerifyAuthorEndorsedLookupFail(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByDID", ctx, "did:firefly:org/org1").Return(nil, fmt.Errorf("pop"))
+
+	_, err := im.VerifyAuthorEndorsed(ctx, "did:firefly:org/org1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestVerifyAuthorEndorsedNotFound(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+
+	endorsed, err := im.VerifyAuthorEndorsed(ctx, "did:unknown:org1")
+	assert.NoError(t, err)
+	assert.False(t, endorsed)
+}
+
+func TestVerifyAuthorEndorsedParentFail(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+	custom := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID:     fftypes.NewUUID(),
+			DID:    "did:firefly:ns/ns1/custom1",
+			Type:   fftypes.IdentityTypeCustom,
+			Parent: fftypes.NewUUID(),
+		},
+	}
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByDID", ctx, custom.DID).Return(custom, nil)
+	mdi.On("GetIdentityByID", ctx, custom.Parent).Return(nil, fmt.Errorf("pop"))
+
+	_, err := im.VerifyAuthorEndorsed(ctx, custom.DID)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestVerifyAuthorEndorsedParentNotFound(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+	custom := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID:     fftypes.NewUUID(),
+			DID:    "did:firefly:ns/ns1/custom1",
+			Type:   fftypes.IdentityTypeCustom,
+			Parent: fftypes.NewUUID(),
+		},
+	}
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByDID", ctx, custom.DID).Return(custom, nil)
+	mdi.On("GetIdentityByID", ctx, custom.Parent).Return(nil, nil)
+
+	endorsed, err := im.VerifyAuthorEndorsed(ctx, custom.DID)
+	assert.NoError(t, err)
+	assert.False(t, endorsed)
+}
+
+func TestVerifyAuthorEndorsedRootNotOrg(t *testing.T) {
+	ctx, im := newTestIdentityManager(t)
+	im.endorsementQuorum = 1
+	custom := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID:   fftypes.NewUUID(),
+			DID:  "did:firefly:ns/ns1/custom1",
+			Type: fftypes.IdentityTypeCustom,
+		},
+	}
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByDID", ctx, custom.DID).Return(custom, nil)
+
+	endorsed, err := im.VerifyAuthorEndorsed(ctx, custom.DID)
+	assert.NoError(t, err)
+	assert.False(t, endorsed)
+}
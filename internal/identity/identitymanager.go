@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
@@ -41,6 +42,7 @@ const (
 type Manager interface {
 	ResolveInputSigningIdentity(ctx context.Context, namespace string, msgSignerRef *fftypes.SignerRef) (err error)
 	ResolveNodeOwnerSigningIdentity(ctx context.Context, msgSignerRef *fftypes.SignerRef) (err error)
+	ResolveAliasRef(ctx context.Context, namespace, value string) (alias *fftypes.Alias, err error)
 	NormalizeSigningKey(ctx context.Context, namespace string, keyNormalizationMode int) (signingKey string, err error)
 	FindIdentityForVerifier(ctx context.Context, iTypes []fftypes.IdentityType, namespace string, verifier *fftypes.VerifierRef) (identity *fftypes.Identity, err error)
 	ResolveIdentitySigner(ctx context.Context, identity *fftypes.Identity) (parentSigner *fftypes.SignerRef, err error)
@@ -50,6 +52,8 @@ type Manager interface {
 	GetNodeOwnerBlockchainKey(ctx context.Context) (*fftypes.VerifierRef, error)
 	GetNodeOwnerOrg(ctx context.Context) (*fftypes.Identity, error)
 	VerifyIdentityChain(ctx context.Context, identity *fftypes.Identity) (immediateParent *fftypes.Identity, retryable bool, err error)
+	VerifyNodeOwnerEndorsed(ctx context.Context) error
+	VerifyAuthorEndorsed(ctx context.Context, author string) (endorsed bool, err error)
 }
 
 type identityManager struct {
@@ -60,6 +64,10 @@ type identityManager struct {
 
 	nodeOwnerBlockchainKey *fftypes.VerifierRef
 	nodeOwningOrgIdentity  *fftypes.Identity
+	endorsementQuorum      int64
+	endorsementMux         sync.Mutex
+	endorsedOrgs           map[fftypes.UUID]bool
+	orgJoinPositions       map[fftypes.UUID]string
 	identityCacheTTL       time.Duration
 	identityCache          *ccache.Cache
 	signingKeyCacheTTL     time.Duration
@@ -77,6 +85,9 @@ func NewIdentityManager(ctx context.Context, di database.Plugin, ii identity.Plu
 		data:               dm,
 		identityCacheTTL:   config.GetDuration(config.IdentityManagerCacheTTL),
 		signingKeyCacheTTL: config.GetDuration(config.IdentityManagerCacheTTL),
+		endorsementQuorum:  config.GetInt64(config.IdentityEndorsementQuorum),
+		endorsedOrgs:       make(map[fftypes.UUID]bool),
+		orgJoinPositions:   make(map[fftypes.UUID]string),
 	}
 	// For the identity and signingkey caches, we just treat them all equally sized and the max items
 	im.identityCache = ccache.New(
@@ -127,6 +138,10 @@ func (im *identityManager) NormalizeSigningKey(ctx context.Context, inputKey str
 func (im *identityManager) ResolveInputSigningIdentity(ctx context.Context, namespace string, msgSignerRef *fftypes.SignerRef) (err error) {
 	log.L(ctx).Debugf("Resolving identity input: key='%s' author='%s'", msgSignerRef.Key, msgSignerRef.Author)
 
+	if err = im.resolveSignerAliases(ctx, namespace, msgSignerRef); err != nil {
+		return err
+	}
+
 	var verifier *fftypes.VerifierRef
 	switch {
 	case msgSignerRef.Author == "" && msgSignerRef.Key == "":
@@ -184,6 +199,52 @@ func (im *identityManager) ResolveInputSigningIdentity(ctx context.Context, name
 	return nil
 }
 
+// resolveSignerAliases replaces any alias references in the author and key of the input with the entries from the address book.
+// When the author is an alias, and no key is specified, the key of the alias is used (if it has one)
+func (im *identityManager) resolveSignerAliases(ctx context.Context, namespace string, msgSignerRef *fftypes.SignerRef) error {
+	authorAlias, err := im.ResolveAliasRef(ctx, namespace, msgSignerRef.Author)
+	if err != nil {
+		return err
+	}
+	if authorAlias != nil {
+		if authorAlias.Identity == "" {
+			return i18n.NewError(ctx, i18n.MsgAliasIdentityMissing, authorAlias.Name)
+		}
+		msgSignerRef.Author = authorAlias.Identity
+		if msgSignerRef.Key == "" {
+			msgSignerRef.Key = authorAlias.Key
+		}
+	}
+	keyAlias, err := im.ResolveAliasRef(ctx, namespace, msgSignerRef.Key)
+	if err != nil {
+		return err
+	}
+	if keyAlias != nil {
+		if keyAlias.Key == "" {
+			return i18n.NewError(ctx, i18n.MsgAliasKeyMissing, keyAlias.Name)
+		}
+		msgSignerRef.Key = keyAlias.Key
+	}
+	return nil
+}
+
+// ResolveAliasRef looks up the address book entry for a value such as "alias:acme".
+// Returns nil (with no error) if the value is not an alias reference
+func (im *identityManager) ResolveAliasRef(ctx context.Context, namespace, value string) (alias *fftypes.Alias, err error) {
+	name, isAlias := fftypes.ParseAliasRef(value)
+	if !isAlias {
+		return nil, nil
+	}
+	alias, err = im.database.GetAliasByName(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if alias == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgAliasNotFound, name)
+	}
+	return alias, nil
+}
+
 // firstVerifierForIdentity does a lookup of the first verifier of a given type (such as a blockchain signing key) registered to an identity,
 // as a convenience to allow you to only specify the org name/DID when sending a message
 func (im *identityManager) firstVerifierForIdentity(ctx context.Context, vType fftypes.VerifierType, identity *fftypes.Identity) (verifier *fftypes.VerifierRef, retryable bool, err error) {
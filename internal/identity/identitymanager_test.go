@@ -421,6 +421,154 @@ func TestResolveInputSigningIdentityByOrgVerifierFail(t *testing.T) {
 
 }
 
+func TestResolveInputSigningIdentityAuthorAliasOk(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+
+	idID := fftypes.NewUUID()
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetAliasByName", ctx, "ns1", "partner").Return(&fftypes.Alias{
+		Name:     "partner",
+		Identity: "org1",
+	}, nil)
+	mdi.On("GetIdentityByName", ctx, fftypes.IdentityTypeOrg, fftypes.SystemNamespace, "org1").
+		Return(&fftypes.Identity{
+			IdentityBase: fftypes.IdentityBase{
+				ID:        idID,
+				DID:       "did:firefly:org/org1",
+				Namespace: fftypes.SystemNamespace,
+				Name:      "org1",
+				Type:      fftypes.IdentityTypeOrg,
+			},
+		}, nil)
+	mdi.On("GetVerifiers", ctx, mock.Anything).
+		Return([]*fftypes.Verifier{
+			(&fftypes.Verifier{
+				Identity:  idID,
+				Namespace: "ns1",
+				VerifierRef: fftypes.VerifierRef{
+					Type:  fftypes.VerifierTypeEthAddress,
+					Value: "fullkey123",
+				},
+			}).Seal(),
+		}, nil, nil)
+
+	msgIdentity := &fftypes.SignerRef{
+		Author: "alias:partner",
+	}
+	err := im.ResolveInputSigningIdentity(ctx, "ns1", msgIdentity)
+	assert.NoError(t, err)
+	assert.Equal(t, "did:firefly:org/org1", msgIdentity.Author)
+	assert.Equal(t, "fullkey123", msgIdentity.Key)
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestResolveInputSigningIdentityAliasNotFound(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetAliasByName", ctx, "ns1", "partner").Return(nil, nil)
+
+	msgIdentity := &fftypes.SignerRef{
+		Author: "alias:partner",
+	}
+	err := im.ResolveInputSigningIdentity(ctx, "ns1", msgIdentity)
+	assert.Regexp(t, "FF10523", err)
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestResolveSignerAliasesAuthorAndKey(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetAliasByName", ctx, "ns1", "partner").Return(&fftypes.Alias{
+		Name:     "partner",
+		Identity: "did:firefly:org/org1",
+		Key:      "0x12345",
+	}, nil)
+
+	msgIdentity := &fftypes.SignerRef{
+		Author: "alias:partner",
+	}
+	err := im.resolveSignerAliases(ctx, "ns1", msgIdentity)
+	assert.NoError(t, err)
+	assert.Equal(t, "did:firefly:org/org1", msgIdentity.Author)
+	assert.Equal(t, "0x12345", msgIdentity.Key)
+
+	msgIdentity = &fftypes.SignerRef{
+		Author: "org2",
+		Key:    "alias:partner",
+	}
+	err = im.resolveSignerAliases(ctx, "ns1", msgIdentity)
+	assert.NoError(t, err)
+	assert.Equal(t, "org2", msgIdentity.Author)
+	assert.Equal(t, "0x12345", msgIdentity.Key)
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestResolveSignerAliasesNoIdentity(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetAliasByName", ctx, "ns1", "signer").Return(&fftypes.Alias{
+		Name: "signer",
+		Key:  "0x12345",
+	}, nil)
+
+	err := im.resolveSignerAliases(ctx, "ns1", &fftypes.SignerRef{
+		Author: "alias:signer",
+	})
+	assert.Regexp(t, "FF10525", err)
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestResolveSignerAliasesNoKey(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetAliasByName", ctx, "ns1", "partner").Return(&fftypes.Alias{
+		Name:     "partner",
+		Identity: "did:firefly:org/org1",
+	}, nil)
+
+	err := im.resolveSignerAliases(ctx, "ns1", &fftypes.SignerRef{
+		Key: "alias:partner",
+	})
+	assert.Regexp(t, "FF10524", err)
+
+	mdi.AssertExpectations(t)
+
+}
+
+func TestResolveSignerAliasesKeyLookupFail(t *testing.T) {
+
+	ctx, im := newTestIdentityManager(t)
+
+	mdi := im.database.(*databasemocks.Plugin)
+	mdi.On("GetAliasByName", ctx, "ns1", "partner").Return(nil, fmt.Errorf("pop"))
+
+	err := im.resolveSignerAliases(ctx, "ns1", &fftypes.SignerRef{
+		Key: "alias:partner",
+	})
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+
+}
+
 func TestNormalizeSigningKeyOrgFallbackOk(t *testing.T) {
 
 	ctx, im := newTestIdentityManager(t)
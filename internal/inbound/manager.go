@@ -0,0 +1,211 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/broadcast"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Manager receives plain JSON business documents posted to the custom inbound endpoints, and maps
+// each one to a FireFly message using the declarative mapping configured for that endpoint. This
+// allows legacy systems to post documents without knowing anything about the message envelope.
+type Manager interface {
+	Receive(ctx context.Context, endpoint string, body *fftypes.JSONAny, waitConfirm bool) (*fftypes.Message, error)
+}
+
+// Endpoint is the configuration of a single custom inbound endpoint, in the inbound.endpoints array
+type Endpoint struct {
+	Name      string               `json:"name"`
+	Namespace string               `json:"namespace,omitempty"`
+	Type      fftypes.MessageType  `json:"type,omitempty"`
+	Topic     string               `json:"topic,omitempty"`
+	Tag       string               `json:"tag,omitempty"`
+	Template  string               `json:"template,omitempty"`
+	Datatype  *fftypes.DatatypeRef `json:"datatype,omitempty"`
+	Group     *fftypes.InputGroup  `json:"group,omitempty"`
+	Mapping   Mapping              `json:"mapping"`
+}
+
+// Mapping contains dot separated paths into the JSON body (such as "order.buyer.id"), from which
+// the fields of the message are extracted. The value defaults to the whole body when not set.
+type Mapping struct {
+	Topic string `json:"topic,omitempty"`
+	Tag   string `json:"tag,omitempty"`
+	CID   string `json:"cid,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+type inboundManager struct {
+	broadcast broadcast.Manager
+	messaging privatemessaging.Manager
+	endpoints map[string]*Endpoint
+}
+
+func NewInboundManager(ctx context.Context, bm broadcast.Manager, pm privatemessaging.Manager) (Manager, error) {
+	if bm == nil || pm == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	im := &inboundManager{
+		broadcast: bm,
+		messaging: pm,
+		endpoints: make(map[string]*Endpoint),
+	}
+	defaultNS := config.GetString(config.NamespacesDefault)
+	for i, endpointObject := range config.GetObjectArray(config.InboundEndpoints) {
+		var endpoint *Endpoint
+		b, _ := json.Marshal(endpointObject)
+		if err := json.Unmarshal(b, &endpoint); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgInboundEndpointInvalid, i, err)
+		}
+		if err := fftypes.ValidateFFNameField(ctx, endpoint.Name, "name"); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgInboundEndpointInvalid, i, err)
+		}
+		if _, exists := im.endpoints[endpoint.Name]; exists {
+			return nil, i18n.NewError(ctx, i18n.MsgInboundEndpointInvalid, i, fmt.Sprintf("duplicate name '%s'", endpoint.Name))
+		}
+		if endpoint.Namespace == "" {
+			endpoint.Namespace = defaultNS
+		}
+		switch endpoint.Type {
+		case "":
+			endpoint.Type = fftypes.MessageTypeBroadcast
+		case fftypes.MessageTypeBroadcast:
+		case fftypes.MessageTypePrivate:
+			if endpoint.Group == nil && endpoint.Template == "" {
+				return nil, i18n.NewError(ctx, i18n.MsgInboundEndpointInvalid, i, "a group is required for private messages")
+			}
+		default:
+			return nil, i18n.NewError(ctx, i18n.MsgInboundEndpointInvalid, i, fmt.Sprintf("unsupported type '%s'", endpoint.Type))
+		}
+		log.L(ctx).Infof("Inbound endpoint '%s' mapped to %s messages in namespace '%s'", endpoint.Name, endpoint.Type, endpoint.Namespace)
+		im.endpoints[endpoint.Name] = endpoint
+	}
+	return im, nil
+}
+
+// lookupPath walks a dot separated path through the nested objects of the body
+func lookupPath(body fftypes.JSONObject, path string) (interface{}, bool) {
+	var current interface{} = map[string]interface{}(body)
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func (im *inboundManager) mapString(ctx context.Context, body fftypes.JSONObject, field, path string) (string, error) {
+	v, ok := lookupPath(body, path)
+	if ok {
+		var s string
+		if s, ok = v.(string); ok && s != "" {
+			return s, nil
+		}
+	}
+	return "", i18n.NewError(ctx, i18n.MsgInboundMappingFailed, field, path)
+}
+
+func (im *inboundManager) mapMessage(ctx context.Context, endpoint *Endpoint, body *fftypes.JSONAny) (*fftypes.MessageInOut, error) {
+	obj, ok := body.JSONObjectOk(true)
+	if !ok {
+		return nil, i18n.NewError(ctx, i18n.MsgInboundBodyNotObject)
+	}
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Tag: endpoint.Tag,
+			},
+		},
+		Group:    endpoint.Group,
+		Template: endpoint.Template,
+	}
+	if endpoint.Topic != "" {
+		in.Header.Topics = fftypes.FFStringArray{endpoint.Topic}
+	}
+
+	mapping := &endpoint.Mapping
+	if mapping.Topic != "" {
+		topic, err := im.mapString(ctx, obj, "topic", mapping.Topic)
+		if err != nil {
+			return nil, err
+		}
+		in.Header.Topics = fftypes.FFStringArray{topic}
+	}
+	if mapping.Tag != "" {
+		tag, err := im.mapString(ctx, obj, "tag", mapping.Tag)
+		if err != nil {
+			return nil, err
+		}
+		in.Header.Tag = tag
+	}
+	if mapping.CID != "" {
+		cid, err := im.mapString(ctx, obj, "cid", mapping.CID)
+		if err == nil {
+			in.Header.CID, err = fftypes.ParseUUID(ctx, cid)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	value := body
+	if mapping.Value != "" {
+		v, ok := lookupPath(obj, mapping.Value)
+		if !ok {
+			return nil, i18n.NewError(ctx, i18n.MsgInboundMappingFailed, "value", mapping.Value)
+		}
+		b, _ := json.Marshal(v)
+		value = fftypes.JSONAnyPtrBytes(b)
+	}
+	data := &fftypes.DataRefOrValue{Value: value}
+	if endpoint.Datatype != nil {
+		data.Validator = fftypes.ValidatorTypeJSON
+		data.Datatype = endpoint.Datatype
+	}
+	in.InlineData = fftypes.InlineData{data}
+	return in, nil
+}
+
+func (im *inboundManager) Receive(ctx context.Context, name string, body *fftypes.JSONAny, waitConfirm bool) (*fftypes.Message, error) {
+	endpoint, ok := im.endpoints[name]
+	if !ok {
+		return nil, i18n.NewError(ctx, i18n.MsgInboundEndpointNotFound, name)
+	}
+	in, err := im.mapMessage(ctx, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Type == fftypes.MessageTypePrivate {
+		return im.messaging.SendMessage(ctx, endpoint.Namespace, in, waitConfirm)
+	}
+	return im.broadcast.BroadcastMessage(ctx, endpoint.Namespace, in, waitConfirm)
+}
@@ -0,0 +1,159 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inbound
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestInbound(t *testing.T, endpoints fftypes.JSONObjectArray) (*inboundManager, *broadcastmocks.Manager, *privatemessagingmocks.Manager) {
+	config.Reset()
+	config.Set(config.InboundEndpoints, endpoints)
+	mbm := &broadcastmocks.Manager{}
+	mpm := &privatemessagingmocks.Manager{}
+	im, err := NewInboundManager(context.Background(), mbm, mpm)
+	assert.NoError(t, err)
+	return im.(*inboundManager), mbm, mpm
+}
+
+func TestNewInboundManagerMissingDeps(t *testing.T) {
+	_, err := NewInboundManager(context.Background(), nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestNewInboundManagerInvalid(t *testing.T) {
+	for _, endpoints := range []fftypes.JSONObjectArray{
+		{{"name": "po", "type": false}},
+		{{"name": "!bad"}},
+		{{"name": "po"}, {"name": "po"}},
+		{{"name": "po", "type": "private"}},
+		{{"name": "po", "type": "transfer_broadcast"}},
+	} {
+		config.Reset()
+		config.Set(config.InboundEndpoints, endpoints)
+		_, err := NewInboundManager(context.Background(), &broadcastmocks.Manager{}, &privatemessagingmocks.Manager{})
+		assert.Regexp(t, "FF10459", err)
+	}
+}
+
+func TestReceiveBroadcastMapped(t *testing.T) {
+	im, mbm, _ := newTestInbound(t, fftypes.JSONObjectArray{
+		{
+			"name":     "purchase-orders",
+			"type":     "broadcast",
+			"topic":    "orders",
+			"tag":      "po",
+			"datatype": fftypes.JSONObject{"name": "purchaseorder", "version": "1.0"},
+			"mapping": fftypes.JSONObject{
+				"topic": "buyer.id",
+				"cid":   "ref",
+				"value": "order",
+			},
+		},
+	})
+
+	cid := fftypes.NewUUID()
+	body := fftypes.JSONAnyPtr(`{"ref":"` + cid.String() + `","buyer":{"id":"acme"},"order":{"lines":[1,2]}}`)
+	msg := &fftypes.Message{}
+	mbm.On("BroadcastMessage", mock.Anything, "default", mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		return in.Header.Topics.String() == "acme" &&
+			in.Header.Tag == "po" &&
+			in.Header.CID.Equals(cid) &&
+			in.InlineData[0].Datatype.Name == "purchaseorder" &&
+			in.InlineData[0].Validator == fftypes.ValidatorTypeJSON &&
+			in.InlineData[0].Value.String() == `{"lines":[1,2]}`
+	}), true).Return(msg, nil)
+
+	out, err := im.Receive(context.Background(), "purchase-orders", body, true)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, out)
+
+	mbm.AssertExpectations(t)
+}
+
+func TestReceivePrivateDefaults(t *testing.T) {
+	im, _, mpm := newTestInbound(t, fftypes.JSONObjectArray{
+		{
+			"name":      "invoices",
+			"namespace": "ns1",
+			"type":      "private",
+			"topic":     "invoices",
+			"mapping":   fftypes.JSONObject{"tag": "kind"},
+			"group": fftypes.JSONObject{
+				"members": []interface{}{fftypes.JSONObject{"identity": "org1"}},
+			},
+		},
+	})
+
+	body := fftypes.JSONAnyPtr(`{"total":10,"kind":"invoice"}`)
+	msg := &fftypes.Message{}
+	mpm.On("SendMessage", mock.Anything, "ns1", mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		return in.Header.Topics.String() == "invoices" &&
+			in.Header.Tag == "invoice" &&
+			in.Group.Members[0].Identity == "org1" &&
+			in.InlineData[0].Datatype == nil &&
+			in.InlineData[0].Value == body
+	}), false).Return(msg, nil)
+
+	out, err := im.Receive(context.Background(), "invoices", body, false)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, out)
+
+	mpm.AssertExpectations(t)
+}
+
+func TestReceiveNotFound(t *testing.T) {
+	im, _, _ := newTestInbound(t, fftypes.JSONObjectArray{})
+	_, err := im.Receive(context.Background(), "unknown", fftypes.JSONAnyPtr(`{}`), false)
+	assert.Regexp(t, "FF10460", err)
+}
+
+func TestReceiveBodyNotObject(t *testing.T) {
+	im, _, _ := newTestInbound(t, fftypes.JSONObjectArray{{"name": "po"}})
+	_, err := im.Receive(context.Background(), "po", fftypes.JSONAnyPtr(`[]`), false)
+	assert.Regexp(t, "FF10462", err)
+}
+
+func TestReceiveMappingFailures(t *testing.T) {
+	im, _, _ := newTestInbound(t, fftypes.JSONObjectArray{
+		{"name": "topic", "mapping": fftypes.JSONObject{"topic": "a.b"}},
+		{"name": "tag", "mapping": fftypes.JSONObject{"tag": "a"}},
+		{"name": "cid", "mapping": fftypes.JSONObject{"cid": "c"}},
+		{"name": "cidbad", "mapping": fftypes.JSONObject{"cid": "a"}},
+		{"name": "value", "mapping": fftypes.JSONObject{"value": "missing"}},
+	})
+	body := fftypes.JSONAnyPtr(`{"a":"not-an-object","c":12345}`)
+
+	_, err := im.Receive(context.Background(), "topic", body, false)
+	assert.Regexp(t, "FF10461.*topic", err)
+	_, err = im.Receive(context.Background(), "tag", fftypes.JSONAnyPtr(`{"a":""}`), false)
+	assert.Regexp(t, "FF10461.*tag", err)
+	_, err = im.Receive(context.Background(), "cid", body, false)
+	assert.Regexp(t, "FF10461.*cid", err)
+	_, err = im.Receive(context.Background(), "cidbad", body, false)
+	assert.Regexp(t, "FF10142", err)
+	_, err = im.Receive(context.Background(), "value", body, false)
+	assert.Regexp(t, "FF10461.*value", err)
+}
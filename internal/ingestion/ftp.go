@@ -0,0 +1,164 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingestion
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/jlaffaye/ftp"
+)
+
+// ftpSource is a directory on an FTP server, read with the github.com/jlaffaye/ftp client
+type ftpSource struct {
+	address  string
+	host     string
+	path     string
+	username string
+	password string
+	timeout  time.Duration
+}
+
+func newFTPSource(dir *Directory, u *url.URL) *ftpSource {
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "21")
+	}
+	username := dir.Username
+	if username == "" {
+		username = "anonymous"
+	}
+	return &ftpSource{
+		address:  address,
+		host:     u.Hostname(),
+		path:     dir.Path,
+		username: username,
+		password: dir.Password,
+		timeout:  time.Duration(dir.Timeout),
+	}
+}
+
+type ftpConn struct {
+	ctx    context.Context
+	client *ftp.ServerConn
+}
+
+func (fs *ftpSource) connect(ctx context.Context) (fileConn, error) {
+	fc := &ftpConn{ctx: ctx}
+	client, err := ftp.Dial(fs.address, ftp.DialWithDialFunc(fs.dial))
+	if err != nil {
+		return nil, fc.remoteError("connect", err)
+	}
+	fc.client = client
+	if err = client.Login(fs.username, fs.password); err != nil {
+		err = fc.remoteError("login", err)
+	} else if err = client.ChangeDir(fs.path); err != nil {
+		err = fc.remoteError("CWD", err)
+	}
+	if err != nil {
+		fc.close()
+		return nil, err
+	}
+	return fc, nil
+}
+
+// dial opens the control and data connections. Data connections are made to the host of the control connection,
+// rather than the address in a PASV response - which is often unroutable behind NAT, and must not be trusted to
+// point elsewhere.
+func (fs *ftpSource) dial(network, address string) (net.Conn, error) {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout(network, net.JoinHostPort(fs.host, port), fs.timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &deadlineConn{Conn: conn, timeout: fs.timeout}, nil
+}
+
+// remoteError reports a failed command. The arguments are not included, as they might include the password.
+func (fc *ftpConn) remoteError(command string, err error) error {
+	return i18n.NewError(fc.ctx, i18n.MsgIngestionRemoteError, "ftp", command, err)
+}
+
+func (fc *ftpConn) list() ([]*sourceFile, error) {
+	entries, err := fc.client.List("")
+	if err != nil {
+		return nil, fc.remoteError("LIST", err)
+	}
+	files := make([]*sourceFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type != ftp.EntryTypeFile {
+			continue
+		}
+		// Only MLSD listings have the time to the second, so otherwise it is read with MDTM where the server has it
+		modified := entry.Time
+		if !fc.client.IsTimePreciseInList() && fc.client.IsGetTimeSupported() {
+			if modified, err = fc.client.GetTime(entry.Name); err != nil {
+				return nil, fc.remoteError("MDTM", err)
+			}
+		}
+		files = append(files, &sourceFile{name: entry.Name, modified: modified})
+	}
+	return files, nil
+}
+
+func (fc *ftpConn) open(name string) (io.ReadCloser, error) {
+	res, err := fc.client.Retr(name)
+	if err != nil {
+		return nil, fc.remoteError("RETR", err)
+	}
+	return &ftpFile{Response: res, fc: fc}, nil
+}
+
+func (fc *ftpConn) archive(name, archiveDir string) error {
+	if err := fc.client.Rename(name, path.Join(archiveDir, name)); err != nil {
+		return fc.remoteError("rename", err)
+	}
+	return nil
+}
+
+func (fc *ftpConn) remove(name string) error {
+	if err := fc.client.Delete(name); err != nil {
+		return fc.remoteError("DELE", err)
+	}
+	return nil
+}
+
+func (fc *ftpConn) close() {
+	_ = fc.client.Quit()
+}
+
+// ftpFile reads a file from its data connection. The transfer is complete once the
+// server confirms it on the control connection, after the data connection is closed.
+type ftpFile struct {
+	*ftp.Response
+	fc *ftpConn
+}
+
+func (ff *ftpFile) Close() error {
+	if err := ff.Response.Close(); err != nil {
+		return ff.fc.remoteError("RETR", err)
+	}
+	return nil
+}
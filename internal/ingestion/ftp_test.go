@@ -0,0 +1,407 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestFTPServer runs an FTP server, serving the files under root. Responses can be overridden by command,
+// or with "transfer" for the response once a data connection is complete.
+func newTestFTPServer(t *testing.T, root string, overrides map[string]string) (addr string, done func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestFTP(conn, root, overrides)
+		}
+	}()
+	return l.Addr().String(), func() { l.Close() }
+}
+
+func serveTestFTP(conn net.Conn, root string, overrides map[string]string) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+	cwd := root
+	var pasv net.Listener
+	var renameFrom string
+	_ = tp.PrintfLine("220 ready")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.SplitN(line, " ", 2)
+		arg := ""
+		if len(command) > 1 {
+			arg = command[1]
+		}
+		if response, ok := overrides[command[0]]; ok {
+			_ = tp.PrintfLine("%s", response)
+			continue
+		}
+		resolve := func(p string) string {
+			if strings.HasPrefix(p, "/") {
+				return filepath.Join(root, p)
+			}
+			return filepath.Join(cwd, p)
+		}
+		data := func(fn func(dc net.Conn)) {
+			_ = tp.PrintfLine("150 opening data connection")
+			dc, err := pasv.Accept()
+			if err == nil {
+				fn(dc)
+				dc.Close()
+			}
+			pasv.Close()
+			complete := "226 transfer complete"
+			if override, ok := overrides["transfer"]; ok {
+				complete = override
+			}
+			_ = tp.PrintfLine("%s", complete)
+		}
+		switch command[0] {
+		case "USER":
+			_ = tp.PrintfLine("331 password required")
+		case "PASS":
+			if arg != "pass1" {
+				_ = tp.PrintfLine("530 login incorrect")
+				continue
+			}
+			_ = tp.PrintfLine("230 logged in")
+		case "FEAT":
+			_ = tp.PrintfLine("211-Features:\r\n MDTM\r\n211 End")
+		case "TYPE":
+			_ = tp.PrintfLine("200 ok")
+		case "CWD":
+			if info, err := os.Stat(resolve(arg)); err != nil || !info.IsDir() {
+				_ = tp.PrintfLine("550 no such directory")
+				continue
+			}
+			cwd = resolve(arg)
+			_ = tp.PrintfLine("250 ok")
+		case "PASV":
+			pasv, _ = net.Listen("tcp", "127.0.0.1:0")
+			port := pasv.Addr().(*net.TCPAddr).Port
+			// The address in the response is deliberately wrong, as the client must use the control connection host
+			_ = tp.PrintfLine("227 Entering Passive Mode (10,0,0,1,%d,%d)", port>>8, port&0xff)
+		case "LIST":
+			data(func(dc net.Conn) {
+				infos, _ := ioutil.ReadDir(cwd)
+				for _, info := range infos {
+					mode := "-rw-r--r--"
+					if info.IsDir() {
+						mode = "drwxr-xr-x"
+					}
+					fmt.Fprintf(dc, "%s 1 user1 user1 %d %s %s\r\n", mode, info.Size(), info.ModTime().UTC().Format("Jan _2 15:04"), info.Name())
+				}
+			})
+		case "MLSD":
+			data(func(dc net.Conn) {
+				infos, _ := ioutil.ReadDir(cwd)
+				fmt.Fprintf(dc, "type=cdir;modify=20220101000000; .\r\n")
+				for _, info := range infos {
+					entryType := "file"
+					if info.IsDir() {
+						entryType = "dir"
+					}
+					fmt.Fprintf(dc, "type=%s;modify=%s;size=%d; %s\r\n", entryType, info.ModTime().UTC().Format("20060102150405"), info.Size(), info.Name())
+				}
+			})
+		case "MDTM":
+			info, err := os.Stat(resolve(arg))
+			if err != nil || info.IsDir() {
+				_ = tp.PrintfLine("550 not a plain file")
+				continue
+			}
+			_ = tp.PrintfLine("213 %s", info.ModTime().UTC().Format("20060102150405"))
+		case "RETR":
+			b, err := ioutil.ReadFile(resolve(arg))
+			if err != nil {
+				pasv.Close()
+				_ = tp.PrintfLine("550 no such file")
+				continue
+			}
+			data(func(dc net.Conn) { _, _ = dc.Write(b) })
+		case "DELE":
+			if err := os.Remove(resolve(arg)); err != nil {
+				_ = tp.PrintfLine("550 no such file")
+				continue
+			}
+			_ = tp.PrintfLine("250 deleted")
+		case "RNFR":
+			renameFrom = resolve(arg)
+			_ = tp.PrintfLine("350 ready for destination")
+		case "RNTO":
+			if err := os.Rename(renameFrom, resolve(arg)); err != nil {
+				_ = tp.PrintfLine("550 rename failed")
+				continue
+			}
+			_ = tp.PrintfLine("250 renamed")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func newTestFTPDir(addr string) *Directory {
+	u, _ := url.Parse("ftp://" + addr)
+	return &Directory{
+		Name: "partner1",
+		Path: "/in",
+		source: newFTPSource(&Directory{
+			Username: "user1",
+			Password: "pass1",
+			Path:     "/in",
+			Timeout:  fftypes.FFDuration(5 * time.Second),
+		}, u),
+	}
+}
+
+func newTestFTPRoot(t *testing.T) string {
+	root := newTestDir(t)
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "in", "subdir"), 0700))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "archive"), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "in", "file1.txt"), []byte("some data"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "in", "file2.txt"), []byte("more data"), 0600))
+	return root
+}
+
+func TestFTPListReadArchiveRemove(t *testing.T) {
+	root := newTestFTPRoot(t)
+	defer os.RemoveAll(root)
+	addr, done := newTestFTPServer(t, root, nil)
+	defer done()
+	dir := newTestFTPDir(addr)
+
+	conn, err := dir.source.connect(context.Background())
+	assert.NoError(t, err)
+	defer conn.close()
+
+	files, err := conn.list()
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.Equal(t, "file1.txt", files[0].name)
+	assert.WithinDuration(t, time.Now(), files[0].modified, 1*time.Minute)
+
+	r, err := conn.open("file1.txt")
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "some data", string(b))
+	assert.NoError(t, r.Close())
+
+	_, err = conn.open("missing")
+	assert.Regexp(t, "FF10484.*RETR.*no such file", err)
+
+	assert.NoError(t, conn.archive("file1.txt", "/archive"))
+	_, err = os.Stat(filepath.Join(root, "archive", "file1.txt"))
+	assert.NoError(t, err)
+	assert.Regexp(t, "FF10484.*rename", conn.archive("file1.txt", "/archive"))
+
+	assert.NoError(t, conn.remove("file2.txt"))
+	assert.Regexp(t, "FF10484.*DELE", conn.remove("file2.txt"))
+}
+
+func TestFTPListMLSD(t *testing.T) {
+	root := newTestFTPRoot(t)
+	defer os.RemoveAll(root)
+	addr, done := newTestFTPServer(t, root, map[string]string{
+		"FEAT": "211-Features:\r\n MLST type*;modify*;\r\n211 End",
+		"MDTM": "502 not implemented",
+	})
+	defer done()
+
+	conn, err := newTestFTPDir(addr).source.connect(context.Background())
+	assert.NoError(t, err)
+	defer conn.close()
+	files, err := conn.list()
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.WithinDuration(t, time.Now(), files[1].modified, 1*time.Minute)
+}
+
+func TestFTPConnectFailures(t *testing.T) {
+	root := newTestFTPRoot(t)
+	defer os.RemoveAll(root)
+
+	for _, test := range []struct {
+		overrides map[string]string
+		password  string
+		err       string
+	}{
+		{map[string]string{}, "wrong", "FF10484.*login.*login incorrect"},
+		{map[string]string{"USER": "530 go away"}, "pass1", "FF10484.*login.*go away"},
+		{map[string]string{"USER": "230 no password needed", "TYPE": "504 no"}, "pass1", "FF10484.*login"},
+		{map[string]string{"CWD": "550 no"}, "pass1", "FF10484.*CWD"},
+	} {
+		addr, done := newTestFTPServer(t, root, test.overrides)
+		dir := newTestFTPDir(addr)
+		dir.source.(*ftpSource).password = test.password
+		_, err := dir.source.connect(context.Background())
+		assert.Regexp(t, test.err, err)
+		assert.NotContains(t, err.Error(), "pass1")
+		done()
+	}
+
+	// Not listening
+	addr, done := newTestFTPServer(t, root, nil)
+	done()
+	_, err := newTestFTPDir(addr).source.connect(context.Background())
+	assert.Regexp(t, "FF10484.*connect", err)
+
+	// Closed after the greeting
+	l2, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer l2.Close()
+	go func() {
+		conn, _ := l2.Accept()
+		fmt.Fprintf(conn, "220 ready\r\n")
+		conn.Close()
+	}()
+	_, err = newTestFTPDir(l2.Addr().String()).source.connect(context.Background())
+	assert.Error(t, err)
+
+	// Bad greeting
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer l.Close()
+	go func() {
+		conn, _ := l.Accept()
+		fmt.Fprintf(conn, "421 busy\r\n")
+		conn.Close()
+	}()
+	_, err = newTestFTPDir(l.Addr().String()).source.connect(context.Background())
+	assert.Regexp(t, "FF10484.*connect", err)
+
+	// Invalid address to dial
+	_, err = newTestFTPDir(addr).source.(*ftpSource).dial("tcp", "no port")
+	assert.Error(t, err)
+}
+
+func TestFTPListFailures(t *testing.T) {
+	root := newTestFTPRoot(t)
+	defer os.RemoveAll(root)
+
+	for _, test := range []struct {
+		overrides map[string]string
+		err       string
+	}{
+		{map[string]string{"PASV": "500 no"}, "FF10484.*LIST"},
+		{map[string]string{"PASV": "227 no address"}, "FF10484.*LIST"},
+		{map[string]string{"PASV": "227 (1,2,3)"}, "FF10484.*LIST"},
+		{map[string]string{"PASV": "227 (1,2,3,4,x,y)"}, "FF10484.*LIST"},
+		{map[string]string{"LIST": "450 busy"}, "FF10484.*LIST"},
+		{map[string]string{"MDTM": "213 notatimestamp!"}, "FF10484.*MDTM"},
+		{map[string]string{"MDTM": "421 closing"}, "FF10484.*MDTM"},
+	} {
+		addr, done := newTestFTPServer(t, root, test.overrides)
+		conn, err := newTestFTPDir(addr).source.connect(context.Background())
+		assert.NoError(t, err)
+		_, err = conn.list()
+		assert.Regexp(t, test.err, err)
+		conn.close()
+		done()
+	}
+}
+
+func TestFTPTransferNotConfirmed(t *testing.T) {
+	root := newTestFTPRoot(t)
+	defer os.RemoveAll(root)
+	addr, done := newTestFTPServer(t, root, map[string]string{"transfer": "426 aborted"})
+	defer done()
+	conn, err := newTestFTPDir(addr).source.connect(context.Background())
+	assert.NoError(t, err)
+	defer conn.close()
+
+	r, err := conn.open("file1.txt")
+	assert.NoError(t, err)
+	_, err = ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Regexp(t, "FF10484.*RETR.*aborted", r.Close())
+}
+
+func TestFTPRenameFromRejected(t *testing.T) {
+	root := newTestFTPRoot(t)
+	defer os.RemoveAll(root)
+	addr, done := newTestFTPServer(t, root, map[string]string{"RNFR": "550 no such file"})
+	defer done()
+	conn, err := newTestFTPDir(addr).source.connect(context.Background())
+	assert.NoError(t, err)
+	defer conn.close()
+	assert.Regexp(t, "FF10484.*rename", conn.archive("file1.txt", "/archive"))
+}
+
+func TestNewFTPSourceDefaults(t *testing.T) {
+	u, _ := url.Parse("ftp://localhost")
+	fs := newFTPSource(&Directory{}, u)
+	assert.Equal(t, "localhost:21", fs.address)
+	assert.Equal(t, "anonymous", fs.username)
+}
+
+func TestIngestFromFTP(t *testing.T) {
+	root := newTestFTPRoot(t)
+	defer os.RemoveAll(root)
+	assert.NoError(t, os.Remove(filepath.Join(root, "in", "file2.txt")))
+	addr, done := newTestFTPServer(t, root, nil)
+	defer done()
+
+	_, tm, cancel := newTestIngestion(t, fftypes.JSONObjectArray{
+		{
+			"name":     "partner1",
+			"url":      "ftp://" + addr,
+			"username": "user1",
+			"password": "pass1",
+			"path":     "/in",
+			"pattern":  "*.txt",
+		},
+	}, "1ms")
+	defer cancel()
+
+	d := &fftypes.Data{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()}
+	tm.mdm.On("UploadBLOB", mock.Anything, "default", mock.Anything, mock.MatchedBy(func(mp *fftypes.Multipart) bool {
+		b, _ := ioutil.ReadAll(mp.Data)
+		return mp.Filename == "file1.txt" && string(b) == "some data"
+	}), true).Return(d, nil)
+	tm.mbm.On("BroadcastMessage", mock.Anything, "default", mock.Anything, true).Return(&fftypes.Message{}, nil)
+
+	for {
+		if _, err := os.Stat(filepath.Join(root, "in", "file1.txt")); os.IsNotExist(err) {
+			break
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/broadcast"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Manager watches the directories that file-based partners deliver into - on an SFTP or FTP server, or
+// on the local filesystem - and converts each arriving file into blob data plus a message. Once the
+// message is confirmed the source file is archived, or deleted.
+type Manager interface {
+	WaitStop()
+}
+
+// Directory is the configuration of a single watched directory, in the ingestion.directories array.
+// The directory is read from the sftp:// or ftp:// server in the URL, or from the local filesystem if there is no URL.
+// The topic and tag can reference the arriving file with {filename}, {basename} and {ext}.
+type Directory struct {
+	Name           string              `json:"name"`
+	URL            string              `json:"url,omitempty"`
+	Username       string              `json:"username,omitempty"`
+	Password       string              `json:"password,omitempty"`
+	PrivateKeyFile string              `json:"privateKeyFile,omitempty"`
+	HostKey        string              `json:"hostKey,omitempty"`
+	Timeout        fftypes.FFDuration  `json:"timeout,omitempty"`
+	Path           string              `json:"path"`
+	Pattern        string              `json:"pattern,omitempty"`
+	Namespace      string              `json:"namespace,omitempty"`
+	Type           fftypes.MessageType `json:"type,omitempty"`
+	Topic          string              `json:"topic,omitempty"`
+	Tag            string              `json:"tag,omitempty"`
+	Group          *fftypes.InputGroup `json:"group,omitempty"`
+	Archive        string              `json:"archive,omitempty"`
+
+	source fileSource
+}
+
+const defaultRemoteTimeout = 30 * time.Second
+
+type ingestionManager struct {
+	ctx          context.Context
+	data         data.Manager
+	broadcast    broadcast.Manager
+	messaging    privatemessaging.Manager
+	directories  []*Directory
+	pollInterval time.Duration
+	settleTime   time.Duration
+	inflight     map[string]bool
+	mux          sync.Mutex
+	wg           sync.WaitGroup
+	done         chan struct{}
+}
+
+func NewIngestionManager(ctx context.Context, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager) (Manager, error) {
+	if dm == nil || bm == nil || pm == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	im := &ingestionManager{
+		ctx:          log.WithLogger(ctx, log.L(ctx).WithField("role", "ingestion")),
+		data:         dm,
+		broadcast:    bm,
+		messaging:    pm,
+		pollInterval: config.GetDuration(config.IngestionPollInterval),
+		settleTime:   config.GetDuration(config.IngestionSettleTime),
+		inflight:     make(map[string]bool),
+		done:         make(chan struct{}),
+	}
+	defaultNS := config.GetString(config.NamespacesDefault)
+	for i, dirObject := range config.GetObjectArray(config.IngestionDirectories) {
+		var dir *Directory
+		b, _ := json.Marshal(dirObject)
+		if err := json.Unmarshal(b, &dir); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgIngestionDirectoryInvalid, i, err)
+		}
+		if err := fftypes.ValidateFFNameField(ctx, dir.Name, "name"); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgIngestionDirectoryInvalid, i, err)
+		}
+		if dir.Path == "" {
+			return nil, i18n.NewError(ctx, i18n.MsgIngestionDirectoryInvalid, i, "a path is required")
+		}
+		if dir.Pattern == "" {
+			dir.Pattern = "*"
+		}
+		if _, err := path.Match(dir.Pattern, ""); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgIngestionDirectoryInvalid, i, err)
+		}
+		if dir.Timeout == 0 {
+			dir.Timeout = fftypes.FFDuration(defaultRemoteTimeout)
+		}
+		var err error
+		if dir.source, err = newFileSource(dir); err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgIngestionDirectoryInvalid, i, err)
+		}
+		if dir.Namespace == "" {
+			dir.Namespace = defaultNS
+		}
+		switch dir.Type {
+		case "":
+			dir.Type = fftypes.MessageTypeBroadcast
+		case fftypes.MessageTypeBroadcast:
+		case fftypes.MessageTypePrivate:
+			if dir.Group == nil {
+				return nil, i18n.NewError(ctx, i18n.MsgIngestionDirectoryInvalid, i, "a group is required for private messages")
+			}
+		default:
+			return nil, i18n.NewError(ctx, i18n.MsgIngestionDirectoryInvalid, i, fmt.Sprintf("unsupported type '%s'", dir.Type))
+		}
+		im.directories = append(im.directories, dir)
+	}
+	if len(im.directories) > 0 {
+		log.L(ctx).Infof("Ingestion watching %d directories every %s", len(im.directories), im.pollInterval)
+		go im.pollLoop()
+	} else {
+		close(im.done)
+	}
+	return im, nil
+}
+
+func (im *ingestionManager) WaitStop() {
+	<-im.done
+	im.wg.Wait()
+}
+
+func (im *ingestionManager) pollLoop() {
+	defer close(im.done)
+	ticker := time.NewTicker(im.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-im.ctx.Done():
+			log.L(im.ctx).Debugf("Ingestion poll loop exiting")
+			return
+		case <-ticker.C:
+		}
+		for _, dir := range im.directories {
+			im.scanDirectory(dir)
+		}
+	}
+}
+
+// scanDirectory starts ingesting each file in the directory that has finished arriving - which we
+// judge by it not having been modified for the settle time, as partners often write files in place.
+func (im *ingestionManager) scanDirectory(dir *Directory) {
+	conn, err := dir.source.connect(im.ctx)
+	if err != nil {
+		log.L(im.ctx).Errorf("Failed to connect to ingestion directory '%s': %s", dir.Name, err)
+		return
+	}
+	files, err := conn.list()
+	conn.close()
+	if err != nil {
+		log.L(im.ctx).Errorf("Failed to scan ingestion directory '%s': %s", dir.Name, err)
+		return
+	}
+	for _, file := range files {
+		if matched, _ := path.Match(dir.Pattern, file.name); !matched || time.Since(file.modified) < im.settleTime {
+			continue
+		}
+		key := dir.Name + "/" + file.name
+		im.mux.Lock()
+		alreadyInflight := im.inflight[key]
+		im.inflight[key] = true
+		im.mux.Unlock()
+		if !alreadyInflight {
+			im.wg.Add(1)
+			go im.ingestFile(dir, file.name)
+		}
+	}
+}
+
+// ingestFile uploads the file, and sends the message. The connection to the source is only held while
+// the file is read, and re-established to archive or delete the file once the message is confirmed.
+func (im *ingestionManager) ingestFile(dir *Directory, name string) {
+	defer func() {
+		im.mux.Lock()
+		delete(im.inflight, dir.Name+"/"+name)
+		im.mux.Unlock()
+		im.wg.Done()
+	}()
+
+	var msg *fftypes.Message
+	d, err := im.uploadFile(dir, name)
+	if err == nil {
+		msg, err = im.sendMessage(dir, name, d)
+	}
+	if err == nil {
+		err = im.removeFile(dir, name)
+	}
+	if err != nil {
+		// The file is left in place, so it will be retried on the next poll
+		log.L(im.ctx).Errorf("Failed to ingest file '%s' from directory '%s': %s", name, dir.Name, err)
+		return
+	}
+	log.L(im.ctx).Infof("Ingested file '%s' from directory '%s' as message '%s'", name, dir.Name, msg.Header.ID)
+}
+
+func (im *ingestionManager) uploadFile(dir *Directory, name string) (*fftypes.Data, error) {
+	conn, err := dir.source.connect(im.ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+	f, err := conn.open(name)
+	if err != nil {
+		return nil, err
+	}
+	d, err := im.data.UploadBLOB(im.ctx, dir.Namespace, &fftypes.DataRefOrValue{}, &fftypes.Multipart{
+		Filename: name,
+		Data:     f,
+	}, true)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return d, err
+}
+
+func (im *ingestionManager) sendMessage(dir *Directory, name string, d *fftypes.Data) (*fftypes.Message, error) {
+	ext := path.Ext(name)
+	placeholders := strings.NewReplacer("{filename}", name, "{basename}", strings.TrimSuffix(name, ext), "{ext}", strings.TrimPrefix(ext, "."))
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Tag: placeholders.Replace(dir.Tag),
+			},
+		},
+		InlineData: fftypes.InlineData{
+			{DataRef: fftypes.DataRef{ID: d.ID, Hash: d.Hash}},
+		},
+		Group: dir.Group,
+	}
+	if dir.Topic != "" {
+		in.Header.Topics = fftypes.FFStringArray{placeholders.Replace(dir.Topic)}
+	}
+	if dir.Type == fftypes.MessageTypePrivate {
+		return im.messaging.SendMessage(im.ctx, dir.Namespace, in, true)
+	}
+	return im.broadcast.BroadcastMessage(im.ctx, dir.Namespace, in, true)
+}
+
+func (im *ingestionManager) removeFile(dir *Directory, name string) error {
+	conn, err := dir.source.connect(im.ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+	if dir.Archive != "" {
+		return conn.archive(name, dir.Archive)
+	}
+	return conn.remove(name)
+}
@@ -0,0 +1,235 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testMocks struct {
+	mdm *datamocks.Manager
+	mbm *broadcastmocks.Manager
+	mpm *privatemessagingmocks.Manager
+}
+
+func newTestIngestion(t *testing.T, directories fftypes.JSONObjectArray, pollInterval string) (*ingestionManager, *testMocks, func()) {
+	config.Reset()
+	config.Set(config.IngestionDirectories, directories)
+	config.Set(config.IngestionPollInterval, pollInterval)
+	config.Set(config.IngestionSettleTime, "0")
+	tm := &testMocks{
+		mdm: &datamocks.Manager{},
+		mbm: &broadcastmocks.Manager{},
+		mpm: &privatemessagingmocks.Manager{},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	im, err := NewIngestionManager(ctx, tm.mdm, tm.mbm, tm.mpm)
+	assert.NoError(t, err)
+	return im.(*ingestionManager), tm, func() {
+		cancel()
+		im.WaitStop()
+		tm.mdm.AssertExpectations(t)
+		tm.mbm.AssertExpectations(t)
+		tm.mpm.AssertExpectations(t)
+	}
+}
+
+func newTestDir(t *testing.T, files ...string) string {
+	dir, err := ioutil.TempDir("", "ff")
+	assert.NoError(t, err)
+	for _, f := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, f), []byte("some data"), 0600)
+		assert.NoError(t, err)
+	}
+	return dir
+}
+
+func TestNewIngestionManagerMissingDeps(t *testing.T) {
+	_, err := NewIngestionManager(context.Background(), nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestNewIngestionManagerInvalid(t *testing.T) {
+	for _, directories := range []fftypes.JSONObjectArray{
+		{{"name": "partner1", "path": false}},
+		{{"name": "!bad", "path": "/tmp"}},
+		{{"name": "partner1"}},
+		{{"name": "partner1", "path": "/tmp", "pattern": "["}},
+		{{"name": "partner1", "path": "/tmp", "type": "private"}},
+		{{"name": "partner1", "path": "/tmp", "type": "transfer_broadcast"}},
+		{{"name": "partner1", "path": "/tmp", "url": "http://example.com"}},
+		{{"name": "partner1", "path": "/tmp", "url": "sftp://example.com"}},
+		{{"name": "partner1", "path": "/tmp", "url": ":::"}},
+	} {
+		config.Reset()
+		config.Set(config.IngestionDirectories, directories)
+		_, err := NewIngestionManager(context.Background(), &datamocks.Manager{}, &broadcastmocks.Manager{}, &privatemessagingmocks.Manager{})
+		assert.Regexp(t, "FF10463", err)
+	}
+}
+
+func TestIngestBroadcastArchived(t *testing.T) {
+	dir := newTestDir(t, "order1.xml")
+	defer os.RemoveAll(dir)
+	archive := newTestDir(t)
+	defer os.RemoveAll(archive)
+
+	_, tm, cancel := newTestIngestion(t, fftypes.JSONObjectArray{
+		{
+			"name":    "partner1",
+			"path":    dir,
+			"pattern": "*.xml",
+			"type":    "broadcast",
+			"topic":   "orders-{basename}",
+			"tag":     "{ext}",
+			"archive": archive,
+		},
+	}, "1ms")
+	defer cancel()
+
+	d := &fftypes.Data{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()}
+	tm.mdm.On("UploadBLOB", mock.Anything, "default", mock.Anything, mock.MatchedBy(func(mp *fftypes.Multipart) bool {
+		return mp.Filename == "order1.xml"
+	}), true).Return(d, nil)
+	sent := make(chan struct{})
+	tm.mbm.On("BroadcastMessage", mock.Anything, "default", mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		return in.Header.Topics.String() == "orders-order1" &&
+			in.Header.Tag == "xml" &&
+			in.InlineData[0].ID.Equals(d.ID)
+	}), true).Return(&fftypes.Message{}, nil).Run(func(args mock.Arguments) {
+		close(sent)
+	})
+
+	<-sent
+	for {
+		if _, err := os.Stat(filepath.Join(archive, "order1.xml")); err == nil {
+			break
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+	_, err := os.Stat(filepath.Join(dir, "order1.xml"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestIngestPrivateDeleted(t *testing.T) {
+	dir := newTestDir(t, "invoice.csv")
+	defer os.RemoveAll(dir)
+
+	im, tm, cancel := newTestIngestion(t, fftypes.JSONObjectArray{
+		{
+			"name":      "partner1",
+			"path":      dir,
+			"namespace": "ns1",
+			"type":      "private",
+			"group": fftypes.JSONObject{
+				"members": []interface{}{fftypes.JSONObject{"identity": "org1"}},
+			},
+		},
+	}, "1h")
+	defer cancel()
+
+	d := &fftypes.Data{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()}
+	tm.mdm.On("UploadBLOB", mock.Anything, "ns1", mock.Anything, mock.Anything, true).Return(d, nil)
+	tm.mpm.On("SendMessage", mock.Anything, "ns1", mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		return in.Header.Topics == nil && in.Group.Members[0].Identity == "org1"
+	}), true).Return(&fftypes.Message{}, nil)
+
+	im.wg.Add(1)
+	im.ingestFile(im.directories[0], "invoice.csv")
+	_, err := os.Stat(filepath.Join(dir, "invoice.csv"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestIngestFailuresLeaveFile(t *testing.T) {
+	dir := newTestDir(t, "file1")
+	defer os.RemoveAll(dir)
+
+	im, tm, cancel := newTestIngestion(t, fftypes.JSONObjectArray{
+		{"name": "partner1", "path": dir, "archive": filepath.Join(dir, "missing")},
+	}, "1h")
+	defer cancel()
+	filename := filepath.Join(dir, "file1")
+
+	d := &fftypes.Data{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()}
+	tm.mdm.On("UploadBLOB", mock.Anything, "default", mock.Anything, mock.Anything, true).Return(nil, fmt.Errorf("pop")).Once()
+	tm.mdm.On("UploadBLOB", mock.Anything, "default", mock.Anything, mock.Anything, true).Return(d, nil)
+	tm.mbm.On("BroadcastMessage", mock.Anything, "default", mock.Anything, true).Return(nil, fmt.Errorf("pop")).Once()
+	tm.mbm.On("BroadcastMessage", mock.Anything, "default", mock.Anything, true).Return(&fftypes.Message{}, nil)
+
+	for i := 0; i < 3; i++ {
+		im.wg.Add(1)
+		im.ingestFile(im.directories[0], "file1")
+		_, err := os.Stat(filename)
+		assert.NoError(t, err)
+	}
+
+	im.wg.Add(1)
+	im.ingestFile(im.directories[0], "missing")
+}
+
+func TestScanDirectorySkips(t *testing.T) {
+	dir := newTestDir(t, "file1")
+	defer os.RemoveAll(dir)
+	err := os.Mkdir(filepath.Join(dir, "subdir"), 0700)
+	assert.NoError(t, err)
+
+	im, _, cancel := newTestIngestion(t, fftypes.JSONObjectArray{}, "1h")
+	defer cancel()
+
+	source := &localSource{path: dir}
+
+	// Already in-flight
+	im.inflight["partner1/file1"] = true
+	im.scanDirectory(&Directory{Name: "partner1", Path: dir, Pattern: "*", source: source})
+
+	// Not yet settled
+	delete(im.inflight, "partner1/file1")
+	im.settleTime = 1 * time.Hour
+	im.scanDirectory(&Directory{Name: "partner1", Path: dir, Pattern: "*", source: source})
+
+	// Missing directory
+	im.scanDirectory(&Directory{Name: "partner1", Path: dir, Pattern: "*", source: &localSource{path: filepath.Join(dir, "missing")}})
+
+	// Connect failure
+	im.scanDirectory(&Directory{Name: "partner1", Path: "/in", Pattern: "*", source: &ftpSource{address: "127.0.0.1:1", timeout: 1 * time.Second}})
+
+	im.wg.Wait()
+}
+
+func TestUploadRemoveConnectFail(t *testing.T) {
+	im, _, cancel := newTestIngestion(t, fftypes.JSONObjectArray{}, "1h")
+	defer cancel()
+	dir := &Directory{Name: "partner1", Path: "/in", source: &ftpSource{address: "127.0.0.1:1", timeout: 1 * time.Second}}
+
+	_, err := im.uploadFile(dir, "file1")
+	assert.Error(t, err)
+	assert.Error(t, im.removeFile(dir, "file1"))
+}
@@ -0,0 +1,159 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+type sftpSource struct {
+	address string
+	path    string
+	timeout time.Duration
+	config  *ssh.ClientConfig
+}
+
+func newSFTPSource(dir *Directory, u *url.URL) (*sftpSource, error) {
+	if dir.HostKey == "" {
+		return nil, fmt.Errorf("a hostKey is required for sftp")
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(dir.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hostKey: %s", err)
+	}
+	var auth []ssh.AuthMethod
+	if dir.PrivateKeyFile != "" {
+		b, err := ioutil.ReadFile(dir.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := ssh.ParsePrivateKey(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid privateKeyFile: %s", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if dir.Password != "" {
+		auth = append(auth, ssh.Password(dir.Password))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("a password or privateKeyFile is required for sftp")
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "22")
+	}
+	return &sftpSource{
+		address: address,
+		path:    dir.Path,
+		timeout: time.Duration(dir.Timeout),
+		config: &ssh.ClientConfig{
+			User:            dir.Username,
+			Auth:            auth,
+			HostKeyCallback: ssh.FixedHostKey(hostKey),
+			Timeout:         time.Duration(dir.Timeout),
+		},
+	}, nil
+}
+
+// sftpConn is a github.com/pkg/sftp client, over an SSH connection that checks the host key of the server
+type sftpConn struct {
+	ctx    context.Context
+	conn   net.Conn
+	ssh    *ssh.Client
+	client *sftp.Client
+	path   string
+}
+
+func (ss *sftpSource) connect(ctx context.Context) (fileConn, error) {
+	conn, err := net.DialTimeout("tcp", ss.address, ss.timeout)
+	if err != nil {
+		return nil, err
+	}
+	sc := &sftpConn{ctx: ctx, conn: &deadlineConn{Conn: conn, timeout: ss.timeout}, path: ss.path}
+	c, chans, reqs, err := ssh.NewClientConn(sc.conn, ss.address, ss.config)
+	if err == nil {
+		sc.ssh = ssh.NewClient(c, chans, reqs)
+		sc.client, err = sftp.NewClient(sc.ssh)
+	}
+	if err != nil {
+		sc.close()
+		return nil, err
+	}
+	return sc, nil
+}
+
+func (sc *sftpConn) remoteError(op string, err error) error {
+	return i18n.NewError(sc.ctx, i18n.MsgIngestionRemoteError, "sftp", op, err)
+}
+
+func (sc *sftpConn) list() ([]*sourceFile, error) {
+	infos, err := sc.client.ReadDir(sc.path)
+	if err != nil {
+		return nil, sc.remoteError("readdir", err)
+	}
+	files := make([]*sourceFile, 0, len(infos))
+	for _, info := range infos {
+		if !info.IsDir() {
+			files = append(files, &sourceFile{name: info.Name(), modified: info.ModTime()})
+		}
+	}
+	return files, nil
+}
+
+func (sc *sftpConn) open(name string) (io.ReadCloser, error) {
+	f, err := sc.client.Open(path.Join(sc.path, name))
+	if err != nil {
+		return nil, sc.remoteError("open", err)
+	}
+	return f, nil
+}
+
+func (sc *sftpConn) archive(name, archiveDir string) error {
+	if err := sc.client.Rename(path.Join(sc.path, name), path.Join(archiveDir, name)); err != nil {
+		return sc.remoteError("rename", err)
+	}
+	return nil
+}
+
+func (sc *sftpConn) remove(name string) error {
+	if err := sc.client.Remove(path.Join(sc.path, name)); err != nil {
+		return sc.remoteError("remove", err)
+	}
+	return nil
+}
+
+func (sc *sftpConn) close() {
+	if sc.client != nil {
+		_ = sc.client.Close()
+	}
+	if sc.ssh != nil {
+		_ = sc.ssh.Close()
+	}
+	_ = sc.conn.Close()
+}
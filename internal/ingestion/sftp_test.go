@@ -0,0 +1,281 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingestion
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/ssh"
+)
+
+// newTestSFTPServer runs an SSH server with an sftp subsystem, serving an in-memory filesystem
+func newTestSFTPServer(t *testing.T) (addr string, hostKey string, done func()) {
+	handlers := sftp.InMemHandler()
+	return newTestSSHServer(t, func(rw io.ReadWriteCloser) {
+		server := sftp.NewRequestServer(rw, handlers)
+		_ = server.Serve()
+		server.Close()
+	})
+}
+
+func newTestSSHServer(t *testing.T, subsystem func(rw io.ReadWriteCloser)) (addr string, hostKey string, done func()) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(priv)
+	assert.NoError(t, err)
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == "user1" && string(pass) == "pass1" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("denied")
+		},
+	}
+	config.AddHostKey(signer)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for newChannel := range chans {
+					ch, chReqs, _ := newChannel.Accept()
+					go func() {
+						for req := range chReqs {
+							ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+							_ = req.Reply(ok, nil)
+							if ok {
+								go subsystem(ch)
+							}
+						}
+					}()
+				}
+			}()
+		}
+	}()
+	return l.Addr().String(), string(ssh.MarshalAuthorizedKey(signer.PublicKey())), func() { l.Close() }
+}
+
+func newTestSFTPDir(t *testing.T, addr, hostKey string) *Directory {
+	u, _ := url.Parse("sftp://" + addr)
+	ss, err := newSFTPSource(&Directory{
+		Username: "user1",
+		Password: "pass1",
+		HostKey:  hostKey,
+		Path:     "/in",
+		Timeout:  fftypes.FFDuration(5 * time.Second),
+	}, u)
+	assert.NoError(t, err)
+	return &Directory{Name: "partner1", Path: "/in", source: ss}
+}
+
+// newTestSFTPConn connects to the server, and writes the files
+func newTestSFTPConn(t *testing.T, addr, hostKey string, files map[string]string) *sftpConn {
+	conn, err := newTestSFTPDir(t, addr, hostKey).source.connect(context.Background())
+	assert.NoError(t, err)
+	sc := conn.(*sftpConn)
+	assert.NoError(t, sc.client.MkdirAll("/in/subdir"))
+	assert.NoError(t, sc.client.MkdirAll("/archive"))
+	for name, content := range files {
+		f, err := sc.client.Create(name)
+		assert.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		assert.NoError(t, err)
+		assert.NoError(t, f.Close())
+	}
+	return sc
+}
+
+func TestSFTPListReadArchiveRemove(t *testing.T) {
+	addr, hostKey, done := newTestSFTPServer(t)
+	defer done()
+	big := make([]byte, 100000)
+	_, _ = rand.Read(big)
+	conn := newTestSFTPConn(t, addr, hostKey, map[string]string{
+		"/in/big.bin":   string(big),
+		"/in/small.txt": "some data",
+	})
+	defer conn.close()
+
+	files, err := conn.list()
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	for _, f := range files {
+		assert.WithinDuration(t, time.Now(), f.modified, 1*time.Minute)
+	}
+
+	r, err := conn.open("big.bin")
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, big, b)
+	assert.NoError(t, r.Close())
+
+	_, err = conn.open("missing")
+	assert.Regexp(t, "FF10484.*open.*not exist", err)
+
+	assert.NoError(t, conn.archive("big.bin", "/archive"))
+	_, err = conn.client.Stat("/archive/big.bin")
+	assert.NoError(t, err)
+	assert.Regexp(t, "FF10484.*rename", conn.archive("big.bin", "/archive"))
+
+	assert.NoError(t, conn.remove("small.txt"))
+	assert.Regexp(t, "FF10484.*remove", conn.remove("small.txt"))
+}
+
+func TestSFTPListMissingDir(t *testing.T) {
+	addr, hostKey, done := newTestSFTPServer(t)
+	defer done()
+	dir := newTestSFTPDir(t, addr, hostKey)
+
+	conn, err := dir.source.connect(context.Background())
+	assert.NoError(t, err)
+	defer conn.close()
+	_, err = conn.list()
+	assert.Regexp(t, "FF10484.*readdir", err)
+}
+
+func TestSFTPConnectFailures(t *testing.T) {
+	addr, hostKey, done := newTestSFTPServer(t)
+	defer done()
+
+	// Wrong password
+	dir := newTestSFTPDir(t, addr, hostKey)
+	dir.source.(*sftpSource).config.Auth = []ssh.AuthMethod{ssh.Password("wrong")}
+	_, err := dir.source.connect(context.Background())
+	assert.Regexp(t, "unable to authenticate", err)
+
+	// Wrong host key
+	_, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+	otherSigner, _ := ssh.NewSignerFromKey(otherPriv)
+	dir = newTestSFTPDir(t, addr, string(ssh.MarshalAuthorizedKey(otherSigner.PublicKey())))
+	_, err = dir.source.connect(context.Background())
+	assert.Regexp(t, "host key mismatch", err)
+
+	// Not listening
+	done()
+	dir = newTestSFTPDir(t, addr, hostKey)
+	_, err = dir.source.connect(context.Background())
+	assert.Error(t, err)
+}
+
+func TestNewSFTPSourceConfig(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(rand.Reader)
+	signer, _ := ssh.NewSignerFromKey(priv)
+	hostKey := string(ssh.MarshalAuthorizedKey(signer.PublicKey()))
+	u, _ := url.Parse("sftp://localhost")
+
+	keyFile, err := ioutil.TempFile("", "ff")
+	assert.NoError(t, err)
+	defer os.Remove(keyFile.Name())
+	_, _ = keyFile.WriteString("not a key")
+	keyFile.Close()
+
+	for _, test := range []struct {
+		dir *Directory
+		err string
+	}{
+		{&Directory{}, "hostKey is required"},
+		{&Directory{HostKey: "bad"}, "invalid hostKey"},
+		{&Directory{HostKey: hostKey}, "password or privateKeyFile is required"},
+		{&Directory{HostKey: hostKey, PrivateKeyFile: "/does/not/exist"}, "no such file"},
+		{&Directory{HostKey: hostKey, PrivateKeyFile: keyFile.Name()}, "invalid privateKeyFile"},
+	} {
+		_, err := newSFTPSource(test.dir, u)
+		assert.Regexp(t, test.err, err)
+	}
+
+	ss, err := newSFTPSource(&Directory{HostKey: hostKey, Password: "pass1"}, u)
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:22", ss.address)
+
+	pkcs8, _ := x509.MarshalPKCS8PrivateKey(priv)
+	assert.NoError(t, ioutil.WriteFile(keyFile.Name(), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), 0600))
+	ss, err = newSFTPSource(&Directory{HostKey: hostKey, PrivateKeyFile: keyFile.Name()}, u)
+	assert.NoError(t, err)
+	assert.Len(t, ss.config.Auth, 1)
+}
+
+func TestSFTPConnectSubsystemClosed(t *testing.T) {
+	addr, hostKey, done := newTestSSHServer(t, func(rw io.ReadWriteCloser) {
+		rw.Close()
+	})
+	defer done()
+
+	dir := newTestSFTPDir(t, addr, hostKey)
+	_, err := dir.source.connect(context.Background())
+	assert.Error(t, err)
+}
+
+func TestIngestFromSFTP(t *testing.T) {
+	addr, hostKey, done := newTestSFTPServer(t)
+	defer done()
+	conn := newTestSFTPConn(t, addr, hostKey, map[string]string{"/in/order1.xml": "<order/>"})
+	defer conn.close()
+
+	_, tm, cancel := newTestIngestion(t, fftypes.JSONObjectArray{
+		{
+			"name":     "partner1",
+			"url":      "sftp://" + addr,
+			"username": "user1",
+			"password": "pass1",
+			"hostKey":  hostKey,
+			"path":     "/in",
+			"archive":  "/archive",
+		},
+	}, "1ms")
+	defer cancel()
+
+	d := &fftypes.Data{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()}
+	tm.mdm.On("UploadBLOB", mock.Anything, "default", mock.Anything, mock.MatchedBy(func(mp *fftypes.Multipart) bool {
+		b, _ := ioutil.ReadAll(mp.Data)
+		return mp.Filename == "order1.xml" && string(b) == "<order/>"
+	}), true).Return(d, nil)
+	tm.mbm.On("BroadcastMessage", mock.Anything, "default", mock.Anything, true).Return(&fftypes.Message{}, nil)
+
+	for {
+		if _, err := conn.client.Stat("/archive/order1.xml"); err == nil {
+			break
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+}
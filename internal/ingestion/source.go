@@ -0,0 +1,120 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileSource is where the files of a watched directory are read from - the local filesystem, or an SFTP or FTP server
+type fileSource interface {
+	connect(ctx context.Context) (fileConn, error)
+}
+
+// fileConn is a connection to a file source, used by a single goroutine. Names are relative to the watched directory.
+type fileConn interface {
+	list() ([]*sourceFile, error)
+	open(name string) (io.ReadCloser, error)
+	archive(name, archiveDir string) error
+	remove(name string) error
+	close()
+}
+
+type sourceFile struct {
+	name     string
+	modified time.Time
+}
+
+func newFileSource(dir *Directory) (fileSource, error) {
+	if dir.URL == "" {
+		return &localSource{path: dir.Path}, nil
+	}
+	u, err := url.Parse(dir.URL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "sftp":
+		return newSFTPSource(dir, u)
+	case "ftp":
+		return newFTPSource(dir, u), nil
+	default:
+		return nil, fmt.Errorf("unsupported url scheme '%s'", u.Scheme)
+	}
+}
+
+// deadlineConn refreshes the deadline of a connection to an SFTP or FTP server on every read and write, so an
+// unresponsive server cannot hang the poller
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (dc *deadlineConn) Read(b []byte) (int, error) {
+	_ = dc.Conn.SetDeadline(time.Now().Add(dc.timeout))
+	return dc.Conn.Read(b)
+}
+
+func (dc *deadlineConn) Write(b []byte) (int, error) {
+	_ = dc.Conn.SetDeadline(time.Now().Add(dc.timeout))
+	return dc.Conn.Write(b)
+}
+
+// localSource reads a directory on the local filesystem, such as one that an SFTP or FTP server delivers into
+type localSource struct {
+	path string
+}
+
+func (ls *localSource) connect(ctx context.Context) (fileConn, error) {
+	return ls, nil
+}
+
+func (ls *localSource) list() ([]*sourceFile, error) {
+	infos, err := ioutil.ReadDir(ls.path)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]*sourceFile, 0, len(infos))
+	for _, info := range infos {
+		if !info.IsDir() {
+			files = append(files, &sourceFile{name: info.Name(), modified: info.ModTime()})
+		}
+	}
+	return files, nil
+}
+
+func (ls *localSource) open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(ls.path, name))
+}
+
+func (ls *localSource) archive(name, archiveDir string) error {
+	return os.Rename(filepath.Join(ls.path, name), filepath.Join(archiveDir, name))
+}
+
+func (ls *localSource) remove(name string) error {
+	return os.Remove(filepath.Join(ls.path, name))
+}
+
+func (ls *localSource) close() {}
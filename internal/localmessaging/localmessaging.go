@@ -0,0 +1,87 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localmessaging
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/batch"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/syncasync"
+	"github.com/hyperledger/firefly/internal/sysmessaging"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const localDispatcherName = "local"
+
+// Manager sends local messages, which are stored, validated and confirmed on this node only - with the same
+// API and event model as other messages, but without a blockchain pin or distribution to any other party
+type Manager interface {
+	fftypes.Named
+
+	NewMessage(ns string, in *fftypes.MessageInOut) sysmessaging.MessageSender
+	SendMessage(ctx context.Context, ns string, in *fftypes.MessageInOut, waitConfirm bool) (out *fftypes.Message, err error)
+	ValidateMessage(ctx context.Context, ns string, in *fftypes.MessageInOut) *fftypes.MessageValidationReport
+}
+
+type localMessaging struct {
+	ctx                   context.Context
+	identity              identity.Manager
+	data                  data.Manager
+	syncasync             syncasync.Bridge
+	maxBatchPayloadLength int64
+}
+
+func NewLocalMessaging(ctx context.Context, im identity.Manager, dm data.Manager, ba batch.Manager, sa syncasync.Bridge) Manager {
+	lm := &localMessaging{
+		ctx:                   ctx,
+		identity:              im,
+		data:                  dm,
+		syncasync:             sa,
+		maxBatchPayloadLength: config.GetByteSize(config.LocalMessagingBatchPayloadLimit),
+	}
+
+	bo := batch.DispatcherOptions{
+		BatchType:      fftypes.BatchTypeLocal,
+		BatchMaxSize:   config.GetUint(config.LocalMessagingBatchSize),
+		BatchMaxBytes:  lm.maxBatchPayloadLength,
+		BatchTimeout:   config.GetDuration(config.LocalMessagingBatchTimeout),
+		DisposeTimeout: config.GetDuration(config.LocalMessagingBatchAgentTimeout),
+	}
+
+	// The batch processor confirms unpinned messages as soon as they are dispatched, and emits the events
+	ba.RegisterDispatcher(localDispatcherName,
+		fftypes.TransactionTypeUnpinned,
+		[]fftypes.MessageType{
+			fftypes.MessageTypeLocal,
+		}, lm.dispatchBatch, bo)
+
+	return lm
+}
+
+func (lm *localMessaging) Name() string {
+	return "LocalMessaging"
+}
+
+func (lm *localMessaging) dispatchBatch(ctx context.Context, state *batch.DispatchState) error {
+	// Nothing to send - the batch is confirmed locally once dispatched
+	log.L(ctx).Infof("Confirming local batch %s with %d messages", state.Persisted.ID, len(state.Payload.Messages))
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localmessaging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/batch"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/batchmocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestLocalMessaging(t *testing.T) (*localMessaging, func()) {
+	config.Reset()
+	mim := &identitymanagermocks.Manager{}
+	mdm := &datamocks.Manager{}
+	mba := &batchmocks.Manager{}
+	msa := &syncasyncmocks.Bridge{}
+	mba.On("RegisterDispatcher",
+		localDispatcherName,
+		fftypes.TransactionTypeUnpinned,
+		[]fftypes.MessageType{
+			fftypes.MessageTypeLocal,
+		}, mock.Anything, mock.Anything).Return()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lm := NewLocalMessaging(ctx, mim, mdm, mba, msa)
+	mba.AssertExpectations(t)
+	return lm.(*localMessaging), cancel
+}
+
+func TestName(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	assert.Equal(t, "LocalMessaging", lm.Name())
+}
+
+func TestDispatchBatch(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+
+	err := lm.dispatchBatch(context.Background(), &batch.DispatchState{
+		Persisted: fftypes.BatchPersisted{
+			BatchHeader: fftypes.BatchHeader{
+				ID: fftypes.NewUUID(),
+			},
+		},
+		Payload: fftypes.BatchPayload{
+			Messages: []*fftypes.Message{{}},
+		},
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,185 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localmessaging
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/sysmessaging"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (lm *localMessaging) NewMessage(ns string, in *fftypes.MessageInOut) sysmessaging.MessageSender {
+	message := &messageSender{
+		mgr:       lm,
+		namespace: ns,
+		msg: &data.NewMessage{
+			Message: in,
+		},
+	}
+	message.setDefaults()
+	return message
+}
+
+func (lm *localMessaging) SendMessage(ctx context.Context, ns string, in *fftypes.MessageInOut, waitConfirm bool) (out *fftypes.Message, err error) {
+	message := lm.NewMessage(ns, in)
+	if waitConfirm {
+		err = message.SendAndWait(ctx)
+	} else {
+		err = message.Send(ctx)
+	}
+	return &in.Message, err
+}
+
+// ValidateMessage runs the checks that would be performed when sending the message, without writing anything.
+// Every check is reported, rather than stopping at the first failure.
+func (lm *localMessaging) ValidateMessage(ctx context.Context, ns string, in *fftypes.MessageInOut) *fftypes.MessageValidationReport {
+	return lm.NewMessage(ns, in).(*messageSender).validate(ctx)
+}
+
+type messageSender struct {
+	mgr       *localMessaging
+	namespace string
+	msg       *data.NewMessage
+	resolved  bool
+}
+
+// sendMethod is the specific operation requested of the messageSender.
+type sendMethod int
+
+const (
+	// methodPrepare requests that the message be validated and sealed, but not sent (i.e. no database writes are performed)
+	methodPrepare sendMethod = iota
+	// methodSend requests that the message be stored, to be confirmed locally, but does not wait for confirmation
+	methodSend
+	// methodSendAndWait requests that the message be stored, and waits until it is confirmed locally
+	methodSendAndWait
+)
+
+func (s *messageSender) Prepare(ctx context.Context) error {
+	return s.resolveAndSend(ctx, methodPrepare)
+}
+
+func (s *messageSender) Send(ctx context.Context) error {
+	return s.resolveAndSend(ctx, methodSend)
+}
+
+func (s *messageSender) SendAndWait(ctx context.Context) error {
+	return s.resolveAndSend(ctx, methodSendAndWait)
+}
+
+func (s *messageSender) setDefaults() {
+	msg := s.msg.Message
+	msg.Header.ID = fftypes.NewUUID()
+	msg.Header.Namespace = s.namespace
+	msg.State = fftypes.MessageStateReady
+	msg.Header.Type = fftypes.MessageTypeLocal
+	// Local messages are never pinned
+	msg.Header.TxType = fftypes.TransactionTypeUnpinned
+}
+
+func (s *messageSender) resolveAndSend(ctx context.Context, method sendMethod) error {
+	if !s.resolved {
+		if err := s.resolve(ctx); err != nil {
+			return err
+		}
+		msgSizeEstimate := s.msg.Message.EstimateSize(true)
+		if msgSizeEstimate > s.mgr.maxBatchPayloadLength {
+			return i18n.NewError(ctx, i18n.MsgTooLargeLocal, float64(msgSizeEstimate)/1024, float64(s.mgr.maxBatchPayloadLength)/1024)
+		}
+		s.resolved = true
+	}
+	return s.sendInternal(ctx, method)
+}
+
+func (s *messageSender) resolve(ctx context.Context) error {
+	msg := s.msg.Message
+
+	// Fill in anything not supplied on the send from the template
+	if msg.Template != "" {
+		if err := s.mgr.data.ApplyMessageTemplate(ctx, msg); err != nil {
+			return err
+		}
+	}
+	if msg.Header.Group != nil || msg.Group != nil {
+		return i18n.NewError(ctx, i18n.MsgLocalMessageGroup)
+	}
+
+	// Resolve the sending identity
+	if err := s.mgr.identity.ResolveInputSigningIdentity(ctx, msg.Header.Namespace, &msg.Header.SignerRef); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgAuthorInvalid)
+	}
+
+	// The data manager is responsible for the heavy lifting of storing/validating all our in-line data elements
+	return s.mgr.data.ResolveInlineData(ctx, s.msg)
+}
+
+func (s *messageSender) validate(ctx context.Context) *fftypes.MessageValidationReport {
+	msg := s.msg.Message
+	report := fftypes.NewMessageValidationReport(msg.Header.Type)
+
+	// The remaining checks depend on the template being applied
+	if msg.Template != "" && !report.AddCheck(fftypes.MessageValidationCheckTemplate, s.mgr.data.ApplyMessageTemplate(ctx, msg)) {
+		return report
+	}
+	if msg.Header.Group != nil || msg.Group != nil {
+		report.AddCheck(fftypes.MessageValidationCheckGroup, i18n.NewError(ctx, i18n.MsgLocalMessageGroup))
+	}
+	err := s.mgr.identity.ResolveInputSigningIdentity(ctx, msg.Header.Namespace, &msg.Header.SignerRef)
+	if err != nil {
+		err = i18n.WrapError(ctx, err, i18n.MsgAuthorInvalid)
+	}
+	report.AddCheck(fftypes.MessageValidationCheckIdentity, err)
+	if report.AddCheck(fftypes.MessageValidationCheckData, s.mgr.data.ResolveInlineData(ctx, s.msg)) {
+		err = nil
+		report.Size = msg.EstimateSize(true)
+		report.MaxSize = s.mgr.maxBatchPayloadLength
+		if report.Size > report.MaxSize {
+			err = i18n.NewError(ctx, i18n.MsgTooLargeLocal, float64(report.Size)/1024, float64(report.MaxSize)/1024)
+		}
+		report.AddCheck(fftypes.MessageValidationCheckSize, err)
+	}
+	return report
+}
+
+func (s *messageSender) sendInternal(ctx context.Context, method sendMethod) (err error) {
+	if method == methodSendAndWait {
+		out, err := s.mgr.syncasync.WaitForMessage(ctx, s.namespace, s.msg.Message.Header.ID, s.Send)
+		if out != nil {
+			s.msg.Message.Message = *out
+		}
+		return err
+	}
+
+	msg := s.msg.Message
+	if err := msg.Seal(ctx); err != nil {
+		return err
+	}
+	if method == methodPrepare {
+		return nil
+	}
+
+	if err := s.mgr.data.WriteNewMessage(ctx, s.msg); err != nil {
+		return err
+	}
+	log.L(ctx).Infof("Sent local message %s:%s sequence=%d datacount=%d", msg.Header.Namespace, msg.Header.ID, msg.Sequence, len(s.msg.AllData))
+
+	return nil
+}
@@ -0,0 +1,356 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localmessaging
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/syncasync"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestLocalMessage() *fftypes.MessageInOut {
+	return &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				SignerRef: fftypes.SignerRef{
+					Author: "did:firefly:org/abcd",
+					Key:    "0x12345",
+				},
+			},
+		},
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"hello": "world"}`)},
+		},
+	}
+}
+
+func TestSendMessageOk(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", ctx, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	msg, err := lm.SendMessage(ctx, "ns1", newTestLocalMessage(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", msg.Header.Namespace)
+	assert.Equal(t, fftypes.MessageTypeLocal, msg.Header.Type)
+	assert.Equal(t, fftypes.TransactionTypeUnpinned, msg.Header.TxType)
+	assert.NotNil(t, msg.Hash)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestSendMessageTemplateOk(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ApplyMessageTemplate", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		in := args[1].(*fftypes.MessageInOut)
+		in.Header.Tag = "tag1"
+	})
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", ctx, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	in := newTestLocalMessage()
+	in.Template = "template1"
+	msg, err := lm.SendMessage(ctx, "ns1", in, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "tag1", msg.Header.Tag)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestSendMessageTemplateFail(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ApplyMessageTemplate", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	in := newTestLocalMessage()
+	in.Template = "template1"
+	_, err := lm.SendMessage(ctx, "ns1", in, false)
+	assert.EqualError(t, err, "pop")
+
+	mdm.AssertExpectations(t)
+}
+
+func TestSendMessageGroupRejected(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+
+	in := newTestLocalMessage()
+	in.Group = &fftypes.InputGroup{
+		Members: []fftypes.MemberInput{{Identity: "org1"}},
+	}
+	_, err := lm.SendMessage(context.Background(), "ns1", in, false)
+	assert.Regexp(t, "FF10514", err)
+}
+
+func TestSendMessageBadIdentity(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := lm.SendMessage(ctx, "ns1", newTestLocalMessage(), false)
+	assert.Regexp(t, "FF10206.*pop", err)
+
+	mim.AssertExpectations(t)
+}
+
+func TestSendMessageBadInput(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	_, err := lm.SendMessage(ctx, "ns1", newTestLocalMessage(), false)
+	assert.EqualError(t, err, "pop")
+
+	mdm.AssertExpectations(t)
+}
+
+func TestSendMessageTooLarge(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	lm.maxBatchPayloadLength = 1000000
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Run(
+		func(args mock.Arguments) {
+			newMsg := args[1].(*data.NewMessage)
+			newMsg.Message.Data = fftypes.DataRefs{
+				{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32(), ValueSize: 1000001},
+			}
+		}).
+		Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	_, err := lm.SendMessage(ctx, "ns1", newTestLocalMessage(), false)
+	assert.Regexp(t, "FF10513", err)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestSendMessageWriteFail(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	_, err := lm.SendMessage(ctx, "ns1", newTestLocalMessage(), false)
+	assert.EqualError(t, err, "pop")
+
+	mdm.AssertExpectations(t)
+}
+
+func TestSendMessageWaitConfirmOk(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+	msa := lm.syncasync.(*syncasyncmocks.Bridge)
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	replyMsg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			Namespace: "ns1",
+			ID:        fftypes.NewUUID(),
+		},
+		State: fftypes.MessageStateConfirmed,
+	}
+	msa.On("WaitForMessage", ctx, "ns1", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			send := args[3].(syncasync.RequestSender)
+			send(ctx)
+		}).
+		Return(replyMsg, nil)
+	mdm.On("WriteNewMessage", ctx, mock.Anything).Return(nil)
+
+	msg, err := lm.SendMessage(ctx, "ns1", newTestLocalMessage(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, *replyMsg, *msg)
+
+	msa.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestPrepareAndSendMessage(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil).Once()
+	mdm.On("WriteNewMessage", ctx, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil).Once()
+
+	in := newTestLocalMessage()
+	sender := lm.NewMessage("ns1", in)
+	err := sender.Prepare(ctx)
+	assert.NoError(t, err)
+	assert.NotNil(t, in.Hash)
+
+	err = sender.Send(ctx)
+	assert.NoError(t, err)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestPrepareMessageSealFail(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	in := newTestLocalMessage()
+	in.Header.Topics = fftypes.FFStringArray{"!bad topic"}
+	err := lm.NewMessage("ns1", in).Prepare(ctx)
+	assert.Regexp(t, "FF10131", err)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestValidateMessageOk(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ApplyMessageTemplate", ctx, mock.Anything).Return(nil)
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	msg := newTestLocalMessage()
+	msg.Template = "template1"
+	report := lm.ValidateMessage(ctx, "ns1", msg)
+	assert.True(t, report.Valid)
+	assert.Equal(t, fftypes.MessageTypeLocal, report.Type)
+	assert.Len(t, report.Checks, 4)
+
+	mdm.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestValidateMessageTemplateFail(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ApplyMessageTemplate", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	msg := newTestLocalMessage()
+	msg.Template = "template1"
+	report := lm.ValidateMessage(ctx, "ns1", msg)
+	assert.False(t, report.Valid)
+	assert.Len(t, report.Checks, 1)
+
+	mdm.AssertExpectations(t)
+}
+
+func TestValidateMessageAllFail(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Return(fmt.Errorf("pop"))
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(fmt.Errorf("pop"))
+
+	msg := newTestLocalMessage()
+	msg.Header.Group = fftypes.NewRandB32()
+	report := lm.ValidateMessage(ctx, "ns1", msg)
+	assert.False(t, report.Valid)
+	assert.Len(t, report.Checks, 3)
+	assert.Equal(t, fftypes.MessageValidationCheckGroup, report.Checks[0].Check)
+	assert.Regexp(t, "FF10206.*pop", report.Checks[1].Error)
+
+	mdm.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestValidateMessageTooLarge(t *testing.T) {
+	lm, cancel := newTestLocalMessaging(t)
+	lm.maxBatchPayloadLength = 1000000
+	defer cancel()
+	mdm := lm.data.(*datamocks.Manager)
+	mim := lm.identity.(*identitymanagermocks.Manager)
+
+	ctx := context.Background()
+	mdm.On("ResolveInlineData", ctx, mock.Anything).Run(
+		func(args mock.Arguments) {
+			newMsg := args[1].(*data.NewMessage)
+			newMsg.Message.Data = fftypes.DataRefs{
+				{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32(), ValueSize: 1000001},
+			}
+		}).
+		Return(nil)
+	mim.On("ResolveInputSigningIdentity", ctx, "ns1", mock.Anything).Return(nil)
+
+	report := lm.ValidateMessage(ctx, "ns1", newTestLocalMessage())
+	assert.False(t, report.Valid)
+	assert.Regexp(t, "FF10513", report.Checks[2].Error)
+
+	mdm.AssertExpectations(t)
+}
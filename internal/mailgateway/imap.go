@@ -0,0 +1,142 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailgateway
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+type imapConfig struct {
+	url      *url.URL
+	username string
+	password string
+	timeout  time.Duration
+}
+
+// imapClient polls a mailbox for replies, with the commands of the go-imap client that are needed
+type imapClient struct {
+	ctx    context.Context
+	client *client.Client
+}
+
+// dialIMAP connects to the server - with TLS for imaps:// URLs - logs in, and selects the mailbox in the URL path
+func dialIMAP(ctx context.Context, conf *imapConfig) (*imapClient, error) {
+	address := conf.url.Host
+	if conf.url.Port() == "" {
+		port := "143"
+		if conf.url.Scheme == "imaps" {
+			port = "993"
+		}
+		address = net.JoinHostPort(conf.url.Hostname(), port)
+	}
+	dialer := &net.Dialer{Timeout: conf.timeout}
+	var ic *client.Client
+	var err error
+	if conf.url.Scheme == "imaps" {
+		ic, err = client.DialWithDialerTLS(dialer, address, &tls.Config{ServerName: conf.url.Hostname()})
+	} else {
+		ic, err = client.DialWithDialer(dialer, address)
+	}
+	if err != nil {
+		return nil, imapError(ctx, "connect", err)
+	}
+	ic.Timeout = conf.timeout
+	c := &imapClient{ctx: ctx, client: ic}
+
+	// The credentials are not included in errors, as the client does not echo the arguments of commands
+	if err = ic.Login(conf.username, conf.password); err != nil {
+		err = imapError(ctx, "LOGIN", err)
+	}
+	if err == nil {
+		mailbox := strings.TrimPrefix(conf.url.Path, "/")
+		if mailbox == "" {
+			mailbox = "INBOX"
+		}
+		if _, err = ic.Select(mailbox, false); err != nil {
+			err = imapError(ctx, "SELECT", err)
+		}
+	}
+	if err != nil {
+		_ = ic.Terminate()
+		return nil, err
+	}
+	return c, nil
+}
+
+func imapError(ctx context.Context, command string, err error) error {
+	return i18n.NewError(ctx, i18n.MsgEmailRepliesIMAPError, command, err)
+}
+
+// unseen returns the UIDs of the messages in the mailbox that have not been seen
+func (c *imapClient) unseen() ([]uint32, error) {
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.SeenFlag}
+	uids, err := c.client.UidSearch(criteria)
+	if err != nil {
+		return nil, imapError(c.ctx, "UID SEARCH", err)
+	}
+	return uids, nil
+}
+
+// fetch returns the raw email, without marking it as seen
+func (c *imapClient) fetch(uid uint32) ([]byte, error) {
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uid)
+	section := &imap.BodySectionName{Peek: true}
+	messages := make(chan *imap.Message, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.client.UidFetch(seqSet, []imap.FetchItem{section.FetchItem()}, messages)
+	}()
+	var raw []byte
+	for msg := range messages {
+		if body := msg.GetBody(section); body != nil {
+			// The literal is already read into memory by the client
+			raw, _ = ioutil.ReadAll(body)
+		}
+	}
+	if err := <-done; err != nil {
+		return nil, imapError(c.ctx, "UID FETCH", err)
+	}
+	if raw == nil {
+		return nil, i18n.NewError(c.ctx, i18n.MsgEmailRepliesIMAPError, "UID FETCH", "no message returned")
+	}
+	return raw, nil
+}
+
+func (c *imapClient) markSeen(uid uint32) error {
+	seqSet := new(imap.SeqSet)
+	seqSet.AddNum(uid)
+	if err := c.client.UidStore(seqSet, imap.FormatFlagsOp(imap.AddFlags, true), []interface{}{imap.SeenFlag}, nil); err != nil {
+		return imapError(c.ctx, "UID STORE", err)
+	}
+	return nil
+}
+
+func (c *imapClient) logout() {
+	_ = c.client.Logout()
+}
@@ -0,0 +1,272 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailgateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/backend"
+	"github.com/emersion/go-imap/backend/memory"
+	"github.com/emersion/go-imap/server"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testIMAPServer is a go-imap server over its memory backend, with a single INBOX for user1
+type testIMAPServer struct {
+	addr     string
+	mux      sync.Mutex
+	user     backend.User
+	inbox    *memory.Mailbox
+	failures map[string]string
+	server   *server.Server
+}
+
+type testIMAPUser struct {
+	backend.User
+	s *testIMAPServer
+}
+
+type testIMAPMailbox struct {
+	backend.Mailbox
+	s *testIMAPServer
+}
+
+// newTestIMAPServer serves the messages, keyed by UID. Commands can be failed with the message in failures.
+func newTestIMAPServer(t *testing.T, messages map[uint32]string, failures map[string]string) *testIMAPServer {
+	be := memory.New()
+	user, err := be.Login(nil, "username", "password")
+	assert.NoError(t, err)
+	mbox, err := user.GetMailbox("INBOX")
+	assert.NoError(t, err)
+	inbox := mbox.(*memory.Mailbox)
+	inbox.Messages = nil
+	var uids []uint32
+	for uid := range messages {
+		uids = append(uids, uid)
+	}
+	sort.Slice(uids, func(i, j int) bool { return uids[i] < uids[j] })
+	for _, uid := range uids {
+		inbox.Messages = append(inbox.Messages, &memory.Message{Uid: uid, Date: time.Now(), Size: uint32(len(messages[uid])), Body: []byte(messages[uid])})
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	s := &testIMAPServer{
+		addr:     l.Addr().String(),
+		user:     user,
+		inbox:    inbox,
+		failures: failures,
+	}
+	s.server = server.New(s)
+	s.server.AllowInsecureAuth = true
+	go func() {
+		_ = s.server.Serve(l)
+	}()
+	return s
+}
+
+func (s *testIMAPServer) failure(command string) error {
+	if msg, ok := s.failures[command]; ok {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (s *testIMAPServer) Login(_ *imap.ConnInfo, username, password string) (backend.User, error) {
+	if err := s.failure("LOGIN"); err != nil {
+		return nil, err
+	}
+	if username != "user1" || password != "pass1" {
+		return nil, backend.ErrInvalidCredentials
+	}
+	return &testIMAPUser{User: s.user, s: s}, nil
+}
+
+func (s *testIMAPServer) isSeen(uid uint32) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, msg := range s.inbox.Messages {
+		if msg.Uid == uid {
+			for _, flag := range msg.Flags {
+				if flag == imap.SeenFlag {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (u *testIMAPUser) GetMailbox(name string) (backend.Mailbox, error) {
+	if err := u.s.failure("SELECT"); err != nil {
+		return nil, err
+	}
+	mbox, err := u.User.GetMailbox(name)
+	if err != nil {
+		return nil, err
+	}
+	return &testIMAPMailbox{Mailbox: mbox, s: u.s}, nil
+}
+
+func (m *testIMAPMailbox) SearchMessages(uid bool, criteria *imap.SearchCriteria) ([]uint32, error) {
+	if err := m.s.failure("UID SEARCH"); err != nil {
+		return nil, err
+	}
+	m.s.mux.Lock()
+	defer m.s.mux.Unlock()
+	return m.Mailbox.SearchMessages(uid, criteria)
+}
+
+func (m *testIMAPMailbox) ListMessages(uid bool, seqSet *imap.SeqSet, items []imap.FetchItem, ch chan<- *imap.Message) error {
+	if err := m.s.failure("UID FETCH"); err != nil {
+		close(ch)
+		if err.Error() == "" {
+			return nil
+		}
+		return err
+	}
+	m.s.mux.Lock()
+	defer m.s.mux.Unlock()
+	return m.Mailbox.ListMessages(uid, seqSet, items, ch)
+}
+
+func (m *testIMAPMailbox) UpdateMessagesFlags(uid bool, seqSet *imap.SeqSet, op imap.FlagsOp, flags []string) error {
+	if err := m.s.failure("UID STORE"); err != nil {
+		return err
+	}
+	m.s.mux.Lock()
+	defer m.s.mux.Unlock()
+	return m.Mailbox.UpdateMessagesFlags(uid, seqSet, op, flags)
+}
+
+func newTestIMAPConfig(s *testIMAPServer) *imapConfig {
+	u, _ := url.Parse(fmt.Sprintf("imap://%s/INBOX", s.addr))
+	return &imapConfig{url: u, username: "user1", password: "pass1", timeout: 5 * time.Second}
+}
+
+func TestPollMailbox(t *testing.T) {
+	gm, tm, signer, done := newTestMailGateway(t)
+	defer done()
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	failing := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	tm.mdi.On("GetMessageByID", mock.Anything, failing.Header.ID).Return(nil, fmt.Errorf("pop"))
+	tm.mdm.On("UploadBLOB", mock.Anything, "ns1", mock.Anything, mock.Anything, true).Return(&fftypes.Data{ID: fftypes.NewUUID()}, nil)
+	tm.mbm.On("BroadcastMessage", mock.Anything, "ns1", mock.Anything, false).Return(&fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}, nil)
+
+	s := newTestIMAPServer(t, map[uint32]string{
+		1: testSignedReply(t, signer, orig, sub, signedContent),
+		2: testReply(orig, sub, "text/plain", "unsigned"),
+		3: testSignedReply(t, signer, failing, sub, signedContent),
+	}, nil)
+	defer s.server.Close()
+	gm.imap = newTestIMAPConfig(s)
+
+	gm.pollMailbox()
+	assert.True(t, s.isSeen(1))  // ingested
+	assert.True(t, s.isSeen(2))  // rejected
+	assert.False(t, s.isSeen(3)) // retried on the next poll
+}
+
+func TestPollMailboxFailures(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	messages := map[uint32]string{1: testReply(orig, sub, "text/plain", "unsigned")}
+
+	for _, failures := range []map[string]string{
+		{"LOGIN": "denied"},
+		{"SELECT": "no such mailbox"},
+		{"UID SEARCH": "unsupported"},
+		{"UID FETCH": "gone"},
+		{"UID FETCH": ""}, // no message returned
+		{"UID STORE": "read only"},
+	} {
+		s := newTestIMAPServer(t, messages, failures)
+		gm.imap = newTestIMAPConfig(s)
+		gm.pollMailbox()
+		assert.False(t, s.isSeen(1))
+		s.server.Close()
+	}
+
+	// Wrong credentials
+	s := newTestIMAPServer(t, messages, nil)
+	defer s.server.Close()
+	gm.imap = newTestIMAPConfig(s)
+	gm.imap.password = "wrong"
+	_, err := dialIMAP(context.Background(), gm.imap)
+	assert.Regexp(t, "FF10493.*LOGIN.*Invalid credentials", err)
+	assert.NotContains(t, err.Error(), "wrong")
+
+	// TLS handshake with a server that does not support it
+	u, _ := url.Parse(fmt.Sprintf("imaps://%s", s.addr))
+	_, err = dialIMAP(context.Background(), &imapConfig{url: u, timeout: 1 * time.Second})
+	assert.Error(t, err)
+
+	// Nothing listening on the default port
+	u, _ = url.Parse("imap://127.0.0.1")
+	_, err = dialIMAP(context.Background(), &imapConfig{url: u, timeout: 1 * time.Second})
+	assert.Error(t, err)
+	u, _ = url.Parse("imaps://127.0.0.1")
+	_, err = dialIMAP(context.Background(), &imapConfig{url: u, timeout: 1 * time.Second})
+	assert.Error(t, err)
+
+	// Connection dropped after the greeting
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	go func() {
+		conn, _ := l.Accept()
+		fmt.Fprintf(conn, "* OK ready\r\n")
+		conn.Close()
+	}()
+	u, _ = url.Parse(fmt.Sprintf("imap://%s", l.Addr()))
+	_, err = dialIMAP(context.Background(), &imapConfig{url: u, timeout: 1 * time.Second})
+	assert.Error(t, err)
+	l.Close()
+}
+
+func TestPollLoop(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	s := newTestIMAPServer(t, map[uint32]string{1: testReply(orig, sub, "text/plain", "unsigned")}, nil)
+	defer s.server.Close()
+
+	config.Set(config.EmailRepliesIMAPURL, fmt.Sprintf("imap://%s", s.addr))
+	config.Set(config.EmailRepliesIMAPUsername, "user1")
+	config.Set(config.EmailRepliesIMAPPassword, "pass1")
+	config.Set(config.EmailRepliesIMAPPollInterval, "1ms")
+	ctx, cancel := context.WithCancel(context.Background())
+	mgr, err := NewMailGateway(ctx, gm.database, gm.data, gm.broadcast, gm.messaging)
+	assert.NoError(t, err)
+	for !s.isSeen(1) {
+		time.Sleep(1 * time.Millisecond)
+	}
+	cancel()
+	mgr.WaitStop()
+}
@@ -0,0 +1,373 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailgateway
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/broadcast"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/events/email"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/smime"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Manager ingests the replies to the emails sent by the email event transport, as messages - either
+// posted to the API, or polled from an IMAP mailbox. The reply is correlated to the original message
+// and subscription from its In-Reply-To header, and is sent to the same group (or broadcast) on the
+// same topics. The sender must be one of the recipients of the subscription, and unless configured
+// otherwise the reply must be S/MIME signed by a certificate for the sender's address. Each attachment
+// becomes a data record - JSON attachments as values, and anything else as a blob.
+type Manager interface {
+	IngestReply(ctx context.Context, ns string, raw io.Reader, waitConfirm bool) (*fftypes.Message, error)
+	WaitStop()
+}
+
+type headers interface {
+	Get(key string) string
+}
+
+type attachment struct {
+	filename  string
+	mediaType string
+	data      []byte
+}
+
+type mailGateway struct {
+	ctx              context.Context
+	database         database.Plugin
+	data             data.Manager
+	broadcast        broadcast.Manager
+	messaging        privatemessaging.Manager
+	replyTag         string
+	requireSignature bool
+	roots            *x509.CertPool
+	imap             *imapConfig
+	done             chan struct{}
+}
+
+func NewMailGateway(ctx context.Context, di database.Plugin, dm data.Manager, bm broadcast.Manager, pm privatemessaging.Manager) (Manager, error) {
+	if di == nil || dm == nil || bm == nil || pm == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	gm := &mailGateway{
+		ctx:              log.WithLogger(ctx, log.L(ctx).WithField("role", "mailgateway")),
+		database:         di,
+		data:             dm,
+		broadcast:        bm,
+		messaging:        pm,
+		replyTag:         config.GetString(config.EmailRepliesTag),
+		requireSignature: config.GetBool(config.EmailRepliesRequireSignature),
+		done:             make(chan struct{}),
+	}
+	if caFile := config.GetString(config.EmailRepliesCAFile); caFile != "" {
+		// Otherwise the system CAs are used
+		b, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, i18n.NewError(ctx, i18n.MsgEmailRepliesCAInvalid, caFile, err)
+		}
+		gm.roots = x509.NewCertPool()
+		if !gm.roots.AppendCertsFromPEM(b) {
+			return nil, i18n.NewError(ctx, i18n.MsgEmailRepliesCAInvalid, caFile, "no certificates found")
+		}
+	}
+	if imapURL := config.GetString(config.EmailRepliesIMAPURL); imapURL != "" {
+		u, err := url.Parse(imapURL)
+		if err != nil || (u.Scheme != "imap" && u.Scheme != "imaps") || u.Hostname() == "" {
+			return nil, i18n.NewError(ctx, i18n.MsgEmailRepliesIMAPInvalid, imapURL)
+		}
+		gm.imap = &imapConfig{
+			url:      u,
+			username: config.GetString(config.EmailRepliesIMAPUsername),
+			password: config.GetString(config.EmailRepliesIMAPPassword),
+			timeout:  config.GetDuration(config.EmailRepliesIMAPTimeout),
+		}
+		log.L(ctx).Infof("Polling IMAP mailbox %s for email replies", u.Redacted())
+		go gm.pollLoop(config.GetDuration(config.EmailRepliesIMAPPollInterval))
+	} else {
+		close(gm.done)
+	}
+	return gm, nil
+}
+
+func (gm *mailGateway) WaitStop() {
+	<-gm.done
+}
+
+func (gm *mailGateway) IngestReply(ctx context.Context, ns string, raw io.Reader, waitConfirm bool) (*fftypes.Message, error) {
+	m, err := mail.ReadMessage(raw)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgEmailReplyInvalid)
+	}
+
+	inReplyTo := m.Header.Get("In-Reply-To")
+	reference, subscription := email.ParseMessageID(inReplyTo)
+	origID, err := fftypes.ParseUUID(ctx, reference)
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgEmailReplyNoReference, inReplyTo)
+	}
+	subID, err := fftypes.ParseUUID(ctx, subscription)
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgEmailReplyNoReference, inReplyTo)
+	}
+	orig, err := gm.database.GetMessageByID(ctx, origID)
+	if err != nil {
+		return nil, err
+	}
+	if ns == "" && orig != nil {
+		// Replies from the mailbox are ingested into the namespace of the original message
+		ns = orig.Header.Namespace
+	}
+	if orig == nil || orig.Header.Namespace != ns {
+		return nil, i18n.NewError(ctx, i18n.MsgEmailReplyMessageNotFound, origID, ns)
+	}
+
+	from, err := gm.checkSender(ctx, ns, subID, m.Header.Get("From"))
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := gm.readReply(ctx, m, from)
+	if err != nil {
+		return nil, err
+	}
+	if len(attachments) == 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgEmailReplyNoAttachments)
+	}
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				CID:    orig.Header.ID,
+				Type:   orig.Header.Type,
+				Group:  orig.Header.Group,
+				Topics: orig.Header.Topics,
+				Tag:    gm.replyTag,
+			},
+		},
+	}
+	if err := gm.uploadAttachments(ctx, ns, attachments, in); err != nil {
+		return nil, err
+	}
+
+	if in.Header.Group != nil {
+		return gm.messaging.SendMessage(ctx, ns, in, waitConfirm)
+	}
+	return gm.broadcast.BroadcastMessage(ctx, ns, in, waitConfirm)
+}
+
+// checkSender checks the reply is from one of the addresses the subscription sent the original email to
+func (gm *mailGateway) checkSender(ctx context.Context, ns string, subID *fftypes.UUID, fromHeader string) (string, error) {
+	from, err := mail.ParseAddress(fromHeader)
+	if err != nil {
+		return "", i18n.WrapError(ctx, err, i18n.MsgEmailReplyInvalid)
+	}
+	sub, err := gm.database.GetSubscriptionByID(ctx, subID)
+	if err != nil {
+		return "", err
+	}
+	if sub != nil && sub.Namespace == ns {
+		for _, to := range strings.Split(sub.Options.TransportOptions().GetString("to"), ",") {
+			if addr, err := mail.ParseAddress(strings.TrimSpace(to)); err == nil && strings.EqualFold(addr.Address, from.Address) {
+				return from.Address, nil
+			}
+		}
+	}
+	return "", i18n.NewError(ctx, i18n.MsgEmailReplySenderInvalid, from.Address)
+}
+
+// readReply verifies the S/MIME signature of the reply, if it is signed, and reads its attachments
+func (gm *mailGateway) readReply(ctx context.Context, m *mail.Message, from string) ([]*attachment, error) {
+	mediaType, params, _ := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	protocol := strings.ToLower(params["protocol"])
+	if mediaType != "multipart/signed" || (protocol != "application/pkcs7-signature" && protocol != "application/x-pkcs7-signature") {
+		if gm.requireSignature {
+			return nil, i18n.NewError(ctx, i18n.MsgEmailReplyNotSigned)
+		}
+		return gm.readAttachments(ctx, m.Header, m.Body, nil)
+	}
+
+	body, err := ioutil.ReadAll(m.Body)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgEmailReplyInvalid)
+	}
+	content, signature, err := smime.ParseSigned(ctx, body, params["boundary"])
+	if err != nil {
+		return nil, err
+	}
+	signer, _, err := smime.Verify(ctx, signature, content, x509.VerifyOptions{
+		Roots:     gm.roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection},
+	})
+	if err != nil {
+		return nil, err
+	}
+	signerMatches := false
+	for _, address := range signer.EmailAddresses {
+		signerMatches = signerMatches || strings.EqualFold(address, from)
+	}
+	if !signerMatches {
+		return nil, i18n.NewError(ctx, i18n.MsgEmailReplySignerMismatch, from, signer.EmailAddresses)
+	}
+
+	// Only the signed content is read, so the signature itself is not ingested
+	r := textproto.NewReader(bufio.NewReader(bytes.NewReader(content)))
+	h, err := r.ReadMIMEHeader()
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgEmailReplyInvalid)
+	}
+	return gm.readAttachments(ctx, h, r.R, nil)
+}
+
+// readAttachments walks the MIME tree of the email, reading and validating each attachment. Nothing is
+// uploaded until every attachment has been read, so a rejected email does not leave behind any data.
+func (gm *mailGateway) readAttachments(ctx context.Context, h headers, body io.Reader, attachments []*attachment) ([]*attachment, error) {
+	mediaType, params, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		mediaType = "text/plain"
+	}
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return attachments, nil
+			}
+			if err != nil {
+				return nil, i18n.WrapError(ctx, err, i18n.MsgEmailReplyInvalid)
+			}
+			if attachments, err = gm.readAttachments(ctx, part.Header, part, attachments); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	disposition, dParams, _ := mime.ParseMediaType(h.Get("Content-Disposition"))
+	if disposition != "attachment" {
+		// The text of the email itself is not ingested
+		return attachments, nil
+	}
+	if strings.EqualFold(h.Get("Content-Transfer-Encoding"), "base64") {
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgEmailReplyInvalid)
+	}
+	if mediaType == "application/json" && !json.Valid(b) {
+		return nil, i18n.NewError(ctx, i18n.MsgEmailReplyAttachmentInvalid, dParams["filename"])
+	}
+	return append(attachments, &attachment{filename: dParams["filename"], mediaType: mediaType, data: b}), nil
+}
+
+// uploadAttachments adds each attachment to the message - JSON attachments as values, and anything else as a blob
+func (gm *mailGateway) uploadAttachments(ctx context.Context, ns string, attachments []*attachment, in *fftypes.MessageInOut) error {
+	for _, a := range attachments {
+		if a.mediaType == "application/json" {
+			in.InlineData = append(in.InlineData, &fftypes.DataRefOrValue{Value: fftypes.JSONAnyPtrBytes(a.data)})
+			continue
+		}
+		d, err := gm.data.UploadBLOB(ctx, ns, &fftypes.DataRefOrValue{}, &fftypes.Multipart{
+			Filename: a.filename,
+			Mimetype: a.mediaType,
+			Data:     bytes.NewReader(a.data),
+		}, true)
+		if err != nil {
+			return err
+		}
+		in.InlineData = append(in.InlineData, &fftypes.DataRefOrValue{DataRef: fftypes.DataRef{ID: d.ID, Hash: d.Hash}})
+	}
+	return nil
+}
+
+func (gm *mailGateway) pollLoop(pollInterval time.Duration) {
+	defer close(gm.done)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-gm.ctx.Done():
+			log.L(gm.ctx).Debugf("Email reply poll loop exiting")
+			return
+		case <-ticker.C:
+		}
+		gm.pollMailbox()
+	}
+}
+
+// pollMailbox ingests each unseen email in the mailbox, into the namespace of the message it replies to.
+// An email is marked as seen once it is ingested, or if it is rejected. Other failures - such as the
+// database being unavailable - leave it unseen, so it is retried on the next poll.
+func (gm *mailGateway) pollMailbox() {
+	c, err := dialIMAP(gm.ctx, gm.imap)
+	if err != nil {
+		log.L(gm.ctx).Errorf("Failed to connect to the IMAP mailbox for email replies: %s", err)
+		return
+	}
+	defer c.logout()
+	uids, err := c.unseen()
+	if err != nil {
+		log.L(gm.ctx).Errorf("Failed to search the IMAP mailbox for email replies: %s", err)
+		return
+	}
+	for _, uid := range uids {
+		raw, err := c.fetch(uid)
+		if err != nil {
+			log.L(gm.ctx).Errorf("Failed to fetch email reply %d: %s", uid, err)
+			return
+		}
+		msg, err := gm.IngestReply(gm.ctx, "", bytes.NewReader(raw), false)
+		switch {
+		case err == nil:
+			log.L(gm.ctx).Infof("Ingested email reply %d as message '%s'", uid, msg.Header.ID)
+		case rejected(err):
+			log.L(gm.ctx).Errorf("Rejected email reply %d: %s", uid, err)
+		default:
+			log.L(gm.ctx).Errorf("Failed to ingest email reply %d: %s", uid, err)
+			continue
+		}
+		if err := c.markSeen(uid); err != nil {
+			log.L(gm.ctx).Errorf("Failed to mark email reply %d as seen: %s", uid, err)
+			return
+		}
+	}
+}
+
+// rejected checks whether an error is a problem with the email itself, which retrying will not resolve
+func rejected(err error) bool {
+	code := strings.SplitN(err.Error(), ":", 2)[0]
+	status, ok := i18n.GetStatusHint(code)
+	return ok && status >= 400 && status < 500
+}
@@ -0,0 +1,484 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mailgateway
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/smime"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testMocks struct {
+	mdi *databasemocks.Plugin
+	mdm *datamocks.Manager
+	mbm *broadcastmocks.Manager
+	mpm *privatemessagingmocks.Manager
+}
+
+type testSigner struct {
+	caFile string
+	cert   *x509.Certificate
+	key    crypto.Signer
+}
+
+// newTestSigner creates a CA, written to a PEM file, and a certificate it issues for the email address
+func newTestSigner(t *testing.T, email string) *testSigner {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(1 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	assert.NoError(t, err)
+	ca, _ := x509.ParseCertificate(caDER)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	certDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber:   big.NewInt(2),
+		Subject:        pkix.Name{CommonName: email},
+		NotBefore:      time.Now().Add(-1 * time.Hour),
+		NotAfter:       time.Now().Add(1 * time.Hour),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection},
+		EmailAddresses: []string{email},
+	}, ca, key.Public(), caKey)
+	assert.NoError(t, err)
+	cert, _ := x509.ParseCertificate(certDER)
+
+	f, err := ioutil.TempFile("", "ca")
+	assert.NoError(t, err)
+	_ = pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	f.Close()
+	return &testSigner{caFile: f.Name(), cert: cert, key: key}
+}
+
+func newTestMailGateway(t *testing.T) (*mailGateway, *testMocks, *testSigner, func()) {
+	config.Reset()
+	signer := newTestSigner(t, "partner@example.com")
+	config.Set(config.EmailRepliesCAFile, signer.caFile)
+	tm := &testMocks{
+		mdi: &databasemocks.Plugin{},
+		mdm: &datamocks.Manager{},
+		mbm: &broadcastmocks.Manager{},
+		mpm: &privatemessagingmocks.Manager{},
+	}
+	gm, err := NewMailGateway(context.Background(), tm.mdi, tm.mdm, tm.mbm, tm.mpm)
+	assert.NoError(t, err)
+	return gm.(*mailGateway), tm, signer, func() {
+		os.Remove(signer.caFile)
+		tm.mdi.AssertExpectations(t)
+		tm.mdm.AssertExpectations(t)
+		tm.mbm.AssertExpectations(t)
+		tm.mpm.AssertExpectations(t)
+	}
+}
+
+// testOriginal mocks the original message, and the subscription that emailed it
+func testOriginal(tm *testMocks, msgType fftypes.MessageType) (*fftypes.Message, *fftypes.Subscription) {
+	orig := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			Namespace: "ns1",
+			Type:      msgType,
+			Topics:    fftypes.FFStringArray{"orders"},
+		},
+	}
+	if msgType == fftypes.MessageTypePrivate {
+		orig.Header.Group = fftypes.NewRandB32()
+	}
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Namespace: "ns1"},
+	}
+	sub.Options.TransportOptions()["to"] = "Partner <PARTNER@example.com>, other@example.com"
+	tm.mdi.On("GetMessageByID", mock.Anything, orig.Header.ID).Return(orig, nil)
+	tm.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil).Maybe()
+	return orig, sub
+}
+
+func testReply(orig *fftypes.Message, sub *fftypes.Subscription, contentType, body string) string {
+	return strings.ReplaceAll(fmt.Sprintf(`From: Partner <partner@example.com>
+To: firefly@example.com
+Subject: Re: FireFly message_confirmed event
+In-Reply-To: <%s.%s.%s@firefly.local>
+MIME-Version: 1.0
+Content-Type: %s
+
+%s`, orig.Header.ID, fftypes.NewUUID(), sub.ID, contentType, body), "\n", "\r\n")
+}
+
+// testSignedReply signs the content, which is a MIME entity with headers, as a multipart/signed reply
+func testSignedReply(t *testing.T, signer *testSigner, orig *fftypes.Message, sub *fftypes.Subscription, content string) string {
+	sig, err := smime.Sign(context.Background(), []byte(strings.ReplaceAll(content, "\n", "\r\n")), signer.cert, signer.key)
+	assert.NoError(t, err)
+	body := "This is an S/MIME signed message\n\n" +
+		"--outer\n" + content + "\n--outer\n" +
+		"Content-Type: application/pkcs7-signature; name=\"smime.p7s\"\n" +
+		"Content-Transfer-Encoding: base64\n" +
+		"Content-Disposition: attachment; filename=\"smime.p7s\"\n\n" +
+		base64.StdEncoding.EncodeToString(sig) + "\n" +
+		"--outer--\n"
+	return testReply(orig, sub, `multipart/signed; protocol="application/pkcs7-signature"; micalg=sha-256; boundary="outer"`, body)
+}
+
+const signedContent = `Content-Type: multipart/mixed; boundary="inner"
+
+--inner
+Content-Type: text/plain
+
+Please find the order acknowledgement attached
+--inner
+Content-Type: application/json
+Content-Disposition: attachment; filename="ack.json"
+
+{"accepted":true}
+--inner
+Content-Type: application/pdf
+Content-Disposition: attachment; filename="ack.pdf"
+Content-Transfer-Encoding: base64
+
+cGRmIGRhdGE=
+--inner--
+`
+
+func TestNewMailGatewayMissingDeps(t *testing.T) {
+	_, err := NewMailGateway(context.Background(), nil, nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestNewMailGatewayBadConfig(t *testing.T) {
+	notPEM, err := ioutil.TempFile("", "ca")
+	assert.NoError(t, err)
+	notPEM.Close()
+	defer os.Remove(notPEM.Name())
+
+	for _, test := range []struct {
+		key   config.RootKey
+		value string
+		err   string
+	}{
+		{config.EmailRepliesCAFile, "/does/not/exist", "FF10491"},
+		{config.EmailRepliesCAFile, notPEM.Name(), "FF10491.*no certificates"},
+		{config.EmailRepliesIMAPURL, "https://mail.example.com", "FF10492"},
+		{config.EmailRepliesIMAPURL, "imaps:///INBOX", "FF10492"},
+		{config.EmailRepliesIMAPURL, ":::", "FF10492"},
+	} {
+		config.Reset()
+		config.Set(test.key, test.value)
+		_, err := NewMailGateway(context.Background(), &databasemocks.Plugin{}, &datamocks.Manager{}, &broadcastmocks.Manager{}, &privatemessagingmocks.Manager{})
+		assert.Regexp(t, test.err, err)
+	}
+}
+
+func TestIngestSignedReplyPrivate(t *testing.T) {
+	gm, tm, signer, done := newTestMailGateway(t)
+	defer done()
+	gm.WaitStop()
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypePrivate)
+	pdf := &fftypes.Data{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()}
+	tm.mdm.On("UploadBLOB", mock.Anything, "ns1", mock.Anything, mock.MatchedBy(func(mp *fftypes.Multipart) bool {
+		b, _ := ioutil.ReadAll(mp.Data)
+		return mp.Filename == "ack.pdf" && mp.Mimetype == "application/pdf" && string(b) == "pdf data"
+	}), true).Return(pdf, nil).Once()
+	msg := &fftypes.Message{}
+	tm.mpm.On("SendMessage", mock.Anything, "ns1", mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		return in.Header.CID.Equals(orig.Header.ID) &&
+			in.Header.Group.Equals(orig.Header.Group) &&
+			in.Header.Topics.String() == "orders" &&
+			in.Header.Tag == "email_reply" &&
+			len(in.InlineData) == 2 &&
+			in.InlineData[0].Value.String() == `{"accepted":true}` &&
+			in.InlineData[1].ID.Equals(pdf.ID)
+	}), true).Return(msg, nil)
+
+	raw := testSignedReply(t, signer, orig, sub, signedContent)
+	out, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), true)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, out)
+}
+
+func TestIngestUnsignedReplyBroadcast(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+	gm.requireSignature = false
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	msg := &fftypes.Message{}
+	tm.mbm.On("BroadcastMessage", mock.Anything, "ns1", mock.AnythingOfType("*fftypes.MessageInOut"), false).Return(msg, nil)
+
+	raw := testReply(orig, sub, "application/json", "")
+	raw = strings.Replace(raw, "Content-Type:", "Content-Disposition: attachment\r\nContent-Type:", 1) + `{"accepted":false}`
+	out, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, out)
+}
+
+func TestIngestReplyNotSigned(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	raw := testReply(orig, sub, `multipart/mixed; boundary="inner"`, strings.SplitN(signedContent, "\n\n", 2)[1])
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10488", err)
+}
+
+func TestIngestReplySignerMismatch(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+
+	// Issued by the trusted CA, but for a different address
+	other := newTestSigner(t, "other@example.com")
+	defer os.Remove(other.caFile)
+	gm.roots.AppendCertsFromPEM(func() []byte { b, _ := ioutil.ReadFile(other.caFile); return b }())
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	raw := testSignedReply(t, other, orig, sub, signedContent)
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10490.*partner@example.com.*other@example.com", err)
+}
+
+func TestIngestReplyUntrustedSigner(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+
+	untrusted := newTestSigner(t, "partner@example.com")
+	defer os.Remove(untrusted.caFile)
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	raw := testSignedReply(t, untrusted, orig, sub, signedContent)
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10487", err)
+}
+
+func TestIngestReplyTamperedContent(t *testing.T) {
+	gm, tm, signer, done := newTestMailGateway(t)
+	defer done()
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	raw := testSignedReply(t, signer, orig, sub, signedContent)
+	raw = strings.Replace(raw, `{"accepted":true}`, `{"accepted":false}`, 1)
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10487.*digest mismatch", err)
+}
+
+func TestIngestReplyBadSignedEntity(t *testing.T) {
+	gm, tm, signer, done := newTestMailGateway(t)
+	defer done()
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	raw := testReply(orig, sub, `multipart/signed; protocol="application/pkcs7-signature"; boundary="outer"`, "no parts")
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10486", err)
+
+	raw = testSignedReply(t, signer, orig, sub, "bad header line\n\nbody")
+	_, err = gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10486", err)
+}
+
+func TestIngestReplySenderNotRecipient(t *testing.T) {
+	gm, tm, signer, done := newTestMailGateway(t)
+	defer done()
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	raw := strings.Replace(testSignedReply(t, signer, orig, sub, signedContent), "From: Partner <partner@example.com>", "From: attacker@example.com", 1)
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10489.*attacker@example.com", err)
+
+	// Subscription in another namespace
+	sub.Namespace = "ns2"
+	_, err = gm.IngestReply(context.Background(), "ns1", strings.NewReader(testSignedReply(t, signer, orig, sub, signedContent)), false)
+	assert.Regexp(t, "FF10489", err)
+
+	// Subscription deleted
+	deleted := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+	tm.mdi.On("GetSubscriptionByID", mock.Anything, deleted.ID).Return(nil, nil)
+	_, err = gm.IngestReply(context.Background(), "ns1", strings.NewReader(testSignedReply(t, signer, orig, deleted, signedContent)), false)
+	assert.Regexp(t, "FF10489", err)
+}
+
+func TestIngestReplyBadFrom(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	raw := strings.Replace(testReply(orig, sub, "text/plain", "hello"), "From: Partner <partner@example.com>", "From: not an address", 1)
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10468", err)
+}
+
+func TestIngestReplySubscriptionLookupFail(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+
+	orig, _ := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	sub := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+	tm.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(nil, fmt.Errorf("pop"))
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(testReply(orig, sub, "text/plain", "hello")), false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestIngestReplyInvalidEmail(t *testing.T) {
+	gm, _, _, done := newTestMailGateway(t)
+	defer done()
+
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader("not an email"), false)
+	assert.Regexp(t, "FF10468", err)
+}
+
+func TestIngestReplyNoReference(t *testing.T) {
+	gm, _, _, done := newTestMailGateway(t)
+	defer done()
+
+	orig := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	sub := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+	raw := strings.Replace(testReply(orig, sub, "text/plain", "hello"), "In-Reply-To", "X-Other", 1)
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10469", err)
+
+	// Without the subscription
+	raw = strings.Replace(testReply(orig, sub, "text/plain", "hello"), "."+sub.ID.String(), "", 1)
+	_, err = gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10469", err)
+}
+
+func TestIngestReplyLookupFail(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+
+	tm.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	orig := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	sub := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID()}}
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(testReply(orig, sub, "text/plain", "hello")), false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestIngestReplyWrongNamespace(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	_, err := gm.IngestReply(context.Background(), "ns2", strings.NewReader(testReply(orig, sub, "text/plain", "hello")), false)
+	assert.Regexp(t, "FF10470", err)
+}
+
+func TestIngestReplyNoAttachments(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+	gm.requireSignature = false
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(testReply(orig, sub, "bad;;", "hello")), false)
+	assert.Regexp(t, "FF10471", err)
+}
+
+func TestIngestReplyBadMultipart(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+	gm.requireSignature = false
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	raw := testReply(orig, sub, `multipart/mixed; boundary="b"`, "--b\r\nbad header\r\n\r\n")
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10468", err)
+}
+
+func TestIngestReplyBadBase64Attachment(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+	gm.requireSignature = false
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	raw := testReply(orig, sub, "application/pdf", "")
+	raw = strings.Replace(raw, "Content-Type:", "Content-Disposition: attachment\r\nContent-Transfer-Encoding: base64\r\nContent-Type:", 1) + "!!!"
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10468", err)
+}
+
+func TestIngestReplyBadJSONAttachmentUploadsNothing(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+	gm.requireSignature = false
+
+	// The PDF comes before the invalid JSON, but is not uploaded - as UploadBLOB is not mocked
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	raw := testReply(orig, sub, `multipart/mixed; boundary="b"`, `--b
+Content-Type: application/pdf
+Content-Disposition: attachment; filename="ack.pdf"
+
+pdf data
+--b
+Content-Type: application/json
+Content-Disposition: attachment; filename="bad.json"
+
+{!json
+--b--
+`)
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.Regexp(t, "FF10472.*bad.json", err)
+}
+
+func TestIngestReplyUploadFail(t *testing.T) {
+	gm, tm, _, done := newTestMailGateway(t)
+	defer done()
+	gm.requireSignature = false
+
+	orig, sub := testOriginal(tm, fftypes.MessageTypeBroadcast)
+	tm.mdm.On("UploadBLOB", mock.Anything, "ns1", mock.Anything, mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+	raw := testReply(orig, sub, `multipart/mixed; boundary="b"`, `--b
+Content-Type: application/pdf
+Content-Disposition: attachment
+
+pdf data
+--b--
+`)
+	_, err := gm.IngestReply(context.Background(), "ns1", strings.NewReader(raw), false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRejected(t *testing.T) {
+	assert.True(t, rejected(fmt.Errorf("FF10468: Invalid email")))
+	assert.False(t, rejected(fmt.Errorf("FF10493: IMAP command 'LOGIN' failed")))
+	assert.False(t, rejected(fmt.Errorf("pop")))
+}
@@ -0,0 +1,46 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var CanaryCounter *prometheus.CounterVec
+var CanaryHistogram *prometheus.HistogramVec
+
+// CanaryCounterName is the prometheus metric for tracking the total number of canary checks, labelled by type and result
+var CanaryCounterName = "ff_canary_total"
+
+// CanaryHistogramName is the prometheus metric for tracking the time for canary messages to be confirmed - histogram
+var CanaryHistogramName = "ff_canary_histogram"
+
+func InitCanaryMetrics() {
+	CanaryCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: CanaryCounterName,
+		Help: "Number of canary checks, by message type and result",
+	}, []string{"type", "result"})
+	CanaryHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: CanaryHistogramName,
+		Help: "Histogram of canary checks, bucketed by time to finished",
+	}, []string{"type"})
+}
+
+func RegisterCanaryMetrics() {
+	registry.MustRegister(CanaryCounter)
+	registry.MustRegister(CanaryHistogram)
+}
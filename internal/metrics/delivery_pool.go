@@ -0,0 +1,84 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var DeliveryPoolSizeGauge prometheus.Gauge
+var DeliveryPoolQueueGauge prometheus.Gauge
+var DeliveryPoolBusyGauge prometheus.Gauge
+
+// DeliveryPoolSizeGaugeName is the prometheus metric for tracking the number of workers in the event delivery pool
+var DeliveryPoolSizeGaugeName = "ff_event_delivery_pool_size"
+
+// DeliveryPoolQueueGaugeName is the prometheus metric for tracking the number of subscriptions waiting for a delivery worker
+var DeliveryPoolQueueGaugeName = "ff_event_delivery_pool_queue_length"
+
+// DeliveryPoolBusyGaugeName is the prometheus metric for tracking the number of delivery workers currently delivering events
+var DeliveryPoolBusyGaugeName = "ff_event_delivery_pool_busy_workers"
+
+func InitDeliveryPoolMetrics() {
+	DeliveryPoolSizeGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: DeliveryPoolSizeGaugeName,
+		Help: "Number of workers in the shared event delivery pool",
+	})
+	DeliveryPoolQueueGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: DeliveryPoolQueueGaugeName,
+		Help: "Number of subscriptions with events waiting for a delivery worker",
+	})
+	DeliveryPoolBusyGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: DeliveryPoolBusyGaugeName,
+		Help: "Number of delivery workers currently delivering events",
+	})
+}
+
+func RegisterDeliveryPoolMetrics() {
+	registry.MustRegister(DeliveryPoolSizeGauge)
+	registry.MustRegister(DeliveryPoolQueueGauge)
+	registry.MustRegister(DeliveryPoolBusyGauge)
+}
+
+// DeliveryPoolStarted records the number of workers in the delivery pool. As with the retry metrics, the
+// gauges are only updated once the metrics registry has been initialized.
+func DeliveryPoolStarted(size int) {
+	if DeliveryPoolSizeGauge != nil {
+		DeliveryPoolSizeGauge.Set(float64(size))
+	}
+}
+
+// DeliveryPoolQueueLength records the number of subscriptions waiting for a delivery worker
+func DeliveryPoolQueueLength(length int) {
+	if DeliveryPoolQueueGauge != nil {
+		DeliveryPoolQueueGauge.Set(float64(length))
+	}
+}
+
+// DeliveryWorkerBusy records a delivery worker picking up a subscription
+func DeliveryWorkerBusy() {
+	if DeliveryPoolBusyGauge != nil {
+		DeliveryPoolBusyGauge.Inc()
+	}
+}
+
+// DeliveryWorkerIdle records a delivery worker finishing with a subscription
+func DeliveryWorkerIdle() {
+	if DeliveryPoolBusyGauge != nil {
+		DeliveryPoolBusyGauge.Dec()
+	}
+}
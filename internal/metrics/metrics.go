@@ -33,6 +33,7 @@ type Manager interface {
 	MessageConfirmed(msg *fftypes.Message, eventType fftypes.FFEnum)
 	TransferSubmitted(transfer *fftypes.TokenTransfer)
 	TransferConfirmed(transfer *fftypes.TokenTransfer)
+	CanaryResult(msgType fftypes.MessageType, passed bool, elapsed time.Duration)
 	AddTime(id string)
 	GetTime(id string) time.Time
 	DeleteTime(id string)
@@ -129,6 +130,15 @@ func (mm *metricsManager) TransferConfirmed(transfer *fftypes.TokenTransfer) {
 	}
 }
 
+func (mm *metricsManager) CanaryResult(msgType fftypes.MessageType, passed bool, elapsed time.Duration) {
+	result := "passed"
+	if !passed {
+		result = "failed"
+	}
+	CanaryCounter.WithLabelValues(msgType.String(), result).Inc()
+	CanaryHistogram.WithLabelValues(msgType.String()).Observe(elapsed.Seconds())
+}
+
 func (mm *metricsManager) AddTime(id string) {
 	mutex.Lock()
 	mm.timeMap[id] = time.Now()
@@ -187,3 +187,15 @@ func TestIsMetricsEnabledFalse(t *testing.T) {
 	mm.metricsEnabled = false
 	assert.Equal(t, mm.IsMetricsEnabled(), false)
 }
+
+func TestCanaryResultPassed(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.CanaryResult(fftypes.MessageTypeBroadcast, true, 2*time.Second)
+}
+
+func TestCanaryResultFailed(t *testing.T) {
+	mm, cancel := newTestMetricsManager(t)
+	defer cancel()
+	mm.CanaryResult(fftypes.MessageTypePrivate, false, time.Minute)
+}
@@ -80,6 +80,9 @@ func initMetricsCollectors() {
 	InitTokenTransferMetrics()
 	InitTokenBurnMetrics()
 	InitBatchPinMetrics()
+	InitCanaryMetrics()
+	InitRetryMetrics()
+	InitDeliveryPoolMetrics()
 }
 
 func registerMetricsCollectors() {
@@ -92,4 +95,7 @@ func registerMetricsCollectors() {
 	RegisterTokenMintMetrics()
 	RegisterTokenTransferMetrics()
 	RegisterTokenBurnMetrics()
+	RegisterCanaryMetrics()
+	RegisterRetryMetrics()
+	RegisterDeliveryPoolMetrics()
 }
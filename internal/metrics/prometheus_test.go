@@ -29,3 +29,31 @@ func TestPrometheusMiddleware(t *testing.T) {
 	assert.NotNil(t, GetAdminServerInstrumentation())
 	assert.NotNil(t, GetRestServerInstrumentation())
 }
+
+func TestRetryMetrics(t *testing.T) {
+	Clear()
+	RetryCounter = nil
+	RetryAttempted("ut")
+	Registry()
+	RetryAttempted("ut")
+	RetryExhausted("ut")
+	BreakerOpened("ut")
+	assert.NotNil(t, RetryCounter)
+}
+
+func TestDeliveryPoolMetrics(t *testing.T) {
+	Clear()
+	DeliveryPoolSizeGauge = nil
+	DeliveryPoolQueueGauge = nil
+	DeliveryPoolBusyGauge = nil
+	DeliveryPoolStarted(5)
+	DeliveryPoolQueueLength(1)
+	DeliveryWorkerBusy()
+	DeliveryWorkerIdle()
+	Registry()
+	DeliveryPoolStarted(5)
+	DeliveryPoolQueueLength(1)
+	DeliveryWorkerBusy()
+	DeliveryWorkerIdle()
+	assert.NotNil(t, DeliveryPoolBusyGauge)
+}
@@ -0,0 +1,77 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var RetryCounter *prometheus.CounterVec
+var RetryExhaustedCounter *prometheus.CounterVec
+var BreakerOpenedCounter *prometheus.CounterVec
+
+// RetryCounterName is the prometheus metric for tracking the total number of retries, labelled by the operation retried
+var RetryCounterName = "ff_retry_total"
+
+// RetryExhaustedCounterName is the prometheus metric for tracking the number of retry loops that exhausted their budget
+var RetryExhaustedCounterName = "ff_retry_exhausted_total"
+
+// BreakerOpenedCounterName is the prometheus metric for tracking the number of times a circuit breaker opened
+var BreakerOpenedCounterName = "ff_retry_breaker_opened_total"
+
+func InitRetryMetrics() {
+	RetryCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: RetryCounterName,
+		Help: "Number of retries, by operation",
+	}, []string{"operation"})
+	RetryExhaustedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: RetryExhaustedCounterName,
+		Help: "Number of retry loops that exhausted their retry budget, by operation",
+	}, []string{"operation"})
+	BreakerOpenedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: BreakerOpenedCounterName,
+		Help: "Number of times a circuit breaker opened, by breaker",
+	}, []string{"breaker"})
+}
+
+func RegisterRetryMetrics() {
+	registry.MustRegister(RetryCounter)
+	registry.MustRegister(RetryExhaustedCounter)
+	registry.MustRegister(BreakerOpenedCounter)
+}
+
+// RetryAttempted counts a retry of an operation. The retry loops are not bound to the metrics manager,
+// so the counters are only updated once the metrics registry has been initialized.
+func RetryAttempted(operation string) {
+	if RetryCounter != nil {
+		RetryCounter.WithLabelValues(operation).Inc()
+	}
+}
+
+// RetryExhausted counts a retry loop that gave up on an operation, as its budget was exhausted
+func RetryExhausted(operation string) {
+	if RetryExhaustedCounter != nil {
+		RetryExhaustedCounter.WithLabelValues(operation).Inc()
+	}
+}
+
+// BreakerOpened counts a circuit breaker opening
+func BreakerOpened(breaker string) {
+	if BreakerOpenedCounter != nil {
+		BreakerOpenedCounter.WithLabelValues(breaker).Inc()
+	}
+}
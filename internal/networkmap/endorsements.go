@@ -0,0 +1,70 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmap
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// EndorseOrganization broadcasts an endorsement of another member org, signed by the org that owns this node.
+// Once an org has been endorsed by the configured quorum of existing members, its nodes can send messages.
+func (nm *networkMap) EndorseOrganization(ctx context.Context, nameOrID string, waitConfirm bool) (*fftypes.IdentityEndorsement, error) {
+	org, err := nm.GetOrganizationByNameOrID(ctx, nameOrID)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+
+	localOrg, err := nm.identity.GetNodeOwnerOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if localOrg.ID.Equals(org.ID) {
+		return nil, i18n.NewError(ctx, i18n.MsgCannotEndorseSelf)
+	}
+
+	endorsement := &fftypes.IdentityEndorsement{
+		ID:       fftypes.NewUUID(),
+		Identity: org.ID,
+		DID:      org.DID,
+	}
+	msg, err := nm.broadcast.BroadcastDefinitionAsNode(ctx, fftypes.SystemNamespace, endorsement, fftypes.SystemTagIdentityEndorsement, waitConfirm)
+	if err != nil {
+		return nil, err
+	}
+	endorsement.Author = localOrg.DID
+	endorsement.Message = msg.Header.ID
+	return endorsement, nil
+}
+
+func (nm *networkMap) GetOrganizationEndorsements(ctx context.Context, nameOrID string, filter database.AndFilter) ([]*fftypes.IdentityEndorsement, *database.FilterResult, error) {
+	org, err := nm.GetOrganizationByNameOrID(ctx, nameOrID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if org == nil {
+		return nil, nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	filter.Condition(filter.Builder().Eq("identity", org.ID))
+	return nm.database.GetIdentityEndorsements(ctx, filter)
+}
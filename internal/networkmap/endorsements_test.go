@@ -0,0 +1,188 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmap
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEndorseOrganizationOk(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	org := testOrg("org2")
+	localOrg := testOrg("org1")
+	msgID := fftypes.NewUUID()
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeOrg, fftypes.SystemNamespace, "org2").Return(org, nil)
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(localOrg, nil)
+	mbm := nm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastDefinitionAsNode", nm.ctx, fftypes.SystemNamespace, mock.MatchedBy(func(e *fftypes.IdentityEndorsement) bool {
+		return e.Identity.Equals(org.ID) && e.DID == org.DID
+	}), fftypes.SystemTagIdentityEndorsement, true).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID},
+	}, nil)
+
+	endorsement, err := nm.EndorseOrganization(nm.ctx, "org2", true)
+	assert.NoError(t, err)
+	assert.Equal(t, localOrg.DID, endorsement.Author)
+	assert.Equal(t, msgID, endorsement.Message)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+}
+
+func TestEndorseOrganizationBroadcastFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	org := testOrg("org2")
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeOrg, fftypes.SystemNamespace, "org2").Return(org, nil)
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(testOrg("org1"), nil)
+	mbm := nm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastDefinitionAsNode", nm.ctx, fftypes.SystemNamespace, mock.Anything, fftypes.SystemTagIdentityEndorsement, false).Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.EndorseOrganization(nm.ctx, "org2", false)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+}
+
+func TestEndorseOrganizationSelf(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	org := testOrg("org1")
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeOrg, fftypes.SystemNamespace, "org1").Return(org, nil)
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(org, nil)
+
+	_, err := nm.EndorseOrganization(nm.ctx, "org1", false)
+	assert.Regexp(t, "FF10447", err)
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestEndorseOrganizationNoLocalOrg(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeOrg, fftypes.SystemNamespace, "org2").Return(testOrg("org2"), nil)
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.EndorseOrganization(nm.ctx, "org2", false)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestEndorseOrganizationNotOrg(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	node := testOrg("node1")
+	node.Type = fftypes.IdentityTypeNode
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", nm.ctx, node.ID).Return(node, nil)
+
+	_, err := nm.EndorseOrganization(nm.ctx, node.ID.String(), false)
+	assert.Regexp(t, "FF10109", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEndorseOrganizationLookupFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeOrg, fftypes.SystemNamespace, "org2").Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.EndorseOrganization(nm.ctx, "org2", false)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetOrganizationEndorsementsOk(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	org := testOrg("org2")
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeOrg, fftypes.SystemNamespace, "org2").Return(org, nil)
+	mdi.On("GetIdentityEndorsements", nm.ctx, mock.Anything).Return([]*fftypes.IdentityEndorsement{}, nil, nil)
+
+	fb := database.IdentityEndorsementQueryFactory.NewFilter(nm.ctx)
+	_, _, err := nm.GetOrganizationEndorsements(nm.ctx, "org2", fb.And())
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetOrganizationEndorsementsNotOrg(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	node := testOrg("node1")
+	node.Type = fftypes.IdentityTypeNode
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", nm.ctx, node.ID).Return(node, nil)
+
+	fb := database.IdentityEndorsementQueryFactory.NewFilter(nm.ctx)
+	_, _, err := nm.GetOrganizationEndorsements(nm.ctx, node.ID.String(), fb.And())
+	assert.Regexp(t, "FF10109", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetOrganizationEndorsementsLookupFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeOrg, fftypes.SystemNamespace, "org2").Return(nil, fmt.Errorf("pop"))
+
+	fb := database.IdentityEndorsementQueryFactory.NewFilter(nm.ctx)
+	_, _, err := nm.GetOrganizationEndorsements(nm.ctx, "org2", fb.And())
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
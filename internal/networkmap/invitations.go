@@ -0,0 +1,137 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmap
+
+import (
+	"context"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// CreateInvitation issues an invitation for a new member to join the network, containing the bootstrap
+// information of the org and node of this member. The invitation is stored locally, and marked redeemed
+// when the org registered by the new member is confirmed.
+func (nm *networkMap) CreateInvitation(ctx context.Context, input *fftypes.InvitationInput) (*fftypes.Invitation, error) {
+	if err := fftypes.ValidateLength(ctx, input.Description, "description", 4096); err != nil {
+		return nil, err
+	}
+
+	org, err := nm.identity.GetNodeOwnerOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+	node, err := nm.database.GetIdentityByName(ctx, fftypes.IdentityTypeNode, fftypes.SystemNamespace, nm.getLocalNodeName(org))
+	if err != nil {
+		return nil, err
+	}
+	peer, err := nm.exchange.GetEndpointInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expiry := config.GetDuration(config.OrgInvitationExpiry)
+	if input.Expiry != nil && *input.Expiry > 0 {
+		expiry = time.Duration(*input.Expiry)
+	}
+	expires := fftypes.FFTime(time.Now().Add(expiry))
+	invitation := &fftypes.Invitation{
+		ID:          fftypes.NewUUID(),
+		Description: input.Description,
+		Inviter:     org.DID,
+		Peer:        peer,
+		State:       fftypes.InvitationStatePending,
+		Created:     fftypes.Now(),
+		Expires:     &expires,
+		Secret:      fftypes.NewRandB32(),
+	}
+	if node != nil {
+		invitation.Node = node.ID
+	}
+	if err = nm.database.InsertInvitation(ctx, invitation); err != nil {
+		return nil, err
+	}
+	invitation.Code = invitation.CodeInfo().Encode()
+	return invitation, nil
+}
+
+// RedeemInvitation onboards this node onto the network with an invitation from an existing member.
+// The data exchange is connected to the node of that member, and the org and node configured on this
+// node are registered. The org must be confirmed before the node can be registered, so registration of
+// the org is always synchronous.
+func (nm *networkMap) RedeemInvitation(ctx context.Context, input *fftypes.InvitationRedeemInput, waitConfirm bool) (*fftypes.InvitationRedemption, error) {
+	ic, err := fftypes.ParseInvitationCode(ctx, input.Code)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(*ic.Expires.Time()) {
+		return nil, i18n.NewError(ctx, i18n.MsgInvitationExpired, ic.ID, ic.Expires)
+	}
+
+	if ic.Peer != nil {
+		if err = nm.exchange.AddPeer(ctx, ic.Peer); err != nil {
+			return nil, err
+		}
+	}
+
+	redemption := &fftypes.InvitationRedemption{Invitation: ic}
+	if redemption.Organization, err = nm.identity.GetNodeOwnerOrg(ctx); err != nil {
+		orgRequest, err := nm.getNodeOrganizationRequest(ctx)
+		if err != nil {
+			return nil, err
+		}
+		orgRequest.Profile = fftypes.JSONObject{
+			fftypes.IdentityProfileInvitation: ic.Reference(),
+		}
+		if redemption.Organization, err = nm.RegisterOrganization(ctx, orgRequest, true); err != nil {
+			return nil, err
+		}
+	} else {
+		log.L(ctx).Infof("Organization '%s' is already registered - registering node only", redemption.Organization.DID)
+	}
+
+	if redemption.Node, err = nm.RegisterNode(ctx, waitConfirm); err != nil {
+		return nil, err
+	}
+	return redemption, nil
+}
+
+func (nm *networkMap) GetInvitations(ctx context.Context, filter database.AndFilter) ([]*fftypes.Invitation, *database.FilterResult, error) {
+	return nm.database.GetInvitations(ctx, filter)
+}
+
+func (nm *networkMap) GetInvitationByID(ctx context.Context, id string) (*fftypes.Invitation, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	invitation, err := nm.database.GetInvitationByID(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInvitationNotFound, id)
+	}
+	if invitation.State == fftypes.InvitationStatePending {
+		invitation.Code = invitation.CodeInfo().Encode()
+	}
+	return invitation, nil
+}
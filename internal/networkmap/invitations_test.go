@@ -0,0 +1,405 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package networkmap
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/syncasync"
+	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/syncasyncmocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testInvitationCode(expires time.Time) *fftypes.InvitationCode {
+	ft := fftypes.FFTime(expires)
+	inv := &fftypes.Invitation{
+		ID:      fftypes.NewUUID(),
+		Inviter: "did:firefly:org/org1",
+		Node:    fftypes.NewUUID(),
+		Peer:    fftypes.JSONObject{"id": "peer1"},
+		Expires: &ft,
+		Secret:  fftypes.NewRandB32(),
+	}
+	return inv.CodeInfo()
+}
+
+func TestCreateInvitationOk(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	config.Set(config.OrgInvitationExpiry, "1h")
+	org := testOrg("org1")
+	node := testOrg("org1.node")
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(org, nil)
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeNode, fftypes.SystemNamespace, "org1.node").Return(node, nil)
+	mdi.On("InsertInvitation", nm.ctx, mock.MatchedBy(func(inv *fftypes.Invitation) bool {
+		return inv.Inviter == org.DID && inv.Node.Equals(node.ID) &&
+			inv.State == fftypes.InvitationStatePending && inv.Secret != nil &&
+			inv.Expires.Time().After(time.Now().Add(50*time.Minute))
+	})).Return(nil)
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("GetEndpointInfo", nm.ctx).Return(fftypes.JSONObject{"id": "peer1"}, nil)
+
+	inv, err := nm.CreateInvitation(nm.ctx, &fftypes.InvitationInput{Description: "org2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "org2", inv.Description)
+
+	ic, err := fftypes.ParseInvitationCode(nm.ctx, inv.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, inv.ID, ic.ID)
+	assert.Equal(t, "peer1", ic.Peer.GetString("id"))
+	assert.True(t, inv.VerifySignature(ic.Signature))
+
+	mim.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func TestCreateInvitationCustomExpiryNoNode(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	config.Set(config.NodeName, "node1")
+	org := testOrg("org1")
+	expiry := fftypes.FFDuration(5 * time.Minute)
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(org, nil)
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeNode, fftypes.SystemNamespace, "node1").Return(nil, nil)
+	mdi.On("InsertInvitation", nm.ctx, mock.MatchedBy(func(inv *fftypes.Invitation) bool {
+		return inv.Node == nil && inv.Expires.Time().Before(time.Now().Add(10*time.Minute))
+	})).Return(nil)
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("GetEndpointInfo", nm.ctx).Return(fftypes.JSONObject{"id": "peer1"}, nil)
+
+	_, err := nm.CreateInvitation(nm.ctx, &fftypes.InvitationInput{Expiry: &expiry})
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCreateInvitationBadDescription(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	_, err := nm.CreateInvitation(nm.ctx, &fftypes.InvitationInput{Description: string(make([]byte, 4097))})
+	assert.Regexp(t, "FF10188", err)
+}
+
+func TestCreateInvitationOrgFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.CreateInvitation(nm.ctx, &fftypes.InvitationInput{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCreateInvitationNodeFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(testOrg("org1"), nil)
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeNode, fftypes.SystemNamespace, "org1.node").Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.CreateInvitation(nm.ctx, &fftypes.InvitationInput{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCreateInvitationEndpointFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(testOrg("org1"), nil)
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeNode, fftypes.SystemNamespace, "org1.node").Return(nil, nil)
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("GetEndpointInfo", nm.ctx).Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.CreateInvitation(nm.ctx, &fftypes.InvitationInput{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCreateInvitationInsertFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(testOrg("org1"), nil)
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByName", nm.ctx, fftypes.IdentityTypeNode, fftypes.SystemNamespace, "org1.node").Return(nil, nil)
+	mdi.On("InsertInvitation", nm.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("GetEndpointInfo", nm.ctx).Return(fftypes.JSONObject{}, nil)
+
+	_, err := nm.CreateInvitation(nm.ctx, &fftypes.InvitationInput{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRedeemInvitationOk(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	config.Set(config.OrgName, "org2")
+	ic := testInvitationCode(time.Now().Add(time.Hour))
+	org := testOrg("org2")
+
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("AddPeer", nm.ctx, ic.Peer).Return(nil)
+	mdx.On("GetEndpointInfo", nm.ctx).Return(fftypes.JSONObject{"id": "peer2"}, nil)
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(nil, fmt.Errorf("not registered")).Once()
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(org, nil)
+	mim.On("GetNodeOwnerBlockchainKey", nm.ctx).Return(&fftypes.VerifierRef{Value: "0x12345"}, nil)
+	mim.On("VerifyIdentityChain", nm.ctx, mock.MatchedBy(func(i *fftypes.Identity) bool {
+		return i.Type == fftypes.IdentityTypeOrg
+	})).Return(nil, false, nil)
+	mim.On("VerifyIdentityChain", nm.ctx, mock.MatchedBy(func(i *fftypes.Identity) bool {
+		return i.Type == fftypes.IdentityTypeNode
+	})).Return(org, false, nil)
+	mim.On("ResolveIdentitySigner", nm.ctx, org).Return(&fftypes.SignerRef{Key: "0x12345"}, nil)
+
+	msa := nm.syncasync.(*syncasyncmocks.Bridge)
+	msa.On("WaitForIdentity", nm.ctx, fftypes.SystemNamespace, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		cb := args[3].(syncasync.RequestSender)
+		err := cb(args[0].(context.Context))
+		assert.NoError(t, err)
+	}).Return(org, nil)
+
+	mockMsg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	mbm := nm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastIdentityClaim", nm.ctx, fftypes.SystemNamespace, mock.MatchedBy(func(claim *fftypes.IdentityClaim) bool {
+		ref := fftypes.ParseInvitationReference(claim.Identity.Profile)
+		return claim.Identity.Type == fftypes.IdentityTypeOrg && ref != nil &&
+			ref.ID.Equals(ic.ID) && ref.Signature.Equals(ic.Signature)
+	}), mock.Anything, fftypes.SystemTagIdentityClaim, false).Return(mockMsg, nil)
+	mbm.On("BroadcastIdentityClaim", nm.ctx, fftypes.SystemNamespace, mock.MatchedBy(func(claim *fftypes.IdentityClaim) bool {
+		return claim.Identity.Type == fftypes.IdentityTypeNode
+	}), mock.Anything, fftypes.SystemTagIdentityClaim, false).Return(mockMsg, nil)
+
+	redemption, err := nm.RedeemInvitation(nm.ctx, &fftypes.InvitationRedeemInput{Code: ic.Encode()}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, org, redemption.Organization)
+	assert.Equal(t, "org2.node", redemption.Node.Name)
+	assert.Equal(t, ic.ID, redemption.Invitation.ID)
+
+	mdx.AssertExpectations(t)
+	mim.AssertExpectations(t)
+	mbm.AssertExpectations(t)
+}
+
+func TestRedeemInvitationOrgAlreadyRegistered(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	ic := testInvitationCode(time.Now().Add(time.Hour))
+	ic.Peer = nil
+	org := testOrg("org2")
+
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("GetEndpointInfo", nm.ctx).Return(fftypes.JSONObject{"id": "peer2"}, nil)
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(org, nil)
+	mim.On("VerifyIdentityChain", nm.ctx, mock.Anything).Return(org, false, nil)
+	mim.On("ResolveIdentitySigner", nm.ctx, org).Return(&fftypes.SignerRef{Key: "0x12345"}, nil)
+
+	mockMsg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	mbm := nm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastIdentityClaim", nm.ctx, fftypes.SystemNamespace, mock.Anything, mock.Anything, fftypes.SystemTagIdentityClaim, false).Return(mockMsg, nil)
+
+	redemption, err := nm.RedeemInvitation(nm.ctx, &fftypes.InvitationRedeemInput{Code: ic.Encode()}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, org, redemption.Organization)
+	assert.NotNil(t, redemption.Node)
+
+	mdx.AssertExpectations(t)
+	mbm.AssertNumberOfCalls(t, "BroadcastIdentityClaim", 1)
+}
+
+func TestRedeemInvitationBadCode(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	_, err := nm.RedeemInvitation(nm.ctx, &fftypes.InvitationRedeemInput{Code: "!!!"}, false)
+	assert.Regexp(t, "FF10443", err)
+}
+
+func TestRedeemInvitationExpired(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	ic := testInvitationCode(time.Now().Add(-1 * time.Minute))
+	_, err := nm.RedeemInvitation(nm.ctx, &fftypes.InvitationRedeemInput{Code: ic.Encode()}, false)
+	assert.Regexp(t, "FF10444", err)
+}
+
+func TestRedeemInvitationAddPeerFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	ic := testInvitationCode(time.Now().Add(time.Hour))
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("AddPeer", nm.ctx, ic.Peer).Return(fmt.Errorf("pop"))
+
+	_, err := nm.RedeemInvitation(nm.ctx, &fftypes.InvitationRedeemInput{Code: ic.Encode()}, false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRedeemInvitationOrgRequestFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	ic := testInvitationCode(time.Now().Add(time.Hour))
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("AddPeer", nm.ctx, ic.Peer).Return(nil)
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(nil, fmt.Errorf("not registered"))
+	mim.On("GetNodeOwnerBlockchainKey", nm.ctx).Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.RedeemInvitation(nm.ctx, &fftypes.InvitationRedeemInput{Code: ic.Encode()}, false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRedeemInvitationRegisterOrgFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	config.Set(config.OrgName, "org2")
+	ic := testInvitationCode(time.Now().Add(time.Hour))
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("AddPeer", nm.ctx, ic.Peer).Return(nil)
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(nil, fmt.Errorf("not registered"))
+	mim.On("GetNodeOwnerBlockchainKey", nm.ctx).Return(&fftypes.VerifierRef{Value: "0x12345"}, nil)
+	mim.On("VerifyIdentityChain", nm.ctx, mock.Anything).Return(nil, false, fmt.Errorf("pop"))
+
+	_, err := nm.RedeemInvitation(nm.ctx, &fftypes.InvitationRedeemInput{Code: ic.Encode()}, false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRedeemInvitationRegisterNodeFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	ic := testInvitationCode(time.Now().Add(time.Hour))
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("AddPeer", nm.ctx, ic.Peer).Return(nil)
+	mdx.On("GetEndpointInfo", nm.ctx).Return(nil, fmt.Errorf("pop"))
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(testOrg("org2"), nil)
+
+	_, err := nm.RedeemInvitation(nm.ctx, &fftypes.InvitationRedeemInput{Code: ic.Encode()}, false)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetInvitations(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetInvitations", nm.ctx, mock.Anything).Return([]*fftypes.Invitation{}, nil, nil)
+	fb := database.InvitationQueryFactory.NewFilter(nm.ctx)
+	_, _, err := nm.GetInvitations(nm.ctx, fb.And(fb.Eq("state", fftypes.InvitationStatePending)))
+	assert.NoError(t, err)
+}
+
+func TestGetInvitationByIDPending(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	ft := fftypes.Now()
+	inv := &fftypes.Invitation{
+		ID:      fftypes.NewUUID(),
+		State:   fftypes.InvitationStatePending,
+		Expires: ft,
+		Secret:  fftypes.NewRandB32(),
+	}
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetInvitationByID", nm.ctx, inv.ID).Return(inv, nil)
+
+	res, err := nm.GetInvitationByID(nm.ctx, inv.ID.String())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, res.Code)
+}
+
+func TestGetInvitationByIDRedeemed(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	inv := &fftypes.Invitation{
+		ID:    fftypes.NewUUID(),
+		State: fftypes.InvitationStateRedeemed,
+	}
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetInvitationByID", nm.ctx, inv.ID).Return(inv, nil)
+
+	res, err := nm.GetInvitationByID(nm.ctx, inv.ID.String())
+	assert.NoError(t, err)
+	assert.Empty(t, res.Code)
+}
+
+func TestGetInvitationByIDBadID(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	_, err := nm.GetInvitationByID(nm.ctx, "bad")
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestGetInvitationByIDFail(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetInvitationByID", nm.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	_, err := nm.GetInvitationByID(nm.ctx, fftypes.NewUUID().String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetInvitationByIDNotFound(t *testing.T) {
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	mdi := nm.database.(*databasemocks.Plugin)
+	mdi.On("GetInvitationByID", nm.ctx, mock.Anything).Return(nil, nil)
+
+	_, err := nm.GetInvitationByID(nm.ctx, fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10445", err)
+}
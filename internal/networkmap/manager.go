@@ -34,9 +34,13 @@ type Manager interface {
 	RegisterNodeOrganization(ctx context.Context, waitConfirm bool) (org *fftypes.Identity, err error)
 	RegisterIdentity(ctx context.Context, ns string, dto *fftypes.IdentityCreateDTO, waitConfirm bool) (identity *fftypes.Identity, err error)
 	UpdateIdentity(ctx context.Context, ns string, id string, dto *fftypes.IdentityUpdateDTO, waitConfirm bool) (identity *fftypes.Identity, err error)
+	CreateInvitation(ctx context.Context, input *fftypes.InvitationInput) (*fftypes.Invitation, error)
+	RedeemInvitation(ctx context.Context, input *fftypes.InvitationRedeemInput, waitConfirm bool) (*fftypes.InvitationRedemption, error)
+	EndorseOrganization(ctx context.Context, nameOrID string, waitConfirm bool) (*fftypes.IdentityEndorsement, error)
 
 	GetOrganizationByNameOrID(ctx context.Context, nameOrID string) (*fftypes.Identity, error)
 	GetOrganizations(ctx context.Context, filter database.AndFilter) ([]*fftypes.Identity, *database.FilterResult, error)
+	GetOrganizationEndorsements(ctx context.Context, nameOrID string, filter database.AndFilter) ([]*fftypes.IdentityEndorsement, *database.FilterResult, error)
 	GetNodeByNameOrID(ctx context.Context, nameOrID string) (*fftypes.Identity, error)
 	GetNodes(ctx context.Context, filter database.AndFilter) ([]*fftypes.Identity, *database.FilterResult, error)
 	GetIdentityByID(ctx context.Context, ns string, id string) (*fftypes.Identity, error)
@@ -45,6 +49,8 @@ type Manager interface {
 	GetVerifiers(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Verifier, *database.FilterResult, error)
 	GetVerifierByHash(ctx context.Context, ns, hash string) (*fftypes.Verifier, error)
 	GetDIDDocForIndentityByID(ctx context.Context, ns, id string) (*DIDDocument, error)
+	GetInvitations(ctx context.Context, filter database.AndFilter) ([]*fftypes.Invitation, *database.FilterResult, error)
+	GetInvitationByID(ctx context.Context, id string) (*fftypes.Invitation, error)
 }
 
 type networkMap struct {
@@ -21,9 +21,19 @@ import (
 	"fmt"
 
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/residency"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
+func (nm *networkMap) getLocalNodeName(nodeOwningOrg *fftypes.Identity) string {
+	nodeName := config.GetString(config.NodeName)
+	if nodeName == "" && nodeOwningOrg.Name != "" {
+		nodeName = fmt.Sprintf("%s.node", nodeOwningOrg.Name)
+	}
+	return nodeName
+}
+
 func (nm *networkMap) RegisterNode(ctx context.Context, waitConfirm bool) (identity *fftypes.Identity, err error) {
 
 	nodeOwningOrg, err := nm.identity.GetNodeOwnerOrg(ctx)
@@ -33,23 +43,30 @@ func (nm *networkMap) RegisterNode(ctx context.Context, waitConfirm bool) (ident
 
 	nodeRequest := &fftypes.IdentityCreateDTO{
 		Parent: nodeOwningOrg.ID,
-		Name:   config.GetString(config.NodeName),
+		Name:   nm.getLocalNodeName(nodeOwningOrg),
 		Type:   fftypes.IdentityTypeNode,
 		IdentityProfile: fftypes.IdentityProfile{
 			Description: config.GetString(config.NodeDescription),
 		},
 	}
-	if nodeRequest.Name == "" {
-		if nodeOwningOrg.Name != "" {
-			nodeRequest.Name = fmt.Sprintf("%s.node", nodeOwningOrg.Name)
-		}
-	}
 
 	dxInfo, err := nm.exchange.GetEndpointInfo(ctx)
 	if err != nil {
 		return nil, err
 	}
 	nodeRequest.Profile = dxInfo
+	if privateTXKey := config.GetString(config.NodePrivateTransactionKey); privateTXKey != "" {
+		if nodeRequest.Profile == nil {
+			nodeRequest.Profile = fftypes.JSONObject{}
+		}
+		nodeRequest.Profile[blockchain.NodeProfilePrivateTransactionKey] = privateTXKey
+	}
+	if region := config.GetString(config.NodeRegion); region != "" {
+		if nodeRequest.Profile == nil {
+			nodeRequest.Profile = fftypes.JSONObject{}
+		}
+		nodeRequest.Profile[residency.NodeProfileRegion] = region
+	}
 
 	return nm.RegisterIdentity(ctx, fftypes.SystemNamespace, nodeRequest, waitConfirm)
 }
@@ -21,9 +21,11 @@ import (
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/residency"
 	"github.com/hyperledger/firefly/mocks/broadcastmocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -66,6 +68,78 @@ func TestRegisterNodeOk(t *testing.T) {
 
 }
 
+func TestRegisterNodePrivateTransactionKey(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	config.Set(config.OrgKey, "0x23456")
+	config.Set(config.OrgName, "org1")
+	config.Set(config.NodePrivateTransactionKey, "BULeR8JyUWhiuuCMU/HLA0Q5pzkYT+cHII3ZKBey3Bo=")
+
+	parentOrg := testOrg("org1")
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(parentOrg, nil)
+	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(parentOrg, false, nil)
+	signerRef := &fftypes.SignerRef{Key: "0x23456"}
+	mim.On("ResolveIdentitySigner", nm.ctx, parentOrg).Return(signerRef, nil)
+
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("GetEndpointInfo", nm.ctx).Return(nil, nil)
+
+	mockMsg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	mbm := nm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastIdentityClaim", nm.ctx,
+		fftypes.SystemNamespace,
+		mock.MatchedBy(func(claim *fftypes.IdentityClaim) bool {
+			return claim.Identity.Profile.GetString(blockchain.NodeProfilePrivateTransactionKey) == "BULeR8JyUWhiuuCMU/HLA0Q5pzkYT+cHII3ZKBey3Bo="
+		}),
+		signerRef,
+		fftypes.SystemTagIdentityClaim, false).Return(mockMsg, nil)
+
+	_, err := nm.RegisterNode(nm.ctx, false)
+	assert.NoError(t, err)
+
+	mbm.AssertExpectations(t)
+}
+
+func TestRegisterNodeRegion(t *testing.T) {
+
+	nm, cancel := newTestNetworkmap(t)
+	defer cancel()
+
+	config.Set(config.OrgKey, "0x23456")
+	config.Set(config.OrgName, "org1")
+	config.Set(config.NodeRegion, "eu-west")
+
+	parentOrg := testOrg("org1")
+
+	mim := nm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", nm.ctx).Return(parentOrg, nil)
+	mim.On("VerifyIdentityChain", nm.ctx, mock.AnythingOfType("*fftypes.Identity")).Return(parentOrg, false, nil)
+	signerRef := &fftypes.SignerRef{Key: "0x23456"}
+	mim.On("ResolveIdentitySigner", nm.ctx, parentOrg).Return(signerRef, nil)
+
+	mdx := nm.exchange.(*dataexchangemocks.Plugin)
+	mdx.On("GetEndpointInfo", nm.ctx).Return(nil, nil)
+
+	mockMsg := &fftypes.Message{Header: fftypes.MessageHeader{ID: fftypes.NewUUID()}}
+	mbm := nm.broadcast.(*broadcastmocks.Manager)
+	mbm.On("BroadcastIdentityClaim", nm.ctx,
+		fftypes.SystemNamespace,
+		mock.MatchedBy(func(claim *fftypes.IdentityClaim) bool {
+			return claim.Identity.Profile.GetString(residency.NodeProfileRegion) == "eu-west"
+		}),
+		signerRef,
+		fftypes.SystemTagIdentityClaim, false).Return(mockMsg, nil)
+
+	_, err := nm.RegisterNode(nm.ctx, false)
+	assert.NoError(t, err)
+
+	mbm.AssertExpectations(t)
+}
+
 func TestRegisterNodePeerInfoFail(t *testing.T) {
 
 	nm, cancel := newTestNetworkmap(t)
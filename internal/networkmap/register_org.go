@@ -26,6 +26,14 @@ import (
 
 // RegisterNodeOrganization is a convenience helper to register the org configured on the node, without any extra info
 func (nm *networkMap) RegisterNodeOrganization(ctx context.Context, waitConfirm bool) (*fftypes.Identity, error) {
+	orgRequest, err := nm.getNodeOrganizationRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return nm.RegisterOrganization(ctx, orgRequest, waitConfirm)
+}
+
+func (nm *networkMap) getNodeOrganizationRequest(ctx context.Context) (*fftypes.IdentityCreateDTO, error) {
 
 	key, err := nm.identity.GetNodeOwnerBlockchainKey(ctx)
 	if err != nil {
@@ -42,7 +50,7 @@ func (nm *networkMap) RegisterNodeOrganization(ctx context.Context, waitConfirm
 	if orgRequest.Name == "" {
 		return nil, i18n.NewError(ctx, i18n.MsgNodeAndOrgIDMustBeSet)
 	}
-	return nm.RegisterOrganization(ctx, orgRequest, waitConfirm)
+	return orgRequest, nil
 }
 
 func (nm *networkMap) RegisterOrganization(ctx context.Context, orgRequest *fftypes.IdentityCreateDTO, waitConfirm bool) (*fftypes.Identity, error) {
@@ -0,0 +1,193 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const (
+	channelTypeEmail     = "email"
+	channelTypeSlack     = "slack"
+	channelTypePagerDuty = "pagerduty"
+
+	defaultSMTPPort          = 587
+	defaultPagerDutyURL      = "https://events.pagerduty.com/v2/enqueue"
+	defaultPagerDutySeverity = "error"
+)
+
+type channelConfig struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	URL        string     `json:"url,omitempty"`
+	RoutingKey string     `json:"routingKey,omitempty"`
+	Severity   string     `json:"severity,omitempty"`
+	From       string     `json:"from,omitempty"`
+	To         []string   `json:"to,omitempty"`
+	SMTP       smtpConfig `json:"smtp,omitempty"`
+}
+
+type smtpConfig struct {
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+type channel interface {
+	name() string
+	send(ctx context.Context, n *notification) error
+}
+
+func (nm *notificationManager) newChannel(ctx context.Context, cc *channelConfig) (channel, error) {
+	requireSet := func(value, field string) error {
+		if value == "" {
+			return i18n.NewError(ctx, i18n.MsgNotificationChannelMissing, cc.Name, cc.Type, field)
+		}
+		return nil
+	}
+	switch cc.Type {
+	case channelTypeEmail:
+		if err := requireSet(cc.SMTP.Host, "smtp.host"); err != nil {
+			return nil, err
+		}
+		if err := requireSet(cc.From, "from"); err != nil {
+			return nil, err
+		}
+		if err := requireSet(strings.Join(cc.To, ","), "to"); err != nil {
+			return nil, err
+		}
+		if cc.SMTP.Port == 0 {
+			cc.SMTP.Port = defaultSMTPPort
+		}
+		return &emailChannel{config: cc, sendMail: smtp.SendMail}, nil
+	case channelTypeSlack:
+		if err := requireSet(cc.URL, "url"); err != nil {
+			return nil, err
+		}
+		return &slackChannel{config: cc, client: nm.httpClient()}, nil
+	case channelTypePagerDuty:
+		if err := requireSet(cc.RoutingKey, "routingKey"); err != nil {
+			return nil, err
+		}
+		if cc.URL == "" {
+			cc.URL = defaultPagerDutyURL
+		}
+		if cc.Severity == "" {
+			cc.Severity = defaultPagerDutySeverity
+		}
+		return &pagerDutyChannel{config: cc, client: nm.httpClient()}, nil
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgNotificationChannelType, cc.Type, cc.Name)
+	}
+}
+
+func (n *notification) title() string {
+	state := "FIRING"
+	if n.Resolved {
+		state = "RESOLVED"
+	}
+	return fmt.Sprintf("[FireFly %s] %s: %s", n.Node, state, n.Rule)
+}
+
+func postWebhook(ctx context.Context, client *resty.Client, url string, body interface{}) error {
+	res, err := client.R().
+		SetContext(ctx).
+		SetBody(body).
+		Post(url)
+	if err != nil || !res.IsSuccess() {
+		return restclient.WrapRestErr(ctx, res, err, i18n.MsgNotificationRESTErr)
+	}
+	return nil
+}
+
+type emailChannel struct {
+	config   *channelConfig
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func (c *emailChannel) name() string {
+	return c.config.Name
+}
+
+func (c *emailChannel) send(ctx context.Context, n *notification) error {
+	var auth smtp.Auth
+	if c.config.SMTP.Username != "" {
+		auth = smtp.PlainAuth("", c.config.SMTP.Username, c.config.SMTP.Password, c.config.SMTP.Host)
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		c.config.From, strings.Join(c.config.To, ", "), n.title(), n.Summary)
+	addr := fmt.Sprintf("%s:%d", c.config.SMTP.Host, c.config.SMTP.Port)
+	return c.sendMail(addr, auth, c.config.From, c.config.To, []byte(msg))
+}
+
+type slackChannel struct {
+	config *channelConfig
+	client *resty.Client
+}
+
+func (c *slackChannel) name() string {
+	return c.config.Name
+}
+
+func (c *slackChannel) send(ctx context.Context, n *notification) error {
+	return postWebhook(ctx, c.client, c.config.URL, fftypes.JSONObject{
+		"text": fmt.Sprintf("*%s*\n%s", n.title(), n.Summary),
+	})
+}
+
+// pagerDutyChannel sends to the PagerDuty Events API v2, using a dedup key for the rule and subject
+// so that the incident is resolved when the condition clears
+type pagerDutyChannel struct {
+	config *channelConfig
+	client *resty.Client
+}
+
+func (c *pagerDutyChannel) name() string {
+	return c.config.Name
+}
+
+func (c *pagerDutyChannel) send(ctx context.Context, n *notification) error {
+	action := "trigger"
+	if n.Resolved {
+		action = "resolve"
+	}
+	return postWebhook(ctx, c.client, c.config.URL, fftypes.JSONObject{
+		"routing_key":  c.config.RoutingKey,
+		"event_action": action,
+		"dedup_key":    fmt.Sprintf("firefly/%s/%s/%s/%s", n.Node, n.Namespace, n.Rule, n.Subject),
+		"payload": fftypes.JSONObject{
+			"summary":   n.Summary,
+			"source":    n.Node,
+			"severity":  c.config.Severity,
+			"component": "firefly",
+			"group":     n.Namespace,
+			"custom_details": fftypes.JSONObject{
+				"rule":    n.Rule,
+				"subject": n.Subject,
+			},
+		},
+	})
+}
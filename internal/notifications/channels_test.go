@@ -0,0 +1,171 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/smtp"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/statsmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestChannels(t *testing.T, channels fftypes.JSONObjectArray) map[string]channel {
+	resetTestConfig(channels, fftypes.JSONObjectArray{})
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	httpConfig.Set(restclient.HTTPCustomClient, mockedClient)
+	nm, err := NewNotificationManager(context.Background(), &databasemocks.Plugin{}, &statsmocks.Collector{})
+	assert.NoError(t, err)
+	loaded, err := nm.(*notificationManager).loadChannels(context.Background())
+	assert.NoError(t, err)
+	return loaded
+}
+
+func testNotification(resolved bool) *notification {
+	return &notification{
+		Node:      "node1",
+		Rule:      "lag",
+		Namespace: "ns1",
+		Subject:   "sub1",
+		Summary:   "Subscription 'sub1' is behind",
+		Resolved:  resolved,
+	}
+}
+
+func TestEmailChannel(t *testing.T) {
+	channels := newTestChannels(t, fftypes.JSONObjectArray{
+		{"name": "mail", "type": "email", "smtp": fftypes.JSONObject{"host": "mail.example.com", "username": "user1", "password": "pass1"}, "from": "ff@example.com", "to": []interface{}{"a@example.com", "b@example.com"}},
+		{"name": "relay", "type": "email", "smtp": fftypes.JSONObject{"host": "relay", "port": 25}, "from": "ff@example.com", "to": []interface{}{"a@example.com"}},
+	})
+	defer httpmock.DeactivateAndReset()
+
+	ec := channels["mail"].(*emailChannel)
+	assert.Equal(t, "mail", ec.name())
+	ec.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Equal(t, "mail.example.com:587", addr)
+		assert.NotNil(t, a)
+		assert.Equal(t, "ff@example.com", from)
+		assert.Equal(t, []string{"a@example.com", "b@example.com"}, to)
+		assert.Equal(t, "From: ff@example.com\r\nTo: a@example.com, b@example.com\r\nSubject: [FireFly node1] FIRING: lag\r\n"+
+			"Content-Type: text/plain; charset=UTF-8\r\n\r\nSubscription 'sub1' is behind\r\n", string(msg))
+		return nil
+	}
+	err := ec.send(context.Background(), testNotification(false))
+	assert.NoError(t, err)
+
+	ec = channels["relay"].(*emailChannel)
+	ec.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		assert.Equal(t, "relay:25", addr)
+		assert.Nil(t, a)
+		assert.Regexp(t, "RESOLVED: lag", string(msg))
+		return fmt.Errorf("pop")
+	}
+	err = ec.send(context.Background(), testNotification(true))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSlackChannel(t *testing.T) {
+	channels := newTestChannels(t, fftypes.JSONObjectArray{
+		{"name": "chat", "type": "slack", "url": "http://localhost:12345/slack"},
+	})
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/slack",
+		func(req *http.Request) (*http.Response, error) {
+			var body fftypes.JSONObject
+			b, _ := ioutil.ReadAll(req.Body)
+			_ = json.Unmarshal(b, &body)
+			assert.Equal(t, "*[FireFly node1] FIRING: lag*\nSubscription 'sub1' is behind", body.GetString("text"))
+			return httpmock.NewStringResponse(200, "ok"), nil
+		})
+
+	sc := channels["chat"]
+	assert.Equal(t, "chat", sc.name())
+	err := sc.send(context.Background(), testNotification(false))
+	assert.NoError(t, err)
+}
+
+func TestSlackChannelFail(t *testing.T) {
+	channels := newTestChannels(t, fftypes.JSONObjectArray{
+		{"name": "chat", "type": "slack", "url": "http://localhost:12345/slack"},
+	})
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("POST", "http://localhost:12345/slack",
+		httpmock.NewStringResponder(404, "no_team"))
+
+	err := channels["chat"].send(context.Background(), testNotification(false))
+	assert.Regexp(t, "FF10426.*no_team", err)
+}
+
+func TestPagerDutyChannel(t *testing.T) {
+	channels := newTestChannels(t, fftypes.JSONObjectArray{
+		{"name": "pager", "type": "pagerduty", "routingKey": "key1"},
+	})
+	defer httpmock.DeactivateAndReset()
+
+	var actions []string
+	httpmock.RegisterResponder("POST", defaultPagerDutyURL,
+		func(req *http.Request) (*http.Response, error) {
+			var body fftypes.JSONObject
+			b, _ := ioutil.ReadAll(req.Body)
+			_ = json.Unmarshal(b, &body)
+			assert.Equal(t, "key1", body.GetString("routing_key"))
+			assert.Equal(t, "firefly/node1/ns1/lag/sub1", body.GetString("dedup_key"))
+			assert.Equal(t, "Subscription 'sub1' is behind", body.GetObject("payload").GetString("summary"))
+			assert.Equal(t, "error", body.GetObject("payload").GetString("severity"))
+			assert.Equal(t, "sub1", body.GetObject("payload").GetObject("custom_details").GetString("subject"))
+			actions = append(actions, body.GetString("event_action"))
+			return httpmock.NewStringResponse(202, "{}"), nil
+		})
+
+	pc := channels["pager"]
+	assert.Equal(t, "pager", pc.name())
+	err := pc.send(context.Background(), testNotification(false))
+	assert.NoError(t, err)
+	err = pc.send(context.Background(), testNotification(true))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"trigger", "resolve"}, actions)
+}
+
+func TestPagerDutyChannelCustomised(t *testing.T) {
+	channels := newTestChannels(t, fftypes.JSONObjectArray{
+		{"name": "pager", "type": "pagerduty", "routingKey": "key1", "url": "http://localhost:12345/pd", "severity": "critical"},
+	})
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "http://localhost:12345/pd",
+		func(req *http.Request) (*http.Response, error) {
+			var body fftypes.JSONObject
+			b, _ := ioutil.ReadAll(req.Body)
+			_ = json.Unmarshal(b, &body)
+			assert.Equal(t, "critical", body.GetObject("payload").GetString("severity"))
+			return httpmock.NewStringResponse(500, "pop"), nil
+		})
+
+	err := channels["pager"].send(context.Background(), testNotification(false))
+	assert.Regexp(t, "FF10426.*pop", err)
+}
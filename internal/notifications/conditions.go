@@ -0,0 +1,183 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/hyperledger/firefly/internal/stats"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const (
+	conditionOperationFailed = "operation_failed"
+	conditionSubscriptionLag = "subscription_lag"
+	conditionPeerUnreachable = "peer_unreachable"
+)
+
+// condition is evaluated for a rule on each interval, returning a summary for each subject for
+// which the condition is currently met
+type condition interface {
+	evaluate(ctx context.Context, r *rule) (map[string]string, error)
+	// notified is called after a notification has been sent for a subject
+	notified(subject string)
+	// resolvable is true for conditions that describe a state that clears, rather than a set of events
+	resolvable() bool
+}
+
+// operationFailedCondition is met once the threshold number of operations have failed in the
+// namespace since the last notification. Failures while a notification is held back by the repeat
+// interval are included in the count of the next notification.
+type operationFailedCondition struct {
+	database database.Plugin
+	since    *fftypes.FFTime
+	count    int64
+	latest   *fftypes.Operation
+}
+
+func (c *operationFailedCondition) evaluate(ctx context.Context, r *rule) (map[string]string, error) {
+	checkTime := fftypes.Now()
+	fb := database.OperationQueryFactory.NewFilterLimit(ctx, 1)
+	filter := fb.And(
+		fb.Eq("namespace", r.Namespace),
+		fb.Eq("status", fftypes.OpStatusFailed),
+		fb.Gt("updated", c.since),
+		fb.Lte("updated", checkTime),
+	).Sort("updated").Descending().Count(true)
+	ops, res, err := c.database.GetOperations(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	c.since = checkTime
+	if len(ops) > 0 {
+		c.latest = ops[0]
+		c.count += *res.TotalCount
+	}
+	if c.count < r.Threshold {
+		return nil, nil
+	}
+	return map[string]string{
+		"": fmt.Sprintf("%d operation(s) failed in namespace '%s' - most recent: %s operation %s failed: %s",
+			c.count, r.Namespace, c.latest.Type, c.latest.ID, c.latest.Error),
+	}, nil
+}
+
+func (c *operationFailedCondition) notified(subject string) {
+	c.count = 0
+}
+
+func (c *operationFailedCondition) resolvable() bool {
+	return false
+}
+
+// subscriptionLagCondition is met for each subscription in the namespace that is more than the
+// threshold number of events behind, using the pre-aggregated status summary
+type subscriptionLagCondition struct {
+	stats stats.Collector
+}
+
+func (c *subscriptionLagCondition) evaluate(ctx context.Context, r *rule) (map[string]string, error) {
+	summary, err := c.stats.GetSummary(ctx, r.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	met := make(map[string]string)
+	for _, sub := range summary.Subscriptions {
+		if sub.Lag > r.Threshold {
+			met[sub.Name] = fmt.Sprintf("Subscription '%s' in namespace '%s' is %d events behind, above the threshold of %d",
+				sub.Name, r.Namespace, sub.Lag, r.Threshold)
+		}
+	}
+	return met, nil
+}
+
+func (c *subscriptionLagCondition) notified(subject string) {}
+
+func (c *subscriptionLagCondition) resolvable() bool {
+	return true
+}
+
+type peerState struct {
+	name      string
+	failures  int64
+	lastError string
+}
+
+// peerUnreachableCondition is met for each peer node where the threshold number of consecutive
+// sends through data exchange have failed, and clears on the next successful send to that peer
+type peerUnreachableCondition struct {
+	database database.Plugin
+	since    *fftypes.FFTime
+	peers    map[string]*peerState
+}
+
+func (c *peerUnreachableCondition) evaluate(ctx context.Context, r *rule) (map[string]string, error) {
+	checkTime := fftypes.Now()
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Eq("namespace", r.Namespace),
+		fb.In("type", []driver.Value{fftypes.OpTypeDataExchangeBatchSend, fftypes.OpTypeDataExchangeBlobSend}),
+		fb.In("status", []driver.Value{fftypes.OpStatusSucceeded, fftypes.OpStatusFailed}),
+		fb.Gt("updated", c.since),
+		fb.Lte("updated", checkTime),
+	).Sort("updated").Ascending()
+	ops, _, err := c.database.GetOperations(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	c.since = checkTime
+	for _, op := range ops {
+		node := op.Input.GetString("node")
+		if op.Status == fftypes.OpStatusSucceeded {
+			delete(c.peers, node)
+			continue
+		}
+		ps, ok := c.peers[node]
+		if !ok {
+			ps = &peerState{name: c.describeNode(ctx, node)}
+			c.peers[node] = ps
+		}
+		ps.failures++
+		ps.lastError = op.Error
+	}
+	met := make(map[string]string)
+	for node, ps := range c.peers {
+		if ps.failures >= r.Threshold {
+			met[node] = fmt.Sprintf("Peer node %s is unreachable from namespace '%s' - %d consecutive sends failed, most recent: %s",
+				ps.name, r.Namespace, ps.failures, ps.lastError)
+		}
+	}
+	return met, nil
+}
+
+func (c *peerUnreachableCondition) describeNode(ctx context.Context, node string) string {
+	if id, err := fftypes.ParseUUID(ctx, node); err == nil {
+		if identity, err := c.database.GetIdentityByID(ctx, id); err == nil && identity != nil {
+			return fmt.Sprintf("'%s' (%s)", identity.Name, node)
+		}
+	}
+	return fmt.Sprintf("'%s'", node)
+}
+
+func (c *peerUnreachableCondition) notified(subject string) {}
+
+func (c *peerUnreachableCondition) resolvable() bool {
+	return true
+}
@@ -0,0 +1,132 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/statsmocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOperationFailedAccumulatesToThreshold(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	c := &operationFailedCondition{database: mdi, since: fftypes.Now()}
+	r := &rule{ruleConfig: ruleConfig{Namespace: "ns1", Threshold: 3}}
+	assert.False(t, c.resolvable())
+
+	op1 := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeTokenTransfer, Error: "pop1"}
+	op2 := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainInvoke, Error: "pop2"}
+	two, one := int64(2), int64(1)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{op1}, &database.FilterResult{TotalCount: &two}, nil).Once()
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, &database.FilterResult{}, nil).Once()
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{op2}, &database.FilterResult{TotalCount: &one}, nil).Once()
+
+	met, err := c.evaluate(context.Background(), r)
+	assert.NoError(t, err)
+	assert.Empty(t, met)
+
+	met, err = c.evaluate(context.Background(), r)
+	assert.NoError(t, err)
+	assert.Empty(t, met)
+
+	met, err = c.evaluate(context.Background(), r)
+	assert.NoError(t, err)
+	assert.Regexp(t, "3 operation\\(s\\) failed in namespace 'ns1'.*blockchain_invoke.*pop2", met[""])
+
+	c.notified("")
+	assert.Zero(t, c.count)
+	mdi.AssertExpectations(t)
+}
+
+func TestOperationFailedQueryFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	since := fftypes.Now()
+	c := &operationFailedCondition{database: mdi, since: since}
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := c.evaluate(context.Background(), &rule{})
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, since, c.since)
+}
+
+func TestSubscriptionLagQueryFail(t *testing.T) {
+	mst := &statsmocks.Collector{}
+	c := &subscriptionLagCondition{stats: mst}
+	mst.On("GetSummary", mock.Anything, "ns1").Return(nil, fmt.Errorf("pop"))
+
+	_, err := c.evaluate(context.Background(), &rule{ruleConfig: ruleConfig{Namespace: "ns1"}})
+	assert.EqualError(t, err, "pop")
+	c.notified("sub1")
+	assert.True(t, c.resolvable())
+}
+
+func TestPeerUnreachable(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	c := &peerUnreachableCondition{database: mdi, since: fftypes.Now(), peers: make(map[string]*peerState)}
+	r := &rule{ruleConfig: ruleConfig{Namespace: "ns1", Threshold: 2}}
+	assert.True(t, c.resolvable())
+
+	node1 := fftypes.NewUUID()
+	node2 := fftypes.NewUUID()
+	failedSend := func(node string, errMsg string) *fftypes.Operation {
+		return &fftypes.Operation{Status: fftypes.OpStatusFailed, Error: errMsg, Input: fftypes.JSONObject{"node": node}}
+	}
+	mdi.On("GetIdentityByID", mock.Anything, node1).Return(&fftypes.Identity{IdentityBase: fftypes.IdentityBase{Name: "peer1"}}, nil)
+	mdi.On("GetIdentityByID", mock.Anything, node2).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
+		failedSend(node1.String(), "timeout1"),
+		failedSend(node1.String(), "timeout2"),
+		failedSend(node2.String(), "refused"),
+		failedSend("not-a-uuid", "refused"),
+		failedSend("not-a-uuid", "refused"),
+	}, nil, nil).Once()
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
+		{Status: fftypes.OpStatusSucceeded, Input: fftypes.JSONObject{"node": node1.String()}},
+		failedSend(node2.String(), "refused"),
+	}, nil, nil).Once()
+
+	met, err := c.evaluate(context.Background(), r)
+	assert.NoError(t, err)
+	assert.Len(t, met, 2)
+	assert.Regexp(t, "Peer node 'peer1' \\("+node1.String()+"\\) is unreachable from namespace 'ns1' - 2 consecutive sends failed, most recent: timeout2", met[node1.String()])
+	assert.Regexp(t, "Peer node 'not-a-uuid' is unreachable", met["not-a-uuid"])
+
+	met, err = c.evaluate(context.Background(), r)
+	assert.NoError(t, err)
+	assert.Len(t, met, 2)
+	assert.Regexp(t, "Peer node '"+node2.String()+"' is unreachable", met[node2.String()])
+	assert.NotContains(t, met, node1.String())
+
+	c.notified(node2.String())
+	mdi.AssertExpectations(t)
+}
+
+func TestPeerUnreachableQueryFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	c := &peerUnreachableCondition{database: mdi, since: fftypes.Now(), peers: make(map[string]*peerState)}
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := c.evaluate(context.Background(), &rule{})
+	assert.EqualError(t, err, "pop")
+}
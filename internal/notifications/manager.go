@@ -0,0 +1,279 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/internal/stats"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+var httpConfig = config.NewPluginConfig("notifications.http")
+
+// InitConfig registers the configuration of the HTTP client used by the webhook channels
+func InitConfig() {
+	restclient.InitPrefix(httpConfig)
+}
+
+// Manager evaluates the notification rules configured by the operator on an interval, and sends
+// notifications to the channels of each rule while its condition is met
+type Manager interface {
+	WaitStop()
+}
+
+type ruleConfig struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Condition string   `json:"condition"`
+	Threshold int64    `json:"threshold"`
+	Channels  []string `json:"channels"`
+}
+
+type rule struct {
+	ruleConfig
+	condition condition
+	channels  []channel
+	alerts    map[string]*alert
+}
+
+// alert is the state of a rule for one subject, such as a subscription or a peer. It stops a
+// notification being repeated on every evaluation while the condition remains met.
+type alert struct {
+	summary  string
+	lastSent time.Time
+}
+
+type notification struct {
+	Node      string
+	Rule      string
+	Namespace string
+	Subject   string
+	Summary   string
+	Resolved  bool
+}
+
+type notificationManager struct {
+	ctx            context.Context
+	database       database.Plugin
+	stats          stats.Collector
+	interval       time.Duration
+	repeatInterval time.Duration
+	client         *resty.Client
+	rules          []*rule
+	done           chan struct{}
+}
+
+func NewNotificationManager(ctx context.Context, di database.Plugin, sc stats.Collector) (Manager, error) {
+	if di == nil || sc == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	nm := &notificationManager{
+		ctx:            log.WithLogger(ctx, log.L(ctx).WithField("role", "notifications")),
+		database:       di,
+		stats:          sc,
+		interval:       config.GetDuration(config.NotificationsInterval),
+		repeatInterval: config.GetDuration(config.NotificationsRepeatInterval),
+		done:           make(chan struct{}),
+	}
+	channels, err := nm.loadChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := nm.loadRules(ctx, channels); err != nil {
+		return nil, err
+	}
+	if len(nm.rules) == 0 {
+		close(nm.done)
+	} else {
+		go nm.evaluationLoop()
+	}
+	return nm, nil
+}
+
+func parseConfigEntry(ctx context.Context, key config.RootKey, i int, entry fftypes.JSONObject, into interface{}) error {
+	b, _ := json.Marshal(entry)
+	if err := json.Unmarshal(b, into); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgNotificationConfigInvalid, key, i)
+	}
+	return nil
+}
+
+func (nm *notificationManager) httpClient() *resty.Client {
+	if nm.client == nil {
+		nm.client = restclient.New(nm.ctx, httpConfig)
+	}
+	return nm.client
+}
+
+func (nm *notificationManager) loadChannels(ctx context.Context) (map[string]channel, error) {
+	channels := make(map[string]channel)
+	for i, entry := range config.GetObjectArray(config.NotificationsChannels) {
+		var cc channelConfig
+		if err := parseConfigEntry(ctx, config.NotificationsChannels, i, entry, &cc); err != nil {
+			return nil, err
+		}
+		if err := fftypes.ValidateFFNameField(ctx, cc.Name, fmt.Sprintf("notifications.channels[%d].name", i)); err != nil {
+			return nil, err
+		}
+		if _, exists := channels[cc.Name]; exists {
+			log.L(ctx).Warnf("Duplicate notification channel (ignored): %s", cc.Name)
+			continue
+		}
+		ch, err := nm.newChannel(ctx, &cc)
+		if err != nil {
+			return nil, err
+		}
+		channels[cc.Name] = ch
+	}
+	return channels, nil
+}
+
+func (nm *notificationManager) loadRules(ctx context.Context, channels map[string]channel) error {
+	names := make(map[string]bool)
+	for i, entry := range config.GetObjectArray(config.NotificationsRules) {
+		r := &rule{alerts: make(map[string]*alert)}
+		if err := parseConfigEntry(ctx, config.NotificationsRules, i, entry, &r.ruleConfig); err != nil {
+			return err
+		}
+		if err := fftypes.ValidateFFNameField(ctx, r.Name, fmt.Sprintf("notifications.rules[%d].name", i)); err != nil {
+			return err
+		}
+		if names[r.Name] {
+			log.L(ctx).Warnf("Duplicate notification rule (ignored): %s", r.Name)
+			continue
+		}
+		if r.Namespace == "" {
+			r.Namespace = config.GetString(config.NamespacesDefault)
+		}
+		var defaultThreshold int64
+		switch r.Condition {
+		case conditionOperationFailed:
+			r.condition = &operationFailedCondition{database: nm.database, since: fftypes.Now()}
+			defaultThreshold = 1
+		case conditionSubscriptionLag:
+			r.condition = &subscriptionLagCondition{stats: nm.stats}
+			defaultThreshold = 100
+		case conditionPeerUnreachable:
+			r.condition = &peerUnreachableCondition{database: nm.database, since: fftypes.Now(), peers: make(map[string]*peerState)}
+			defaultThreshold = 1
+		default:
+			return i18n.NewError(ctx, i18n.MsgNotificationRuleCondition, r.Condition, r.Name)
+		}
+		if r.Threshold <= 0 {
+			r.Threshold = defaultThreshold
+		}
+		if len(r.Channels) == 0 {
+			return i18n.NewError(ctx, i18n.MsgNotificationRuleNoChannels, r.Name)
+		}
+		for _, name := range r.Channels {
+			ch, ok := channels[name]
+			if !ok {
+				return i18n.NewError(ctx, i18n.MsgNotificationChannelUnknown, r.Name, name)
+			}
+			r.channels = append(r.channels, ch)
+		}
+		names[r.Name] = true
+		nm.rules = append(nm.rules, r)
+	}
+	return nil
+}
+
+func (nm *notificationManager) WaitStop() {
+	<-nm.done
+}
+
+func (nm *notificationManager) evaluationLoop() {
+	defer close(nm.done)
+	ticker := time.NewTicker(nm.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-nm.ctx.Done():
+			log.L(nm.ctx).Debugf("Notification loop exiting")
+			return
+		case <-ticker.C:
+		}
+		for _, r := range nm.rules {
+			nm.evaluateRule(nm.ctx, r)
+		}
+	}
+}
+
+// evaluateRule sends a notification for each subject that newly meets the condition of the rule,
+// or that has continued to meet it for longer than the repeat interval. Once the condition is no
+// longer met for a subject, a resolved notification is sent, for those conditions that can resolve.
+func (nm *notificationManager) evaluateRule(ctx context.Context, r *rule) {
+	met, err := r.condition.evaluate(ctx, r)
+	if err != nil {
+		log.L(ctx).Errorf("Failed to evaluate notification rule '%s': %s", r.Name, err)
+		return
+	}
+	now := time.Now()
+	subjects := make([]string, 0, len(met))
+	for subject := range met {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+	for _, subject := range subjects {
+		a, existing := r.alerts[subject]
+		if !existing {
+			a = &alert{}
+			r.alerts[subject] = a
+		}
+		a.summary = met[subject]
+		if existing && now.Sub(a.lastSent) < nm.repeatInterval {
+			continue
+		}
+		a.lastSent = now
+		nm.notify(ctx, r, &notification{Subject: subject, Summary: a.summary})
+		r.condition.notified(subject)
+	}
+	if !r.condition.resolvable() {
+		return
+	}
+	for subject, a := range r.alerts {
+		if _, ok := met[subject]; !ok {
+			nm.notify(ctx, r, &notification{Subject: subject, Summary: a.summary, Resolved: true})
+			delete(r.alerts, subject)
+		}
+	}
+}
+
+// notify sends to every channel of the rule. A failure on one channel is logged, and does not stop
+// the notification being sent to the others, or cause it to be repeated on the next evaluation.
+func (nm *notificationManager) notify(ctx context.Context, r *rule, n *notification) {
+	n.Node = config.GetString(config.NodeName)
+	n.Rule = r.Name
+	n.Namespace = r.Namespace
+	log.L(ctx).Infof("Notification for rule '%s' (resolved=%t): %s", r.Name, n.Resolved, n.Summary)
+	for _, ch := range r.channels {
+		if err := ch.send(ctx, n); err != nil {
+			log.L(ctx).Errorf("Failed to send notification for rule '%s' to channel '%s': %s", r.Name, ch.name(), err)
+		}
+	}
+}
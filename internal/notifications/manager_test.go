@@ -0,0 +1,235 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/statsmocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testChannel struct {
+	sent []*notification
+	err  error
+}
+
+func (c *testChannel) name() string {
+	return "test"
+}
+
+func (c *testChannel) send(ctx context.Context, n *notification) error {
+	c.sent = append(c.sent, n)
+	return c.err
+}
+
+func resetTestConfig(channels, rules fftypes.JSONObjectArray) {
+	config.Reset()
+	InitConfig()
+	config.Set(config.NotificationsChannels, channels)
+	config.Set(config.NotificationsRules, rules)
+}
+
+func newTestNotificationManager(t *testing.T, rules fftypes.JSONObjectArray) (*notificationManager, *databasemocks.Plugin, *statsmocks.Collector, func()) {
+	resetTestConfig(fftypes.JSONObjectArray{
+		{"name": "ops", "type": "slack", "url": "http://localhost:12345/slack"},
+	}, rules)
+	mdi := &databasemocks.Plugin{}
+	mst := &statsmocks.Collector{}
+	ctx, cancel := context.WithCancel(context.Background())
+	nm, err := NewNotificationManager(ctx, mdi, mst)
+	assert.NoError(t, err)
+	return nm.(*notificationManager), mdi, mst, func() {
+		cancel()
+		nm.WaitStop()
+	}
+}
+
+func TestNewNotificationManagerMissingDeps(t *testing.T) {
+	_, err := NewNotificationManager(context.Background(), nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestNewNotificationManagerNoRules(t *testing.T) {
+	resetTestConfig(fftypes.JSONObjectArray{}, fftypes.JSONObjectArray{})
+	nm, err := NewNotificationManager(context.Background(), &databasemocks.Plugin{}, &statsmocks.Collector{})
+	assert.NoError(t, err)
+	nm.WaitStop()
+}
+
+func TestNewNotificationManagerRuleDefaults(t *testing.T) {
+	nm, _, _, done := newTestNotificationManager(t, fftypes.JSONObjectArray{
+		{"name": "failures", "condition": "operation_failed", "channels": []interface{}{"ops"}},
+		{"name": "lag", "namespace": "ns1", "condition": "subscription_lag", "channels": []interface{}{"ops"}},
+		{"name": "peers", "condition": "peer_unreachable", "threshold": 3, "channels": []interface{}{"ops"}},
+		{"name": "peers", "condition": "peer_unreachable", "channels": []interface{}{"ops"}},
+	})
+	defer done()
+	assert.Len(t, nm.rules, 3)
+	assert.Equal(t, "default", nm.rules[0].Namespace)
+	assert.Equal(t, int64(1), nm.rules[0].Threshold)
+	assert.Equal(t, "ns1", nm.rules[1].Namespace)
+	assert.Equal(t, int64(100), nm.rules[1].Threshold)
+	assert.Equal(t, int64(3), nm.rules[2].Threshold)
+	assert.Len(t, nm.rules[2].channels, 1)
+}
+
+func TestNewNotificationManagerBadConfig(t *testing.T) {
+	slack := fftypes.JSONObject{"name": "ops", "type": "slack", "url": "http://localhost:12345/slack"}
+	tests := []struct {
+		channels fftypes.JSONObjectArray
+		rules    fftypes.JSONObjectArray
+		err      string
+	}{
+		{channels: fftypes.JSONObjectArray{{"name": "ops", "to": "not-an-array"}}, err: "FF10421.*notifications.channels\\[0\\]"},
+		{channels: fftypes.JSONObjectArray{{"name": "!bad", "type": "slack"}}, err: "FF10131.*notifications.channels\\[0\\].name"},
+		{channels: fftypes.JSONObjectArray{{"name": "ops", "type": "sms"}}, err: "FF10422.*sms"},
+		{channels: fftypes.JSONObjectArray{{"name": "ops", "type": "email"}}, err: "FF10423.*smtp.host"},
+		{channels: fftypes.JSONObjectArray{{"name": "ops", "type": "email", "smtp": fftypes.JSONObject{"host": "mail"}}}, err: "FF10423.*from"},
+		{channels: fftypes.JSONObjectArray{{"name": "ops", "type": "email", "smtp": fftypes.JSONObject{"host": "mail"}, "from": "ff@example.com"}}, err: "FF10423.*to"},
+		{channels: fftypes.JSONObjectArray{{"name": "ops", "type": "slack"}}, err: "FF10423.*url"},
+		{channels: fftypes.JSONObjectArray{{"name": "ops", "type": "pagerduty"}}, err: "FF10423.*routingKey"},
+		{channels: fftypes.JSONObjectArray{slack}, rules: fftypes.JSONObjectArray{{"name": "r1", "threshold": "many"}}, err: "FF10421.*notifications.rules\\[0\\]"},
+		{channels: fftypes.JSONObjectArray{slack}, rules: fftypes.JSONObjectArray{{"name": "!bad"}}, err: "FF10131.*notifications.rules\\[0\\].name"},
+		{channels: fftypes.JSONObjectArray{slack}, rules: fftypes.JSONObjectArray{{"name": "r1", "condition": "cpu_high"}}, err: "FF10424.*cpu_high"},
+		{channels: fftypes.JSONObjectArray{slack}, rules: fftypes.JSONObjectArray{{"name": "r1", "condition": "operation_failed"}}, err: "FF10427.*r1"},
+		{channels: fftypes.JSONObjectArray{slack}, rules: fftypes.JSONObjectArray{{"name": "r1", "condition": "operation_failed", "channels": []interface{}{"other"}}}, err: "FF10425.*other"},
+	}
+	for _, test := range tests {
+		resetTestConfig(test.channels, test.rules)
+		_, err := NewNotificationManager(context.Background(), &databasemocks.Plugin{}, &statsmocks.Collector{})
+		assert.Regexp(t, test.err, err)
+	}
+}
+
+func TestNewNotificationManagerDuplicateChannel(t *testing.T) {
+	resetTestConfig(fftypes.JSONObjectArray{
+		{"name": "ops", "type": "pagerduty", "routingKey": "key1"},
+		{"name": "ops", "type": "sms"},
+	}, fftypes.JSONObjectArray{})
+	nm, err := NewNotificationManager(context.Background(), &databasemocks.Plugin{}, &statsmocks.Collector{})
+	assert.NoError(t, err)
+	channels, err := nm.(*notificationManager).loadChannels(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, channels, 1)
+}
+
+func TestEvaluationLoop(t *testing.T) {
+	resetTestConfig(fftypes.JSONObjectArray{
+		{"name": "ops", "type": "slack", "url": "http://localhost:12345/slack"},
+	}, fftypes.JSONObjectArray{
+		{"name": "lag", "namespace": "ns1", "condition": "subscription_lag", "channels": []interface{}{"ops"}},
+	})
+	config.Set(config.NotificationsInterval, "1ms")
+	ctx, cancel := context.WithCancel(context.Background())
+	mst := &statsmocks.Collector{}
+	mst.On("GetSummary", mock.Anything, "ns1").Return(&fftypes.NamespaceStatusSummary{}, nil).Run(func(args mock.Arguments) {
+		cancel()
+	})
+	nm, err := NewNotificationManager(ctx, &databasemocks.Plugin{}, mst)
+	assert.NoError(t, err)
+	nm.WaitStop()
+	mst.AssertExpectations(t)
+}
+
+func TestEvaluateRuleTriggerRepeatResolve(t *testing.T) {
+	nm, _, mst, done := newTestNotificationManager(t, fftypes.JSONObjectArray{
+		{"name": "lag", "namespace": "ns1", "condition": "subscription_lag", "threshold": 10, "channels": []interface{}{"ops"}},
+	})
+	defer done()
+	config.Set(config.NodeName, "node1")
+	tc := &testChannel{err: fmt.Errorf("pop")}
+	r := nm.rules[0]
+	r.channels = []channel{tc}
+
+	lagging := &fftypes.NamespaceStatusSummary{
+		Subscriptions: []*fftypes.SubscriptionLag{
+			{Name: "sub1", Lag: 50},
+			{Name: "sub2", Lag: 20},
+			{Name: "sub3", Lag: 5},
+		},
+	}
+	mst.On("GetSummary", mock.Anything, "ns1").Return(lagging, nil).Twice()
+
+	nm.evaluateRule(nm.ctx, r)
+	assert.Len(t, tc.sent, 2)
+	assert.Equal(t, "sub1", tc.sent[0].Subject)
+	assert.Equal(t, "sub2", tc.sent[1].Subject)
+	assert.Equal(t, "node1", tc.sent[0].Node)
+	assert.Equal(t, "lag", tc.sent[0].Rule)
+	assert.Equal(t, "ns1", tc.sent[0].Namespace)
+	assert.Regexp(t, "sub1.*50 events behind", tc.sent[0].Summary)
+	assert.False(t, tc.sent[0].Resolved)
+
+	// Held back by the repeat interval
+	nm.evaluateRule(nm.ctx, r)
+	assert.Len(t, tc.sent, 2)
+
+	// Repeated once the interval has passed, and resolved once the lag clears
+	r.alerts["sub1"].lastSent = time.Now().Add(-2 * time.Hour)
+	mst.On("GetSummary", mock.Anything, "ns1").Return(&fftypes.NamespaceStatusSummary{
+		Subscriptions: []*fftypes.SubscriptionLag{{Name: "sub1", Lag: 50}},
+	}, nil).Once()
+	nm.evaluateRule(nm.ctx, r)
+	assert.Len(t, tc.sent, 4)
+	assert.Equal(t, "sub1", tc.sent[2].Subject)
+	assert.False(t, tc.sent[2].Resolved)
+	assert.Equal(t, "sub2", tc.sent[3].Subject)
+	assert.True(t, tc.sent[3].Resolved)
+	assert.Len(t, r.alerts, 1)
+}
+
+func TestEvaluateRuleEventsNotResolved(t *testing.T) {
+	nm, mdi, _, done := newTestNotificationManager(t, fftypes.JSONObjectArray{
+		{"name": "failures", "namespace": "ns1", "condition": "operation_failed", "channels": []interface{}{"ops"}},
+	})
+	defer done()
+	tc := &testChannel{}
+	r := nm.rules[0]
+	r.channels = []channel{tc}
+
+	op := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainInvoke, Error: "pop"}
+	total := int64(1)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{op}, &database.FilterResult{TotalCount: &total}, nil).Once()
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, &database.FilterResult{}, nil).Once()
+
+	nm.evaluateRule(nm.ctx, r)
+	nm.evaluateRule(nm.ctx, r)
+	assert.Len(t, tc.sent, 1)
+	assert.Len(t, r.alerts, 1)
+}
+
+func TestEvaluateRuleFail(t *testing.T) {
+	nm, _, mst, done := newTestNotificationManager(t, fftypes.JSONObjectArray{
+		{"name": "lag", "namespace": "ns1", "condition": "subscription_lag", "channels": []interface{}{"ops"}},
+	})
+	defer done()
+	tc := &testChannel{}
+	nm.rules[0].channels = []channel{tc}
+	mst.On("GetSummary", mock.Anything, "ns1").Return(nil, fmt.Errorf("pop"))
+
+	nm.evaluateRule(nm.ctx, nm.rules[0])
+	assert.Empty(t, tc.sent)
+}
@@ -19,6 +19,7 @@ package oapispec
 import (
 	"context"
 	"net/http"
+	"net/url"
 
 	"github.com/hyperledger/firefly/internal/orchestrator"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -38,4 +39,8 @@ type APIRequest struct {
 	SuccessStatus   int
 	APIBaseURL      string
 	ResponseHeaders http.Header
+	// Admin is true if the request arrived on the admin listener, rather than the main API
+	Admin bool
+	// FilterFromQuery builds a filter from a set of query values, using the same syntax as the filter on the request
+	FilterFromQuery func(query url.Values, ff database.QueryFactory) (database.AndFilter, error)
 }
@@ -63,6 +63,8 @@ type Route struct {
 	FormUploadHandler func(r *APIRequest) (output interface{}, err error)
 	// Deprecated whether this route is deprecated
 	Deprecated bool
+	// Streaming routes hold the response open until the client disconnects, so are not bound by the API request timeout
+	Streaming bool
 }
 
 // PathParam is a description of a path parameter
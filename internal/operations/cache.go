@@ -19,19 +19,25 @@ package operations
 import (
 	"context"
 	"encoding/json"
+	"sync"
 
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
 type operationCacheKey struct{}
-type operationCache map[string]*fftypes.Operation
 
-func getOperationCache(ctx context.Context) operationCache {
+// operationCache is safe for use by multiple routines, as operations can be added in parallel within one context
+type operationCache struct {
+	mux sync.Mutex
+	ops map[string]*fftypes.Operation
+}
+
+func getOperationCache(ctx context.Context) *operationCache {
 	ctxKey := operationCacheKey{}
 	cacheVal := ctx.Value(ctxKey)
 	if cacheVal != nil {
-		if cache, ok := cacheVal.(operationCache); ok {
+		if cache, ok := cacheVal.(*operationCache); ok {
 			return cache
 		}
 	}
@@ -56,7 +62,7 @@ func getCacheKey(op *fftypes.Operation) (string, error) {
 func beginCache(ctx context.Context) (ctx1 context.Context) {
 	l := log.L(ctx).WithField("opcache", fftypes.ShortID())
 	ctx1 = log.WithLogger(ctx, l)
-	return context.WithValue(ctx1, operationCacheKey{}, operationCache{})
+	return context.WithValue(ctx1, operationCacheKey{}, &operationCache{ops: make(map[string]*fftypes.Operation)})
 }
 
 func RunWithOperationCache(ctx context.Context, fn func(ctx context.Context) error) error {
@@ -68,7 +74,9 @@ func (om *operationsManager) AddOrReuseOperation(ctx context.Context, op *fftype
 	cache := getOperationCache(ctx)
 	if cache != nil {
 		if cacheKey, err := getCacheKey(op); err == nil {
-			if cached, ok := cache[cacheKey]; ok {
+			cache.mux.Lock()
+			defer cache.mux.Unlock()
+			if cached, ok := cache.ops[cacheKey]; ok {
 				// Identical operation already added in this context
 				*op = *cached
 				return nil
@@ -76,7 +84,7 @@ func (om *operationsManager) AddOrReuseOperation(ctx context.Context, op *fftype
 			if err = om.database.InsertOperation(ctx, op); err != nil {
 				return err
 			}
-			cache[cacheKey] = op
+			cache.ops[cacheKey] = op
 			return nil
 		}
 	}
@@ -38,6 +38,7 @@ type Manager interface {
 	RunOperation(ctx context.Context, op *fftypes.PreparedOperation) error
 	RetryOperation(ctx context.Context, ns string, opID *fftypes.UUID) (*fftypes.Operation, error)
 	AddOrReuseOperation(ctx context.Context, op *fftypes.Operation) error
+	StepFailed(ctx context.Context, opID *fftypes.UUID) error
 }
 
 type operationsManager struct {
@@ -45,6 +46,7 @@ type operationsManager struct {
 	database database.Plugin
 	tokens   map[string]tokens.Plugin
 	handlers map[fftypes.OpType]OperationHandler
+	saga     *sagaCoordinator
 }
 
 func NewOperationsManager(ctx context.Context, di database.Plugin, ti map[string]tokens.Plugin) (Manager, error) {
@@ -57,6 +59,8 @@ func NewOperationsManager(ctx context.Context, di database.Plugin, ti map[string
 		tokens:   ti,
 		handlers: make(map[fftypes.OpType]OperationHandler),
 	}
+	om.saga = &sagaCoordinator{om: om}
+	om.RegisterHandler(ctx, om.saga, []fftypes.OpType{fftypes.OpTypeSagaCompensate})
 	return om, nil
 }
 
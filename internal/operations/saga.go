@@ -0,0 +1,162 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// CompensatingHandler is implemented by operation handlers that can undo a completed step of a multi-step
+// send (such as blob upload -> data exchange transfer -> batch pin), when a later step fails permanently.
+type CompensatingHandler interface {
+	OperationHandler
+	CompensationRequired(step, failed *fftypes.Operation) bool
+	CompensateOperation(ctx context.Context, id *fftypes.UUID, step *fftypes.Operation) (complete bool, err error)
+}
+
+type compensateData struct {
+	Step *fftypes.Operation
+}
+
+// sagaCoordinator is the handler for compensation operations, which are recorded in the same transaction as
+// the step they undo - so they are visible alongside it, and can be retried like any other operation.
+type sagaCoordinator struct {
+	om *operationsManager
+}
+
+func (sc *sagaCoordinator) Name() string {
+	return "SagaCoordinator"
+}
+
+func (sc *sagaCoordinator) PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error) {
+	stepID, err := fftypes.ParseUUID(ctx, op.Input.GetString("step"))
+	if err != nil {
+		return nil, err
+	}
+	step, err := sc.om.database.GetOperationByID(ctx, stepID)
+	if err != nil {
+		return nil, err
+	} else if step == nil {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return opCompensate(op, step), nil
+}
+
+func (sc *sagaCoordinator) RunOperation(ctx context.Context, op *fftypes.PreparedOperation) (complete bool, err error) {
+	data, ok := op.Data.(compensateData)
+	if !ok {
+		return false, i18n.NewError(ctx, i18n.MsgOperationNotSupported)
+	}
+	handler, ok := sc.om.handlers[data.Step.Type].(CompensatingHandler)
+	if !ok {
+		return false, i18n.NewError(ctx, i18n.MsgOperationNotSupported)
+	}
+	return handler.CompensateOperation(ctx, op.ID, data.Step)
+}
+
+func opCompensate(op *fftypes.Operation, step *fftypes.Operation) *fftypes.PreparedOperation {
+	return &fftypes.PreparedOperation{
+		ID:   op.ID,
+		Type: op.Type,
+		Data: compensateData{Step: step},
+	}
+}
+
+// StepFailed is called when an operation fails permanently, as reported asynchronously by a plugin. Each other
+// step of the transaction that must be undone has a compensation operation recorded and then run.
+func (om *operationsManager) StepFailed(ctx context.Context, opID *fftypes.UUID) error {
+	failed, err := om.database.GetOperationByID(ctx, opID)
+	if err != nil || failed == nil {
+		return err
+	}
+
+	switch failed.Type {
+	case fftypes.OpTypeSagaCompensate:
+		// A failed compensation is recorded against its own operation, and can be retried from there
+		return nil
+	case fftypes.OpTypeBlockchainPinRollup:
+		// Each of the batch pins in the rollup failed along with it
+		for _, idStr := range failed.Input.GetStringArray("operations") {
+			if pinOpID, err := fftypes.ParseUUID(ctx, idStr); err == nil {
+				if err := om.StepFailed(ctx, pinOpID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	steps, _, err := om.database.GetOperations(ctx, fb.And(
+		fb.Eq("tx", failed.Transaction),
+		fb.Eq("namespace", failed.Namespace),
+	))
+	if err != nil {
+		return err
+	}
+
+	compensated := make(map[fftypes.UUID]bool)
+	for _, step := range steps {
+		if step.Type == fftypes.OpTypeSagaCompensate {
+			if stepID, err := fftypes.ParseUUID(ctx, step.Input.GetString("step")); err == nil {
+				compensated[*stepID] = true
+			}
+		}
+	}
+
+	var compensations []*fftypes.PreparedOperation
+	err = om.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		for _, step := range steps {
+			if step.ID.Equals(failed.ID) || step.Retry != nil || compensated[*step.ID] {
+				continue
+			}
+			handler, ok := om.handlers[step.Type].(CompensatingHandler)
+			if !ok || !handler.CompensationRequired(step, failed) {
+				continue
+			}
+			// The compensation is performed by the same plugin as the step, so updates from that plugin correlate to it
+			op := fftypes.NewOperation(om.saga, step.Namespace, step.Transaction, fftypes.OpTypeSagaCompensate)
+			op.Plugin = step.Plugin
+			op.Input = fftypes.JSONObject{
+				"step":   step.ID.String(),
+				"failed": failed.ID.String(),
+			}
+			if err := om.database.InsertOperation(ctx, op); err != nil {
+				return err
+			}
+			compensations = append(compensations, opCompensate(op, step))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, po := range compensations {
+		log.L(ctx).Infof("Compensating operation %s after failure of %s operation %s", po.Data.(compensateData).Step.ID, failed.Type, failed.ID)
+		if err := om.RunOperation(ctx, po); err != nil {
+			// The failure is recorded against the compensation operation
+			log.L(ctx).Errorf("Compensation operation %s failed: %s", po.ID, err)
+		}
+	}
+	return nil
+}
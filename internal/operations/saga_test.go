@@ -0,0 +1,336 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operations
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockCompensatingHandler struct {
+	mockHandler
+	Required      bool
+	Compensated   []*fftypes.Operation
+	CompensateOK  bool
+	CompensateErr error
+}
+
+func (m *mockCompensatingHandler) CompensationRequired(step, failed *fftypes.Operation) bool {
+	return m.Required
+}
+
+func (m *mockCompensatingHandler) CompensateOperation(ctx context.Context, id *fftypes.UUID, step *fftypes.Operation) (complete bool, err error) {
+	m.Compensated = append(m.Compensated, step)
+	return m.CompensateOK, m.CompensateErr
+}
+
+func TestStepFailedCompensates(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	txID := fftypes.NewUUID()
+	failed := &fftypes.Operation{ID: fftypes.NewUUID(), Namespace: "ns1", Transaction: txID, Type: fftypes.OpTypeDataExchangeBatchSend}
+	pin := &fftypes.Operation{ID: fftypes.NewUUID(), Namespace: "ns1", Transaction: txID, Type: fftypes.OpTypeBlockchainBatchPin, Plugin: "ethereum"}
+	retried := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainBatchPin, Retry: pin.ID}
+	done := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainBatchPin}
+	doneCompensation := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeSagaCompensate, Input: fftypes.JSONObject{"step": done.ID.String()}}
+	blob := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeDataExchangeBlobSend}
+
+	mch := &mockCompensatingHandler{Required: true, CompensateOK: true}
+	om.RegisterHandler(ctx, mch, []fftypes.OpType{fftypes.OpTypeBlockchainBatchPin})
+	om.RegisterHandler(ctx, &mockHandler{}, []fftypes.OpType{fftypes.OpTypeDataExchangeBlobSend})
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, failed.ID).Return(failed, nil)
+	mdi.On("GetOperations", ctx, mock.Anything).Return([]*fftypes.Operation{failed, pin, retried, done, doneCompensation, blob}, nil, nil)
+	var compensation *fftypes.Operation
+	mdi.On("InsertOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		compensation = op
+		return op.Type == fftypes.OpTypeSagaCompensate &&
+			op.Plugin == "ethereum" &&
+			op.Namespace == "ns1" &&
+			op.Transaction.Equals(txID) &&
+			op.Input.GetString("step") == pin.ID.String() &&
+			op.Input.GetString("failed") == failed.ID.String()
+	})).Return(nil).Once()
+	mdi.On("ResolveOperation", ctx, mock.Anything, fftypes.OpStatusSucceeded, "", fftypes.JSONObject(nil)).Return(nil)
+
+	err := om.StepFailed(ctx, failed.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.Operation{pin}, mch.Compensated)
+	mdi.AssertCalled(t, "ResolveOperation", ctx, compensation.ID, fftypes.OpStatusSucceeded, "", fftypes.JSONObject(nil))
+
+	mdi.AssertExpectations(t)
+}
+
+func TestStepFailedCompensationFails(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	failed := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainBatchPin}
+	send := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeDataExchangeBatchSend}
+
+	mch := &mockCompensatingHandler{Required: true, CompensateErr: fmt.Errorf("pop")}
+	om.RegisterHandler(ctx, mch, []fftypes.OpType{fftypes.OpTypeDataExchangeBatchSend})
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, failed.ID).Return(failed, nil)
+	mdi.On("GetOperations", ctx, mock.Anything).Return([]*fftypes.Operation{failed, send}, nil, nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("ResolveOperation", ctx, mock.Anything, fftypes.OpStatusFailed, "pop", fftypes.JSONObject(nil)).Return(nil)
+
+	err := om.StepFailed(ctx, failed.ID)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestStepFailedNotRequired(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	failed := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeTokenTransfer}
+	send := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeDataExchangeBatchSend}
+
+	mch := &mockCompensatingHandler{Required: false}
+	om.RegisterHandler(ctx, mch, []fftypes.OpType{fftypes.OpTypeDataExchangeBatchSend})
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, failed.ID).Return(failed, nil)
+	mdi.On("GetOperations", ctx, mock.Anything).Return([]*fftypes.Operation{failed, send}, nil, nil)
+
+	err := om.StepFailed(ctx, failed.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, mch.Compensated)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestStepFailedInsertFail(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	failed := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainBatchPin}
+	send := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeDataExchangeBatchSend}
+
+	mch := &mockCompensatingHandler{Required: true}
+	om.RegisterHandler(ctx, mch, []fftypes.OpType{fftypes.OpTypeDataExchangeBatchSend})
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, failed.ID).Return(failed, nil)
+	mdi.On("GetOperations", ctx, mock.Anything).Return([]*fftypes.Operation{failed, send}, nil, nil)
+	mdi.On("InsertOperation", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := om.StepFailed(ctx, failed.ID)
+	assert.EqualError(t, err, "pop")
+	assert.Empty(t, mch.Compensated)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestStepFailedGetOperationsFail(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	failed := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainBatchPin}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, failed.ID).Return(failed, nil)
+	mdi.On("GetOperations", ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := om.StepFailed(ctx, failed.ID)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestStepFailedNotFound(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	opID := fftypes.NewUUID()
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, opID).Return(nil, nil)
+
+	err := om.StepFailed(ctx, opID)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestStepFailedCompensation(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	failed := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeSagaCompensate}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, failed.ID).Return(failed, nil)
+
+	err := om.StepFailed(ctx, failed.ID)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestStepFailedRollup(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	pin := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainBatchPin}
+	rollup := &fftypes.Operation{
+		ID:    fftypes.NewUUID(),
+		Type:  fftypes.OpTypeBlockchainPinRollup,
+		Input: fftypes.JSONObject{"operations": []string{"bad", pin.ID.String()}},
+	}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, rollup.ID).Return(rollup, nil)
+	mdi.On("GetOperationByID", ctx, pin.ID).Return(pin, nil)
+	mdi.On("GetOperations", ctx, mock.Anything).Return([]*fftypes.Operation{pin}, nil, nil)
+
+	err := om.StepFailed(ctx, rollup.ID)
+	assert.NoError(t, err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestStepFailedRollupFail(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	pinID := fftypes.NewUUID()
+	rollup := &fftypes.Operation{
+		ID:    fftypes.NewUUID(),
+		Type:  fftypes.OpTypeBlockchainPinRollup,
+		Input: fftypes.JSONObject{"operations": []string{pinID.String()}},
+	}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, rollup.ID).Return(rollup, nil)
+	mdi.On("GetOperationByID", ctx, pinID).Return(nil, fmt.Errorf("pop"))
+
+	err := om.StepFailed(ctx, rollup.ID)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPrepareCompensationOperation(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	step := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainBatchPin}
+	op := &fftypes.Operation{
+		ID:    fftypes.NewUUID(),
+		Type:  fftypes.OpTypeSagaCompensate,
+		Input: fftypes.JSONObject{"step": step.ID.String()},
+	}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, step.ID).Return(step, nil)
+
+	po, err := om.PrepareOperation(ctx, op)
+	assert.NoError(t, err)
+	assert.Equal(t, opCompensate(op, step), po)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestPrepareCompensationOperationBadInput(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	op := &fftypes.Operation{Type: fftypes.OpTypeSagaCompensate}
+	_, err := om.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestPrepareCompensationOperationStepFail(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	stepID := fftypes.NewUUID()
+	op := &fftypes.Operation{Type: fftypes.OpTypeSagaCompensate, Input: fftypes.JSONObject{"step": stepID.String()}}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, stepID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := om.PrepareOperation(ctx, op)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPrepareCompensationOperationStepNotFound(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	stepID := fftypes.NewUUID()
+	op := &fftypes.Operation{Type: fftypes.OpTypeSagaCompensate, Input: fftypes.JSONObject{"step": stepID.String()}}
+
+	mdi := om.database.(*databasemocks.Plugin)
+	mdi.On("GetOperationByID", ctx, stepID).Return(nil, nil)
+
+	_, err := om.PrepareOperation(ctx, op)
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestRunCompensationOperationBadData(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	_, err := om.saga.RunOperation(context.Background(), &fftypes.PreparedOperation{Type: fftypes.OpTypeSagaCompensate})
+	assert.Regexp(t, "FF10371", err)
+}
+
+func TestRunCompensationOperationNotCompensating(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	ctx := context.Background()
+	om.RegisterHandler(ctx, &mockHandler{}, []fftypes.OpType{fftypes.OpTypeBlockchainBatchPin})
+	step := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeBlockchainBatchPin}
+	op := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeSagaCompensate}
+
+	_, err := om.saga.RunOperation(ctx, opCompensate(op, step))
+	assert.Regexp(t, "FF10371", err)
+}
+
+func TestSagaCoordinatorName(t *testing.T) {
+	om, cancel := newTestOperations(t)
+	defer cancel()
+
+	assert.Equal(t, "SagaCoordinator", om.saga.Name())
+}
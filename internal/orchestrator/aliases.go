@@ -0,0 +1,63 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// SaveAlias creates or replaces an entry in the address book of the namespace
+func (or *orchestrator) SaveAlias(ctx context.Context, ns string, alias *fftypes.Alias) (*fftypes.Alias, error) {
+	alias.Namespace = ns
+	if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	if err := alias.Validate(ctx); err != nil {
+		return nil, err
+	}
+	if err := or.database.UpsertAlias(ctx, alias); err != nil {
+		return nil, err
+	}
+	return alias, nil
+}
+
+func (or *orchestrator) GetAliases(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Alias, *database.FilterResult, error) {
+	filter = or.scopeNS(ns, filter)
+	return or.database.GetAliases(ctx, filter)
+}
+
+func (or *orchestrator) GetAliasByName(ctx context.Context, ns, name string) (*fftypes.Alias, error) {
+	if err := or.verifyNamespaceSyntax(ctx, ns); err != nil {
+		return nil, err
+	}
+	return or.database.GetAliasByName(ctx, ns, name)
+}
+
+func (or *orchestrator) DeleteAlias(ctx context.Context, ns, name string) error {
+	alias, err := or.GetAliasByName(ctx, ns, name)
+	if err != nil {
+		return err
+	}
+	if alias == nil {
+		return i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return or.database.DeleteAlias(ctx, alias.ID)
+}
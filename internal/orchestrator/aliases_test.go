@@ -0,0 +1,104 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSaveAliasOk(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("UpsertAlias", mock.Anything, mock.MatchedBy(func(a *fftypes.Alias) bool {
+		return a.Namespace == "ns1" && a.Name == "partner"
+	})).Return(nil)
+	alias, err := or.SaveAlias(context.Background(), "ns1", &fftypes.Alias{
+		Name:     "partner",
+		Identity: "did:firefly:org/org1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", alias.Namespace)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestSaveAliasBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(fmt.Errorf("pop"))
+	_, err := or.SaveAlias(context.Background(), "ns1", &fftypes.Alias{Name: "partner"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSaveAliasInvalid(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	_, err := or.SaveAlias(context.Background(), "ns1", &fftypes.Alias{Name: "partner"})
+	assert.Regexp(t, "FF10522", err)
+}
+
+func TestSaveAliasFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("UpsertAlias", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := or.SaveAlias(context.Background(), "ns1", &fftypes.Alias{Name: "partner", Key: "0x12345"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetAliases(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetAliases", mock.Anything, mock.Anything).Return([]*fftypes.Alias{}, nil, nil)
+	fb := database.AliasQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("name", "partner"))
+	_, _, err := or.GetAliases(context.Background(), "ns1", f)
+	assert.NoError(t, err)
+}
+
+func TestGetAliasByNameBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetAliasByName(context.Background(), "!bad", "partner")
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestDeleteAliasOk(t *testing.T) {
+	or := newTestOrchestrator()
+	aliasID := fftypes.NewUUID()
+	or.mdi.On("GetAliasByName", mock.Anything, "ns1", "partner").Return(&fftypes.Alias{ID: aliasID}, nil)
+	or.mdi.On("DeleteAlias", mock.Anything, aliasID).Return(nil)
+	err := or.DeleteAlias(context.Background(), "ns1", "partner")
+	assert.NoError(t, err)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestDeleteAliasLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetAliasByName", mock.Anything, "ns1", "partner").Return(nil, fmt.Errorf("pop"))
+	err := or.DeleteAlias(context.Background(), "ns1", "partner")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeleteAliasNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetAliasByName", mock.Anything, "ns1", "partner").Return(nil, nil)
+	err := or.DeleteAlias(context.Background(), "ns1", "partner")
+	assert.Regexp(t, "FF10109", err)
+}
@@ -0,0 +1,188 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"database/sql/driver"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (or *orchestrator) GetBatchManifest(ctx context.Context, ns, id string) (*fftypes.BatchManifestStatus, error) {
+	return or.inspectBatch(ctx, ns, id, false)
+}
+
+// VerifyBatch re-computes the hashes of a stored batch, and of the local copy of each message and data in its
+// manifest, reporting each mismatch that would cause the batch to be rejected
+func (or *orchestrator) VerifyBatch(ctx context.Context, ns, id string) (*fftypes.BatchManifestStatus, error) {
+	return or.inspectBatch(ctx, ns, id, true)
+}
+
+func (or *orchestrator) inspectBatch(ctx context.Context, ns, id string, verify bool) (*fftypes.BatchManifestStatus, error) {
+	u, err := or.verifyIDAndNamespace(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := or.database.GetBatchByID(ctx, u)
+	if err != nil || batch == nil || batch.Namespace != ns {
+		return nil, err
+	}
+	var manifest *fftypes.BatchManifest
+	if batch.Manifest == nil || batch.Manifest.Unmarshal(ctx, &manifest) != nil || manifest == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgBatchManifestInvalid, batch.ID)
+	}
+
+	status := &fftypes.BatchManifestStatus{
+		ID:           batch.ID,
+		Type:         batch.Type,
+		Namespace:    batch.Namespace,
+		TX:           batch.TX,
+		Hash:         batch.Hash,
+		ManifestHash: fftypes.HashString(batch.Manifest.String()),
+		Messages:     make([]*fftypes.ManifestMessageStatus, len(manifest.Messages)),
+		Data:         make([]*fftypes.ManifestDataStatus, len(manifest.Data)),
+	}
+	valid := status.ManifestHash.Equals(batch.Hash)
+
+	msgs, err := or.manifestMessages(ctx, manifest)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range manifest.Messages {
+		ms := &fftypes.ManifestMessageStatus{MessageManifestEntry: *entry}
+		msg := msgs[*entry.ID]
+		if msg != nil {
+			ms.State = msg.State
+			ms.LocalHash = msg.Hash
+		}
+		if verify {
+			if err := verifyManifestMessage(ctx, entry, msg); err != nil {
+				ms.Error = err.Error()
+				valid = false
+			}
+		}
+		status.Messages[i] = ms
+	}
+
+	data, err := or.manifestData(ctx, manifest, verify)
+	if err != nil {
+		return nil, err
+	}
+	for i, entry := range manifest.Data {
+		ds := &fftypes.ManifestDataStatus{DataRef: *entry}
+		d := data[*entry.ID]
+		if d != nil {
+			ds.LocalHash = d.Hash
+		}
+		if verify {
+			if err := verifyManifestData(ctx, entry, d); err != nil {
+				ds.Error = err.Error()
+				valid = false
+			}
+		}
+		status.Data[i] = ds
+	}
+
+	if verify {
+		status.Valid = &valid
+	}
+	return status, nil
+}
+
+func (or *orchestrator) manifestMessages(ctx context.Context, manifest *fftypes.BatchManifest) (map[fftypes.UUID]*fftypes.Message, error) {
+	msgs := make(map[fftypes.UUID]*fftypes.Message, len(manifest.Messages))
+	if len(manifest.Messages) == 0 {
+		return msgs, nil
+	}
+	ids := make([]driver.Value, len(manifest.Messages))
+	for i, entry := range manifest.Messages {
+		ids[i] = entry.ID
+	}
+	fb := database.MessageQueryFactory.NewFilter(ctx)
+	results, _, err := or.database.GetMessages(ctx, fb.In("id", ids))
+	if err != nil {
+		return nil, err
+	}
+	for _, msg := range results {
+		msgs[*msg.Header.ID] = msg
+	}
+	return msgs, nil
+}
+
+// manifestData reads the local copy of each data item in the manifest. The values are only needed
+// to re-compute the hashes when verifying.
+func (or *orchestrator) manifestData(ctx context.Context, manifest *fftypes.BatchManifest, withValues bool) (map[fftypes.UUID]*fftypes.Data, error) {
+	data := make(map[fftypes.UUID]*fftypes.Data, len(manifest.Data))
+	if len(manifest.Data) == 0 {
+		return data, nil
+	}
+	ids := make([]driver.Value, len(manifest.Data))
+	for i, entry := range manifest.Data {
+		ids[i] = entry.ID
+	}
+	fb := database.DataQueryFactory.NewFilter(ctx)
+	filter := fb.In("id", ids)
+	if withValues {
+		results, _, err := or.database.GetData(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range results {
+			data[*d.ID] = d
+		}
+		return data, nil
+	}
+	refs, _, err := or.database.GetDataRefs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range refs {
+		data[*ref.ID] = &fftypes.Data{ID: ref.ID, Hash: ref.Hash}
+	}
+	return data, nil
+}
+
+func verifyManifestMessage(ctx context.Context, entry *fftypes.MessageManifestEntry, msg *fftypes.Message) error {
+	switch {
+	case msg == nil:
+		return i18n.NewError(ctx, i18n.MsgManifestEntryNotFound)
+	case !msg.Hash.Equals(entry.Hash):
+		return i18n.NewError(ctx, i18n.MsgManifestEntryHashMismatch, msg.Hash, entry.Hash)
+	default:
+		return msg.Verify(ctx)
+	}
+}
+
+func verifyManifestData(ctx context.Context, entry *fftypes.DataRef, d *fftypes.Data) error {
+	switch {
+	case d == nil:
+		return i18n.NewError(ctx, i18n.MsgManifestEntryNotFound)
+	case !d.Hash.Equals(entry.Hash):
+		return i18n.NewError(ctx, i18n.MsgManifestEntryHashMismatch, d.Hash, entry.Hash)
+	}
+	hash, err := d.CalcHash(ctx)
+	if err != nil {
+		return err
+	}
+	if !hash.Equals(d.Hash) {
+		return i18n.NewError(ctx, i18n.MsgDataHashInvalid, hash, d.Hash)
+	}
+	return nil
+}
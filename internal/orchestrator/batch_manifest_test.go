@@ -0,0 +1,219 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestManifestBatch(t *testing.T, msgCount, dataCount int) (*fftypes.BatchPersisted, []*fftypes.Message, fftypes.DataArray) {
+	data := make(fftypes.DataArray, dataCount)
+	for i := range data {
+		data[i] = &fftypes.Data{Value: fftypes.JSONAnyPtr(fmt.Sprintf(`"value%d"`, i))}
+		assert.NoError(t, data[i].Seal(context.Background(), nil))
+	}
+	msgs := make([]*fftypes.Message, msgCount)
+	for i := range msgs {
+		msgs[i] = &fftypes.Message{
+			Header: fftypes.MessageHeader{Namespace: "ns1"},
+			State:  fftypes.MessageStateConfirmed,
+		}
+		if i < len(data) {
+			msgs[i].Data = fftypes.DataRefs{{ID: data[i].ID, Hash: data[i].Hash}}
+		}
+		assert.NoError(t, msgs[i].Seal(context.Background()))
+	}
+	batch := &fftypes.Batch{
+		BatchHeader: fftypes.BatchHeader{
+			ID:        fftypes.NewUUID(),
+			Type:      fftypes.BatchTypeBroadcast,
+			Namespace: "ns1",
+		},
+		Payload: fftypes.BatchPayload{
+			TX:       fftypes.TransactionRef{Type: fftypes.TransactionTypeBatchPin, ID: fftypes.NewUUID()},
+			Messages: msgs,
+			Data:     data,
+		},
+	}
+	bp, _ := batch.Confirmed()
+	bp.Hash = fftypes.HashString(bp.Manifest.String())
+	return bp, msgs, data
+}
+
+func TestGetBatchManifest(t *testing.T) {
+	or := newTestOrchestrator()
+	bp, msgs, data := newTestManifestBatch(t, 2, 2)
+
+	or.mdi.On("GetBatchByID", mock.Anything, bp.ID).Return(bp, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(msgs[0:1], nil, nil)
+	or.mdi.On("GetDataRefs", mock.Anything, mock.Anything).Return(fftypes.DataRefs{{ID: data[1].ID, Hash: data[1].Hash}}, nil, nil)
+
+	status, err := or.GetBatchManifest(or.ctx, "ns1", bp.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, bp.Hash, status.ManifestHash)
+	assert.Nil(t, status.Valid)
+	assert.Len(t, status.Messages, 2)
+	assert.Equal(t, msgs[0].Header.ID, status.Messages[0].ID)
+	assert.Equal(t, fftypes.MessageStateConfirmed, status.Messages[0].State)
+	assert.Equal(t, msgs[0].Hash, status.Messages[0].LocalHash)
+	assert.Empty(t, status.Messages[1].State)
+	assert.Nil(t, status.Messages[1].LocalHash)
+	assert.Len(t, status.Data, 2)
+	assert.Nil(t, status.Data[0].LocalHash)
+	assert.Equal(t, data[1].Hash, status.Data[1].LocalHash)
+	assert.Empty(t, status.Data[1].Error)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetBatchManifestEmpty(t *testing.T) {
+	or := newTestOrchestrator()
+	bp, _, _ := newTestManifestBatch(t, 0, 0)
+
+	or.mdi.On("GetBatchByID", mock.Anything, bp.ID).Return(bp, nil)
+
+	status, err := or.GetBatchManifest(or.ctx, "ns1", bp.ID.String())
+	assert.NoError(t, err)
+	assert.Empty(t, status.Messages)
+	assert.Empty(t, status.Data)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestGetBatchManifestBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetBatchManifest(or.ctx, "ns1", "!uuid")
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestGetBatchManifestFail(t *testing.T) {
+	or := newTestOrchestrator()
+	id := fftypes.NewUUID()
+	or.mdi.On("GetBatchByID", mock.Anything, id).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetBatchManifest(or.ctx, "ns1", id.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBatchManifestOtherNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	bp, _, _ := newTestManifestBatch(t, 1, 1)
+	or.mdi.On("GetBatchByID", mock.Anything, bp.ID).Return(bp, nil)
+	status, err := or.GetBatchManifest(or.ctx, "ns2", bp.ID.String())
+	assert.NoError(t, err)
+	assert.Nil(t, status)
+}
+
+func TestGetBatchManifestInvalid(t *testing.T) {
+	or := newTestOrchestrator()
+	bp, _, _ := newTestManifestBatch(t, 1, 1)
+	bp.Manifest = fftypes.JSONAnyPtr("!json")
+	or.mdi.On("GetBatchByID", mock.Anything, bp.ID).Return(bp, nil)
+	_, err := or.GetBatchManifest(or.ctx, "ns1", bp.ID.String())
+	assert.Regexp(t, "FF10516", err)
+}
+
+func TestGetBatchManifestGetMessagesFail(t *testing.T) {
+	or := newTestOrchestrator()
+	bp, _, _ := newTestManifestBatch(t, 1, 1)
+	or.mdi.On("GetBatchByID", mock.Anything, bp.ID).Return(bp, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetBatchManifest(or.ctx, "ns1", bp.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetBatchManifestGetDataRefsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	bp, msgs, _ := newTestManifestBatch(t, 1, 1)
+	or.mdi.On("GetBatchByID", mock.Anything, bp.ID).Return(bp, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(msgs, nil, nil)
+	or.mdi.On("GetDataRefs", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetBatchManifest(or.ctx, "ns1", bp.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestVerifyBatchValid(t *testing.T) {
+	or := newTestOrchestrator()
+	bp, msgs, data := newTestManifestBatch(t, 2, 2)
+
+	or.mdi.On("GetBatchByID", mock.Anything, bp.ID).Return(bp, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(msgs, nil, nil)
+	or.mdi.On("GetData", mock.Anything, mock.Anything).Return(data, nil, nil)
+
+	status, err := or.VerifyBatch(or.ctx, "ns1", bp.ID.String())
+	assert.NoError(t, err)
+	assert.True(t, *status.Valid)
+	for _, ms := range status.Messages {
+		assert.Empty(t, ms.Error)
+	}
+	for _, ds := range status.Data {
+		assert.Empty(t, ds.Error)
+	}
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestVerifyBatchMismatches(t *testing.T) {
+	or := newTestOrchestrator()
+	bp, msgs, data := newTestManifestBatch(t, 3, 4)
+	bp.Hash = fftypes.NewRandB32()
+
+	// Message 0 is missing, message 1 has a different hash, and message 2 fails verification
+	localMsgs := []*fftypes.Message{
+		{Header: msgs[1].Header, Hash: fftypes.NewRandB32()},
+		{Header: msgs[2].Header, Hash: msgs[2].Hash, Data: fftypes.DataRefs{}},
+	}
+	// Data 0 is missing, data 1 has a different hash, data 2 has a value that does not match its hash,
+	// and data 3 has no value to compute the hash from
+	localData := fftypes.DataArray{
+		{ID: data[1].ID, Hash: fftypes.NewRandB32()},
+		{ID: data[2].ID, Hash: data[2].Hash, Value: fftypes.JSONAnyPtr(`"changed"`)},
+		{ID: data[3].ID, Hash: data[3].Hash},
+	}
+	or.mdi.On("GetBatchByID", mock.Anything, bp.ID).Return(bp, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(localMsgs, nil, nil)
+	or.mdi.On("GetData", mock.Anything, mock.Anything).Return(localData, nil, nil)
+
+	status, err := or.VerifyBatch(or.ctx, "ns1", bp.ID.String())
+	assert.NoError(t, err)
+	assert.False(t, *status.Valid)
+	assert.NotEqual(t, bp.Hash, status.ManifestHash)
+	assert.Regexp(t, "FF10517", status.Messages[0].Error)
+	assert.Regexp(t, "FF10518", status.Messages[1].Error)
+	assert.Regexp(t, "FF10146", status.Messages[2].Error)
+	assert.Regexp(t, "FF10517", status.Data[0].Error)
+	assert.Regexp(t, "FF10518", status.Data[1].Error)
+	assert.Regexp(t, "FF10519", status.Data[2].Error)
+	assert.Regexp(t, "FF10199", status.Data[3].Error)
+
+	or.mdi.AssertExpectations(t)
+}
+
+func TestVerifyBatchGetDataFail(t *testing.T) {
+	or := newTestOrchestrator()
+	bp, msgs, _ := newTestManifestBatch(t, 1, 1)
+	or.mdi.On("GetBatchByID", mock.Anything, bp.ID).Return(bp, nil)
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(msgs, nil, nil)
+	or.mdi.On("GetData", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.VerifyBatch(or.ctx, "ns1", bp.ID.String())
+	assert.EqualError(t, err, "pop")
+}
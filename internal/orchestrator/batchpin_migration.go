@@ -0,0 +1,34 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// GetBatchPinMigration returns the status of a configured move to a new BatchPin contract, or nil if there is none
+func (or *orchestrator) GetBatchPinMigration(ctx context.Context) *fftypes.BatchPinMigration {
+	return or.blockchain.GetBatchPinMigration(ctx)
+}
+
+// ActivateBatchPinMigration submits batch pins to the new BatchPin contract from now on,
+// without waiting for the activation block to be observed
+func (or *orchestrator) ActivateBatchPinMigration(ctx context.Context) (*fftypes.BatchPinMigration, error) {
+	return or.blockchain.ActivateBatchPinMigration(ctx)
+}
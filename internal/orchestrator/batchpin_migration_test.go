@@ -0,0 +1,42 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetBatchPinMigration(t *testing.T) {
+	or := newTestOrchestrator()
+	status := &fftypes.BatchPinMigration{From: "0x123", To: "0x456", ActivationBlock: 100}
+	or.mbi.On("GetBatchPinMigration", mock.Anything).Return(status)
+	assert.Equal(t, status, or.GetBatchPinMigration(context.Background()))
+}
+
+func TestActivateBatchPinMigration(t *testing.T) {
+	or := newTestOrchestrator()
+	status := &fftypes.BatchPinMigration{From: "0x123", To: "0x456", ActivationBlock: 100, Active: true}
+	or.mbi.On("ActivateBatchPinMigration", mock.Anything).Return(status, nil)
+	res, err := or.ActivateBatchPinMigration(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, status, res)
+}
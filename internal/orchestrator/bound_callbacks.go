@@ -18,6 +18,7 @@ package orchestrator
 
 import (
 	"github.com/hyperledger/firefly/internal/events"
+	"github.com/hyperledger/firefly/internal/replay"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/dataexchange"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -28,10 +29,11 @@ type boundCallbacks struct {
 	bi blockchain.Plugin
 	dx dataexchange.Plugin
 	ei events.EventManager
+	rl replay.Manager
 }
 
-func (bc *boundCallbacks) BlockchainOpUpdate(operationID *fftypes.UUID, txState blockchain.TransactionStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
-	return bc.ei.OperationUpdate(bc.bi, operationID, txState, blockchainTXID, errorMessage, opOutput)
+func (bc *boundCallbacks) BlockchainOpUpdate(operationID *fftypes.UUID, tx *fftypes.BlockchainTransaction, errorMessage string, opOutput fftypes.JSONObject, receipt *fftypes.BlockchainReceipt) error {
+	return bc.ei.BlockchainOpUpdate(bc.bi, operationID, tx, errorMessage, opOutput, receipt)
 }
 
 func (bc *boundCallbacks) TokenOpUpdate(plugin tokens.Plugin, operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error {
@@ -39,6 +41,10 @@ func (bc *boundCallbacks) TokenOpUpdate(plugin tokens.Plugin, operationID *fftyp
 }
 
 func (bc *boundCallbacks) BatchPinComplete(batch *blockchain.BatchPin, signingKey *fftypes.VerifierRef) error {
+	if bc.rl.Replaying() {
+		return nil // only the events in the replay log are processed in debug mode
+	}
+	bc.rl.RecordBatchPin(batch, signingKey)
 	return bc.ei.BatchPinComplete(bc.bi, batch, signingKey)
 }
 
@@ -47,10 +53,18 @@ func (bc *boundCallbacks) TransferResult(trackingID string, status fftypes.OpSta
 }
 
 func (bc *boundCallbacks) BLOBReceived(peerID string, hash fftypes.Bytes32, size int64, payloadRef string) error {
+	if bc.rl.Replaying() {
+		return nil
+	}
+	bc.rl.RecordBLOB(peerID, hash, size, payloadRef)
 	return bc.ei.BLOBReceived(bc.dx, peerID, hash, size, payloadRef)
 }
 
 func (bc *boundCallbacks) MessageReceived(peerID string, data []byte) (manifest string, err error) {
+	if bc.rl.Replaying() {
+		return "", nil
+	}
+	bc.rl.RecordMessage(peerID, data)
 	return bc.ei.MessageReceived(bc.dx, peerID, data)
 }
 
@@ -66,6 +80,10 @@ func (bc *boundCallbacks) BlockchainEvent(event *blockchain.EventWithSubscriptio
 	return bc.ei.BlockchainEvent(event)
 }
 
+func (bc *boundCallbacks) ChainReorg(reorg *blockchain.Reorg) error {
+	return bc.ei.ChainReorg(bc.bi, reorg)
+}
+
 func (bc *boundCallbacks) TokensApproved(plugin tokens.Plugin, approval *tokens.TokenApproval) error {
 	return bc.ei.TokensApproved(plugin, approval)
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/eventmocks"
+	"github.com/hyperledger/firefly/mocks/replaymocks"
 	"github.com/hyperledger/firefly/mocks/tokenmocks"
 	"github.com/hyperledger/firefly/pkg/blockchain"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -36,7 +37,8 @@ func TestBoundCallbacks(t *testing.T) {
 	mbi := &blockchainmocks.Plugin{}
 	mdx := &dataexchangemocks.Plugin{}
 	mti := &tokenmocks.Plugin{}
-	bc := boundCallbacks{bi: mbi, dx: mdx, ei: mei}
+	mrl := &replaymocks.Manager{}
+	bc := boundCallbacks{bi: mbi, dx: mdx, ei: mei, rl: mrl}
 
 	info := fftypes.JSONObject{"hello": "world"}
 	batch := &blockchain.BatchPin{TransactionID: fftypes.NewUUID()}
@@ -46,12 +48,16 @@ func TestBoundCallbacks(t *testing.T) {
 	hash := fftypes.NewRandB32()
 	opID := fftypes.NewUUID()
 
+	mrl.On("Replaying").Return(false)
+	mrl.On("RecordBatchPin", batch, &fftypes.VerifierRef{Value: "0x12345", Type: fftypes.VerifierTypeEthAddress}).Return()
 	mei.On("BatchPinComplete", mbi, batch, &fftypes.VerifierRef{Value: "0x12345", Type: fftypes.VerifierTypeEthAddress}).Return(fmt.Errorf("pop"))
 	err := bc.BatchPinComplete(batch, &fftypes.VerifierRef{Value: "0x12345", Type: fftypes.VerifierTypeEthAddress})
 	assert.EqualError(t, err, "pop")
 
-	mei.On("OperationUpdate", mbi, opID, fftypes.OpStatusFailed, "0xffffeeee", "error info", info).Return(fmt.Errorf("pop"))
-	err = bc.BlockchainOpUpdate(opID, fftypes.OpStatusFailed, "0xffffeeee", "error info", info)
+	receipt := &fftypes.BlockchainReceipt{RevertReason: "error info"}
+	blockchainTX := &fftypes.BlockchainTransaction{ID: "0xffffeeee", Status: fftypes.OpStatusFailed}
+	mei.On("BlockchainOpUpdate", mbi, opID, blockchainTX, "error info", info, receipt).Return(fmt.Errorf("pop"))
+	err = bc.BlockchainOpUpdate(opID, blockchainTX, "error info", info, receipt)
 	assert.EqualError(t, err, "pop")
 
 	mei.On("OperationUpdate", mti, opID, fftypes.OpStatusFailed, "0xffffeeee", "error info", info).Return(fmt.Errorf("pop"))
@@ -64,10 +70,12 @@ func TestBoundCallbacks(t *testing.T) {
 	})
 	assert.EqualError(t, err, "pop")
 
+	mrl.On("RecordBLOB", "peer1", *hash, int64(12345), "ns1/id1").Return()
 	mei.On("BLOBReceived", mdx, "peer1", *hash, int64(12345), "ns1/id1").Return(fmt.Errorf("pop"))
 	err = bc.BLOBReceived("peer1", *hash, 12345, "ns1/id1")
 	assert.EqualError(t, err, "pop")
 
+	mrl.On("RecordMessage", "peer1", []byte{}).Return()
 	mei.On("MessageReceived", mdx, "peer1", []byte{}).Return("manifest data", fmt.Errorf("pop"))
 	_, err = bc.MessageReceived("peer1", []byte{})
 	assert.EqualError(t, err, "pop")
@@ -87,4 +95,28 @@ func TestBoundCallbacks(t *testing.T) {
 	mei.On("BlockchainEvent", mock.AnythingOfType("*blockchain.EventWithSubscription")).Return(fmt.Errorf("pop"))
 	err = bc.BlockchainEvent(&blockchain.EventWithSubscription{})
 	assert.EqualError(t, err, "pop")
+
+	reorg := &blockchain.Reorg{BlockNumber: 12345}
+	mei.On("ChainReorg", mbi, reorg).Return(fmt.Errorf("pop"))
+	err = bc.ChainReorg(reorg)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestBoundCallbacksReplaying(t *testing.T) {
+	mei := &eventmocks.EventManager{}
+	mrl := &replaymocks.Manager{}
+	bc := boundCallbacks{ei: mei, rl: mrl}
+
+	mrl.On("Replaying").Return(true)
+
+	err := bc.BatchPinComplete(&blockchain.BatchPin{}, &fftypes.VerifierRef{})
+	assert.NoError(t, err)
+	err = bc.BLOBReceived("peer1", *fftypes.NewRandB32(), 12345, "ns1/id1")
+	assert.NoError(t, err)
+	manifest, err := bc.MessageReceived("peer1", []byte{})
+	assert.NoError(t, err)
+	assert.Empty(t, manifest)
+
+	mrl.AssertExpectations(t)
+	mei.AssertExpectations(t)
 }
@@ -0,0 +1,111 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// changeCollections is the collection of the resource referred to by each type of event
+var changeCollections = map[fftypes.EventType]string{
+	fftypes.EventTypeTransactionSubmitted:        string(database.CollectionTransactions),
+	fftypes.EventTypeMessageConfirmed:            string(database.CollectionMessages),
+	fftypes.EventTypeMessageRejected:             string(database.CollectionMessages),
+	fftypes.EventTypeMessageReorged:              string(database.CollectionMessages),
+	fftypes.EventTypeMessageAnchored:             string(database.CollectionMessages),
+	fftypes.EventTypeMessageQuarantined:          string(database.CollectionMessages),
+	fftypes.EventTypeMessageSchemaDrift:          string(database.CollectionMessages),
+	fftypes.EventTypeBlobQuarantined:             string(database.CollectionMessages),
+	fftypes.EventTypeCanaryPassed:                string(database.CollectionMessages),
+	fftypes.EventTypeCanaryFailed:                string(database.CollectionMessages),
+	fftypes.EventTypeBatchAssembled:              string(database.CollectionBatches),
+	fftypes.EventTypeBatchSealed:                 string(database.CollectionBatches),
+	fftypes.EventTypeBatchDispatched:             string(database.CollectionBatches),
+	fftypes.EventTypeBatchPinSubmitted:           string(database.CollectionBatches),
+	fftypes.EventTypeBatchConfirmed:              string(database.CollectionBatches),
+	fftypes.EventTypeNamespaceConfirmed:          string(database.CollectionNamespaces),
+	fftypes.EventTypeDatatypeConfirmed:           string(database.CollectionDataTypes),
+	fftypes.EventTypeIdentityConfirmed:           string(database.CollectionIdentities),
+	fftypes.EventTypeIdentityEndorsed:            string(database.CollectionIdentities),
+	fftypes.EventTypeIdentityUpdated:             string(database.CollectionIdentities),
+	fftypes.EventTypePoolConfirmed:               string(database.CollectionTokenPools),
+	fftypes.EventTypeTransferConfirmed:           string(database.CollectionTokenTransfers),
+	fftypes.EventTypeTransferOpFailed:            string(database.CollectionOperations),
+	fftypes.EventTypeApprovalConfirmed:           string(database.CollectionTokenApprovals),
+	fftypes.EventTypeApprovalOpFailed:            string(database.CollectionOperations),
+	fftypes.EventTypeTokenMetadataUpdated:        string(database.CollectionTokenMetadata),
+	fftypes.EventTypeContractInterfaceConfirmed:  string(database.CollectionFFIs),
+	fftypes.EventTypeContractAPIConfirmed:        string(database.CollectionContractAPIs),
+	fftypes.EventTypeBlockchainEventReceived:     string(database.CollectionBlockchainEvents),
+	fftypes.EventTypeGovernanceProposalConfirmed: string(database.CollectionGovernanceProposals),
+	fftypes.EventTypeGovernanceProposalApproved:  string(database.CollectionGovernanceProposals),
+	fftypes.EventTypeGovernanceProposalRejected:  string(database.CollectionGovernanceProposals),
+	fftypes.EventTypeGovernanceVoteConfirmed:     string(database.CollectionGovernanceVotes),
+	fftypes.EventTypeInvitationRedeemed:          string(database.CollectionInvitations),
+}
+
+// GetChanges returns the changes in a namespace after the given sequence, derived from the events
+// recorded for each change. Clients sync incrementally by passing the returned next sequence back in.
+func (or *orchestrator) GetChanges(ctx context.Context, ns string, since int64, limit int) (*fftypes.ChangeFeed, error) {
+	if err := or.verifyNamespaceSyntax(ctx, ns); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = config.GetInt(config.APIDefaultFilterLimit)
+	}
+	maxLimit := config.GetInt(config.APIMaxFilterLimit)
+	if limit > maxLimit {
+		return nil, i18n.NewError(ctx, i18n.MsgMaxFilterLimit, maxLimit)
+	}
+
+	fb := database.EventQueryFactory.NewFilter(ctx)
+	filter := fb.And(
+		fb.Gt("sequence", since),
+		fb.Eq("namespace", ns),
+	).Sort("sequence").Limit(uint64(limit))
+	events, _, err := or.database.GetEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &fftypes.ChangeFeed{
+		Changes: make([]*fftypes.ChangeFeedEntry, len(events)),
+		Next:    since,
+	}
+	for i, event := range events {
+		entry := &fftypes.ChangeFeedEntry{
+			Sequence: event.Sequence,
+			Event:    event.Type,
+		}
+		if collection, ok := changeCollections[event.Type]; ok {
+			entry.Collection = collection
+			entry.ID = event.Reference
+		} else {
+			// Any other event is reported as a change to the event itself
+			entry.Collection = string(database.CollectionEvents)
+			entry.ID = event.ID
+		}
+		feed.Changes[i] = entry
+		feed.Next = event.Sequence
+	}
+	return feed, nil
+}
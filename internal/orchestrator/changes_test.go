@@ -0,0 +1,81 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetChanges(t *testing.T) {
+	or := newTestOrchestrator()
+	msgID := fftypes.NewUUID()
+	eventID := fftypes.NewUUID()
+	or.mdi.On("GetEvents", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 25 && fi.Sort[0].Field == "sequence" && !fi.Sort[0].Descending
+	})).Return([]*fftypes.Event{
+		{ID: fftypes.NewUUID(), Sequence: 101, Type: fftypes.EventTypeMessageConfirmed, Reference: msgID},
+		{ID: eventID, Sequence: 102, Type: fftypes.EventType("custom"), Reference: fftypes.NewUUID()},
+	}, nil, nil)
+
+	feed, err := or.GetChanges(context.Background(), "ns1", 100, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(102), feed.Next)
+	assert.Equal(t, &fftypes.ChangeFeedEntry{
+		Sequence: 101, Collection: "messages", ID: msgID, Event: fftypes.EventTypeMessageConfirmed,
+	}, feed.Changes[0])
+	assert.Equal(t, "events", feed.Changes[1].Collection)
+	assert.Equal(t, eventID, feed.Changes[1].ID)
+}
+
+func TestGetChangesNone(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+
+	feed, err := or.GetChanges(context.Background(), "ns1", 100, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), feed.Next)
+	assert.Empty(t, feed.Changes)
+}
+
+func TestGetChangesBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetChanges(context.Background(), "!wrong", 0, 10)
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestGetChangesLimitTooLarge(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.APIMaxFilterLimit, 10)
+	_, err := or.GetChanges(context.Background(), "ns1", 0, 11)
+	assert.Regexp(t, "FF10184", err)
+}
+
+func TestGetChangesFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetChanges(context.Background(), "ns1", 0, 10)
+	assert.EqualError(t, err, "pop")
+}
@@ -18,12 +18,107 @@ package orchestrator
 
 import (
 	"context"
+	"strings"
 
+	"github.com/hyperledger/firefly/internal/blockchain/bifactory"
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/database/difactory"
+	"github.com/hyperledger/firefly/internal/dataexchange/dxfactory"
+	"github.com/hyperledger/firefly/internal/events/eifactory"
+	"github.com/hyperledger/firefly/internal/gateway"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/identity/iifactory"
+	"github.com/hyperledger/firefly/internal/notifications"
+	"github.com/hyperledger/firefly/internal/scanning/scfactory"
+	"github.com/hyperledger/firefly/internal/sharedstorage/ssfactory"
+	"github.com/hyperledger/firefly/internal/tokens/tifactory"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/tokens"
 )
 
+// InitConfig registers the configuration of every plugin known to the factories,
+// so that all keys are known before the configuration is loaded and validated
+func InitConfig() {
+	bifactory.InitPrefix(blockchainConfig)
+	difactory.InitPrefix(databaseConfig)
+	ssfactory.InitPrefix(sharedstorageConfig)
+	// For backward compatibility also init with the old "publicstorage" prefix
+	ssfactory.InitPrefix(publicstorageConfig)
+	dxfactory.InitPrefix(dataexchangeConfig)
+	tifactory.InitPrefix(tokensConfig)
+	iifactory.InitPrefix(identityConfig)
+	eifactory.InitPrefix(eventsConfig)
+	scfactory.InitPrefix(scanningConfig)
+	notifications.InitConfig()
+	gateway.InitConfig()
+}
+
+// ValidatePluginConfig checks each plugin selection in the configuration refers to a known plugin,
+// and that a configuration section exists for those plugins that cannot run without one
+func ValidatePluginConfig(ctx context.Context) (problems []error) {
+	checkSection := func(typeKey config.RootKey, pluginType string, getPlugin func() error) {
+		if pluginType == "" {
+			problems = append(problems, i18n.NewError(ctx, i18n.MsgConfigPluginTypeMissing, typeKey))
+		} else if err := getPlugin(); err != nil {
+			problems = append(problems, err)
+		} else if section := strings.TrimSuffix(string(typeKey), ".type") + "." + pluginType; !config.HasSection(section) {
+			problems = append(problems, i18n.NewError(ctx, i18n.MsgConfigPluginSectionMissing, section, pluginType))
+		}
+	}
+
+	biType := config.GetString(config.BlockchainType)
+	checkSection(config.BlockchainType, biType, func() (err error) { _, err = bifactory.GetPlugin(ctx, biType); return err })
+
+	diType := config.GetString(config.DatabaseType)
+	checkSection(config.DatabaseType, diType, func() (err error) { _, err = difactory.GetPlugin(ctx, diType); return err })
+
+	dxType := config.GetString(config.DataexchangeType)
+	checkSection(config.DataexchangeType, dxType, func() (err error) {
+		pluginName := dxType
+		if pluginName == "https" {
+			pluginName = "ffdx"
+		}
+		_, err = dxfactory.GetPlugin(ctx, pluginName)
+		return err
+	})
+
+	ssTypeKey := config.SharedStorageType
+	ssType := config.GetString(ssTypeKey)
+	if ssType == "" && config.GetString(config.PublicStorageType) != "" {
+		ssTypeKey = config.PublicStorageType
+		ssType = config.GetString(ssTypeKey)
+	}
+	checkSection(ssTypeKey, ssType, func() (err error) { _, err = ssfactory.GetPlugin(ctx, ssType); return err })
+
+	if _, err := iifactory.GetPlugin(ctx, config.GetString(config.IdentityType)); err != nil {
+		problems = append(problems, err)
+	}
+
+	// Blob scanning is optional, but when enabled the plugin must be known and configured
+	if scType := config.GetString(config.ScanningType); scType != "" {
+		checkSection(config.ScanningType, scType, func() (err error) { _, err = scfactory.GetPlugin(ctx, scType); return err })
+	}
+
+	tokensConfigArraySize := tokensConfig.ArraySize()
+	for i := 0; i < tokensConfigArraySize; i++ {
+		prefix := tokensConfig.ArrayEntry(i)
+		pluginName := prefix.GetString(tokens.TokensConfigPlugin)
+		if pluginName == "" {
+			pluginName = prefix.GetString(tokens.TokensConfigConnector)
+		}
+		if pluginName == "https" {
+			pluginName = "fftokens"
+		}
+		if prefix.GetString(tokens.TokensConfigName) == "" || pluginName == "" {
+			problems = append(problems, i18n.NewError(ctx, i18n.MsgMissingTokensPluginConfig))
+		} else if _, err := tifactory.GetPlugin(ctx, pluginName); err != nil {
+			problems = append(problems, err)
+		}
+	}
+
+	return problems
+}
 func (or *orchestrator) GetConfig(ctx context.Context) fftypes.JSONObject {
 	return config.GetConfig()
 }
@@ -19,10 +19,12 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -98,3 +100,73 @@ func TestResetConfig(t *testing.T) {
 	cancelFunc()
 	<-or.ctx.Done()
 }
+
+func readTestConfig(t *testing.T, yaml string) {
+	config.Reset()
+	InitConfig()
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(yaml))
+	assert.NoError(t, err)
+}
+
+func TestValidatePluginConfigOk(t *testing.T) {
+	readTestConfig(t, `
+blockchain:
+  type: ethereum
+  ethereum:
+    ethconnect:
+      url: http://localhost:8080
+database:
+  type: postgres
+  postgres:
+    url: postgres://localhost
+dataexchange:
+  type: https
+  https:
+    url: http://localhost:5000
+publicstorage:
+  type: ipfs
+  ipfs:
+    api:
+      url: http://localhost:5001
+scanning:
+  type: clamav
+  clamav:
+    address: localhost:3310
+tokens:
+- name: erc1155
+  connector: https
+`)
+	assert.Empty(t, ValidatePluginConfig(context.Background()))
+}
+
+func TestValidatePluginConfigProblems(t *testing.T) {
+	readTestConfig(t, `
+blockchain:
+  type: wrong
+database:
+  type: postgres
+dataexchange:
+  type: ""
+sharedstorage:
+  type: ipfs
+identity:
+  type: wrong
+scanning:
+  type: icap
+tokens:
+- name: erc1155
+- name: erc20
+  plugin: wrong
+`)
+	problems := ValidatePluginConfig(context.Background())
+	assert.Len(t, problems, 8)
+	assert.Regexp(t, "FF10110.*wrong", problems[0])
+	assert.Regexp(t, "FF10382.*database.postgres", problems[1])
+	assert.Regexp(t, "FF10381.*dataexchange.type", problems[2])
+	assert.Regexp(t, "FF10382.*sharedstorage.ipfs", problems[3])
+	assert.Regexp(t, "FF10212.*wrong", problems[4])
+	assert.Regexp(t, "FF10382.*scanning.icap", problems[5])
+	assert.Regexp(t, "FF10273", problems[6])
+	assert.Regexp(t, "FF10272.*wrong", problems[7])
+}
@@ -19,7 +19,10 @@ package orchestrator
 import (
 	"context"
 	"database/sql/driver"
+	"sort"
+	"strings"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -183,6 +186,11 @@ func (or *orchestrator) GetMessagesWithData(ctx context.Context, ns string, filt
 	return msgsData, fr, err
 }
 
+func (or *orchestrator) GetMessageStates(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.MessageStateRecord, *database.FilterResult, error) {
+	filter = or.scopeNS(ns, filter)
+	return or.database.GetMessageStates(ctx, filter)
+}
+
 func (or *orchestrator) GetMessageData(ctx context.Context, ns, id string) (fftypes.DataArray, error) {
 	msg, err := or.getMessageByID(ctx, ns, id)
 	if err != nil || msg == nil {
@@ -192,31 +200,98 @@ func (or *orchestrator) GetMessageData(ctx context.Context, ns, id string) (ffty
 	return data, err
 }
 
-func (or *orchestrator) getMessageTransactionID(ctx context.Context, ns, id string) (*fftypes.UUID, error) {
+var messageIncludes = map[string]bool{"data": true, "transaction": true, "events": true}
+
+// GetMessageByIDWithIncludes returns a message with the requested related records embedded, saving the
+// caller a round trip for each. Data is always returned as references unless "data" is included.
+func (or *orchestrator) GetMessageByIDWithIncludes(ctx context.Context, ns, id string, includes []string) (*fftypes.MessageWithIncludes, error) {
+	include := make(map[string]bool)
+	for _, inc := range includes {
+		inc = strings.ToLower(strings.TrimSpace(inc))
+		if inc == "" {
+			continue
+		}
+		if !messageIncludes[inc] {
+			return nil, i18n.NewError(ctx, i18n.MsgUnknownInclude, inc)
+		}
+		include[inc] = true
+	}
+
 	msg, err := or.getMessageByID(ctx, ns, id)
-	if err != nil || msg == nil {
+	if err != nil {
 		return nil, err
 	}
-	var txID *fftypes.UUID
-	if msg.Header.TxType == fftypes.TransactionTypeBatchPin {
-		if msg.BatchID == nil {
-			return nil, i18n.NewError(ctx, i18n.MsgBatchNotSet)
+	res := &fftypes.MessageWithIncludes{
+		MessageInOut: fftypes.MessageInOut{Message: *msg},
+	}
+
+	if include["data"] {
+		data, _, err := or.data.GetMessageDataCached(ctx, msg)
+		if err != nil {
+			return nil, err
 		}
-		batch, err := or.database.GetBatchByID(ctx, msg.BatchID)
+		res.SetInlineData(data)
+	} else {
+		res.SetDataRefs(msg.Data)
+	}
+
+	// Messages only have a transaction once they have been sealed into a batch
+	if include["transaction"] && msg.Header.TxType == fftypes.TransactionTypeBatchPin && msg.BatchID != nil {
+		txID, err := or.getBatchTransactionID(ctx, msg)
 		if err != nil {
 			return nil, err
 		}
-		if batch == nil {
-			return nil, i18n.NewError(ctx, i18n.MsgBatchNotFound, msg.BatchID)
+		if res.Transaction, err = or.database.GetTransactionByID(ctx, txID); err != nil {
+			return nil, err
+		}
+	}
+
+	if include["events"] {
+		// A single query for the events referring to the message, or any of its data
+		referencedIDs := make([]driver.Value, len(msg.Data)+1)
+		referencedIDs[0] = msg.Header.ID
+		for i, dataRef := range msg.Data {
+			referencedIDs[i+1] = dataRef.ID
 		}
-		txID = batch.TX.ID
-		if txID == nil {
-			return nil, i18n.NewError(ctx, i18n.MsgBatchTXNotSet, msg.BatchID)
+		fb := database.EventQueryFactory.NewFilter(ctx)
+		filter := fb.And(
+			fb.In("reference", referencedIDs),
+			fb.Eq("namespace", ns),
+		).Sort("sequence")
+		if res.Events, _, err = or.database.GetEvents(ctx, filter); err != nil {
+			return nil, err
 		}
-	} else {
+	}
+
+	return res, nil
+}
+
+func (or *orchestrator) getMessageTransactionID(ctx context.Context, ns, id string) (*fftypes.UUID, error) {
+	msg, err := or.getMessageByID(ctx, ns, id)
+	if err != nil || msg == nil {
+		return nil, err
+	}
+	if msg.Header.TxType != fftypes.TransactionTypeBatchPin {
 		return nil, i18n.NewError(ctx, i18n.MsgNoTransaction)
 	}
-	return txID, nil
+	return or.getBatchTransactionID(ctx, msg)
+}
+
+func (or *orchestrator) getBatchTransactionID(ctx context.Context, msg *fftypes.Message) (*fftypes.UUID, error) {
+	if msg.BatchID == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgBatchNotSet)
+	}
+	batch, err := or.database.GetBatchByID(ctx, msg.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgBatchNotFound, msg.BatchID)
+	}
+	if batch.TX.ID == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgBatchTXNotSet, msg.BatchID)
+	}
+	return batch.TX.ID, nil
 }
 
 func (or *orchestrator) GetMessageTransaction(ctx context.Context, ns, id string) (*fftypes.Transaction, error) {
@@ -253,6 +328,20 @@ func (or *orchestrator) GetMessageEvents(ctx context.Context, ns, id string, fil
 	return or.database.GetEvents(ctx, filter)
 }
 
+func (or *orchestrator) GetMessageApprovals(ctx context.Context, ns, id string) ([]*fftypes.MessageApproval, *database.FilterResult, error) {
+	msg, err := or.getMessageByID(ctx, ns, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	filter := database.MessageApprovalQueryFactory.NewFilter(ctx).Eq("message", msg.Header.ID)
+	return or.database.GetMessageApprovals(ctx, filter)
+}
+
+func (or *orchestrator) GetApprovals(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.MessageApproval, *database.FilterResult, error) {
+	filter = or.scopeNS(ns, filter)
+	return or.database.GetMessageApprovals(ctx, filter)
+}
+
 func (or *orchestrator) GetBatches(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.BatchPersisted, *database.FilterResult, error) {
 	filter = or.scopeNS(ns, filter)
 	return or.database.GetBatches(ctx, filter)
@@ -287,6 +376,87 @@ func (or *orchestrator) GetEvents(ctx context.Context, ns string, filter databas
 	return or.database.GetEvents(ctx, filter)
 }
 
+// byIDsFilter builds a namespace scoped filter for a set of IDs, returning the position of
+// each distinct ID so the results can be returned in the order they were requested
+func (or *orchestrator) byIDsFilter(ctx context.Context, ns string, ids []*fftypes.UUID, qf database.QueryFactory) (database.Filter, map[fftypes.UUID]int, error) {
+	if err := or.verifyNamespaceSyntax(ctx, ns); err != nil {
+		return nil, nil, err
+	}
+	maxIDs := config.GetInt(config.APIMaxFilterLimit)
+	if len(ids) == 0 || len(ids) > maxIDs {
+		return nil, nil, i18n.NewError(ctx, i18n.MsgByIDsCount, maxIDs)
+	}
+	positions := make(map[fftypes.UUID]int, len(ids))
+	values := make([]driver.Value, 0, len(ids))
+	for _, id := range ids {
+		if id == nil {
+			return nil, nil, i18n.NewError(ctx, i18n.MsgNilID)
+		}
+		if _, dup := positions[*id]; !dup {
+			positions[*id] = len(values)
+			values = append(values, id)
+		}
+	}
+	fb := qf.NewFilter(ctx)
+	filter := fb.And(
+		fb.In("id", values),
+		fb.Eq("namespace", ns),
+	).Limit(uint64(len(values)))
+	return filter, positions, nil
+}
+
+func (or *orchestrator) GetMessagesByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) ([]*fftypes.Message, error) {
+	filter, positions, err := or.byIDsFilter(ctx, ns, ids, database.MessageQueryFactory)
+	if err != nil {
+		return nil, err
+	}
+	msgs, _, err := or.database.GetMessages(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(msgs, func(i, j int) bool { return positions[*msgs[i].Header.ID] < positions[*msgs[j].Header.ID] })
+	return msgs, nil
+}
+
+func (or *orchestrator) GetDataByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) (fftypes.DataArray, error) {
+	filter, positions, err := or.byIDsFilter(ctx, ns, ids, database.DataQueryFactory)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := or.database.GetData(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(data, func(i, j int) bool { return positions[*data[i].ID] < positions[*data[j].ID] })
+	return data, nil
+}
+
+func (or *orchestrator) GetEventsByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) ([]*fftypes.Event, error) {
+	filter, positions, err := or.byIDsFilter(ctx, ns, ids, database.EventQueryFactory)
+	if err != nil {
+		return nil, err
+	}
+	events, _, err := or.database.GetEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(events, func(i, j int) bool { return positions[*events[i].ID] < positions[*events[j].ID] })
+	return events, nil
+}
+
+func (or *orchestrator) GetTransactionsByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) ([]*fftypes.Transaction, error) {
+	filter, positions, err := or.byIDsFilter(ctx, ns, ids, database.TransactionQueryFactory)
+	if err != nil {
+		return nil, err
+	}
+	txns, _, err := or.database.GetTransactions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(txns, func(i, j int) bool { return positions[*txns[i].ID] < positions[*txns[j].ID] })
+	return txns, nil
+}
+
 func (or *orchestrator) GetBlockchainEventByID(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEvent, error) {
 	return or.database.GetBlockchainEventByID(ctx, id)
 }
@@ -308,6 +478,30 @@ func (or *orchestrator) GetTransactionBlockchainEvents(ctx context.Context, ns,
 	return or.database.GetBlockchainEvents(ctx, filter)
 }
 
+// GetTransactionBlockchainTransactions returns the normalized descriptors reported by the blockchain
+// plugins for the operations of a transaction, with any transaction shared by operations listed once
+func (or *orchestrator) GetTransactionBlockchainTransactions(ctx context.Context, ns, id string) ([]*fftypes.BlockchainTransaction, error) {
+	ops, _, err := or.GetTransactionOperations(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]*fftypes.BlockchainTransaction, 0, len(ops))
+	seen := make(map[string]bool)
+	for _, op := range ops {
+		if op.BlockchainTX == nil {
+			continue
+		}
+		if op.BlockchainTX.ID != "" {
+			if seen[op.BlockchainTX.ID] {
+				continue
+			}
+			seen[op.BlockchainTX.ID] = true
+		}
+		txs = append(txs, op.BlockchainTX)
+	}
+	return txs, nil
+}
+
 func (or *orchestrator) GetPins(ctx context.Context, filter database.AndFilter) ([]*fftypes.Pin, *database.FilterResult, error) {
 	return or.database.GetPins(ctx, filter)
 }
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
@@ -61,6 +62,27 @@ func TestGetTransactionOperationBadID(t *testing.T) {
 	assert.Regexp(t, "FF10142", err)
 }
 
+func TestGetTransactionBlockchainTransactions(t *testing.T) {
+	or := newTestOrchestrator()
+	tx1 := &fftypes.BlockchainTransaction{ID: "0x12345", Status: fftypes.OpStatusSucceeded}
+	tx2 := &fftypes.BlockchainTransaction{Status: fftypes.OpStatusFailed}
+	or.mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
+		{BlockchainTX: tx1},
+		{BlockchainTX: tx1},
+		{},
+		{BlockchainTX: tx2},
+	}, nil, nil)
+	txs, err := or.GetTransactionBlockchainTransactions(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.NoError(t, err)
+	assert.Equal(t, []*fftypes.BlockchainTransaction{tx1, tx2}, txs)
+}
+
+func TestGetTransactionBlockchainTransactionsBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetTransactionBlockchainTransactions(context.Background(), "ns1", "")
+	assert.Regexp(t, "FF10142", err)
+}
+
 func TestGetNamespaces(t *testing.T) {
 	or := newTestOrchestrator()
 	or.mdi.On("GetNamespaces", mock.Anything, mock.Anything).Return([]*fftypes.Namespace{}, nil, nil)
@@ -164,6 +186,120 @@ func TestGetMessageByIDWithDataFail(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func newTestIncludesMessage() *fftypes.Message {
+	return &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:     fftypes.NewUUID(),
+			TxType: fftypes.TransactionTypeBatchPin,
+		},
+		BatchID: fftypes.NewUUID(),
+		Data: fftypes.DataRefs{
+			{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32()},
+		},
+	}
+}
+
+func TestGetMessageByIDWithIncludesAll(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := newTestIncludesMessage()
+	txID := fftypes.NewUUID()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdm.On("GetMessageDataCached", mock.Anything, msg).Return(fftypes.DataArray{
+		{ID: msg.Data[0].ID, Hash: msg.Data[0].Hash, Value: fftypes.JSONAnyPtr("{}")},
+	}, true, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, msg.BatchID).Return(&fftypes.BatchPersisted{
+		TX: fftypes.TransactionRef{ID: txID},
+	}, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, txID).Return(&fftypes.Transaction{ID: txID}, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return len(fi.Children[0].Values) == 2
+	})).Return([]*fftypes.Event{{ID: fftypes.NewUUID()}}, nil, nil)
+
+	res, err := or.GetMessageByIDWithIncludes(context.Background(), "ns1", msg.Header.ID.String(), []string{"Data", " transaction", "events", ""})
+	assert.NoError(t, err)
+	assert.NotNil(t, res.InlineData[0].Value)
+	assert.Equal(t, txID, res.Transaction.ID)
+	assert.Len(t, res.Events, 1)
+}
+
+func TestGetMessageByIDWithIncludesNone(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := newTestIncludesMessage()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+
+	res, err := or.GetMessageByIDWithIncludes(context.Background(), "ns1", msg.Header.ID.String(), []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, msg.Data[0].ID, res.InlineData[0].ID)
+	assert.Nil(t, res.InlineData[0].Value)
+	assert.Nil(t, res.Transaction)
+	assert.Nil(t, res.Events)
+}
+
+func TestGetMessageByIDWithIncludesUnbatched(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := newTestIncludesMessage()
+	msg.BatchID = nil
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+
+	res, err := or.GetMessageByIDWithIncludes(context.Background(), "ns1", msg.Header.ID.String(), []string{"transaction"})
+	assert.NoError(t, err)
+	assert.Nil(t, res.Transaction)
+}
+
+func TestGetMessageByIDWithIncludesUnknown(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetMessageByIDWithIncludes(context.Background(), "ns1", fftypes.NewUUID().String(), []string{"wrong"})
+	assert.Regexp(t, "FF10548", err)
+}
+
+func TestGetMessageByIDWithIncludesMsgFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetMessageByIDWithIncludes(context.Background(), "ns1", fftypes.NewUUID().String(), []string{"data"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageByIDWithIncludesDataFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := newTestIncludesMessage()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdm.On("GetMessageDataCached", mock.Anything, msg).Return(nil, false, fmt.Errorf("pop"))
+	_, err := or.GetMessageByIDWithIncludes(context.Background(), "ns1", msg.Header.ID.String(), []string{"data"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageByIDWithIncludesBatchFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := newTestIncludesMessage()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, msg.BatchID).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetMessageByIDWithIncludes(context.Background(), "ns1", msg.Header.ID.String(), []string{"transaction"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageByIDWithIncludesTxFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := newTestIncludesMessage()
+	txID := fftypes.NewUUID()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetBatchByID", mock.Anything, msg.BatchID).Return(&fftypes.BatchPersisted{
+		TX: fftypes.TransactionRef{ID: txID},
+	}, nil)
+	or.mdi.On("GetTransactionByID", mock.Anything, txID).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetMessageByIDWithIncludes(context.Background(), "ns1", msg.Header.ID.String(), []string{"transaction"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageByIDWithIncludesEventsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := newTestIncludesMessage()
+	or.mdi.On("GetMessageByID", mock.Anything, msg.Header.ID).Return(msg, nil)
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetMessageByIDWithIncludes(context.Background(), "ns1", msg.Header.ID.String(), []string{"events"})
+	assert.EqualError(t, err, "pop")
+}
+
 func TestGetMessages(t *testing.T) {
 	or := newTestOrchestrator()
 	u := fftypes.NewUUID()
@@ -174,6 +310,15 @@ func TestGetMessages(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetMessageStates(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessageStates", mock.Anything, mock.Anything).Return([]*fftypes.MessageStateRecord{}, nil, nil)
+	fb := database.MessageStateQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("state", fftypes.MessageStateConfirmed))
+	_, _, err := or.GetMessageStates(context.Background(), "ns1", f)
+	assert.NoError(t, err)
+}
+
 func TestGetMessagesWithDataFailMsg(t *testing.T) {
 	or := newTestOrchestrator()
 	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
@@ -428,6 +573,38 @@ func TestGetMessageEventsBadMsgID(t *testing.T) {
 	assert.Nil(t, ev)
 }
 
+func TestGetMessageApprovalsOk(t *testing.T) {
+	or := newTestOrchestrator()
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID: fftypes.NewUUID(),
+		},
+	}
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(msg, nil)
+	or.mdi.On("GetMessageApprovals", mock.Anything, mock.Anything).Return([]*fftypes.MessageApproval{}, nil, nil)
+	_, _, err := or.GetMessageApprovals(context.Background(), "ns1", msg.Header.ID.String())
+	assert.NoError(t, err)
+	calculatedFilter, err := or.mdi.Calls[1].Arguments[1].(database.Filter).Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`message == '%s'`, msg.Header.ID), calculatedFilter.String())
+}
+
+func TestGetMessageApprovalsBadMsgID(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(nil, nil)
+	_, _, err := or.GetMessageApprovals(context.Background(), "ns1", fftypes.NewUUID().String())
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestGetApprovals(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessageApprovals", mock.Anything, mock.Anything).Return([]*fftypes.MessageApproval{}, nil, nil)
+	fb := database.MessageApprovalQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("approver", "alice"))
+	_, _, err := or.GetApprovals(context.Background(), "ns1", f)
+	assert.NoError(t, err)
+}
+
 func TestGetBatchByID(t *testing.T) {
 	or := newTestOrchestrator()
 	u := fftypes.NewUUID()
@@ -698,3 +875,121 @@ func TestGetPins(t *testing.T) {
 	_, _, err := or.GetPins(context.Background(), f)
 	assert.NoError(t, err)
 }
+
+func TestGetMessagesByIDs(t *testing.T) {
+	or := newTestOrchestrator()
+	id1, id2, id3 := fftypes.NewUUID(), fftypes.NewUUID(), fftypes.NewUUID()
+	or.mdi.On("GetMessages", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == 3
+	})).Return([]*fftypes.Message{
+		{Header: fftypes.MessageHeader{ID: id2}},
+		{Header: fftypes.MessageHeader{ID: id1}},
+	}, nil, nil)
+	msgs, err := or.GetMessagesByIDs(context.Background(), "ns1", []*fftypes.UUID{id1, id3, id2, id1})
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, id1, msgs[0].Header.ID)
+	assert.Equal(t, id2, msgs[1].Header.ID)
+}
+
+func TestGetMessagesByIDsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetMessagesByIDs(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessagesByIDsBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetMessagesByIDs(context.Background(), "!wrong", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestGetMessagesByIDsNone(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetMessagesByIDs(context.Background(), "ns1", []*fftypes.UUID{})
+	assert.Regexp(t, "FF10546", err)
+}
+
+func TestGetMessagesByIDsTooMany(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.APIMaxFilterLimit, 1)
+	_, err := or.GetMessagesByIDs(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID(), fftypes.NewUUID()})
+	assert.Regexp(t, "FF10546", err)
+}
+
+func TestGetMessagesByIDsNilID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetMessagesByIDs(context.Background(), "ns1", []*fftypes.UUID{nil})
+	assert.Regexp(t, "FF10203", err)
+}
+
+func TestGetDataByIDs(t *testing.T) {
+	or := newTestOrchestrator()
+	id1, id2 := fftypes.NewUUID(), fftypes.NewUUID()
+	or.mdi.On("GetData", mock.Anything, mock.Anything).Return(fftypes.DataArray{{ID: id2}, {ID: id1}}, nil, nil)
+	data, err := or.GetDataByIDs(context.Background(), "ns1", []*fftypes.UUID{id1, id2})
+	assert.NoError(t, err)
+	assert.Equal(t, id1, data[0].ID)
+	assert.Equal(t, id2, data[1].ID)
+}
+
+func TestGetDataByIDsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetData", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetDataByIDs(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetDataByIDsBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetDataByIDs(context.Background(), "!wrong", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestGetEventsByIDs(t *testing.T) {
+	or := newTestOrchestrator()
+	id1, id2 := fftypes.NewUUID(), fftypes.NewUUID()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{ID: id2}, {ID: id1}}, nil, nil)
+	events, err := or.GetEventsByIDs(context.Background(), "ns1", []*fftypes.UUID{id1, id2})
+	assert.NoError(t, err)
+	assert.Equal(t, id1, events[0].ID)
+	assert.Equal(t, id2, events[1].ID)
+}
+
+func TestGetEventsByIDsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetEventsByIDs(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetEventsByIDsBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetEventsByIDs(context.Background(), "!wrong", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestGetTransactionsByIDs(t *testing.T) {
+	or := newTestOrchestrator()
+	id1, id2 := fftypes.NewUUID(), fftypes.NewUUID()
+	or.mdi.On("GetTransactions", mock.Anything, mock.Anything).Return([]*fftypes.Transaction{{ID: id2}, {ID: id1}}, nil, nil)
+	txns, err := or.GetTransactionsByIDs(context.Background(), "ns1", []*fftypes.UUID{id1, id2})
+	assert.NoError(t, err)
+	assert.Equal(t, id1, txns[0].ID)
+	assert.Equal(t, id2, txns[1].ID)
+}
+
+func TestGetTransactionsByIDsFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetTransactions", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.GetTransactionsByIDs(context.Background(), "ns1", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetTransactionsByIDsBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetTransactionsByIDs(context.Background(), "!wrong", []*fftypes.UUID{fftypes.NewUUID()})
+	assert.Regexp(t, "FF10131", err)
+}
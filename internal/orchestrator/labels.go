@@ -0,0 +1,74 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// UpdateMessageLabels adds and removes the local labels of a message
+func (or *orchestrator) UpdateMessageLabels(ctx context.Context, ns, id string, input *fftypes.LabelsUpdate) (msg *fftypes.Message, err error) {
+	u, err := or.verifyIDAndNamespace(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	err = or.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if msg, err = or.database.GetMessageByID(ctx, u); err != nil {
+			return err
+		}
+		if msg == nil || msg.Header.Namespace != ns {
+			return i18n.NewError(ctx, i18n.Msg404NotFound)
+		}
+		if msg.Labels, err = input.Apply(ctx, msg.Labels); err != nil {
+			return err
+		}
+		return or.database.UpdateMessage(ctx, msg.Header.ID, database.MessageQueryFactory.NewUpdate(ctx).Set("labels", msg.Labels))
+	})
+	if err != nil {
+		return nil, err
+	}
+	or.data.UpdateMessageIfCached(ctx, msg)
+	return msg, nil
+}
+
+// UpdateDataLabels adds and removes the local labels of a data item
+func (or *orchestrator) UpdateDataLabels(ctx context.Context, ns, id string, input *fftypes.LabelsUpdate) (data *fftypes.Data, err error) {
+	u, err := or.verifyIDAndNamespace(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	err = or.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if data, err = or.database.GetDataByID(ctx, u, true); err != nil {
+			return err
+		}
+		if data == nil || data.Namespace != ns {
+			return i18n.NewError(ctx, i18n.Msg404NotFound)
+		}
+		if data.Labels, err = input.Apply(ctx, data.Labels); err != nil {
+			return err
+		}
+		return or.database.UpdateData(ctx, data.ID, database.DataQueryFactory.NewUpdate(ctx).Set("labels", data.Labels))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
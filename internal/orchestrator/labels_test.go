@@ -0,0 +1,134 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateMessageLabelsOk(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	msgID := fftypes.NewUUID()
+	or.mdi.On("GetMessageByID", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "ns1"},
+		Labels: fftypes.FFStringArray{"pending"},
+	}, nil)
+	or.mdi.On("UpdateMessage", mock.Anything, msgID, mock.Anything).Return(nil)
+	or.mdm.On("UpdateMessageIfCached", mock.Anything, mock.Anything).Return()
+
+	msg, err := or.UpdateMessageLabels(context.Background(), "ns1", msgID.String(), &fftypes.LabelsUpdate{
+		Add:    []string{"reviewed"},
+		Remove: []string{"pending"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.FFStringArray{"reviewed"}, msg.Labels)
+	or.mdi.AssertExpectations(t)
+	or.mdm.AssertExpectations(t)
+}
+
+func TestUpdateMessageLabelsBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.UpdateMessageLabels(context.Background(), "ns1", "bad", &fftypes.LabelsUpdate{})
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestUpdateMessageLabelsLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := or.UpdateMessageLabels(context.Background(), "ns1", fftypes.NewUUID().String(), &fftypes.LabelsUpdate{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestUpdateMessageLabelsWrongNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "ns2"},
+	}, nil)
+	_, err := or.UpdateMessageLabels(context.Background(), "ns1", fftypes.NewUUID().String(), &fftypes.LabelsUpdate{})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestUpdateMessageLabelsInvalid(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "ns1"},
+	}, nil)
+	_, err := or.UpdateMessageLabels(context.Background(), "ns1", fftypes.NewUUID().String(), &fftypes.LabelsUpdate{
+		Add: []string{"!bad"},
+	})
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestUpdateDataLabelsOk(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	dataID := fftypes.NewUUID()
+	or.mdi.On("GetDataByID", mock.Anything, dataID, true).Return(&fftypes.Data{
+		ID:        dataID,
+		Namespace: "ns1",
+	}, nil)
+	or.mdi.On("UpdateData", mock.Anything, dataID, mock.Anything).Return(nil)
+
+	data, err := or.UpdateDataLabels(context.Background(), "ns1", dataID.String(), &fftypes.LabelsUpdate{
+		Add: []string{"Archived"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.FFStringArray{"archived"}, data.Labels)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestUpdateDataLabelsBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.UpdateDataLabels(context.Background(), "ns1", "bad", &fftypes.LabelsUpdate{})
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestUpdateDataLabelsLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdi.On("GetDataByID", mock.Anything, mock.Anything, true).Return(nil, fmt.Errorf("pop"))
+	_, err := or.UpdateDataLabels(context.Background(), "ns1", fftypes.NewUUID().String(), &fftypes.LabelsUpdate{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestUpdateDataLabelsNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdi.On("GetDataByID", mock.Anything, mock.Anything, true).Return(nil, nil)
+	_, err := or.UpdateDataLabels(context.Background(), "ns1", fftypes.NewUUID().String(), &fftypes.LabelsUpdate{})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestUpdateDataLabelsInvalid(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdi.On("GetDataByID", mock.Anything, mock.Anything, true).Return(&fftypes.Data{Namespace: "ns1"}, nil)
+	_, err := or.UpdateDataLabels(context.Background(), "ns1", fftypes.NewUUID().String(), &fftypes.LabelsUpdate{
+		Add: []string{"!bad"},
+	})
+	assert.Regexp(t, "FF10131", err)
+}
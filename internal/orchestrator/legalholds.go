@@ -0,0 +1,146 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (or *orchestrator) verifyLegalHoldRef(ctx context.Context, ns string, input *fftypes.LegalHoldInput) error {
+	var found bool
+	switch input.Type {
+	case fftypes.LegalHoldTypeMessage:
+		msg, err := or.database.GetMessageByID(ctx, input.Ref)
+		if err != nil {
+			return err
+		}
+		found = msg != nil && msg.Header.Namespace == ns
+	case fftypes.LegalHoldTypeData:
+		data, err := or.database.GetDataByID(ctx, input.Ref, false)
+		if err != nil {
+			return err
+		}
+		found = data != nil && data.Namespace == ns
+	default:
+		return i18n.NewError(ctx, i18n.MsgLegalHoldTypeInvalid, input.Type)
+	}
+	if !found {
+		return i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return nil
+}
+
+// PlaceLegalHold marks a message or data item as non-deletable, until the hold is released
+func (or *orchestrator) PlaceLegalHold(ctx context.Context, ns string, input *fftypes.LegalHoldInput) (hold *fftypes.LegalHold, err error) {
+	if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	if input.Ref == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgLegalHoldRefMissing)
+	}
+	if input.Reason == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgLegalHoldReasonMissing)
+	}
+
+	err = or.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		if err := or.verifyLegalHoldRef(ctx, ns, input); err != nil {
+			return err
+		}
+		fb := database.LegalHoldQueryFactory.NewFilterLimit(ctx, 1)
+		existing, _, err := or.database.GetLegalHolds(ctx, fb.And(
+			fb.Eq("namespace", ns),
+			fb.Eq("ref", input.Ref),
+			fb.Eq("released", nil),
+		))
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return i18n.NewError(ctx, i18n.MsgAlreadyLegalHeld, input.Type, input.Ref, existing[0].ID)
+		}
+		hold = &fftypes.LegalHold{
+			ID:        fftypes.NewUUID(),
+			Namespace: ns,
+			Type:      input.Type,
+			Ref:       input.Ref,
+			Reason:    input.Reason,
+		}
+		return or.database.InsertLegalHold(ctx, hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Legal hold %s placed on %s %s: %s", hold.ID, hold.Type, hold.Ref, hold.Reason)
+	return hold, nil
+}
+
+// ReleaseLegalHold releases a hold. The hold itself is retained, as a record of when and why it was released.
+func (or *orchestrator) ReleaseLegalHold(ctx context.Context, ns, id string, input *fftypes.LegalHoldRelease) (hold *fftypes.LegalHold, err error) {
+	u, err := or.verifyIDAndNamespace(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Reason == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgLegalHoldReasonMissing)
+	}
+
+	err = or.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		hold, err = or.database.GetLegalHoldByID(ctx, u)
+		if err != nil {
+			return err
+		}
+		if hold == nil || hold.Namespace != ns {
+			return i18n.NewError(ctx, i18n.Msg404NotFound)
+		}
+		if !hold.IsActive() {
+			return i18n.NewError(ctx, i18n.MsgLegalHoldReleased, hold.ID)
+		}
+		hold.Released = fftypes.Now()
+		hold.ReleaseReason = input.Reason
+		update := database.LegalHoldQueryFactory.NewUpdate(ctx).
+			Set("released", hold.Released).
+			Set("releasereason", hold.ReleaseReason)
+		return or.database.UpdateLegalHold(ctx, hold.ID, update)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.L(ctx).Infof("Legal hold %s released on %s %s: %s", hold.ID, hold.Type, hold.Ref, hold.ReleaseReason)
+	return hold, nil
+}
+
+func (or *orchestrator) GetLegalHolds(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.LegalHold, *database.FilterResult, error) {
+	filter = or.scopeNS(ns, filter)
+	return or.database.GetLegalHolds(ctx, filter)
+}
+
+func (or *orchestrator) GetLegalHoldByID(ctx context.Context, ns, id string) (*fftypes.LegalHold, error) {
+	u, err := or.verifyIDAndNamespace(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	hold, err := or.database.GetLegalHoldByID(ctx, u)
+	if err == nil && hold != nil && hold.Namespace != ns {
+		return nil, nil
+	}
+	return hold, err
+}
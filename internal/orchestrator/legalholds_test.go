@@ -0,0 +1,286 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func passthroughRunAsGroup(mdi *databasemocks.Plugin) {
+	rag := mdi.On("RunAsGroup", mock.Anything, mock.Anything)
+	rag.RunFn = func(a mock.Arguments) {
+		rag.ReturnArguments = mock.Arguments{
+			a[1].(func(context.Context) error)(a[0].(context.Context)),
+		}
+	}
+}
+
+func TestPlaceLegalHoldMessageOk(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	msgID := fftypes.NewUUID()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetMessageByID", mock.Anything, msgID).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: msgID, Namespace: "ns1"},
+	}, nil)
+	or.mdi.On("GetLegalHolds", mock.Anything, mock.Anything).Return([]*fftypes.LegalHold{}, nil, nil)
+	or.mdi.On("InsertLegalHold", mock.Anything, mock.MatchedBy(func(hold *fftypes.LegalHold) bool {
+		return hold.Namespace == "ns1" && hold.Type == fftypes.LegalHoldTypeMessage && hold.Ref.Equals(msgID)
+	})).Return(nil)
+
+	hold, err := or.PlaceLegalHold(or.ctx, "ns1", &fftypes.LegalHoldInput{
+		Type:   fftypes.LegalHoldTypeMessage,
+		Ref:    msgID,
+		Reason: "case 1234",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "case 1234", hold.Reason)
+	assert.True(t, hold.IsActive())
+	or.mdi.AssertExpectations(t)
+}
+
+func TestPlaceLegalHoldDataOk(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	dataID := fftypes.NewUUID()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetDataByID", mock.Anything, dataID, false).Return(&fftypes.Data{ID: dataID, Namespace: "ns1"}, nil)
+	or.mdi.On("GetLegalHolds", mock.Anything, mock.Anything).Return([]*fftypes.LegalHold{}, nil, nil)
+	or.mdi.On("InsertLegalHold", mock.Anything, mock.Anything).Return(nil)
+
+	hold, err := or.PlaceLegalHold(or.ctx, "ns1", &fftypes.LegalHoldInput{
+		Type:   fftypes.LegalHoldTypeData,
+		Ref:    dataID,
+		Reason: "case 1234",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.LegalHoldTypeData, hold.Type)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestPlaceLegalHoldBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "!wrong").Return(fmt.Errorf("pop"))
+	_, err := or.PlaceLegalHold(or.ctx, "!wrong", &fftypes.LegalHoldInput{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPlaceLegalHoldMissingRef(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	_, err := or.PlaceLegalHold(or.ctx, "ns1", &fftypes.LegalHoldInput{
+		Type:   fftypes.LegalHoldTypeMessage,
+		Reason: "case 1234",
+	})
+	assert.Regexp(t, "FF10414", err)
+}
+
+func TestPlaceLegalHoldMissingReason(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	_, err := or.PlaceLegalHold(or.ctx, "ns1", &fftypes.LegalHoldInput{
+		Type: fftypes.LegalHoldTypeMessage,
+		Ref:  fftypes.NewUUID(),
+	})
+	assert.Regexp(t, "FF10415", err)
+}
+
+func TestPlaceLegalHoldBadType(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	_, err := or.PlaceLegalHold(or.ctx, "ns1", &fftypes.LegalHoldInput{
+		Type:   "wrong",
+		Ref:    fftypes.NewUUID(),
+		Reason: "case 1234",
+	})
+	assert.Regexp(t, "FF10413.*wrong", err)
+}
+
+func TestPlaceLegalHoldMessageLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := or.PlaceLegalHold(or.ctx, "ns1", &fftypes.LegalHoldInput{
+		Type:   fftypes.LegalHoldTypeMessage,
+		Ref:    fftypes.NewUUID(),
+		Reason: "case 1234",
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPlaceLegalHoldDataLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetDataByID", mock.Anything, mock.Anything, false).Return(nil, fmt.Errorf("pop"))
+	_, err := or.PlaceLegalHold(or.ctx, "ns1", &fftypes.LegalHoldInput{
+		Type:   fftypes.LegalHoldTypeData,
+		Ref:    fftypes.NewUUID(),
+		Reason: "case 1234",
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPlaceLegalHoldDataWrongNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetDataByID", mock.Anything, mock.Anything, false).Return(&fftypes.Data{Namespace: "ns2"}, nil)
+	_, err := or.PlaceLegalHold(or.ctx, "ns1", &fftypes.LegalHoldInput{
+		Type:   fftypes.LegalHoldTypeData,
+		Ref:    fftypes.NewUUID(),
+		Reason: "case 1234",
+	})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestPlaceLegalHoldQueryExistingFail(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "ns1"},
+	}, nil)
+	or.mdi.On("GetLegalHolds", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := or.PlaceLegalHold(or.ctx, "ns1", &fftypes.LegalHoldInput{
+		Type:   fftypes.LegalHoldTypeMessage,
+		Ref:    fftypes.NewUUID(),
+		Reason: "case 1234",
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestPlaceLegalHoldAlreadyHeld(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetMessageByID", mock.Anything, mock.Anything).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{Namespace: "ns1"},
+	}, nil)
+	or.mdi.On("GetLegalHolds", mock.Anything, mock.Anything).Return([]*fftypes.LegalHold{
+		{ID: fftypes.NewUUID()},
+	}, nil, nil)
+	_, err := or.PlaceLegalHold(or.ctx, "ns1", &fftypes.LegalHoldInput{
+		Type:   fftypes.LegalHoldTypeMessage,
+		Ref:    fftypes.NewUUID(),
+		Reason: "case 1234",
+	})
+	assert.Regexp(t, "FF10416", err)
+}
+
+func TestReleaseLegalHoldOk(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	holdID := fftypes.NewUUID()
+	or.mdi.On("GetLegalHoldByID", mock.Anything, holdID).Return(&fftypes.LegalHold{
+		ID:        holdID,
+		Namespace: "ns1",
+		Type:      fftypes.LegalHoldTypeMessage,
+		Ref:       fftypes.NewUUID(),
+	}, nil)
+	or.mdi.On("UpdateLegalHold", mock.Anything, holdID, mock.Anything).Return(nil)
+
+	hold, err := or.ReleaseLegalHold(or.ctx, "ns1", holdID.String(), &fftypes.LegalHoldRelease{Reason: "case closed"})
+	assert.NoError(t, err)
+	assert.False(t, hold.IsActive())
+	assert.Equal(t, "case closed", hold.ReleaseReason)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestReleaseLegalHoldBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.ReleaseLegalHold(or.ctx, "ns1", "bad", &fftypes.LegalHoldRelease{Reason: "case closed"})
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestReleaseLegalHoldMissingReason(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.ReleaseLegalHold(or.ctx, "ns1", fftypes.NewUUID().String(), &fftypes.LegalHoldRelease{})
+	assert.Regexp(t, "FF10415", err)
+}
+
+func TestReleaseLegalHoldLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdi.On("GetLegalHoldByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := or.ReleaseLegalHold(or.ctx, "ns1", fftypes.NewUUID().String(), &fftypes.LegalHoldRelease{Reason: "case closed"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestReleaseLegalHoldNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdi.On("GetLegalHoldByID", mock.Anything, mock.Anything).Return(nil, nil)
+	_, err := or.ReleaseLegalHold(or.ctx, "ns1", fftypes.NewUUID().String(), &fftypes.LegalHoldRelease{Reason: "case closed"})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestReleaseLegalHoldAlreadyReleased(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	or.mdi.On("GetLegalHoldByID", mock.Anything, mock.Anything).Return(&fftypes.LegalHold{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Released:  fftypes.Now(),
+	}, nil)
+	_, err := or.ReleaseLegalHold(or.ctx, "ns1", fftypes.NewUUID().String(), &fftypes.LegalHoldRelease{Reason: "case closed"})
+	assert.Regexp(t, "FF10417", err)
+}
+
+func TestGetLegalHolds(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetLegalHolds", mock.Anything, mock.Anything).Return([]*fftypes.LegalHold{}, nil, nil)
+	fb := database.LegalHoldQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("ref", u))
+	_, _, err := or.GetLegalHolds(context.Background(), "ns1", f)
+	assert.NoError(t, err)
+}
+
+func TestGetLegalHoldByID(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetLegalHoldByID", mock.Anything, u).Return(&fftypes.LegalHold{ID: u, Namespace: "ns1"}, nil)
+	hold, err := or.GetLegalHoldByID(context.Background(), "ns1", u.String())
+	assert.NoError(t, err)
+	assert.Equal(t, u, hold.ID)
+}
+
+func TestGetLegalHoldByIDWrongNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	u := fftypes.NewUUID()
+	or.mdi.On("GetLegalHoldByID", mock.Anything, u).Return(&fftypes.LegalHold{ID: u, Namespace: "ns2"}, nil)
+	hold, err := or.GetLegalHoldByID(context.Background(), "ns1", u.String())
+	assert.NoError(t, err)
+	assert.Nil(t, hold)
+}
+
+func TestGetLegalHoldByIDBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetLegalHoldByID(context.Background(), "ns1", "bad")
+	assert.Regexp(t, "FF10142", err)
+}
@@ -18,14 +18,43 @@ package orchestrator
 
 import (
 	"context"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
-func (or *orchestrator) RequestReply(ctx context.Context, ns string, msg *fftypes.MessageInOut) (reply *fftypes.MessageInOut, err error) {
+func (or *orchestrator) RequestReply(ctx context.Context, ns string, msg *fftypes.MessageInOut, timeout time.Duration) (reply *fftypes.MessageInOut, err error) {
 	if msg.Header.Group == nil && (msg.Group == nil || len(msg.Group.Members) == 0) {
 		return nil, i18n.NewError(ctx, i18n.MsgRequestMustBePrivate)
 	}
-	return or.PrivateMessaging().RequestReply(ctx, ns, msg)
+	return or.PrivateMessaging().RequestReply(ctx, ns, msg, timeout)
+}
+
+// RequestReplyBroadcast broadcasts a request message, and waits for a broadcast reply that correlates to it
+func (or *orchestrator) RequestReplyBroadcast(ctx context.Context, ns string, msg *fftypes.MessageInOut, timeout time.Duration) (reply *fftypes.MessageInOut, err error) {
+	if msg.Header.Group != nil || msg.Group != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgRequestMustBeBroadcast)
+	}
+	return or.Broadcast().RequestReply(ctx, ns, msg, timeout)
+}
+
+// ValidateMessage performs all the checks of sending a message, without persisting or sending it.
+// The message is checked as a broadcast, private or local message based on its type - or if no type is set,
+// on whether a group is specified.
+func (or *orchestrator) ValidateMessage(ctx context.Context, ns string, msg *fftypes.MessageInOut) (*fftypes.MessageValidationReport, error) {
+	if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	switch msg.Header.Type {
+	case fftypes.MessageTypeLocal:
+		return or.LocalMessaging().ValidateMessage(ctx, ns, msg), nil
+	case fftypes.MessageTypePrivate, fftypes.MessageTypeTransferPrivate:
+		return or.PrivateMessaging().ValidateMessage(ctx, ns, msg), nil
+	case "":
+		if msg.Header.Group != nil || msg.Group != nil {
+			return or.PrivateMessaging().ValidateMessage(ctx, ns, msg), nil
+		}
+	}
+	return or.Broadcast().ValidateMessage(ctx, ns, msg), nil
 }
@@ -18,16 +18,19 @@ package orchestrator
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestRequestReplyMissingGroup(t *testing.T) {
 	or := newTestOrchestrator()
 	input := &fftypes.MessageInOut{}
-	_, err := or.RequestReply(context.Background(), "ns1", input)
+	_, err := or.RequestReply(context.Background(), "ns1", input, 0)
 	assert.Regexp(t, "FF10271", err)
 }
 
@@ -40,7 +43,87 @@ func TestRequestReply(t *testing.T) {
 			},
 		},
 	}
-	or.mpm.On("RequestReply", context.Background(), "ns1", input).Return(&fftypes.MessageInOut{}, nil)
-	_, err := or.RequestReply(context.Background(), "ns1", input)
+	or.mpm.On("RequestReply", context.Background(), "ns1", input, time.Duration(0)).Return(&fftypes.MessageInOut{}, nil)
+	_, err := or.RequestReply(context.Background(), "ns1", input, 0)
 	assert.NoError(t, err)
 }
+
+func TestRequestReplyBroadcastWithGroup(t *testing.T) {
+	or := newTestOrchestrator()
+	input := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Group: fftypes.NewRandB32(),
+			},
+		},
+	}
+	_, err := or.RequestReplyBroadcast(context.Background(), "ns1", input, 0)
+	assert.Regexp(t, "FF10551", err)
+}
+
+func TestRequestReplyBroadcast(t *testing.T) {
+	or := newTestOrchestrator()
+	input := &fftypes.MessageInOut{}
+	or.mbm.On("RequestReply", context.Background(), "ns1", input, time.Duration(0)).Return(&fftypes.MessageInOut{}, nil)
+	_, err := or.RequestReplyBroadcast(context.Background(), "ns1", input, 0)
+	assert.NoError(t, err)
+}
+
+func TestValidateMessageBroadcast(t *testing.T) {
+	or := newTestOrchestrator()
+	input := &fftypes.MessageInOut{}
+	report := fftypes.NewMessageValidationReport(fftypes.MessageTypeBroadcast)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mbm.On("ValidateMessage", context.Background(), "ns1", input).Return(report)
+	res, err := or.ValidateMessage(context.Background(), "ns1", input)
+	assert.NoError(t, err)
+	assert.Equal(t, report, res)
+}
+
+func TestValidateMessagePrivateGroup(t *testing.T) {
+	or := newTestOrchestrator()
+	input := &fftypes.MessageInOut{
+		Group: &fftypes.InputGroup{
+			Members: []fftypes.MemberInput{
+				{Identity: "org1"},
+			},
+		},
+	}
+	report := fftypes.NewMessageValidationReport(fftypes.MessageTypePrivate)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mpm.On("ValidateMessage", context.Background(), "ns1", input).Return(report)
+	res, err := or.ValidateMessage(context.Background(), "ns1", input)
+	assert.NoError(t, err)
+	assert.Equal(t, report, res)
+}
+
+func TestValidateMessagePrivateType(t *testing.T) {
+	or := newTestOrchestrator()
+	input := &fftypes.MessageInOut{}
+	input.Header.Type = fftypes.MessageTypeTransferPrivate
+	report := fftypes.NewMessageValidationReport(fftypes.MessageTypeTransferPrivate)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mpm.On("ValidateMessage", context.Background(), "ns1", input).Return(report)
+	res, err := or.ValidateMessage(context.Background(), "ns1", input)
+	assert.NoError(t, err)
+	assert.Equal(t, report, res)
+}
+
+func TestValidateMessageLocal(t *testing.T) {
+	or := newTestOrchestrator()
+	input := &fftypes.MessageInOut{}
+	input.Header.Type = fftypes.MessageTypeLocal
+	report := fftypes.NewMessageValidationReport(fftypes.MessageTypeLocal)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mlm.On("ValidateMessage", context.Background(), "ns1", input).Return(report)
+	res, err := or.ValidateMessage(context.Background(), "ns1", input)
+	assert.NoError(t, err)
+	assert.Equal(t, report, res)
+}
+
+func TestValidateMessageBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(fmt.Errorf("pop"))
+	_, err := or.ValidateMessage(context.Background(), "ns1", &fftypes.MessageInOut{})
+	assert.EqualError(t, err, "pop")
+}
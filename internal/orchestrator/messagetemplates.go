@@ -0,0 +1,78 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// SaveMessageTemplate creates or replaces a message template. Any datatypes the template refers to must
+// already exist, although the default values are only validated against them when a message is sent.
+func (or *orchestrator) SaveMessageTemplate(ctx context.Context, ns string, template *fftypes.MessageTemplate) (*fftypes.MessageTemplate, error) {
+	template.Namespace = ns
+	if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	if err := fftypes.ValidateFFNameFieldNoUUID(ctx, template.Name, "name"); err != nil {
+		return nil, err
+	}
+	for i, d := range template.Data {
+		if d == nil {
+			return nil, i18n.NewError(ctx, i18n.MsgMessageTemplateDataMissing, i)
+		}
+		if d.Datatype != nil {
+			datatype, err := or.database.GetDatatypeByName(ctx, ns, d.Datatype.Name, d.Datatype.Version)
+			if err != nil {
+				return nil, err
+			}
+			if datatype == nil {
+				return nil, i18n.NewError(ctx, i18n.MsgDatatypeNotFound, d.Datatype)
+			}
+		}
+	}
+	if err := or.database.UpsertMessageTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (or *orchestrator) GetMessageTemplates(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.MessageTemplate, *database.FilterResult, error) {
+	filter = or.scopeNS(ns, filter)
+	return or.database.GetMessageTemplates(ctx, filter)
+}
+
+func (or *orchestrator) GetMessageTemplateByName(ctx context.Context, ns, name string) (*fftypes.MessageTemplate, error) {
+	if err := or.verifyNamespaceSyntax(ctx, ns); err != nil {
+		return nil, err
+	}
+	return or.database.GetMessageTemplateByName(ctx, ns, name)
+}
+
+func (or *orchestrator) DeleteMessageTemplate(ctx context.Context, ns, name string) error {
+	template, err := or.GetMessageTemplateByName(ctx, ns, name)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		return i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return or.database.DeleteMessageTemplate(ctx, template.ID)
+}
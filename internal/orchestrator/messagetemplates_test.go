@@ -0,0 +1,144 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSaveMessageTemplateOk(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetDatatypeByName", mock.Anything, "ns1", "widget", "1.0").Return(&fftypes.Datatype{}, nil)
+	or.mdi.On("UpsertMessageTemplate", mock.Anything, mock.MatchedBy(func(t *fftypes.MessageTemplate) bool {
+		return t.Namespace == "ns1" && t.Name == "template1"
+	})).Return(nil)
+	template, err := or.SaveMessageTemplate(context.Background(), "ns1", &fftypes.MessageTemplate{
+		Name: "template1",
+		Data: fftypes.InlineData{
+			{Datatype: &fftypes.DatatypeRef{Name: "widget", Version: "1.0"}},
+			{Value: fftypes.JSONAnyPtr(`"footer"`)},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", template.Namespace)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestSaveMessageTemplateBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(fmt.Errorf("pop"))
+	_, err := or.SaveMessageTemplate(context.Background(), "ns1", &fftypes.MessageTemplate{Name: "template1"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSaveMessageTemplateBadName(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	_, err := or.SaveMessageTemplate(context.Background(), "ns1", &fftypes.MessageTemplate{Name: "!bad"})
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestSaveMessageTemplateEmptyData(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	_, err := or.SaveMessageTemplate(context.Background(), "ns1", &fftypes.MessageTemplate{
+		Name: "template1",
+		Data: fftypes.InlineData{nil},
+	})
+	assert.Regexp(t, "FF10458", err)
+}
+
+func TestSaveMessageTemplateDatatypeLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetDatatypeByName", mock.Anything, "ns1", "widget", "1.0").Return(nil, fmt.Errorf("pop"))
+	_, err := or.SaveMessageTemplate(context.Background(), "ns1", &fftypes.MessageTemplate{
+		Name: "template1",
+		Data: fftypes.InlineData{
+			{Datatype: &fftypes.DatatypeRef{Name: "widget", Version: "1.0"}},
+		},
+	})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSaveMessageTemplateDatatypeNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("GetDatatypeByName", mock.Anything, "ns1", "widget", "1.0").Return(nil, nil)
+	_, err := or.SaveMessageTemplate(context.Background(), "ns1", &fftypes.MessageTemplate{
+		Name: "template1",
+		Data: fftypes.InlineData{
+			{Datatype: &fftypes.DatatypeRef{Name: "widget", Version: "1.0"}},
+		},
+	})
+	assert.Regexp(t, "FF10195", err)
+}
+
+func TestSaveMessageTemplateFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("UpsertMessageTemplate", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := or.SaveMessageTemplate(context.Background(), "ns1", &fftypes.MessageTemplate{Name: "template1"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetMessageTemplates(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessageTemplates", mock.Anything, mock.Anything).Return([]*fftypes.MessageTemplate{}, nil, nil)
+	fb := database.MessageTemplateQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("name", "template1"))
+	_, _, err := or.GetMessageTemplates(context.Background(), "ns1", f)
+	assert.NoError(t, err)
+}
+
+func TestGetMessageTemplateByNameBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetMessageTemplateByName(context.Background(), "!bad", "template1")
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestDeleteMessageTemplateOk(t *testing.T) {
+	or := newTestOrchestrator()
+	templateID := fftypes.NewUUID()
+	or.mdi.On("GetMessageTemplateByName", mock.Anything, "ns1", "template1").Return(&fftypes.MessageTemplate{ID: templateID}, nil)
+	or.mdi.On("DeleteMessageTemplate", mock.Anything, templateID).Return(nil)
+	err := or.DeleteMessageTemplate(context.Background(), "ns1", "template1")
+	assert.NoError(t, err)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestDeleteMessageTemplateLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessageTemplateByName", mock.Anything, "ns1", "template1").Return(nil, fmt.Errorf("pop"))
+	err := or.DeleteMessageTemplate(context.Background(), "ns1", "template1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeleteMessageTemplateNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetMessageTemplateByName", mock.Anything, "ns1", "template1").Return(nil, nil)
+	err := or.DeleteMessageTemplate(context.Background(), "ns1", "template1")
+	assert.Regexp(t, "FF10109", err)
+}
@@ -19,12 +19,16 @@ package orchestrator
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/hyperledger/firefly/internal/approvals"
 	"github.com/hyperledger/firefly/internal/assets"
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/batchpin"
 	"github.com/hyperledger/firefly/internal/blockchain/bifactory"
+	"github.com/hyperledger/firefly/internal/bridging"
 	"github.com/hyperledger/firefly/internal/broadcast"
+	"github.com/hyperledger/firefly/internal/canary"
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/contracts"
 	"github.com/hyperledger/firefly/internal/data"
@@ -32,15 +36,30 @@ import (
 	"github.com/hyperledger/firefly/internal/dataexchange/dxfactory"
 	"github.com/hyperledger/firefly/internal/definitions"
 	"github.com/hyperledger/firefly/internal/events"
+	"github.com/hyperledger/firefly/internal/faults"
+	"github.com/hyperledger/firefly/internal/gateway"
+	"github.com/hyperledger/firefly/internal/governance"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/identity"
 	"github.com/hyperledger/firefly/internal/identity/iifactory"
+	"github.com/hyperledger/firefly/internal/inbound"
+	"github.com/hyperledger/firefly/internal/ingestion"
+	"github.com/hyperledger/firefly/internal/localmessaging"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/mailgateway"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/networkmap"
+	"github.com/hyperledger/firefly/internal/notifications"
 	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/internal/ping"
 	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/replay"
+	"github.com/hyperledger/firefly/internal/reports"
+	"github.com/hyperledger/firefly/internal/scanning/scfactory"
+	"github.com/hyperledger/firefly/internal/schemadrift"
 	"github.com/hyperledger/firefly/internal/sharedstorage/ssfactory"
+	"github.com/hyperledger/firefly/internal/stats"
+	"github.com/hyperledger/firefly/internal/swaps"
 	"github.com/hyperledger/firefly/internal/syncasync"
 	"github.com/hyperledger/firefly/internal/tokens/tifactory"
 	"github.com/hyperledger/firefly/internal/txcommon"
@@ -49,6 +68,7 @@ import (
 	"github.com/hyperledger/firefly/pkg/dataexchange"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	idplugin "github.com/hyperledger/firefly/pkg/identity"
+	"github.com/hyperledger/firefly/pkg/scanning"
 	"github.com/hyperledger/firefly/pkg/sharedstorage"
 	"github.com/hyperledger/firefly/pkg/tokens"
 )
@@ -62,6 +82,8 @@ var (
 	publicstorageConfig = config.NewPluginConfig("publicstorage")
 	dataexchangeConfig  = config.NewPluginConfig("dataexchange")
 	tokensConfig        = config.NewPluginConfig("tokens").Array()
+	eventsConfig        = config.NewPluginConfig("events")
+	scanningConfig      = config.NewPluginConfig("scanning")
 )
 
 // Orchestrator is the main interface behind the API, implementing the actions
@@ -71,6 +93,7 @@ type Orchestrator interface {
 	WaitStop() // The close itself is performed by canceling the context
 	Broadcast() broadcast.Manager
 	PrivateMessaging() privatemessaging.Manager
+	LocalMessaging() localmessaging.Manager
 	Events() events.EventManager
 	NetworkMap() networkmap.Manager
 	Data() data.Manager
@@ -79,10 +102,22 @@ type Orchestrator interface {
 	Metrics() metrics.Manager
 	BatchManager() batch.Manager
 	Operations() operations.Manager
+	Approvals() approvals.Manager
+	Swaps() swaps.Manager
+	Governance() governance.Manager
+	Ping() ping.Manager
+	Inbound() inbound.Manager
+	MailGateway() mailgateway.Manager
 	IsPreInit() bool
 
 	// Status
 	GetStatus(ctx context.Context) (*fftypes.NodeStatus, error)
+	GetStatusSummary(ctx context.Context, ns string) (*fftypes.NamespaceStatusSummary, error)
+	GetQueueDepths(ctx context.Context) (*fftypes.QueueDepths, error)
+
+	// Schema drift
+	GetTopicSchemas(ctx context.Context, ns string) ([]*fftypes.TopicSchema, error)
+	GetTopicSchema(ctx context.Context, ns, topic string) (*fftypes.TopicSchema, error)
 
 	// Subscription management
 	GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Subscription, *database.FilterResult, error)
@@ -90,6 +125,47 @@ type Orchestrator interface {
 	CreateSubscription(ctx context.Context, ns string, subDef *fftypes.Subscription) (*fftypes.Subscription, error)
 	CreateUpdateSubscription(ctx context.Context, ns string, subDef *fftypes.Subscription) (*fftypes.Subscription, error)
 	DeleteSubscription(ctx context.Context, ns, id string) error
+	TapSubscription(ctx context.Context, ns, id string, eventCount int) (<-chan *fftypes.SubscriptionTapRecord, error)
+	RewindSubscription(ctx context.Context, ns, id string, rewind *fftypes.SubscriptionRewind) (*fftypes.Offset, error)
+	DryRunSubscription(ctx context.Context, ns string, dryRun *fftypes.SubscriptionDryRun) ([]*fftypes.SubscriptionDryRunResult, error)
+	StreamEvents(ctx context.Context, ns, firstEvent string) (<-chan *fftypes.EnrichedEvent, error)
+
+	// Legal holds
+	GetLegalHolds(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.LegalHold, *database.FilterResult, error)
+	GetLegalHoldByID(ctx context.Context, ns, id string) (*fftypes.LegalHold, error)
+	PlaceLegalHold(ctx context.Context, ns string, input *fftypes.LegalHoldInput) (*fftypes.LegalHold, error)
+	ReleaseLegalHold(ctx context.Context, ns, id string, input *fftypes.LegalHoldRelease) (*fftypes.LegalHold, error)
+
+	// Quarantine
+	GetQuarantinedMessages(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.QuarantinedMessage, *database.FilterResult, error)
+	GetQuarantinedMessageByID(ctx context.Context, ns, id string) (*fftypes.QuarantinedMessage, error)
+	ReprocessQuarantinedMessage(ctx context.Context, ns, id string) (*fftypes.Message, error)
+
+	// Labels
+	UpdateMessageLabels(ctx context.Context, ns, id string, input *fftypes.LabelsUpdate) (*fftypes.Message, error)
+	UpdateDataLabels(ctx context.Context, ns, id string, input *fftypes.LabelsUpdate) (*fftypes.Data, error)
+
+	// Saved queries
+	SaveQuery(ctx context.Context, ns string, query *fftypes.SavedQuery) (*fftypes.SavedQuery, error)
+	GetSavedQueries(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.SavedQuery, *database.FilterResult, error)
+	GetSavedQueryByName(ctx context.Context, ns, name string) (*fftypes.SavedQuery, error)
+	DeleteSavedQuery(ctx context.Context, ns, name string) error
+
+	// Aliases
+	SaveAlias(ctx context.Context, ns string, alias *fftypes.Alias) (*fftypes.Alias, error)
+	GetAliases(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Alias, *database.FilterResult, error)
+	GetAliasByName(ctx context.Context, ns, name string) (*fftypes.Alias, error)
+	DeleteAlias(ctx context.Context, ns, name string) error
+
+	// Message templates
+	SaveMessageTemplate(ctx context.Context, ns string, template *fftypes.MessageTemplate) (*fftypes.MessageTemplate, error)
+	GetMessageTemplates(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.MessageTemplate, *database.FilterResult, error)
+	GetMessageTemplateByName(ctx context.Context, ns, name string) (*fftypes.MessageTemplate, error)
+	DeleteMessageTemplate(ctx context.Context, ns, name string) error
+
+	// Reports
+	GetReports(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Report, *database.FilterResult, error)
+	GetReportByID(ctx context.Context, ns, id string) (*fftypes.Report, error)
 
 	// Data Query
 	GetNamespace(ctx context.Context, ns string) (*fftypes.Namespace, error)
@@ -97,20 +173,30 @@ type Orchestrator interface {
 	GetTransactionByID(ctx context.Context, ns, id string) (*fftypes.Transaction, error)
 	GetTransactionOperations(ctx context.Context, ns, id string) ([]*fftypes.Operation, *database.FilterResult, error)
 	GetTransactionBlockchainEvents(ctx context.Context, ns, id string) ([]*fftypes.BlockchainEvent, *database.FilterResult, error)
+	GetTransactionBlockchainTransactions(ctx context.Context, ns, id string) ([]*fftypes.BlockchainTransaction, error)
 	GetTransactionStatus(ctx context.Context, ns, id string) (*fftypes.TransactionStatus, error)
 	GetTransactions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Transaction, *database.FilterResult, error)
+	GetTransactionsByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) ([]*fftypes.Transaction, error)
 	GetMessageByID(ctx context.Context, ns, id string) (*fftypes.Message, error)
+	GetMessagesByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) ([]*fftypes.Message, error)
 	GetMessageByIDWithData(ctx context.Context, ns, id string) (*fftypes.MessageInOut, error)
+	GetMessageByIDWithIncludes(ctx context.Context, ns, id string, includes []string) (*fftypes.MessageWithIncludes, error)
 	GetMessages(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Message, *database.FilterResult, error)
 	GetMessagesWithData(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.MessageInOut, *database.FilterResult, error)
+	GetMessageStates(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.MessageStateRecord, *database.FilterResult, error)
 	GetMessageTransaction(ctx context.Context, ns, id string) (*fftypes.Transaction, error)
 	GetMessageOperations(ctx context.Context, ns, id string) ([]*fftypes.Operation, *database.FilterResult, error)
 	GetMessageEvents(ctx context.Context, ns, id string, filter database.AndFilter) ([]*fftypes.Event, *database.FilterResult, error)
 	GetMessageData(ctx context.Context, ns, id string) (fftypes.DataArray, error)
 	GetMessagesForData(ctx context.Context, ns, dataID string, filter database.AndFilter) ([]*fftypes.Message, *database.FilterResult, error)
+	GetMessageApprovals(ctx context.Context, ns, id string) ([]*fftypes.MessageApproval, *database.FilterResult, error)
+	GetApprovals(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.MessageApproval, *database.FilterResult, error)
 	GetBatchByID(ctx context.Context, ns, id string) (*fftypes.BatchPersisted, error)
 	GetBatches(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.BatchPersisted, *database.FilterResult, error)
+	GetBatchManifest(ctx context.Context, ns, id string) (*fftypes.BatchManifestStatus, error)
+	VerifyBatch(ctx context.Context, ns, id string) (*fftypes.BatchManifestStatus, error)
 	GetDataByID(ctx context.Context, ns, id string) (*fftypes.Data, error)
+	GetDataByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) (fftypes.DataArray, error)
 	GetData(ctx context.Context, ns string, filter database.AndFilter) (fftypes.DataArray, *database.FilterResult, error)
 	GetDatatypeByID(ctx context.Context, ns, id string) (*fftypes.Datatype, error)
 	GetDatatypeByName(ctx context.Context, ns, name, version string) (*fftypes.Datatype, error)
@@ -118,11 +204,13 @@ type Orchestrator interface {
 	GetOperationByID(ctx context.Context, ns, id string) (*fftypes.Operation, error)
 	GetOperations(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Operation, *database.FilterResult, error)
 	GetEventByID(ctx context.Context, ns, id string) (*fftypes.Event, error)
+	GetEventsByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) ([]*fftypes.Event, error)
 	GetEvents(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Event, *database.FilterResult, error)
 	GetEventsWithReferences(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.EnrichedEvent, *database.FilterResult, error)
 	GetBlockchainEventByID(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEvent, error)
 	GetBlockchainEvents(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.BlockchainEvent, *database.FilterResult, error)
 	GetPins(ctx context.Context, filter database.AndFilter) ([]*fftypes.Pin, *database.FilterResult, error)
+	GetChanges(ctx context.Context, ns string, since int64, limit int) (*fftypes.ChangeFeed, error)
 
 	// Charts
 	GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*fftypes.ChartHistogram, error)
@@ -135,8 +223,14 @@ type Orchestrator interface {
 	DeleteConfigRecord(ctx context.Context, key string) (err error)
 	ResetConfig(ctx context.Context)
 
+	// BatchPin contract migration
+	GetBatchPinMigration(ctx context.Context) *fftypes.BatchPinMigration
+	ActivateBatchPinMigration(ctx context.Context) (*fftypes.BatchPinMigration, error)
+
 	// Message Routing
-	RequestReply(ctx context.Context, ns string, msg *fftypes.MessageInOut) (reply *fftypes.MessageInOut, err error)
+	RequestReply(ctx context.Context, ns string, msg *fftypes.MessageInOut, timeout time.Duration) (reply *fftypes.MessageInOut, err error)
+	RequestReplyBroadcast(ctx context.Context, ns string, msg *fftypes.MessageInOut, timeout time.Duration) (reply *fftypes.MessageInOut, err error)
+	ValidateMessage(ctx context.Context, ns string, msg *fftypes.MessageInOut) (*fftypes.MessageValidationReport, error)
 }
 
 type orchestrator struct {
@@ -149,11 +243,13 @@ type orchestrator struct {
 	identityPlugin idplugin.Plugin
 	sharedstorage  sharedstorage.Plugin
 	dataexchange   dataexchange.Plugin
+	scanning       scanning.Plugin
 	events         events.EventManager
 	networkmap     networkmap.Manager
 	batch          batch.Manager
 	broadcast      broadcast.Manager
 	messaging      privatemessaging.Manager
+	localmessaging localmessaging.Manager
 	definitions    definitions.DefinitionHandlers
 	data           data.Manager
 	syncasync      syncasync.Bridge
@@ -166,6 +262,21 @@ type orchestrator struct {
 	node           *fftypes.UUID
 	metrics        metrics.Manager
 	operations     operations.Manager
+	approvals      approvals.Manager
+	stats          stats.Collector
+	notifications  notifications.Manager
+	reports        reports.Manager
+	swaps          swaps.Manager
+	governance     governance.Manager
+	ping           ping.Manager
+	canary         canary.Manager
+	schemadrift    schemadrift.Manager
+	inbound        inbound.Manager
+	ingestion      ingestion.Manager
+	mailgateway    mailgateway.Manager
+	bridging       bridging.Manager
+	gateway        gateway.Manager
+	replay         replay.Manager
 	txHelper       txcommon.Helper
 }
 
@@ -173,13 +284,7 @@ func NewOrchestrator() Orchestrator {
 	or := &orchestrator{}
 
 	// Initialize the config on all the factories
-	bifactory.InitPrefix(blockchainConfig)
-	difactory.InitPrefix(databaseConfig)
-	ssfactory.InitPrefix(sharedstorageConfig)
-	// For backward compatibility also init with the old "publicstorage" prefix
-	ssfactory.InitPrefix(publicstorageConfig)
-	dxfactory.InitPrefix(dataexchangeConfig)
-	tifactory.InitPrefix(tokensConfig)
+	InitConfig()
 
 	return or
 }
@@ -187,7 +292,10 @@ func NewOrchestrator() Orchestrator {
 func (or *orchestrator) Init(ctx context.Context, cancelCtx context.CancelFunc) (err error) {
 	or.ctx = ctx
 	or.cancelCtx = cancelCtx
-	err = or.initPlugins(ctx)
+	err = faults.Init(ctx)
+	if err == nil {
+		err = or.initPlugins(ctx)
+	}
 	if or.preInitMode {
 		return nil
 	}
@@ -201,6 +309,7 @@ func (or *orchestrator) Init(ctx context.Context, cancelCtx context.CancelFunc)
 	or.bc.bi = or.blockchain
 	or.bc.ei = or.events
 	or.bc.dx = or.dataexchange
+	or.bc.rl = or.replay
 	return err
 }
 
@@ -222,6 +331,9 @@ func (or *orchestrator) Start() error {
 	if err == nil {
 		err = or.messaging.Start()
 	}
+	if err == nil {
+		err = or.ping.Start()
+	}
 	if err == nil {
 		for _, el := range or.tokens {
 			if err = el.Start(); err != nil {
@@ -232,6 +344,15 @@ func (or *orchestrator) Start() error {
 	if err == nil {
 		err = or.metrics.Start()
 	}
+	if err == nil {
+		err = or.bridging.Start()
+	}
+	if err == nil {
+		err = or.gateway.Start()
+	}
+	if err == nil {
+		err = or.replay.Start(or.events, or.blockchain, or.dataexchange)
+	}
 	or.started = true
 	return err
 }
@@ -240,6 +361,10 @@ func (or *orchestrator) WaitStop() {
 	if !or.started {
 		return
 	}
+	if or.replay != nil {
+		or.replay.WaitStop()
+		or.replay = nil
+	}
 	if or.batch != nil {
 		or.batch.WaitStop()
 		or.batch = nil
@@ -252,6 +377,38 @@ func (or *orchestrator) WaitStop() {
 		or.data.WaitStop()
 		or.data = nil
 	}
+	if or.swaps != nil {
+		or.swaps.WaitStop()
+		or.swaps = nil
+	}
+	if or.reports != nil {
+		or.reports.WaitStop()
+		or.reports = nil
+	}
+	if or.canary != nil {
+		or.canary.WaitStop()
+		or.canary = nil
+	}
+	if or.schemadrift != nil {
+		or.schemadrift.WaitStop()
+		or.schemadrift = nil
+	}
+	if or.ingestion != nil {
+		or.ingestion.WaitStop()
+		or.ingestion = nil
+	}
+	if or.mailgateway != nil {
+		or.mailgateway.WaitStop()
+		or.mailgateway = nil
+	}
+	if or.notifications != nil {
+		or.notifications.WaitStop()
+		or.notifications = nil
+	}
+	if or.stats != nil {
+		or.stats.WaitStop()
+		or.stats = nil
+	}
 	or.started = false
 }
 
@@ -267,6 +424,10 @@ func (or *orchestrator) PrivateMessaging() privatemessaging.Manager {
 	return or.messaging
 }
 
+func (or *orchestrator) LocalMessaging() localmessaging.Manager {
+	return or.localmessaging
+}
+
 func (or *orchestrator) Events() events.EventManager {
 	return or.events
 }
@@ -299,6 +460,30 @@ func (or *orchestrator) Operations() operations.Manager {
 	return or.operations
 }
 
+func (or *orchestrator) Approvals() approvals.Manager {
+	return or.approvals
+}
+
+func (or *orchestrator) Swaps() swaps.Manager {
+	return or.swaps
+}
+
+func (or *orchestrator) Governance() governance.Manager {
+	return or.governance
+}
+
+func (or *orchestrator) Ping() ping.Manager {
+	return or.ping
+}
+
+func (or *orchestrator) Inbound() inbound.Manager {
+	return or.inbound
+}
+
+func (or *orchestrator) MailGateway() mailgateway.Manager {
+	return or.mailgateway
+}
+
 func (or *orchestrator) initDatabaseCheckPreinit(ctx context.Context) (err error) {
 	if or.database == nil {
 		diType := config.GetString(config.DatabaseType)
@@ -403,6 +588,19 @@ func (or *orchestrator) initPlugins(ctx context.Context) (err error) {
 		return err
 	}
 
+	if or.scanning == nil {
+		if scType := config.GetString(config.ScanningType); scType != "" {
+			if or.scanning, err = scfactory.GetPlugin(ctx, scType); err != nil {
+				return err
+			}
+		}
+	}
+	if or.scanning != nil {
+		if err = or.scanning.Init(ctx, scanningConfig.SubPrefix(or.scanning.Name())); err != nil {
+			return err
+		}
+	}
+
 	if or.tokens == nil {
 		or.tokens = make(map[string]tokens.Plugin)
 		tokensConfigArraySize := tokensConfig.ArraySize()
@@ -452,8 +650,15 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 		or.metrics = metrics.NewMetricsManager(ctx)
 	}
 
+	if or.replay == nil {
+		if or.replay, err = replay.NewReplayManager(ctx, or.sharedstorage); err != nil {
+			return err
+		}
+		or.sharedstorage = or.replay.SharedStorage()
+	}
+
 	if or.data == nil {
-		or.data, err = data.NewDataManager(ctx, or.database, or.sharedstorage, or.dataexchange)
+		or.data, err = data.NewDataManager(ctx, or.database, or.sharedstorage, or.dataexchange, or.scanning)
 		if err != nil {
 			return err
 		}
@@ -483,26 +688,52 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 		}
 	}
 
+	if or.approvals == nil {
+		if or.approvals, err = approvals.NewApprovalManager(ctx, or.database); err != nil {
+			return err
+		}
+	}
+
+	if or.stats == nil {
+		or.stats = stats.NewCollector(ctx, or.database)
+	}
+
+	if or.notifications == nil {
+		if or.notifications, err = notifications.NewNotificationManager(ctx, or.database, or.stats); err != nil {
+			return err
+		}
+	}
+
+	if or.reports == nil {
+		if or.reports, err = reports.NewReportManager(ctx, or.database); err != nil {
+			return err
+		}
+	}
+
 	or.syncasync = syncasync.NewSyncAsyncBridge(ctx, or.database, or.data)
 
 	if or.batchpin == nil {
-		if or.batchpin, err = batchpin.NewBatchPinSubmitter(ctx, or.database, or.identity, or.blockchain, or.metrics, or.operations); err != nil {
+		if or.batchpin, err = batchpin.NewBatchPinSubmitter(ctx, or.database, or.identity, or.blockchain, or.sharedstorage, or.metrics, or.operations); err != nil {
 			return err
 		}
 	}
 
 	if or.messaging == nil {
-		if or.messaging, err = privatemessaging.NewPrivateMessaging(ctx, or.database, or.identity, or.dataexchange, or.blockchain, or.batch, or.data, or.syncasync, or.batchpin, or.metrics, or.operations); err != nil {
+		if or.messaging, err = privatemessaging.NewPrivateMessaging(ctx, or.database, or.identity, or.dataexchange, or.blockchain, or.batch, or.data, or.syncasync, or.batchpin, or.metrics, or.operations, or.approvals); err != nil {
 			return err
 		}
 	}
 
 	if or.broadcast == nil {
-		if or.broadcast, err = broadcast.NewBroadcastManager(ctx, or.database, or.identity, or.data, or.blockchain, or.dataexchange, or.sharedstorage, or.batch, or.syncasync, or.batchpin, or.metrics, or.operations); err != nil {
+		if or.broadcast, err = broadcast.NewBroadcastManager(ctx, or.database, or.identity, or.data, or.blockchain, or.dataexchange, or.sharedstorage, or.batch, or.syncasync, or.batchpin, or.metrics, or.operations, or.approvals); err != nil {
 			return err
 		}
 	}
 
+	if or.localmessaging == nil {
+		or.localmessaging = localmessaging.NewLocalMessaging(ctx, or.identity, or.data, or.batch, or.syncasync)
+	}
+
 	if or.assets == nil {
 		or.assets, err = assets.NewAssetManager(ctx, or.database, or.identity, or.data, or.syncasync, or.broadcast, or.messaging, or.tokens, or.metrics, or.operations, or.txHelper)
 		if err != nil {
@@ -510,6 +741,12 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 		}
 	}
 
+	if or.swaps == nil {
+		if or.swaps, err = swaps.NewSwapManager(ctx, or.database, or.identity, or.assets); err != nil {
+			return err
+		}
+	}
+
 	if or.contracts == nil {
 		or.contracts, err = contracts.NewContractManager(ctx, or.database, or.broadcast, or.identity, or.blockchain, or.operations, or.txHelper)
 		if err != nil {
@@ -517,10 +754,16 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 		}
 	}
 
-	or.definitions = definitions.NewDefinitionHandlers(or.database, or.blockchain, or.dataexchange, or.data, or.identity, or.broadcast, or.messaging, or.assets, or.contracts)
+	if or.governance == nil {
+		if or.governance, err = governance.NewGovernanceManager(ctx, or.database, or.broadcast, or.blockchain); err != nil {
+			return err
+		}
+	}
+
+	or.definitions = definitions.NewDefinitionHandlers(or.database, or.blockchain, or.dataexchange, or.data, or.identity, or.broadcast, or.messaging, or.assets, or.contracts, or.governance)
 
 	if or.events == nil {
-		or.events, err = events.NewEventManager(ctx, or, or.sharedstorage, or.database, or.blockchain, or.identity, or.definitions, or.data, or.broadcast, or.messaging, or.assets, or.metrics, or.txHelper)
+		or.events, err = events.NewEventManager(ctx, or, or.sharedstorage, or.database, or.blockchain, or.identity, or.definitions, or.data, or.broadcast, or.messaging, or.assets, or.metrics, or.operations, or.txHelper)
 		if err != nil {
 			return err
 		}
@@ -533,6 +776,54 @@ func (or *orchestrator) initComponents(ctx context.Context) (err error) {
 		}
 	}
 
+	if or.ping == nil {
+		if or.ping, err = ping.NewPingManager(ctx, or.database, or.identity, or.messaging, or.networkmap, or.events); err != nil {
+			return err
+		}
+	}
+
+	if or.canary == nil {
+		if or.canary, err = canary.NewCanaryManager(ctx, or.database, or.identity, or.broadcast, or.messaging, or.metrics); err != nil {
+			return err
+		}
+	}
+
+	if or.schemadrift == nil {
+		if or.schemadrift, err = schemadrift.NewSchemaDriftManager(ctx, or.database, or.data); err != nil {
+			return err
+		}
+	}
+
+	if or.inbound == nil {
+		if or.inbound, err = inbound.NewInboundManager(ctx, or.broadcast, or.messaging); err != nil {
+			return err
+		}
+	}
+
+	if or.ingestion == nil {
+		if or.ingestion, err = ingestion.NewIngestionManager(ctx, or.data, or.broadcast, or.messaging); err != nil {
+			return err
+		}
+	}
+
+	if or.mailgateway == nil {
+		if or.mailgateway, err = mailgateway.NewMailGateway(ctx, or.database, or.data, or.broadcast, or.messaging); err != nil {
+			return err
+		}
+	}
+
+	if or.bridging == nil {
+		if or.bridging, err = bridging.NewBridgeManager(ctx, or.database, or.data, or.events, or.broadcast, or.messaging); err != nil {
+			return err
+		}
+	}
+
+	if or.gateway == nil {
+		if or.gateway, err = gateway.NewGatewayManager(ctx, or.database, or.data, or.events); err != nil {
+			return err
+		}
+	}
+
 	or.syncasync.Init(or.events)
 
 	return nil
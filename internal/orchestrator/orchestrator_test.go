@@ -24,24 +24,42 @@ import (
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/dataexchange/dxfactory"
 	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/internal/scanning/scfactory"
 	"github.com/hyperledger/firefly/internal/tokens/tifactory"
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
 	"github.com/hyperledger/firefly/mocks/assetmocks"
 	"github.com/hyperledger/firefly/mocks/batchmocks"
 	"github.com/hyperledger/firefly/mocks/batchpinmocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/bridgingmocks"
 	"github.com/hyperledger/firefly/mocks/broadcastmocks"
+	"github.com/hyperledger/firefly/mocks/canarymocks"
 	"github.com/hyperledger/firefly/mocks/contractmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/eventmocks"
+	"github.com/hyperledger/firefly/mocks/gatewaymocks"
+	"github.com/hyperledger/firefly/mocks/governancemocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/mocks/identitymocks"
+	"github.com/hyperledger/firefly/mocks/inboundmocks"
+	"github.com/hyperledger/firefly/mocks/ingestionmocks"
+	"github.com/hyperledger/firefly/mocks/localmessagingmocks"
+	"github.com/hyperledger/firefly/mocks/mailgatewaymocks"
 	"github.com/hyperledger/firefly/mocks/metricsmocks"
 	"github.com/hyperledger/firefly/mocks/networkmapmocks"
+	"github.com/hyperledger/firefly/mocks/notificationmocks"
 	"github.com/hyperledger/firefly/mocks/operationmocks"
+	"github.com/hyperledger/firefly/mocks/pingmocks"
 	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/mocks/replaymocks"
+	"github.com/hyperledger/firefly/mocks/reportmocks"
+	"github.com/hyperledger/firefly/mocks/scanningmocks"
+	"github.com/hyperledger/firefly/mocks/schemadriftmocks"
 	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/mocks/statsmocks"
+	"github.com/hyperledger/firefly/mocks/swapmocks"
 	"github.com/hyperledger/firefly/mocks/tokenmocks"
 	"github.com/hyperledger/firefly/mocks/txcommonmocks"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -72,6 +90,22 @@ type testOrchestrator struct {
 	mcm *contractmocks.Manager
 	mmi *metricsmocks.Manager
 	mom *operationmocks.Manager
+	mav *approvalmocks.Manager
+	mst *statsmocks.Collector
+	mnf *notificationmocks.Manager
+	mrp *reportmocks.Manager
+	msw *swapmocks.Manager
+	mgv *governancemocks.Manager
+	mpg *pingmocks.Manager
+	mcn *canarymocks.Manager
+	msd *schemadriftmocks.Manager
+	mib *inboundmocks.Manager
+	mig *ingestionmocks.Manager
+	mmg *mailgatewaymocks.Manager
+	mbg *bridgingmocks.Manager
+	mgw *gatewaymocks.Manager
+	mrl *replaymocks.Manager
+	mlm *localmessagingmocks.Manager
 	mbp *batchpinmocks.Submitter
 	mth *txcommonmocks.Helper
 }
@@ -101,6 +135,22 @@ func newTestOrchestrator() *testOrchestrator {
 		mcm: &contractmocks.Manager{},
 		mmi: &metricsmocks.Manager{},
 		mom: &operationmocks.Manager{},
+		mav: &approvalmocks.Manager{},
+		mst: &statsmocks.Collector{},
+		mnf: &notificationmocks.Manager{},
+		mrp: &reportmocks.Manager{},
+		msw: &swapmocks.Manager{},
+		mgv: &governancemocks.Manager{},
+		mpg: &pingmocks.Manager{},
+		mcn: &canarymocks.Manager{},
+		msd: &schemadriftmocks.Manager{},
+		mib: &inboundmocks.Manager{},
+		mig: &ingestionmocks.Manager{},
+		mmg: &mailgatewaymocks.Manager{},
+		mbg: &bridgingmocks.Manager{},
+		mgw: &gatewaymocks.Manager{},
+		mrl: &replaymocks.Manager{},
+		mlm: &localmessagingmocks.Manager{},
 		mbp: &batchpinmocks.Submitter{},
 		mth: &txcommonmocks.Helper{},
 	}
@@ -121,6 +171,22 @@ func newTestOrchestrator() *testOrchestrator {
 	tor.orchestrator.tokens = map[string]tokens.Plugin{"token": tor.mti}
 	tor.orchestrator.metrics = tor.mmi
 	tor.orchestrator.operations = tor.mom
+	tor.orchestrator.approvals = tor.mav
+	tor.orchestrator.stats = tor.mst
+	tor.orchestrator.notifications = tor.mnf
+	tor.orchestrator.reports = tor.mrp
+	tor.orchestrator.swaps = tor.msw
+	tor.orchestrator.governance = tor.mgv
+	tor.orchestrator.ping = tor.mpg
+	tor.orchestrator.canary = tor.mcn
+	tor.orchestrator.schemadrift = tor.msd
+	tor.orchestrator.inbound = tor.mib
+	tor.orchestrator.ingestion = tor.mig
+	tor.orchestrator.mailgateway = tor.mmg
+	tor.orchestrator.bridging = tor.mbg
+	tor.orchestrator.gateway = tor.mgw
+	tor.orchestrator.replay = tor.mrl
+	tor.orchestrator.localmessaging = tor.mlm
 	tor.orchestrator.batchpin = tor.mbp
 	tor.orchestrator.txHelper = tor.mth
 	tor.mdi.On("Name").Return("mock-di").Maybe()
@@ -327,6 +393,57 @@ func TestDataExchangePluginOldName(t *testing.T) {
 	assert.EqualError(t, err, "pop")
 }
 
+func TestBadScanningPlugin(t *testing.T) {
+	or := newTestOrchestrator()
+	config.Set(config.ScanningType, "wrong")
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mbi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mps.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	or.mdx.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.Regexp(t, "FF10538.*wrong", err)
+}
+
+func TestScanningInitFail(t *testing.T) {
+	or := newTestOrchestrator()
+	msc := &scanningmocks.Plugin{}
+	or.scanning = msc
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mbi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mps.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	or.mdx.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	msc.On("Name").Return("clamav")
+	msc.On("Init", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestScanningPluginOk(t *testing.T) {
+	or := newTestOrchestrator()
+	scfactory.InitPrefix(scanningConfig)
+	config.Set(config.ScanningType, "clamav")
+	or.mdi.On("GetConfigRecords", mock.Anything, mock.Anything, mock.Anything).Return([]*fftypes.ConfigRecord{}, nil, nil)
+	or.mdi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mbi.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mii.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mps.On("Init", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	or.mdx.On("Init", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	or.mdi.On("GetNamespace", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	ctx, cancelCtx := context.WithCancel(context.Background())
+	err := or.Init(ctx, cancelCtx)
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, "clamav", or.scanning.Name())
+}
+
 func TestBadTokensPlugin(t *testing.T) {
 	or := newTestOrchestrator()
 	tifactory.InitPrefix(tokensConfig)
@@ -466,6 +583,15 @@ func TestInitMessagingComponentFail(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitLocalMessagingComponent(t *testing.T) {
+	or := newTestOrchestrator()
+	or.localmessaging = nil
+	or.mba.On("RegisterDispatcher", "local", fftypes.TransactionTypeUnpinned, []fftypes.MessageType{fftypes.MessageTypeLocal}, mock.Anything, mock.Anything).Return()
+	err := or.initComponents(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, or.localmessaging)
+}
+
 func TestInitEventsComponentFail(t *testing.T) {
 	or := newTestOrchestrator()
 	or.database = nil
@@ -512,6 +638,33 @@ func TestInitMetricsComponent(t *testing.T) {
 	or.initComponents(context.Background())
 }
 
+func TestInitStatsComponent(t *testing.T) {
+	or := newTestOrchestrator()
+	or.stats = nil
+	or.initComponents(or.ctx)
+	assert.NotNil(t, or.stats)
+	or.cancelCtx()
+	or.stats.WaitStop()
+}
+
+func TestInitNotificationsComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.notifications = nil
+	config.Set(config.NotificationsChannels, fftypes.JSONObjectArray{
+		{"name": "ops", "type": "unknown"},
+	})
+	err := or.initComponents(or.ctx)
+	assert.Regexp(t, "FF10422", err)
+}
+
+func TestInitReportsComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.reports = nil
+	config.Set(config.ReportsPeriod, "yearly")
+	err := or.initComponents(or.ctx)
+	assert.Regexp(t, "FF10428", err)
+}
+
 func TestInitIdentityComponentFail(t *testing.T) {
 	or := newTestOrchestrator()
 	or.database = nil
@@ -529,6 +682,104 @@ func TestInitAssetsComponentFail(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitSwapsComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.swaps = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestInitGovernanceComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.governance = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestInitPingComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.ping = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestInitReplayComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.sharedstorage = nil
+	or.replay = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestInitReplayComponentDisabled(t *testing.T) {
+	or := newTestOrchestrator()
+	or.replay = nil
+	or.data = nil
+	or.database = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+	assert.Equal(t, or.mps, or.sharedstorage)
+}
+
+func TestInitCanaryComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.canary = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestInitSchemaDriftComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.schemadrift = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestInitInboundComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.inbound = nil
+	config.Set(config.InboundEndpoints, fftypes.JSONObjectArray{{"name": "!bad"}})
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10459", err)
+}
+
+func TestInitIngestionComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.ingestion = nil
+	config.Set(config.IngestionDirectories, fftypes.JSONObjectArray{{"name": "!bad"}})
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10463", err)
+}
+
+func TestInitMailGatewayComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.mailgateway = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestInitBridgingComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.bridging = nil
+	config.Set(config.BridgesRules, fftypes.JSONObjectArray{{"name": "!bad"}})
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10501", err)
+}
+
+func TestInitGatewayComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.gateway = nil
+	config.Set(config.GatewayRelays, fftypes.JSONObjectArray{{"name": "!bad"}})
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10502", err)
+}
+
 func TestInitContractsComponentFail(t *testing.T) {
 	or := newTestOrchestrator()
 	or.database = nil
@@ -553,6 +804,14 @@ func TestInitOperationsComponentFail(t *testing.T) {
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestInitApprovalsComponentFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.database = nil
+	or.approvals = nil
+	err := or.initComponents(context.Background())
+	assert.Regexp(t, "FF10128", err)
+}
+
 func TestStartBatchFail(t *testing.T) {
 	config.Reset()
 	or := newTestOrchestrator()
@@ -570,6 +829,7 @@ func TestStartTokensFail(t *testing.T) {
 	or.mem.On("Start").Return(nil)
 	or.mbm.On("Start").Return(nil)
 	or.mpm.On("Start").Return(nil)
+	or.mpg.On("Start").Return(nil)
 	or.mam.On("Start").Return(nil)
 	or.mti.On("Start").Return(fmt.Errorf("pop"))
 	err := or.Start()
@@ -584,9 +844,13 @@ func TestStartStopOk(t *testing.T) {
 	or.mem.On("Start").Return(nil)
 	or.mbm.On("Start").Return(nil)
 	or.mpm.On("Start").Return(nil)
+	or.mpg.On("Start").Return(nil)
 	or.mam.On("Start").Return(nil)
 	or.mti.On("Start").Return(nil)
 	or.mmi.On("Start").Return(nil)
+	or.mbg.On("Start").Return(nil)
+	or.mgw.On("Start").Return(nil)
+	or.mrl.On("Start", or.mem, or.mbi, or.mdx).Return(nil)
 	or.mbi.On("WaitStop").Return(nil)
 	or.mba.On("WaitStop").Return(nil)
 	or.mem.On("WaitStop").Return(nil)
@@ -594,6 +858,15 @@ func TestStartStopOk(t *testing.T) {
 	or.mam.On("WaitStop").Return(nil)
 	or.mti.On("WaitStop").Return(nil)
 	or.mdm.On("WaitStop").Return(nil)
+	or.mst.On("WaitStop").Return(nil)
+	or.mnf.On("WaitStop").Return(nil)
+	or.mrp.On("WaitStop").Return(nil)
+	or.msw.On("WaitStop").Return(nil)
+	or.mcn.On("WaitStop").Return(nil)
+	or.msd.On("WaitStop").Return(nil)
+	or.mig.On("WaitStop").Return(nil)
+	or.mmg.On("WaitStop").Return(nil)
+	or.mrl.On("WaitStop").Return(nil)
 	err := or.Start()
 	assert.NoError(t, err)
 	or.WaitStop()
@@ -679,6 +952,7 @@ func TestInitOK(t *testing.T) {
 	assert.False(t, or.IsPreInit())
 	assert.Equal(t, or.mbm, or.Broadcast())
 	assert.Equal(t, or.mpm, or.PrivateMessaging())
+	assert.Equal(t, or.mlm, or.LocalMessaging())
 	assert.Equal(t, or.mem, or.Events())
 	assert.Equal(t, or.mba, or.BatchManager())
 	assert.Equal(t, or.mnm, or.NetworkMap())
@@ -687,6 +961,12 @@ func TestInitOK(t *testing.T) {
 	assert.Equal(t, or.mcm, or.Contracts())
 	assert.Equal(t, or.mmi, or.Metrics())
 	assert.Equal(t, or.mom, or.Operations())
+	assert.Equal(t, or.mav, or.Approvals())
+	assert.Equal(t, or.msw, or.Swaps())
+	assert.Equal(t, or.mgv, or.Governance())
+	assert.Equal(t, or.mpg, or.Ping())
+	assert.Equal(t, or.mib, or.Inbound())
+	assert.Equal(t, or.mmg, or.MailGateway())
 }
 
 func TestInitDataExchangeGetNodesFail(t *testing.T) {
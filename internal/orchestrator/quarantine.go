@@ -0,0 +1,106 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (or *orchestrator) GetQuarantinedMessages(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.QuarantinedMessage, *database.FilterResult, error) {
+	filter = or.scopeNS(ns, filter)
+	return or.database.GetQuarantinedMessages(ctx, filter)
+}
+
+func (or *orchestrator) GetQuarantinedMessageByID(ctx context.Context, ns, id string) (*fftypes.QuarantinedMessage, error) {
+	u, err := or.verifyIDAndNamespace(ctx, ns, id)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := or.database.GetQuarantinedMessageByID(ctx, u)
+	if err == nil && entry != nil && entry.Namespace != ns {
+		return nil, nil
+	}
+	return entry, err
+}
+
+// ReprocessQuarantinedMessage re-runs validation of a quarantined message, for example after a missing datatype
+// has been broadcast. If it now passes, the message is confirmed and removed from quarantine.
+func (or *orchestrator) ReprocessQuarantinedMessage(ctx context.Context, ns, id string) (*fftypes.Message, error) {
+	entry, err := or.GetQuarantinedMessageByID(ctx, ns, id)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	msg, data, foundAll, err := or.data.GetMessageWithDataCached(ctx, entry.Message)
+	if err != nil {
+		return nil, err
+	}
+	if msg == nil || !foundAll {
+		return nil, i18n.NewError(ctx, i18n.MsgQuarantineMessageIncomplete, entry.Message)
+	}
+	valid, reason, err := or.data.ValidateAll(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, i18n.NewError(ctx, i18n.MsgQuarantineStillInvalid, entry.Message, reason)
+	}
+
+	// The confirmation event references the transaction of the batch the message arrived in
+	var tx *fftypes.UUID
+	if msg.BatchID != nil {
+		batch, err := or.database.GetBatchByID(ctx, msg.BatchID)
+		if err != nil {
+			return nil, err
+		}
+		if batch != nil {
+			tx = batch.TX.ID
+		}
+	}
+
+	confirmed := fftypes.Now()
+	err = or.database.RunAsGroup(ctx, func(ctx context.Context) error {
+		update := database.MessageQueryFactory.NewUpdate(ctx).
+			Set("state", fftypes.MessageStateConfirmed).
+			Set("confirmed", confirmed)
+		if err := or.database.UpdateMessage(ctx, msg.Header.ID, update); err != nil {
+			return err
+		}
+		for _, topic := range msg.Header.Topics {
+			event := fftypes.NewEvent(fftypes.EventTypeMessageConfirmed, ns, msg.Header.ID, tx, topic)
+			event.Correlator = msg.Header.CID
+			if err := or.database.InsertEvent(ctx, event); err != nil {
+				return err
+			}
+		}
+		return or.database.DeleteQuarantinedMessage(ctx, entry.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	msg.State = fftypes.MessageStateConfirmed
+	msg.Confirmed = confirmed
+	or.data.UpdateMessageIfCached(ctx, msg)
+	log.L(ctx).Infof("Quarantined message %s reprocessed and confirmed", msg.Header.ID)
+	return msg, nil
+}
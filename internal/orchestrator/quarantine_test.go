@@ -0,0 +1,194 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestQuarantine() (*fftypes.QuarantinedMessage, *fftypes.Message) {
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:        fftypes.NewUUID(),
+			CID:       fftypes.NewUUID(),
+			Namespace: "ns1",
+			Topics:    fftypes.FFStringArray{"topic1", "topic2"},
+		},
+		BatchID: fftypes.NewUUID(),
+		State:   fftypes.MessageStateQuarantined,
+	}
+	entry := &fftypes.QuarantinedMessage{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		Message:   msg.Header.ID,
+		Batch:     msg.BatchID,
+		Reason:    "pop",
+	}
+	return entry, msg
+}
+
+func TestGetQuarantinedMessages(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetQuarantinedMessages", mock.Anything, mock.Anything).Return([]*fftypes.QuarantinedMessage{}, nil, nil)
+	fb := database.QuarantineQueryFactory.NewFilter(or.ctx)
+	f := fb.And(fb.Eq("reason", "pop"))
+	_, _, err := or.GetQuarantinedMessages(or.ctx, "ns1", f)
+	assert.NoError(t, err)
+}
+
+func TestGetQuarantinedMessageByID(t *testing.T) {
+	or := newTestOrchestrator()
+	entry, _ := newTestQuarantine()
+	or.mdi.On("GetQuarantinedMessageByID", mock.Anything, entry.ID).Return(entry, nil)
+	res, err := or.GetQuarantinedMessageByID(or.ctx, "ns1", entry.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, entry, res)
+}
+
+func TestGetQuarantinedMessageByIDBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetQuarantinedMessageByID(or.ctx, "ns1", "bad")
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestGetQuarantinedMessageByIDWrongNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	entry, _ := newTestQuarantine()
+	or.mdi.On("GetQuarantinedMessageByID", mock.Anything, entry.ID).Return(entry, nil)
+	res, err := or.GetQuarantinedMessageByID(or.ctx, "ns2", entry.ID.String())
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestReprocessQuarantinedMessageOk(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	entry, msg := newTestQuarantine()
+	txID := fftypes.NewUUID()
+	or.mdi.On("GetQuarantinedMessageByID", mock.Anything, entry.ID).Return(entry, nil)
+	or.mdm.On("GetMessageWithDataCached", mock.Anything, msg.Header.ID).Return(msg, fftypes.DataArray{}, true, nil)
+	or.mdm.On("ValidateAll", mock.Anything, fftypes.DataArray{}).Return(true, "", nil)
+	or.mdi.On("GetBatchByID", mock.Anything, msg.BatchID).Return(&fftypes.BatchPersisted{
+		TX: fftypes.TransactionRef{ID: txID},
+	}, nil)
+	or.mdi.On("UpdateMessage", mock.Anything, msg.Header.ID, mock.Anything).Return(nil)
+	or.mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeMessageConfirmed && e.Reference.Equals(msg.Header.ID) &&
+			e.Transaction.Equals(txID) && e.Correlator.Equals(msg.Header.CID)
+	})).Return(nil).Twice()
+	or.mdi.On("DeleteQuarantinedMessage", mock.Anything, entry.ID).Return(nil)
+	or.mdm.On("UpdateMessageIfCached", mock.Anything, msg).Return()
+
+	res, err := or.ReprocessQuarantinedMessage(or.ctx, "ns1", entry.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateConfirmed, res.State)
+	assert.NotNil(t, res.Confirmed)
+
+	or.mdi.AssertExpectations(t)
+	or.mdm.AssertExpectations(t)
+}
+
+func TestReprocessQuarantinedMessageNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	entry, _ := newTestQuarantine()
+	or.mdi.On("GetQuarantinedMessageByID", mock.Anything, entry.ID).Return(nil, nil)
+	res, err := or.ReprocessQuarantinedMessage(or.ctx, "ns1", entry.ID.String())
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
+
+func TestReprocessQuarantinedMessageGetMessageFail(t *testing.T) {
+	or := newTestOrchestrator()
+	entry, msg := newTestQuarantine()
+	or.mdi.On("GetQuarantinedMessageByID", mock.Anything, entry.ID).Return(entry, nil)
+	or.mdm.On("GetMessageWithDataCached", mock.Anything, msg.Header.ID).Return(nil, nil, false, fmt.Errorf("pop"))
+	_, err := or.ReprocessQuarantinedMessage(or.ctx, "ns1", entry.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestReprocessQuarantinedMessageIncomplete(t *testing.T) {
+	or := newTestOrchestrator()
+	entry, msg := newTestQuarantine()
+	or.mdi.On("GetQuarantinedMessageByID", mock.Anything, entry.ID).Return(entry, nil)
+	or.mdm.On("GetMessageWithDataCached", mock.Anything, msg.Header.ID).Return(msg, fftypes.DataArray{}, false, nil)
+	_, err := or.ReprocessQuarantinedMessage(or.ctx, "ns1", entry.ID.String())
+	assert.Regexp(t, "FF10520", err)
+}
+
+func TestReprocessQuarantinedMessageValidateFail(t *testing.T) {
+	or := newTestOrchestrator()
+	entry, msg := newTestQuarantine()
+	or.mdi.On("GetQuarantinedMessageByID", mock.Anything, entry.ID).Return(entry, nil)
+	or.mdm.On("GetMessageWithDataCached", mock.Anything, msg.Header.ID).Return(msg, fftypes.DataArray{}, true, nil)
+	or.mdm.On("ValidateAll", mock.Anything, fftypes.DataArray{}).Return(false, "", fmt.Errorf("pop"))
+	_, err := or.ReprocessQuarantinedMessage(or.ctx, "ns1", entry.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestReprocessQuarantinedMessageStillInvalid(t *testing.T) {
+	or := newTestOrchestrator()
+	entry, msg := newTestQuarantine()
+	or.mdi.On("GetQuarantinedMessageByID", mock.Anything, entry.ID).Return(entry, nil)
+	or.mdm.On("GetMessageWithDataCached", mock.Anything, msg.Header.ID).Return(msg, fftypes.DataArray{}, true, nil)
+	or.mdm.On("ValidateAll", mock.Anything, fftypes.DataArray{}).Return(false, "bad data", nil)
+	_, err := or.ReprocessQuarantinedMessage(or.ctx, "ns1", entry.ID.String())
+	assert.Regexp(t, "FF10521.*bad data", err)
+}
+
+func TestReprocessQuarantinedMessageGetBatchFail(t *testing.T) {
+	or := newTestOrchestrator()
+	entry, msg := newTestQuarantine()
+	or.mdi.On("GetQuarantinedMessageByID", mock.Anything, entry.ID).Return(entry, nil)
+	or.mdm.On("GetMessageWithDataCached", mock.Anything, msg.Header.ID).Return(msg, fftypes.DataArray{}, true, nil)
+	or.mdm.On("ValidateAll", mock.Anything, fftypes.DataArray{}).Return(true, "", nil)
+	or.mdi.On("GetBatchByID", mock.Anything, msg.BatchID).Return(nil, fmt.Errorf("pop"))
+	_, err := or.ReprocessQuarantinedMessage(or.ctx, "ns1", entry.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestReprocessQuarantinedMessageUpdateFail(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	entry, msg := newTestQuarantine()
+	or.mdi.On("GetQuarantinedMessageByID", mock.Anything, entry.ID).Return(entry, nil)
+	or.mdm.On("GetMessageWithDataCached", mock.Anything, msg.Header.ID).Return(msg, fftypes.DataArray{}, true, nil)
+	or.mdm.On("ValidateAll", mock.Anything, fftypes.DataArray{}).Return(true, "", nil)
+	or.mdi.On("GetBatchByID", mock.Anything, msg.BatchID).Return(nil, nil)
+	or.mdi.On("UpdateMessage", mock.Anything, msg.Header.ID, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := or.ReprocessQuarantinedMessage(or.ctx, "ns1", entry.ID.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestReprocessQuarantinedMessageInsertEventFail(t *testing.T) {
+	or := newTestOrchestrator()
+	passthroughRunAsGroup(or.mdi)
+	entry, msg := newTestQuarantine()
+	msg.BatchID = nil
+	or.mdi.On("GetQuarantinedMessageByID", mock.Anything, entry.ID).Return(entry, nil)
+	or.mdm.On("GetMessageWithDataCached", mock.Anything, msg.Header.ID).Return(msg, fftypes.DataArray{}, true, nil)
+	or.mdm.On("ValidateAll", mock.Anything, fftypes.DataArray{}).Return(true, "", nil)
+	or.mdi.On("UpdateMessage", mock.Anything, msg.Header.ID, mock.Anything).Return(nil)
+	or.mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := or.ReprocessQuarantinedMessage(or.ctx, "ns1", entry.ID.String())
+	assert.EqualError(t, err, "pop")
+}
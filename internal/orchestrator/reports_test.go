@@ -0,0 +1,68 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetReports(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetReports", mock.Anything, mock.Anything).Return([]*fftypes.Report{}, nil, nil)
+	fb := database.ReportQueryFactory.NewFilter(or.ctx)
+	f := fb.And(fb.Eq("period", "monthly"))
+	_, _, err := or.GetReports(or.ctx, "ns1", f)
+	assert.NoError(t, err)
+}
+
+func TestGetReportByID(t *testing.T) {
+	or := newTestOrchestrator()
+	id := fftypes.NewUUID()
+	or.mdi.On("GetReportByID", mock.Anything, id).Return(&fftypes.Report{ID: id, Namespace: "ns1"}, nil)
+	report, err := or.GetReportByID(or.ctx, "ns1", id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, id, report.ID)
+}
+
+func TestGetReportByIDWrongNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	id := fftypes.NewUUID()
+	or.mdi.On("GetReportByID", mock.Anything, id).Return(&fftypes.Report{ID: id, Namespace: "ns2"}, nil)
+	report, err := or.GetReportByID(or.ctx, "ns1", id.String())
+	assert.NoError(t, err)
+	assert.Nil(t, report)
+}
+
+func TestGetReportByIDFail(t *testing.T) {
+	or := newTestOrchestrator()
+	id := fftypes.NewUUID()
+	or.mdi.On("GetReportByID", mock.Anything, id).Return(nil, fmt.Errorf("pop"))
+	_, err := or.GetReportByID(or.ctx, "ns1", id.String())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetReportByIDBadID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetReportByID(or.ctx, "ns1", "bad")
+	assert.Regexp(t, "FF10142", err)
+}
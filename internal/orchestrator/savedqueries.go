@@ -0,0 +1,64 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// SaveQuery creates or replaces a saved query. The filter is validated against the collection by the
+// API layer before it is saved, as the filter syntax is that of the REST API.
+func (or *orchestrator) SaveQuery(ctx context.Context, ns string, query *fftypes.SavedQuery) (*fftypes.SavedQuery, error) {
+	query.Namespace = ns
+	if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	if err := fftypes.ValidateFFNameFieldNoUUID(ctx, query.Name, "name"); err != nil {
+		return nil, err
+	}
+	if err := or.database.UpsertSavedQuery(ctx, query); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+func (or *orchestrator) GetSavedQueries(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.SavedQuery, *database.FilterResult, error) {
+	filter = or.scopeNS(ns, filter)
+	return or.database.GetSavedQueries(ctx, filter)
+}
+
+func (or *orchestrator) GetSavedQueryByName(ctx context.Context, ns, name string) (*fftypes.SavedQuery, error) {
+	if err := or.verifyNamespaceSyntax(ctx, ns); err != nil {
+		return nil, err
+	}
+	return or.database.GetSavedQueryByName(ctx, ns, name)
+}
+
+func (or *orchestrator) DeleteSavedQuery(ctx context.Context, ns, name string) error {
+	query, err := or.GetSavedQueryByName(ctx, ns, name)
+	if err != nil {
+		return err
+	}
+	if query == nil {
+		return i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return or.database.DeleteSavedQuery(ctx, query.ID)
+}
@@ -0,0 +1,104 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSaveQueryOk(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("UpsertSavedQuery", mock.Anything, mock.MatchedBy(func(q *fftypes.SavedQuery) bool {
+		return q.Namespace == "ns1" && q.Name == "query1"
+	})).Return(nil)
+	query, err := or.SaveQuery(context.Background(), "ns1", &fftypes.SavedQuery{
+		Name:       "query1",
+		Collection: "messages",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", query.Namespace)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestSaveQueryBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(fmt.Errorf("pop"))
+	_, err := or.SaveQuery(context.Background(), "ns1", &fftypes.SavedQuery{Name: "query1"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSaveQueryBadName(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	_, err := or.SaveQuery(context.Background(), "ns1", &fftypes.SavedQuery{Name: "!bad"})
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestSaveQueryFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdi.On("UpsertSavedQuery", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := or.SaveQuery(context.Background(), "ns1", &fftypes.SavedQuery{Name: "query1"})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetSavedQueries(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSavedQueries", mock.Anything, mock.Anything).Return([]*fftypes.SavedQuery{}, nil, nil)
+	fb := database.SavedQueryQueryFactory.NewFilter(context.Background())
+	f := fb.And(fb.Eq("collection", "messages"))
+	_, _, err := or.GetSavedQueries(context.Background(), "ns1", f)
+	assert.NoError(t, err)
+}
+
+func TestGetSavedQueryByNameBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.GetSavedQueryByName(context.Background(), "!bad", "query1")
+	assert.Regexp(t, "FF10131", err)
+}
+
+func TestDeleteSavedQueryOk(t *testing.T) {
+	or := newTestOrchestrator()
+	queryID := fftypes.NewUUID()
+	or.mdi.On("GetSavedQueryByName", mock.Anything, "ns1", "query1").Return(&fftypes.SavedQuery{ID: queryID}, nil)
+	or.mdi.On("DeleteSavedQuery", mock.Anything, queryID).Return(nil)
+	err := or.DeleteSavedQuery(context.Background(), "ns1", "query1")
+	assert.NoError(t, err)
+	or.mdi.AssertExpectations(t)
+}
+
+func TestDeleteSavedQueryLookupFail(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSavedQueryByName", mock.Anything, "ns1", "query1").Return(nil, fmt.Errorf("pop"))
+	err := or.DeleteSavedQuery(context.Background(), "ns1", "query1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestDeleteSavedQueryNotFound(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSavedQueryByName", mock.Anything, "ns1", "query1").Return(nil, nil)
+	err := or.DeleteSavedQuery(context.Background(), "ns1", "query1")
+	assert.Regexp(t, "FF10109", err)
+}
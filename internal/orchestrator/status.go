@@ -18,10 +18,12 @@ package orchestrator
 
 import (
 	"context"
+	"database/sql/driver"
 	"fmt"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
@@ -81,3 +83,31 @@ func (or *orchestrator) GetStatus(ctx context.Context) (status *fftypes.NodeStat
 
 	return status, nil
 }
+
+func (or *orchestrator) GetStatusSummary(ctx context.Context, ns string) (*fftypes.NamespaceStatusSummary, error) {
+	if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	return or.stats.GetSummary(ctx, ns)
+}
+
+// GetQueueDepths returns a snapshot of the internal queues of the node, which can be used to diagnose stalls
+func (or *orchestrator) GetQueueDepths(ctx context.Context) (*fftypes.QueueDepths, error) {
+	depths := &fftypes.QueueDepths{
+		Dispatchers:     or.events.QueueDepths(),
+		BatchProcessors: or.batch.QueueDepths(),
+		Created:         fftypes.Now(),
+	}
+	fb := database.OperationQueryFactory.NewFilterLimit(ctx, 1)
+	_, res, err := or.database.GetOperations(ctx, fb.And(
+		fb.In("type", []driver.Value{fftypes.OpTypeDataExchangeBatchSend, fftypes.OpTypeDataExchangeBlobSend}),
+		fb.Eq("status", fftypes.OpStatusPending),
+	).Count(true))
+	if err != nil {
+		return nil, err
+	}
+	if res != nil && res.TotalCount != nil {
+		depths.DataExchange.PendingOperations = *res.TotalCount
+	}
+	return depths, nil
+}
@@ -18,12 +18,15 @@ package orchestrator
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestGetStatusRegistered(t *testing.T) {
@@ -203,3 +206,64 @@ func TestGetStatusNodeError(t *testing.T) {
 	assert.Nil(t, or.GetNodeUUID(or.ctx))
 
 }
+
+func TestGetStatusSummary(t *testing.T) {
+	or := newTestOrchestrator()
+	summary := &fftypes.NamespaceStatusSummary{Namespace: "ns1"}
+	or.mdm.On("VerifyNamespaceExists", or.ctx, "ns1").Return(nil)
+	or.mst.On("GetSummary", or.ctx, "ns1").Return(summary, nil)
+
+	res, err := or.GetStatusSummary(or.ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, summary, res)
+}
+
+func TestGetStatusSummaryBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", or.ctx, "ns1").Return(fmt.Errorf("pop"))
+
+	_, err := or.GetStatusSummary(or.ctx, "ns1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetQueueDepths(t *testing.T) {
+	or := newTestOrchestrator()
+
+	or.mem.On("QueueDepths").Return([]*fftypes.DispatcherQueueDepth{{Connection: "conn1", Inflight: 5}})
+	or.mba.On("QueueDepths").Return([]*fftypes.BatchQueueDepth{{Name: "proc1", Assembling: 3}})
+	var total int64 = 2
+	or.mdi.On("GetOperations", or.ctx, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Count && strings.Contains(fi.String(), "( type IN ['dataexchange_batch_send','dataexchange_blob_send'] ) && ( status == 'Pending' )")
+	})).Return([]*fftypes.Operation{}, &database.FilterResult{TotalCount: &total}, nil)
+
+	depths, err := or.GetQueueDepths(or.ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, depths.Dispatchers[0].Inflight)
+	assert.Equal(t, 3, depths.BatchProcessors[0].Assembling)
+	assert.Equal(t, int64(2), depths.DataExchange.PendingOperations)
+	assert.NotNil(t, depths.Created)
+}
+
+func TestGetQueueDepthsNoCount(t *testing.T) {
+	or := newTestOrchestrator()
+
+	or.mem.On("QueueDepths").Return([]*fftypes.DispatcherQueueDepth{})
+	or.mba.On("QueueDepths").Return([]*fftypes.BatchQueueDepth{})
+	or.mdi.On("GetOperations", or.ctx, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+
+	depths, err := or.GetQueueDepths(or.ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, depths.DataExchange.PendingOperations)
+}
+
+func TestGetQueueDepthsFail(t *testing.T) {
+	or := newTestOrchestrator()
+
+	or.mem.On("QueueDepths").Return([]*fftypes.DispatcherQueueDepth{})
+	or.mba.On("QueueDepths").Return([]*fftypes.BatchQueueDepth{})
+	or.mdi.On("GetOperations", or.ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := or.GetQueueDepths(or.ctx)
+	assert.EqualError(t, err, "pop")
+}
@@ -41,6 +41,13 @@ func (or *orchestrator) createUpdateSubscription(ctx context.Context, ns string,
 	if err := or.data.VerifyNamespaceExists(ctx, subDef.Namespace); err != nil {
 		return nil, err
 	}
+	for _, ns := range subDef.Filter.Namespaces {
+		if ns != fftypes.SubscriptionAllNamespaces {
+			if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+				return nil, err
+			}
+		}
+	}
 	if err := fftypes.ValidateFFNameFieldNoUUID(ctx, subDef.Name, "name"); err != nil {
 		return nil, err
 	}
@@ -66,6 +73,66 @@ func (or *orchestrator) DeleteSubscription(ctx context.Context, ns, id string) e
 	return or.events.DeleteDurableSubscription(ctx, sub)
 }
 
+// TapSubscription attaches a debug tap to a live subscription, returning a stream of the decisions the
+// event dispatcher makes about the next events on that subscription. The stream is closed when the tap
+// completes, times out, or the context is cancelled.
+func (or *orchestrator) TapSubscription(ctx context.Context, ns, id string, eventCount int) (<-chan *fftypes.SubscriptionTapRecord, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := or.database.GetSubscriptionByID(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil || sub.Namespace != ns {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	return or.events.TapSubscription(ctx, sub.ID, eventCount)
+}
+
+// RewindSubscription replays the events of a durable subscription, from the event with the requested sequence onwards
+func (or *orchestrator) RewindSubscription(ctx context.Context, ns, id string, rewind *fftypes.SubscriptionRewind) (*fftypes.Offset, error) {
+	if rewind.Sequence == nil || *rewind.Sequence < 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgSubscriptionRewindSequence)
+	}
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := or.database.GetSubscriptionByID(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil || sub.Namespace != ns {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	// The offset is the last event delivered, so delivery starts at the event after it
+	return or.events.RewindDurableSubscription(ctx, sub, *rewind.Sequence-1)
+}
+
+// DryRunSubscription evaluates a candidate subscription filter against recent events, or a provided sample
+// of events, reporting which would be delivered without creating a subscription
+func (or *orchestrator) DryRunSubscription(ctx context.Context, ns string, dryRun *fftypes.SubscriptionDryRun) ([]*fftypes.SubscriptionDryRunResult, error) {
+	if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	return or.events.DryRunSubscription(ctx, ns, dryRun)
+}
+
+// StreamEvents streams every event in the namespace after the first event, without creating a subscription.
+// The stream is closed when the context is cancelled.
+func (or *orchestrator) StreamEvents(ctx context.Context, ns, firstEvent string) (<-chan *fftypes.EnrichedEvent, error) {
+	if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	var fe *fftypes.SubOptsFirstEvent
+	if firstEvent != "" {
+		fe = (*fftypes.SubOptsFirstEvent)(&firstEvent)
+	}
+	return or.events.StreamEvents(ctx, ns, fe)
+}
+
 func (or *orchestrator) GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Subscription, *database.FilterResult, error) {
 	filter = or.scopeNS(ns, filter)
 	return or.database.GetSubscriptions(ctx, filter)
@@ -39,6 +39,39 @@ func TestCreateSubscriptionBadNamespace(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 }
 
+func TestCreateSubscriptionBadFilterNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns2").Return(fmt.Errorf("pop"))
+	_, err := or.CreateSubscription(or.ctx, "ns1", &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			Name: "sub1",
+		},
+		Filter: fftypes.SubscriptionFilter{
+			Namespaces: []string{"ns2"},
+		},
+	})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestCreateSubscriptionMultipleNamespacesOk(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			Name: "monitor",
+		},
+		Filter: fftypes.SubscriptionFilter{
+			Namespaces: []string{"ns2", fftypes.SubscriptionAllNamespaces},
+		},
+	}
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns2").Return(nil)
+	or.mem.On("CreateUpdateDurableSubscription", mock.Anything, mock.Anything, true).Return(nil)
+	_, err := or.CreateSubscription(or.ctx, "ns1", sub)
+	assert.NoError(t, err)
+	or.mdm.AssertExpectations(t)
+}
+
 func TestCreateSubscriptionBadName(t *testing.T) {
 	or := newTestOrchestrator()
 	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
@@ -134,6 +167,145 @@ func TestDeleteSubscription(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestTapSubscriptionBadUUID(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.TapSubscription(or.ctx, "ns1", "! a UUID", 10)
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestTapSubscriptionLookupError(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := or.TapSubscription(or.ctx, "ns1", fftypes.NewUUID().String(), 10)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestTapSubscriptionNSMismatch(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	_, err := or.TapSubscription(or.ctx, "ns2", sub.ID.String(), 10)
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestTapSubscription(t *testing.T) {
+	or := newTestOrchestrator()
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	records := make(chan *fftypes.SubscriptionTapRecord)
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mem.On("TapSubscription", mock.Anything, sub.ID, 10).Return((<-chan *fftypes.SubscriptionTapRecord)(records), nil)
+	ch, err := or.TapSubscription(or.ctx, "ns1", sub.ID.String(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, (<-chan *fftypes.SubscriptionTapRecord)(records), ch)
+}
+
+func TestRewindSubscriptionNoSequence(t *testing.T) {
+	or := newTestOrchestrator()
+	_, err := or.RewindSubscription(or.ctx, "ns1", fftypes.NewUUID().String(), &fftypes.SubscriptionRewind{})
+	assert.Regexp(t, "FF10557", err)
+}
+
+func TestRewindSubscriptionBadUUID(t *testing.T) {
+	or := newTestOrchestrator()
+	seq := int64(100)
+	_, err := or.RewindSubscription(or.ctx, "ns1", "! a UUID", &fftypes.SubscriptionRewind{Sequence: &seq})
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestRewindSubscriptionLookupError(t *testing.T) {
+	or := newTestOrchestrator()
+	seq := int64(100)
+	or.mdi.On("GetSubscriptionByID", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := or.RewindSubscription(or.ctx, "ns1", fftypes.NewUUID().String(), &fftypes.SubscriptionRewind{Sequence: &seq})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRewindSubscriptionNSMismatch(t *testing.T) {
+	or := newTestOrchestrator()
+	seq := int64(100)
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	_, err := or.RewindSubscription(or.ctx, "ns2", sub.ID.String(), &fftypes.SubscriptionRewind{Sequence: &seq})
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestRewindSubscription(t *testing.T) {
+	or := newTestOrchestrator()
+	seq := int64(100)
+	sub := &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{
+			ID:        fftypes.NewUUID(),
+			Name:      "sub1",
+			Namespace: "ns1",
+		},
+	}
+	offset := &fftypes.Offset{Current: 99}
+	or.mdi.On("GetSubscriptionByID", mock.Anything, sub.ID).Return(sub, nil)
+	or.mem.On("RewindDurableSubscription", mock.Anything, sub, int64(99)).Return(offset, nil)
+	res, err := or.RewindSubscription(or.ctx, "ns1", sub.ID.String(), &fftypes.SubscriptionRewind{Sequence: &seq})
+	assert.NoError(t, err)
+	assert.Equal(t, offset, res)
+}
+
+func TestDryRunSubscriptionBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "!wrong").Return(fmt.Errorf("pop"))
+	_, err := or.DryRunSubscription(or.ctx, "!wrong", &fftypes.SubscriptionDryRun{})
+	assert.Regexp(t, "pop", err)
+}
+
+func TestDryRunSubscription(t *testing.T) {
+	or := newTestOrchestrator()
+	dryRun := &fftypes.SubscriptionDryRun{Limit: 10}
+	results := []*fftypes.SubscriptionDryRunResult{{Matched: true}}
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mem.On("DryRunSubscription", mock.Anything, "ns1", dryRun).Return(results, nil)
+	res, err := or.DryRunSubscription(or.ctx, "ns1", dryRun)
+	assert.NoError(t, err)
+	assert.Equal(t, results, res)
+}
+
+func TestStreamEventsBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "!wrong").Return(fmt.Errorf("pop"))
+	_, err := or.StreamEvents(or.ctx, "!wrong", "")
+	assert.Regexp(t, "pop", err)
+}
+
+func TestStreamEvents(t *testing.T) {
+	or := newTestOrchestrator()
+	events := make(<-chan *fftypes.EnrichedEvent)
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.mem.On("StreamEvents", mock.Anything, "ns1", (*fftypes.SubOptsFirstEvent)(nil)).Return(events, nil).Once()
+	or.mem.On("StreamEvents", mock.Anything, "ns1", mock.MatchedBy(func(fe *fftypes.SubOptsFirstEvent) bool {
+		return fe != nil && *fe == "12345"
+	})).Return(events, nil).Once()
+	res, err := or.StreamEvents(or.ctx, "ns1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, events, res)
+	_, err = or.StreamEvents(or.ctx, "ns1", "12345")
+	assert.NoError(t, err)
+	or.mem.AssertExpectations(t)
+}
+
 func TestGetSubscriptions(t *testing.T) {
 	or := newTestOrchestrator()
 	u := fftypes.NewUUID()
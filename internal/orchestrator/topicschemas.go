@@ -0,0 +1,37 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+func (or *orchestrator) GetTopicSchemas(ctx context.Context, ns string) ([]*fftypes.TopicSchema, error) {
+	if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	return or.schemadrift.GetTopicSchemas(ctx, ns), nil
+}
+
+func (or *orchestrator) GetTopicSchema(ctx context.Context, ns, topic string) (*fftypes.TopicSchema, error) {
+	if err := or.data.VerifyNamespaceExists(ctx, ns); err != nil {
+		return nil, err
+	}
+	return or.schemadrift.GetTopicSchema(ctx, ns, topic), nil
+}
@@ -0,0 +1,58 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTopicSchemas(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.msd.On("GetTopicSchemas", mock.Anything, "ns1").Return([]*fftypes.TopicSchema{{Topic: "topic1"}})
+	schemas, err := or.GetTopicSchemas(or.ctx, "ns1")
+	assert.NoError(t, err)
+	assert.Len(t, schemas, 1)
+}
+
+func TestGetTopicSchemasBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(fmt.Errorf("pop"))
+	_, err := or.GetTopicSchemas(or.ctx, "ns1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetTopicSchema(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(nil)
+	or.msd.On("GetTopicSchema", mock.Anything, "ns1", "topic1").Return(&fftypes.TopicSchema{Topic: "topic1"})
+	schema, err := or.GetTopicSchema(or.ctx, "ns1", "topic1")
+	assert.NoError(t, err)
+	assert.Equal(t, "topic1", schema.Topic)
+}
+
+func TestGetTopicSchemaBadNamespace(t *testing.T) {
+	or := newTestOrchestrator()
+	or.mdm.On("VerifyNamespaceExists", mock.Anything, "ns1").Return(fmt.Errorf("pop"))
+	_, err := or.GetTopicSchema(or.ctx, "ns1", "topic1")
+	assert.EqualError(t, err, "pop")
+}
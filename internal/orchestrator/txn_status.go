@@ -40,13 +40,14 @@ func pendingPlaceholder(t fftypes.TransactionStatusType) *fftypes.TransactionSta
 
 func txOperationStatus(op *fftypes.Operation) *fftypes.TransactionStatusDetails {
 	return &fftypes.TransactionStatusDetails{
-		Status:    op.Status,
-		Type:      fftypes.TransactionStatusTypeOperation,
-		SubType:   op.Type.String(),
-		Timestamp: op.Updated,
-		ID:        op.ID,
-		Error:     op.Error,
-		Info:      op.Output,
+		Status:       op.Status,
+		Type:         fftypes.TransactionStatusTypeOperation,
+		SubType:      op.Type.String(),
+		Timestamp:    op.Updated,
+		ID:           op.ID,
+		Error:        op.Error,
+		Info:         op.Output,
+		BlockchainTX: op.BlockchainTX,
 	}
 }
 
@@ -50,6 +50,11 @@ func TestGetTransactionStatusBatchPinSuccess(t *testing.T) {
 			Type:    fftypes.OpTypeBlockchainBatchPin,
 			Updated: fftypes.UnixTime(0),
 			Output:  fftypes.JSONObject{"transactionHash": "0x100"},
+			BlockchainTX: &fftypes.BlockchainTransaction{
+				ID:     "0x100",
+				Signer: "0x12345",
+				Status: fftypes.OpStatusSucceeded,
+			},
 		},
 	}
 	events := []*fftypes.BlockchainEvent{
@@ -102,7 +107,8 @@ func TestGetTransactionStatusBatchPinSuccess(t *testing.T) {
 				"status": "Succeeded",
 				"timestamp": "1970-01-01T00:00:00Z",
 				"id": "` + ops[0].ID.String() + `",
-				"info": {"transactionHash": "0x100"}
+				"info": {"transactionHash": "0x100"},
+				"blockchainTx": {"id": "0x100", "signer": "0x12345", "status": "Succeeded"}
 			}
 		]
 	}`)
@@ -0,0 +1,211 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ping
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/networkmap"
+	"github.com/hyperledger/firefly/internal/privatemessaging"
+	"github.com/hyperledger/firefly/internal/sysmessaging"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Manager measures the latency between members of the network. A ping is a pinned private message, with
+// no data, sent to another member org in the system namespace. Every node replies automatically to a ping
+// it receives, and the time taken for the ping to be confirmed, and for the reply to be confirmed, is
+// recorded on the node that sent the ping.
+type Manager interface {
+	SendPing(ctx context.Context, orgNameOrID string) (*fftypes.Ping, error)
+	GetPings(ctx context.Context, filter database.AndFilter) ([]*fftypes.Ping, *database.FilterResult, error)
+	GetPingByID(ctx context.Context, id string) (*fftypes.Ping, error)
+	Start() error
+}
+
+type pingManager struct {
+	ctx        context.Context
+	database   database.Plugin
+	identity   identity.Manager
+	messaging  privatemessaging.Manager
+	networkmap networkmap.Manager
+	sysevents  sysmessaging.SystemEvents
+}
+
+func NewPingManager(ctx context.Context, di database.Plugin, im identity.Manager, pm privatemessaging.Manager, nm networkmap.Manager, se sysmessaging.SystemEvents) (Manager, error) {
+	if di == nil || im == nil || pm == nil || nm == nil || se == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	return &pingManager{
+		ctx:        log.WithLogField(ctx, "role", "ping-manager"),
+		database:   di,
+		identity:   im,
+		messaging:  pm,
+		networkmap: nm,
+		sysevents:  se,
+	}, nil
+}
+
+func (pm *pingManager) Start() error {
+	return pm.sysevents.AddSystemEventListener(fftypes.SystemNamespace, pm.eventCallback)
+}
+
+func (pm *pingManager) SendPing(ctx context.Context, orgNameOrID string) (*fftypes.Ping, error) {
+	org, err := pm.networkmap.GetOrganizationByNameOrID(ctx, orgNameOrID)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	localOrg, err := pm.identity.GetNodeOwnerOrg(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if localOrg.ID.Equals(org.ID) {
+		return nil, i18n.NewError(ctx, i18n.MsgCannotPingSelf)
+	}
+
+	in := &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Tag:    fftypes.SystemTagPing,
+				Topics: fftypes.FFStringArray{fftypes.SystemTopicPing},
+			},
+		},
+		Group: &fftypes.InputGroup{
+			Members: []fftypes.MemberInput{
+				{Identity: org.DID},
+			},
+		},
+	}
+
+	// The ping must be recorded before it is sent, so it is known when the confirmation arrives
+	sender := pm.messaging.NewMessage(fftypes.SystemNamespace, in)
+	if err = sender.Prepare(ctx); err != nil {
+		return nil, err
+	}
+	ping := &fftypes.Ping{
+		ID:      in.Header.ID,
+		Target:  org.DID,
+		State:   fftypes.PingStatePending,
+		Created: fftypes.Now(),
+	}
+	if err = pm.database.InsertPing(ctx, ping); err != nil {
+		return nil, err
+	}
+	if err = sender.Send(ctx); err != nil {
+		return nil, err
+	}
+	return ping, nil
+}
+
+func (pm *pingManager) GetPings(ctx context.Context, filter database.AndFilter) ([]*fftypes.Ping, *database.FilterResult, error) {
+	return pm.database.GetPings(ctx, filter)
+}
+
+func (pm *pingManager) GetPingByID(ctx context.Context, id string) (*fftypes.Ping, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return pm.database.GetPingByID(ctx, u)
+}
+
+// eventCallback never returns an error, as that would prevent delivery of the event to other system listeners.
+// Latency measurement is best effort, so a failure only means a ping is never completed.
+func (pm *pingManager) eventCallback(event *fftypes.EventDelivery) error {
+	if event.Type != fftypes.EventTypeMessageConfirmed || event.Topic != fftypes.SystemTopicPing {
+		return nil
+	}
+	l := log.L(pm.ctx)
+	msg, err := pm.database.GetMessageByID(pm.ctx, event.Reference)
+	if err == nil && msg != nil {
+		switch msg.Header.Tag {
+		case fftypes.SystemTagPing:
+			err = pm.handlePingConfirmed(pm.ctx, msg)
+		case fftypes.SystemTagPong:
+			err = pm.handlePongConfirmed(pm.ctx, msg)
+		}
+	}
+	if err != nil {
+		l.Errorf("Failed to process ping message '%s': %s", event.Reference, err)
+	}
+	return nil
+}
+
+func (pm *pingManager) handlePingConfirmed(ctx context.Context, msg *fftypes.Message) error {
+	ping, err := pm.database.GetPingByID(ctx, msg.Header.ID)
+	if err != nil {
+		return err
+	}
+	if ping == nil {
+		return pm.sendPong(ctx, msg)
+	}
+	if ping.State != fftypes.PingStatePending {
+		return nil
+	}
+	ping.State = fftypes.PingStateConfirmed
+	ping.Confirmed = fftypes.Now()
+	ping.ChainLatency = fftypes.FFDuration(ping.Confirmed.Time().Sub(*ping.Created.Time()))
+	return pm.database.UpdatePing(ctx, ping)
+}
+
+func (pm *pingManager) sendPong(ctx context.Context, ping *fftypes.Message) error {
+	localOrg, err := pm.identity.GetNodeOwnerOrg(ctx)
+	if err != nil {
+		return err
+	}
+	if ping.Header.Author == localOrg.DID {
+		// Sent by another node of our own org, or the record of the ping has been lost
+		return nil
+	}
+	log.L(ctx).Infof("Replying to ping '%s' from '%s'", ping.Header.ID, ping.Header.Author)
+	return pm.messaging.NewMessage(fftypes.SystemNamespace, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				CID:    ping.Header.ID,
+				Group:  ping.Header.Group,
+				Tag:    fftypes.SystemTagPong,
+				Topics: fftypes.FFStringArray{fftypes.SystemTopicPing},
+			},
+		},
+	}).Send(ctx)
+}
+
+func (pm *pingManager) handlePongConfirmed(ctx context.Context, msg *fftypes.Message) error {
+	if msg.Header.CID == nil {
+		return nil
+	}
+	ping, err := pm.database.GetPingByID(ctx, msg.Header.CID)
+	if err != nil || ping == nil || ping.State == fftypes.PingStateCompleted {
+		return err
+	}
+	if msg.Header.Author != ping.Target {
+		log.L(ctx).Warnf("Ignoring reply '%s' to ping '%s' from '%s' - expected '%s'", msg.Header.ID, ping.ID, msg.Header.Author, ping.Target)
+		return nil
+	}
+	ping.State = fftypes.PingStateCompleted
+	ping.Reply = msg.Header.ID
+	ping.Completed = fftypes.Now()
+	ping.RoundTrip = fftypes.FFDuration(ping.Completed.Time().Sub(*ping.Created.Time()))
+	log.L(ctx).Infof("Ping '%s' to '%s' completed in %s", ping.ID, ping.Target, ping.RoundTrip.String())
+	return pm.database.UpdatePing(ctx, ping)
+}
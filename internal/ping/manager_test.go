@@ -0,0 +1,498 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ping
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/networkmapmocks"
+	"github.com/hyperledger/firefly/mocks/privatemessagingmocks"
+	"github.com/hyperledger/firefly/mocks/sysmessagingmocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestPing(t *testing.T) (*pingManager, func()) {
+	mdi := &databasemocks.Plugin{}
+	mim := &identitymanagermocks.Manager{}
+	mpm := &privatemessagingmocks.Manager{}
+	mnm := &networkmapmocks.Manager{}
+	mse := &sysmessagingmocks.SystemEvents{}
+	pm, err := NewPingManager(context.Background(), mdi, mim, mpm, mnm, mse)
+	assert.NoError(t, err)
+	return pm.(*pingManager), func() {
+		mdi.AssertExpectations(t)
+		mim.AssertExpectations(t)
+		mpm.AssertExpectations(t)
+		mnm.AssertExpectations(t)
+		mse.AssertExpectations(t)
+	}
+}
+
+func testOrg(name string) *fftypes.Identity {
+	return &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID:   fftypes.NewUUID(),
+			DID:  "did:firefly:org/" + name,
+			Type: fftypes.IdentityTypeOrg,
+			Name: name,
+		},
+	}
+}
+
+func testPingEvent(msgID *fftypes.UUID) *fftypes.EventDelivery {
+	return &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID:        fftypes.NewUUID(),
+				Type:      fftypes.EventTypeMessageConfirmed,
+				Namespace: fftypes.SystemNamespace,
+				Reference: msgID,
+				Topic:     fftypes.SystemTopicPing,
+			},
+		},
+	}
+}
+
+func testPingMessage(tag, author string, cid *fftypes.UUID) *fftypes.Message {
+	return &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:    fftypes.NewUUID(),
+			CID:   cid,
+			Group: fftypes.NewRandB32(),
+			Tag:   tag,
+			SignerRef: fftypes.SignerRef{
+				Author: author,
+			},
+		},
+	}
+}
+
+func TestNewPingManagerFail(t *testing.T) {
+	_, err := NewPingManager(context.Background(), nil, nil, nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestStart(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	mse := pm.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", fftypes.SystemNamespace, mock.Anything).Return(nil)
+
+	err := pm.Start()
+	assert.NoError(t, err)
+}
+
+func TestSendPingOk(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	org := testOrg("org2")
+	msgID := fftypes.NewUUID()
+	mnm := pm.networkmap.(*networkmapmocks.Manager)
+	mnm.On("GetOrganizationByNameOrID", pm.ctx, "org2").Return(org, nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(testOrg("org1"), nil)
+	mms := &sysmessagingmocks.MessageSender{}
+	mpm := pm.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("NewMessage", fftypes.SystemNamespace, mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		return in.Header.Tag == fftypes.SystemTagPing && in.Group.Members[0].Identity == org.DID
+	})).Run(func(args mock.Arguments) {
+		args[1].(*fftypes.MessageInOut).Header.ID = msgID
+	}).Return(mms)
+	mms.On("Prepare", pm.ctx).Return(nil)
+	mms.On("Send", pm.ctx).Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("InsertPing", pm.ctx, mock.MatchedBy(func(p *fftypes.Ping) bool {
+		return p.ID.Equals(msgID) && p.Target == org.DID && p.State == fftypes.PingStatePending
+	})).Return(nil)
+
+	ping, err := pm.SendPing(pm.ctx, "org2")
+	assert.NoError(t, err)
+	assert.Equal(t, msgID, ping.ID)
+
+	mms.AssertExpectations(t)
+}
+
+func TestSendPingSendFail(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	mnm := pm.networkmap.(*networkmapmocks.Manager)
+	mnm.On("GetOrganizationByNameOrID", pm.ctx, "org2").Return(testOrg("org2"), nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(testOrg("org1"), nil)
+	mms := &sysmessagingmocks.MessageSender{}
+	mpm := pm.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("NewMessage", fftypes.SystemNamespace, mock.Anything).Return(mms)
+	mms.On("Prepare", pm.ctx).Return(nil)
+	mms.On("Send", pm.ctx).Return(fmt.Errorf("pop"))
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("InsertPing", pm.ctx, mock.Anything).Return(nil)
+
+	_, err := pm.SendPing(pm.ctx, "org2")
+	assert.EqualError(t, err, "pop")
+
+	mms.AssertExpectations(t)
+}
+
+func TestSendPingInsertFail(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	mnm := pm.networkmap.(*networkmapmocks.Manager)
+	mnm.On("GetOrganizationByNameOrID", pm.ctx, "org2").Return(testOrg("org2"), nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(testOrg("org1"), nil)
+	mms := &sysmessagingmocks.MessageSender{}
+	mpm := pm.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("NewMessage", fftypes.SystemNamespace, mock.Anything).Return(mms)
+	mms.On("Prepare", pm.ctx).Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("InsertPing", pm.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := pm.SendPing(pm.ctx, "org2")
+	assert.EqualError(t, err, "pop")
+
+	mms.AssertExpectations(t)
+}
+
+func TestSendPingPrepareFail(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	mnm := pm.networkmap.(*networkmapmocks.Manager)
+	mnm.On("GetOrganizationByNameOrID", pm.ctx, "org2").Return(testOrg("org2"), nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(testOrg("org1"), nil)
+	mms := &sysmessagingmocks.MessageSender{}
+	mpm := pm.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("NewMessage", fftypes.SystemNamespace, mock.Anything).Return(mms)
+	mms.On("Prepare", pm.ctx).Return(fmt.Errorf("pop"))
+
+	_, err := pm.SendPing(pm.ctx, "org2")
+	assert.EqualError(t, err, "pop")
+
+	mms.AssertExpectations(t)
+}
+
+func TestSendPingSelf(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	org := testOrg("org1")
+	mnm := pm.networkmap.(*networkmapmocks.Manager)
+	mnm.On("GetOrganizationByNameOrID", pm.ctx, "org1").Return(org, nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(org, nil)
+
+	_, err := pm.SendPing(pm.ctx, "org1")
+	assert.Regexp(t, "FF10448", err)
+}
+
+func TestSendPingNoLocalOrg(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	mnm := pm.networkmap.(*networkmapmocks.Manager)
+	mnm.On("GetOrganizationByNameOrID", pm.ctx, "org2").Return(testOrg("org2"), nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(nil, fmt.Errorf("pop"))
+
+	_, err := pm.SendPing(pm.ctx, "org2")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestSendPingNotOrg(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	mnm := pm.networkmap.(*networkmapmocks.Manager)
+	mnm.On("GetOrganizationByNameOrID", pm.ctx, "node1").Return(nil, nil)
+
+	_, err := pm.SendPing(pm.ctx, "node1")
+	assert.Regexp(t, "FF10109", err)
+}
+
+func TestSendPingLookupFail(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	mnm := pm.networkmap.(*networkmapmocks.Manager)
+	mnm.On("GetOrganizationByNameOrID", pm.ctx, "org2").Return(nil, fmt.Errorf("pop"))
+
+	_, err := pm.SendPing(pm.ctx, "org2")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetPings(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetPings", pm.ctx, mock.Anything).Return([]*fftypes.Ping{}, nil, nil)
+
+	fb := database.PingQueryFactory.NewFilter(pm.ctx)
+	_, _, err := pm.GetPings(pm.ctx, fb.And())
+	assert.NoError(t, err)
+}
+
+func TestGetPingByID(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	id := fftypes.NewUUID()
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetPingByID", pm.ctx, id).Return(&fftypes.Ping{ID: id}, nil)
+
+	ping, err := pm.GetPingByID(pm.ctx, id.String())
+	assert.NoError(t, err)
+	assert.Equal(t, id, ping.ID)
+}
+
+func TestGetPingByIDBadUUID(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	_, err := pm.GetPingByID(pm.ctx, "!uuid")
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestEventCallbackIgnored(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	event := testPingEvent(fftypes.NewUUID())
+	event.Topic = fftypes.SystemTopicDefinitions
+	err := pm.eventCallback(event)
+	assert.NoError(t, err)
+}
+
+func TestEventCallbackMessageNotFound(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	event := testPingEvent(fftypes.NewUUID())
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, event.Reference).Return(nil, nil)
+
+	err := pm.eventCallback(event)
+	assert.NoError(t, err)
+}
+
+func TestEventCallbackMessageLookupFail(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	event := testPingEvent(fftypes.NewUUID())
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, event.Reference).Return(nil, fmt.Errorf("pop"))
+
+	err := pm.eventCallback(event)
+	assert.NoError(t, err)
+}
+
+func TestEventCallbackOtherTag(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	msg := testPingMessage("other", "did:firefly:org/org2", nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, msg.Header.ID).Return(msg, nil)
+
+	err := pm.eventCallback(testPingEvent(msg.Header.ID))
+	assert.NoError(t, err)
+}
+
+func TestPingConfirmedLocal(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	msg := testPingMessage(fftypes.SystemTagPing, "did:firefly:org/org1", nil)
+	created := fftypes.FFTime(time.Now().Add(-1 * time.Second))
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, msg.Header.ID).Return(msg, nil)
+	mdi.On("GetPingByID", pm.ctx, msg.Header.ID).Return(&fftypes.Ping{
+		ID:      msg.Header.ID,
+		State:   fftypes.PingStatePending,
+		Created: &created,
+	}, nil)
+	mdi.On("UpdatePing", pm.ctx, mock.MatchedBy(func(p *fftypes.Ping) bool {
+		return p.State == fftypes.PingStateConfirmed && p.Confirmed != nil && time.Duration(p.ChainLatency) >= time.Second
+	})).Return(nil)
+
+	err := pm.eventCallback(testPingEvent(msg.Header.ID))
+	assert.NoError(t, err)
+}
+
+func TestPingConfirmedLocalAlreadyConfirmed(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	msg := testPingMessage(fftypes.SystemTagPing, "did:firefly:org/org1", nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, msg.Header.ID).Return(msg, nil)
+	mdi.On("GetPingByID", pm.ctx, msg.Header.ID).Return(&fftypes.Ping{
+		ID:    msg.Header.ID,
+		State: fftypes.PingStateCompleted,
+	}, nil)
+
+	err := pm.eventCallback(testPingEvent(msg.Header.ID))
+	assert.NoError(t, err)
+}
+
+func TestPingConfirmedLookupFail(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	msg := testPingMessage(fftypes.SystemTagPing, "did:firefly:org/org1", nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, msg.Header.ID).Return(msg, nil)
+	mdi.On("GetPingByID", pm.ctx, msg.Header.ID).Return(nil, fmt.Errorf("pop"))
+
+	err := pm.eventCallback(testPingEvent(msg.Header.ID))
+	assert.NoError(t, err)
+}
+
+func TestPingConfirmedRemoteSendsPong(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	msg := testPingMessage(fftypes.SystemTagPing, "did:firefly:org/org1", nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, msg.Header.ID).Return(msg, nil)
+	mdi.On("GetPingByID", pm.ctx, msg.Header.ID).Return(nil, nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(testOrg("org2"), nil)
+	mms := &sysmessagingmocks.MessageSender{}
+	mpm := pm.messaging.(*privatemessagingmocks.Manager)
+	mpm.On("NewMessage", fftypes.SystemNamespace, mock.MatchedBy(func(in *fftypes.MessageInOut) bool {
+		return in.Header.Tag == fftypes.SystemTagPong && in.Header.CID.Equals(msg.Header.ID) && in.Header.Group.Equals(msg.Header.Group)
+	})).Return(mms)
+	mms.On("Send", pm.ctx).Return(fmt.Errorf("pop"))
+
+	err := pm.eventCallback(testPingEvent(msg.Header.ID))
+	assert.NoError(t, err)
+
+	mms.AssertExpectations(t)
+}
+
+func TestPingConfirmedRemoteOwnOrg(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	msg := testPingMessage(fftypes.SystemTagPing, "did:firefly:org/org1", nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, msg.Header.ID).Return(msg, nil)
+	mdi.On("GetPingByID", pm.ctx, msg.Header.ID).Return(nil, nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(testOrg("org1"), nil)
+
+	err := pm.eventCallback(testPingEvent(msg.Header.ID))
+	assert.NoError(t, err)
+}
+
+func TestPingConfirmedRemoteNoLocalOrg(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	msg := testPingMessage(fftypes.SystemTagPing, "did:firefly:org/org1", nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, msg.Header.ID).Return(msg, nil)
+	mdi.On("GetPingByID", pm.ctx, msg.Header.ID).Return(nil, nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(nil, fmt.Errorf("pop"))
+
+	err := pm.eventCallback(testPingEvent(msg.Header.ID))
+	assert.NoError(t, err)
+}
+
+func TestPongConfirmedOk(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	pingID := fftypes.NewUUID()
+	msg := testPingMessage(fftypes.SystemTagPong, "did:firefly:org/org2", pingID)
+	created := fftypes.FFTime(time.Now().Add(-2 * time.Second))
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, msg.Header.ID).Return(msg, nil)
+	mdi.On("GetPingByID", pm.ctx, pingID).Return(&fftypes.Ping{
+		ID:        pingID,
+		Target:    "did:firefly:org/org2",
+		State:     fftypes.PingStateConfirmed,
+		Created:   &created,
+		Confirmed: fftypes.Now(),
+	}, nil)
+	mdi.On("UpdatePing", pm.ctx, mock.MatchedBy(func(p *fftypes.Ping) bool {
+		return p.State == fftypes.PingStateCompleted && p.Reply.Equals(msg.Header.ID) && time.Duration(p.RoundTrip) >= 2*time.Second
+	})).Return(nil)
+
+	err := pm.eventCallback(testPingEvent(msg.Header.ID))
+	assert.NoError(t, err)
+}
+
+func TestPongConfirmedWrongAuthor(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	pingID := fftypes.NewUUID()
+	msg := testPingMessage(fftypes.SystemTagPong, "did:firefly:org/org3", pingID)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, msg.Header.ID).Return(msg, nil)
+	mdi.On("GetPingByID", pm.ctx, pingID).Return(&fftypes.Ping{
+		ID:     pingID,
+		Target: "did:firefly:org/org2",
+		State:  fftypes.PingStateConfirmed,
+	}, nil)
+
+	err := pm.eventCallback(testPingEvent(msg.Header.ID))
+	assert.NoError(t, err)
+}
+
+func TestPongConfirmedUnknownPing(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	pingID := fftypes.NewUUID()
+	msg := testPingMessage(fftypes.SystemTagPong, "did:firefly:org/org2", pingID)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, msg.Header.ID).Return(msg, nil)
+	mdi.On("GetPingByID", pm.ctx, pingID).Return(nil, nil)
+
+	err := pm.eventCallback(testPingEvent(msg.Header.ID))
+	assert.NoError(t, err)
+}
+
+func TestPongConfirmedNoCID(t *testing.T) {
+	pm, done := newTestPing(t)
+	defer done()
+
+	msg := testPingMessage(fftypes.SystemTagPong, "did:firefly:org/org2", nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetMessageByID", pm.ctx, msg.Header.ID).Return(msg, nil)
+
+	err := pm.eventCallback(testPingEvent(msg.Header.ID))
+	assert.NoError(t, err)
+}
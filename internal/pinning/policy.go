@@ -0,0 +1,114 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pinning
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"hash"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Rules are the pinning rules of a namespace
+type Rules struct {
+	// Deferred confirms private messages as soon as they are delivered by data exchange, and anchors their pins later
+	Deferred bool `json:"deferred"`
+	// Interval is how often the deferred pins are anchored, overriding batchpin.deferred.interval
+	Interval *fftypes.FFDuration `json:"interval,omitempty"`
+	// ContextSalt is shared privately between the members of the namespace, and salts the hashes of the topic and group
+	// contexts, so that observers of the chain cannot correlate the volume of activity on a business context
+	ContextSalt string `json:"contextSalt,omitempty"`
+}
+
+// Policy holds the pinning rules configured against each of the predefined namespaces.
+// Namespaces with deferred pinning trade delayed finality for latency - private messages are confirmed
+// off-chain on delivery, and reconciled when the pins of their batches are anchored on the blockchain.
+type Policy struct {
+	namespaces map[string]*Rules
+}
+
+func NewPolicy(ctx context.Context) (*Policy, error) {
+	p := &Policy{
+		namespaces: make(map[string]*Rules),
+	}
+	for i, nsObject := range config.GetObjectArray(config.NamespacesPredefined) {
+		rulesObject, ok := nsObject.GetObjectOk("pinning")
+		if !ok {
+			continue
+		}
+		var rules *Rules
+		b, _ := json.Marshal(rulesObject)
+		if err := json.Unmarshal(b, &rules); err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgPinningRulesInvalid, i)
+		}
+		if rules.Interval != nil && *rules.Interval <= 0 {
+			return nil, i18n.NewError(ctx, i18n.MsgPinningRulesInvalid, i)
+		}
+		p.namespaces[nsObject.GetString("name")] = rules
+	}
+	return p, nil
+}
+
+// IsDeferred returns true if private messages in the namespace are confirmed before their pins are anchored
+func (p *Policy) IsDeferred(ns string) bool {
+	rules, ok := p.namespaces[ns]
+	return ok && rules.Deferred
+}
+
+// DeferredNamespaces returns the sorted list of namespaces with deferred pinning
+func (p *Policy) DeferredNamespaces() []string {
+	namespaces := make([]string, 0)
+	for ns, rules := range p.namespaces {
+		if rules.Deferred {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// Interval returns how often the deferred pins of the namespace are anchored
+func (p *Policy) Interval(ns string) time.Duration {
+	if rules, ok := p.namespaces[ns]; ok && rules.Interval != nil {
+		return time.Duration(*rules.Interval)
+	}
+	return config.GetDuration(config.BatchPinDeferredInterval)
+}
+
+// ContextSalt returns the salt for the contexts of messages in the namespace, which is empty if contexts are not salted
+func (p *Policy) ContextSalt(ns string) string {
+	if rules, ok := p.namespaces[ns]; ok {
+		return rules.ContextSalt
+	}
+	return ""
+}
+
+// NewContextHash returns the hash used to calculate the contexts and pins of messages. Salted contexts use an HMAC
+// keyed with the salt, and unsalted contexts are a plain SHA-256 for compatibility with existing pins.
+func NewContextHash(salt string) hash.Hash {
+	if salt == "" {
+		return sha256.New()
+	}
+	return hmac.New(sha256.New, []byte(salt))
+}
@@ -0,0 +1,103 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pinning
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPolicyDefaults(t *testing.T) {
+	config.Reset()
+	p, err := NewPolicy(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, p.namespaces)
+	assert.False(t, p.IsDeferred("default"))
+	assert.Empty(t, p.DeferredNamespaces())
+	assert.Equal(t, time.Minute, p.Interval("default"))
+}
+
+func TestNewPolicyInvalid(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "pinning": fftypes.JSONObject{"deferred": "yes"}},
+	})
+	_, err := NewPolicy(context.Background())
+	assert.Regexp(t, "FF10515.*0", err)
+}
+
+func TestNewPolicyBadInterval(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "default"},
+		{"name": "ns1", "pinning": fftypes.JSONObject{"deferred": true, "interval": "0s"}},
+	})
+	_, err := NewPolicy(context.Background())
+	assert.Regexp(t, "FF10515.*1", err)
+}
+
+func TestDeferredNamespaces(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "default"},
+		{"name": "ns3", "pinning": fftypes.JSONObject{"deferred": true}},
+		{"name": "ns2", "pinning": fftypes.JSONObject{"deferred": false, "interval": "10s"}},
+		{"name": "ns1", "pinning": fftypes.JSONObject{"deferred": true, "interval": "30s"}},
+	})
+	p, err := NewPolicy(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"ns1", "ns3"}, p.DeferredNamespaces())
+	assert.True(t, p.IsDeferred("ns1"))
+	assert.False(t, p.IsDeferred("ns2"))
+	assert.True(t, p.IsDeferred("ns3"))
+	assert.False(t, p.IsDeferred("default"))
+	assert.Equal(t, 30*time.Second, p.Interval("ns1"))
+	assert.Equal(t, 10*time.Second, p.Interval("ns2"))
+	assert.Equal(t, time.Minute, p.Interval("ns3"))
+}
+
+func TestContextSalt(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "default"},
+		{"name": "ns1", "pinning": fftypes.JSONObject{"contextsalt": "secret"}},
+	})
+	p, err := NewPolicy(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "secret", p.ContextSalt("ns1"))
+	assert.Equal(t, "", p.ContextSalt("default"))
+}
+
+func TestNewContextHash(t *testing.T) {
+	unsalted := NewContextHash("")
+	unsalted.Write([]byte("topic1"))
+	expected := sha256.Sum256([]byte("topic1"))
+	assert.Equal(t, expected[:], unsalted.Sum(nil))
+
+	salted := NewContextHash("secret")
+	salted.Write([]byte("topic1"))
+	assert.NotEqual(t, expected[:], salted.Sum(nil))
+	assert.Len(t, salted.Sum(nil), 32)
+}
@@ -18,6 +18,7 @@ package privatemessaging
 
 import (
 	"context"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/i18n"
@@ -51,7 +52,7 @@ func (pm *privateMessaging) SendMessage(ctx context.Context, ns string, in *ffty
 	return &in.Message, err
 }
 
-func (pm *privateMessaging) RequestReply(ctx context.Context, ns string, in *fftypes.MessageInOut) (*fftypes.MessageInOut, error) {
+func (pm *privateMessaging) RequestReply(ctx context.Context, ns string, in *fftypes.MessageInOut, timeout time.Duration) (*fftypes.MessageInOut, error) {
 	if in.Header.Tag == "" {
 		return nil, i18n.NewError(ctx, i18n.MsgRequestReplyTagRequired)
 	}
@@ -59,7 +60,13 @@ func (pm *privateMessaging) RequestReply(ctx context.Context, ns string, in *fft
 		return nil, i18n.NewError(ctx, i18n.MsgRequestCannotHaveCID)
 	}
 	message := pm.NewMessage(ns, in)
-	return pm.syncasync.WaitForReply(ctx, ns, in.Header.ID, message.Send)
+	return pm.syncasync.WaitForReply(ctx, ns, in.Header.ID, timeout, message.Send)
+}
+
+// ValidateMessage runs the checks that would be performed when sending the message, without writing anything.
+// Every check is reported, rather than stopping at the first failure. A new group is resolved, but not initialized.
+func (pm *privateMessaging) ValidateMessage(ctx context.Context, ns string, in *fftypes.MessageInOut) *fftypes.MessageValidationReport {
+	return pm.NewMessage(ns, in).(*messageSender).validate(ctx)
 }
 
 // sendMethod is the specific operation requested of the messageSender.
@@ -114,6 +121,11 @@ func (s *messageSender) setDefaults() {
 
 func (s *messageSender) resolveAndSend(ctx context.Context, method sendMethod) error {
 
+	// Until the org that owns this node is endorsed by the network, it cannot send private messages
+	if err := s.mgr.identity.VerifyNodeOwnerEndorsed(ctx); err != nil {
+		return err
+	}
+
 	if !s.resolved {
 		if err := s.resolve(ctx); err != nil {
 			return err
@@ -129,8 +141,16 @@ func (s *messageSender) resolveAndSend(ctx context.Context, method sendMethod) e
 }
 
 func (s *messageSender) resolve(ctx context.Context) error {
-	// Resolve the sending identity
 	msg := s.msg.Message
+
+	// Fill in anything not supplied on the send from the template
+	if msg.Template != "" {
+		if err := s.mgr.data.ApplyMessageTemplate(ctx, msg); err != nil {
+			return err
+		}
+	}
+
+	// Resolve the sending identity
 	if err := s.mgr.identity.ResolveInputSigningIdentity(ctx, msg.Header.Namespace, &msg.Header.SignerRef); err != nil {
 		return i18n.WrapError(ctx, err, i18n.MsgAuthorInvalid)
 	}
@@ -145,6 +165,34 @@ func (s *messageSender) resolve(ctx context.Context) error {
 	return err
 }
 
+func (s *messageSender) validate(ctx context.Context) *fftypes.MessageValidationReport {
+	msg := s.msg.Message
+	report := fftypes.NewMessageValidationReport(msg.Header.Type)
+
+	report.AddCheck(fftypes.MessageValidationCheckEndorsement, s.mgr.identity.VerifyNodeOwnerEndorsed(ctx))
+	// The remaining checks depend on the template being applied
+	if msg.Template != "" && !report.AddCheck(fftypes.MessageValidationCheckTemplate, s.mgr.data.ApplyMessageTemplate(ctx, msg)) {
+		return report
+	}
+	err := s.mgr.identity.ResolveInputSigningIdentity(ctx, msg.Header.Namespace, &msg.Header.SignerRef)
+	if err != nil {
+		err = i18n.WrapError(ctx, err, i18n.MsgAuthorInvalid)
+	}
+	report.AddCheck(fftypes.MessageValidationCheckIdentity, err)
+	_, _, err = s.mgr.resolveGroup(ctx, msg)
+	report.AddCheck(fftypes.MessageValidationCheckGroup, err)
+	if report.AddCheck(fftypes.MessageValidationCheckData, s.mgr.data.ResolveInlineData(ctx, s.msg)) {
+		err = nil
+		report.Size = msg.EstimateSize(true)
+		report.MaxSize = s.mgr.maxBatchPayloadLength
+		if report.Size > report.MaxSize {
+			err = i18n.NewError(ctx, i18n.MsgTooLargePrivate, float64(report.Size)/1024, float64(report.MaxSize)/1024)
+		}
+		report.AddCheck(fftypes.MessageValidationCheckSize, err)
+	}
+	return report
+}
+
 func (s *messageSender) sendInternal(ctx context.Context, method sendMethod) error {
 	msg := &s.msg.Message.Message
 
@@ -166,7 +214,9 @@ func (s *messageSender) sendInternal(ctx context.Context, method sendMethod) err
 		return nil
 	}
 
-	// Store the message - this asynchronously triggers the next step in process
+	// Store the message - this asynchronously triggers the next step in process,
+	// unless the message is held back until it has been approved
+	s.mgr.approvals.ApplyPolicy(ctx, msg)
 	if err := s.mgr.data.WriteNewMessage(ctx, s.msg); err != nil {
 		return err
 	}
@@ -20,10 +20,12 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/syncasync"
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
@@ -169,6 +171,47 @@ func TestSendUnpinnedMessageE2EOk(t *testing.T) {
 
 }
 
+func TestSendMessageAwaitingApproval(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	mam := &approvalmocks.Manager{}
+	pm.approvals = mam
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(nil)
+
+	groupID := fftypes.NewRandB32()
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ResolveInlineData", pm.ctx, mock.Anything).Return(nil)
+	mdm.On("WriteNewMessage", pm.ctx, mock.MatchedBy(func(newMsg *data.NewMessage) bool {
+		return newMsg.Message.State == fftypes.MessageStateAwaitingApproval
+	})).Return(nil).Once()
+	mam.On("ApplyPolicy", pm.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		args[1].(*fftypes.Message).State = fftypes.MessageStateAwaitingApproval
+	}).Return()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, groupID).Return(&fftypes.Group{Hash: groupID}, nil)
+
+	msg, err := pm.SendMessage(pm.ctx, "ns1", &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Group: groupID,
+				Tag:   "payments",
+			},
+		},
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"some": "data"}`)},
+		},
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateAwaitingApproval, msg.State)
+
+	mdm.AssertExpectations(t)
+	mam.AssertExpectations(t)
+}
+
 func TestSendMessageBadGroup(t *testing.T) {
 
 	pm, cancel := newTestPrivateMessaging(t)
@@ -213,6 +256,48 @@ func TestSendMessageBadIdentity(t *testing.T) {
 
 }
 
+func TestSendMessageTemplateFail(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ApplyMessageTemplate", pm.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	_, err := pm.SendMessage(pm.ctx, "ns1", &fftypes.MessageInOut{
+		Template: "template1",
+	}, false)
+	assert.EqualError(t, err, "pop")
+
+	mdm.AssertExpectations(t)
+
+}
+
+func TestSendMessageNodeOwnerNotEndorsed(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mim := &identitymanagermocks.Manager{}
+	pm.identity = mim
+	mim.On("VerifyNodeOwnerEndorsed", pm.ctx).Return(fmt.Errorf("pop"))
+
+	_, err := pm.SendMessage(pm.ctx, "ns1", &fftypes.MessageInOut{
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"some": "data"}`)},
+		},
+		Group: &fftypes.InputGroup{
+			Members: []fftypes.MemberInput{
+				{Identity: "org1"},
+			},
+		},
+	}, false)
+	assert.EqualError(t, err, "pop")
+
+	mim.AssertExpectations(t)
+
+}
+
 func TestResolveAndSendBadInlineData(t *testing.T) {
 
 	pm, cancel := newTestPrivateMessaging(t)
@@ -565,9 +650,9 @@ func TestRequestReplyMissingTag(t *testing.T) {
 	defer cancel()
 
 	msa := pm.syncasync.(*syncasyncmocks.Bridge)
-	msa.On("WaitForReply", pm.ctx, "ns1", mock.Anything).Return(nil, nil)
+	msa.On("WaitForReply", pm.ctx, "ns1", mock.Anything, mock.Anything).Return(nil, nil)
 
-	_, err := pm.RequestReply(pm.ctx, "ns1", &fftypes.MessageInOut{})
+	_, err := pm.RequestReply(pm.ctx, "ns1", &fftypes.MessageInOut{}, 0)
 	assert.Regexp(t, "FF10261", err)
 }
 
@@ -576,7 +661,7 @@ func TestRequestReplyInvalidCID(t *testing.T) {
 	defer cancel()
 
 	msa := pm.syncasync.(*syncasyncmocks.Bridge)
-	msa.On("WaitForReply", pm.ctx, "ns1", mock.Anything).Return(nil, nil)
+	msa.On("WaitForReply", pm.ctx, "ns1", mock.Anything, mock.Anything).Return(nil, nil)
 
 	_, err := pm.RequestReply(pm.ctx, "ns1", &fftypes.MessageInOut{
 		Message: fftypes.Message{
@@ -586,7 +671,7 @@ func TestRequestReplyInvalidCID(t *testing.T) {
 				Group: fftypes.NewRandB32(),
 			},
 		},
-	})
+	}, 0)
 	assert.Regexp(t, "FF10262", err)
 }
 
@@ -598,9 +683,9 @@ func TestRequestReplySuccess(t *testing.T) {
 	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(nil)
 
 	msa := pm.syncasync.(*syncasyncmocks.Bridge)
-	msa.On("WaitForReply", pm.ctx, "ns1", mock.Anything, mock.Anything).
+	msa.On("WaitForReply", pm.ctx, "ns1", mock.Anything, 10*time.Second, mock.Anything).
 		Run(func(args mock.Arguments) {
-			send := args[3].(syncasync.RequestSender)
+			send := args[4].(syncasync.RequestSender)
 			send(pm.ctx)
 		}).
 		Return(nil, nil)
@@ -624,7 +709,7 @@ func TestRequestReplySuccess(t *testing.T) {
 				},
 			},
 		},
-	})
+	}, 10*time.Second)
 	assert.NoError(t, err)
 }
 
@@ -844,3 +929,169 @@ func TestSendDataTransferInsertOperationFail(t *testing.T) {
 	assert.Regexp(t, "pop", err)
 
 }
+
+func TestValidateMessageOk(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	groupID := fftypes.NewRandB32()
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(nil)
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ApplyMessageTemplate", pm.ctx, mock.Anything).Return(nil)
+	mdm.On("ResolveInlineData", pm.ctx, mock.Anything).Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, groupID).Return(&fftypes.Group{Hash: groupID}, nil)
+
+	report := pm.ValidateMessage(pm.ctx, "ns1", &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Group: groupID,
+			},
+		},
+		Template: "template1",
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"some": "data"}`)},
+		},
+	})
+	assert.True(t, report.Valid)
+	assert.Equal(t, fftypes.MessageTypePrivate, report.Type)
+	checks := make([]fftypes.MessageValidationCheck, len(report.Checks))
+	for i, c := range report.Checks {
+		checks[i] = c.Check
+	}
+	assert.Equal(t, []fftypes.MessageValidationCheck{
+		fftypes.MessageValidationCheckEndorsement,
+		fftypes.MessageValidationCheckTemplate,
+		fftypes.MessageValidationCheckIdentity,
+		fftypes.MessageValidationCheckGroup,
+		fftypes.MessageValidationCheckData,
+		fftypes.MessageValidationCheckSize,
+	}, checks)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+
+}
+
+func TestValidateMessageNewGroupNotInitialized(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	rootOrg := newTestOrg("rootorg")
+	localNode := newTestNode("node1", rootOrg)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(rootOrg, nil)
+	mim.On("CachedIdentityLookup", pm.ctx, "org1").Return(rootOrg, false, nil)
+
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ResolveInlineData", pm.ctx, mock.Anything).Return(nil)
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{localNode}, nil, nil)
+	mdi.On("GetGroupByHash", pm.ctx, mock.Anything).Return(nil, nil)
+
+	msg := &fftypes.MessageInOut{
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`{"some": "data"}`)},
+		},
+		Group: &fftypes.InputGroup{
+			Members: []fftypes.MemberInput{
+				{Identity: "org1"},
+			},
+		},
+	}
+	report := pm.ValidateMessage(pm.ctx, "ns1", msg)
+	assert.True(t, report.Valid)
+	assert.NotNil(t, msg.Header.Group)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+	mdi.AssertExpectations(t)
+
+}
+
+func TestValidateMessageTemplateFail(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ApplyMessageTemplate", pm.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	report := pm.ValidateMessage(pm.ctx, "ns1", &fftypes.MessageInOut{
+		Template: "template1",
+	})
+	assert.False(t, report.Valid)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, "pop", report.Checks[1].Error)
+
+	mdm.AssertExpectations(t)
+
+}
+
+func TestValidateMessageAllFail(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mim := &identitymanagermocks.Manager{}
+	pm.identity = mim
+	mim.On("VerifyNodeOwnerEndorsed", pm.ctx).Return(fmt.Errorf("pop1"))
+	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(fmt.Errorf("pop2"))
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ResolveInlineData", pm.ctx, mock.Anything).Return(fmt.Errorf("pop3"))
+
+	report := pm.ValidateMessage(pm.ctx, "ns1", &fftypes.MessageInOut{
+		Group: &fftypes.InputGroup{},
+	})
+	assert.False(t, report.Valid)
+	assert.Len(t, report.Checks, 4)
+	for _, c := range report.Checks {
+		assert.False(t, c.Valid)
+	}
+	assert.Regexp(t, "FF10206.*pop2", report.Checks[1].Error)
+	assert.Regexp(t, "FF10219", report.Checks[2].Error)
+
+	mim.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+
+}
+
+func TestValidateMessageTooLarge(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	pm.maxBatchPayloadLength = 100000
+	defer cancel()
+
+	groupID := fftypes.NewRandB32()
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveInputSigningIdentity", pm.ctx, "ns1", mock.Anything).Return(nil)
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("ResolveInlineData", pm.ctx, mock.Anything).Run(func(args mock.Arguments) {
+		newMsg := args[1].(*data.NewMessage)
+		newMsg.Message.Data = fftypes.DataRefs{
+			{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32(), ValueSize: 100001},
+		}
+	}).Return(nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, groupID).Return(&fftypes.Group{Hash: groupID}, nil)
+
+	report := pm.ValidateMessage(pm.ctx, "ns1", &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Group: groupID,
+			},
+		},
+	})
+	assert.False(t, report.Valid)
+	assert.Equal(t, fftypes.MessageValidationCheckSize, report.Checks[4].Check)
+	assert.Regexp(t, "FF10328", report.Checks[4].Error)
+
+	mdm.AssertExpectations(t)
+
+}
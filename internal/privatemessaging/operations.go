@@ -57,6 +57,27 @@ func addBatchSendInputs(op *fftypes.Operation, nodeID *fftypes.UUID, groupHash *
 	}
 }
 
+func addBatchSendRelayInputs(op *fftypes.Operation, relayed bool, relay []*fftypes.RelayBranch) {
+	if relayed {
+		op.Input["relayed"] = true
+	}
+	if len(relay) > 0 {
+		op.Input["relay"] = relay
+	}
+}
+
+func retrieveBatchSendRelayInputs(ctx context.Context, op *fftypes.Operation) (relayed bool, relay []*fftypes.RelayBranch, err error) {
+	relayed = op.Input.GetBool("relayed")
+	if relayInput, ok := op.Input["relay"]; ok {
+		// The tree is in its original form when the operation is prepared locally, or generic JSON after retrieval from the database
+		b, _ := json.Marshal(relayInput)
+		if err = json.Unmarshal(b, &relay); err != nil {
+			return false, nil, i18n.WrapError(ctx, err, i18n.MsgJSONObjectParseFailed, "relay")
+		}
+	}
+	return relayed, relay, nil
+}
+
 func retrieveBatchSendInputs(ctx context.Context, op *fftypes.Operation) (nodeID *fftypes.UUID, groupHash *fftypes.Bytes32, batchID *fftypes.UUID, err error) {
 	nodeID, err = fftypes.ParseUUID(ctx, op.Input.GetString("node"))
 	if err == nil {
@@ -94,6 +115,10 @@ func (pm *privateMessaging) PrepareOperation(ctx context.Context, op *fftypes.Op
 		if err != nil {
 			return nil, err
 		}
+		relayed, relay, err := retrieveBatchSendRelayInputs(ctx, op)
+		if err != nil {
+			return nil, err
+		}
 		node, err := pm.database.GetIdentityByID(ctx, nodeID)
 		if err != nil {
 			return nil, err
@@ -116,7 +141,7 @@ func (pm *privateMessaging) PrepareOperation(ctx context.Context, op *fftypes.Op
 		if err != nil {
 			return nil, err
 		}
-		transport := &fftypes.TransportWrapper{Group: group, Batch: batch}
+		transport := &fftypes.TransportWrapper{Group: group, Batch: batch, Relayed: relayed, Relay: relay}
 		return opBatchSend(op, node, transport), nil
 
 	default:
@@ -141,6 +166,35 @@ func (pm *privateMessaging) RunOperation(ctx context.Context, op *fftypes.Prepar
 	}
 }
 
+// CompensationRequired sends a tombstone for a batch that was delivered to a node, but whose pin then failed.
+// Otherwise the node would hold the messages pending forever. Nodes that received the batch through a relay
+// are not sent the tombstone.
+func (pm *privateMessaging) CompensationRequired(step, failed *fftypes.Operation) bool {
+	return step.Type == fftypes.OpTypeDataExchangeBatchSend &&
+		step.Status == fftypes.OpStatusSucceeded &&
+		failed.Type == fftypes.OpTypeBlockchainBatchPin
+}
+
+func (pm *privateMessaging) CompensateOperation(ctx context.Context, id *fftypes.UUID, step *fftypes.Operation) (complete bool, err error) {
+	// Pinned batches are sent without the group, so only the node and batch are retrieved
+	nodeID, err := fftypes.ParseUUID(ctx, step.Input.GetString("node"))
+	if err != nil {
+		return false, err
+	}
+	batchID, err := fftypes.ParseUUID(ctx, step.Input.GetString("batch"))
+	if err != nil {
+		return false, err
+	}
+	node, err := pm.database.GetIdentityByID(ctx, nodeID)
+	if err != nil {
+		return false, err
+	} else if node == nil {
+		return false, i18n.NewError(ctx, i18n.Msg404NotFound)
+	}
+	payload, _ := json.Marshal(&fftypes.TransportWrapper{Tombstone: batchID})
+	return false, pm.exchange.SendMessage(ctx, id, node.Profile.GetString("id"), payload)
+}
+
 func opTransferBlob(op *fftypes.Operation, node *fftypes.Identity, blob *fftypes.Blob) *fftypes.PreparedOperation {
 	return &fftypes.PreparedOperation{
 		ID:   op.ID,
@@ -320,6 +320,53 @@ func TestPrepareOperationBatchSendBadInput(t *testing.T) {
 	assert.Regexp(t, "FF10142", err)
 }
 
+func TestPrepareOperationBatchSendRelayed(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	node := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID()}}
+	group := &fftypes.Group{Hash: fftypes.NewRandB32()}
+	bp := &fftypes.BatchPersisted{BatchHeader: fftypes.BatchHeader{ID: fftypes.NewUUID()}}
+	batch := &fftypes.Batch{BatchHeader: bp.BatchHeader}
+	relayNode := fftypes.NewUUID()
+
+	// Inputs as they are read back from the database
+	op := &fftypes.Operation{Type: fftypes.OpTypeDataExchangeBatchSend}
+	addBatchSendInputs(op, node.ID, group.Hash, batch.ID)
+	addBatchSendRelayInputs(op, true, []*fftypes.RelayBranch{{Node: relayNode}})
+	var dbInput fftypes.JSONObject
+	assert.NoError(t, dbInput.Scan(op.Input.String()))
+	op.Input = dbInput
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdm := pm.data.(*datamocks.Manager)
+	mdm.On("HydrateBatch", context.Background(), bp).Return(batch, nil)
+	mdi.On("GetIdentityByID", context.Background(), node.ID).Return(node, nil)
+	mdi.On("GetGroupByHash", context.Background(), group.Hash).Return(group, nil)
+	mdi.On("GetBatchByID", context.Background(), batch.ID).Return(bp, nil)
+
+	po, err := pm.PrepareOperation(context.Background(), op)
+	assert.NoError(t, err)
+	transport := po.Data.(batchSendData).Transport
+	assert.True(t, transport.Relayed)
+	assert.Equal(t, relayNode, transport.Relay[0].Node)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestPrepareOperationBatchSendBadRelay(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	op := &fftypes.Operation{Type: fftypes.OpTypeDataExchangeBatchSend}
+	addBatchSendInputs(op, fftypes.NewUUID(), fftypes.NewRandB32(), fftypes.NewUUID())
+	op.Input["relay"] = "bad"
+
+	_, err := pm.PrepareOperation(context.Background(), op)
+	assert.Regexp(t, "FF10151", err)
+}
+
 func TestPrepareOperationBatchSendNodeFail(t *testing.T) {
 	pm, cancel := newTestPrivateMessaging(t)
 	defer cancel()
@@ -532,3 +579,105 @@ func TestRunOperationBatchSendInvalidData(t *testing.T) {
 	assert.False(t, complete)
 	assert.Regexp(t, "FF10137", err)
 }
+
+func TestCompensationRequired(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	sent := &fftypes.Operation{Type: fftypes.OpTypeDataExchangeBatchSend, Status: fftypes.OpStatusSucceeded}
+	pending := &fftypes.Operation{Type: fftypes.OpTypeDataExchangeBatchSend, Status: fftypes.OpStatusPending}
+	blob := &fftypes.Operation{Type: fftypes.OpTypeDataExchangeBlobSend, Status: fftypes.OpStatusSucceeded}
+	pinFailed := &fftypes.Operation{Type: fftypes.OpTypeBlockchainBatchPin}
+	assert.True(t, pm.CompensationRequired(sent, pinFailed))
+	assert.False(t, pm.CompensationRequired(pending, pinFailed))
+	assert.False(t, pm.CompensationRequired(blob, pinFailed))
+	assert.False(t, pm.CompensationRequired(sent, &fftypes.Operation{Type: fftypes.OpTypeDataExchangeBatchSend}))
+}
+
+func TestCompensateBatchSendTombstone(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	node := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			ID: fftypes.NewUUID(),
+		},
+		IdentityProfile: fftypes.IdentityProfile{
+			Profile: fftypes.JSONObject{
+				"id": "peer1",
+			},
+		},
+	}
+	batchID := fftypes.NewUUID()
+	step := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeDataExchangeBatchSend}
+	addBatchSendInputs(step, node.ID, nil, batchID)
+	compensationID := fftypes.NewUUID()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdx := pm.exchange.(*dataexchangemocks.Plugin)
+	mdi.On("GetIdentityByID", context.Background(), node.ID).Return(node, nil)
+	mdx.On("SendMessage", context.Background(), compensationID, "peer1", []byte(`{"tombstone":"`+batchID.String()+`"}`)).Return(nil)
+
+	complete, err := pm.CompensateOperation(context.Background(), compensationID, step)
+	assert.NoError(t, err)
+	assert.False(t, complete)
+
+	mdi.AssertExpectations(t)
+	mdx.AssertExpectations(t)
+}
+
+func TestCompensateBatchSendBadNode(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	step := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeDataExchangeBatchSend, Input: fftypes.JSONObject{}}
+
+	_, err := pm.CompensateOperation(context.Background(), fftypes.NewUUID(), step)
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestCompensateBatchSendBadBatch(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	step := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeDataExchangeBatchSend, Input: fftypes.JSONObject{
+		"node": fftypes.NewUUID().String(),
+	}}
+
+	_, err := pm.CompensateOperation(context.Background(), fftypes.NewUUID(), step)
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestCompensateBatchSendNodeFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	nodeID := fftypes.NewUUID()
+	step := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeDataExchangeBatchSend}
+	addBatchSendInputs(step, nodeID, nil, fftypes.NewUUID())
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", context.Background(), nodeID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := pm.CompensateOperation(context.Background(), fftypes.NewUUID(), step)
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+}
+
+func TestCompensateBatchSendNodeNotFound(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	nodeID := fftypes.NewUUID()
+	step := &fftypes.Operation{ID: fftypes.NewUUID(), Type: fftypes.OpTypeDataExchangeBatchSend}
+	addBatchSendInputs(step, nodeID, nil, fftypes.NewUUID())
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", context.Background(), nodeID).Return(nil, nil)
+
+	_, err := pm.CompensateOperation(context.Background(), fftypes.NewUUID(), step)
+	assert.Regexp(t, "FF10109", err)
+
+	mdi.AssertExpectations(t)
+}
@@ -18,7 +18,10 @@ package privatemessaging
 
 import (
 	"context"
+	"sync"
+	"time"
 
+	"github.com/hyperledger/firefly/internal/approvals"
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/batchpin"
 	"github.com/hyperledger/firefly/internal/config"
@@ -28,6 +31,7 @@ import (
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/metrics"
 	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/internal/residency"
 	"github.com/hyperledger/firefly/internal/retry"
 	"github.com/hyperledger/firefly/internal/syncasync"
 	"github.com/hyperledger/firefly/internal/sysmessaging"
@@ -48,7 +52,10 @@ type Manager interface {
 	Start() error
 	NewMessage(ns string, msg *fftypes.MessageInOut) sysmessaging.MessageSender
 	SendMessage(ctx context.Context, ns string, in *fftypes.MessageInOut, waitConfirm bool) (out *fftypes.Message, err error)
-	RequestReply(ctx context.Context, ns string, request *fftypes.MessageInOut) (reply *fftypes.MessageInOut, err error)
+	ValidateMessage(ctx context.Context, ns string, in *fftypes.MessageInOut) *fftypes.MessageValidationReport
+	PreviewGroup(ctx context.Context, ns string, in *fftypes.InputGroup) (*fftypes.GroupPreview, error)
+	RequestReply(ctx context.Context, ns string, request *fftypes.MessageInOut, timeout time.Duration) (reply *fftypes.MessageInOut, err error)
+	RelayBatch(tw *fftypes.TransportWrapper)
 
 	// From operations.OperationHandler
 	PrepareOperation(ctx context.Context, op *fftypes.Operation) (*fftypes.PreparedOperation, error)
@@ -74,13 +81,22 @@ type privateMessaging struct {
 	maxBatchPayloadLength int64
 	metrics               metrics.Manager
 	operations            operations.Manager
+	approvals             approvals.Manager
+	residency             *residency.Policy
 	orgFirstNodes         map[fftypes.UUID]*fftypes.Identity
+	transfers             *transferLimits
+	relayFanout           int
+	relayMinGroupSize     int
 }
 
-func NewPrivateMessaging(ctx context.Context, di database.Plugin, im identity.Manager, dx dataexchange.Plugin, bi blockchain.Plugin, ba batch.Manager, dm data.Manager, sa syncasync.Bridge, bp batchpin.Submitter, mm metrics.Manager, om operations.Manager) (Manager, error) {
-	if di == nil || im == nil || dx == nil || bi == nil || ba == nil || dm == nil || mm == nil || om == nil {
+func NewPrivateMessaging(ctx context.Context, di database.Plugin, im identity.Manager, dx dataexchange.Plugin, bi blockchain.Plugin, ba batch.Manager, dm data.Manager, sa syncasync.Bridge, bp batchpin.Submitter, mm metrics.Manager, om operations.Manager, am approvals.Manager) (Manager, error) {
+	if di == nil || im == nil || dx == nil || bi == nil || ba == nil || dm == nil || mm == nil || om == nil || am == nil {
 		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
 	}
+	rp, err := residency.NewPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	pm := &privateMessaging{
 		ctx:           ctx,
@@ -98,16 +114,21 @@ func NewPrivateMessaging(ctx context.Context, di database.Plugin, im identity.Ma
 			data:          dm,
 			groupCacheTTL: config.GetDuration(config.GroupCacheTTL),
 		},
-		retry: retry.Retry{
-			InitialDelay: config.GetDuration(config.PrivateMessagingRetryInitDelay),
-			MaximumDelay: config.GetDuration(config.PrivateMessagingRetryMaxDelay),
-			Factor:       config.GetFloat64(config.PrivateMessagingRetryFactor),
-		},
+		retry:                 *retry.NewFromConfig(config.PrivateMessagingRetryInitDelay, config.PrivateMessagingRetryMaxDelay, config.PrivateMessagingRetryFactor),
 		opCorrelationRetries:  config.GetInt(config.PrivateMessagingOpCorrelationRetries),
 		maxBatchPayloadLength: config.GetByteSize(config.PrivateMessagingBatchPayloadLimit),
 		metrics:               mm,
 		operations:            om,
+		approvals:             am,
+		residency:             rp,
 		orgFirstNodes:         make(map[fftypes.UUID]*fftypes.Identity),
+		transfers: newTransferLimits(
+			config.GetInt(config.PrivateMessagingTransferParallelism),
+			config.GetInt(config.PrivateMessagingTransferPeerConcurrency),
+			config.GetByteSize(config.PrivateMessagingTransferPeerBandwidth),
+		),
+		relayFanout:       config.GetInt(config.PrivateMessagingRelayFanout),
+		relayMinGroupSize: config.GetInt(config.PrivateMessagingRelayMinGroupSize),
 	}
 	pm.groupManager.groupCache = ccache.New(
 		// We use a LRU cache with a size-aware max
@@ -205,6 +226,9 @@ func (pm *privateMessaging) transferBlobs(ctx context.Context, data fftypes.Data
 			if blob == nil {
 				return i18n.NewError(ctx, i18n.MsgBlobNotFound, d.Blob)
 			}
+			if err = pm.transfers.pace(ctx, node.ID.String(), blob.Size); err != nil {
+				return err
+			}
 
 			op := fftypes.NewOperation(
 				pm.exchange,
@@ -222,6 +246,19 @@ func (pm *privateMessaging) transferBlobs(ctx context.Context, data fftypes.Data
 	return nil
 }
 
+func estimateBatchSize(batch *fftypes.Batch) (size int64) {
+	for _, m := range batch.Payload.Messages {
+		size += m.EstimateSize(false)
+	}
+	for _, d := range batch.Payload.Data {
+		size += d.EstimateSize()
+	}
+	return size
+}
+
+// sendData sends the batch to each of the nodes in parallel, and waits for all of the sends to be initiated.
+// The first failure is returned, so the whole batch is retried - with operations reused for the nodes that succeeded.
+// For large groups the batch might instead be sent to a subset of the nodes, that relay it on to the rest.
 func (pm *privateMessaging) sendData(ctx context.Context, tw *fftypes.TransportWrapper, nodes []*fftypes.Identity) (err error) {
 	l := log.L(ctx)
 	batch := tw.Batch
@@ -232,38 +269,91 @@ func (pm *privateMessaging) sendData(ctx context.Context, tw *fftypes.TransportW
 		return err
 	}
 
-	// Write it to the dataexchange for each member
+	targets := make([]*relayTarget, 0, len(nodes))
 	for i, node := range nodes {
-
 		if node.Parent.Equals(localOrg.ID) {
 			l.Debugf("Skipping send of batch for local node %s:%s for group=%s node=%s (%d/%d)", batch.Namespace, batch.ID, batch.Group, node.ID, i+1, len(nodes))
 			continue
 		}
+		targets = append(targets, &relayTarget{node: node})
+	}
+	remoteCount := len(targets)
+	if pm.shouldRelay(tw, remoteCount) {
+		targets = buildRelayTargets(targets, pm.relayFanout)
+		l.Debugf("Relaying batch %s:%s to %d nodes via %d direct sends", batch.Namespace, batch.ID, remoteCount, len(targets))
+	}
 
-		l.Debugf("Sending batch %s:%s to group=%s node=%s (%d/%d)", batch.Namespace, batch.ID, batch.Group, node.ID, i+1, len(nodes))
-
-		// Initiate transfer of any blobs first
-		if err = pm.transferBlobs(ctx, batch.Payload.Data, batch.Payload.TX.ID, node); err != nil {
-			return err
+	startTime := time.Now()
+	batchSize := estimateBatchSize(batch)
+	errs := make([]error, len(targets))
+	inflight := make(chan struct{}, pm.transfers.parallelism)
+	wg := sync.WaitGroup{}
+	for i, target := range targets {
+		inflight <- struct{}{}
+		wg.Add(1)
+		go func(i int, target *relayTarget) {
+			defer wg.Done()
+			defer func() { <-inflight }()
+			l.Debugf("Sending batch %s:%s to group=%s node=%s (%d/%d)", batch.Namespace, batch.ID, batch.Group, target.node.ID, i+1, len(targets))
+			errs[i] = pm.sendDataToNode(ctx, tw, target.node, target.relay, batchSize)
+		}(i, target)
+	}
+	wg.Wait()
+
+	sent, failed := 0, 0
+	for i, nodeErr := range errs {
+		if nodeErr != nil {
+			l.Errorf("Failed to send batch %s:%s to node=%s: %s", batch.Namespace, batch.ID, targets[i].node.ID, nodeErr)
+			failed++
+			if err == nil {
+				err = nodeErr
+			}
+		} else {
+			sent++
 		}
+	}
+	l.Infof("Sent batch %s:%s to %d nodes (failed=%d relayed=%d) in %.2fms", batch.Namespace, batch.ID, sent, failed, remoteCount-len(targets), float64(time.Since(startTime))/float64(time.Millisecond))
+	return err
+}
 
-		op := fftypes.NewOperation(
-			pm.exchange,
-			batch.Namespace,
-			batch.Payload.TX.ID,
-			fftypes.OpTypeDataExchangeBatchSend)
-		var groupHash *fftypes.Bytes32
-		if tw.Group != nil {
-			groupHash = tw.Group.Hash
-		}
-		addBatchSendInputs(op, node.ID, groupHash, batch.ID)
-		if err = pm.operations.AddOrReuseOperation(ctx, op); err != nil {
-			return err
-		}
-		if err = pm.operations.RunOperation(ctx, opBatchSend(op, node, tw)); err != nil {
-			return err
-		}
+func (pm *privateMessaging) sendDataToNode(ctx context.Context, tw *fftypes.TransportWrapper, node *fftypes.Identity, relay []*fftypes.RelayBranch, batchSize int64) error {
+	batch := tw.Batch
+	release, err := pm.transfers.acquire(ctx, node.ID.String())
+	if err != nil {
+		return err
 	}
+	defer release()
 
-	return nil
+	// Initiate transfer of any blobs first
+	if err = pm.transferBlobs(ctx, batch.Payload.Data, batch.Payload.TX.ID, node); err != nil {
+		return err
+	}
+	if err = pm.transfers.pace(ctx, node.ID.String(), batchSize); err != nil {
+		return err
+	}
+
+	op := fftypes.NewOperation(
+		pm.exchange,
+		batch.Namespace,
+		batch.Payload.TX.ID,
+		fftypes.OpTypeDataExchangeBatchSend)
+	var groupHash *fftypes.Bytes32
+	if tw.Group != nil {
+		groupHash = tw.Group.Hash
+	}
+	addBatchSendInputs(op, node.ID, groupHash, batch.ID)
+	addBatchSendRelayInputs(op, tw.Relayed, relay)
+	if err = pm.operations.AddOrReuseOperation(ctx, op); err != nil {
+		return err
+	}
+	if tw.Relayed || len(relay) > 0 {
+		// Each node is sent its own branches of the relay tree
+		tw = &fftypes.TransportWrapper{
+			Group:   tw.Group,
+			Batch:   batch,
+			Relayed: tw.Relayed,
+			Relay:   relay,
+		}
+	}
+	return pm.operations.RunOperation(ctx, opBatchSend(op, node, tw))
 }
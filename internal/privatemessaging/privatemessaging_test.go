@@ -23,6 +23,7 @@ import (
 
 	"github.com/hyperledger/firefly/internal/batch"
 	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/approvalmocks"
 	"github.com/hyperledger/firefly/mocks/batchmocks"
 	"github.com/hyperledger/firefly/mocks/batchpinmocks"
 	"github.com/hyperledger/firefly/mocks/blockchainmocks"
@@ -54,6 +55,9 @@ func newTestPrivateMessagingCommon(t *testing.T, metricsEnabled bool) (*privateM
 	mbp := &batchpinmocks.Submitter{}
 	mmi := &metricsmocks.Manager{}
 	mom := &operationmocks.Manager{}
+	mam := &approvalmocks.Manager{}
+
+	mim.On("VerifyNodeOwnerEndorsed", mock.Anything).Return(nil).Maybe()
 
 	mba.On("RegisterDispatcher",
 		pinnedPrivateDispatcherName,
@@ -72,9 +76,10 @@ func newTestPrivateMessagingCommon(t *testing.T, metricsEnabled bool) (*privateM
 		}, mock.Anything, mock.Anything).Return()
 	mmi.On("IsMetricsEnabled").Return(metricsEnabled)
 	mom.On("RegisterHandler", mock.Anything, mock.Anything, mock.Anything)
+	mam.On("ApplyPolicy", mock.Anything, mock.Anything).Return().Maybe()
 
 	ctx, cancel := context.WithCancel(context.Background())
-	pm, err := NewPrivateMessaging(ctx, mdi, mim, mdx, mbi, mba, mdm, msa, mbp, mmi, mom)
+	pm, err := NewPrivateMessaging(ctx, mdi, mim, mdx, mbi, mba, mdm, msa, mbp, mmi, mom, mam)
 	assert.NoError(t, err)
 
 	// Default mocks to save boilerplate in the tests
@@ -202,10 +207,19 @@ func TestDispatchBatchWithBlobs(t *testing.T) {
 }
 
 func TestNewPrivateMessagingMissingDeps(t *testing.T) {
-	_, err := NewPrivateMessaging(context.Background(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	_, err := NewPrivateMessaging(context.Background(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	assert.Regexp(t, "FF10128", err)
 }
 
+func TestNewPrivateMessagingBadResidency(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "residency": fftypes.JSONObject{"regions": "eu-west"}},
+	})
+	_, err := NewPrivateMessaging(context.Background(), &databasemocks.Plugin{}, &identitymanagermocks.Manager{}, &dataexchangemocks.Plugin{}, &blockchainmocks.Plugin{}, &batchmocks.Manager{}, &datamocks.Manager{}, &syncasyncmocks.Bridge{}, &batchpinmocks.Submitter{}, &metricsmocks.Manager{}, &operationmocks.Manager{}, &approvalmocks.Manager{})
+	assert.Regexp(t, "FF10409", err)
+}
+
 func TestDispatchErrorFindingGroup(t *testing.T) {
 	pm, cancel := newTestPrivateMessaging(t)
 	defer cancel()
@@ -27,33 +27,62 @@ import (
 )
 
 func (pm *privateMessaging) resolveRecipientList(ctx context.Context, in *fftypes.MessageInOut) error {
+	group, isNew, err := pm.resolveGroup(ctx, in)
+	if err != nil {
+		return err
+	}
+
+	// If the group is new, we need to do a group initialization, before we send the message itself.
+	if isNew {
+		return pm.groupManager.groupInit(ctx, &in.Header.SignerRef, group)
+	}
+	return nil
+}
+
+// resolveGroup finds or generates the group for the message, without writing anything
+func (pm *privateMessaging) resolveGroup(ctx context.Context, in *fftypes.MessageInOut) (group *fftypes.Group, isNew bool, err error) {
 	if in.Header.Group != nil {
 		log.L(ctx).Debugf("Group '%s' specified for message", in.Header.Group)
 		group, err := pm.database.GetGroupByHash(ctx, in.Header.Group)
 		if err != nil {
-			return err
+			return nil, false, err
 		}
 		if group == nil {
-			return i18n.NewError(ctx, i18n.MsgGroupNotFound, in.Header.Group)
+			return nil, false, i18n.NewError(ctx, i18n.MsgGroupNotFound, in.Header.Group)
 		}
 		// We have a group already resolved
-		return nil
+		return group, false, pm.checkResidency(ctx, group)
 	}
 	if in.Group == nil || len(in.Group.Members) == 0 {
-		return i18n.NewError(ctx, i18n.MsgGroupMustHaveMembers)
+		return nil, false, i18n.NewError(ctx, i18n.MsgGroupMustHaveMembers)
 	}
-	group, isNew, err := pm.findOrGenerateGroup(ctx, in)
+	group, isNew, err = pm.findOrGenerateGroup(ctx, in)
 	if err != nil {
-		return err
+		return nil, false, err
 	}
 	log.L(ctx).Debugf("Resolved group '%s' for message. New=%t", group.Hash, isNew)
+	if err := pm.checkResidency(ctx, group); err != nil {
+		return nil, false, err
+	}
 	in.Message.Header.Group = group.Hash
+	return group, isNew, nil
+}
 
-	// If the group is new, we need to do a group initialization, before we send the message itself.
-	if isNew {
-		return pm.groupManager.groupInit(ctx, &in.Header.SignerRef, group)
+// PreviewGroup resolves a list of recipients to the group a private send would use, without writing anything
+func (pm *privateMessaging) PreviewGroup(ctx context.Context, ns string, in *fftypes.InputGroup) (*fftypes.GroupPreview, error) {
+	if err := fftypes.ValidateFFNameField(ctx, ns, "namespace"); err != nil {
+		return nil, err
+	}
+	msg := &fftypes.MessageInOut{Group: in}
+	msg.Header.Namespace = ns
+	group, isNew, err := pm.resolveGroup(ctx, msg)
+	if err != nil {
+		return nil, err
 	}
-	return err
+	return &fftypes.GroupPreview{
+		Group:  group,
+		Exists: !isNew,
+	}, nil
 }
 
 func (pm *privateMessaging) getFirstNodeForOrg(ctx context.Context, identity *fftypes.Identity) (*fftypes.Identity, error) {
@@ -103,6 +132,20 @@ func (pm *privateMessaging) resolveNode(ctx context.Context, identity *fftypes.I
 	return node, nil
 }
 
+func (pm *privateMessaging) resolveMemberAlias(ctx context.Context, namespace, member string) (string, error) {
+	if _, isAlias := fftypes.ParseAliasRef(member); !isAlias {
+		return member, nil
+	}
+	alias, err := pm.identity.ResolveAliasRef(ctx, namespace, member)
+	if err != nil {
+		return "", err
+	}
+	if alias.Identity == "" {
+		return "", i18n.NewError(ctx, i18n.MsgAliasIdentityMissing, alias.Name)
+	}
+	return alias.Identity, nil
+}
+
 func (pm *privateMessaging) getRecipients(ctx context.Context, in *fftypes.MessageInOut) (gi *fftypes.GroupIdentity, err error) {
 
 	localOrg, err := pm.identity.GetNodeOwnerOrg(ctx)
@@ -118,8 +161,12 @@ func (pm *privateMessaging) getRecipients(ctx context.Context, in *fftypes.Messa
 		Members:   make(fftypes.Members, len(in.Group.Members)),
 	}
 	for i, rInput := range in.Group.Members {
-		// Resolve the identity
-		identity, _, err := pm.identity.CachedIdentityLookup(ctx, rInput.Identity)
+		// Resolve the identity, which might be a reference to an alias in the address book
+		did, err := pm.resolveMemberAlias(ctx, in.Message.Header.Namespace, rInput.Identity)
+		if err != nil {
+			return nil, err
+		}
+		identity, _, err := pm.identity.CachedIdentityLookup(ctx, did)
 		if err != nil {
 			return nil, err
 		}
@@ -196,3 +243,35 @@ func (pm *privateMessaging) findOrGenerateGroup(ctx context.Context, in *fftypes
 	}
 	return newCandidate, true, nil
 }
+
+// checkResidency verifies that the data residency rules of the namespace permit the payload
+// to be sent to each of the nodes in the group, other than our own node
+func (pm *privateMessaging) checkResidency(ctx context.Context, group *fftypes.Group) error {
+	if !pm.residency.RestrictsNodes(group.Namespace) {
+		return nil
+	}
+	localOrg, err := pm.identity.GetNodeOwnerOrg(ctx)
+	if err != nil {
+		return err
+	}
+	localNodeID, err := pm.resolveLocalNode(ctx, localOrg)
+	if err != nil {
+		return err
+	}
+	for _, member := range group.Members {
+		if member.Node.Equals(localNodeID) {
+			continue
+		}
+		node, err := pm.identity.CachedIdentityLookupByID(ctx, member.Node)
+		if err != nil {
+			return err
+		}
+		if node == nil {
+			return i18n.NewError(ctx, i18n.MsgNodeNotFound, member.Node)
+		}
+		if err := pm.residency.CheckNode(ctx, group.Namespace, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/residency"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
 	"github.com/hyperledger/firefly/pkg/database"
@@ -170,6 +172,101 @@ func TestResolveMemberListLookupFail(t *testing.T) {
 
 }
 
+func TestResolveMemberListAliasOk(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	localOrg := newTestOrg("org1")
+	localNode := newTestNode("node1", localOrg)
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{localNode}, nil, nil)
+	mdi.On("GetGroupByHash", pm.ctx, mock.Anything, mock.Anything).Return(&fftypes.Group{Hash: fftypes.NewRandB32()}, nil, nil).Once()
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveAliasRef", pm.ctx, "ns1", "alias:partner").Return(&fftypes.Alias{Name: "partner", Identity: "org1"}, nil)
+	mim.On("CachedIdentityLookup", pm.ctx, "org1").Return(localOrg, false, nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localNode, nil)
+
+	err := pm.resolveRecipientList(pm.ctx, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Namespace: "ns1",
+				SignerRef: fftypes.SignerRef{
+					Author: "org1",
+				},
+			},
+		},
+		Group: &fftypes.InputGroup{
+			Members: []fftypes.MemberInput{
+				{Identity: "alias:partner"},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+
+}
+
+func TestResolveMemberListAliasFail(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	localOrg := newTestOrg("org1")
+	localNode := newTestNode("node1", localOrg)
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveAliasRef", pm.ctx, "ns1", "alias:partner").Return(nil, fmt.Errorf("pop"))
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localNode, nil)
+
+	err := pm.resolveRecipientList(pm.ctx, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Namespace: "ns1",
+			},
+		},
+		Group: &fftypes.InputGroup{
+			Members: []fftypes.MemberInput{
+				{Identity: "alias:partner"},
+			},
+		},
+	})
+	assert.Regexp(t, "pop", err)
+	mim.AssertExpectations(t)
+
+}
+
+func TestResolveMemberListAliasNoIdentity(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	localOrg := newTestOrg("org1")
+	localNode := newTestNode("node1", localOrg)
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("ResolveAliasRef", pm.ctx, "ns1", "alias:partner").Return(&fftypes.Alias{Name: "partner", Key: "0x12345"}, nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localNode, nil)
+
+	err := pm.resolveRecipientList(pm.ctx, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Namespace: "ns1",
+			},
+		},
+		Group: &fftypes.InputGroup{
+			Members: []fftypes.MemberInput{
+				{Identity: "alias:partner"},
+			},
+		},
+	})
+	assert.Regexp(t, "FF10525", err)
+	mim.AssertExpectations(t)
+
+}
+
 func TestResolveMemberListGetGroupsFail(t *testing.T) {
 
 	pm, cancel := newTestPrivateMessaging(t)
@@ -435,3 +532,236 @@ func TestResolveLocalNodeNotError(t *testing.T) {
 	_, err := pm.resolveLocalNode(pm.ctx, newTestOrg("localorg"))
 	assert.EqualError(t, err, "pop")
 }
+
+func setTestResidency(t *testing.T, pm *privateMessaging, rules fftypes.JSONObject) {
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "residency": rules},
+	})
+	rp, err := residency.NewPolicy(pm.ctx)
+	assert.NoError(t, err)
+	pm.residency = rp
+}
+
+func TestResolveMemberListNewGroupResidencyDenied(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	setTestResidency(t, pm, fftypes.JSONObject{"regions": []interface{}{"eu-west"}})
+
+	localOrg := newTestOrg("localorg")
+	remoteOrg := newTestOrg("remoteorg")
+	localNode := newTestNode("node1", localOrg)
+	remoteNode := newTestNode("node2", remoteOrg)
+	remoteNode.Profile[residency.NodeProfileRegion] = "us-east"
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{remoteNode}, nil, nil).Once()
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{localNode}, nil, nil).Once()
+	mdi.On("GetGroupByHash", pm.ctx, mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", pm.ctx, "remoteorg").Return(remoteOrg, false, nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localOrg, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, remoteNode.ID).Return(remoteNode, nil)
+
+	err := pm.resolveRecipientList(pm.ctx, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Namespace: "ns1",
+			},
+		},
+		Group: &fftypes.InputGroup{
+			Members: []fftypes.MemberInput{
+				{Identity: remoteOrg.Name},
+			},
+		},
+	})
+	assert.Regexp(t, "FF10411.*us-east", err)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+
+}
+
+func TestResolveReceipientListExistingResidencyOk(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	setTestResidency(t, pm, fftypes.JSONObject{"regions": []interface{}{"eu-west"}})
+
+	localOrg := newTestOrg("localorg")
+	remoteOrg := newTestOrg("remoteorg")
+	localNode := newTestNode("node1", localOrg)
+	remoteNode := newTestNode("node2", remoteOrg)
+	remoteNode.Profile[residency.NodeProfileRegion] = "eu-west"
+	pm.localNodeID = localNode.ID
+
+	groupID := fftypes.NewRandB32()
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetGroupByHash", pm.ctx, groupID).Return(&fftypes.Group{
+		GroupIdentity: fftypes.GroupIdentity{
+			Namespace: "ns1",
+			Members: fftypes.Members{
+				{Identity: localOrg.DID, Node: localNode.ID},
+				{Identity: remoteOrg.DID, Node: remoteNode.ID},
+			},
+		},
+		Hash: groupID,
+	}, nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localOrg, nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, remoteNode.ID).Return(remoteNode, nil)
+
+	err := pm.resolveRecipientList(pm.ctx, &fftypes.MessageInOut{
+		Message: fftypes.Message{
+			Header: fftypes.MessageHeader{
+				Namespace: "ns1",
+				Group:     groupID,
+			},
+		},
+	})
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+}
+
+func TestCheckResidencyLocalOrgFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	setTestResidency(t, pm, fftypes.JSONObject{"regions": []interface{}{"eu-west"}})
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(nil, fmt.Errorf("pop"))
+
+	err := pm.checkResidency(pm.ctx, &fftypes.Group{GroupIdentity: fftypes.GroupIdentity{Namespace: "ns1"}})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCheckResidencyLocalNodeFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	setTestResidency(t, pm, fftypes.JSONObject{"regions": []interface{}{"eu-west"}})
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(newTestOrg("localorg"), nil)
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := pm.checkResidency(pm.ctx, &fftypes.Group{GroupIdentity: fftypes.GroupIdentity{Namespace: "ns1"}})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCheckResidencyNodeLookupFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	setTestResidency(t, pm, fftypes.JSONObject{"regions": []interface{}{"eu-west"}})
+	pm.localNodeID = fftypes.NewUUID()
+
+	nodeID := fftypes.NewUUID()
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(newTestOrg("localorg"), nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, nodeID).Return(nil, fmt.Errorf("pop"))
+
+	err := pm.checkResidency(pm.ctx, &fftypes.Group{GroupIdentity: fftypes.GroupIdentity{
+		Namespace: "ns1",
+		Members:   fftypes.Members{{Node: nodeID}},
+	}})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCheckResidencyNodeNotFound(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	setTestResidency(t, pm, fftypes.JSONObject{"regions": []interface{}{"eu-west"}})
+	pm.localNodeID = fftypes.NewUUID()
+
+	nodeID := fftypes.NewUUID()
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(newTestOrg("localorg"), nil)
+	mim.On("CachedIdentityLookupByID", pm.ctx, nodeID).Return(nil, nil)
+
+	err := pm.checkResidency(pm.ctx, &fftypes.Group{GroupIdentity: fftypes.GroupIdentity{
+		Namespace: "ns1",
+		Members:   fftypes.Members{{Node: nodeID}},
+	}})
+	assert.Regexp(t, "FF10224", err)
+}
+
+func TestPreviewGroupNew(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	localOrg := newTestOrg("org1")
+	localNode := newTestNode("node1", localOrg)
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{localNode}, nil, nil)
+	mdi.On("GetGroupByHash", pm.ctx, mock.Anything).Return(nil, nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", pm.ctx, "org1").Return(localOrg, false, nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localOrg, nil)
+
+	preview, err := pm.PreviewGroup(pm.ctx, "ns1", &fftypes.InputGroup{
+		Members: []fftypes.MemberInput{
+			{Identity: "org1"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.False(t, preview.Exists)
+	assert.Equal(t, "ns1", preview.Group.Namespace)
+	assert.Equal(t, preview.Group.GroupIdentity.Hash(), preview.Group.Hash)
+	assert.Len(t, preview.Group.Members, 1)
+	assert.Equal(t, localOrg.DID, preview.Group.Members[0].Identity)
+	assert.Equal(t, localNode.ID, preview.Group.Members[0].Node)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+
+}
+
+func TestPreviewGroupExisting(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	localOrg := newTestOrg("org1")
+	localNode := newTestNode("node1", localOrg)
+	existing := &fftypes.Group{Hash: fftypes.NewRandB32()}
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentities", pm.ctx, mock.Anything).Return([]*fftypes.Identity{localNode}, nil, nil)
+	mdi.On("GetGroupByHash", pm.ctx, mock.Anything).Return(existing, nil)
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("CachedIdentityLookup", pm.ctx, "org1").Return(localOrg, false, nil)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localOrg, nil)
+
+	preview, err := pm.PreviewGroup(pm.ctx, "ns1", &fftypes.InputGroup{
+		Members: []fftypes.MemberInput{
+			{Identity: "org1"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, preview.Exists)
+	assert.Equal(t, existing, preview.Group)
+	mdi.AssertExpectations(t)
+	mim.AssertExpectations(t)
+
+}
+
+func TestPreviewGroupBadNamespace(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	_, err := pm.PreviewGroup(pm.ctx, "!wrong", &fftypes.InputGroup{})
+	assert.Regexp(t, "FF10131", err)
+
+}
+
+func TestPreviewGroupEmpty(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	_, err := pm.PreviewGroup(pm.ctx, "ns1", &fftypes.InputGroup{})
+	assert.Regexp(t, "FF10219", err)
+
+}
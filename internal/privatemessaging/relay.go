@@ -0,0 +1,104 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privatemessaging
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/operations"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// relayTarget is a node the batch is sent to directly, along with the branches of the relay tree that node
+// must re-relay the batch to
+type relayTarget struct {
+	node  *fftypes.Identity
+	relay []*fftypes.RelayBranch
+}
+
+// shouldRelay determines if a batch is sent to the group via a relay tree. Only pinned batches are relayed,
+// as the receiving nodes verify the author of a relayed batch against the pin on the blockchain (rather
+// than against the node it was received from). The payload is transferred over data exchange to each
+// node in the tree, so it is only ever visible to members of the group.
+func (pm *privateMessaging) shouldRelay(tw *fftypes.TransportWrapper, remoteCount int) bool {
+	return pm.relayMinGroupSize > 0 &&
+		pm.relayFanout > 0 &&
+		remoteCount >= pm.relayMinGroupSize &&
+		remoteCount > pm.relayFanout &&
+		tw.Batch.Payload.TX.Type == fftypes.TransactionTypeBatchPin
+}
+
+// buildRelayTargets arranges the nodes into a tree with the local node at the root, using the layout of
+// a k-ary heap - where the children of position p are (p*fanout)+1 to (p*fanout)+fanout. The local node
+// is position 0, so sends directly to the first fanout nodes, and the depth of the tree is log(N).
+func buildRelayTargets(targets []*relayTarget, fanout int) []*relayTarget {
+	var branches func(pos int) []*fftypes.RelayBranch
+	branches = func(pos int) []*fftypes.RelayBranch {
+		var children []*fftypes.RelayBranch
+		for child := pos*fanout + 1; child <= pos*fanout+fanout && child <= len(targets); child++ {
+			children = append(children, &fftypes.RelayBranch{
+				Node:  targets[child-1].node.ID,
+				Relay: branches(child),
+			})
+		}
+		return children
+	}
+	roots := targets[0:fanout]
+	for i, root := range roots {
+		root.relay = branches(i + 1)
+	}
+	return roots
+}
+
+// RelayBatch re-relays a batch received from another member of the group, to each of the branches of
+// the relay tree beneath the local node. The blobs of the batch might still be in-flight to this node,
+// so the relay is retried in the background until it succeeds.
+func (pm *privateMessaging) RelayBatch(tw *fftypes.TransportWrapper) {
+	go func() {
+		_ = operations.RunWithOperationCache(pm.ctx, func(ctx context.Context) error {
+			return pm.retry.Do(ctx, "relay batch", func(attempt int) (retry bool, err error) {
+				return true, pm.relayBatch(ctx, tw)
+			})
+		})
+	}()
+}
+
+func (pm *privateMessaging) relayBatch(ctx context.Context, tw *fftypes.TransportWrapper) error {
+	batch := tw.Batch
+	relayed := &fftypes.TransportWrapper{
+		Group:   tw.Group,
+		Batch:   batch,
+		Relayed: true,
+	}
+	batchSize := estimateBatchSize(batch)
+	for _, branch := range tw.Relay {
+		node, err := pm.database.GetIdentityByID(ctx, branch.Node)
+		if err != nil {
+			return err
+		}
+		if node == nil {
+			log.L(ctx).Errorf("Unable to relay batch %s:%s to unknown node=%s", batch.Namespace, batch.ID, branch.Node)
+			continue
+		}
+		log.L(ctx).Debugf("Relaying batch %s:%s to group=%s node=%s (branch nodes=%d)", batch.Namespace, batch.ID, batch.Group, node.ID, len(branch.RelayNodes()))
+		if err := pm.sendDataToNode(ctx, relayed, node, branch.Relay, batchSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privatemessaging
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRelayTargets(count int) []*relayTarget {
+	targets := make([]*relayTarget, count)
+	for i := range targets {
+		targets[i] = &relayTarget{node: newTestNode(fmt.Sprintf("node%d", i), newTestOrg(fmt.Sprintf("org%d", i)))}
+	}
+	return targets
+}
+
+func TestBuildRelayTargets(t *testing.T) {
+	targets := newTestRelayTargets(10)
+
+	roots := buildRelayTargets(targets, 3)
+	assert.Len(t, roots, 3)
+	assert.Equal(t, targets[0], roots[0])
+	assert.Len(t, roots[0].relay, 3)
+	assert.Equal(t, targets[3].node.ID, roots[0].relay[0].Node)
+	assert.Equal(t, targets[5].node.ID, roots[0].relay[2].Node)
+	assert.Equal(t, targets[6].node.ID, roots[1].relay[0].Node)
+	assert.Len(t, roots[2].relay, 1)
+	assert.Equal(t, targets[9].node.ID, roots[2].relay[0].Node)
+
+	// Every node is reached exactly once
+	reached := map[fftypes.UUID]bool{}
+	for _, root := range roots {
+		rb := &fftypes.RelayBranch{Node: root.node.ID, Relay: root.relay}
+		for _, n := range rb.RelayNodes() {
+			assert.False(t, reached[*n])
+			reached[*n] = true
+		}
+	}
+	assert.Len(t, reached, 10)
+}
+
+func TestShouldRelay(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	tw := newTestTransportWrapper()
+	tw.Batch.Payload.TX.Type = fftypes.TransactionTypeBatchPin
+	assert.False(t, pm.shouldRelay(tw, 100))
+
+	pm.relayMinGroupSize = 10
+	pm.relayFanout = 4
+	assert.True(t, pm.shouldRelay(tw, 10))
+	assert.False(t, pm.shouldRelay(tw, 9))
+
+	pm.relayFanout = 10
+	assert.False(t, pm.shouldRelay(tw, 10))
+
+	pm.relayFanout = 0
+	assert.False(t, pm.shouldRelay(tw, 10))
+
+	tw.Batch.Payload.TX.Type = fftypes.TransactionTypeUnpinned
+	assert.False(t, pm.shouldRelay(tw, 100))
+}
+
+func TestSendDataRelayTree(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+	pm.relayMinGroupSize = 3
+	pm.relayFanout = 2
+
+	localOrg := newTestOrg("localorg")
+	nodes := []*fftypes.Identity{newTestNode("local", localOrg)}
+	for _, target := range newTestRelayTargets(5) {
+		nodes = append(nodes, target.node)
+	}
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localOrg, nil)
+
+	mom := pm.operations.(*operationmocks.Manager)
+	mom.On("AddOrReuseOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return len(op.Input["relay"].([]*fftypes.RelayBranch)) > 0
+	})).Return(nil).Twice()
+	mom.On("RunOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		data := op.Data.(batchSendData)
+		return data.Node.ID.Equals(nodes[1].ID) && len(data.Transport.Relay) == 2 && !data.Transport.Relayed
+	})).Return(nil).Once()
+	mom.On("RunOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		data := op.Data.(batchSendData)
+		return data.Node.ID.Equals(nodes[2].ID) && len(data.Transport.Relay) == 1 && !data.Transport.Relayed
+	})).Return(nil).Once()
+
+	tw := newTestTransportWrapper()
+	tw.Batch.Payload.TX.Type = fftypes.TransactionTypeBatchPin
+	err := pm.sendData(pm.ctx, tw, nodes)
+	assert.NoError(t, err)
+	assert.Nil(t, tw.Relay)
+
+	mim.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestRelayBatch(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	node1 := newTestNode("node1", newTestOrg("org1"))
+	unknown := fftypes.NewUUID()
+	leaf := fftypes.NewUUID()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", mock.Anything, unknown).Return(nil, nil)
+	mdi.On("GetIdentityByID", mock.Anything, node1.ID).Return(node1, nil)
+
+	mom := pm.operations.(*operationmocks.Manager)
+	mom.On("AddOrReuseOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.Operation) bool {
+		return op.Input.GetBool("relayed")
+	})).Return(nil)
+	relayed := make(chan struct{})
+	mom.On("RunOperation", mock.Anything, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		data := op.Data.(batchSendData)
+		return data.Node.ID.Equals(node1.ID) && data.Transport.Relayed && data.Transport.Relay[0].Node.Equals(leaf)
+	})).Return(nil).Run(func(args mock.Arguments) {
+		close(relayed)
+	})
+
+	tw := newTestTransportWrapper()
+	tw.Relay = []*fftypes.RelayBranch{
+		{Node: unknown},
+		{Node: node1.ID, Relay: []*fftypes.RelayBranch{{Node: leaf}}},
+	}
+	pm.RelayBatch(tw)
+	<-relayed
+
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestRelayBatchLookupFail(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", pm.ctx, mock.Anything).Return(nil, fmt.Errorf("pop"))
+
+	tw := newTestTransportWrapper()
+	tw.Relay = []*fftypes.RelayBranch{{Node: fftypes.NewUUID()}}
+	err := pm.relayBatch(pm.ctx, tw)
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestRelayBatchSendFail(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	node1 := newTestNode("node1", newTestOrg("org1"))
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetIdentityByID", pm.ctx, node1.ID).Return(node1, nil)
+
+	mom := pm.operations.(*operationmocks.Manager)
+	mom.On("AddOrReuseOperation", pm.ctx, mock.Anything).Return(fmt.Errorf("pop"))
+
+	tw := newTestTransportWrapper()
+	tw.Relay = []*fftypes.RelayBranch{{Node: node1.ID}}
+	err := pm.relayBatch(pm.ctx, tw)
+	assert.Regexp(t, "pop", err)
+
+	mdi.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
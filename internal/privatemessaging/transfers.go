@@ -0,0 +1,106 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privatemessaging
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// transferLimits bounds the transfers initiated to each peer node, across all the batches being dispatched.
+// Each node has a limited number of concurrent sends, and an optional bandwidth cap that paces the start
+// of each transfer by the size of the previous transfers to that node.
+type transferLimits struct {
+	parallelism     int
+	peerConcurrency int
+	peerBandwidth   int64
+	mux             sync.Mutex
+	peers           map[string]*peerLimiter
+}
+
+type peerLimiter struct {
+	slots    chan struct{}
+	mux      sync.Mutex
+	nextFree time.Time
+}
+
+func newTransferLimits(parallelism, peerConcurrency int, peerBandwidth int64) *transferLimits {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	if peerConcurrency < 1 {
+		peerConcurrency = 1
+	}
+	return &transferLimits{
+		parallelism:     parallelism,
+		peerConcurrency: peerConcurrency,
+		peerBandwidth:   peerBandwidth,
+		peers:           make(map[string]*peerLimiter),
+	}
+}
+
+func (tl *transferLimits) peer(peerID string) *peerLimiter {
+	tl.mux.Lock()
+	defer tl.mux.Unlock()
+	pl, ok := tl.peers[peerID]
+	if !ok {
+		pl = &peerLimiter{slots: make(chan struct{}, tl.peerConcurrency)}
+		tl.peers[peerID] = pl
+	}
+	return pl
+}
+
+// acquire waits for a free send slot for the peer, returning a function to release it
+func (tl *transferLimits) acquire(ctx context.Context, peerID string) (release func(), err error) {
+	pl := tl.peer(peerID)
+	select {
+	case pl.slots <- struct{}{}:
+		return func() { <-pl.slots }, nil
+	case <-ctx.Done():
+		return nil, i18n.NewError(ctx, i18n.MsgContextCanceled)
+	}
+}
+
+// pace waits until a transfer of the given size to the peer fits within the bandwidth cap
+func (tl *transferLimits) pace(ctx context.Context, peerID string, size int64) error {
+	if tl.peerBandwidth <= 0 || size <= 0 {
+		return nil
+	}
+	pl := tl.peer(peerID)
+	pl.mux.Lock()
+	now := time.Now()
+	start := pl.nextFree
+	if start.Before(now) {
+		start = now
+	}
+	pl.nextFree = start.Add(time.Duration(float64(size) / float64(tl.peerBandwidth) * float64(time.Second)))
+	pl.mux.Unlock()
+
+	delay := start.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(delay):
+		return nil
+	case <-ctx.Done():
+		return i18n.NewError(ctx, i18n.MsgContextCanceled)
+	}
+}
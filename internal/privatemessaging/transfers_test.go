@@ -0,0 +1,180 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privatemessaging
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/mocks/operationmocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransferLimitsDefaults(t *testing.T) {
+	tl := newTransferLimits(0, 0, 0)
+	assert.Equal(t, 1, tl.parallelism)
+	assert.Equal(t, 1, tl.peerConcurrency)
+	assert.NoError(t, tl.pace(context.Background(), "peer1", 1000))
+}
+
+func TestTransferLimitsAcquire(t *testing.T) {
+	tl := newTransferLimits(1, 1, 0)
+
+	release, err := tl.acquire(context.Background(), "peer1")
+	assert.NoError(t, err)
+
+	// A different peer has its own slots
+	release2, err := tl.acquire(context.Background(), "peer2")
+	assert.NoError(t, err)
+	release2()
+
+	// The same peer blocks until released
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = tl.acquire(ctx, "peer1")
+	assert.Regexp(t, "FF10158", err)
+
+	release()
+	release, err = tl.acquire(context.Background(), "peer1")
+	assert.NoError(t, err)
+	release()
+}
+
+func TestTransferLimitsPace(t *testing.T) {
+	tl := newTransferLimits(1, 1, 1000)
+
+	// The first transfer starts immediately, and the next waits for it at 1000 bytes/sec
+	startTime := time.Now()
+	assert.NoError(t, tl.pace(context.Background(), "peer1", 50))
+	assert.NoError(t, tl.pace(context.Background(), "peer1", 50))
+	assert.GreaterOrEqual(t, int64(time.Since(startTime)), int64(40*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := tl.pace(ctx, "peer1", 1000)
+	assert.Regexp(t, "FF10158", err)
+}
+
+func newTestTransportWrapper() *fftypes.TransportWrapper {
+	groupID := fftypes.NewRandB32()
+	return &fftypes.TransportWrapper{
+		Batch: &fftypes.Batch{
+			BatchHeader: fftypes.BatchHeader{
+				ID:    fftypes.NewUUID(),
+				Group: groupID,
+			},
+			Payload: fftypes.BatchPayload{
+				Messages: []*fftypes.Message{
+					{Header: fftypes.MessageHeader{Tag: "mytag", Group: groupID}},
+				},
+			},
+		},
+	}
+}
+
+func TestSendDataParallelPartialFail(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	localOrg := newTestOrg("localorg")
+	node1 := newTestNode("node1", localOrg)
+	node2 := newTestNode("node2", newTestOrg("remoteorg1"))
+	node3 := newTestNode("node3", newTestOrg("remoteorg2"))
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", pm.ctx).Return(localOrg, nil)
+
+	mom := pm.operations.(*operationmocks.Manager)
+	mom.On("AddOrReuseOperation", pm.ctx, mock.Anything).Return(nil)
+	mom.On("RunOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		return op.Data.(batchSendData).Node.ID.Equals(node2.ID)
+	})).Return(nil)
+	mom.On("RunOperation", pm.ctx, mock.MatchedBy(func(op *fftypes.PreparedOperation) bool {
+		return op.Data.(batchSendData).Node.ID.Equals(node3.ID)
+	})).Return(fmt.Errorf("pop"))
+
+	err := pm.sendData(pm.ctx, newTestTransportWrapper(), []*fftypes.Identity{node1, node2, node3})
+	assert.Regexp(t, "pop", err)
+
+	mim.AssertExpectations(t)
+	mom.AssertExpectations(t)
+}
+
+func TestSendDataAcquireFail(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	node2 := newTestNode("node2", newTestOrg("remoteorg"))
+	pm.transfers = newTransferLimits(1, 1, 0)
+	_, err := pm.transfers.acquire(pm.ctx, node2.ID.String())
+	assert.NoError(t, err)
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", mock.Anything).Return(newTestOrg("localorg"), nil)
+
+	ctx, cancelCtx := context.WithCancel(pm.ctx)
+	cancelCtx()
+	err = pm.sendData(ctx, newTestTransportWrapper(), []*fftypes.Identity{node2})
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestSendDataPaceFail(t *testing.T) {
+
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	node2 := newTestNode("node2", newTestOrg("remoteorg"))
+	pm.transfers = newTransferLimits(1, 1, 1)
+	assert.NoError(t, pm.transfers.pace(pm.ctx, node2.ID.String(), 1000))
+
+	mim := pm.identity.(*identitymanagermocks.Manager)
+	mim.On("GetNodeOwnerOrg", mock.Anything).Return(newTestOrg("localorg"), nil)
+
+	ctx, cancelCtx := context.WithTimeout(pm.ctx, 10*time.Millisecond)
+	defer cancelCtx()
+	err := pm.sendData(ctx, newTestTransportWrapper(), []*fftypes.Identity{node2})
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestTransferBlobsPaceFail(t *testing.T) {
+	pm, cancel := newTestPrivateMessaging(t)
+	defer cancel()
+
+	node1 := newTestNode("node1", newTestOrg("org1"))
+	pm.transfers = newTransferLimits(1, 1, 1)
+	assert.NoError(t, pm.transfers.pace(pm.ctx, node1.ID.String(), 1000))
+
+	mdi := pm.database.(*databasemocks.Plugin)
+	mdi.On("GetBlobMatchingHash", mock.Anything, mock.Anything).Return(&fftypes.Blob{PayloadRef: "blob/1", Size: 1000}, nil)
+
+	ctx, cancelCtx := context.WithCancel(pm.ctx)
+	cancelCtx()
+	err := pm.transferBlobs(ctx, fftypes.DataArray{
+		{ID: fftypes.NewUUID(), Hash: fftypes.NewRandB32(), Blob: &fftypes.BlobRef{Hash: fftypes.NewRandB32()}},
+	}, fftypes.NewUUID(), node1)
+	assert.Regexp(t, "FF10158", err)
+
+	mdi.AssertExpectations(t)
+}
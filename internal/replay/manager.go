@@ -0,0 +1,258 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/events"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/dataexchange"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/sharedstorage"
+)
+
+// maxEntrySize is the largest line that can be read from a replay log, which must hold a whole batch payload
+var maxEntrySize = 64 * 1024 * 1024
+
+// Manager records the inbound events from the blockchain, data exchange and shared storage plugins to an
+// append-only replay log. In debug mode it instead replays a log into the event manager, in the order it
+// was recorded, so that hard-to-reproduce ordering problems in the aggregator can be analyzed offline.
+type Manager interface {
+	SharedStorage() sharedstorage.Plugin
+	Replaying() bool
+	RecordBatchPin(batchPin *blockchain.BatchPin, signingKey *fftypes.VerifierRef)
+	RecordMessage(peerID string, data []byte)
+	RecordBLOB(peerID string, hash fftypes.Bytes32, size int64, payloadRef string)
+	Start(ei events.EventManager, bi blockchain.Plugin, dx dataexchange.Plugin) error
+	WaitStop()
+}
+
+type EntryType string
+
+const (
+	// EntryTypeBatchPin is a batch pin delivered by the blockchain plugin
+	EntryTypeBatchPin EntryType = "batch_pin"
+	// EntryTypeMessage is a message delivered by the data exchange plugin, such as a private batch
+	EntryTypeMessage EntryType = "dx_message"
+	// EntryTypeBLOB is a blob transfer delivered by the data exchange plugin
+	EntryTypeBLOB EntryType = "dx_blob"
+	// EntryTypeSharedStorageData is a payload retrieved from shared storage, such as a broadcast batch
+	EntryTypeSharedStorageData EntryType = "shared_storage_data"
+)
+
+// Entry is a single line of the replay log
+type Entry struct {
+	Sequence   int64                `json:"seq"`
+	Timestamp  *fftypes.FFTime      `json:"timestamp"`
+	Type       EntryType            `json:"type"`
+	BatchPin   *blockchain.BatchPin `json:"batchPin,omitempty"`
+	SigningKey *fftypes.VerifierRef `json:"signingKey,omitempty"`
+	PeerID     string               `json:"peer,omitempty"`
+	Hash       *fftypes.Bytes32     `json:"hash,omitempty"`
+	Size       int64                `json:"size,omitempty"`
+	PayloadRef string               `json:"payloadRef,omitempty"`
+	Data       []byte               `json:"data,omitempty"`
+}
+
+type replayManager struct {
+	ctx           context.Context
+	sharedstorage sharedstorage.Plugin
+	mux           sync.Mutex
+	recording     bool
+	logFile       *os.File
+	encoder       *json.Encoder
+	sequence      int64
+	source        string
+	realtime      bool
+	entries       []*Entry
+	payloads      map[string][]byte
+	done          chan struct{}
+}
+
+func NewReplayManager(ctx context.Context, ss sharedstorage.Plugin) (Manager, error) {
+	if ss == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	rm := &replayManager{
+		ctx:           log.WithLogger(ctx, log.L(ctx).WithField("role", "replay")),
+		sharedstorage: ss,
+		source:        config.GetString(config.ReplayDebugSource),
+		realtime:      config.GetBool(config.ReplayDebugRealtime),
+	}
+	rm.recording = config.GetBool(config.ReplayLogEnabled)
+	if rm.recording && rm.source != "" {
+		return nil, i18n.NewError(ctx, i18n.MsgReplayRecordDuringReplay)
+	}
+	if rm.recording {
+		path := config.GetString(config.ReplayLogPath)
+		if path == "" {
+			return nil, i18n.NewError(ctx, i18n.MsgReplayLogPathMissing)
+		}
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgReplayLogOpenFailed, path)
+		}
+		log.L(ctx).Infof("Recording inbound events to replay log '%s'", path)
+		rm.logFile = f
+		rm.encoder = json.NewEncoder(f)
+	}
+	if rm.source != "" {
+		if err := rm.load(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return rm, nil
+}
+
+func (rm *replayManager) load(ctx context.Context) error {
+	f, err := os.Open(rm.source)
+	if err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgReplayLogOpenFailed, rm.source)
+	}
+	defer f.Close()
+	rm.payloads = make(map[string][]byte)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxEntrySize)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return i18n.NewError(ctx, i18n.MsgReplayLogInvalid, line, rm.source, err)
+		}
+		valid := entry.Timestamp != nil
+		switch entry.Type {
+		case EntryTypeBatchPin:
+			valid = valid && entry.BatchPin != nil
+		case EntryTypeMessage:
+		case EntryTypeBLOB:
+			valid = valid && entry.Hash != nil
+		case EntryTypeSharedStorageData:
+			// Payloads are served on demand, when the event manager requests them from shared storage
+			rm.payloads[entry.PayloadRef] = entry.Data
+			continue
+		default:
+			valid = false
+		}
+		if !valid {
+			return i18n.NewError(ctx, i18n.MsgReplayLogInvalid, line, rm.source, entry.Type)
+		}
+		rm.entries = append(rm.entries, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return i18n.WrapError(ctx, err, i18n.MsgReplayLogOpenFailed, rm.source)
+	}
+	log.L(ctx).Warnf("Debug mode: replaying %d events from replay log '%s' - live inbound events will be dropped", len(rm.entries), rm.source)
+	return nil
+}
+
+func (rm *replayManager) SharedStorage() sharedstorage.Plugin {
+	if !rm.recording && !rm.Replaying() {
+		return rm.sharedstorage
+	}
+	return &replaySharedStorage{Plugin: rm.sharedstorage, rm: rm}
+}
+
+func (rm *replayManager) Replaying() bool {
+	return rm.source != ""
+}
+
+func (rm *replayManager) record(entry *Entry) {
+	rm.mux.Lock()
+	defer rm.mux.Unlock()
+	if rm.logFile == nil {
+		return
+	}
+	rm.sequence++
+	entry.Sequence = rm.sequence
+	entry.Timestamp = fftypes.Now()
+	if err := rm.encoder.Encode(entry); err != nil {
+		log.L(rm.ctx).Errorf("Failed to record %s to replay log: %s", entry.Type, err)
+	}
+}
+
+func (rm *replayManager) RecordBatchPin(batchPin *blockchain.BatchPin, signingKey *fftypes.VerifierRef) {
+	rm.record(&Entry{Type: EntryTypeBatchPin, BatchPin: batchPin, SigningKey: signingKey})
+}
+
+func (rm *replayManager) RecordMessage(peerID string, data []byte) {
+	rm.record(&Entry{Type: EntryTypeMessage, PeerID: peerID, Data: data})
+}
+
+func (rm *replayManager) RecordBLOB(peerID string, hash fftypes.Bytes32, size int64, payloadRef string) {
+	rm.record(&Entry{Type: EntryTypeBLOB, PeerID: peerID, Hash: &hash, Size: size, PayloadRef: payloadRef})
+}
+
+func (rm *replayManager) Start(ei events.EventManager, bi blockchain.Plugin, dx dataexchange.Plugin) error {
+	if rm.Replaying() {
+		rm.done = make(chan struct{})
+		go rm.replayLoop(ei, bi, dx)
+	}
+	return nil
+}
+
+func (rm *replayManager) WaitStop() {
+	if rm.done != nil {
+		<-rm.done
+	}
+	rm.mux.Lock()
+	defer rm.mux.Unlock()
+	if rm.logFile != nil {
+		_ = rm.logFile.Close()
+		rm.logFile = nil
+	}
+}
+
+// replayLoop delivers the entries to the event manager one at a time, in the order they were recorded.
+// Each callback only returns once the event manager has processed the event, or the node is stopping.
+func (rm *replayManager) replayLoop(ei events.EventManager, bi blockchain.Plugin, dx dataexchange.Plugin) {
+	defer close(rm.done)
+	l := log.L(rm.ctx)
+	for i, entry := range rm.entries {
+		if rm.realtime && i > 0 {
+			delay := entry.Timestamp.Time().Sub(*rm.entries[i-1].Timestamp.Time())
+			select {
+			case <-time.After(delay):
+			case <-rm.ctx.Done():
+				l.Infof("Replay stopped after %d of %d events", i, len(rm.entries))
+				return
+			}
+		}
+		l.Debugf("Replaying %s event seq=%d recorded at %s", entry.Type, entry.Sequence, entry.Timestamp)
+		var err error
+		switch entry.Type {
+		case EntryTypeBatchPin:
+			err = ei.BatchPinComplete(bi, entry.BatchPin, entry.SigningKey)
+		case EntryTypeMessage:
+			_, err = ei.MessageReceived(dx, entry.PeerID, entry.Data)
+		case EntryTypeBLOB:
+			err = ei.BLOBReceived(dx, entry.PeerID, *entry.Hash, entry.Size, entry.PayloadRef)
+		}
+		if err != nil {
+			l.Errorf("Replay stopped at %s event seq=%d: %s", entry.Type, entry.Sequence, err)
+			return
+		}
+	}
+	l.Infof("Replay of %d events from '%s' complete", len(rm.entries), rm.source)
+}
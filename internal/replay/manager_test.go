@@ -0,0 +1,272 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/blockchainmocks"
+	"github.com/hyperledger/firefly/mocks/dataexchangemocks"
+	"github.com/hyperledger/firefly/mocks/eventmocks"
+	"github.com/hyperledger/firefly/mocks/sharedstoragemocks"
+	"github.com/hyperledger/firefly/pkg/blockchain"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRecorder(t *testing.T) (*replayManager, *sharedstoragemocks.Plugin, string) {
+	config.Reset()
+	path := filepath.Join(t.TempDir(), "replay.log")
+	config.Set(config.ReplayLogEnabled, true)
+	config.Set(config.ReplayLogPath, path)
+	mss := &sharedstoragemocks.Plugin{}
+	rm, err := NewReplayManager(context.Background(), mss)
+	assert.NoError(t, err)
+	return rm.(*replayManager), mss, path
+}
+
+func writeTestLog(t *testing.T, lines ...string) string {
+	path := filepath.Join(t.TempDir(), "replay.log")
+	err := ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600)
+	assert.NoError(t, err)
+	return path
+}
+
+func TestNewReplayManagerMissingDeps(t *testing.T) {
+	_, err := NewReplayManager(context.Background(), nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestReplayDisabled(t *testing.T) {
+	config.Reset()
+	mss := &sharedstoragemocks.Plugin{}
+	rm, err := NewReplayManager(context.Background(), mss)
+	assert.NoError(t, err)
+	assert.Equal(t, mss, rm.SharedStorage())
+	assert.False(t, rm.Replaying())
+	rm.RecordMessage("peer1", []byte("hello"))
+	assert.NoError(t, rm.Start(nil, nil, nil))
+	rm.WaitStop()
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	rm, mss, path := newTestRecorder(t)
+
+	batchPin := &blockchain.BatchPin{
+		Namespace:       "ns1",
+		TransactionID:   fftypes.NewUUID(),
+		BatchID:         fftypes.NewUUID(),
+		BatchHash:       fftypes.NewRandB32(),
+		BatchPayloadRef: "Qm12345",
+		Contexts:        []*fftypes.Bytes32{fftypes.NewRandB32()},
+		Event: blockchain.Event{
+			ProtocolID: "000000000010/000020/000030",
+			Timestamp:  fftypes.Now(),
+		},
+	}
+	signingKey := &fftypes.VerifierRef{Type: fftypes.VerifierTypeEthAddress, Value: "0x12345"}
+	hash := fftypes.NewRandB32()
+	mss.On("RetrieveData", mock.Anything, "Qm12345").Return(ioutil.NopCloser(strings.NewReader(`{"id":"batch1"}`)), nil)
+
+	ss := rm.SharedStorage()
+	rm.RecordBatchPin(batchPin, signingKey)
+	reader, err := ss.RetrieveData(context.Background(), "Qm12345")
+	assert.NoError(t, err)
+	b, _ := ioutil.ReadAll(reader)
+	assert.Equal(t, `{"id":"batch1"}`, string(b))
+	rm.RecordMessage("peer1", []byte("private batch"))
+	rm.RecordBLOB("peer1", *hash, 12345, "ns1/blob1")
+	assert.NoError(t, rm.Start(nil, nil, nil))
+	rm.WaitStop()
+	rm.RecordMessage("peer1", []byte("ignored after stop"))
+
+	config.Reset()
+	config.Set(config.ReplayDebugSource, path)
+	config.Set(config.ReplayDebugRealtime, true)
+	mss2 := &sharedstoragemocks.Plugin{}
+	rmi, err := NewReplayManager(context.Background(), mss2)
+	assert.NoError(t, err)
+	rm = rmi.(*replayManager)
+	assert.True(t, rm.Replaying())
+	assert.Len(t, rm.entries, 3)
+	assert.Equal(t, int64(4), rm.entries[2].Sequence)
+
+	reader, err = rm.SharedStorage().RetrieveData(context.Background(), "Qm12345")
+	assert.NoError(t, err)
+	b, _ = ioutil.ReadAll(reader)
+	assert.Equal(t, `{"id":"batch1"}`, string(b))
+
+	mei := &eventmocks.EventManager{}
+	mbi := &blockchainmocks.Plugin{}
+	mdx := &dataexchangemocks.Plugin{}
+	mei.On("BatchPinComplete", mbi, mock.MatchedBy(func(bp *blockchain.BatchPin) bool {
+		return bp.BatchID.Equals(batchPin.BatchID) && bp.Event.ProtocolID == batchPin.Event.ProtocolID
+	}), signingKey).Return(nil)
+	mei.On("MessageReceived", mdx, "peer1", []byte("private batch")).Return("", nil)
+	mei.On("BLOBReceived", mdx, "peer1", *hash, int64(12345), "ns1/blob1").Return(nil)
+	assert.NoError(t, rm.Start(mei, mbi, mdx))
+	rm.WaitStop()
+
+	mss.AssertExpectations(t)
+	mss2.AssertExpectations(t)
+	mei.AssertExpectations(t)
+}
+
+func TestRecordAndReplayEnabled(t *testing.T) {
+	config.Reset()
+	config.Set(config.ReplayLogEnabled, true)
+	config.Set(config.ReplayDebugSource, "replay.log")
+	_, err := NewReplayManager(context.Background(), &sharedstoragemocks.Plugin{})
+	assert.Regexp(t, "FF10454", err)
+}
+
+func TestRecordNoPath(t *testing.T) {
+	config.Reset()
+	config.Set(config.ReplayLogEnabled, true)
+	_, err := NewReplayManager(context.Background(), &sharedstoragemocks.Plugin{})
+	assert.Regexp(t, "FF10451", err)
+}
+
+func TestRecordOpenFail(t *testing.T) {
+	config.Reset()
+	config.Set(config.ReplayLogEnabled, true)
+	config.Set(config.ReplayLogPath, filepath.Join(t.TempDir(), "missing", "replay.log"))
+	_, err := NewReplayManager(context.Background(), &sharedstoragemocks.Plugin{})
+	assert.Regexp(t, "FF10452", err)
+}
+
+func TestRecordWriteFail(t *testing.T) {
+	rm, _, path := newTestRecorder(t)
+	rm.logFile.Close()
+	rm.RecordMessage("peer1", []byte("lost"))
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Empty(t, b)
+}
+
+func TestRecordRetrieveFail(t *testing.T) {
+	rm, mss, _ := newTestRecorder(t)
+	defer rm.WaitStop()
+	mss.On("RetrieveData", mock.Anything, "Qm12345").Return(nil, fmt.Errorf("pop"))
+	_, err := rm.SharedStorage().RetrieveData(context.Background(), "Qm12345")
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, int64(0), rm.sequence)
+}
+
+func TestRecordRetrieveReadFail(t *testing.T) {
+	rm, mss, _ := newTestRecorder(t)
+	defer rm.WaitStop()
+	mss.On("RetrieveData", mock.Anything, "Qm12345").Return(ioutil.NopCloser(iotest.ErrReader(fmt.Errorf("pop"))), nil)
+	_, err := rm.SharedStorage().RetrieveData(context.Background(), "Qm12345")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestLoadMissing(t *testing.T) {
+	config.Reset()
+	config.Set(config.ReplayDebugSource, filepath.Join(t.TempDir(), "missing.log"))
+	_, err := NewReplayManager(context.Background(), &sharedstoragemocks.Plugin{})
+	assert.Regexp(t, "FF10452", err)
+}
+
+func TestLoadBadJSON(t *testing.T) {
+	config.Reset()
+	config.Set(config.ReplayDebugSource, writeTestLog(t, `{"seq":1,"type":"dx_message","timestamp":"2022-01-01T00:00:00Z"}`, "!json"))
+	_, err := NewReplayManager(context.Background(), &sharedstoragemocks.Plugin{})
+	assert.Regexp(t, "FF10453.*line 2", err)
+}
+
+func TestLoadInvalidEntries(t *testing.T) {
+	for _, line := range []string{
+		`{"seq":1,"type":"dx_message"}`,
+		`{"seq":1,"type":"batch_pin","timestamp":"2022-01-01T00:00:00Z"}`,
+		`{"seq":1,"type":"dx_blob","timestamp":"2022-01-01T00:00:00Z"}`,
+		`{"seq":1,"type":"unknown","timestamp":"2022-01-01T00:00:00Z"}`,
+	} {
+		config.Reset()
+		config.Set(config.ReplayDebugSource, writeTestLog(t, line))
+		_, err := NewReplayManager(context.Background(), &sharedstoragemocks.Plugin{})
+		assert.Regexp(t, "FF10453.*line 1", err)
+	}
+}
+
+func TestLoadEntryTooLarge(t *testing.T) {
+	defer func() { maxEntrySize = 64 * 1024 * 1024 }()
+	maxEntrySize = 10
+	config.Reset()
+	config.Set(config.ReplayDebugSource, writeTestLog(t, `{"seq":1,"type":"dx_message","timestamp":"2022-01-01T00:00:00Z"}`))
+	_, err := NewReplayManager(context.Background(), &sharedstoragemocks.Plugin{})
+	assert.Regexp(t, "FF10452", err)
+}
+
+func TestReplayStopsOnError(t *testing.T) {
+	config.Reset()
+	config.Set(config.ReplayDebugSource, writeTestLog(t,
+		`{"seq":1,"type":"dx_message","peer":"peer1","timestamp":"2022-01-01T00:00:00Z"}`,
+		`{"seq":2,"type":"dx_message","peer":"peer1","timestamp":"2022-01-01T00:00:01Z"}`,
+	))
+	rm, err := NewReplayManager(context.Background(), &sharedstoragemocks.Plugin{})
+	assert.NoError(t, err)
+
+	mei := &eventmocks.EventManager{}
+	mei.On("MessageReceived", mock.Anything, "peer1", mock.Anything).Return("", fmt.Errorf("pop")).Once()
+	assert.NoError(t, rm.Start(mei, nil, nil))
+	rm.WaitStop()
+	mei.AssertExpectations(t)
+}
+
+func TestReplayRealtimeCancelled(t *testing.T) {
+	config.Reset()
+	config.Set(config.ReplayDebugRealtime, true)
+	config.Set(config.ReplayDebugSource, writeTestLog(t,
+		`{"seq":1,"type":"dx_message","peer":"peer1","timestamp":"2022-01-01T00:00:00Z"}`,
+		`{"seq":2,"type":"dx_message","peer":"peer1","timestamp":"2022-01-02T00:00:00Z"}`,
+	))
+	ctx, cancel := context.WithCancel(context.Background())
+	rm, err := NewReplayManager(ctx, &sharedstoragemocks.Plugin{})
+	assert.NoError(t, err)
+
+	mei := &eventmocks.EventManager{}
+	mei.On("MessageReceived", mock.Anything, "peer1", mock.Anything).Return("", nil).Once().Run(func(args mock.Arguments) {
+		cancel()
+	})
+	assert.NoError(t, rm.Start(mei, nil, nil))
+	rm.WaitStop()
+	mei.AssertExpectations(t)
+}
+
+func TestRecordedLogFormat(t *testing.T) {
+	rm, _, path := newTestRecorder(t)
+	rm.RecordMessage("peer1", []byte("hello"))
+	rm.WaitStop()
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	assert.True(t, scanner.Scan())
+	assert.Regexp(t, `^{"seq":1,"timestamp":"[^"]+","type":"dx_message","peer":"peer1","data":"aGVsbG8="}$`, scanner.Text())
+}
@@ -0,0 +1,50 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/hyperledger/firefly/pkg/sharedstorage"
+)
+
+// replaySharedStorage records the payloads retrieved from shared storage to the replay log, and in debug
+// mode serves them from the log being replayed (falling back to the plugin for any that were not recorded)
+type replaySharedStorage struct {
+	sharedstorage.Plugin
+	rm *replayManager
+}
+
+func (s *replaySharedStorage) RetrieveData(ctx context.Context, payloadRef string) (io.ReadCloser, error) {
+	if data, ok := s.rm.payloads[payloadRef]; ok {
+		return ioutil.NopCloser(bytes.NewReader(data)), nil
+	}
+	reader, err := s.Plugin.RetrieveData(ctx, payloadRef)
+	if err != nil || !s.rm.recording {
+		return reader, err
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	s.rm.record(&Entry{Type: EntryTypeSharedStorageData, PayloadRef: payloadRef, Data: data})
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
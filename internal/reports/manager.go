@@ -0,0 +1,319 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reports
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// dataPageSize is the number of data records read in each page, when totalling storage growth
+const dataPageSize = 100
+
+// operationPageSize is the number of operations read in each page, when totalling blockchain costs
+const operationPageSize = 100
+
+// Manager generates a report for each namespace at the end of every period, which is stored so that
+// it can be downloaded through the API, and optionally written as files to a sink directory
+type Manager interface {
+	WaitStop()
+}
+
+type reportManager struct {
+	ctx         context.Context
+	database    database.Plugin
+	period      fftypes.ReportPeriod
+	interval    time.Duration
+	sinkDir     string
+	sinkFormats []string
+	done        chan struct{}
+}
+
+func NewReportManager(ctx context.Context, di database.Plugin) (Manager, error) {
+	if di == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	rm := &reportManager{
+		ctx:      log.WithLogger(ctx, log.L(ctx).WithField("role", "reports")),
+		database: di,
+		period:   fftypes.ReportPeriod(strings.ToLower(config.GetString(config.ReportsPeriod))),
+		interval: config.GetDuration(config.ReportsCheckInterval),
+		sinkDir:  config.GetString(config.ReportsSinkDirectory),
+		done:     make(chan struct{}),
+	}
+	validPeriod := false
+	for _, p := range fftypes.FFEnumValues("reportperiod") {
+		validPeriod = validPeriod || p.(string) == string(rm.period)
+	}
+	if !validPeriod {
+		return nil, i18n.NewError(ctx, i18n.MsgReportPeriodInvalid, rm.period)
+	}
+	for _, format := range config.GetStringSlice(config.ReportsSinkFormats) {
+		format = strings.ToLower(format)
+		if format != "json" && format != "csv" {
+			return nil, i18n.NewError(ctx, i18n.MsgReportFormatInvalid, format)
+		}
+		rm.sinkFormats = append(rm.sinkFormats, format)
+	}
+	if config.GetBool(config.ReportsEnabled) {
+		go rm.reportLoop()
+	} else {
+		close(rm.done)
+	}
+	return rm, nil
+}
+
+func (rm *reportManager) WaitStop() {
+	<-rm.done
+}
+
+// reportLoop checks on startup, and then on each interval, whether a report is due in each namespace
+func (rm *reportManager) reportLoop() {
+	defer close(rm.done)
+	for {
+		rm.generateDueReports(rm.ctx)
+		select {
+		case <-rm.ctx.Done():
+			log.L(rm.ctx).Debugf("Report loop exiting")
+			return
+		case <-time.After(rm.interval):
+		}
+	}
+}
+
+func (rm *reportManager) generateDueReports(ctx context.Context) {
+	start, end := fftypes.PeriodBounds(rm.period, time.Now())
+	namespaces, _, err := rm.database.GetNamespaces(ctx, database.NamespaceQueryFactory.NewFilter(ctx).And())
+	if err != nil {
+		log.L(ctx).Errorf("Failed to query namespaces for reports: %s", err)
+		return
+	}
+	for _, ns := range namespaces {
+		if ns.Name == fftypes.SystemNamespace {
+			continue
+		}
+		if err := rm.generateReportIfMissing(ctx, ns.Name, fftypes.UnixTime(start.Unix()), fftypes.UnixTime(end.Unix())); err != nil {
+			log.L(ctx).Errorf("Failed to generate %s report for namespace '%s': %s", rm.period, ns.Name, err)
+		}
+	}
+}
+
+func (rm *reportManager) generateReportIfMissing(ctx context.Context, ns string, start, end *fftypes.FFTime) error {
+	fb := database.ReportQueryFactory.NewFilterLimit(ctx, 1)
+	existing, _, err := rm.database.GetReports(ctx, fb.And(
+		fb.Eq("namespace", ns),
+		fb.Eq("period", rm.period),
+		fb.Eq("start", start),
+	))
+	if err != nil || len(existing) > 0 {
+		return err
+	}
+
+	report := &fftypes.Report{
+		ID:        fftypes.NewUUID(),
+		Namespace: ns,
+		Period:    rm.period,
+		Start:     start,
+		End:       end,
+	}
+	if report.Metrics, err = rm.generateMetrics(ctx, ns, start, end); err != nil {
+		return err
+	}
+	if err = rm.database.InsertReport(ctx, report); err != nil {
+		return err
+	}
+	log.L(ctx).Infof("Generated %s report %s for namespace '%s' starting %s", rm.period, report.ID, ns, start)
+	rm.writeToSink(ctx, report)
+	return nil
+}
+
+func totalCount(res *database.FilterResult, err error) (int64, error) {
+	if err != nil || res == nil || res.TotalCount == nil {
+		return 0, err
+	}
+	return *res.TotalCount, nil
+}
+
+func (rm *reportManager) countMessages(ctx context.Context, build func(fb database.FilterBuilder) database.Filter) (int64, error) {
+	fb := database.MessageQueryFactory.NewFilterLimit(ctx, 1)
+	_, res, err := rm.database.GetMessages(ctx, build(fb).Count(true))
+	return totalCount(res, err)
+}
+
+func (rm *reportManager) countOperations(ctx context.Context, build func(fb database.FilterBuilder) database.Filter) (int64, error) {
+	fb := database.OperationQueryFactory.NewFilterLimit(ctx, 1)
+	_, res, err := rm.database.GetOperations(ctx, build(fb).Count(true))
+	return totalCount(res, err)
+}
+
+func (rm *reportManager) generateMetrics(ctx context.Context, ns string, start, end *fftypes.FFTime) (*fftypes.ReportMetrics, error) {
+	metrics := &fftypes.ReportMetrics{
+		Counterparties: []*fftypes.ReportCounterparty{},
+		Operations:     []*fftypes.ReportOperations{},
+		Costs:          []*fftypes.ReportCosts{},
+	}
+	inPeriod := func(fb database.FilterBuilder, field string, filters ...database.Filter) database.Filter {
+		return fb.And(append([]database.Filter{
+			fb.Eq("namespace", ns),
+			fb.Gte(field, start),
+			fb.Lt(field, end),
+		}, filters...)...)
+	}
+
+	// Message volumes are counted by author, for the messages confirmed in the period
+	ifb := database.IdentityQueryFactory.NewFilter(ctx)
+	identities, _, err := rm.database.GetIdentities(ctx, ifb.Or(
+		ifb.Eq("namespace", ns),
+		ifb.Eq("namespace", fftypes.SystemNamespace),
+	))
+	if err != nil {
+		return nil, err
+	}
+	for _, identity := range identities {
+		count, err := rm.countMessages(ctx, func(fb database.FilterBuilder) database.Filter {
+			return inPeriod(fb, "confirmed", fb.Eq("author", identity.DID))
+		})
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			metrics.Counterparties = append(metrics.Counterparties, &fftypes.ReportCounterparty{
+				Identity: identity.DID,
+				Name:     identity.Name,
+				Messages: count,
+			})
+		}
+	}
+
+	// Failure rates are calculated for the operations created in the period
+	for _, opType := range fftypes.FFEnumValues("optype") {
+		total, err := rm.countOperations(ctx, func(fb database.FilterBuilder) database.Filter {
+			return inPeriod(fb, "created", fb.Eq("type", opType))
+		})
+		if err != nil {
+			return nil, err
+		}
+		if total == 0 {
+			continue
+		}
+		failed, err := rm.countOperations(ctx, func(fb database.FilterBuilder) database.Filter {
+			return inPeriod(fb, "created", fb.Eq("type", opType), fb.Eq("status", fftypes.OpStatusFailed))
+		})
+		if err != nil {
+			return nil, err
+		}
+		metrics.Operations = append(metrics.Operations, &fftypes.ReportOperations{
+			Type:        fftypes.OpType(opType.(string)),
+			Total:       total,
+			Failed:      failed,
+			FailureRate: float64(failed) / float64(total),
+		})
+	}
+
+	// Storage growth totals the sizes of the data created in the period, a page at a time
+	storage := &metrics.Storage
+	if storage.Messages, err = rm.countMessages(ctx, func(fb database.FilterBuilder) database.Filter {
+		return inPeriod(fb, "created")
+	}); err != nil {
+		return nil, err
+	}
+	for skip := uint64(0); ; skip += dataPageSize {
+		fb := database.DataQueryFactory.NewFilterLimit(ctx, dataPageSize)
+		page, _, err := rm.database.GetData(ctx, inPeriod(fb, "created").Skip(skip))
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range page {
+			storage.Data++
+			storage.ValueBytes += d.ValueSize
+			if d.Blob != nil && d.Blob.Hash != nil {
+				storage.Blobs++
+				storage.BlobBytes += d.Blob.Size
+			}
+		}
+		if len(page) < dataPageSize {
+			break
+		}
+	}
+
+	// Blockchain costs total the receipts of the operations created in the period, by operation type
+	costs := map[fftypes.OpType]*fftypes.ReportCosts{}
+	for skip := uint64(0); ; skip += operationPageSize {
+		fb := database.OperationQueryFactory.NewFilterLimit(ctx, operationPageSize)
+		page, _, err := rm.database.GetOperations(ctx, inPeriod(fb, "created").Skip(skip))
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range page {
+			if op.Receipt != nil {
+				addCost(costs, op)
+			}
+		}
+		if len(page) < operationPageSize {
+			break
+		}
+	}
+	for _, opType := range fftypes.FFEnumValues("optype") {
+		if c, ok := costs[fftypes.OpType(opType.(string))]; ok {
+			metrics.Costs = append(metrics.Costs, c)
+		}
+	}
+	return metrics, nil
+}
+
+func addCost(costs map[fftypes.OpType]*fftypes.ReportCosts, op *fftypes.Operation) {
+	c, ok := costs[op.Type]
+	if !ok {
+		c = &fftypes.ReportCosts{
+			Type:    op.Type,
+			GasUsed: fftypes.NewFFBigInt(0),
+			Fee:     fftypes.NewFFBigInt(0),
+		}
+		costs[op.Type] = c
+	}
+	c.Transactions++
+	if op.Receipt.GasUsed != nil {
+		c.GasUsed.Int().Add(c.GasUsed.Int(), op.Receipt.GasUsed.Int())
+	}
+	if op.Receipt.Fee != nil {
+		c.Fee.Int().Add(c.Fee.Int(), op.Receipt.Fee.Int())
+	}
+}
+
+// writeToSink writes the report in each configured format. The report is already stored, so a failure
+// is logged rather than retried - the report can still be downloaded through the API.
+func (rm *reportManager) writeToSink(ctx context.Context, report *fftypes.Report) {
+	if rm.sinkDir == "" {
+		return
+	}
+	for _, format := range rm.sinkFormats {
+		content, _ := report.Formatted(ctx, format)
+		path := filepath.Join(rm.sinkDir, report.FileName(format))
+		if err := ioutil.WriteFile(path, content, 0640); err != nil {
+			log.L(ctx).Errorf("Failed to write report %s to '%s': %s", report.ID, path, err)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reports
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestReportManager(t *testing.T) (*reportManager, *databasemocks.Plugin) {
+	config.Reset()
+	mdi := &databasemocks.Plugin{}
+	rm, err := NewReportManager(context.Background(), mdi)
+	assert.NoError(t, err)
+	rm.WaitStop()
+	return rm.(*reportManager), mdi
+}
+
+func counted(count int64) *database.FilterResult {
+	return &database.FilterResult{TotalCount: &count}
+}
+
+func filterContains(parts ...string) interface{} {
+	return mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		fs := fi.String()
+		for _, p := range parts {
+			if !strings.Contains(fs, p) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func filterSkip(skip uint64) interface{} {
+	return mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Skip == skip
+	})
+}
+
+func filterLimit(limit uint64) interface{} {
+	return mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == limit
+	})
+}
+
+func testStart() *fftypes.FFTime {
+	return fftypes.UnixTime(1643673600) // 2022-02-01
+}
+
+func testEnd() *fftypes.FFTime {
+	return fftypes.UnixTime(1646092800) // 2022-03-01
+}
+
+func mockMetrics(mdi *databasemocks.Plugin) {
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{
+		{IdentityBase: fftypes.IdentityBase{DID: "did:firefly:org/org1", Name: "org1"}},
+		{IdentityBase: fftypes.IdentityBase{DID: "did:firefly:org/org2", Name: "org2"}},
+	}, nil, nil)
+	mdi.On("GetMessages", mock.Anything, filterContains("did:firefly:org/org1")).Return([]*fftypes.Message{}, counted(5), nil)
+	mdi.On("GetMessages", mock.Anything, filterContains("did:firefly:org/org2")).Return([]*fftypes.Message{}, counted(0), nil)
+	mdi.On("GetMessages", mock.Anything, filterContains("created")).Return([]*fftypes.Message{}, counted(7), nil)
+	fullOpsPage := make([]*fftypes.Operation, operationPageSize)
+	for i := range fullOpsPage {
+		fullOpsPage[i] = &fftypes.Operation{Type: fftypes.OpTypeBlockchainInvoke}
+	}
+	fullOpsPage[0].Receipt = &fftypes.BlockchainReceipt{GasUsed: fftypes.NewFFBigInt(21000), Fee: fftypes.NewFFBigInt(210000)}
+	fullOpsPage[1].Receipt = &fftypes.BlockchainReceipt{GasUsed: fftypes.NewFFBigInt(30000), Fee: fftypes.NewFFBigInt(300000)}
+	mdi.On("GetOperations", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Limit == operationPageSize && fi.Skip == 0
+	})).Return(fullOpsPage, nil, nil)
+	mdi.On("GetOperations", mock.Anything, filterLimit(operationPageSize)).Return([]*fftypes.Operation{
+		{Type: fftypes.OpTypeBlockchainBatchPin, Receipt: &fftypes.BlockchainReceipt{}},
+	}, nil, nil)
+	mdi.On("GetOperations", mock.Anything, filterContains("blockchain_batch_pin", "Failed")).Return([]*fftypes.Operation{}, counted(1), nil)
+	mdi.On("GetOperations", mock.Anything, filterContains("blockchain_batch_pin")).Return([]*fftypes.Operation{}, counted(4), nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, counted(0), nil)
+	fullPage := make(fftypes.DataArray, dataPageSize)
+	for i := range fullPage {
+		fullPage[i] = &fftypes.Data{ValueSize: 10}
+	}
+	mdi.On("GetData", mock.Anything, filterSkip(0)).Return(fullPage, nil, nil)
+	mdi.On("GetData", mock.Anything, mock.Anything).Return(fftypes.DataArray{
+		{ValueSize: 5, Blob: &fftypes.BlobRef{Hash: fftypes.NewRandB32(), Size: 1024}},
+		{ValueSize: 5, Blob: &fftypes.BlobRef{}},
+	}, nil, nil)
+}
+
+func TestNewReportManagerMissingDeps(t *testing.T) {
+	_, err := NewReportManager(context.Background(), nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestNewReportManagerBadPeriod(t *testing.T) {
+	config.Reset()
+	config.Set(config.ReportsPeriod, "yearly")
+	_, err := NewReportManager(context.Background(), &databasemocks.Plugin{})
+	assert.Regexp(t, "FF10428.*yearly", err)
+}
+
+func TestNewReportManagerBadFormat(t *testing.T) {
+	config.Reset()
+	config.Set(config.ReportsSinkFormats, []string{"json", "xml"})
+	_, err := NewReportManager(context.Background(), &databasemocks.Plugin{})
+	assert.Regexp(t, "FF10429.*xml", err)
+}
+
+func TestReportLoop(t *testing.T) {
+	config.Reset()
+	config.Set(config.ReportsEnabled, true)
+	config.Set(config.ReportsPeriod, "Weekly")
+	config.Set(config.ReportsCheckInterval, "1ms")
+	ctx, cancel := context.WithCancel(context.Background())
+	mdi := &databasemocks.Plugin{}
+	mdi.On("GetNamespaces", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Once()
+	mdi.On("GetNamespaces", mock.Anything, mock.Anything).Return([]*fftypes.Namespace{}, nil, nil).Run(func(args mock.Arguments) {
+		cancel()
+	})
+	rm, err := NewReportManager(ctx, mdi)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.ReportPeriodWeekly, rm.(*reportManager).period)
+	rm.WaitStop()
+	mdi.AssertExpectations(t)
+}
+
+func TestGenerateDueReports(t *testing.T) {
+	rm, mdi := newTestReportManager(t)
+	rm.sinkDir = t.TempDir()
+	rm.sinkFormats = []string{"json", "csv"}
+
+	mdi.On("GetNamespaces", mock.Anything, mock.Anything).Return([]*fftypes.Namespace{
+		{Name: fftypes.SystemNamespace},
+		{Name: "ns1"},
+		{Name: "ns2"},
+		{Name: "ns3"},
+	}, nil, nil)
+	mdi.On("GetReports", mock.Anything, filterContains("ns1")).Return([]*fftypes.Report{{}}, nil, nil)
+	mdi.On("GetReports", mock.Anything, filterContains("ns2")).Return([]*fftypes.Report{}, nil, nil)
+	mdi.On("GetReports", mock.Anything, filterContains("ns3")).Return(nil, nil, fmt.Errorf("pop"))
+	mockMetrics(mdi)
+	var inserted *fftypes.Report
+	mdi.On("InsertReport", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		inserted = args[1].(*fftypes.Report)
+	})
+
+	rm.generateDueReports(context.Background())
+
+	assert.Equal(t, "ns2", inserted.Namespace)
+	assert.Equal(t, fftypes.ReportPeriodMonthly, inserted.Period)
+	m := inserted.Metrics
+	assert.Equal(t, []*fftypes.ReportCounterparty{{Identity: "did:firefly:org/org1", Name: "org1", Messages: 5}}, m.Counterparties)
+	assert.Equal(t, []*fftypes.ReportOperations{{Type: fftypes.OpTypeBlockchainBatchPin, Total: 4, Failed: 1, FailureRate: 0.25}}, m.Operations)
+	assert.Equal(t, fftypes.ReportStorage{Messages: 7, Data: 102, ValueBytes: 1010, Blobs: 1, BlobBytes: 1024}, m.Storage)
+	assert.Equal(t, []*fftypes.ReportCosts{
+		{Type: fftypes.OpTypeBlockchainBatchPin, Transactions: 1, GasUsed: fftypes.NewFFBigInt(0), Fee: fftypes.NewFFBigInt(0)},
+		{Type: fftypes.OpTypeBlockchainInvoke, Transactions: 2, GasUsed: fftypes.NewFFBigInt(51000), Fee: fftypes.NewFFBigInt(510000)},
+	}, m.Costs)
+
+	b, err := ioutil.ReadFile(filepath.Join(rm.sinkDir, inserted.FileName("csv")))
+	assert.NoError(t, err)
+	assert.Equal(t, inserted.CSV(), b)
+	_, err = os.Stat(filepath.Join(rm.sinkDir, inserted.FileName("json")))
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+}
+
+func TestGenerateReportSinkFail(t *testing.T) {
+	rm, mdi := newTestReportManager(t)
+	rm.sinkDir = filepath.Join(t.TempDir(), "missing")
+	mdi.On("GetReports", mock.Anything, mock.Anything).Return([]*fftypes.Report{}, nil, nil)
+	mockMetrics(mdi)
+	mdi.On("InsertReport", mock.Anything, mock.Anything).Return(nil)
+
+	err := rm.generateReportIfMissing(context.Background(), "ns1", testStart(), testEnd())
+	assert.NoError(t, err)
+}
+
+func TestGenerateReportInsertFail(t *testing.T) {
+	rm, mdi := newTestReportManager(t)
+	mdi.On("GetReports", mock.Anything, mock.Anything).Return([]*fftypes.Report{}, nil, nil)
+	mockMetrics(mdi)
+	mdi.On("InsertReport", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+
+	err := rm.generateReportIfMissing(context.Background(), "ns1", testStart(), testEnd())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGenerateMetricsIdentitiesFail(t *testing.T) {
+	rm, mdi := newTestReportManager(t)
+	mdi.On("GetReports", mock.Anything, mock.Anything).Return([]*fftypes.Report{}, nil, nil)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := rm.generateReportIfMissing(context.Background(), "ns1", testStart(), testEnd())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGenerateMetricsCounterpartyFail(t *testing.T) {
+	rm, mdi := newTestReportManager(t)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{
+		{IdentityBase: fftypes.IdentityBase{DID: "did:firefly:org/org1"}},
+	}, nil, nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := rm.generateMetrics(context.Background(), "ns1", testStart(), testEnd())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGenerateMetricsOperationsTotalFail(t *testing.T) {
+	rm, mdi := newTestReportManager(t)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := rm.generateMetrics(context.Background(), "ns1", testStart(), testEnd())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGenerateMetricsOperationsFailedFail(t *testing.T) {
+	rm, mdi := newTestReportManager(t)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	mdi.On("GetOperations", mock.Anything, filterContains("Failed")).Return(nil, nil, fmt.Errorf("pop"))
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, counted(1), nil)
+
+	_, err := rm.generateMetrics(context.Background(), "ns1", testStart(), testEnd())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGenerateMetricsStorageMessagesFail(t *testing.T) {
+	rm, mdi := newTestReportManager(t)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := rm.generateMetrics(context.Background(), "ns1", testStart(), testEnd())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGenerateMetricsStorageDataFail(t *testing.T) {
+	rm, mdi := newTestReportManager(t)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, counted(0), nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, counted(0), nil)
+	mdi.On("GetData", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := rm.generateMetrics(context.Background(), "ns1", testStart(), testEnd())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGenerateMetricsCostsFail(t *testing.T) {
+	rm, mdi := newTestReportManager(t)
+	mdi.On("GetIdentities", mock.Anything, mock.Anything).Return([]*fftypes.Identity{}, nil, nil)
+	mdi.On("GetOperations", mock.Anything, filterLimit(operationPageSize)).Return(nil, nil, fmt.Errorf("pop"))
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, counted(0), nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, counted(0), nil)
+	mdi.On("GetData", mock.Anything, mock.Anything).Return(fftypes.DataArray{}, nil, nil)
+
+	_, err := rm.generateMetrics(context.Background(), "ns1", testStart(), testEnd())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGenerateReportNoSink(t *testing.T) {
+	rm, mdi := newTestReportManager(t)
+	mdi.On("GetReports", mock.Anything, mock.Anything).Return([]*fftypes.Report{}, nil, nil)
+	mockMetrics(mdi)
+	mdi.On("InsertReport", mock.Anything, mock.Anything).Return(nil)
+
+	err := rm.generateReportIfMissing(context.Background(), "ns1", testStart(), testEnd())
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+}
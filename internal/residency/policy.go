@@ -0,0 +1,107 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package residency
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const (
+	// NodeProfileRegion is the field in the profile of a node, that holds the region in which the node stores data
+	NodeProfileRegion = "region"
+	// NodeProfileEndpoint is the field in the profile of a node, that holds the endpoint of its data exchange
+	NodeProfileEndpoint = "endpoint"
+)
+
+// Rules are the data residency constraints of a namespace. An empty list places no constraint.
+type Rules struct {
+	Regions       []string `json:"regions"`
+	DXEndpoints   []string `json:"dxEndpoints"`
+	SharedStorage []string `json:"sharedStorage"`
+}
+
+// Policy holds the data residency rules configured against each of the predefined namespaces,
+// which restrict where the payloads of messages in that namespace can be sent and stored
+type Policy struct {
+	namespaces map[string]*Rules
+}
+
+func NewPolicy(ctx context.Context) (*Policy, error) {
+	p := &Policy{
+		namespaces: make(map[string]*Rules),
+	}
+	for i, nsObject := range config.GetObjectArray(config.NamespacesPredefined) {
+		rulesObject, ok := nsObject.GetObjectOk("residency")
+		if !ok {
+			continue
+		}
+		var rules *Rules
+		b, _ := json.Marshal(rulesObject)
+		if err := json.Unmarshal(b, &rules); err != nil {
+			return nil, i18n.WrapError(ctx, err, i18n.MsgResidencyRulesInvalid, i)
+		}
+		p.namespaces[nsObject.GetString("name")] = rules
+	}
+	return p, nil
+}
+
+func permitted(allowed []string, value string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if a == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSharedStorage verifies that payloads in the namespace can be published to the named shared storage plugin
+func (p *Policy) CheckSharedStorage(ctx context.Context, ns, pluginName string) error {
+	if rules, ok := p.namespaces[ns]; ok && !permitted(rules.SharedStorage, pluginName) {
+		return i18n.NewError(ctx, i18n.MsgSharedStorageNotPermitted, ns, pluginName)
+	}
+	return nil
+}
+
+// RestrictsNodes returns true if the namespace constrains the nodes that private payloads can be sent to
+func (p *Policy) RestrictsNodes(ns string) bool {
+	rules, ok := p.namespaces[ns]
+	return ok && (len(rules.Regions) > 0 || len(rules.DXEndpoints) > 0)
+}
+
+// CheckNode verifies that payloads in the namespace can be sent to the node, based on the region
+// and data exchange endpoint published in the profile of the node
+func (p *Policy) CheckNode(ctx context.Context, ns string, node *fftypes.Identity) error {
+	rules, ok := p.namespaces[ns]
+	if !ok {
+		return nil
+	}
+	if region := node.Profile.GetString(NodeProfileRegion); !permitted(rules.Regions, region) {
+		return i18n.NewError(ctx, i18n.MsgNodeRegionNotPermitted, ns, node.DID, region)
+	}
+	if endpoint := node.Profile.GetString(NodeProfileEndpoint); !permitted(rules.DXEndpoints, endpoint) {
+		return i18n.NewError(ctx, i18n.MsgNodeEndpointNotPermitted, ns, node.DID, endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package residency
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPolicy(t *testing.T, rules fftypes.JSONObject) *Policy {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "default"},
+		{"name": "ns1", "residency": rules},
+	})
+	p, err := NewPolicy(context.Background())
+	assert.NoError(t, err)
+	return p
+}
+
+func TestNewPolicyDefaults(t *testing.T) {
+	config.Reset()
+	p, err := NewPolicy(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, p.namespaces)
+}
+
+func TestNewPolicyInvalid(t *testing.T) {
+	config.Reset()
+	config.Set(config.NamespacesPredefined, fftypes.JSONObjectArray{
+		{"name": "ns1", "residency": fftypes.JSONObject{"regions": "eu-west"}},
+	})
+	_, err := NewPolicy(context.Background())
+	assert.Regexp(t, "FF10409.*0", err)
+}
+
+func TestCheckSharedStorage(t *testing.T) {
+	p := newTestPolicy(t, fftypes.JSONObject{
+		"sharedStorage": []interface{}{"ipfs_eu"},
+	})
+	ctx := context.Background()
+	assert.NoError(t, p.CheckSharedStorage(ctx, "ns1", "ipfs_eu"))
+	assert.NoError(t, p.CheckSharedStorage(ctx, "default", "ipfs_us"))
+	err := p.CheckSharedStorage(ctx, "ns1", "ipfs_us")
+	assert.Regexp(t, "FF10410.*ns1.*ipfs_us", err)
+}
+
+func TestRestrictsNodes(t *testing.T) {
+	p := newTestPolicy(t, fftypes.JSONObject{
+		"sharedStorage": []interface{}{"ipfs_eu"},
+	})
+	assert.False(t, p.RestrictsNodes("ns1"))
+	assert.False(t, p.RestrictsNodes("default"))
+
+	p = newTestPolicy(t, fftypes.JSONObject{
+		"regions": []interface{}{"eu-west"},
+	})
+	assert.True(t, p.RestrictsNodes("ns1"))
+
+	p = newTestPolicy(t, fftypes.JSONObject{
+		"dxEndpoints": []interface{}{"https://dx.eu.example.com"},
+	})
+	assert.True(t, p.RestrictsNodes("ns1"))
+}
+
+func TestCheckNode(t *testing.T) {
+	p := newTestPolicy(t, fftypes.JSONObject{
+		"regions":     []interface{}{"eu-west", "eu-central"},
+		"dxEndpoints": []interface{}{"https://dx.eu.example.com"},
+	})
+	ctx := context.Background()
+	node := &fftypes.Identity{
+		IdentityBase: fftypes.IdentityBase{
+			DID: "did:firefly:node/node2",
+		},
+		IdentityProfile: fftypes.IdentityProfile{
+			Profile: fftypes.JSONObject{
+				"region":   "eu-central",
+				"endpoint": "https://dx.eu.example.com",
+			},
+		},
+	}
+	assert.NoError(t, p.CheckNode(ctx, "ns1", node))
+	assert.NoError(t, p.CheckNode(ctx, "default", &fftypes.Identity{}))
+	assert.NoError(t, p.CheckSharedStorage(ctx, "ns1", "ipfs_us"))
+
+	node.Profile["endpoint"] = "https://dx.us.example.com"
+	err := p.CheckNode(ctx, "ns1", node)
+	assert.Regexp(t, "FF10412.*node2.*dx.us", err)
+
+	node.Profile["region"] = "us-east"
+	err = p.CheckNode(ctx, "ns1", node)
+	assert.Regexp(t, "FF10411.*node2.*us-east", err)
+
+	err = p.CheckNode(ctx, "ns1", &fftypes.Identity{})
+	assert.Regexp(t, "FF10411", err)
+}
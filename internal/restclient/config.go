@@ -24,11 +24,13 @@ const (
 	defaultRetryWaitTime             = "250ms"
 	defaultRetryMaxWaitTime          = "30s"
 	defaultRequestTimeout            = "30s"
+	defaultCallTimeout               = "2m"
 	defaultHTTPIdleTimeout           = "475ms" // Node.js default keepAliveTimeout is 5 seconds, so we have to set a base below this
 	defaultHTTPMaxIdleConns          = 100     // match Go's default
 	defaultHTTPConnectionTimeout     = "30s"
 	defaultHTTPTLSHandshakeTimeout   = "10s" // match Go's default
 	defaultHTTPExpectContinueTimeout = "1s"  // match Go's default
+	defaultSigningMaxSkew            = "1m"
 )
 
 const (
@@ -52,6 +54,8 @@ const (
 	HTTPConfigRetryMaxDelay = "retry.maxWaitTime"
 	// HTTPConfigRequestTimeout the request timeout
 	HTTPConfigRequestTimeout = "requestTimeout"
+	// HTTPConfigCallTimeout the overall timeout for a call, including any retries - enforced as a deadline on the context of the call
+	HTTPConfigCallTimeout = "callTimeout"
 	// HTTPIdleTimeout the max duration to hold a HTTP keepalive connection between calls
 	HTTPIdleTimeout = "idleTimeout"
 	// HTTPMaxIdleConns the max number of idle connections to hold pooled
@@ -63,6 +67,11 @@ const (
 	// HTTPExpectContinueTimeout see ExpectContinueTimeout in Go docs
 	HTTPExpectContinueTimeout = "expectContinueTimeout"
 
+	// HTTPConfigSigningKey if set, every request is signed with a HMAC-SHA256 signature using this key, shared with the connector
+	HTTPConfigSigningKey = "signing.key"
+	// HTTPConfigSigningMaxSkew the maximum age of a signed message received from the connector, before it is rejected as a potential replay
+	HTTPConfigSigningMaxSkew = "signing.maxSkew"
+
 	// HTTPCustomClient - unit test only - allows injection of a custom HTTP client to resty
 	HTTPCustomClient = "customClient"
 )
@@ -78,11 +87,14 @@ func InitPrefix(prefix config.KeySet) {
 	prefix.AddKnownKey(HTTPConfigRetryInitDelay, defaultRetryWaitTime)
 	prefix.AddKnownKey(HTTPConfigRetryMaxDelay, defaultRetryMaxWaitTime)
 	prefix.AddKnownKey(HTTPConfigRequestTimeout, defaultRequestTimeout)
+	prefix.AddKnownKey(HTTPConfigCallTimeout, defaultCallTimeout)
 	prefix.AddKnownKey(HTTPIdleTimeout, defaultHTTPIdleTimeout)
 	prefix.AddKnownKey(HTTPMaxIdleConns, defaultHTTPMaxIdleConns)
 	prefix.AddKnownKey(HTTPConnectionTimeout, defaultHTTPConnectionTimeout)
 	prefix.AddKnownKey(HTTTPTLSHandshakeTimeout, defaultHTTPTLSHandshakeTimeout)
 	prefix.AddKnownKey(HTTPExpectContinueTimeout, defaultHTTPExpectContinueTimeout)
+	prefix.AddKnownKey(HTTPConfigSigningKey)
+	prefix.AddKnownKey(HTTPConfigSigningMaxSkew, defaultSigningMaxSkew)
 
 	prefix.AddKnownKey(HTTPCustomClient)
 }
@@ -19,6 +19,7 @@ package restclient
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -30,16 +31,43 @@ import (
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/signing"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/sirupsen/logrus"
 )
 
 type retryCtxKey struct{}
 
+type noCallTimeoutKey struct{}
+
 type retryCtx struct {
 	id       string
 	start    time.Time
 	attempts uint
+	cancel   context.CancelFunc
+}
+
+// TimeoutError is returned by WrapRestErr when a call did not complete before the deadline on its context,
+// either because of the call timeout of the client, or a deadline set by the caller.
+type TimeoutError struct {
+	error
+}
+
+func (te *TimeoutError) Unwrap() error {
+	return te.error
+}
+
+// IsTimeout returns true if the error is, or wraps, a timeout of a call
+func IsTimeout(err error) bool {
+	var te *TimeoutError
+	return errors.As(err, &te)
+}
+
+// WithoutCallTimeout marks the context of a request that uses SetDoNotParseResponse(true), so the call timeout
+// of the client is not applied to it. The caller reads the response body after the call returns, so a deadline
+// would cut off a large download part way through - and the middleware that releases the timeout does not run.
+func WithoutCallTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCallTimeoutKey{}, true)
 }
 
 // OnAfterResponse when using SetDoNotParseResponse(true) for streming binary replies,
@@ -107,6 +135,7 @@ func New(ctx context.Context, staticConfig config.Prefix) *resty.Client {
 	}
 
 	client.SetTimeout(staticConfig.GetDuration(HTTPConfigRequestTimeout))
+	callTimeout := staticConfig.GetDuration(HTTPConfigCallTimeout)
 
 	client.OnBeforeRequest(func(c *resty.Client, req *resty.Request) error {
 		rctx := req.Context()
@@ -117,6 +146,10 @@ func New(ctx context.Context, staticConfig config.Prefix) *resty.Client {
 				id:    fftypes.ShortID(),
 				start: time.Now(),
 			}
+			// The call timeout spans all the retries of the request, so that a hung connector cannot block the caller
+			if callTimeout > 0 && rctx.Value(noCallTimeoutKey{}) == nil {
+				rctx, r.cancel = context.WithTimeout(rctx, callTimeout)
+			}
 			rctx = context.WithValue(rctx, retryCtxKey{}, r)
 			// Create a request logger from the root logger passed into the client
 			l := log.L(ctx).WithField("breq", r.id)
@@ -129,7 +162,16 @@ func New(ctx context.Context, staticConfig config.Prefix) *resty.Client {
 
 	// Note that callers using SetNotParseResponse will need to invoke this themselves
 
-	client.OnAfterResponse(func(c *resty.Client, r *resty.Response) error { OnAfterResponse(c, r); return nil })
+	retryEnabled := staticConfig.GetBool(HTTPConfigRetryEnabled)
+	client.OnAfterResponse(func(c *resty.Client, r *resty.Response) error {
+		OnAfterResponse(c, r)
+		if !retryEnabled {
+			// With retry enabled, the retry condition releases the call timeout once there are no more attempts
+			cancelCallTimeout(r.Request)
+		}
+		return nil
+	})
+	client.OnError(func(req *resty.Request, err error) { cancelCallTimeout(req) })
 
 	headers := staticConfig.GetObject(HTTPConfigHeaders)
 	for k, v := range headers {
@@ -143,7 +185,14 @@ func New(ctx context.Context, staticConfig config.Prefix) *resty.Client {
 		client.SetHeader("Authorization", fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", authUsername, authPassword)))))
 	}
 
-	if staticConfig.GetBool(HTTPConfigRetryEnabled) {
+	if signingKey := staticConfig.GetString(HTTPConfigSigningKey); signingKey != "" {
+		signer := signing.NewSigner(signingKey, staticConfig.GetDuration(HTTPConfigSigningMaxSkew))
+		client.SetPreRequestHook(func(c *resty.Client, req *http.Request) error {
+			return signer.SignRequest(req)
+		})
+	}
+
+	if retryEnabled {
 		retryCount := staticConfig.GetInt(HTTPConfigRetryCount)
 		minTimeout := staticConfig.GetDuration(HTTPConfigRetryInitDelay)
 		maxTimeout := staticConfig.GetDuration(HTTPConfigRetryMaxDelay)
@@ -153,6 +202,9 @@ func New(ctx context.Context, staticConfig config.Prefix) *resty.Client {
 			SetRetryMaxWaitTime(maxTimeout).
 			AddRetryCondition(func(r *resty.Response, err error) bool {
 				if r == nil || r.IsSuccess() {
+					if r != nil {
+						cancelCallTimeout(r.Request)
+					}
 					return false
 				}
 				rctx := r.Request.Context()
@@ -166,6 +218,14 @@ func New(ctx context.Context, staticConfig config.Prefix) *resty.Client {
 	return client
 }
 
+// cancelCallTimeout releases the call timeout of a request. Requests using SetDoNotParseResponse do not have
+// a call timeout, as they are marked with WithoutCallTimeout.
+func cancelCallTimeout(req *resty.Request) {
+	if rc, ok := req.Context().Value(retryCtxKey{}).(*retryCtx); ok && rc.cancel != nil {
+		rc.cancel()
+	}
+}
+
 func WrapRestErr(ctx context.Context, res *resty.Response, err error, key i18n.MessageKey) error {
 	var respData string
 	if res != nil {
@@ -183,7 +243,15 @@ func WrapRestErr(ctx context.Context, res *resty.Response, err error, key i18n.M
 		}
 	}
 	if err != nil {
+		if isTimeoutErr(err) {
+			return &TimeoutError{error: i18n.WrapError(ctx, i18n.WrapError(ctx, err, key, respData), i18n.MsgConnectorCallTimeout)}
+		}
 		return i18n.WrapError(ctx, err, key, respData)
 	}
 	return i18n.NewError(ctx, key, respData)
 }
+
+func isTimeoutErr(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
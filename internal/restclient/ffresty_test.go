@@ -18,13 +18,18 @@ package restclient
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/signing"
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
 )
@@ -69,6 +74,33 @@ func TestRequestOK(t *testing.T) {
 	assert.Equal(t, 1, httpmock.GetTotalCallCount())
 }
 
+func TestRequestSigned(t *testing.T) {
+
+	customClient := &http.Client{}
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.Set(HTTPConfigSigningKey, "key1")
+	utConfPrefix.Set(HTTPCustomClient, customClient)
+
+	c := New(context.Background(), utConfPrefix)
+	httpmock.ActivateNonDefault(customClient)
+	defer httpmock.DeactivateAndReset()
+
+	signer := signing.NewSigner("key1", 1*time.Minute)
+	httpmock.RegisterResponder("POST", "http://localhost:12345/test",
+		func(req *http.Request) (*http.Response, error) {
+			err := signer.Verify(req.Context(), signing.DirectionOutbound, req.Method, req.URL.RequestURI(),
+				req.Header.Get(signing.HeaderTimestamp), req.Header.Get(signing.HeaderSignature), []byte(`{"some":"data"}`))
+			assert.NoError(t, err)
+			return httpmock.NewStringResponder(200, `{}`)(req)
+		})
+
+	resp, err := c.R().SetBody(map[string]string{"some": "data"}).Post("/test")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode())
+}
+
 func TestRequestRetry(t *testing.T) {
 
 	ctx := context.Background()
@@ -159,3 +191,106 @@ func TestErrResponse(t *testing.T) {
 func TestOnAfterResponseNil(t *testing.T) {
 	OnAfterResponse(nil, nil)
 }
+
+func TestCallTimeout(t *testing.T) {
+
+	ctx := context.Background()
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.Set(HTTPConfigCallTimeout, "10ms")
+
+	c := New(ctx, utConfPrefix)
+	httpmock.ActivateNonDefault(c.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder("GET", "http://localhost:12345/test",
+		func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		})
+
+	resp, err := c.R().Get("/test")
+	assert.Error(t, err)
+
+	err = WrapRestErr(ctx, resp, err, i18n.MsgEthconnectRESTErr)
+	assert.Regexp(t, "FF10507.*FF10111", err)
+	assert.True(t, IsTimeout(err))
+	assert.True(t, IsTimeout(fmt.Errorf("wrapped: %w", err)))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestCallTimeoutSpansRetries(t *testing.T) {
+
+	ctx := context.Background()
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigURL, "http://localhost:12345")
+	utConfPrefix.Set(HTTPConfigRetryEnabled, true)
+	utConfPrefix.Set(HTTPConfigRetryInitDelay, 1)
+	utConfPrefix.Set(HTTPConfigRequestTimeout, "10m")
+
+	c := New(ctx, utConfPrefix)
+	httpmock.ActivateNonDefault(c.GetClient())
+	defer httpmock.DeactivateAndReset()
+
+	var deadlines []time.Time
+	httpmock.RegisterResponder("GET", "http://localhost:12345/test",
+		func(req *http.Request) (*http.Response, error) {
+			deadline, ok := req.Context().Deadline()
+			assert.True(t, ok)
+			deadlines = append(deadlines, deadline)
+			assert.NoError(t, req.Context().Err())
+			if len(deadlines) < 3 {
+				return httpmock.NewStringResponse(500, `{"message": "pop"}`), nil
+			}
+			return httpmock.NewStringResponse(200, `{}`), nil
+		})
+
+	resp, err := c.R().Get("/test")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode())
+	assert.Len(t, deadlines, 3)
+	assert.Equal(t, deadlines[0], deadlines[2])
+	assert.Regexp(t, "canceled", resp.Request.Context().Err())
+}
+
+func TestCallTimeoutNotAppliedToStreamedResponse(t *testing.T) {
+
+	ctx := context.Background()
+
+	// The server takes several times the call timeout to stream the whole body
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		for i := 0; i < 5; i++ {
+			_, _ = w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	resetConf()
+	utConfPrefix.Set(HTTPConfigURL, server.URL)
+	utConfPrefix.Set(HTTPConfigCallTimeout, "10ms")
+
+	c := New(ctx, utConfPrefix)
+	resp, err := c.R().
+		SetContext(WithoutCallTimeout(ctx)).
+		SetDoNotParseResponse(true).
+		Get("/stream")
+	assert.NoError(t, err)
+	defer resp.RawBody().Close()
+	_, hasDeadline := resp.Request.Context().Deadline()
+	assert.False(t, hasDeadline)
+
+	body, err := ioutil.ReadAll(resp.RawBody())
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat("chunk", 5), string(body))
+}
+
+func TestErrNotTimeout(t *testing.T) {
+	err := WrapRestErr(context.Background(), nil, fmt.Errorf("pop"), i18n.MsgEthconnectRESTErr)
+	assert.Regexp(t, "FF10111.*pop", err)
+	assert.False(t, IsTimeout(err))
+}
@@ -0,0 +1,136 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/metrics"
+)
+
+var breakers = struct {
+	sync.Mutex
+	database *CircuitBreaker
+}{}
+
+// databaseBreaker returns the circuit breaker shared by all the retry loops for database operations, if enabled
+func databaseBreaker() *CircuitBreaker {
+	if !config.GetBool(config.RetryBreakerEnabled) {
+		return nil
+	}
+	breakers.Lock()
+	defer breakers.Unlock()
+	if breakers.database == nil {
+		breakers.database = NewCircuitBreaker("database",
+			config.GetInt(config.RetryBreakerFailureThreshold),
+			config.GetDuration(config.RetryBreakerResetTimeout))
+	}
+	return breakers.database
+}
+
+// CircuitBreaker is shared by the retry loops that call the same target. After a number of consecutive failed
+// attempts across all the loops it opens, and the loops wait for the reset timeout before a single trial attempt
+// is let through. The breaker closes again if the trial succeeds, or stays open for another timeout if it fails.
+type CircuitBreaker struct {
+	name             string
+	failureThreshold int
+	resetTimeout     time.Duration
+	mux              sync.Mutex
+	failures         int
+	openUntil        time.Time
+	trial            bool
+	changed          chan struct{}
+}
+
+func NewCircuitBreaker(name string, failureThreshold int, resetTimeout time.Duration) *CircuitBreaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	return &CircuitBreaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		resetTimeout:     resetTimeout,
+		changed:          make(chan struct{}),
+	}
+}
+
+// admit blocks until an attempt can be made, waking up whenever the state of the breaker changes
+func (cb *CircuitBreaker) admit(ctx context.Context) error {
+	for {
+		wait, changed := cb.check()
+		if wait <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return i18n.NewError(ctx, i18n.MsgContextCanceled)
+		case <-changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// check returns how long to wait before an attempt can be made - zero if the attempt can be made now
+func (cb *CircuitBreaker) check() (time.Duration, <-chan struct{}) {
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+	if cb.failures < cb.failureThreshold {
+		return 0, nil
+	}
+	if now := time.Now(); now.Before(cb.openUntil) {
+		return cb.openUntil.Sub(now), cb.changed
+	}
+	if cb.trial {
+		// Another loop is making the trial attempt, so wait for the outcome
+		return cb.resetTimeout, cb.changed
+	}
+	cb.trial = true
+	return 0, nil
+}
+
+// done records the outcome of an attempt, and wakes up any loops waiting on the breaker
+func (cb *CircuitBreaker) done(ctx context.Context, failed bool) {
+	cb.mux.Lock()
+	defer cb.mux.Unlock()
+	wasOpen := cb.failures >= cb.failureThreshold
+	cb.trial = false
+	if failed {
+		cb.failures++
+		if cb.failures >= cb.failureThreshold {
+			cb.openUntil = time.Now().Add(cb.resetTimeout)
+			if !wasOpen {
+				log.L(ctx).Warnf("Circuit breaker '%s' opened after %d consecutive failures", cb.name, cb.failures)
+				metrics.BreakerOpened(cb.name)
+			}
+		}
+	} else {
+		if wasOpen {
+			log.L(ctx).Infof("Circuit breaker '%s' closed", cb.name)
+		}
+		cb.failures = 0
+	}
+	close(cb.changed)
+	cb.changed = make(chan struct{})
+}
@@ -0,0 +1,120 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabaseBreakerShared(t *testing.T) {
+	config.Reset()
+	config.Set(config.RetryBreakerEnabled, true)
+	defer func() {
+		breakers.database = nil
+	}()
+	r1 := NewFromConfig(config.BatchRetryInitDelay, config.BatchRetryMaxDelay, config.BatchRetryFactor)
+	r2 := NewFromConfig(config.EventAggregatorRetryInitDelay, config.EventAggregatorRetryMaxDelay, config.EventAggregatorRetryFactor)
+	assert.NotNil(t, r1.Breaker)
+	assert.Equal(t, r1.Breaker, r2.Breaker)
+	assert.Equal(t, "database", r1.Breaker.name)
+}
+
+func TestBreakerOpensAndCloses(t *testing.T) {
+	cb := NewCircuitBreaker("ut", 0, 10*time.Millisecond)
+	assert.Equal(t, 1, cb.failureThreshold)
+	r := Retry{
+		MaximumDelay: 1 * time.Microsecond,
+		InitialDelay: 1 * time.Microsecond,
+		Breaker:      cb,
+	}
+	start := time.Now()
+	err := r.Do(context.Background(), "unit test", func(i int) (retry bool, err error) {
+		if i < 3 {
+			return true, fmt.Errorf("pop")
+		}
+		return false, nil
+	})
+	assert.NoError(t, err)
+	// Two failed attempts, so we waited for the reset timeout twice before the successful trial
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(20*time.Millisecond))
+	assert.Equal(t, 0, cb.failures)
+	assert.False(t, cb.trial)
+}
+
+func TestBreakerSingleTrial(t *testing.T) {
+	cb := NewCircuitBreaker("ut", 1, 1*time.Minute)
+	cb.done(context.Background(), true)
+	cb.openUntil = time.Now()
+
+	// First caller gets the trial
+	assert.NoError(t, cb.admit(context.Background()))
+	assert.True(t, cb.trial)
+
+	// Second caller waits for the outcome of the trial
+	wait, _ := cb.check()
+	assert.Equal(t, 1*time.Minute, wait)
+	admitted := make(chan error)
+	go func() {
+		admitted <- cb.admit(context.Background())
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cb.done(context.Background(), false)
+	assert.NoError(t, <-admitted)
+	assert.Equal(t, 0, cb.failures)
+}
+
+func TestBreakerWaitsForResetTimeout(t *testing.T) {
+	cb := NewCircuitBreaker("ut", 1, 5*time.Millisecond)
+	cb.done(context.Background(), true)
+	start := time.Now()
+	assert.NoError(t, cb.admit(context.Background()))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(5*time.Millisecond))
+	assert.True(t, cb.trial)
+}
+
+func TestBreakerContextCancelled(t *testing.T) {
+	cb := NewCircuitBreaker("ut", 1, 1*time.Minute)
+	r := Retry{
+		MaximumDelay: 1 * time.Microsecond,
+		InitialDelay: 1 * time.Microsecond,
+		Breaker:      cb,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	err := r.Do(ctx, "unit test", func(i int) (retry bool, err error) {
+		return true, fmt.Errorf("pop")
+	})
+	assert.Regexp(t, "FF10158", err)
+}
+
+func TestBreakerNotCountingNonRetryableErrors(t *testing.T) {
+	cb := NewCircuitBreaker("ut", 1, 1*time.Minute)
+	r := Retry{
+		Breaker: cb,
+	}
+	err := r.Do(context.Background(), "unit test", func(i int) (retry bool, err error) {
+		return false, fmt.Errorf("pop")
+	})
+	assert.Regexp(t, "pop", err)
+	assert.Equal(t, 0, cb.failures)
+}
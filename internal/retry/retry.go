@@ -18,22 +18,52 @@ package retry
 
 import (
 	"context"
+	"math/rand"
+	"sync"
 	"time"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/metrics"
 )
 
 const (
 	defaultFactor = 2.0
 )
 
-// Retry is a concurrency safe retry structure that configures a simple backoff retry mechanism
+var jitterRand = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+// Retry is a concurrency safe retry structure that configures a simple backoff retry mechanism.
+//
+// Jitter is the fraction of each delay that is randomized, so that retry loops that failed at the same time
+// (such as every dispatcher after a database blip) do not all retry at the same time. MaximumElapsed is a
+// budget for the total time spent retrying, after which the last error is returned. Retry loops calling the
+// same target can share a circuit breaker, so they stop calling a target that is down.
 type Retry struct {
-	InitialDelay time.Duration
-	MaximumDelay time.Duration
-	Factor       float64
-	ErrCallback  func(err error)
+	InitialDelay   time.Duration
+	MaximumDelay   time.Duration
+	Factor         float64
+	Jitter         float64
+	MaximumElapsed time.Duration
+	Breaker        *CircuitBreaker
+	ErrCallback    func(err error)
+}
+
+// NewFromConfig builds a retry for database operations from the delay settings of a component, along with the
+// jitter, budget and circuit breaker settings that are shared by all components
+func NewFromConfig(initDelay, maxDelay, factor config.RootKey) *Retry {
+	return &Retry{
+		InitialDelay:   config.GetDuration(initDelay),
+		MaximumDelay:   config.GetDuration(maxDelay),
+		Factor:         config.GetFloat64(factor),
+		Jitter:         config.GetFloat64(config.RetryJitter),
+		MaximumElapsed: config.GetDuration(config.RetryMaxElapsed),
+		Breaker:        databaseBreaker(),
+	}
 }
 
 // DoCustomLog disables the automatic attempt logging, so the caller should do logging for each attempt
@@ -46,14 +76,26 @@ func (r *Retry) DoCustomLog(ctx context.Context, f func(attempt int) (retry bool
 // you'll be using a closure for that.
 func (r *Retry) Do(ctx context.Context, logDescription string, f func(attempt int) (retry bool, err error)) error {
 	attempt := 0
+	startTime := time.Now()
 	delay := r.InitialDelay
 	factor := r.Factor
 	if factor < 1 { // Can't reduce
 		factor = defaultFactor
 	}
 	for {
+		if r.Breaker != nil {
+			if err := r.Breaker.admit(ctx); err != nil {
+				return err
+			}
+		}
 		attempt++
+		if attempt > 1 {
+			metrics.RetryAttempted(logDescription)
+		}
 		retry, err := f(attempt)
+		if r.Breaker != nil {
+			r.Breaker.done(ctx, retry && err != nil)
+		}
 		if err != nil && logDescription != "" {
 			log.L(ctx).Errorf("%s attempt %d: %s", logDescription, attempt, err)
 			if r.ErrCallback != nil {
@@ -84,8 +126,29 @@ func (r *Retry) Do(ctx context.Context, logDescription string, f func(attempt in
 			}
 		}
 
+		// Check the budget allows for another attempt after the delay
+		sleep := r.jitter(delay)
+		if r.MaximumElapsed > 0 && now.Add(sleep).Sub(startTime) > r.MaximumElapsed {
+			metrics.RetryExhausted(logDescription)
+			return i18n.WrapError(ctx, err, i18n.MsgRetryBudgetExhausted, r.MaximumElapsed, attempt)
+		}
+
 		// Sleep and set the delay for next time
-		time.Sleep(delay)
+		time.Sleep(sleep)
 		delay = time.Duration(float64(delay) * factor)
 	}
 }
+
+// jitter shortens the delay by a random amount, up to the jitter fraction of the delay
+func (r *Retry) jitter(delay time.Duration) time.Duration {
+	jitter := r.Jitter
+	if jitter <= 0 {
+		return delay
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	jitterRand.Lock()
+	defer jitterRand.Unlock()
+	return delay - time.Duration(jitterRand.Float64()*jitter*float64(delay))
+}
@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -65,3 +66,62 @@ func TestRetryContextCancellled(t *testing.T) {
 	})
 	assert.Regexp(t, "FF10158", err)
 }
+
+func TestRetryJitter(t *testing.T) {
+	r := Retry{
+		MaximumDelay: 100 * time.Millisecond,
+		InitialDelay: 100 * time.Millisecond,
+		Jitter:       2,
+	}
+	for i := 0; i < 10; i++ {
+		d := r.jitter(100 * time.Millisecond)
+		assert.GreaterOrEqual(t, int64(d), int64(0))
+		assert.LessOrEqual(t, int64(d), int64(100*time.Millisecond))
+	}
+	r.Jitter = 0
+	assert.Equal(t, 100*time.Millisecond, r.jitter(100*time.Millisecond))
+}
+
+func TestRetryJitterEventuallyOk(t *testing.T) {
+	r := Retry{
+		MaximumDelay: 3 * time.Microsecond,
+		InitialDelay: 1 * time.Microsecond,
+		Factor:       3,
+		Jitter:       0.5,
+	}
+	err := r.Do(context.Background(), "unit test", func(i int) (retry bool, err error) {
+		if i < 5 {
+			return true, fmt.Errorf("pop")
+		}
+		return false, nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestRetryBudgetExhausted(t *testing.T) {
+	r := Retry{
+		MaximumDelay:   1 * time.Second,
+		InitialDelay:   1 * time.Second,
+		MaximumElapsed: 10 * time.Millisecond,
+	}
+	attempts := 0
+	err := r.Do(context.Background(), "unit test", func(i int) (retry bool, err error) {
+		attempts = i
+		return true, fmt.Errorf("pop")
+	})
+	assert.Regexp(t, "FF10506.*pop", err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestNewFromConfig(t *testing.T) {
+	config.Reset()
+	config.Set(config.RetryJitter, 0.5)
+	config.Set(config.RetryMaxElapsed, "1m")
+	r := NewFromConfig(config.BatchRetryInitDelay, config.BatchRetryMaxDelay, config.BatchRetryFactor)
+	assert.Equal(t, config.GetDuration(config.BatchRetryInitDelay), r.InitialDelay)
+	assert.Equal(t, config.GetDuration(config.BatchRetryMaxDelay), r.MaximumDelay)
+	assert.Equal(t, config.GetFloat64(config.BatchRetryFactor), r.Factor)
+	assert.Equal(t, 0.5, r.Jitter)
+	assert.Equal(t, time.Minute, r.MaximumElapsed)
+	assert.Nil(t, r.Breaker)
+}
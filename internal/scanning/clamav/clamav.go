@@ -0,0 +1,119 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clamav
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/scanning"
+)
+
+// ClamAV scans blobs by streaming them to a clamd daemon, using the INSTREAM command
+type ClamAV struct {
+	ctx          context.Context
+	capabilities *scanning.Capabilities
+	network      string
+	address      string
+	timeout      time.Duration
+	chunkSize    int64
+	dial         func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+func (c *ClamAV) Name() string {
+	return "clamav"
+}
+
+func (c *ClamAV) Init(ctx context.Context, prefix config.Prefix) error {
+	c.ctx = log.WithLogField(ctx, "scanning", "clamav")
+	c.network = prefix.GetString(ClamAVConfNetwork)
+	c.address = prefix.GetString(ClamAVConfAddress)
+	if c.address == "" {
+		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, prefix.Resolve(ClamAVConfAddress), "clamav")
+	}
+	c.timeout = prefix.GetDuration(ClamAVConfTimeout)
+	c.chunkSize = prefix.GetByteSize(ClamAVConfChunkSize)
+	c.dial = (&net.Dialer{}).DialContext
+	c.capabilities = &scanning.Capabilities{}
+	return nil
+}
+
+func (c *ClamAV) Capabilities() *scanning.Capabilities {
+	return c.capabilities
+}
+
+func (c *ClamAV) ScanBlob(ctx context.Context, content io.Reader) (string, error) {
+	conn, err := c.dial(ctx, c.network, c.address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(c.timeout))
+
+	if _, err = conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return "", err
+	}
+	// Each chunk is prefixed with its length as a 4 byte unsigned integer in network byte order,
+	// and a zero length chunk marks the end of the stream
+	buff := make([]byte, 4+c.chunkSize)
+	for {
+		n, readErr := io.ReadFull(content, buff[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buff, uint32(n))
+			if _, err = conn.Write(buff[:4+n]); err != nil {
+				return "", err
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return "", readErr
+		}
+	}
+	if _, err = conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return "", err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return "", err
+	}
+	return c.parseReply(ctx, strings.TrimSuffix(reply, "\x00"))
+}
+
+// parseReply handles the responses "stream: OK", "stream: <threat> FOUND" and "<reason> ERROR"
+func (c *ClamAV) parseReply(ctx context.Context, reply string) (string, error) {
+	log.L(ctx).Debugf("clamd reply: %s", reply)
+	result := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case result == "OK":
+		return "", nil
+	case strings.HasSuffix(result, " FOUND"):
+		return strings.TrimSuffix(result, " FOUND"), nil
+	default:
+		return "", i18n.NewError(ctx, i18n.MsgScanningResponseInvalid, c.Name(), reply)
+	}
+}
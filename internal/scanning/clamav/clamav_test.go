@@ -0,0 +1,222 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clamav
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var utConfPrefix = config.NewPluginConfig("clamav_unit_tests")
+
+func resetConf() {
+	config.Reset()
+	c := &ClamAV{}
+	c.InitPrefix(utConfPrefix)
+}
+
+// fakeClamd reads an INSTREAM request from the connection, and replies with the result of the supplied function
+func fakeClamd(t *testing.T, conn net.Conn, reply func(content []byte) string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	cmd, err := r.ReadString(0)
+	assert.NoError(t, err)
+	assert.Equal(t, "zINSTREAM\x00", cmd)
+	var content bytes.Buffer
+	for {
+		lenBytes := make([]byte, 4)
+		if _, err := io.ReadFull(r, lenBytes); err != nil {
+			return
+		}
+		chunkLen := binary.BigEndian.Uint32(lenBytes)
+		if chunkLen == 0 {
+			break
+		}
+		if _, err := io.CopyN(&content, r, int64(chunkLen)); err != nil {
+			return
+		}
+	}
+	_, _ = conn.Write([]byte(reply(content.Bytes()) + "\x00"))
+}
+
+func newTestClamAV(t *testing.T, reply func(content []byte) string) (*ClamAV, func()) {
+	resetConf()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go fakeClamd(t, conn, reply)
+		}
+	}()
+	utConfPrefix.Set(ClamAVConfAddress, l.Addr().String())
+	utConfPrefix.Set(ClamAVConfChunkSize, "4")
+	c := &ClamAV{}
+	err = c.Init(context.Background(), utConfPrefix)
+	assert.NoError(t, err)
+	return c, func() { l.Close() }
+}
+
+// failConn is a connection on which writes fail after a number have succeeded
+type failConn struct {
+	net.Conn
+	writes int
+}
+
+func (f *failConn) Write(b []byte) (int, error) {
+	if f.writes == 0 {
+		return 0, fmt.Errorf("pop")
+	}
+	f.writes--
+	return len(b), nil
+}
+
+func (f *failConn) Close() error {
+	return nil
+}
+
+func TestInitMissingAddress(t *testing.T) {
+	resetConf()
+	utConfPrefix.Set(ClamAVConfAddress, "")
+	c := &ClamAV{}
+	err := c.Init(context.Background(), utConfPrefix)
+	assert.Regexp(t, "FF10138", err)
+}
+
+func TestInitOk(t *testing.T) {
+	resetConf()
+	c := &ClamAV{}
+	err := c.Init(context.Background(), utConfPrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, "clamav", c.Name())
+	assert.NotNil(t, c.Capabilities())
+	assert.Equal(t, "localhost:3310", c.address)
+}
+
+func TestScanBlobClean(t *testing.T) {
+	var received string
+	c, done := newTestClamAV(t, func(content []byte) string {
+		received = string(content)
+		return "stream: OK"
+	})
+	defer done()
+
+	threat, err := c.ScanBlob(context.Background(), strings.NewReader("some blob content"))
+	assert.NoError(t, err)
+	assert.Empty(t, threat)
+	assert.Equal(t, "some blob content", received)
+}
+
+func TestScanBlobFound(t *testing.T) {
+	c, done := newTestClamAV(t, func(content []byte) string {
+		return "stream: Eicar-Test-Signature FOUND"
+	})
+	defer done()
+
+	threat, err := c.ScanBlob(context.Background(), strings.NewReader("X5O!P%@AP"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Eicar-Test-Signature", threat)
+}
+
+func TestScanBlobError(t *testing.T) {
+	c, done := newTestClamAV(t, func(content []byte) string {
+		return "INSTREAM size limit exceeded. ERROR"
+	})
+	defer done()
+
+	_, err := c.ScanBlob(context.Background(), strings.NewReader("some blob content"))
+	assert.Regexp(t, "FF10539.*size limit", err)
+}
+
+func TestScanBlobNoReply(t *testing.T) {
+	resetConf()
+	c := &ClamAV{}
+	err := c.Init(context.Background(), utConfPrefix)
+	assert.NoError(t, err)
+	client, _ := net.Pipe()
+	c.dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return &noReplyConn{failConn{Conn: client, writes: 3}}, nil
+	}
+
+	_, err = c.ScanBlob(context.Background(), strings.NewReader("some blob content"))
+	assert.Error(t, err)
+}
+
+// noReplyConn accepts the request, then returns EOF on read, as if the server closed the connection without replying
+type noReplyConn struct {
+	failConn
+}
+
+func (c *noReplyConn) Read(b []byte) (int, error) {
+	return 0, io.EOF
+}
+
+func TestScanBlobDialFail(t *testing.T) {
+	resetConf()
+	c := &ClamAV{}
+	err := c.Init(context.Background(), utConfPrefix)
+	assert.NoError(t, err)
+	c.dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return nil, fmt.Errorf("pop")
+	}
+
+	_, err = c.ScanBlob(context.Background(), strings.NewReader("some blob content"))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestScanBlobReadFail(t *testing.T) {
+	c, done := newTestClamAV(t, func(content []byte) string {
+		return "stream: OK"
+	})
+	defer done()
+
+	_, err := c.ScanBlob(context.Background(), iotest.ErrReader(fmt.Errorf("pop")))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestScanBlobWriteFail(t *testing.T) {
+	resetConf()
+	utConfPrefix.Set(ClamAVConfChunkSize, "4")
+	c := &ClamAV{}
+	err := c.Init(context.Background(), utConfPrefix)
+	assert.NoError(t, err)
+
+	for writes := 0; writes < 3; writes++ {
+		client, _ := net.Pipe()
+		fc := &failConn{Conn: client, writes: writes}
+		c.dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return fc, nil
+		}
+		// The content is a single chunk, so the writes are: command, chunk, terminator
+		_, err = c.ScanBlob(context.Background(), strings.NewReader("1234"))
+		assert.Regexp(t, "pop", err)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clamav
+
+import (
+	"github.com/hyperledger/firefly/internal/config"
+)
+
+const (
+	// ClamAVConfNetwork is the network used to connect to clamd - "tcp" or "unix"
+	ClamAVConfNetwork = "network"
+	// ClamAVConfAddress is the host:port, or the path of the unix socket, clamd is listening on
+	ClamAVConfAddress = "address"
+	// ClamAVConfTimeout is the maximum time to wait for clamd to scan a blob
+	ClamAVConfTimeout = "timeout"
+	// ClamAVConfChunkSize is the size of each chunk streamed to clamd
+	ClamAVConfChunkSize = "chunkSize"
+)
+
+func (c *ClamAV) InitPrefix(prefix config.Prefix) {
+	prefix.AddKnownKey(ClamAVConfNetwork, "tcp")
+	prefix.AddKnownKey(ClamAVConfAddress, "localhost:3310")
+	prefix.AddKnownKey(ClamAVConfTimeout, "2m")
+	prefix.AddKnownKey(ClamAVConfChunkSize, "64Kb")
+}
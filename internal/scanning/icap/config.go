@@ -0,0 +1,36 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icap
+
+import (
+	"github.com/hyperledger/firefly/internal/config"
+)
+
+const (
+	// ICAPConfURL is the icap:// URL of the scanning service, such as icap://localhost:1344/avscan
+	ICAPConfURL = "url"
+	// ICAPConfTimeout is the maximum time to wait for the ICAP server to scan a blob
+	ICAPConfTimeout = "timeout"
+	// ICAPConfChunkSize is the size of each chunk of the encapsulated body streamed to the ICAP server
+	ICAPConfChunkSize = "chunkSize"
+)
+
+func (i *ICAP) InitPrefix(prefix config.Prefix) {
+	prefix.AddKnownKey(ICAPConfURL)
+	prefix.AddKnownKey(ICAPConfTimeout, "2m")
+	prefix.AddKnownKey(ICAPConfChunkSize, "64Kb")
+}
@@ -0,0 +1,170 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icap
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/scanning"
+)
+
+const defaultICAPPort = "1344"
+
+// encapsulatedResponseHeader is the HTTP response the blob is wrapped in, for a RESPMOD request
+const encapsulatedResponseHeader = "HTTP/1.1 200 OK\r\nContent-Type: application/octet-stream\r\n\r\n"
+
+// ICAP scans blobs by sending them to an ICAP server (RFC 3507) as the body of a RESPMOD request
+type ICAP struct {
+	ctx          context.Context
+	capabilities *scanning.Capabilities
+	serviceURL   *url.URL
+	timeout      time.Duration
+	chunkSize    int64
+	dial         func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+func (i *ICAP) Name() string {
+	return "icap"
+}
+
+func (i *ICAP) Init(ctx context.Context, prefix config.Prefix) (err error) {
+	i.ctx = log.WithLogField(ctx, "scanning", "icap")
+	serviceURL := prefix.GetString(ICAPConfURL)
+	if serviceURL == "" {
+		return i18n.NewError(ctx, i18n.MsgMissingPluginConfig, prefix.Resolve(ICAPConfURL), "icap")
+	}
+	i.serviceURL, err = url.Parse(serviceURL)
+	if err != nil || i.serviceURL.Scheme != "icap" || i.serviceURL.Hostname() == "" {
+		return i18n.NewError(ctx, i18n.MsgInvalidURL, serviceURL)
+	}
+	i.timeout = prefix.GetDuration(ICAPConfTimeout)
+	i.chunkSize = prefix.GetByteSize(ICAPConfChunkSize)
+	i.dial = (&net.Dialer{}).DialContext
+	i.capabilities = &scanning.Capabilities{}
+	return nil
+}
+
+func (i *ICAP) Capabilities() *scanning.Capabilities {
+	return i.capabilities
+}
+
+func (i *ICAP) address() string {
+	port := i.serviceURL.Port()
+	if port == "" {
+		port = defaultICAPPort
+	}
+	return net.JoinHostPort(i.serviceURL.Hostname(), port)
+}
+
+func (i *ICAP) ScanBlob(ctx context.Context, content io.Reader) (string, error) {
+	conn, err := i.dial(ctx, "tcp", i.address())
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(i.timeout))
+
+	// We allow a 204 response, so the server does not need to echo back the content when it is clean
+	header := fmt.Sprintf("RESPMOD %s ICAP/1.0\r\nHost: %s\r\nAllow: 204\r\nEncapsulated: res-hdr=0, res-body=%d\r\n\r\n%s",
+		i.serviceURL, i.serviceURL.Host, len(encapsulatedResponseHeader), encapsulatedResponseHeader)
+	if _, err = conn.Write([]byte(header)); err != nil {
+		return "", err
+	}
+
+	// The body is sent with HTTP chunked transfer encoding, ending with a zero length chunk
+	buff := make([]byte, i.chunkSize)
+	for {
+		n, readErr := io.ReadFull(content, buff)
+		if n > 0 {
+			chunk := append([]byte(fmt.Sprintf("%x\r\n", n)), buff[:n]...)
+			if _, err = conn.Write(append(chunk, "\r\n"...)); err != nil {
+				return "", err
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return "", readErr
+		}
+	}
+	if _, err = conn.Write([]byte("0\r\n\r\n")); err != nil {
+		return "", err
+	}
+
+	return i.readResponse(ctx, textproto.NewReader(bufio.NewReader(conn)))
+}
+
+// readResponse interprets the status and headers of the ICAP response. A 204 means the content was not modified,
+// so is clean. A 200 means the server has replaced the content, which it does when a threat was found - the name
+// of the threat is reported in one of the de-facto standard headers used by ICAP anti-virus servers.
+func (i *ICAP) readResponse(ctx context.Context, r *textproto.Reader) (string, error) {
+	status, err := r.ReadLine()
+	if err != nil {
+		return "", err
+	}
+	headers, err := r.ReadMIMEHeader()
+	if err != nil {
+		return "", err
+	}
+	log.L(ctx).Debugf("ICAP response: %s", status)
+
+	parts := strings.SplitN(status, " ", 3)
+	if len(parts) < 2 || !strings.HasPrefix(parts[0], "ICAP/") {
+		return "", i18n.NewError(ctx, i18n.MsgScanningResponseInvalid, i.Name(), status)
+	}
+	switch parts[1] {
+	case "204":
+		return "", nil
+	case "200":
+		return threatFromHeaders(headers), nil
+	default:
+		return "", i18n.NewError(ctx, i18n.MsgScanningResponseInvalid, i.Name(), status)
+	}
+}
+
+func threatFromHeaders(headers textproto.MIMEHeader) string {
+	if virusID := headers.Get("X-Virus-Id"); virusID != "" {
+		return virusID
+	}
+	// X-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;
+	if infection := headers.Get("X-Infection-Found"); infection != "" {
+		for _, field := range strings.Split(infection, ";") {
+			if kv := strings.SplitN(strings.TrimSpace(field), "=", 2); len(kv) == 2 && kv[0] == "Threat" {
+				return kv[1]
+			}
+		}
+		return infection
+	}
+	if violation := headers.Get("X-Violations-Found"); violation != "" {
+		return violation
+	}
+	// A modified response without any threat headers means the server did not honor the 204,
+	// and echoed back the unmodified content
+	return ""
+}
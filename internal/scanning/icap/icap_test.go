@@ -0,0 +1,258 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package icap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httputil"
+	"net/textproto"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var utConfPrefix = config.NewPluginConfig("icap_unit_tests")
+
+func resetConf() {
+	config.Reset()
+	i := &ICAP{}
+	i.InitPrefix(utConfPrefix)
+}
+
+// fakeICAPServer reads a RESPMOD request from the connection, and replies with the result of the supplied function
+func fakeICAPServer(t *testing.T, conn net.Conn, reply func(requestLine string, content []byte) string) {
+	defer conn.Close()
+	r := textproto.NewReader(bufio.NewReader(conn))
+	requestLine, err := r.ReadLine()
+	assert.NoError(t, err)
+	icapHeaders, err := r.ReadMIMEHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, "204", icapHeaders.Get("Allow"))
+	statusLine, err := r.ReadLine() // the encapsulated HTTP response header
+	assert.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1 200 OK", statusLine)
+	_, err = r.ReadMIMEHeader()
+	assert.NoError(t, err)
+	var content bytes.Buffer
+	_, err = io.Copy(&content, httputil.NewChunkedReader(r.R))
+	assert.NoError(t, err)
+	_, _ = conn.Write([]byte(reply(requestLine, content.Bytes())))
+}
+
+func newTestICAP(t *testing.T, reply func(requestLine string, content []byte) string) (*ICAP, func()) {
+	resetConf()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go fakeICAPServer(t, conn, reply)
+		}
+	}()
+	utConfPrefix.Set(ICAPConfURL, fmt.Sprintf("icap://%s/avscan", l.Addr()))
+	utConfPrefix.Set(ICAPConfChunkSize, "4")
+	i := &ICAP{}
+	err = i.Init(context.Background(), utConfPrefix)
+	assert.NoError(t, err)
+	return i, func() { l.Close() }
+}
+
+// failConn is a connection on which writes fail after a number have succeeded
+type failConn struct {
+	net.Conn
+	writes int
+}
+
+func (f *failConn) Write(b []byte) (int, error) {
+	if f.writes == 0 {
+		return 0, fmt.Errorf("pop")
+	}
+	f.writes--
+	return len(b), nil
+}
+
+func (f *failConn) Close() error {
+	return nil
+}
+
+// replyConn accepts the request, then returns the supplied reply
+type replyConn struct {
+	failConn
+	reply io.Reader
+}
+
+func (c *replyConn) Read(b []byte) (int, error) {
+	return c.reply.Read(b)
+}
+
+func newTestICAPReply(t *testing.T, reply string) *ICAP {
+	resetConf()
+	utConfPrefix.Set(ICAPConfURL, "icap://localhost/avscan")
+	i := &ICAP{}
+	err := i.Init(context.Background(), utConfPrefix)
+	assert.NoError(t, err)
+	client, _ := net.Pipe()
+	i.dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return &replyConn{failConn: failConn{Conn: client, writes: 10}, reply: strings.NewReader(reply)}, nil
+	}
+	return i
+}
+
+func TestInitMissingURL(t *testing.T) {
+	resetConf()
+	i := &ICAP{}
+	err := i.Init(context.Background(), utConfPrefix)
+	assert.Regexp(t, "FF10138", err)
+}
+
+func TestInitBadURL(t *testing.T) {
+	resetConf()
+	utConfPrefix.Set(ICAPConfURL, "http://localhost/avscan")
+	i := &ICAP{}
+	err := i.Init(context.Background(), utConfPrefix)
+	assert.Regexp(t, "FF10162", err)
+}
+
+func TestInitDefaultPort(t *testing.T) {
+	resetConf()
+	utConfPrefix.Set(ICAPConfURL, "icap://localhost/avscan")
+	i := &ICAP{}
+	err := i.Init(context.Background(), utConfPrefix)
+	assert.NoError(t, err)
+	assert.Equal(t, "icap", i.Name())
+	assert.NotNil(t, i.Capabilities())
+	assert.Equal(t, "localhost:1344", i.address())
+}
+
+func TestScanBlobClean(t *testing.T) {
+	var received, request string
+	i, done := newTestICAP(t, func(requestLine string, content []byte) string {
+		request = requestLine
+		received = string(content)
+		return "ICAP/1.0 204 No Content\r\nISTag: \"1\"\r\n\r\n"
+	})
+	defer done()
+
+	threat, err := i.ScanBlob(context.Background(), strings.NewReader("some blob content"))
+	assert.NoError(t, err)
+	assert.Empty(t, threat)
+	assert.Equal(t, "some blob content", received)
+	assert.Regexp(t, "^RESPMOD icap://.*/avscan ICAP/1.0$", request)
+}
+
+func TestScanBlobVirusID(t *testing.T) {
+	i, done := newTestICAP(t, func(requestLine string, content []byte) string {
+		return "ICAP/1.0 200 OK\r\nX-Virus-ID: Eicar-Test-Signature\r\n\r\n"
+	})
+	defer done()
+
+	threat, err := i.ScanBlob(context.Background(), strings.NewReader("X5O!P%@AP"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Eicar-Test-Signature", threat)
+}
+
+func TestScanBlobInfectionFound(t *testing.T) {
+	i := newTestICAPReply(t, "ICAP/1.0 200 OK\r\nX-Infection-Found: Type=0; Resolution=2; Threat=Eicar-Test-Signature;\r\n\r\n")
+	threat, err := i.ScanBlob(context.Background(), strings.NewReader("X5O!P%@AP"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Eicar-Test-Signature", threat)
+}
+
+func TestScanBlobInfectionFoundNoThreat(t *testing.T) {
+	i := newTestICAPReply(t, "ICAP/1.0 200 OK\r\nX-Infection-Found: Type=0; Resolution=2\r\n\r\n")
+	threat, err := i.ScanBlob(context.Background(), strings.NewReader("X5O!P%@AP"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Type=0; Resolution=2", threat)
+}
+
+func TestScanBlobViolationsFound(t *testing.T) {
+	i := newTestICAPReply(t, "ICAP/1.0 200 OK\r\nX-Violations-Found: 1\r\n\r\n")
+	threat, err := i.ScanBlob(context.Background(), strings.NewReader("X5O!P%@AP"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", threat)
+}
+
+func TestScanBlobModifiedNoThreat(t *testing.T) {
+	i := newTestICAPReply(t, "ICAP/1.0 200 OK\r\nEncapsulated: res-hdr=0, res-body=64\r\n\r\n")
+	threat, err := i.ScanBlob(context.Background(), strings.NewReader("some blob content"))
+	assert.NoError(t, err)
+	assert.Empty(t, threat)
+}
+
+func TestScanBlobServerError(t *testing.T) {
+	i := newTestICAPReply(t, "ICAP/1.0 500 Server Error\r\n\r\n")
+	_, err := i.ScanBlob(context.Background(), strings.NewReader("some blob content"))
+	assert.Regexp(t, "FF10539.*500", err)
+}
+
+func TestScanBlobBadStatus(t *testing.T) {
+	i := newTestICAPReply(t, "HTTP/1.1 200 OK\r\n\r\n")
+	_, err := i.ScanBlob(context.Background(), strings.NewReader("some blob content"))
+	assert.Regexp(t, "FF10539", err)
+}
+
+func TestScanBlobNoReply(t *testing.T) {
+	i := newTestICAPReply(t, "")
+	_, err := i.ScanBlob(context.Background(), strings.NewReader("some blob content"))
+	assert.Error(t, err)
+}
+
+func TestScanBlobBadHeaders(t *testing.T) {
+	i := newTestICAPReply(t, "ICAP/1.0 204 No Content\r\n bad continuation\r\n")
+	_, err := i.ScanBlob(context.Background(), strings.NewReader("some blob content"))
+	assert.Error(t, err)
+}
+
+func TestScanBlobDialFail(t *testing.T) {
+	i := newTestICAPReply(t, "")
+	i.dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return nil, fmt.Errorf("pop")
+	}
+	_, err := i.ScanBlob(context.Background(), strings.NewReader("some blob content"))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestScanBlobReadFail(t *testing.T) {
+	i := newTestICAPReply(t, "")
+	_, err := i.ScanBlob(context.Background(), iotest.ErrReader(fmt.Errorf("pop")))
+	assert.Regexp(t, "pop", err)
+}
+
+func TestScanBlobWriteFail(t *testing.T) {
+	i := newTestICAPReply(t, "")
+	client, _ := net.Pipe()
+	for writes := 0; writes < 3; writes++ {
+		fc := &failConn{Conn: client, writes: writes}
+		i.dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+			return fc, nil
+		}
+		// The content is a single chunk, so the writes are: header, chunk, terminator
+		_, err := i.ScanBlob(context.Background(), strings.NewReader("1234"))
+		assert.Regexp(t, "pop", err)
+	}
+}
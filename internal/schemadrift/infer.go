@@ -0,0 +1,209 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemadrift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+const (
+	typeNull    = "null"
+	typeBoolean = "boolean"
+	typeInteger = "integer"
+	typeNumber  = "number"
+	typeString  = "string"
+	typeArray   = "array"
+	typeObject  = "object"
+)
+
+// payloadSchema infers a single schema that covers the JSON values of all the data in a message, and
+// returns the first datatype declared by the data. The schema is nil if no data has a value.
+func payloadSchema(data fftypes.DataArray) (schema *fftypes.InferredSchema, datatype *fftypes.DatatypeRef) {
+	for _, d := range data {
+		if datatype == nil {
+			datatype = d.Datatype
+		}
+		if d.Value.IsNil() {
+			continue
+		}
+		var v interface{}
+		decoder := json.NewDecoder(bytes.NewReader(d.Value.Bytes()))
+		decoder.UseNumber()
+		if err := decoder.Decode(&v); err != nil {
+			continue
+		}
+		schema = mergeSchema(schema, inferSchema(v))
+	}
+	return schema, datatype
+}
+
+// inferSchema returns the schema of a single JSON value, decoded with numbers preserved
+func inferSchema(v interface{}) *fftypes.InferredSchema {
+	switch tv := v.(type) {
+	case nil:
+		return &fftypes.InferredSchema{Type: []string{typeNull}}
+	case bool:
+		return &fftypes.InferredSchema{Type: []string{typeBoolean}}
+	case json.Number:
+		if strings.ContainsAny(tv.String(), ".eE") {
+			return &fftypes.InferredSchema{Type: []string{typeNumber}}
+		}
+		return &fftypes.InferredSchema{Type: []string{typeInteger}}
+	case string:
+		return &fftypes.InferredSchema{Type: []string{typeString}}
+	case []interface{}:
+		s := &fftypes.InferredSchema{Type: []string{typeArray}}
+		for _, item := range tv {
+			s.Items = mergeSchema(s.Items, inferSchema(item))
+		}
+		return s
+	default:
+		obj := v.(map[string]interface{})
+		s := &fftypes.InferredSchema{
+			Type:       []string{typeObject},
+			Properties: make(map[string]*fftypes.InferredSchema, len(obj)),
+			Required:   make([]string, 0, len(obj)),
+		}
+		for name, pv := range obj {
+			s.Properties[name] = inferSchema(pv)
+			s.Required = append(s.Required, name)
+		}
+		sort.Strings(s.Required)
+		return s
+	}
+}
+
+func hasType(s *fftypes.InferredSchema, t string) bool {
+	for _, st := range s.Type {
+		if st == t {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsType checks whether a value of the type is valid against the schema, where any integer is a valid number
+func allowsType(s *fftypes.InferredSchema, t string) bool {
+	return hasType(s, t) || (t == typeInteger && hasType(s, typeNumber))
+}
+
+func mergeTypes(a, b *fftypes.InferredSchema) []string {
+	types := make([]string, 0, len(a.Type)+len(b.Type))
+	seen := make(map[string]bool)
+	for _, t := range append(append([]string{}, a.Type...), b.Type...) {
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	if seen[typeNumber] && seen[typeInteger] {
+		for i, t := range types {
+			if t == typeInteger {
+				types = append(types[:i], types[i+1:]...)
+				break
+			}
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// mergeSchema returns a new schema that both the schemas are valid against. Properties are only required
+// if they are required by both schemas. Neither of the schemas is modified, so they can be safely shared.
+func mergeSchema(a, b *fftypes.InferredSchema) *fftypes.InferredSchema {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	merged := &fftypes.InferredSchema{
+		Type:  mergeTypes(a, b),
+		Items: mergeSchema(a.Items, b.Items),
+	}
+	aObject, bObject := hasType(a, typeObject), hasType(b, typeObject)
+	if aObject || bObject {
+		merged.Properties = make(map[string]*fftypes.InferredSchema)
+		for name, ps := range a.Properties {
+			merged.Properties[name] = ps
+		}
+		for name, ps := range b.Properties {
+			merged.Properties[name] = mergeSchema(merged.Properties[name], ps)
+		}
+	}
+	switch {
+	case aObject && bObject:
+		for _, name := range a.Required {
+			if containsString(b.Required, name) {
+				merged.Required = append(merged.Required, name)
+			}
+		}
+	case aObject:
+		merged.Required = a.Required
+	case bObject:
+		merged.Required = b.Required
+	}
+	return merged
+}
+
+func containsString(list []string, s string) bool {
+	for _, ls := range list {
+		if ls == s {
+			return true
+		}
+	}
+	return false
+}
+
+// diffSchema describes each way the observed schema of a payload differs from the known schema, using a
+// JSONPath style reference to the location of each difference
+func diffSchema(path string, known, observed *fftypes.InferredSchema) (diffs []string) {
+	for _, t := range observed.Type {
+		if !allowsType(known, t) {
+			diffs = append(diffs, fmt.Sprintf("%s: type '%s' does not match expected type '%s'", path, t, strings.Join(known.Type, "|")))
+		}
+	}
+	if hasType(known, typeObject) && hasType(observed, typeObject) {
+		names := make([]string, 0, len(observed.Properties))
+		for name := range observed.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if ks, ok := known.Properties[name]; ok {
+				diffs = append(diffs, diffSchema(path+"."+name, ks, observed.Properties[name])...)
+			} else {
+				diffs = append(diffs, fmt.Sprintf("%s.%s: new property", path, name))
+			}
+		}
+		for _, name := range known.Required {
+			if _, ok := observed.Properties[name]; !ok {
+				diffs = append(diffs, fmt.Sprintf("%s.%s: required property is missing", path, name))
+			}
+		}
+	}
+	if known.Items != nil && observed.Items != nil {
+		diffs = append(diffs, diffSchema(path+"[]", known.Items, observed.Items)...)
+	}
+	return diffs
+}
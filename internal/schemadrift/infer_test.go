@@ -0,0 +1,105 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemadrift
+
+import (
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSchema(values ...string) *fftypes.InferredSchema {
+	data := make(fftypes.DataArray, len(values))
+	for i, v := range values {
+		data[i] = &fftypes.Data{Value: fftypes.JSONAnyPtr(v)}
+	}
+	schema, _ := payloadSchema(data)
+	return schema
+}
+
+func TestInferSchema(t *testing.T) {
+	schema := testSchema(`{"id":1,"amount":1.5,"name":"a","ok":true,"note":null,"tags":["x","y"],"lines":[{"qty":1},{"qty":2.5,"sku":"b"}],"none":[]}`)
+	assert.Equal(t, []string{"object"}, schema.Type)
+	assert.Equal(t, []string{"amount", "id", "lines", "name", "none", "note", "ok", "tags"}, schema.Required)
+	assert.Equal(t, []string{"integer"}, schema.Properties["id"].Type)
+	assert.Equal(t, []string{"number"}, schema.Properties["amount"].Type)
+	assert.Equal(t, []string{"string"}, schema.Properties["name"].Type)
+	assert.Equal(t, []string{"boolean"}, schema.Properties["ok"].Type)
+	assert.Equal(t, []string{"null"}, schema.Properties["note"].Type)
+	assert.Equal(t, []string{"string"}, schema.Properties["tags"].Items.Type)
+	assert.Nil(t, schema.Properties["none"].Items)
+	lines := schema.Properties["lines"].Items
+	assert.Equal(t, []string{"number"}, lines.Properties["qty"].Type)
+	assert.Equal(t, []string{"qty"}, lines.Required)
+	assert.Equal(t, []string{"string"}, lines.Properties["sku"].Type)
+}
+
+func TestPayloadSchemaSkipsBlobsAndBadJSON(t *testing.T) {
+	datatype := &fftypes.DatatypeRef{Name: "widget", Version: "1"}
+	schema, dt := payloadSchema(fftypes.DataArray{
+		{Blob: &fftypes.BlobRef{Hash: fftypes.NewRandB32()}},
+		{Value: fftypes.JSONAnyPtr("!json"), Datatype: datatype},
+		{Value: fftypes.JSONAnyPtr(`"value"`)},
+	})
+	assert.Equal(t, datatype, dt)
+	assert.Equal(t, []string{"string"}, schema.Type)
+
+	schema, dt = payloadSchema(fftypes.DataArray{{Blob: &fftypes.BlobRef{Hash: fftypes.NewRandB32()}}})
+	assert.Nil(t, schema)
+	assert.Nil(t, dt)
+}
+
+func TestMergeSchema(t *testing.T) {
+	schema := testSchema(`{"a":1,"b":"x"}`, `{"a":"y","c":[1]}`, `"text"`, `2.5`)
+	assert.Equal(t, []string{"number", "object", "string"}, schema.Type)
+	assert.Equal(t, []string{"a"}, schema.Required)
+	assert.Equal(t, []string{"integer", "string"}, schema.Properties["a"].Type)
+	assert.Equal(t, []string{"integer"}, schema.Properties["c"].Items.Type)
+
+	schema = testSchema(`"text"`, `{"a":1}`)
+	assert.Equal(t, []string{"a"}, schema.Required)
+	schema = testSchema(`{"a":1}`, `"text"`)
+	assert.Equal(t, []string{"a"}, schema.Required)
+	schema = testSchema(`1.5`, `1`)
+	assert.Equal(t, []string{"number"}, schema.Type)
+}
+
+func TestMergeSchemaDoesNotModifyInputs(t *testing.T) {
+	a := testSchema(`{"a":{"b":1}}`)
+	b := testSchema(`{"a":{"b":"x"}}`)
+	merged := mergeSchema(a, b)
+	assert.Equal(t, []string{"integer", "string"}, merged.Properties["a"].Properties["b"].Type)
+	assert.Equal(t, []string{"integer"}, a.Properties["a"].Properties["b"].Type)
+	assert.Equal(t, []string{"string"}, b.Properties["a"].Properties["b"].Type)
+}
+
+func TestDiffSchema(t *testing.T) {
+	known := testSchema(`{"id":1,"amount":1.5,"lines":[{"sku":"a"}],"note":"x"}`, `{"id":2,"amount":2,"lines":[]}`)
+	assert.Empty(t, diffSchema("$", known, testSchema(`{"id":3,"amount":3,"lines":[{"sku":"b"}]}`)))
+
+	diffs := diffSchema("$", known, testSchema(`{"id":"4","lines":[{"sku":1}],"extra":true}`))
+	assert.Equal(t, []string{
+		"$.extra: new property",
+		"$.id: type 'string' does not match expected type 'integer'",
+		"$.lines[].sku: type 'integer' does not match expected type 'string'",
+		"$.amount: required property is missing",
+	}, diffs)
+
+	diffs = diffSchema("$", known, testSchema(`[1]`))
+	assert.Equal(t, []string{"$: type 'array' does not match expected type 'object'"}, diffs)
+}
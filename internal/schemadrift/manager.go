@@ -0,0 +1,228 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemadrift
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/data"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Manager infers the schema of the payloads on each topic from the messages confirmed over time, and emits
+// an event when a message drifts from the schema - such as a new property, a missing property, or a change
+// of type - so that changes made by upstream integrations are detected before they break consumers.
+// Schemas are inferred in memory, from the messages confirmed after the node started.
+type Manager interface {
+	GetTopicSchemas(ctx context.Context, ns string) []*fftypes.TopicSchema
+	GetTopicSchema(ctx context.Context, ns, topic string) *fftypes.TopicSchema
+	WaitStop()
+}
+
+type topicKey struct {
+	namespace string
+	topic     string
+}
+
+type schemaDriftManager struct {
+	ctx          context.Context
+	database     database.Plugin
+	data         data.Manager
+	interval     time.Duration
+	batchSize    int
+	minSamples   int64
+	maxTopics    int
+	started      bool
+	lastEventSeq int64
+	mux          sync.Mutex
+	topics       map[topicKey]*fftypes.TopicSchema
+	done         chan struct{}
+}
+
+func NewSchemaDriftManager(ctx context.Context, di database.Plugin, dm data.Manager) (Manager, error) {
+	if di == nil || dm == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	sm := &schemaDriftManager{
+		ctx:        log.WithLogger(ctx, log.L(ctx).WithField("role", "schema-drift")),
+		database:   di,
+		data:       dm,
+		interval:   config.GetDuration(config.SchemaDriftInterval),
+		batchSize:  config.GetInt(config.SchemaDriftBatchSize),
+		minSamples: config.GetInt64(config.SchemaDriftMinSamples),
+		maxTopics:  config.GetInt(config.SchemaDriftMaxTopics),
+		topics:     make(map[topicKey]*fftypes.TopicSchema),
+		done:       make(chan struct{}),
+	}
+	if config.GetBool(config.SchemaDriftEnabled) {
+		log.L(ctx).Infof("Schema drift analysis enabled, reporting drift after %d messages on each topic", sm.minSamples)
+		go sm.analysisLoop()
+	} else {
+		close(sm.done)
+	}
+	return sm, nil
+}
+
+// GetTopicSchemas returns the schemas inferred for the topics of a namespace, sorted by topic
+func (sm *schemaDriftManager) GetTopicSchemas(ctx context.Context, ns string) []*fftypes.TopicSchema {
+	sm.mux.Lock()
+	defer sm.mux.Unlock()
+	schemas := make([]*fftypes.TopicSchema, 0)
+	for key, ts := range sm.topics {
+		if key.namespace == ns {
+			schemas = append(schemas, ts)
+		}
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].Topic < schemas[j].Topic })
+	return schemas
+}
+
+func (sm *schemaDriftManager) GetTopicSchema(ctx context.Context, ns, topic string) *fftypes.TopicSchema {
+	sm.mux.Lock()
+	defer sm.mux.Unlock()
+	return sm.topics[topicKey{namespace: ns, topic: topic}]
+}
+
+func (sm *schemaDriftManager) WaitStop() {
+	<-sm.done
+}
+
+func (sm *schemaDriftManager) analysisLoop() {
+	defer close(sm.done)
+	ticker := time.NewTicker(sm.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sm.ctx.Done():
+			log.L(sm.ctx).Debugf("Schema drift loop exiting")
+			return
+		case <-ticker.C:
+		}
+		if err := sm.analyzeNewMessages(sm.ctx); err != nil {
+			log.L(sm.ctx).Errorf("Schema drift analysis failed: %s", err)
+		}
+	}
+}
+
+// analyzeNewMessages analyzes the next page of messages confirmed since the last pass. Analysis starts from
+// the newest event when the node starts, and a message that fails to be analyzed is retried on the next pass.
+func (sm *schemaDriftManager) analyzeNewMessages(ctx context.Context) error {
+	if !sm.started {
+		f := database.EventQueryFactory.NewFilter(ctx).And().Sort("sequence").Descending().Limit(1)
+		newestEvents, _, err := sm.database.GetEvents(ctx, f)
+		if err != nil {
+			return err
+		}
+		sm.lastEventSeq = -1
+		if len(newestEvents) > 0 {
+			sm.lastEventSeq = newestEvents[0].Sequence
+		}
+		sm.started = true
+	}
+
+	fb := database.EventQueryFactory.NewFilterLimit(ctx, uint64(sm.batchSize))
+	events, _, err := sm.database.GetEvents(ctx, fb.And(
+		fb.Eq("type", fftypes.EventTypeMessageConfirmed),
+		fb.Gt("sequence", sm.lastEventSeq),
+	).Sort("sequence"))
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if err := sm.analyzeMessage(ctx, event); err != nil {
+			return err
+		}
+		sm.lastEventSeq = event.Sequence
+	}
+	return nil
+}
+
+// analyzeMessage compares the payload of a confirmed message with the schema of the topic of the event, reporting
+// any drift once enough messages have been seen on the topic. The schema is then widened to include the message,
+// so the same drift is not reported again. There is one confirmed event for each topic of a message.
+func (sm *schemaDriftManager) analyzeMessage(ctx context.Context, event *fftypes.Event) error {
+	if event.Namespace == fftypes.SystemNamespace || event.Topic == "" {
+		return nil
+	}
+	msg, data, _, err := sm.data.GetMessageWithDataCached(ctx, event.Reference)
+	if err != nil {
+		return err
+	}
+	if msg == nil || msg.Header.Type == fftypes.MessageTypeDefinition || msg.Header.Type == fftypes.MessageTypeGroupInit {
+		return nil
+	}
+	observed, datatype := payloadSchema(data)
+	if observed == nil {
+		return nil
+	}
+
+	key := topicKey{namespace: event.Namespace, topic: event.Topic}
+	sm.mux.Lock()
+	ts := sm.topics[key]
+	full := ts == nil && len(sm.topics) >= sm.maxTopics
+	sm.mux.Unlock()
+	if full {
+		log.L(ctx).Debugf("Schema drift not analyzed for topic '%s' in namespace '%s', as the maximum of %d topics is reached", key.topic, key.namespace, sm.maxTopics)
+		return nil
+	}
+
+	now := fftypes.Now()
+	updated := &fftypes.TopicSchema{
+		Namespace: key.namespace,
+		Topic:     key.topic,
+		Created:   now,
+	}
+	if ts != nil {
+		*updated = *ts
+		if ts.Samples >= sm.minSamples {
+			var diffs []string
+			if ts.Datatype.String() != datatype.String() {
+				diffs = append(diffs, fmt.Sprintf("datatype '%s' does not match expected datatype '%s'", datatype, ts.Datatype))
+			}
+			diffs = append(diffs, diffSchema("$", ts.Schema, observed)...)
+			if len(diffs) > 0 {
+				log.L(ctx).Infof("Message '%s' on topic '%s' drifted from the inferred schema: %v", msg.Header.ID, key.topic, diffs)
+				if err := sm.database.InsertEvent(ctx, fftypes.NewEvent(fftypes.EventTypeMessageSchemaDrift, key.namespace, msg.Header.ID, nil, key.topic)); err != nil {
+					return err
+				}
+				updated.Drifts++
+				updated.LastDrift = &fftypes.SchemaDrift{
+					Message:     msg.Header.ID,
+					Differences: diffs,
+					Detected:    now,
+				}
+			}
+		}
+	}
+	updated.Datatype = datatype
+	updated.Schema = mergeSchema(updated.Schema, observed)
+	updated.Samples++
+	updated.Updated = now
+
+	sm.mux.Lock()
+	sm.topics[key] = updated
+	sm.mux.Unlock()
+	return nil
+}
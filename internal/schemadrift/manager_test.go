@@ -0,0 +1,265 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemadrift
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestSchemaDrift(t *testing.T) (*schemaDriftManager, *databasemocks.Plugin, *datamocks.Manager, func()) {
+	config.Reset()
+	config.Set(config.SchemaDriftMinSamples, 2)
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	sm, err := NewSchemaDriftManager(context.Background(), mdi, mdm)
+	assert.NoError(t, err)
+	return sm.(*schemaDriftManager), mdi, mdm, func() {
+		mdi.AssertExpectations(t)
+		mdm.AssertExpectations(t)
+	}
+}
+
+func newTestMessage(mdm *datamocks.Manager, msgType fftypes.MessageType, datatype *fftypes.DatatypeRef, values ...string) *fftypes.Event {
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{
+			ID:   fftypes.NewUUID(),
+			Type: msgType,
+		},
+	}
+	data := make(fftypes.DataArray, len(values))
+	for i, v := range values {
+		data[i] = &fftypes.Data{Value: fftypes.JSONAnyPtr(v), Datatype: datatype}
+	}
+	mdm.On("GetMessageWithDataCached", mock.Anything, msg.Header.ID).Return(msg, data, true, nil).Once()
+	return &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeMessageConfirmed,
+		Namespace: "ns1",
+		Topic:     "topic1",
+		Reference: msg.Header.ID,
+	}
+}
+
+func TestNewSchemaDriftManagerMissingDeps(t *testing.T) {
+	_, err := NewSchemaDriftManager(context.Background(), nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestSchemaDriftDisabled(t *testing.T) {
+	sm, _, _, done := newTestSchemaDrift(t)
+	defer done()
+	assert.Empty(t, sm.GetTopicSchemas(context.Background(), "ns1"))
+	sm.WaitStop()
+}
+
+func TestSchemaDriftLoop(t *testing.T) {
+	config.Reset()
+	config.Set(config.SchemaDriftEnabled, true)
+	config.Set(config.SchemaDriftInterval, "1ms")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	calls := 0
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+	})
+
+	sm, err := NewSchemaDriftManager(ctx, mdi, mdm)
+	assert.NoError(t, err)
+	sm.WaitStop()
+
+	mdi.AssertExpectations(t)
+}
+
+func TestAnalyzeNewMessagesDrift(t *testing.T) {
+	sm, mdi, mdm, done := newTestSchemaDrift(t)
+	defer done()
+
+	events := []*fftypes.Event{
+		newTestMessage(mdm, fftypes.MessageTypeBroadcast, nil, `{"a":1,"b":"x"}`),
+		newTestMessage(mdm, fftypes.MessageTypeBroadcast, nil, `{"a":2,"b":"y"}`),
+		newTestMessage(mdm, fftypes.MessageTypePrivate, nil, `{"a":"3","c":true}`),
+		newTestMessage(mdm, fftypes.MessageTypeBroadcast, nil, `{"a":"4","c":false}`),
+	}
+	for i, e := range events {
+		e.Sequence = int64(11 + i)
+	}
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{{Sequence: 10}}, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(events, nil, nil).Once()
+	mdi.On("InsertEvent", mock.Anything, mock.MatchedBy(func(e *fftypes.Event) bool {
+		return e.Type == fftypes.EventTypeMessageSchemaDrift && e.Namespace == "ns1" && e.Topic == "topic1" && e.Reference.Equals(events[2].Reference)
+	})).Return(nil).Once()
+
+	err := sm.analyzeNewMessages(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(14), sm.lastEventSeq)
+
+	ts := sm.GetTopicSchema(context.Background(), "ns1", "topic1")
+	assert.Equal(t, int64(4), ts.Samples)
+	assert.Equal(t, int64(1), ts.Drifts)
+	assert.Equal(t, events[2].Reference, ts.LastDrift.Message)
+	assert.Equal(t, []string{
+		"$.a: type 'string' does not match expected type 'integer'",
+		"$.c: new property",
+		"$.b: required property is missing",
+	}, ts.LastDrift.Differences)
+	assert.Equal(t, []string{"integer", "string"}, ts.Schema.Properties["a"].Type)
+	assert.Equal(t, []string{"a"}, ts.Schema.Required)
+
+	schemas := sm.GetTopicSchemas(context.Background(), "ns1")
+	assert.Equal(t, []*fftypes.TopicSchema{ts}, schemas)
+	assert.Empty(t, sm.GetTopicSchemas(context.Background(), "ns2"))
+}
+
+func TestAnalyzeNewMessagesStartEmpty(t *testing.T) {
+	sm, mdi, _, done := newTestSchemaDrift(t)
+	defer done()
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil)
+	err := sm.analyzeNewMessages(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-1), sm.lastEventSeq)
+	assert.True(t, sm.started)
+}
+
+func TestAnalyzeNewMessagesStartFail(t *testing.T) {
+	sm, mdi, _, done := newTestSchemaDrift(t)
+	defer done()
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := sm.analyzeNewMessages(context.Background())
+	assert.EqualError(t, err, "pop")
+	assert.False(t, sm.started)
+}
+
+func TestAnalyzeNewMessagesGetEventsFail(t *testing.T) {
+	sm, mdi, _, done := newTestSchemaDrift(t)
+	defer done()
+
+	sm.started = true
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := sm.analyzeNewMessages(context.Background())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestAnalyzeNewMessagesGetMessageFail(t *testing.T) {
+	sm, mdi, mdm, done := newTestSchemaDrift(t)
+	defer done()
+
+	sm.started = true
+	sm.lastEventSeq = 10
+	msgID := fftypes.NewUUID()
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{
+		{Sequence: 11, Namespace: "ns1", Topic: "topic1", Reference: msgID},
+	}, nil, nil)
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(nil, nil, false, fmt.Errorf("pop"))
+	err := sm.analyzeNewMessages(context.Background())
+	assert.EqualError(t, err, "pop")
+	assert.Equal(t, int64(10), sm.lastEventSeq)
+}
+
+func TestAnalyzeMessageSkipped(t *testing.T) {
+	sm, _, mdm, done := newTestSchemaDrift(t)
+	defer done()
+
+	ctx := context.Background()
+	assert.NoError(t, sm.analyzeMessage(ctx, &fftypes.Event{Namespace: fftypes.SystemNamespace, Topic: "topic1"}))
+	assert.NoError(t, sm.analyzeMessage(ctx, &fftypes.Event{Namespace: "ns1"}))
+
+	msgID := fftypes.NewUUID()
+	mdm.On("GetMessageWithDataCached", mock.Anything, msgID).Return(nil, nil, false, nil)
+	assert.NoError(t, sm.analyzeMessage(ctx, &fftypes.Event{Namespace: "ns1", Topic: "topic1", Reference: msgID}))
+
+	assert.NoError(t, sm.analyzeMessage(ctx, newTestMessage(mdm, fftypes.MessageTypeDefinition, nil, `{}`)))
+	assert.NoError(t, sm.analyzeMessage(ctx, newTestMessage(mdm, fftypes.MessageTypeGroupInit, nil, `{}`)))
+	assert.NoError(t, sm.analyzeMessage(ctx, newTestMessage(mdm, fftypes.MessageTypeBroadcast, nil)))
+	assert.Empty(t, sm.topics)
+}
+
+func TestAnalyzeMessageMaxTopics(t *testing.T) {
+	sm, _, mdm, done := newTestSchemaDrift(t)
+	defer done()
+
+	sm.maxTopics = 1
+	ctx := context.Background()
+	assert.NoError(t, sm.analyzeMessage(ctx, newTestMessage(mdm, fftypes.MessageTypeBroadcast, nil, `{}`)))
+	e := newTestMessage(mdm, fftypes.MessageTypeBroadcast, nil, `{}`)
+	e.Topic = "topic2"
+	assert.NoError(t, sm.analyzeMessage(ctx, e))
+	assert.Len(t, sm.topics, 1)
+	assert.Nil(t, sm.GetTopicSchema(ctx, "ns1", "topic2"))
+}
+
+func TestAnalyzeMessageDatatypeDrift(t *testing.T) {
+	sm, mdi, mdm, done := newTestSchemaDrift(t)
+	defer done()
+
+	ctx := context.Background()
+	v1 := &fftypes.DatatypeRef{Name: "widget", Version: "1"}
+	v2 := &fftypes.DatatypeRef{Name: "widget", Version: "2"}
+	assert.NoError(t, sm.analyzeMessage(ctx, newTestMessage(mdm, fftypes.MessageTypeBroadcast, v1, `{"a":1}`)))
+	assert.NoError(t, sm.analyzeMessage(ctx, newTestMessage(mdm, fftypes.MessageTypeBroadcast, v1, `{"a":2}`)))
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(nil)
+	assert.NoError(t, sm.analyzeMessage(ctx, newTestMessage(mdm, fftypes.MessageTypeBroadcast, v2, `{"a":3}`)))
+
+	ts := sm.GetTopicSchema(ctx, "ns1", "topic1")
+	assert.Equal(t, []string{"datatype 'widget/2' does not match expected datatype 'widget/1'"}, ts.LastDrift.Differences)
+	assert.Equal(t, v2, ts.Datatype)
+}
+
+func TestAnalyzeMessageInsertEventFail(t *testing.T) {
+	sm, mdi, mdm, done := newTestSchemaDrift(t)
+	defer done()
+
+	ctx := context.Background()
+	assert.NoError(t, sm.analyzeMessage(ctx, newTestMessage(mdm, fftypes.MessageTypeBroadcast, nil, `{"a":1}`)))
+	assert.NoError(t, sm.analyzeMessage(ctx, newTestMessage(mdm, fftypes.MessageTypeBroadcast, nil, `{"a":2}`)))
+	mdi.On("InsertEvent", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	err := sm.analyzeMessage(ctx, newTestMessage(mdm, fftypes.MessageTypeBroadcast, nil, `{"b":3}`))
+	assert.EqualError(t, err, "pop")
+
+	ts := sm.GetTopicSchema(ctx, "ns1", "topic1")
+	assert.Equal(t, int64(2), ts.Samples)
+	assert.Zero(t, ts.Drifts)
+}
+
+func TestGetTopicSchemasSorted(t *testing.T) {
+	sm, _, _, done := newTestSchemaDrift(t)
+	defer done()
+
+	for _, topic := range []string{"topic2", "topic3", "topic1"} {
+		sm.topics[topicKey{namespace: "ns1", topic: topic}] = &fftypes.TopicSchema{Namespace: "ns1", Topic: topic}
+	}
+	schemas := sm.GetTopicSchemas(context.Background(), "ns1")
+	assert.Equal(t, "topic1", schemas[0].Topic)
+	assert.Equal(t, "topic2", schemas[1].Topic)
+	assert.Equal(t, "topic3", schemas[2].Topic)
+}
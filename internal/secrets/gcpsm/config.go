@@ -0,0 +1,43 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpsm
+
+import (
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+)
+
+const (
+	defaultSecretManagerURL = "https://secretmanager.googleapis.com"
+	defaultMetadataURL      = "http://metadata.google.internal"
+)
+
+const (
+	// GCPSMConfigToken is a static OAuth2 access token - if not set, a token for the default service account is obtained from the metadata server
+	GCPSMConfigToken = "token"
+	// GCPSMConfigMetadataSubconf is the http configuration to connect to the metadata server
+	GCPSMConfigMetadataSubconf = "metadata"
+)
+
+func (s *SecretManager) InitPrefix(prefix config.Prefix) {
+	restclient.InitPrefix(prefix)
+	prefix.SetDefault(restclient.HTTPConfigURL, defaultSecretManagerURL)
+	prefix.AddKnownKey(GCPSMConfigToken)
+	metadataPrefix := prefix.SubPrefix(GCPSMConfigMetadataSubconf)
+	restclient.InitPrefix(metadataPrefix)
+	metadataPrefix.SetDefault(restclient.HTTPConfigURL, defaultMetadataURL)
+}
@@ -0,0 +1,95 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpsm
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+)
+
+// SecretManager resolves secret references of the form "gcpsm://projects/<project>/secrets/<secret>"
+// by accessing the latest version of the secret in Google Cloud Secret Manager. A specific version
+// can be selected with a "/versions/<version>" suffix.
+type SecretManager struct{}
+
+type accessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+type accessSecretResponse struct {
+	Payload struct {
+		Data string `json:"data"`
+	} `json:"payload"`
+}
+
+func (s *SecretManager) Name() string {
+	return "gcpsm"
+}
+
+func (s *SecretManager) GetSecret(ctx context.Context, prefix config.Prefix, ref string) (string, error) {
+	name := strings.Trim(ref, "/")
+	parts := strings.Split(name, "/")
+	if (len(parts) != 4 && len(parts) != 6) || parts[0] != "projects" || parts[2] != "secrets" || (len(parts) == 6 && parts[4] != "versions") {
+		return "", i18n.NewError(ctx, i18n.MsgSecretRefInvalid, ref, "projects/<project>/secrets/<secret>[/versions/<version>]")
+	}
+	if len(parts) == 4 {
+		name += "/versions/latest"
+	}
+
+	token, err := s.getAccessToken(ctx, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	var secret accessSecretResponse
+	res, err := restclient.New(ctx, prefix).R().
+		SetContext(ctx).
+		SetAuthToken(token).
+		SetResult(&secret).
+		Get("/v1/" + name + ":access")
+	if err != nil || !res.IsSuccess() {
+		return "", restclient.WrapRestErr(ctx, res, err, i18n.MsgGCPSecretManagerRESTErr)
+	}
+	data, err := base64.StdEncoding.DecodeString(secret.Payload.Data)
+	if err != nil {
+		return "", i18n.WrapError(ctx, err, i18n.MsgSecretPayloadInvalid, name)
+	}
+	log.L(ctx).Debugf("Fetched secret %s from Google Cloud Secret Manager", name)
+	return string(data), nil
+}
+
+func (s *SecretManager) getAccessToken(ctx context.Context, prefix config.Prefix) (string, error) {
+	if token := prefix.GetString(GCPSMConfigToken); token != "" {
+		return token, nil
+	}
+	var tokenRes accessTokenResponse
+	res, err := restclient.New(ctx, prefix.SubPrefix(GCPSMConfigMetadataSubconf)).R().
+		SetContext(ctx).
+		SetHeader("Metadata-Flavor", "Google").
+		SetResult(&tokenRes).
+		Get("/computeMetadata/v1/instance/service-accounts/default/token")
+	if err != nil || !res.IsSuccess() || tokenRes.AccessToken == "" {
+		return "", restclient.WrapRestErr(ctx, res, err, i18n.MsgGCPMetadataTokenErr)
+	}
+	return tokenRes.AccessToken, nil
+}
@@ -0,0 +1,144 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcpsm
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var utConfPrefix = config.NewPluginConfig("gcpsm_unit_tests")
+
+func newTestSecretManager() (*SecretManager, func()) {
+	config.Reset()
+	s := &SecretManager{}
+	s.InitPrefix(utConfPrefix)
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	utConfPrefix.Set(restclient.HTTPCustomClient, mockedClient)
+	utConfPrefix.SubPrefix(GCPSMConfigMetadataSubconf).Set(restclient.HTTPCustomClient, mockedClient)
+	return s, httpmock.DeactivateAndReset
+}
+
+func TestGetSecretMetadataToken(t *testing.T) {
+	s, done := newTestSecretManager()
+	defer done()
+
+	httpmock.RegisterResponder("GET", "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "Google", req.Header.Get("Metadata-Flavor"))
+			return httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+				"access_token": "mdtoken",
+			})(req)
+		})
+	httpmock.RegisterResponder("GET", "https://secretmanager.googleapis.com/v1/projects/p1/secrets/db-password/versions/latest:access",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "Bearer mdtoken", req.Header.Get("Authorization"))
+			return httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+				"payload": map[string]interface{}{
+					"data": base64.StdEncoding.EncodeToString([]byte("s3cret")),
+				},
+			})(req)
+		})
+
+	secret, err := s.GetSecret(context.Background(), utConfPrefix, "projects/p1/secrets/db-password")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", secret)
+	assert.Equal(t, "gcpsm", s.Name())
+}
+
+func TestGetSecretStaticTokenVersion(t *testing.T) {
+	s, done := newTestSecretManager()
+	defer done()
+	utConfPrefix.Set(GCPSMConfigToken, "stoken")
+
+	httpmock.RegisterResponder("GET", "https://secretmanager.googleapis.com/v1/projects/p1/secrets/db-password/versions/3:access",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "Bearer stoken", req.Header.Get("Authorization"))
+			return httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+				"payload": map[string]interface{}{
+					"data": base64.StdEncoding.EncodeToString([]byte("v3")),
+				},
+			})(req)
+		})
+
+	secret, err := s.GetSecret(context.Background(), utConfPrefix, "projects/p1/secrets/db-password/versions/3")
+	assert.NoError(t, err)
+	assert.Equal(t, "v3", secret)
+}
+
+func TestGetSecretBadPayload(t *testing.T) {
+	s, done := newTestSecretManager()
+	defer done()
+	utConfPrefix.Set(GCPSMConfigToken, "stoken")
+
+	httpmock.RegisterResponder("GET", "https://secretmanager.googleapis.com/v1/projects/p1/secrets/db-password/versions/latest:access",
+		httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+			"payload": map[string]interface{}{
+				"data": "!!! not base64",
+			},
+		}))
+
+	_, err := s.GetSecret(context.Background(), utConfPrefix, "projects/p1/secrets/db-password")
+	assert.Regexp(t, "FF10392", err)
+}
+
+func TestGetSecretRESTError(t *testing.T) {
+	s, done := newTestSecretManager()
+	defer done()
+	utConfPrefix.Set(GCPSMConfigToken, "stoken")
+
+	httpmock.RegisterResponder("GET", "https://secretmanager.googleapis.com/v1/projects/p1/secrets/db-password/versions/latest:access",
+		httpmock.NewStringResponder(404, "not found"))
+
+	_, err := s.GetSecret(context.Background(), utConfPrefix, "projects/p1/secrets/db-password")
+	assert.Regexp(t, "FF10390", err)
+}
+
+func TestGetSecretMetadataTokenFail(t *testing.T) {
+	s, done := newTestSecretManager()
+	defer done()
+
+	httpmock.RegisterResponder("GET", "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token",
+		httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{}))
+
+	_, err := s.GetSecret(context.Background(), utConfPrefix, "projects/p1/secrets/db-password")
+	assert.Regexp(t, "FF10391", err)
+}
+
+func TestGetSecretBadRef(t *testing.T) {
+	s, done := newTestSecretManager()
+	defer done()
+
+	for _, ref := range []string{
+		"db-password",
+		"projects/p1/db-password",
+		"folders/p1/secrets/db-password",
+		"projects/p1/keys/db-password",
+		"projects/p1/secrets/db-password/latest/3",
+	} {
+		_, err := s.GetSecret(context.Background(), utConfPrefix, ref)
+		assert.Regexp(t, "FF10388", err)
+	}
+}
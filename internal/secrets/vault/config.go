@@ -0,0 +1,38 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+)
+
+const (
+	// VaultConfigToken is the Vault token used to authenticate
+	VaultConfigToken = "token"
+	// VaultConfigTokenFile is a file containing the Vault token, re-read on each request so that a token renewed by a Vault agent is picked up
+	VaultConfigTokenFile = "tokenFile"
+	// VaultConfigNamespace is the Vault Enterprise namespace containing the secrets
+	VaultConfigNamespace = "namespace"
+)
+
+func (v *Vault) InitPrefix(prefix config.Prefix) {
+	restclient.InitPrefix(prefix)
+	prefix.AddKnownKey(VaultConfigToken)
+	prefix.AddKnownKey(VaultConfigTokenFile)
+	prefix.AddKnownKey(VaultConfigNamespace)
+}
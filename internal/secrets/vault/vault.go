@@ -0,0 +1,88 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Vault resolves secret references of the form "vault://<path>#<field>" by reading from
+// the HTTP API of HashiCorp Vault - for example "vault://secret/data/firefly/db#password"
+// for version 2 of the key/value secrets engine
+type Vault struct{}
+
+func (v *Vault) Name() string {
+	return "vault"
+}
+
+func (v *Vault) GetSecret(ctx context.Context, prefix config.Prefix, ref string) (string, error) {
+	hashIdx := strings.LastIndex(ref, "#")
+	if hashIdx <= 0 || hashIdx == len(ref)-1 {
+		return "", i18n.NewError(ctx, i18n.MsgSecretRefInvalid, ref, "<path>#<field>")
+	}
+	path := strings.TrimPrefix(ref[0:hashIdx], "/")
+	field := ref[hashIdx+1:]
+
+	if prefix.GetString(restclient.HTTPConfigURL) == "" {
+		return "", i18n.NewError(ctx, i18n.MsgMissingPluginConfig, prefix.Resolve(restclient.HTTPConfigURL), "vault")
+	}
+	token := prefix.GetString(VaultConfigToken)
+	if tokenFile := prefix.GetString(VaultConfigTokenFile); tokenFile != "" {
+		b, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return "", i18n.WrapError(ctx, err, i18n.MsgConfigSecretFileRead, tokenFile, prefix.Resolve(VaultConfigTokenFile))
+		}
+		token = strings.TrimSpace(string(b))
+	}
+
+	req := restclient.New(ctx, prefix).R().
+		SetContext(ctx).
+		SetHeader("X-Vault-Token", token)
+	if namespace := prefix.GetString(VaultConfigNamespace); namespace != "" {
+		req.SetHeader("X-Vault-Namespace", namespace)
+	}
+	var body fftypes.JSONObject
+	res, err := req.SetResult(&body).Get("/v1/" + path)
+	if err != nil || !res.IsSuccess() {
+		return "", restclient.WrapRestErr(ctx, res, err, i18n.MsgVaultRESTErr)
+	}
+
+	data := body.GetObject("data")
+	if _, isKVv2 := data.GetObjectOk("metadata"); isKVv2 {
+		// Version 2 of the key/value engine nests the secret data alongside its metadata
+		data = data.GetObject("data")
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", i18n.NewError(ctx, i18n.MsgSecretFieldNotFound, field, path)
+	}
+	log.L(ctx).Debugf("Fetched secret %s#%s from Vault", path, field)
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	b, _ := json.Marshal(value)
+	return string(b), nil
+}
@@ -0,0 +1,148 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vault
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/restclient"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var utConfPrefix = config.NewPluginConfig("vault_unit_tests")
+
+func newTestVault() (*Vault, func()) {
+	config.Reset()
+	v := &Vault{}
+	v.InitPrefix(utConfPrefix)
+	mockedClient := &http.Client{}
+	httpmock.ActivateNonDefault(mockedClient)
+	utConfPrefix.Set(restclient.HTTPConfigURL, "http://vault:8200")
+	utConfPrefix.Set(restclient.HTTPCustomClient, mockedClient)
+	return v, httpmock.DeactivateAndReset
+}
+
+func TestGetSecretKVv2(t *testing.T) {
+	v, done := newTestVault()
+	defer done()
+	utConfPrefix.Set(VaultConfigToken, "vtoken")
+	utConfPrefix.Set(VaultConfigNamespace, "ns1")
+
+	httpmock.RegisterResponder("GET", "http://vault:8200/v1/secret/data/firefly/db",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "vtoken", req.Header.Get("X-Vault-Token"))
+			assert.Equal(t, "ns1", req.Header.Get("X-Vault-Namespace"))
+			return httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+				"data": map[string]interface{}{
+					"data": map[string]interface{}{
+						"password": "s3cret",
+						"port":     5432,
+					},
+					"metadata": map[string]interface{}{
+						"version": 3,
+					},
+				},
+			})(req)
+		})
+
+	secret, err := v.GetSecret(context.Background(), utConfPrefix, "/secret/data/firefly/db#password")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cret", secret)
+	assert.Equal(t, "vault", v.Name())
+
+	secret, err = v.GetSecret(context.Background(), utConfPrefix, "secret/data/firefly/db#port")
+	assert.NoError(t, err)
+	assert.Equal(t, "5432", secret)
+
+	_, err = v.GetSecret(context.Background(), utConfPrefix, "secret/data/firefly/db#missing")
+	assert.Regexp(t, "FF10389.*missing", err)
+}
+
+func TestGetSecretKVv1TokenFile(t *testing.T) {
+	v, done := newTestVault()
+	defer done()
+	dir, err := ioutil.TempDir("", "ff")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tokenFile := path.Join(dir, "token")
+	err = ioutil.WriteFile(tokenFile, []byte("ftoken\n"), 0600)
+	assert.NoError(t, err)
+	utConfPrefix.Set(VaultConfigTokenFile, tokenFile)
+
+	httpmock.RegisterResponder("GET", "http://vault:8200/v1/kv/firefly",
+		func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "ftoken", req.Header.Get("X-Vault-Token"))
+			assert.Empty(t, req.Header.Get("X-Vault-Namespace"))
+			return httpmock.NewJsonResponderOrPanic(200, map[string]interface{}{
+				"data": map[string]interface{}{
+					"apikey": "k3y",
+				},
+			})(req)
+		})
+
+	secret, err := v.GetSecret(context.Background(), utConfPrefix, "kv/firefly#apikey")
+	assert.NoError(t, err)
+	assert.Equal(t, "k3y", secret)
+}
+
+func TestGetSecretBadTokenFile(t *testing.T) {
+	v, done := newTestVault()
+	defer done()
+	utConfPrefix.Set(VaultConfigTokenFile, "!!!missing")
+
+	_, err := v.GetSecret(context.Background(), utConfPrefix, "kv/firefly#apikey")
+	assert.Regexp(t, "FF10385", err)
+}
+
+func TestGetSecretRESTError(t *testing.T) {
+	v, done := newTestVault()
+	defer done()
+
+	httpmock.RegisterResponder("GET", "http://vault:8200/v1/kv/firefly",
+		httpmock.NewJsonResponderOrPanic(403, map[string]interface{}{
+			"errors": []string{"permission denied"},
+		}))
+
+	_, err := v.GetSecret(context.Background(), utConfPrefix, "kv/firefly#apikey")
+	assert.Regexp(t, "FF10387", err)
+}
+
+func TestGetSecretBadRef(t *testing.T) {
+	v, done := newTestVault()
+	defer done()
+
+	_, err := v.GetSecret(context.Background(), utConfPrefix, "kv/firefly")
+	assert.Regexp(t, "FF10388", err)
+	_, err = v.GetSecret(context.Background(), utConfPrefix, "kv/firefly#")
+	assert.Regexp(t, "FF10388", err)
+}
+
+func TestGetSecretMissingURL(t *testing.T) {
+	v, done := newTestVault()
+	defer done()
+	utConfPrefix.Set(restclient.HTTPConfigURL, "")
+
+	_, err := v.GetSecret(context.Background(), utConfPrefix, "kv/firefly#apikey")
+	assert.Regexp(t, "FF10138", err)
+}
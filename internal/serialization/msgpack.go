@@ -0,0 +1,109 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serialization
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// appendMsgPack appends a value decoded from JSON (with json.Number for numbers) in MessagePack.
+// Object keys are written in sorted order, so the encoding is deterministic.
+func appendMsgPack(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xc0)
+	case bool:
+		if v {
+			return append(b, 0xc3)
+		}
+		return append(b, 0xc2)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return appendMsgPackInt(b, i)
+		}
+		f, _ := v.Float64()
+		return appendMsgPackFloat(b, f)
+	case string:
+		return append(appendMsgPackLength(b, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb), v...)
+	case []interface{}:
+		b = appendMsgPackLength(b, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, e := range v {
+			b = appendMsgPack(b, e)
+		}
+		return b
+	case map[string]interface{}:
+		b = appendMsgPackLength(b, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b = appendMsgPack(b, k)
+			b = appendMsgPack(b, v[k])
+		}
+		return b
+	default:
+		return append(b, 0xc0)
+	}
+}
+
+// appendMsgPackLength writes the header of a string, array or map, using the fix format if the length
+// is less than fixLimit, and otherwise the 8 bit (strings only), 16 bit or 32 bit length format
+func appendMsgPackLength(b []byte, l int, fix byte, fixLimit int, l8, l16, l32 byte) []byte {
+	switch {
+	case l < fixLimit:
+		return append(b, fix|byte(l))
+	case l8 != 0 && l <= math.MaxUint8:
+		return append(b, l8, byte(l))
+	case l <= math.MaxUint16:
+		return appendUint(append(b, l16), uint64(l), 2)
+	default:
+		return appendUint(append(b, l32), uint64(l), 4)
+	}
+}
+
+func appendMsgPackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= 0x7f:
+		return append(b, byte(i))
+	case i < 0 && i >= -32:
+		return append(b, byte(i))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		return append(b, 0xd0, byte(i))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		return appendUint(append(b, 0xd1), uint64(i), 2)
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		return appendUint(append(b, 0xd2), uint64(i), 4)
+	default:
+		return appendUint(append(b, 0xd3), uint64(i), 8)
+	}
+}
+
+func appendMsgPackFloat(b []byte, f float64) []byte {
+	return appendUint(append(b, 0xcb), math.Float64bits(f), 8)
+}
+
+// appendUint appends the low n bytes of v, big-endian
+func appendUint(b []byte, v uint64, n int) []byte {
+	for i := n - 1; i >= 0; i-- {
+		b = append(b, byte(v>>(8*i)))
+	}
+	return b
+}
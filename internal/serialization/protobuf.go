@@ -0,0 +1,242 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serialization
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// protobufSchema is the Protocol Buffers definition of the event frames, published by the node
+const protobufSchema = `syntax = "proto3";
+
+package firefly;
+
+import "google/protobuf/struct.proto";
+
+// EventDelivery is an event delivered to a subscription. UUIDs are the 16 bytes of the UUID.
+message EventDelivery {
+  bytes id = 1;
+  int64 sequence = 2;
+  string type = 3;
+  string namespace = 4;
+  bytes reference = 5;
+  bytes correlator = 6;
+  bytes tx = 7;
+  string topic = 8;
+  int64 created = 9; // Unix time in nanoseconds
+  SubscriptionRef subscription = 10;
+  string resume_token = 11;
+  // The remaining fields of the JSON event frame, such as the message, data and transaction
+  google.protobuf.Struct enrichment = 15;
+}
+
+message SubscriptionRef {
+  bytes id = 1;
+  string namespace = 2;
+  string name = 3;
+}
+`
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+type protoKind int
+
+const (
+	protoUUID protoKind = iota
+	protoInt64
+	protoString
+	protoTime
+	protoSubscription
+)
+
+type protoField struct {
+	name   string
+	number uint64
+	kind   protoKind
+}
+
+var eventFields = []protoField{
+	{"id", 1, protoUUID},
+	{"sequence", 2, protoInt64},
+	{"type", 3, protoString},
+	{"namespace", 4, protoString},
+	{"reference", 5, protoUUID},
+	{"correlator", 6, protoUUID},
+	{"tx", 7, protoUUID},
+	{"topic", 8, protoString},
+	{"created", 9, protoTime},
+	{"subscription", 10, protoSubscription},
+	{"resumeToken", 11, protoString},
+}
+
+var subscriptionFields = []protoField{
+	{"id", 1, protoUUID},
+	{"namespace", 2, protoString},
+	{"name", 3, protoString},
+}
+
+const enrichmentField = 15
+
+func marshalProtobuf(frame map[string]interface{}) []byte {
+	b, rest := appendProtoFields(nil, eventFields, frame)
+	if len(rest) > 0 {
+		b = appendProtoBytes(b, enrichmentField, appendProtoStruct(nil, rest))
+	}
+	return b
+}
+
+// appendProtoFields writes the typed fields of a message, and returns the fields of the
+// object that are not in the message or do not have the expected type
+func appendProtoFields(b []byte, fields []protoField, obj map[string]interface{}) ([]byte, map[string]interface{}) {
+	rest := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		rest[k] = v
+	}
+	for _, f := range fields {
+		v, ok := obj[f.name]
+		if !ok {
+			continue
+		}
+		if v == nil {
+			delete(rest, f.name)
+			continue
+		}
+		var written bool
+		b, written = appendProtoField(b, f, v)
+		if written {
+			delete(rest, f.name)
+		}
+	}
+	return b, rest
+}
+
+func appendProtoField(b []byte, f protoField, v interface{}) ([]byte, bool) {
+	switch f.kind {
+	case protoUUID:
+		s, _ := v.(string)
+		u, err := fftypes.ParseUUID(context.Background(), s)
+		if err != nil {
+			return b, false
+		}
+		return appendProtoBytes(b, f.number, u[:]), true
+	case protoInt64:
+		n, _ := v.(json.Number)
+		i, err := n.Int64()
+		if err != nil {
+			return b, false
+		}
+		return appendVarint(appendTag(b, f.number, wireVarint), uint64(i)), true
+	case protoString:
+		s, ok := v.(string)
+		if !ok {
+			return b, false
+		}
+		return appendProtoBytes(b, f.number, []byte(s)), true
+	case protoTime:
+		s, _ := v.(string)
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return b, false
+		}
+		return appendVarint(appendTag(b, f.number, wireVarint), uint64(t.UnixNano())), true
+	default: // protoSubscription
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return b, false
+		}
+		sub, rest := appendProtoFields(nil, subscriptionFields, obj)
+		if len(rest) > 0 {
+			return b, false
+		}
+		return appendProtoBytes(b, f.number, sub), true
+	}
+}
+
+// appendProtoStruct writes an object as a google.protobuf.Struct, with its map entries in key order
+func appendProtoStruct(b []byte, obj map[string]interface{}) []byte {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		entry := appendProtoBytes(nil, 1, []byte(k))
+		entry = appendProtoBytes(entry, 2, appendProtoValue(nil, obj[k]))
+		b = appendProtoBytes(b, 1, entry)
+	}
+	return b
+}
+
+// appendProtoValue writes the oneof of a google.protobuf.Value
+func appendProtoValue(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return appendFixed64(appendTag(b, 2, wireFixed64), math.Float64bits(f))
+	case string:
+		return appendProtoBytes(b, 3, []byte(v))
+	case bool:
+		i := uint64(0)
+		if v {
+			i = 1
+		}
+		return appendVarint(appendTag(b, 4, wireVarint), i)
+	case map[string]interface{}:
+		return appendProtoBytes(b, 5, appendProtoStruct(nil, v))
+	case []interface{}:
+		var list []byte
+		for _, e := range v {
+			list = appendProtoBytes(list, 1, appendProtoValue(nil, e))
+		}
+		return appendProtoBytes(b, 6, list)
+	default:
+		return appendVarint(appendTag(b, 1, wireVarint), 0) // NULL_VALUE
+	}
+}
+
+func appendTag(b []byte, number uint64, wireType uint64) []byte {
+	return appendVarint(b, number<<3|wireType)
+}
+
+func appendProtoBytes(b []byte, number uint64, v []byte) []byte {
+	return append(appendVarint(appendTag(b, number, wireBytes), uint64(len(v))), v...)
+}
+
+func appendVarint(b []byte, v uint64) []byte {
+	for v >= 0x80 {
+		b = append(b, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(b, byte(v))
+}
+
+func appendFixed64(b []byte, v uint64) []byte {
+	for i := 0; i < 8; i++ {
+		b = append(b, byte(v>>(8*i)))
+	}
+	return b
+}
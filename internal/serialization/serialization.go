@@ -0,0 +1,94 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package serialization encodes the event frames delivered to subscriptions, as JSON or in one
+// of the more compact binary formats for high-frequency consumers.
+package serialization
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Valid returns true if the format is one of the supported serializations
+func Valid(format fftypes.SubOptsSerialization) bool {
+	switch format {
+	case fftypes.SubOptsSerializationJSON, fftypes.SubOptsSerializationMsgPack, fftypes.SubOptsSerializationProtobuf:
+		return true
+	default:
+		return false
+	}
+}
+
+// Binary returns true if the format is not JSON, so frames must be sent as binary
+func Binary(format *fftypes.SubOptsSerialization) bool {
+	return format != nil && *format != fftypes.SubOptsSerializationJSON
+}
+
+// ContentType is the MIME type of frames in the format
+func ContentType(format fftypes.SubOptsSerialization) string {
+	switch format {
+	case fftypes.SubOptsSerializationMsgPack:
+		return "application/msgpack"
+	case fftypes.SubOptsSerializationProtobuf:
+		return "application/x-protobuf"
+	default:
+		return "application/json"
+	}
+}
+
+// Marshal encodes an event frame in the format. The binary formats are built from the JSON
+// encoding of the frame, so they carry exactly the same fields.
+func Marshal(ctx context.Context, format fftypes.SubOptsSerialization, event *fftypes.EventDelivery) ([]byte, error) {
+	if !Valid(format) {
+		return nil, i18n.NewError(ctx, i18n.MsgInvalidSerialization, format)
+	}
+	b, err := json.Marshal(event)
+	if err != nil || format == fftypes.SubOptsSerializationJSON {
+		return b, err
+	}
+	var frame map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	_ = d.Decode(&frame) // we just marshalled it
+	if format == fftypes.SubOptsSerializationMsgPack {
+		return appendMsgPack(nil, frame), nil
+	}
+	return marshalProtobuf(frame), nil
+}
+
+// Schema returns the schema of event frames in the format - a Protocol Buffers definition for
+// protobuf, and a JSON Schema for the JSON and MessagePack frames
+func Schema(ctx context.Context, format fftypes.SubOptsSerialization) (string, error) {
+	switch format {
+	case fftypes.SubOptsSerializationProtobuf:
+		return protobufSchema, nil
+	case fftypes.SubOptsSerializationJSON, fftypes.SubOptsSerializationMsgPack:
+		schemaRef, err := openapi3gen.NewSchemaRefForValue(&fftypes.EventDelivery{}, nil)
+		if err != nil {
+			return "", err
+		}
+		b, _ := json.MarshalIndent(schemaRef.Value, "", "  ")
+		return string(b), nil
+	default:
+		return "", i18n.NewError(ctx, i18n.MsgInvalidSerialization, format)
+	}
+}
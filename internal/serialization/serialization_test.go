@@ -0,0 +1,179 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serialization
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormats(t *testing.T) {
+	json, msgpack, protobuf := fftypes.SubOptsSerializationJSON, fftypes.SubOptsSerializationMsgPack, fftypes.SubOptsSerializationProtobuf
+	assert.True(t, Valid(json))
+	assert.True(t, Valid(msgpack))
+	assert.True(t, Valid(protobuf))
+	assert.False(t, Valid("xml"))
+	assert.False(t, Binary(nil))
+	assert.False(t, Binary(&json))
+	assert.True(t, Binary(&msgpack))
+	assert.Equal(t, "application/json", ContentType(json))
+	assert.Equal(t, "application/msgpack", ContentType(msgpack))
+	assert.Equal(t, "application/x-protobuf", ContentType(protobuf))
+}
+
+func TestMarshalJSON(t *testing.T) {
+	b, err := Marshal(context.Background(), fftypes.SubOptsSerializationJSON, &fftypes.EventDelivery{})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^\{"id":null`, string(b))
+}
+
+func TestMarshalInvalid(t *testing.T) {
+	_, err := Marshal(context.Background(), "xml", &fftypes.EventDelivery{})
+	assert.Regexp(t, "FF10498.*xml", err)
+}
+
+func TestMarshalMsgPack(t *testing.T) {
+	b, err := Marshal(context.Background(), fftypes.SubOptsSerializationMsgPack, &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{Sequence: 1, Topic: "t"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "\x88"+
+		"\xa7created\xc0"+
+		"\xa2id\xc0"+
+		"\xa9namespace\xa0"+
+		"\xa9reference\xc0"+
+		"\xa8sequence\x01"+
+		"\xacsubscription\x83\xa2id\xc0\xa4name\xa0\xa9namespace\xa0"+
+		"\xa5topic\xa1t"+
+		"\xa4type\xa0", string(b))
+}
+
+func decodeJSON(s string) interface{} {
+	var v interface{}
+	d := json.NewDecoder(strings.NewReader(s))
+	d.UseNumber()
+	_ = d.Decode(&v)
+	return v
+}
+
+func TestAppendMsgPack(t *testing.T) {
+	for _, test := range []struct {
+		value    interface{}
+		expected string
+	}{
+		{nil, "\xc0"},
+		{true, "\xc3"},
+		{false, "\xc2"},
+		{json.Number("127"), "\x7f"},
+		{json.Number("-32"), "\xe0"},
+		{json.Number("128"), "\xd1\x00\x80"},
+		{json.Number("-33"), "\xd0\xdf"},
+		{json.Number("-32768"), "\xd1\x80\x00"},
+		{json.Number("65536"), "\xd2\x00\x01\x00\x00"},
+		{json.Number("4294967296"), "\xd3\x00\x00\x00\x01\x00\x00\x00\x00"},
+		{json.Number("1.5"), "\xcb\x3f\xf8\x00\x00\x00\x00\x00\x00"},
+		{strings.Repeat("a", 31), "\xbf" + strings.Repeat("a", 31)},
+		{strings.Repeat("a", 32), "\xd9\x20" + strings.Repeat("a", 32)},
+		{strings.Repeat("a", 256), "\xda\x01\x00" + strings.Repeat("a", 256)},
+		{strings.Repeat("a", 65536), "\xdb\x00\x01\x00\x00" + strings.Repeat("a", 65536)},
+		{decodeJSON(`[1,"a"]`), "\x92\x01\xa1a"},
+		{make([]interface{}, 16), "\xdc\x00\x10" + strings.Repeat("\xc0", 16)},
+		{decodeJSON(`{"b":1,"a":2}`), "\x82\xa1a\x02\xa1b\x01"},
+		{struct{}{}, "\xc0"},
+	} {
+		assert.Equal(t, test.expected, string(appendMsgPack(nil, test.value)))
+	}
+	assert.Equal(t, "\xdf\x00\x01\x00\x00", string(appendMsgPackLength(nil, 65536, 0x80, 16, 0, 0xde, 0xdf)))
+}
+
+func TestMarshalProtobuf(t *testing.T) {
+	id := fftypes.MustParseUUID("11111111-2222-3333-4444-555555555555")
+	created := fftypes.FFTime(time.Unix(0, 1))
+	b, err := Marshal(context.Background(), fftypes.SubOptsSerializationProtobuf, &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID:        id,
+				Sequence:  300,
+				Type:      fftypes.EventTypeMessageConfirmed,
+				Namespace: "ns1",
+				Created:   &created,
+			},
+		},
+		Subscription: fftypes.SubscriptionRef{Name: "sub1"},
+		ResumeToken:  "tok",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "\x0a\x10"+string(id[:])+
+		"\x10\xac\x02"+
+		"\x1a\x11message_confirmed"+
+		"\x22\x03ns1"+
+		"\x48\x01"+
+		"\x52\x08\x12\x00\x1a\x04sub1"+
+		"\x5a\x03tok", string(b))
+}
+
+func TestMarshalProtobufEnrichment(t *testing.T) {
+	frame := decodeJSON(`{
+		"id": "not a uuid",
+		"sequence": "not a number",
+		"type": 1,
+		"created": "not a time",
+		"subscription": {"id": null, "extra": true},
+		"message": {"n": 1.5, "s": "a", "b": false, "l": [null], "t": true}
+	}`).(map[string]interface{})
+	b := marshalProtobuf(frame)
+	expected := map[string]string{
+		"created":      "\x1a\x0anot a time",
+		"id":           "\x1a\x0anot a uuid",
+		"message":      "\x2a\x39" + "\x0a\x07\x0a\x01b\x12\x02\x20\x00" + "\x0a\x0b\x0a\x01l\x12\x06\x32\x04\x0a\x02\x08\x00" + "\x0a\x0e\x0a\x01n\x12\x09\x11\x00\x00\x00\x00\x00\x00\xf8\x3f" + "\x0a\x08\x0a\x01s\x12\x03\x1a\x01a" + "\x0a\x07\x0a\x01t\x12\x02\x20\x01",
+		"sequence":     "\x1a\x0cnot a number",
+		"subscription": "\x2a\x17" + "\x0a\x0b\x0a\x05extra\x12\x02\x20\x01" + "\x0a\x08\x0a\x02id\x12\x02\x08\x00",
+		"type":         "\x11\x00\x00\x00\x00\x00\x00\xf0\x3f",
+	}
+	var structBytes string
+	for _, k := range []string{"created", "id", "message", "sequence", "subscription", "type"} {
+		v := expected[k]
+		entry := string(appendProtoBytes(nil, 1, []byte(k))) + string(appendProtoBytes(nil, 2, []byte(v)))
+		structBytes += string(appendProtoBytes(nil, 1, []byte(entry)))
+	}
+	assert.Equal(t, string(appendProtoBytes(nil, enrichmentField, []byte(structBytes))), string(b))
+}
+
+func TestMarshalProtobufNullValue(t *testing.T) {
+	assert.Equal(t, "\x08\x00", string(appendProtoValue(nil, nil)))
+}
+
+func TestSchema(t *testing.T) {
+	schema, err := Schema(context.Background(), fftypes.SubOptsSerializationProtobuf)
+	assert.NoError(t, err)
+	assert.Regexp(t, "message EventDelivery", schema)
+
+	schema, err = Schema(context.Background(), fftypes.SubOptsSerializationMsgPack)
+	assert.NoError(t, err)
+	assert.Regexp(t, `"resumeToken"`, schema)
+
+	_, err = Schema(context.Background(), "xml")
+	assert.Regexp(t, "FF10498", err)
+}
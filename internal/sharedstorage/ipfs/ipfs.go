@@ -88,7 +88,7 @@ func (i *IPFS) PublishData(ctx context.Context, data io.Reader) (string, error)
 
 func (i *IPFS) RetrieveData(ctx context.Context, payloadRef string) (data io.ReadCloser, err error) {
 	res, err := i.gwClient.R().
-		SetContext(ctx).
+		SetContext(restclient.WithoutCallTimeout(ctx)).
 		SetDoNotParseResponse(true).
 		Get(fmt.Sprintf("/ipfs/%s", payloadRef))
 	restclient.OnAfterResponse(i.gwClient, res) // required using SetDoNotParseResponse
@@ -0,0 +1,175 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signing
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+const (
+	// HeaderTimestamp is the HTTP header containing the time a request was signed
+	HeaderTimestamp = "X-FireFly-Timestamp"
+	// HeaderSignature is the HTTP header containing the signature of a request
+	HeaderSignature = "X-FireFly-Signature"
+	// MethodMessage is used in place of the HTTP method when signing a websocket message
+	MethodMessage = "MESSAGE"
+	// DirectionOutbound is included in the signature of everything FireFly sends to the connector
+	DirectionOutbound = "firefly->connector"
+	// DirectionInbound is included in the signature of everything the connector sends to FireFly
+	DirectionInbound = "connector->firefly"
+)
+
+// SignedMessage is the envelope used to carry a signed websocket message
+type SignedMessage struct {
+	Timestamp string          `json:"timestamp"`
+	Signature string          `json:"signature"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// Signer signs messages sent to a connector, and verifies messages received from it, using
+// HMAC-SHA256 with a key shared with the connector. Each signature covers a timestamp, and
+// verification rejects messages outside of the allowed clock skew, as well as any signature
+// that has already been received within that window - so captured messages cannot be replayed.
+// The direction of travel is part of every signature, so a message signed by FireFly cannot be
+// reflected back to it as though it came from the connector (or vice versa).
+type Signer struct {
+	key     []byte
+	maxSkew time.Duration
+	mux     sync.Mutex
+	seen    map[string]bool
+	expiry  *list.List
+}
+
+type seenSignature struct {
+	signature string
+	expires   time.Time
+}
+
+func NewSigner(key string, maxSkew time.Duration) *Signer {
+	return &Signer{
+		key:     []byte(key),
+		maxSkew: maxSkew,
+		seen:    make(map[string]bool),
+		expiry:  list.New(),
+	}
+}
+
+// Signature calculates the signature over the direction, method, path, timestamp and a hash of the body
+func (s *Signer) Signature(direction, method, path, timestamp string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(direction + "\n" + method + "\n" + path + "\n" + timestamp + "\n" + hex.EncodeToString(bodyHash[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest adds the timestamp and signature headers to an outbound HTTP request
+func (s *Signer) SignRequest(req *http.Request) error {
+	var body []byte
+	if req.GetBody != nil {
+		r, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		if body, err = ioutil.ReadAll(r); err != nil {
+			return err
+		}
+	} else if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	timestamp := time.Now().UTC().Format(time.RFC3339Nano)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, s.Signature(DirectionOutbound, req.Method, req.URL.RequestURI(), timestamp, body))
+	return nil
+}
+
+// SignMessage wraps a websocket message in a signed envelope
+func (s *Signer) SignMessage(payload []byte) []byte {
+	timestamp := time.Now().UTC().Format(time.RFC3339Nano)
+	b, _ := json.Marshal(&SignedMessage{
+		Timestamp: timestamp,
+		Signature: s.Signature(DirectionOutbound, MethodMessage, "", timestamp, payload),
+		Payload:   payload,
+	})
+	return b
+}
+
+// VerifyMessage checks the signed envelope of a received websocket message, returning the payload
+func (s *Signer) VerifyMessage(ctx context.Context, message []byte) ([]byte, error) {
+	var signed SignedMessage
+	if err := json.Unmarshal(message, &signed); err != nil || signed.Signature == "" || signed.Timestamp == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgSignatureMissing)
+	}
+	if err := s.Verify(ctx, DirectionInbound, MethodMessage, "", signed.Timestamp, signed.Signature, signed.Payload); err != nil {
+		return nil, err
+	}
+	return signed.Payload, nil
+}
+
+// Verify checks a signature is valid, recent, and has not been seen before
+func (s *Signer) Verify(ctx context.Context, direction, method, path, timestamp, signature string, body []byte) error {
+	expected := s.Signature(direction, method, path, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return i18n.NewError(ctx, i18n.MsgSignatureInvalid)
+	}
+	signedAt, err := time.Parse(time.RFC3339Nano, timestamp)
+	now := time.Now()
+	if err != nil || signedAt.Before(now.Add(-s.maxSkew)) || signedAt.After(now.Add(s.maxSkew)) {
+		return i18n.NewError(ctx, i18n.MsgSignatureExpired, timestamp, s.maxSkew)
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	now = time.Now()
+	s.expireSeen(now)
+	if s.seen[signature] {
+		return i18n.NewError(ctx, i18n.MsgSignatureReplayed)
+	}
+	// A signature accepted now can be accepted again until its timestamp falls outside the skew,
+	// which is at most two skews from now. Entries are queued in the order they arrive, so the
+	// queue is always ordered by expiry.
+	s.seen[signature] = true
+	s.expiry.PushBack(&seenSignature{signature: signature, expires: now.Add(2 * s.maxSkew)})
+	return nil
+}
+
+func (s *Signer) expireSeen(now time.Time) {
+	for e := s.expiry.Front(); e != nil; e = s.expiry.Front() {
+		entry := e.Value.(*seenSignature)
+		if entry.expires.After(now) {
+			return
+		}
+		delete(s.seen, entry.signature)
+		s.expiry.Remove(e)
+	}
+}
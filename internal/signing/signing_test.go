@@ -0,0 +1,175 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type errReader struct{}
+
+func (er *errReader) Read(p []byte) (int, error) {
+	return 0, fmt.Errorf("pop")
+}
+
+func connectorMessage(s *Signer, payload string) []byte {
+	ts := time.Now().UTC().Format(time.RFC3339Nano)
+	b, _ := json.Marshal(&SignedMessage{
+		Timestamp: ts,
+		Signature: s.Signature(DirectionInbound, MethodMessage, "", ts, []byte(payload)),
+		Payload:   json.RawMessage(payload),
+	})
+	return b
+}
+
+func TestSignVerifyMessage(t *testing.T) {
+	s := NewSigner("key1", 1*time.Minute)
+	signed := connectorMessage(s, `{"some":"event"}`)
+
+	payload, err := s.VerifyMessage(context.Background(), signed)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"some":"event"}`, string(payload))
+
+	_, err = s.VerifyMessage(context.Background(), signed)
+	assert.Regexp(t, "FF10396", err)
+}
+
+func TestVerifyMessageWrongKey(t *testing.T) {
+	signed := connectorMessage(NewSigner("key1", 1*time.Minute), `{}`)
+	_, err := NewSigner("key2", 1*time.Minute).VerifyMessage(context.Background(), signed)
+	assert.Regexp(t, "FF10394", err)
+}
+
+func TestVerifyMessageReflected(t *testing.T) {
+	s := NewSigner("key1", 1*time.Minute)
+	_, err := s.VerifyMessage(context.Background(), s.SignMessage([]byte(`{}`)))
+	assert.Regexp(t, "FF10394", err)
+}
+
+func TestVerifyMessageUnsigned(t *testing.T) {
+	s := NewSigner("key1", 1*time.Minute)
+	_, err := s.VerifyMessage(context.Background(), []byte(`{"some":"event"}`))
+	assert.Regexp(t, "FF10393", err)
+	_, err = s.VerifyMessage(context.Background(), []byte(`!json`))
+	assert.Regexp(t, "FF10393", err)
+}
+
+func TestVerifyExpired(t *testing.T) {
+	s := NewSigner("key1", 1*time.Minute)
+	for _, ts := range []string{
+		time.Now().Add(-2 * time.Minute).UTC().Format(time.RFC3339Nano),
+		time.Now().Add(2 * time.Minute).UTC().Format(time.RFC3339Nano),
+		"not a time",
+	} {
+		sig := s.Signature(DirectionInbound, MethodMessage, "", ts, []byte(`{}`))
+		err := s.Verify(context.Background(), DirectionInbound, MethodMessage, "", ts, sig, []byte(`{}`))
+		assert.Regexp(t, "FF10395", err)
+	}
+}
+
+func TestVerifyExpiresSeen(t *testing.T) {
+	s := NewSigner("key1", 1*time.Minute)
+	s.seen["old"] = true
+	s.expiry.PushBack(&seenSignature{signature: "old", expires: time.Now().Add(-1 * time.Second)})
+	s.seen["new"] = true
+	s.expiry.PushBack(&seenSignature{signature: "new", expires: time.Now().Add(1 * time.Minute)})
+	ts := time.Now().UTC().Format(time.RFC3339Nano)
+	err := s.Verify(context.Background(), DirectionInbound, "GET", "/", ts, s.Signature(DirectionInbound, "GET", "/", ts, nil), nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, s.seen, "old")
+	assert.Contains(t, s.seen, "new")
+	assert.Len(t, s.seen, 2)
+	assert.Equal(t, 2, s.expiry.Len())
+}
+
+func TestSignRequest(t *testing.T) {
+	s := NewSigner("key1", 1*time.Minute)
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:12345/api/v1/send?x=y", bytes.NewReader([]byte(`{"a":"b"}`)))
+	assert.NoError(t, err)
+	err = s.SignRequest(req)
+	assert.NoError(t, err)
+
+	ts := req.Header.Get(HeaderTimestamp)
+	err = s.Verify(context.Background(), DirectionOutbound, http.MethodPost, "/api/v1/send?x=y", ts, req.Header.Get(HeaderSignature), []byte(`{"a":"b"}`))
+	assert.NoError(t, err)
+}
+
+func TestSignRequestBodyWithoutGetBody(t *testing.T) {
+	s := NewSigner("key1", 1*time.Minute)
+	req, err := http.NewRequest(http.MethodPut, "http://localhost:12345/blobs", nil)
+	assert.NoError(t, err)
+	req.Body = ioutil.NopCloser(strings.NewReader("blob data"))
+	err = s.SignRequest(req)
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadAll(req.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "blob data", string(b))
+	expected := s.Signature(DirectionOutbound, http.MethodPut, "/blobs", req.Header.Get(HeaderTimestamp), b)
+	assert.Equal(t, expected, req.Header.Get(HeaderSignature))
+}
+
+func TestSignRequestNoBody(t *testing.T) {
+	s := NewSigner("key1", 1*time.Minute)
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:12345/status", nil)
+	assert.NoError(t, err)
+	err = s.SignRequest(req)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, req.Header.Get(HeaderSignature))
+}
+
+func TestSignRequestGetBodyFail(t *testing.T) {
+	s := NewSigner("key1", 1*time.Minute)
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:12345", nil)
+	assert.NoError(t, err)
+	req.GetBody = func() (io.ReadCloser, error) { return nil, fmt.Errorf("pop") }
+	err = s.SignRequest(req)
+	assert.Regexp(t, "pop", err)
+
+	req.GetBody = func() (io.ReadCloser, error) { return ioutil.NopCloser(&errReader{}), nil }
+	err = s.SignRequest(req)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestSignRequestReadBodyFail(t *testing.T) {
+	s := NewSigner("key1", 1*time.Minute)
+	req, err := http.NewRequest(http.MethodPost, "http://localhost:12345", nil)
+	assert.NoError(t, err)
+	req.Body = ioutil.NopCloser(&errReader{})
+	err = s.SignRequest(req)
+	assert.Regexp(t, "pop", err)
+}
+
+func TestSignMessageEnvelope(t *testing.T) {
+	s := NewSigner("key1", 1*time.Minute)
+	var envelope SignedMessage
+	err := json.Unmarshal(s.SignMessage([]byte(`[1,2,3]`)), &envelope)
+	assert.NoError(t, err)
+	assert.Equal(t, `[1,2,3]`, string(envelope.Payload))
+	assert.Equal(t, s.Signature(DirectionOutbound, MethodMessage, "", envelope.Timestamp, envelope.Payload), envelope.Signature)
+}
@@ -0,0 +1,179 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package smime signs and verifies the detached CMS (PKCS#7) signatures of S/MIME multipart/signed
+// entities, and encrypts S/MIME enveloped-data, as used by signed emails and AS2 messages. The CMS
+// structures are built and parsed by go.mozilla.org/pkcs7 - this package applies the S/MIME rules
+// on top, such as the signer certificate chaining to a trusted root.
+package smime
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"io/ioutil"
+	"mime/multipart"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+	"go.mozilla.org/pkcs7"
+)
+
+var (
+	digestAlgorithms = map[crypto.Hash]asn1.ObjectIdentifier{
+		crypto.SHA256: pkcs7.OIDDigestAlgorithmSHA256,
+		crypto.SHA384: pkcs7.OIDDigestAlgorithmSHA384,
+		crypto.SHA512: pkcs7.OIDDigestAlgorithmSHA512,
+	}
+	// MICAlgorithms are the names of the digests in the micalg parameter of a multipart/signed content type
+	MICAlgorithms = map[crypto.Hash]string{
+		crypto.SHA256: "sha-256",
+		crypto.SHA384: "sha-384",
+		crypto.SHA512: "sha-512",
+	}
+)
+
+func init() {
+	// The algorithm is a package setting of the pkcs7 library, rather than a parameter of Encrypt
+	pkcs7.ContentEncryptionAlgorithm = pkcs7.EncryptionAlgorithmAES256CBC
+}
+
+func digestHash(algorithm asn1.ObjectIdentifier) (crypto.Hash, bool) {
+	for hash, oid := range digestAlgorithms {
+		if algorithm.Equal(oid) {
+			return hash, true
+		}
+	}
+	return 0, false
+}
+
+// Sign returns a DER encoded detached CMS signature over the content, using SHA-256. The content type,
+// signing time, and message digest are signed attributes, and the signer's certificate is included.
+func Sign(ctx context.Context, content []byte, cert *x509.Certificate, key crypto.Signer) ([]byte, error) {
+	switch key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgSMIMEInvalid, "unsupported signing key type")
+	}
+	sd, err := pkcs7.NewSignedData(content)
+	if err != nil {
+		return nil, err
+	}
+	sd.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+	if err := sd.AddSigner(cert, key, pkcs7.SignerInfoConfig{}); err != nil {
+		return nil, err
+	}
+	sd.Detach()
+	return sd.Finish()
+}
+
+// Verify checks a DER encoded detached CMS signature over the content, and that the certificate of the
+// signer chains to one of the roots in the verify options - the other certificates in the signature are
+// used as intermediates. The verified certificate of the signer is returned, along with the digest
+// algorithm the signer used.
+func Verify(ctx context.Context, signature, content []byte, opts x509.VerifyOptions) (*x509.Certificate, crypto.Hash, error) {
+	p7, err := pkcs7.Parse(signature)
+	if err != nil {
+		return nil, 0, i18n.WrapError(ctx, err, i18n.MsgSMIMEInvalid, err)
+	}
+	if len(p7.Signers) != 1 {
+		return nil, 0, i18n.NewError(ctx, i18n.MsgSMIMEInvalid, "exactly one signer is required")
+	}
+	cert := p7.GetOnlySigner()
+	if cert == nil {
+		return nil, 0, i18n.NewError(ctx, i18n.MsgSMIMESignatureInvalid, "signer certificate not included")
+	}
+	si := p7.Signers[0]
+	hash, ok := digestHash(si.DigestAlgorithm.Algorithm)
+	if !ok {
+		return nil, 0, i18n.NewError(ctx, i18n.MsgSMIMESignatureInvalid, "unsupported digest algorithm "+si.DigestAlgorithm.Algorithm.String())
+	}
+	if len(si.AuthenticatedAttributes) > 0 {
+		var contentType asn1.ObjectIdentifier
+		if err := p7.UnmarshalSignedAttribute(pkcs7.OIDAttributeContentType, &contentType); err != nil || !contentType.Equal(pkcs7.OIDData) {
+			return nil, 0, i18n.NewError(ctx, i18n.MsgSMIMESignatureInvalid, "signed content type is not data")
+		}
+	}
+
+	// The signature is checked here, and the certificate chain below with the options of the caller
+	p7.Content = content
+	if err := p7.Verify(); err != nil {
+		return nil, 0, i18n.WrapError(ctx, err, i18n.MsgSMIMESignatureInvalid, err)
+	}
+	if opts.Intermediates == nil {
+		opts.Intermediates = x509.NewCertPool()
+	}
+	for _, c := range p7.Certificates {
+		opts.Intermediates.AddCert(c)
+	}
+	if _, err := cert.Verify(opts); err != nil {
+		return nil, 0, i18n.WrapError(ctx, err, i18n.MsgSMIMESignatureInvalid, err)
+	}
+	return cert, hash, nil
+}
+
+// ParseSigned splits the body of a multipart/signed entity into the signed content, which is the raw
+// first part including its MIME headers, and the decoded signature from the second part. The content
+// is returned in canonical form, with CRLF line endings, as that is the form that is signed.
+func ParseSigned(ctx context.Context, body []byte, boundary string) (content, signature []byte, err error) {
+	delimiter := []byte("--" + boundary)
+	start := bytes.Index(body, delimiter)
+	if boundary == "" || start < 0 || (start > 0 && body[start-1] != '\n') {
+		return nil, nil, i18n.NewError(ctx, i18n.MsgSMIMEInvalid, "missing multipart boundary")
+	}
+	start += len(delimiter)
+	lineEnd := bytes.IndexByte(body[start:], '\n')
+	if lineEnd < 0 {
+		return nil, nil, i18n.NewError(ctx, i18n.MsgSMIMEInvalid, "missing signed content")
+	}
+	start += lineEnd + 1
+	end := bytes.Index(body[start:], append([]byte("\n"), delimiter...))
+	if end < 0 {
+		return nil, nil, i18n.NewError(ctx, i18n.MsgSMIMEInvalid, "missing signature")
+	}
+	content = bytes.TrimSuffix(body[start:start+end], []byte("\r"))
+	content = bytes.ReplaceAll(bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n"))
+
+	mr := multipart.NewReader(bytes.NewReader(body), boundary)
+	var part *multipart.Part
+	for i := 0; i < 2 && err == nil; i++ {
+		part, err = mr.NextPart()
+	}
+	if err != nil {
+		return nil, nil, i18n.NewError(ctx, i18n.MsgSMIMEInvalid, "missing signature")
+	}
+	if signature, err = ioutil.ReadAll(part); err == nil && strings.EqualFold(part.Header.Get("Content-Transfer-Encoding"), "base64") {
+		signature, err = base64.StdEncoding.DecodeString(string(bytes.Join(bytes.Fields(signature), nil)))
+	}
+	if err != nil {
+		return nil, nil, i18n.WrapError(ctx, err, i18n.MsgSMIMEInvalid, err)
+	}
+	return content, signature, nil
+}
+
+// Encrypt returns DER encoded CMS enveloped-data of the content, encrypted with a random AES-256-CBC key
+// that is in turn encrypted for the RSA public key of the recipient's certificate
+func Encrypt(ctx context.Context, content []byte, recipient *x509.Certificate) ([]byte, error) {
+	if _, ok := recipient.PublicKey.(*rsa.PublicKey); !ok {
+		return nil, i18n.NewError(ctx, i18n.MsgSMIMEInvalid, "encryption requires an RSA recipient certificate")
+	}
+	return pkcs7.Encrypt(content, []*x509.Certificate{recipient})
+}
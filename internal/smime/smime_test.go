@@ -0,0 +1,293 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smime
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	mathrand "math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mozilla.org/pkcs7"
+)
+
+func newTestCert(t *testing.T, key crypto.Signer, parent *x509.Certificate, parentKey crypto.Signer, isCA bool, email string) *x509.Certificate {
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("test %s", serial)},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(1 * time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection},
+		SubjectKeyId:          serial.Bytes(),
+	}
+	if email != "" {
+		template.EmailAddresses = []string{email}
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), parentKey)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return cert
+}
+
+func newTestChain(t *testing.T) (roots *x509.CertPool, leaf *x509.Certificate, leafKey crypto.Signer) {
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ca := newTestCert(t, caKey, nil, nil, true, "")
+	leafKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+	leaf = newTestCert(t, leafKey, ca, caKey, false, "partner@example.com")
+	roots = x509.NewCertPool()
+	roots.AddCert(ca)
+	return roots, leaf, leafKey
+}
+
+func verifyOptions(roots *x509.CertPool) x509.VerifyOptions {
+	return x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection}}
+}
+
+// signWith builds a detached signature over "data" with the pkcs7 library directly, after fn adjusts it
+func signWith(t *testing.T, cert *x509.Certificate, key crypto.Signer, digest asn1.ObjectIdentifier, fn func(sd *pkcs7.SignedData)) []byte {
+	sd, err := pkcs7.NewSignedData([]byte("data"))
+	assert.NoError(t, err)
+	sd.SetDigestAlgorithm(digest)
+	fn(sd)
+	sd.Detach()
+	b, err := sd.Finish()
+	assert.NoError(t, err)
+	return b
+}
+
+// mutate makes a random change to a copy of b, for the fuzz tests
+func mutate(r *mathrand.Rand, b []byte) []byte {
+	b = append([]byte{}, b...)
+	for i := r.Intn(4); i >= 0 && len(b) > 0; i-- {
+		pos := r.Intn(len(b))
+		switch r.Intn(3) {
+		case 0:
+			b[pos] = byte(r.Intn(256))
+		case 1:
+			b = append(b[:pos], b[pos+1:]...)
+		default:
+			b = append(b[:pos], append([]byte{byte(r.Intn(256))}, b[pos:]...)...)
+		}
+	}
+	return b
+}
+
+func TestSignVerifyRSA(t *testing.T) {
+	roots, leaf, leafKey := newTestChain(t)
+	content := []byte("Content-Type: text/plain\r\n\r\nhello\r\n")
+
+	sig, err := Sign(context.Background(), content, leaf, leafKey)
+	assert.NoError(t, err)
+
+	signer, hash, err := Verify(context.Background(), sig, content, verifyOptions(roots))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"partner@example.com"}, signer.EmailAddresses)
+	assert.Equal(t, crypto.SHA256, hash)
+
+	_, _, err = Verify(context.Background(), sig, []byte("tampered"), verifyOptions(roots))
+	assert.Regexp(t, "FF10487.*digest mismatch", err)
+
+	_, _, err = Verify(context.Background(), sig, content, verifyOptions(x509.NewCertPool()))
+	assert.Regexp(t, "FF10487.*unknown authority", err)
+}
+
+func TestSignVerifyECDSASelfSigned(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	cert := newTestCert(t, key, nil, nil, false, "")
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+
+	sig, err := Sign(context.Background(), []byte("data"), cert, key)
+	assert.NoError(t, err)
+	signer, _, err := Verify(context.Background(), sig, []byte("data"), verifyOptions(roots))
+	assert.NoError(t, err)
+	assert.Equal(t, cert.Raw, signer.Raw)
+}
+
+func TestSignUnsupportedKey(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	_, err := Sign(context.Background(), []byte("data"), &x509.Certificate{}, key)
+	assert.Regexp(t, "FF10486", err)
+}
+
+func TestSignFail(t *testing.T) {
+	_, leaf, leafKey := newTestChain(t)
+	_, err := Sign(context.Background(), []byte("data"), &x509.Certificate{}, leafKey)
+	assert.Error(t, err)
+
+	tooSmall := &rsa.PrivateKey{PublicKey: rsa.PublicKey{N: big.NewInt(3233), E: 17}, D: big.NewInt(2753)}
+	_, err = Sign(context.Background(), []byte("data"), leaf, tooSmall)
+	assert.Error(t, err)
+}
+
+func TestVerifyWithoutSignedAttributes(t *testing.T) {
+	roots, leaf, leafKey := newTestChain(t)
+	sig := signWith(t, leaf, leafKey, pkcs7.OIDDigestAlgorithmSHA512, func(sd *pkcs7.SignedData) {
+		assert.NoError(t, sd.SignWithoutAttr(leaf, leafKey, pkcs7.SignerInfoConfig{}))
+	})
+	_, hash, err := Verify(context.Background(), sig, []byte("data"), verifyOptions(roots))
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.SHA512, hash)
+}
+
+func TestVerifyInvalid(t *testing.T) {
+	roots, leaf, leafKey := newTestChain(t)
+	enveloped, err := Encrypt(context.Background(), []byte("data"), leaf)
+	assert.NoError(t, err)
+	addSigner := func(sd *pkcs7.SignedData) {
+		assert.NoError(t, sd.AddSigner(leaf, leafKey, pkcs7.SignerInfoConfig{}))
+	}
+
+	for _, test := range []struct {
+		sig []byte
+		err string
+	}{
+		{[]byte("!asn1"), "FF10486"},
+		{enveloped, "FF10486.*one signer"},
+		{signWith(t, leaf, leafKey, pkcs7.OIDDigestAlgorithmSHA256, func(sd *pkcs7.SignedData) {
+			addSigner(sd)
+			addSigner(sd)
+		}), "FF10486.*one signer"},
+		{signWith(t, leaf, leafKey, pkcs7.OIDDigestAlgorithmSHA256, func(sd *pkcs7.SignedData) {
+			addSigner(sd)
+			sd.GetSignedData().SignerInfos[0].IssuerAndSerialNumber.SerialNumber = big.NewInt(1)
+		}), "FF10487.*not included"},
+		{signWith(t, leaf, leafKey, pkcs7.OIDDigestAlgorithmSHA1, addSigner), "FF10487.*unsupported digest"},
+		{signWith(t, leaf, leafKey, pkcs7.OIDDigestAlgorithmSHA256, func(sd *pkcs7.SignedData) {
+			sd.GetSignedData().ContentInfo.ContentType = pkcs7.OIDSignedData
+			addSigner(sd)
+		}), "FF10487.*content type"},
+		{signWith(t, leaf, leafKey, pkcs7.OIDDigestAlgorithmSHA256, func(sd *pkcs7.SignedData) {
+			addSigner(sd)
+			sd.GetSignedData().SignerInfos[0].EncryptedDigest = []byte("wrong")
+		}), "FF10487"},
+	} {
+		_, _, err := Verify(context.Background(), test.sig, []byte("data"), verifyOptions(roots))
+		assert.Regexp(t, test.err, err)
+	}
+}
+
+func TestVerifyFuzz(t *testing.T) {
+	roots, leaf, leafKey := newTestChain(t)
+	sig, err := Sign(context.Background(), []byte("data"), leaf, leafKey)
+	assert.NoError(t, err)
+
+	r := mathrand.New(mathrand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		mutated := mutate(r, sig)
+		assert.NotPanics(t, func() {
+			_, _, _ = Verify(context.Background(), mutated, []byte("data"), verifyOptions(roots))
+		})
+	}
+}
+
+func TestParseSigned(t *testing.T) {
+	sig := base64.StdEncoding.EncodeToString([]byte("signature"))
+	body := "preamble\n" +
+		"--b1\n" +
+		"Content-Type: text/plain\n" +
+		"\n" +
+		"hello\n" +
+		"\n" +
+		"--b1\n" +
+		"Content-Type: application/pkcs7-signature\n" +
+		"Content-Transfer-Encoding: base64\n" +
+		"\n" +
+		sig[:4] + "\n" + sig[4:] + "\n" +
+		"--b1--\n"
+	content, signature, err := ParseSigned(context.Background(), []byte(body), "b1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Content-Type: text/plain\r\n\r\nhello\r\n", string(content))
+	assert.Equal(t, "signature", string(signature))
+
+	for _, test := range []struct {
+		body     string
+		boundary string
+		err      string
+	}{
+		{body, "", "FF10486.*boundary"},
+		{"x--b1\r\n", "b1", "FF10486.*boundary"},
+		{"--b1", "b1", "FF10486.*signed content"},
+		{"--b1\r\ncontent\r\n", "b1", "FF10486.*missing signature"},
+		{"--b1\r\n\r\ncontent\r\n--b1--\r\n", "b1", "FF10486.*missing signature"},
+		{"--b1\r\n\r\ncontent\r\n--b1\r\nContent-Transfer-Encoding: base64\r\n\r\n!!!\r\n--b1--\r\n", "b1", "FF10486"},
+	} {
+		_, _, err := ParseSigned(context.Background(), []byte(test.body), test.boundary)
+		assert.Regexp(t, test.err, err)
+	}
+}
+
+func TestParseSignedFuzz(t *testing.T) {
+	seeds := []string{
+		"--b1\r\nContent-Type: text/plain\r\n\r\nhello\r\n--b1\r\nContent-Transfer-Encoding: base64\r\n\r\nc2lnbmF0dXJl\r\n--b1--\r\n",
+		"preamble\n--b1\n\nhello\n\n--b1\nContent-Type: application/pkcs7-signature\n\nsignature\n--b1--\n",
+	}
+	r := mathrand.New(mathrand.NewSource(1))
+	for i := 0; i < 5000; i++ {
+		body := mutate(r, []byte(seeds[i%len(seeds)]))
+		assert.NotPanics(t, func() {
+			content, _, err := ParseSigned(context.Background(), body, "b1")
+			if err == nil {
+				// the signed content is always canonicalized to CRLF line endings
+				assert.NotContains(t, string(bytes.ReplaceAll(content, []byte("\r\n"), nil)), "\n")
+			}
+		})
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	_, leaf, leafKey := newTestChain(t)
+
+	for _, content := range []string{"", "hello", "0123456789abcdef"} {
+		enveloped, err := Encrypt(context.Background(), []byte(content), leaf)
+		assert.NoError(t, err)
+		p7, err := pkcs7.Parse(enveloped)
+		assert.NoError(t, err)
+		decrypted, err := p7.Decrypt(leaf, leafKey)
+		assert.NoError(t, err)
+		assert.Equal(t, content, string(decrypted))
+	}
+}
+
+func TestEncryptNotRSA(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	cert := newTestCert(t, key, nil, nil, false, "")
+	_, err := Encrypt(context.Background(), []byte("data"), cert)
+	assert.Regexp(t, "FF10486.*RSA", err)
+}
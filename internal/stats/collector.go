@@ -0,0 +1,271 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// eventWindowHours is the number of hourly buckets counted for the events summary, including the current hour
+const eventWindowHours = 24
+
+// Collector maintains a pre-aggregated status summary for each namespace, so that dashboards
+// can load a summary in a single call without running a set of queries on every page load
+type Collector interface {
+	GetSummary(ctx context.Context, ns string) (*fftypes.NamespaceStatusSummary, error)
+	WaitStop()
+}
+
+type collector struct {
+	ctx        context.Context
+	database   database.Plugin
+	interval   time.Duration
+	mux        sync.Mutex
+	namespaces map[string]*namespaceStats
+	done       chan struct{}
+}
+
+// namespaceStats holds the state for the summary of one namespace.
+// Events are counted incrementally into hourly buckets, by counting only those events after the
+// last sequence seen in each refresh. The other counts change as existing records are updated,
+// so they are re-counted in each refresh.
+type namespaceStats struct {
+	namespace    string
+	lastEventSeq int64
+	eventBuckets map[int64]int64
+	summary      *fftypes.NamespaceStatusSummary
+}
+
+func NewCollector(ctx context.Context, di database.Plugin) Collector {
+	c := &collector{
+		ctx:        log.WithLogger(ctx, log.L(ctx).WithField("role", "stats-collector")),
+		database:   di,
+		interval:   config.GetDuration(config.StatusSummaryInterval),
+		namespaces: make(map[string]*namespaceStats),
+		done:       make(chan struct{}),
+	}
+	go c.refreshLoop()
+	return c
+}
+
+// GetSummary returns the latest summary for the namespace. The first request for a namespace
+// calculates the baseline, after which the summary is refreshed in the background.
+func (c *collector) GetSummary(ctx context.Context, ns string) (*fftypes.NamespaceStatusSummary, error) {
+	c.mux.Lock()
+	nss, ok := c.namespaces[ns]
+	c.mux.Unlock()
+	if !ok {
+		nss = &namespaceStats{
+			namespace:    ns,
+			eventBuckets: make(map[int64]int64),
+		}
+		if err := c.baseline(ctx, nss); err != nil {
+			return nil, err
+		}
+		if err := c.refresh(ctx, nss); err != nil {
+			return nil, err
+		}
+		c.mux.Lock()
+		c.namespaces[ns] = nss
+		c.mux.Unlock()
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return nss.summary, nil
+}
+
+func (c *collector) WaitStop() {
+	<-c.done
+}
+
+func (c *collector) refreshLoop() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			log.L(c.ctx).Debugf("Stats collector loop exiting")
+			return
+		case <-ticker.C:
+		}
+		c.mux.Lock()
+		namespaces := make([]*namespaceStats, 0, len(c.namespaces))
+		for _, nss := range c.namespaces {
+			namespaces = append(namespaces, nss)
+		}
+		c.mux.Unlock()
+		for _, nss := range namespaces {
+			if err := c.refresh(c.ctx, nss); err != nil {
+				log.L(c.ctx).Errorf("Failed to refresh status summary for namespace '%s': %s", nss.namespace, err)
+			}
+		}
+	}
+}
+
+func hourStart(t time.Time) int64 {
+	return t.Truncate(time.Hour).Unix()
+}
+
+func totalCount(res *database.FilterResult, err error) (int64, error) {
+	if err != nil || res == nil || res.TotalCount == nil {
+		return 0, err
+	}
+	return *res.TotalCount, nil
+}
+
+// baseline counts the events already in each hourly bucket, up to the latest event sequence
+func (c *collector) baseline(ctx context.Context, nss *namespaceStats) error {
+	fb := database.EventQueryFactory.NewFilterLimit(ctx, 1)
+	latest, _, err := c.database.GetEvents(ctx, fb.And(fb.Eq("namespace", nss.namespace)).Sort("sequence").Descending())
+	if err != nil {
+		return err
+	}
+	nss.lastEventSeq = -1
+	if len(latest) > 0 {
+		nss.lastEventSeq = latest[0].Sequence
+	}
+
+	current := time.Now().Truncate(time.Hour)
+	for i := 0; i < eventWindowHours; i++ {
+		start := current.Add(time.Duration(-i) * time.Hour)
+		fb := database.EventQueryFactory.NewFilterLimit(ctx, 1)
+		_, res, err := c.database.GetEvents(ctx, fb.And(
+			fb.Eq("namespace", nss.namespace),
+			fb.Gte("created", fftypes.FFTime(start)),
+			fb.Lt("created", fftypes.FFTime(start.Add(time.Hour))),
+			fb.Lte("sequence", nss.lastEventSeq),
+		).Count(true))
+		count, err := totalCount(res, err)
+		if err != nil {
+			return err
+		}
+		nss.eventBuckets[start.Unix()] = count
+	}
+	return nil
+}
+
+// countNewEvents adds the events since the last refresh to the current hourly bucket
+func (c *collector) countNewEvents(ctx context.Context, nss *namespaceStats, now time.Time) error {
+	fb := database.EventQueryFactory.NewFilterLimit(ctx, 1)
+	latest, res, err := c.database.GetEvents(ctx, fb.And(
+		fb.Eq("namespace", nss.namespace),
+		fb.Gt("sequence", nss.lastEventSeq),
+	).Sort("sequence").Descending().Count(true))
+	count, err := totalCount(res, err)
+	if err != nil {
+		return err
+	}
+	if len(latest) > 0 {
+		nss.lastEventSeq = latest[0].Sequence
+		nss.eventBuckets[hourStart(now)] += count
+	}
+	windowStart := hourStart(now.Add(-(eventWindowHours - 1) * time.Hour))
+	for bucket := range nss.eventBuckets {
+		if bucket < windowStart {
+			delete(nss.eventBuckets, bucket)
+		}
+	}
+	return nil
+}
+
+func (c *collector) countSubscriptionLag(ctx context.Context, ns string) ([]*fftypes.SubscriptionLag, error) {
+	fb := database.SubscriptionQueryFactory.NewFilter(ctx)
+	subs, _, err := c.database.GetSubscriptions(ctx, fb.And(fb.Eq("namespace", ns)))
+	if err != nil {
+		return nil, err
+	}
+	lags := make([]*fftypes.SubscriptionLag, 0, len(subs))
+	for _, sub := range subs {
+		offset, err := c.database.GetOffset(ctx, fftypes.OffsetTypeSubscription, sub.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		if offset == nil {
+			// The subscription has not started delivering events yet
+			continue
+		}
+		fb := database.EventQueryFactory.NewFilterLimit(ctx, 1)
+		_, res, err := c.database.GetEvents(ctx, fb.And(
+			fb.Eq("namespace", ns),
+			fb.Gt("sequence", offset.Current),
+		).Count(true))
+		lag, err := totalCount(res, err)
+		if err != nil {
+			return nil, err
+		}
+		lags = append(lags, &fftypes.SubscriptionLag{
+			ID:     sub.ID,
+			Name:   sub.Name,
+			Offset: offset.Current,
+			Lag:    lag,
+		})
+	}
+	return lags, nil
+}
+
+func (c *collector) refresh(ctx context.Context, nss *namespaceStats) error {
+	now := time.Now()
+	summary := &fftypes.NamespaceStatusSummary{
+		Namespace: nss.namespace,
+		Messages:  make(map[fftypes.MessageState]int64),
+	}
+
+	for _, state := range fftypes.FFEnumValues("messagestate") {
+		fb := database.MessageQueryFactory.NewFilterLimit(ctx, 1)
+		_, res, err := c.database.GetMessages(ctx, fb.And(
+			fb.Eq("namespace", nss.namespace),
+			fb.Eq("state", state),
+		).Count(true))
+		if summary.Messages[fftypes.MessageState(state.(string))], err = totalCount(res, err); err != nil {
+			return err
+		}
+	}
+
+	if err := c.countNewEvents(ctx, nss, now); err != nil {
+		return err
+	}
+	for _, count := range nss.eventBuckets {
+		summary.EventsLast24h += count
+	}
+
+	fb := database.OperationQueryFactory.NewFilterLimit(ctx, 1)
+	_, res, err := c.database.GetOperations(ctx, fb.And(
+		fb.Eq("namespace", nss.namespace),
+		fb.Eq("status", fftypes.OpStatusPending),
+	).Count(true))
+	if summary.PendingOperations, err = totalCount(res, err); err != nil {
+		return err
+	}
+
+	if summary.Subscriptions, err = c.countSubscriptionLag(ctx, nss.namespace); err != nil {
+		return err
+	}
+
+	summary.Updated = fftypes.Now()
+	c.mux.Lock()
+	nss.summary = summary
+	c.mux.Unlock()
+	return nil
+}
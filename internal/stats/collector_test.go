@@ -0,0 +1,283 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestCollector(t *testing.T) (*collector, *databasemocks.Plugin, func()) {
+	config.Reset()
+	config.Set(config.StatusSummaryInterval, "1h")
+	mdi := &databasemocks.Plugin{}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewCollector(ctx, mdi).(*collector)
+	return c, mdi, func() {
+		cancel()
+		c.WaitStop()
+	}
+}
+
+func counted(count int64) *database.FilterResult {
+	return &database.FilterResult{TotalCount: &count}
+}
+
+func filterHas(field string) interface{} {
+	return mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		for _, c := range fi.Children {
+			if c.Field == field {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func filterCounts() interface{} {
+	return mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Count
+	})
+}
+
+func mockRefresh(mdi *databasemocks.Plugin, subs []*fftypes.Subscription) {
+	mdi.On("GetMessages", mock.Anything, filterHas("state")).Return([]*fftypes.Message{}, counted(2), nil)
+	mdi.On("GetEvents", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return fi.Count && len(fi.Sort) > 0
+	})).Return([]*fftypes.Event{{Sequence: 110}}, counted(10), nil).Once()
+	mdi.On("GetOperations", mock.Anything, filterHas("status")).Return([]*fftypes.Operation{}, counted(3), nil)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return(subs, nil, nil)
+}
+
+func TestGetSummary(t *testing.T) {
+	c, mdi, done := newTestCollector(t)
+	defer done()
+
+	sub1 := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	sub2 := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub2"}}
+
+	// Baseline
+	mdi.On("GetEvents", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return !fi.Count && len(fi.Sort) > 0
+	})).Return([]*fftypes.Event{{Sequence: 100}}, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, filterHas("created")).Return([]*fftypes.Event{}, counted(1), nil).Times(eventWindowHours)
+	// Refresh
+	mockRefresh(mdi, []*fftypes.Subscription{sub1, sub2})
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub1.ID.String()).Return(&fftypes.Offset{Current: 105}, nil)
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub2.ID.String()).Return(nil, nil)
+	mdi.On("GetEvents", mock.Anything, filterCounts()).Return([]*fftypes.Event{}, counted(5), nil).Once()
+
+	summary, err := c.GetSummary(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", summary.Namespace)
+	assert.Equal(t, int64(2), summary.Messages[fftypes.MessageStateConfirmed])
+	assert.Len(t, summary.Messages, len(fftypes.FFEnumValues("messagestate")))
+	assert.Equal(t, int64(eventWindowHours+10), summary.EventsLast24h)
+	assert.Equal(t, int64(3), summary.PendingOperations)
+	assert.Equal(t, []*fftypes.SubscriptionLag{
+		{ID: sub1.ID, Name: "sub1", Offset: 105, Lag: 5},
+	}, summary.Subscriptions)
+	assert.Equal(t, int64(110), c.namespaces["ns1"].lastEventSeq)
+
+	// Served from the pre-aggregated summary
+	summary2, err := c.GetSummary(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, summary, summary2)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetSummaryNoEvents(t *testing.T) {
+	c, mdi, done := newTestCollector(t)
+	defer done()
+
+	mdi.On("GetEvents", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return !fi.Count && len(fi.Sort) > 0
+	})).Return([]*fftypes.Event{}, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, filterHas("created")).Return([]*fftypes.Event{}, counted(0), nil).Times(eventWindowHours)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, counted(0), nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, counted(0), nil).Once()
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, counted(0), nil)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{}, nil, nil)
+
+	summary, err := c.GetSummary(context.Background(), "ns1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), summary.EventsLast24h)
+	assert.Equal(t, int64(-1), c.namespaces["ns1"].lastEventSeq)
+	assert.Empty(t, summary.Subscriptions)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestGetSummaryBaselineLatestFail(t *testing.T) {
+	c, mdi, done := newTestCollector(t)
+	defer done()
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := c.GetSummary(context.Background(), "ns1")
+	assert.EqualError(t, err, "pop")
+	assert.Empty(t, c.namespaces)
+}
+
+func TestGetSummaryBaselineCountFail(t *testing.T) {
+	c, mdi, done := newTestCollector(t)
+	defer done()
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, nil, nil).Once()
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := c.GetSummary(context.Background(), "ns1")
+	assert.EqualError(t, err, "pop")
+}
+
+func TestGetSummaryRefreshFail(t *testing.T) {
+	c, mdi, done := newTestCollector(t)
+	defer done()
+
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, counted(0), nil)
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	_, err := c.GetSummary(context.Background(), "ns1")
+	assert.EqualError(t, err, "pop")
+	assert.Empty(t, c.namespaces)
+}
+
+func newBaselinedStats() *namespaceStats {
+	return &namespaceStats{
+		namespace:    "ns1",
+		lastEventSeq: 100,
+		eventBuckets: map[int64]int64{
+			hourStart(time.Now().Add(-48 * time.Hour)): 1000,
+		},
+	}
+}
+
+func TestRefreshMessagesFail(t *testing.T) {
+	c, mdi, done := newTestCollector(t)
+	defer done()
+
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := c.refresh(context.Background(), newBaselinedStats())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRefreshEventsFail(t *testing.T) {
+	c, mdi, done := newTestCollector(t)
+	defer done()
+
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, counted(0), nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := c.refresh(context.Background(), newBaselinedStats())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRefreshOperationsFail(t *testing.T) {
+	c, mdi, done := newTestCollector(t)
+	defer done()
+
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, counted(0), nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, counted(0), nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	nss := newBaselinedStats()
+	err := c.refresh(context.Background(), nss)
+	assert.EqualError(t, err, "pop")
+	assert.Empty(t, nss.eventBuckets) // expired bucket removed
+}
+
+func TestRefreshSubscriptionsFail(t *testing.T) {
+	c, mdi, done := newTestCollector(t)
+	defer done()
+
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, counted(0), nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, counted(0), nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, counted(0), nil)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := c.refresh(context.Background(), newBaselinedStats())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRefreshOffsetFail(t *testing.T) {
+	c, mdi, done := newTestCollector(t)
+	defer done()
+
+	sub1 := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, counted(0), nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, counted(0), nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, counted(0), nil)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{sub1}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub1.ID.String()).Return(nil, fmt.Errorf("pop"))
+	err := c.refresh(context.Background(), newBaselinedStats())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRefreshLagFail(t *testing.T) {
+	c, mdi, done := newTestCollector(t)
+	defer done()
+
+	sub1 := &fftypes.Subscription{SubscriptionRef: fftypes.SubscriptionRef{ID: fftypes.NewUUID(), Name: "sub1"}}
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return([]*fftypes.Message{}, counted(0), nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return([]*fftypes.Event{}, counted(0), nil).Once()
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, counted(0), nil)
+	mdi.On("GetSubscriptions", mock.Anything, mock.Anything).Return([]*fftypes.Subscription{sub1}, nil, nil)
+	mdi.On("GetOffset", mock.Anything, fftypes.OffsetTypeSubscription, sub1.ID.String()).Return(&fftypes.Offset{Current: 1}, nil)
+	mdi.On("GetEvents", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := c.refresh(context.Background(), newBaselinedStats())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestRefreshLoop(t *testing.T) {
+	config.Reset()
+	config.Set(config.StatusSummaryInterval, "1ms")
+	mdi := &databasemocks.Plugin{}
+	ctx, cancel := context.WithCancel(context.Background())
+	refreshed := make(chan struct{})
+	mdi.On("GetMessages", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop")).Run(func(args mock.Arguments) {
+		select {
+		case refreshed <- struct{}{}:
+		default:
+		}
+	})
+	c := &collector{
+		ctx:        ctx,
+		database:   mdi,
+		interval:   config.GetDuration(config.StatusSummaryInterval),
+		namespaces: map[string]*namespaceStats{"ns1": newBaselinedStats()},
+		done:       make(chan struct{}),
+	}
+	go c.refreshLoop()
+	<-refreshed
+	cancel()
+	c.WaitStop()
+}
+
+func TestTotalCountNoResult(t *testing.T) {
+	count, err := totalCount(nil, nil)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
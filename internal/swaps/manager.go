@@ -0,0 +1,413 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swaps
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/assets"
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Manager coordinates swaps of tokens between two parties, using an escrow key owned by this node.
+// Each party deposits their leg into the escrow. Once both deposits are confirmed, each leg is released
+// to its recipient. If the swap times out first, any deposited legs are refunded to their depositors.
+type Manager interface {
+	CreateSwap(ctx context.Context, ns string, input *fftypes.SwapInput) (*fftypes.Swap, error)
+	GetSwaps(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Swap, *database.FilterResult, error)
+	GetSwapByID(ctx context.Context, ns, id string) (*fftypes.Swap, error)
+	WaitStop()
+}
+
+type swapManager struct {
+	ctx            context.Context
+	database       database.Plugin
+	identity       identity.Manager
+	assets         assets.Manager
+	escrowKey      string
+	pollInterval   time.Duration
+	defaultTimeout time.Duration
+	done           chan struct{}
+}
+
+func NewSwapManager(ctx context.Context, di database.Plugin, im identity.Manager, am assets.Manager) (Manager, error) {
+	if di == nil || im == nil || am == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInitializationNilDepError)
+	}
+	sm := &swapManager{
+		ctx:            log.WithLogger(ctx, log.L(ctx).WithField("role", "swaps")),
+		database:       di,
+		identity:       im,
+		assets:         am,
+		escrowKey:      config.GetString(config.SwapsEscrowKey),
+		pollInterval:   config.GetDuration(config.SwapsPollInterval),
+		defaultTimeout: config.GetDuration(config.SwapsDefaultTimeout),
+		done:           make(chan struct{}),
+	}
+	go sm.swapLoop()
+	return sm, nil
+}
+
+func (sm *swapManager) WaitStop() {
+	<-sm.done
+}
+
+func (sm *swapManager) swapLoop() {
+	defer close(sm.done)
+	for {
+		select {
+		case <-time.After(sm.pollInterval):
+			sm.processActiveSwaps(sm.ctx)
+		case <-sm.ctx.Done():
+			log.L(sm.ctx).Debugf("Swap manager exiting")
+			return
+		}
+	}
+}
+
+func (sm *swapManager) GetSwaps(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Swap, *database.FilterResult, error) {
+	return sm.database.GetSwaps(ctx, filter.Condition(filter.Builder().Eq("namespace", ns)))
+}
+
+func (sm *swapManager) GetSwapByID(ctx context.Context, ns, id string) (*fftypes.Swap, error) {
+	u, err := fftypes.ParseUUID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	swap, err := sm.database.GetSwapByID(ctx, u)
+	if err != nil || swap == nil || swap.Namespace != ns {
+		return nil, err
+	}
+	return swap, nil
+}
+
+func (sm *swapManager) resolveLeg(ctx context.Context, ns, name string, input *fftypes.SwapLegInput) (leg *fftypes.SwapLeg, err error) {
+	if input.Pool == "" || input.From == "" || input.To == "" || input.Amount.Int().Sign() <= 0 {
+		return nil, i18n.NewError(ctx, i18n.MsgSwapLegInvalid, name)
+	}
+	pool, err := sm.assets.GetTokenPoolByNameOrID(ctx, ns, input.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if pool.State != fftypes.TokenPoolStateConfirmed {
+		return nil, i18n.NewError(ctx, i18n.MsgTokenPoolNotConfirmed)
+	}
+	leg = &fftypes.SwapLeg{
+		Pool:       pool.ID,
+		Connector:  pool.Connector,
+		TokenIndex: input.TokenIndex,
+		Amount:     input.Amount,
+	}
+	if leg.From, err = sm.identity.NormalizeSigningKey(ctx, input.From, identity.KeyNormalizationBlockchainPlugin); err != nil {
+		return nil, err
+	}
+	if leg.To, err = sm.identity.NormalizeSigningKey(ctx, input.To, identity.KeyNormalizationBlockchainPlugin); err != nil {
+		return nil, err
+	}
+	return leg, nil
+}
+
+func (sm *swapManager) resolveEscrowKey(ctx context.Context) (string, error) {
+	if sm.escrowKey != "" {
+		return sm.identity.NormalizeSigningKey(ctx, sm.escrowKey, identity.KeyNormalizationBlockchainPlugin)
+	}
+	verifier, err := sm.identity.GetNodeOwnerBlockchainKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	return verifier.Value, nil
+}
+
+func (sm *swapManager) CreateSwap(ctx context.Context, ns string, input *fftypes.SwapInput) (swap *fftypes.Swap, err error) {
+	swap = &fftypes.Swap{
+		ID:        fftypes.NewUUID(),
+		Namespace: ns,
+		State:     fftypes.SwapStateAwaitingDeposits,
+	}
+	if swap.Initiator, err = sm.resolveLeg(ctx, ns, "initiator", &input.Initiator); err != nil {
+		return nil, err
+	}
+	if swap.Counterparty, err = sm.resolveLeg(ctx, ns, "counterparty", &input.Counterparty); err != nil {
+		return nil, err
+	}
+	if swap.Escrow, err = sm.resolveEscrowKey(ctx); err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(input.Timeout)
+	if timeout <= 0 {
+		timeout = sm.defaultTimeout
+	}
+	swap.Expires = fftypes.UnixTime(time.Now().Add(timeout).Unix())
+
+	// The swap is stored before the initiator deposit is submitted, so that the deposit is never
+	// made into the escrow without a swap to release or refund it
+	if err = sm.database.InsertSwap(ctx, swap); err != nil {
+		return nil, err
+	}
+
+	leg := swap.Initiator
+	transfer, err := sm.submitTransfer(ctx, swap, leg, leg.From, swap.Escrow)
+	if err != nil {
+		log.L(ctx).Errorf("Failed to deposit initiator leg of swap '%s': %s", swap.ID, err)
+		swap.State = fftypes.SwapStateFailed
+		swap.Error = err.Error()
+		if updateErr := sm.database.UpdateSwap(ctx, swap); updateErr != nil {
+			log.L(ctx).Errorf("Failed to record failure of swap '%s': %s", swap.ID, updateErr)
+		}
+		return nil, err
+	}
+	leg.Deposit = transfer.LocalID
+	leg.DepositTX = transfer.TX.ID
+	if err = sm.database.UpdateSwap(ctx, swap); err != nil {
+		return nil, err
+	}
+	return swap, nil
+}
+
+func (sm *swapManager) submitTransfer(ctx context.Context, swap *fftypes.Swap, leg *fftypes.SwapLeg, from, to string) (*fftypes.TokenTransfer, error) {
+	return sm.assets.TransferTokens(ctx, swap.Namespace, &fftypes.TokenTransferInput{
+		TokenTransfer: fftypes.TokenTransfer{
+			Type:       fftypes.TokenTransferTypeTransfer,
+			Connector:  leg.Connector,
+			TokenIndex: leg.TokenIndex,
+			Key:        from,
+			From:       from,
+			To:         to,
+			Amount:     leg.Amount,
+		},
+		Pool: leg.Pool.String(),
+	}, false)
+}
+
+func (sm *swapManager) processActiveSwaps(ctx context.Context) {
+	fb := database.SwapQueryFactory.NewFilter(ctx)
+	active, _, err := sm.database.GetSwaps(ctx, fb.And(
+		fb.In("state", []driver.Value{
+			fftypes.SwapStateAwaitingDeposits,
+			fftypes.SwapStateReleasing,
+			fftypes.SwapStateRefunding,
+		}),
+	).Sort("created").Ascending())
+	if err != nil {
+		log.L(ctx).Errorf("Failed to query active swaps: %s", err)
+		return
+	}
+	if len(active) == 0 {
+		return
+	}
+
+	claimed, err := sm.claimedDeposits(ctx, active[0].Created)
+	if err != nil {
+		log.L(ctx).Errorf("Failed to query claimed swap deposits: %s", err)
+		return
+	}
+	for _, swap := range active {
+		if err := sm.processSwap(ctx, swap, claimed); err != nil {
+			log.L(ctx).Errorf("Failed to process swap '%s': %s", swap.ID, err)
+		}
+	}
+}
+
+// claimedDeposits returns the transfers already matched as deposits into a swap, so that each transfer
+// into the escrow is only ever used for one swap. Any transfer that could match an active swap was created
+// after the oldest active swap, so any swap that claimed it was last updated after that point.
+func (sm *swapManager) claimedDeposits(ctx context.Context, since *fftypes.FFTime) (map[fftypes.UUID]bool, error) {
+	fb := database.SwapQueryFactory.NewFilter(ctx)
+	swaps, _, err := sm.database.GetSwaps(ctx, fb.Or(
+		fb.Gte("created", since),
+		fb.Gte("updated", since),
+	))
+	if err != nil {
+		return nil, err
+	}
+	claimed := make(map[fftypes.UUID]bool)
+	for _, swap := range swaps {
+		for _, leg := range swap.Legs() {
+			if leg.Deposit != nil {
+				claimed[*leg.Deposit] = true
+			}
+		}
+	}
+	return claimed, nil
+}
+
+func (sm *swapManager) processSwap(ctx context.Context, swap *fftypes.Swap, claimed map[fftypes.UUID]bool) (err error) {
+	origState := swap.State
+	changed := false
+	if swap.State != fftypes.SwapStateReleasing && swap.Counterparty.Deposit == nil {
+		// Deposits that arrive while refunding are still matched, so that they are returned
+		if changed, err = sm.matchDeposit(ctx, swap, swap.Counterparty, claimed); err != nil {
+			return err
+		}
+	}
+
+	var legChanged bool
+	switch swap.State {
+	case fftypes.SwapStateAwaitingDeposits:
+		deposited := true
+		for _, leg := range swap.Legs() {
+			confirmed, _, err := sm.transferStatus(ctx, leg.Deposit, leg.DepositTX)
+			if err != nil {
+				return err
+			}
+			deposited = deposited && confirmed
+		}
+		switch {
+		case deposited:
+			swap.State = fftypes.SwapStateReleasing
+		case time.Now().After(*swap.Expires.Time()):
+			swap.State = fftypes.SwapStateRefunding
+		}
+	case fftypes.SwapStateReleasing:
+		var complete bool
+		if complete, legChanged, err = sm.releaseLegs(ctx, swap, false); err != nil {
+			return err
+		}
+		if complete {
+			swap.State = fftypes.SwapStateCompleted
+		}
+	case fftypes.SwapStateRefunding:
+		var complete bool
+		if complete, legChanged, err = sm.releaseLegs(ctx, swap, true); err != nil {
+			return err
+		}
+		if complete {
+			swap.State = fftypes.SwapStateRefunded
+		}
+	}
+
+	if changed || legChanged || swap.State != origState {
+		if swap.State != origState {
+			log.L(ctx).Infof("Swap '%s' moved from %s to %s", swap.ID, origState, swap.State)
+		}
+		return sm.database.UpdateSwap(ctx, swap)
+	}
+	return nil
+}
+
+// matchDeposit looks for an unclaimed transfer of the leg into the escrow, made after the swap was created
+func (sm *swapManager) matchDeposit(ctx context.Context, swap *fftypes.Swap, leg *fftypes.SwapLeg, claimed map[fftypes.UUID]bool) (bool, error) {
+	fb := database.TokenTransferQueryFactory.NewFilter(ctx)
+	transfers, _, err := sm.database.GetTokenTransfers(ctx, fb.And(
+		fb.Eq("namespace", swap.Namespace),
+		fb.Eq("pool", leg.Pool),
+		fb.Eq("type", fftypes.TokenTransferTypeTransfer),
+		fb.Eq("from", leg.From),
+		fb.Eq("to", swap.Escrow),
+		fb.Gte("created", swap.Created),
+	).Sort("created").Ascending())
+	if err != nil {
+		return false, err
+	}
+	for _, transfer := range transfers {
+		if claimed[*transfer.LocalID] || transfer.TokenIndex != leg.TokenIndex || transfer.Amount.Int().Cmp(leg.Amount.Int()) != 0 {
+			continue
+		}
+		log.L(ctx).Infof("Matched transfer '%s' as the counterparty deposit for swap '%s'", transfer.LocalID, swap.ID)
+		leg.Deposit = transfer.LocalID
+		claimed[*transfer.LocalID] = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// transferStatus reports whether a transfer has been confirmed, or has failed so will never be confirmed
+func (sm *swapManager) transferStatus(ctx context.Context, localID, txID *fftypes.UUID) (confirmed, failed bool, err error) {
+	if localID == nil {
+		return false, true, nil
+	}
+	transfer, err := sm.database.GetTokenTransfer(ctx, localID)
+	if err != nil || transfer != nil {
+		return transfer != nil, false, err
+	}
+	if txID == nil {
+		return false, false, nil
+	}
+	fb := database.OperationQueryFactory.NewFilter(ctx)
+	ops, _, err := sm.database.GetOperations(ctx, fb.And(
+		fb.Eq("tx", txID),
+		fb.Eq("type", fftypes.OpTypeTokenTransfer),
+	))
+	if err != nil {
+		return false, false, err
+	}
+	for _, op := range ops {
+		if op.Status == fftypes.OpStatusFailed {
+			return false, true, nil
+		}
+	}
+	return false, false, nil
+}
+
+// releaseLegs transfers each leg out of the escrow - to its recipient, or back to its depositor for a refund.
+// Returns true once every transfer out of the escrow has been confirmed.
+func (sm *swapManager) releaseLegs(ctx context.Context, swap *fftypes.Swap, refund bool) (complete, changed bool, err error) {
+	complete = true
+	for _, leg := range swap.Legs() {
+		if refund {
+			deposited, depositFailed, err := sm.transferStatus(ctx, leg.Deposit, leg.DepositTX)
+			if err != nil {
+				return false, false, err
+			}
+			if depositFailed {
+				continue // nothing to refund
+			}
+			if !deposited {
+				complete = false // wait for the deposit to be confirmed or fail
+				continue
+			}
+		}
+
+		if leg.Release != nil {
+			released, releaseFailed, err := sm.transferStatus(ctx, leg.Release, leg.ReleaseTX)
+			if err != nil {
+				return false, false, err
+			}
+			if released {
+				continue
+			}
+			complete = false
+			if !releaseFailed {
+				continue
+			}
+			log.L(ctx).Warnf("Transfer '%s' out of escrow for swap '%s' failed - retrying", leg.Release, swap.ID)
+		}
+
+		to := leg.To
+		if refund {
+			to = leg.From
+		}
+		complete = false
+		transfer, err := sm.submitTransfer(ctx, swap, leg, swap.Escrow, to)
+		if err != nil {
+			// Retried on the next poll
+			log.L(ctx).Errorf("Failed to transfer out of escrow for swap '%s': %s", swap.ID, err)
+			continue
+		}
+		leg.Release = transfer.LocalID
+		leg.ReleaseTX = transfer.TX.ID
+		changed = true
+	}
+	return complete, changed, nil
+}
@@ -0,0 +1,529 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swaps
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/internal/identity"
+	"github.com/hyperledger/firefly/mocks/assetmocks"
+	"github.com/hyperledger/firefly/mocks/databasemocks"
+	"github.com/hyperledger/firefly/mocks/identitymanagermocks"
+	"github.com/hyperledger/firefly/pkg/database"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestSwapManager(t *testing.T) (*swapManager, *databasemocks.Plugin, *identitymanagermocks.Manager, *assetmocks.Manager) {
+	config.Reset()
+	mdi := &databasemocks.Plugin{}
+	mim := &identitymanagermocks.Manager{}
+	mam := &assetmocks.Manager{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sm, err := NewSwapManager(ctx, mdi, mim, mam)
+	assert.NoError(t, err)
+	sm.WaitStop()
+	return sm.(*swapManager), mdi, mim, mam
+}
+
+func testSwapInput() *fftypes.SwapInput {
+	return &fftypes.SwapInput{
+		Initiator: fftypes.SwapLegInput{
+			Pool:   "pool1",
+			Amount: *fftypes.NewFFBigInt(10),
+			From:   "0x111",
+			To:     "0x222",
+		},
+		Counterparty: fftypes.SwapLegInput{
+			Pool:       "pool2",
+			TokenIndex: "1",
+			Amount:     *fftypes.NewFFBigInt(1),
+			From:       "0x222",
+			To:         "0x111",
+		},
+	}
+}
+
+func testSwap(state fftypes.SwapState) *fftypes.Swap {
+	return &fftypes.Swap{
+		ID:        fftypes.NewUUID(),
+		Namespace: "ns1",
+		State:     state,
+		Escrow:    "0xeee",
+		Initiator: &fftypes.SwapLeg{
+			Pool:      fftypes.NewUUID(),
+			Connector: "erc20",
+			Amount:    *fftypes.NewFFBigInt(10),
+			From:      "0x111",
+			To:        "0x222",
+			Deposit:   fftypes.NewUUID(),
+			DepositTX: fftypes.NewUUID(),
+		},
+		Counterparty: &fftypes.SwapLeg{
+			Pool:       fftypes.NewUUID(),
+			Connector:  "erc1155",
+			TokenIndex: "1",
+			Amount:     *fftypes.NewFFBigInt(1),
+			From:       "0x222",
+			To:         "0x111",
+		},
+		Created: fftypes.Now(),
+		Expires: fftypes.UnixTime(time.Now().Add(1 * time.Hour).Unix()),
+	}
+}
+
+func mockPools(mam *assetmocks.Manager) {
+	mam.On("GetTokenPoolByNameOrID", mock.Anything, "ns1", "pool1").Return(&fftypes.TokenPool{
+		ID: fftypes.NewUUID(), Connector: "erc20", State: fftypes.TokenPoolStateConfirmed,
+	}, nil)
+	mam.On("GetTokenPoolByNameOrID", mock.Anything, "ns1", "pool2").Return(&fftypes.TokenPool{
+		ID: fftypes.NewUUID(), Connector: "erc1155", State: fftypes.TokenPoolStateConfirmed,
+	}, nil)
+}
+
+func mockKeys(mim *identitymanagermocks.Manager) {
+	mim.On("NormalizeSigningKey", mock.Anything, mock.Anything, identity.KeyNormalizationBlockchainPlugin).Return(func(ctx context.Context, key string, mode int) string {
+		return key
+	}, nil)
+}
+
+func TestNewSwapManagerMissingDeps(t *testing.T) {
+	_, err := NewSwapManager(context.Background(), nil, nil, nil)
+	assert.Regexp(t, "FF10128", err)
+}
+
+func TestSwapLoop(t *testing.T) {
+	config.Reset()
+	config.Set(config.SwapsPollInterval, "1ms")
+	ctx, cancel := context.WithCancel(context.Background())
+	mdi := &databasemocks.Plugin{}
+	mdi.On("GetSwaps", mock.Anything, mock.Anything).Return([]*fftypes.Swap{}, nil, nil).Run(func(args mock.Arguments) {
+		cancel()
+	})
+	sm, err := NewSwapManager(ctx, mdi, &identitymanagermocks.Manager{}, &assetmocks.Manager{})
+	assert.NoError(t, err)
+	sm.WaitStop()
+	mdi.AssertExpectations(t)
+}
+
+func TestGetSwaps(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	mdi.On("GetSwaps", mock.Anything, mock.Anything).Return([]*fftypes.Swap{}, nil, nil)
+	fb := database.SwapQueryFactory.NewFilter(context.Background())
+	_, _, err := sm.GetSwaps(context.Background(), "ns1", fb.And())
+	assert.NoError(t, err)
+	mdi.AssertExpectations(t)
+}
+
+func TestGetSwapByID(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateCompleted)
+	mdi.On("GetSwapByID", mock.Anything, swap.ID).Return(swap, nil)
+	result, err := sm.GetSwapByID(context.Background(), "ns1", swap.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, swap, result)
+	result, err = sm.GetSwapByID(context.Background(), "ns2", swap.ID.String())
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestGetSwapByIDBadID(t *testing.T) {
+	sm, _, _, _ := newTestSwapManager(t)
+	_, err := sm.GetSwapByID(context.Background(), "ns1", "bad")
+	assert.Regexp(t, "FF10142", err)
+}
+
+func TestCreateSwapSuccess(t *testing.T) {
+	sm, mdi, mim, mam := newTestSwapManager(t)
+	mockPools(mam)
+	mockKeys(mim)
+	mim.On("GetNodeOwnerBlockchainKey", mock.Anything).Return(&fftypes.VerifierRef{Value: "0xeee"}, nil)
+	mdi.On("InsertSwap", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpdateSwap", mock.Anything, mock.Anything).Return(nil)
+	deposit := &fftypes.TokenTransfer{LocalID: fftypes.NewUUID(), TX: fftypes.TransactionRef{ID: fftypes.NewUUID()}}
+	mam.On("TransferTokens", mock.Anything, "ns1", mock.MatchedBy(func(t *fftypes.TokenTransferInput) bool {
+		return t.From == "0x111" && t.To == "0xeee" && t.Connector == "erc20" && t.Amount.Int().Int64() == 10
+	}), false).Return(deposit, nil)
+
+	swap, err := sm.CreateSwap(context.Background(), "ns1", testSwapInput())
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.SwapStateAwaitingDeposits, swap.State)
+	assert.Equal(t, "0xeee", swap.Escrow)
+	assert.Equal(t, deposit.LocalID, swap.Initiator.Deposit)
+	assert.Equal(t, deposit.TX.ID, swap.Initiator.DepositTX)
+	assert.Nil(t, swap.Counterparty.Deposit)
+	assert.True(t, swap.Expires.Time().After(time.Now().Add(59*time.Minute)))
+	mdi.AssertExpectations(t)
+	mam.AssertExpectations(t)
+}
+
+func TestCreateSwapConfiguredEscrowAndTimeout(t *testing.T) {
+	sm, mdi, mim, mam := newTestSwapManager(t)
+	sm.escrowKey = "0xfff"
+	mockPools(mam)
+	mockKeys(mim)
+	mdi.On("UpdateSwap", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	mam.On("TransferTokens", mock.Anything, "ns1", mock.Anything, false).Return(&fftypes.TokenTransfer{}, nil)
+
+	input := testSwapInput()
+	input.Timeout = fftypes.FFDuration(1 * time.Minute)
+	mdi.On("InsertSwap", mock.Anything, mock.MatchedBy(func(s *fftypes.Swap) bool {
+		return s.Escrow == "0xfff" && s.Expires.Time().Before(time.Now().Add(2*time.Minute))
+	})).Return(nil)
+	_, err := sm.CreateSwap(context.Background(), "ns1", input)
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
+func TestCreateSwapInvalidLegs(t *testing.T) {
+	sm, _, mim, mam := newTestSwapManager(t)
+	mockPools(mam)
+	mockKeys(mim)
+
+	input := testSwapInput()
+	input.Initiator.Pool = ""
+	_, err := sm.CreateSwap(context.Background(), "ns1", input)
+	assert.Regexp(t, "FF10432.*initiator", err)
+
+	input = testSwapInput()
+	input.Counterparty.Amount = *fftypes.NewFFBigInt(0)
+	_, err = sm.CreateSwap(context.Background(), "ns1", input)
+	assert.Regexp(t, "FF10432.*counterparty", err)
+}
+
+func TestCreateSwapPoolFail(t *testing.T) {
+	sm, _, _, mam := newTestSwapManager(t)
+	mam.On("GetTokenPoolByNameOrID", mock.Anything, "ns1", "pool1").Return(nil, fmt.Errorf("pop"))
+	_, err := sm.CreateSwap(context.Background(), "ns1", testSwapInput())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCreateSwapPoolNotConfirmed(t *testing.T) {
+	sm, _, _, mam := newTestSwapManager(t)
+	mam.On("GetTokenPoolByNameOrID", mock.Anything, "ns1", "pool1").Return(&fftypes.TokenPool{State: fftypes.TokenPoolStatePending}, nil)
+	_, err := sm.CreateSwap(context.Background(), "ns1", testSwapInput())
+	assert.Regexp(t, "FF10293", err)
+}
+
+func TestCreateSwapBadFromKey(t *testing.T) {
+	sm, _, mim, mam := newTestSwapManager(t)
+	mockPools(mam)
+	mim.On("NormalizeSigningKey", mock.Anything, "0x111", identity.KeyNormalizationBlockchainPlugin).Return("", fmt.Errorf("pop"))
+	_, err := sm.CreateSwap(context.Background(), "ns1", testSwapInput())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCreateSwapBadToKey(t *testing.T) {
+	sm, _, mim, mam := newTestSwapManager(t)
+	mockPools(mam)
+	mim.On("NormalizeSigningKey", mock.Anything, "0x111", identity.KeyNormalizationBlockchainPlugin).Return("0x111", nil)
+	mim.On("NormalizeSigningKey", mock.Anything, "0x222", identity.KeyNormalizationBlockchainPlugin).Return("", fmt.Errorf("pop"))
+	_, err := sm.CreateSwap(context.Background(), "ns1", testSwapInput())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCreateSwapCounterpartyFail(t *testing.T) {
+	sm, _, mim, mam := newTestSwapManager(t)
+	mockPools(mam)
+	mockKeys(mim)
+	input := testSwapInput()
+	input.Counterparty.From = ""
+	_, err := sm.CreateSwap(context.Background(), "ns1", input)
+	assert.Regexp(t, "FF10432.*counterparty", err)
+}
+
+func TestCreateSwapEscrowKeyFail(t *testing.T) {
+	sm, _, mim, mam := newTestSwapManager(t)
+	mockPools(mam)
+	mockKeys(mim)
+	mim.On("GetNodeOwnerBlockchainKey", mock.Anything).Return(nil, fmt.Errorf("pop"))
+	_, err := sm.CreateSwap(context.Background(), "ns1", testSwapInput())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCreateSwapInsertFail(t *testing.T) {
+	sm, mdi, mim, mam := newTestSwapManager(t)
+	mockPools(mam)
+	mockKeys(mim)
+	mim.On("GetNodeOwnerBlockchainKey", mock.Anything).Return(&fftypes.VerifierRef{Value: "0xeee"}, nil)
+	mdi.On("InsertSwap", mock.Anything, mock.Anything).Return(fmt.Errorf("pop"))
+	_, err := sm.CreateSwap(context.Background(), "ns1", testSwapInput())
+	assert.EqualError(t, err, "pop")
+}
+
+func TestCreateSwapDepositFail(t *testing.T) {
+	sm, mdi, mim, mam := newTestSwapManager(t)
+	mockPools(mam)
+	mockKeys(mim)
+	mim.On("GetNodeOwnerBlockchainKey", mock.Anything).Return(&fftypes.VerifierRef{Value: "0xeee"}, nil)
+	mdi.On("InsertSwap", mock.Anything, mock.Anything).Return(nil)
+	mdi.On("UpdateSwap", mock.Anything, mock.MatchedBy(func(s *fftypes.Swap) bool {
+		return s.State == fftypes.SwapStateFailed && s.Error == "pop"
+	})).Return(fmt.Errorf("pop2"))
+	mam.On("TransferTokens", mock.Anything, "ns1", mock.Anything, false).Return(nil, fmt.Errorf("pop"))
+	_, err := sm.CreateSwap(context.Background(), "ns1", testSwapInput())
+	assert.EqualError(t, err, "pop")
+	mdi.AssertExpectations(t)
+}
+
+func TestProcessActiveSwapsQueryFail(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	mdi.On("GetSwaps", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	sm.processActiveSwaps(context.Background())
+	mdi.AssertExpectations(t)
+}
+
+func TestProcessActiveSwapsClaimedFail(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	mdi.On("GetSwaps", mock.Anything, mock.Anything).Return([]*fftypes.Swap{testSwap(fftypes.SwapStateAwaitingDeposits)}, nil, nil).Once()
+	mdi.On("GetSwaps", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	sm.processActiveSwaps(context.Background())
+	mdi.AssertExpectations(t)
+}
+
+func TestProcessActiveSwapsMatchDeposit(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	swap1 := testSwap(fftypes.SwapStateAwaitingDeposits)
+	swap2 := testSwap(fftypes.SwapStateAwaitingDeposits)
+	swap2.Counterparty.Pool = swap1.Counterparty.Pool
+	claimedBefore := testSwap(fftypes.SwapStateCompleted)
+	claimedBefore.Counterparty.Deposit = fftypes.NewUUID()
+
+	transfers := []*fftypes.TokenTransfer{
+		{LocalID: claimedBefore.Counterparty.Deposit, TokenIndex: "1", Amount: *fftypes.NewFFBigInt(1)},
+		{LocalID: fftypes.NewUUID(), TokenIndex: "2", Amount: *fftypes.NewFFBigInt(1)},
+		{LocalID: fftypes.NewUUID(), TokenIndex: "1", Amount: *fftypes.NewFFBigInt(5)},
+		{LocalID: fftypes.NewUUID(), TokenIndex: "1", Amount: *fftypes.NewFFBigInt(1)},
+	}
+	mdi.On("GetSwaps", mock.Anything, mock.Anything).Return([]*fftypes.Swap{swap1, swap2}, nil, nil).Once()
+	mdi.On("GetSwaps", mock.Anything, mock.Anything).Return([]*fftypes.Swap{swap1, swap2, claimedBefore}, nil, nil).Once()
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return(transfers, nil, nil)
+	mdi.On("GetTokenTransfer", mock.Anything, swap1.Initiator.Deposit).Return(&fftypes.TokenTransfer{}, nil)
+	mdi.On("GetTokenTransfer", mock.Anything, swap2.Initiator.Deposit).Return(nil, fmt.Errorf("pop"))
+	mdi.On("GetTokenTransfer", mock.Anything, transfers[3].LocalID).Return(transfers[3], nil)
+	mdi.On("UpdateSwap", mock.Anything, swap1).Return(nil)
+
+	sm.processActiveSwaps(context.Background())
+
+	// The first swap claims the matching deposit, and the second is left waiting
+	assert.Equal(t, transfers[3].LocalID, swap1.Counterparty.Deposit)
+	assert.Equal(t, fftypes.SwapStateReleasing, swap1.State)
+	assert.Nil(t, swap2.Counterparty.Deposit)
+	assert.Equal(t, fftypes.SwapStateAwaitingDeposits, swap2.State)
+	mdi.AssertExpectations(t)
+}
+
+func TestProcessSwapNoActive(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	mdi.On("GetSwaps", mock.Anything, mock.Anything).Return([]*fftypes.Swap{}, nil, nil).Once()
+	sm.processActiveSwaps(context.Background())
+	mdi.AssertExpectations(t)
+}
+
+func TestProcessSwapMatchFail(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+	err := sm.processSwap(context.Background(), testSwap(fftypes.SwapStateAwaitingDeposits), map[fftypes.UUID]bool{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestProcessSwapDepositStatusFail(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateAwaitingDeposits)
+	swap.Counterparty.Deposit = fftypes.NewUUID()
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Initiator.Deposit).Return(nil, fmt.Errorf("pop"))
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestProcessSwapExpired(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateAwaitingDeposits)
+	swap.Expires = fftypes.UnixTime(time.Now().Add(-1 * time.Minute).Unix())
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Initiator.Deposit).Return(&fftypes.TokenTransfer{}, nil)
+	mdi.On("UpdateSwap", mock.Anything, swap).Return(nil)
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.SwapStateRefunding, swap.State)
+	mdi.AssertExpectations(t)
+}
+
+func TestProcessSwapNoChange(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateAwaitingDeposits)
+	swap.Initiator.DepositTX = nil
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Initiator.Deposit).Return(nil, nil)
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.SwapStateAwaitingDeposits, swap.State)
+	mdi.AssertExpectations(t)
+}
+
+func TestProcessSwapRelease(t *testing.T) {
+	sm, mdi, _, mam := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateReleasing)
+	swap.Counterparty.Deposit = fftypes.NewUUID()
+	swap.Initiator.Release = fftypes.NewUUID()
+	swap.Initiator.ReleaseTX = fftypes.NewUUID()
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Initiator.Release).Return(&fftypes.TokenTransfer{}, nil)
+	release := &fftypes.TokenTransfer{LocalID: fftypes.NewUUID(), TX: fftypes.TransactionRef{ID: fftypes.NewUUID()}}
+	mam.On("TransferTokens", mock.Anything, "ns1", mock.MatchedBy(func(t *fftypes.TokenTransferInput) bool {
+		return t.Key == "0xeee" && t.From == "0xeee" && t.To == "0x111" && t.TokenIndex == "1"
+	}), false).Return(release, nil)
+	mdi.On("UpdateSwap", mock.Anything, swap).Return(nil)
+
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.SwapStateReleasing, swap.State)
+	assert.Equal(t, release.LocalID, swap.Counterparty.Release)
+	mdi.AssertExpectations(t)
+	mam.AssertExpectations(t)
+}
+
+func TestProcessSwapReleaseComplete(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateReleasing)
+	swap.Initiator.Release = fftypes.NewUUID()
+	swap.Counterparty.Release = fftypes.NewUUID()
+	mdi.On("GetTokenTransfer", mock.Anything, mock.Anything).Return(&fftypes.TokenTransfer{}, nil)
+	mdi.On("UpdateSwap", mock.Anything, swap).Return(nil)
+
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.SwapStateCompleted, swap.State)
+	mdi.AssertExpectations(t)
+}
+
+func TestProcessSwapReleasePending(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateReleasing)
+	swap.Initiator.Release = fftypes.NewUUID()
+	swap.Initiator.ReleaseTX = fftypes.NewUUID()
+	swap.Counterparty.Release = fftypes.NewUUID()
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Initiator.Release).Return(nil, nil)
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Counterparty.Release).Return(&fftypes.TokenTransfer{}, nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.SwapStateReleasing, swap.State)
+	mdi.AssertExpectations(t)
+}
+
+func TestProcessSwapReleaseRetry(t *testing.T) {
+	sm, mdi, _, mam := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateReleasing)
+	failedRelease := fftypes.NewUUID()
+	swap.Initiator.Release = failedRelease
+	swap.Initiator.ReleaseTX = fftypes.NewUUID()
+	swap.Counterparty.Release = fftypes.NewUUID()
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Initiator.Release).Return(nil, nil)
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Counterparty.Release).Return(&fftypes.TokenTransfer{}, nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{
+		{Status: fftypes.OpStatusFailed},
+	}, nil, nil)
+	mam.On("TransferTokens", mock.Anything, "ns1", mock.Anything, false).Return(nil, fmt.Errorf("pop"))
+
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.NoError(t, err)
+	assert.Equal(t, failedRelease, swap.Initiator.Release)
+	mdi.AssertExpectations(t)
+	mam.AssertExpectations(t)
+}
+
+func TestProcessSwapReleaseStatusFail(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateReleasing)
+	swap.Initiator.Release = fftypes.NewUUID()
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Initiator.Release).Return(nil, fmt.Errorf("pop"))
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestProcessSwapRefund(t *testing.T) {
+	sm, mdi, _, mam := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateRefunding)
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Initiator.Deposit).Return(&fftypes.TokenTransfer{}, nil)
+	refund := &fftypes.TokenTransfer{LocalID: fftypes.NewUUID(), TX: fftypes.TransactionRef{ID: fftypes.NewUUID()}}
+	mam.On("TransferTokens", mock.Anything, "ns1", mock.MatchedBy(func(t *fftypes.TokenTransferInput) bool {
+		return t.From == "0xeee" && t.To == "0x111" && t.Connector == "erc20"
+	}), false).Return(refund, nil)
+	mdi.On("UpdateSwap", mock.Anything, swap).Return(nil)
+
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.SwapStateRefunding, swap.State)
+	assert.Equal(t, refund.LocalID, swap.Initiator.Release)
+	assert.Nil(t, swap.Counterparty.Release)
+	mdi.AssertExpectations(t)
+	mam.AssertExpectations(t)
+}
+
+func TestProcessSwapRefundComplete(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateRefunding)
+	swap.Initiator.Release = fftypes.NewUUID()
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	mdi.On("GetTokenTransfer", mock.Anything, mock.Anything).Return(&fftypes.TokenTransfer{}, nil)
+	mdi.On("UpdateSwap", mock.Anything, swap).Return(nil)
+
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.SwapStateRefunded, swap.State)
+	mdi.AssertExpectations(t)
+}
+
+func TestProcessSwapRefundDepositPending(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateRefunding)
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Initiator.Deposit).Return(nil, nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return([]*fftypes.Operation{}, nil, nil)
+
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.SwapStateRefunding, swap.State)
+	mdi.AssertExpectations(t)
+}
+
+func TestProcessSwapRefundDepositStatusFail(t *testing.T) {
+	sm, mdi, _, _ := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateRefunding)
+	mdi.On("GetTokenTransfers", mock.Anything, mock.Anything).Return([]*fftypes.TokenTransfer{}, nil, nil)
+	mdi.On("GetTokenTransfer", mock.Anything, swap.Initiator.Deposit).Return(nil, nil)
+	mdi.On("GetOperations", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.EqualError(t, err, "pop")
+}
+
+func TestProcessSwapOtherState(t *testing.T) {
+	sm, _, _, _ := newTestSwapManager(t)
+	swap := testSwap(fftypes.SwapStateCompleted)
+	swap.Counterparty.Deposit = fftypes.NewUUID()
+	err := sm.processSwap(context.Background(), swap, map[fftypes.UUID]bool{})
+	assert.NoError(t, err)
+}
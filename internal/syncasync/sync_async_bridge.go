@@ -22,6 +22,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/internal/data"
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
@@ -40,8 +41,9 @@ type Bridge interface {
 	// The following "WaitFor*" methods all wait for a particular type of event callback, and block until it is received.
 	// To use them, invoke the appropriate method, and pass a "send" callback that is expected to trigger the relevant event.
 
-	// WaitForReply waits for a reply to the message with the supplied ID
-	WaitForReply(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*fftypes.MessageInOut, error)
+	// WaitForReply waits for a reply to the message with the supplied ID, for up to the supplied timeout.
+	// A zero timeout uses the configured default
+	WaitForReply(ctx context.Context, ns string, id *fftypes.UUID, timeout time.Duration, send RequestSender) (*fftypes.MessageInOut, error)
 	// WaitForMessage waits for a message with the supplied ID
 	WaitForMessage(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*fftypes.Message, error)
 	// WaitForIdentity waits for an identity with the supplied ID
@@ -106,10 +108,11 @@ func (sa *syncAsyncBridge) Init(sysevents sysmessaging.SystemEvents) {
 }
 
 func (sa *syncAsyncBridge) addInFlight(ns string, id *fftypes.UUID, reqType requestType) (*inflightRequest, error) {
+	// The response channel is buffered, so a response that arrives after the request has timed out does not block the resolver
 	inflight := &inflightRequest{
 		id:        id,
 		startTime: time.Now(),
-		response:  make(chan inflightResponse),
+		response:  make(chan inflightResponse, 1),
 		reqType:   reqType,
 	}
 	sa.inflightMux.Lock()
@@ -510,7 +513,7 @@ func (sa *syncAsyncBridge) resolveFailedTokenApproval(inflight *inflightRequest,
 	inflight.response <- inflightResponse{err: err}
 }
 
-func (sa *syncAsyncBridge) sendAndWait(ctx context.Context, ns string, id *fftypes.UUID, reqType requestType, send RequestSender) (interface{}, error) {
+func (sa *syncAsyncBridge) sendAndWait(ctx context.Context, ns string, id *fftypes.UUID, reqType requestType, timeout time.Duration, send RequestSender) (interface{}, error) {
 	inflight, err := sa.addInFlight(ns, id, reqType)
 	if err != nil {
 		return nil, err
@@ -531,17 +534,29 @@ func (sa *syncAsyncBridge) sendAndWait(ctx context.Context, ns string, id *fftyp
 		return nil, err
 	}
 
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
 	select {
 	case <-ctx.Done():
 		return nil, i18n.NewError(ctx, i18n.MsgRequestTimeout, inflight.id, inflight.msInflight())
+	case <-expired:
+		return nil, i18n.NewError(ctx, i18n.MsgReplyTimeout, inflight.id, timeout)
 	case reply := <-inflight.response:
 		replyID = reply.id
 		return reply.data, reply.err
 	}
 }
 
-func (sa *syncAsyncBridge) WaitForReply(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*fftypes.MessageInOut, error) {
-	reply, err := sa.sendAndWait(ctx, ns, id, messageReply, send)
+func (sa *syncAsyncBridge) WaitForReply(ctx context.Context, ns string, id *fftypes.UUID, timeout time.Duration, send RequestSender) (*fftypes.MessageInOut, error) {
+	if timeout <= 0 {
+		timeout = config.GetDuration(config.MessageConfirmTimeout)
+	}
+	reply, err := sa.sendAndWait(ctx, ns, id, messageReply, timeout, send)
 	if err != nil {
 		return nil, err
 	}
@@ -549,7 +564,7 @@ func (sa *syncAsyncBridge) WaitForReply(ctx context.Context, ns string, id *ffty
 }
 
 func (sa *syncAsyncBridge) WaitForMessage(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*fftypes.Message, error) {
-	reply, err := sa.sendAndWait(ctx, ns, id, messageConfirm, send)
+	reply, err := sa.sendAndWait(ctx, ns, id, messageConfirm, 0, send)
 	if err != nil {
 		return nil, err
 	}
@@ -557,7 +572,7 @@ func (sa *syncAsyncBridge) WaitForMessage(ctx context.Context, ns string, id *ff
 }
 
 func (sa *syncAsyncBridge) WaitForIdentity(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*fftypes.Identity, error) {
-	reply, err := sa.sendAndWait(ctx, ns, id, identityConfirm, send)
+	reply, err := sa.sendAndWait(ctx, ns, id, identityConfirm, 0, send)
 	if err != nil {
 		return nil, err
 	}
@@ -565,7 +580,7 @@ func (sa *syncAsyncBridge) WaitForIdentity(ctx context.Context, ns string, id *f
 }
 
 func (sa *syncAsyncBridge) WaitForTokenPool(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*fftypes.TokenPool, error) {
-	reply, err := sa.sendAndWait(ctx, ns, id, tokenPoolConfirm, send)
+	reply, err := sa.sendAndWait(ctx, ns, id, tokenPoolConfirm, 0, send)
 	if err != nil {
 		return nil, err
 	}
@@ -573,7 +588,7 @@ func (sa *syncAsyncBridge) WaitForTokenPool(ctx context.Context, ns string, id *
 }
 
 func (sa *syncAsyncBridge) WaitForTokenTransfer(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*fftypes.TokenTransfer, error) {
-	reply, err := sa.sendAndWait(ctx, ns, id, tokenTransferConfirm, send)
+	reply, err := sa.sendAndWait(ctx, ns, id, tokenTransferConfirm, 0, send)
 	if err != nil {
 		return nil, err
 	}
@@ -581,7 +596,7 @@ func (sa *syncAsyncBridge) WaitForTokenTransfer(ctx context.Context, ns string,
 }
 
 func (sa *syncAsyncBridge) WaitForTokenApproval(ctx context.Context, ns string, id *fftypes.UUID, send RequestSender) (*fftypes.TokenApproval, error) {
-	reply, err := sa.sendAndWait(ctx, ns, id, tokenApproveConfirm, send)
+	reply, err := sa.sendAndWait(ctx, ns, id, tokenApproveConfirm, 0, send)
 	if err != nil {
 		return nil, err
 	}
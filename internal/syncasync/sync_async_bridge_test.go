@@ -21,7 +21,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/hyperledger/firefly/internal/config"
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
 	"github.com/hyperledger/firefly/mocks/sysmessagingmocks"
@@ -31,6 +33,7 @@ import (
 )
 
 func newTestSyncAsyncBridge(t *testing.T) (*syncAsyncBridge, func()) {
+	config.Reset()
 	ctx, cancel := context.WithCancel(context.Background())
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
@@ -73,7 +76,7 @@ func TestRequestReplyOk(t *testing.T) {
 		{ID: dataID, Value: fftypes.JSONAnyPtr(`"response data"`)},
 	}, true, nil)
 
-	reply, err := sa.WaitForReply(sa.ctx, "ns1", requestID, func(ctx context.Context) error {
+	reply, err := sa.WaitForReply(sa.ctx, "ns1", requestID, 0, func(ctx context.Context) error {
 		go func() {
 			sa.eventCallback(&fftypes.EventDelivery{
 				EnrichedEvent: fftypes.EnrichedEvent{
@@ -197,12 +200,60 @@ func TestRequestReplyTimeout(t *testing.T) {
 	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
 	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
 
-	_, err := sa.WaitForReply(sa.ctx, "ns1", fftypes.NewUUID(), func(ctx context.Context) error {
+	_, err := sa.WaitForReply(sa.ctx, "ns1", fftypes.NewUUID(), 0, func(ctx context.Context) error {
 		return nil
 	})
 	assert.Regexp(t, "FF10260", err)
 }
 
+func TestRequestReplyExplicitTimeout(t *testing.T) {
+
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	requestID := fftypes.NewUUID()
+	_, err := sa.WaitForReply(sa.ctx, "ns1", requestID, 1*time.Millisecond, func(ctx context.Context) error {
+		return nil
+	})
+	assert.Regexp(t, "FF10552", err)
+
+	assert.Nil(t, sa.getInFlight("ns1", messageReply, requestID))
+}
+
+func TestLateResponseDoesNotBlock(t *testing.T) {
+
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	inflight, err := sa.addInFlight("ns1", fftypes.NewUUID(), messageReply)
+	assert.NoError(t, err)
+	sa.removeInFlight("ns1", inflight.id)
+
+	// Nothing is waiting for the response, but resolving must still return
+	sa.resolveRejected(inflight, inflight.id)
+}
+
+func TestRequestReplyDefaultTimeout(t *testing.T) {
+
+	sa, cancel := newTestSyncAsyncBridge(t)
+	defer cancel()
+	config.Set(config.MessageConfirmTimeout, "1ms")
+
+	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
+	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(nil)
+
+	_, err := sa.WaitForReply(sa.ctx, "ns1", fftypes.NewUUID(), 0, func(ctx context.Context) error {
+		return nil
+	})
+	assert.Regexp(t, "FF10552", err)
+}
+
 func TestRequestSetupSystemListenerFail(t *testing.T) {
 
 	sa, cancel := newTestSyncAsyncBridge(t)
@@ -211,7 +262,7 @@ func TestRequestSetupSystemListenerFail(t *testing.T) {
 	mse := sa.sysevents.(*sysmessagingmocks.SystemEvents)
 	mse.On("AddSystemEventListener", "ns1", mock.Anything).Return(fmt.Errorf("pop"))
 
-	_, err := sa.WaitForReply(sa.ctx, "ns1", fftypes.NewUUID(), func(ctx context.Context) error {
+	_, err := sa.WaitForReply(sa.ctx, "ns1", fftypes.NewUUID(), 0, func(ctx context.Context) error {
 		return nil
 	})
 	assert.Regexp(t, "pop", err)
@@ -16,9 +16,15 @@
 
 package sysmessaging
 
-import "github.com/hyperledger/firefly/internal/events/system"
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/events/system"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
 
 // SystemEvents specifies the internal interface, without creating a cycle
 type SystemEvents interface {
 	AddSystemEventListener(ns string, el system.EventListener) error
+	AddDurableSystemEventListener(ctx context.Context, ns, name string, filter *fftypes.SubscriptionFilter, el system.EventListener) error
 }
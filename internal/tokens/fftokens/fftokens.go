@@ -79,6 +79,7 @@ type tokenApproval struct {
 	Signer    string             `json:"signer"`
 	Operator  string             `json:"operator"`
 	Approved  bool               `json:"approved"`
+	Allowance string             `json:"allowance,omitempty"`
 	PoolID    string             `json:"poolId"`
 	RequestID string             `json:"requestId,omitempty"`
 	Data      string             `json:"data,omitempty"`
@@ -349,7 +350,8 @@ func (ft *FFTokens) handleTokenApproval(ctx context.Context, data fftypes.JSONOb
 	poolProtocolID := data.GetString("poolId")
 	operatorAddress := data.GetString("operator")
 	approved := data.GetBool("approved")
-	rawOutput := data.GetObject("rawOutput") // optional
+	allowanceStr := data.GetString("allowance") // optional - only for fungible approvals
+	rawOutput := data.GetObject("rawOutput")    // optional
 	tx := data.GetObject("transaction")
 	txHash := tx.GetString("transactionHash") // optional
 
@@ -382,6 +384,15 @@ func (ft *FFTokens) handleTokenApproval(ctx context.Context, data fftypes.JSONOb
 		txType = fftypes.TransactionTypeTokenApproval
 	}
 
+	var allowance *fftypes.FFBigInt
+	if allowanceStr != "" {
+		allowance = &fftypes.FFBigInt{}
+		if _, ok := allowance.Int().SetString(allowanceStr, 10); !ok {
+			log.L(ctx).Errorf("%s event is not valid - invalid allowance: %+v", eventName, data)
+			return nil // move on
+		}
+	}
+
 	approval := &tokens.TokenApproval{
 		PoolProtocolID: poolProtocolID,
 		TokenApproval: fftypes.TokenApproval{
@@ -389,6 +400,7 @@ func (ft *FFTokens) handleTokenApproval(ctx context.Context, data fftypes.JSONOb
 			Key:        signerAddress,
 			Operator:   operatorAddress,
 			Approved:   approved,
+			Allowance:  allowance,
 			ProtocolID: eventProtocolID,
 			TX: fftypes.TransactionRef{
 				ID:   transferData.TX,
@@ -597,16 +609,20 @@ func (ft *FFTokens) TokensApproval(ctx context.Context, opID *fftypes.UUID, pool
 		TX:     approval.TX.ID,
 		TXType: approval.TX.Type,
 	})
+	body := &tokenApproval{
+		PoolID:    poolProtocolID,
+		Signer:    approval.Key,
+		Operator:  approval.Operator,
+		Approved:  approval.Approved,
+		RequestID: opID.String(),
+		Data:      string(data),
+		Config:    approval.Config,
+	}
+	if approval.Allowance != nil {
+		body.Allowance = approval.Allowance.Int().String()
+	}
 	res, err := ft.client.R().SetContext(ctx).
-		SetBody(&tokenApproval{
-			PoolID:    poolProtocolID,
-			Signer:    approval.Key,
-			Operator:  approval.Operator,
-			Approved:  approval.Approved,
-			RequestID: opID.String(),
-			Data:      string(data),
-			Config:    approval.Config,
-		}).
+		SetBody(body).
 		Post("/api/v1/approval")
 	if err != nil || !res.IsSuccess() {
 		return restclient.WrapRestErr(ctx, res, err, i18n.MsgTokensRESTErr)
@@ -514,6 +514,35 @@ func TestTokenApproval(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestTokenApprovalAllowance(t *testing.T) {
+	h, _, _, httpURL, done := newTestFFTokens(t)
+	defer done()
+
+	approval := &fftypes.TokenApproval{
+		LocalID:   fftypes.NewUUID(),
+		Operator:  "0x02",
+		Key:       "0x123",
+		Approved:  true,
+		Allowance: fftypes.NewFFBigInt(1000),
+		TX: fftypes.TransactionRef{
+			ID:   fftypes.NewUUID(),
+			Type: fftypes.TransactionTypeTokenApproval,
+		},
+	}
+
+	httpmock.RegisterResponder("POST", fmt.Sprintf("%s/api/v1/approval", httpURL),
+		func(req *http.Request) (*http.Response, error) {
+			body := make(fftypes.JSONObject)
+			err := json.NewDecoder(req.Body).Decode(&body)
+			assert.NoError(t, err)
+			assert.Equal(t, "1000", body.GetString("allowance"))
+			return httpmock.NewJsonResponderOrPanic(202, fftypes.JSONObject{"id": "1"})(req)
+		})
+
+	err := h.TokensApproval(context.Background(), fftypes.NewUUID(), "123", approval)
+	assert.NoError(t, err)
+}
+
 func TestTokenApprovalError(t *testing.T) {
 	h, _, _, httpURL, done := newTestFFTokens(t)
 	defer done()
@@ -934,7 +963,7 @@ func TestEvents(t *testing.T) {
 
 	// token-approval: success
 	mcb.On("TokensApproved", h, mock.MatchedBy(func(t *tokens.TokenApproval) bool {
-		return t.Approved == true && t.Operator == "0x0" && t.PoolProtocolID == "F1" && t.Event.ProtocolID == "000000000010/000020/000030/000040"
+		return t.Approved == true && t.Operator == "0x0" && t.PoolProtocolID == "F1" && t.Event.ProtocolID == "000000000010/000020/000030/000040" && t.Allowance == nil
 	})).Return(nil).Once()
 	fromServer <- fftypes.JSONObject{
 		"id":    "17",
@@ -956,7 +985,7 @@ func TestEvents(t *testing.T) {
 
 	// token-approval: success (no data)
 	mcb.On("TokensApproved", h, mock.MatchedBy(func(t *tokens.TokenApproval) bool {
-		return t.Approved == true && t.Operator == "0x0" && t.PoolProtocolID == "F1" && t.Event.ProtocolID == "000000000010/000020/000030/000040"
+		return t.Approved == true && t.Operator == "0x0" && t.PoolProtocolID == "F1" && t.Event.ProtocolID == "000000000010/000020/000030/000040" && t.Allowance == nil
 	})).Return(nil).Once()
 	fromServer <- fftypes.JSONObject{
 		"id":    "18",
@@ -972,6 +1001,41 @@ func TestEvents(t *testing.T) {
 	msg = <-toServer
 	assert.Equal(t, `{"data":{"id":"18"},"event":"ack"}`, string(msg))
 
+	// token-approval: success (with allowance)
+	mcb.On("TokensApproved", h, mock.MatchedBy(func(t *tokens.TokenApproval) bool {
+		return t.Allowance != nil && t.Allowance.Int().Int64() == 500 && t.PoolProtocolID == "F1"
+	})).Return(nil).Once()
+	fromServer <- fftypes.JSONObject{
+		"id":    "19",
+		"event": "token-approval",
+		"data": fftypes.JSONObject{
+			"id":        "000000000010/000020/000030/000050",
+			"poolId":    "F1",
+			"signer":    "0x0",
+			"operator":  "0x0",
+			"approved":  true,
+			"allowance": "500",
+		},
+	}.String()
+	msg = <-toServer
+	assert.Equal(t, `{"data":{"id":"19"},"event":"ack"}`, string(msg))
+
+	// token-approval: invalid allowance
+	fromServer <- fftypes.JSONObject{
+		"id":    "20",
+		"event": "token-approval",
+		"data": fftypes.JSONObject{
+			"id":        "000000000010/000020/000030/000060",
+			"poolId":    "F1",
+			"signer":    "0x0",
+			"operator":  "0x0",
+			"approved":  true,
+			"allowance": "bad",
+		},
+	}.String()
+	msg = <-toServer
+	assert.Equal(t, `{"data":{"id":"20"},"event":"ack"}`, string(msg))
+
 	// token-approval: missing data
 	fromServer <- fftypes.JSONObject{
 		"id":    "9",
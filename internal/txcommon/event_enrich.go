@@ -18,10 +18,24 @@ package txcommon
 
 import (
 	"context"
+	"database/sql/driver"
 
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
+// messageEventStates are the states messages are moved to, in the same database transaction as each type of event is inserted
+var messageEventStates = map[fftypes.EventType]fftypes.MessageState{
+	fftypes.EventTypeMessageConfirmed:   fftypes.MessageStateConfirmed,
+	fftypes.EventTypeMessageRejected:    fftypes.MessageStateRejected,
+	fftypes.EventTypeMessageReorged:     fftypes.MessageStateUnconfirmed,
+	fftypes.EventTypeMessageAnchored:    fftypes.MessageStateAnchored,
+	fftypes.EventTypeMessageQuarantined: fftypes.MessageStateQuarantined,
+	fftypes.EventTypeBlobQuarantined:    fftypes.MessageStateQuarantined,
+	fftypes.EventTypeMessageSchemaDrift: fftypes.MessageStateConfirmed,
+}
+
 func (t *transactionHelper) EnrichEvent(ctx context.Context, event *fftypes.Event) (*fftypes.EnrichedEvent, error) {
 	e := &fftypes.EnrichedEvent{
 		Event: *event,
@@ -34,18 +48,142 @@ func (t *transactionHelper) EnrichEvent(ctx context.Context, event *fftypes.Even
 			return nil, err
 		}
 		e.Transaction = tx
-	case fftypes.EventTypeMessageConfirmed, fftypes.EventTypeMessageRejected:
-		msg, _, _, err := t.data.GetMessageWithDataCached(ctx, event.Reference)
+	case fftypes.EventTypeMessageConfirmed, fftypes.EventTypeMessageRejected, fftypes.EventTypeMessageReorged, fftypes.EventTypeMessageAnchored,
+		fftypes.EventTypeMessageQuarantined, fftypes.EventTypeBlobQuarantined, fftypes.EventTypeMessageSchemaDrift:
+		msg, err := t.enrichMessage(ctx, event)
 		if err != nil {
 			return nil, err
 		}
 		e.Message = msg
+	case fftypes.EventTypeBatchAssembled, fftypes.EventTypeBatchSealed, fftypes.EventTypeBatchDispatched,
+		fftypes.EventTypeBatchPinSubmitted, fftypes.EventTypeBatchConfirmed:
+		batch, err := t.database.GetBatchByID(ctx, event.Reference)
+		if err != nil {
+			return nil, err
+		}
+		if batch != nil {
+			e.Batch = batch.Summary()
+		}
 	case fftypes.EventTypeBlockchainEventReceived:
 		be, err := t.database.GetBlockchainEventByID(ctx, event.Reference)
 		if err != nil {
 			return nil, err
 		}
 		e.BlockchainEvent = be
+	case fftypes.EventTypePoolConfirmed:
+		pool, err := t.database.GetTokenPoolByID(ctx, event.Reference)
+		if err != nil {
+			return nil, err
+		}
+		e.TokenPool = pool
+	case fftypes.EventTypeApprovalConfirmed:
+		approval, err := t.database.GetTokenApproval(ctx, event.Reference)
+		if err != nil {
+			return nil, err
+		}
+		e.TokenApproval = approval
+	case fftypes.EventTypeTokenMetadataUpdated:
+		metadata, err := t.database.GetTokenMetadataByID(ctx, event.Reference)
+		if err != nil {
+			return nil, err
+		}
+		e.TokenMetadata = metadata
+	case fftypes.EventTypeTransferConfirmed:
+		if err := t.enrichTokenTransfer(ctx, e); err != nil {
+			return nil, err
+		}
 	}
 	return e, nil
 }
+
+// enrichMessage reads the message for a message event. The state of a cached message cannot be relied upon, as
+// the cache is not updated in the same transaction as the event is inserted. So if the cached message is not in
+// the state the event moved it to, the message is re-read from the database (and the cache refreshed).
+// The message might also have changed state again since the event was inserted - such as being unconfirmed by
+// a reorg straight after confirmation. In that case the latest persisted message is delivered, as there will be
+// a later event for the change.
+func (t *transactionHelper) enrichMessage(ctx context.Context, event *fftypes.Event) (*fftypes.Message, error) {
+	msg, _, _, err := t.data.GetMessageWithDataCached(ctx, event.Reference)
+	if err != nil || msg == nil {
+		return nil, err
+	}
+	eventState := messageEventStates[event.Type]
+	if msg.State == eventState {
+		return msg, nil
+	}
+
+	log.L(ctx).Debugf("Cached message %s is in state '%s' for %s event %s - re-reading", msg.Header.ID, msg.State, event.Type, event.ID)
+	msg, err = t.database.GetMessageByID(ctx, event.Reference)
+	if err != nil || msg == nil {
+		return nil, err
+	}
+	t.data.UpdateMessageIfCached(ctx, msg)
+	if msg.State != eventState {
+		log.L(ctx).Infof("Message %s has moved from state '%s' to '%s' since %s event %s", msg.Header.ID, eventState, msg.State, event.Type, event.ID)
+	}
+	return msg, nil
+}
+
+// enrichTokenTransfer adds the pool, the counterparty orgs and any linked message to a transfer
+// event, so that applications receive everything they need to display it in a single delivery
+func (t *transactionHelper) enrichTokenTransfer(ctx context.Context, e *fftypes.EnrichedEvent) (err error) {
+	transfer, err := t.database.GetTokenTransfer(ctx, e.Reference)
+	if err != nil || transfer == nil {
+		return err
+	}
+	e.TokenTransfer = transfer
+
+	if transfer.Pool != nil {
+		if e.TokenPool, err = t.database.GetTokenPoolByID(ctx, transfer.Pool); err != nil {
+			return err
+		}
+	}
+
+	counterparties := &fftypes.Counterparties{}
+	if counterparties.From, err = t.orgForKey(ctx, transfer.Namespace, transfer.From); err != nil {
+		return err
+	}
+	if counterparties.To, err = t.orgForKey(ctx, transfer.Namespace, transfer.To); err != nil {
+		return err
+	}
+	if counterparties.From != nil || counterparties.To != nil {
+		e.Counterparties = counterparties
+	}
+
+	if transfer.Message != nil {
+		if e.Message, _, _, err = t.data.GetMessageWithDataCached(ctx, transfer.Message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orgForKey resolves a blockchain key to the org that owns it, by following the parent chain
+// of the identity the key is registered to. Returns nil if the key is not registered.
+func (t *transactionHelper) orgForKey(ctx context.Context, ns, key string) (*fftypes.Identity, error) {
+	if key == "" {
+		return nil, nil
+	}
+	fb := database.VerifierQueryFactory.NewFilter(ctx)
+	verifiers, _, err := t.database.GetVerifiers(ctx, fb.And(
+		fb.In("namespace", []driver.Value{ns, fftypes.SystemNamespace}),
+		fb.Eq("value", key),
+	).Limit(1))
+	if err != nil || len(verifiers) == 0 {
+		return nil, err
+	}
+
+	loopDetect := make(map[fftypes.UUID]bool)
+	for identityID := verifiers[0].Identity; identityID != nil && !loopDetect[*identityID]; {
+		loopDetect[*identityID] = true
+		identity, err := t.database.GetIdentityByID(ctx, identityID)
+		if err != nil || identity == nil {
+			return nil, err
+		}
+		if identity.Type == fftypes.IdentityTypeOrg {
+			return identity, nil
+		}
+		identityID = identity.Parent
+	}
+	return nil, nil
+}
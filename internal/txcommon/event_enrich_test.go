@@ -19,10 +19,12 @@ package txcommon
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/hyperledger/firefly/mocks/databasemocks"
 	"github.com/hyperledger/firefly/mocks/datamocks"
+	"github.com/hyperledger/firefly/pkg/database"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -41,6 +43,7 @@ func TestEnrichMessageConfirmed(t *testing.T) {
 	// Setup enrichment
 	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(&fftypes.Message{
 		Header: fftypes.MessageHeader{ID: ref1},
+		State:  fftypes.MessageStateConfirmed,
 	}, nil, true, nil)
 
 	event := &fftypes.Event{
@@ -90,6 +93,7 @@ func TestEnrichMessageRejected(t *testing.T) {
 	// Setup enrichment
 	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(&fftypes.Message{
 		Header: fftypes.MessageHeader{ID: ref1},
+		State:  fftypes.MessageStateRejected,
 	}, nil, true, nil)
 
 	event := &fftypes.Event{
@@ -103,6 +107,110 @@ func TestEnrichMessageRejected(t *testing.T) {
 	assert.Equal(t, ref1, enriched.Message.Header.ID)
 }
 
+func TestEnrichMessageStaleCache(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: ref1},
+		State:  fftypes.MessageStatePending,
+	}, nil, true, nil)
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: ref1},
+		State:  fftypes.MessageStateConfirmed,
+	}
+	mdi.On("GetMessageByID", mock.Anything, ref1).Return(msg, nil)
+	mdm.On("UpdateMessageIfCached", mock.Anything, msg).Return()
+
+	enriched, err := txHelper.EnrichEvent(ctx, &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeMessageConfirmed,
+		Reference: ref1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, msg, enriched.Message)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestEnrichMessageChangedSinceEvent(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: ref1},
+		State:  fftypes.MessageStatePending,
+	}, nil, true, nil)
+	msg := &fftypes.Message{
+		Header: fftypes.MessageHeader{ID: ref1},
+		State:  fftypes.MessageStateUnconfirmed,
+	}
+	mdi.On("GetMessageByID", mock.Anything, ref1).Return(msg, nil)
+	mdm.On("UpdateMessageIfCached", mock.Anything, msg).Return()
+
+	enriched, err := txHelper.EnrichEvent(ctx, &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeMessageConfirmed,
+		Reference: ref1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.MessageStateUnconfirmed, enriched.Message.State)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestEnrichMessageStaleCacheReadFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: ref1},
+		State:  fftypes.MessageStatePending,
+	}, nil, true, nil)
+	mdi.On("GetMessageByID", mock.Anything, ref1).Return(nil, fmt.Errorf("pop"))
+
+	_, err := txHelper.EnrichEvent(ctx, &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeMessageReorged,
+		Reference: ref1,
+	})
+	assert.EqualError(t, err, "pop")
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestEnrichMessageNotFound(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdm.On("GetMessageWithDataCached", mock.Anything, ref1).Return(nil, nil, false, nil)
+
+	enriched, err := txHelper.EnrichEvent(ctx, &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeMessageConfirmed,
+		Reference: ref1,
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, enriched.Message)
+
+	mdm.AssertExpectations(t)
+}
+
 func TestEnrichTxSubmitted(t *testing.T) {
 	mdi := &databasemocks.Plugin{}
 	mdm := &datamocks.Manager{}
@@ -200,3 +308,385 @@ func TestEnrichBlockchainEventFail(t *testing.T) {
 	_, err := txHelper.EnrichEvent(ctx, event)
 	assert.EqualError(t, err, "pop")
 }
+
+func TestEnrichBatchSealed(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetBatchByID", mock.Anything, ref1).Return(&fftypes.BatchPersisted{
+		BatchHeader: fftypes.BatchHeader{
+			ID: ref1,
+		},
+		Manifest: fftypes.JSONAnyPtr(`{"messages":[{},{}],"data":[{}]}`),
+	}, nil)
+
+	event := &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeBatchSealed,
+		Reference: ref1,
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.Batch.ID)
+	assert.Equal(t, 2, enriched.Batch.Messages)
+	assert.Equal(t, 1, enriched.Batch.Data)
+}
+
+func TestEnrichBatchConfirmedNotFound(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetBatchByID", mock.Anything, ref1).Return(nil, nil)
+
+	event := &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeBatchConfirmed,
+		Reference: ref1,
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Nil(t, enriched.Batch)
+}
+
+func TestEnrichBatchFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetBatchByID", mock.Anything, ref1).Return(nil, fmt.Errorf("pop"))
+
+	event := &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeBatchAssembled,
+		Reference: ref1,
+	}
+
+	_, err := txHelper.EnrichEvent(ctx, event)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestEnrichTokenPoolConfirmed(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetTokenPoolByID", mock.Anything, ref1).Return(&fftypes.TokenPool{
+		ID: ref1,
+	}, nil)
+
+	event := &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypePoolConfirmed,
+		Reference: ref1,
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.TokenPool.ID)
+}
+
+func TestEnrichTokenPoolConfirmedFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetTokenPoolByID", mock.Anything, ref1).Return(nil, fmt.Errorf("pop"))
+
+	event := &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypePoolConfirmed,
+		Reference: ref1,
+	}
+
+	_, err := txHelper.EnrichEvent(ctx, event)
+	assert.EqualError(t, err, "pop")
+}
+
+func newTestTransferEvent(transfer *fftypes.TokenTransfer) *fftypes.Event {
+	return &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeTransferConfirmed,
+		Namespace: "ns1",
+		Reference: transfer.LocalID,
+	}
+}
+
+func TestEnrichTokenTransferConfirmed(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	org1 := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Type: fftypes.IdentityTypeOrg, Name: "org1"}}
+	node1 := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Type: fftypes.IdentityTypeNode, Parent: org1.ID}}
+	org2 := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Type: fftypes.IdentityTypeOrg, Name: "org2"}}
+	transfer := &fftypes.TokenTransfer{
+		LocalID:   fftypes.NewUUID(),
+		Pool:      fftypes.NewUUID(),
+		Namespace: "ns1",
+		From:      "0x111",
+		To:        "0x222",
+		Message:   fftypes.NewUUID(),
+	}
+
+	mdi.On("GetTokenTransfer", mock.Anything, transfer.LocalID).Return(transfer, nil)
+	mdi.On("GetTokenPoolByID", mock.Anything, transfer.Pool).Return(&fftypes.TokenPool{ID: transfer.Pool, Name: "pool1"}, nil)
+	mdi.On("GetVerifiers", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return strings.Contains(fi.String(), "0x111")
+	})).Return([]*fftypes.Verifier{{Identity: node1.ID}}, nil, nil)
+	mdi.On("GetVerifiers", mock.Anything, mock.MatchedBy(func(f database.Filter) bool {
+		fi, _ := f.Finalize()
+		return strings.Contains(fi.String(), "0x222")
+	})).Return([]*fftypes.Verifier{{Identity: org2.ID}}, nil, nil)
+	mdi.On("GetIdentityByID", mock.Anything, node1.ID).Return(node1, nil)
+	mdi.On("GetIdentityByID", mock.Anything, org1.ID).Return(org1, nil)
+	mdi.On("GetIdentityByID", mock.Anything, org2.ID).Return(org2, nil)
+	mdm.On("GetMessageWithDataCached", mock.Anything, transfer.Message).Return(&fftypes.Message{
+		Header: fftypes.MessageHeader{ID: transfer.Message},
+	}, nil, true, nil)
+
+	enriched, err := txHelper.EnrichEvent(ctx, newTestTransferEvent(transfer))
+	assert.NoError(t, err)
+	assert.Equal(t, transfer, enriched.TokenTransfer)
+	assert.Equal(t, "pool1", enriched.TokenPool.Name)
+	assert.Equal(t, org1, enriched.Counterparties.From)
+	assert.Equal(t, org2, enriched.Counterparties.To)
+	assert.Equal(t, transfer.Message, enriched.Message.Header.ID)
+
+	mdi.AssertExpectations(t)
+	mdm.AssertExpectations(t)
+}
+
+func TestEnrichTokenTransferMintUnregistered(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	transfer := &fftypes.TokenTransfer{
+		LocalID:   fftypes.NewUUID(),
+		Namespace: "ns1",
+		To:        "0x222",
+	}
+
+	mdi.On("GetTokenTransfer", mock.Anything, transfer.LocalID).Return(transfer, nil)
+	mdi.On("GetVerifiers", mock.Anything, mock.Anything).Return([]*fftypes.Verifier{}, nil, nil)
+
+	enriched, err := txHelper.EnrichEvent(ctx, newTestTransferEvent(transfer))
+	assert.NoError(t, err)
+	assert.Equal(t, transfer, enriched.TokenTransfer)
+	assert.Nil(t, enriched.TokenPool)
+	assert.Nil(t, enriched.Counterparties)
+	assert.Nil(t, enriched.Message)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEnrichTokenTransferNoOrg(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	custom1 := &fftypes.Identity{IdentityBase: fftypes.IdentityBase{ID: fftypes.NewUUID(), Type: fftypes.IdentityTypeCustom}}
+	custom1.Parent = custom1.ID
+	transfer := &fftypes.TokenTransfer{
+		LocalID:   fftypes.NewUUID(),
+		Namespace: "ns1",
+		From:      "0x111",
+	}
+
+	mdi.On("GetTokenTransfer", mock.Anything, transfer.LocalID).Return(transfer, nil)
+	mdi.On("GetVerifiers", mock.Anything, mock.Anything).Return([]*fftypes.Verifier{{Identity: custom1.ID}}, nil, nil)
+	mdi.On("GetIdentityByID", mock.Anything, custom1.ID).Return(custom1, nil).Once()
+
+	enriched, err := txHelper.EnrichEvent(ctx, newTestTransferEvent(transfer))
+	assert.NoError(t, err)
+	assert.Nil(t, enriched.Counterparties)
+
+	mdi.AssertExpectations(t)
+}
+
+func TestEnrichTokenTransferNotFound(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	transfer := &fftypes.TokenTransfer{LocalID: fftypes.NewUUID()}
+	mdi.On("GetTokenTransfer", mock.Anything, transfer.LocalID).Return(nil, nil)
+
+	enriched, err := txHelper.EnrichEvent(ctx, newTestTransferEvent(transfer))
+	assert.NoError(t, err)
+	assert.Nil(t, enriched.TokenTransfer)
+}
+
+func TestEnrichTokenTransferFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	transfer := &fftypes.TokenTransfer{LocalID: fftypes.NewUUID()}
+	mdi.On("GetTokenTransfer", mock.Anything, transfer.LocalID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := txHelper.EnrichEvent(ctx, newTestTransferEvent(transfer))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestEnrichTokenTransferPoolFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	transfer := &fftypes.TokenTransfer{LocalID: fftypes.NewUUID(), Pool: fftypes.NewUUID()}
+	mdi.On("GetTokenTransfer", mock.Anything, transfer.LocalID).Return(transfer, nil)
+	mdi.On("GetTokenPoolByID", mock.Anything, transfer.Pool).Return(nil, fmt.Errorf("pop"))
+
+	_, err := txHelper.EnrichEvent(ctx, newTestTransferEvent(transfer))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestEnrichTokenTransferFromFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	transfer := &fftypes.TokenTransfer{LocalID: fftypes.NewUUID(), From: "0x111"}
+	mdi.On("GetTokenTransfer", mock.Anything, transfer.LocalID).Return(transfer, nil)
+	mdi.On("GetVerifiers", mock.Anything, mock.Anything).Return(nil, nil, fmt.Errorf("pop"))
+
+	_, err := txHelper.EnrichEvent(ctx, newTestTransferEvent(transfer))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestEnrichTokenTransferToFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	identityID := fftypes.NewUUID()
+	transfer := &fftypes.TokenTransfer{LocalID: fftypes.NewUUID(), To: "0x222"}
+	mdi.On("GetTokenTransfer", mock.Anything, transfer.LocalID).Return(transfer, nil)
+	mdi.On("GetVerifiers", mock.Anything, mock.Anything).Return([]*fftypes.Verifier{{Identity: identityID}}, nil, nil)
+	mdi.On("GetIdentityByID", mock.Anything, identityID).Return(nil, fmt.Errorf("pop"))
+
+	_, err := txHelper.EnrichEvent(ctx, newTestTransferEvent(transfer))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestEnrichTokenTransferMessageFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	transfer := &fftypes.TokenTransfer{LocalID: fftypes.NewUUID(), Message: fftypes.NewUUID()}
+	mdi.On("GetTokenTransfer", mock.Anything, transfer.LocalID).Return(transfer, nil)
+	mdm.On("GetMessageWithDataCached", mock.Anything, transfer.Message).Return(nil, nil, false, fmt.Errorf("pop"))
+
+	_, err := txHelper.EnrichEvent(ctx, newTestTransferEvent(transfer))
+	assert.EqualError(t, err, "pop")
+}
+
+func TestEnrichTokenApprovalConfirmed(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetTokenApproval", mock.Anything, ref1).Return(&fftypes.TokenApproval{
+		LocalID:   ref1,
+		Allowance: fftypes.NewFFBigInt(100),
+	}, nil)
+
+	event := &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeApprovalConfirmed,
+		Reference: ref1,
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.TokenApproval.LocalID)
+}
+
+func TestEnrichTokenApprovalConfirmedFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetTokenApproval", mock.Anything, ref1).Return(nil, fmt.Errorf("pop"))
+
+	event := &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeApprovalConfirmed,
+		Reference: ref1,
+	}
+
+	_, err := txHelper.EnrichEvent(ctx, event)
+	assert.EqualError(t, err, "pop")
+}
+
+func TestEnrichTokenMetadataUpdated(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetTokenMetadataByID", mock.Anything, ref1).Return(&fftypes.TokenMetadata{
+		ID:         ref1,
+		TokenIndex: "1",
+	}, nil)
+
+	event := &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeTokenMetadataUpdated,
+		Reference: ref1,
+	}
+
+	enriched, err := txHelper.EnrichEvent(ctx, event)
+	assert.NoError(t, err)
+	assert.Equal(t, ref1, enriched.TokenMetadata.ID)
+}
+
+func TestEnrichTokenMetadataUpdatedFail(t *testing.T) {
+	mdi := &databasemocks.Plugin{}
+	mdm := &datamocks.Manager{}
+	txHelper := NewTransactionHelper(mdi, mdm)
+	ctx := context.Background()
+
+	ref1 := fftypes.NewUUID()
+	mdi.On("GetTokenMetadataByID", mock.Anything, ref1).Return(nil, fmt.Errorf("pop"))
+
+	event := &fftypes.Event{
+		ID:        fftypes.NewUUID(),
+		Type:      fftypes.EventTypeTokenMetadataUpdated,
+		Reference: ref1,
+	}
+
+	_, err := txHelper.EnrichEvent(ctx, event)
+	assert.EqualError(t, err, "pop")
+}
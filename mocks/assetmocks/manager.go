@@ -78,6 +78,29 @@ func (_m *Manager) CreateTokenPool(ctx context.Context, ns string, pool *fftypes
 	return r0, r1
 }
 
+// GetCounterpartyKeys provides a mock function with given fields: ctx, ns, counterparty
+func (_m *Manager) GetCounterpartyKeys(ctx context.Context, ns string, counterparty string) ([]string, error) {
+	ret := _m.Called(ctx, ns, counterparty)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []string); ok {
+		r0 = rf(ctx, ns, counterparty)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, counterparty)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTokenAccountPools provides a mock function with given fields: ctx, ns, key, filter
 func (_m *Manager) GetTokenAccountPools(ctx context.Context, ns string, key string, filter database.AndFilter) ([]*fftypes.TokenAccountPool, *database.FilterResult, error) {
 	ret := _m.Called(ctx, ns, key, filter)
@@ -229,6 +252,61 @@ func (_m *Manager) GetTokenConnectors(ctx context.Context, ns string) ([]*fftype
 	return r0, r1
 }
 
+// GetTokenMetadataList provides a mock function with given fields: ctx, ns, poolNameOrID, filter
+func (_m *Manager) GetTokenMetadataList(ctx context.Context, ns string, poolNameOrID string, filter database.AndFilter) ([]*fftypes.TokenMetadata, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, poolNameOrID, filter)
+
+	var r0 []*fftypes.TokenMetadata
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, database.AndFilter) []*fftypes.TokenMetadata); ok {
+		r0 = rf(ctx, ns, poolNameOrID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.TokenMetadata)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, poolNameOrID, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, poolNameOrID, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetTokenMetadataValue provides a mock function with given fields: ctx, ns, poolNameOrID, tokenIndex
+func (_m *Manager) GetTokenMetadataValue(ctx context.Context, ns string, poolNameOrID string, tokenIndex string) (*fftypes.JSONAny, error) {
+	ret := _m.Called(ctx, ns, poolNameOrID, tokenIndex)
+
+	var r0 *fftypes.JSONAny
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *fftypes.JSONAny); ok {
+		r0 = rf(ctx, ns, poolNameOrID, tokenIndex)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.JSONAny)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, ns, poolNameOrID, tokenIndex)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTokenPool provides a mock function with given fields: ctx, ns, connector, poolName
 func (_m *Manager) GetTokenPool(ctx context.Context, ns string, connector string, poolName string) (*fftypes.TokenPool, error) {
 	ret := _m.Called(ctx, ns, connector, poolName)
@@ -475,6 +553,29 @@ func (_m *Manager) RunOperation(ctx context.Context, op *fftypes.PreparedOperati
 	return r0, r1
 }
 
+// SetTokenMetadata provides a mock function with given fields: ctx, ns, poolNameOrID, tokenIndex, input
+func (_m *Manager) SetTokenMetadata(ctx context.Context, ns string, poolNameOrID string, tokenIndex string, input *fftypes.TokenMetadataInput) (*fftypes.TokenMetadata, error) {
+	ret := _m.Called(ctx, ns, poolNameOrID, tokenIndex, input)
+
+	var r0 *fftypes.TokenMetadata
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, *fftypes.TokenMetadataInput) *fftypes.TokenMetadata); ok {
+		r0 = rf(ctx, ns, poolNameOrID, tokenIndex, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.TokenMetadata)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, *fftypes.TokenMetadataInput) error); ok {
+		r1 = rf(ctx, ns, poolNameOrID, tokenIndex, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // TokenApproval provides a mock function with given fields: ctx, ns, approval, waitConfirm
 func (_m *Manager) TokenApproval(ctx context.Context, ns string, approval *fftypes.TokenApprovalInput, waitConfirm bool) (*fftypes.TokenApproval, error) {
 	ret := _m.Called(ctx, ns, approval, waitConfirm)
@@ -35,6 +35,22 @@ func (_m *Manager) NewMessages() chan<- int64 {
 	return r0
 }
 
+// QueueDepths provides a mock function with given fields:
+func (_m *Manager) QueueDepths() []*fftypes.BatchQueueDepth {
+	ret := _m.Called()
+
+	var r0 []*fftypes.BatchQueueDepth
+	if rf, ok := ret.Get(0).(func() []*fftypes.BatchQueueDepth); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.BatchQueueDepth)
+		}
+	}
+
+	return r0
+}
+
 // RegisterDispatcher provides a mock function with given fields: name, txType, msgTypes, handler, batchOptions
 func (_m *Manager) RegisterDispatcher(name string, txType fftypes.FFEnum, msgTypes []fftypes.FFEnum, handler batch.DispatchHandler, batchOptions batch.DispatcherOptions) {
 	_m.Called(name, txType, msgTypes, handler, batchOptions)
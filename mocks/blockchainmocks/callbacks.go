@@ -42,13 +42,27 @@ func (_m *Callbacks) BlockchainEvent(event *blockchain.EventWithSubscription) er
 	return r0
 }
 
-// BlockchainOpUpdate provides a mock function with given fields: operationID, txState, blockchainTXID, errorMessage, opOutput
-func (_m *Callbacks) BlockchainOpUpdate(operationID *fftypes.UUID, txState fftypes.OpStatus, blockchainTXID string, errorMessage string, opOutput fftypes.JSONObject) error {
-	ret := _m.Called(operationID, txState, blockchainTXID, errorMessage, opOutput)
+// BlockchainOpUpdate provides a mock function with given fields: operationID, tx, errorMessage, opOutput, receipt
+func (_m *Callbacks) BlockchainOpUpdate(operationID *fftypes.UUID, tx *fftypes.BlockchainTransaction, errorMessage string, opOutput fftypes.JSONObject, receipt *fftypes.BlockchainReceipt) error {
+	ret := _m.Called(operationID, tx, errorMessage, opOutput, receipt)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*fftypes.UUID, fftypes.OpStatus, string, string, fftypes.JSONObject) error); ok {
-		r0 = rf(operationID, txState, blockchainTXID, errorMessage, opOutput)
+	if rf, ok := ret.Get(0).(func(*fftypes.UUID, *fftypes.BlockchainTransaction, string, fftypes.JSONObject, *fftypes.BlockchainReceipt) error); ok {
+		r0 = rf(operationID, tx, errorMessage, opOutput, receipt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ChainReorg provides a mock function with given fields: reorg
+func (_m *Callbacks) ChainReorg(reorg *blockchain.Reorg) error {
+	ret := _m.Called(reorg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*blockchain.Reorg) error); ok {
+		r0 = rf(reorg)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -18,6 +18,29 @@ type Plugin struct {
 	mock.Mock
 }
 
+// ActivateBatchPinMigration provides a mock function with given fields: ctx
+func (_m *Plugin) ActivateBatchPinMigration(ctx context.Context) (*fftypes.BatchPinMigration, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.BatchPinMigration
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.BatchPinMigration); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.BatchPinMigration)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddContractListener provides a mock function with given fields: ctx, subscription
 func (_m *Plugin) AddContractListener(ctx context.Context, subscription *fftypes.ContractListenerInput) error {
 	ret := _m.Called(ctx, subscription)
@@ -85,6 +108,22 @@ func (_m *Plugin) GenerateFFI(ctx context.Context, generationRequest *fftypes.FF
 	return r0, r1
 }
 
+// GetBatchPinMigration provides a mock function with given fields: ctx
+func (_m *Plugin) GetBatchPinMigration(ctx context.Context) *fftypes.BatchPinMigration {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.BatchPinMigration
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.BatchPinMigration); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.BatchPinMigration)
+		}
+	}
+
+	return r0
+}
+
 // GetFFIParamValidator provides a mock function with given fields: ctx
 func (_m *Plugin) GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error) {
 	ret := _m.Called(ctx)
@@ -127,13 +166,13 @@ func (_m *Plugin) InitPrefix(prefix config.Prefix) {
 	_m.Called(prefix)
 }
 
-// InvokeContract provides a mock function with given fields: ctx, operationID, signingKey, location, method, input
-func (_m *Plugin) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) error {
-	ret := _m.Called(ctx, operationID, signingKey, location, method, input)
+// InvokeContract provides a mock function with given fields: ctx, operationID, signingKey, location, method, input, errors
+func (_m *Plugin) InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors fftypes.FFIErrors) error {
+	ret := _m.Called(ctx, operationID, signingKey, location, method, input, errors)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, string, *fftypes.JSONAny, *fftypes.FFIMethod, map[string]interface{}) error); ok {
-		r0 = rf(ctx, operationID, signingKey, location, method, input)
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, string, *fftypes.JSONAny, *fftypes.FFIMethod, map[string]interface{}, fftypes.FFIErrors) error); ok {
+		r0 = rf(ctx, operationID, signingKey, location, method, input, errors)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -199,6 +238,27 @@ func (_m *Plugin) QueryContract(ctx context.Context, location *fftypes.JSONAny,
 	return r0, r1
 }
 
+// RewindContractListener provides a mock function with given fields: ctx, subscription, blockNumber
+func (_m *Plugin) RewindContractListener(ctx context.Context, subscription *fftypes.ContractListener, blockNumber uint64) (string, error) {
+	ret := _m.Called(ctx, subscription, blockNumber)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.ContractListener, uint64) string); ok {
+		r0 = rf(ctx, subscription, blockNumber)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.ContractListener, uint64) error); ok {
+		r1 = rf(ctx, subscription, blockNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Start provides a mock function with given fields:
 func (_m *Plugin) Start() error {
 	ret := _m.Called()
@@ -9,6 +9,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	sysmessaging "github.com/hyperledger/firefly/internal/sysmessaging"
+
+	time "time"
 )
 
 // Manager is an autogenerated mock type for the Manager type
@@ -39,6 +41,29 @@ func (_m *Manager) BroadcastDatatype(ctx context.Context, ns string, datatype *f
 	return r0, r1
 }
 
+// BroadcastDatatypeBundle provides a mock function with given fields: ctx, ns, bundle, waitConfirm
+func (_m *Manager) BroadcastDatatypeBundle(ctx context.Context, ns string, bundle *fftypes.DatatypeBundle, waitConfirm bool) (*fftypes.Message, error) {
+	ret := _m.Called(ctx, ns, bundle, waitConfirm)
+
+	var r0 *fftypes.Message
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.DatatypeBundle, bool) *fftypes.Message); ok {
+		r0 = rf(ctx, ns, bundle, waitConfirm)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Message)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.DatatypeBundle, bool) error); ok {
+		r1 = rf(ctx, ns, bundle, waitConfirm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BroadcastDefinition provides a mock function with given fields: ctx, ns, def, signingIdentity, tag, waitConfirm
 func (_m *Manager) BroadcastDefinition(ctx context.Context, ns string, def fftypes.Definition, signingIdentity *fftypes.SignerRef, tag string, waitConfirm bool) (*fftypes.Message, error) {
 	ret := _m.Called(ctx, ns, def, signingIdentity, tag, waitConfirm)
@@ -177,6 +202,29 @@ func (_m *Manager) BroadcastTokenPool(ctx context.Context, ns string, pool *ffty
 	return r0, r1
 }
 
+// GetAnonymousAuthor provides a mock function with given fields: ctx, ns, id
+func (_m *Manager) GetAnonymousAuthor(ctx context.Context, ns string, id string) (*fftypes.SignerRef, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.SignerRef
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.SignerRef); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.SignerRef)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Name provides a mock function with given fields:
 func (_m *Manager) Name() string {
 	ret := _m.Called()
@@ -230,6 +278,29 @@ func (_m *Manager) PrepareOperation(ctx context.Context, op *fftypes.Operation)
 	return r0, r1
 }
 
+// RequestReply provides a mock function with given fields: ctx, ns, in, timeout
+func (_m *Manager) RequestReply(ctx context.Context, ns string, in *fftypes.MessageInOut, timeout time.Duration) (*fftypes.MessageInOut, error) {
+	ret := _m.Called(ctx, ns, in, timeout)
+
+	var r0 *fftypes.MessageInOut
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.MessageInOut, time.Duration) *fftypes.MessageInOut); ok {
+		r0 = rf(ctx, ns, in, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageInOut)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.MessageInOut, time.Duration) error); ok {
+		r1 = rf(ctx, ns, in, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RunOperation provides a mock function with given fields: ctx, op
 func (_m *Manager) RunOperation(ctx context.Context, op *fftypes.PreparedOperation) (bool, error) {
 	ret := _m.Called(ctx, op)
@@ -265,6 +336,22 @@ func (_m *Manager) Start() error {
 	return r0
 }
 
+// ValidateMessage provides a mock function with given fields: ctx, ns, in
+func (_m *Manager) ValidateMessage(ctx context.Context, ns string, in *fftypes.MessageInOut) *fftypes.MessageValidationReport {
+	ret := _m.Called(ctx, ns, in)
+
+	var r0 *fftypes.MessageValidationReport
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.MessageInOut) *fftypes.MessageValidationReport); ok {
+		r0 = rf(ctx, ns, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageValidationReport)
+		}
+	}
+
+	return r0
+}
+
 // WaitStop provides a mock function with given fields:
 func (_m *Manager) WaitStop() {
 	_m.Called()
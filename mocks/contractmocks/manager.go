@@ -417,6 +417,29 @@ func (_m *Manager) PrepareOperation(ctx context.Context, op *fftypes.Operation)
 	return r0, r1
 }
 
+// RewindContractListener provides a mock function with given fields: ctx, ns, nameOrID, rewind
+func (_m *Manager) RewindContractListener(ctx context.Context, ns string, nameOrID string, rewind *fftypes.ContractListenerRewind) (*fftypes.ContractListener, error) {
+	ret := _m.Called(ctx, ns, nameOrID, rewind)
+
+	var r0 *fftypes.ContractListener
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.ContractListenerRewind) *fftypes.ContractListener); ok {
+		r0 = rf(ctx, ns, nameOrID, rewind)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.ContractListener)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *fftypes.ContractListenerRewind) error); ok {
+		r1 = rf(ctx, ns, nameOrID, rewind)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RunOperation provides a mock function with given fields: ctx, op
 func (_m *Manager) RunOperation(ctx context.Context, op *fftypes.PreparedOperation) (bool, error) {
 	ret := _m.Called(ctx, op)
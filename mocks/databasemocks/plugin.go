@@ -35,6 +35,20 @@ func (_m *Plugin) Capabilities() *database.Capabilities {
 	return r0
 }
 
+// DeleteAlias provides a mock function with given fields: ctx, id
+func (_m *Plugin) DeleteAlias(ctx context.Context, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteBlob provides a mock function with given fields: ctx, sequence
 func (_m *Plugin) DeleteBlob(ctx context.Context, sequence int64) error {
 	ret := _m.Called(ctx, sequence)
@@ -77,6 +91,34 @@ func (_m *Plugin) DeleteContractListenerByID(ctx context.Context, id *fftypes.UU
 	return r0
 }
 
+// DeleteEvents provides a mock function with given fields: ctx, ids
+func (_m *Plugin) DeleteEvents(ctx context.Context, ids []*fftypes.UUID) error {
+	ret := _m.Called(ctx, ids)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []*fftypes.UUID) error); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteMessageTemplate provides a mock function with given fields: ctx, id
+func (_m *Plugin) DeleteMessageTemplate(ctx context.Context, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteNamespace provides a mock function with given fields: ctx, id
 func (_m *Plugin) DeleteNamespace(ctx context.Context, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, id)
@@ -147,6 +189,34 @@ func (_m *Plugin) DeletePin(ctx context.Context, sequence int64) error {
 	return r0
 }
 
+// DeleteQuarantinedMessage provides a mock function with given fields: ctx, id
+func (_m *Plugin) DeleteQuarantinedMessage(ctx context.Context, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSavedQuery provides a mock function with given fields: ctx, id
+func (_m *Plugin) DeleteSavedQuery(ctx context.Context, id *fftypes.UUID) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteSubscriptionByID provides a mock function with given fields: ctx, id
 func (_m *Plugin) DeleteSubscriptionByID(ctx context.Context, id *fftypes.UUID) error {
 	ret := _m.Called(ctx, id)
@@ -161,16 +231,16 @@ func (_m *Plugin) DeleteSubscriptionByID(ctx context.Context, id *fftypes.UUID)
 	return r0
 }
 
-// GetBatchByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetBatchByID(ctx context.Context, id *fftypes.UUID) (*fftypes.BatchPersisted, error) {
+// GetGovernanceProposalByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetGovernanceProposalByID(ctx context.Context, id *fftypes.UUID) (*fftypes.GovernanceProposal, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.BatchPersisted
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.BatchPersisted); ok {
+	var r0 *fftypes.GovernanceProposal
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.GovernanceProposal); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.BatchPersisted)
+			r0 = ret.Get(0).(*fftypes.GovernanceProposal)
 		}
 	}
 
@@ -184,16 +254,16 @@ func (_m *Plugin) GetBatchByID(ctx context.Context, id *fftypes.UUID) (*fftypes.
 	return r0, r1
 }
 
-// GetBatches provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetBatches(ctx context.Context, filter database.Filter) ([]*fftypes.BatchPersisted, *database.FilterResult, error) {
+// GetGovernanceProposals provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetGovernanceProposals(ctx context.Context, filter database.Filter) ([]*fftypes.GovernanceProposal, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.BatchPersisted
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.BatchPersisted); ok {
+	var r0 []*fftypes.GovernanceProposal
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.GovernanceProposal); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.BatchPersisted)
+			r0 = ret.Get(0).([]*fftypes.GovernanceProposal)
 		}
 	}
 
@@ -216,39 +286,48 @@ func (_m *Plugin) GetBatches(ctx context.Context, filter database.Filter) ([]*ff
 	return r0, r1, r2
 }
 
-// GetBlobMatchingHash provides a mock function with given fields: ctx, hash
-func (_m *Plugin) GetBlobMatchingHash(ctx context.Context, hash *fftypes.Bytes32) (*fftypes.Blob, error) {
-	ret := _m.Called(ctx, hash)
+// GetGovernanceVotes provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetGovernanceVotes(ctx context.Context, filter database.Filter) ([]*fftypes.GovernanceVote, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
 
-	var r0 *fftypes.Blob
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) *fftypes.Blob); ok {
-		r0 = rf(ctx, hash)
+	var r0 []*fftypes.GovernanceVote
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.GovernanceVote); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Blob)
+			r0 = ret.Get(0).([]*fftypes.GovernanceVote)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32) error); ok {
-		r1 = rf(ctx, hash)
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
-// GetBlobs provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetBlobs(ctx context.Context, filter database.Filter) ([]*fftypes.Blob, *database.FilterResult, error) {
+// GetIdentityEndorsements provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetIdentityEndorsements(ctx context.Context, filter database.Filter) ([]*fftypes.IdentityEndorsement, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Blob
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Blob); ok {
+	var r0 []*fftypes.IdentityEndorsement
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.IdentityEndorsement); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Blob)
+			r0 = ret.Get(0).([]*fftypes.IdentityEndorsement)
 		}
 	}
 
@@ -271,16 +350,16 @@ func (_m *Plugin) GetBlobs(ctx context.Context, filter database.Filter) ([]*ffty
 	return r0, r1, r2
 }
 
-// GetBlockchainEventByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetBlockchainEventByID(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEvent, error) {
+// GetInvitationByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetInvitationByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Invitation, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.BlockchainEvent
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.BlockchainEvent); ok {
+	var r0 *fftypes.Invitation
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Invitation); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.BlockchainEvent)
+			r0 = ret.Get(0).(*fftypes.Invitation)
 		}
 	}
 
@@ -294,16 +373,16 @@ func (_m *Plugin) GetBlockchainEventByID(ctx context.Context, id *fftypes.UUID)
 	return r0, r1
 }
 
-// GetBlockchainEvents provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetBlockchainEvents(ctx context.Context, filter database.Filter) ([]*fftypes.BlockchainEvent, *database.FilterResult, error) {
+// GetInvitations provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetInvitations(ctx context.Context, filter database.Filter) ([]*fftypes.Invitation, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.BlockchainEvent
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.BlockchainEvent); ok {
+	var r0 []*fftypes.Invitation
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Invitation); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.BlockchainEvent)
+			r0 = ret.Get(0).([]*fftypes.Invitation)
 		}
 	}
 
@@ -326,45 +405,22 @@ func (_m *Plugin) GetBlockchainEvents(ctx context.Context, filter database.Filte
 	return r0, r1, r2
 }
 
-// GetChartHistogram provides a mock function with given fields: ctx, ns, intervals, collection
-func (_m *Plugin) GetChartHistogram(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, collection database.CollectionName) ([]*fftypes.ChartHistogram, error) {
-	ret := _m.Called(ctx, ns, intervals, collection)
-
-	var r0 []*fftypes.ChartHistogram
-	if rf, ok := ret.Get(0).(func(context.Context, string, []fftypes.ChartHistogramInterval, database.CollectionName) []*fftypes.ChartHistogram); ok {
-		r0 = rf(ctx, ns, intervals, collection)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.ChartHistogram)
-		}
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, []fftypes.ChartHistogramInterval, database.CollectionName) error); ok {
-		r1 = rf(ctx, ns, intervals, collection)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
-// GetConfigRecord provides a mock function with given fields: ctx, key
-func (_m *Plugin) GetConfigRecord(ctx context.Context, key string) (*fftypes.ConfigRecord, error) {
-	ret := _m.Called(ctx, key)
+// GetLegalHoldByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetLegalHoldByID(ctx context.Context, id *fftypes.UUID) (*fftypes.LegalHold, error) {
+	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.ConfigRecord
-	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.ConfigRecord); ok {
-		r0 = rf(ctx, key)
+	var r0 *fftypes.LegalHold
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.LegalHold); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.ConfigRecord)
+			r0 = ret.Get(0).(*fftypes.LegalHold)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, key)
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -372,16 +428,16 @@ func (_m *Plugin) GetConfigRecord(ctx context.Context, key string) (*fftypes.Con
 	return r0, r1
 }
 
-// GetConfigRecords provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetConfigRecords(ctx context.Context, filter database.Filter) ([]*fftypes.ConfigRecord, *database.FilterResult, error) {
+// GetLegalHolds provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetLegalHolds(ctx context.Context, filter database.Filter) ([]*fftypes.LegalHold, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.ConfigRecord
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.ConfigRecord); ok {
+	var r0 []*fftypes.LegalHold
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.LegalHold); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.ConfigRecord)
+			r0 = ret.Get(0).([]*fftypes.LegalHold)
 		}
 	}
 
@@ -404,39 +460,48 @@ func (_m *Plugin) GetConfigRecords(ctx context.Context, filter database.Filter)
 	return r0, r1, r2
 }
 
-// GetContractAPIByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetContractAPIByID(ctx context.Context, id *fftypes.UUID) (*fftypes.ContractAPI, error) {
-	ret := _m.Called(ctx, id)
+// GetMessageApprovals provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetMessageApprovals(ctx context.Context, filter database.Filter) ([]*fftypes.MessageApproval, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
 
-	var r0 *fftypes.ContractAPI
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.ContractAPI); ok {
-		r0 = rf(ctx, id)
+	var r0 []*fftypes.MessageApproval
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.MessageApproval); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.ContractAPI)
+			r0 = ret.Get(0).([]*fftypes.MessageApproval)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
-		r1 = rf(ctx, id)
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
-// GetContractAPIByName provides a mock function with given fields: ctx, ns, name
-func (_m *Plugin) GetContractAPIByName(ctx context.Context, ns string, name string) (*fftypes.ContractAPI, error) {
+// GetAliasByName provides a mock function with given fields: ctx, ns, name
+func (_m *Plugin) GetAliasByName(ctx context.Context, ns string, name string) (*fftypes.Alias, error) {
 	ret := _m.Called(ctx, ns, name)
 
-	var r0 *fftypes.ContractAPI
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.ContractAPI); ok {
+	var r0 *fftypes.Alias
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Alias); ok {
 		r0 = rf(ctx, ns, name)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.ContractAPI)
+			r0 = ret.Get(0).(*fftypes.Alias)
 		}
 	}
 
@@ -450,22 +515,22 @@ func (_m *Plugin) GetContractAPIByName(ctx context.Context, ns string, name stri
 	return r0, r1
 }
 
-// GetContractAPIs provides a mock function with given fields: ctx, ns, filter
-func (_m *Plugin) GetContractAPIs(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.ContractAPI, *database.FilterResult, error) {
-	ret := _m.Called(ctx, ns, filter)
+// GetAliases provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetAliases(ctx context.Context, filter database.Filter) ([]*fftypes.Alias, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.ContractAPI
-	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.ContractAPI); ok {
-		r0 = rf(ctx, ns, filter)
+	var r0 []*fftypes.Alias
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Alias); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.ContractAPI)
+			r0 = ret.Get(0).([]*fftypes.Alias)
 		}
 	}
 
 	var r1 *database.FilterResult
-	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
-		r1 = rf(ctx, ns, filter)
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*database.FilterResult)
@@ -473,8 +538,8 @@ func (_m *Plugin) GetContractAPIs(ctx context.Context, ns string, filter databas
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
-		r2 = rf(ctx, ns, filter)
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -482,39 +547,16 @@ func (_m *Plugin) GetContractAPIs(ctx context.Context, ns string, filter databas
 	return r0, r1, r2
 }
 
-// GetContractListener provides a mock function with given fields: ctx, ns, name
-func (_m *Plugin) GetContractListener(ctx context.Context, ns string, name string) (*fftypes.ContractListener, error) {
-	ret := _m.Called(ctx, ns, name)
-
-	var r0 *fftypes.ContractListener
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.ContractListener); ok {
-		r0 = rf(ctx, ns, name)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.ContractListener)
-		}
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, ns, name)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
-// GetContractListenerByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetContractListenerByID(ctx context.Context, id *fftypes.UUID) (*fftypes.ContractListener, error) {
+// GetBatchByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetBatchByID(ctx context.Context, id *fftypes.UUID) (*fftypes.BatchPersisted, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.ContractListener
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.ContractListener); ok {
+	var r0 *fftypes.BatchPersisted
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.BatchPersisted); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.ContractListener)
+			r0 = ret.Get(0).(*fftypes.BatchPersisted)
 		}
 	}
 
@@ -528,45 +570,22 @@ func (_m *Plugin) GetContractListenerByID(ctx context.Context, id *fftypes.UUID)
 	return r0, r1
 }
 
-// GetContractListenerByProtocolID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetContractListenerByProtocolID(ctx context.Context, id string) (*fftypes.ContractListener, error) {
-	ret := _m.Called(ctx, id)
+// GetBatches provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetBatches(ctx context.Context, filter database.Filter) ([]*fftypes.BatchPersisted, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
 
-	var r0 *fftypes.ContractListener
-	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.ContractListener); ok {
-		r0 = rf(ctx, id)
+	var r0 []*fftypes.BatchPersisted
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.BatchPersisted); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.ContractListener)
+			r0 = ret.Get(0).([]*fftypes.BatchPersisted)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, id)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
-// GetContractListeners provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetContractListeners(ctx context.Context, filter database.Filter) ([]*fftypes.ContractListener, *database.FilterResult, error) {
-	ret := _m.Called(ctx, filter)
-
-	var r0 []*fftypes.ContractListener
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.ContractListener); ok {
-		r0 = rf(ctx, filter)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.ContractListener)
-		}
-	}
-
-	var r1 *database.FilterResult
-	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
-		r1 = rf(ctx, filter)
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*database.FilterResult)
@@ -583,16 +602,39 @@ func (_m *Plugin) GetContractListeners(ctx context.Context, filter database.Filt
 	return r0, r1, r2
 }
 
-// GetData provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetData(ctx context.Context, filter database.Filter) (fftypes.DataArray, *database.FilterResult, error) {
+// GetBlobMatchingHash provides a mock function with given fields: ctx, hash
+func (_m *Plugin) GetBlobMatchingHash(ctx context.Context, hash *fftypes.Bytes32) (*fftypes.Blob, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 *fftypes.Blob
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) *fftypes.Blob); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Blob)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBlobs provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetBlobs(ctx context.Context, filter database.Filter) ([]*fftypes.Blob, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 fftypes.DataArray
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) fftypes.DataArray); ok {
+	var r0 []*fftypes.Blob
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Blob); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(fftypes.DataArray)
+			r0 = ret.Get(0).([]*fftypes.Blob)
 		}
 	}
 
@@ -615,22 +657,22 @@ func (_m *Plugin) GetData(ctx context.Context, filter database.Filter) (fftypes.
 	return r0, r1, r2
 }
 
-// GetDataByID provides a mock function with given fields: ctx, id, withValue
-func (_m *Plugin) GetDataByID(ctx context.Context, id *fftypes.UUID, withValue bool) (*fftypes.Data, error) {
-	ret := _m.Called(ctx, id, withValue)
+// GetBlockchainEventByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetBlockchainEventByID(ctx context.Context, id *fftypes.UUID) (*fftypes.BlockchainEvent, error) {
+	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.Data
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, bool) *fftypes.Data); ok {
-		r0 = rf(ctx, id, withValue)
+	var r0 *fftypes.BlockchainEvent
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.BlockchainEvent); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Data)
+			r0 = ret.Get(0).(*fftypes.BlockchainEvent)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID, bool) error); ok {
-		r1 = rf(ctx, id, withValue)
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -638,16 +680,16 @@ func (_m *Plugin) GetDataByID(ctx context.Context, id *fftypes.UUID, withValue b
 	return r0, r1
 }
 
-// GetDataRefs provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetDataRefs(ctx context.Context, filter database.Filter) (fftypes.DataRefs, *database.FilterResult, error) {
+// GetBlockchainEvents provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetBlockchainEvents(ctx context.Context, filter database.Filter) ([]*fftypes.BlockchainEvent, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 fftypes.DataRefs
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) fftypes.DataRefs); ok {
+	var r0 []*fftypes.BlockchainEvent
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.BlockchainEvent); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(fftypes.DataRefs)
+			r0 = ret.Get(0).([]*fftypes.BlockchainEvent)
 		}
 	}
 
@@ -670,22 +712,22 @@ func (_m *Plugin) GetDataRefs(ctx context.Context, filter database.Filter) (ffty
 	return r0, r1, r2
 }
 
-// GetDatatypeByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetDatatypeByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Datatype, error) {
-	ret := _m.Called(ctx, id)
+// GetChartHistogram provides a mock function with given fields: ctx, ns, intervals, collection
+func (_m *Plugin) GetChartHistogram(ctx context.Context, ns string, intervals []fftypes.ChartHistogramInterval, collection database.CollectionName) ([]*fftypes.ChartHistogram, error) {
+	ret := _m.Called(ctx, ns, intervals, collection)
 
-	var r0 *fftypes.Datatype
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Datatype); ok {
-		r0 = rf(ctx, id)
+	var r0 []*fftypes.ChartHistogram
+	if rf, ok := ret.Get(0).(func(context.Context, string, []fftypes.ChartHistogramInterval, database.CollectionName) []*fftypes.ChartHistogram); ok {
+		r0 = rf(ctx, ns, intervals, collection)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Datatype)
+			r0 = ret.Get(0).([]*fftypes.ChartHistogram)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, string, []fftypes.ChartHistogramInterval, database.CollectionName) error); ok {
+		r1 = rf(ctx, ns, intervals, collection)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -693,22 +735,22 @@ func (_m *Plugin) GetDatatypeByID(ctx context.Context, id *fftypes.UUID) (*fftyp
 	return r0, r1
 }
 
-// GetDatatypeByName provides a mock function with given fields: ctx, ns, name, version
-func (_m *Plugin) GetDatatypeByName(ctx context.Context, ns string, name string, version string) (*fftypes.Datatype, error) {
-	ret := _m.Called(ctx, ns, name, version)
+// GetConfigRecord provides a mock function with given fields: ctx, key
+func (_m *Plugin) GetConfigRecord(ctx context.Context, key string) (*fftypes.ConfigRecord, error) {
+	ret := _m.Called(ctx, key)
 
-	var r0 *fftypes.Datatype
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *fftypes.Datatype); ok {
-		r0 = rf(ctx, ns, name, version)
+	var r0 *fftypes.ConfigRecord
+	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.ConfigRecord); ok {
+		r0 = rf(ctx, key)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Datatype)
+			r0 = ret.Get(0).(*fftypes.ConfigRecord)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
-		r1 = rf(ctx, ns, name, version)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -716,16 +758,16 @@ func (_m *Plugin) GetDatatypeByName(ctx context.Context, ns string, name string,
 	return r0, r1
 }
 
-// GetDatatypes provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetDatatypes(ctx context.Context, filter database.Filter) ([]*fftypes.Datatype, *database.FilterResult, error) {
+// GetConfigRecords provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetConfigRecords(ctx context.Context, filter database.Filter) ([]*fftypes.ConfigRecord, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Datatype
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Datatype); ok {
+	var r0 []*fftypes.ConfigRecord
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.ConfigRecord); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Datatype)
+			r0 = ret.Get(0).([]*fftypes.ConfigRecord)
 		}
 	}
 
@@ -748,16 +790,16 @@ func (_m *Plugin) GetDatatypes(ctx context.Context, filter database.Filter) ([]*
 	return r0, r1, r2
 }
 
-// GetEventByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetEventByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Event, error) {
+// GetContractAPIByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetContractAPIByID(ctx context.Context, id *fftypes.UUID) (*fftypes.ContractAPI, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.Event
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Event); ok {
+	var r0 *fftypes.ContractAPI
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.ContractAPI); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Event)
+			r0 = ret.Get(0).(*fftypes.ContractAPI)
 		}
 	}
 
@@ -771,22 +813,45 @@ func (_m *Plugin) GetEventByID(ctx context.Context, id *fftypes.UUID) (*fftypes.
 	return r0, r1
 }
 
-// GetEvents provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetEvents(ctx context.Context, filter database.Filter) ([]*fftypes.Event, *database.FilterResult, error) {
-	ret := _m.Called(ctx, filter)
+// GetContractAPIByName provides a mock function with given fields: ctx, ns, name
+func (_m *Plugin) GetContractAPIByName(ctx context.Context, ns string, name string) (*fftypes.ContractAPI, error) {
+	ret := _m.Called(ctx, ns, name)
 
-	var r0 []*fftypes.Event
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Event); ok {
-		r0 = rf(ctx, filter)
+	var r0 *fftypes.ContractAPI
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.ContractAPI); ok {
+		r0 = rf(ctx, ns, name)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Event)
+			r0 = ret.Get(0).(*fftypes.ContractAPI)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetContractAPIs provides a mock function with given fields: ctx, ns, filter
+func (_m *Plugin) GetContractAPIs(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.ContractAPI, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.ContractAPI
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.ContractAPI); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.ContractAPI)
 		}
 	}
 
 	var r1 *database.FilterResult
-	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
-		r1 = rf(ctx, filter)
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*database.FilterResult)
@@ -794,8 +859,8 @@ func (_m *Plugin) GetEvents(ctx context.Context, filter database.Filter) ([]*fft
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
-		r2 = rf(ctx, filter)
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -803,22 +868,22 @@ func (_m *Plugin) GetEvents(ctx context.Context, filter database.Filter) ([]*fft
 	return r0, r1, r2
 }
 
-// GetFFI provides a mock function with given fields: ctx, ns, name, version
-func (_m *Plugin) GetFFI(ctx context.Context, ns string, name string, version string) (*fftypes.FFI, error) {
-	ret := _m.Called(ctx, ns, name, version)
+// GetContractListener provides a mock function with given fields: ctx, ns, name
+func (_m *Plugin) GetContractListener(ctx context.Context, ns string, name string) (*fftypes.ContractListener, error) {
+	ret := _m.Called(ctx, ns, name)
 
-	var r0 *fftypes.FFI
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *fftypes.FFI); ok {
-		r0 = rf(ctx, ns, name, version)
+	var r0 *fftypes.ContractListener
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.ContractListener); ok {
+		r0 = rf(ctx, ns, name)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.FFI)
+			r0 = ret.Get(0).(*fftypes.ContractListener)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
-		r1 = rf(ctx, ns, name, version)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -826,16 +891,16 @@ func (_m *Plugin) GetFFI(ctx context.Context, ns string, name string, version st
 	return r0, r1
 }
 
-// GetFFIByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetFFIByID(ctx context.Context, id *fftypes.UUID) (*fftypes.FFI, error) {
+// GetContractListenerByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetContractListenerByID(ctx context.Context, id *fftypes.UUID) (*fftypes.ContractListener, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.FFI
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.FFI); ok {
+	var r0 *fftypes.ContractListener
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.ContractListener); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.FFI)
+			r0 = ret.Get(0).(*fftypes.ContractListener)
 		}
 	}
 
@@ -849,44 +914,21 @@ func (_m *Plugin) GetFFIByID(ctx context.Context, id *fftypes.UUID) (*fftypes.FF
 	return r0, r1
 }
 
-// GetFFIEvent provides a mock function with given fields: ctx, ns, interfaceID, pathName
-func (_m *Plugin) GetFFIEvent(ctx context.Context, ns string, interfaceID *fftypes.UUID, pathName string) (*fftypes.FFIEvent, error) {
-	ret := _m.Called(ctx, ns, interfaceID, pathName)
-
-	var r0 *fftypes.FFIEvent
-	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, string) *fftypes.FFIEvent); ok {
-		r0 = rf(ctx, ns, interfaceID, pathName)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.FFIEvent)
-		}
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID, string) error); ok {
-		r1 = rf(ctx, ns, interfaceID, pathName)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
-// GetFFIEventByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetFFIEventByID(ctx context.Context, id *fftypes.UUID) (*fftypes.FFIEvent, error) {
+// GetContractListenerByProtocolID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetContractListenerByProtocolID(ctx context.Context, id string) (*fftypes.ContractListener, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.FFIEvent
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.FFIEvent); ok {
+	var r0 *fftypes.ContractListener
+	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.ContractListener); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.FFIEvent)
+			r0 = ret.Get(0).(*fftypes.ContractListener)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
 		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
@@ -895,16 +937,16 @@ func (_m *Plugin) GetFFIEventByID(ctx context.Context, id *fftypes.UUID) (*fftyp
 	return r0, r1
 }
 
-// GetFFIEvents provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetFFIEvents(ctx context.Context, filter database.Filter) ([]*fftypes.FFIEvent, *database.FilterResult, error) {
+// GetContractListeners provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetContractListeners(ctx context.Context, filter database.Filter) ([]*fftypes.ContractListener, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.FFIEvent
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.FFIEvent); ok {
+	var r0 []*fftypes.ContractListener
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.ContractListener); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.FFIEvent)
+			r0 = ret.Get(0).([]*fftypes.ContractListener)
 		}
 	}
 
@@ -927,22 +969,54 @@ func (_m *Plugin) GetFFIEvents(ctx context.Context, filter database.Filter) ([]*
 	return r0, r1, r2
 }
 
-// GetFFIMethod provides a mock function with given fields: ctx, ns, interfaceID, pathName
-func (_m *Plugin) GetFFIMethod(ctx context.Context, ns string, interfaceID *fftypes.UUID, pathName string) (*fftypes.FFIMethod, error) {
-	ret := _m.Called(ctx, ns, interfaceID, pathName)
+// GetData provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetData(ctx context.Context, filter database.Filter) (fftypes.DataArray, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
 
-	var r0 *fftypes.FFIMethod
-	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, string) *fftypes.FFIMethod); ok {
-		r0 = rf(ctx, ns, interfaceID, pathName)
+	var r0 fftypes.DataArray
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) fftypes.DataArray); ok {
+		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.FFIMethod)
+			r0 = ret.Get(0).(fftypes.DataArray)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetDataByID provides a mock function with given fields: ctx, id, withValue
+func (_m *Plugin) GetDataByID(ctx context.Context, id *fftypes.UUID, withValue bool) (*fftypes.Data, error) {
+	ret := _m.Called(ctx, id, withValue)
+
+	var r0 *fftypes.Data
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, bool) *fftypes.Data); ok {
+		r0 = rf(ctx, id, withValue)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Data)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID, string) error); ok {
-		r1 = rf(ctx, ns, interfaceID, pathName)
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID, bool) error); ok {
+		r1 = rf(ctx, id, withValue)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -950,16 +1024,16 @@ func (_m *Plugin) GetFFIMethod(ctx context.Context, ns string, interfaceID *ffty
 	return r0, r1
 }
 
-// GetFFIMethods provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetFFIMethods(ctx context.Context, filter database.Filter) ([]*fftypes.FFIMethod, *database.FilterResult, error) {
+// GetDataRefs provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetDataRefs(ctx context.Context, filter database.Filter) (fftypes.DataRefs, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.FFIMethod
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.FFIMethod); ok {
+	var r0 fftypes.DataRefs
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) fftypes.DataRefs); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.FFIMethod)
+			r0 = ret.Get(0).(fftypes.DataRefs)
 		}
 	}
 
@@ -982,54 +1056,45 @@ func (_m *Plugin) GetFFIMethods(ctx context.Context, filter database.Filter) ([]
 	return r0, r1, r2
 }
 
-// GetFFIs provides a mock function with given fields: ctx, ns, filter
-func (_m *Plugin) GetFFIs(ctx context.Context, ns string, filter database.Filter) ([]*fftypes.FFI, *database.FilterResult, error) {
-	ret := _m.Called(ctx, ns, filter)
+// GetDatatypeByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetDatatypeByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Datatype, error) {
+	ret := _m.Called(ctx, id)
 
-	var r0 []*fftypes.FFI
-	if rf, ok := ret.Get(0).(func(context.Context, string, database.Filter) []*fftypes.FFI); ok {
-		r0 = rf(ctx, ns, filter)
+	var r0 *fftypes.Datatype
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Datatype); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.FFI)
-		}
-	}
-
-	var r1 *database.FilterResult
-	if rf, ok := ret.Get(1).(func(context.Context, string, database.Filter) *database.FilterResult); ok {
-		r1 = rf(ctx, ns, filter)
-	} else {
-		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*database.FilterResult)
+			r0 = ret.Get(0).(*fftypes.Datatype)
 		}
 	}
 
-	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context, string, database.Filter) error); ok {
-		r2 = rf(ctx, ns, filter)
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
 	} else {
-		r2 = ret.Error(2)
+		r1 = ret.Error(1)
 	}
 
-	return r0, r1, r2
+	return r0, r1
 }
 
-// GetGroupByHash provides a mock function with given fields: ctx, hash
-func (_m *Plugin) GetGroupByHash(ctx context.Context, hash *fftypes.Bytes32) (*fftypes.Group, error) {
-	ret := _m.Called(ctx, hash)
+// GetDatatypeByName provides a mock function with given fields: ctx, ns, name, version
+func (_m *Plugin) GetDatatypeByName(ctx context.Context, ns string, name string, version string) (*fftypes.Datatype, error) {
+	ret := _m.Called(ctx, ns, name, version)
 
-	var r0 *fftypes.Group
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) *fftypes.Group); ok {
-		r0 = rf(ctx, hash)
+	var r0 *fftypes.Datatype
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *fftypes.Datatype); ok {
+		r0 = rf(ctx, ns, name, version)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Group)
+			r0 = ret.Get(0).(*fftypes.Datatype)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32) error); ok {
-		r1 = rf(ctx, hash)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, ns, name, version)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1037,16 +1102,16 @@ func (_m *Plugin) GetGroupByHash(ctx context.Context, hash *fftypes.Bytes32) (*f
 	return r0, r1
 }
 
-// GetGroups provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetGroups(ctx context.Context, filter database.Filter) ([]*fftypes.Group, *database.FilterResult, error) {
+// GetDatatypes provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetDatatypes(ctx context.Context, filter database.Filter) ([]*fftypes.Datatype, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Group
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Group); ok {
+	var r0 []*fftypes.Datatype
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Datatype); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Group)
+			r0 = ret.Get(0).([]*fftypes.Datatype)
 		}
 	}
 
@@ -1069,16 +1134,39 @@ func (_m *Plugin) GetGroups(ctx context.Context, filter database.Filter) ([]*fft
 	return r0, r1, r2
 }
 
-// GetIdentities provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetIdentities(ctx context.Context, filter database.Filter) ([]*fftypes.Identity, *database.FilterResult, error) {
+// GetEventByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetEventByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Event, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.Event
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Event); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEvents provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetEvents(ctx context.Context, filter database.Filter) ([]*fftypes.Event, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Identity
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Identity); ok {
+	var r0 []*fftypes.Event
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Event); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Identity)
+			r0 = ret.Get(0).([]*fftypes.Event)
 		}
 	}
 
@@ -1101,22 +1189,22 @@ func (_m *Plugin) GetIdentities(ctx context.Context, filter database.Filter) ([]
 	return r0, r1, r2
 }
 
-// GetIdentityByDID provides a mock function with given fields: ctx, did
-func (_m *Plugin) GetIdentityByDID(ctx context.Context, did string) (*fftypes.Identity, error) {
-	ret := _m.Called(ctx, did)
+// GetFFI provides a mock function with given fields: ctx, ns, name, version
+func (_m *Plugin) GetFFI(ctx context.Context, ns string, name string, version string) (*fftypes.FFI, error) {
+	ret := _m.Called(ctx, ns, name, version)
 
-	var r0 *fftypes.Identity
-	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.Identity); ok {
-		r0 = rf(ctx, did)
+	var r0 *fftypes.FFI
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *fftypes.FFI); ok {
+		r0 = rf(ctx, ns, name, version)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Identity)
+			r0 = ret.Get(0).(*fftypes.FFI)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, did)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, ns, name, version)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1124,16 +1212,16 @@ func (_m *Plugin) GetIdentityByDID(ctx context.Context, did string) (*fftypes.Id
 	return r0, r1
 }
 
-// GetIdentityByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetIdentityByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Identity, error) {
+// GetFFIByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetFFIByID(ctx context.Context, id *fftypes.UUID) (*fftypes.FFI, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.Identity
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Identity); ok {
+	var r0 *fftypes.FFI
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.FFI); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Identity)
+			r0 = ret.Get(0).(*fftypes.FFI)
 		}
 	}
 
@@ -1147,22 +1235,22 @@ func (_m *Plugin) GetIdentityByID(ctx context.Context, id *fftypes.UUID) (*fftyp
 	return r0, r1
 }
 
-// GetIdentityByName provides a mock function with given fields: ctx, iType, namespace, name
-func (_m *Plugin) GetIdentityByName(ctx context.Context, iType fftypes.FFEnum, namespace string, name string) (*fftypes.Identity, error) {
-	ret := _m.Called(ctx, iType, namespace, name)
+// GetFFIEvent provides a mock function with given fields: ctx, ns, interfaceID, pathName
+func (_m *Plugin) GetFFIEvent(ctx context.Context, ns string, interfaceID *fftypes.UUID, pathName string) (*fftypes.FFIEvent, error) {
+	ret := _m.Called(ctx, ns, interfaceID, pathName)
 
-	var r0 *fftypes.Identity
-	if rf, ok := ret.Get(0).(func(context.Context, fftypes.FFEnum, string, string) *fftypes.Identity); ok {
-		r0 = rf(ctx, iType, namespace, name)
+	var r0 *fftypes.FFIEvent
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, string) *fftypes.FFIEvent); ok {
+		r0 = rf(ctx, ns, interfaceID, pathName)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Identity)
+			r0 = ret.Get(0).(*fftypes.FFIEvent)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, fftypes.FFEnum, string, string) error); ok {
-		r1 = rf(ctx, iType, namespace, name)
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID, string) error); ok {
+		r1 = rf(ctx, ns, interfaceID, pathName)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1170,16 +1258,16 @@ func (_m *Plugin) GetIdentityByName(ctx context.Context, iType fftypes.FFEnum, n
 	return r0, r1
 }
 
-// GetMessageByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetMessageByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Message, error) {
+// GetFFIEventByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetFFIEventByID(ctx context.Context, id *fftypes.UUID) (*fftypes.FFIEvent, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.Message
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Message); ok {
+	var r0 *fftypes.FFIEvent
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.FFIEvent); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Message)
+			r0 = ret.Get(0).(*fftypes.FFIEvent)
 		}
 	}
 
@@ -1193,39 +1281,16 @@ func (_m *Plugin) GetMessageByID(ctx context.Context, id *fftypes.UUID) (*fftype
 	return r0, r1
 }
 
-// GetMessageIDs provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetMessageIDs(ctx context.Context, filter database.Filter) ([]*fftypes.IDAndSequence, error) {
-	ret := _m.Called(ctx, filter)
-
-	var r0 []*fftypes.IDAndSequence
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.IDAndSequence); ok {
-		r0 = rf(ctx, filter)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.IDAndSequence)
-		}
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) error); ok {
-		r1 = rf(ctx, filter)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
-// GetMessages provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetMessages(ctx context.Context, filter database.Filter) ([]*fftypes.Message, *database.FilterResult, error) {
+// GetFFIEvents provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetFFIEvents(ctx context.Context, filter database.Filter) ([]*fftypes.FFIEvent, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Message
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Message); ok {
+	var r0 []*fftypes.FFIEvent
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.FFIEvent); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Message)
+			r0 = ret.Get(0).([]*fftypes.FFIEvent)
 		}
 	}
 
@@ -1248,16 +1313,424 @@ func (_m *Plugin) GetMessages(ctx context.Context, filter database.Filter) ([]*f
 	return r0, r1, r2
 }
 
-// GetMessagesForData provides a mock function with given fields: ctx, dataID, filter
-func (_m *Plugin) GetMessagesForData(ctx context.Context, dataID *fftypes.UUID, filter database.Filter) ([]*fftypes.Message, *database.FilterResult, error) {
-	ret := _m.Called(ctx, dataID, filter)
+// GetFFIMethod provides a mock function with given fields: ctx, ns, interfaceID, pathName
+func (_m *Plugin) GetFFIMethod(ctx context.Context, ns string, interfaceID *fftypes.UUID, pathName string) (*fftypes.FFIMethod, error) {
+	ret := _m.Called(ctx, ns, interfaceID, pathName)
 
-	var r0 []*fftypes.Message
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, database.Filter) []*fftypes.Message); ok {
-		r0 = rf(ctx, dataID, filter)
+	var r0 *fftypes.FFIMethod
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, string) *fftypes.FFIMethod); ok {
+		r0 = rf(ctx, ns, interfaceID, pathName)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Message)
+			r0 = ret.Get(0).(*fftypes.FFIMethod)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID, string) error); ok {
+		r1 = rf(ctx, ns, interfaceID, pathName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFFIMethods provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetFFIMethods(ctx context.Context, filter database.Filter) ([]*fftypes.FFIMethod, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.FFIMethod
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.FFIMethod); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.FFIMethod)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetFFIs provides a mock function with given fields: ctx, ns, filter
+func (_m *Plugin) GetFFIs(ctx context.Context, ns string, filter database.Filter) ([]*fftypes.FFI, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.FFI
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.Filter) []*fftypes.FFI); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.FFI)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.Filter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetGroupByHash provides a mock function with given fields: ctx, hash
+func (_m *Plugin) GetGroupByHash(ctx context.Context, hash *fftypes.Bytes32) (*fftypes.Group, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 *fftypes.Group
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) *fftypes.Group); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Group)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetGroups provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetGroups(ctx context.Context, filter database.Filter) ([]*fftypes.Group, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.Group
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Group); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Group)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetIdentities provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetIdentities(ctx context.Context, filter database.Filter) ([]*fftypes.Identity, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.Identity
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Identity); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Identity)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetIdentityByDID provides a mock function with given fields: ctx, did
+func (_m *Plugin) GetIdentityByDID(ctx context.Context, did string) (*fftypes.Identity, error) {
+	ret := _m.Called(ctx, did)
+
+	var r0 *fftypes.Identity
+	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.Identity); ok {
+		r0 = rf(ctx, did)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Identity)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, did)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetIdentityByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetIdentityByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Identity, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.Identity
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Identity); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Identity)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetIdentityByName provides a mock function with given fields: ctx, iType, namespace, name
+func (_m *Plugin) GetIdentityByName(ctx context.Context, iType fftypes.FFEnum, namespace string, name string) (*fftypes.Identity, error) {
+	ret := _m.Called(ctx, iType, namespace, name)
+
+	var r0 *fftypes.Identity
+	if rf, ok := ret.Get(0).(func(context.Context, fftypes.FFEnum, string, string) *fftypes.Identity); ok {
+		r0 = rf(ctx, iType, namespace, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Identity)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, fftypes.FFEnum, string, string) error); ok {
+		r1 = rf(ctx, iType, namespace, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMessageByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetMessageByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Message, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.Message
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Message); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Message)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMessageIDs provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetMessageIDs(ctx context.Context, filter database.Filter) ([]*fftypes.IDAndSequence, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.IDAndSequence
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.IDAndSequence); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.IDAndSequence)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMessageStates provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetMessageStates(ctx context.Context, filter database.Filter) ([]*fftypes.MessageStateRecord, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.MessageStateRecord
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.MessageStateRecord); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.MessageStateRecord)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessageTemplateByName provides a mock function with given fields: ctx, ns, name
+func (_m *Plugin) GetMessageTemplateByName(ctx context.Context, ns string, name string) (*fftypes.MessageTemplate, error) {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 *fftypes.MessageTemplate
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.MessageTemplate); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageTemplate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMessageTemplates provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetMessageTemplates(ctx context.Context, filter database.Filter) ([]*fftypes.MessageTemplate, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.MessageTemplate
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.MessageTemplate); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.MessageTemplate)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessages provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetMessages(ctx context.Context, filter database.Filter) ([]*fftypes.Message, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.Message
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Message); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Message)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessagesForData provides a mock function with given fields: ctx, dataID, filter
+func (_m *Plugin) GetMessagesForData(ctx context.Context, dataID *fftypes.UUID, filter database.Filter) ([]*fftypes.Message, *database.FilterResult, error) {
+	ret := _m.Called(ctx, dataID, filter)
+
+	var r0 []*fftypes.Message
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, database.Filter) []*fftypes.Message); ok {
+		r0 = rf(ctx, dataID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Message)
 		}
 	}
 
@@ -1271,8 +1744,251 @@ func (_m *Plugin) GetMessagesForData(ctx context.Context, dataID *fftypes.UUID,
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context, *fftypes.UUID, database.Filter) error); ok {
-		r2 = rf(ctx, dataID, filter)
+	if rf, ok := ret.Get(2).(func(context.Context, *fftypes.UUID, database.Filter) error); ok {
+		r2 = rf(ctx, dataID, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetNamespace provides a mock function with given fields: ctx, name
+func (_m *Plugin) GetNamespace(ctx context.Context, name string) (*fftypes.Namespace, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 *fftypes.Namespace
+	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.Namespace); ok {
+		r0 = rf(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Namespace)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNamespaces provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetNamespaces(ctx context.Context, filter database.Filter) ([]*fftypes.Namespace, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.Namespace
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Namespace); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Namespace)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetNextPinByContextAndIdentity provides a mock function with given fields: ctx, _a1, identity
+func (_m *Plugin) GetNextPinByContextAndIdentity(ctx context.Context, _a1 *fftypes.Bytes32, identity string) (*fftypes.NextPin, error) {
+	ret := _m.Called(ctx, _a1, identity)
+
+	var r0 *fftypes.NextPin
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32, string) *fftypes.NextPin); ok {
+		r0 = rf(ctx, _a1, identity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NextPin)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32, string) error); ok {
+		r1 = rf(ctx, _a1, identity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNextPinByHash provides a mock function with given fields: ctx, hash
+func (_m *Plugin) GetNextPinByHash(ctx context.Context, hash *fftypes.Bytes32) (*fftypes.NextPin, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 *fftypes.NextPin
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) *fftypes.NextPin); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NextPin)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNextPins provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetNextPins(ctx context.Context, filter database.Filter) ([]*fftypes.NextPin, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.NextPin
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.NextPin); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.NextPin)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetNonce provides a mock function with given fields: ctx, hash
+func (_m *Plugin) GetNonce(ctx context.Context, hash *fftypes.Bytes32) (*fftypes.Nonce, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 *fftypes.Nonce
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) *fftypes.Nonce); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Nonce)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetNonces provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetNonces(ctx context.Context, filter database.Filter) ([]*fftypes.Nonce, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.Nonce
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Nonce); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Nonce)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetOffset provides a mock function with given fields: ctx, t, name
+func (_m *Plugin) GetOffset(ctx context.Context, t fftypes.FFEnum, name string) (*fftypes.Offset, error) {
+	ret := _m.Called(ctx, t, name)
+
+	var r0 *fftypes.Offset
+	if rf, ok := ret.Get(0).(func(context.Context, fftypes.FFEnum, string) *fftypes.Offset); ok {
+		r0 = rf(ctx, t, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Offset)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, fftypes.FFEnum, string) error); ok {
+		r1 = rf(ctx, t, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOffsets provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetOffsets(ctx context.Context, filter database.Filter) ([]*fftypes.Offset, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.Offset
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Offset); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Offset)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -1280,22 +1996,22 @@ func (_m *Plugin) GetMessagesForData(ctx context.Context, dataID *fftypes.UUID,
 	return r0, r1, r2
 }
 
-// GetNamespace provides a mock function with given fields: ctx, name
-func (_m *Plugin) GetNamespace(ctx context.Context, name string) (*fftypes.Namespace, error) {
-	ret := _m.Called(ctx, name)
+// GetOperationByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetOperationByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Operation, error) {
+	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.Namespace
-	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.Namespace); ok {
-		r0 = rf(ctx, name)
+	var r0 *fftypes.Operation
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Operation); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Namespace)
+			r0 = ret.Get(0).(*fftypes.Operation)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, name)
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1303,16 +2019,16 @@ func (_m *Plugin) GetNamespace(ctx context.Context, name string) (*fftypes.Names
 	return r0, r1
 }
 
-// GetNamespaces provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetNamespaces(ctx context.Context, filter database.Filter) ([]*fftypes.Namespace, *database.FilterResult, error) {
+// GetOperations provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetOperations(ctx context.Context, filter database.Filter) ([]*fftypes.Operation, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Namespace
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Namespace); ok {
+	var r0 []*fftypes.Operation
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Operation); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Namespace)
+			r0 = ret.Get(0).([]*fftypes.Operation)
 		}
 	}
 
@@ -1335,45 +2051,22 @@ func (_m *Plugin) GetNamespaces(ctx context.Context, filter database.Filter) ([]
 	return r0, r1, r2
 }
 
-// GetNextPinByContextAndIdentity provides a mock function with given fields: ctx, _a1, identity
-func (_m *Plugin) GetNextPinByContextAndIdentity(ctx context.Context, _a1 *fftypes.Bytes32, identity string) (*fftypes.NextPin, error) {
-	ret := _m.Called(ctx, _a1, identity)
-
-	var r0 *fftypes.NextPin
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32, string) *fftypes.NextPin); ok {
-		r0 = rf(ctx, _a1, identity)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.NextPin)
-		}
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32, string) error); ok {
-		r1 = rf(ctx, _a1, identity)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
-// GetNextPinByHash provides a mock function with given fields: ctx, hash
-func (_m *Plugin) GetNextPinByHash(ctx context.Context, hash *fftypes.Bytes32) (*fftypes.NextPin, error) {
-	ret := _m.Called(ctx, hash)
+// GetPingByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetPingByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Ping, error) {
+	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.NextPin
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) *fftypes.NextPin); ok {
-		r0 = rf(ctx, hash)
+	var r0 *fftypes.Ping
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Ping); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.NextPin)
+			r0 = ret.Get(0).(*fftypes.Ping)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32) error); ok {
-		r1 = rf(ctx, hash)
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1381,16 +2074,16 @@ func (_m *Plugin) GetNextPinByHash(ctx context.Context, hash *fftypes.Bytes32) (
 	return r0, r1
 }
 
-// GetNextPins provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetNextPins(ctx context.Context, filter database.Filter) ([]*fftypes.NextPin, *database.FilterResult, error) {
+// GetPings provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetPings(ctx context.Context, filter database.Filter) ([]*fftypes.Ping, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.NextPin
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.NextPin); ok {
+	var r0 []*fftypes.Ping
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Ping); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.NextPin)
+			r0 = ret.Get(0).([]*fftypes.Ping)
 		}
 	}
 
@@ -1413,39 +2106,16 @@ func (_m *Plugin) GetNextPins(ctx context.Context, filter database.Filter) ([]*f
 	return r0, r1, r2
 }
 
-// GetNonce provides a mock function with given fields: ctx, hash
-func (_m *Plugin) GetNonce(ctx context.Context, hash *fftypes.Bytes32) (*fftypes.Nonce, error) {
-	ret := _m.Called(ctx, hash)
-
-	var r0 *fftypes.Nonce
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Bytes32) *fftypes.Nonce); ok {
-		r0 = rf(ctx, hash)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Nonce)
-		}
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Bytes32) error); ok {
-		r1 = rf(ctx, hash)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
-// GetNonces provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetNonces(ctx context.Context, filter database.Filter) ([]*fftypes.Nonce, *database.FilterResult, error) {
+// GetPins provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetPins(ctx context.Context, filter database.Filter) ([]*fftypes.Pin, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Nonce
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Nonce); ok {
+	var r0 []*fftypes.Pin
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Pin); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Nonce)
+			r0 = ret.Get(0).([]*fftypes.Pin)
 		}
 	}
 
@@ -1468,22 +2138,22 @@ func (_m *Plugin) GetNonces(ctx context.Context, filter database.Filter) ([]*fft
 	return r0, r1, r2
 }
 
-// GetOffset provides a mock function with given fields: ctx, t, name
-func (_m *Plugin) GetOffset(ctx context.Context, t fftypes.FFEnum, name string) (*fftypes.Offset, error) {
-	ret := _m.Called(ctx, t, name)
+// GetQuarantinedMessageByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetQuarantinedMessageByID(ctx context.Context, id *fftypes.UUID) (*fftypes.QuarantinedMessage, error) {
+	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.Offset
-	if rf, ok := ret.Get(0).(func(context.Context, fftypes.FFEnum, string) *fftypes.Offset); ok {
-		r0 = rf(ctx, t, name)
+	var r0 *fftypes.QuarantinedMessage
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.QuarantinedMessage); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Offset)
+			r0 = ret.Get(0).(*fftypes.QuarantinedMessage)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, fftypes.FFEnum, string) error); ok {
-		r1 = rf(ctx, t, name)
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1491,16 +2161,16 @@ func (_m *Plugin) GetOffset(ctx context.Context, t fftypes.FFEnum, name string)
 	return r0, r1
 }
 
-// GetOffsets provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetOffsets(ctx context.Context, filter database.Filter) ([]*fftypes.Offset, *database.FilterResult, error) {
+// GetQuarantinedMessages provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetQuarantinedMessages(ctx context.Context, filter database.Filter) ([]*fftypes.QuarantinedMessage, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Offset
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Offset); ok {
+	var r0 []*fftypes.QuarantinedMessage
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.QuarantinedMessage); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Offset)
+			r0 = ret.Get(0).([]*fftypes.QuarantinedMessage)
 		}
 	}
 
@@ -1523,16 +2193,16 @@ func (_m *Plugin) GetOffsets(ctx context.Context, filter database.Filter) ([]*ff
 	return r0, r1, r2
 }
 
-// GetOperationByID provides a mock function with given fields: ctx, id
-func (_m *Plugin) GetOperationByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Operation, error) {
+// GetReportByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetReportByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Report, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 *fftypes.Operation
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Operation); ok {
+	var r0 *fftypes.Report
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Report); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Operation)
+			r0 = ret.Get(0).(*fftypes.Report)
 		}
 	}
 
@@ -1546,16 +2216,16 @@ func (_m *Plugin) GetOperationByID(ctx context.Context, id *fftypes.UUID) (*ffty
 	return r0, r1
 }
 
-// GetOperations provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetOperations(ctx context.Context, filter database.Filter) ([]*fftypes.Operation, *database.FilterResult, error) {
+// GetReports provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetReports(ctx context.Context, filter database.Filter) ([]*fftypes.Report, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Operation
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Operation); ok {
+	var r0 []*fftypes.Report
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Report); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Operation)
+			r0 = ret.Get(0).([]*fftypes.Report)
 		}
 	}
 
@@ -1578,16 +2248,16 @@ func (_m *Plugin) GetOperations(ctx context.Context, filter database.Filter) ([]
 	return r0, r1, r2
 }
 
-// GetPins provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetPins(ctx context.Context, filter database.Filter) ([]*fftypes.Pin, *database.FilterResult, error) {
+// GetSavedQueries provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetSavedQueries(ctx context.Context, filter database.Filter) ([]*fftypes.SavedQuery, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.Pin
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Pin); ok {
+	var r0 []*fftypes.SavedQuery
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.SavedQuery); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Pin)
+			r0 = ret.Get(0).([]*fftypes.SavedQuery)
 		}
 	}
 
@@ -1610,6 +2280,29 @@ func (_m *Plugin) GetPins(ctx context.Context, filter database.Filter) ([]*fftyp
 	return r0, r1, r2
 }
 
+// GetSavedQueryByName provides a mock function with given fields: ctx, ns, name
+func (_m *Plugin) GetSavedQueryByName(ctx context.Context, ns string, name string) (*fftypes.SavedQuery, error) {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 *fftypes.SavedQuery
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.SavedQuery); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.SavedQuery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetSubscriptionByID provides a mock function with given fields: ctx, id
 func (_m *Plugin) GetSubscriptionByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Subscription, error) {
 	ret := _m.Called(ctx, id)
@@ -1688,6 +2381,61 @@ func (_m *Plugin) GetSubscriptions(ctx context.Context, filter database.Filter)
 	return r0, r1, r2
 }
 
+// GetSwapByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetSwapByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Swap, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.Swap
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.Swap); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Swap)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSwaps provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetSwaps(ctx context.Context, filter database.Filter) ([]*fftypes.Swap, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.Swap
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.Swap); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Swap)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetTokenAccountPools provides a mock function with given fields: ctx, key, filter
 func (_m *Plugin) GetTokenAccountPools(ctx context.Context, key string, filter database.Filter) ([]*fftypes.TokenAccountPool, *database.FilterResult, error) {
 	ret := _m.Called(ctx, key, filter)
@@ -1802,12 +2550,67 @@ func (_m *Plugin) GetTokenApprovalByProtocolID(ctx context.Context, connector st
 func (_m *Plugin) GetTokenApprovals(ctx context.Context, filter database.Filter) ([]*fftypes.TokenApproval, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.TokenApproval
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.TokenApproval); ok {
+	var r0 []*fftypes.TokenApproval
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.TokenApproval); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.TokenApproval)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.Filter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.Filter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetTokenBalance provides a mock function with given fields: ctx, poolID, tokenIndex, identity
+func (_m *Plugin) GetTokenBalance(ctx context.Context, poolID *fftypes.UUID, tokenIndex string, identity string) (*fftypes.TokenBalance, error) {
+	ret := _m.Called(ctx, poolID, tokenIndex, identity)
+
+	var r0 *fftypes.TokenBalance
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, string, string) *fftypes.TokenBalance); ok {
+		r0 = rf(ctx, poolID, tokenIndex, identity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.TokenBalance)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID, string, string) error); ok {
+		r1 = rf(ctx, poolID, tokenIndex, identity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTokenBalances provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetTokenBalances(ctx context.Context, filter database.Filter) ([]*fftypes.TokenBalance, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.TokenBalance
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.TokenBalance); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.TokenApproval)
+			r0 = ret.Get(0).([]*fftypes.TokenBalance)
 		}
 	}
 
@@ -1830,22 +2633,22 @@ func (_m *Plugin) GetTokenApprovals(ctx context.Context, filter database.Filter)
 	return r0, r1, r2
 }
 
-// GetTokenBalance provides a mock function with given fields: ctx, poolID, tokenIndex, identity
-func (_m *Plugin) GetTokenBalance(ctx context.Context, poolID *fftypes.UUID, tokenIndex string, identity string) (*fftypes.TokenBalance, error) {
-	ret := _m.Called(ctx, poolID, tokenIndex, identity)
+// GetTokenMetadata provides a mock function with given fields: ctx, poolID, tokenIndex
+func (_m *Plugin) GetTokenMetadata(ctx context.Context, poolID *fftypes.UUID, tokenIndex string) (*fftypes.TokenMetadata, error) {
+	ret := _m.Called(ctx, poolID, tokenIndex)
 
-	var r0 *fftypes.TokenBalance
-	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, string, string) *fftypes.TokenBalance); ok {
-		r0 = rf(ctx, poolID, tokenIndex, identity)
+	var r0 *fftypes.TokenMetadata
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, string) *fftypes.TokenMetadata); ok {
+		r0 = rf(ctx, poolID, tokenIndex)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.TokenBalance)
+			r0 = ret.Get(0).(*fftypes.TokenMetadata)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID, string, string) error); ok {
-		r1 = rf(ctx, poolID, tokenIndex, identity)
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID, string) error); ok {
+		r1 = rf(ctx, poolID, tokenIndex)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1853,16 +2656,39 @@ func (_m *Plugin) GetTokenBalance(ctx context.Context, poolID *fftypes.UUID, tok
 	return r0, r1
 }
 
-// GetTokenBalances provides a mock function with given fields: ctx, filter
-func (_m *Plugin) GetTokenBalances(ctx context.Context, filter database.Filter) ([]*fftypes.TokenBalance, *database.FilterResult, error) {
+// GetTokenMetadataByID provides a mock function with given fields: ctx, id
+func (_m *Plugin) GetTokenMetadataByID(ctx context.Context, id *fftypes.UUID) (*fftypes.TokenMetadata, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.TokenMetadata
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) *fftypes.TokenMetadata); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.TokenMetadata)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTokenMetadataList provides a mock function with given fields: ctx, filter
+func (_m *Plugin) GetTokenMetadataList(ctx context.Context, filter database.Filter) ([]*fftypes.TokenMetadata, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
 
-	var r0 []*fftypes.TokenBalance
-	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.TokenBalance); ok {
+	var r0 []*fftypes.TokenMetadata
+	if rf, ok := ret.Get(0).(func(context.Context, database.Filter) []*fftypes.TokenMetadata); ok {
 		r0 = rf(ctx, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.TokenBalance)
+			r0 = ret.Get(0).([]*fftypes.TokenMetadata)
 		}
 	}
 
@@ -2216,6 +3042,90 @@ func (_m *Plugin) InitPrefix(prefix config.Prefix) {
 	_m.Called(prefix)
 }
 
+// InsertGovernanceProposal provides a mock function with given fields: ctx, proposal
+func (_m *Plugin) InsertGovernanceProposal(ctx context.Context, proposal *fftypes.GovernanceProposal) error {
+	ret := _m.Called(ctx, proposal)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.GovernanceProposal) error); ok {
+		r0 = rf(ctx, proposal)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertGovernanceVote provides a mock function with given fields: ctx, vote
+func (_m *Plugin) InsertGovernanceVote(ctx context.Context, vote *fftypes.GovernanceVote) error {
+	ret := _m.Called(ctx, vote)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.GovernanceVote) error); ok {
+		r0 = rf(ctx, vote)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertIdentityEndorsement provides a mock function with given fields: ctx, endorsement
+func (_m *Plugin) InsertIdentityEndorsement(ctx context.Context, endorsement *fftypes.IdentityEndorsement) error {
+	ret := _m.Called(ctx, endorsement)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.IdentityEndorsement) error); ok {
+		r0 = rf(ctx, endorsement)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertInvitation provides a mock function with given fields: ctx, invitation
+func (_m *Plugin) InsertInvitation(ctx context.Context, invitation *fftypes.Invitation) error {
+	ret := _m.Called(ctx, invitation)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Invitation) error); ok {
+		r0 = rf(ctx, invitation)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertLegalHold provides a mock function with given fields: ctx, hold
+func (_m *Plugin) InsertLegalHold(ctx context.Context, hold *fftypes.LegalHold) error {
+	ret := _m.Called(ctx, hold)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.LegalHold) error); ok {
+		r0 = rf(ctx, hold)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertMessageApproval provides a mock function with given fields: ctx, approval
+func (_m *Plugin) InsertMessageApproval(ctx context.Context, approval *fftypes.MessageApproval) error {
+	ret := _m.Called(ctx, approval)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.MessageApproval) error); ok {
+		r0 = rf(ctx, approval)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertBlob provides a mock function with given fields: ctx, blob
 func (_m *Plugin) InsertBlob(ctx context.Context, blob *fftypes.Blob) error {
 	ret := _m.Called(ctx, blob)
@@ -2314,6 +3224,20 @@ func (_m *Plugin) InsertOperation(ctx context.Context, operation *fftypes.Operat
 	return r0
 }
 
+// InsertPing provides a mock function with given fields: ctx, ping
+func (_m *Plugin) InsertPing(ctx context.Context, ping *fftypes.Ping) error {
+	ret := _m.Called(ctx, ping)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Ping) error); ok {
+		r0 = rf(ctx, ping)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertPins provides a mock function with given fields: ctx, pins
 func (_m *Plugin) InsertPins(ctx context.Context, pins []*fftypes.Pin) error {
 	ret := _m.Called(ctx, pins)
@@ -2328,6 +3252,48 @@ func (_m *Plugin) InsertPins(ctx context.Context, pins []*fftypes.Pin) error {
 	return r0
 }
 
+// InsertQuarantinedMessage provides a mock function with given fields: ctx, quarantined
+func (_m *Plugin) InsertQuarantinedMessage(ctx context.Context, quarantined *fftypes.QuarantinedMessage) error {
+	ret := _m.Called(ctx, quarantined)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.QuarantinedMessage) error); ok {
+		r0 = rf(ctx, quarantined)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertReport provides a mock function with given fields: ctx, report
+func (_m *Plugin) InsertReport(ctx context.Context, report *fftypes.Report) error {
+	ret := _m.Called(ctx, report)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Report) error); ok {
+		r0 = rf(ctx, report)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// InsertSwap provides a mock function with given fields: ctx, swap
+func (_m *Plugin) InsertSwap(ctx context.Context, swap *fftypes.Swap) error {
+	ret := _m.Called(ctx, swap)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Swap) error); ok {
+		r0 = rf(ctx, swap)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // InsertTransaction provides a mock function with given fields: ctx, data
 func (_m *Plugin) InsertTransaction(ctx context.Context, data *fftypes.Transaction) error {
 	ret := _m.Called(ctx, data)
@@ -2398,6 +3364,34 @@ func (_m *Plugin) RunAsGroup(ctx context.Context, fn func(context.Context) error
 	return r0
 }
 
+// SetOperationBlockchainTX provides a mock function with given fields: ctx, id, blockchainTX
+func (_m *Plugin) SetOperationBlockchainTX(ctx context.Context, id *fftypes.UUID, blockchainTX *fftypes.BlockchainTransaction) error {
+	ret := _m.Called(ctx, id, blockchainTX)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, *fftypes.BlockchainTransaction) error); ok {
+		r0 = rf(ctx, id, blockchainTX)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetOperationReceipt provides a mock function with given fields: ctx, id, receipt
+func (_m *Plugin) SetOperationReceipt(ctx context.Context, id *fftypes.UUID, receipt *fftypes.BlockchainReceipt) error {
+	ret := _m.Called(ctx, id, receipt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, *fftypes.BlockchainReceipt) error); ok {
+		r0 = rf(ctx, id, receipt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateBatch provides a mock function with given fields: ctx, id, update
 func (_m *Plugin) UpdateBatch(ctx context.Context, id *fftypes.UUID, update database.Update) error {
 	ret := _m.Called(ctx, id, update)
@@ -2412,6 +3406,27 @@ func (_m *Plugin) UpdateBatch(ctx context.Context, id *fftypes.UUID, update data
 	return r0
 }
 
+// UpdateContractListenerCheckpoint provides a mock function with given fields: ctx, id, checkpoint, advanceOnly
+func (_m *Plugin) UpdateContractListenerCheckpoint(ctx context.Context, id *fftypes.UUID, checkpoint string, advanceOnly bool) (bool, error) {
+	ret := _m.Called(ctx, id, checkpoint, advanceOnly)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, string, bool) bool); ok {
+		r0 = rf(ctx, id, checkpoint, advanceOnly)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID, string, bool) error); ok {
+		r1 = rf(ctx, id, checkpoint, advanceOnly)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateData provides a mock function with given fields: ctx, id, update
 func (_m *Plugin) UpdateData(ctx context.Context, id *fftypes.UUID, update database.Update) error {
 	ret := _m.Called(ctx, id, update)
@@ -2454,6 +3469,20 @@ func (_m *Plugin) UpdateEvent(ctx context.Context, id *fftypes.UUID, update data
 	return r0
 }
 
+// UpdateGovernanceProposal provides a mock function with given fields: ctx, proposal
+func (_m *Plugin) UpdateGovernanceProposal(ctx context.Context, proposal *fftypes.GovernanceProposal) error {
+	ret := _m.Called(ctx, proposal)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.GovernanceProposal) error); ok {
+		r0 = rf(ctx, proposal)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateGroup provides a mock function with given fields: ctx, hash, update
 func (_m *Plugin) UpdateGroup(ctx context.Context, hash *fftypes.Bytes32, update database.Update) error {
 	ret := _m.Called(ctx, hash, update)
@@ -2482,6 +3511,34 @@ func (_m *Plugin) UpdateIdentity(ctx context.Context, id *fftypes.UUID, update d
 	return r0
 }
 
+// UpdateInvitation provides a mock function with given fields: ctx, invitation
+func (_m *Plugin) UpdateInvitation(ctx context.Context, invitation *fftypes.Invitation) error {
+	ret := _m.Called(ctx, invitation)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Invitation) error); ok {
+		r0 = rf(ctx, invitation)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateLegalHold provides a mock function with given fields: ctx, id, update
+func (_m *Plugin) UpdateLegalHold(ctx context.Context, id *fftypes.UUID, update database.Update) error {
+	ret := _m.Called(ctx, id, update)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, database.Update) error); ok {
+		r0 = rf(ctx, id, update)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateMessage provides a mock function with given fields: ctx, id, update
 func (_m *Plugin) UpdateMessage(ctx context.Context, id *fftypes.UUID, update database.Update) error {
 	ret := _m.Called(ctx, id, update)
@@ -2552,6 +3609,20 @@ func (_m *Plugin) UpdateOperation(ctx context.Context, id *fftypes.UUID, update
 	return r0
 }
 
+// UpdatePing provides a mock function with given fields: ctx, ping
+func (_m *Plugin) UpdatePing(ctx context.Context, ping *fftypes.Ping) error {
+	ret := _m.Called(ctx, ping)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Ping) error); ok {
+		r0 = rf(ctx, ping)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdatePins provides a mock function with given fields: ctx, filter, update
 func (_m *Plugin) UpdatePins(ctx context.Context, filter database.Filter, update database.Update) error {
 	ret := _m.Called(ctx, filter, update)
@@ -2580,6 +3651,20 @@ func (_m *Plugin) UpdateSubscription(ctx context.Context, ns string, name string
 	return r0
 }
 
+// UpdateSwap provides a mock function with given fields: ctx, swap
+func (_m *Plugin) UpdateSwap(ctx context.Context, swap *fftypes.Swap) error {
+	ret := _m.Called(ctx, swap)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Swap) error); ok {
+		r0 = rf(ctx, swap)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateTokenBalances provides a mock function with given fields: ctx, transfer
 func (_m *Plugin) UpdateTokenBalances(ctx context.Context, transfer *fftypes.TokenTransfer) error {
 	ret := _m.Called(ctx, transfer)
@@ -2622,6 +3707,20 @@ func (_m *Plugin) UpdateVerifier(ctx context.Context, hash *fftypes.Bytes32, upd
 	return r0
 }
 
+// UpsertAlias provides a mock function with given fields: ctx, alias
+func (_m *Plugin) UpsertAlias(ctx context.Context, alias *fftypes.Alias) error {
+	ret := _m.Called(ctx, alias)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Alias) error); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertBatch provides a mock function with given fields: ctx, data
 func (_m *Plugin) UpsertBatch(ctx context.Context, data *fftypes.BatchPersisted) error {
 	ret := _m.Called(ctx, data)
@@ -2790,6 +3889,34 @@ func (_m *Plugin) UpsertMessage(ctx context.Context, message *fftypes.Message, o
 	return r0
 }
 
+// UpsertMessageState provides a mock function with given fields: ctx, state
+func (_m *Plugin) UpsertMessageState(ctx context.Context, state *fftypes.MessageStateRecord) error {
+	ret := _m.Called(ctx, state)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.MessageStateRecord) error); ok {
+		r0 = rf(ctx, state)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertMessageTemplate provides a mock function with given fields: ctx, template
+func (_m *Plugin) UpsertMessageTemplate(ctx context.Context, template *fftypes.MessageTemplate) error {
+	ret := _m.Called(ctx, template)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.MessageTemplate) error); ok {
+		r0 = rf(ctx, template)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertNamespace provides a mock function with given fields: ctx, data, allowExisting
 func (_m *Plugin) UpsertNamespace(ctx context.Context, data *fftypes.Namespace, allowExisting bool) error {
 	ret := _m.Called(ctx, data, allowExisting)
@@ -2846,6 +3973,20 @@ func (_m *Plugin) UpsertPin(ctx context.Context, parked *fftypes.Pin) error {
 	return r0
 }
 
+// UpsertSavedQuery provides a mock function with given fields: ctx, query
+func (_m *Plugin) UpsertSavedQuery(ctx context.Context, query *fftypes.SavedQuery) error {
+	ret := _m.Called(ctx, query)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.SavedQuery) error); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertSubscription provides a mock function with given fields: ctx, data, allowExisting
 func (_m *Plugin) UpsertSubscription(ctx context.Context, data *fftypes.Subscription, allowExisting bool) error {
 	ret := _m.Called(ctx, data, allowExisting)
@@ -2874,6 +4015,20 @@ func (_m *Plugin) UpsertTokenApproval(ctx context.Context, approval *fftypes.Tok
 	return r0
 }
 
+// UpsertTokenMetadata provides a mock function with given fields: ctx, metadata
+func (_m *Plugin) UpsertTokenMetadata(ctx context.Context, metadata *fftypes.TokenMetadata) error {
+	ret := _m.Called(ctx, metadata)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.TokenMetadata) error); ok {
+		r0 = rf(ctx, metadata)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpsertTokenPool provides a mock function with given fields: ctx, pool
 func (_m *Plugin) UpsertTokenPool(ctx context.Context, pool *fftypes.TokenPool) error {
 	ret := _m.Called(ctx, pool)
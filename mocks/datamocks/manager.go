@@ -18,6 +18,20 @@ type Manager struct {
 	mock.Mock
 }
 
+// ApplyMessageTemplate provides a mock function with given fields: ctx, in
+func (_m *Manager) ApplyMessageTemplate(ctx context.Context, in *fftypes.MessageInOut) error {
+	ret := _m.Called(ctx, in)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.MessageInOut) error); ok {
+		r0 = rf(ctx, in)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CheckDatatype provides a mock function with given fields: ctx, ns, datatype
 func (_m *Manager) CheckDatatype(ctx context.Context, ns string, datatype *fftypes.Datatype) error {
 	ret := _m.Called(ctx, ns, datatype)
@@ -32,6 +46,20 @@ func (_m *Manager) CheckDatatype(ctx context.Context, ns string, datatype *fftyp
 	return r0
 }
 
+// CheckDatatypes provides a mock function with given fields: ctx, ns, datatypes
+func (_m *Manager) CheckDatatypes(ctx context.Context, ns string, datatypes []*fftypes.Datatype) error {
+	ret := _m.Called(ctx, ns, datatypes)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.Datatype) error); ok {
+		r0 = rf(ctx, ns, datatypes)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CopyBlobPStoDX provides a mock function with given fields: ctx, _a1
 func (_m *Manager) CopyBlobPStoDX(ctx context.Context, _a1 *fftypes.Data) (*fftypes.Blob, error) {
 	ret := _m.Called(ctx, _a1)
@@ -239,6 +267,20 @@ func (_m *Manager) ResolveInlineData(ctx context.Context, msg *data.NewMessage)
 	return r0
 }
 
+// ScanBlob provides a mock function with given fields: ctx, blob
+func (_m *Manager) ScanBlob(ctx context.Context, blob *fftypes.Blob) error {
+	ret := _m.Called(ctx, blob)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Blob) error); ok {
+		r0 = rf(ctx, blob)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateMessageCache provides a mock function with given fields: msg, _a1
 func (_m *Manager) UpdateMessageCache(msg *fftypes.Message, _a1 fftypes.DataArray) {
 	_m.Called(msg, _a1)
@@ -272,6 +314,29 @@ func (_m *Manager) UploadBLOB(ctx context.Context, ns string, inData *fftypes.Da
 	return r0, r1
 }
 
+// UploadEDI provides a mock function with given fields: ctx, ns, raw
+func (_m *Manager) UploadEDI(ctx context.Context, ns string, raw string) (fftypes.DataArray, error) {
+	ret := _m.Called(ctx, ns, raw)
+
+	var r0 fftypes.DataArray
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) fftypes.DataArray); ok {
+		r0 = rf(ctx, ns, raw)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(fftypes.DataArray)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, raw)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UploadJSON provides a mock function with given fields: ctx, ns, inData
 func (_m *Manager) UploadJSON(ctx context.Context, ns string, inData *fftypes.DataRefOrValue) (*fftypes.Data, error) {
 	ret := _m.Called(ctx, ns, inData)
@@ -296,7 +361,7 @@ func (_m *Manager) UploadJSON(ctx context.Context, ns string, inData *fftypes.Da
 }
 
 // ValidateAll provides a mock function with given fields: ctx, _a1
-func (_m *Manager) ValidateAll(ctx context.Context, _a1 fftypes.DataArray) (bool, error) {
+func (_m *Manager) ValidateAll(ctx context.Context, _a1 fftypes.DataArray) (bool, string, error) {
 	ret := _m.Called(ctx, _a1)
 
 	var r0 bool
@@ -306,14 +371,21 @@ func (_m *Manager) ValidateAll(ctx context.Context, _a1 fftypes.DataArray) (bool
 		r0 = ret.Get(0).(bool)
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, fftypes.DataArray) error); ok {
+	var r1 string
+	if rf, ok := ret.Get(1).(func(context.Context, fftypes.DataArray) string); ok {
 		r1 = rf(ctx, _a1)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(string)
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, fftypes.DataArray) error); ok {
+		r2 = rf(ctx, _a1)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // VerifyNamespaceExists provides a mock function with given fields: ctx, ns
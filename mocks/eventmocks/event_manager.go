@@ -23,6 +23,20 @@ type EventManager struct {
 	mock.Mock
 }
 
+// AddDurableSystemEventListener provides a mock function with given fields: ctx, ns, name, filter, el
+func (_m *EventManager) AddDurableSystemEventListener(ctx context.Context, ns string, name string, filter *fftypes.SubscriptionFilter, el system.EventListener) error {
+	ret := _m.Called(ctx, ns, name, filter, el)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.SubscriptionFilter, system.EventListener) error); ok {
+		r0 = rf(ctx, ns, name, filter, el)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AddSystemEventListener provides a mock function with given fields: ns, el
 func (_m *EventManager) AddSystemEventListener(ns string, el system.EventListener) error {
 	ret := _m.Called(ns, el)
@@ -79,6 +93,34 @@ func (_m *EventManager) BlockchainEvent(event *blockchain.EventWithSubscription)
 	return r0
 }
 
+// BlockchainOpUpdate provides a mock function with given fields: plugin, operationID, tx, errorMessage, opOutput, receipt
+func (_m *EventManager) BlockchainOpUpdate(plugin fftypes.Named, operationID *fftypes.UUID, tx *fftypes.BlockchainTransaction, errorMessage string, opOutput fftypes.JSONObject, receipt *fftypes.BlockchainReceipt) error {
+	ret := _m.Called(plugin, operationID, tx, errorMessage, opOutput, receipt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(fftypes.Named, *fftypes.UUID, *fftypes.BlockchainTransaction, string, fftypes.JSONObject, *fftypes.BlockchainReceipt) error); ok {
+		r0 = rf(plugin, operationID, tx, errorMessage, opOutput, receipt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ChainReorg provides a mock function with given fields: bi, reorg
+func (_m *EventManager) ChainReorg(bi blockchain.Plugin, reorg *blockchain.Reorg) error {
+	ret := _m.Called(bi, reorg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(blockchain.Plugin, *blockchain.Reorg) error); ok {
+		r0 = rf(bi, reorg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ChangeEvents provides a mock function with given fields:
 func (_m *EventManager) ChangeEvents() chan<- *fftypes.ChangeEvent {
 	ret := _m.Called()
@@ -139,6 +181,29 @@ func (_m *EventManager) DeletedSubscriptions() chan<- *fftypes.UUID {
 	return r0
 }
 
+// DryRunSubscription provides a mock function with given fields: ctx, ns, dryRun
+func (_m *EventManager) DryRunSubscription(ctx context.Context, ns string, dryRun *fftypes.SubscriptionDryRun) ([]*fftypes.SubscriptionDryRunResult, error) {
+	ret := _m.Called(ctx, ns, dryRun)
+
+	var r0 []*fftypes.SubscriptionDryRunResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.SubscriptionDryRun) []*fftypes.SubscriptionDryRunResult); ok {
+		r0 = rf(ctx, ns, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.SubscriptionDryRunResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.SubscriptionDryRun) error); ok {
+		r1 = rf(ctx, ns, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // MessageReceived provides a mock function with given fields: dx, peerID, data
 func (_m *EventManager) MessageReceived(dx dataexchange.Plugin, peerID string, data []byte) (string, error) {
 	ret := _m.Called(dx, peerID, data)
@@ -222,6 +287,45 @@ func (_m *EventManager) OperationUpdate(plugin fftypes.Named, operationID *fftyp
 	return r0
 }
 
+// QueueDepths provides a mock function with given fields:
+func (_m *EventManager) QueueDepths() []*fftypes.DispatcherQueueDepth {
+	ret := _m.Called()
+
+	var r0 []*fftypes.DispatcherQueueDepth
+	if rf, ok := ret.Get(0).(func() []*fftypes.DispatcherQueueDepth); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.DispatcherQueueDepth)
+		}
+	}
+
+	return r0
+}
+
+// RewindDurableSubscription provides a mock function with given fields: ctx, subDef, offset
+func (_m *EventManager) RewindDurableSubscription(ctx context.Context, subDef *fftypes.Subscription, offset int64) (*fftypes.Offset, error) {
+	ret := _m.Called(ctx, subDef, offset)
+
+	var r0 *fftypes.Offset
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.Subscription, int64) *fftypes.Offset); ok {
+		r0 = rf(ctx, subDef, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Offset)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.Subscription, int64) error); ok {
+		r1 = rf(ctx, subDef, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Start provides a mock function with given fields:
 func (_m *EventManager) Start() error {
 	ret := _m.Called()
@@ -236,6 +340,29 @@ func (_m *EventManager) Start() error {
 	return r0
 }
 
+// StreamEvents provides a mock function with given fields: ctx, ns, firstEvent
+func (_m *EventManager) StreamEvents(ctx context.Context, ns string, firstEvent *fftypes.SubOptsFirstEvent) (<-chan *fftypes.EnrichedEvent, error) {
+	ret := _m.Called(ctx, ns, firstEvent)
+
+	var r0 <-chan *fftypes.EnrichedEvent
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.SubOptsFirstEvent) <-chan *fftypes.EnrichedEvent); ok {
+		r0 = rf(ctx, ns, firstEvent)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan *fftypes.EnrichedEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.SubOptsFirstEvent) error); ok {
+		r1 = rf(ctx, ns, firstEvent)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SubscriptionUpdates provides a mock function with given fields:
 func (_m *EventManager) SubscriptionUpdates() chan<- *fftypes.UUID {
 	ret := _m.Called()
@@ -252,6 +379,29 @@ func (_m *EventManager) SubscriptionUpdates() chan<- *fftypes.UUID {
 	return r0
 }
 
+// TapSubscription provides a mock function with given fields: ctx, id, eventCount
+func (_m *EventManager) TapSubscription(ctx context.Context, id *fftypes.UUID, eventCount int) (<-chan *fftypes.SubscriptionTapRecord, error) {
+	ret := _m.Called(ctx, id, eventCount)
+
+	var r0 <-chan *fftypes.SubscriptionTapRecord
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID, int) <-chan *fftypes.SubscriptionTapRecord); ok {
+		r0 = rf(ctx, id, eventCount)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan *fftypes.SubscriptionTapRecord)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.UUID, int) error); ok {
+		r1 = rf(ctx, id, eventCount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // TokenPoolCreated provides a mock function with given fields: ti, pool
 func (_m *EventManager) TokenPoolCreated(ti tokens.Plugin, pool *tokens.TokenPool) error {
 	ret := _m.Called(ti, pool)
@@ -181,6 +181,29 @@ func (_m *Manager) NormalizeSigningKey(ctx context.Context, namespace string, ke
 	return r0, r1
 }
 
+// ResolveAliasRef provides a mock function with given fields: ctx, namespace, value
+func (_m *Manager) ResolveAliasRef(ctx context.Context, namespace string, value string) (*fftypes.Alias, error) {
+	ret := _m.Called(ctx, namespace, value)
+
+	var r0 *fftypes.Alias
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Alias); ok {
+		r0 = rf(ctx, namespace, value)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Alias)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, namespace, value)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ResolveIdentitySigner provides a mock function with given fields: ctx, _a1
 func (_m *Manager) ResolveIdentitySigner(ctx context.Context, _a1 *fftypes.Identity) (*fftypes.SignerRef, error) {
 	ret := _m.Called(ctx, _a1)
@@ -232,6 +255,27 @@ func (_m *Manager) ResolveNodeOwnerSigningIdentity(ctx context.Context, msgSigne
 	return r0
 }
 
+// VerifyAuthorEndorsed provides a mock function with given fields: ctx, author
+func (_m *Manager) VerifyAuthorEndorsed(ctx context.Context, author string) (bool, error) {
+	ret := _m.Called(ctx, author)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, author)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, author)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // VerifyIdentityChain provides a mock function with given fields: ctx, _a1
 func (_m *Manager) VerifyIdentityChain(ctx context.Context, _a1 *fftypes.Identity) (*fftypes.Identity, bool, error) {
 	ret := _m.Called(ctx, _a1)
@@ -261,3 +305,17 @@ func (_m *Manager) VerifyIdentityChain(ctx context.Context, _a1 *fftypes.Identit
 
 	return r0, r1, r2
 }
+
+// VerifyNodeOwnerEndorsed provides a mock function with given fields: ctx
+func (_m *Manager) VerifyNodeOwnerEndorsed(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
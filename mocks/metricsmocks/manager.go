@@ -20,6 +20,11 @@ func (_m *Manager) AddTime(id string) {
 	_m.Called(id)
 }
 
+// CanaryResult provides a mock function with given fields: msgType, passed, elapsed
+func (_m *Manager) CanaryResult(msgType fftypes.FFEnum, passed bool, elapsed time.Duration) {
+	_m.Called(msgType, passed, elapsed)
+}
+
 // CountBatchPin provides a mock function with given fields:
 func (_m *Manager) CountBatchPin() {
 	_m.Called()
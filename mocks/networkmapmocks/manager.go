@@ -18,6 +18,52 @@ type Manager struct {
 	mock.Mock
 }
 
+// CreateInvitation provides a mock function with given fields: ctx, input
+func (_m *Manager) CreateInvitation(ctx context.Context, input *fftypes.InvitationInput) (*fftypes.Invitation, error) {
+	ret := _m.Called(ctx, input)
+
+	var r0 *fftypes.Invitation
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.InvitationInput) *fftypes.Invitation); ok {
+		r0 = rf(ctx, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Invitation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.InvitationInput) error); ok {
+		r1 = rf(ctx, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EndorseOrganization provides a mock function with given fields: ctx, nameOrID, waitConfirm
+func (_m *Manager) EndorseOrganization(ctx context.Context, nameOrID string, waitConfirm bool) (*fftypes.IdentityEndorsement, error) {
+	ret := _m.Called(ctx, nameOrID, waitConfirm)
+
+	var r0 *fftypes.IdentityEndorsement
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) *fftypes.IdentityEndorsement); ok {
+		r0 = rf(ctx, nameOrID, waitConfirm)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.IdentityEndorsement)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, bool) error); ok {
+		r1 = rf(ctx, nameOrID, waitConfirm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDIDDocForIndentityByID provides a mock function with given fields: ctx, ns, id
 func (_m *Manager) GetDIDDocForIndentityByID(ctx context.Context, ns string, id string) (*networkmap.DIDDocument, error) {
 	ret := _m.Called(ctx, ns, id)
@@ -128,6 +174,61 @@ func (_m *Manager) GetIdentityVerifiers(ctx context.Context, ns string, id strin
 	return r0, r1, r2
 }
 
+// GetInvitationByID provides a mock function with given fields: ctx, id
+func (_m *Manager) GetInvitationByID(ctx context.Context, id string) (*fftypes.Invitation, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *fftypes.Invitation
+	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.Invitation); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Invitation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInvitations provides a mock function with given fields: ctx, filter
+func (_m *Manager) GetInvitations(ctx context.Context, filter database.AndFilter) ([]*fftypes.Invitation, *database.FilterResult, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []*fftypes.Invitation
+	if rf, ok := ret.Get(0).(func(context.Context, database.AndFilter) []*fftypes.Invitation); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Invitation)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, database.AndFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetNodeByNameOrID provides a mock function with given fields: ctx, nameOrID
 func (_m *Manager) GetNodeByNameOrID(ctx context.Context, nameOrID string) (*fftypes.Identity, error) {
 	ret := _m.Called(ctx, nameOrID)
@@ -206,6 +307,38 @@ func (_m *Manager) GetOrganizationByNameOrID(ctx context.Context, nameOrID strin
 	return r0, r1
 }
 
+// GetOrganizationEndorsements provides a mock function with given fields: ctx, nameOrID, filter
+func (_m *Manager) GetOrganizationEndorsements(ctx context.Context, nameOrID string, filter database.AndFilter) ([]*fftypes.IdentityEndorsement, *database.FilterResult, error) {
+	ret := _m.Called(ctx, nameOrID, filter)
+
+	var r0 []*fftypes.IdentityEndorsement
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.IdentityEndorsement); ok {
+		r0 = rf(ctx, nameOrID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.IdentityEndorsement)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, nameOrID, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, nameOrID, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetOrganizations provides a mock function with given fields: ctx, filter
 func (_m *Manager) GetOrganizations(ctx context.Context, filter database.AndFilter) ([]*fftypes.Identity, *database.FilterResult, error) {
 	ret := _m.Called(ctx, filter)
@@ -293,6 +426,29 @@ func (_m *Manager) GetVerifiers(ctx context.Context, ns string, filter database.
 	return r0, r1, r2
 }
 
+// RedeemInvitation provides a mock function with given fields: ctx, input, waitConfirm
+func (_m *Manager) RedeemInvitation(ctx context.Context, input *fftypes.InvitationRedeemInput, waitConfirm bool) (*fftypes.InvitationRedemption, error) {
+	ret := _m.Called(ctx, input, waitConfirm)
+
+	var r0 *fftypes.InvitationRedemption
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.InvitationRedeemInput, bool) *fftypes.InvitationRedemption); ok {
+		r0 = rf(ctx, input, waitConfirm)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.InvitationRedemption)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *fftypes.InvitationRedeemInput, bool) error); ok {
+		r1 = rf(ctx, input, waitConfirm)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // RegisterIdentity provides a mock function with given fields: ctx, ns, dto, waitConfirm
 func (_m *Manager) RegisterIdentity(ctx context.Context, ns string, dto *fftypes.IdentityCreateDTO, waitConfirm bool) (*fftypes.Identity, error) {
 	ret := _m.Called(ctx, ns, dto, waitConfirm)
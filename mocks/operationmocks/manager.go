@@ -94,3 +94,17 @@ func (_m *Manager) RunOperation(ctx context.Context, op *fftypes.PreparedOperati
 
 	return r0
 }
+
+// StepFailed provides a mock function with given fields: ctx, opID
+func (_m *Manager) StepFailed(ctx context.Context, opID *fftypes.UUID) error {
+	ret := _m.Called(ctx, opID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *fftypes.UUID) error); ok {
+		r0 = rf(ctx, opID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -3,6 +3,8 @@
 package orchestratormocks
 
 import (
+	approvals "github.com/hyperledger/firefly/internal/approvals"
+
 	assets "github.com/hyperledger/firefly/internal/assets"
 	batch "github.com/hyperledger/firefly/internal/batch"
 
@@ -20,6 +22,14 @@ import (
 
 	fftypes "github.com/hyperledger/firefly/pkg/fftypes"
 
+	governance "github.com/hyperledger/firefly/internal/governance"
+
+	inbound "github.com/hyperledger/firefly/internal/inbound"
+
+	localmessaging "github.com/hyperledger/firefly/internal/localmessaging"
+
+	mailgateway "github.com/hyperledger/firefly/internal/mailgateway"
+
 	metrics "github.com/hyperledger/firefly/internal/metrics"
 
 	mock "github.com/stretchr/testify/mock"
@@ -28,7 +38,13 @@ import (
 
 	operations "github.com/hyperledger/firefly/internal/operations"
 
+	ping "github.com/hyperledger/firefly/internal/ping"
+
 	privatemessaging "github.com/hyperledger/firefly/internal/privatemessaging"
+
+	swaps "github.com/hyperledger/firefly/internal/swaps"
+
+	time "time"
 )
 
 // Orchestrator is an autogenerated mock type for the Orchestrator type
@@ -36,6 +52,43 @@ type Orchestrator struct {
 	mock.Mock
 }
 
+// ActivateBatchPinMigration provides a mock function with given fields: ctx
+func (_m *Orchestrator) ActivateBatchPinMigration(ctx context.Context) (*fftypes.BatchPinMigration, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.BatchPinMigration
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.BatchPinMigration); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.BatchPinMigration)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Approvals provides a mock function with given fields:
+func (_m *Orchestrator) Approvals() approvals.Manager {
+	ret := _m.Called()
+
+	var r0 approvals.Manager
+	if rf, ok := ret.Get(0).(func() approvals.Manager); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(approvals.Manager)
+	}
+
+	return r0
+}
+
 // Assets provides a mock function with given fields:
 func (_m *Orchestrator) Assets() assets.Manager {
 	ret := _m.Called()
@@ -162,6 +215,20 @@ func (_m *Orchestrator) Data() data.Manager {
 	return r0
 }
 
+// DeleteAlias provides a mock function with given fields: ctx, ns, name
+func (_m *Orchestrator) DeleteAlias(ctx context.Context, ns string, name string) error {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteConfigRecord provides a mock function with given fields: ctx, key
 func (_m *Orchestrator) DeleteConfigRecord(ctx context.Context, key string) error {
 	ret := _m.Called(ctx, key)
@@ -176,6 +243,34 @@ func (_m *Orchestrator) DeleteConfigRecord(ctx context.Context, key string) erro
 	return r0
 }
 
+// DeleteMessageTemplate provides a mock function with given fields: ctx, ns, name
+func (_m *Orchestrator) DeleteMessageTemplate(ctx context.Context, ns string, name string) error {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSavedQuery provides a mock function with given fields: ctx, ns, name
+func (_m *Orchestrator) DeleteSavedQuery(ctx context.Context, ns string, name string) error {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteSubscription provides a mock function with given fields: ctx, ns, id
 func (_m *Orchestrator) DeleteSubscription(ctx context.Context, ns string, id string) error {
 	ret := _m.Called(ctx, ns, id)
@@ -190,6 +285,29 @@ func (_m *Orchestrator) DeleteSubscription(ctx context.Context, ns string, id st
 	return r0
 }
 
+// DryRunSubscription provides a mock function with given fields: ctx, ns, dryRun
+func (_m *Orchestrator) DryRunSubscription(ctx context.Context, ns string, dryRun *fftypes.SubscriptionDryRun) ([]*fftypes.SubscriptionDryRunResult, error) {
+	ret := _m.Called(ctx, ns, dryRun)
+
+	var r0 []*fftypes.SubscriptionDryRunResult
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.SubscriptionDryRun) []*fftypes.SubscriptionDryRunResult); ok {
+		r0 = rf(ctx, ns, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.SubscriptionDryRunResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.SubscriptionDryRun) error); ok {
+		r1 = rf(ctx, ns, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Events provides a mock function with given fields:
 func (_m *Orchestrator) Events() events.EventManager {
 	ret := _m.Called()
@@ -206,6 +324,93 @@ func (_m *Orchestrator) Events() events.EventManager {
 	return r0
 }
 
+// GetAliasByName provides a mock function with given fields: ctx, ns, name
+func (_m *Orchestrator) GetAliasByName(ctx context.Context, ns string, name string) (*fftypes.Alias, error) {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 *fftypes.Alias
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Alias); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Alias)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAliases provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetAliases(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Alias, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.Alias
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.Alias); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Alias)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetApprovals provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetApprovals(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.MessageApproval, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.MessageApproval
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.MessageApproval); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.MessageApproval)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetBatchByID provides a mock function with given fields: ctx, ns, id
 func (_m *Orchestrator) GetBatchByID(ctx context.Context, ns string, id string) (*fftypes.BatchPersisted, error) {
 	ret := _m.Called(ctx, ns, id)
@@ -229,6 +434,45 @@ func (_m *Orchestrator) GetBatchByID(ctx context.Context, ns string, id string)
 	return r0, r1
 }
 
+// GetBatchPinMigration provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetBatchPinMigration(ctx context.Context) *fftypes.BatchPinMigration {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.BatchPinMigration
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.BatchPinMigration); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.BatchPinMigration)
+		}
+	}
+
+	return r0
+}
+
+// GetBatchManifest provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetBatchManifest(ctx context.Context, ns string, id string) (*fftypes.BatchManifestStatus, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.BatchManifestStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.BatchManifestStatus); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.BatchManifestStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBatches provides a mock function with given fields: ctx, ns, filter
 func (_m *Orchestrator) GetBatches(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.BatchPersisted, *database.FilterResult, error) {
 	ret := _m.Called(ctx, ns, filter)
@@ -316,6 +560,29 @@ func (_m *Orchestrator) GetBlockchainEvents(ctx context.Context, ns string, filt
 	return r0, r1, r2
 }
 
+// GetChanges provides a mock function with given fields: ctx, ns, since, limit
+func (_m *Orchestrator) GetChanges(ctx context.Context, ns string, since int64, limit int) (*fftypes.ChangeFeed, error) {
+	ret := _m.Called(ctx, ns, since, limit)
+
+	var r0 *fftypes.ChangeFeed
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) *fftypes.ChangeFeed); ok {
+		r0 = rf(ctx, ns, since, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.ChangeFeed)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int) error); ok {
+		r1 = rf(ctx, ns, since, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetChartHistogram provides a mock function with given fields: ctx, ns, startTime, endTime, buckets, tableName
 func (_m *Orchestrator) GetChartHistogram(ctx context.Context, ns string, startTime int64, endTime int64, buckets int64, tableName database.CollectionName) ([]*fftypes.ChartHistogram, error) {
 	ret := _m.Called(ctx, ns, startTime, endTime, buckets, tableName)
@@ -465,6 +732,29 @@ func (_m *Orchestrator) GetDataByID(ctx context.Context, ns string, id string) (
 	return r0, r1
 }
 
+// GetDataByIDs provides a mock function with given fields: ctx, ns, ids
+func (_m *Orchestrator) GetDataByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) (fftypes.DataArray, error) {
+	ret := _m.Called(ctx, ns, ids)
+
+	var r0 fftypes.DataArray
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.UUID) fftypes.DataArray); ok {
+		r0 = rf(ctx, ns, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(fftypes.DataArray)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []*fftypes.UUID) error); ok {
+		r1 = rf(ctx, ns, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDatatypeByID provides a mock function with given fields: ctx, ns, id
 func (_m *Orchestrator) GetDatatypeByID(ctx context.Context, ns string, id string) (*fftypes.Datatype, error) {
 	ret := _m.Called(ctx, ns, id)
@@ -598,6 +888,29 @@ func (_m *Orchestrator) GetEvents(ctx context.Context, ns string, filter databas
 	return r0, r1, r2
 }
 
+// GetEventsByIDs provides a mock function with given fields: ctx, ns, ids
+func (_m *Orchestrator) GetEventsByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) ([]*fftypes.Event, error) {
+	ret := _m.Called(ctx, ns, ids)
+
+	var r0 []*fftypes.Event
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.UUID) []*fftypes.Event); ok {
+		r0 = rf(ctx, ns, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Event)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []*fftypes.UUID) error); ok {
+		r1 = rf(ctx, ns, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetEventsWithReferences provides a mock function with given fields: ctx, ns, filter
 func (_m *Orchestrator) GetEventsWithReferences(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.EnrichedEvent, *database.FilterResult, error) {
 	ret := _m.Called(ctx, ns, filter)
@@ -630,16 +943,16 @@ func (_m *Orchestrator) GetEventsWithReferences(ctx context.Context, ns string,
 	return r0, r1, r2
 }
 
-// GetMessageByID provides a mock function with given fields: ctx, ns, id
-func (_m *Orchestrator) GetMessageByID(ctx context.Context, ns string, id string) (*fftypes.Message, error) {
+// GetLegalHoldByID provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetLegalHoldByID(ctx context.Context, ns string, id string) (*fftypes.LegalHold, error) {
 	ret := _m.Called(ctx, ns, id)
 
-	var r0 *fftypes.Message
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Message); ok {
+	var r0 *fftypes.LegalHold
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.LegalHold); ok {
 		r0 = rf(ctx, ns, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Message)
+			r0 = ret.Get(0).(*fftypes.LegalHold)
 		}
 	}
 
@@ -653,27 +966,137 @@ func (_m *Orchestrator) GetMessageByID(ctx context.Context, ns string, id string
 	return r0, r1
 }
 
-// GetMessageByIDWithData provides a mock function with given fields: ctx, ns, id
-func (_m *Orchestrator) GetMessageByIDWithData(ctx context.Context, ns string, id string) (*fftypes.MessageInOut, error) {
-	ret := _m.Called(ctx, ns, id)
+// GetLegalHolds provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetLegalHolds(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.LegalHold, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
 
-	var r0 *fftypes.MessageInOut
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.MessageInOut); ok {
-		r0 = rf(ctx, ns, id)
+	var r0 []*fftypes.LegalHold
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.LegalHold); ok {
+		r0 = rf(ctx, ns, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.MessageInOut)
+			r0 = ret.Get(0).([]*fftypes.LegalHold)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, ns, id)
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
 	} else {
-		r1 = ret.Error(1)
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessageApprovals provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetMessageApprovals(ctx context.Context, ns string, id string) ([]*fftypes.MessageApproval, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 []*fftypes.MessageApproval
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*fftypes.MessageApproval); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.MessageApproval)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, ns, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessageByID provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetMessageByID(ctx context.Context, ns string, id string) (*fftypes.Message, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.Message
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Message); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Message)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMessageByIDWithData provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetMessageByIDWithData(ctx context.Context, ns string, id string) (*fftypes.MessageInOut, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.MessageInOut
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.MessageInOut); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageInOut)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMessageByIDWithIncludes provides a mock function with given fields: ctx, ns, id, includes
+func (_m *Orchestrator) GetMessageByIDWithIncludes(ctx context.Context, ns string, id string, includes []string) (*fftypes.MessageWithIncludes, error) {
+	ret := _m.Called(ctx, ns, id, includes)
+
+	var r0 *fftypes.MessageWithIncludes
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, []string) *fftypes.MessageWithIncludes); ok {
+		r0 = rf(ctx, ns, id, includes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageWithIncludes)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, []string) error); ok {
+		r1 = rf(ctx, ns, id, includes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // GetMessageData provides a mock function with given fields: ctx, ns, id
@@ -763,6 +1186,93 @@ func (_m *Orchestrator) GetMessageOperations(ctx context.Context, ns string, id
 	return r0, r1, r2
 }
 
+// GetMessageStates provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetMessageStates(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.MessageStateRecord, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.MessageStateRecord
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.MessageStateRecord); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.MessageStateRecord)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetMessageTemplateByName provides a mock function with given fields: ctx, ns, name
+func (_m *Orchestrator) GetMessageTemplateByName(ctx context.Context, ns string, name string) (*fftypes.MessageTemplate, error) {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 *fftypes.MessageTemplate
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.MessageTemplate); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageTemplate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetMessageTemplates provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetMessageTemplates(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.MessageTemplate, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.MessageTemplate
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.MessageTemplate); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.MessageTemplate)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetMessageTransaction provides a mock function with given fields: ctx, ns, id
 func (_m *Orchestrator) GetMessageTransaction(ctx context.Context, ns string, id string) (*fftypes.Transaction, error) {
 	ret := _m.Called(ctx, ns, id)
@@ -818,6 +1328,29 @@ func (_m *Orchestrator) GetMessages(ctx context.Context, ns string, filter datab
 	return r0, r1, r2
 }
 
+// GetMessagesByIDs provides a mock function with given fields: ctx, ns, ids
+func (_m *Orchestrator) GetMessagesByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) ([]*fftypes.Message, error) {
+	ret := _m.Called(ctx, ns, ids)
+
+	var r0 []*fftypes.Message
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.UUID) []*fftypes.Message); ok {
+		r0 = rf(ctx, ns, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Message)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []*fftypes.UUID) error); ok {
+		r1 = rf(ctx, ns, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMessagesForData provides a mock function with given fields: ctx, ns, dataID, filter
 func (_m *Orchestrator) GetMessagesForData(ctx context.Context, ns string, dataID string, filter database.AndFilter) ([]*fftypes.Message, *database.FilterResult, error) {
 	ret := _m.Called(ctx, ns, dataID, filter)
@@ -1024,39 +1557,16 @@ func (_m *Orchestrator) GetPins(ctx context.Context, filter database.AndFilter)
 	return r0, r1, r2
 }
 
-// GetStatus provides a mock function with given fields: ctx
-func (_m *Orchestrator) GetStatus(ctx context.Context) (*fftypes.NodeStatus, error) {
-	ret := _m.Called(ctx)
-
-	var r0 *fftypes.NodeStatus
-	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.NodeStatus); ok {
-		r0 = rf(ctx)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.NodeStatus)
-		}
-	}
-
-	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
-		r1 = rf(ctx)
-	} else {
-		r1 = ret.Error(1)
-	}
-
-	return r0, r1
-}
-
-// GetSubscriptionByID provides a mock function with given fields: ctx, ns, id
-func (_m *Orchestrator) GetSubscriptionByID(ctx context.Context, ns string, id string) (*fftypes.Subscription, error) {
+// GetQuarantinedMessageByID provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetQuarantinedMessageByID(ctx context.Context, ns string, id string) (*fftypes.QuarantinedMessage, error) {
 	ret := _m.Called(ctx, ns, id)
 
-	var r0 *fftypes.Subscription
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Subscription); ok {
+	var r0 *fftypes.QuarantinedMessage
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.QuarantinedMessage); ok {
 		r0 = rf(ctx, ns, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Subscription)
+			r0 = ret.Get(0).(*fftypes.QuarantinedMessage)
 		}
 	}
 
@@ -1070,16 +1580,16 @@ func (_m *Orchestrator) GetSubscriptionByID(ctx context.Context, ns string, id s
 	return r0, r1
 }
 
-// GetSubscriptions provides a mock function with given fields: ctx, ns, filter
-func (_m *Orchestrator) GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Subscription, *database.FilterResult, error) {
+// GetQuarantinedMessages provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetQuarantinedMessages(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.QuarantinedMessage, *database.FilterResult, error) {
 	ret := _m.Called(ctx, ns, filter)
 
-	var r0 []*fftypes.Subscription
-	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.Subscription); ok {
+	var r0 []*fftypes.QuarantinedMessage
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.QuarantinedMessage); ok {
 		r0 = rf(ctx, ns, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Subscription)
+			r0 = ret.Get(0).([]*fftypes.QuarantinedMessage)
 		}
 	}
 
@@ -1102,48 +1612,39 @@ func (_m *Orchestrator) GetSubscriptions(ctx context.Context, ns string, filter
 	return r0, r1, r2
 }
 
-// GetTransactionBlockchainEvents provides a mock function with given fields: ctx, ns, id
-func (_m *Orchestrator) GetTransactionBlockchainEvents(ctx context.Context, ns string, id string) ([]*fftypes.BlockchainEvent, *database.FilterResult, error) {
-	ret := _m.Called(ctx, ns, id)
+// GetQueueDepths provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetQueueDepths(ctx context.Context) (*fftypes.QueueDepths, error) {
+	ret := _m.Called(ctx)
 
-	var r0 []*fftypes.BlockchainEvent
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*fftypes.BlockchainEvent); ok {
-		r0 = rf(ctx, ns, id)
+	var r0 *fftypes.QueueDepths
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.QueueDepths); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.BlockchainEvent)
-		}
-	}
-
-	var r1 *database.FilterResult
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) *database.FilterResult); ok {
-		r1 = rf(ctx, ns, id)
-	} else {
-		if ret.Get(1) != nil {
-			r1 = ret.Get(1).(*database.FilterResult)
+			r0 = ret.Get(0).(*fftypes.QueueDepths)
 		}
 	}
 
-	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
-		r2 = rf(ctx, ns, id)
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
-		r2 = ret.Error(2)
+		r1 = ret.Error(1)
 	}
 
-	return r0, r1, r2
+	return r0, r1
 }
 
-// GetTransactionByID provides a mock function with given fields: ctx, ns, id
-func (_m *Orchestrator) GetTransactionByID(ctx context.Context, ns string, id string) (*fftypes.Transaction, error) {
+// GetReportByID provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetReportByID(ctx context.Context, ns string, id string) (*fftypes.Report, error) {
 	ret := _m.Called(ctx, ns, id)
 
-	var r0 *fftypes.Transaction
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Transaction); ok {
+	var r0 *fftypes.Report
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Report); ok {
 		r0 = rf(ctx, ns, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*fftypes.Transaction)
+			r0 = ret.Get(0).(*fftypes.Report)
 		}
 	}
 
@@ -1157,22 +1658,22 @@ func (_m *Orchestrator) GetTransactionByID(ctx context.Context, ns string, id st
 	return r0, r1
 }
 
-// GetTransactionOperations provides a mock function with given fields: ctx, ns, id
-func (_m *Orchestrator) GetTransactionOperations(ctx context.Context, ns string, id string) ([]*fftypes.Operation, *database.FilterResult, error) {
-	ret := _m.Called(ctx, ns, id)
+// GetReports provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetReports(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Report, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
 
-	var r0 []*fftypes.Operation
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*fftypes.Operation); ok {
-		r0 = rf(ctx, ns, id)
+	var r0 []*fftypes.Report
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.Report); ok {
+		r0 = rf(ctx, ns, filter)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*fftypes.Operation)
+			r0 = ret.Get(0).([]*fftypes.Report)
 		}
 	}
 
 	var r1 *database.FilterResult
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) *database.FilterResult); ok {
-		r1 = rf(ctx, ns, id)
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*database.FilterResult)
@@ -1180,8 +1681,8 @@ func (_m *Orchestrator) GetTransactionOperations(ctx context.Context, ns string,
 	}
 
 	var r2 error
-	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
-		r2 = rf(ctx, ns, id)
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -1189,7 +1690,319 @@ func (_m *Orchestrator) GetTransactionOperations(ctx context.Context, ns string,
 	return r0, r1, r2
 }
 
-// GetTransactionStatus provides a mock function with given fields: ctx, ns, id
+// GetSavedQueries provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetSavedQueries(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.SavedQuery, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.SavedQuery
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.SavedQuery); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.SavedQuery)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetSavedQueryByName provides a mock function with given fields: ctx, ns, name
+func (_m *Orchestrator) GetSavedQueryByName(ctx context.Context, ns string, name string) (*fftypes.SavedQuery, error) {
+	ret := _m.Called(ctx, ns, name)
+
+	var r0 *fftypes.SavedQuery
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.SavedQuery); ok {
+		r0 = rf(ctx, ns, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.SavedQuery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStatus provides a mock function with given fields: ctx
+func (_m *Orchestrator) GetStatus(ctx context.Context) (*fftypes.NodeStatus, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *fftypes.NodeStatus
+	if rf, ok := ret.Get(0).(func(context.Context) *fftypes.NodeStatus); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NodeStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetStatusSummary provides a mock function with given fields: ctx, ns
+func (_m *Orchestrator) GetStatusSummary(ctx context.Context, ns string) (*fftypes.NamespaceStatusSummary, error) {
+	ret := _m.Called(ctx, ns)
+
+	var r0 *fftypes.NamespaceStatusSummary
+	if rf, ok := ret.Get(0).(func(context.Context, string) *fftypes.NamespaceStatusSummary); ok {
+		r0 = rf(ctx, ns)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.NamespaceStatusSummary)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ns)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptionByID provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetSubscriptionByID(ctx context.Context, ns string, id string) (*fftypes.Subscription, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Subscription); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Subscription)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptions provides a mock function with given fields: ctx, ns, filter
+func (_m *Orchestrator) GetSubscriptions(ctx context.Context, ns string, filter database.AndFilter) ([]*fftypes.Subscription, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, filter)
+
+	var r0 []*fftypes.Subscription
+	if rf, ok := ret.Get(0).(func(context.Context, string, database.AndFilter) []*fftypes.Subscription); ok {
+		r0 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Subscription)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, database.AndFilter) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, database.AndFilter) error); ok {
+		r2 = rf(ctx, ns, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetTopicSchema provides a mock function with given fields: ctx, ns, topic
+func (_m *Orchestrator) GetTopicSchema(ctx context.Context, ns string, topic string) (*fftypes.TopicSchema, error) {
+	ret := _m.Called(ctx, ns, topic)
+
+	var r0 *fftypes.TopicSchema
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.TopicSchema); ok {
+		r0 = rf(ctx, ns, topic)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.TopicSchema)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, topic)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTopicSchemas provides a mock function with given fields: ctx, ns
+func (_m *Orchestrator) GetTopicSchemas(ctx context.Context, ns string) ([]*fftypes.TopicSchema, error) {
+	ret := _m.Called(ctx, ns)
+
+	var r0 []*fftypes.TopicSchema
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*fftypes.TopicSchema); ok {
+		r0 = rf(ctx, ns)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.TopicSchema)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ns)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTransactionBlockchainEvents provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetTransactionBlockchainEvents(ctx context.Context, ns string, id string) ([]*fftypes.BlockchainEvent, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 []*fftypes.BlockchainEvent
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*fftypes.BlockchainEvent); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.BlockchainEvent)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, ns, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetTransactionBlockchainTransactions provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetTransactionBlockchainTransactions(ctx context.Context, ns string, id string) ([]*fftypes.BlockchainTransaction, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 []*fftypes.BlockchainTransaction
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*fftypes.BlockchainTransaction); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.BlockchainTransaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTransactionByID provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetTransactionByID(ctx context.Context, ns string, id string) (*fftypes.Transaction, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Transaction); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTransactionOperations provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) GetTransactionOperations(ctx context.Context, ns string, id string) ([]*fftypes.Operation, *database.FilterResult, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 []*fftypes.Operation
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*fftypes.Operation); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Operation)
+		}
+	}
+
+	var r1 *database.FilterResult
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) *database.FilterResult); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*database.FilterResult)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, string, string) error); ok {
+		r2 = rf(ctx, ns, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetTransactionStatus provides a mock function with given fields: ctx, ns, id
 func (_m *Orchestrator) GetTransactionStatus(ctx context.Context, ns string, id string) (*fftypes.TransactionStatus, error) {
 	ret := _m.Called(ctx, ns, id)
 
@@ -1244,29 +2057,114 @@ func (_m *Orchestrator) GetTransactions(ctx context.Context, ns string, filter d
 	return r0, r1, r2
 }
 
+// GetTransactionsByIDs provides a mock function with given fields: ctx, ns, ids
+func (_m *Orchestrator) GetTransactionsByIDs(ctx context.Context, ns string, ids []*fftypes.UUID) ([]*fftypes.Transaction, error) {
+	ret := _m.Called(ctx, ns, ids)
+
+	var r0 []*fftypes.Transaction
+	if rf, ok := ret.Get(0).(func(context.Context, string, []*fftypes.UUID) []*fftypes.Transaction); ok {
+		r0 = rf(ctx, ns, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*fftypes.Transaction)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, []*fftypes.UUID) error); ok {
+		r1 = rf(ctx, ns, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Governance provides a mock function with given fields:
+func (_m *Orchestrator) Governance() governance.Manager {
+	ret := _m.Called()
+
+	var r0 governance.Manager
+	if rf, ok := ret.Get(0).(func() governance.Manager); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(governance.Manager)
+	}
+
+	return r0
+}
+
+// Inbound provides a mock function with given fields:
+func (_m *Orchestrator) Inbound() inbound.Manager {
+	ret := _m.Called()
+
+	var r0 inbound.Manager
+	if rf, ok := ret.Get(0).(func() inbound.Manager); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(inbound.Manager)
+		}
+	}
+
+	return r0
+}
+
 // Init provides a mock function with given fields: ctx, cancelCtx
 func (_m *Orchestrator) Init(ctx context.Context, cancelCtx context.CancelFunc) error {
 	ret := _m.Called(ctx, cancelCtx)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, context.CancelFunc) error); ok {
-		r0 = rf(ctx, cancelCtx)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, context.CancelFunc) error); ok {
+		r0 = rf(ctx, cancelCtx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IsPreInit provides a mock function with given fields:
+func (_m *Orchestrator) IsPreInit() bool {
+	ret := _m.Called()
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func() bool); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// LocalMessaging provides a mock function with given fields:
+func (_m *Orchestrator) LocalMessaging() localmessaging.Manager {
+	ret := _m.Called()
+
+	var r0 localmessaging.Manager
+	if rf, ok := ret.Get(0).(func() localmessaging.Manager); ok {
+		r0 = rf()
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(localmessaging.Manager)
+		}
 	}
 
 	return r0
 }
 
-// IsPreInit provides a mock function with given fields:
-func (_m *Orchestrator) IsPreInit() bool {
+// MailGateway provides a mock function with given fields:
+func (_m *Orchestrator) MailGateway() mailgateway.Manager {
 	ret := _m.Called()
 
-	var r0 bool
-	if rf, ok := ret.Get(0).(func() bool); ok {
+	var r0 mailgateway.Manager
+	if rf, ok := ret.Get(0).(func() mailgateway.Manager); ok {
 		r0 = rf()
 	} else {
-		r0 = ret.Get(0).(bool)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(mailgateway.Manager)
+		}
 	}
 
 	return r0
@@ -1320,6 +2218,43 @@ func (_m *Orchestrator) Operations() operations.Manager {
 	return r0
 }
 
+// Ping provides a mock function with given fields:
+func (_m *Orchestrator) Ping() ping.Manager {
+	ret := _m.Called()
+
+	var r0 ping.Manager
+	if rf, ok := ret.Get(0).(func() ping.Manager); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(ping.Manager)
+	}
+
+	return r0
+}
+
+// PlaceLegalHold provides a mock function with given fields: ctx, ns, input
+func (_m *Orchestrator) PlaceLegalHold(ctx context.Context, ns string, input *fftypes.LegalHoldInput) (*fftypes.LegalHold, error) {
+	ret := _m.Called(ctx, ns, input)
+
+	var r0 *fftypes.LegalHold
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.LegalHoldInput) *fftypes.LegalHold); ok {
+		r0 = rf(ctx, ns, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.LegalHold)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.LegalHoldInput) error); ok {
+		r1 = rf(ctx, ns, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // PrivateMessaging provides a mock function with given fields:
 func (_m *Orchestrator) PrivateMessaging() privatemessaging.Manager {
 	ret := _m.Called()
@@ -1359,13 +2294,59 @@ func (_m *Orchestrator) PutConfigRecord(ctx context.Context, key string, configR
 	return r0, r1
 }
 
-// RequestReply provides a mock function with given fields: ctx, ns, msg
-func (_m *Orchestrator) RequestReply(ctx context.Context, ns string, msg *fftypes.MessageInOut) (*fftypes.MessageInOut, error) {
-	ret := _m.Called(ctx, ns, msg)
+// ReleaseLegalHold provides a mock function with given fields: ctx, ns, id, input
+func (_m *Orchestrator) ReleaseLegalHold(ctx context.Context, ns string, id string, input *fftypes.LegalHoldRelease) (*fftypes.LegalHold, error) {
+	ret := _m.Called(ctx, ns, id, input)
+
+	var r0 *fftypes.LegalHold
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.LegalHoldRelease) *fftypes.LegalHold); ok {
+		r0 = rf(ctx, ns, id, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.LegalHold)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *fftypes.LegalHoldRelease) error); ok {
+		r1 = rf(ctx, ns, id, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReprocessQuarantinedMessage provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) ReprocessQuarantinedMessage(ctx context.Context, ns string, id string) (*fftypes.Message, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.Message
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.Message); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Message)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RequestReply provides a mock function with given fields: ctx, ns, msg, timeout
+func (_m *Orchestrator) RequestReply(ctx context.Context, ns string, msg *fftypes.MessageInOut, timeout time.Duration) (*fftypes.MessageInOut, error) {
+	ret := _m.Called(ctx, ns, msg, timeout)
 
 	var r0 *fftypes.MessageInOut
-	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.MessageInOut) *fftypes.MessageInOut); ok {
-		r0 = rf(ctx, ns, msg)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.MessageInOut, time.Duration) *fftypes.MessageInOut); ok {
+		r0 = rf(ctx, ns, msg, timeout)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*fftypes.MessageInOut)
@@ -1373,8 +2354,31 @@ func (_m *Orchestrator) RequestReply(ctx context.Context, ns string, msg *fftype
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.MessageInOut) error); ok {
-		r1 = rf(ctx, ns, msg)
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.MessageInOut, time.Duration) error); ok {
+		r1 = rf(ctx, ns, msg, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RequestReplyBroadcast provides a mock function with given fields: ctx, ns, msg, timeout
+func (_m *Orchestrator) RequestReplyBroadcast(ctx context.Context, ns string, msg *fftypes.MessageInOut, timeout time.Duration) (*fftypes.MessageInOut, error) {
+	ret := _m.Called(ctx, ns, msg, timeout)
+
+	var r0 *fftypes.MessageInOut
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.MessageInOut, time.Duration) *fftypes.MessageInOut); ok {
+		r0 = rf(ctx, ns, msg, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageInOut)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.MessageInOut, time.Duration) error); ok {
+		r1 = rf(ctx, ns, msg, timeout)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1387,6 +2391,98 @@ func (_m *Orchestrator) ResetConfig(ctx context.Context) {
 	_m.Called(ctx)
 }
 
+// RewindSubscription provides a mock function with given fields: ctx, ns, id, rewind
+func (_m *Orchestrator) RewindSubscription(ctx context.Context, ns string, id string, rewind *fftypes.SubscriptionRewind) (*fftypes.Offset, error) {
+	ret := _m.Called(ctx, ns, id, rewind)
+
+	var r0 *fftypes.Offset
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.SubscriptionRewind) *fftypes.Offset); ok {
+		r0 = rf(ctx, ns, id, rewind)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Offset)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *fftypes.SubscriptionRewind) error); ok {
+		r1 = rf(ctx, ns, id, rewind)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveAlias provides a mock function with given fields: ctx, ns, alias
+func (_m *Orchestrator) SaveAlias(ctx context.Context, ns string, alias *fftypes.Alias) (*fftypes.Alias, error) {
+	ret := _m.Called(ctx, ns, alias)
+
+	var r0 *fftypes.Alias
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.Alias) *fftypes.Alias); ok {
+		r0 = rf(ctx, ns, alias)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Alias)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.Alias) error); ok {
+		r1 = rf(ctx, ns, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveMessageTemplate provides a mock function with given fields: ctx, ns, template
+func (_m *Orchestrator) SaveMessageTemplate(ctx context.Context, ns string, template *fftypes.MessageTemplate) (*fftypes.MessageTemplate, error) {
+	ret := _m.Called(ctx, ns, template)
+
+	var r0 *fftypes.MessageTemplate
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.MessageTemplate) *fftypes.MessageTemplate); ok {
+		r0 = rf(ctx, ns, template)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageTemplate)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.MessageTemplate) error); ok {
+		r1 = rf(ctx, ns, template)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveQuery provides a mock function with given fields: ctx, ns, query
+func (_m *Orchestrator) SaveQuery(ctx context.Context, ns string, query *fftypes.SavedQuery) (*fftypes.SavedQuery, error) {
+	ret := _m.Called(ctx, ns, query)
+
+	var r0 *fftypes.SavedQuery
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.SavedQuery) *fftypes.SavedQuery); ok {
+		r0 = rf(ctx, ns, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.SavedQuery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.SavedQuery) error); ok {
+		r1 = rf(ctx, ns, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Start provides a mock function with given fields:
 func (_m *Orchestrator) Start() error {
 	ret := _m.Called()
@@ -1401,6 +2497,158 @@ func (_m *Orchestrator) Start() error {
 	return r0
 }
 
+// StreamEvents provides a mock function with given fields: ctx, ns, firstEvent
+func (_m *Orchestrator) StreamEvents(ctx context.Context, ns string, firstEvent string) (<-chan *fftypes.EnrichedEvent, error) {
+	ret := _m.Called(ctx, ns, firstEvent)
+
+	var r0 <-chan *fftypes.EnrichedEvent
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) <-chan *fftypes.EnrichedEvent); ok {
+		r0 = rf(ctx, ns, firstEvent)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan *fftypes.EnrichedEvent)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, firstEvent)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Swaps provides a mock function with given fields:
+func (_m *Orchestrator) Swaps() swaps.Manager {
+	ret := _m.Called()
+
+	var r0 swaps.Manager
+	if rf, ok := ret.Get(0).(func() swaps.Manager); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(swaps.Manager)
+	}
+
+	return r0
+}
+
+// TapSubscription provides a mock function with given fields: ctx, ns, id, eventCount
+func (_m *Orchestrator) TapSubscription(ctx context.Context, ns string, id string, eventCount int) (<-chan *fftypes.SubscriptionTapRecord, error) {
+	ret := _m.Called(ctx, ns, id, eventCount)
+
+	var r0 <-chan *fftypes.SubscriptionTapRecord
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) <-chan *fftypes.SubscriptionTapRecord); ok {
+		r0 = rf(ctx, ns, id, eventCount)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan *fftypes.SubscriptionTapRecord)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, ns, id, eventCount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateDataLabels provides a mock function with given fields: ctx, ns, id, input
+func (_m *Orchestrator) UpdateDataLabels(ctx context.Context, ns string, id string, input *fftypes.LabelsUpdate) (*fftypes.Data, error) {
+	ret := _m.Called(ctx, ns, id, input)
+
+	var r0 *fftypes.Data
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.LabelsUpdate) *fftypes.Data); ok {
+		r0 = rf(ctx, ns, id, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Data)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *fftypes.LabelsUpdate) error); ok {
+		r1 = rf(ctx, ns, id, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateMessageLabels provides a mock function with given fields: ctx, ns, id, input
+func (_m *Orchestrator) UpdateMessageLabels(ctx context.Context, ns string, id string, input *fftypes.LabelsUpdate) (*fftypes.Message, error) {
+	ret := _m.Called(ctx, ns, id, input)
+
+	var r0 *fftypes.Message
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.LabelsUpdate) *fftypes.Message); ok {
+		r0 = rf(ctx, ns, id, input)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.Message)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, *fftypes.LabelsUpdate) error); ok {
+		r1 = rf(ctx, ns, id, input)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateMessage provides a mock function with given fields: ctx, ns, msg
+func (_m *Orchestrator) ValidateMessage(ctx context.Context, ns string, msg *fftypes.MessageInOut) (*fftypes.MessageValidationReport, error) {
+	ret := _m.Called(ctx, ns, msg)
+
+	var r0 *fftypes.MessageValidationReport
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.MessageInOut) *fftypes.MessageValidationReport); ok {
+		r0 = rf(ctx, ns, msg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageValidationReport)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.MessageInOut) error); ok {
+		r1 = rf(ctx, ns, msg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyBatch provides a mock function with given fields: ctx, ns, id
+func (_m *Orchestrator) VerifyBatch(ctx context.Context, ns string, id string) (*fftypes.BatchManifestStatus, error) {
+	ret := _m.Called(ctx, ns, id)
+
+	var r0 *fftypes.BatchManifestStatus
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *fftypes.BatchManifestStatus); ok {
+		r0 = rf(ctx, ns, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.BatchManifestStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, ns, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WaitStop provides a mock function with given fields:
 func (_m *Orchestrator) WaitStop() {
 	_m.Called()
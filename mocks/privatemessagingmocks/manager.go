@@ -11,6 +11,8 @@ import (
 	mock "github.com/stretchr/testify/mock"
 
 	sysmessaging "github.com/hyperledger/firefly/internal/sysmessaging"
+
+	time "time"
 )
 
 // Manager is an autogenerated mock type for the Manager type
@@ -147,13 +149,41 @@ func (_m *Manager) PrepareOperation(ctx context.Context, op *fftypes.Operation)
 	return r0, r1
 }
 
-// RequestReply provides a mock function with given fields: ctx, ns, request
-func (_m *Manager) RequestReply(ctx context.Context, ns string, request *fftypes.MessageInOut) (*fftypes.MessageInOut, error) {
-	ret := _m.Called(ctx, ns, request)
+// PreviewGroup provides a mock function with given fields: ctx, ns, in
+func (_m *Manager) PreviewGroup(ctx context.Context, ns string, in *fftypes.InputGroup) (*fftypes.GroupPreview, error) {
+	ret := _m.Called(ctx, ns, in)
+
+	var r0 *fftypes.GroupPreview
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.InputGroup) *fftypes.GroupPreview); ok {
+		r0 = rf(ctx, ns, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.GroupPreview)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.InputGroup) error); ok {
+		r1 = rf(ctx, ns, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RelayBatch provides a mock function with given fields: tw
+func (_m *Manager) RelayBatch(tw *fftypes.TransportWrapper) {
+	_m.Called(tw)
+}
+
+// RequestReply provides a mock function with given fields: ctx, ns, request, timeout
+func (_m *Manager) RequestReply(ctx context.Context, ns string, request *fftypes.MessageInOut, timeout time.Duration) (*fftypes.MessageInOut, error) {
+	ret := _m.Called(ctx, ns, request, timeout)
 
 	var r0 *fftypes.MessageInOut
-	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.MessageInOut) *fftypes.MessageInOut); ok {
-		r0 = rf(ctx, ns, request)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.MessageInOut, time.Duration) *fftypes.MessageInOut); ok {
+		r0 = rf(ctx, ns, request, timeout)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*fftypes.MessageInOut)
@@ -161,8 +191,8 @@ func (_m *Manager) RequestReply(ctx context.Context, ns string, request *fftypes
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.MessageInOut) error); ok {
-		r1 = rf(ctx, ns, request)
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.MessageInOut, time.Duration) error); ok {
+		r1 = rf(ctx, ns, request, timeout)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -250,3 +280,19 @@ func (_m *Manager) Start() error {
 
 	return r0
 }
+
+// ValidateMessage provides a mock function with given fields: ctx, ns, in
+func (_m *Manager) ValidateMessage(ctx context.Context, ns string, in *fftypes.MessageInOut) *fftypes.MessageValidationReport {
+	ret := _m.Called(ctx, ns, in)
+
+	var r0 *fftypes.MessageValidationReport
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.MessageInOut) *fftypes.MessageValidationReport); ok {
+		r0 = rf(ctx, ns, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*fftypes.MessageValidationReport)
+		}
+	}
+
+	return r0
+}
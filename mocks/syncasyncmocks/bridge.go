@@ -11,6 +11,8 @@ import (
 	syncasync "github.com/hyperledger/firefly/internal/syncasync"
 
 	sysmessaging "github.com/hyperledger/firefly/internal/sysmessaging"
+
+	time "time"
 )
 
 // Bridge is an autogenerated mock type for the Bridge type
@@ -69,13 +71,13 @@ func (_m *Bridge) WaitForMessage(ctx context.Context, ns string, id *fftypes.UUI
 	return r0, r1
 }
 
-// WaitForReply provides a mock function with given fields: ctx, ns, id, send
-func (_m *Bridge) WaitForReply(ctx context.Context, ns string, id *fftypes.UUID, send syncasync.RequestSender) (*fftypes.MessageInOut, error) {
-	ret := _m.Called(ctx, ns, id, send)
+// WaitForReply provides a mock function with given fields: ctx, ns, id, timeout, send
+func (_m *Bridge) WaitForReply(ctx context.Context, ns string, id *fftypes.UUID, timeout time.Duration, send syncasync.RequestSender) (*fftypes.MessageInOut, error) {
+	ret := _m.Called(ctx, ns, id, timeout, send)
 
 	var r0 *fftypes.MessageInOut
-	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, syncasync.RequestSender) *fftypes.MessageInOut); ok {
-		r0 = rf(ctx, ns, id, send)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *fftypes.UUID, time.Duration, syncasync.RequestSender) *fftypes.MessageInOut); ok {
+		r0 = rf(ctx, ns, id, timeout, send)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*fftypes.MessageInOut)
@@ -83,8 +85,8 @@ func (_m *Bridge) WaitForReply(ctx context.Context, ns string, id *fftypes.UUID,
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID, syncasync.RequestSender) error); ok {
-		r1 = rf(ctx, ns, id, send)
+	if rf, ok := ret.Get(1).(func(context.Context, string, *fftypes.UUID, time.Duration, syncasync.RequestSender) error); ok {
+		r1 = rf(ctx, ns, id, timeout, send)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -3,6 +3,10 @@
 package sysmessagingmocks
 
 import (
+	context "context"
+
+	fftypes "github.com/hyperledger/firefly/pkg/fftypes"
+
 	mock "github.com/stretchr/testify/mock"
 
 	system "github.com/hyperledger/firefly/internal/events/system"
@@ -13,6 +17,20 @@ type SystemEvents struct {
 	mock.Mock
 }
 
+// AddDurableSystemEventListener provides a mock function with given fields: ctx, ns, name, filter, el
+func (_m *SystemEvents) AddDurableSystemEventListener(ctx context.Context, ns string, name string, filter *fftypes.SubscriptionFilter, el system.EventListener) error {
+	ret := _m.Called(ctx, ns, name, filter, el)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *fftypes.SubscriptionFilter, system.EventListener) error); ok {
+		r0 = rf(ctx, ns, name, filter, el)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AddSystemEventListener provides a mock function with given fields: ns, el
 func (_m *SystemEvents) AddSystemEventListener(ns string, el system.EventListener) error {
 	ret := _m.Called(ns, el)
@@ -51,8 +51,16 @@ type Plugin interface {
 	// SubmitBatchPin sequences a batch of message globally to all viewers of a given ledger
 	SubmitBatchPin(ctx context.Context, operationID *fftypes.UUID, ledgerID *fftypes.UUID, signingKey string, batch *BatchPin) error
 
-	// InvokeContract submits a new transaction to be executed by custom on-chain logic
-	InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) error
+	// GetBatchPinMigration returns the status of a configured move to a new BatchPin contract, or nil if there is none
+	GetBatchPinMigration(ctx context.Context) *fftypes.BatchPinMigration
+
+	// ActivateBatchPinMigration switches batch pin submission to the new BatchPin contract immediately,
+	// without waiting for the activation block to be observed
+	ActivateBatchPinMigration(ctx context.Context) (*fftypes.BatchPinMigration, error)
+
+	// InvokeContract submits a new transaction to be executed by custom on-chain logic.
+	// The errors are the custom errors declared on the interface, used to decode the reason if the transaction is reverted
+	InvokeContract(ctx context.Context, operationID *fftypes.UUID, signingKey string, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}, errors fftypes.FFIErrors) error
 
 	// QueryContract executes a method via custom on-chain logic and returns the result
 	QueryContract(ctx context.Context, location *fftypes.JSONAny, method *fftypes.FFIMethod, input map[string]interface{}) (interface{}, error)
@@ -63,6 +71,10 @@ type Plugin interface {
 	// DeleteContractListener deletes a previously-created subscription
 	DeleteContractListener(ctx context.Context, subscription *fftypes.ContractListener) error
 
+	// RewindContractListener asks the connector to redeliver events for a subscription, starting from the
+	// specified block. Returns a checkpoint, which sorts before the protocol ID of every event in that block
+	RewindContractListener(ctx context.Context, subscription *fftypes.ContractListener, blockNumber uint64) (checkpoint string, err error)
+
 	// GetFFIParamValidator returns a blockchain-plugin-specific validator for FFIParams and their JSON Schema
 	GetFFIParamValidator(ctx context.Context) (fftypes.FFIParamValidator, error)
 
@@ -77,13 +89,16 @@ type Plugin interface {
 // cluster instances of the node.
 type Callbacks interface {
 	// BlockchainOpUpdate notifies firefly of an update to this plugin's operation within a transaction.
-	// Only success/failure and errorMessage (for errors) are modeled.
-	// opOutput can be used to add opaque protocol specific JSON from the plugin (protocol transaction ID etc.)
+	// The transaction is described in the same way by every plugin - with the ID of the transaction on the chain,
+	// its signer, success/failure, and finality - and any protocol specific detail is put in its extras.
+	// errorMessage is set for failures.
+	// opOutput can be used to add opaque protocol specific JSON from the plugin (the full reply from the connector etc.)
 	// Note this is an optional hook information, and stored separately to the confirmation of the actual event that was being submitted/sequenced.
 	// Only the party submitting the transaction will see this data.
+	// receipt is the cost and outcome of the transaction, where the connector reported it.
 	//
 	// Error should will only be returned in shutdown scenarios
-	BlockchainOpUpdate(operationID *fftypes.UUID, txState TransactionStatus, blockchainTXID, errorMessage string, opOutput fftypes.JSONObject) error
+	BlockchainOpUpdate(operationID *fftypes.UUID, tx *fftypes.BlockchainTransaction, errorMessage string, opOutput fftypes.JSONObject, receipt *fftypes.BlockchainReceipt) error
 
 	// BatchPinComplete notifies on the arrival of a sequenced batch of messages, which might have been
 	// submitted by us, or by any other authorized party in the network.
@@ -93,6 +108,12 @@ type Callbacks interface {
 
 	// BlockchainEvent notifies on the arrival of any event from a user-created subscription.
 	BlockchainEvent(event *EventWithSubscription) error
+
+	// ChainReorg notifies that batch pins previously delivered by BatchPinComplete are in blocks that
+	// have been removed from the canonical chain.
+	//
+	// Error should will only be returned in shutdown scenarios
+	ChainReorg(reorg *Reorg) error
 }
 
 // Capabilities the supported featureset of the blockchain
@@ -101,8 +122,15 @@ type Capabilities struct {
 	// GlobalSequencer means submitting an ordered piece of data visible to all
 	// participants of the network (requires an all-participant chain)
 	GlobalSequencer bool
+
+	// PrivateTransactions means batch pins for private groups can be submitted as private
+	// transactions, so they are only visible to the nodes in the group
+	PrivateTransactions bool
 }
 
+// NodeProfilePrivateTransactionKey is the field in the profile of a node, that holds the key of the node in the private transaction manager
+const NodeProfilePrivateTransactionKey = "privateTransactionKey"
+
 // TransactionStatus is the only architecturally significant thing that Firefly tracks on blockchain transactions.
 // All other data is consider protocol specific, and hence stored as opaque data.
 type TransactionStatus = fftypes.OpStatus
@@ -144,6 +172,12 @@ type BatchPin struct {
 	//     for batches sent by that sender, within the context (maintined by the sender FireFly node)
 	Contexts []*fftypes.Bytes32
 
+	// PrivateFrom is the private transaction key of this node, set only when submitting a private batch and the plugin supports private transactions
+	PrivateFrom string
+
+	// PrivateFor is the private transaction keys of all the nodes in the group, set only when PrivateFrom is set
+	PrivateFor []string
+
 	// Event contains info on the underlying blockchain event for this batch pin
 	Event Event
 }
@@ -172,6 +206,15 @@ type Event struct {
 	BlockchainTXID string
 }
 
+// Reorg describes the batch pins affected by a chain reorganization
+type Reorg struct {
+	// BlockNumber is the first block that was found to have been replaced
+	BlockNumber uint64
+
+	// BatchIDs are the batches that were pinned in the replaced blocks
+	BatchIDs []*fftypes.UUID
+}
+
 type EventWithSubscription struct {
 	Event
 
@@ -0,0 +1,192 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package client is a typed Go client for the FireFly API, for applications that send and receive
+// messages through a FireFly node. Events are consumed over a WebSocket, which is reconnected
+// automatically, with the acknowledgement of each event managed by the client.
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/hyperledger/firefly/internal/i18n"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// DefaultNamespace is the namespace requests are made in, if one is not configured
+const DefaultNamespace = "default"
+
+const (
+	defaultRequestTimeout    = 30 * time.Second
+	defaultReconnectDelay    = 250 * time.Millisecond
+	defaultMaxReconnectDelay = 30 * time.Second
+	defaultHeartbeatInterval = 30 * time.Second
+)
+
+// Config is the configuration of a Client
+type Config struct {
+	// URL is the base URL of the FireFly node, such as http://localhost:5000
+	URL string
+	// Namespace is the namespace requests are made in, which is DefaultNamespace if not set
+	Namespace string
+	// Username and Password set basic auth credentials on every request, and on the WebSocket
+	Username string
+	Password string
+	// Headers are set on every request, and on the WebSocket
+	Headers map[string]string
+	// RequestTimeout is the timeout of each request
+	RequestTimeout time.Duration
+	// ReconnectDelay is the initial delay before a WebSocket is reconnected, which doubles on each attempt up to MaxReconnectDelay
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+	// HeartbeatInterval is how often a ping is sent on a WebSocket, to detect a connection that is no longer alive
+	HeartbeatInterval time.Duration
+}
+
+// Client is a client for the FireFly API, that makes requests in a single namespace
+type Client struct {
+	config    Config
+	http      *resty.Client
+	namespace string
+}
+
+// APIError is returned when the FireFly API responds to a request with an error status
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return i18n.ExpandWithCode(context.Background(), i18n.MsgClientAPIError, e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// IsNotFound checks whether an error is an API response that the requested resource does not exist
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// New returns a client for the FireFly node at the configured URL
+func New(ctx context.Context, config *Config) (*Client, error) {
+	if config.URL == "" {
+		return nil, i18n.NewError(ctx, i18n.MsgClientURLRequired)
+	}
+	if _, err := url.Parse(config.URL); err != nil {
+		return nil, i18n.WrapError(ctx, err, i18n.MsgInvalidURL, config.URL)
+	}
+	c := &Client{
+		config:    *config,
+		namespace: config.Namespace,
+	}
+	if c.namespace == "" {
+		c.namespace = DefaultNamespace
+	}
+	if c.config.RequestTimeout <= 0 {
+		c.config.RequestTimeout = defaultRequestTimeout
+	}
+	if c.config.ReconnectDelay <= 0 {
+		c.config.ReconnectDelay = defaultReconnectDelay
+	}
+	if c.config.MaxReconnectDelay <= 0 {
+		c.config.MaxReconnectDelay = defaultMaxReconnectDelay
+	}
+	if c.config.HeartbeatInterval <= 0 {
+		c.config.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	c.http = resty.New().
+		SetBaseURL(strings.TrimSuffix(config.URL, "/") + "/api/v1").
+		SetTimeout(c.config.RequestTimeout).
+		SetHeaders(config.Headers)
+	if config.Username != "" && config.Password != "" {
+		c.http.SetBasicAuth(config.Username, config.Password)
+	}
+	return c, nil
+}
+
+// Namespace returns a client that makes requests in a different namespace, sharing the same connection pool
+func (c *Client) Namespace(ns string) *Client {
+	nc := *c
+	nc.namespace = ns
+	return &nc
+}
+
+func (c *Client) nsPath(path string) string {
+	return "/namespaces/" + url.PathEscape(c.namespace) + path
+}
+
+func (c *Client) request(ctx context.Context, method, path string, query url.Values, body, result interface{}) error {
+	req := c.http.R().
+		SetContext(ctx).
+		SetError(&fftypes.RESTError{})
+	if query != nil {
+		req.SetQueryParamsFromValues(query)
+	}
+	if body != nil {
+		req.SetBody(body)
+	}
+	if result != nil {
+		req.SetResult(result)
+	}
+	res, err := req.Execute(method, path)
+	if err != nil {
+		return err
+	}
+	if res.IsError() {
+		apiErr := &APIError{
+			Method:     method,
+			Path:       path,
+			StatusCode: res.StatusCode(),
+			Message:    res.String(),
+		}
+		if restErr, ok := res.Error().(*fftypes.RESTError); ok && restErr.Error != "" {
+			apiErr.Message = restErr.Error
+		}
+		return apiErr
+	}
+	return nil
+}
+
+func confirmQuery(confirm bool) url.Values {
+	if confirm {
+		return url.Values{"confirm": []string{"true"}}
+	}
+	return nil
+}
+
+// GetStatus returns the status of the node
+func (c *Client) GetStatus(ctx context.Context) (*fftypes.NodeStatus, error) {
+	var status fftypes.NodeStatus
+	if err := c.request(ctx, http.MethodGet, "/status", nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetOrganizations returns the organizations registered in the network
+func (c *Client) GetOrganizations(ctx context.Context) ([]*fftypes.Identity, error) {
+	var orgs []*fftypes.Identity
+	if err := c.request(ctx, http.MethodGet, "/network/organizations", nil, nil, &orgs); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
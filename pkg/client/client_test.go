@@ -0,0 +1,145 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, func()) {
+	server := httptest.NewServer(handler)
+	c, err := New(context.Background(), &Config{
+		URL:      server.URL + "/",
+		Username: "user",
+		Password: "pass",
+		Headers:  map[string]string{"X-Test": "value"},
+	})
+	assert.NoError(t, err)
+	return c, server.Close
+}
+
+func jsonResponse(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestNewMissingURL(t *testing.T) {
+	_, err := New(context.Background(), &Config{})
+	assert.Regexp(t, "FF10543", err)
+}
+
+func TestNewBadURL(t *testing.T) {
+	_, err := New(context.Background(), &Config{URL: ":bad"})
+	assert.Regexp(t, "FF10162", err)
+}
+
+func TestNewDefaults(t *testing.T) {
+	c, err := New(context.Background(), &Config{URL: "http://localhost:5000"})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultNamespace, c.namespace)
+	assert.Equal(t, defaultRequestTimeout, c.config.RequestTimeout)
+	assert.Equal(t, defaultReconnectDelay, c.config.ReconnectDelay)
+	assert.Equal(t, defaultMaxReconnectDelay, c.config.MaxReconnectDelay)
+	assert.Equal(t, defaultHeartbeatInterval, c.config.HeartbeatInterval)
+	assert.Equal(t, "/namespaces/ns%2F1/data", c.Namespace("ns/1").nsPath("/data"))
+	assert.Equal(t, "/namespaces/default/data", c.nsPath("/data"))
+}
+
+func TestGetStatus(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/status", r.URL.Path)
+		assert.Equal(t, "value", r.Header.Get("X-Test"))
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", username)
+		assert.Equal(t, "pass", password)
+		jsonResponse(w, 200, &fftypes.NodeStatus{Node: fftypes.NodeStatusNode{Name: "node1"}})
+	})
+	defer done()
+
+	status, err := c.GetStatus(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "node1", status.Node.Name)
+}
+
+func TestGetStatusNotFound(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, 404, &fftypes.RESTError{Error: "FF10109: Not found"})
+	})
+	defer done()
+
+	_, err := c.GetStatus(context.Background())
+	assert.Regexp(t, "FF10542.*GET /status.*404.*FF10109", err)
+	assert.True(t, IsNotFound(err))
+	assert.False(t, IsNotFound(context.Canceled))
+}
+
+func TestGetStatusErrorNotJSON(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(502)
+		_, _ = w.Write([]byte("bad gateway"))
+	})
+	defer done()
+
+	_, err := c.GetStatus(context.Background())
+	apiErr := err.(*APIError)
+	assert.Equal(t, 502, apiErr.StatusCode)
+	assert.Equal(t, "bad gateway", apiErr.Message)
+}
+
+func TestGetStatusRequestFail(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	done()
+
+	_, err := c.GetStatus(context.Background())
+	assert.Error(t, err)
+	assert.False(t, IsNotFound(err))
+}
+
+func TestGetOrganizations(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v1/network/organizations", r.URL.Path)
+		jsonResponse(w, 200, []*fftypes.Identity{
+			{IdentityBase: fftypes.IdentityBase{Name: "org1"}},
+		})
+	})
+	defer done()
+
+	orgs, err := c.GetOrganizations(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, orgs, 1)
+	assert.Equal(t, "org1", orgs[0].Name)
+}
+
+func TestGetOrganizationsFail(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, 500, &fftypes.RESTError{Error: "pop"})
+	})
+	defer done()
+
+	_, err := c.GetOrganizations(context.Background())
+	assert.Regexp(t, "pop", err)
+}
@@ -0,0 +1,99 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// UploadData uploads a JSON value, which can then be referred to by ID in the data of messages
+func (c *Client) UploadData(ctx context.Context, data *fftypes.DataRefOrValue) (*fftypes.Data, error) {
+	var result fftypes.Data
+	if err := c.request(ctx, http.MethodPost, c.nsPath("/data"), nil, data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetData returns a piece of data by ID
+func (c *Client) GetData(ctx context.Context, id *fftypes.UUID) (*fftypes.Data, error) {
+	var result fftypes.Data
+	if err := c.request(ctx, http.MethodGet, c.nsPath("/data/"+id.String()), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Broadcast sends a message to every member of the network. If confirm is set, the request waits
+// for the message to be confirmed, otherwise it returns as soon as the message is accepted.
+func (c *Client) Broadcast(ctx context.Context, msg *fftypes.MessageInOut, confirm bool) (*fftypes.Message, error) {
+	var result fftypes.Message
+	if err := c.request(ctx, http.MethodPost, c.nsPath("/messages/broadcast"), confirmQuery(confirm), msg, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SendPrivate sends a message to the members of the group of the message. If confirm is set, the
+// request waits for the message to be confirmed, otherwise it returns as soon as the message is accepted.
+func (c *Client) SendPrivate(ctx context.Context, msg *fftypes.MessageInOut, confirm bool) (*fftypes.Message, error) {
+	var result fftypes.Message
+	if err := c.request(ctx, http.MethodPost, c.nsPath("/messages/private"), confirmQuery(confirm), msg, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetMessage returns a message by ID
+func (c *Client) GetMessage(ctx context.Context, id *fftypes.UUID) (*fftypes.Message, error) {
+	var result fftypes.Message
+	if err := c.request(ctx, http.MethodGet, c.nsPath("/messages/"+id.String()), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetMessageData returns the data of a message, in the order it is referenced by the message
+func (c *Client) GetMessageData(ctx context.Context, id *fftypes.UUID) (fftypes.DataArray, error) {
+	var result fftypes.DataArray
+	if err := c.request(ctx, http.MethodGet, c.nsPath("/messages/"+id.String()+"/data"), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetMessages queries messages, using the filter syntax of the API in the query (such as "topics=topic1" and "sort=-created")
+func (c *Client) GetMessages(ctx context.Context, query url.Values) ([]*fftypes.Message, error) {
+	var result []*fftypes.Message
+	if err := c.request(ctx, http.MethodGet, c.nsPath("/messages"), query, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetEvents queries events, using the filter syntax of the API in the query (such as "type=message_confirmed")
+func (c *Client) GetEvents(ctx context.Context, query url.Values) ([]*fftypes.Event, error) {
+	var result []*fftypes.Event
+	if err := c.request(ctx, http.MethodGet, c.nsPath("/events"), query, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
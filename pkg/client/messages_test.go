@@ -0,0 +1,176 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadData(t *testing.T) {
+	dataID := fftypes.NewUUID()
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/namespaces/default/data", r.URL.Path)
+		var in fftypes.DataRefOrValue
+		err := json.NewDecoder(r.Body).Decode(&in)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, in.Value.String())
+		jsonResponse(w, 201, &fftypes.Data{ID: dataID, Value: in.Value})
+	})
+	defer done()
+
+	data, err := c.UploadData(context.Background(), &fftypes.DataRefOrValue{Value: fftypes.JSONAnyPtr(`{"a":1}`)})
+	assert.NoError(t, err)
+	assert.Equal(t, dataID, data.ID)
+}
+
+func TestGetData(t *testing.T) {
+	dataID := fftypes.NewUUID()
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/ns1/data/"+dataID.String(), r.URL.Path)
+		jsonResponse(w, 200, &fftypes.Data{ID: dataID})
+	})
+	defer done()
+
+	data, err := c.Namespace("ns1").GetData(context.Background(), dataID)
+	assert.NoError(t, err)
+	assert.Equal(t, dataID, data.ID)
+}
+
+func TestBroadcast(t *testing.T) {
+	msgID := fftypes.NewUUID()
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/namespaces/default/messages/broadcast", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("confirm"))
+		var in fftypes.MessageInOut
+		err := json.NewDecoder(r.Body).Decode(&in)
+		assert.NoError(t, err)
+		assert.Equal(t, "topic1", in.Header.Topics[0])
+		jsonResponse(w, 200, &fftypes.Message{Header: fftypes.MessageHeader{ID: msgID}, State: fftypes.MessageStateConfirmed})
+	})
+	defer done()
+
+	msg, err := c.Broadcast(context.Background(), &fftypes.MessageInOut{
+		Message: fftypes.Message{Header: fftypes.MessageHeader{Topics: fftypes.FFStringArray{"topic1"}}},
+		InlineData: fftypes.InlineData{
+			{Value: fftypes.JSONAnyPtr(`"hello"`)},
+		},
+	}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, msgID, msg.Header.ID)
+	assert.Equal(t, fftypes.MessageStateConfirmed, msg.State)
+}
+
+func TestSendPrivate(t *testing.T) {
+	msgID := fftypes.NewUUID()
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/default/messages/private", r.URL.Path)
+		assert.Empty(t, r.URL.Query().Get("confirm"))
+		jsonResponse(w, 202, &fftypes.Message{Header: fftypes.MessageHeader{ID: msgID}})
+	})
+	defer done()
+
+	msg, err := c.SendPrivate(context.Background(), &fftypes.MessageInOut{
+		Group: &fftypes.InputGroup{Members: []fftypes.MemberInput{{Identity: "org1"}}},
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, msgID, msg.Header.ID)
+}
+
+func TestGetMessage(t *testing.T) {
+	msgID := fftypes.NewUUID()
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/default/messages/"+msgID.String(), r.URL.Path)
+		jsonResponse(w, 200, &fftypes.Message{Header: fftypes.MessageHeader{ID: msgID}})
+	})
+	defer done()
+
+	msg, err := c.GetMessage(context.Background(), msgID)
+	assert.NoError(t, err)
+	assert.Equal(t, msgID, msg.Header.ID)
+}
+
+func TestGetMessageData(t *testing.T) {
+	msgID := fftypes.NewUUID()
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/default/messages/"+msgID.String()+"/data", r.URL.Path)
+		jsonResponse(w, 200, fftypes.DataArray{{ID: fftypes.NewUUID()}})
+	})
+	defer done()
+
+	data, err := c.GetMessageData(context.Background(), msgID)
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+}
+
+func TestGetMessages(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/default/messages", r.URL.Path)
+		assert.Equal(t, "topic1", r.URL.Query().Get("topics"))
+		jsonResponse(w, 200, []*fftypes.Message{{}, {}})
+	})
+	defer done()
+
+	msgs, err := c.GetMessages(context.Background(), url.Values{"topics": []string{"topic1"}})
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+}
+
+func TestGetEvents(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/default/events", r.URL.Path)
+		assert.Equal(t, "message_confirmed", r.URL.Query().Get("type"))
+		jsonResponse(w, 200, []*fftypes.Event{{Type: fftypes.EventTypeMessageConfirmed}})
+	})
+	defer done()
+
+	events, err := c.GetEvents(context.Background(), url.Values{"type": []string{"message_confirmed"}})
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestRequestsFail(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse(w, 500, &fftypes.RESTError{Error: "pop"})
+	})
+	defer done()
+
+	ctx := context.Background()
+	id := fftypes.NewUUID()
+	checkErr := func(_ interface{}, err error) {
+		assert.Regexp(t, "FF10542.*500.*pop", err)
+	}
+	checkErr(c.UploadData(ctx, &fftypes.DataRefOrValue{}))
+	checkErr(c.GetData(ctx, id))
+	checkErr(c.Broadcast(ctx, &fftypes.MessageInOut{}, false))
+	checkErr(c.SendPrivate(ctx, &fftypes.MessageInOut{}, false))
+	checkErr(c.GetMessage(ctx, id))
+	checkErr(c.GetMessageData(ctx, id))
+	checkErr(c.GetMessages(ctx, nil))
+	checkErr(c.GetEvents(ctx, nil))
+	checkErr(c.CreateSubscription(ctx, &fftypes.Subscription{}))
+	checkErr(c.GetSubscriptions(ctx, nil))
+	checkErr(nil, c.DeleteSubscription(ctx, id))
+}
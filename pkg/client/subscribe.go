@@ -0,0 +1,241 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/hyperledger/firefly/internal/log"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/hyperledger/firefly/pkg/wsclient"
+)
+
+// SubscribeOptions configures the events delivered to a Subscription
+type SubscribeOptions struct {
+	// Name is the name of a durable subscription created with CreateSubscription. If not set, an ephemeral
+	// subscription is started using the Filter and Options, which lasts as long as the Subscription.
+	Name    string
+	Filter  fftypes.SubscriptionFilter
+	Options fftypes.SubscriptionOptions
+	// AutoAck acknowledges each event as it is delivered. Otherwise each event must be acknowledged with
+	// Event.Ack, and only as many events as the readAhead option of the subscription are delivered unacknowledged.
+	AutoAck bool
+	// ResumeToken resumes an ephemeral subscription after the event the token was delivered with
+	ResumeToken string
+}
+
+// Subscription delivers events over a WebSocket, which is reconnected automatically if the connection is
+// lost. Events delivered on a lost connection, that were not acknowledged, are delivered again after the
+// reconnect. An ephemeral subscription resumes its server-side session if the node still holds it, and
+// otherwise resumes after the last event that was acknowledged.
+type Subscription struct {
+	ctx         context.Context
+	ws          wsclient.WSClient
+	start       fftypes.WSClientActionStartPayload
+	mux         sync.Mutex
+	connection  int
+	resumeToken string
+	session     string
+	events      chan *Event
+	closing     chan struct{}
+	closeOnce   sync.Once
+}
+
+// Event is an event delivered on a Subscription
+type Event struct {
+	fftypes.EventDelivery
+	sub        *Subscription
+	connection int
+}
+
+// Subscribe connects a WebSocket, and starts delivering the events of a subscription. The subscription is
+// closed when the context is cancelled, or Close is called.
+func (c *Client) Subscribe(ctx context.Context, options *SubscribeOptions) (*Subscription, error) {
+	autoAck := options.AutoAck
+	s := &Subscription{
+		ctx: log.WithLogger(ctx, log.L(ctx).WithField("role", "firefly-client")),
+		start: fftypes.WSClientActionStartPayload{
+			WSClientActionBase: fftypes.WSClientActionBase{
+				Type: fftypes.WSClientActionStart,
+			},
+			AutoAck:   &autoAck,
+			Namespace: c.namespace,
+			Name:      options.Name,
+			Ephemeral: options.Name == "",
+			Filter:    options.Filter,
+			Options:   options.Options,
+		},
+		resumeToken: options.ResumeToken,
+		events:      make(chan *Event),
+		closing:     make(chan struct{}),
+	}
+	headers := make(fftypes.JSONObject, len(c.config.Headers))
+	for k, v := range c.config.Headers {
+		headers[k] = v
+	}
+	ws, err := wsclient.New(s.ctx, &wsclient.WSConfig{
+		HTTPURL:           c.config.URL,
+		WSKeyPath:         "/ws",
+		InitialDelay:      c.config.ReconnectDelay,
+		MaximumDelay:      c.config.MaxReconnectDelay,
+		AuthUsername:      c.config.Username,
+		AuthPassword:      c.config.Password,
+		HTTPHeaders:       headers,
+		HeartbeatInterval: c.config.HeartbeatInterval,
+	}, nil, s.afterConnect)
+	if err != nil {
+		return nil, err
+	}
+	s.ws = ws
+	if err := ws.Connect(); err != nil {
+		return nil, err
+	}
+	go s.receiveLoop()
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.closing:
+		}
+	}()
+	return s, nil
+}
+
+// Events returns the channel events are delivered on, which is closed once the subscription is closed
+func (s *Subscription) Events() <-chan *Event {
+	return s.events
+}
+
+// ResumeToken returns the token of the last event that was acknowledged on an ephemeral subscription, which
+// can be used to resume the subscription after that event with a new Subscription
+func (s *Subscription) ResumeToken() string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return s.resumeToken
+}
+
+// Close closes the WebSocket of the subscription
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closing)
+		s.ws.Close()
+	})
+}
+
+// afterConnect starts the subscription on each new connection. An ephemeral subscription resumes its session, or is
+// started after the last event that was acknowledged. Events delivered on the previous connection can no longer be acknowledged.
+func (s *Subscription) afterConnect(ctx context.Context, w wsclient.WSClient) error {
+	s.mux.Lock()
+	s.connection++
+	start := s.start
+	if start.Ephemeral {
+		start.ResumeToken = s.resumeToken
+		start.Session = s.session
+	}
+	s.mux.Unlock()
+	b, _ := json.Marshal(&start)
+	return w.Send(ctx, b)
+}
+
+func (s *Subscription) receiveLoop() {
+	defer close(s.events)
+	for data := range s.ws.Receive() {
+		var msg struct {
+			Type    fftypes.WSClientPayloadType `json:"type"`
+			Error   string                      `json:"error"`
+			Session string                      `json:"session"`
+		}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.L(s.ctx).Errorf("Invalid message received on WebSocket: %s", err)
+			continue
+		}
+		switch msg.Type {
+		case fftypes.WSProtocolErrorEventType:
+			log.L(s.ctx).Errorf("Protocol error received on WebSocket: %s", msg.Error)
+			continue
+		case fftypes.WSClientActionChangeNotifcation:
+			continue
+		case fftypes.WSSessionEventType:
+			s.mux.Lock()
+			s.session = msg.Session
+			s.mux.Unlock()
+			continue
+		}
+		event := &Event{sub: s}
+		if err := json.Unmarshal(data, &event.EventDelivery); err != nil {
+			log.L(s.ctx).Errorf("Invalid event received on WebSocket: %s", err)
+			continue
+		}
+		s.mux.Lock()
+		event.connection = s.connection
+		if *s.start.AutoAck && event.ResumeToken != "" {
+			s.resumeToken = event.ResumeToken
+		}
+		s.mux.Unlock()
+		select {
+		case s.events <- event:
+		case <-s.closing:
+			// Events are discarded once closed, until the WebSocket has closed
+		}
+	}
+}
+
+// Ack acknowledges the event, so the next event can be delivered. Acknowledging an event that was
+// delivered on a connection that has since been lost has no effect, as it is delivered again.
+func (e *Event) Ack(ctx context.Context) error {
+	return e.ack(ctx, &fftypes.WSClientActionAckPayload{
+		WSClientActionBase: fftypes.WSClientActionBase{
+			Type: fftypes.WSClientActionAck,
+		},
+		ID:           e.ID,
+		Subscription: &e.Subscription,
+	})
+}
+
+// AckUpTo acknowledges the event, and every unacknowledged event with an earlier sequence on the same
+// subscription, in a single round trip.
+func (e *Event) AckUpTo(ctx context.Context) error {
+	return e.ack(ctx, &fftypes.WSClientActionAckPayload{
+		WSClientActionBase: fftypes.WSClientActionBase{
+			Type: fftypes.WSClientActionAck,
+		},
+		UpToSequence: e.Sequence,
+		Subscription: &e.Subscription,
+	})
+}
+
+func (e *Event) ack(ctx context.Context, ack *fftypes.WSClientActionAckPayload) error {
+	s := e.sub
+	s.mux.Lock()
+	stale := e.connection != s.connection
+	s.mux.Unlock()
+	if *s.start.AutoAck || stale {
+		return nil
+	}
+	b, _ := json.Marshal(ack)
+	if err := s.ws.Send(ctx, b); err != nil {
+		return err
+	}
+	if e.ResumeToken != "" {
+		s.mux.Lock()
+		s.resumeToken = e.ResumeToken
+		s.mux.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestWSClient starts a server that accepts any number of WebSocket connections, returning each one on a channel
+func newTestWSClient(t *testing.T) (*Client, chan *websocket.Conn, func()) {
+	upgrader := &websocket.Upgrader{}
+	conns := make(chan *websocket.Conn, 5)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/ws", r.URL.Path)
+		assert.Equal(t, "value", r.Header.Get("X-Test"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		assert.NoError(t, err)
+		conns <- conn
+	}))
+	c, err := New(context.Background(), &Config{
+		URL:            server.URL,
+		Namespace:      "ns1",
+		Headers:        map[string]string{"X-Test": "value"},
+		ReconnectDelay: time.Millisecond,
+	})
+	assert.NoError(t, err)
+	return c, conns, server.Close
+}
+
+func readStart(t *testing.T, conn *websocket.Conn) *fftypes.WSClientActionStartPayload {
+	var start fftypes.WSClientActionStartPayload
+	err := conn.ReadJSON(&start)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.WSClientActionStart, start.Type)
+	assert.Equal(t, "ns1", start.Namespace)
+	return &start
+}
+
+func readAck(t *testing.T, conn *websocket.Conn) *fftypes.WSClientActionAckPayload {
+	var ack fftypes.WSClientActionAckPayload
+	err := conn.ReadJSON(&ack)
+	assert.NoError(t, err)
+	assert.Equal(t, fftypes.WSClientActionAck, ack.Type)
+	return &ack
+}
+
+func sendEvent(t *testing.T, conn *websocket.Conn, resumeToken string) *fftypes.EventDelivery {
+	event := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{
+				ID:   fftypes.NewUUID(),
+				Type: fftypes.EventTypeMessageConfirmed,
+			},
+		},
+		Subscription: fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"},
+		ResumeToken:  resumeToken,
+	}
+	err := conn.WriteJSON(event)
+	assert.NoError(t, err)
+	return event
+}
+
+func TestSubscribeEphemeralAckAndResume(t *testing.T) {
+	c, conns, done := newTestWSClient(t)
+	defer done()
+
+	ctx := context.Background()
+	sub, err := c.Subscribe(ctx, &SubscribeOptions{
+		Filter:      fftypes.SubscriptionFilter{Topic: "topic1"},
+		ResumeToken: "token0",
+	})
+	assert.NoError(t, err)
+
+	conn1 := <-conns
+	start := readStart(t, conn1)
+	assert.True(t, start.Ephemeral)
+	assert.False(t, *start.AutoAck)
+	assert.Equal(t, "topic1", start.Filter.Topic)
+	assert.Equal(t, "token0", start.ResumeToken)
+	assert.Empty(t, start.Session)
+	_ = conn1.WriteJSON(&fftypes.WSSessionPayload{Type: fftypes.WSSessionEventType, Session: "session1"})
+
+	// Messages that are not events are skipped
+	_ = conn1.WriteMessage(websocket.TextMessage, []byte(`!json`))
+	_ = conn1.WriteJSON(&fftypes.WSProtocolErrorPayload{Type: fftypes.WSProtocolErrorEventType, Error: "pop"})
+	_ = conn1.WriteJSON(&fftypes.WSChangeNotification{WSClientActionBase: fftypes.WSClientActionBase{Type: fftypes.WSClientActionChangeNotifcation}})
+	_ = conn1.WriteMessage(websocket.TextMessage, []byte(`{"id":12345}`))
+
+	sentA := sendEvent(t, conn1, "tokenA")
+	eventA := <-sub.Events()
+	assert.Equal(t, sentA.ID, eventA.ID)
+	err = eventA.Ack(ctx)
+	assert.NoError(t, err)
+	ack := readAck(t, conn1)
+	assert.Equal(t, sentA.ID, ack.ID)
+	assert.Equal(t, "sub1", ack.Subscription.Name)
+	assert.Equal(t, "tokenA", sub.ResumeToken())
+
+	// An event that is not acknowledged before the connection is lost is delivered again after the reconnect
+	sendEvent(t, conn1, "tokenB")
+	eventB := <-sub.Events()
+	conn1.Close()
+
+	conn2 := <-conns
+	start = readStart(t, conn2)
+	assert.Equal(t, "tokenA", start.ResumeToken)
+	assert.Equal(t, "session1", start.Session)
+	err = eventB.Ack(ctx)
+	assert.NoError(t, err)
+
+	sentC := sendEvent(t, conn2, "tokenC")
+	eventC := <-sub.Events()
+	err = eventC.Ack(ctx)
+	assert.NoError(t, err)
+	ack = readAck(t, conn2)
+	assert.Equal(t, sentC.ID, ack.ID)
+	assert.Equal(t, "tokenC", sub.ResumeToken())
+
+	sub.Close()
+	sub.Close()
+	for range sub.Events() {
+	}
+	err = eventC.Ack(ctx)
+	assert.Regexp(t, "FF10160", err)
+}
+
+func TestSubscribeDurableAutoAck(t *testing.T) {
+	c, conns, done := newTestWSClient(t)
+	defer done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, err := c.Subscribe(ctx, &SubscribeOptions{
+		Name:    "sub1",
+		AutoAck: true,
+	})
+	assert.NoError(t, err)
+
+	conn := <-conns
+	start := readStart(t, conn)
+	assert.False(t, start.Ephemeral)
+	assert.True(t, *start.AutoAck)
+	assert.Equal(t, "sub1", start.Name)
+	assert.Empty(t, start.ResumeToken)
+
+	sent := sendEvent(t, conn, "")
+	event := <-sub.Events()
+	assert.Equal(t, sent.ID, event.ID)
+	err = event.Ack(ctx)
+	assert.NoError(t, err)
+
+	// An event that is not read is discarded when the subscription closes
+	sendEvent(t, conn, "")
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	for range sub.Events() {
+	}
+}
+
+func TestSubscribeAutoAckResumeToken(t *testing.T) {
+	c, conns, done := newTestWSClient(t)
+	defer done()
+
+	sub, err := c.Subscribe(context.Background(), &SubscribeOptions{AutoAck: true})
+	assert.NoError(t, err)
+	defer sub.Close()
+
+	conn := <-conns
+	readStart(t, conn)
+	sendEvent(t, conn, "tokenA")
+	<-sub.Events()
+	assert.Equal(t, "tokenA", sub.ResumeToken())
+}
+
+func TestSubscribeAckUpTo(t *testing.T) {
+	c, conns, done := newTestWSClient(t)
+	defer done()
+
+	ctx := context.Background()
+	sub, err := c.Subscribe(ctx, &SubscribeOptions{Name: "sub1"})
+	assert.NoError(t, err)
+	defer sub.Close()
+
+	conn := <-conns
+	readStart(t, conn)
+	sendEvent(t, conn, "")
+	sent := &fftypes.EventDelivery{
+		EnrichedEvent: fftypes.EnrichedEvent{
+			Event: fftypes.Event{ID: fftypes.NewUUID(), Sequence: 12345},
+		},
+		Subscription: fftypes.SubscriptionRef{Namespace: "ns1", Name: "sub1"},
+	}
+	err = conn.WriteJSON(sent)
+	assert.NoError(t, err)
+	<-sub.Events()
+	event := <-sub.Events()
+
+	err = event.AckUpTo(ctx)
+	assert.NoError(t, err)
+	ack := readAck(t, conn)
+	assert.Nil(t, ack.ID)
+	assert.Equal(t, int64(12345), ack.UpToSequence)
+	assert.Equal(t, "sub1", ack.Subscription.Name)
+}
+
+func TestSubscribeConnectFail(t *testing.T) {
+	c, _, done := newTestWSClient(t)
+	done()
+
+	_, err := c.Subscribe(context.Background(), &SubscribeOptions{})
+	assert.Regexp(t, "FF10161", err)
+}
+
+func TestSubscribeBadURL(t *testing.T) {
+	c := &Client{config: Config{URL: ":bad"}}
+	_, err := c.Subscribe(context.Background(), &SubscribeOptions{})
+	assert.Regexp(t, "FF10162", err)
+}
@@ -0,0 +1,49 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// CreateSubscription creates a durable subscription, which can be consumed over a WebSocket with Subscribe.
+// The offset of a durable subscription is stored by the node, so no events are missed while disconnected.
+func (c *Client) CreateSubscription(ctx context.Context, sub *fftypes.Subscription) (*fftypes.Subscription, error) {
+	var result fftypes.Subscription
+	if err := c.request(ctx, http.MethodPost, c.nsPath("/subscriptions"), nil, sub, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSubscriptions queries the durable subscriptions, using the filter syntax of the API in the query (such as "name=sub1")
+func (c *Client) GetSubscriptions(ctx context.Context, query url.Values) ([]*fftypes.Subscription, error) {
+	var result []*fftypes.Subscription
+	if err := c.request(ctx, http.MethodGet, c.nsPath("/subscriptions"), query, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteSubscription deletes a durable subscription by ID
+func (c *Client) DeleteSubscription(ctx context.Context, id *fftypes.UUID) error {
+	return c.request(ctx, http.MethodDelete, c.nsPath("/subscriptions/"+id.String()), nil, nil, nil)
+}
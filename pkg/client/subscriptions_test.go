@@ -0,0 +1,76 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/hyperledger/firefly/pkg/fftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSubscription(t *testing.T) {
+	subID := fftypes.NewUUID()
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/namespaces/default/subscriptions", r.URL.Path)
+		var in fftypes.Subscription
+		err := json.NewDecoder(r.Body).Decode(&in)
+		assert.NoError(t, err)
+		assert.Equal(t, "sub1", in.Name)
+		in.ID = subID
+		jsonResponse(w, 201, &in)
+	})
+	defer done()
+
+	sub, err := c.CreateSubscription(context.Background(), &fftypes.Subscription{
+		SubscriptionRef: fftypes.SubscriptionRef{Name: "sub1"},
+		Transport:       "websockets",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, subID, sub.ID)
+}
+
+func TestGetSubscriptions(t *testing.T) {
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/default/subscriptions", r.URL.Path)
+		assert.Equal(t, "sub1", r.URL.Query().Get("name"))
+		jsonResponse(w, 200, []*fftypes.Subscription{{}})
+	})
+	defer done()
+
+	subs, err := c.GetSubscriptions(context.Background(), url.Values{"name": []string{"sub1"}})
+	assert.NoError(t, err)
+	assert.Len(t, subs, 1)
+}
+
+func TestDeleteSubscription(t *testing.T) {
+	subID := fftypes.NewUUID()
+	c, done := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/api/v1/namespaces/default/subscriptions/"+subID.String(), r.URL.Path)
+		w.WriteHeader(204)
+	})
+	defer done()
+
+	err := c.DeleteSubscription(context.Background(), subID)
+	assert.NoError(t, err)
+}
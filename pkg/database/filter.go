@@ -119,13 +119,15 @@ const (
 	FilterOpIEndsWith FilterOp = ":$"
 	// FilterOpNotICont does not contain the specified text, case insensitive
 	FilterOpNotIEndsWith FilterOp = ";$"
+	// FilterOpTopicMatch matches a hierarchical topic against an MQTT-style pattern, such as "a.*" or "a.#"
+	FilterOpTopicMatch FilterOp = "~="
 )
 
 func filterOpIsStringMatch(op FilterOp) bool {
 	for _, r := range string(op) {
 		switch r {
-		case '%', '^', '$', ':':
-			// Partial or case-insensitive matches all need a string
+		case '%', '^', '$', ':', '~':
+			// Partial, case-insensitive or topic matches all need a string
 			return true
 		}
 	}
@@ -135,7 +137,7 @@ func filterOpIsStringMatch(op FilterOp) bool {
 func filterCannotAcceptNull(op FilterOp) bool {
 	for _, r := range string(op) {
 		switch r {
-		case '%', '^', '$', ':', '>', '<':
+		case '%', '^', '$', ':', '~', '>', '<':
 			// string based matching, or gt/lt cannot accept null
 			return true
 		}
@@ -195,6 +197,8 @@ type FilterBuilder interface {
 	IEndsWith(name string, value driver.Value) Filter
 	// NotIEndsWith disallows the string att the end - case insensitive
 	NotIEndsWith(name string, value driver.Value) Filter
+	// TopicMatch allows topics matching an MQTT-style pattern, where "*" matches one level and "#" the remaining levels
+	TopicMatch(name string, pattern driver.Value) Filter
 }
 
 // NullBehavior specifies whether to sort nulls first or last in a query
@@ -363,6 +367,10 @@ func (f *baseFilter) Finalize() (fi *FilterInfo, err error) {
 		if !ok {
 			return nil, i18n.NewError(f.fb.ctx, i18n.MsgInvalidFilterField, name)
 		}
+		if _, multiValue := field.(*FFStringArrayField); multiValue && f.op == FilterOpTopicMatch {
+			// The levels of a pattern cannot be matched against each topic in a joined list of topics
+			return nil, i18n.NewError(f.fb.ctx, i18n.MsgTopicMatchMultiValueField, name)
+		}
 		skipScan := false
 		switch f.value.(type) {
 		case nil:
@@ -581,6 +589,10 @@ func (fb *filterBuilder) NotIEndsWith(name string, value driver.Value) Filter {
 	return fb.fieldFilter(FilterOpNotIEndsWith, name, value)
 }
 
+func (fb *filterBuilder) TopicMatch(name string, pattern driver.Value) Filter {
+	return fb.fieldFilter(FilterOpTopicMatch, name, pattern)
+}
+
 func (fb *filterBuilder) fieldFilter(op FilterOp, name string, value interface{}) Filter {
 	return &fieldFilter{
 		baseFilter: baseFilter{
@@ -97,6 +97,19 @@ func TestBuildMessageFilter4(t *testing.T) {
 	assert.Equal(t, "( topics := 'abc' ) && ( topics ;= 'bcd' ) && ( topics ^= 'cde' ) && ( topics :^ 'def' ) && ( topics !^ 'efg' ) && ( topics ;^ 'fgh' ) && ( topics $= 'hij' ) && ( topics :$ 'ikl' ) && ( topics !$ 'lmn' ) && ( topics ;$ 'mno' )", f.String())
 }
 
+func TestBuildEventFilterTopicMatch(t *testing.T) {
+	fb := EventQueryFactory.NewFilter(context.Background())
+	f, err := fb.TopicMatch("topic", "a.*.#").Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, "topic ~= 'a.*.#'", f.String())
+}
+
+func TestBuildMessageFilterTopicMatchMultiValue(t *testing.T) {
+	fb := MessageQueryFactory.NewFilter(context.Background())
+	_, err := fb.TopicMatch("topics", "a.*.#").Finalize()
+	assert.Regexp(t, "FF10559", err)
+}
+
 func TestBuildMessageBadInFilterField(t *testing.T) {
 	fb := MessageQueryFactory.NewFilter(context.Background())
 	_, err := fb.And(
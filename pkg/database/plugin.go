@@ -211,6 +211,12 @@ type iOperationCollection interface {
 	// ResolveOperation - Resolve operation upon completion
 	ResolveOperation(ctx context.Context, id *fftypes.UUID, status fftypes.OpStatus, errorMsg string, output fftypes.JSONObject) (err error)
 
+	// SetOperationReceipt - Record the receipt of the blockchain transaction submitted by an operation
+	SetOperationReceipt(ctx context.Context, id *fftypes.UUID, receipt *fftypes.BlockchainReceipt) (err error)
+
+	// SetOperationBlockchainTX - Record the normalized description of the blockchain transaction submitted by an operation
+	SetOperationBlockchainTX(ctx context.Context, id *fftypes.UUID, blockchainTX *fftypes.BlockchainTransaction) (err error)
+
 	// UpdateOperation - Update an operation
 	UpdateOperation(ctx context.Context, id *fftypes.UUID, update Update) (err error)
 
@@ -257,6 +263,9 @@ type iEventCollection interface {
 
 	// GetEvents - Get events
 	GetEvents(ctx context.Context, filter Filter) (message []*fftypes.Event, res *FilterResult, err error)
+
+	// DeleteEvents - Delete the events with the supplied IDs, as part of compaction
+	DeleteEvents(ctx context.Context, ids []*fftypes.UUID) (err error)
 }
 
 type iIdentitiesCollection interface {
@@ -435,6 +444,20 @@ type iTokenApprovalCollection interface {
 	GetTokenApprovals(ctx context.Context, filter Filter) ([]*fftypes.TokenApproval, *FilterResult, error)
 }
 
+type iTokenMetadataCollection interface {
+	// UpsertTokenMetadata - Link a token to its metadata, replacing any existing link for the token
+	UpsertTokenMetadata(ctx context.Context, metadata *fftypes.TokenMetadata) error
+
+	// GetTokenMetadata - Get the metadata link for a token by pool and token index
+	GetTokenMetadata(ctx context.Context, poolID *fftypes.UUID, tokenIndex string) (*fftypes.TokenMetadata, error)
+
+	// GetTokenMetadataByID - Get a token metadata link by ID
+	GetTokenMetadataByID(ctx context.Context, id *fftypes.UUID) (*fftypes.TokenMetadata, error)
+
+	// GetTokenMetadataList - Get token metadata links
+	GetTokenMetadataList(ctx context.Context, filter Filter) ([]*fftypes.TokenMetadata, *FilterResult, error)
+}
+
 type iFFICollection interface {
 	UpsertFFI(ctx context.Context, cd *fftypes.FFI) error
 	GetFFIs(ctx context.Context, ns string, filter Filter) ([]*fftypes.FFI, *FilterResult, error)
@@ -478,6 +501,11 @@ type iContractListenerCollection interface {
 	// GetContractListeners - get smart contract subscriptions
 	GetContractListeners(ctx context.Context, filter Filter) ([]*fftypes.ContractListener, *FilterResult, error)
 
+	// UpdateContractListenerCheckpoint - record the protocol ID of the last event processed for a listener.
+	// When advanceOnly is set, the checkpoint is only updated if it moves forwards, and false is returned for an event
+	// that has already been processed (such as one redelivered by the connector)
+	UpdateContractListenerCheckpoint(ctx context.Context, id *fftypes.UUID, checkpoint string, advanceOnly bool) (advanced bool, err error)
+
 	// DeleteContractListener - delete a subscription to an external smart contract
 	DeleteContractListenerByID(ctx context.Context, id *fftypes.UUID) (err error)
 }
@@ -493,6 +521,173 @@ type iBlockchainEventCollection interface {
 	GetBlockchainEvents(ctx context.Context, filter Filter) ([]*fftypes.BlockchainEvent, *FilterResult, error)
 }
 
+type iMessageApprovalCollection interface {
+	// InsertMessageApproval - insert the record of an approval of a message
+	InsertMessageApproval(ctx context.Context, approval *fftypes.MessageApproval) (err error)
+
+	// GetMessageApprovals - get message approvals
+	GetMessageApprovals(ctx context.Context, filter Filter) ([]*fftypes.MessageApproval, *FilterResult, error)
+}
+
+type iLegalHoldCollection interface {
+	// InsertLegalHold - insert a legal hold
+	InsertLegalHold(ctx context.Context, hold *fftypes.LegalHold) (err error)
+
+	// UpdateLegalHold - update a legal hold
+	UpdateLegalHold(ctx context.Context, id *fftypes.UUID, update Update) (err error)
+
+	// GetLegalHoldByID - get a legal hold by ID
+	GetLegalHoldByID(ctx context.Context, id *fftypes.UUID) (*fftypes.LegalHold, error)
+
+	// GetLegalHolds - get legal holds
+	GetLegalHolds(ctx context.Context, filter Filter) ([]*fftypes.LegalHold, *FilterResult, error)
+}
+
+type iSavedQueryCollection interface {
+	// UpsertSavedQuery - create or replace a saved query, matching on namespace and name
+	UpsertSavedQuery(ctx context.Context, query *fftypes.SavedQuery) (err error)
+
+	// GetSavedQueryByName - get a saved query by name
+	GetSavedQueryByName(ctx context.Context, ns, name string) (*fftypes.SavedQuery, error)
+
+	// GetSavedQueries - get saved queries
+	GetSavedQueries(ctx context.Context, filter Filter) ([]*fftypes.SavedQuery, *FilterResult, error)
+
+	// DeleteSavedQuery - delete a saved query
+	DeleteSavedQuery(ctx context.Context, id *fftypes.UUID) (err error)
+}
+
+type iQuarantineCollection interface {
+	// InsertQuarantinedMessage - insert the record of a message held in quarantine
+	InsertQuarantinedMessage(ctx context.Context, quarantined *fftypes.QuarantinedMessage) (err error)
+
+	// GetQuarantinedMessageByID - get a quarantined message by ID
+	GetQuarantinedMessageByID(ctx context.Context, id *fftypes.UUID) (*fftypes.QuarantinedMessage, error)
+
+	// GetQuarantinedMessages - get quarantined messages
+	GetQuarantinedMessages(ctx context.Context, filter Filter) ([]*fftypes.QuarantinedMessage, *FilterResult, error)
+
+	// DeleteQuarantinedMessage - delete the record of a quarantined message, once it has been reprocessed
+	DeleteQuarantinedMessage(ctx context.Context, id *fftypes.UUID) (err error)
+}
+
+type iMessageStateCollection interface {
+	// UpsertMessageState - create or replace the state record of a message
+	UpsertMessageState(ctx context.Context, state *fftypes.MessageStateRecord) (err error)
+
+	// GetMessageStates - get message state records
+	GetMessageStates(ctx context.Context, filter Filter) ([]*fftypes.MessageStateRecord, *FilterResult, error)
+}
+
+type iAliasCollection interface {
+	// UpsertAlias - create or replace an alias in the address book, matching on namespace and name
+	UpsertAlias(ctx context.Context, alias *fftypes.Alias) (err error)
+
+	// GetAliasByName - get an alias by name
+	GetAliasByName(ctx context.Context, ns, name string) (*fftypes.Alias, error)
+
+	// GetAliases - get aliases
+	GetAliases(ctx context.Context, filter Filter) ([]*fftypes.Alias, *FilterResult, error)
+
+	// DeleteAlias - delete an alias
+	DeleteAlias(ctx context.Context, id *fftypes.UUID) (err error)
+}
+
+type iReportCollection interface {
+	// InsertReport - insert a generated report
+	InsertReport(ctx context.Context, report *fftypes.Report) (err error)
+
+	// GetReportByID - get a report by ID
+	GetReportByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Report, error)
+
+	// GetReports - get reports
+	GetReports(ctx context.Context, filter Filter) ([]*fftypes.Report, *FilterResult, error)
+}
+
+type iSwapCollection interface {
+	// InsertSwap - insert a new swap
+	InsertSwap(ctx context.Context, swap *fftypes.Swap) (err error)
+
+	// UpdateSwap - update the state and legs of a swap
+	UpdateSwap(ctx context.Context, swap *fftypes.Swap) (err error)
+
+	// GetSwapByID - get a swap by ID
+	GetSwapByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Swap, error)
+
+	// GetSwaps - get swaps
+	GetSwaps(ctx context.Context, filter Filter) ([]*fftypes.Swap, *FilterResult, error)
+}
+
+type iGovernanceCollection interface {
+	// InsertGovernanceProposal - insert a new governance proposal
+	InsertGovernanceProposal(ctx context.Context, proposal *fftypes.GovernanceProposal) (err error)
+
+	// UpdateGovernanceProposal - update the voting state of a governance proposal
+	UpdateGovernanceProposal(ctx context.Context, proposal *fftypes.GovernanceProposal) (err error)
+
+	// GetGovernanceProposalByID - get a governance proposal by ID
+	GetGovernanceProposalByID(ctx context.Context, id *fftypes.UUID) (*fftypes.GovernanceProposal, error)
+
+	// GetGovernanceProposals - get governance proposals
+	GetGovernanceProposals(ctx context.Context, filter Filter) ([]*fftypes.GovernanceProposal, *FilterResult, error)
+
+	// InsertGovernanceVote - insert a vote on a governance proposal
+	InsertGovernanceVote(ctx context.Context, vote *fftypes.GovernanceVote) (err error)
+
+	// GetGovernanceVotes - get votes on governance proposals
+	GetGovernanceVotes(ctx context.Context, filter Filter) ([]*fftypes.GovernanceVote, *FilterResult, error)
+}
+
+type iIdentityEndorsementCollection interface {
+	// InsertIdentityEndorsement - insert the endorsement of an identity by a member org
+	InsertIdentityEndorsement(ctx context.Context, endorsement *fftypes.IdentityEndorsement) (err error)
+
+	// GetIdentityEndorsements - get identity endorsements
+	GetIdentityEndorsements(ctx context.Context, filter Filter) ([]*fftypes.IdentityEndorsement, *FilterResult, error)
+}
+
+type iInvitationCollection interface {
+	// InsertInvitation - insert a new invitation
+	InsertInvitation(ctx context.Context, invitation *fftypes.Invitation) (err error)
+
+	// UpdateInvitation - update the redemption state of an invitation
+	UpdateInvitation(ctx context.Context, invitation *fftypes.Invitation) (err error)
+
+	// GetInvitationByID - get an invitation by ID
+	GetInvitationByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Invitation, error)
+
+	// GetInvitations - get invitations
+	GetInvitations(ctx context.Context, filter Filter) ([]*fftypes.Invitation, *FilterResult, error)
+}
+
+type iPingCollection interface {
+	// InsertPing - insert a ping sent to another member org
+	InsertPing(ctx context.Context, ping *fftypes.Ping) (err error)
+
+	// UpdatePing - update the state and latency measurements of a ping
+	UpdatePing(ctx context.Context, ping *fftypes.Ping) (err error)
+
+	// GetPingByID - get a ping by ID
+	GetPingByID(ctx context.Context, id *fftypes.UUID) (*fftypes.Ping, error)
+
+	// GetPings - get pings
+	GetPings(ctx context.Context, filter Filter) ([]*fftypes.Ping, *FilterResult, error)
+}
+
+type iMessageTemplateCollection interface {
+	// UpsertMessageTemplate - create or replace a message template, matching on namespace and name
+	UpsertMessageTemplate(ctx context.Context, template *fftypes.MessageTemplate) (err error)
+
+	// GetMessageTemplateByName - get a message template by name
+	GetMessageTemplateByName(ctx context.Context, ns, name string) (*fftypes.MessageTemplate, error)
+
+	// GetMessageTemplates - get message templates
+	GetMessageTemplates(ctx context.Context, filter Filter) ([]*fftypes.MessageTemplate, *FilterResult, error)
+
+	// DeleteMessageTemplate - delete a message template
+	DeleteMessageTemplate(ctx context.Context, id *fftypes.UUID) (err error)
+}
+
 // PersistenceInterface are the operations that must be implemented by a database interface plugin.
 type iChartCollection interface {
 	// GetChartHistogram - Get charting data for a histogram
@@ -556,12 +751,26 @@ type PersistenceInterface interface {
 	iTokenBalanceCollection
 	iTokenTransferCollection
 	iTokenApprovalCollection
+	iTokenMetadataCollection
 	iFFICollection
 	iFFIMethodCollection
 	iFFIEventCollection
 	iContractAPICollection
 	iContractListenerCollection
 	iBlockchainEventCollection
+	iMessageApprovalCollection
+	iLegalHoldCollection
+	iSavedQueryCollection
+	iQuarantineCollection
+	iMessageStateCollection
+	iAliasCollection
+	iMessageTemplateCollection
+	iReportCollection
+	iSwapCollection
+	iGovernanceCollection
+	iInvitationCollection
+	iIdentityEndorsementCollection
+	iPingCollection
 	iChartCollection
 }
 
@@ -606,6 +815,15 @@ const (
 	CollectionContractAPIs      UUIDCollectionNS = "contractapis"
 	CollectionContractListeners UUIDCollectionNS = "contractsubscriptions"
 	CollectionIdentities        UUIDCollectionNS = "identities"
+	CollectionMessageApprovals  UUIDCollectionNS = "messageapprovals"
+	CollectionLegalHolds        UUIDCollectionNS = "legalholds"
+	CollectionSavedQueries      UUIDCollectionNS = "savedqueries"
+	CollectionQuarantine        UUIDCollectionNS = "quarantine"
+	CollectionAliases           UUIDCollectionNS = "aliases"
+	CollectionMessageTemplates  UUIDCollectionNS = "messagetemplates"
+	CollectionReports           UUIDCollectionNS = "reports"
+	CollectionSwaps             UUIDCollectionNS = "swaps"
+	CollectionTokenMetadata     UUIDCollectionNS = "tokenmetadata"
 )
 
 // HashCollectionNS is a collection where the primary key is a hash, such that it can
@@ -625,6 +843,13 @@ const (
 	CollectionNamespaces     UUIDCollection = "namespaces"
 	CollectionTokenTransfers UUIDCollection = "tokentransfers"
 	CollectionTokenApprovals UUIDCollection = "tokenapprovals"
+
+	CollectionGovernanceProposals UUIDCollection = "governanceproposals"
+	CollectionGovernanceVotes     UUIDCollection = "governancevotes"
+
+	CollectionInvitations          UUIDCollection = "invitations"
+	CollectionIdentityEndorsements UUIDCollection = "identityendorsements"
+	CollectionPings                UUIDCollection = "pings"
 )
 
 // OtherCollection are odd balls, that don't fit any of the categories above.
@@ -687,23 +912,27 @@ var NamespaceQueryFactory = &queryFields{
 
 // MessageQueryFactory filter fields for messages
 var MessageQueryFactory = &queryFields{
-	"id":        &UUIDField{},
-	"cid":       &UUIDField{},
-	"namespace": &StringField{},
-	"type":      &StringField{},
-	"author":    &StringField{},
-	"key":       &StringField{},
-	"topics":    &FFStringArrayField{},
-	"tag":       &StringField{},
-	"group":     &Bytes32Field{},
-	"created":   &TimeField{},
-	"hash":      &Bytes32Field{},
-	"pins":      &FFStringArrayField{},
-	"state":     &StringField{},
-	"confirmed": &TimeField{},
-	"sequence":  &Int64Field{},
-	"txtype":    &StringField{},
-	"batch":     &UUIDField{},
+	"id":          &UUIDField{},
+	"cid":         &UUIDField{},
+	"namespace":   &StringField{},
+	"type":        &StringField{},
+	"author":      &StringField{},
+	"key":         &StringField{},
+	"topics":      &FFStringArrayField{},
+	"orderingkey": &StringField{},
+	"labels":      &FFStringArrayField{},
+	"tag":         &StringField{},
+	"priority":    &Int64Field{},
+	"group":       &Bytes32Field{},
+	"created":     &TimeField{},
+	"hash":        &Bytes32Field{},
+	"pins":        &FFStringArrayField{},
+	"state":       &StringField{},
+	"confirmed":   &TimeField{},
+	"sequence":    &Int64Field{},
+	"txtype":      &StringField{},
+	"batch":       &UUIDField{},
+	"attachments": &JSONField{},
 }
 
 // BatchQueryFactory filter fields for batches
@@ -746,6 +975,7 @@ var DataQueryFactory = &queryFields{
 	"blob.size":        &Int64Field{},
 	"created":          &TimeField{},
 	"value":            &JSONField{},
+	"labels":           &FFStringArrayField{},
 }
 
 // DatatypeQueryFactory filter fields for data definitions
@@ -768,18 +998,20 @@ var OffsetQueryFactory = &queryFields{
 
 // OperationQueryFactory filter fields for data operations
 var OperationQueryFactory = &queryFields{
-	"id":        &UUIDField{},
-	"tx":        &UUIDField{},
-	"type":      &StringField{},
-	"namespace": &StringField{},
-	"status":    &StringField{},
-	"error":     &StringField{},
-	"plugin":    &StringField{},
-	"input":     &JSONField{},
-	"output":    &JSONField{},
-	"created":   &TimeField{},
-	"updated":   &TimeField{},
-	"retry":     &UUIDField{},
+	"id":           &UUIDField{},
+	"tx":           &UUIDField{},
+	"type":         &StringField{},
+	"namespace":    &StringField{},
+	"status":       &StringField{},
+	"error":        &StringField{},
+	"plugin":       &StringField{},
+	"input":        &JSONField{},
+	"output":       &JSONField{},
+	"receipt":      &JSONField{},
+	"blockchaintx": &JSONField{},
+	"created":      &TimeField{},
+	"updated":      &TimeField{},
+	"retry":        &UUIDField{},
 }
 
 // SubscriptionQueryFactory filter fields for data subscriptions
@@ -805,6 +1037,7 @@ var EventQueryFactory = &queryFields{
 	"topic":      &StringField{},
 	"sequence":   &Int64Field{},
 	"created":    &TimeField{},
+	"compacted":  &Int64Field{},
 }
 
 // PinQueryFactory filter fields for parked contexts
@@ -883,6 +1116,7 @@ var BlobQueryFactory = &queryFields{
 	"size":       &Int64Field{},
 	"payloadref": &StringField{},
 	"created":    &TimeField{},
+	"threat":     &StringField{},
 }
 
 // TokenPoolQueryFactory filter fields for token pools
@@ -965,6 +1199,17 @@ var TokenApprovalQueryFacory = &queryFields{
 	"blockchainevent": &UUIDField{},
 }
 
+// TokenMetadataQueryFactory filter fields for token metadata links
+var TokenMetadataQueryFactory = &queryFields{
+	"id":         &UUIDField{},
+	"namespace":  &StringField{},
+	"pool":       &UUIDField{},
+	"tokenindex": &StringField{},
+	"data":       &UUIDField{},
+	"created":    &TimeField{},
+	"updated":    &TimeField{},
+}
+
 // FFIQueryFactory filter fields for contract definitions
 var FFIQueryFactory = &queryFields{
 	"id":        &UUIDField{},
@@ -999,6 +1244,7 @@ var ContractListenerQueryFactory = &queryFields{
 	"interface":  &UUIDField{},
 	"namespace":  &StringField{},
 	"protocolid": &StringField{},
+	"checkpoint": &StringField{},
 	"created":    &TimeField{},
 }
 
@@ -1015,6 +1261,163 @@ var BlockchainEventQueryFactory = &queryFields{
 	"timestamp":  &TimeField{},
 }
 
+// MessageApprovalQueryFactory filter fields for message approvals
+var MessageApprovalQueryFactory = &queryFields{
+	"id":        &UUIDField{},
+	"namespace": &StringField{},
+	"message":   &UUIDField{},
+	"policy":    &StringField{},
+	"approver":  &StringField{},
+	"comment":   &StringField{},
+	"created":   &TimeField{},
+}
+
+// LegalHoldQueryFactory filter fields for legal holds
+var LegalHoldQueryFactory = &queryFields{
+	"id":            &UUIDField{},
+	"namespace":     &StringField{},
+	"type":          &StringField{},
+	"ref":           &UUIDField{},
+	"reason":        &StringField{},
+	"created":       &TimeField{},
+	"released":      &TimeField{},
+	"releasereason": &StringField{},
+}
+
+// SavedQueryQueryFactory filter fields for saved queries
+var SavedQueryQueryFactory = &queryFields{
+	"id":          &UUIDField{},
+	"namespace":   &StringField{},
+	"name":        &StringField{},
+	"description": &StringField{},
+	"collection":  &StringField{},
+	"created":     &TimeField{},
+	"updated":     &TimeField{},
+}
+
+// QuarantineQueryFactory filter fields for quarantined messages
+var QuarantineQueryFactory = &queryFields{
+	"id":        &UUIDField{},
+	"namespace": &StringField{},
+	"message":   &UUIDField{},
+	"batch":     &UUIDField{},
+	"reason":    &StringField{},
+	"created":   &TimeField{},
+}
+
+// MessageStateQueryFactory filter fields for message state records
+var MessageStateQueryFactory = &queryFields{
+	"id":         &UUIDField{},
+	"namespace":  &StringField{},
+	"state":      &StringField{},
+	"confirmed":  &TimeField{},
+	"batch":      &UUIDField{},
+	"tx":         &UUIDField{},
+	"replycount": &Int64Field{},
+	"updated":    &TimeField{},
+}
+
+// AliasQueryFactory filter fields for address book aliases
+var AliasQueryFactory = &queryFields{
+	"id":          &UUIDField{},
+	"namespace":   &StringField{},
+	"name":        &StringField{},
+	"description": &StringField{},
+	"identity":    &StringField{},
+	"key":         &StringField{},
+	"created":     &TimeField{},
+	"updated":     &TimeField{},
+}
+
+// ReportQueryFactory filter fields for reports
+var ReportQueryFactory = &queryFields{
+	"id":        &UUIDField{},
+	"namespace": &StringField{},
+	"period":    &StringField{},
+	"start":     &TimeField{},
+	"end":       &TimeField{},
+	"created":   &TimeField{},
+}
+
+// SwapQueryFactory filter fields for swaps
+var SwapQueryFactory = &queryFields{
+	"id":        &UUIDField{},
+	"namespace": &StringField{},
+	"state":     &StringField{},
+	"escrow":    &StringField{},
+	"created":   &TimeField{},
+	"updated":   &TimeField{},
+	"expires":   &TimeField{},
+}
+
+// GovernanceProposalQueryFactory filter fields for governance proposals
+var GovernanceProposalQueryFactory = &queryFields{
+	"id":          &UUIDField{},
+	"type":        &StringField{},
+	"description": &StringField{},
+	"author":      &StringField{},
+	"message":     &UUIDField{},
+	"state":       &StringField{},
+	"approvals":   &Int64Field{},
+	"rejections":  &Int64Field{},
+	"created":     &TimeField{},
+	"decided":     &TimeField{},
+}
+
+// GovernanceVoteQueryFactory filter fields for votes on governance proposals
+var GovernanceVoteQueryFactory = &queryFields{
+	"id":       &UUIDField{},
+	"proposal": &UUIDField{},
+	"approve":  &BoolField{},
+	"author":   &StringField{},
+	"message":  &UUIDField{},
+	"created":  &TimeField{},
+}
+
+// IdentityEndorsementQueryFactory filter fields for endorsements of identities by member orgs
+var IdentityEndorsementQueryFactory = &queryFields{
+	"id":       &UUIDField{},
+	"identity": &UUIDField{},
+	"did":      &StringField{},
+	"author":   &StringField{},
+	"message":  &UUIDField{},
+	"created":  &TimeField{},
+}
+
+// InvitationQueryFactory filter fields for invitations issued by this node
+var InvitationQueryFactory = &queryFields{
+	"id":          &UUIDField{},
+	"description": &StringField{},
+	"inviter":     &StringField{},
+	"node":        &UUIDField{},
+	"state":       &StringField{},
+	"redeemedby":  &StringField{},
+	"created":     &TimeField{},
+	"expires":     &TimeField{},
+	"redeemed":    &TimeField{},
+}
+
+// PingQueryFactory filter fields for pings sent by this node
+var PingQueryFactory = &queryFields{
+	"id":        &UUIDField{},
+	"target":    &StringField{},
+	"state":     &StringField{},
+	"reply":     &UUIDField{},
+	"created":   &TimeField{},
+	"confirmed": &TimeField{},
+	"completed": &TimeField{},
+}
+
+// MessageTemplateQueryFactory filter fields for message templates
+var MessageTemplateQueryFactory = &queryFields{
+	"id":          &UUIDField{},
+	"namespace":   &StringField{},
+	"name":        &StringField{},
+	"description": &StringField{},
+	"created":     &TimeField{},
+	"updated":     &TimeField{},
+}
+
 // ContractAPIQueryFactory filter fields for Contract APIs
 var ContractAPIQueryFactory = &queryFields{
 	"id":        &UUIDField{},
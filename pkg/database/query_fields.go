@@ -25,6 +25,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/pkg/fftypes"
@@ -238,6 +239,7 @@ func (f *Int64Field) filterAsString() bool                 { return false }
 func (f *Int64Field) description() string                  { return "Integer" }
 
 type TimeField struct{}
+
 type timeField struct{ t *fftypes.FFTime }
 
 func (f *timeField) Scan(src interface{}) (err error) {
@@ -247,6 +249,10 @@ func (f *timeField) Scan(src interface{}) (err error) {
 	case int64:
 		f.t = fftypes.UnixTime(tv)
 	case string:
+		if strings.HasPrefix(tv, "now") {
+			f.t, err = parseRelativeTime(tv)
+			return err
+		}
 		f.t, err = fftypes.ParseTimeString(tv)
 		return err
 	case fftypes.FFTime:
@@ -273,6 +279,20 @@ func (f *TimeField) getSerialization() FieldSerialization { return &timeField{}
 func (f *TimeField) filterAsString() bool                 { return false }
 func (f *TimeField) description() string                  { return "Date-time" }
 
+// parseRelativeTime allows filters to use a time relative to when the query runs, such as "now-1h"
+func parseRelativeTime(str string) (*fftypes.FFTime, error) {
+	t := time.Now()
+	if offset := str[len("now"):]; offset != "" {
+		d, err := time.ParseDuration(offset)
+		if err != nil || (offset[0] != '-' && offset[0] != '+') {
+			return nil, i18n.NewError(context.Background(), i18n.MsgRelativeTimeInvalid, str)
+		}
+		t = t.Add(d)
+	}
+	ft := fftypes.FFTime(t)
+	return &ft, nil
+}
+
 type JSONField struct{}
 type jsonField struct{ b []byte }
 
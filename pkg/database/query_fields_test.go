@@ -155,6 +155,31 @@ func TestTimeField(t *testing.T) {
 
 }
 
+func TestTimeFieldRelative(t *testing.T) {
+
+	f := timeField{}
+
+	before := time.Now()
+	err := f.Scan("now")
+	assert.NoError(t, err)
+	assert.False(t, f.t.Time().Before(before))
+
+	err = f.Scan("now-1h")
+	assert.NoError(t, err)
+	assert.True(t, f.t.Time().Before(before.Add(-59*time.Minute)))
+
+	err = f.Scan("now+30m")
+	assert.NoError(t, err)
+	assert.True(t, f.t.Time().After(before.Add(29*time.Minute)))
+
+	err = f.Scan("now1h")
+	assert.Regexp(t, "FF10418", err)
+
+	err = f.Scan("now-lots")
+	assert.Regexp(t, "FF10418", err)
+
+}
+
 func TestJSONField(t *testing.T) {
 
 	fd := &JSONField{}
@@ -91,6 +91,46 @@ type Plugin interface {
 	TransferBLOB(ctx context.Context, opID *fftypes.UUID, peerID string, payloadRef string) (err error)
 }
 
+// StreamingPlugin is implemented by data exchange plugins that support chunked transfer of blobs, as
+// reported by the Streaming capability. This allows very large blobs to be moved between FireFly and the
+// plugin without either side staging the whole payload. Each chunk is acknowledged with a resume token,
+// so an interrupted stream can be re-opened to continue after the last acknowledged chunk.
+type StreamingPlugin interface {
+	Plugin
+
+	// OpenUploadStream starts a chunked upload of a blob to storage, or resumes one if a resume token from a previous ChunkAck is supplied
+	OpenUploadStream(ctx context.Context, ns string, id fftypes.UUID, resumeToken string) (UploadStream, error)
+
+	// OpenDownloadStream starts a chunked download of a stored blob, or resumes one after the chunk acknowledged with the supplied resume token
+	OpenDownloadStream(ctx context.Context, payloadRef string, resumeToken string) (DownloadStream, error)
+}
+
+// UploadStream is a chunked upload of a blob
+type UploadStream interface {
+	// SendChunk sends the next chunk of the blob, returning once the plugin has acknowledged it
+	SendChunk(ctx context.Context, chunk []byte) (ack *ChunkAck, err error)
+
+	// Complete finishes the upload, with the same results as UploadBLOB
+	Complete(ctx context.Context) (payloadRef string, hash *fftypes.Bytes32, size int64, err error)
+}
+
+// DownloadStream is a chunked download of a blob
+type DownloadStream interface {
+	// NextChunk returns the next chunk of the blob, or io.EOF after the last chunk
+	NextChunk(ctx context.Context) (chunk []byte, ack *ChunkAck, err error)
+
+	// Close releases the resources of the download
+	Close() error
+}
+
+// ChunkAck is the acknowledgement of a chunk
+type ChunkAck struct {
+	// Offset is the number of bytes of the blob transferred, up to and including the acknowledged chunk
+	Offset int64
+	// ResumeToken is an opaque token that can be used to resume the stream after the acknowledged chunk
+	ResumeToken string
+}
+
 // Callbacks is the interface provided to the data exchange plugin, to allow it to pass events back to firefly.
 type Callbacks interface {
 
@@ -109,4 +149,6 @@ type Callbacks interface {
 type Capabilities struct {
 	// Manifest - whether TransferResult events contain the manifest generated by the receiving FireFly
 	Manifest bool
+	// Streaming - whether the plugin implements StreamingPlugin, for chunked upload and download of blobs
+	Streaming bool
 }
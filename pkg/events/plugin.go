@@ -57,6 +57,17 @@ type ChangeEventListener interface {
 	ChangeEvent(connID string, ce *fftypes.ChangeEvent)
 }
 
+// Enricher is implemented by deployment-specific code, loaded as a Go plugin, to compute additional fields for
+// each event before it is delivered to an application (such as business keys decoded from the message payload),
+// so applications do not need to parse the payload themselves. The plugin must export a variable named "Enricher".
+type Enricher interface {
+	fftypes.Named
+
+	// Enrich returns the fields to add to the event, which are delivered under the name of the enricher.
+	// The data of the message is supplied for message events, regardless of whether the subscription includes data.
+	Enrich(ctx context.Context, event *fftypes.EventDelivery, data fftypes.DataArray) (fftypes.JSONObject, error)
+}
+
 // PluginAll is a combined interface for easy mocking, with all optional features
 type PluginAll interface {
 	Plugin
@@ -93,4 +104,6 @@ type Callbacks interface {
 type Capabilities struct {
 	// ChangeEvents is whether change events are supported
 	ChangeEvents bool
+	// Serialization is whether event frames can be delivered in the binary serialization formats, as well as JSON
+	Serialization bool
 }
@@ -0,0 +1,63 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// AliasPrefix marks a reference to an alias in the address book, such as "alias:acme", which can be used
+// in place of an identity or key in send requests and query filters
+const AliasPrefix = "alias:"
+
+// Alias is an entry in the local address book, mapping a friendly name to the identity and/or signing key of
+// a counterparty, so that applications do not need to hardcode DIDs and keys
+type Alias struct {
+	ID          *UUID   `json:"id"`
+	Namespace   string  `json:"namespace"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Identity    string  `json:"identity,omitempty"`
+	Key         string  `json:"key,omitempty"`
+	Created     *FFTime `json:"created"`
+	Updated     *FFTime `json:"updated,omitempty"`
+}
+
+// Validate checks the alias has a valid name, and maps to an identity or key
+func (a *Alias) Validate(ctx context.Context) error {
+	if err := ValidateFFNameFieldNoUUID(ctx, a.Name, "name"); err != nil {
+		return err
+	}
+	if a.Identity == "" && a.Key == "" {
+		return i18n.NewError(ctx, i18n.MsgAliasTargetMissing)
+	}
+	if err := ValidateLength(ctx, a.Identity, "identity", 1024); err != nil {
+		return err
+	}
+	return ValidateLength(ctx, a.Key, "key", 1024)
+}
+
+// ParseAliasRef returns the name of the alias, if the value is a reference to an alias
+func ParseAliasRef(value string) (name string, isAlias bool) {
+	if strings.HasPrefix(value, AliasPrefix) {
+		return value[len(AliasPrefix):], true
+	}
+	return "", false
+}
@@ -0,0 +1,53 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliasValidate(t *testing.T) {
+	ctx := context.Background()
+
+	a := &Alias{Name: "!bad"}
+	assert.Regexp(t, "FF10131.*name", a.Validate(ctx))
+
+	a = &Alias{Name: "partner"}
+	assert.Regexp(t, "FF10522", a.Validate(ctx))
+
+	a = &Alias{Name: "partner", Identity: strings.Repeat("x", 1025)}
+	assert.Regexp(t, "FF10188.*identity", a.Validate(ctx))
+
+	a = &Alias{Name: "partner", Key: strings.Repeat("x", 1025)}
+	assert.Regexp(t, "FF10188.*key", a.Validate(ctx))
+
+	a = &Alias{Name: "partner", Identity: "did:firefly:org/org1", Key: "0x12345"}
+	assert.NoError(t, a.Validate(ctx))
+}
+
+func TestParseAliasRef(t *testing.T) {
+	name, isAlias := ParseAliasRef("alias:partner")
+	assert.True(t, isAlias)
+	assert.Equal(t, "partner", name)
+
+	_, isAlias = ParseAliasRef("did:firefly:org/org1")
+	assert.False(t, isAlias)
+}
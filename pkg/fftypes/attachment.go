@@ -0,0 +1,90 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"mime"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// Attachment describes a file carried by a message, tying its document metadata to a blob
+// that is attached to one of the data items of the message
+type Attachment struct {
+	Filename string   `json:"filename"`
+	MimeType string   `json:"mimetype,omitempty"`
+	Size     int64    `json:"size"`
+	Hash     *Bytes32 `json:"hash"`
+}
+
+// AttachmentManifest lists the files attached to a message. It is part of the message header,
+// so the metadata is covered by the message hash and cannot be modified in flight.
+type AttachmentManifest []*Attachment
+
+func (am AttachmentManifest) Validate(ctx context.Context) error {
+	filenames := make(map[string]bool, len(am))
+	for i, a := range am {
+		if a == nil || a.Filename == "" {
+			return i18n.NewError(ctx, i18n.MsgMissingRequiredField, fmt.Sprintf("header.attachments[%d].filename", i))
+		}
+		if err := ValidateLength(ctx, a.Filename, fmt.Sprintf("header.attachments[%d].filename", i), 1024); err != nil {
+			return err
+		}
+		if a.Hash == nil {
+			return i18n.NewError(ctx, i18n.MsgMissingRequiredField, fmt.Sprintf("header.attachments[%d].hash", i))
+		}
+		if a.MimeType != "" {
+			if _, _, err := mime.ParseMediaType(a.MimeType); err != nil {
+				return i18n.NewError(ctx, i18n.MsgAttachmentInvalidMimeType, a.MimeType, i)
+			}
+		}
+		if filenames[a.Filename] {
+			return i18n.NewError(ctx, i18n.MsgDuplicateArrayEntry, "filename", i, a.Filename)
+		}
+		filenames[a.Filename] = true
+	}
+	return nil
+}
+
+// Scan implements sql.Scanner
+func (am *AttachmentManifest) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(src, &am)
+	case string:
+		if src == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(src), &am)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, am)
+	}
+}
+
+// Value implements sql.Valuer
+func (am AttachmentManifest) Value() (driver.Value, error) {
+	if am == nil {
+		return nil, nil
+	}
+	return json.Marshal(am)
+}
@@ -0,0 +1,81 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentManifestValidate(t *testing.T) {
+	ctx := context.Background()
+	hash := NewRandB32()
+
+	assert.NoError(t, AttachmentManifest{
+		{Filename: "invoice.pdf", MimeType: "application/pdf", Size: 12345, Hash: hash},
+		{Filename: "photo.jpg", MimeType: "image/jpeg; charset=binary", Hash: NewRandB32()},
+	}.Validate(ctx))
+	assert.NoError(t, AttachmentManifest(nil).Validate(ctx))
+
+	err := AttachmentManifest{nil}.Validate(ctx)
+	assert.Regexp(t, "FF10140.*attachments\\[0\\].filename", err)
+
+	err = AttachmentManifest{{Hash: hash}}.Validate(ctx)
+	assert.Regexp(t, "FF10140.*attachments\\[0\\].filename", err)
+
+	err = AttachmentManifest{{Filename: string(make([]byte, 1025)), Hash: hash}}.Validate(ctx)
+	assert.Regexp(t, "FF10188", err)
+
+	err = AttachmentManifest{{Filename: "invoice.pdf"}}.Validate(ctx)
+	assert.Regexp(t, "FF10140.*attachments\\[0\\].hash", err)
+
+	err = AttachmentManifest{{Filename: "invoice.pdf", MimeType: "application/", Hash: hash}}.Validate(ctx)
+	assert.Regexp(t, "FF10535", err)
+
+	err = AttachmentManifest{
+		{Filename: "invoice.pdf", Hash: hash},
+		{Filename: "invoice.pdf", Hash: NewRandB32()},
+	}.Validate(ctx)
+	assert.Regexp(t, "FF10228.*invoice.pdf", err)
+}
+
+func TestAttachmentManifestDatabaseSerialization(t *testing.T) {
+	var nilManifest AttachmentManifest
+	v, err := nilManifest.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	m1 := AttachmentManifest{
+		{Filename: "invoice.pdf", MimeType: "application/pdf", Size: 12345, Hash: NewRandB32()},
+	}
+	v, err = m1.Value()
+	assert.NoError(t, err)
+
+	var m2 AttachmentManifest
+	err = m2.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, m1, m2)
+	err = m2.Scan(string(v.([]byte)))
+	assert.NoError(t, err)
+	assert.Equal(t, m1, m2)
+
+	assert.NoError(t, m2.Scan(nil))
+	assert.NoError(t, m2.Scan(""))
+	assert.Regexp(t, "FF10125", m2.Scan(12345))
+}
@@ -29,6 +29,8 @@ var (
 	BatchTypeBroadcast = ffEnum("batchtype", "broadcast")
 	// BatchTypePrivate is a batch that is sent privately to a group
 	BatchTypePrivate = ffEnum("batchtype", "private")
+	// BatchTypeLocal is a batch of local messages, that is confirmed on the local node without being sent anywhere
+	BatchTypeLocal = ffEnum("batchtype", "local")
 )
 
 const (
@@ -80,6 +82,44 @@ type BatchPersisted struct {
 	Confirmed  *FFTime        `json:"confirmed"`
 }
 
+// BatchSummary is the header of a batch, with the number of messages and data it contains, that is
+// included in the batch lifecycle events
+type BatchSummary struct {
+	BatchHeader
+	TX       TransactionRef `json:"tx"`
+	Messages int            `json:"messages"`
+	Data     int            `json:"data"`
+}
+
+// BatchManifestStatus is the manifest of a stored batch, with the local copy of each message and data in it,
+// and the hash computed from the manifest. Used to debug batches that were rejected on a hash mismatch.
+type BatchManifestStatus struct {
+	ID           *UUID                    `json:"id"`
+	Type         BatchType                `json:"type" ffenum:"batchtype"`
+	Namespace    string                   `json:"namespace"`
+	TX           TransactionRef           `json:"tx"`
+	Hash         *Bytes32                 `json:"hash"`
+	ManifestHash *Bytes32                 `json:"manifestHash"`
+	Messages     []*ManifestMessageStatus `json:"messages"`
+	Data         []*ManifestDataStatus    `json:"data"`
+	Valid        *bool                    `json:"valid,omitempty"`
+}
+
+// ManifestMessageStatus is a message in the manifest of a batch, with the state and hash of the local copy
+type ManifestMessageStatus struct {
+	MessageManifestEntry
+	State     MessageState `json:"state,omitempty" ffenum:"messagestate"`
+	LocalHash *Bytes32     `json:"localHash,omitempty"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// ManifestDataStatus is a data item in the manifest of a batch, with the hash of the local copy
+type ManifestDataStatus struct {
+	DataRef
+	LocalHash *Bytes32 `json:"localHash,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
 // BatchPayload contains the full JSON of the messages and data, but
 // importantly only the immutable parts of the messages/data.
 // In v0.13 and earlier, we used the whole of this payload object to
@@ -153,3 +193,17 @@ func (b *Batch) Confirmed() (*BatchPersisted, *BatchManifest) {
 		Confirmed:   Now(),
 	}, manifest
 }
+
+// Summary returns the header of the batch, with the counts of the messages and data in its manifest
+func (b *BatchPersisted) Summary() *BatchSummary {
+	summary := &BatchSummary{
+		BatchHeader: b.BatchHeader,
+		TX:          b.TX,
+	}
+	var manifest BatchManifest
+	if b.Manifest != nil && json.Unmarshal(b.Manifest.Bytes(), &manifest) == nil {
+		summary.Messages = len(manifest.Messages)
+		summary.Data = len(manifest.Data)
+	}
+	return summary
+}
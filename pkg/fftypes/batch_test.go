@@ -62,3 +62,41 @@ func TestSQLSerializedManifest(t *testing.T) {
 	assert.NotEqual(t, batch.Payload.Hash().String(), hex.EncodeToString(mfHash[:]))
 
 }
+
+func TestBatchSummary(t *testing.T) {
+
+	batch := Batch{
+		BatchHeader: BatchHeader{
+			ID:        NewUUID(),
+			Type:      BatchTypePrivate,
+			Namespace: "ns1",
+		},
+		Payload: BatchPayload{
+			TX: TransactionRef{
+				Type: TransactionTypeBatchPin,
+				ID:   NewUUID(),
+			},
+			Messages: []*Message{
+				{Header: MessageHeader{ID: NewUUID()}},
+				{Header: MessageHeader{ID: NewUUID()}},
+			},
+			Data: DataArray{
+				{ID: NewUUID()},
+			},
+		},
+	}
+
+	bp, _ := batch.Confirmed()
+	summary := bp.Summary()
+	assert.Equal(t, batch.BatchHeader, summary.BatchHeader)
+	assert.Equal(t, batch.Payload.TX, summary.TX)
+	assert.Equal(t, 2, summary.Messages)
+	assert.Equal(t, 1, summary.Data)
+
+	bp.Manifest = JSONAnyPtr("!json")
+	summary = bp.Summary()
+	assert.Equal(t, batch.BatchHeader.ID, summary.ID)
+	assert.Zero(t, summary.Messages)
+	assert.Zero(t, summary.Data)
+
+}
@@ -0,0 +1,29 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// BatchPinMigration is the status of a move of the BatchPin contract to a new address. Pins are
+// accepted from both contracts during the transition, and this node submits to the new contract
+// once it has been activated.
+type BatchPinMigration struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	ActivationBlock uint64  `json:"activationBlock"`
+	LatestBlock     *uint64 `json:"latestBlock,omitempty"`
+	Active          bool    `json:"active"`
+	Activated       *FFTime `json:"activated,omitempty"`
+}
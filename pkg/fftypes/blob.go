@@ -22,5 +22,6 @@ type Blob struct {
 	PayloadRef string   `json:"payloadRef,omitempty"`
 	Peer       string   `json:"peer,omitempty"`
 	Created    *FFTime  `json:"created,omitempty"`
+	Threat     string   `json:"threat,omitempty"` // Set if the blob was quarantined after the scanner found a threat
 	Sequence   int64    `json:"-"`
 }
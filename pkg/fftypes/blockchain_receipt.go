@@ -0,0 +1,75 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// BlockchainReceipt is the cost and outcome of a blockchain transaction, as reported by the connector
+// when the operation that submitted it is resolved. Fields that the blockchain does not have (such as
+// gas on Fabric) are omitted.
+type BlockchainReceipt struct {
+	BlockNumber       *uint64              `json:"blockNumber,omitempty"`
+	GasUsed           *FFBigInt            `json:"gasUsed,omitempty"`
+	EffectiveGasPrice *FFBigInt            `json:"effectiveGasPrice,omitempty"`
+	Fee               *FFBigInt            `json:"fee,omitempty"`
+	RevertReason      string               `json:"revertReason,omitempty"`
+	ErrorData         *BlockchainErrorData `json:"errorData,omitempty"`
+}
+
+// BlockchainErrorData is the structured form of the data returned by a reverted transaction.
+// The name and params are only set when the error could be decoded, and the raw data is always kept.
+type BlockchainErrorData struct {
+	Name      string     `json:"name,omitempty"`
+	Signature string     `json:"signature,omitempty"`
+	Params    JSONObject `json:"params,omitempty"`
+	Data      string     `json:"data"`
+}
+
+// Scan implements sql.Scanner
+func (r *BlockchainReceipt) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+
+	case string:
+		if src == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(src), &r)
+
+	case []byte:
+		return json.Unmarshal(src, &r)
+
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, r)
+	}
+}
+
+// Value implements sql.Valuer
+func (r *BlockchainReceipt) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	b, _ := json.Marshal(r)
+	return string(b), nil
+}
@@ -0,0 +1,54 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockchainReceiptScanValue(t *testing.T) {
+	blockNumber := uint64(12345)
+	r := &BlockchainReceipt{
+		BlockNumber: &blockNumber,
+		GasUsed:     NewFFBigInt(21000),
+	}
+	v, err := r.Value()
+	assert.NoError(t, err)
+
+	var r1 BlockchainReceipt
+	err = r1.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12345), *r1.BlockNumber)
+	assert.Equal(t, int64(21000), r1.GasUsed.Int().Int64())
+
+	var r2 BlockchainReceipt
+	err = r2.Scan([]byte(v.(string)))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(12345), *r2.BlockNumber)
+
+	var r3 BlockchainReceipt
+	assert.NoError(t, r3.Scan(nil))
+	assert.NoError(t, r3.Scan(""))
+	assert.Regexp(t, "FF10125", r3.Scan(12345))
+
+	var rNil *BlockchainReceipt
+	v, err = rNil.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
@@ -0,0 +1,74 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// BlockchainTransaction is the normalized description of a blockchain transaction, reported by the blockchain
+// plugin when the operation that submitted it is resolved. The same fields are used for every type of blockchain,
+// and anything only one type of blockchain has (such as the nonce on Ethereum, or the channel on Fabric) is held
+// in the extras.
+type BlockchainTransaction struct {
+	ID       string              `json:"id"`
+	Signer   string              `json:"signer,omitempty"`
+	Status   OpStatus            `json:"status"`
+	Finality *BlockchainFinality `json:"finality,omitempty"`
+	Extras   JSONObject          `json:"extras,omitempty"`
+}
+
+// BlockchainFinality is where the transaction was recorded on the chain, and whether the plugin considers
+// it final - that is it cannot be removed by a re-organization of the chain
+type BlockchainFinality struct {
+	BlockNumber *uint64 `json:"blockNumber,omitempty"`
+	BlockHash   string  `json:"blockHash,omitempty"`
+	Final       bool    `json:"final"`
+}
+
+// Scan implements sql.Scanner
+func (tx *BlockchainTransaction) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+
+	case string:
+		if src == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(src), &tx)
+
+	case []byte:
+		return json.Unmarshal(src, &tx)
+
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, tx)
+	}
+}
+
+// Value implements sql.Valuer
+func (tx *BlockchainTransaction) Value() (driver.Value, error) {
+	if tx == nil {
+		return nil, nil
+	}
+	b, _ := json.Marshal(tx)
+	return string(b), nil
+}
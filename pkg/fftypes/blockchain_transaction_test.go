@@ -0,0 +1,62 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockchainTransactionScanValue(t *testing.T) {
+	blockNumber := uint64(12345)
+	tx := &BlockchainTransaction{
+		ID:     "0x12345",
+		Signer: "0xabcde",
+		Status: OpStatusSucceeded,
+		Finality: &BlockchainFinality{
+			BlockNumber: &blockNumber,
+			Final:       true,
+		},
+		Extras: JSONObject{"nonce": "1"},
+	}
+	v, err := tx.Value()
+	assert.NoError(t, err)
+
+	var tx1 BlockchainTransaction
+	err = tx1.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x12345", tx1.ID)
+	assert.Equal(t, uint64(12345), *tx1.Finality.BlockNumber)
+	assert.True(t, tx1.Finality.Final)
+	assert.Equal(t, "1", tx1.Extras.GetString("nonce"))
+
+	var tx2 BlockchainTransaction
+	err = tx2.Scan([]byte(v.(string)))
+	assert.NoError(t, err)
+	assert.Equal(t, OpStatusSucceeded, tx2.Status)
+
+	var tx3 BlockchainTransaction
+	assert.NoError(t, tx3.Scan(nil))
+	assert.NoError(t, tx3.Scan(""))
+	assert.Regexp(t, "FF10125", tx3.Scan(12345))
+
+	var txNil *BlockchainTransaction
+	v, err = txNil.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
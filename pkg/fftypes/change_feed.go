@@ -0,0 +1,32 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// ChangeFeedEntry is a compact record of a change to a resource in a namespace, ordered by the
+// sequence of the event that recorded the change
+type ChangeFeedEntry struct {
+	Sequence   int64     `json:"sequence"`
+	Collection string    `json:"collection"`
+	ID         *UUID     `json:"id"`
+	Event      EventType `json:"event" ffenum:"eventtype"`
+}
+
+// ChangeFeed is a page of changes in a namespace, along with the sequence to request the next page after
+type ChangeFeed struct {
+	Changes []*ChangeFeedEntry `json:"changes"`
+	Next    int64              `json:"next"`
+}
@@ -27,6 +27,10 @@ const (
 	SystemTopicDefinitions = "ff_definition"
 	// SystemBatchPinTopic is the FireFly event topic for events from the FireFly batch pin listener
 	SystemBatchPinTopic = "ff_batch_pin"
+	// SystemTopicPing is the FireFly message topic for the private messages used to measure latency between members
+	SystemTopicPing = "ff_ping"
+	// SystemTopicCanary is the FireFly message topic for the messages sent by the canary, to check confirmation end-to-end
+	SystemTopicCanary = "ff_canary"
 )
 
 const (
@@ -34,6 +38,9 @@ const (
 	// SystemTagDefineDatatype is the tag for messages that broadcast data definitions
 	SystemTagDefineDatatype = "ff_define_datatype"
 
+	// SystemTagDefineDatatypeBundle is the tag for messages that broadcast a bundle of data definitions, that are confirmed together
+	SystemTagDefineDatatypeBundle = "ff_define_datatype_bundle"
+
 	// SystemTagDefineNamespace is the tag for messages that broadcast namespace definitions
 	SystemTagDefineNamespace = "ff_define_namespace"
 
@@ -63,4 +70,22 @@ const (
 
 	// SystemTagIdentityUpdate is the tag for messages that broadcast an identity update
 	SystemTagIdentityUpdate = "ff_identity_update"
+
+	// SystemTagGovernanceProposal is the tag for messages that broadcast a network governance proposal
+	SystemTagGovernanceProposal = "ff_governance_proposal"
+
+	// SystemTagGovernanceVote is the tag for messages that broadcast a vote on a network governance proposal
+	SystemTagGovernanceVote = "ff_governance_vote"
+
+	// SystemTagIdentityEndorsement is the tag for messages that broadcast the endorsement of a new member org by an existing member
+	SystemTagIdentityEndorsement = "ff_identity_endorsement"
+
+	// SystemTagPing is the tag for private messages sent to another member org, to measure the latency between members
+	SystemTagPing = "ff_ping"
+
+	// SystemTagPong is the tag for the private message sent automatically in reply to a ping
+	SystemTagPong = "ff_pong"
+
+	// SystemTagCanary is the tag for the broadcast and private messages sent by the canary
+	SystemTagCanary = "ff_canary"
 )
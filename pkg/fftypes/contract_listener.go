@@ -25,20 +25,35 @@ import (
 )
 
 type ContractListener struct {
-	ID         *UUID                    `json:"id,omitempty"`
-	Interface  *FFIReference            `json:"interface,omitempty"`
-	Namespace  string                   `json:"namespace,omitempty"`
-	Name       string                   `json:"name,omitempty"`
-	ProtocolID string                   `json:"protocolId,omitempty"`
-	Location   *JSONAny                 `json:"location,omitempty"`
-	Created    *FFTime                  `json:"created,omitempty"`
-	Event      *FFISerializedEvent      `json:"event,omitempty"`
-	Topic      string                   `json:"topic,omitempty"`
-	Options    *ContractListenerOptions `json:"options,omitempty"`
+	ID         *UUID                     `json:"id,omitempty"`
+	Interface  *FFIReference             `json:"interface,omitempty"`
+	Namespace  string                    `json:"namespace,omitempty"`
+	Name       string                    `json:"name,omitempty"`
+	ProtocolID string                    `json:"protocolId,omitempty"`
+	Location   *JSONAny                  `json:"location,omitempty"`
+	Created    *FFTime                   `json:"created,omitempty"`
+	Event      *FFISerializedEvent       `json:"event,omitempty"`
+	Topic      string                    `json:"topic,omitempty"`
+	Options    *ContractListenerOptions  `json:"options,omitempty"`
+	Checkpoint string                    `json:"checkpoint,omitempty"`
+	Backfill   *ContractListenerBackfill `json:"backfill,omitempty"`
 }
 
 type ContractListenerOptions struct {
-	FirstEvent string `json:"firstEvent,omitempty"`
+	FirstEvent string  `json:"firstEvent,omitempty"`
+	FromBlock  *uint64 `json:"fromBlock,omitempty"`
+}
+
+// ContractListenerBackfill reports the progress of a listener created with a fromBlock, as the connector
+// delivers the historical events in order through the normal event pipeline
+type ContractListenerBackfill struct {
+	FromBlock    uint64  `json:"fromBlock"`
+	CurrentBlock *uint64 `json:"currentBlock,omitempty"`
+	Events       int64   `json:"events"`
+}
+
+type ContractListenerRewind struct {
+	BlockNumber *uint64 `json:"blockNumber"`
 }
 
 type ContractListenerInput struct {
@@ -35,6 +35,7 @@ type ContractCallRequest struct {
 	Key       string                 `json:"key,omitempty"`
 	Method    *FFIMethod             `json:"method,omitempty"`
 	Input     map[string]interface{} `json:"input"`
+	Errors    FFIErrors              `json:"errors,omitempty"`
 }
 
 type ContractCallResponse struct {
@@ -48,6 +48,7 @@ type Data struct {
 	Datatype  *DatatypeRef  `json:"datatype,omitempty"`
 	Value     *JSONAny      `json:"value"`
 	Blob      *BlobRef      `json:"blob,omitempty"`
+	Labels    FFStringArray `json:"labels,omitempty"` // Mutable labels that are local to this node, and not part of the hash
 
 	ValueSize int64 `json:"-"` // Used internally for message size calcuation, without full payload retrieval
 }
@@ -18,10 +18,17 @@ package fftypes
 
 import (
 	"context"
+	"encoding/json"
+	"sort"
+	"strings"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 )
 
+// DatatypeRefURLPrefix is the prefix of the "$ref" URLs a JSON schema uses to reference another datatype in
+// the same namespace, in the form "ff://datatypes/<name>/<version>"
+const DatatypeRefURLPrefix = "ff://datatypes/"
+
 type ValidatorType = FFEnum
 
 var (
@@ -81,3 +88,73 @@ func (dt *Datatype) Topic() string {
 func (dt *Datatype) SetBroadcastMessage(msgID *UUID) {
 	dt.Message = msgID
 }
+
+// References returns the distinct datatypes referenced by the JSON schema of this datatype
+func (dt *Datatype) References() []*DatatypeRef {
+	var schema interface{}
+	if dt.Value == nil || json.Unmarshal(dt.Value.Bytes(), &schema) != nil {
+		return nil
+	}
+	refs := make(map[string]*DatatypeRef)
+	findDatatypeRefs(schema, refs)
+	results := make([]*DatatypeRef, 0, len(refs))
+	for _, ref := range refs {
+		results = append(results, ref)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].String() < results[j].String() })
+	return results
+}
+
+func findDatatypeRefs(v interface{}, refs map[string]*DatatypeRef) {
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for k, child := range vt {
+			if s, ok := child.(string); ok && k == "$ref" {
+				if ref := ParseDatatypeRefURL(s); ref != nil {
+					refs[ref.String()] = ref
+				}
+			} else {
+				findDatatypeRefs(child, refs)
+			}
+		}
+	case []interface{}:
+		for _, child := range vt {
+			findDatatypeRefs(child, refs)
+		}
+	}
+}
+
+// ParseDatatypeRefURL returns the datatype referenced by a "ff://datatypes/<name>/<version>" URL, ignoring any
+// fragment, or nil if the URL does not reference a datatype
+func ParseDatatypeRefURL(url string) *DatatypeRef {
+	if !strings.HasPrefix(url, DatatypeRefURLPrefix) {
+		return nil
+	}
+	url = strings.SplitN(strings.TrimPrefix(url, DatatypeRefURLPrefix), "#", 2)[0]
+	parts := strings.Split(url, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+	return &DatatypeRef{Name: parts[0], Version: parts[1]}
+}
+
+// DatatypeBundle is a set of datatypes that may reference each other, broadcast together as a single definition.
+// The datatypes are confirmed in the order they appear in the bundle, so a datatype must appear after any others
+// in the bundle that it references.
+type DatatypeBundle struct {
+	Datatypes []*Datatype `json:"datatypes"`
+}
+
+func (db *DatatypeBundle) Topic() string {
+	ns := ""
+	if len(db.Datatypes) > 0 {
+		ns = db.Datatypes[0].Namespace
+	}
+	return typeNamespaceNameTopicHash("datatypebundle", ns, "")
+}
+
+func (db *DatatypeBundle) SetBroadcastMessage(msgID *UUID) {
+	for _, dt := range db.Datatypes {
+		dt.SetBroadcastMessage(msgID)
+	}
+}
@@ -79,3 +79,40 @@ func TestDatatypeValidation(t *testing.T) {
 	def.SetBroadcastMessage(NewUUID())
 	assert.NotNil(t, dt.Message)
 }
+
+func TestDatatypeReferences(t *testing.T) {
+	dt := &Datatype{
+		Value: JSONAnyPtr(`{
+			"properties": {
+				"customer": {"$ref": "ff://datatypes/customer/1.0"},
+				"addresses": {
+					"type": "array",
+					"items": {"allOf": [{"$ref": "ff://datatypes/address/2.0#/$defs/line"}]}
+				},
+				"other": {"$ref": "#/$defs/other"},
+				"again": {"$ref": "ff://datatypes/customer/1.0"},
+				"bad": {"$ref": "ff://datatypes/nothing"}
+			}
+		}`),
+	}
+	refs := dt.References()
+	assert.Len(t, refs, 2)
+	assert.Equal(t, "address/2.0", refs[0].String())
+	assert.Equal(t, "customer/1.0", refs[1].String())
+
+	dt.Value = JSONAnyPtr(`!json`)
+	assert.Empty(t, dt.References())
+}
+
+func TestDatatypeBundle(t *testing.T) {
+	bundle := &DatatypeBundle{}
+	var def Definition = bundle
+	assert.Equal(t, typeNamespaceNameTopicHash("datatypebundle", "", ""), def.Topic())
+
+	bundle.Datatypes = []*Datatype{{Namespace: "ns1"}, {Namespace: "ns1"}}
+	assert.Equal(t, typeNamespaceNameTopicHash("datatypebundle", "ns1", ""), def.Topic())
+	msgID := NewUUID()
+	def.SetBroadcastMessage(msgID)
+	assert.Equal(t, msgID, bundle.Datatypes[0].Message)
+	assert.Equal(t, msgID, bundle.Datatypes[1].Message)
+}
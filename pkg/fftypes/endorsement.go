@@ -0,0 +1,39 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// IdentityEndorsement is the endorsement of a new member org by an existing member, sent as a system broadcast.
+// Until an org has been endorsed by the configured quorum of existing members, its node is quarantined - it
+// can receive broadcasts, but cannot send messages outside of the system namespace.
+type IdentityEndorsement struct {
+	ID       *UUID   `json:"id"`
+	Identity *UUID   `json:"identity"`
+	DID      string  `json:"did"`
+	Author   string  `json:"author,omitempty"`
+	Message  *UUID   `json:"message,omitempty"`
+	Created  *FFTime `json:"created,omitempty"`
+}
+
+func (e *IdentityEndorsement) Topic() string {
+	// Endorsements are on the same topic as the identity, so they are always processed after the claim
+	identity := &IdentityBase{DID: e.DID}
+	return identity.Topic()
+}
+
+func (e *IdentityEndorsement) SetBroadcastMessage(msgID *UUID) {
+	e.Message = msgID
+}
@@ -0,0 +1,36 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityEndorsementTopic(t *testing.T) {
+	org := &IdentityBase{DID: "did:firefly:org/org1"}
+	e := &IdentityEndorsement{
+		ID:  NewUUID(),
+		DID: org.DID,
+	}
+	assert.Equal(t, org.Topic(), e.Topic())
+
+	msgID := NewUUID()
+	e.SetBroadcastMessage(msgID)
+	assert.Equal(t, msgID, e.Message)
+}
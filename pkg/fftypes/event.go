@@ -27,12 +27,32 @@ var (
 	EventTypeMessageConfirmed = ffEnum("eventtype", "message_confirmed")
 	// EventTypeMessageRejected occurs if a message is received and confirmed from a sequencing perspective, but is rejected as invalid (mismatch to schema, or duplicate system broadcast)
 	EventTypeMessageRejected = ffEnum("eventtype", "message_rejected")
+	// EventTypeMessageReorged occurs if the blockchain transaction that pinned a confirmed message is removed by a chain reorganization. The message is confirmed again when the pin is re-delivered on the canonical chain
+	EventTypeMessageReorged = ffEnum("eventtype", "message_reorged")
+	// EventTypeMessageAnchored occurs in a namespace with deferred pinning, when the pin of a message that was already confirmed off-chain is anchored on the blockchain
+	EventTypeMessageAnchored = ffEnum("eventtype", "message_anchored")
+	// EventTypeMessageQuarantined occurs when the data of a message received from the network fails validation, and the message is quarantined until it is reprocessed
+	EventTypeMessageQuarantined = ffEnum("eventtype", "message_quarantined")
+	// EventTypeBlobQuarantined is a security event that occurs when a message is quarantined because the blob scanner found a threat in one of its blobs (the reference is the message)
+	EventTypeBlobQuarantined = ffEnum("eventtype", "blob_quarantined")
+	// EventTypeBatchAssembled occurs when a batch has been assembled from the messages ready to send (the reference is the batch)
+	EventTypeBatchAssembled = ffEnum("eventtype", "batch_assembled")
+	// EventTypeBatchSealed occurs when the manifest and hash of an assembled batch have been finalized, and it has been stored
+	EventTypeBatchSealed = ffEnum("eventtype", "batch_sealed")
+	// EventTypeBatchDispatched occurs when a sealed batch has been published to shared storage, or sent to the members of a private group
+	EventTypeBatchDispatched = ffEnum("eventtype", "batch_dispatched")
+	// EventTypeBatchPinSubmitted occurs when the blockchain transaction to pin a dispatched batch has been submitted
+	EventTypeBatchPinSubmitted = ffEnum("eventtype", "batch_pin_submitted")
+	// EventTypeBatchConfirmed occurs when the pin of a batch has been received from the blockchain
+	EventTypeBatchConfirmed = ffEnum("eventtype", "batch_confirmed")
 	// EventTypeNamespaceConfirmed occurs when a new namespace is ready for use (on the namespace itself)
 	EventTypeNamespaceConfirmed = ffEnum("eventtype", "namespace_confirmed")
 	// EventTypeDatatypeConfirmed occurs when a new datatype is ready for use (on the namespace of the datatype)
 	EventTypeDatatypeConfirmed = ffEnum("eventtype", "datatype_confirmed")
 	// EventTypeIdentityConfirmed occurs when a new identity has been confirmed, as as result of a signed claim broadcast, and any associated claim verification
 	EventTypeIdentityConfirmed = ffEnum("eventtype", "identity_confirmed")
+	// EventTypeIdentityEndorsed occurs when the endorsement of a new member org by an existing member has been confirmed
+	EventTypeIdentityEndorsed = ffEnum("eventtype", "identity_endorsed")
 	// EventTypeIdentityUpdated occurs when an existing identity is update by the owner of that identity
 	EventTypeIdentityUpdated = ffEnum("eventtype", "identity_updated")
 	// EventTypePoolConfirmed occurs when a new token pool is ready for use
@@ -45,12 +65,30 @@ var (
 	EventTypeApprovalConfirmed = ffEnum("eventtype", "token_approval_confirmed")
 	// EventTypeApprovalOpFailed occurs when a token approval submitted by this node has failed (based on feedback from connector)
 	EventTypeApprovalOpFailed = ffEnum("eventtype", "token_approval_op_failed")
+	// EventTypeTokenMetadataUpdated occurs when a token in a non-fungible pool is linked to a new metadata data record
+	EventTypeTokenMetadataUpdated = ffEnum("eventtype", "token_metadata_updated")
 	// EventTypeContractInterfaceConfirmed occurs when a new contract interface has been confirmed
 	EventTypeContractInterfaceConfirmed = ffEnum("eventtype", "contract_interface_confirmed")
 	// EventTypeContractAPIConfirmed occurs when a new contract API has been confirmed
 	EventTypeContractAPIConfirmed = ffEnum("eventtype", "contract_api_confirmed")
 	// EventTypeBlockchainEventReceived occurs when a new event has been received from the blockchain
 	EventTypeBlockchainEventReceived = ffEnum("eventtype", "blockchain_event_received")
+	// EventTypeGovernanceProposalConfirmed occurs when a network governance proposal has been confirmed, and is open for voting
+	EventTypeGovernanceProposalConfirmed = ffEnum("eventtype", "governance_proposal_confirmed")
+	// EventTypeGovernanceVoteConfirmed occurs when a vote by a member organization on a network governance proposal has been confirmed
+	EventTypeGovernanceVoteConfirmed = ffEnum("eventtype", "governance_vote_confirmed")
+	// EventTypeGovernanceProposalApproved occurs when a majority of member organizations have approved a network governance proposal, and the change has been applied
+	EventTypeGovernanceProposalApproved = ffEnum("eventtype", "governance_proposal_approved")
+	// EventTypeGovernanceProposalRejected occurs when enough member organizations have voted against a network governance proposal that it cannot be approved
+	EventTypeGovernanceProposalRejected = ffEnum("eventtype", "governance_proposal_rejected")
+	// EventTypeInvitationRedeemed occurs on the node that issued an invitation, when the organization of the new member that redeemed it has been confirmed
+	EventTypeInvitationRedeemed = ffEnum("eventtype", "invitation_redeemed")
+	// EventTypeCanaryPassed occurs when a message sent by the canary was confirmed within the SLA (the reference is the canary message)
+	EventTypeCanaryPassed = ffEnum("eventtype", "canary_passed")
+	// EventTypeCanaryFailed occurs when a message sent by the canary failed, or was not confirmed within the SLA (the reference is the canary message)
+	EventTypeCanaryFailed = ffEnum("eventtype", "canary_failed")
+	// EventTypeMessageSchemaDrift occurs when a confirmed message has a payload that differs from the schema inferred for its topic (the reference is the message)
+	EventTypeMessageSchemaDrift = ffEnum("eventtype", "message_schema_drift")
 )
 
 // Event is an activity in the system, delivered reliably to applications, that indicates something has happened in the network
@@ -64,6 +102,7 @@ type Event struct {
 	Transaction *UUID     `json:"tx,omitempty"`
 	Topic       string    `json:"topic,omitempty"`
 	Created     *FFTime   `json:"created"`
+	Compacted   int64     `json:"compacted,omitempty"`
 }
 
 // EnrichedEvent adds the referred object to an event
@@ -72,19 +111,40 @@ type EnrichedEvent struct {
 	Message         *Message         `json:"message,omitempty"`
 	Transaction     *Transaction     `json:"transaction,omitempty"`
 	BlockchainEvent *BlockchainEvent `json:"blockchainevent,omitempty"`
+	TokenPool       *TokenPool       `json:"tokenPool,omitempty"`
+	TokenTransfer   *TokenTransfer   `json:"tokenTransfer,omitempty"`
+	TokenApproval   *TokenApproval   `json:"tokenApproval,omitempty"`
+	TokenMetadata   *TokenMetadata   `json:"tokenMetadata,omitempty"`
+	Counterparties  *Counterparties  `json:"counterparties,omitempty"`
+	Batch           *BatchSummary    `json:"batch,omitempty"`
+}
+
+// Counterparties are the org identities that own the keys on each side of a token transfer.
+// Either side is omitted for a mint/burn, or when the key is not registered to an org.
+type Counterparties struct {
+	From *Identity `json:"from,omitempty"`
+	To   *Identity `json:"to,omitempty"`
 }
 
 // EventDelivery adds the referred object to an event, as well as details of the subscription that caused the event to
-// be dispatched to an application.
+// be dispatched to an application. Transports that can resume an ephemeral subscription include a resume token,
+// which the application can supply when it reconnects to continue after this event.
+// Fields computed by any enrichment plugins are included under the name of each plugin.
+// For subscriptions with the withData option, the full data of a message is included inline.
 type EventDelivery struct {
 	EnrichedEvent
 	Subscription SubscriptionRef `json:"subscription"`
+	ResumeToken  string          `json:"resumeToken,omitempty"`
+	Enrichments  JSONObject      `json:"enrichments,omitempty"`
+	Data         DataArray       `json:"data,omitempty"`
 }
 
 // EventDeliveryResponse is the payload an application sends back, to confirm it has accepted (or rejected) the event and as such
-// does not need to receive it again.
+// does not need to receive it again. Setting UpToSequence, in place of an ID, responds to every event in flight on the subscription
+// with a sequence up to and including that one.
 type EventDeliveryResponse struct {
 	ID           *UUID           `json:"id"`
+	UpToSequence int64           `json:"upToSequence,omitempty"`
 	Rejected     bool            `json:"rejected,omitempty"`
 	Info         string          `json:"info,omitempty"`
 	Subscription SubscriptionRef `json:"subscription"`
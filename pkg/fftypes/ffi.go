@@ -46,6 +46,7 @@ type FFI struct {
 	Version     string       `json:"version"`
 	Methods     []*FFIMethod `json:"methods,omitempty"`
 	Events      []*FFIEvent  `json:"events,omitempty"`
+	Errors      FFIErrors    `json:"errors,omitempty"`
 }
 
 type FFIMethod struct {
@@ -73,6 +74,16 @@ type FFIEvent struct {
 	FFIEventDefinition
 }
 
+// FFIError is a custom error that can be returned by the contract when a transaction is reverted,
+// which allows failures to be decoded into a readable form
+type FFIError struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Params      FFIParams `json:"params"`
+}
+
+type FFIErrors []*FFIError
+
 type FFIParam struct {
 	Name   string   `json:"name"`
 	Schema *JSONAny `json:"schema,omitempty"`
@@ -128,3 +139,25 @@ func (m FFIParams) Value() (driver.Value, error) {
 	bytes, _ := json.Marshal(m)
 	return bytes, nil
 }
+
+// Scan implements sql.Scanner
+func (m *FFIErrors) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), &m)
+	case []byte:
+		return json.Unmarshal(src, &m)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, m)
+	}
+}
+
+func (m FFIErrors) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	bytes, _ := json.Marshal(m)
+	return bytes, nil
+}
@@ -147,3 +147,42 @@ func TestFFISetBroadCastMessage(t *testing.T) {
 	ffi.SetBroadcastMessage(msgID)
 	assert.Equal(t, ffi.Message, msgID)
 }
+
+func TestFFIErrorsScan(t *testing.T) {
+	errs := FFIErrors{}
+	err := errs.Scan([]byte(`[{"name": "InsufficientBalance", "params": [{"name": "available"}]}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, "InsufficientBalance", errs[0].Name)
+	assert.Equal(t, "available", errs[0].Params[0].Name)
+
+	errs = FFIErrors{}
+	err = errs.Scan(`[{"name": "Unauthorized", "params": []}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "Unauthorized", errs[0].Name)
+}
+
+func TestFFIErrorsScanNil(t *testing.T) {
+	var errs FFIErrors
+	err := errs.Scan(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, errs)
+}
+
+func TestFFIErrorsScanError(t *testing.T) {
+	errs := FFIErrors{}
+	err := errs.Scan(12345)
+	assert.Regexp(t, "FF10125", err)
+}
+
+func TestFFIErrorsValue(t *testing.T) {
+	errs := FFIErrors{
+		{Name: "Unauthorized", Params: FFIParams{}},
+	}
+	val, err := errs.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte(`[{"name":"Unauthorized","params":[]}]`), val)
+
+	val, err = FFIErrors(nil).Value()
+	assert.NoError(t, err)
+	assert.Nil(t, val)
+}
@@ -0,0 +1,146 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// GovernanceChangeType is the type of network-wide configuration change that is proposed
+type GovernanceChangeType = FFEnum
+
+var (
+	// GovernanceChangeMemberApproval approves a new member organization joining the network
+	GovernanceChangeMemberApproval = ffEnum("governancechangetype", "member_approval")
+	// GovernanceChangeContractMigration moves the network to a new BatchPin contract
+	GovernanceChangeContractMigration = ffEnum("governancechangetype", "contract_migration")
+	// GovernanceChangeProtocolVersion moves the network to a new version of the FireFly protocol
+	GovernanceChangeProtocolVersion = ffEnum("governancechangetype", "protocol_version")
+)
+
+// GovernanceProposalState is the state of voting on a governance proposal
+type GovernanceProposalState = FFEnum
+
+var (
+	// GovernanceProposalStatePending the proposal is open for voting
+	GovernanceProposalStatePending = ffEnum("governanceproposalstate", "pending")
+	// GovernanceProposalStateApproved a majority of the member organizations voted to approve the proposal
+	GovernanceProposalStateApproved = ffEnum("governanceproposalstate", "approved")
+	// GovernanceProposalStateRejected enough member organizations voted against the proposal that it cannot be approved
+	GovernanceProposalStateRejected = ffEnum("governanceproposalstate", "rejected")
+)
+
+// GovernanceProposal is a network-wide configuration change, proposed by a member organization via a
+// system broadcast, which every node applies automatically once it has been approved
+type GovernanceProposal struct {
+	ID          *UUID                   `json:"id"`
+	Type        GovernanceChangeType    `json:"type" ffenum:"governancechangetype"`
+	Description string                  `json:"description,omitempty"`
+	Change      *JSONAny                `json:"change,omitempty"`
+	Author      string                  `json:"author,omitempty"`
+	Message     *UUID                   `json:"message,omitempty"`
+	State       GovernanceProposalState `json:"state,omitempty" ffenum:"governanceproposalstate"`
+	Approvals   int                     `json:"approvals"`
+	Rejections  int                     `json:"rejections"`
+	Created     *FFTime                 `json:"created,omitempty"`
+	Decided     *FFTime                 `json:"decided,omitempty"`
+}
+
+// GovernanceVote is the vote of a member organization on a governance proposal, sent as a system broadcast
+type GovernanceVote struct {
+	ID       *UUID   `json:"id"`
+	Proposal *UUID   `json:"proposal"`
+	Approve  bool    `json:"approve"`
+	Comment  string  `json:"comment,omitempty"`
+	Author   string  `json:"author,omitempty"`
+	Message  *UUID   `json:"message,omitempty"`
+	Created  *FFTime `json:"created,omitempty"`
+}
+
+// GovernanceVoteInput is the input to vote on a governance proposal
+type GovernanceVoteInput struct {
+	Approve bool   `json:"approve"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// GovernanceMemberApproval is the change for a member_approval proposal
+type GovernanceMemberApproval struct {
+	Identity string `json:"identity"`
+}
+
+// GovernanceContractMigration is the change for a contract_migration proposal
+type GovernanceContractMigration struct {
+	Contract string `json:"contract"`
+}
+
+// GovernanceProtocolVersion is the change for a protocol_version proposal
+type GovernanceProtocolVersion struct {
+	Version int `json:"version"`
+}
+
+func governanceTopic(proposal *UUID) string {
+	// Votes are on the same topic as the proposal, so they are always processed after it
+	return typeNamespaceNameTopicHash("governance", SystemNamespace, proposal.String())
+}
+
+func (p *GovernanceProposal) Validate(ctx context.Context, existing bool) (err error) {
+	if p.Type == "" {
+		return i18n.NewError(ctx, i18n.MsgGovernanceTypeRequired)
+	}
+	if err = ValidateLength(ctx, p.Description, "description", 4096); err != nil {
+		return err
+	}
+	if existing {
+		if p.ID == nil {
+			return i18n.NewError(ctx, i18n.MsgNilID)
+		}
+	}
+	return nil
+}
+
+func (p *GovernanceProposal) Topic() string {
+	return governanceTopic(p.ID)
+}
+
+func (p *GovernanceProposal) SetBroadcastMessage(msgID *UUID) {
+	p.Message = msgID
+}
+
+func (v *GovernanceVote) Validate(ctx context.Context, existing bool) (err error) {
+	if v.Proposal == nil {
+		return i18n.NewError(ctx, i18n.MsgGovernanceProposalRequired)
+	}
+	if err = ValidateLength(ctx, v.Comment, "comment", 1024); err != nil {
+		return err
+	}
+	if existing {
+		if v.ID == nil {
+			return i18n.NewError(ctx, i18n.MsgNilID)
+		}
+	}
+	return nil
+}
+
+func (v *GovernanceVote) Topic() string {
+	return governanceTopic(v.Proposal)
+}
+
+func (v *GovernanceVote) SetBroadcastMessage(msgID *UUID) {
+	v.Message = msgID
+}
@@ -0,0 +1,72 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGovernanceProposalValidation(t *testing.T) {
+	p := &GovernanceProposal{}
+	assert.Regexp(t, "FF10438", p.Validate(context.Background(), false))
+
+	p.Type = GovernanceChangeProtocolVersion
+	p.Description = strings.Repeat("a", 4097)
+	assert.Regexp(t, "FF10188.*description", p.Validate(context.Background(), false))
+
+	p.Description = "upgrade"
+	assert.NoError(t, p.Validate(context.Background(), false))
+	assert.Regexp(t, "FF10203", p.Validate(context.Background(), true))
+
+	p.ID = NewUUID()
+	assert.NoError(t, p.Validate(context.Background(), true))
+
+	msgID := NewUUID()
+	p.SetBroadcastMessage(msgID)
+	assert.Equal(t, msgID, p.Message)
+}
+
+func TestGovernanceVoteValidation(t *testing.T) {
+	v := &GovernanceVote{}
+	assert.Regexp(t, "FF10439", v.Validate(context.Background(), false))
+
+	v.Proposal = NewUUID()
+	v.Comment = strings.Repeat("a", 1025)
+	assert.Regexp(t, "FF10188.*comment", v.Validate(context.Background(), false))
+
+	v.Comment = "agreed"
+	assert.NoError(t, v.Validate(context.Background(), false))
+	assert.Regexp(t, "FF10203", v.Validate(context.Background(), true))
+
+	v.ID = NewUUID()
+	assert.NoError(t, v.Validate(context.Background(), true))
+
+	msgID := NewUUID()
+	v.SetBroadcastMessage(msgID)
+	assert.Equal(t, msgID, v.Message)
+}
+
+func TestGovernanceVoteSameTopicAsProposal(t *testing.T) {
+	p := &GovernanceProposal{ID: NewUUID()}
+	v := &GovernanceVote{Proposal: p.ID}
+	assert.Equal(t, p.Topic(), v.Topic())
+	assert.NotEqual(t, p.Topic(), (&GovernanceProposal{ID: NewUUID()}).Topic())
+}
@@ -40,6 +40,12 @@ type Group struct {
 	Created *FFTime  `json:"created,omitempty"`
 }
 
+// GroupPreview is the group that a private send to a list of recipients would use
+type GroupPreview struct {
+	Group  *Group `json:"group"`
+	Exists bool   `json:"exists"`
+}
+
 type Members []*Member
 
 func (m Members) Len() int           { return len(m) }
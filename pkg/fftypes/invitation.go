@@ -0,0 +1,175 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// InvitationState is the state of an invitation for a new member to join the network
+type InvitationState = FFEnum
+
+var (
+	// InvitationStatePending the invitation has been issued, and not yet redeemed
+	InvitationStatePending = ffEnum("invitationstate", "pending")
+	// InvitationStateRedeemed the organization of the new member that redeemed the invitation has been confirmed
+	InvitationStateRedeemed = ffEnum("invitationstate", "redeemed")
+)
+
+// IdentityProfileInvitation is the field in the profile of an org identity, that references the invitation it was registered from
+const IdentityProfileInvitation = "invitation"
+
+// Invitation is issued by an existing member, to onboard a new member organization and node onto the network.
+// Invitations are only stored on the node that issued them. The code is passed out-of-band to the operator
+// of the new node, and contains the bootstrap information that node needs to register and connect.
+type Invitation struct {
+	ID          *UUID           `json:"id"`
+	Description string          `json:"description,omitempty"`
+	Inviter     string          `json:"inviter"`
+	Node        *UUID           `json:"node,omitempty"`
+	Peer        JSONObject      `json:"peer,omitempty"`
+	State       InvitationState `json:"state" ffenum:"invitationstate"`
+	RedeemedBy  string          `json:"redeemedBy,omitempty"`
+	Created     *FFTime         `json:"created,omitempty"`
+	Expires     *FFTime         `json:"expires,omitempty"`
+	Redeemed    *FFTime         `json:"redeemed,omitempty"`
+	Code        string          `json:"code,omitempty"`
+	Secret      *Bytes32        `json:"-"`
+}
+
+// InvitationInput is the input to issue a new invitation
+type InvitationInput struct {
+	Description string      `json:"description,omitempty"`
+	Expiry      *FFDuration `json:"expiry,omitempty"`
+}
+
+// InvitationCode is the signed bootstrap information encoded into the code of an invitation
+type InvitationCode struct {
+	ID        *UUID      `json:"id"`
+	Inviter   string     `json:"inviter"`
+	Node      *UUID      `json:"node,omitempty"`
+	Peer      JSONObject `json:"peer"`
+	Expires   *FFTime    `json:"expires"`
+	Signature *Bytes32   `json:"signature,omitempty"`
+}
+
+// InvitationRedeemInput is the input to redeem an invitation on a new node
+type InvitationRedeemInput struct {
+	Code string `json:"code"`
+}
+
+// InvitationRedemption is the result of redeeming an invitation on a new node
+type InvitationRedemption struct {
+	Invitation   *InvitationCode `json:"invitation"`
+	Organization *Identity       `json:"organization,omitempty"`
+	Node         *Identity       `json:"node,omitempty"`
+}
+
+// InvitationReference is added to the profile of the org registered by a new member, so that the node
+// that issued the invitation can mark it redeemed once the org is confirmed
+type InvitationReference struct {
+	ID        *UUID    `json:"id"`
+	Signature *Bytes32 `json:"signature"`
+}
+
+// CodeInfo returns the bootstrap information shared with the new member, signed with the secret of the invitation
+func (inv *Invitation) CodeInfo() *InvitationCode {
+	ic := &InvitationCode{
+		ID:      inv.ID,
+		Inviter: inv.Inviter,
+		Node:    inv.Node,
+		Peer:    inv.Peer,
+		Expires: inv.Expires,
+	}
+	ic.Signature = ic.sign(inv.Secret)
+	return ic
+}
+
+// VerifySignature checks a signature presented by a new member was generated from this invitation
+func (inv *Invitation) VerifySignature(signature *Bytes32) bool {
+	if signature == nil {
+		return false
+	}
+	return hmac.Equal(inv.CodeInfo().Signature[:], signature[:])
+}
+
+func (ic *InvitationCode) sign(secret *Bytes32) *Bytes32 {
+	var key []byte
+	if secret != nil {
+		key = secret[:]
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(ic.ID.String()))
+	h.Write([]byte{0x00})
+	h.Write([]byte(ic.Inviter))
+	h.Write([]byte{0x00})
+	h.Write([]byte(ic.Node.String()))
+	h.Write([]byte{0x00})
+	h.Write([]byte(ic.Peer.String()))
+	h.Write([]byte{0x00})
+	h.Write([]byte(ic.Expires.String()))
+	return HashResult(h)
+}
+
+// Encode returns the opaque code, that is given to the operator of the new node
+func (ic *InvitationCode) Encode() string {
+	b, _ := json.Marshal(ic)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Reference returns the reference to the invitation, that is added to the profile of the new member org
+func (ic *InvitationCode) Reference() *InvitationReference {
+	return &InvitationReference{
+		ID:        ic.ID,
+		Signature: ic.Signature,
+	}
+}
+
+// ParseInvitationCode decodes the code of an invitation issued by an existing member
+func ParseInvitationCode(ctx context.Context, code string) (*InvitationCode, error) {
+	b, err := base64.RawURLEncoding.DecodeString(code)
+	if err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInvitationCodeInvalid, err)
+	}
+	var ic InvitationCode
+	if err = json.Unmarshal(b, &ic); err != nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInvitationCodeInvalid, err)
+	}
+	if ic.ID == nil || ic.Inviter == "" || ic.Signature == nil || ic.Expires == nil {
+		return nil, i18n.NewError(ctx, i18n.MsgInvitationCodeInvalid, "missing fields")
+	}
+	return &ic, nil
+}
+
+// ParseInvitationReference extracts the invitation reference from the profile of an org identity, if there is one
+func ParseInvitationReference(profile JSONObject) *InvitationReference {
+	if profile == nil || profile[IdentityProfileInvitation] == nil {
+		return nil
+	}
+	var ref InvitationReference
+	b, _ := json.Marshal(profile[IdentityProfileInvitation])
+	if err := json.Unmarshal(b, &ref); err != nil || ref.ID == nil || ref.Signature == nil {
+		return nil
+	}
+	return &ref
+}
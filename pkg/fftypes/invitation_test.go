@@ -0,0 +1,102 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestInvitation() *Invitation {
+	return &Invitation{
+		ID:      NewUUID(),
+		Inviter: "did:firefly:org/org1",
+		Node:    NewUUID(),
+		Peer:    JSONObject{"id": "peer1", "endpoint": "https://peer1.example.com"},
+		Expires: Now(),
+		Secret:  NewRandB32(),
+	}
+}
+
+func TestInvitationCodeRoundTrip(t *testing.T) {
+	inv := newTestInvitation()
+	ic := inv.CodeInfo()
+	assert.NotNil(t, ic.Signature)
+
+	parsed, err := ParseInvitationCode(context.Background(), ic.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, inv.ID, parsed.ID)
+	assert.Equal(t, inv.Inviter, parsed.Inviter)
+	assert.Equal(t, inv.Node, parsed.Node)
+	assert.Equal(t, "peer1", parsed.Peer.GetString("id"))
+	assert.True(t, inv.VerifySignature(parsed.Signature))
+
+	ref := parsed.Reference()
+	assert.Equal(t, inv.ID, ref.ID)
+	assert.Equal(t, ic.Signature, ref.Signature)
+}
+
+func TestInvitationVerifySignatureMismatch(t *testing.T) {
+	inv := newTestInvitation()
+	ic := inv.CodeInfo()
+
+	assert.False(t, inv.VerifySignature(nil))
+	assert.False(t, inv.VerifySignature(NewRandB32()))
+
+	other := newTestInvitation()
+	other.ID = inv.ID
+	assert.False(t, other.VerifySignature(ic.Signature))
+
+	inv.Secret = nil
+	assert.False(t, inv.VerifySignature(ic.Signature))
+}
+
+func TestParseInvitationCodeBadBase64(t *testing.T) {
+	_, err := ParseInvitationCode(context.Background(), "!!!")
+	assert.Regexp(t, "FF10443", err)
+}
+
+func TestParseInvitationCodeBadJSON(t *testing.T) {
+	_, err := ParseInvitationCode(context.Background(), base64.RawURLEncoding.EncodeToString([]byte("!json")))
+	assert.Regexp(t, "FF10443", err)
+}
+
+func TestParseInvitationCodeMissingFields(t *testing.T) {
+	_, err := ParseInvitationCode(context.Background(), base64.RawURLEncoding.EncodeToString([]byte("{}")))
+	assert.Regexp(t, "FF10443.*missing", err)
+}
+
+func TestParseInvitationReference(t *testing.T) {
+	id := NewUUID()
+	sig := NewRandB32()
+	ref := ParseInvitationReference(JSONObject{
+		IdentityProfileInvitation: map[string]interface{}{
+			"id":        id.String(),
+			"signature": sig.String(),
+		},
+	})
+	assert.Equal(t, id, ref.ID)
+	assert.Equal(t, sig, ref.Signature)
+
+	assert.Nil(t, ParseInvitationReference(nil))
+	assert.Nil(t, ParseInvitationReference(JSONObject{}))
+	assert.Nil(t, ParseInvitationReference(JSONObject{IdentityProfileInvitation: "wrong"}))
+	assert.Nil(t, ParseInvitationReference(JSONObject{IdentityProfileInvitation: map[string]interface{}{"id": id.String()}}))
+}
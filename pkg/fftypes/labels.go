@@ -0,0 +1,49 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"strings"
+)
+
+// LabelsUpdate adds and removes the labels of a message or data item. Labels are mutable and local
+// to this node, so they can be used to track the progress of local workflows against the record.
+type LabelsUpdate struct {
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+}
+
+// Apply returns the result of applying the update to an existing set of labels, as a sorted set of
+// lower case labels. Removals are applied after additions.
+func (lu *LabelsUpdate) Apply(ctx context.Context, existing FFStringArray) (FFStringArray, error) {
+	added, _ := append(FFStringArray{}, existing...).AddToSortedSet(lu.Add...)
+	labels := FFStringArray{}
+	for _, label := range added {
+		removed := false
+		for _, r := range lu.Remove {
+			removed = removed || strings.EqualFold(label, r)
+		}
+		if !removed {
+			labels = append(labels, label)
+		}
+	}
+	if err := labels.Validate(ctx, "labels", true, FFStringNameItemsMax); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
@@ -0,0 +1,51 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelsUpdateApply(t *testing.T) {
+	lu := &LabelsUpdate{
+		Add:    []string{"Reviewed", "disputed", "reviewed"},
+		Remove: []string{"Pending"},
+	}
+	labels, err := lu.Apply(context.Background(), FFStringArray{"pending", "archived"})
+	assert.NoError(t, err)
+	assert.Equal(t, FFStringArray{"archived", "disputed", "reviewed"}, labels)
+}
+
+func TestLabelsUpdateApplyRemoveAll(t *testing.T) {
+	lu := &LabelsUpdate{
+		Remove: []string{"pending"},
+	}
+	labels, err := lu.Apply(context.Background(), FFStringArray{"pending"})
+	assert.NoError(t, err)
+	assert.Equal(t, FFStringArray{}, labels)
+}
+
+func TestLabelsUpdateApplyInvalid(t *testing.T) {
+	lu := &LabelsUpdate{
+		Add: []string{"not,valid"},
+	}
+	_, err := lu.Apply(context.Background(), nil)
+	assert.Regexp(t, "FF10131.*labels\\[0\\]", err)
+}
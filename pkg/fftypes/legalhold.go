@@ -0,0 +1,57 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// LegalHoldType is the type of the resource placed under a legal hold
+type LegalHoldType = FFEnum
+
+var (
+	// LegalHoldTypeMessage is a hold on a message
+	LegalHoldTypeMessage = ffEnum("legalholdtype", "message")
+	// LegalHoldTypeData is a hold on a data item
+	LegalHoldTypeData = ffEnum("legalholdtype", "data")
+)
+
+// LegalHoldInput is the input to place a message or data item under a legal hold
+type LegalHoldInput struct {
+	Type   LegalHoldType `json:"type" ffenum:"legalholdtype"`
+	Ref    *UUID         `json:"ref"`
+	Reason string        `json:"reason"`
+}
+
+// LegalHoldRelease is the input to release a legal hold
+type LegalHoldRelease struct {
+	Reason string `json:"reason"`
+}
+
+// LegalHold marks a message or data item as non-deletable until the hold is released.
+// Holds are never deleted, so that they provide an audit trail of when and why they were placed and released.
+type LegalHold struct {
+	ID            *UUID         `json:"id"`
+	Namespace     string        `json:"namespace"`
+	Type          LegalHoldType `json:"type" ffenum:"legalholdtype"`
+	Ref           *UUID         `json:"ref"`
+	Reason        string        `json:"reason"`
+	Created       *FFTime       `json:"created"`
+	Released      *FFTime       `json:"released,omitempty"`
+	ReleaseReason string        `json:"releaseReason,omitempty"`
+}
+
+// IsActive returns true if the hold has not been released
+func (h *LegalHold) IsActive() bool {
+	return h.Released == nil
+}
@@ -45,6 +45,8 @@ var (
 	MessageTypeTransferBroadcast = ffEnum("messagetype", "transfer_broadcast")
 	// MessageTypeTransferPrivate is a private message to accompany/annotate a token transfer
 	MessageTypeTransferPrivate = ffEnum("messagetype", "transfer_private")
+	// MessageTypeLocal is a message that is stored and confirmed on the local node only, without a blockchain pin or sending it to any other party
+	MessageTypeLocal = ffEnum("messagetype", "local")
 )
 
 // MessageState is the current transmission/confirmation state of a message
@@ -63,6 +65,14 @@ var (
 	MessageStateConfirmed = ffEnum("messagestate", "confirmed")
 	// MessageStateRejected is a message that has completed confirmation, but has been rejected by FireFly
 	MessageStateRejected = ffEnum("messagestate", "rejected")
+	// MessageStateUnconfirmed is a message that was confirmed, but the blockchain transaction that pinned it was removed by a chain reorganization
+	MessageStateUnconfirmed = ffEnum("messagestate", "unconfirmed")
+	// MessageStateAnchored is a message in a namespace with deferred pinning, that was confirmed off-chain and has since had its pin anchored on the blockchain
+	MessageStateAnchored = ffEnum("messagestate", "anchored")
+	// MessageStateQuarantined is a message received from the network whose data failed validation, which is held in quarantine until it is reprocessed
+	MessageStateQuarantined = ffEnum("messagestate", "quarantined")
+	// MessageStateAwaitingApproval is a message created locally that is tagged with an approval policy, and is held until it has been approved
+	MessageStateAwaitingApproval = ffEnum("messagestate", "awaiting_approval")
 )
 
 // MessageHeader contains all fields that contribute to the hash
@@ -73,12 +83,28 @@ type MessageHeader struct {
 	Type   MessageType     `json:"type" ffenum:"messagetype"`
 	TxType TransactionType `json:"txtype,omitempty"`
 	SignerRef
-	Created   *FFTime       `json:"created,omitempty"`
-	Namespace string        `json:"namespace,omitempty"`
-	Group     *Bytes32      `json:"group,omitempty"`
-	Topics    FFStringArray `json:"topics,omitempty"`
-	Tag       string        `json:"tag,omitempty"`
-	DataHash  *Bytes32      `json:"datahash,omitempty"`
+	Created     *FFTime            `json:"created,omitempty"`
+	Namespace   string             `json:"namespace,omitempty"`
+	Group       *Bytes32           `json:"group,omitempty"`
+	Topics      FFStringArray      `json:"topics,omitempty"`
+	OrderingKey string             `json:"orderingkey,omitempty"`
+	Tag         string             `json:"tag,omitempty"`
+	Priority    int64              `json:"priority,omitempty"`
+	Attachments AttachmentManifest `json:"attachments,omitempty"`
+	DataHash    *Bytes32           `json:"datahash,omitempty"`
+}
+
+// OrderingKeySeparator joins a topic and an ordering key to form the pin context. Topics cannot contain it.
+const OrderingKeySeparator = "/"
+
+// PinContext returns the string hashed to form the ordering context of the message on a topic. Messages with
+// an ordering key are sequenced per combination of topic and key, rather than per topic, so that high cardinality
+// ordering domains (such as individual accounts) do not each require their own topic.
+func (h *MessageHeader) PinContext(topic string) string {
+	if h.OrderingKey == "" {
+		return topic
+	}
+	return topic + OrderingKeySeparator + h.OrderingKey
 }
 
 // Message is the envelope by which coordinated data exchange can happen between parties in the network
@@ -92,7 +118,8 @@ type Message struct {
 	Confirmed *FFTime       `json:"confirmed,omitempty"`
 	Data      DataRefs      `json:"data"`
 	Pins      FFStringArray `json:"pins,omitempty"`
-	Sequence  int64         `json:"-"` // Local database sequence used internally for batch assembly
+	Labels    FFStringArray `json:"labels,omitempty"` // Mutable labels that are local to this node, and not part of the hash
+	Sequence  int64         `json:"-"`                // Local database sequence used internally for batch assembly
 }
 
 // BatchMessage is the fields in a message record that are assured to be consistent on all parties.
@@ -113,6 +140,15 @@ type MessageInOut struct {
 	Message
 	InlineData InlineData  `json:"data"`
 	Group      *InputGroup `json:"group,omitempty"`
+	Template   string      `json:"template,omitempty"`
+	Anonymous  bool        `json:"anonymous,omitempty"`
+}
+
+// MessageWithIncludes is a message with related records embedded, as requested by the caller
+type MessageWithIncludes struct {
+	MessageInOut
+	Transaction *Transaction `json:"transaction,omitempty"`
+	Events      []*Event     `json:"events,omitempty"`
 }
 
 // InputGroup declares a group in-line for auotmatic resolution, without having to define a group up-front
@@ -163,6 +199,16 @@ func (m *MessageInOut) SetInlineData(data []*Data) {
 	}
 }
 
+// SetDataRefs sets the data array to the references of the message, without resolving the values
+func (m *MessageInOut) SetDataRefs(refs DataRefs) {
+	m.InlineData = make(InlineData, len(refs))
+	for i, ref := range refs {
+		m.InlineData[i] = &DataRefOrValue{
+			DataRef: *ref,
+		}
+	}
+}
+
 const messageSizeEstimateBase = int64(1024)
 
 func (m *Message) EstimateSize(includeDataRefs bool) int64 {
@@ -228,11 +274,17 @@ func (m *Message) VerifyFields(ctx context.Context) error {
 	if err := m.Header.Topics.Validate(ctx, "header.topics", true, 10 /* Pins need 96 chars each*/); err != nil {
 		return err
 	}
+	if err := ValidateLength(ctx, m.Header.OrderingKey, "header.orderingkey", 256); err != nil {
+		return err
+	}
 	if m.Header.Tag != "" {
 		if err := ValidateFFNameField(ctx, m.Header.Tag, "header.tag"); err != nil {
 			return err
 		}
 	}
+	if err := m.Header.Attachments.Validate(ctx); err != nil {
+		return err
+	}
 	return m.DupDataCheck(ctx)
 }
 
@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -65,6 +66,33 @@ func TestSealBadTagString(t *testing.T) {
 	assert.Regexp(t, `FF10131.*header.tag`, err)
 }
 
+func TestSealOrderingKeyTooLong(t *testing.T) {
+	msg := Message{
+		Header: MessageHeader{
+			OrderingKey: strings.Repeat("a", 257),
+		},
+	}
+	err := msg.Seal(context.Background())
+	assert.Regexp(t, `FF10188.*header.orderingkey`, err)
+}
+
+func TestSealBadAttachments(t *testing.T) {
+	msg := Message{
+		Header: MessageHeader{
+			Attachments: AttachmentManifest{{Filename: "invoice.pdf"}},
+		},
+	}
+	err := msg.Seal(context.Background())
+	assert.Regexp(t, `FF10140.*header.attachments\[0\].hash`, err)
+}
+
+func TestPinContext(t *testing.T) {
+	h := MessageHeader{}
+	assert.Equal(t, "topic1", h.PinContext("topic1"))
+	h.OrderingKey = "account1"
+	assert.Equal(t, "topic1/account1", h.PinContext("topic1"))
+}
+
 func TestVerifyTXType(t *testing.T) {
 	msg := Message{
 		Header: MessageHeader{
@@ -259,6 +287,15 @@ func TestSetInlineData(t *testing.T) {
 	assert.Regexp(t, "some data", string(b))
 }
 
+func TestSetDataRefs(t *testing.T) {
+	msg := &MessageInOut{}
+	ref := &DataRef{ID: NewUUID(), Hash: NewRandB32()}
+	msg.SetDataRefs(DataRefs{ref})
+	b, err := json.Marshal(&msg)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"data":[{"id":"`+ref.ID.String()+`","hash":"`+ref.Hash.String()+`"}]`)
+}
+
 func TestMessageImmutable(t *testing.T) {
 	msg := &Message{
 		Header: MessageHeader{
@@ -0,0 +1,73 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// MessageValidationCheck is one of the checks performed when validating a message before it is sent
+type MessageValidationCheck = FFEnum
+
+var (
+	// MessageValidationCheckEndorsement verifies the org that owns this node has been endorsed by the network
+	MessageValidationCheckEndorsement = ffEnum("msgvalidationcheck", "endorsement")
+	// MessageValidationCheckTemplate applies the message template, if one is specified
+	MessageValidationCheckTemplate = ffEnum("msgvalidationcheck", "template")
+	// MessageValidationCheckIdentity resolves the signing identity of the message
+	MessageValidationCheckIdentity = ffEnum("msgvalidationcheck", "identity")
+	// MessageValidationCheckGroup resolves the recipients of a private message into a group
+	MessageValidationCheckGroup = ffEnum("msgvalidationcheck", "group")
+	// MessageValidationCheckData resolves data references, and validates inline data against its datatype
+	MessageValidationCheckData = ffEnum("msgvalidationcheck", "data")
+	// MessageValidationCheckResidency verifies the data residency rules of the namespace permit the message to be sent
+	MessageValidationCheckResidency = ffEnum("msgvalidationcheck", "residency")
+	// MessageValidationCheckSize verifies the message fits within the maximum batch payload size
+	MessageValidationCheckSize = ffEnum("msgvalidationcheck", "size")
+)
+
+// MessageValidationResult is the outcome of a single check
+type MessageValidationResult struct {
+	Check MessageValidationCheck `json:"check" ffenum:"msgvalidationcheck"`
+	Valid bool                   `json:"valid"`
+	Error string                 `json:"error,omitempty"`
+}
+
+// MessageValidationReport is the result of validating a message without persisting or sending it
+type MessageValidationReport struct {
+	Valid   bool                       `json:"valid"`
+	Type    MessageType                `json:"type" ffenum:"messagetype"`
+	Size    int64                      `json:"size,omitempty"`
+	MaxSize int64                      `json:"maxSize,omitempty"`
+	Checks  []*MessageValidationResult `json:"checks"`
+}
+
+// NewMessageValidationReport returns an empty report, that is valid until a failed check is added
+func NewMessageValidationReport(msgType MessageType) *MessageValidationReport {
+	return &MessageValidationReport{
+		Valid:  true,
+		Type:   msgType,
+		Checks: []*MessageValidationResult{},
+	}
+}
+
+// AddCheck records the outcome of a check, and returns true if it passed
+func (r *MessageValidationReport) AddCheck(check MessageValidationCheck, err error) bool {
+	result := &MessageValidationResult{Check: check, Valid: err == nil}
+	if err != nil {
+		result.Error = err.Error()
+		r.Valid = false
+	}
+	r.Checks = append(r.Checks, result)
+	return result.Valid
+}
@@ -0,0 +1,39 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageValidationReport(t *testing.T) {
+	r := NewMessageValidationReport(MessageTypeBroadcast)
+	assert.True(t, r.Valid)
+
+	assert.True(t, r.AddCheck(MessageValidationCheckIdentity, nil))
+	assert.True(t, r.Valid)
+
+	assert.False(t, r.AddCheck(MessageValidationCheckData, fmt.Errorf("pop")))
+	assert.False(t, r.Valid)
+	assert.Equal(t, []*MessageValidationResult{
+		{Check: MessageValidationCheckIdentity, Valid: true},
+		{Check: MessageValidationCheckData, Valid: false, Error: "pop"},
+	}, r.Checks)
+}
@@ -0,0 +1,52 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// ApprovalPolicy requires a number of approvals from a set of designated approvers, before a message
+// tagged with the name of the policy is sent to the network
+type ApprovalPolicy struct {
+	Name      string   `json:"name"`
+	Required  int      `json:"required"`
+	Approvers []string `json:"approvers"`
+}
+
+// ApprovalInput is the input to approve a message that is awaiting approval
+type ApprovalInput struct {
+	Approver string `json:"approver"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// MessageApproval is the audit record of an approver approving a message, under the policy the message is tagged with
+type MessageApproval struct {
+	ID        *UUID   `json:"id"`
+	Namespace string  `json:"namespace"`
+	Message   *UUID   `json:"message"`
+	Policy    string  `json:"policy"`
+	Approver  string  `json:"approver"`
+	Comment   string  `json:"comment,omitempty"`
+	Created   *FFTime `json:"created"`
+}
+
+// HasApprover returns true if the approver is one of the designated approvers of the policy
+func (p *ApprovalPolicy) HasApprover(approver string) bool {
+	for _, a := range p.Approvers {
+		if a == approver {
+			return true
+		}
+	}
+	return false
+}
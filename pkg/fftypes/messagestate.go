@@ -0,0 +1,34 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// MessageStateRecord is a denormalized summary of the state of a message, maintained by the aggregator in the same
+// database transaction that dispatches the message. Lists of messages and their states can be queried from this one
+// record, rather than joining the messages with their batches, transactions and replies.
+//
+// A reply can be confirmed before the request it correlates to, in which case the record of the request only has
+// a reply count until the request itself is dispatched.
+type MessageStateRecord struct {
+	ID         *UUID        `json:"id"`
+	Namespace  string       `json:"namespace"`
+	State      MessageState `json:"state,omitempty" ffenum:"messagestate"`
+	Confirmed  *FFTime      `json:"confirmed,omitempty"`
+	Batch      *UUID        `json:"batch,omitempty"`
+	TX         *UUID        `json:"tx,omitempty"`
+	ReplyCount int64        `json:"replyCount"`
+	Updated    *FFTime      `json:"updated"`
+}
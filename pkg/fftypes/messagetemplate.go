@@ -0,0 +1,182 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// MessageTemplate is a named skeleton for messages that are sent repeatedly with the same shape.
+// A send that references the template by name inherits the header fields, and the data entries
+// (including their datatype references and default values), for anything it does not supply itself.
+type MessageTemplate struct {
+	ID          *UUID                 `json:"id"`
+	Namespace   string                `json:"namespace"`
+	Name        string                `json:"name"`
+	Description string                `json:"description,omitempty"`
+	Header      MessageTemplateHeader `json:"header"`
+	Group       *InputGroup           `json:"group,omitempty"`
+	Data        InlineData            `json:"data"`
+	Created     *FFTime               `json:"created"`
+	Updated     *FFTime               `json:"updated,omitempty"`
+}
+
+// MessageTemplateHeader is the subset of the message header that can be defaulted from a template
+type MessageTemplateHeader struct {
+	SignerRef
+	Topics FFStringArray `json:"topics,omitempty"`
+	Tag    string        `json:"tag,omitempty"`
+}
+
+// Apply fills in the fields of the message that are not set, from the template.
+// Data entries are matched by position. Where both the template and the message supply a JSON
+// object value for an entry, the fields of the message value override those of the template.
+func (t *MessageTemplate) Apply(in *MessageInOut) {
+	header := &in.Header
+	if header.Author == "" && header.Key == "" {
+		header.SignerRef = t.Header.SignerRef
+	}
+	if len(header.Topics) == 0 {
+		header.Topics = t.Header.Topics
+	}
+	if header.Tag == "" {
+		header.Tag = t.Header.Tag
+	}
+	if in.Group == nil && header.Group == nil && t.Group != nil {
+		group := *t.Group
+		in.Group = &group
+	}
+
+	for i, tmplData := range t.Data {
+		if i >= len(in.InlineData) {
+			in.InlineData = append(in.InlineData, nil)
+		}
+		if in.InlineData[i] == nil {
+			d := *tmplData
+			in.InlineData[i] = &d
+			continue
+		}
+		d := in.InlineData[i]
+		if d.ID != nil {
+			// A reference to existing data is used as-is
+			continue
+		}
+		if d.Validator == "" {
+			d.Validator = tmplData.Validator
+		}
+		if d.Datatype == nil {
+			d.Datatype = tmplData.Datatype
+		}
+		d.Value = mergeTemplateValue(tmplData.Value, d.Value)
+	}
+}
+
+func mergeTemplateValue(defaults, value *JSONAny) *JSONAny {
+	if value.IsNil() {
+		return defaults
+	}
+	if defaults.IsNil() {
+		return value
+	}
+	defaultObj, ok1 := defaults.JSONObjectOk(true)
+	valueObj, ok2 := value.JSONObjectOk(true)
+	if !ok1 || !ok2 {
+		return value
+	}
+	merged := JSONObject{}
+	for k, v := range defaultObj {
+		merged[k] = v
+	}
+	for k, v := range valueObj {
+		merged[k] = v
+	}
+	b, _ := json.Marshal(merged)
+	return JSONAnyPtrBytes(b)
+}
+
+// Scan implements sql.Scanner
+func (h *MessageTemplateHeader) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(src, &h)
+	case string:
+		if src == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(src), &h)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, h)
+	}
+}
+
+// Value implements sql.Valuer
+func (h MessageTemplateHeader) Value() (driver.Value, error) {
+	return json.Marshal(&h)
+}
+
+// Scan implements sql.Scanner
+func (ig *InputGroup) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(src, &ig)
+	case string:
+		if src == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(src), &ig)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, ig)
+	}
+}
+
+// Value implements sql.Valuer
+func (ig *InputGroup) Value() (driver.Value, error) {
+	if ig == nil {
+		return nil, nil
+	}
+	return json.Marshal(ig)
+}
+
+// Scan implements sql.Scanner
+func (id *InlineData) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(src, &id)
+	case string:
+		if src == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(src), &id)
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, id)
+	}
+}
+
+// Value implements sql.Valuer
+func (id InlineData) Value() (driver.Value, error) {
+	return json.Marshal(id)
+}
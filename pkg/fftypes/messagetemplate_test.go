@@ -0,0 +1,191 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageTemplateApplyDefaults(t *testing.T) {
+	tmpl := &MessageTemplate{
+		Header: MessageTemplateHeader{
+			SignerRef: SignerRef{Author: "did:firefly:org/org1"},
+			Topics:    FFStringArray{"topic1"},
+			Tag:       "tag1",
+		},
+		Group: &InputGroup{
+			Members: []MemberInput{{Identity: "org2"}},
+		},
+		Data: InlineData{
+			{
+				Datatype: &DatatypeRef{Name: "widget", Version: "1.0"},
+				Value:    JSONAnyPtr(`{"color":"red","size":1}`),
+			},
+			{
+				Value: JSONAnyPtr(`"footer"`),
+			},
+		},
+	}
+
+	in := &MessageInOut{}
+	tmpl.Apply(in)
+	assert.Equal(t, "did:firefly:org/org1", in.Header.Author)
+	assert.Equal(t, FFStringArray{"topic1"}, in.Header.Topics)
+	assert.Equal(t, "tag1", in.Header.Tag)
+	assert.Equal(t, "org2", in.Group.Members[0].Identity)
+	assert.Len(t, in.InlineData, 2)
+	assert.Equal(t, "widget", in.InlineData[0].Datatype.Name)
+	assert.Equal(t, `{"color":"red","size":1}`, in.InlineData[0].Value.String())
+	assert.Equal(t, `"footer"`, in.InlineData[1].Value.String())
+}
+
+func TestMessageTemplateApplyOverrides(t *testing.T) {
+	tmpl := &MessageTemplate{
+		Header: MessageTemplateHeader{
+			SignerRef: SignerRef{Author: "did:firefly:org/org1"},
+			Topics:    FFStringArray{"topic1"},
+			Tag:       "tag1",
+		},
+		Group: &InputGroup{
+			Members: []MemberInput{{Identity: "org2"}},
+		},
+		Data: InlineData{
+			{
+				Validator: ValidatorTypeJSON,
+				Datatype:  &DatatypeRef{Name: "widget", Version: "1.0"},
+				Value:     JSONAnyPtr(`{"color":"red","size":1}`),
+			},
+			{
+				Value: JSONAnyPtr(`"header"`),
+			},
+			{
+				Value: JSONAnyPtr(`{"a":1}`),
+			},
+		},
+	}
+
+	dataID := NewUUID()
+	in := &MessageInOut{
+		Message: Message{
+			Header: MessageHeader{
+				SignerRef: SignerRef{Key: "0x12345"},
+				Topics:    FFStringArray{"topic2"},
+				Tag:       "tag2",
+				Group:     NewRandB32(),
+			},
+		},
+		InlineData: InlineData{
+			{
+				Value: JSONAnyPtr(`{"size":2}`),
+			},
+			{
+				DataRef: DataRef{ID: dataID},
+			},
+			{
+				Value: JSONAnyPtr(`"not an object"`),
+			},
+			{
+				Value: JSONAnyPtr(`"extra"`),
+			},
+		},
+	}
+	tmpl.Apply(in)
+	assert.Equal(t, "", in.Header.Author)
+	assert.Equal(t, "0x12345", in.Header.Key)
+	assert.Equal(t, FFStringArray{"topic2"}, in.Header.Topics)
+	assert.Equal(t, "tag2", in.Header.Tag)
+	assert.Nil(t, in.Group)
+	assert.Len(t, in.InlineData, 4)
+	assert.Equal(t, ValidatorTypeJSON, in.InlineData[0].Validator)
+	assert.Equal(t, "widget", in.InlineData[0].Datatype.Name)
+	assert.Equal(t, `{"color":"red","size":2}`, in.InlineData[0].Value.String())
+	assert.Equal(t, dataID, in.InlineData[1].ID)
+	assert.Nil(t, in.InlineData[1].Value)
+	assert.Equal(t, `"not an object"`, in.InlineData[2].Value.String())
+	assert.Equal(t, `"extra"`, in.InlineData[3].Value.String())
+}
+
+func TestMergeTemplateValue(t *testing.T) {
+	assert.Nil(t, mergeTemplateValue(nil, nil))
+	assert.Equal(t, `{"a":1}`, mergeTemplateValue(nil, JSONAnyPtr(`{"a":1}`)).String())
+	assert.Equal(t, `{"a":1}`, mergeTemplateValue(JSONAnyPtr(`{"a":1}`), nil).String())
+	assert.Equal(t, `"b"`, mergeTemplateValue(JSONAnyPtr(`"a"`), JSONAnyPtr(`"b"`)).String())
+}
+
+func TestMessageTemplateHeaderDatabaseSerialization(t *testing.T) {
+	h1 := MessageTemplateHeader{
+		Topics: FFStringArray{"topic1"},
+		Tag:    "tag1",
+	}
+	v, err := h1.Value()
+	assert.NoError(t, err)
+
+	var h2 MessageTemplateHeader
+	err = h2.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, h1, h2)
+	err = h2.Scan(string(v.([]byte)))
+	assert.NoError(t, err)
+	assert.Equal(t, h1, h2)
+
+	assert.NoError(t, h2.Scan(nil))
+	assert.NoError(t, h2.Scan(""))
+	assert.Regexp(t, "FF10125", h2.Scan(12345))
+}
+
+func TestInputGroupDatabaseSerialization(t *testing.T) {
+	var nilGroup *InputGroup
+	v, err := nilGroup.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	g1 := &InputGroup{Name: "group1", Members: []MemberInput{{Identity: "org1"}}}
+	v, err = g1.Value()
+	assert.NoError(t, err)
+
+	var g2 InputGroup
+	err = g2.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, *g1, g2)
+	err = g2.Scan(string(v.([]byte)))
+	assert.NoError(t, err)
+	assert.Equal(t, *g1, g2)
+
+	assert.NoError(t, g2.Scan(nil))
+	assert.NoError(t, g2.Scan(""))
+	assert.Regexp(t, "FF10125", g2.Scan(12345))
+}
+
+func TestInlineDataDatabaseSerialization(t *testing.T) {
+	d1 := InlineData{{Value: JSONAnyPtr(`{"a":1}`)}}
+	v, err := d1.Value()
+	assert.NoError(t, err)
+
+	var d2 InlineData
+	err = d2.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, d1, d2)
+	err = d2.Scan(string(v.([]byte)))
+	assert.NoError(t, err)
+	assert.Equal(t, d1, d2)
+
+	assert.NoError(t, d2.Scan(nil))
+	assert.NoError(t, d2.Scan(""))
+	assert.Regexp(t, "FF10125", d2.Scan(12345))
+}
@@ -24,6 +24,8 @@ type OpType = FFEnum
 var (
 	// OpTypeBlockchainBatchPin is a blockchain transaction to pin a batch
 	OpTypeBlockchainBatchPin = ffEnum("optype", "blockchain_batch_pin")
+	// OpTypeBlockchainPinRollup is a blockchain transaction to pin the Merkle root of a rollup of batch pins
+	OpTypeBlockchainPinRollup = ffEnum("optype", "blockchain_pin_rollup")
 	// OpTypeBlockchainInvoke is a smart contract invoke
 	OpTypeBlockchainInvoke = ffEnum("optype", "blockchain_invoke")
 	// OpTypeSharedStorageBatchBroadcast is a shared storage operation to store broadcast data
@@ -40,6 +42,8 @@ var (
 	OpTypeTokenTransfer = ffEnum("optype", "token_transfer")
 	// OpTypeTokenApproval is a token approval
 	OpTypeTokenApproval = ffEnum("optype", "token_approval")
+	// OpTypeSagaCompensate undoes a completed step of a multi-step send, after a later step failed permanently
+	OpTypeSagaCompensate = ffEnum("optype", "saga_compensate")
 )
 
 // OpStatus is the current status of an operation
@@ -75,18 +79,20 @@ func NewOperation(plugin Named, namespace string, tx *UUID, opType OpType) *Oper
 
 // Operation is a description of an action performed as part of a transaction submitted by this node
 type Operation struct {
-	ID          *UUID      `json:"id"`
-	Namespace   string     `json:"namespace"`
-	Transaction *UUID      `json:"tx"`
-	Type        OpType     `json:"type" ffenum:"optype"`
-	Status      OpStatus   `json:"status"`
-	Error       string     `json:"error,omitempty"`
-	Plugin      string     `json:"plugin"`
-	Input       JSONObject `json:"input,omitempty"`
-	Output      JSONObject `json:"output,omitempty"`
-	Created     *FFTime    `json:"created,omitempty"`
-	Updated     *FFTime    `json:"updated,omitempty"`
-	Retry       *UUID      `json:"retry,omitempty"`
+	ID           *UUID                  `json:"id"`
+	Namespace    string                 `json:"namespace"`
+	Transaction  *UUID                  `json:"tx"`
+	Type         OpType                 `json:"type" ffenum:"optype"`
+	Status       OpStatus               `json:"status"`
+	Error        string                 `json:"error,omitempty"`
+	Plugin       string                 `json:"plugin"`
+	Input        JSONObject             `json:"input,omitempty"`
+	Output       JSONObject             `json:"output,omitempty"`
+	Receipt      *BlockchainReceipt     `json:"receipt,omitempty"`
+	BlockchainTX *BlockchainTransaction `json:"blockchainTx,omitempty"`
+	Created      *FFTime                `json:"created,omitempty"`
+	Updated      *FFTime                `json:"updated,omitempty"`
+	Retry        *UUID                  `json:"retry,omitempty"`
 }
 
 // PreparedOperation is an operation that has gathered all the raw data ready to send to a plugin
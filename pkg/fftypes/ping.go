@@ -0,0 +1,45 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// PingState is the state of a ping sent to another member org
+type PingState = FFEnum
+
+var (
+	// PingStatePending the ping has been sent, and is not yet confirmed
+	PingStatePending = ffEnum("pingstate", "pending")
+	// PingStateConfirmed the ping has been pinned to the blockchain, and confirmed by this node
+	PingStateConfirmed = ffEnum("pingstate", "confirmed")
+	// PingStateCompleted the reply from the target org has been confirmed by this node
+	PingStateCompleted = ffEnum("pingstate", "completed")
+)
+
+// Ping is a lightweight private message sent to another member org, which replies automatically. The ping
+// and the reply are both transferred over data exchange and pinned to the blockchain, so the round trip time
+// is the end-to-end latency of a private message exchange between the two members.
+// The ID of the ping is the ID of the message that was sent.
+type Ping struct {
+	ID           *UUID      `json:"id"`
+	Target       string     `json:"target"`
+	State        PingState  `json:"state" ffenum:"pingstate"`
+	Reply        *UUID      `json:"reply,omitempty"`
+	Created      *FFTime    `json:"created,omitempty"`
+	Confirmed    *FFTime    `json:"confirmed,omitempty"`
+	Completed    *FFTime    `json:"completed,omitempty"`
+	ChainLatency FFDuration `json:"chainLatency,omitempty"`
+	RoundTrip    FFDuration `json:"roundTrip,omitempty"`
+}
@@ -0,0 +1,142 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"crypto/sha256"
+)
+
+// PinRollup is a set of batch pins that were submitted to the blockchain together in a single transaction,
+// pinning only the Merkle root of the individual pins. The rollup is published to shared storage, so that
+// every member of the network can verify it against the root on-chain and process each of the pins.
+type PinRollup struct {
+	ID        *UUID          `json:"id"`
+	Namespace string         `json:"namespace"`
+	Root      *Bytes32       `json:"root"`
+	Pins      []*RolledUpPin `json:"pins"`
+}
+
+// RolledUpPin is the information for a single batch that would otherwise have been pinned in its own transaction
+type RolledUpPin struct {
+	TransactionID *UUID      `json:"tx"`
+	BatchID       *UUID      `json:"batch"`
+	BatchHash     *Bytes32   `json:"hash"`
+	PayloadRef    string     `json:"payloadRef,omitempty"`
+	Contexts      []*Bytes32 `json:"contexts"`
+}
+
+// MerkleProofStep is one level of a proof that a leaf is included under a Merkle root.
+// Left is true when the sibling hash is on the left of the hash being proven.
+type MerkleProofStep struct {
+	Hash *Bytes32 `json:"hash"`
+	Left bool     `json:"left,omitempty"`
+}
+
+// Leaf returns the Merkle tree leaf for the pin, within the namespace of the rollup
+func (p *RolledUpPin) Leaf(namespace string) *Bytes32 {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write([]byte(namespace))
+	h.Write([]byte{0x00})
+	if p.TransactionID != nil {
+		h.Write(p.TransactionID[:])
+	}
+	if p.BatchID != nil {
+		h.Write(p.BatchID[:])
+	}
+	if p.BatchHash != nil {
+		h.Write(p.BatchHash[:])
+	}
+	h.Write([]byte(p.PayloadRef))
+	h.Write([]byte{0x00})
+	for _, c := range p.Contexts {
+		h.Write(c[:])
+	}
+	return HashResult(h)
+}
+
+// Leaves returns the Merkle tree leaves for all the pins in the rollup
+func (r *PinRollup) Leaves() []*Bytes32 {
+	leaves := make([]*Bytes32, len(r.Pins))
+	for i, p := range r.Pins {
+		leaves[i] = p.Leaf(r.Namespace)
+	}
+	return leaves
+}
+
+func merkleNode(left, right *Bytes32) *Bytes32 {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left[:])
+	h.Write(right[:])
+	return HashResult(h)
+}
+
+// merkleLevels builds every level of the tree, from the leaves up to the root.
+// A node without a sibling is promoted to the next level unchanged.
+func merkleLevels(leaves []*Bytes32) [][]*Bytes32 {
+	levels := [][]*Bytes32{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([]*Bytes32, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, merkleNode(level[i], level[i+1]))
+			} else {
+				next = append(next, level[i])
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+// MerkleRoot returns the root of the Merkle tree over the leaves, or nil if there are no leaves
+func MerkleRoot(leaves []*Bytes32) *Bytes32 {
+	if len(leaves) == 0 {
+		return nil
+	}
+	levels := merkleLevels(leaves)
+	return levels[len(levels)-1][0]
+}
+
+// MerkleProof returns the proof that the leaf at the index is included under the root of the tree
+func MerkleProof(leaves []*Bytes32, index int) []*MerkleProofStep {
+	proof := []*MerkleProofStep{}
+	levels := merkleLevels(leaves)
+	for _, level := range levels[:len(levels)-1] {
+		sibling := index ^ 1
+		if sibling < len(level) {
+			proof = append(proof, &MerkleProofStep{Hash: level[sibling], Left: sibling < index})
+		}
+		index /= 2
+	}
+	return proof
+}
+
+// VerifyMerkleProof checks that the leaf is included under the root, using a proof from MerkleProof
+func VerifyMerkleProof(leaf *Bytes32, proof []*MerkleProofStep, root *Bytes32) bool {
+	hash := leaf
+	for _, step := range proof {
+		if step.Left {
+			hash = merkleNode(step.Hash, hash)
+		} else {
+			hash = merkleNode(hash, step.Hash)
+		}
+	}
+	return hash.Equals(root)
+}
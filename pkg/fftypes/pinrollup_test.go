@@ -0,0 +1,60 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinRollupMerkleProofs(t *testing.T) {
+	for count := 1; count <= 9; count++ {
+		rollup := &PinRollup{Namespace: "ns1"}
+		for i := 0; i < count; i++ {
+			rollup.Pins = append(rollup.Pins, &RolledUpPin{
+				TransactionID: NewUUID(),
+				BatchID:       NewUUID(),
+				BatchHash:     NewRandB32(),
+				PayloadRef:    "ref1",
+				Contexts:      []*Bytes32{NewRandB32()},
+			})
+		}
+		leaves := rollup.Leaves()
+		root := MerkleRoot(leaves)
+		for i, leaf := range leaves {
+			proof := MerkleProof(leaves, i)
+			assert.True(t, VerifyMerkleProof(leaf, proof, root), "count=%d index=%d", count, i)
+			assert.False(t, VerifyMerkleProof(NewRandB32(), proof, root), "count=%d index=%d", count, i)
+		}
+	}
+}
+
+func TestPinRollupSingleLeafIsRoot(t *testing.T) {
+	leaf := NewRandB32()
+	assert.Equal(t, leaf, MerkleRoot([]*Bytes32{leaf}))
+	assert.Empty(t, MerkleProof([]*Bytes32{leaf}, 0))
+}
+
+func TestPinRollupEmpty(t *testing.T) {
+	assert.Nil(t, MerkleRoot([]*Bytes32{}))
+}
+
+func TestPinRollupLeafNamespace(t *testing.T) {
+	pin := &RolledUpPin{}
+	assert.NotEqual(t, pin.Leaf("ns1"), pin.Leaf("ns2"))
+}
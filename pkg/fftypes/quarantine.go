@@ -0,0 +1,29 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// QuarantinedMessage records a message received from the network whose data failed validation. The message is set
+// aside in the quarantined state, rather than being rejected or blocking the messages behind it on the same topic,
+// so that it can be reprocessed once the cause has been fixed (such as by broadcasting a missing datatype).
+type QuarantinedMessage struct {
+	ID        *UUID   `json:"id"`
+	Namespace string  `json:"namespace"`
+	Message   *UUID   `json:"message"`
+	Batch     *UUID   `json:"batch,omitempty"`
+	Reason    string  `json:"reason"`
+	Created   *FFTime `json:"created"`
+}
@@ -0,0 +1,48 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// QueueDepths is a snapshot of the internal queues of the node, to help diagnose stalls
+type QueueDepths struct {
+	Dispatchers     []*DispatcherQueueDepth `json:"dispatchers"`
+	BatchProcessors []*BatchQueueDepth      `json:"batchProcessors"`
+	DataExchange    DataExchangeQueueDepth  `json:"dataExchange"`
+	Created         *FFTime                 `json:"created"`
+}
+
+// DispatcherQueueDepth is the number of events in flight to a connection on a subscription
+type DispatcherQueueDepth struct {
+	Subscription SubscriptionRef `json:"subscription"`
+	Connection   string          `json:"connection"`
+	Transport    string          `json:"transport"`
+	Inflight     int             `json:"inflight"`
+	ReadAhead    int             `json:"readAhead"`
+}
+
+// BatchQueueDepth is the number of messages waiting to be assembled, and being assembled, into a batch
+type BatchQueueDepth struct {
+	Dispatcher string `json:"dispatcher"`
+	Name       string `json:"name"`
+	Queued     int    `json:"queued"`
+	Assembling int    `json:"assembling"`
+	Flushing   *UUID  `json:"flushing,omitempty"`
+}
+
+// DataExchangeQueueDepth is the number of data exchange transfers that have been submitted, but not completed
+type DataExchangeQueueDepth struct {
+	PendingOperations int64 `json:"pendingOperations"`
+}
@@ -0,0 +1,205 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// ReportPeriod is the length of the period covered by each scheduled report
+type ReportPeriod = FFEnum
+
+var (
+	// ReportPeriodDaily covers each day, starting at midnight UTC
+	ReportPeriodDaily = ffEnum("reportperiod", "daily")
+	// ReportPeriodWeekly covers each week, starting at midnight UTC on Monday
+	ReportPeriodWeekly = ffEnum("reportperiod", "weekly")
+	// ReportPeriodMonthly covers each calendar month, starting at midnight UTC on the first day
+	ReportPeriodMonthly = ffEnum("reportperiod", "monthly")
+)
+
+// Report is a summary of the activity in a namespace over a completed period, such as a month,
+// generated on a schedule for governance reporting
+type Report struct {
+	ID        *UUID          `json:"id"`
+	Namespace string         `json:"namespace"`
+	Period    ReportPeriod   `json:"period" ffenum:"reportperiod"`
+	Start     *FFTime        `json:"start"`
+	End       *FFTime        `json:"end"`
+	Created   *FFTime        `json:"created"`
+	Metrics   *ReportMetrics `json:"metrics"`
+}
+
+// ReportMetrics are the figures in a report, which are stored together as JSON
+type ReportMetrics struct {
+	Counterparties []*ReportCounterparty `json:"counterparties"`
+	Operations     []*ReportOperations   `json:"operations"`
+	Storage        ReportStorage         `json:"storage"`
+	Costs          []*ReportCosts        `json:"costs"`
+}
+
+// ReportCounterparty is the volume of confirmed messages authored by an identity in the period
+type ReportCounterparty struct {
+	Identity string `json:"identity"`
+	Name     string `json:"name"`
+	Messages int64  `json:"messages"`
+}
+
+// ReportOperations is the failure rate of an operation type, for the operations created in the period
+type ReportOperations struct {
+	Type        OpType  `json:"type"`
+	Total       int64   `json:"total"`
+	Failed      int64   `json:"failed"`
+	FailureRate float64 `json:"failureRate"`
+}
+
+// ReportStorage is the growth in stored messages and data in the period
+type ReportStorage struct {
+	Messages   int64 `json:"messages"`
+	Data       int64 `json:"data"`
+	ValueBytes int64 `json:"valueBytes"`
+	Blobs      int64 `json:"blobs"`
+	BlobBytes  int64 `json:"blobBytes"`
+}
+
+// ReportCosts is the total cost of the blockchain transactions with a receipt, for an operation type,
+// for the operations created in the period
+type ReportCosts struct {
+	Type         OpType    `json:"type"`
+	Transactions int64     `json:"transactions"`
+	GasUsed      *FFBigInt `json:"gasUsed"`
+	Fee          *FFBigInt `json:"fee"`
+}
+
+// PeriodBounds returns the start and end of the most recent period that completed before the time
+func PeriodBounds(period ReportPeriod, now time.Time) (start, end time.Time) {
+	now = now.UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case ReportPeriodDaily:
+		end = midnight
+		start = end.AddDate(0, 0, -1)
+	case ReportPeriodWeekly:
+		end = midnight.AddDate(0, 0, -((int(midnight.Weekday()) + 6) % 7))
+		start = end.AddDate(0, 0, -7)
+	default:
+		end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		start = end.AddDate(0, -1, 0)
+	}
+	return start, end
+}
+
+// FileName is the name used when the report is written to a sink, or downloaded
+func (r *Report) FileName(extension string) string {
+	return fmt.Sprintf("%s-%s-%s.%s", r.Namespace, r.Period, r.Start.Time().UTC().Format("2006-01-02"), extension)
+}
+
+// Formatted renders the report in the requested format - json or csv
+func (r *Report) Formatted(ctx context.Context, format string) ([]byte, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		b, _ := json.MarshalIndent(r, "", "  ")
+		return b, nil
+	case "csv":
+		return r.CSV(), nil
+	default:
+		return nil, i18n.NewError(ctx, i18n.MsgReportFormatInvalid, format)
+	}
+}
+
+// CSV renders the report with one row per figure, with columns for the section of the report,
+// the name of the counterparty or operation type the figure relates to, the metric and the value
+func (r *Report) CSV() []byte {
+	rows := [][]string{
+		{"section", "name", "metric", "value"},
+		{"report", "", "namespace", r.Namespace},
+		{"report", "", "period", string(r.Period)},
+		{"report", "", "start", r.Start.String()},
+		{"report", "", "end", r.End.String()},
+	}
+	formatInt := func(v int64) string { return strconv.FormatInt(v, 10) }
+	if r.Metrics != nil {
+		for _, c := range r.Metrics.Counterparties {
+			rows = append(rows, []string{"counterparty", c.Identity, "messages", formatInt(c.Messages)})
+		}
+		for _, o := range r.Metrics.Operations {
+			rows = append(rows,
+				[]string{"operations", string(o.Type), "total", formatInt(o.Total)},
+				[]string{"operations", string(o.Type), "failed", formatInt(o.Failed)},
+				[]string{"operations", string(o.Type), "failureRate", strconv.FormatFloat(o.FailureRate, 'f', 4, 64)},
+			)
+		}
+		s := r.Metrics.Storage
+		rows = append(rows,
+			[]string{"storage", "", "messages", formatInt(s.Messages)},
+			[]string{"storage", "", "data", formatInt(s.Data)},
+			[]string{"storage", "", "valueBytes", formatInt(s.ValueBytes)},
+			[]string{"storage", "", "blobs", formatInt(s.Blobs)},
+			[]string{"storage", "", "blobBytes", formatInt(s.BlobBytes)},
+		)
+		for _, c := range r.Metrics.Costs {
+			rows = append(rows,
+				[]string{"costs", string(c.Type), "transactions", formatInt(c.Transactions)},
+				[]string{"costs", string(c.Type), "gasUsed", c.GasUsed.Int().String()},
+				[]string{"costs", string(c.Type), "fee", c.Fee.Int().String()},
+			)
+		}
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.WriteAll(rows) // writing to a bytes.Buffer cannot fail
+	return buf.Bytes()
+}
+
+// Scan implements sql.Scanner
+func (m *ReportMetrics) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+
+	case string:
+		if src == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(src), &m)
+
+	case []byte:
+		return json.Unmarshal(src, &m)
+
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, m)
+	}
+}
+
+// Value implements sql.Valuer
+func (m *ReportMetrics) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	b, _ := json.Marshal(m)
+	return string(b), nil
+}
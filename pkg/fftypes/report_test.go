@@ -0,0 +1,133 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	now := time.Date(2022, 3, 16, 13, 45, 0, 0, time.UTC) // a Wednesday
+
+	start, end := PeriodBounds(ReportPeriodDaily, now)
+	assert.Equal(t, time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2022, 3, 16, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = PeriodBounds(ReportPeriodWeekly, now)
+	assert.Equal(t, time.Date(2022, 3, 7, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = PeriodBounds(ReportPeriodWeekly, time.Date(2022, 3, 20, 23, 0, 0, 0, time.UTC)) // a Sunday
+	assert.Equal(t, time.Date(2022, 3, 7, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = PeriodBounds(ReportPeriodMonthly, time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestReportCSV(t *testing.T) {
+	r := &Report{
+		Namespace: "ns1",
+		Period:    ReportPeriodMonthly,
+		Start:     UnixTime(1643673600), // 2022-02-01
+		End:       UnixTime(1646092800), // 2022-03-01
+		Metrics: &ReportMetrics{
+			Counterparties: []*ReportCounterparty{
+				{Identity: "did:firefly:org/org1", Name: "org1", Messages: 10},
+			},
+			Operations: []*ReportOperations{
+				{Type: OpTypeBlockchainBatchPin, Total: 3, Failed: 1, FailureRate: 1.0 / 3},
+			},
+			Storage: ReportStorage{Messages: 10, Data: 12, ValueBytes: 2048, Blobs: 1, BlobBytes: 1024},
+			Costs: []*ReportCosts{
+				{Type: OpTypeBlockchainBatchPin, Transactions: 2, GasUsed: NewFFBigInt(50000), Fee: NewFFBigInt(500000)},
+			},
+		},
+	}
+	assert.Equal(t, "ns1-monthly-2022-02-01.csv", r.FileName("csv"))
+	assert.Equal(t, `section,name,metric,value
+report,,namespace,ns1
+report,,period,monthly
+report,,start,2022-02-01T00:00:00Z
+report,,end,2022-03-01T00:00:00Z
+counterparty,did:firefly:org/org1,messages,10
+operations,blockchain_batch_pin,total,3
+operations,blockchain_batch_pin,failed,1
+operations,blockchain_batch_pin,failureRate,0.3333
+storage,,messages,10
+storage,,data,12
+storage,,valueBytes,2048
+storage,,blobs,1
+storage,,blobBytes,1024
+costs,blockchain_batch_pin,transactions,2
+costs,blockchain_batch_pin,gasUsed,50000
+costs,blockchain_batch_pin,fee,500000
+`, string(r.CSV()))
+
+	r.Metrics = nil
+	assert.NotContains(t, string(r.CSV()), "storage")
+}
+
+func TestReportMetricsScanValue(t *testing.T) {
+	m := &ReportMetrics{Storage: ReportStorage{Messages: 5}}
+	v, err := m.Value()
+	assert.NoError(t, err)
+
+	var m1 ReportMetrics
+	err = m1.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), m1.Storage.Messages)
+
+	var m2 ReportMetrics
+	err = m2.Scan([]byte(v.(string)))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), m2.Storage.Messages)
+
+	var m3 ReportMetrics
+	assert.NoError(t, m3.Scan(nil))
+	assert.NoError(t, m3.Scan(""))
+	assert.Regexp(t, "FF10125", m3.Scan(12345))
+
+	var mNil *ReportMetrics
+	v, err = mNil.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestReportFormatted(t *testing.T) {
+	r := &Report{Namespace: "ns1", Period: ReportPeriodDaily, Start: Now(), End: Now()}
+
+	b, err := r.Formatted(context.Background(), "JSON")
+	assert.NoError(t, err)
+	var r1 Report
+	err = json.Unmarshal(b, &r1)
+	assert.NoError(t, err)
+	assert.Equal(t, "ns1", r1.Namespace)
+
+	b, err = r.Formatted(context.Background(), "csv")
+	assert.NoError(t, err)
+	assert.Equal(t, r.CSV(), b)
+
+	_, err = r.Formatted(context.Background(), "xml")
+	assert.Regexp(t, "FF10429", err)
+}
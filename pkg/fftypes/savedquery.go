@@ -0,0 +1,32 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// SavedQuery is a named filter over one of the collections of a namespace, so that a consistent view can be
+// shared between applications. The filter uses the same query string syntax as the REST API for the collection,
+// such as "confirmed=?&created=<now-1h&sort=created". It is parsed each time the query is run, so relative
+// times are evaluated at the point of execution.
+type SavedQuery struct {
+	ID          *UUID   `json:"id"`
+	Namespace   string  `json:"namespace"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Collection  string  `json:"collection"`
+	Filter      string  `json:"filter"`
+	Created     *FFTime `json:"created"`
+	Updated     *FFTime `json:"updated,omitempty"`
+}
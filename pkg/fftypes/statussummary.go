@@ -0,0 +1,35 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// NamespaceStatusSummary is a pre-aggregated summary of the activity in a namespace, for dashboards
+type NamespaceStatusSummary struct {
+	Namespace         string                 `json:"namespace"`
+	Updated           *FFTime                `json:"updated"`
+	Messages          map[MessageState]int64 `json:"messages"`
+	EventsLast24h     int64                  `json:"eventsLast24h"`
+	PendingOperations int64                  `json:"pendingOperations"`
+	Subscriptions     []*SubscriptionLag     `json:"subscriptions"`
+}
+
+// SubscriptionLag is the number of events in the namespace that a subscription has yet to deliver
+type SubscriptionLag struct {
+	ID     *UUID  `json:"id"`
+	Name   string `json:"name"`
+	Offset int64  `json:"offset"`
+	Lag    int64  `json:"lag"`
+}
@@ -21,23 +21,41 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"net/url"
+	"strconv"
 
 	"github.com/hyperledger/firefly/internal/i18n"
 )
 
-// SubscriptionFilter contains regular expressions to match against events. All must match for an event to be dispatched to a subscription
+// SubscriptionAllNamespaces can be used in the namespaces of a subscription filter, to match events in every namespace
+const SubscriptionAllNamespaces = "*"
+
+// SubscriptionFilter contains regular expressions to match against events. All must match for an event to be dispatched to a subscription.
+// Namespaces lists other namespaces to match events from, in addition to the namespace of the subscription.
+// A filter that spans other namespaces can only be used on the admin API.
 type SubscriptionFilter struct {
+	Namespaces       []string              `json:"namespaces,omitempty"`
 	Events           string                `json:"events,omitempty"`
 	Message          MessageFilter         `json:"message,omitempty"`
 	Transaction      TransactionFilter     `json:"transaction,omitempty"`
 	BlockchainEvent  BlockchainEventFilter `json:"blockchainevent,omitempty"`
 	Topic            string                `json:"topic,omitempty"`
+	TopicPattern     string                `json:"topicPattern,omitempty"`
 	DeprecatedTopics string                `json:"topics,omitempty"`
 	DeprecatedTag    string                `json:"tag,omitempty"`
 	DeprecatedGroup  string                `json:"group,omitempty"`
 	DeprecatedAuthor string                `json:"author,omitempty"`
 }
 
+// SpansNamespaces returns true if the filter matches events in any namespace other than the one supplied
+func (sf *SubscriptionFilter) SpansNamespaces(ns string) bool {
+	for _, fns := range sf.Namespaces {
+		if fns != ns {
+			return true
+		}
+	}
+	return false
+}
+
 func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
 	return SubscriptionFilter{
 		Events: query.Get("filter.events"),
@@ -54,6 +72,7 @@ func NewSubscriptionFilterFromQuery(query url.Values) SubscriptionFilter {
 			Type: query.Get("filter.transaction.type"),
 		},
 		Topic:            query.Get("filter.topic"),
+		TopicPattern:     query.Get("filter.topicPattern"),
 		DeprecatedTag:    query.Get("filter.tag"),
 		DeprecatedTopics: query.Get("filter.topics"),
 		DeprecatedGroup:  query.Get("filter.group"),
@@ -86,11 +105,50 @@ const (
 	SubOptsFirstEventNewest SubOptsFirstEvent = "newest"
 )
 
+// UnmarshalJSON accepts an exact sequence as a JSON number, as well as a string
+func (fe *SubOptsFirstEvent) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*fe = SubOptsFirstEvent(s)
+		return nil
+	}
+	var sequence int64
+	if err := json.Unmarshal(b, &sequence); err != nil {
+		return err
+	}
+	*fe = SubOptsFirstEvent(strconv.FormatInt(sequence, 10))
+	return nil
+}
+
+// SubOptsOrdering is the order in which events are dispatched to the subscription
+type SubOptsOrdering string
+
+const (
+	// SubOptsOrderingSequence dispatches events in the order they occurred
+	SubOptsOrderingSequence SubOptsOrdering = "sequence"
+	// SubOptsOrderingPriority dispatches events for higher priority messages first (then in sequence), within each page of events read by the dispatcher
+	SubOptsOrderingPriority SubOptsOrdering = "priority"
+)
+
+// SubOptsSerialization is the wire format of the event frames delivered to the subscription, on the transports that support it
+type SubOptsSerialization string
+
+const (
+	// SubOptsSerializationJSON sends event frames as JSON
+	SubOptsSerializationJSON SubOptsSerialization = "json"
+	// SubOptsSerializationMsgPack sends event frames as MessagePack, with the same structure as the JSON frames
+	SubOptsSerializationMsgPack SubOptsSerialization = "msgpack"
+	// SubOptsSerializationProtobuf sends event frames as Protocol Buffers, using the schema published by the node
+	SubOptsSerializationProtobuf SubOptsSerialization = "protobuf"
+)
+
 // SubscriptionCoreOptions are the core options that apply across all transports
 type SubscriptionCoreOptions struct {
-	FirstEvent *SubOptsFirstEvent `json:"firstEvent,omitempty"`
-	ReadAhead  *uint16            `json:"readAhead,omitempty"`
-	WithData   *bool              `json:"withData,omitempty"`
+	FirstEvent    *SubOptsFirstEvent    `json:"firstEvent,omitempty"`
+	ReadAhead     *uint16               `json:"readAhead,omitempty"`
+	WithData      *bool                 `json:"withData,omitempty"`
+	Ordering      *SubOptsOrdering      `json:"ordering,omitempty"`
+	Serialization *SubOptsSerialization `json:"serialization,omitempty"`
 }
 
 // SubscriptionOptions cutomize the behavior of subscriptions
@@ -123,6 +181,11 @@ type Subscription struct {
 	Updated   *FFTime             `json:"updated"`
 }
 
+// SubscriptionRewind asks for the events of a durable subscription to be delivered again, starting from the event with the given sequence
+type SubscriptionRewind struct {
+	Sequence *int64 `json:"sequence"`
+}
+
 func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
 	so.additionalOptions = JSONObject{}
 	err := json.Unmarshal(b, &so.additionalOptions)
@@ -135,6 +198,8 @@ func (so *SubscriptionOptions) UnmarshalJSON(b []byte) error {
 	delete(so.additionalOptions, "firstEvent")
 	delete(so.additionalOptions, "readAhead")
 	delete(so.additionalOptions, "withData")
+	delete(so.additionalOptions, "ordering")
+	delete(so.additionalOptions, "serialization")
 	return nil
 }
 
@@ -151,6 +216,12 @@ func (so SubscriptionOptions) MarshalJSON() ([]byte, error) {
 	if so.ReadAhead != nil {
 		so.additionalOptions["readAhead"] = float64(*so.ReadAhead)
 	}
+	if so.Ordering != nil {
+		so.additionalOptions["ordering"] = *so.Ordering
+	}
+	if so.Serialization != nil {
+		so.additionalOptions["serialization"] = *so.Serialization
+	}
 	return json.Marshal(&so.additionalOptions)
 }
 
@@ -0,0 +1,32 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// SubscriptionDryRun is a candidate subscription filter to evaluate, either against the most recent
+// events in the namespace, or against a sample of events provided by the caller
+type SubscriptionDryRun struct {
+	Filter SubscriptionFilter `json:"filter"`
+	Limit  int                `json:"limit,omitempty"`
+	Events []*EnrichedEvent   `json:"events,omitempty"`
+}
+
+// SubscriptionDryRunResult reports whether a single event would be delivered on a subscription with the candidate filter
+type SubscriptionDryRunResult struct {
+	Event    *EnrichedEvent `json:"event"`
+	Matched  bool           `json:"matched"`
+	Mismatch string         `json:"mismatch,omitempty"`
+}
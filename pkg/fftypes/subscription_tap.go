@@ -0,0 +1,47 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// TapDecision is a decision the event dispatcher made about an event, as reported to a subscription tap
+type TapDecision = FFEnum
+
+var (
+	// TapDecisionMatched the event matched the filter of the subscription
+	TapDecisionMatched = ffEnum("tapdecision", "matched")
+	// TapDecisionFiltered the event did not match the filter of the subscription, and will not be delivered
+	TapDecisionFiltered = ffEnum("tapdecision", "filtered")
+	// TapDecisionHeld the event matched, but is held until the consumer acknowledges events within the readahead
+	TapDecisionHeld = ffEnum("tapdecision", "held")
+	// TapDecisionDispatched the event was passed to the transport for delivery to the consumer
+	TapDecisionDispatched = ffEnum("tapdecision", "dispatched")
+	// TapDecisionAcked the consumer acknowledged the event
+	TapDecisionAcked = ffEnum("tapdecision", "acked")
+	// TapDecisionRejected the consumer (or the transport) rejected the event, so it will be redelivered
+	TapDecisionRejected = ffEnum("tapdecision", "rejected")
+)
+
+// SubscriptionTapRecord is a single entry in the decision log streamed from a debug tap on a live subscription
+type SubscriptionTapRecord struct {
+	Timestamp    *FFTime     `json:"timestamp"`
+	Subscription *UUID       `json:"subscription"`
+	Connection   string      `json:"connection"`
+	Event        *UUID       `json:"event"`
+	Sequence     int64       `json:"sequence"`
+	EventType    EventType   `json:"eventType" ffenum:"eventtype"`
+	Decision     TapDecision `json:"decision" ffenum:"tapdecision"`
+	Info         string      `json:"info,omitempty"`
+}
@@ -28,12 +28,16 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	firstEvent := SubOptsFirstEventNewest
 	readAhead := uint16(50)
 	yes := true
+	ordering := SubOptsOrderingPriority
+	serialization := SubOptsSerializationMsgPack
 	sub1 := &Subscription{
 		Options: SubscriptionOptions{
 			SubscriptionCoreOptions: SubscriptionCoreOptions{
-				FirstEvent: &firstEvent,
-				ReadAhead:  &readAhead,
-				WithData:   &yes,
+				FirstEvent:    &firstEvent,
+				ReadAhead:     &readAhead,
+				WithData:      &yes,
+				Ordering:      &ordering,
+				Serialization: &serialization,
 			},
 		},
 		Filter: SubscriptionFilter{},
@@ -46,7 +50,7 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	// Verify it serializes as bytes to the database
 	b1, err := sub1.Options.Value()
 	assert.NoError(t, err)
-	assert.Equal(t, `{"firstEvent":"newest","my-nested-opts":{"myopt1":12345,"myopt2":"test"},"readAhead":50,"withData":true}`, string(b1.([]byte)))
+	assert.Equal(t, `{"firstEvent":"newest","my-nested-opts":{"myopt1":12345,"myopt2":"test"},"ordering":"priority","readAhead":50,"serialization":"msgpack","withData":true}`, string(b1.([]byte)))
 
 	f1, err := sub1.Filter.Value()
 	assert.NoError(t, err)
@@ -61,12 +65,16 @@ func TestSubscriptionOptionsDatabaseSerialization(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, SubOptsFirstEventNewest, *sub2.Options.FirstEvent)
 	assert.Equal(t, uint16(50), *sub2.Options.ReadAhead)
+	assert.Equal(t, SubOptsOrderingPriority, *sub2.Options.Ordering)
+	assert.Equal(t, SubOptsSerializationMsgPack, *sub2.Options.Serialization)
 	assert.Equal(t, string(b1.([]byte)), string(b2.([]byte)))
 
 	// Confirm we don't pass core options, to transports
 	assert.Nil(t, sub2.Options.TransportOptions()["withData"])
 	assert.Nil(t, sub2.Options.TransportOptions()["firstEvent"])
 	assert.Nil(t, sub2.Options.TransportOptions()["readAhead"])
+	assert.Nil(t, sub2.Options.TransportOptions()["ordering"])
+	assert.Nil(t, sub2.Options.TransportOptions()["serialization"])
 
 	// Confirm we get back the transport options
 	assert.Equal(t, float64(12345), sub2.Options.TransportOptions().GetObject("my-nested-opts")["myopt1"])
@@ -107,11 +115,31 @@ func TestSubscriptionUnMarshalFail(t *testing.T) {
 	assert.Regexp(t, "readAhead", err)
 }
 
+func TestSubscriptionFirstEventUnmarshal(t *testing.T) {
+
+	var so SubscriptionOptions
+	err := json.Unmarshal([]byte(`{"firstEvent": "oldest"}`), &so)
+	assert.NoError(t, err)
+	assert.Equal(t, SubOptsFirstEventOldest, *so.FirstEvent)
+
+	err = json.Unmarshal([]byte(`{"firstEvent": 12345}`), &so)
+	assert.NoError(t, err)
+	assert.Equal(t, SubOptsFirstEvent("12345"), *so.FirstEvent)
+
+	b, err := json.Marshal(&so)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"firstEvent":"12345"}`, string(b))
+
+	err = json.Unmarshal([]byte(`{"firstEvent": 1.5}`), &so)
+	assert.Regexp(t, "cannot unmarshal number 1.5", err)
+}
+
 func TestNewSubscriptionFilterFromQuery(t *testing.T) {
-	query, _ := url.ParseQuery("filter.events=message_confirmed&filter.topic=topic1&filter.message.author=did:firefly:org/author1&filter.blockchain.name=flapflip&filter.transaction.type=test&filter.group=deprecated")
+	query, _ := url.ParseQuery("filter.events=message_confirmed&filter.topic=topic1&filter.topicPattern=a.%23&filter.message.author=did:firefly:org/author1&filter.blockchain.name=flapflip&filter.transaction.type=test&filter.group=deprecated")
 	expectedFilter := SubscriptionFilter{
-		Events: "message_confirmed",
-		Topic:  "topic1",
+		Events:       "message_confirmed",
+		Topic:        "topic1",
+		TopicPattern: "a.#",
 		Message: MessageFilter{
 			Author: "did:firefly:org/author1",
 		},
@@ -127,3 +155,10 @@ func TestNewSubscriptionFilterFromQuery(t *testing.T) {
 	assert.Equal(t, expectedFilter, filter)
 
 }
+
+func TestSubscriptionFilterSpansNamespaces(t *testing.T) {
+	assert.False(t, (&SubscriptionFilter{}).SpansNamespaces("ns1"))
+	assert.False(t, (&SubscriptionFilter{Namespaces: []string{"ns1"}}).SpansNamespaces("ns1"))
+	assert.True(t, (&SubscriptionFilter{Namespaces: []string{"ns1", "ns2"}}).SpansNamespaces("ns1"))
+	assert.True(t, (&SubscriptionFilter{Namespaces: []string{SubscriptionAllNamespaces}}).SpansNamespaces("ns1"))
+}
@@ -0,0 +1,123 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+// SwapState is the progress of a swap through the escrow
+type SwapState = FFEnum
+
+var (
+	// SwapStateAwaitingDeposits is waiting for both legs to be transferred into the escrow
+	SwapStateAwaitingDeposits = ffEnum("swapstate", "awaiting_deposits")
+	// SwapStateReleasing is transferring each leg from the escrow to its recipient
+	SwapStateReleasing = ffEnum("swapstate", "releasing")
+	// SwapStateCompleted is when both legs have been released to their recipients
+	SwapStateCompleted = ffEnum("swapstate", "completed")
+	// SwapStateRefunding is transferring each deposited leg back to its depositor, after the swap timed out
+	SwapStateRefunding = ffEnum("swapstate", "refunding")
+	// SwapStateRefunded is when every deposited leg has been returned to its depositor
+	SwapStateRefunded = ffEnum("swapstate", "refunded")
+	// SwapStateFailed is when the initiator leg could not be deposited, so the swap never started
+	SwapStateFailed = ffEnum("swapstate", "failed")
+)
+
+// SwapLegInput is one side of a requested swap
+type SwapLegInput struct {
+	Pool       string   `json:"pool"`
+	TokenIndex string   `json:"tokenIndex,omitempty"`
+	Amount     FFBigInt `json:"amount"`
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+}
+
+// SwapInput is a request to exchange tokens between two parties, through an escrow key owned by this node.
+// The initiator leg is deposited by this node when the swap is created. The counterparty deposits their
+// leg by transferring it to the escrow key.
+type SwapInput struct {
+	Initiator    SwapLegInput `json:"initiator"`
+	Counterparty SwapLegInput `json:"counterparty"`
+	Timeout      FFDuration   `json:"timeout,omitempty"`
+}
+
+// SwapLeg is one side of a swap, along with the transfers that move it into and out of the escrow
+type SwapLeg struct {
+	Pool       *UUID    `json:"pool"`
+	Connector  string   `json:"connector"`
+	TokenIndex string   `json:"tokenIndex,omitempty"`
+	Amount     FFBigInt `json:"amount"`
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Deposit    *UUID    `json:"deposit,omitempty"`   // local ID of the transfer into the escrow
+	DepositTX  *UUID    `json:"depositTx,omitempty"` // only set for a deposit submitted by this node
+	Release    *UUID    `json:"release,omitempty"`   // local ID of the transfer out of the escrow - to the recipient, or back to the depositor on refund
+	ReleaseTX  *UUID    `json:"releaseTx,omitempty"`
+}
+
+// Swap is a two leg exchange of tokens, coordinated through an escrow key owned by this node
+type Swap struct {
+	ID           *UUID     `json:"id"`
+	Namespace    string    `json:"namespace"`
+	State        SwapState `json:"state" ffenum:"swapstate"`
+	Escrow       string    `json:"escrow"`
+	Initiator    *SwapLeg  `json:"initiator"`
+	Counterparty *SwapLeg  `json:"counterparty"`
+	Error        string    `json:"error,omitempty"`
+	Created      *FFTime   `json:"created"`
+	Updated      *FFTime   `json:"updated,omitempty"`
+	Expires      *FFTime   `json:"expires"`
+}
+
+// Legs returns both legs of the swap, initiator first
+func (s *Swap) Legs() []*SwapLeg {
+	return []*SwapLeg{s.Initiator, s.Counterparty}
+}
+
+// Scan implements sql.Scanner
+func (l *SwapLeg) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+
+	case string:
+		if src == "" {
+			return nil
+		}
+		return json.Unmarshal([]byte(src), &l)
+
+	case []byte:
+		return json.Unmarshal(src, &l)
+
+	default:
+		return i18n.NewError(context.Background(), i18n.MsgScanFailed, src, l)
+	}
+}
+
+// Value implements sql.Valuer
+func (l *SwapLeg) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	b, _ := json.Marshal(l)
+	return string(b), nil
+}
@@ -0,0 +1,65 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwapLegs(t *testing.T) {
+	swap := &Swap{
+		Initiator:    &SwapLeg{From: "0x1"},
+		Counterparty: &SwapLeg{From: "0x2"},
+	}
+	legs := swap.Legs()
+	assert.Equal(t, "0x1", legs[0].From)
+	assert.Equal(t, "0x2", legs[1].From)
+}
+
+func TestSwapLegScanValue(t *testing.T) {
+	leg := &SwapLeg{
+		Pool:    NewUUID(),
+		Amount:  *NewFFBigInt(100),
+		From:    "0x1",
+		To:      "0x2",
+		Deposit: NewUUID(),
+	}
+	v, err := leg.Value()
+	assert.NoError(t, err)
+
+	var leg2 SwapLeg
+	err = leg2.Scan(v)
+	assert.NoError(t, err)
+	assert.Equal(t, leg.Deposit, leg2.Deposit)
+	assert.Equal(t, int64(100), leg2.Amount.Int().Int64())
+
+	var leg3 SwapLeg
+	err = leg3.Scan([]byte(v.(string)))
+	assert.NoError(t, err)
+	assert.Equal(t, leg.Pool, leg3.Pool)
+
+	var nilLeg *SwapLeg
+	v, err = nilLeg.Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	assert.NoError(t, leg2.Scan(nil))
+	assert.NoError(t, leg2.Scan(""))
+	assert.Regexp(t, "FF10125", leg2.Scan(12345))
+}
@@ -29,6 +29,7 @@ type TokenApproval struct {
 	Key             string         `json:"key,omitempty"`
 	Operator        string         `json:"operator,omitempty"`
 	Approved        bool           `json:"approved"`
+	Allowance       *FFBigInt      `json:"allowance,omitempty"` // for fungible pools, the amount the operator may transfer
 	Info            JSONObject     `json:"info,omitempty"`
 	Namespace       string         `json:"namespace,omitempty"`
 	ProtocolID      string         `json:"protocolId,omitempty"`
@@ -0,0 +1,33 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// TokenMetadata links a single token in a non-fungible pool to the data record that is the
+// authoritative metadata document for that token
+type TokenMetadata struct {
+	ID         *UUID   `json:"id"`
+	Namespace  string  `json:"namespace"`
+	Pool       *UUID   `json:"pool"`
+	TokenIndex string  `json:"tokenIndex"`
+	Data       *UUID   `json:"data"`
+	Created    *FFTime `json:"created"`
+	Updated    *FFTime `json:"updated,omitempty"`
+}
+
+type TokenMetadataInput struct {
+	Data *UUID `json:"data"`
+}
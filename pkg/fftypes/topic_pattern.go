@@ -0,0 +1,88 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hyperledger/firefly/internal/i18n"
+)
+
+const (
+	// TopicLevelSeparator separates the levels of a hierarchical topic, such as "orders.emea.uk"
+	TopicLevelSeparator = "."
+	// TopicWildcardSingle matches exactly one non-empty level of a topic
+	TopicWildcardSingle = "*"
+	// TopicWildcardMulti matches zero or more trailing levels of a topic, and must be the last level of a pattern
+	TopicWildcardMulti = "#"
+)
+
+// TopicPattern is a parsed MQTT-style pattern for matching hierarchical topics, such as "orders.*.uk" or "orders.#"
+type TopicPattern struct {
+	// Levels are the levels of the pattern before any multi-level wildcard
+	Levels []string
+	// MultiLevel is true if the pattern ends with a multi-level wildcard
+	MultiLevel bool
+}
+
+// ParseTopicPattern parses and validates a topic pattern. Wildcards must occupy a whole level.
+func ParseTopicPattern(ctx context.Context, pattern string) (*TopicPattern, error) {
+	levels := strings.Split(pattern, TopicLevelSeparator)
+	tp := &TopicPattern{}
+	for i, level := range levels {
+		switch {
+		case level == "":
+			return nil, i18n.NewError(ctx, i18n.MsgInvalidTopicPattern, pattern)
+		case level == TopicWildcardMulti && i == len(levels)-1:
+			tp.MultiLevel = true
+			continue
+		case level != TopicWildcardSingle && strings.ContainsAny(level, TopicWildcardSingle+TopicWildcardMulti):
+			return nil, i18n.NewError(ctx, i18n.MsgInvalidTopicPattern, pattern)
+		}
+		tp.Levels = append(tp.Levels, level)
+	}
+	return tp, nil
+}
+
+// Matches checks whether a topic matches the pattern
+func (tp *TopicPattern) Matches(topic string) bool {
+	levels := strings.Split(topic, TopicLevelSeparator)
+	if len(levels) < len(tp.Levels) || (!tp.MultiLevel && len(levels) != len(tp.Levels)) {
+		return false
+	}
+	for i, level := range tp.Levels {
+		if (level == TopicWildcardSingle && levels[i] == "") || (level != TopicWildcardSingle && level != levels[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// LiteralAfterWildcard is true if a literal level follows a single-level wildcard in the pattern,
+// such as "orders.*.uk"
+func (tp *TopicPattern) LiteralAfterWildcard() bool {
+	wildcard := false
+	for _, level := range tp.Levels {
+		if level == TopicWildcardSingle {
+			wildcard = true
+		} else if wildcard {
+			return true
+		}
+	}
+	return false
+}
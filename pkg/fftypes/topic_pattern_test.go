@@ -0,0 +1,67 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTopicPatternInvalid(t *testing.T) {
+	for _, p := range []string{"", "a..b", "a.", "#.a", "a.#.b", "a*", "a.b#"} {
+		_, err := ParseTopicPattern(context.Background(), p)
+		assert.Regexp(t, "FF10528", err, p)
+	}
+}
+
+func TestTopicPatternMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		match   bool
+	}{
+		{"a.b.c", "a.b.c", true},
+		{"a.b.c", "a.b", false},
+		{"a.*", "a.b", true},
+		{"a.*", "a", false},
+		{"a.*", "a.", false},
+		{"*", "", false},
+		{"a.*", "a.b.c", false},
+		{"a.*.c", "a.b.c", true},
+		{"a.*.c", "a.b.d", false},
+		{"a.#", "a", true},
+		{"a.#", "a.b.c", true},
+		{"a.#", "ab.c", false},
+		{"*.b.#", "x.b", true},
+		{"*.b.#", "x.c.b", false},
+		{"#", "anything.at.all", true},
+	}
+	for _, test := range tests {
+		tp, err := ParseTopicPattern(context.Background(), test.pattern)
+		assert.NoError(t, err)
+		assert.Equal(t, test.match, tp.Matches(test.topic), "%s %s", test.pattern, test.topic)
+	}
+}
+
+func TestTopicPatternLiteralAfterWildcard(t *testing.T) {
+	tp, _ := ParseTopicPattern(context.Background(), "a.*.c.#")
+	assert.True(t, tp.LiteralAfterWildcard())
+	tp, _ = ParseTopicPattern(context.Background(), "a.b.*.#")
+	assert.False(t, tp.LiteralAfterWildcard())
+}
@@ -0,0 +1,47 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fftypes
+
+// TopicSchema is the schema inferred from the payloads of the messages confirmed on a topic. Once enough messages
+// have been seen, a message whose payload differs from the schema is reported as drift, and the schema is
+// widened to include it - so each change made by an upstream integration is reported once.
+type TopicSchema struct {
+	Namespace string          `json:"namespace"`
+	Topic     string          `json:"topic"`
+	Datatype  *DatatypeRef    `json:"datatype,omitempty"`
+	Schema    *InferredSchema `json:"schema"`
+	Samples   int64           `json:"samples"`
+	Drifts    int64           `json:"drifts"`
+	LastDrift *SchemaDrift    `json:"lastDrift,omitempty"`
+	Created   *FFTime         `json:"created"`
+	Updated   *FFTime         `json:"updated"`
+}
+
+// InferredSchema is the subset of JSON Schema that is inferred from payloads
+type InferredSchema struct {
+	Type       []string                   `json:"type"`
+	Properties map[string]*InferredSchema `json:"properties,omitempty"`
+	Required   []string                   `json:"required,omitempty"`
+	Items      *InferredSchema            `json:"items,omitempty"`
+}
+
+// SchemaDrift records the differences found between a message and the schema of its topic
+type SchemaDrift struct {
+	Message     *UUID    `json:"message"`
+	Differences []string `json:"differences"`
+	Detected    *FFTime  `json:"detected"`
+}
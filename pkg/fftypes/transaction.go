@@ -27,6 +27,8 @@ var (
 	TransactionTypeUnpinned = ffEnum("txtype", "unpinned")
 	// TransactionTypeBatchPin represents a pinning transaction, that verifies the originator of the data, and sequences the event deterministically between parties
 	TransactionTypeBatchPin = ffEnum("txtype", "batch_pin")
+	// TransactionTypePinRollup represents a single transaction pinning a rollup of many batch pins
+	TransactionTypePinRollup = ffEnum("txtype", "pin_rollup")
 	// TransactionTypeTokenPool represents a token pool creation
 	TransactionTypeTokenPool = ffEnum("txtype", "token_pool")
 	// TransactionTypeTokenTransfer represents a token transfer
@@ -65,13 +67,14 @@ var (
 )
 
 type TransactionStatusDetails struct {
-	Type      TransactionStatusType `json:"type"`
-	SubType   string                `json:"subtype,omitempty"`
-	Status    OpStatus              `json:"status"`
-	Timestamp *FFTime               `json:"timestamp,omitempty"`
-	ID        *UUID                 `json:"id,omitempty"`
-	Error     string                `json:"error,omitempty"`
-	Info      JSONObject            `json:"info,omitempty"`
+	Type         TransactionStatusType  `json:"type"`
+	SubType      string                 `json:"subtype,omitempty"`
+	Status       OpStatus               `json:"status"`
+	Timestamp    *FFTime                `json:"timestamp,omitempty"`
+	ID           *UUID                  `json:"id,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+	Info         JSONObject             `json:"info,omitempty"`
+	BlockchainTX *BlockchainTransaction `json:"blockchainTx,omitempty"`
 }
 
 type TransactionStatus struct {
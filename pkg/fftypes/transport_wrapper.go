@@ -23,10 +23,32 @@ var (
 	TransportPayloadTypeBatch   = ffEnum("transportpayload", "batch")
 )
 
-// TransportWrapper wraps paylaods over data exchange transfers, for easy deserialization at target
+// TransportWrapper wraps paylaods over data exchange transfers, for easy deserialization at target.
+// A wrapper with a tombstone instead of a batch withdraws a pinned batch previously sent to the node,
+// after the pin failed.
 type TransportWrapper struct {
-	Group *Group `json:"group,omitempty"`
-	Batch *Batch `json:"batch,omitempty"`
+	Group     *Group         `json:"group,omitempty"`
+	Batch     *Batch         `json:"batch,omitempty"`
+	Relayed   bool           `json:"relayed,omitempty"`
+	Relay     []*RelayBranch `json:"relay,omitempty"`
+	Tombstone *UUID          `json:"tombstone,omitempty"`
+}
+
+// RelayBranch is a member node in the relay tree of a private batch. The node receiving the batch
+// re-relays it to each of the branches listed in the transport wrapper, so the sender only transfers
+// directly to the roots of the tree.
+type RelayBranch struct {
+	Node  *UUID          `json:"node"`
+	Relay []*RelayBranch `json:"relay,omitempty"`
+}
+
+// RelayNodes returns the IDs of all the nodes in the branch, including the node itself
+func (rb *RelayBranch) RelayNodes() []*UUID {
+	nodes := []*UUID{rb.Node}
+	for _, child := range rb.Relay {
+		nodes = append(nodes, child.RelayNodes()...)
+	}
+	return nodes
 }
 
 type TransportStatusUpdate struct {
@@ -58,3 +58,17 @@ func TestNillBatchManifest(t *testing.T) {
 	assert.Nil(t, tw.Batch.Manifest())
 
 }
+
+func TestRelayNodes(t *testing.T) {
+
+	n1, n2, n3, n4 := NewUUID(), NewUUID(), NewUUID(), NewUUID()
+	rb := &RelayBranch{
+		Node: n1,
+		Relay: []*RelayBranch{
+			{Node: n2, Relay: []*RelayBranch{{Node: n3}}},
+			{Node: n4},
+		},
+	}
+	assert.Equal(t, []*UUID{n1, n2, n3, n4}, rb.RelayNodes())
+
+}
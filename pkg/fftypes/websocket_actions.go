@@ -30,6 +30,9 @@ var (
 
 	// WSClientActionChangeNotifcation a special event type that is a local database change event, and never requires an ack
 	WSClientActionChangeNotifcation = ffEnum("wstype", "change_notification")
+
+	// WSSessionEventType is sent by the server when an ephemeral subscription starts, with a token the client can use to resume the session after a reconnect
+	WSSessionEventType = ffEnum("wstype", "session")
 )
 
 // WSClientActionBase is the base fields of all client actions sent on the websocket
@@ -48,13 +51,17 @@ type WSClientActionStartPayload struct {
 	Filter       SubscriptionFilter  `json:"filter"`
 	Options      SubscriptionOptions `json:"options"`
 	ChangeEvents string              `json:"changeEvents,omitempty"`
+	ResumeToken  string              `json:"resumeToken,omitempty"`
+	Session      string              `json:"session,omitempty"`
 }
 
-// WSClientActionAckPayload acknowldges a received event (not applicable in AutoAck mode)
+// WSClientActionAckPayload acknowldges a received event (not applicable in AutoAck mode), or with UpToSequence
+// acknowledges every event received on the subscription with a sequence up to and including that one
 type WSClientActionAckPayload struct {
 	WSClientActionBase
 
 	ID           *UUID            `json:"id,omitempty"`
+	UpToSequence int64            `json:"upToSequence,omitempty"`
 	Subscription *SubscriptionRef `json:"subscription,omitempty"`
 }
 
@@ -64,6 +71,12 @@ type WSProtocolErrorPayload struct {
 	Error string              `json:"error"`
 }
 
+// WSSessionPayload is sent to the client by the server, with the token of the session for this connection
+type WSSessionPayload struct {
+	Type    WSClientPayloadType `json:"type" ffenum:"wstype"`
+	Session string              `json:"session"`
+}
+
 // WSChangeNotification is a special notification type for a change event, that does *not* require an ack
 type WSChangeNotification struct {
 	WSClientActionBase
@@ -0,0 +1,47 @@
+// Copyright © 2022 Kaleido, Inc.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scanning
+
+import (
+	"context"
+	"io"
+
+	"github.com/hyperledger/firefly/internal/config"
+	"github.com/hyperledger/firefly/pkg/fftypes"
+)
+
+// Plugin is the interface implemented by each blob scanning plugin, which checks blobs received
+// from the network for viruses and other malware, before they are made available to applications
+type Plugin interface {
+	fftypes.Named
+
+	// InitPrefix initializes the set of configuration options that are valid, with defaults. Called on all plugins.
+	InitPrefix(prefix config.Prefix)
+
+	// Init initializes the plugin, with configuration
+	Init(ctx context.Context, prefix config.Prefix) error
+
+	// Capabilities returns capabilities - not called until after Init
+	Capabilities() *Capabilities
+
+	// ScanBlob streams the content of a blob to the scanner. It returns the name of the threat found,
+	// or an empty string if the blob is clean. An error means the scan could not be completed.
+	ScanBlob(ctx context.Context, content io.Reader) (threat string, err error)
+}
+
+type Capabilities struct {
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/hyperledger/firefly/internal/i18n"
 	"github.com/hyperledger/firefly/internal/log"
 	"github.com/hyperledger/firefly/internal/retry"
+	"github.com/hyperledger/firefly/internal/signing"
 	"github.com/hyperledger/firefly/pkg/fftypes"
 )
 
@@ -45,6 +46,8 @@ type WSConfig struct {
 	AuthPassword           string             `json:"authPassword,omitempty"`
 	HTTPHeaders            fftypes.JSONObject `json:"headers,omitempty"`
 	HeartbeatInterval      time.Duration      `json:"heartbeatInterval,omitempty"`
+	SigningKey             string             `json:"signingKey,omitempty"`
+	SigningMaxSkew         time.Duration      `json:"signingMaxSkew,omitempty"`
 }
 
 type WSClient interface {
@@ -75,6 +78,7 @@ type wsClient struct {
 	heartbeatMux         sync.Mutex
 	activePingSent       *time.Time
 	lastPingCompleted    time.Time
+	signer               *signing.Signer
 }
 
 // WSPreConnectHandler will be called before every connect/reconnect. Any error returned will prevent the websocket from connecting.
@@ -120,6 +124,10 @@ func New(ctx context.Context, config *WSConfig, beforeConnect WSPreConnectHandle
 	if authUsername != "" && authPassword != "" {
 		w.headers.Set("Authorization", fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", authUsername, authPassword)))))
 	}
+	if config.SigningKey != "" {
+		// All messages in both directions are wrapped in a signed envelope
+		w.signer = signing.NewSigner(config.SigningKey, config.SigningMaxSkew)
+	}
 
 	return w, nil
 }
@@ -160,6 +168,9 @@ func (w *wsClient) SetURL(url string) {
 }
 
 func (w *wsClient) Send(ctx context.Context, message []byte) error {
+	if w.signer != nil {
+		message = w.signer.SignMessage(message)
+	}
 	// Send
 	select {
 	case w.send <- message:
@@ -248,8 +259,15 @@ func (w *wsClient) readLoop() {
 			return
 		}
 
-		// Pass the message to the consumer
 		l.Tracef("WS %s read (mt=%d): %s", w.url, mt, message)
+		if w.signer != nil {
+			if message, err = w.signer.VerifyMessage(w.ctx, message); err != nil {
+				l.Errorf("WS %s discarded message: %s", w.url, err)
+				continue
+			}
+		}
+
+		// Pass the message to the consumer
 		select {
 		case <-w.sendDone:
 			l.Debugf("WS %s closing reader after send error", w.url)
@@ -18,6 +18,7 @@ package wsclient
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -25,6 +26,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/hyperledger/firefly/internal/signing"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -95,6 +97,55 @@ func TestWSClientE2E(t *testing.T) {
 
 }
 
+func TestWSClientSigned(t *testing.T) {
+
+	toServer, fromServer, url, close := NewTestWSServer(nil)
+	defer close()
+
+	wsConfig := generateConfig()
+	wsConfig.HTTPURL = url
+	wsConfig.SigningKey = "key1"
+	wsConfig.SigningMaxSkew = 1 * time.Minute
+
+	wsc, err := New(context.Background(), wsConfig, nil, nil)
+	assert.NoError(t, err)
+	err = wsc.Connect()
+	assert.NoError(t, err)
+	defer wsc.Close()
+
+	// Outbound messages are wrapped in a signed envelope
+	err = wsc.Send(context.Background(), []byte(`{"type":"ack"}`))
+	assert.NoError(t, err)
+	serverSigner := signing.NewSigner("key1", 1*time.Minute)
+	reflected := <-toServer
+	var envelope signing.SignedMessage
+	err = json.Unmarshal([]byte(reflected), &envelope)
+	assert.NoError(t, err)
+	err = serverSigner.Verify(context.Background(), signing.DirectionOutbound, signing.MethodMessage, "", envelope.Timestamp, envelope.Signature, envelope.Payload)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type":"ack"}`, string(envelope.Payload))
+
+	serverSign := func(payload string) string {
+		ts := time.Now().UTC().Format(time.RFC3339Nano)
+		b, _ := json.Marshal(&signing.SignedMessage{
+			Timestamp: ts,
+			Signature: serverSigner.Signature(signing.DirectionInbound, signing.MethodMessage, "", ts, []byte(payload)),
+			Payload:   json.RawMessage(payload),
+		})
+		return string(b)
+	}
+
+	// Unsigned, reflected, and replayed, inbound messages are discarded
+	signed := serverSign(`{"event":"one"}`)
+	fromServer <- `{"event":"unsigned"}`
+	fromServer <- reflected
+	fromServer <- signed
+	fromServer <- signed
+	fromServer <- serverSign(`{"event":"two"}`)
+	assert.Equal(t, `{"event":"one"}`, string(<-wsc.Receive()))
+	assert.Equal(t, `{"event":"two"}`, string(<-wsc.Receive()))
+}
+
 func TestWSClientBadURL(t *testing.T) {
 	wsConfig := generateConfig()
 	wsConfig.HTTPURL = ":::"